@@ -0,0 +1,335 @@
+// Package archive bundles a plan and its state into a portable tar.gz,
+// so plans can be moved between machines or shared for debugging.
+//
+// An archive holds a manifest.json and, under a directory named after the
+// plan, the plan file, its progress, feedback, and instructions files, the
+// worktree's execution context and checkpoint, and the plan's log.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// ManifestName is the name of the manifest entry in an archive.
+const ManifestName = "manifest.json"
+
+// FormatVersion is the archive layout version written by Export.
+const FormatVersion = 1
+
+// ImportsDir is the directory within .ralph holding the execution context
+// of imported plans that have no worktree yet.
+const ImportsDir = "imports"
+
+// File names of plan state within an archive's plan directory.
+const (
+	PlanFile         = "plan.md"
+	ProgressFile     = "progress.md"
+	FeedbackFile     = "feedback.md"
+	InstructionsFile = "instructions.md"
+	ChangesFile      = "changes.json"
+	ContextFile      = runner.ContextFilename
+	CheckpointFile   = runner.CheckpointFilename
+	LogFile          = "plan.log"
+)
+
+// knownFiles are the entries Read accepts; anything else is ignored.
+var knownFiles = map[string]bool{
+	PlanFile: true, ProgressFile: true, FeedbackFile: true, InstructionsFile: true, ChangesFile: true,
+	ContextFile: true, CheckpointFile: true, LogFile: true,
+}
+
+var (
+	// ErrNoManifest is returned when an archive has no manifest.json.
+	ErrNoManifest = errors.New("archive has no manifest")
+
+	// ErrNoPlan is returned when an archive has no plan file.
+	ErrNoPlan = errors.New("archive has no plan file")
+
+	// ErrUnsupportedVersion is returned for archives written by a newer ralph.
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+)
+
+// Manifest describes an exported plan.
+type Manifest struct {
+	// Version is the archive layout version.
+	Version int `json:"version"`
+
+	// Plan is the plan name.
+	Plan string `json:"plan"`
+
+	// Status is the queue directory the plan was exported from (pending, current, ...).
+	Status string `json:"status,omitempty"`
+
+	// ExportedAt is when the archive was created.
+	ExportedAt time.Time `json:"exported_at"`
+
+	// Files lists the plan state entries included, e.g. "plan.md", "context.json".
+	Files []string `json:"files"`
+}
+
+// Source locates the state of a plan to export.
+type Source struct {
+	// Plan is the plan to export.
+	Plan *plan.Plan
+
+	// Status is the queue directory the plan is in.
+	Status string
+
+	// WorktreePath is the plan's worktree. Its execution context and
+	// checkpoint are included if present (optional).
+	WorktreePath string
+
+	// LogPath is the plan's log file, included if present (optional).
+	LogPath string
+}
+
+// Export writes src as a tar.gz archive to w.
+// Optional files that don't exist are skipped.
+func Export(w io.Writer, src Source) (*Manifest, error) {
+	candidates := []struct {
+		name, path string
+	}{
+		{PlanFile, src.Plan.Path},
+		{ProgressFile, plan.ProgressPath(src.Plan)},
+		{FeedbackFile, plan.FeedbackPath(src.Plan)},
+		{InstructionsFile, plan.InstructionsPath(src.Plan)},
+		{ChangesFile, plan.ChangesPath(src.Plan)},
+	}
+	if src.WorktreePath != "" {
+		candidates = append(candidates,
+			struct{ name, path string }{ContextFile, runner.ContextPath(src.WorktreePath)},
+			struct{ name, path string }{CheckpointFile, runner.CheckpointPath(src.WorktreePath)},
+		)
+	}
+	if src.LogPath != "" {
+		candidates = append(candidates, struct{ name, path string }{LogFile, src.LogPath})
+	}
+
+	manifest := &Manifest{
+		Version:    FormatVersion,
+		Plan:       src.Plan.Name,
+		Status:     src.Status,
+		ExportedAt: time.Now().UTC(),
+	}
+	contents := make(map[string][]byte)
+	for _, c := range candidates {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			if os.IsNotExist(err) && c.name != PlanFile {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", c.path, err)
+		}
+		contents[c.name] = data
+		manifest.Files = append(manifest.Files, c.name)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, ManifestName, manifestData, manifest.ExportedAt); err != nil {
+		return nil, err
+	}
+	for _, name := range manifest.Files {
+		if err := writeEntry(tw, path.Join(manifest.Plan, name), contents[name], manifest.ExportedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("closing tar: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("closing gzip: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeEntry adds a regular file to tw.
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// Archive is an archive read into memory.
+type Archive struct {
+	// Manifest describes the exported plan.
+	Manifest Manifest
+
+	files map[string][]byte
+}
+
+// Read parses a tar.gz archive written by Export.
+func Read(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gz.Close()
+
+	var manifestData []byte
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		if hdr.Name == ManifestName {
+			manifestData = data
+			continue
+		}
+		entries[hdr.Name] = data
+	}
+
+	if manifestData == nil {
+		return nil, ErrNoManifest
+	}
+	a := &Archive{files: make(map[string][]byte)}
+	if err := json.Unmarshal(manifestData, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	if a.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: %d (this ralph reads up to %d)", ErrUnsupportedVersion, a.Manifest.Version, FormatVersion)
+	}
+	// The plan name becomes a file name on import
+	if a.Manifest.Plan == "" || a.Manifest.Plan != filepath.Base(a.Manifest.Plan) || a.Manifest.Plan == ".." {
+		return nil, fmt.Errorf("invalid plan name in manifest: %q", a.Manifest.Plan)
+	}
+
+	for name, data := range entries {
+		dir, file := path.Split(name)
+		if path.Clean(dir) == a.Manifest.Plan && knownFiles[file] {
+			a.files[file] = data
+		}
+	}
+	if _, ok := a.files[PlanFile]; !ok {
+		return nil, ErrNoPlan
+	}
+	return a, nil
+}
+
+// Has reports whether the archive contains the named plan state file.
+func (a *Archive) Has(name string) bool {
+	_, ok := a.files[name]
+	return ok
+}
+
+// ExtractPlan writes the plan, progress, feedback, instructions, and changes
+// files into dir as <plan>.md, <plan>.progress.md, <plan>.feedback.md,
+// <plan>.instructions.md, and <plan>.changes.json, and returns the
+// plan file path. Fails if the plan file already exists in dir.
+func (a *Archive) ExtractPlan(dir string) (string, error) {
+	planPath := filepath.Join(dir, a.Manifest.Plan+".md")
+	if _, err := os.Stat(planPath); err == nil {
+		return "", fmt.Errorf("%s already exists", planPath)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", dir, err)
+	}
+
+	base := filepath.Join(dir, a.Manifest.Plan)
+	// Sidecars first, so a plan file is never left without them
+	for _, f := range []struct{ name, dest string }{
+		{ProgressFile, base + ".progress.md"},
+		{FeedbackFile, base + ".feedback.md"},
+		{InstructionsFile, base + ".instructions.md"},
+		{ChangesFile, base + ".changes.json"},
+		{PlanFile, planPath},
+	} {
+		if err := a.extract(f.name, f.dest); err != nil {
+			return "", err
+		}
+	}
+	return planPath, nil
+}
+
+// ExtractContext writes the execution context and checkpoint into dir
+// (a worktree's .ralph directory) and returns the paths written.
+// Existing files are left alone.
+func (a *Archive) ExtractContext(dir string) ([]string, error) {
+	var written []string
+	for _, name := range []string{ContextFile, CheckpointFile} {
+		dest := filepath.Join(dir, name)
+		if !a.Has(name) {
+			continue
+		}
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return written, fmt.Errorf("creating %s: %w", dir, err)
+		}
+		if err := a.extract(name, dest); err != nil {
+			return written, err
+		}
+		written = append(written, dest)
+	}
+	return written, nil
+}
+
+// ExtractLog writes the plan log to dest unless a log already exists there.
+// Returns true if the log was written.
+func (a *Archive) ExtractLog(dest string) (bool, error) {
+	if !a.Has(LogFile) {
+		return false, nil
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return false, fmt.Errorf("creating %s: %w", filepath.Dir(dest), err)
+	}
+	if err := a.extract(LogFile, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// extract writes the named entry to dest, if the archive has it.
+func (a *Archive) extract(name, dest string) error {
+	data, ok := a.files[name]
+	if !ok {
+		return nil
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", dest, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// writePlan creates a plan with progress and feedback files in dir.
+func writePlan(t *testing.T, dir string) *plan.Plan {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	planPath := filepath.Join(dir, "alpha.md")
+	os.WriteFile(planPath, []byte("# Plan: Alpha\n- [ ] Task 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "alpha.progress.md"), []byte("# Progress\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "alpha.feedback.md"), []byte("# Feedback\n"), 0644)
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("plan.Load() error = %v", err)
+	}
+	return p
+}
+
+func TestExportRead_RoundTrip(t *testing.T) {
+	src := t.TempDir()
+	p := writePlan(t, filepath.Join(src, "plans", "current"))
+	os.WriteFile(plan.InstructionsPath(p), []byte("Use table-driven tests.\n"), 0644)
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "main.go", Change: plan.ChangeModified}})
+
+	wt := filepath.Join(src, "worktree")
+	runner.SaveContext(&runner.Context{Iteration: 4}, runner.ContextPath(wt))
+	logPath := filepath.Join(src, "alpha.log")
+	os.WriteFile(logPath, []byte("log line\n"), 0644)
+
+	var buf bytes.Buffer
+	manifest, err := Export(&buf, Source{Plan: p, Status: "current", WorktreePath: wt, LogPath: logPath})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	// No checkpoint was written, so it is skipped
+	want := []string{PlanFile, ProgressFile, FeedbackFile, InstructionsFile, ChangesFile, ContextFile, LogFile}
+	if len(manifest.Files) != len(want) {
+		t.Fatalf("manifest.Files = %v, want %v", manifest.Files, want)
+	}
+
+	a, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if a.Manifest.Plan != "alpha" || a.Manifest.Status != "current" || a.Manifest.Version != FormatVersion {
+		t.Errorf("manifest = %+v", a.Manifest)
+	}
+
+	dest := t.TempDir()
+	planPath, err := a.ExtractPlan(filepath.Join(dest, "pending"))
+	if err != nil {
+		t.Fatalf("ExtractPlan() error = %v", err)
+	}
+	for _, path := range []string{planPath, filepath.Join(dest, "pending", "alpha.progress.md"), filepath.Join(dest, "pending", "alpha.feedback.md"), filepath.Join(dest, "pending", "alpha.instructions.md"), filepath.Join(dest, "pending", "alpha.changes.json")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("missing %s: %v", path, err)
+		}
+	}
+	if _, err := a.ExtractPlan(filepath.Join(dest, "pending")); err == nil {
+		t.Error("ExtractPlan() should refuse to overwrite an existing plan")
+	}
+
+	written, err := a.ExtractContext(filepath.Join(dest, "ctx"))
+	if err != nil || len(written) != 1 {
+		t.Fatalf("ExtractContext() = %v, %v", written, err)
+	}
+	ctx, err := runner.LoadContext(filepath.Join(dest, "ctx", ContextFile))
+	if err != nil || ctx.Iteration != 4 {
+		t.Errorf("restored context = %+v, %v", ctx, err)
+	}
+
+	destLog := filepath.Join(dest, "logs", "alpha.log")
+	if ok, err := a.ExtractLog(destLog); !ok || err != nil {
+		t.Errorf("ExtractLog() = %v, %v", ok, err)
+	}
+	if ok, _ := a.ExtractLog(destLog); ok {
+		t.Error("ExtractLog() should keep an existing log")
+	}
+}
+
+func TestExport_MissingPlan(t *testing.T) {
+	p := &plan.Plan{Name: "gone", Path: filepath.Join(t.TempDir(), "gone.md")}
+	if _, err := Export(&bytes.Buffer{}, Source{Plan: p}); err == nil {
+		t.Error("expected error when the plan file is missing")
+	}
+}
+
+// buildArchive writes a tar.gz with the given entries.
+func buildArchive(t *testing.T, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return &buf
+}
+
+func TestRead_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		wantErr error
+	}{
+		{"no manifest", map[string]string{"alpha/plan.md": "# Plan"}, ErrNoManifest},
+		{"no plan", map[string]string{ManifestName: `{"version":1,"plan":"alpha"}`}, ErrNoPlan},
+		{"newer version", map[string]string{ManifestName: `{"version":99,"plan":"alpha"}`}, ErrUnsupportedVersion},
+		{"path in name", map[string]string{ManifestName: `{"version":1,"plan":"../alpha"}`, "../alpha/plan.md": "x"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(buildArchive(t, tt.entries))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRead_IgnoresUnknownEntries(t *testing.T) {
+	a, err := Read(buildArchive(t, map[string]string{
+		ManifestName:      `{"version":1,"plan":"alpha"}`,
+		"alpha/plan.md":   "# Plan: Alpha\n",
+		"alpha/evil.sh":   "rm -rf /",
+		"other/plan.md":   "# Other\n",
+		"alpha/../x.json": "{}",
+	}))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(a.files) != 1 || !a.Has(PlanFile) {
+		t.Errorf("files = %v, want only plan.md", a.files)
+	}
+}
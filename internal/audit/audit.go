@@ -0,0 +1,372 @@
+// Package audit provides the append-only, tamper-evident audit log.
+// Every state-changing operation (queue moves, commits, pull requests, the
+// config in effect) is stored as a JSON line in .ralph/audit.jsonl whose
+// hash covers the previous entry's hash, so editing, removing, or reordering
+// an entry breaks the chain from that point on.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the audit log in the .ralph directory.
+const FileName = "audit.jsonl"
+
+// Actions.
+const (
+	// ActionConfig records the config in effect when a worker or run starts.
+	ActionConfig = "config"
+
+	// ActionQueueMove records a plan moving between queue directories.
+	ActionQueueMove = "queue_move"
+
+	// ActionCommit records a commit made by ralph.
+	ActionCommit = "commit"
+
+	// ActionPROpened records a pull request opened for a plan.
+	ActionPROpened = "pr_opened"
+
+	// ActionMerged records a plan branch merged into the base branch.
+	ActionMerged = "merged"
+)
+
+// genesisHash is the Prev of the first entry.
+const genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// redacted replaces secret config values in config snapshots.
+const redacted = "[redacted]"
+
+// Entry is a single audit log entry.
+type Entry struct {
+	// Seq is the entry's position in the log, starting at 1.
+	Seq int64 `json:"seq"`
+
+	// Time is when the operation happened, in UTC.
+	Time time.Time `json:"time"`
+
+	// Actor is who performed the operation, e.g. "worker" or "cli:alice".
+	Actor string `json:"actor,omitempty"`
+
+	// Action is the operation (see Action* constants).
+	Action string `json:"action"`
+
+	// Plan is the plan name, if the operation is about a plan.
+	Plan string `json:"plan,omitempty"`
+
+	// Details are the operation's specifics, e.g. "from" and "to" for queue moves.
+	Details map[string]string `json:"details,omitempty"`
+
+	// Prev is the previous entry's Hash.
+	Prev string `json:"prev"`
+
+	// Hash is the SHA-256 of the entry without Hash, in hex.
+	Hash string `json:"hash"`
+}
+
+// computeHash returns the hash of e, which covers every field but Hash.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("marshaling audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log appends entries to a hash-chained JSON lines file. Appends take an
+// exclusive lock on the file, so a worker and CLI commands can record to the
+// same log. A nil *Log records nothing.
+type Log struct {
+	path  string
+	actor string
+	mu    sync.Mutex
+}
+
+// Path returns the audit log path for the given .ralph directory.
+func Path(configDir string) string {
+	return filepath.Join(configDir, FileName)
+}
+
+// NewLog creates a Log for the file at path whose entries default to actor.
+func NewLog(path, actor string) *Log {
+	return &Log{path: path, actor: actor}
+}
+
+// Record appends an entry, filling in Seq, Time, Prev, and Hash, and Actor
+// if empty. Does nothing on a nil Log.
+func (l *Log) Record(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	if e.Actor == "" {
+		e.Actor = l.actor
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("creating audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("locking audit log: %w", err)
+	}
+	defer unlockFile(f)
+
+	last, err := lastEntry(f)
+	if err != nil {
+		return err
+	}
+	e.Seq, e.Prev = 1, genesisHash
+	if last != nil {
+		e.Seq, e.Prev = last.Seq+1, last.Hash
+	}
+	if e.Hash, err = e.computeHash(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling audit entry: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit entry: %w", err)
+	}
+	return nil
+}
+
+// QueueMove records a plan moving from one queue directory to another
+// (e.g. "pending" to "current").
+func (l *Log) QueueMove(planName, from, to string) error {
+	return l.Record(Entry{Action: ActionQueueMove, Plan: planName, Details: map[string]string{"from": from, "to": to}})
+}
+
+// Config records the config in effect: its hash and a snapshot with secrets
+// redacted (see Snapshot).
+func (l *Log) Config(cfg *config.Config) error {
+	if l == nil {
+		return nil
+	}
+	snapshot, sum, err := Snapshot(cfg)
+	if err != nil {
+		return err
+	}
+	return l.Record(Entry{Action: ActionConfig, Details: map[string]string{"sha256": sum, "config": snapshot}})
+}
+
+// lastEntry returns the last entry of the log file f, or nil if it is empty.
+// Reads backwards from the end, so appending stays cheap as the log grows.
+func lastEntry(f *os.File) (*Entry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	const chunk = 4096
+	size := info.Size()
+	var tail []byte
+	for offset := size; offset > 0; {
+		n := int64(chunk)
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		tail = append(buf, tail...)
+
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			tail = trimmed[i+1:]
+			break
+		}
+		if offset == 0 {
+			tail = trimmed
+		}
+	}
+	if len(bytes.TrimSpace(tail)) == 0 {
+		return nil, nil
+	}
+
+	var e Entry
+	if err := json.Unmarshal(tail, &e); err != nil {
+		return nil, fmt.Errorf("audit log's last entry is malformed: %w", err)
+	}
+	return &e, nil
+}
+
+// ReadAll returns all entries in the log at path, oldest first. Returns no
+// entries if the log doesn't exist.
+func ReadAll(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return entries, &VerifyError{Line: line, Reason: "malformed entry: " + err.Error()}
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("reading audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// VerifyError reports where the audit log's hash chain is broken.
+type VerifyError struct {
+	// Line is the 1-based line of the first bad entry.
+	Line int
+
+	// Seq is the bad entry's sequence number, if it could be read.
+	Seq int64
+
+	// Reason describes what is wrong.
+	Reason string
+}
+
+func (e *VerifyError) Error() string {
+	if e.Seq > 0 {
+		return fmt.Sprintf("audit log broken at line %d (seq %d): %s", e.Line, e.Seq, e.Reason)
+	}
+	return fmt.Sprintf("audit log broken at line %d: %s", e.Line, e.Reason)
+}
+
+// Verify checks the hash chain of entries: each entry's hash must match its
+// content, and its Seq and Prev must follow the previous entry. Returns the
+// head hash (the last entry's, or "" for no entries), which can be kept
+// elsewhere to later detect entries removed from the end.
+func Verify(entries []Entry) (string, error) {
+	prev, seq := genesisHash, int64(0)
+	for i, e := range entries {
+		fail := func(reason string) error {
+			return &VerifyError{Line: i + 1, Seq: e.Seq, Reason: reason}
+		}
+		if e.Seq != seq+1 {
+			return "", fail(fmt.Sprintf("expected seq %d", seq+1))
+		}
+		if e.Prev != prev {
+			return "", fail("previous hash doesn't match")
+		}
+		sum, err := e.computeHash()
+		if err != nil {
+			return "", err
+		}
+		if sum != e.Hash {
+			return "", fail("hash doesn't match the entry's content")
+		}
+		prev, seq = e.Hash, e.Seq
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	return prev, nil
+}
+
+// VerifyFile reads and verifies the audit log at path. Returns the number of
+// entries and the head hash.
+func VerifyFile(path string) (int, string, error) {
+	entries, err := ReadAll(path)
+	if err != nil {
+		return len(entries), "", err
+	}
+	head, err := Verify(entries)
+	return len(entries), head, err
+}
+
+// Snapshot returns cfg as YAML with secret values (tokens, keys, secrets,
+// passwords) redacted, and the SHA-256 of the unredacted YAML in hex, so a
+// config change is visible in the log even if only a secret changed.
+func Snapshot(cfg *config.Config) (string, string, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", "", fmt.Errorf("marshaling config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return "", "", fmt.Errorf("parsing config: %w", err)
+	}
+	redactSecrets(&node)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return "", "", fmt.Errorf("marshaling config: %w", err)
+	}
+	return out.String(), hex.EncodeToString(sum[:]), nil
+}
+
+// redactSecrets replaces the non-empty values of secret keys in a YAML tree.
+func redactSecrets(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Value != "" && isSecretKey(key.Value) {
+				value.Value = redacted
+				value.Tag = "!!str"
+				continue
+			}
+			redactSecrets(value)
+		}
+		return
+	}
+	for _, child := range node.Content {
+		redactSecrets(child)
+	}
+}
+
+// isSecretKey reports whether a config key holds a secret, e.g. bot_token,
+// api_key, webhook_secret, or webhook_url.
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"token", "secret", "password"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	// Slack webhook URLs carry their credential in the URL
+	return key == "key" || strings.HasSuffix(key, "_key") || strings.HasSuffix(key, "webhook_url")
+}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestLog_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ralph", FileName)
+	l := NewLog(path, "worker")
+
+	if err := l.QueueMove("feature", "pending", "current"); err != nil {
+		t.Fatalf("QueueMove() error = %v", err)
+	}
+	if err := l.Record(Entry{Action: ActionCommit, Plan: "feature", Actor: "run", Details: map[string]string{"to": "abc123"}}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	entries, err := ReadAll(path)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	first, second := entries[0], entries[1]
+	if first.Seq != 1 || first.Prev != genesisHash || first.Actor != "worker" || first.Details["to"] != "current" {
+		t.Errorf("first entry = %+v", first)
+	}
+	if second.Seq != 2 || second.Prev != first.Hash || second.Actor != "run" {
+		t.Errorf("second entry = %+v", second)
+	}
+
+	head, err := Verify(entries)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if head != second.Hash {
+		t.Errorf("head = %s, want %s", head, second.Hash)
+	}
+}
+
+func TestLog_RecordNil(t *testing.T) {
+	var l *Log
+	if err := l.QueueMove("feature", "pending", "current"); err != nil {
+		t.Errorf("nil Log QueueMove() error = %v", err)
+	}
+	if err := l.Config(config.Defaults()); err != nil {
+		t.Errorf("nil Log Config() error = %v", err)
+	}
+}
+
+func TestLog_RecordLongEntries(t *testing.T) {
+	// Entries longer than the read-back chunk still chain
+	path := filepath.Join(t.TempDir(), FileName)
+	l := NewLog(path, "worker")
+	long := strings.Repeat("x", 10000)
+	for i := 0; i < 3; i++ {
+		if err := l.Record(Entry{Action: ActionConfig, Details: map[string]string{"config": long}}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	n, _, err := VerifyFile(path)
+	if err != nil || n != 3 {
+		t.Errorf("VerifyFile() = %d, %v", n, err)
+	}
+}
+
+func TestLog_RecordConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	// Separate Logs stand in for separate processes
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := NewLog(path, "worker")
+			for j := 0; j < 10; j++ {
+				l.QueueMove("feature", "pending", "current")
+			}
+		}()
+	}
+	wg.Wait()
+
+	n, _, err := VerifyFile(path)
+	if err != nil || n != 40 {
+		t.Errorf("VerifyFile() = %d, %v, want 40 entries in an intact chain", n, err)
+	}
+}
+
+func TestVerify_Tampering(t *testing.T) {
+	newEntries := func(t *testing.T) []Entry {
+		path := filepath.Join(t.TempDir(), FileName)
+		l := NewLog(path, "worker")
+		for _, to := range []string{"current", "complete", "pending"} {
+			l.QueueMove("feature", "x", to)
+		}
+		entries, err := ReadAll(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name   string
+		tamper func([]Entry) []Entry
+		line   int
+	}{
+		{"edited", func(e []Entry) []Entry { e[1].Details["to"] = "abandoned"; return e }, 2},
+		{"removed", func(e []Entry) []Entry { return append(e[:1], e[2:]...) }, 2},
+		{"reordered", func(e []Entry) []Entry { e[1], e[2] = e[2], e[1]; return e }, 2},
+		{"rehashed without chaining", func(e []Entry) []Entry {
+			e[0].Actor = "mallory"
+			e[0].Hash, _ = e[0].computeHash()
+			return e
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tt.tamper(newEntries(t)))
+			var verr *VerifyError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Verify() error = %v, want a VerifyError", err)
+			}
+			if verr.Line != tt.line {
+				t.Errorf("broken at line %d, want %d", verr.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestReadAll_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	l := NewLog(path, "worker")
+	l.QueueMove("feature", "pending", "current")
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("not json\n")
+	f.Close()
+
+	if _, _, err := VerifyFile(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("VerifyFile() error = %v, want malformed line 2", err)
+	}
+	if err := l.QueueMove("feature", "current", "complete"); err == nil {
+		t.Error("Record() should refuse to chain onto a malformed entry")
+	}
+}
+
+func TestReadAll_Missing(t *testing.T) {
+	entries, err := ReadAll(filepath.Join(t.TempDir(), FileName))
+	if err != nil || entries != nil {
+		t.Errorf("ReadAll() = %v, %v", entries, err)
+	}
+	if head, err := Verify(nil); head != "" || err != nil {
+		t.Errorf("Verify(nil) = %q, %v", head, err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Slack.BotToken = "xoxb-secret"
+	cfg.Slack.WebhookURL = "https://hooks.slack.com/services/secret"
+	cfg.Linear.APIKey = "lin_secret"
+	cfg.Project.Name = "ralph"
+
+	snapshot, sum, err := Snapshot(cfg)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	for _, secret := range []string{"xoxb-secret", "services/secret", "lin_secret"} {
+		if strings.Contains(snapshot, secret) {
+			t.Errorf("snapshot leaks %q:\n%s", secret, snapshot)
+		}
+	}
+	if !strings.Contains(snapshot, "bot_token: '[redacted]'") || !strings.Contains(snapshot, "name: ralph") {
+		t.Errorf("snapshot = \n%s", snapshot)
+	}
+
+	// A changed secret changes the hash
+	cfg.Slack.BotToken = "xoxb-other"
+	if _, other, _ := Snapshot(cfg); other == sum {
+		t.Error("hash should cover secret values")
+	}
+}
+
+func TestLog_Config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := NewLog(path, "worker").Config(config.Defaults()); err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	entries, _ := ReadAll(path)
+	if len(entries) != 1 || entries[0].Action != ActionConfig || len(entries[0].Details["sha256"]) != 64 || entries[0].Details["config"] == "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,407 @@
+// Package backup captures ralph's state in a tar.gz and restores it, so a
+// workstation migration or a lost disk doesn't lose the queue history and
+// in-flight execution contexts.
+//
+// A backup holds a manifest.json, the plans tree under plans/, the .ralph
+// directory under ralph/ (without worktrees, lock files, or the state
+// database, which is rebuilt from the rest), and the execution context and
+// checkpoint of each worktree under worktrees/<name>/.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/state"
+)
+
+// ManifestName is the name of the manifest entry in a backup.
+const ManifestName = "manifest.json"
+
+// FormatVersion is the backup layout version written by Create.
+const FormatVersion = 1
+
+// Entry prefixes for the parts of a backup.
+const (
+	plansPrefix     = "plans/"
+	configPrefix    = "ralph/"
+	worktreesPrefix = "worktrees/"
+)
+
+var (
+	// ErrNoManifest is returned when a backup has no manifest.json.
+	ErrNoManifest = errors.New("backup has no manifest")
+
+	// ErrUnsupportedVersion is returned for backups written by a newer ralph.
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+)
+
+// Manifest describes a backup.
+type Manifest struct {
+	// Version is the backup layout version.
+	Version int `json:"version"`
+
+	// CreatedAt is when the backup was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Files is the number of files in the backup.
+	Files int `json:"files"`
+
+	// Contexts lists the worktrees whose execution context is included.
+	Contexts []string `json:"contexts,omitempty"`
+}
+
+// Options locates the state to back up or restore.
+type Options struct {
+	// PlansDir is the plans directory with the queue subdirectories.
+	PlansDir string
+
+	// ConfigDir is the .ralph directory.
+	ConfigDir string
+
+	// WorktreesDir is the worktrees directory, left out of the backup
+	// except for each worktree's execution context and checkpoint.
+	WorktreesDir string
+}
+
+// file is a file to back up.
+type file struct {
+	name string
+	path string
+	mode fs.FileMode
+}
+
+// Create writes a backup of the state in opts to w.
+// Directories that don't exist are skipped.
+func Create(w io.Writer, opts Options) (*Manifest, error) {
+	var files []file
+	plansFiles, err := collect(opts.PlansDir, plansPrefix, func(rel string) bool {
+		return path.Base(rel) != plan.LockFileName
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, plansFiles...)
+
+	configFiles, err := collect(opts.ConfigDir, configPrefix, func(rel string) bool {
+		return !excludedConfigPath(rel, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, configFiles...)
+
+	manifest := &Manifest{Version: FormatVersion, CreatedAt: time.Now().UTC()}
+	contexts, err := worktreeContexts(opts.WorktreesDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range contexts {
+		files = append(files, f)
+		name := strings.Split(strings.TrimPrefix(f.name, worktreesPrefix), "/")[0]
+		if len(manifest.Contexts) == 0 || manifest.Contexts[len(manifest.Contexts)-1] != name {
+			manifest.Contexts = append(manifest.Contexts, name)
+		}
+	}
+	manifest.Files = len(files)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, ManifestName, manifestData, 0644, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.path, err)
+		}
+		if err := writeEntry(tw, f.name, data, f.mode, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("closing tar: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("closing gzip: %w", err)
+	}
+	return manifest, nil
+}
+
+// collect lists the regular files under dir for which keep returns true,
+// named prefix + their slash-separated path relative to dir.
+func collect(dir, prefix string, keep func(rel string) bool) ([]file, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	var files []file
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && !keep(rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !keep(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{name: prefix + rel, path: p, mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// excludedConfigPath reports whether a path in the .ralph directory is
+// left out of backups: the worktrees, lock files, and the state database.
+func excludedConfigPath(rel string, opts Options) bool {
+	if opts.WorktreesDir != "" {
+		if wt, err := filepath.Rel(opts.ConfigDir, opts.WorktreesDir); err == nil {
+			wt = filepath.ToSlash(wt) + "/"
+			if strings.HasPrefix(rel, wt) {
+				return true
+			}
+		}
+	}
+	base := path.Base(rel)
+	if base == plan.LockFileName || base == control.LockFileName || base == notify.SpoolLockFileName {
+		return true
+	}
+	return rel == state.FileName || rel == state.FileName+"-wal" || rel == state.FileName+"-shm"
+}
+
+// worktreeContexts lists the execution context and checkpoint of each
+// worktree, sorted by worktree.
+func worktreeContexts(dir string) ([]file, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing %s: %w", dir, err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var files []file
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		wt := filepath.Join(dir, entry.Name())
+		for _, p := range []string{runner.ContextPath(wt), runner.CheckpointPath(wt)} {
+			if _, err := os.Stat(p); err != nil {
+				continue
+			}
+			files = append(files, file{name: worktreesPrefix + entry.Name() + "/" + filepath.Base(p), path: p, mode: 0644})
+		}
+	}
+	return files, nil
+}
+
+// writeEntry adds a regular file to tw.
+func writeEntry(tw *tar.Writer, name string, data []byte, mode fs.FileMode, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// Result reports what Restore did.
+type Result struct {
+	// Manifest describes the backup restored.
+	Manifest Manifest
+
+	// Written lists the files written.
+	Written []string
+
+	// Unchanged is the number of files that already had the backed-up content.
+	Unchanged int
+}
+
+// ConflictError is returned by Restore when files exist with different
+// content and force is false. Nothing is written.
+type ConflictError struct {
+	// Paths are the conflicting files.
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d file(s) already exist with different content, e.g. %s", len(e.Paths), e.Paths[0])
+}
+
+// restoreFile is a file read from a backup.
+type restoreFile struct {
+	dest string
+	data []byte
+	mode fs.FileMode
+}
+
+// Restore extracts a backup into the locations in opts. A worktree's
+// execution context goes back into the worktree if it exists, otherwise
+// into .ralph/imports/<name>/ like an imported plan's. Files that exist
+// with the same content are left alone; if any exist with different
+// content, Restore fails with a *ConflictError before writing anything,
+// unless force is set and they are overwritten.
+func Restore(r io.Reader, opts Options, force bool) (*Result, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gz.Close()
+
+	var manifestData []byte
+	var files []restoreFile
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		if hdr.Name == ManifestName {
+			manifestData = data
+			continue
+		}
+		dest, err := destination(hdr.Name, opts)
+		if err != nil {
+			return nil, err
+		}
+		if dest == "" {
+			continue
+		}
+		mode := fs.FileMode(hdr.Mode).Perm()
+		if mode == 0 {
+			mode = 0644
+		}
+		files = append(files, restoreFile{dest: dest, data: data, mode: mode})
+	}
+
+	if manifestData == nil {
+		return nil, ErrNoManifest
+	}
+	result := &Result{}
+	if err := json.Unmarshal(manifestData, &result.Manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	if result.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: %d (this ralph reads up to %d)", ErrUnsupportedVersion, result.Manifest.Version, FormatVersion)
+	}
+
+	var pending []restoreFile
+	var conflicts []string
+	for _, f := range files {
+		existing, err := os.ReadFile(f.dest)
+		switch {
+		case err == nil && bytes.Equal(existing, f.data):
+			result.Unchanged++
+		case err == nil:
+			conflicts = append(conflicts, f.dest)
+			pending = append(pending, f)
+		case os.IsNotExist(err):
+			pending = append(pending, f)
+		default:
+			return nil, fmt.Errorf("reading %s: %w", f.dest, err)
+		}
+	}
+	if len(conflicts) > 0 && !force {
+		return nil, &ConflictError{Paths: conflicts}
+	}
+
+	for _, f := range pending {
+		if err := os.MkdirAll(filepath.Dir(f.dest), 0755); err != nil {
+			return result, fmt.Errorf("creating %s: %w", filepath.Dir(f.dest), err)
+		}
+		if err := plan.WriteFileAtomic(f.dest, f.data, f.mode); err != nil {
+			return result, fmt.Errorf("writing %s: %w", f.dest, err)
+		}
+		result.Written = append(result.Written, f.dest)
+	}
+	return result, nil
+}
+
+// destination maps a backup entry to where it's restored. Entries outside
+// the backup's parts are ignored (""); entries escaping them are an error.
+func destination(name string, opts Options) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(clean, "/../") {
+		return "", fmt.Errorf("invalid path in backup: %q", name)
+	}
+
+	switch {
+	case strings.HasPrefix(clean, plansPrefix):
+		return filepath.Join(opts.PlansDir, filepath.FromSlash(strings.TrimPrefix(clean, plansPrefix))), nil
+	case strings.HasPrefix(clean, configPrefix):
+		return filepath.Join(opts.ConfigDir, filepath.FromSlash(strings.TrimPrefix(clean, configPrefix))), nil
+	case strings.HasPrefix(clean, worktreesPrefix):
+		parts := strings.Split(strings.TrimPrefix(clean, worktreesPrefix), "/")
+		if len(parts) != 2 || (parts[1] != runner.ContextFilename && parts[1] != runner.CheckpointFilename) {
+			return "", nil
+		}
+		wt := filepath.Join(opts.WorktreesDir, parts[0])
+		if info, err := os.Stat(wt); opts.WorktreesDir != "" && err == nil && info.IsDir() {
+			return filepath.Join(filepath.Dir(runner.ContextPath(wt)), parts[1]), nil
+		}
+		return filepath.Join(opts.ConfigDir, archive.ImportsDir, parts[0], parts[1]), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,197 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/state"
+)
+
+func write(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testState creates a repository's ralph state under dir.
+func testState(t *testing.T, dir string) Options {
+	t.Helper()
+	opts := Options{
+		PlansDir:     filepath.Join(dir, "plans"),
+		ConfigDir:    filepath.Join(dir, ".ralph"),
+		WorktreesDir: filepath.Join(dir, ".ralph", "worktrees"),
+	}
+	write(t, filepath.Join(opts.PlansDir, "pending", "next.md"), "# Plan: next\n", 0644)
+	write(t, filepath.Join(opts.PlansDir, "current", "active.md"), "# Plan: active\n", 0644)
+	write(t, filepath.Join(opts.PlansDir, "current", "active.progress.md"), "# Progress\n", 0644)
+	write(t, filepath.Join(opts.PlansDir, "current", plan.LockFileName), "", 0644)
+	write(t, filepath.Join(opts.PlansDir, "complete", "done.summary.md"), "# Summary\n", 0644)
+	write(t, filepath.Join(opts.ConfigDir, "config.yaml"), "project:\n  name: test\n", 0644)
+	write(t, filepath.Join(opts.ConfigDir, "events.jsonl"), "{}\n", 0644)
+	write(t, filepath.Join(opts.ConfigDir, "worktree-init"), "#!/bin/sh\n", 0755)
+	write(t, filepath.Join(opts.ConfigDir, state.FileName), "db", 0644)
+	write(t, filepath.Join(opts.WorktreesDir, "active", "main.go"), "package main\n", 0644)
+	write(t, runner.ContextPath(filepath.Join(opts.WorktreesDir, "active")), `{"iteration":3}`, 0644)
+	write(t, runner.CheckpointPath(filepath.Join(opts.WorktreesDir, "active")), `{}`, 0644)
+	return opts
+}
+
+// listFiles returns the files under dir, relative and slash-separated.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	opts := testState(t, t.TempDir())
+
+	var buf bytes.Buffer
+	manifest, err := Create(&buf, opts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if manifest.Files != 9 || !reflect.DeepEqual(manifest.Contexts, []string{"active"}) {
+		t.Errorf("manifest = %+v, want 9 files and the active worktree's context", manifest)
+	}
+
+	// Restore on a new machine, without worktrees
+	target := t.TempDir()
+	restoreOpts := Options{
+		PlansDir:     filepath.Join(target, "plans"),
+		ConfigDir:    filepath.Join(target, ".ralph"),
+		WorktreesDir: filepath.Join(target, ".ralph", "worktrees"),
+	}
+	result, err := Restore(bytes.NewReader(buf.Bytes()), restoreOpts, false)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(result.Written) != 9 || result.Unchanged != 0 {
+		t.Errorf("Restore() = %d written, %d unchanged; want 9 written", len(result.Written), result.Unchanged)
+	}
+
+	want := []string{
+		".ralph/config.yaml",
+		".ralph/events.jsonl",
+		".ralph/" + archive.ImportsDir + "/active/" + runner.CheckpointFilename,
+		".ralph/" + archive.ImportsDir + "/active/" + runner.ContextFilename,
+		".ralph/worktree-init",
+		"plans/complete/done.summary.md",
+		"plans/current/active.md",
+		"plans/current/active.progress.md",
+		"plans/pending/next.md",
+	}
+	if got := listFiles(t, target); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored files = %q, want %q", got, want)
+	}
+	if info, err := os.Stat(filepath.Join(restoreOpts.ConfigDir, "worktree-init")); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("worktree-init should stay executable, got %v, %v", info, err)
+	}
+
+	// Restoring again changes nothing
+	result, err = Restore(bytes.NewReader(buf.Bytes()), restoreOpts, false)
+	if err != nil || len(result.Written) != 0 || result.Unchanged != 9 {
+		t.Errorf("second Restore() = %+v, %v; want everything unchanged", result, err)
+	}
+}
+
+func TestRestore_IntoWorktree(t *testing.T) {
+	opts := testState(t, t.TempDir())
+	var buf bytes.Buffer
+	if _, err := Create(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Remove(runner.ContextPath(filepath.Join(opts.WorktreesDir, "active")))
+	if _, err := Restore(bytes.NewReader(buf.Bytes()), opts, false); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	data, err := os.ReadFile(runner.ContextPath(filepath.Join(opts.WorktreesDir, "active")))
+	if err != nil || string(data) != `{"iteration":3}` {
+		t.Errorf("worktree context = %q, %v; want it restored into the worktree", data, err)
+	}
+}
+
+func TestRestore_Conflicts(t *testing.T) {
+	opts := testState(t, t.TempDir())
+	var buf bytes.Buffer
+	if _, err := Create(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(opts.ConfigDir, "config.yaml")
+	os.WriteFile(configPath, []byte("changed\n"), 0644)
+	nextPath := filepath.Join(opts.PlansDir, "pending", "next.md")
+	os.Remove(nextPath)
+
+	_, err := Restore(bytes.NewReader(buf.Bytes()), opts, false)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !reflect.DeepEqual(conflict.Paths, []string{configPath}) {
+		t.Fatalf("Restore() error = %v, want a conflict on config.yaml", err)
+	}
+	if _, err := os.Stat(nextPath); !os.IsNotExist(err) {
+		t.Error("Restore() with conflicts should not write anything")
+	}
+
+	result, err := Restore(bytes.NewReader(buf.Bytes()), opts, true)
+	if err != nil {
+		t.Fatalf("Restore(force) error = %v", err)
+	}
+	if len(result.Written) != 2 {
+		t.Errorf("Restore(force) wrote %q, want config.yaml and next.md", result.Written)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "project:\n  name: test\n" {
+		t.Errorf("config.yaml = %q, want the backed-up content", data)
+	}
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	if _, err := Restore(bytes.NewReader([]byte("not a backup")), Options{}, false); err == nil {
+		t.Error("Restore() should reject a file that isn't a tar.gz")
+	}
+
+	// A per-plan archive has no backup manifest
+	var buf bytes.Buffer
+	dir := t.TempDir()
+	write(t, filepath.Join(dir, "p.md"), "# Plan\n", 0644)
+	p, _ := plan.Load(filepath.Join(dir, "p.md"))
+	archive.Export(&buf, archive.Source{Plan: p})
+	if _, err := Restore(&buf, Options{PlansDir: dir}, false); err == nil {
+		t.Error("Restore() should reject a plan archive")
+	}
+}
+
+func TestDestination(t *testing.T) {
+	opts := Options{PlansDir: "plans", ConfigDir: ".ralph"}
+	for _, name := range []string{"../etc/passwd", "plans/../../x", "/etc/passwd"} {
+		if _, err := destination(name, opts); err == nil {
+			t.Errorf("destination(%q) should be rejected", name)
+		}
+	}
+	if dest, err := destination("other/file", opts); err != nil || dest != "" {
+		t.Errorf("destination(other/file) = %q, %v; want it ignored", dest, err)
+	}
+	if dest, _ := destination("ralph/prompts/prompt.md", opts); dest != filepath.Join(".ralph", "prompts", "prompt.md") {
+		t.Errorf("destination(ralph/...) = %q", dest)
+	}
+}
@@ -0,0 +1,186 @@
+// Package bench runs benchmark commands and compares Go benchmark results
+// between the base branch and a plan's branch, like benchstat.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/gate"
+)
+
+// minSamples is how many runs of a benchmark each side needs before the
+// comparison requires the samples not to overlap (see Delta.Significant).
+const minSamples = 3
+
+// Results maps a benchmark name to the values measured for each unit,
+// e.g. results["BenchmarkParse"]["ns/op"] = [1200, 1180, 1210].
+type Results map[string]map[string][]float64
+
+// Run runs command in dir and parses the benchmark lines it prints.
+func Run(ctx context.Context, dir, command string) (Results, error) {
+	output, err := gate.Output(ctx, dir, command)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", command, err)
+	}
+	results := Parse(output)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%s printed no benchmark results", command)
+	}
+	return results, nil
+}
+
+// Parse reads Go benchmark lines from output, e.g.
+// "BenchmarkParse-8   1000   1234 ns/op   56 B/op   2 allocs/op".
+// The GOMAXPROCS suffix is dropped so runs on different machines compare.
+func Parse(output string) Results {
+	results := make(Results)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+
+		name := fields[0]
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			if results[name] == nil {
+				results[name] = make(map[string][]float64)
+			}
+			results[name][fields[i+1]] = append(results[name][fields[i+1]], value)
+		}
+	}
+	return results
+}
+
+// Delta is the change of one benchmark unit between two runs.
+type Delta struct {
+	// Name is the benchmark name, e.g. "BenchmarkParse".
+	Name string
+
+	// Unit is the measured unit, e.g. "ns/op".
+	Unit string
+
+	// Old and New are the medians of the base and branch samples.
+	Old, New float64
+
+	// Change is how many percent worse the branch is; negative is better.
+	// For throughput units like "MB/s" higher is better.
+	Change float64
+
+	// Significant is false if both sides have enough samples and their
+	// ranges overlap, so the change may be noise.
+	Significant bool
+}
+
+// String formats the delta, e.g. "BenchmarkParse ns/op: 1200 → 1500 (+25.0%)".
+func (d Delta) String() string {
+	return fmt.Sprintf("%s %s: %.4g → %.4g (%+.1f%%)", d.Name, d.Unit, d.Old, d.New, d.Change)
+}
+
+// Compare returns the deltas of the benchmarks and units measured in both
+// runs, sorted by name and unit.
+func Compare(base, head Results) []Delta {
+	var deltas []Delta
+	for name, units := range head {
+		for unit, values := range units {
+			old := base[name][unit]
+			if len(old) == 0 || len(values) == 0 {
+				continue
+			}
+			d := Delta{Name: name, Unit: unit, Old: median(old), New: median(values), Significant: true}
+			if d.Old != 0 {
+				d.Change = (d.New - d.Old) / d.Old * 100
+			}
+			higherIsBetter := strings.HasSuffix(unit, "/s")
+			if higherIsBetter {
+				d.Change = -d.Change
+			}
+			if len(old) >= minSamples && len(values) >= minSamples {
+				d.Significant = !overlap(old, values)
+			}
+			deltas = append(deltas, d)
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].Name != deltas[j].Name {
+			return deltas[i].Name < deltas[j].Name
+		}
+		return deltas[i].Unit < deltas[j].Unit
+	})
+	return deltas
+}
+
+// Regressions returns the significant deltas more than threshold percent worse.
+func Regressions(deltas []Delta, threshold float64) []Delta {
+	var regressions []Delta
+	for _, d := range deltas {
+		if d.Significant && d.Change > threshold {
+			regressions = append(regressions, d)
+		}
+	}
+	return regressions
+}
+
+// Gate summarizes a comparison as the bench gate: it fails if any benchmark
+// regressed more than threshold percent.
+func Gate(command string, deltas []Delta, threshold float64) gate.Result {
+	regressions := Regressions(deltas, threshold)
+	result := gate.Result{Name: gate.Bench, Command: command, Passed: len(regressions) == 0}
+	if result.Passed {
+		result.Output = fmt.Sprintf("%d benchmark measurements within %g%% of the base branch", len(deltas), threshold)
+		return result
+	}
+	lines := make([]string, len(regressions))
+	for i, d := range regressions {
+		lines[i] = d.String()
+	}
+	result.Output = strings.Join(lines, "\n")
+	return result
+}
+
+// median returns the median of values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// overlap reports whether the ranges of a and b overlap.
+func overlap(a, b []float64) bool {
+	minA, maxA := bounds(a)
+	minB, maxB := bounds(b)
+	return minA <= maxB && minB <= maxA
+}
+
+// bounds returns the smallest and largest of values.
+func bounds(values []float64) (float64, float64) {
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return lo, hi
+}
@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const baseOutput = `goos: linux
+goarch: amd64
+pkg: github.com/acme/app
+BenchmarkParse-8     	    1000	      1000 ns/op	      64 B/op	       2 allocs/op
+BenchmarkParse-8     	    1000	      1010 ns/op	      64 B/op	       2 allocs/op
+BenchmarkParse-8     	    1000	       990 ns/op	      64 B/op	       2 allocs/op
+BenchmarkCopy-8      	     500	      2000 ns/op	  500.00 MB/s
+BenchmarkRemoved-8   	     100	       100 ns/op
+PASS
+ok  	github.com/acme/app	3.2s
+`
+
+func TestParse(t *testing.T) {
+	results := Parse(baseOutput)
+	if len(results) != 3 {
+		t.Fatalf("Parse() = %v, want 3 benchmarks", results)
+	}
+	if got := results["BenchmarkParse"]["ns/op"]; len(got) != 3 || got[1] != 1010 {
+		t.Errorf("BenchmarkParse ns/op = %v", got)
+	}
+	if got := results["BenchmarkParse"]["allocs/op"]; len(got) != 3 || got[0] != 2 {
+		t.Errorf("BenchmarkParse allocs/op = %v", got)
+	}
+	if got := results["BenchmarkCopy"]["MB/s"]; len(got) != 1 || got[0] != 500 {
+		t.Errorf("BenchmarkCopy MB/s = %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	head := Parse(`BenchmarkParse-4 1000 1300 ns/op 64 B/op 2 allocs/op
+BenchmarkParse-4 1000 1320 ns/op 64 B/op 2 allocs/op
+BenchmarkParse-4 1000 1290 ns/op 64 B/op 2 allocs/op
+BenchmarkCopy-4 500 2000 ns/op 400.00 MB/s
+BenchmarkNew-4 100 50 ns/op
+`)
+	deltas := Compare(Parse(baseOutput), head)
+
+	got := make(map[string]Delta)
+	for _, d := range deltas {
+		got[d.Name+" "+d.Unit] = d
+	}
+	if len(deltas) != 5 {
+		t.Fatalf("Compare() = %v, want the 5 measurements in both runs", deltas)
+	}
+	if d := got["BenchmarkParse ns/op"]; d.Old != 1000 || d.New != 1300 || d.Change != 30 || !d.Significant {
+		t.Errorf("BenchmarkParse ns/op = %+v", d)
+	}
+	// Lower throughput is worse
+	if d := got["BenchmarkCopy MB/s"]; d.Change != 20 {
+		t.Errorf("BenchmarkCopy MB/s change = %v, want +20", d.Change)
+	}
+	if d := got["BenchmarkParse B/op"]; d.Change != 0 {
+		t.Errorf("BenchmarkParse B/op change = %v", d.Change)
+	}
+
+	regressions := Regressions(deltas, 25)
+	if len(regressions) != 1 || regressions[0].String() != "BenchmarkParse ns/op: 1000 → 1300 (+30.0%)" {
+		t.Errorf("Regressions() = %v", regressions)
+	}
+}
+
+func TestCompare_OverlappingSamplesAreNoise(t *testing.T) {
+	base := Parse("BenchmarkA 10 100 ns/op\nBenchmarkA 10 200 ns/op\nBenchmarkA 10 110 ns/op\n")
+	head := Parse("BenchmarkA 10 150 ns/op\nBenchmarkA 10 160 ns/op\nBenchmarkA 10 155 ns/op\n")
+
+	deltas := Compare(base, head)
+	if len(deltas) != 1 || deltas[0].Significant {
+		t.Fatalf("Compare() = %+v, want an insignificant change", deltas)
+	}
+	if got := Regressions(deltas, 10); len(got) != 0 {
+		t.Errorf("Regressions() = %v, want none for noise", got)
+	}
+}
+
+func TestGate(t *testing.T) {
+	deltas := []Delta{
+		{Name: "BenchmarkA", Unit: "ns/op", Old: 100, New: 105, Change: 5, Significant: true},
+		{Name: "BenchmarkB", Unit: "ns/op", Old: 100, New: 150, Change: 50, Significant: true},
+	}
+	if r := Gate("make bench", deltas, 10); r.Passed || r.Name != "bench" || !strings.Contains(r.Output, "BenchmarkB") || strings.Contains(r.Output, "BenchmarkA") {
+		t.Errorf("Gate() = %+v, want BenchmarkB regressed", r)
+	}
+	if r := Gate("make bench", deltas, 60); !r.Passed {
+		t.Errorf("Gate() with a 60%% threshold = %+v, want passed", r)
+	}
+}
+
+func TestRun(t *testing.T) {
+	results, err := Run(context.Background(), t.TempDir(), "echo 'BenchmarkX-2 10 42 ns/op'")
+	if err != nil || results["BenchmarkX"]["ns/op"][0] != 42 {
+		t.Errorf("Run() = %v, %v", results, err)
+	}
+	if _, err := Run(context.Background(), t.TempDir(), "echo PASS"); err == nil {
+		t.Error("Run() should fail without benchmark output")
+	}
+	if _, err := Run(context.Background(), t.TempDir(), "exit 1"); err == nil {
+		t.Error("Run() should fail when the command fails")
+	}
+}
@@ -0,0 +1,130 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/worker"
+	"github.com/arvesolland/ralph/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	abandonReason       string
+	abandonDeleteBranch bool
+)
+
+var abandonCmd = &cobra.Command{
+	Use:   "abandon <plan>",
+	Short: "Give up on a plan and record why",
+	Long: `Abandon a plan that is no longer wanted, recording the reason.
+
+The plan is moved to plans/abandoned/ with its progress file, the reason is
+appended to the progress file and recorded in .ralph/events.jsonl, and the
+worktree is removed. Use --delete-branch to also delete the feature branch.
+
+Pending plans are abandoned immediately. For the current plan, the request is
+written to .ralph/control.json: a running worker stops the plan after the
+current iteration, otherwise it is abandoned when the worker next starts.
+
+Example:
+  ralph abandon my-feature --reason "superseded by new-auth"
+  ralph abandon my-feature --reason "wrong approach" --delete-branch`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAbandon,
+}
+
+func init() {
+	rootCmd.AddCommand(abandonCmd)
+	abandonCmd.Flags().StringVar(&abandonReason, "reason", "", "why the plan is being abandoned (required)")
+	abandonCmd.Flags().BoolVar(&abandonDeleteBranch, "delete-branch", false, "also delete the plan's feature branch")
+}
+
+func runAbandon(cmd *cobra.Command, args []string) error {
+	reason := strings.TrimSpace(abandonReason)
+	if reason == "" {
+		return fmt.Errorf("--reason is required")
+	}
+
+	queue := plan.NewQueue("plans")
+	queue.Audit = cliAuditLog()
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	configDir := filepath.Dir(GetConfigPath())
+	current, err := queue.Current()
+	if err != nil {
+		return fmt.Errorf("checking current plan: %w", err)
+	}
+
+	if current != nil && current.Name == p.Name {
+		store := control.NewStore(control.Path(configDir))
+		err := store.RequestAbandon(p.Name, control.AbandonRequest{
+			Reason:       reason,
+			By:           "cli",
+			DeleteBranch: abandonDeleteBranch,
+		})
+		if err != nil {
+			return fmt.Errorf("requesting abandon: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Abandon requested for %s; the worker stops it after the current iteration (or when it next starts)\n", p.Name)
+		return nil
+	}
+
+	pending, err := queue.Pending()
+	if err != nil {
+		return fmt.Errorf("listing pending plans: %w", err)
+	}
+	isPending := false
+	for _, candidate := range pending {
+		if candidate.Name == p.Name {
+			isPending = true
+			break
+		}
+	}
+	if !isPending {
+		return fmt.Errorf("plan %s is already complete", p.Name)
+	}
+
+	opts := worker.AbandonOptions{
+		Queue:        queue,
+		Events:       events.NewLog(events.Path(configDir)),
+		Control:      control.NewStore(control.Path(configDir)),
+		Reason:       reason,
+		By:           "cli",
+		DeleteBranch: abandonDeleteBranch,
+	}
+
+	if cfg, err := config.LoadWithDefaults(GetConfigPath()); err == nil {
+		opts.ScratchRoot = worktree.ScratchRoot(cfg, filepath.Dir(configDir))
+	} else {
+		log.Debug("Not removing the scratch directory, failed to load config: %v", err)
+	}
+
+	// Worktree and branch cleanup is best effort outside a git repository
+	g := git.NewGit(".")
+	if _, err := g.RepoRoot(); err == nil {
+		opts.Git = g
+		if manager, err := worktree.NewManager(g, filepath.Join(configDir, "worktrees")); err == nil {
+			opts.WorktreeManager = manager
+		} else {
+			log.Debug("Worktree manager unavailable: %v", err)
+		}
+	}
+
+	if err := worker.AbandonPlan(p, opts); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Abandoned %s: %s\n", p.Name, reason)
+	return nil
+}
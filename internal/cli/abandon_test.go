@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// setupAbandonTest creates a queue in a temp directory and chdirs into it.
+func setupAbandonTest(t *testing.T) func() {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join("plans", dir), 0755)
+	}
+	return func() { os.Chdir(oldWd) }
+}
+
+func TestRunAbandon_Pending(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	abandonReason = "superseded"
+	defer func() { abandonReason = "" }()
+
+	var out bytes.Buffer
+	abandonCmd.SetOut(&out)
+	defer abandonCmd.SetOut(nil)
+
+	if err := runAbandon(abandonCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runAbandon() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join("plans", "abandoned", "alpha.md")); err != nil {
+		t.Errorf("plan not moved to abandoned/: %v", err)
+	}
+	progress, _ := os.ReadFile(filepath.Join("plans", "abandoned", "alpha.progress.md"))
+	if !strings.Contains(string(progress), "Reason: superseded") {
+		t.Errorf("progress file = %q", progress)
+	}
+
+	ev, _ := events.NewLog(events.Path(".ralph")).Last(events.TypePlanAbandoned)
+	if ev == nil || ev.Plan != "alpha" {
+		t.Errorf("plan_abandoned event = %+v", ev)
+	}
+	if !strings.Contains(out.String(), "Abandoned alpha: superseded") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunAbandon_CurrentRequestsViaControl(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "current", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	abandonReason = "wrong approach"
+	abandonDeleteBranch = true
+	defer func() { abandonReason, abandonDeleteBranch = "", false }()
+
+	var out bytes.Buffer
+	abandonCmd.SetOut(&out)
+	defer abandonCmd.SetOut(nil)
+
+	if err := runAbandon(abandonCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runAbandon() error = %v", err)
+	}
+
+	// The plan stays in current/ until the worker acts on the request
+	if _, err := os.Stat(filepath.Join("plans", "current", "alpha.md")); err != nil {
+		t.Errorf("current plan should remain until the worker stops it: %v", err)
+	}
+	req := control.NewStore(control.Path(".ralph")).AbandonRequest("alpha")
+	if req == nil || req.Reason != "wrong approach" || !req.DeleteBranch {
+		t.Errorf("abandon request = %+v", req)
+	}
+	if !strings.Contains(out.String(), "Abandon requested for alpha") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunAbandon_Errors(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "complete", "done.md"), []byte("# Plan: Done\n"), 0644)
+
+	if err := runAbandon(abandonCmd, []string{"done"}); err == nil || !strings.Contains(err.Error(), "--reason") {
+		t.Errorf("expected --reason error, got %v", err)
+	}
+
+	abandonReason = "no longer needed"
+	defer func() { abandonReason = "" }()
+
+	if err := runAbandon(abandonCmd, []string{"done"}); err == nil || !strings.Contains(err.Error(), "already complete") {
+		t.Errorf("expected complete plan error, got %v", err)
+	}
+	if err := runAbandon(abandonCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for missing plan")
+	}
+}
+
+func TestRunAbandon_RemovesScratch(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("worktree:\n  scratch_dir: scratch\n"), 0644)
+	alpha := filepath.Join("scratch", worktree.ScratchName("alpha"))
+	beta := filepath.Join("scratch", worktree.ScratchName("beta"))
+	os.MkdirAll(filepath.Join(alpha, "build"), 0755)
+	os.MkdirAll(beta, 0755)
+
+	abandonReason = "superseded"
+	defer func() { abandonReason = "" }()
+	abandonCmd.SetOut(&bytes.Buffer{})
+	defer abandonCmd.SetOut(nil)
+
+	if err := runAbandon(abandonCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runAbandon() error = %v", err)
+	}
+	if _, err := os.Stat(alpha); !os.IsNotExist(err) {
+		t.Errorf("alpha's scratch directory should be removed: %v", err)
+	}
+	if _, err := os.Stat(beta); err != nil {
+		t.Errorf("beta's scratch directory should be kept: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/spf13/cobra"
+)
+
+var rejectReason string
+
+var approveCmd = &cobra.Command{
+	Use:   "approve <plan>",
+	Short: "Approve a completed plan's PR or merge",
+	Long: `Approve a plan that is waiting for approval.
+
+With completion.require_approval set, the worker asks for approval once a
+plan is verified complete and waits before opening the pull request or
+merging. The decision is written to .ralph/control.json and the worker
+continues within one poll interval.
+
+Example:
+  ralph approve my-feature`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApprove,
+}
+
+var rejectCmd = &cobra.Command{
+	Use:   "reject <plan>",
+	Short: "Reject a completed plan's PR or merge",
+	Long: `Reject a plan that is waiting for approval.
+
+The worker moves the plan to plans/failed/ without opening a pull request
+or merging, keeping its worktree and branch. Requeue it with: ralph retry
+
+Example:
+  ralph reject my-feature --reason "needs a data migration first"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReject,
+}
+
+func init() {
+	rootCmd.AddCommand(approveCmd)
+	rootCmd.AddCommand(rejectCmd)
+	rejectCmd.Flags().StringVar(&rejectReason, "reason", "", "why the plan is rejected")
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	return decideApproval(cmd, args[0], true, "")
+}
+
+func runReject(cmd *cobra.Command, args []string) error {
+	return decideApproval(cmd, args[0], false, strings.TrimSpace(rejectReason))
+}
+
+// decideApproval records an approval decision for a plan awaiting approval.
+func decideApproval(cmd *cobra.Command, planName string, approved bool, reason string) error {
+	store := control.NewStore(control.Path(filepath.Dir(GetConfigPath())))
+	if err := store.Decide(planName, approved, "cli", reason); err != nil {
+		return err
+	}
+
+	if approved {
+		fmt.Fprintf(cmd.OutOrStdout(), "Approved %s; the worker continues with the PR/merge\n", planName)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "Rejected %s; the worker moves it to failed/\n", planName)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/control"
+)
+
+func TestRunApprove(t *testing.T) {
+	defer setupAbandonTest(t)()
+	store := control.NewStore(control.Path(filepath.Dir(GetConfigPath())))
+
+	if err := runApprove(approveCmd, []string{"alpha"}); err == nil || !strings.Contains(err.Error(), "not awaiting approval") {
+		t.Errorf("runApprove() without a request error = %v", err)
+	}
+
+	store.RequestApproval("alpha")
+	var out bytes.Buffer
+	approveCmd.SetOut(&out)
+	defer approveCmd.SetOut(nil)
+
+	if err := runApprove(approveCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runApprove() error = %v", err)
+	}
+	if a := store.Approval("alpha"); a == nil || a.Decision != control.ApprovalApproved || a.By != "cli" {
+		t.Errorf("Approval() = %+v, want approved", a)
+	}
+	if !strings.Contains(out.String(), "Approved alpha") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunReject(t *testing.T) {
+	defer setupAbandonTest(t)()
+	store := control.NewStore(control.Path(filepath.Dir(GetConfigPath())))
+	store.RequestApproval("alpha")
+
+	rejectReason = "needs a migration"
+	defer func() { rejectReason = "" }()
+	var out bytes.Buffer
+	rejectCmd.SetOut(&out)
+	defer rejectCmd.SetOut(nil)
+
+	if err := runReject(rejectCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runReject() error = %v", err)
+	}
+	if a := store.Approval("alpha"); a == nil || a.Decision != control.ApprovalRejected || a.Reason != "needs a migration" {
+		t.Errorf("Approval() = %+v, want rejected", a)
+	}
+	if !strings.Contains(out.String(), "Rejected alpha") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
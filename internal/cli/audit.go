@@ -0,0 +1,223 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditHead   string
+	auditLast   string
+	auditFormat string
+	auditOutput string
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Verify and export the audit log",
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the integrity of the audit log",
+	Long: `Check the hash chain of .ralph/audit.jsonl.
+
+Each entry's hash covers its content and the previous entry's hash, so an
+edited, removed, or reordered entry breaks the chain from that point on.
+Prints the number of entries and the head hash. Keep the head hash
+somewhere else and pass it with --head later to also detect entries
+removed from the end.
+
+Example:
+  ralph audit verify
+  ralph audit verify --head 3f2a...`,
+	Args: cobra.NoArgs,
+	RunE: runAuditVerify,
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the audit log for a compliance review",
+	Long: `Export the entries of .ralph/audit.jsonl as JSON or CSV.
+
+The JSON export includes whether the hash chain verified and its head hash;
+a broken chain is also reported on stderr.
+
+Example:
+  ralph audit export --format csv --output audit.csv
+  ralph audit export --last 90d`,
+	Args: cobra.NoArgs,
+	RunE: runAuditExport,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	auditCmd.AddCommand(auditExportCmd)
+	auditVerifyCmd.Flags().StringVar(&auditHead, "head", "", "head hash from an earlier verify that must still be in the log")
+	auditExportCmd.Flags().StringVar(&auditLast, "last", "", "only export entries from this period (e.g. 30d, 2w, 12h)")
+	auditExportCmd.Flags().StringVar(&auditFormat, "format", "json", "output format: json or csv")
+	auditExportCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "write the export to a file instead of stdout")
+}
+
+// newAuditLog returns the audit log for actor if audit.enabled is set, or nil.
+func newAuditLog(cfg *config.Config, configDir, actor string) *audit.Log {
+	if cfg == nil || !cfg.Audit.Enabled {
+		return nil
+	}
+	return audit.NewLog(audit.Path(configDir), actor)
+}
+
+// cliAuditLog returns the audit log CLI commands record their queue moves
+// to, or nil if auditing is off.
+func cliAuditLog() *audit.Log {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		log.Warn("Not recording to the audit log, failed to load config: %v", err)
+		return nil
+	}
+	actor := "cli"
+	if user := os.Getenv("USER"); user != "" {
+		actor += ":" + user
+	}
+	return newAuditLog(cfg, filepath.Dir(GetConfigPath()), actor)
+}
+
+func runAuditVerify(cmd *cobra.Command, args []string) error {
+	path := audit.Path(filepath.Dir(GetConfigPath()))
+	entries, err := audit.ReadAll(path)
+	if err != nil {
+		return err
+	}
+	head, err := audit.Verify(entries)
+	if err != nil {
+		return err
+	}
+
+	if auditHead != "" {
+		found := false
+		for _, e := range entries {
+			if e.Hash == auditHead {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("head %s is not in the audit log; entries may have been removed", auditHead)
+		}
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "Audit log is empty")
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Audit log OK: %d entries, head %s\n", len(entries), head)
+	return nil
+}
+
+func runAuditExport(cmd *cobra.Command, args []string) error {
+	if auditFormat != "json" && auditFormat != "csv" {
+		return fmt.Errorf("--format must be 'json' or 'csv', got '%s'", auditFormat)
+	}
+	var since time.Time
+	if auditLast != "" {
+		period, err := parsePeriod(auditLast)
+		if err != nil {
+			return err
+		}
+		since = time.Now().Add(-period)
+	}
+
+	entries, err := audit.ReadAll(audit.Path(filepath.Dir(GetConfigPath())))
+	if err != nil {
+		return err
+	}
+	head, verifyErr := audit.Verify(entries)
+	if verifyErr != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", verifyErr)
+	}
+
+	var selected []audit.Entry
+	for _, e := range entries {
+		if since.IsZero() || e.Time.After(since) {
+			selected = append(selected, e)
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if auditOutput != "" {
+		f, err := os.Create(auditOutput)
+		if err != nil {
+			return fmt.Errorf("creating export file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if auditFormat == "csv" {
+		w := csv.NewWriter(out)
+		w.Write([]string{"seq", "time", "actor", "action", "plan", "details", "hash"})
+		for _, e := range selected {
+			w.Write([]string{strconv.FormatInt(e.Seq, 10), e.Time.Format(time.RFC3339), e.Actor, e.Action, e.Plan, formatDetails(e.Details), e.Hash})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("writing export: %w", err)
+		}
+	} else {
+		export := struct {
+			Verified    bool          `json:"verified"`
+			VerifyError string        `json:"verify_error,omitempty"`
+			Head        string        `json:"head,omitempty"`
+			Entries     []audit.Entry `json:"entries"`
+		}{Verified: verifyErr == nil, Head: head, Entries: selected}
+		if verifyErr != nil {
+			export.VerifyError = verifyErr.Error()
+		}
+		if export.Entries == nil {
+			export.Entries = []audit.Entry{}
+		}
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling export: %w", err)
+		}
+		if _, err := fmt.Fprintln(out, string(data)); err != nil {
+			return fmt.Errorf("writing export: %w", err)
+		}
+	}
+
+	if auditOutput != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Exported %d entries to %s\n", len(selected), auditOutput)
+	}
+	return nil
+}
+
+// formatDetails formats entry details as "key=value" pairs sorted by key.
+// The config snapshot is left out; it is in the JSON export.
+func formatDetails(details map[string]string) string {
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		if k != "config" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + details[k]
+	}
+	return strings.Join(pairs, "; ")
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/audit"
+)
+
+// setupAuditTest creates a queue and an audit log with two queue moves.
+func setupAuditTest(t *testing.T) (string, func()) {
+	t.Helper()
+	cleanup := setupAbandonTest(t)
+
+	path := audit.Path(".ralph")
+	l := audit.NewLog(path, "worker")
+	l.QueueMove("alpha", "pending", "current")
+	l.QueueMove("alpha", "current", "complete")
+	return path, cleanup
+}
+
+func TestRunAuditVerify(t *testing.T) {
+	path, cleanup := setupAuditTest(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	auditVerifyCmd.SetOut(&out)
+	defer auditVerifyCmd.SetOut(nil)
+
+	if err := runAuditVerify(auditVerifyCmd, nil); err != nil {
+		t.Fatalf("runAuditVerify() error = %v", err)
+	}
+	entries, _ := audit.ReadAll(path)
+	head := entries[len(entries)-1].Hash
+	if want := "Audit log OK: 2 entries, head " + head; !strings.Contains(out.String(), want) {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	// An earlier head is still in the log
+	auditHead = entries[0].Hash
+	defer func() { auditHead = "" }()
+	if err := runAuditVerify(auditVerifyCmd, nil); err != nil {
+		t.Errorf("runAuditVerify() with an earlier head error = %v", err)
+	}
+
+	// A head that was dropped from the end is not
+	os.WriteFile(path, []byte(mustLine(t, entries[0])), 0644)
+	auditHead = head
+	if err := runAuditVerify(auditVerifyCmd, nil); err == nil || !strings.Contains(err.Error(), "not in the audit log") {
+		t.Errorf("runAuditVerify() error = %v, want a missing head", err)
+	}
+}
+
+func TestRunAuditVerify_Tampered(t *testing.T) {
+	path, cleanup := setupAuditTest(t)
+	defer cleanup()
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte(`"to":"complete"`), []byte(`"to":"abandoned"`), 1), 0644)
+
+	err := runAuditVerify(auditVerifyCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("runAuditVerify() error = %v, want broken at line 2", err)
+	}
+}
+
+func TestRunAuditVerify_Empty(t *testing.T) {
+	defer setupAbandonTest(t)()
+
+	var out bytes.Buffer
+	auditVerifyCmd.SetOut(&out)
+	defer auditVerifyCmd.SetOut(nil)
+
+	if err := runAuditVerify(auditVerifyCmd, nil); err != nil {
+		t.Fatalf("runAuditVerify() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Audit log is empty") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestRunAuditExport_JSON(t *testing.T) {
+	_, cleanup := setupAuditTest(t)
+	defer cleanup()
+
+	var out bytes.Buffer
+	auditExportCmd.SetOut(&out)
+	defer auditExportCmd.SetOut(nil)
+
+	if err := runAuditExport(auditExportCmd, nil); err != nil {
+		t.Fatalf("runAuditExport() error = %v", err)
+	}
+	var export struct {
+		Verified bool          `json:"verified"`
+		Head     string        `json:"head"`
+		Entries  []audit.Entry `json:"entries"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &export); err != nil {
+		t.Fatalf("export is not JSON: %v\n%s", err, out.String())
+	}
+	if !export.Verified || len(export.Entries) != 2 || export.Head != export.Entries[1].Hash {
+		t.Errorf("export = %+v", export)
+	}
+}
+
+func TestRunAuditExport_CSV(t *testing.T) {
+	_, cleanup := setupAuditTest(t)
+	defer cleanup()
+
+	auditFormat = "csv"
+	auditOutput = "audit.csv"
+	defer func() { auditFormat, auditOutput = "json", "" }()
+
+	var out bytes.Buffer
+	auditExportCmd.SetOut(&out)
+	defer auditExportCmd.SetOut(nil)
+
+	if err := runAuditExport(auditExportCmd, nil); err != nil {
+		t.Fatalf("runAuditExport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Exported 2 entries to audit.csv") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	f, err := os.Open("audit.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "seq" {
+		t.Fatalf("records = %v", records)
+	}
+	if got := records[2]; got[0] != "2" || got[2] != "worker" || got[3] != "queue_move" || got[4] != "alpha" || got[5] != "from=current; to=complete" {
+		t.Errorf("row = %v", got)
+	}
+}
+
+func TestRunAuditExport_InvalidFormat(t *testing.T) {
+	auditFormat = "xml"
+	defer func() { auditFormat = "json" }()
+
+	if err := runAuditExport(auditExportCmd, nil); err == nil {
+		t.Error("runAuditExport() should reject an unknown format")
+	}
+}
+
+func TestRunAbandon_RecordsAudit(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("audit:\n  enabled: true\n"), 0644)
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	abandonReason = "superseded"
+	defer func() { abandonReason = "" }()
+
+	abandonCmd.SetOut(&bytes.Buffer{})
+	defer abandonCmd.SetOut(nil)
+	if err := runAbandon(abandonCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runAbandon() error = %v", err)
+	}
+
+	entries, err := audit.ReadAll(audit.Path(".ralph"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Details["from"] != "pending" || entries[0].Details["to"] != "abandoned" || !strings.HasPrefix(entries[0].Actor, "cli") {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+// mustLine returns e as a JSON line.
+func mustLine(t *testing.T, e audit.Entry) string {
+	t.Helper()
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
@@ -0,0 +1,139 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/backup"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupOutput string
+	restoreForce bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the plans and .ralph state to a tar.gz",
+	Long: `Back up everything needed to carry on elsewhere: the plans tree with every
+queue's plans, progress, feedback, and summaries; the .ralph directory with
+the config, prompts, events, audit log, logs, and Slack thread tracker; and
+the execution context and checkpoint of each worktree.
+
+Worktrees themselves are left out (their branches are in git), as are lock
+files and the state database, which is rebuilt from the rest.
+
+Example:
+  ralph backup
+  ralph backup -o ~/ralph-backup.tar.gz`,
+	Args: cobra.NoArgs,
+	RunE: runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <backup>",
+	Short: "Restore the plans and .ralph state from a backup",
+	Long: `Restore a backup created by 'ralph backup' into plans/ and .ralph/.
+
+Files that already exist with the same content are left alone. If any
+exist with different content, nothing is restored unless --force is given
+to overwrite them. A worktree's execution context is restored into the
+worktree if it exists, otherwise into .ralph/imports/<plan>/.
+
+Stop the worker before restoring.
+
+Example:
+  ralph restore ralph-backup-20240130-1432.tar.gz
+  ralph restore backup.tar.gz --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "backup path (default: ralph-backup-<date>-<time>.tar.gz)")
+	restoreCmd.Flags().BoolVar(&restoreForce, "force", false, "overwrite files that differ from the backup")
+}
+
+// backupOptions returns the backup locations for the current repository.
+func backupOptions() backup.Options {
+	configDir := filepath.Dir(GetConfigPath())
+	return backup.Options{
+		PlansDir:     "plans",
+		ConfigDir:    configDir,
+		WorktreesDir: filepath.Join(configDir, "worktrees"),
+	}
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	opts := backupOptions()
+	output := backupOutput
+	if output == "" {
+		output = "ralph-backup-" + time.Now().Format("20060102-1504") + ".tar.gz"
+	}
+	for _, dir := range []string{opts.PlansDir, opts.ConfigDir} {
+		if within(output, dir) {
+			return fmt.Errorf("write the backup outside %s, it would include itself", dir)
+		}
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("creating backup: %w", err)
+	}
+	manifest, err := backup.Create(f, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("writing backup: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Backed up %d file(s) to %s, with the execution context of %d worktree(s)\n",
+		manifest.Files, output, len(manifest.Contexts))
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("opening backup: %w", err)
+	}
+	defer f.Close()
+
+	result, err := backup.Restore(f, backupOptions(), restoreForce)
+	var conflict *backup.ConflictError
+	if errors.As(err, &conflict) {
+		return fmt.Errorf("%w; use --force to overwrite them", err)
+	}
+	if err != nil {
+		return fmt.Errorf("restoring %s: %w", args[0], err)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, path := range result.Written {
+		fmt.Fprintf(out, "Restored %s\n", path)
+	}
+	fmt.Fprintf(out, "Restored %d file(s) from a backup of %s, %d already up to date\n",
+		len(result.Written), result.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"), result.Unchanged)
+	return nil
+}
+
+// within reports whether path is inside dir.
+func within(path, dir string) bool {
+	absPath, err1 := filepath.Abs(path)
+	absDir, err2 := filepath.Abs(dir)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBackupAndRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	os.MkdirAll(filepath.Join("plans", "pending"), 0755)
+	os.WriteFile(filepath.Join("plans", "pending", "next.md"), []byte("# Plan: next\n"), 0644)
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("project:\n  name: test\n"), 0644)
+
+	backupOutput = filepath.Join(t.TempDir(), "backup.tar.gz")
+	defer func() { backupOutput = "" }()
+
+	var out bytes.Buffer
+	backupCmd.SetOut(&out)
+	defer backupCmd.SetOut(nil)
+	if err := runBackup(backupCmd, nil); err != nil {
+		t.Fatalf("runBackup() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Backed up 2 file(s)") {
+		t.Errorf("unexpected backup output: %q", out.String())
+	}
+
+	// Lose the plan and change the config
+	os.Remove(filepath.Join("plans", "pending", "next.md"))
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("changed\n"), 0644)
+
+	out.Reset()
+	restoreCmd.SetOut(&out)
+	defer restoreCmd.SetOut(nil)
+	err := runRestore(restoreCmd, []string{backupOutput})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("runRestore() error = %v, want a conflict suggesting --force", err)
+	}
+
+	restoreForce = true
+	defer func() { restoreForce = false }()
+	if err := runRestore(restoreCmd, []string{backupOutput}); err != nil {
+		t.Fatalf("runRestore(--force) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Restored 2 file(s)") {
+		t.Errorf("unexpected restore output: %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join("plans", "pending", "next.md")); err != nil {
+		t.Error("runRestore() should restore the plan")
+	}
+}
+
+func TestRunBackup_InsideStateDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	backupOutput = filepath.Join(".ralph", "backup.tar.gz")
+	defer func() { backupOutput = "" }()
+	if err := runBackup(backupCmd, nil); err == nil {
+		t.Error("runBackup() should refuse to write the backup inside .ralph")
+	}
+}
@@ -0,0 +1,80 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var changesJSON bool
+
+var changesCmd = &cobra.Command{
+	Use:   "changes <plan>",
+	Short: "List the files a plan has created, modified, or deleted",
+	Long: `List every file the plan has changed across its iterations.
+
+The ledger (<plan>.changes.json next to the plan) is updated from each
+iteration's git diff. A file created and later modified is listed as
+created; a file created and later deleted is not listed. Ralph's own files
+(the plan, its progress file, and the execution context) are left out.
+
+Example:
+  ralph changes my-feature
+  ralph changes my-feature --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChanges,
+}
+
+func init() {
+	rootCmd.AddCommand(changesCmd)
+	changesCmd.Flags().BoolVar(&changesJSON, "json", false, "print the ledger as JSON")
+}
+
+func runChanges(cmd *cobra.Command, args []string) error {
+	queue := plan.NewQueue("plans")
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	changes, err := plan.LoadChanges(p)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if changesJSON {
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling changes: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if len(changes.Files) == 0 {
+		fmt.Fprintf(out, "No changes recorded for %s\n", p.Name)
+		return nil
+	}
+
+	fmt.Fprintf(out, "%s: %s\n\n", p.Name, changes.Summary())
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, f := range changes.Files {
+		fmt.Fprintf(tw, "  %s\t%s\titerations %s\n", f.Change, f.Path, joinInts(f.Iterations))
+	}
+	return tw.Flush()
+}
+
+// joinInts formats numbers as a comma-separated list.
+func joinInts(nums []int) string {
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestRunChanges(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "complete", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	var out bytes.Buffer
+	changesCmd.SetOut(&out)
+	defer changesCmd.SetOut(nil)
+
+	if err := runChanges(changesCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runChanges() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No changes recorded for alpha") {
+		t.Errorf("output without ledger = %q", out.String())
+	}
+
+	p := &plan.Plan{Path: filepath.Join("plans", "complete", "alpha.md")}
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "src/app.go", Change: plan.ChangeCreated}})
+	plan.RecordChanges(p, 3, []plan.FileChange{{Path: "src/app.go", Change: plan.ChangeModified}, {Path: "README.md", Change: plan.ChangeModified}})
+
+	out.Reset()
+	if err := runChanges(changesCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runChanges() error = %v", err)
+	}
+	got := out.String()
+	if !strings.Contains(got, "alpha: 2 files changed: 1 created, 1 modified") {
+		t.Errorf("missing summary: %q", got)
+	}
+	if !strings.Contains(got, "created   src/app.go  iterations 1, 3") {
+		t.Errorf("missing file line: %q", got)
+	}
+
+	changesJSON = true
+	defer func() { changesJSON = false }()
+	out.Reset()
+	if err := runChanges(changesCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runChanges() --json error = %v", err)
+	}
+	var ledger plan.Changes
+	if err := json.Unmarshal(out.Bytes(), &ledger); err != nil || len(ledger.Files) != 2 {
+		t.Errorf("--json output = %q, %v", out.String(), err)
+	}
+
+	if err := runChanges(changesCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown plan")
+	}
+}
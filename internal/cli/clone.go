@@ -0,0 +1,62 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var clonePlanCmd = &cobra.Command{
+	Use:   "clone-plan <existing> <new-name>",
+	Short: "Copy a plan into pending/ as a starting point for similar work",
+	Long: `Copy an existing plan into plans/pending/ under a new name.
+
+The copy starts fresh: checkboxes are unchecked, the plan status is reset to
+pending, completed task statuses are reset to open, and the progress and
+feedback files are new and empty. The feature branch is derived from the new
+name. The source plan may be in any queue, including complete/ and abandoned/.
+
+Example:
+  ralph clone-plan weekly-report weekly-report-march`,
+	Args: cobra.ExactArgs(2),
+	RunE: runClonePlan,
+}
+
+func init() {
+	rootCmd.AddCommand(clonePlanCmd)
+}
+
+func runClonePlan(cmd *cobra.Command, args []string) error {
+	plansDir := "plans"
+	queue := plan.NewQueue(plansDir)
+
+	src, err := queue.Find(args[0])
+	if errors.Is(err, plan.ErrPlanNotFound) {
+		// Find doesn't look in abandoned/, which is a fine template source
+		abandoned := filepath.Join(plansDir, "abandoned", strings.TrimSuffix(filepath.Base(args[0]), ".md")+".md")
+		if p, loadErr := plan.Load(abandoned); loadErr == nil {
+			src, err = p, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// Plan names are unique across all queues, not just pending/
+	if existing := queuedPlanPath(plansDir, plan.CloneName(args[1])); existing != "" {
+		return fmt.Errorf("plan %s already exists at %s", plan.CloneName(args[1]), existing)
+	}
+
+	p, err := plan.Clone(src, queue.PendingDir(), args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Cloned %s to %s (branch %s)\n", src.Name, p.Path, p.Branch)
+	return nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunClonePlan(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "complete", "alpha.md"), []byte("# Plan: Alpha\n**Status:** complete\n- [x] Task 1\n"), 0644)
+
+	var out bytes.Buffer
+	clonePlanCmd.SetOut(&out)
+	defer clonePlanCmd.SetOut(nil)
+
+	if err := runClonePlan(clonePlanCmd, []string{"alpha", "Alpha Two"}); err != nil {
+		t.Fatalf("runClonePlan() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("plans", "pending", "alpha-two.md"))
+	if err != nil {
+		t.Fatalf("clone not written to pending/: %v", err)
+	}
+	if !strings.Contains(string(data), "**Status:** pending\n- [ ] Task 1") {
+		t.Errorf("clone content = %q", data)
+	}
+	if !strings.Contains(out.String(), "Cloned alpha to") || !strings.Contains(out.String(), "branch feat/alpha-two") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	// The new name must be unused in every queue
+	if err := runClonePlan(clonePlanCmd, []string{"alpha", "alpha-two"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("duplicate clone error = %v, want already exists", err)
+	}
+}
+
+func TestRunClonePlan_FromAbandoned(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.MkdirAll(filepath.Join("plans", "abandoned"), 0755)
+	os.WriteFile(filepath.Join("plans", "abandoned", "old.md"), []byte("# Plan: Old\n"), 0644)
+
+	clonePlanCmd.SetOut(&bytes.Buffer{})
+	defer clonePlanCmd.SetOut(nil)
+
+	if err := runClonePlan(clonePlanCmd, []string{"old", "new"}); err != nil {
+		t.Fatalf("runClonePlan() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("plans", "pending", "new.md")); err != nil {
+		t.Errorf("clone not written: %v", err)
+	}
+	if err := runClonePlan(clonePlanCmd, []string{"missing", "other"}); err == nil {
+		t.Error("expected error for unknown source plan")
+	}
+}
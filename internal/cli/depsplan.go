@@ -0,0 +1,75 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arvesolland/ralph/internal/deps"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var depsPlanDryRun bool
+
+var depsPlanCmd = &cobra.Command{
+	Use:   "deps-plan",
+	Short: "Create a pending plan updating outdated dependencies",
+	Long: `Find outdated dependencies and create a pending plan that updates them.
+
+Outdated Go modules come from "go list -u -m all" (direct dependencies only)
+and npm packages from "npm outdated", for whichever of go.mod and
+package.json exist in the working directory. Each major update gets its own
+task, since it may need code changes; the minor and patch updates of an
+ecosystem share one. The plan's Context lists every update with a link to
+its changelog.
+
+Example:
+  ralph deps-plan
+  ralph deps-plan --dry-run   # print the plan instead of queueing it`,
+	Args: cobra.NoArgs,
+	RunE: runDepsPlan,
+}
+
+func init() {
+	rootCmd.AddCommand(depsPlanCmd)
+	depsPlanCmd.Flags().BoolVar(&depsPlanDryRun, "dry-run", false, "print the plan without creating it")
+}
+
+func runDepsPlan(cmd *cobra.Command, args []string) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	updates, err := deps.Find(cmd.Context(), dir)
+	if err != nil {
+		if len(updates) == 0 {
+			return err
+		}
+		log.Warn("Some dependencies weren't checked: %v", err)
+	}
+	return createDepsPlan(cmd, plan.NewQueue("plans"), updates)
+}
+
+// createDepsPlan queues a plan for updates, or prints it with --dry-run.
+func createDepsPlan(cmd *cobra.Command, queue *plan.Queue, updates []deps.Update) error {
+	if len(updates) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "All dependencies are up to date")
+		return nil
+	}
+
+	opts := deps.PlanOptions(deps.GroupUpdates(updates))
+	if depsPlanDryRun {
+		fmt.Fprint(cmd.OutOrStdout(), opts.Body)
+		return nil
+	}
+
+	p, err := createIssuePlan(queue, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s: %d updates in %d tasks\n", p.Path, len(updates), len(opts.Tasks))
+	return nil
+}
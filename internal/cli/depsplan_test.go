@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/deps"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestCreateDepsPlan(t *testing.T) {
+	defer setupAbandonTest(t)()
+
+	var out bytes.Buffer
+	depsPlanCmd.SetOut(&out)
+	defer depsPlanCmd.SetOut(nil)
+
+	updates := []deps.Update{
+		{Ecosystem: deps.Go, Name: "github.com/spf13/cobra", Current: "v1.7.0", Latest: "v1.8.1", Kind: deps.Minor, Changelog: "https://github.com/spf13/cobra/releases"},
+		{Ecosystem: deps.NPM, Name: "react", Current: "17.0.2", Latest: "18.3.1", Kind: deps.Major, Changelog: "https://www.npmjs.com/package/react?activeTab=versions"},
+	}
+	if err := createDepsPlan(depsPlanCmd, plan.NewQueue("plans"), updates); err != nil {
+		t.Fatalf("createDepsPlan() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("plans", "pending", "update-dependencies.md"))
+	if err != nil {
+		t.Fatalf("plan not written to pending/: %v", err)
+	}
+	for _, want := range []string{
+		"**Labels:** dependencies",
+		"### T1: Update Go minor and patch dependencies (1)",
+		"### T2: Update react to 18.3.1 (major)",
+		"([changelog](https://github.com/spf13/cobra/releases))",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("plan missing %q:\n%s", want, data)
+		}
+	}
+	if !strings.Contains(out.String(), "2 updates in 2 tasks") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestCreateDepsPlan_UpToDate(t *testing.T) {
+	defer setupAbandonTest(t)()
+
+	var out bytes.Buffer
+	depsPlanCmd.SetOut(&out)
+	defer depsPlanCmd.SetOut(nil)
+
+	if err := createDepsPlan(depsPlanCmd, plan.NewQueue("plans"), nil); err != nil {
+		t.Fatalf("createDepsPlan() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "up to date") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if entries, _ := os.ReadDir(filepath.Join("plans", "pending")); len(entries) != 0 {
+		t.Errorf("created %d plans, want none", len(entries))
+	}
+}
@@ -0,0 +1,354 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/slack-go/slack"
+	"github.com/spf13/cobra"
+)
+
+// minGitVersion is the oldest git with `git worktree remove`.
+var minGitVersion = [2]int{2, 17}
+
+// Doctor check outcomes.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorResult is the outcome of one environment check.
+type doctorResult struct {
+	Name   string
+	Status string
+	Detail string
+	// Hint tells the user how to fix a warning or failure.
+	Hint string
+}
+
+// Hooks for external tools, replaced in tests.
+var (
+	doctorLookPath = exec.LookPath
+	doctorRun      = func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).CombinedOutput()
+		return strings.TrimSpace(string(out)), err
+	}
+	doctorSlackAuth = func(token string) (string, error) {
+		resp, err := slack.New(token).AuthTest()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s in %s", resp.User, resp.Team), nil
+	}
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the local environment",
+	Long: `Check that everything ralph needs is installed and configured.
+
+Checks:
+- git is installed and recent enough for worktrees
+- claude CLI is installed, in the supported version range, and authenticated
+- gh is installed and authenticated (needed for PR completion mode)
+- Slack bot token is valid (auth.test), if configured
+- plans/ queue directories exist
+- the worktree base directory is writable
+- .ralph/config.yaml is valid
+
+Each failing check prints a hint on how to fix it. Exits non-zero if any
+check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	configFile := GetConfigPath()
+	cfg, cfgResult := checkConfig(configFile)
+
+	results := []doctorResult{
+		checkGit(),
+		checkClaude(cfg),
+		checkGH(cfg),
+		checkSlack(cfg),
+		checkQueueDirs("plans"),
+		checkWorktreeDir(filepath.Join(filepath.Dir(configFile), "worktrees")),
+		cfgResult,
+	}
+
+	failed := printDoctorResults(cmd.OutOrStdout(), results)
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// printDoctorResults prints one line per check, with hints, and returns
+// the number of failed checks.
+func printDoctorResults(w io.Writer, results []doctorResult) int {
+	symbols := map[string]string{doctorPass: "✓", doctorWarn: "!", doctorFail: "✗", doctorSkip: "-"}
+
+	failed := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "%s %s: %s\n", symbols[r.Status], r.Name, r.Detail)
+		if r.Hint != "" && (r.Status == doctorWarn || r.Status == doctorFail) {
+			fmt.Fprintf(w, "    → %s\n", r.Hint)
+		}
+		if r.Status == doctorFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// gitVersionRegex extracts major and minor from "git version 2.43.0".
+var gitVersionRegex = regexp.MustCompile(`git version (\d+)\.(\d+)`)
+
+// checkGit verifies git is installed and supports worktree removal.
+func checkGit() doctorResult {
+	r := doctorResult{Name: "git"}
+
+	out, err := doctorRun("git", "--version")
+	if err != nil {
+		r.Status, r.Detail = doctorFail, "not found"
+		r.Hint = "Install git: https://git-scm.com/downloads"
+		return r
+	}
+
+	m := gitVersionRegex.FindStringSubmatch(out)
+	if m == nil {
+		r.Status, r.Detail = doctorWarn, fmt.Sprintf("could not parse version from %q", out)
+		return r
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major < minGitVersion[0] || (major == minGitVersion[0] && minor < minGitVersion[1]) {
+		r.Status, r.Detail = doctorFail, out
+		r.Hint = fmt.Sprintf("Upgrade git to %d.%d or newer (needed for git worktree remove)", minGitVersion[0], minGitVersion[1])
+		return r
+	}
+
+	r.Status, r.Detail = doctorPass, out
+	return r
+}
+
+// checkClaude verifies the claude CLI is installed, within the configured
+// version range, and has credentials.
+func checkClaude(cfg *config.Config) doctorResult {
+	r := doctorResult{Name: "claude"}
+
+	binary := runner.DefaultClaudeBinary
+	if cfg.Runner.ClaudePath != "" {
+		binary = cfg.Runner.ClaudePath
+	}
+	if _, err := doctorLookPath(binary); err != nil {
+		r.Status, r.Detail = doctorFail, binary+" not found"
+		r.Hint = "Install Claude Code: npm install -g @anthropic-ai/claude-code, or set runner.claude_path"
+		return r
+	}
+
+	version, err := doctorRun(binary, "--version")
+	if err != nil {
+		r.Status, r.Detail = doctorFail, fmt.Sprintf("claude --version failed: %v", err)
+		r.Hint = "Reinstall Claude Code: npm install -g @anthropic-ai/claude-code"
+		return r
+	}
+
+	if v, err := runner.ParseClaudeVersion(version); err != nil {
+		r.Status, r.Detail = doctorWarn, fmt.Sprintf("could not parse version from %q", version)
+		return r
+	} else if err := runner.CheckClaudeVersion(v, cfg.Runner.MinClaudeVersion, cfg.Runner.MaxClaudeVersion); err != nil {
+		r.Status, r.Detail = doctorFail, version
+		r.Hint = err.Error()
+		return r
+	}
+
+	if !claudeAuthenticated() {
+		r.Status, r.Detail = doctorFail, version+" (not authenticated)"
+		r.Hint = "Run `claude` once and log in, or set ANTHROPIC_API_KEY"
+		return r
+	}
+
+	r.Status, r.Detail = doctorPass, version
+	return r
+}
+
+// claudeAuthenticated reports whether an API key or stored login exists.
+func claudeAuthenticated() bool {
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		return true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return false
+	}
+	for _, path := range []string{
+		filepath.Join(home, ".claude", ".credentials.json"),
+		filepath.Join(home, ".claude.json"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGH verifies the GitHub CLI is installed and logged in. It is only
+// required in PR completion mode; in merge mode problems are warnings.
+func checkGH(cfg *config.Config) doctorResult {
+	r := doctorResult{Name: "gh"}
+
+	problem := doctorFail
+	if cfg.Completion.Mode == "merge" {
+		problem = doctorWarn
+	}
+
+	if _, err := doctorLookPath("gh"); err != nil {
+		r.Status, r.Detail = problem, "not found in PATH (needed for PR completion mode)"
+		r.Hint = "Install the GitHub CLI: https://cli.github.com"
+		return r
+	}
+
+	if _, err := doctorRun("gh", "auth", "status"); err != nil {
+		r.Status, r.Detail = problem, "not authenticated"
+		r.Hint = "Run `gh auth login`"
+		return r
+	}
+
+	r.Status, r.Detail = doctorPass, "installed and authenticated"
+	return r
+}
+
+// checkSlack validates the Slack bot token with auth.test, if one is configured.
+// With slack.global_bot, tokens come from the environment or ~/.ralph/slack.env.
+func checkSlack(cfg *config.Config) doctorResult {
+	r := doctorResult{Name: "slack"}
+
+	botToken, appToken := cfg.Slack.BotToken, cfg.Slack.AppToken
+	tokenSource := "slack.bot_token"
+	if cfg.Slack.GlobalBot {
+		global, err := notify.LoadGlobalBotConfig()
+		if err != nil {
+			r.Status, r.Detail = doctorFail, err.Error()
+			r.Hint = "Fix " + filepath.Join(notify.GlobalBotPath, notify.BotConfigFilename)
+			return r
+		}
+		botToken, appToken = global.BotToken, global.AppToken
+		tokenSource = "SLACK_BOT_TOKEN"
+	}
+
+	if botToken == "" {
+		r.Status = doctorSkip
+		if cfg.Slack.WebhookURL != "" {
+			r.Detail = "webhook configured (no bot token to verify)"
+		} else {
+			r.Detail = "not configured"
+		}
+		return r
+	}
+
+	who, err := doctorSlackAuth(botToken)
+	if err != nil {
+		r.Status, r.Detail = doctorFail, fmt.Sprintf("auth.test failed: %v", err)
+		r.Hint = fmt.Sprintf("Check %s and that the app is installed in the workspace", tokenSource)
+		return r
+	}
+
+	if appToken == "" {
+		r.Status, r.Detail = doctorWarn, fmt.Sprintf("bot token valid (%s), no app token", who)
+		r.Hint = "Set slack.app_token (xapp-...) to enable replies and /ralph commands via Socket Mode"
+		return r
+	}
+
+	r.Status, r.Detail = doctorPass, fmt.Sprintf("bot token valid (%s)", who)
+	return r
+}
+
+// checkQueueDirs verifies the plan queue directories exist.
+func checkQueueDirs(plansDir string) doctorResult {
+	r := doctorResult{Name: "queue"}
+
+	var missing []string
+	for _, sub := range []string{"pending", "current", "complete"} {
+		dir := filepath.Join(plansDir, sub)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			missing = append(missing, dir)
+		}
+	}
+	if len(missing) > 0 {
+		r.Status, r.Detail = doctorFail, "missing "+strings.Join(missing, ", ")
+		r.Hint = "Run `ralph init`, or create the directories with mkdir -p"
+		return r
+	}
+
+	r.Status, r.Detail = doctorPass, plansDir+"/{pending,current,complete}"
+	return r
+}
+
+// checkWorktreeDir verifies worktrees can be created under dir. If dir
+// doesn't exist yet, its nearest existing parent must be writable.
+func checkWorktreeDir(dir string) doctorResult {
+	r := doctorResult{Name: "worktrees"}
+
+	target := dir
+	for {
+		if _, err := os.Stat(target); err == nil {
+			break
+		}
+		parent := filepath.Dir(target)
+		if parent == target {
+			break
+		}
+		target = parent
+	}
+
+	f, err := os.CreateTemp(target, ".ralph-doctor-*")
+	if err != nil {
+		r.Status, r.Detail = doctorFail, fmt.Sprintf("%s is not writable", target)
+		r.Hint = fmt.Sprintf("Fix permissions on %s (worktrees are created in %s)", target, dir)
+		return r
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	r.Status, r.Detail = doctorPass, dir+" is writable"
+	return r
+}
+
+// checkConfig loads and validates the config file. It always returns a
+// usable config (defaults if the file is missing or invalid) for other checks.
+func checkConfig(path string) (*config.Config, doctorResult) {
+	r := doctorResult{Name: "config"}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		r.Status, r.Detail = doctorWarn, path+" not found, using defaults"
+		r.Hint = "Run `ralph init` to create it"
+		return config.Defaults(), r
+	}
+
+	cfg, err := config.LoadWithDefaults(path)
+	if err != nil {
+		r.Status, r.Detail = doctorFail, err.Error()
+		r.Hint = "Fix the YAML syntax or invalid setting in " + path
+		return config.Defaults(), r
+	}
+
+	r.Status, r.Detail = doctorPass, path+" is valid"
+	return cfg, r
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// stubDoctor replaces the external tool hooks for the duration of a test.
+func stubDoctor(t *testing.T, run func(name string, args ...string) (string, error), lookPath func(string) (string, error)) {
+	t.Helper()
+	oldRun, oldLook := doctorRun, doctorLookPath
+	t.Cleanup(func() { doctorRun, doctorLookPath = oldRun, oldLook })
+	doctorRun, doctorLookPath = run, lookPath
+}
+
+func TestCheckGit(t *testing.T) {
+	tests := []struct {
+		out    string
+		err    error
+		status string
+	}{
+		{"git version 2.43.0", nil, doctorPass},
+		{"git version 2.16.1", nil, doctorFail},
+		{"", errors.New("not found"), doctorFail},
+		{"something else", nil, doctorWarn},
+	}
+
+	for _, tt := range tests {
+		stubDoctor(t, func(string, ...string) (string, error) { return tt.out, tt.err }, nil)
+		if got := checkGit(); got.Status != tt.status {
+			t.Errorf("checkGit() with %q = %+v, want status %s", tt.out, got, tt.status)
+		}
+	}
+}
+
+func TestCheckGH(t *testing.T) {
+	notFound := func(string) (string, error) { return "", errors.New("not found") }
+	found := func(string) (string, error) { return "/usr/bin/gh", nil }
+	authFails := func(string, ...string) (string, error) { return "", errors.New("exit 1") }
+	authOK := func(string, ...string) (string, error) { return "Logged in", nil }
+
+	cfg := config.Defaults()
+
+	stubDoctor(t, authOK, notFound)
+	if got := checkGH(cfg); got.Status != doctorFail || got.Hint == "" {
+		t.Errorf("missing gh in pr mode = %+v, want fail with hint", got)
+	}
+
+	// Merge mode doesn't need gh
+	cfg.Completion.Mode = "merge"
+	if got := checkGH(cfg); got.Status != doctorWarn {
+		t.Errorf("missing gh in merge mode = %+v, want warn", got)
+	}
+	cfg.Completion.Mode = "pr"
+
+	stubDoctor(t, authFails, found)
+	if got := checkGH(cfg); got.Status != doctorFail || !strings.Contains(got.Hint, "gh auth login") {
+		t.Errorf("unauthenticated gh = %+v", got)
+	}
+
+	stubDoctor(t, authOK, found)
+	if got := checkGH(cfg); got.Status != doctorPass {
+		t.Errorf("authenticated gh = %+v, want pass", got)
+	}
+}
+
+func TestCheckClaude(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	found := func(string) (string, error) { return "/usr/bin/claude", nil }
+	version := func(string, ...string) (string, error) { return "1.0.0 (Claude Code)", nil }
+
+	cfg := config.Defaults()
+
+	stubDoctor(t, version, func(string) (string, error) { return "", errors.New("not found") })
+	if got := checkClaude(cfg); got.Status != doctorFail {
+		t.Errorf("missing claude = %+v, want fail", got)
+	}
+
+	stubDoctor(t, version, found)
+	if got := checkClaude(cfg); got.Status != doctorFail || !strings.Contains(got.Detail, "not authenticated") {
+		t.Errorf("no credentials = %+v, want not authenticated", got)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+	if got := checkClaude(cfg); got.Status != doctorPass {
+		t.Errorf("with API key = %+v, want pass", got)
+	}
+
+	// Pinned below the installed version
+	cfg.Runner.MaxClaudeVersion = "1.0.0"
+	if got := checkClaude(cfg); got.Status != doctorFail || !strings.Contains(got.Hint, "max") {
+		t.Errorf("above max version = %+v, want fail", got)
+	}
+	cfg.Runner.MaxClaudeVersion = ""
+
+	// The configured path is checked instead of claude on PATH
+	cfg.Runner.ClaudePath = "/opt/claude/bin/claude"
+	var looked string
+	stubDoctor(t, version, func(name string) (string, error) { looked = name; return name, nil })
+	checkClaude(cfg)
+	if looked != cfg.Runner.ClaudePath {
+		t.Errorf("looked up %q, want %q", looked, cfg.Runner.ClaudePath)
+	}
+}
+
+func TestCheckSlack(t *testing.T) {
+	oldAuth := doctorSlackAuth
+	defer func() { doctorSlackAuth = oldAuth }()
+
+	cfg := config.Defaults()
+	if got := checkSlack(cfg); got.Status != doctorSkip {
+		t.Errorf("unconfigured = %+v, want skip", got)
+	}
+
+	cfg.Slack.BotToken = "xoxb-bad"
+	doctorSlackAuth = func(string) (string, error) { return "", errors.New("invalid_auth") }
+	if got := checkSlack(cfg); got.Status != doctorFail || !strings.Contains(got.Detail, "invalid_auth") {
+		t.Errorf("invalid token = %+v, want fail", got)
+	}
+
+	doctorSlackAuth = func(string) (string, error) { return "ralph in acme", nil }
+	if got := checkSlack(cfg); got.Status != doctorWarn {
+		t.Errorf("no app token = %+v, want warn", got)
+	}
+
+	cfg.Slack.AppToken = "xapp-good"
+	if got := checkSlack(cfg); got.Status != doctorPass || !strings.Contains(got.Detail, "ralph in acme") {
+		t.Errorf("valid tokens = %+v, want pass", got)
+	}
+}
+
+func TestCheckQueueDirs(t *testing.T) {
+	plansDir := filepath.Join(t.TempDir(), "plans")
+	os.MkdirAll(filepath.Join(plansDir, "pending"), 0755)
+
+	got := checkQueueDirs(plansDir)
+	if got.Status != doctorFail || !strings.Contains(got.Detail, "current") || !strings.Contains(got.Detail, "complete") {
+		t.Errorf("checkQueueDirs() = %+v, want missing current and complete", got)
+	}
+
+	os.MkdirAll(filepath.Join(plansDir, "current"), 0755)
+	os.MkdirAll(filepath.Join(plansDir, "complete"), 0755)
+	if got := checkQueueDirs(plansDir); got.Status != doctorPass {
+		t.Errorf("checkQueueDirs() = %+v, want pass", got)
+	}
+}
+
+func TestCheckWorktreeDir(t *testing.T) {
+	// A missing directory is fine if its parent is writable
+	dir := filepath.Join(t.TempDir(), ".ralph", "worktrees")
+	if got := checkWorktreeDir(dir); got.Status != doctorPass {
+		t.Errorf("checkWorktreeDir() = %+v, want pass", got)
+	}
+
+	if os.Getuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	readOnly := t.TempDir()
+	os.Chmod(readOnly, 0555)
+	defer os.Chmod(readOnly, 0755)
+	if got := checkWorktreeDir(filepath.Join(readOnly, "worktrees")); got.Status != doctorFail {
+		t.Errorf("checkWorktreeDir() = %+v, want fail", got)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if _, got := checkConfig(path); got.Status != doctorWarn {
+		t.Errorf("missing config = %+v, want warn", got)
+	}
+
+	os.WriteFile(path, []byte("completion:\n  mode: squash\n"), 0644)
+	cfg, got := checkConfig(path)
+	if got.Status != doctorFail || cfg == nil {
+		t.Errorf("invalid config = %+v, want fail with default config", got)
+	}
+
+	os.WriteFile(path, []byte("completion:\n  mode: merge\n"), 0644)
+	cfg, got = checkConfig(path)
+	if got.Status != doctorPass || cfg.Completion.Mode != "merge" {
+		t.Errorf("valid config = %+v (mode %q), want pass", got, cfg.Completion.Mode)
+	}
+}
+
+func TestPrintDoctorResults(t *testing.T) {
+	var out bytes.Buffer
+	failed := printDoctorResults(&out, []doctorResult{
+		{Name: "git", Status: doctorPass, Detail: "git version 2.43.0", Hint: "unused"},
+		{Name: "gh", Status: doctorFail, Detail: "not authenticated", Hint: "Run `gh auth login`"},
+		{Name: "slack", Status: doctorSkip, Detail: "not configured"},
+	})
+
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	want := "✓ git: git version 2.43.0\n✗ gh: not authenticated\n    → Run `gh auth login`\n- slack: not configured\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
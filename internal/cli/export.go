@@ -0,0 +1,92 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export <plan>",
+	Short: "Bundle a plan and its state into a portable archive",
+	Long: `Export a plan as a tar.gz archive that can be imported on another machine
+or shared for debugging.
+
+The archive contains the plan file, its progress and feedback files, the
+worktree's execution context and checkpoint (if a worktree exists), and the
+plan's log from .ralph/logs/.
+
+Example:
+  ralph export my-feature
+  ralph export my-feature -o /tmp/my-feature.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "archive path (default: <plan>.tar.gz)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	queue := plan.NewQueue("plans")
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	configDir := filepath.Dir(GetConfigPath())
+	src := archive.Source{
+		Plan:    p,
+		Status:  filepath.Base(filepath.Dir(p.Path)),
+		LogPath: log.PlanLogPath(configDir, p.Name),
+	}
+	if manager := planWorktreeManager(configDir); manager != nil && manager.Exists(p) {
+		src.WorktreePath = manager.Path(p)
+	}
+
+	output := exportOutput
+	if output == "" {
+		output = p.Name + ".tar.gz"
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("creating archive: %w", err)
+	}
+	manifest, err := archive.Export(f, src)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("writing archive: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %s (%s) to %s: %d file(s)\n", p.Name, manifest.Status, output, len(manifest.Files))
+	return nil
+}
+
+// planWorktreeManager returns the worktree manager for .ralph/worktrees,
+// or nil outside a git repository.
+func planWorktreeManager(configDir string) *worktree.WorktreeManager {
+	g := git.NewGit(".")
+	if _, err := g.RepoRoot(); err != nil {
+		return nil
+	}
+	manager, err := worktree.NewManager(g, filepath.Join(configDir, "worktrees"))
+	if err != nil {
+		log.Debug("Worktree manager unavailable: %v", err)
+		return nil
+	}
+	return manager
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+func TestRunExport(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.progress.md"), []byte("# Progress\n"), 0644)
+	logPath := log.PlanLogPath(".ralph", "alpha")
+	os.MkdirAll(filepath.Dir(logPath), 0755)
+	os.WriteFile(logPath, []byte("log\n"), 0644)
+
+	exportOutput = "out.tar.gz"
+	defer func() { exportOutput = "" }()
+
+	var out bytes.Buffer
+	exportCmd.SetOut(&out)
+	defer exportCmd.SetOut(nil)
+
+	if err := runExport(exportCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Exported alpha (pending) to out.tar.gz: 3 file(s)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	f, err := os.Open("out.tar.gz")
+	if err != nil {
+		t.Fatalf("archive not written: %v", err)
+	}
+	defer f.Close()
+	a, err := archive.Read(f)
+	if err != nil {
+		t.Fatalf("archive.Read() error = %v", err)
+	}
+	if !a.Has(archive.PlanFile) || !a.Has(archive.ProgressFile) || !a.Has(archive.LogFile) || a.Has(archive.FeedbackFile) {
+		t.Errorf("manifest files = %v", a.Manifest.Files)
+	}
+}
+
+func TestRunExport_DefaultOutput(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "complete", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	exportCmd.SetOut(&bytes.Buffer{})
+	defer exportCmd.SetOut(nil)
+
+	if err := runExport(exportCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	if _, err := os.Stat("alpha.tar.gz"); err != nil {
+		t.Errorf("default archive not written: %v", err)
+	}
+}
+
+func TestRunExport_NotFound(t *testing.T) {
+	defer setupAbandonTest(t)()
+	if err := runExport(exportCmd, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown plan")
+	}
+}
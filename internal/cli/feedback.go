@@ -0,0 +1,211 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	feedbackSource  string
+	feedbackMessage string
+	feedbackStdin   bool
+	feedbackUrgent  bool
+	feedbackAs      string
+)
+
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback <plan>",
+	Short: "Add feedback to a plan",
+	Long: `Append an entry to the Pending section of a plan's feedback file.
+
+The agent reads pending feedback at the start of each iteration, so this
+lets humans and scripts (CI failure hooks, error trackers) respond to a
+plan without going through Slack.
+
+The plan can be given by name (searched in current/, pending/, complete/)
+or as a path to the plan file.
+
+Entries tagged !urgent (or added with --urgent) are handled first, and a
+notification is sent if they stay unprocessed for more than one iteration.
+
+With --as the entry is attributed to a person, shown by their display name
+from feedback.authors in the config if listed there. Attributed entries are
+also recorded in the events log.
+
+Example:
+  ralph feedback my-feature -m "Use OAuth instead of API keys"
+  make test 2>&1 | ralph feedback my-feature --source ci --stdin
+  ralph feedback my-feature --urgent -m "Production login is broken"
+  ralph feedback my-feature --as alice -m "Keep the v1 endpoint for now"
+  ralph feedback status my-feature`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFeedback,
+}
+
+func init() {
+	rootCmd.AddCommand(feedbackCmd)
+	feedbackCmd.Flags().StringVar(&feedbackSource, "source", "cli", "source label recorded with the entry")
+	feedbackCmd.Flags().StringVarP(&feedbackMessage, "message", "m", "", "feedback text")
+	feedbackCmd.Flags().BoolVar(&feedbackStdin, "stdin", false, "read feedback text from stdin")
+	feedbackCmd.Flags().BoolVar(&feedbackUrgent, "urgent", false, "tag the entry "+plan.UrgentTag)
+	feedbackCmd.Flags().StringVar(&feedbackAs, "as", "", "attribute the entry to this person (see feedback.authors)")
+
+	feedbackCmd.AddCommand(feedbackStatusCmd)
+}
+
+var feedbackStatusCmd = &cobra.Command{
+	Use:   "status <plan>",
+	Short: "List pending and processed feedback for a plan",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runFeedbackStatus,
+}
+
+func runFeedback(cmd *cobra.Command, args []string) error {
+	if feedbackStdin && feedbackMessage != "" {
+		return fmt.Errorf("use either --message or --stdin, not both")
+	}
+	if !feedbackStdin && feedbackMessage == "" {
+		return fmt.Errorf("feedback text required: use --message or --stdin")
+	}
+
+	text := feedbackMessage
+	if feedbackStdin {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+
+	text = formatFeedbackText(text)
+	if text == "" {
+		return fmt.Errorf("feedback text is empty")
+	}
+
+	if feedbackUrgent && !strings.Contains(strings.ToLower(text), plan.UrgentTag) {
+		text = plan.UrgentTag + " " + text
+	}
+
+	queue := plan.NewQueue("plans")
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	author := feedbackAuthor(feedbackAs)
+	if err := plan.AppendFeedback(p, plan.FeedbackSource(feedbackSource, author), text); err != nil {
+		return fmt.Errorf("appending feedback: %w", err)
+	}
+	if author != "" {
+		eventLog := events.NewLog(events.Path(filepath.Dir(GetConfigPath())))
+		if err := eventLog.Append(events.Event{Type: events.TypeFeedback, Plan: p.Name, Author: author, Message: feedbackSource}); err != nil {
+			log.Debug("Failed to record feedback event: %v", err)
+		}
+	}
+
+	log.Success("Feedback added to %s", plan.FeedbackPath(p))
+	return nil
+}
+
+// feedbackAuthor returns the display name for --as: the name in
+// feedback.authors, or the name as given.
+func feedbackAuthor(as string) string {
+	as = strings.TrimSpace(as)
+	if as == "" {
+		return ""
+	}
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		log.Debug("Failed to load config for feedback authors: %v", err)
+		return as
+	}
+	if name := cfg.Feedback.Authors[as]; name != "" {
+		return name
+	}
+	return as
+}
+
+// formatFeedbackText trims the text and indents continuation lines so
+// multi-line input stays within a single Pending entry.
+func formatFeedbackText(text string) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = "  " + strings.TrimRight(lines[i], " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runFeedbackStatus(cmd *cobra.Command, args []string) error {
+	queue := plan.NewQueue("plans")
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	fb, err := plan.LoadFeedback(p)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	fmt.Printf("Feedback: %s\n", p.Name)
+	fmt.Println()
+
+	fmt.Printf("Pending: %d entry(s)", len(fb.Pending))
+	if urgent := len(fb.UrgentPending()); urgent > 0 {
+		fmt.Printf(", %d urgent", urgent)
+	}
+	fmt.Println()
+	for _, e := range fb.Pending {
+		fmt.Printf("  - [%s] (%s) %s\n", e.ID, formatAge(e.Age(now)), firstLine(e.Text))
+	}
+	fmt.Println()
+
+	fmt.Printf("Processed: %d entry(s)\n", len(fb.Processed))
+	for _, e := range fb.Processed {
+		fmt.Printf("  - [%s] (%s) %s\n", e.ID, formatAge(e.Age(now)), firstLine(e.Text))
+		if e.Outcome != "" {
+			fmt.Printf("      Outcome: %s\n", e.Outcome)
+		}
+	}
+
+	return nil
+}
+
+// formatAge formats a duration as a short human-readable age (e.g., "5m ago").
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
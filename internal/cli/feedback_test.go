@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupFeedbackTest creates a plans/ queue with one current plan and chdirs into it.
+func setupFeedbackTest(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	for _, sub := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(tmpDir, "plans", sub), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "plans", "current", "test-plan.md"), []byte("# Plan: Test\n- [ ] Task 1\n"), 0644)
+
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	os.Chdir(tmpDir)
+
+	// Reset flag state between tests
+	feedbackSource = "cli"
+	feedbackMessage = ""
+	feedbackStdin = false
+	feedbackUrgent = false
+	feedbackAs = ""
+
+	return tmpDir
+}
+
+func TestFeedbackCmd_FlagsRegistered(t *testing.T) {
+	cmd := feedbackCmd
+
+	if cmd.Flags().Lookup("source") == nil {
+		t.Error("expected --source flag to be registered")
+	} else if cmd.Flags().Lookup("source").DefValue != "cli" {
+		t.Errorf("expected --source default to be 'cli', got %q", cmd.Flags().Lookup("source").DefValue)
+	}
+
+	msgFlag := cmd.Flags().Lookup("message")
+	if msgFlag == nil {
+		t.Error("expected --message flag to be registered")
+	} else if msgFlag.Shorthand != "m" {
+		t.Errorf("expected --message shorthand to be 'm', got %q", msgFlag.Shorthand)
+	}
+
+	if cmd.Flags().Lookup("stdin") == nil {
+		t.Error("expected --stdin flag to be registered")
+	}
+}
+
+func TestRunFeedback_Message(t *testing.T) {
+	tmpDir := setupFeedbackTest(t)
+	feedbackMessage = "Use OAuth instead"
+
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err != nil {
+		t.Fatalf("runFeedback() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"))
+	if err != nil {
+		t.Fatalf("feedback file not written: %v", err)
+	}
+	if !strings.Contains(string(data), "cli: Use OAuth instead") {
+		t.Errorf("feedback file missing entry, got:\n%s", data)
+	}
+}
+
+func TestRunFeedback_As(t *testing.T) {
+	tmpDir := setupFeedbackTest(t)
+	os.MkdirAll(filepath.Join(tmpDir, ".ralph"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".ralph", "config.yaml"), []byte("feedback:\n  authors:\n    alice: Alice Smith\n"), 0644)
+	feedbackMessage = "Keep the v1 endpoint"
+	feedbackAs = "alice"
+
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err != nil {
+		t.Fatalf("runFeedback() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"))
+	if !strings.Contains(string(data), "cli (by Alice Smith): Keep the v1 endpoint") {
+		t.Errorf("feedback entry not attributed, got:\n%s", data)
+	}
+	evs, _ := os.ReadFile(filepath.Join(tmpDir, ".ralph", "events.jsonl"))
+	if !strings.Contains(string(evs), `"type":"feedback"`) || !strings.Contains(string(evs), `"author":"Alice Smith"`) {
+		t.Errorf("events log missing attributed feedback event, got:\n%s", evs)
+	}
+
+	// Names not in feedback.authors are recorded as given
+	feedbackAs = "bob"
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err != nil {
+		t.Fatalf("runFeedback() error = %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"))
+	if !strings.Contains(string(data), "cli (by bob): Keep the v1 endpoint") {
+		t.Errorf("feedback entry not attributed to bob, got:\n%s", data)
+	}
+}
+
+func TestRunFeedback_Stdin(t *testing.T) {
+	tmpDir := setupFeedbackTest(t)
+	feedbackStdin = true
+	feedbackSource = "ci"
+
+	feedbackCmd.SetIn(strings.NewReader("FAIL: TestLogin\nexpected 200, got 500\n"))
+	defer feedbackCmd.SetIn(nil)
+
+	if err := runFeedback(feedbackCmd, []string{"plans/current/test-plan.md"}); err != nil {
+		t.Fatalf("runFeedback() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"))
+	if !strings.Contains(string(data), "ci: FAIL: TestLogin\n  expected 200, got 500") {
+		t.Errorf("feedback file missing multi-line entry, got:\n%s", data)
+	}
+}
+
+func TestRunFeedback_Validation(t *testing.T) {
+	setupFeedbackTest(t)
+
+	// Neither -m nor --stdin
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err == nil {
+		t.Error("expected error without --message or --stdin")
+	}
+
+	// Both -m and --stdin
+	feedbackMessage = "text"
+	feedbackStdin = true
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err == nil {
+		t.Error("expected error with both --message and --stdin")
+	}
+
+	// Unknown plan
+	feedbackStdin = false
+	err := runFeedback(feedbackCmd, []string{"missing"})
+	if err == nil || !strings.Contains(err.Error(), "plan not found") {
+		t.Errorf("expected plan not found error, got: %v", err)
+	}
+}
+
+func TestFormatFeedbackText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  single line  ", "single line"},
+		{"line one\r\nline two\n", "line one\n  line two"},
+		{"\n\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatFeedbackText(tt.in); got != tt.want {
+			t.Errorf("formatFeedbackText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRunFeedback_Urgent(t *testing.T) {
+	tmpDir := setupFeedbackTest(t)
+	feedbackMessage = "Production login is broken"
+	feedbackUrgent = true
+
+	if err := runFeedback(feedbackCmd, []string{"test-plan"}); err != nil {
+		t.Fatalf("runFeedback() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"))
+	if !strings.Contains(string(data), "cli: !urgent Production login is broken") {
+		t.Errorf("expected urgent tag in entry, got:\n%s", data)
+	}
+}
+
+func TestRunFeedbackStatus(t *testing.T) {
+	tmpDir := setupFeedbackTest(t)
+
+	feedback := `# Feedback: test-plan
+
+## Pending
+- [2024-01-30 14:32] cli: !urgent Production login is broken
+
+## Processed
+- [2024-01-29 09:00] Use OAuth
+  Outcome: Switched auth to OAuth
+`
+	os.WriteFile(filepath.Join(tmpDir, "plans", "current", "test-plan.feedback.md"), []byte(feedback), 0644)
+
+	var buf bytes.Buffer
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runFeedbackStatus(feedbackStatusCmd, []string{"test-plan"})
+
+	w.Close()
+	buf.ReadFrom(r)
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("runFeedbackStatus() error = %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"Pending: 1 entry(s), 1 urgent",
+		"[2024-01-30 14:32]",
+		"Production login is broken",
+		"Processed: 1 entry(s)",
+		"Outcome: Switched auth to OAuth",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{3 * time.Hour, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
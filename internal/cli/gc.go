@@ -0,0 +1,95 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/gc"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcDryRun bool
+	gcDays   int
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove runtime artifacts of long-finished plans",
+	Long: `Remove the runtime artifacts of plans that finished more than
+gc.retention_days ago (default 30): per-plan logs in .ralph/logs/, execution
+context in .ralph/imports/ and in leftover worktrees, and Slack thread
+tracker entries.
+
+The plan files and their progress, feedback, summary, and changes files are
+kept, as are the events log, audit log, lessons, and knowledge base.
+
+Set gc.auto: true to collect garbage every time the worker starts.
+Use --dry-run to see what would be removed without removing anything.
+
+Example:
+  ralph gc --dry-run
+  ralph gc --days 7`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "show what would be removed without removing anything")
+	gcCmd.Flags().IntVar(&gcDays, "days", 0, "remove artifacts of plans finished more than this many days ago (default from config)")
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	configDir := filepath.Dir(GetConfigPath())
+
+	opts := worker.GCOptions(cfg, configDir)
+	opts.PlansDir = "plans"
+	opts.WorktreesDir = filepath.Join(configDir, "worktrees")
+	opts.DryRun = gcDryRun
+	if gcDays > 0 {
+		opts.RetentionDays = gcDays
+	}
+	tracker, err := notify.NewThreadTracker(notify.ThreadTrackerPath(configDir))
+	if err != nil {
+		log.Warn("Not pruning Slack thread entries, failed to load the thread tracker: %v", err)
+	} else {
+		opts.Threads = tracker
+	}
+
+	result, err := gc.Collect(opts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	verb := "Removed"
+	if gcDryRun {
+		verb = "Would remove"
+	}
+	for _, a := range result.Artifacts {
+		if a.Kind == gc.KindThread {
+			fmt.Fprintf(out, "%s: %s Slack thread entry\n", verb, a.Plan)
+			continue
+		}
+		fmt.Fprintf(out, "%s: %s (%s)\n", verb, a.Path, runner.FormatSize(a.Size))
+	}
+
+	reclaimed := "reclaimed"
+	if gcDryRun {
+		reclaimed = "would reclaim"
+	}
+	fmt.Fprintf(out, "%s %d artifact(s) of %d finished plan(s), %s %s\n",
+		verb, len(result.Artifacts), result.Plans, reclaimed, runner.FormatSize(result.Reclaimed))
+	return nil
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+func TestGCCmd_FlagsRegistered(t *testing.T) {
+	for _, name := range []string{"dry-run", "days"} {
+		if gcCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be registered", name)
+		}
+	}
+}
+
+func TestRunGC(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	os.MkdirAll(filepath.Join("plans", "complete"), 0755)
+	planPath := filepath.Join("plans", "complete", "old-plan.md")
+	os.WriteFile(planPath, []byte("# Plan\n"), 0644)
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	os.Chtimes(planPath, old, old)
+	logPath := log.PlanLogPath(".ralph", "old-plan")
+	os.MkdirAll(filepath.Dir(logPath), 0755)
+	os.WriteFile(logPath, []byte("0123456789"), 0644)
+
+	gcDays = 7
+	gcDryRun = true
+	defer func() { gcDays, gcDryRun = 0, false }()
+
+	var out bytes.Buffer
+	gcCmd.SetOut(&out)
+	defer gcCmd.SetOut(nil)
+
+	if err := runGC(gcCmd, nil); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would remove 1 artifact(s) of 1 finished plan(s), would reclaim 10 B") {
+		t.Errorf("unexpected dry run output: %q", out.String())
+	}
+	if _, err := os.Stat(logPath); err != nil {
+		t.Fatal("a dry run should keep the log")
+	}
+
+	gcDryRun = false
+	out.Reset()
+	if err := runGC(gcCmd, nil); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Removed 1 artifact(s) of 1 finished plan(s), reclaimed 10 B") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("runGC() should remove the log")
+	}
+}
@@ -0,0 +1,134 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+// importQueues are the queue directories a plan can be imported into.
+var importQueues = []string{"pending", "current", "complete", "failed", "abandoned"}
+
+var importTo string
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import a plan archive created by ralph export",
+	Long: `Import a plan archive created by 'ralph export'.
+
+The plan, progress, and feedback files are written to plans/<queue>/
+(pending by default). The import is refused if a plan with the same name
+is already queued anywhere, or if --to current and a plan is already current.
+
+The archived execution context and checkpoint are restored into the plan's
+worktree if it exists, otherwise into .ralph/imports/<plan>/ for inspection.
+The plan log is restored to .ralph/logs/<plan>.log unless one exists.
+
+Example:
+  ralph import my-feature.tar.gz
+  ralph import my-feature.tar.gz --to complete`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importTo, "to", "pending", "queue to import into: pending, current, complete, failed, or abandoned")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if !validImportQueue(importTo) {
+		return fmt.Errorf("invalid --to %q: must be one of pending, current, complete, failed, abandoned", importTo)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	a, err := archive.Read(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", args[0], err)
+	}
+	name := a.Manifest.Plan
+
+	plansDir := "plans"
+	if existing := queuedPlanPath(plansDir, name); existing != "" {
+		return fmt.Errorf("plan %s already exists at %s", name, existing)
+	}
+	if importTo == "current" {
+		current, err := plan.NewQueue(plansDir).Current()
+		if err != nil {
+			return fmt.Errorf("checking current plan: %w", err)
+		}
+		if current != nil {
+			return fmt.Errorf("%w (%s); import to pending instead", plan.ErrQueueFull, current.Name)
+		}
+	}
+
+	planPath, err := a.ExtractPlan(filepath.Join(plansDir, importTo))
+	if err != nil {
+		return err
+	}
+	p, err := plan.Load(planPath)
+	if err != nil {
+		return fmt.Errorf("loading imported plan: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Imported %s to %s\n", name, planPath)
+
+	configDir := filepath.Dir(GetConfigPath())
+	contextDir := filepath.Join(configDir, archive.ImportsDir, name)
+	if manager := planWorktreeManager(configDir); manager != nil && manager.Exists(p) {
+		contextDir = filepath.Dir(runner.ContextPath(manager.Path(p)))
+	}
+	written, err := a.ExtractContext(contextDir)
+	if err != nil {
+		return fmt.Errorf("restoring execution context: %w", err)
+	}
+	for _, path := range written {
+		fmt.Fprintf(out, "Restored %s\n", path)
+	}
+
+	logPath := log.PlanLogPath(configDir, name)
+	restored, err := a.ExtractLog(logPath)
+	if err != nil {
+		return fmt.Errorf("restoring plan log: %w", err)
+	}
+	if restored {
+		fmt.Fprintf(out, "Restored %s\n", logPath)
+	} else if a.Has(archive.LogFile) {
+		log.Warn("Kept existing %s; archived log not restored", logPath)
+	}
+	return nil
+}
+
+// validImportQueue reports whether queue is a directory plans can be imported into.
+func validImportQueue(queue string) bool {
+	for _, q := range importQueues {
+		if q == queue {
+			return true
+		}
+	}
+	return false
+}
+
+// queuedPlanPath returns the path of a plan named name in any queue
+// directory under plansDir, or "" if there is none.
+func queuedPlanPath(plansDir, name string) string {
+	for _, queue := range importQueues {
+		path := filepath.Join(plansDir, queue, name+".md")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// writeTestArchive exports a plan named alpha, with an execution context,
+// to a file outside the working directory and returns its path.
+func writeTestArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "alpha.md")
+	os.WriteFile(planPath, []byte("# Plan: Alpha\n- [ ] Task 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "alpha.feedback.md"), []byte("# Feedback\n"), 0644)
+	wt := filepath.Join(dir, "wt")
+	runner.SaveContext(&runner.Context{Iteration: 2}, runner.ContextPath(wt))
+
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("plan.Load() error = %v", err)
+	}
+	path := filepath.Join(dir, "alpha.tar.gz")
+	f, _ := os.Create(path)
+	defer f.Close()
+	if _, err := archive.Export(f, archive.Source{Plan: p, Status: "current", WorktreePath: wt}); err != nil {
+		t.Fatalf("archive.Export() error = %v", err)
+	}
+	return path
+}
+
+func TestRunImport_Pending(t *testing.T) {
+	archivePath := writeTestArchive(t)
+	defer setupAbandonTest(t)()
+
+	var out bytes.Buffer
+	importCmd.SetOut(&out)
+	defer importCmd.SetOut(nil)
+
+	if err := runImport(importCmd, []string{archivePath}); err != nil {
+		t.Fatalf("runImport() error = %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join("plans", "pending", "alpha.md"),
+		filepath.Join("plans", "pending", "alpha.feedback.md"),
+		filepath.Join(".ralph", archive.ImportsDir, "alpha", runner.ContextFilename),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("missing %s: %v", path, err)
+		}
+	}
+	if !strings.Contains(out.String(), "Imported alpha to") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	// A second import of the same plan is refused
+	if err := runImport(importCmd, []string{archivePath}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second import error = %v, want already exists", err)
+	}
+}
+
+func TestRunImport_ToCurrent(t *testing.T) {
+	archivePath := writeTestArchive(t)
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "current", "beta.md"), []byte("# Plan: Beta\n"), 0644)
+
+	importTo = "current"
+	defer func() { importTo = "pending" }()
+	importCmd.SetOut(&bytes.Buffer{})
+	defer importCmd.SetOut(nil)
+
+	err := runImport(importCmd, []string{archivePath})
+	if !errors.Is(err, plan.ErrQueueFull) {
+		t.Errorf("runImport() error = %v, want ErrQueueFull", err)
+	}
+
+	os.Remove(filepath.Join("plans", "current", "beta.md"))
+	if err := runImport(importCmd, []string{archivePath}); err != nil {
+		t.Fatalf("runImport() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("plans", "current", "alpha.md")); err != nil {
+		t.Errorf("plan not imported to current/: %v", err)
+	}
+}
+
+func TestRunImport_InvalidQueue(t *testing.T) {
+	importTo = "elsewhere"
+	defer func() { importTo = "pending" }()
+	if err := runImport(importCmd, []string{"x.tar.gz"}); err == nil || !strings.Contains(err.Error(), "invalid --to") {
+		t.Errorf("runImport() error = %v, want invalid --to", err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import "github.com/spf13/cobra"
+
+var importGitHubCmd = &cobra.Command{
+	Use:   "import-github <issue>",
+	Short: "Create a pending plan from a GitHub issue",
+	Long: `Create a pending plan from a GitHub issue, with its feedback and progress files.
+
+The plan is titled after the issue number and title. The body becomes the
+plan's Context, the issue's labels its **Labels:**, and a **GitHub:**
+header records the number. While the worker processes the plan the issue
+gets the github.in_progress label, then github.in_review with a comment
+linking the pull request, and it is closed once the plan is merged.
+
+Requires github.issues: true and an authenticated gh CLI. Issues come from
+github.repo, or the repository gh infers from the working directory.
+
+Example:
+  ralph import-github 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportGitHub,
+}
+
+func init() {
+	rootCmd.AddCommand(importGitHubCmd)
+}
+
+func runImportGitHub(cmd *cobra.Command, args []string) error {
+	return importIssue(cmd, "github", args[0])
+}
@@ -0,0 +1,91 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+	"github.com/spf13/cobra"
+
+	// Register the trackers
+	_ "github.com/arvesolland/ralph/internal/github"
+	_ "github.com/arvesolland/ralph/internal/jira"
+	_ "github.com/arvesolland/ralph/internal/linear"
+)
+
+// importIssue creates a pending plan from the issue key in the tracker
+// registered as name, refusing issues that already have a plan.
+func importIssue(cmd *cobra.Command, name, rawKey string) error {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	t, err := tracker.New(name, cfg)
+	if err != nil {
+		return err
+	}
+	key, err := t.ParseKey(rawKey)
+	if err != nil {
+		return err
+	}
+
+	queue := plan.NewQueue("plans")
+	if existing := planForIssue(queue, t, key); existing != nil {
+		return fmt.Errorf("%s is already queued as %s", rawKey, existing.Path)
+	}
+
+	issue, err := t.FetchIssue(key)
+	if err != nil {
+		return err
+	}
+	p, err := createIssuePlan(queue, t.PlanOptions(issue))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s from %s: %s\n", p.Path, rawKey, issue.Title)
+	return nil
+}
+
+// createIssuePlan scaffolds a pending plan for an imported issue with its
+// feedback and progress files.
+func createIssuePlan(queue *plan.Queue, opts plan.ScaffoldOptions) (*plan.Plan, error) {
+	if err := os.MkdirAll(queue.PendingDir(), 0755); err != nil {
+		return nil, fmt.Errorf("creating plans/pending: %w", err)
+	}
+	p, err := plan.Scaffold(queue.PendingDir(), opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.CreateFeedbackFile(p); err != nil {
+		return nil, fmt.Errorf("creating feedback file: %w", err)
+	}
+	if err := plan.CreateProgressFile(p); err != nil {
+		return nil, fmt.Errorf("creating progress file: %w", err)
+	}
+	return p, nil
+}
+
+// planForIssue returns the pending, current, or complete plan linked to the
+// tracker's issue key, or nil if there is none.
+func planForIssue(queue *plan.Queue, t tracker.Tracker, key string) *plan.Plan {
+	var plans []*plan.Plan
+	if pending, err := queue.Pending(); err == nil {
+		plans = append(plans, pending...)
+	}
+	if current, err := queue.Current(); err == nil && current != nil {
+		plans = append(plans, current)
+	}
+	if complete, err := queue.Completed(); err == nil {
+		plans = append(plans, complete...)
+	}
+	for _, p := range plans {
+		if t.IssueFor(p) == key {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import "github.com/spf13/cobra"
+
+var importJiraCmd = &cobra.Command{
+	Use:   "import-jira <issue>",
+	Short: "Create a pending plan from a Jira issue",
+	Long: `Create a pending plan from a Jira issue, with its feedback and progress files.
+
+The plan is titled after the issue key and summary, so its branch name
+carries the key and Jira links the branch to the issue. The description
+becomes the plan's Context, the issue's labels its **Labels:**, and a
+**Jira:** header records the key. While the worker processes the plan the
+issue is moved to jira.in_progress, then jira.in_review with a comment
+linking the pull request, and jira.done once it is merged.
+
+Requires jira.url and an API token (jira.api_token or $JIRA_API_TOKEN).
+
+Example:
+  ralph import-jira PROJ-123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportJira,
+}
+
+func init() {
+	rootCmd.AddCommand(importJiraCmd)
+}
+
+func runImportJira(cmd *cobra.Command, args []string) error {
+	return importIssue(cmd, "jira", args[0])
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/jira"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestRunImportJira(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PROJ-9" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `{"key": "PROJ-9", "fields": {"summary": "Add CSV export", "description": "Reports need a CSV download.", "labels": ["reports"]}}`)
+	}))
+	defer server.Close()
+
+	defer setupAbandonTest(t)()
+	t.Setenv(jira.TokenEnv, "tok")
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("jira:\n  url: "+server.URL+"\n"), 0644)
+
+	var out bytes.Buffer
+	importJiraCmd.SetOut(&out)
+	defer importJiraCmd.SetOut(nil)
+
+	if err := runImportJira(importJiraCmd, []string{"proj-9"}); err != nil {
+		t.Fatalf("runImportJira() error = %v", err)
+	}
+
+	p, err := plan.Load(filepath.Join("plans", "pending", "proj-9-add-csv-export.md"))
+	if err != nil {
+		t.Fatalf("plan not created: %v (output %q)", err, out.String())
+	}
+	if p.Jira != "PROJ-9" || len(p.Labels) != 1 || !strings.Contains(p.Content, "Reports need a CSV download.") {
+		t.Errorf("plan = %+v", p)
+	}
+	if _, err := os.Stat(plan.ProgressPath(p)); err != nil {
+		t.Errorf("missing progress file: %v", err)
+	}
+
+	// The same issue isn't imported twice
+	if err := runImportJira(importJiraCmd, []string{"PROJ-9"}); err == nil || !strings.Contains(err.Error(), "already queued") {
+		t.Errorf("second import error = %v, want already queued", err)
+	}
+	if err := runImportJira(importJiraCmd, []string{"not a key"}); err == nil {
+		t.Error("invalid key should be rejected")
+	}
+}
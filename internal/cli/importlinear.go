@@ -0,0 +1,35 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import "github.com/spf13/cobra"
+
+var importLinearCmd = &cobra.Command{
+	Use:   "import-linear <issue-id>",
+	Short: "Create a pending plan from a Linear issue",
+	Long: `Create a pending plan from a Linear issue, with its feedback and progress files.
+
+The plan is titled after the issue identifier and title, so its branch name
+carries the identifier and Linear links the branch to the issue. The
+description becomes the plan's Context, the issue's labels its **Labels:**,
+and a **Linear:** header records the identifier. While the worker processes
+the plan the issue is moved to linear.in_progress, then linear.in_review
+with a comment linking the pull request, and linear.done once it is merged.
+
+To queue issues automatically when they get linear.trigger_label, run
+ralph serve --linear and point a Linear webhook at it.
+
+Requires an API key (linear.api_key or $LINEAR_API_KEY).
+
+Example:
+  ralph import-linear ENG-123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportLinear,
+}
+
+func init() {
+	rootCmd.AddCommand(importLinearCmd)
+}
+
+func runImportLinear(cmd *cobra.Command, args []string) error {
+	return importIssue(cmd, "linear", args[0])
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/linear"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// newFakeLinear serves issue ENG-9 labeled "ralph" over GraphQL.
+func newFakeLinear(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != "ENG-9" {
+			io.WriteString(w, `{"errors": [{"message": "Entity not found: Issue"}]}`)
+			return
+		}
+		io.WriteString(w, `{"data": {"issue": {"id": "uuid-9", "identifier": "ENG-9", "title": "Add CSV export", "description": "Reports need a CSV download.", "url": "https://linear.app/acme/issue/ENG-9", "labels": {"nodes": [{"name": "ralph"}]}}}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunImportLinear(t *testing.T) {
+	server := newFakeLinear(t)
+
+	defer setupAbandonTest(t)()
+	t.Setenv(linear.APIKeyEnv, "lin_key")
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "config.yaml"), []byte("linear:\n  api_url: "+server.URL+"\n"), 0644)
+
+	var out bytes.Buffer
+	importLinearCmd.SetOut(&out)
+	defer importLinearCmd.SetOut(nil)
+
+	if err := runImportLinear(importLinearCmd, []string{"eng-9"}); err != nil {
+		t.Fatalf("runImportLinear() error = %v", err)
+	}
+
+	p, err := plan.Load(filepath.Join("plans", "pending", "eng-9-add-csv-export.md"))
+	if err != nil {
+		t.Fatalf("plan not created: %v (output %q)", err, out.String())
+	}
+	if p.Linear != "ENG-9" || !strings.Contains(p.Content, "Reports need a CSV download.") {
+		t.Errorf("plan = %+v", p)
+	}
+
+	// The same issue isn't imported twice
+	if err := runImportLinear(importLinearCmd, []string{"ENG-9"}); err == nil || !strings.Contains(err.Error(), "already queued") {
+		t.Errorf("second import error = %v, want already queued", err)
+	}
+	if err := runImportLinear(importLinearCmd, []string{"not an id"}); err == nil {
+		t.Error("invalid ID should be rejected")
+	}
+}
+
+func TestNewLinearWebhook(t *testing.T) {
+	server := newFakeLinear(t)
+
+	defer setupAbandonTest(t)()
+	t.Setenv(linear.APIKeyEnv, "lin_key")
+	t.Setenv(linear.WebhookSecretEnv, "")
+
+	cfg := config.Defaults()
+	cfg.Linear.APIURL = server.URL
+	if _, err := newLinearWebhook(cfg, ".ralph"); err == nil || !strings.Contains(err.Error(), "webhook secret") {
+		t.Fatalf("newLinearWebhook() without secret error = %v", err)
+	}
+
+	cfg.Linear.WebhookSecret = "whsec"
+	handler, err := newLinearWebhook(cfg, ".ralph")
+	if err != nil {
+		t.Fatalf("newLinearWebhook() error = %v", err)
+	}
+
+	body := fmt.Sprintf(`{"action": "update", "type": "Issue", "webhookTimestamp": %d, "data": {"identifier": "ENG-9", "labels": [{"name": "ralph"}]}}`, time.Now().UnixMilli())
+	mac := hmac.New(sha256.New, []byte("whsec"))
+	mac.Write([]byte(body))
+	for i := 0; i < 2; i++ { // a redelivery doesn't queue the issue twice
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/linear", strings.NewReader(body))
+		req.Header.Set("Linear-Signature", hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("delivery %d status = %d: %s", i+1, rec.Code, rec.Body.String())
+		}
+	}
+
+	pending, _ := plan.NewQueue("plans").Pending()
+	if len(pending) != 1 || pending[0].Linear != "ENG-9" {
+		t.Errorf("pending = %v, want one plan for ENG-9", pending)
+	}
+	if _, err := os.Stat(filepath.Join(".ralph", "events.jsonl")); err != nil {
+		t.Errorf("plan_queued event not recorded: %v", err)
+	}
+}
@@ -10,26 +10,49 @@ import (
 
 	"github.com/arvesolland/ralph/internal/config"
 	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
-	detectFlag bool
+	detectFlag  bool
+	promptsFlag bool
+	sampleFlag  bool
 )
 
+// samplePlanTitle is the title of the plan created by `ralph init --sample`.
+const samplePlanTitle = "Example plan"
+
+// promptStubs are the .ralph/*.md files injected into prompts, with guidance
+// for filling them in. They are created by `ralph init --prompts`.
+var promptStubs = []struct {
+	name    string
+	content string
+}{
+	{"principles.md", "# Principles\n\n<!-- Development principles the agent should follow, e.g. \"prefer small, tested changes\". -->\n"},
+	{"patterns.md", "# Patterns\n\n<!-- Code patterns and conventions used in this project. -->\n"},
+	{"boundaries.md", "# Boundaries\n\n<!-- Files and directories the agent must never modify. -->\n"},
+	{"tech-stack.md", "# Tech Stack\n\n<!-- Languages, frameworks, and key libraries. -->\n"},
+}
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize a new Ralph project",
 	Long: `Initialize a new Ralph project in the current directory.
 
-Creates the .ralph/ configuration directory, plan queue directories,
-and specs directory structure. Optionally auto-detects project settings.`,
+Creates the .ralph/ configuration directory with a commented config.yaml,
+the plan queue directories, and the specs directory structure.
+
+Optionally auto-detects project settings (--detect), writes prompt
+customization stubs to .ralph/ (--prompts), and adds an example plan
+with its feedback and progress files to plans/pending/ (--sample).`,
 	RunE: runInit,
 }
 
 func init() {
 	initCmd.Flags().BoolVar(&detectFlag, "detect", false, "auto-detect project settings")
+	initCmd.Flags().BoolVar(&promptsFlag, "prompts", false, "create prompt customization stubs in .ralph/")
+	initCmd.Flags().BoolVar(&sampleFlag, "sample", false, "create an example plan in plans/pending/")
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -76,6 +99,15 @@ func runInit(cmd *cobra.Command, args []string) error {
 		log.Debug("Created worktrees .gitignore")
 	}
 
+	// Keep the queue lock files out of git
+	plansGitignore := filepath.Join(cwd, "plans", ".gitignore")
+	if !fileExistsInit(plansGitignore) {
+		if err := os.WriteFile(plansGitignore, []byte(plan.LockFileName+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to create plans .gitignore: %w", err)
+		}
+		log.Debug("Created plans .gitignore")
+	}
+
 	// Build config
 	cfg := config.Defaults()
 
@@ -114,12 +146,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write config file
-	configData, err := yaml.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	if err := os.WriteFile(configPath, configData, 0644); err != nil {
+	if err := os.WriteFile(configPath, config.MarshalCommented(cfg), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 	log.Success("Created config: %s", configPath)
@@ -133,6 +160,18 @@ func runInit(cmd *cobra.Command, args []string) error {
 		log.Success("Created specs index: %s", indexPath)
 	}
 
+	if promptsFlag {
+		if err := createPromptStubs(ralphDir); err != nil {
+			return err
+		}
+	}
+
+	if sampleFlag {
+		if err := createSamplePlan(filepath.Join(cwd, "plans", "pending")); err != nil {
+			return err
+		}
+	}
+
 	// Print summary
 	fmt.Println()
 	log.Success("Ralph initialized successfully!")
@@ -141,8 +180,14 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println("  .ralph/")
 	fmt.Println("    config.yaml      - Project configuration")
 	fmt.Println("    worktrees/       - Execution worktrees (gitignored)")
+	if promptsFlag {
+		fmt.Println("    *.md             - Prompt customization (principles, patterns, boundaries, tech stack)")
+	}
 	fmt.Println("  plans/")
 	fmt.Println("    pending/         - Plans waiting to be executed")
+	if sampleFlag {
+		fmt.Println("      example-plan.md - Example plan (delete it or edit it before running the worker)")
+	}
 	fmt.Println("    current/         - Currently executing plan")
 	fmt.Println("    complete/        - Completed plans")
 	fmt.Println("  specs/")
@@ -156,6 +201,52 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// createPromptStubs writes the prompt customization files that don't exist yet.
+func createPromptStubs(ralphDir string) error {
+	for _, stub := range promptStubs {
+		path := filepath.Join(ralphDir, stub.name)
+		if fileExistsInit(path) {
+			log.Debug("Prompt file exists, skipping: %s", path)
+			continue
+		}
+		if err := os.WriteFile(path, []byte(stub.content), 0644); err != nil {
+			return fmt.Errorf("failed to create %s: %w", stub.name, err)
+		}
+		log.Success("Created prompt stub: %s", path)
+	}
+	return nil
+}
+
+// createSamplePlan adds an example plan with its feedback and progress files
+// to pendingDir, unless one was already created.
+func createSamplePlan(pendingDir string) error {
+	if fileExistsInit(filepath.Join(pendingDir, "example-plan.md")) {
+		log.Debug("Example plan exists, skipping")
+		return nil
+	}
+
+	p, err := plan.Scaffold(pendingDir, plan.ScaffoldOptions{
+		Title: samplePlanTitle,
+		Body:  "An example plan showing the plan format. Replace the tasks with real work, or delete this file.",
+		Tasks: []string{
+			"Add a CONTRIBUTING.md describing how to build and test the project",
+			"Link CONTRIBUTING.md from the README",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create example plan: %w", err)
+	}
+	if err := plan.CreateFeedbackFile(p); err != nil {
+		return fmt.Errorf("failed to create example feedback file: %w", err)
+	}
+	if err := plan.CreateProgressFile(p); err != nil {
+		return fmt.Errorf("failed to create example progress file: %w", err)
+	}
+
+	log.Success("Created example plan: %s", p.Path)
+	return nil
+}
+
 // fileExistsInit checks if a file exists (local to avoid name collision with config package).
 func fileExistsInit(path string) bool {
 	_, err := os.Stat(path)
@@ -3,7 +3,11 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
 )
 
 func TestRunInit_CreatesDirectoryStructure(t *testing.T) {
@@ -59,6 +63,7 @@ func TestRunInit_CreatesDirectoryStructure(t *testing.T) {
 	expectedFiles := []string{
 		".ralph/config.yaml",
 		".ralph/worktrees/.gitignore",
+		"plans/.gitignore",
 		"specs/INDEX.md",
 	}
 
@@ -222,3 +227,79 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRunInit_PromptsAndSample(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	// An existing prompt file is kept
+	os.MkdirAll(".ralph", 0755)
+	os.WriteFile(filepath.Join(".ralph", "principles.md"), []byte("Keep it simple"), 0644)
+
+	detectFlag, promptsFlag, sampleFlag = false, true, true
+	defer func() { promptsFlag, sampleFlag = false, false }()
+
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+
+	for _, stub := range promptStubs {
+		if _, err := os.Stat(filepath.Join(".ralph", stub.name)); err != nil {
+			t.Errorf("prompt stub %s not created: %v", stub.name, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(".ralph", "principles.md")); string(data) != "Keep it simple" {
+		t.Errorf("existing principles.md overwritten: %q", data)
+	}
+
+	for _, file := range []string{"example-plan.md", "example-plan.feedback.md", "example-plan.progress.md"} {
+		if _, err := os.Stat(filepath.Join("plans", "pending", file)); err != nil {
+			t.Errorf("sample bundle file %s not created: %v", file, err)
+		}
+	}
+	p, err := plan.Load(filepath.Join("plans", "pending", "example-plan.md"))
+	if err != nil {
+		t.Fatalf("loading example plan: %v", err)
+	}
+	if !strings.Contains(p.Content, "### T2: Link CONTRIBUTING.md") || len(p.Tasks) == 0 {
+		t.Errorf("unexpected example plan:\n%s", p.Content)
+	}
+
+	// Running again doesn't create a second example plan
+	if err := createSamplePlan(filepath.Join("plans", "pending")); err != nil {
+		t.Fatalf("createSamplePlan() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("plans", "pending", "example-plan-2.md")); !os.IsNotExist(err) {
+		t.Error("createSamplePlan() should not duplicate the example plan")
+	}
+}
+
+func TestRunInit_CommentedConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	detectFlag = false
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(".ralph", "config.yaml"))
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if !strings.Contains(string(data), "# Branch feature branches are created from") {
+		t.Errorf("config should explain each setting:\n%s", data)
+	}
+
+	cfg, err := config.LoadWithDefaults(filepath.Join(".ralph", "config.yaml"))
+	if err != nil {
+		t.Fatalf("generated config does not load: %v", err)
+	}
+	if cfg.Git.BaseBranch != "main" || cfg.Completion.Mode != "pr" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
@@ -0,0 +1,83 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/worker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneDays       int
+	pruneMaxEntries int
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage Slack notification state",
+}
+
+var notifyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Prune stale entries from the Slack thread tracker",
+	Long: `Remove stale entries from .ralph/slack_threads.json.
+
+Entries for plans completed more than --days ago are removed, the total
+number of entries is capped at --max-entries (oldest completed plans go
+first), and each entry's notified blocker list is compacted.
+
+Defaults come from slack.thread_retention_days and slack.max_threads in
+.ralph/config.yaml. The worker also prunes automatically on startup.
+
+Example:
+  ralph notify prune
+  ralph notify prune --days 7 --max-entries 100`,
+	Args: cobra.NoArgs,
+	RunE: runNotifyPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyPruneCmd)
+	notifyPruneCmd.Flags().IntVar(&pruneDays, "days", 0, "remove plans completed more than this many days ago (default from config)")
+	notifyPruneCmd.Flags().IntVar(&pruneMaxEntries, "max-entries", 0, "maximum number of entries to keep (default from config)")
+}
+
+func runNotifyPrune(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	opts := worker.ThreadPruneOptions(cfg)
+	if pruneDays > 0 {
+		opts.MaxAge = time.Duration(pruneDays) * 24 * time.Hour
+	}
+	if pruneMaxEntries > 0 {
+		opts.MaxEntries = pruneMaxEntries
+	}
+
+	tracker, err := notify.NewThreadTracker(notify.ThreadTrackerPath(filepath.Dir(GetConfigPath())))
+	if err != nil {
+		return fmt.Errorf("loading thread tracker: %w", err)
+	}
+
+	result, err := tracker.Prune(opts)
+	if err != nil {
+		return fmt.Errorf("pruning thread tracker: %w", err)
+	}
+
+	for _, name := range result.Removed {
+		log.Info("Removed: %s", name)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %d thread entr(ies), compacted %d blocker list(s)\n",
+		len(result.Removed), result.Compacted)
+	return nil
+}
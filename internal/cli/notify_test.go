@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/notify"
+)
+
+func TestNotifyPruneCmd_FlagsRegistered(t *testing.T) {
+	for _, name := range []string{"days", "max-entries"} {
+		if notifyPruneCmd.Flags().Lookup(name) == nil {
+			t.Errorf("expected --%s flag to be registered", name)
+		}
+	}
+}
+
+func TestRunNotifyPrune(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	trackerPath := filepath.Join(".ralph", notify.ThreadsFilename)
+	tracker, err := notify.NewThreadTracker(trackerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Set("old-plan", &notify.ThreadInfo{ThreadTS: "1", CompletedAt: time.Now().Add(-10 * 24 * time.Hour)})
+	tracker.Set("new-plan", &notify.ThreadInfo{ThreadTS: "2", CompletedAt: time.Now()})
+
+	pruneDays = 7
+	defer func() { pruneDays = 0 }()
+
+	var out bytes.Buffer
+	notifyPruneCmd.SetOut(&out)
+	defer notifyPruneCmd.SetOut(nil)
+
+	if err := runNotifyPrune(notifyPruneCmd, nil); err != nil {
+		t.Fatalf("runNotifyPrune() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), "Removed 1 thread entr(ies)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	reloaded, _ := notify.NewThreadTracker(trackerPath)
+	if reloaded.Get("old-plan") != nil {
+		t.Error("old-plan should be pruned")
+	}
+	if reloaded.Get("new-plan") == nil {
+		t.Error("new-plan should be kept")
+	}
+}
@@ -0,0 +1,47 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Edit plan headers",
+}
+
+var planSetStatusCmd = &cobra.Command{
+	Use:   "set-status <plan> <status>",
+	Short: "Set a plan's **Status:** line",
+	Long: `Set the plan's **Status:** line, adding one under the title if it has none.
+Task status lines and the rest of the file are left alone.
+
+Statuses: ` + strings.Join(plan.Statuses, ", ") + `
+
+Example:
+  ralph plan set-status my-feature blocked`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPlanSetStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planSetStatusCmd)
+}
+
+func runPlanSetStatus(cmd *cobra.Command, args []string) error {
+	p, err := plan.NewQueue("plans").Find(args[0])
+	if err != nil {
+		return err
+	}
+	previous := p.Status
+	if err := plan.SetStatus(p, strings.ToLower(args[1])); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Status of %s: %s → %s\n", p.Name, previous, p.Status)
+	return nil
+}
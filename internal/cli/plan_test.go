@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPlanSetStatus(t *testing.T) {
+	defer setupAbandonTest(t)()
+	path := filepath.Join("plans", "pending", "alpha.md")
+	os.WriteFile(path, []byte("# Plan: Alpha\n**Status:** pending\n"), 0644)
+
+	var out bytes.Buffer
+	planSetStatusCmd.SetOut(&out)
+	defer planSetStatusCmd.SetOut(nil)
+
+	if err := runPlanSetStatus(planSetStatusCmd, []string{"alpha", "Open"}); err != nil {
+		t.Fatalf("runPlanSetStatus() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "# Plan: Alpha\n**Status:** open\n" {
+		t.Errorf("plan = %q", data)
+	}
+	if !strings.Contains(out.String(), "pending → open") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	if err := runPlanSetStatus(planSetStatusCmd, []string{"alpha", "finished"}); err == nil {
+		t.Error("expected an error for an invalid status")
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/linear"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/page"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// configureRedaction masks secrets in all log output, Claude transcripts, and
+// Slack messages: the configured Slack credentials, ingest token, Jira API
+// token, Linear API key and webhook secret, and paging key, MCP server env
+// and header values, values from the env files in worktree.copy_env_files (relative to
+// root), and redact.patterns.
+func configureRedaction(cfg *config.Config, root string) error {
+	secrets := []string{cfg.Slack.WebhookURL, cfg.Slack.BotToken, cfg.Slack.AppToken, cfg.Serve.IngestToken, cfg.Jira.APIToken,
+		linear.APIKey(cfg.Linear), linear.WebhookSecret(cfg.Linear), page.Key(cfg.Blockers.Page)}
+	for _, server := range cfg.Runner.MCPServers {
+		for _, v := range server.Env {
+			secrets = append(secrets, v)
+		}
+		for _, v := range server.Headers {
+			secrets = append(secrets, v)
+		}
+	}
+
+	for _, name := range worktree.ParseEnvFileList(cfg.Worktree.CopyEnvFiles) {
+		values, err := log.EnvFileValues(filepath.Join(root, name))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Warn("Failed to read %s for redaction: %v", name, err)
+			}
+			continue
+		}
+		secrets = append(secrets, values...)
+	}
+
+	redactor, err := log.NewRedactor(secrets, cfg.Redact.Patterns)
+	if err != nil {
+		return err
+	}
+	log.SetRedactor(redactor)
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportLast   string
+	reportFormat string
+	reportOutput string
+	reportLabels []string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report per-plan statistics from the events log",
+	Long: `Aggregate per-plan statistics from .ralph/events.jsonl into a report.
+
+For each plan the report shows iterations used vs max, tokens, wall time,
+verification failures, and blockers, plus totals and the average number
+of iterations needed to complete, and the gotchas from each plan's progress
+file. Use it to tune max_iterations and
+prompt templates based on real runs. With --label, only plans that had all
+the given labels when they started are included.
+
+Example:
+  ralph report
+  ralph report --last 30d
+  ralph report --last 12h --format html --output report.html
+  ralph report --label backend`,
+	Args: cobra.NoArgs,
+	RunE: runReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportLast, "last", "", "only include events from this period (e.g. 30d, 2w, 12h)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "markdown", "output format: markdown or html")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "write the report to a file instead of stdout")
+	reportCmd.Flags().StringSliceVar(&reportLabels, "label", nil, "only include plans with this label (repeatable; all must match)")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if reportFormat != "markdown" && reportFormat != "html" {
+		return fmt.Errorf("--format must be 'markdown' or 'html', got '%s'", reportFormat)
+	}
+
+	now := time.Now()
+	var since time.Time
+	if reportLast != "" {
+		period, err := parsePeriod(reportLast)
+		if err != nil {
+			return err
+		}
+		since = now.Add(-period)
+	}
+
+	// A plan's labels are on its start event, which may predate --last;
+	// Build applies the period either way
+	eventLog := events.NewLog(events.Path(filepath.Dir(GetConfigPath())))
+	from := since
+	if len(reportLabels) > 0 {
+		from = time.Time{}
+	}
+	var evs []events.Event
+	var err error
+	if db := cliStateDB(nil, eventLog); db != nil {
+		defer db.Close()
+		evs, err = db.Events(from)
+	} else {
+		evs, err = eventLog.Since(from)
+	}
+	if err != nil {
+		return fmt.Errorf("reading events log: %w", err)
+	}
+	evs = plan.EventsWithLabels(evs, reportLabels)
+
+	r := report.Build(evs, since, now)
+	addProgressGotchas(r, plan.NewQueue("plans"))
+
+	out := cmd.OutOrStdout()
+	if reportOutput != "" {
+		f, err := os.Create(reportOutput)
+		if err != nil {
+			return fmt.Errorf("creating report file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if reportFormat == "html" {
+		err = r.WriteHTML(out)
+	} else {
+		err = r.WriteMarkdown(out)
+	}
+	if err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	if reportOutput != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "Report written to %s\n", reportOutput)
+	}
+	return nil
+}
+
+// addProgressGotchas adds the gotchas from each reported plan's progress
+// file. Plans no longer in the queue are skipped.
+func addProgressGotchas(r *report.Report, queue *plan.Queue) {
+	for _, s := range r.Plans {
+		p, err := queue.Find(s.Name)
+		if err != nil {
+			continue
+		}
+		if progress, err := plan.LoadProgress(p); err == nil {
+			r.AddGotchas(s.Name, progress.Gotchas())
+		}
+	}
+}
+
+// parsePeriod parses a period such as "30d", "2w", or any Go duration ("12h").
+func parsePeriod(s string) (time.Duration, error) {
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(s) > 1 {
+		if mult, ok := unit[s[len(s)-1]]; ok {
+			n, err := strconv.Atoi(strings.TrimSpace(s[:len(s)-1]))
+			if err == nil && n > 0 {
+				return time.Duration(n) * mult, nil
+			}
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 30d, 2w, 12h)", s)
+	}
+	return d, nil
+}
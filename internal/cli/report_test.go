@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"-3d", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePeriod(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parsePeriod(%q) = %v, %v; want %v, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	eventLog := events.NewLog(events.Path(".ralph"))
+	eventLog.Append(events.Event{Time: time.Now().Add(-60 * 24 * time.Hour), Type: events.TypeIteration, Plan: "old-plan"})
+	eventLog.Append(events.Event{Type: events.TypeIteration, Plan: "new-plan", MaxIterations: 30, Duration: time.Minute})
+	eventLog.Append(events.Event{Type: events.TypePlanCompleted, Plan: "new-plan"})
+
+	os.MkdirAll(filepath.Join("plans", "complete"), 0755)
+	os.WriteFile(filepath.Join("plans", "complete", "new-plan.md"), []byte("# Plan: New\n"), 0644)
+	os.WriteFile(filepath.Join("plans", "complete", "new-plan.progress.md"), []byte("### Iteration 1: T1\n**Gotcha:** Flags persist between tests\n"), 0644)
+
+	reportLast = "30d"
+	defer func() { reportLast = "" }()
+
+	var out bytes.Buffer
+	reportCmd.SetOut(&out)
+	defer reportCmd.SetOut(nil)
+
+	if err := runReport(reportCmd, nil); err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+
+	text := out.String()
+	if !strings.Contains(text, "| new-plan | completed | 1/30 |") {
+		t.Errorf("report missing new-plan:\n%s", text)
+	}
+	if strings.Contains(text, "old-plan") {
+		t.Errorf("report should exclude events outside --last:\n%s", text)
+	}
+	if !strings.Contains(text, "### new-plan\n\n- Flags persist between tests") {
+		t.Errorf("report missing gotchas from the progress file:\n%s", text)
+	}
+}
+
+func TestRunReport_Labels(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	eventLog := events.NewLog(events.Path(".ralph"))
+	eventLog.Append(events.Event{Time: time.Now().Add(-60 * 24 * time.Hour), Type: events.TypePlanStarted, Plan: "api", Labels: []string{"backend"}})
+	eventLog.Append(events.Event{Type: events.TypeIteration, Plan: "api", MaxIterations: 30})
+	eventLog.Append(events.Event{Type: events.TypePlanStarted, Plan: "guide", Labels: []string{"docs"}})
+	eventLog.Append(events.Event{Type: events.TypeIteration, Plan: "guide", MaxIterations: 30})
+
+	// The start event predates --last but still labels the plan
+	reportLast = "30d"
+	reportLabels = []string{"backend"}
+	defer func() { reportLast, reportLabels = "", nil }()
+
+	var out bytes.Buffer
+	reportCmd.SetOut(&out)
+	defer reportCmd.SetOut(nil)
+
+	if err := runReport(reportCmd, nil); err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+
+	text := out.String()
+	if !strings.Contains(text, "| api |") {
+		t.Errorf("report should include the backend plan, got:\n%s", text)
+	}
+	if strings.Contains(text, "guide") {
+		t.Errorf("report should leave out plans without the label, got:\n%s", text)
+	}
+}
+
+func TestRunReport_HTMLToFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	reportFormat = "html"
+	reportOutput = filepath.Join(tmpDir, "report.html")
+	defer func() { reportFormat, reportOutput = "markdown", "" }()
+
+	var out bytes.Buffer
+	reportCmd.SetOut(&out)
+	defer reportCmd.SetOut(nil)
+
+	if err := runReport(reportCmd, nil); err != nil {
+		t.Fatalf("runReport() error = %v", err)
+	}
+
+	content, err := os.ReadFile(reportOutput)
+	if err != nil {
+		t.Fatalf("report file not written: %v", err)
+	}
+	if !strings.Contains(string(content), "<h1>Ralph Report</h1>") {
+		t.Errorf("unexpected report file:\n%s", content)
+	}
+	if !strings.Contains(out.String(), "Report written to") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
+
+func TestRunReport_InvalidFormat(t *testing.T) {
+	reportFormat = "pdf"
+	defer func() { reportFormat = "markdown" }()
+
+	if err := runReport(reportCmd, nil); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
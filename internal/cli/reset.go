@@ -3,80 +3,99 @@ package cli
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
 	"github.com/arvesolland/ralph/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 var resetCmd = &cobra.Command{
-	Use:   "reset",
-	Short: "Move current plan back to pending",
-	Long: `Reset the current plan by moving it from current/ back to pending/.
+	Use:   "reset [plan]",
+	Short: "Reset a plan's execution state and return it to pending",
+	Long: `Reset a plan for a clean retry, keeping the plan itself.
 
-This is useful when you want to restart a plan from scratch, or when
-a plan was interrupted and you want to start over.
+Resets the current plan, or the named plan if given (current or pending).
+The execution context (iteration counter) is cleared, the progress file is
+truncated, and the worktree, feature branch, and changes ledger are removed
+so the next run starts from a fresh base branch. The plan is moved back to pending/.
 
-If a worktree exists for the plan, it will be removed (unless --keep-worktree
-is specified).
+Use --keep-progress to keep the progress log, --keep-branch to keep the
+feature branch (the next run continues from its commits), or --keep-worktree
+to keep the worktree (only its execution context is cleared; implies
+--keep-branch).
 
 By default, prompts for confirmation before resetting.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runReset,
 }
 
 var (
 	resetForce        bool
 	resetKeepWorktree bool
+	resetKeepProgress bool
+	resetKeepBranch   bool
 )
 
 func init() {
 	rootCmd.AddCommand(resetCmd)
 	resetCmd.Flags().BoolVarP(&resetForce, "force", "f", false, "Skip confirmation prompt")
 	resetCmd.Flags().BoolVar(&resetKeepWorktree, "keep-worktree", false, "Don't remove the worktree")
+	resetCmd.Flags().BoolVar(&resetKeepProgress, "keep-progress", false, "Don't truncate the progress file")
+	resetCmd.Flags().BoolVar(&resetKeepBranch, "keep-branch", false, "Don't delete the feature branch")
 }
 
 func runReset(cmd *cobra.Command, args []string) error {
 	// Initialize git to find repo root
 	g := git.NewGit(".")
-	repoRoot, err := g.RepoRoot()
-	if err != nil {
+	if _, err := g.RepoRoot(); err != nil {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
 
 	// Create queue
 	plansDir := "plans"
 	queue := plan.NewQueue(plansDir)
+	queue.Audit = cliAuditLog()
 
-	// Get current plan
-	current, err := queue.Current()
+	target, inCurrent, err := resetTarget(queue, args)
 	if err != nil {
-		return fmt.Errorf("checking current plan: %w", err)
+		return err
 	}
 
-	if current == nil {
-		return fmt.Errorf("no current plan to reset")
-	}
+	// A kept worktree still has the branch checked out
+	keepBranch := resetKeepBranch || resetKeepWorktree
+
+	worktreesDir := ".ralph/worktrees"
+	manager, managerErr := worktree.NewManager(g, worktreesDir)
+	hasWorktree := managerErr == nil && manager.Exists(target)
 
 	// Confirm unless --force
 	if !resetForce {
-		fmt.Printf("Reset plan '%s' back to pending?\n", current.Name)
-		fmt.Printf("Branch: %s\n", current.Branch)
+		fmt.Printf("Reset plan '%s' back to pending?\n", target.Name)
+		fmt.Printf("Branch: %s", target.Branch)
+		if !keepBranch {
+			fmt.Print(" (will be deleted)")
+		}
+		fmt.Println()
 
-		// Check if worktree exists
-		worktreesDir := ".ralph/worktrees"
-		manager, err := worktree.NewManager(g, worktreesDir)
-		if err == nil && manager.Exists(current) {
+		if hasWorktree {
 			if resetKeepWorktree {
-				fmt.Println("Worktree will be kept")
+				fmt.Println("Worktree will be kept; its execution context will be cleared")
 			} else {
-				fmt.Printf("Worktree at %s will be removed\n", manager.Path(current))
+				fmt.Printf("Worktree at %s will be removed\n", manager.Path(target))
 			}
 		}
+		if !resetKeepProgress {
+			fmt.Println("Progress file will be truncated")
+		}
 
 		fmt.Print("\nContinue? [y/N] ")
 		reader := bufio.NewReader(os.Stdin)
@@ -92,31 +111,92 @@ func runReset(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Remove worktree if it exists and --keep-worktree is not set
-	if !resetKeepWorktree {
-		worktreesDir := ".ralph/worktrees"
-		manager, err := worktree.NewManager(g, worktreesDir)
-		if err == nil && manager.Exists(current) {
-			log.Info("Removing worktree...")
-			// Don't delete branch - user might want to continue later
-			if err := manager.Remove(current, false); err != nil {
-				log.Warn("Failed to remove worktree: %v", err)
-				// Continue anyway - the reset itself is more important
-			} else {
-				log.Success("Worktree removed")
-			}
+	switch {
+	case hasWorktree && resetKeepWorktree:
+		// Clear the execution context so the next run starts at iteration 1
+		wtPath := manager.Path(target)
+		removeIfExists(runner.ContextPath(wtPath), "execution context")
+		removeIfExists(runner.CheckpointPath(wtPath), "iteration checkpoint")
+		if !resetKeepProgress {
+			removeIfExists(filepath.Join(wtPath, plansDir, "current", filepath.Base(plan.ProgressPath(target))), "worktree progress file")
+		}
+	case hasWorktree:
+		log.Info("Removing worktree...")
+		if err := manager.Remove(target, !keepBranch); err != nil {
+			log.Warn("Failed to remove worktree: %v", err)
+			// Continue anyway - the reset itself is more important
+		} else {
+			log.Success("Worktree removed")
+		}
+	case !keepBranch && target.Branch != "":
+		if err := g.DeleteBranch(target.Branch, true); err != nil && !errors.Is(err, git.ErrBranchNotFound) {
+			log.Warn("Failed to delete branch %s: %v", target.Branch, err)
 		}
 	}
 
-	// Reset the plan
-	if err := queue.Reset(current); err != nil {
-		return fmt.Errorf("resetting plan: %w", err)
+	if !resetKeepProgress {
+		removeIfExists(plan.ProgressPath(target), "progress file")
+	}
+	// The ledger describes the feature branch's commits
+	if !keepBranch {
+		removeIfExists(plan.ChangesPath(target), "changes ledger")
 	}
 
-	log.Success("Plan '%s' reset to pending", current.Name)
+	// Reset the plan
+	if inCurrent {
+		if err := queue.Reset(target); err != nil {
+			return fmt.Errorf("resetting plan: %w", err)
+		}
+	}
 
-	// Store repo root for reference (unused but avoids warning)
-	_ = repoRoot
+	// Start ETA estimates over for the next run
+	eventLog := events.NewLog(events.Path(filepath.Dir(GetConfigPath())))
+	if err := eventLog.Append(events.Event{Type: events.TypePlanReset, Plan: target.Name}); err != nil {
+		log.Debug("Failed to record %s event: %v", events.TypePlanReset, err)
+	}
 
+	log.Success("Plan '%s' reset to pending", target.Name)
 	return nil
 }
+
+// resetTarget returns the plan to reset and whether it is in current/.
+// With no arguments, the current plan is used.
+func resetTarget(queue *plan.Queue, args []string) (*plan.Plan, bool, error) {
+	current, err := queue.Current()
+	if err != nil {
+		return nil, false, fmt.Errorf("checking current plan: %w", err)
+	}
+
+	if len(args) == 0 {
+		if current == nil {
+			return nil, false, fmt.Errorf("no current plan to reset")
+		}
+		return current, true, nil
+	}
+
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return nil, false, err
+	}
+	if current != nil && current.Name == p.Name {
+		return current, true, nil
+	}
+
+	pending, err := queue.Pending()
+	if err != nil {
+		return nil, false, fmt.Errorf("listing pending plans: %w", err)
+	}
+	for _, candidate := range pending {
+		if candidate.Name == p.Name {
+			return candidate, false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("plan %s is already complete", p.Name)
+}
+
+// removeIfExists removes a file, logging failures other than it not existing.
+func removeIfExists(path, what string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Warn("Failed to remove %s: %v", what, err)
+	}
+}
@@ -6,14 +6,17 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/runner"
 )
 
 func TestResetCmd_HelpOutput(t *testing.T) {
 	// Verify the command is properly registered
 	cmd := resetCmd
 
-	if cmd.Use != "reset" {
-		t.Errorf("expected Use = 'reset', got %q", cmd.Use)
+	if cmd.Use != "reset [plan]" {
+		t.Errorf("expected Use = 'reset [plan]', got %q", cmd.Use)
 	}
 
 	if cmd.Short == "" {
@@ -352,3 +355,149 @@ func TestResetCmd_KeepWorktree(t *testing.T) {
 		t.Error("expected plan to be in pending/")
 	}
 }
+
+// setupResetRepo creates a git repo with an initial commit and a plan queue,
+// chdirs into it, and returns the repo path.
+func setupResetRepo(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(tmpDir, "plans", dir), 0755)
+	}
+
+	oldWd, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	os.Chdir(tmpDir)
+	return tmpDir
+}
+
+// branchExists reports whether a local branch exists in the repo.
+func branchExists(t *testing.T, dir, branch string) bool {
+	t.Helper()
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
+func TestResetCmd_NamedPendingPlan(t *testing.T) {
+	tmpDir := setupResetRepo(t)
+
+	// A skipped plan back in pending with a branch and progress from a previous run
+	os.WriteFile(filepath.Join(tmpDir, "plans", "pending", "test-plan.md"), []byte("# Plan: Test\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "plans", "pending", "test-plan.progress.md"), []byte("old progress"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "plans", "pending", "test-plan.changes.json"), []byte(`{"files":[]}`), 0644)
+	exec.Command("git", "-C", tmpDir, "branch", "feat/test-plan").Run()
+
+	resetForce = true
+	defer func() { resetForce = false }()
+
+	if err := runReset(resetCmd, []string{"test-plan"}); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "plans", "pending", "test-plan.md")); err != nil {
+		t.Error("expected plan to stay in pending/")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "plans", "pending", "test-plan.progress.md")); !os.IsNotExist(err) {
+		t.Error("expected progress file to be truncated")
+	}
+	if branchExists(t, tmpDir, "feat/test-plan") {
+		t.Error("expected branch to be deleted for a fresh base")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "plans", "pending", "test-plan.changes.json")); !os.IsNotExist(err) {
+		t.Error("expected changes ledger to be removed with the branch")
+	}
+
+	ev, _ := events.NewLog(events.Path(".ralph")).Last(events.TypePlanReset)
+	if ev == nil || ev.Plan != "test-plan" {
+		t.Errorf("plan_reset event = %+v", ev)
+	}
+}
+
+func TestResetCmd_KeepProgressAndBranch(t *testing.T) {
+	tmpDir := setupResetRepo(t)
+
+	currentDir := filepath.Join(tmpDir, "plans", "current")
+	os.WriteFile(filepath.Join(currentDir, "test-plan.md"), []byte("# Plan: Test\n"), 0644)
+	os.WriteFile(filepath.Join(currentDir, "test-plan.progress.md"), []byte("old progress"), 0644)
+	os.WriteFile(filepath.Join(currentDir, "test-plan.changes.json"), []byte(`{"files":[]}`), 0644)
+	worktreePath := filepath.Join(tmpDir, ".ralph", "worktrees", "test-plan")
+	exec.Command("git", "-C", tmpDir, "worktree", "add", "-b", "feat/test-plan", worktreePath).Run()
+
+	resetForce, resetKeepProgress, resetKeepBranch = true, true, true
+	defer func() { resetForce, resetKeepProgress, resetKeepBranch = false, false, false }()
+
+	if err := runReset(resetCmd, nil); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Error("expected worktree to be removed")
+	}
+	if !branchExists(t, tmpDir, "feat/test-plan") {
+		t.Error("expected branch to be kept with --keep-branch")
+	}
+	if _, err := os.Stat(filepath.Join(currentDir, "test-plan.progress.md")); err != nil {
+		t.Error("expected progress file to be kept with --keep-progress")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "plans", "pending", "test-plan.changes.json")); err != nil {
+		t.Error("expected changes ledger to follow the plan to pending/ with --keep-branch")
+	}
+}
+
+func TestResetCmd_KeepWorktreeClearsContext(t *testing.T) {
+	tmpDir := setupResetRepo(t)
+
+	os.WriteFile(filepath.Join(tmpDir, "plans", "current", "test-plan.md"), []byte("# Plan: Test\n"), 0644)
+	worktreePath := filepath.Join(tmpDir, ".ralph", "worktrees", "test-plan")
+	exec.Command("git", "-C", tmpDir, "worktree", "add", "-b", "feat/test-plan", worktreePath).Run()
+
+	ctxPath := runner.ContextPath(worktreePath)
+	os.MkdirAll(filepath.Dir(ctxPath), 0755)
+	os.WriteFile(ctxPath, []byte(`{"iteration": 12}`), 0644)
+	wtProgress := filepath.Join(worktreePath, "plans", "current", "test-plan.progress.md")
+	os.MkdirAll(filepath.Dir(wtProgress), 0755)
+	os.WriteFile(wtProgress, []byte("old progress"), 0644)
+
+	resetForce, resetKeepWorktree = true, true
+	defer func() { resetForce, resetKeepWorktree = false, false }()
+
+	if err := runReset(resetCmd, nil); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Error("expected worktree to be kept")
+	}
+	if _, err := os.Stat(ctxPath); !os.IsNotExist(err) {
+		t.Error("expected execution context to be cleared")
+	}
+	if _, err := os.Stat(wtProgress); !os.IsNotExist(err) {
+		t.Error("expected worktree progress copy to be truncated")
+	}
+}
+
+func TestResetCmd_CompletePlan(t *testing.T) {
+	tmpDir := setupResetRepo(t)
+	os.WriteFile(filepath.Join(tmpDir, "plans", "complete", "done.md"), []byte("# Plan: Done\n"), 0644)
+
+	resetForce = true
+	defer func() { resetForce = false }()
+
+	err := runReset(resetCmd, []string{"done"})
+	if err == nil || !strings.Contains(err.Error(), "already complete") {
+		t.Errorf("expected complete plan error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/spf13/cobra"
+)
+
+var retryCmd = &cobra.Command{
+	Use:   "retry <plan>",
+	Short: "Requeue a failed or abandoned plan",
+	Long: `Move a plan from plans/failed/ or plans/abandoned/ back to plans/pending/.
+
+Plans land in failed/ when they reach max iterations without completing,
+or when worker.plan_retries is set and they fail without retries left.
+Retrying resets the plan's automatic retry count.
+The plan keeps its progress and feedback files. If its worktree still
+exists it is kept, with the execution context cleared so the next run
+starts again at iteration 1 on top of the work already committed.
+
+Example:
+  ralph retry my-feature`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRetry,
+}
+
+func init() {
+	rootCmd.AddCommand(retryCmd)
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	plansDir := "plans"
+	queue := plan.NewQueue(plansDir)
+	queue.Audit = cliAuditLog()
+
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".md")
+	var p *plan.Plan
+	var source string
+	for _, dir := range []string{"failed", "abandoned"} {
+		path := filepath.Join(plansDir, dir, name+".md")
+		if loaded, err := plan.Load(path); err == nil {
+			p, source = loaded, path
+			break
+		}
+	}
+	if p == nil {
+		return fmt.Errorf("%w: %s", plan.ErrPlanNotRetryable, name)
+	}
+
+	// A plan of the same name may have been created or cloned since
+	if existing := queuedPlanPath(plansDir, name); existing != source {
+		return fmt.Errorf("plan %s already exists at %s", name, existing)
+	}
+
+	if err := queue.Retry(p); err != nil {
+		return err
+	}
+
+	configDir := filepath.Dir(GetConfigPath())
+	if manager := planWorktreeManager(configDir); manager != nil && manager.Exists(p) {
+		wtPath := manager.Path(p)
+		removeIfExists(runner.ContextPath(wtPath), "execution context")
+		removeIfExists(runner.CheckpointPath(wtPath), "iteration checkpoint")
+	}
+
+	// Start the automatic retry budget over
+	if err := control.NewStore(control.Path(configDir)).ClearRetry(p.Name); err != nil {
+		log.Debug("Failed to clear retry state: %v", err)
+	}
+
+	eventLog := events.NewLog(events.Path(configDir))
+	if err := eventLog.Append(events.Event{Type: events.TypePlanReset, Plan: p.Name, Message: "retry"}); err != nil {
+		log.Debug("Failed to record %s event: %v", events.TypePlanReset, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Requeued %s to %s\n", p.Name, p.Path)
+	return nil
+}
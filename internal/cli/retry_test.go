@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestRunRetry_Failed(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.MkdirAll(filepath.Join("plans", "failed"), 0755)
+	os.WriteFile(filepath.Join("plans", "failed", "stuck.md"), []byte("# Plan: Stuck\n"), 0644)
+	os.WriteFile(filepath.Join("plans", "failed", "stuck.progress.md"), []byte("## Failed\n"), 0644)
+
+	var out bytes.Buffer
+	retryCmd.SetOut(&out)
+	defer retryCmd.SetOut(nil)
+
+	if err := runRetry(retryCmd, []string{"stuck"}); err != nil {
+		t.Fatalf("runRetry() error = %v", err)
+	}
+
+	for _, path := range []string{"stuck.md", "stuck.progress.md"} {
+		if _, err := os.Stat(filepath.Join("plans", "pending", path)); err != nil {
+			t.Errorf("%s not moved to pending/: %v", path, err)
+		}
+	}
+	if !strings.Contains(out.String(), "Requeued stuck") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	ev, _ := events.NewLog(events.Path(".ralph")).Last(events.TypePlanReset)
+	if ev == nil || ev.Plan != "stuck" || ev.Message != "retry" {
+		t.Errorf("plan_reset event = %+v", ev)
+	}
+}
+
+func TestRunRetry_Abandoned(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.MkdirAll(filepath.Join("plans", "abandoned"), 0755)
+	os.WriteFile(filepath.Join("plans", "abandoned", "old.md"), []byte("# Plan: Old\n"), 0644)
+
+	retryCmd.SetOut(&bytes.Buffer{})
+	defer retryCmd.SetOut(nil)
+
+	if err := runRetry(retryCmd, []string{"old"}); err != nil {
+		t.Fatalf("runRetry() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join("plans", "pending", "old.md")); err != nil {
+		t.Errorf("plan not moved to pending/: %v", err)
+	}
+}
+
+func TestRunRetry_Errors(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "pending", "waiting.md"), []byte("# Plan: Waiting\n"), 0644)
+
+	if err := runRetry(retryCmd, []string{"waiting"}); !errors.Is(err, plan.ErrPlanNotRetryable) {
+		t.Errorf("pending plan: error = %v, want ErrPlanNotRetryable", err)
+	}
+
+	// A newer plan with the same name blocks the retry
+	os.MkdirAll(filepath.Join("plans", "failed"), 0755)
+	os.WriteFile(filepath.Join("plans", "failed", "waiting.md"), []byte("# Plan: Waiting\n"), 0644)
+	if err := runRetry(retryCmd, []string{"waiting"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("duplicate: error = %v, want already exists", err)
+	}
+}
@@ -2,8 +2,11 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/i18n"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +17,8 @@ var (
 	verbose    bool
 	quiet      bool
 	noColor    bool
+	logLevel   string
+	logFormat  string
 )
 
 // rootCmd represents the base command when called without any subcommands.
@@ -27,20 +32,51 @@ with progress persisted in files and git.
 Ralph manages plan-based development workflows where an AI agent executes
 tasks iteratively, with each iteration getting a fresh context window
 while progress is tracked in plan files and git commits.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Configure logging based on flags
-		logger := log.Default().(*log.ConsoleLogger)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		configureLocale(cmd.Root())
+		return configureLogging(log.Default())
+	},
+}
 
-		if verbose {
-			logger.SetLevel(log.LevelDebug)
-		} else if quiet {
-			logger.SetLevel(log.LevelWarn)
-		}
+// configureLocale selects the locale of notifications and CLI output from
+// RALPH_LOCALE, project.locale, or the environment's locale, and localizes
+// the prefix of command errors.
+func configureLocale(root *cobra.Command) {
+	var configured string
+	if cfg, err := config.LoadWithDefaults(GetConfigPath()); err == nil {
+		configured = cfg.Project.Locale
+	}
+	i18n.SetLocale(i18n.Resolve(configured))
+	root.SetErrPrefix(i18n.T("Error:"))
+}
 
-		if noColor {
-			logger.SetColorEnabled(false)
+// configureLogging applies the global logging flags to logger.
+// --log-level takes precedence over --verbose and --quiet.
+func configureLogging(logger log.Logger) error {
+	if verbose {
+		logger.SetLevel(log.LevelDebug)
+	} else if quiet {
+		logger.SetLevel(log.LevelWarn)
+	}
+	if logLevel != "" {
+		level, err := log.ParseLevel(logLevel)
+		if err != nil {
+			return fmt.Errorf("--log-level: %w", err)
 		}
-	},
+		logger.SetLevel(level)
+	}
+
+	format, err := log.ParseFormat(logFormat)
+	if err != nil {
+		return fmt.Errorf("--log-format: %w", err)
+	}
+	logger.SetFormat(format)
+
+	// JSON lines are for machines; never wrap them in color codes
+	if noColor || format == log.FormatJSON {
+		logger.SetColorEnabled(false)
+	}
+	return nil
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -57,6 +93,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output (debug level)")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress informational output (warnings and errors only)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable color output")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "minimum log level: debug, info, warn, or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log output format: text or json")
 }
 
 // GetConfigPath returns the config path from flags.
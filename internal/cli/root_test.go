@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+func TestConfigureLogging(t *testing.T) {
+	defer func() { verbose, logLevel, logFormat = false, "", "text" }()
+
+	var buf bytes.Buffer
+	logger := log.NewConsoleLogger()
+	logger.SetOutput(&buf)
+
+	// --log-level wins over --verbose
+	verbose, logLevel, logFormat = true, "warn", "json"
+	if err := configureLogging(logger); err != nil {
+		t.Fatalf("configureLogging() error = %v", err)
+	}
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, `"msg":"shown"`) {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestConfigureLogging_Invalid(t *testing.T) {
+	defer func() { logLevel, logFormat = "", "text" }()
+
+	logLevel = "loud"
+	if err := configureLogging(log.NewConsoleLogger()); err == nil {
+		t.Error("expected error for invalid --log-level")
+	}
+
+	logLevel, logFormat = "", "xml"
+	if err := configureLogging(log.NewConsoleLogger()); err == nil {
+		t.Error("expected error for invalid --log-format")
+	}
+}
@@ -12,10 +12,12 @@ import (
 
 	"github.com/arvesolland/ralph/internal/config"
 	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/knowledge"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/prompt"
 	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worker"
 	"github.com/spf13/cobra"
 )
 
@@ -87,11 +89,16 @@ func runRun(cmd *cobra.Command, args []string) error {
 	g := git.NewGit(worktreePath)
 
 	// Verify we're in a git repo
-	_, err = g.RepoRoot()
+	repoRoot, err := g.RepoRoot()
 	if err != nil {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
 
+	// Mask secrets before anything reaches logs or transcripts
+	if err := configureRedaction(cfg, repoRoot); err != nil {
+		return fmt.Errorf("configuring redaction: %w", err)
+	}
+
 	// Get current branch for context
 	currentBranch, err := g.CurrentBranch()
 	if err != nil {
@@ -107,8 +114,17 @@ func runRun(cmd *cobra.Command, args []string) error {
 	promptsDir := filepath.Join(configDir, "prompts")
 	promptBuilder := prompt.NewBuilder(cfg, configDir, promptsDir)
 
-	// Create CLI runner
-	claudeRunner := runner.NewCLIRunner()
+	// Create CLI runner, checking the claude install before the first iteration
+	claudeRunner, err := worker.NewClaudeRunner(cmd.Context(), cfg, configDir)
+	if err != nil {
+		return err
+	}
+	worker.ApplyPresetEnv(claudeRunner, cfg, repoRoot)
+
+	auditLog := newAuditLog(cfg, configDir, "run")
+	if err := auditLog.Config(cfg); err != nil {
+		log.Warn("Failed to record config in the audit log: %v", err)
+	}
 
 	// Create iteration loop
 	loop := runner.NewIterationLoop(runner.LoopConfig{
@@ -119,6 +135,8 @@ func runRun(cmd *cobra.Command, args []string) error {
 		Git:           g,
 		PromptBuilder: promptBuilder,
 		WorktreePath:  worktreePath,
+		Audit:         auditLog,
+		KnowledgePath: knowledge.Path(configDir),
 		OnIteration: func(iteration int, result *runner.Result) {
 			log.Info("Iteration %d/%d complete", iteration, maxIterations)
 			if result.IsComplete {
@@ -0,0 +1,85 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/vuln"
+	"github.com/spf13/cobra"
+)
+
+var (
+	securityPlanInput  string
+	securityPlanGroup  string
+	securityPlanDryRun bool
+)
+
+var securityPlanCmd = &cobra.Command{
+	Use:   "security-plan --input <report.json>",
+	Short: "Create pending plans fixing the vulnerabilities in a scanner report",
+	Long: `Parse a vulnerability scanner report and create pending plans that fix
+its findings.
+
+Supported reports are trivy's JSON output (trivy fs --format json) and
+govulncheck's (govulncheck -json). Findings are grouped into one plan per
+package (the default) or per severity with --group severity. Each plan has one
+task per affected package, and its Context lists every finding with the
+advisory link, where it's reached (the scanned target, or govulncheck's call
+stack), and the version that fixes it.
+
+Plan titles start with a priority, P1 for critical through P5 for unknown, so
+the queue works through the most severe first. govulncheck reports no
+severity: vulnerabilities whose code is called are high, the rest low.
+
+Example:
+  trivy fs --format json -o trivy.json . && ralph security-plan --input trivy.json
+  govulncheck -json ./... > vulns.json && ralph security-plan --input vulns.json --group severity`,
+	Args: cobra.NoArgs,
+	RunE: runSecurityPlan,
+}
+
+func init() {
+	rootCmd.AddCommand(securityPlanCmd)
+	securityPlanCmd.Flags().StringVar(&securityPlanInput, "input", "", "trivy or govulncheck JSON report (required)")
+	securityPlanCmd.Flags().StringVar(&securityPlanGroup, "group", vuln.ByPackage, "one plan per \"package\" or \"severity\"")
+	securityPlanCmd.Flags().BoolVar(&securityPlanDryRun, "dry-run", false, "list the plans without creating them")
+	securityPlanCmd.MarkFlagRequired("input")
+}
+
+func runSecurityPlan(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(securityPlanInput)
+	if err != nil {
+		return fmt.Errorf("reading report: %w", err)
+	}
+	findings, err := vuln.Parse(data)
+	if err != nil {
+		return err
+	}
+	buckets, err := vuln.Group(findings, securityPlanGroup)
+	if err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No vulnerabilities found")
+		return nil
+	}
+
+	queue := plan.NewQueue("plans")
+	source := "ralph security-plan --input " + filepath.Base(securityPlanInput)
+	for _, b := range buckets {
+		opts := vuln.PlanOptions(b, source)
+		if securityPlanDryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%d findings)\n", opts.Title, len(b.Findings))
+			continue
+		}
+		p, err := createIssuePlan(queue, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Created %s: %d findings\n", p.Path, len(b.Findings))
+	}
+	return nil
+}
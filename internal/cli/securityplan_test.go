@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSecurityPlan(t *testing.T) {
+	report, err := filepath.Abs(filepath.Join("..", "vuln", "testdata", "trivy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer setupAbandonTest(t)()
+
+	securityPlanInput, securityPlanGroup = report, "package"
+	defer func() { securityPlanInput, securityPlanGroup, securityPlanDryRun = "", "package", false }()
+
+	var out bytes.Buffer
+	securityPlanCmd.SetOut(&out)
+	defer securityPlanCmd.SetOut(nil)
+
+	if err := runSecurityPlan(securityPlanCmd, nil); err != nil {
+		t.Fatalf("runSecurityPlan() error = %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join("plans", "pending"))
+	var names []string
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".progress.md") && !strings.HasSuffix(e.Name(), ".feedback.md") {
+			names = append(names, e.Name())
+		}
+	}
+	want := "p1-fix-critical-vulnerabilities-in-lodash.md,p3-fix-medium-vulnerabilities-in-minimist.md"
+	if strings.Join(names, ",") != want {
+		t.Errorf("pending plans = %v, want %s", names, want)
+	}
+	data, _ := os.ReadFile(filepath.Join("plans", "pending", "p1-fix-critical-vulnerabilities-in-lodash.md"))
+	if !strings.Contains(string(data), "**Labels:** security, critical") || !strings.Contains(string(data), "### T1: Upgrade lodash to 4.17.22") {
+		t.Errorf("plan content = %s", data)
+	}
+
+	// --dry-run lists the plans without creating them
+	out.Reset()
+	os.RemoveAll(filepath.Join("plans", "pending"))
+	securityPlanGroup, securityPlanDryRun = "severity", true
+	if err := runSecurityPlan(securityPlanCmd, nil); err != nil {
+		t.Fatalf("runSecurityPlan(--dry-run) error = %v", err)
+	}
+	if !strings.Contains(out.String(), "P1: Fix critical vulnerabilities (1 findings)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join("plans", "pending")); !os.IsNotExist(err) {
+		t.Error("--dry-run created plans")
+	}
+}
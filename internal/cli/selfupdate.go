@@ -0,0 +1,127 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/update"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateForce bool
+
+// newUpdateClient creates the release client; replaced in tests.
+var newUpdateClient = update.NewClient
+
+// executablePath returns the running binary's path; replaced in tests.
+var executablePath = func() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update ralph to the latest release",
+	Long: `Download the latest ralph release for this OS and architecture from
+GitHub and replace the running binary.
+
+The archive is verified against the release's checksums.txt, and
+checksums.txt against its ed25519 signature when this binary was built
+with a release public key. The new binary is written next to the old one
+and renamed into place, so a failed update leaves the old binary intact.
+
+Development builds (version "dev") are only updated with --force.`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateForce, "force", false, "install the latest release even if it isn't newer")
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	out := cmd.OutOrStdout()
+	client := newUpdateClient()
+
+	rel, newer, err := checkForUpdate(ctx, client)
+	if err != nil {
+		return err
+	}
+	if !newer && !selfUpdateForce {
+		if Version == "dev" {
+			return fmt.Errorf("development build; use --force to install release %s", rel.Version())
+		}
+		fmt.Fprintf(out, "ralph %s is up to date\n", Version)
+		return nil
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return fmt.Errorf("locating ralph binary: %w", err)
+	}
+
+	if client.PublicKey == "" {
+		log.Warn("This build has no release public key; verifying checksums only")
+	}
+	log.Info("Downloading ralph %s...", rel.Version())
+	binary, err := client.Download(ctx, rel)
+	if err != nil {
+		return fmt.Errorf("downloading release: %w", err)
+	}
+
+	if err := update.ReplaceExecutable(exe, binary); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Updated ralph %s -> %s (%s)\n", Version, rel.Version(), exe)
+	return nil
+}
+
+// checkForUpdate fetches the latest release and reports whether it is newer
+// than this binary. Development builds are never considered out of date.
+func checkForUpdate(ctx context.Context, client *update.Client) (*update.Release, bool, error) {
+	rel, err := client.LatestRelease(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if Version == "dev" {
+		return rel, false, nil
+	}
+
+	cmp, err := update.CompareVersions(Version, rel.Version())
+	if err != nil {
+		return nil, false, fmt.Errorf("comparing versions: %w", err)
+	}
+	return rel, cmp < 0, nil
+}
+
+// printUpdateCheck reports whether a newer release is available.
+func printUpdateCheck(ctx context.Context, w io.Writer) error {
+	rel, newer, err := checkForUpdate(ctx, newUpdateClient())
+	if err != nil {
+		return fmt.Errorf("checking for updates: %w", err)
+	}
+
+	switch {
+	case newer:
+		fmt.Fprintf(w, "Update available: %s -> %s (run 'ralph self-update')\n", Version, rel.Version())
+		if rel.HTMLURL != "" {
+			fmt.Fprintf(w, "  %s\n", rel.HTMLURL)
+		}
+	case Version == "dev":
+		fmt.Fprintf(w, "Development build; latest release is %s\n", rel.Version())
+	default:
+		fmt.Fprintf(w, "ralph %s is up to date\n", Version)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/update"
+)
+
+// stubRelease serves latest release tag with an unsigned archive containing
+// binary, and points the update client and executable path at test values.
+func stubRelease(t *testing.T, tag string, binary string) string {
+	t.Helper()
+
+	name := update.AssetName(strings.TrimPrefix(tag, "v"), runtime.GOOS, runtime.GOARCH)
+	var buf bytes.Buffer
+	if runtime.GOOS == "windows" {
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create(update.BinaryName(runtime.GOOS))
+		f.Write([]byte(binary))
+		zw.Close()
+	} else {
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		tw.WriteHeader(&tar.Header{Name: update.BinaryName(runtime.GOOS), Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(binary))
+		tw.Close()
+		gz.Close()
+	}
+	archive := buf.Bytes()
+	sum := sha256.Sum256(archive)
+	sums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/repos/arvesolland/ralph/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(update.Release{TagName: tag, HTMLURL: "https://example.com/" + tag, Assets: []update.Asset{
+			{Name: name, URL: srv.URL + "/archive"},
+			{Name: update.ChecksumsAsset, URL: srv.URL + "/sums"},
+		}})
+	})
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(sums)) })
+
+	exe := filepath.Join(t.TempDir(), "ralph")
+	os.WriteFile(exe, []byte("old binary"), 0755)
+
+	oldClient, oldExe, oldVersion := newUpdateClient, executablePath, Version
+	t.Cleanup(func() {
+		newUpdateClient, executablePath, Version, selfUpdateForce = oldClient, oldExe, oldVersion, false
+	})
+	newUpdateClient = func() *update.Client {
+		return &update.Client{Owner: "arvesolland", Repo: "ralph", APIURL: srv.URL}
+	}
+	executablePath = func() (string, error) { return exe, nil }
+	return exe
+}
+
+func TestRunSelfUpdate_InstallsNewerRelease(t *testing.T) {
+	exe := stubRelease(t, "v1.3.0", "new binary")
+	Version = "1.2.0"
+
+	var out bytes.Buffer
+	selfUpdateCmd.SetOut(&out)
+	defer selfUpdateCmd.SetOut(nil)
+
+	if err := runSelfUpdate(selfUpdateCmd, nil); err != nil {
+		t.Fatalf("runSelfUpdate() error = %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new binary" {
+		t.Errorf("binary = %q, want new binary", data)
+	}
+	if !strings.Contains(out.String(), "Updated ralph 1.2.0 -> 1.3.0") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestRunSelfUpdate_UpToDate(t *testing.T) {
+	exe := stubRelease(t, "v1.3.0", "new binary")
+	Version = "1.3.0"
+
+	var out bytes.Buffer
+	selfUpdateCmd.SetOut(&out)
+	defer selfUpdateCmd.SetOut(nil)
+
+	if err := runSelfUpdate(selfUpdateCmd, nil); err != nil {
+		t.Fatalf("runSelfUpdate() error = %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old binary" {
+		t.Errorf("binary replaced although up to date: %q", data)
+	}
+	if !strings.Contains(out.String(), "is up to date") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestRunSelfUpdate_DevBuildNeedsForce(t *testing.T) {
+	exe := stubRelease(t, "v1.3.0", "new binary")
+	Version = "dev"
+
+	if err := runSelfUpdate(selfUpdateCmd, nil); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("runSelfUpdate() error = %v, want hint about --force", err)
+	}
+
+	selfUpdateForce = true
+	selfUpdateCmd.SetOut(&bytes.Buffer{})
+	defer selfUpdateCmd.SetOut(nil)
+	if err := runSelfUpdate(selfUpdateCmd, nil); err != nil {
+		t.Fatalf("runSelfUpdate(--force) error = %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new binary" {
+		t.Errorf("binary = %q, want new binary", data)
+	}
+}
+
+func TestPrintUpdateCheck(t *testing.T) {
+	stubRelease(t, "v1.3.0", "new binary")
+
+	Version = "1.2.0"
+	var out bytes.Buffer
+	if err := printUpdateCheck(context.Background(), &out); err != nil {
+		t.Fatalf("printUpdateCheck() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Update available: 1.2.0 -> 1.3.0") || !strings.Contains(out.String(), "https://example.com/v1.3.0") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	Version = "1.3.0"
+	out.Reset()
+	printUpdateCheck(context.Background(), &out)
+	if !strings.Contains(out.String(), "up to date") {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,235 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/ingest"
+	"github.com/arvesolland/ralph/internal/linear"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// ingestTokenEnv overrides serve.ingest_token.
+const ingestTokenEnv = "RALPH_INGEST_TOKEN"
+
+// serveShutdownTimeout bounds how long in-flight requests may take on shutdown.
+const serveShutdownTimeout = 5 * time.Second
+
+var (
+	serveIngest bool
+	serveLinear bool
+	serveAddr   string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP listener that accepts new plans",
+	Long: `Run an HTTP listener so external systems (forms, ticketing, chat ops)
+can enqueue work.
+
+With --ingest, POST /plans queues a new plan in plans/pending/ with its
+feedback and progress files. Requests must carry the token from
+serve.ingest_token (or $RALPH_INGEST_TOKEN) as "Authorization: Bearer <token>".
+
+A JSON body has title, body, source, tasks, and labels fields, scaffolded
+into a plan like a Slack request, or a markdown field with a complete plan.
+A text/markdown body with a "# Plan: <title>" heading is queued as-is;
+without a heading it is the request text, and the title, source, and label
+query parameters fill in the rest.
+
+The reply is JSON with the plan name, branch, and queue position.
+
+With --linear, POST /webhooks/linear receives Linear webhooks: when an issue
+is created or updated with linear.trigger_label it is queued like
+ralph import-linear, unless it already has a plan. Deliveries are verified
+with linear.webhook_secret (or $LINEAR_WEBHOOK_SECRET).
+
+GET /healthz answers "ok" for monitoring. The listener binds to serve.addr
+(default 127.0.0.1:8484); put a TLS-terminating proxy in front of it before
+exposing it beyond localhost.
+
+Example:
+  ralph serve --ingest
+  curl -H "Authorization: Bearer $RALPH_INGEST_TOKEN" \
+       -d '{"title": "Add audit log", "body": "Record who changed what."}' \
+       http://127.0.0.1:8484/plans`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().BoolVar(&serveIngest, "ingest", false, "accept new plans on POST /plans")
+	serveCmd.Flags().BoolVar(&serveLinear, "linear", false, "queue labeled Linear issues from webhooks on POST /webhooks/linear")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "address to listen on (default from serve.addr)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if !serveIngest && !serveLinear {
+		return fmt.Errorf("nothing to serve: pass --ingest to accept plans on POST /plans or --linear for Linear webhooks")
+	}
+
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if serveAddr != "" {
+		cfg.Serve.Addr = serveAddr
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid --addr: %w", err)
+		}
+	}
+
+	configDir := filepath.Dir(GetConfigPath())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	if serveIngest {
+		handler, err := newIngestHandler(cfg, configDir)
+		if err != nil {
+			return err
+		}
+		mux.Handle("/plans", handler)
+	}
+	if serveLinear {
+		handler, err := newLinearWebhook(cfg, configDir)
+		if err != nil {
+			return err
+		}
+		mux.Handle("/webhooks/linear", handler)
+	}
+	if err := configureRedaction(cfg, "."); err != nil {
+		return fmt.Errorf("configuring redaction: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", cfg.Serve.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", cfg.Serve.Addr, err)
+	}
+	if host, _, _ := net.SplitHostPort(cfg.Serve.Addr); !isLoopback(host) {
+		log.Warn("Listening on %s without TLS: put a TLS-terminating proxy in front of it", cfg.Serve.Addr)
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() { errc <- server.Serve(listener) }()
+	if serveIngest {
+		log.Info("Accepting plans on http://%s/plans", listener.Addr())
+	}
+	if serveLinear {
+		log.Info("Accepting Linear webhooks on http://%s/webhooks/linear", listener.Addr())
+	}
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("serving: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("shutting down: %w", err)
+	}
+	return nil
+}
+
+// newIngestHandler builds the POST /plans handler for the plans/ queue,
+// recording events in configDir. The token comes from $RALPH_INGEST_TOKEN or
+// serve.ingest_token; without one the endpoint would be open, so it's an error.
+func newIngestHandler(cfg *config.Config, configDir string) (*ingest.Handler, error) {
+	token := os.Getenv(ingestTokenEnv)
+	if token == "" {
+		token = cfg.Serve.IngestToken
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no ingest token: set serve.ingest_token or $%s", ingestTokenEnv)
+	}
+
+	plansDir := "plans"
+	if err := os.MkdirAll(filepath.Join(plansDir, "pending"), 0755); err != nil {
+		return nil, fmt.Errorf("creating plans/pending: %w", err)
+	}
+
+	handler := ingest.NewHandler(plan.NewQueue(plansDir), token)
+	handler.Events = events.NewLog(events.Path(configDir))
+	return handler, nil
+}
+
+// newLinearWebhook builds the POST /webhooks/linear handler, which queues
+// issues given linear.trigger_label in the plans/ queue and records events
+// in configDir. It needs a webhook secret to verify deliveries and an API
+// key to fetch the issues.
+func newLinearWebhook(cfg *config.Config, configDir string) (*linear.WebhookHandler, error) {
+	secret := linear.WebhookSecret(cfg.Linear)
+	if secret == "" {
+		return nil, fmt.Errorf("no Linear webhook secret: set linear.webhook_secret or $%s", linear.WebhookSecretEnv)
+	}
+	t, err := tracker.New("linear", cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := plan.NewQueue("plans")
+	eventLog := events.NewLog(events.Path(configDir))
+	label := cfg.Linear.TriggerLabel
+
+	// mu serializes queueing so redelivered webhooks don't create duplicates
+	var mu sync.Mutex
+	return linear.NewWebhookHandler(secret, label, func(id string) error {
+		issue, err := t.FetchIssue(id)
+		if err != nil {
+			return err
+		}
+		if !issue.HasLabel(label) {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if planForIssue(queue, t, issue.Key) != nil {
+			return nil
+		}
+		p, err := createIssuePlan(queue, t.PlanOptions(issue))
+		if err != nil {
+			return err
+		}
+
+		source := "Linear " + issue.Key
+		log.Info("Queued plan %s from %s", p.Name, source)
+		if err := eventLog.Append(events.Event{Type: events.TypePlanQueued, Plan: p.Name, Labels: p.Labels, Message: source}); err != nil {
+			log.Debug("Failed to record %s event: %v", events.TypePlanQueued, err)
+		}
+		return nil
+	}), nil
+}
+
+// isLoopback reports whether host is a loopback address or localhost.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
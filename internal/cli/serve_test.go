@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestRunServe_RequiresIngest(t *testing.T) {
+	serveIngest = false
+	if err := runServe(serveCmd, nil); err == nil || !strings.Contains(err.Error(), "--ingest") {
+		t.Errorf("runServe() error = %v, want a hint to pass --ingest", err)
+	}
+}
+
+func TestNewIngestHandler(t *testing.T) {
+	defer setupAbandonTest(t)()
+	t.Setenv(ingestTokenEnv, "")
+
+	cfg := config.Defaults()
+	if _, err := newIngestHandler(cfg, ".ralph"); err == nil || !strings.Contains(err.Error(), "no ingest token") {
+		t.Fatalf("newIngestHandler() without token error = %v", err)
+	}
+
+	// The environment variable takes precedence over the config
+	cfg.Serve.IngestToken = "from-config"
+	t.Setenv(ingestTokenEnv, "from-env")
+	handler, err := newIngestHandler(cfg, ".ralph")
+	if err != nil {
+		t.Fatalf("newIngestHandler() error = %v", err)
+	}
+
+	for token, want := range map[string]int{"from-config": http.StatusUnauthorized, "from-env": http.StatusCreated} {
+		req := httptest.NewRequest(http.MethodPost, "/plans", strings.NewReader(`{"title": "Add audit log"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("token %s: status = %d, want %d", token, rec.Code, want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join("plans", "pending", "add-audit-log.md")); err != nil {
+		t.Errorf("plan not queued: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(".ralph", "events.jsonl")); err != nil {
+		t.Errorf("plan_queued event not recorded: %v", err)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, want := range map[string]bool{"127.0.0.1": true, "::1": true, "localhost": true, "": false, "0.0.0.0": false, "10.0.0.5": false} {
+		if got := isLoopback(host); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snoozeUntil string
+	snoozeClear bool
+)
+
+var snoozeCmd = &cobra.Command{
+	Use:   "snooze <plan>",
+	Short: "Park a pending plan until a wake time",
+	Long: `Park a pending plan so the worker leaves it alone until a wake time.
+
+The wake time is stored in the plan's **Snoozed-Until:** header. The worker
+skips the plan until then, and removes the header when it wakes. ralph
+status lists snoozed plans with their wake times.
+
+--until takes a day and time ("mon 9am", "friday 14:30", "tomorrow"; a day
+alone wakes at 09:00), a time of day ("3pm"), a period ("2h", "3d", "1w"),
+or a date ("2026-01-05", "2026-01-05 14:00"). Times are local.
+
+Example:
+  ralph snooze my-feature --until "mon 9am"
+  ralph snooze my-feature --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnooze,
+}
+
+func init() {
+	rootCmd.AddCommand(snoozeCmd)
+	snoozeCmd.Flags().StringVar(&snoozeUntil, "until", "", "when the plan wakes, e.g. \"mon 9am\" or \"3d\"")
+	snoozeCmd.Flags().BoolVar(&snoozeClear, "clear", false, "wake the plan now")
+}
+
+func runSnooze(cmd *cobra.Command, args []string) error {
+	if snoozeUntil == "" && !snoozeClear {
+		return fmt.Errorf("--until or --clear is required")
+	}
+
+	queue := plan.NewQueue("plans")
+	pending, err := queue.Pending()
+	if err != nil {
+		return fmt.Errorf("listing pending plans: %w", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".md")
+	var p *plan.Plan
+	for _, candidate := range pending {
+		if candidate.Name == name {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		return fmt.Errorf("plan %s is not pending; only pending plans can be snoozed", name)
+	}
+
+	if snoozeClear {
+		if err := plan.Wake(p); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is no longer snoozed\n", p.Name)
+		return nil
+	}
+
+	until, err := plan.ParseWakeTime(snoozeUntil, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := plan.Snooze(p, until); err != nil {
+		return err
+	}
+
+	eventLog := events.NewLog(events.Path(filepath.Dir(GetConfigPath())))
+	if err := eventLog.Append(events.Event{Type: events.TypePlanSnoozed, Plan: p.Name, Message: until.Format(time.RFC3339)}); err != nil {
+		log.Debug("Failed to record %s event: %v", events.TypePlanSnoozed, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Snoozed %s until %s\n", p.Name, until.Format("Mon Jan 2 15:04"))
+	return nil
+}
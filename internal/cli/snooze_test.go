@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestRunSnooze(t *testing.T) {
+	defer setupAbandonTest(t)()
+	path := filepath.Join("plans", "pending", "alpha.md")
+	os.WriteFile(path, []byte("# Plan: Alpha\n**Status:** pending\n"), 0644)
+	defer func() { snoozeUntil, snoozeClear = "", false }()
+
+	var out bytes.Buffer
+	snoozeCmd.SetOut(&out)
+	defer snoozeCmd.SetOut(nil)
+
+	snoozeUntil = "3d"
+	if err := runSnooze(snoozeCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runSnooze() error = %v", err)
+	}
+	p, _ := plan.Load(path)
+	if p.SnoozedUntil.IsZero() || !strings.HasPrefix(out.String(), "Snoozed alpha until ") {
+		t.Errorf("after snooze: until %v, output %q", p.SnoozedUntil, out.String())
+	}
+	ev, _ := events.NewLog(events.Path(".ralph")).Last(events.TypePlanSnoozed)
+	if ev == nil || ev.Plan != "alpha" || ev.Message == "" {
+		t.Errorf("plan_snoozed event = %+v", ev)
+	}
+
+	snoozeUntil, snoozeClear = "", true
+	if err := runSnooze(snoozeCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runSnooze(--clear) error = %v", err)
+	}
+	if p, _ := plan.Load(path); !p.SnoozedUntil.IsZero() {
+		t.Errorf("still snoozed until %v after --clear", p.SnoozedUntil)
+	}
+}
+
+func TestRunSnooze_Errors(t *testing.T) {
+	defer setupAbandonTest(t)()
+	os.WriteFile(filepath.Join("plans", "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	os.WriteFile(filepath.Join("plans", "current", "beta.md"), []byte("# Plan: Beta\n"), 0644)
+	defer func() { snoozeUntil = "" }()
+
+	tests := map[string]struct{ plan, until string }{
+		"no wake time": {"alpha", ""},
+		"bad time":     {"alpha", "whenever"},
+		"not pending":  {"beta", "3d"},
+		"missing":      {"gamma", "3d"},
+	}
+	for name, tt := range tests {
+		snoozeUntil = tt.until
+		if err := runSnooze(snoozeCmd, []string{tt.plan}); err == nil {
+			t.Errorf("%s: runSnooze() succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage the SQLite state database",
+}
+
+var stateSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Bring the state database up to date with the queue and events log",
+	Long: `Bring .ralph/state.db up to date (state.backend: sqlite).
+
+The worker, status, and report keep the database in sync as they go; sync
+catches up on changes made by hand, such as plans added or deleted outside
+ralph. Only new or changed plans are parsed. With --rebuild the database is
+deleted and rebuilt from plans/ and .ralph/events.jsonl.
+
+Example:
+  ralph state sync
+  ralph state sync --rebuild`,
+	Args: cobra.NoArgs,
+	RunE: runStateSync,
+}
+
+var stateRebuild bool
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateSyncCmd)
+	stateSyncCmd.Flags().BoolVar(&stateRebuild, "rebuild", false, "delete the database and rebuild it from the files")
+}
+
+func runStateSync(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if cfg.State.Backend != config.StateSQLite {
+		return fmt.Errorf("state.backend is '%s'; set it to 'sqlite' to use the state database", cfg.State.Backend)
+	}
+
+	configDir := filepath.Dir(GetConfigPath())
+	path := state.Path(configDir)
+	if stateRebuild {
+		for _, p := range []string{path, path + "-wal", path + "-shm"} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing state database: %w", err)
+			}
+		}
+	}
+
+	db, err := state.Open(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.Sync(plan.NewQueue("plans"), events.NewLog(events.Path(configDir)))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Synced %s: %d plan(s) updated, %d removed, %d event(s) read\n",
+		path, result.Plans, result.Removed, result.Events)
+	return nil
+}
+
+// openStateDB opens the state database if state.backend is sqlite, brings
+// it up to date, and connects the queue and events log (either may be nil)
+// to it: the queue records its moves there and lists finished plans from
+// it, and events appended to the log are mirrored into it. Returns nil if
+// the backend is files; if the database can't be used, a warning is logged
+// and nil returned, so callers fall back to the files. Close the database
+// when done.
+func openStateDB(cfg *config.Config, configDir string, queue *plan.Queue, eventLog *events.Log) *state.DB {
+	if cfg == nil || cfg.State.Backend != config.StateSQLite {
+		return nil
+	}
+	db, err := state.Open(state.Path(configDir))
+	if err != nil {
+		log.Warn("Not using the state database: %v", err)
+		return nil
+	}
+	if _, err := db.Sync(queue, eventLog); err != nil {
+		log.Warn("Not using the state database, failed to sync it: %v", err)
+		db.Close()
+		return nil
+	}
+
+	if queue != nil {
+		queue.Index = db
+	}
+	if eventLog != nil {
+		eventLog.Mirror = func(e events.Event) {
+			if err := db.RecordEvent(e); err != nil {
+				log.Debug("Failed to record %s event in the state database: %v", e.Type, err)
+			}
+		}
+	}
+	return db
+}
+
+// cliStateDB is openStateDB for CLI commands, loading the config itself.
+func cliStateDB(queue *plan.Queue, eventLog *events.Log) *state.DB {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil {
+		log.Debug("Not using the state database, failed to load config: %v", err)
+		return nil
+	}
+	return openStateDB(cfg, filepath.Dir(GetConfigPath()), queue, eventLog)
+}
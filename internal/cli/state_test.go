@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/state"
+)
+
+func TestOpenStateDB_Files(t *testing.T) {
+	cfg := config.Defaults()
+	queue := plan.NewQueue(t.TempDir())
+	if db := openStateDB(cfg, t.TempDir(), queue, nil); db != nil {
+		db.Close()
+		t.Error("openStateDB() with state.backend files should return nil")
+	}
+	if queue.Index != nil {
+		t.Error("openStateDB() with state.backend files should leave the queue alone")
+	}
+}
+
+func TestOpenStateDB_FallsBack(t *testing.T) {
+	for _, d := range sql.Drivers() {
+		if d == state.DriverName {
+			t.Skip("built with the SQLite driver")
+		}
+	}
+
+	cfg := config.Defaults()
+	cfg.State.Backend = config.StateSQLite
+	configDir := t.TempDir()
+	queue := plan.NewQueue(t.TempDir())
+	eventLog := events.NewLog(events.Path(configDir))
+	if db := openStateDB(cfg, configDir, queue, eventLog); db != nil {
+		db.Close()
+		t.Fatal("openStateDB() without the driver should return nil")
+	}
+	if queue.Index != nil || eventLog.Mirror != nil {
+		t.Error("openStateDB() without the driver should fall back to the files")
+	}
+}
+
+func TestRunStateSync_FilesBackend(t *testing.T) {
+	dir := t.TempDir()
+	oldConfigPath := configPath
+	configPath = filepath.Join(dir, ".ralph", "config.yaml")
+	defer func() { configPath = oldConfigPath }()
+
+	if err := runStateSync(stateSyncCmd, nil); err == nil {
+		t.Error("runStateSync() with state.backend files should fail")
+	}
+}
@@ -4,8 +4,16 @@ package cli
 import (
 	"fmt"
 	"os"
-
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/i18n"
 	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/worker"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +22,7 @@ const (
 	statusColorReset  = "\033[0m"
 	statusColorGreen  = "\033[32m"
 	statusColorYellow = "\033[33m"
+	statusColorRed    = "\033[31m"
 	statusColorGray   = "\033[90m"
 )
 
@@ -24,14 +33,22 @@ var statusCmd = &cobra.Command{
 
 Shows:
 - Count of plans in each queue (pending, current, complete)
-- Current plan name and branch if one is active
-- List of pending plans by name
-- Worktree status (count, paths)`,
+- Current plan name and branch if one is active, with an ETA once
+  enough iterations have been recorded in .ralph/events.jsonl
+- List of pending plans by name, noting snoozed plans' wake times
+  (ralph snooze) and plans held back because they overlap an unmerged
+  plan branch (worker.avoid_overlap)
+- Worktree status (count, paths)
+
+With --label, only plans that have all the given labels are counted and listed.`,
 	RunE: runStatus,
 }
 
+var statusLabels []string
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().StringSliceVar(&statusLabels, "label", nil, "only show plans with this label (repeatable; all must match)")
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -40,12 +57,19 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	// Check if plans directory exists
 	if _, err := os.Stat(plansDir); os.IsNotExist(err) {
-		fmt.Println("No plans directory found. Run 'ralph init' to initialize.")
+		fmt.Println(i18n.T("No plans directory found. Run 'ralph init' to initialize."))
 		return nil
 	}
 
 	queue := plan.NewQueue(plansDir)
-	status, err := queue.Status()
+	queue.Events = events.NewLog(events.Path(filepath.Dir(GetConfigPath())))
+	if manager := planWorktreeManager(filepath.Dir(GetConfigPath())); manager != nil {
+		queue.Live = manager.LivePlan
+	}
+	if db := cliStateDB(queue, queue.Events); db != nil {
+		defer db.Close()
+	}
+	status, err := queue.StatusFor(statusLabels)
 	if err != nil {
 		return fmt.Errorf("getting queue status: %w", err)
 	}
@@ -53,55 +77,90 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Determine if we should use colors
 	useColor := !noColor && isTerminalFd(os.Stdout)
 
+	// label colors a status label when writing to a terminal
+	label := func(color, text string) string {
+		if useColor {
+			return color + i18n.T(text) + statusColorReset
+		}
+		return i18n.T(text)
+	}
+
 	// Print header
-	fmt.Println("Queue Status")
-	fmt.Println("============")
+	printHeading(i18n.T("Queue Status"), "=")
 	fmt.Println()
 
 	// Current plan (green)
 	if status.CurrentPlan != "" {
-		if useColor {
-			fmt.Printf("%sCurrent:%s %s (branch: feat/%s)\n",
-				statusColorGreen, statusColorReset,
-				status.CurrentPlan, status.CurrentPlan)
-		} else {
-			fmt.Printf("Current: %s (branch: feat/%s)\n",
-				status.CurrentPlan, status.CurrentPlan)
+		fmt.Printf("%s %s\n", label(statusColorGreen, "Current:"),
+			i18n.Sprintf("%s (branch: %s)", status.CurrentPlan, status.CurrentBranch))
+		if status.CurrentETA != nil {
+			fmt.Printf("  %s\n", status.CurrentETA)
 		}
 	} else {
-		if useColor {
-			fmt.Printf("%sCurrent:%s (none)\n", statusColorGray, statusColorReset)
-		} else {
-			fmt.Println("Current: (none)")
-		}
+		fmt.Printf("%s %s\n", label(statusColorGray, "Current:"), i18n.T("(none)"))
 	}
 	fmt.Println()
 
 	// Pending plans (yellow)
-	if useColor {
-		fmt.Printf("%sPending:%s %d plan(s)\n", statusColorYellow, statusColorReset, status.PendingCount)
-	} else {
-		fmt.Printf("Pending: %d plan(s)\n", status.PendingCount)
-	}
+	fmt.Printf("%s %s\n", label(statusColorYellow, "Pending:"), i18n.Sprintf("%d plan(s)", status.PendingCount))
 	if len(status.PendingPlans) > 0 {
+		overlaps := statusOverlaps(queue)
 		for _, name := range status.PendingPlans {
-			fmt.Printf("  - %s\n", name)
+			if until, ok := status.SnoozedUntil[name]; ok {
+				fmt.Printf("  - %s: %s\n", name, i18n.Sprintf("snoozed until %s", until.Local().Format("Mon Jan 2 15:04")))
+			} else if o := overlaps[name]; o != nil {
+				fmt.Printf("  - %s: %s\n", name, o)
+			} else {
+				fmt.Printf("  - %s\n", name)
+			}
 		}
 	}
 	fmt.Println()
 
 	// Complete count
-	fmt.Printf("Complete: %d plan(s)\n", status.CompleteCount)
+	fmt.Printf("%s %s\n", i18n.T("Complete:"), i18n.Sprintf("%d plan(s)", status.CompleteCount))
+	if status.AbandonedCount > 0 {
+		fmt.Printf("%s %s\n", i18n.T("Abandoned:"), i18n.Sprintf("%d plan(s)", status.AbandonedCount))
+	}
+	if status.FailedCount > 0 {
+		fmt.Printf("%s %s\n", label(statusColorRed, "Failed:"), i18n.Sprintf("%d plan(s)", status.FailedCount))
+		for _, name := range status.FailedPlans {
+			fmt.Printf("  - %s (ralph retry %s)\n", name, name)
+		}
+	}
 	fmt.Println()
 
 	// Worktree status (placeholder until worktree module is implemented)
-	fmt.Println("Worktrees")
-	fmt.Println("---------")
-	fmt.Printf("  (worktree status not yet implemented)\n")
+	printHeading(i18n.T("Worktrees"), "-")
+	fmt.Printf("  %s\n", i18n.T("(worktree status not yet implemented)"))
 
 	return nil
 }
 
+// printHeading prints a heading underlined to its width.
+func printHeading(title, underline string) {
+	fmt.Println(title)
+	fmt.Println(strings.Repeat(underline, utf8.RuneCountInString(title)))
+}
+
+// statusOverlaps returns the pending plans the worker holds back because of
+// worker.avoid_overlap, or nil if it is off or can't be checked.
+func statusOverlaps(queue *plan.Queue) map[string]*worker.Overlap {
+	cfg, err := config.LoadWithDefaults(GetConfigPath())
+	if err != nil || !cfg.Worker.AvoidOverlap {
+		return nil
+	}
+	g := git.NewGit(".")
+	if _, err := g.RepoRoot(); err != nil {
+		return nil
+	}
+	overlaps, err := worker.FindOverlaps(queue, g, cfg.Git.BaseBranch)
+	if err != nil {
+		return nil
+	}
+	return overlaps
+}
+
 // isTerminalFd checks if the given file is a terminal.
 func isTerminalFd(f *os.File) bool {
 	stat, err := f.Stat()
@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -170,6 +171,37 @@ func TestRunStatus_WithPendingPlans(t *testing.T) {
 	}
 }
 
+func TestRunStatus_WithFailedPlans(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"pending", "current", "complete", "failed"} {
+		os.MkdirAll(filepath.Join(tmpDir, "plans", dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "plans", "failed", "stuck.md"), []byte("# Plan: Stuck\n"), 0644)
+
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	var buf bytes.Buffer
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runStatus(nil, nil)
+
+	w.Close()
+	buf.ReadFrom(r)
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Failed: 1 plan(s)") || !strings.Contains(output, "stuck (ralph retry stuck)") {
+		t.Errorf("expected failed plan listed, got: %s", output)
+	}
+}
+
 func TestRunStatus_OutputFormat(t *testing.T) {
 	// Create temp directory with full queue
 	tmpDir := t.TempDir()
@@ -265,3 +297,54 @@ func TestRunStatus_ExitCode(t *testing.T) {
 		t.Errorf("expected exit code 0 (nil error), got error: %v", err)
 	}
 }
+
+func TestRunStatus_OverlappingPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(tmpDir, "plans", dir), 0755)
+	}
+	os.MkdirAll(filepath.Join(tmpDir, ".ralph"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".ralph", "config.yaml"), []byte("worker:\n  avoid_overlap: true\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "plans", "complete", "auth.md"), []byte("# Plan: Auth\n**Scope:** internal/auth/\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "plans", "pending", "login.md"), []byte("# Plan: Login\n**Scope:** internal/auth/login.go\n"), 0644)
+
+	// feat/auth has a commit that isn't on main yet
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+		{"checkout", "-q", "-b", "feat/auth"},
+		{"commit", "-q", "--allow-empty", "-m", "auth"},
+		{"checkout", "-q", "main"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	oldWd, _ := os.Getwd()
+	defer os.Chdir(oldWd)
+	os.Chdir(tmpDir)
+
+	var buf bytes.Buffer
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runStatus(nil, nil)
+
+	w.Close()
+	buf.ReadFrom(r)
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Errorf("expected no error, got: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "login: waiting for feat/auth to merge (overlaps internal/auth/login.go)") {
+		t.Errorf("expected login to be shown waiting for feat/auth, got: %s", output)
+	}
+}
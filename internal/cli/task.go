@@ -0,0 +1,76 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/spf13/cobra"
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Add and check off plan tasks",
+	Long: `Edit a plan's checkbox tasks without touching the rest of the file.
+
+Plans are found by name in current/, pending/, complete/, and failed/, or
+given as a path to the .md file. Edits to the current plan reach a running
+worker before its next iteration.`,
+}
+
+var taskAddCmd = &cobra.Command{
+	Use:   "add <plan> <text>",
+	Short: "Append an unchecked task to a plan",
+	Long: `Append "- [ ] <text>" to a plan after its last task, at the indentation of
+the last top-level task. A plan without tasks gets it at the end.
+
+Example:
+  ralph task add my-feature "Handle expired tokens"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTaskAdd,
+}
+
+var taskCheckCmd = &cobra.Command{
+	Use:   "check <plan> <index|pattern>",
+	Short: "Check off a plan task",
+	Long: `Check off a task, chosen by its number among all of the plan's tasks
+(subtasks included, counting from 1 in file order) or by text that appears
+in exactly one task, case-insensitively.
+
+Example:
+  ralph task check my-feature 3
+  ralph task check my-feature "expired tokens"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTaskCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskAddCmd)
+	taskCmd.AddCommand(taskCheckCmd)
+}
+
+func runTaskAdd(cmd *cobra.Command, args []string) error {
+	p, err := plan.NewQueue("plans").Find(args[0])
+	if err != nil {
+		return err
+	}
+	if err := plan.AddTask(p, args[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Added task %d to %s: %s\n", plan.CountTotal(p.Tasks), p.Name, args[1])
+	return nil
+}
+
+func runTaskCheck(cmd *cobra.Command, args []string) error {
+	p, err := plan.NewQueue("plans").Find(args[0])
+	if err != nil {
+		return err
+	}
+	task, err := plan.CheckTask(p, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Checked off in %s: %s (%d/%d complete)\n", p.Name, task.Text, plan.CountComplete(p.Tasks), plan.CountTotal(p.Tasks))
+	return nil
+}
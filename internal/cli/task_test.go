@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTaskAddAndCheck(t *testing.T) {
+	defer setupAbandonTest(t)()
+	path := filepath.Join("plans", "pending", "alpha.md")
+	os.WriteFile(path, []byte("# Plan: Alpha\n- [ ] Task 1\n"), 0644)
+
+	var out bytes.Buffer
+	taskAddCmd.SetOut(&out)
+	taskCheckCmd.SetOut(&out)
+	defer taskAddCmd.SetOut(nil)
+	defer taskCheckCmd.SetOut(nil)
+
+	if err := runTaskAdd(taskAddCmd, []string{"alpha", "Task 2"}); err != nil {
+		t.Fatalf("runTaskAdd() error = %v", err)
+	}
+	if err := runTaskCheck(taskCheckCmd, []string{"alpha", "task 2"}); err != nil {
+		t.Fatalf("runTaskCheck() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if want := "# Plan: Alpha\n- [ ] Task 1\n- [x] Task 2\n"; string(data) != want {
+		t.Errorf("plan = %q, want %q", data, want)
+	}
+	if !strings.Contains(out.String(), "Added task 2 to alpha") || !strings.Contains(out.String(), "(1/2 complete)") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	if err := runTaskCheck(taskCheckCmd, []string{"alpha", "task"}); err == nil {
+		t.Error("expected an error for an ambiguous pattern")
+	}
+	if err := runTaskAdd(taskAddCmd, []string{"missing", "x"}); err == nil {
+		t.Error("expected an error for an unknown plan")
+	}
+}
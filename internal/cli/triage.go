@@ -0,0 +1,74 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/triage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	triageFromURL string
+	triageStdin   bool
+)
+
+var triageCmd = &cobra.Command{
+	Use:   "triage --from-url <log url> | --stdin",
+	Short: "Create a pending plan fixing a failing CI run",
+	Long: `Read a failing CI log and create a pending plan scoped to fixing the failure.
+
+The log is fetched from --from-url (a raw log URL) or read from stdin. Color
+codes and CI timestamps are stripped; failing tests are read from "go test"
+and pytest output, and the lines around each failure (failed tests, panics,
+tracebacks, compiler errors, ##[error] annotations) become a log excerpt in
+the plan's Context. The plan reproduces the failure first, then fixes each
+failing test.
+
+Example:
+  gh run view 1234 --log-failed | ralph triage --stdin
+  ralph triage --from-url https://ci.example.com/builds/1234/log.txt`,
+	Args: cobra.NoArgs,
+	RunE: runTriage,
+}
+
+func init() {
+	rootCmd.AddCommand(triageCmd)
+	triageCmd.Flags().StringVar(&triageFromURL, "from-url", "", "URL of the raw CI log")
+	triageCmd.Flags().BoolVar(&triageStdin, "stdin", false, "read the CI log from stdin")
+}
+
+func runTriage(cmd *cobra.Command, args []string) error {
+	if triageStdin == (triageFromURL != "") {
+		return fmt.Errorf("use either --from-url or --stdin")
+	}
+
+	var log, source string
+	if triageStdin {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		log, source = string(data), "CI log from stdin"
+	} else {
+		var err error
+		if log, err = triage.Fetch(triageFromURL); err != nil {
+			return err
+		}
+		source = triageFromURL
+	}
+
+	failure := triage.Extract(log)
+	if len(failure.Tests) == 0 && failure.Excerpt == "" {
+		return fmt.Errorf("no failure found in the CI log")
+	}
+
+	p, err := createIssuePlan(plan.NewQueue("plans"), triage.PlanOptions(failure, source))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s: %d failing tests\n", p.Path, len(failure.Tests))
+	return nil
+}
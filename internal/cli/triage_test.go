@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTriage_Stdin(t *testing.T) {
+	defer setupAbandonTest(t)()
+
+	triageStdin = true
+	defer func() { triageStdin = false }()
+
+	var out bytes.Buffer
+	triageCmd.SetOut(&out)
+	triageCmd.SetIn(strings.NewReader("=== RUN   TestLoad\n--- FAIL: TestLoad (0.00s)\n    load_test.go:9: file missing\nFAIL\n"))
+	defer triageCmd.SetOut(nil)
+	defer triageCmd.SetIn(nil)
+
+	if err := runTriage(triageCmd, nil); err != nil {
+		t.Fatalf("runTriage() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join("plans", "pending", "fix-ci-failure-in-testload.md"))
+	if err != nil {
+		t.Fatalf("plan not written to pending/: %v", err)
+	}
+	for _, want := range []string{"**Labels:** ci", "load_test.go:9: file missing", "### T2: Fix TestLoad"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("plan missing %q:\n%s", want, data)
+		}
+	}
+
+	// A log without a failure creates nothing
+	triageCmd.SetIn(strings.NewReader("ok\tgithub.com/acme/app\t0.1s\n"))
+	if err := runTriage(triageCmd, nil); err == nil || !strings.Contains(err.Error(), "no failure") {
+		t.Errorf("runTriage() error = %v, want no failure found", err)
+	}
+}
+
+func TestRunTriage_NeedsOneSource(t *testing.T) {
+	if err := runTriage(triageCmd, nil); err == nil {
+		t.Error("runTriage() should require --from-url or --stdin")
+	}
+	triageStdin, triageFromURL = true, "https://ci.example.com/log"
+	defer func() { triageStdin, triageFromURL = false, "" }()
+	if err := runTriage(triageCmd, nil); err == nil {
+		t.Error("runTriage() should reject both --from-url and --stdin")
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -13,18 +14,29 @@ var (
 	BuildDate = "unknown"
 )
 
+var versionCheck bool
+
 // versionCmd represents the version command.
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version information",
-	Long:  `Display the version, git commit, and build date of this ralph binary.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("ralph version %s\n", Version)
-		fmt.Printf("  commit:  %s\n", Commit)
-		fmt.Printf("  built:   %s\n", BuildDate)
+	Long: `Display the version, git commit, and build date of this ralph binary.
+
+With --check, also report whether a newer release is available.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "ralph version %s\n", Version)
+		fmt.Fprintf(out, "  commit:  %s\n", Commit)
+		fmt.Fprintf(out, "  built:   %s\n", BuildDate)
+
+		if versionCheck {
+			return printUpdateCheck(context.Background(), out)
+		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "check GitHub for a newer release")
 }
@@ -5,12 +5,11 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
 	"time"
 
 	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
@@ -22,11 +21,17 @@ import (
 )
 
 var (
-	workerOnce        bool
-	workerPRMode      bool
-	workerMergeMode   bool
-	workerInterval    time.Duration
-	workerMaxIter     int
+	workerOnce         bool
+	workerPRMode       bool
+	workerMergeMode    bool
+	workerInterval     time.Duration
+	workerMaxIter      int
+	workerDrainTimeout time.Duration
+	workerNoWatch      bool
+	workerLabels       []string
+	workerInclude      []string
+	workerExclude      []string
+	workerForce        bool
 )
 
 var workerCmd = &cobra.Command{
@@ -43,12 +48,32 @@ The worker will:
 6. Repeat for the next pending plan
 
 With --once, it processes a single plan and exits.
-Without --once, it runs continuously, polling for new plans.
+Without --once, it runs continuously. Plans dropped into pending/ are picked
+up within a second; the queue is also polled every --interval as a fallback.
+Use --no-watch on network filesystems where change notifications are unreliable.
+
+Shutdown is two-stage: the first Ctrl+C (or SIGTERM) lets the in-flight
+iteration finish and sync its state, then the worker exits. A second signal
+stops immediately and writes .ralph/recovery.json; the next run resumes from
+the iteration checkpoint. --drain-timeout bounds the graceful wait.
+
+With --label, the worker only picks plans whose **Labels:** header has all
+the given labels, so separate workers can handle, say, docs and backend plans.
+--include and --exclude (or worker.include / worker.exclude) do the same by
+plan name glob; the flags replace the configured patterns.
+
+At startup the worker checks the main worktree (worker.main_repo_check): it
+should be on git.base_branch, not behind its upstream, and have no uncommitted
+changes under plans/ that syncing plan files back could overwrite. Problems are
+logged; with main_repo_check: fail the worker refuses to start unless --force.
 
 Example:
   ralph worker           # continuous mode
   ralph worker --once    # single plan mode
-  ralph worker --merge   # merge directly instead of creating PR`,
+  ralph worker --merge   # merge directly instead of creating PR
+  ralph worker --label docs
+  ralph worker --include 'infra-*'
+  ralph worker --exclude 'infra-*'`,
 	RunE: runWorker,
 }
 
@@ -60,6 +85,12 @@ func init() {
 	workerCmd.Flags().BoolVar(&workerMergeMode, "merge", false, "use merge mode for completion")
 	workerCmd.Flags().DurationVar(&workerInterval, "interval", worker.DefaultPollInterval, "poll interval when queue is empty")
 	workerCmd.Flags().IntVar(&workerMaxIter, "max", worker.DefaultMaxIterations, "maximum iterations per plan")
+	workerCmd.Flags().BoolVar(&workerNoWatch, "no-watch", false, "don't watch plans/pending for new plans; only poll (for network filesystems)")
+	workerCmd.Flags().DurationVar(&workerDrainTimeout, "drain-timeout", 0, "on shutdown, wait at most this long for the current iteration (0 = no limit)")
+	workerCmd.Flags().StringSliceVar(&workerLabels, "label", nil, "only process plans with this label (repeatable; all must match)")
+	workerCmd.Flags().StringSliceVar(&workerInclude, "include", nil, "only process plans whose names match this glob (repeatable)")
+	workerCmd.Flags().StringSliceVar(&workerExclude, "exclude", nil, "never process plans whose names match this glob (repeatable)")
+	workerCmd.Flags().BoolVar(&workerForce, "force", false, "start even if the main worktree check fails")
 }
 
 func runWorker(cmd *cobra.Command, args []string) error {
@@ -82,6 +113,17 @@ func runWorker(cmd *cobra.Command, args []string) error {
 		completionMode = cfg.Completion.Mode
 	}
 
+	// Plan name filters from flags replace the configured ones
+	if cmd.Flags().Changed("include") {
+		cfg.Worker.Include = workerInclude
+	}
+	if cmd.Flags().Changed("exclude") {
+		cfg.Worker.Exclude = workerExclude
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid plan filter: %w", err)
+	}
+
 	// Get working directory (main worktree)
 	mainWorktreePath, err := os.Getwd()
 	if err != nil {
@@ -97,6 +139,16 @@ func runWorker(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("not in a git repository: %w", err)
 	}
 
+	// Mask secrets before anything reaches logs or Slack
+	if err := configureRedaction(cfg, repoRoot); err != nil {
+		return fmt.Errorf("configuring redaction: %w", err)
+	}
+
+	// Refuse to sync plan files into a main worktree holding someone's work
+	if err := worker.EnforceMainRepoCheck(g, cfg, workerForce); err != nil {
+		return err
+	}
+
 	// Set up paths
 	configDir := filepath.Join(repoRoot, ".ralph")
 	plansDir := filepath.Join(repoRoot, "plans")
@@ -118,19 +170,32 @@ func runWorker(cmd *cobra.Command, args []string) error {
 
 	// Initialize queue
 	queue := plan.NewQueue(plansDir)
+	queue.Events = events.NewLog(events.Path(configDir))
+	queue.Audit = newAuditLog(cfg, configDir, "worker")
+	if err := queue.Audit.Config(cfg); err != nil {
+		log.Warn("Failed to record config in the audit log: %v", err)
+	}
+	if db := openStateDB(cfg, configDir, queue, queue.Events); db != nil {
+		defer db.Close()
+	}
 
 	// Initialize worktree manager
 	wtManager, err := worktree.NewManager(g, worktreesDir)
 	if err != nil {
 		return fmt.Errorf("initializing worktree manager: %w", err)
 	}
+	queue.Live = wtManager.LivePlan
 
 	// Initialize prompt builder
 	promptsDir := filepath.Join(configDir, "prompts")
 	promptBuilder := prompt.NewBuilder(cfg, configDir, promptsDir)
 
-	// Create Claude runner
-	claudeRunner := runner.NewCLIRunner()
+	// Create Claude runner, checking the claude install before any plan is activated
+	claudeRunner, err := worker.NewClaudeRunner(cmd.Context(), cfg, configDir)
+	if err != nil {
+		return err
+	}
+	worker.ApplyPresetEnv(claudeRunner, cfg, mainWorktreePath)
 
 	// Create worker
 	w := worker.NewWorker(worker.WorkerConfig{
@@ -143,44 +208,47 @@ func runWorker(cmd *cobra.Command, args []string) error {
 		Runner:           claudeRunner,
 		PromptBuilder:    promptBuilder,
 		PollInterval:     workerInterval,
+		NoWatch:          workerNoWatch,
 		MaxIterations:    workerMaxIter,
 		CompletionMode:   completionMode,
-		OnPlanStart: func(p *plan.Plan) {
-			log.Success("=== Starting plan: %s ===", p.Name)
-			log.Info("Branch: %s", p.Branch)
-		},
-		OnPlanComplete: func(p *plan.Plan, result *runner.LoopResult) {
-			log.Success("=== Plan complete: %s ===", p.Name)
-			log.Info("Iterations: %d", result.Iterations)
-			if result.Completed {
-				log.Success("Verified complete!")
-			}
-		},
-		OnPlanError: func(p *plan.Plan, err error) {
-			log.Error("=== Plan error: %s ===", p.Name)
-			log.Error("Error: %v", err)
-		},
-		OnBlocker: func(p *plan.Plan, blocker *runner.Blocker) {
-			log.Warn("=== Blocker detected in %s ===", p.Name)
-			log.Warn("Description: %s", blocker.Description)
-			if blocker.Action != "" {
-				log.Info("Action required: %s", blocker.Action)
-			}
-		},
+		DrainTimeout:     workerDrainTimeout,
+		Labels:           workerLabels,
+		Observers: []worker.Observer{worker.Callbacks{
+			OnPlanStart: func(p *plan.Plan) {
+				log.Success("=== Starting plan: %s ===", p.Name)
+				log.Info("Branch: %s", p.Branch)
+			},
+			OnPlanComplete: func(p *plan.Plan, result *runner.LoopResult) {
+				log.Success("=== Plan complete: %s ===", p.Name)
+				log.Info("Iterations: %d", result.Iterations)
+				if result.Completed {
+					log.Success("Verified complete!")
+				}
+			},
+			OnPlanError: func(p *plan.Plan, err error) {
+				log.Error("=== Plan error: %s ===", p.Name)
+				log.Error("Error: %v", err)
+			},
+			OnBlocker: func(p *plan.Plan, blocker *runner.Blocker) {
+				log.Warn("=== Blocker detected in %s ===", p.Name)
+				log.Warn("Description: %s", blocker.Description)
+				if blocker.Action != "" {
+					log.Info("Action required: %s", blocker.Action)
+				}
+			},
+		}},
 	})
 
-	// Set up signal handling for graceful shutdown
+	// Set up two-stage signal handling: drain first, then stop immediately
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	stopSignals := w.HandleSignals(cancel)
+	defer stopSignals()
 
-	go func() {
-		sig := <-sigCh
-		log.Warn("Received signal %v, stopping after current iteration...", sig)
-		cancel()
-	}()
+	// Set up Slack notifications and the Socket Mode bot (replies and /ralph commands)
+	cleanup := w.SetupNotifications(ctx)
+	defer cleanup()
 
 	// Run the worker
 	log.Info("Worker starting...")
@@ -196,7 +264,11 @@ func runWorker(cmd *cobra.Command, args []string) error {
 				log.Info("No pending plans in queue")
 				return nil
 			}
-			if err == context.Canceled {
+			if err == worker.ErrPaused {
+				log.Info("Worker is paused (resume with /ralph resume)")
+				return nil
+			}
+			if err == context.Canceled || err == worker.ErrInterrupted {
 				log.Warn("Worker interrupted")
 				return nil
 			}
@@ -208,7 +280,7 @@ func runWorker(cmd *cobra.Command, args []string) error {
 	// Run continuously
 	err = w.Run(ctx)
 	if err != nil {
-		if err == context.Canceled {
+		if err == context.Canceled || err == worker.ErrInterrupted {
 			log.Info("Worker stopped")
 			return nil
 		}
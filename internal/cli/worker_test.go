@@ -139,9 +139,10 @@ func TestWorkerCmd_OnceMode_EmptyQueue(t *testing.T) {
 	os.MkdirAll(filepath.Join(plansDir, "current"), 0755)
 	os.MkdirAll(filepath.Join(plansDir, "complete"), 0755)
 
-	// Create .ralph directory
+	// Create .ralph directory, skipping the claude preflight
 	ralphDir := filepath.Join(tempDir, ".ralph")
 	os.MkdirAll(ralphDir, 0755)
+	os.WriteFile(filepath.Join(ralphDir, "config.yaml"), []byte("runner:\n  skip_preflight: true\n"), 0644)
 
 	// Change to temp directory
 	if err := os.Chdir(tempDir); err != nil {
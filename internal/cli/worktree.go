@@ -0,0 +1,109 @@
+// Package cli provides the command-line interface for ralph.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	worktreeRepairCheck bool
+	worktreeRepairForce bool
+)
+
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "Inspect and repair plan worktrees",
+}
+
+var worktreeRepairCmd = &cobra.Command{
+	Use:   "repair <plan>",
+	Short: "Detect and fix a broken plan worktree",
+	Long: `Check a plan's worktree for problems that make git fail, and fix them.
+
+Detected problems:
+  - a missing or broken .git file: relinked with git worktree repair, or the
+    directory is moved aside to <worktree>.broken-<time> (keeping any
+    uncommitted work) and the worktree is re-added from the plan branch
+  - stale worktree entries whose directories are gone: pruned
+  - lock files left by a crashed git command: removed if older than a
+    minute (any age with --force)
+  - a detached HEAD: the plan branch is checked out, unless HEAD has
+    commits the branch doesn't
+  - a merge, rebase, am, cherry-pick, or revert left half-done: aborted,
+    restoring the branch to where it was before the operation started
+
+The worker runs the same check before each iteration, leaving interrupted
+operations alone when worktree.interrupted_ops is "block".
+
+Example:
+  ralph worktree repair my-feature
+  ralph worktree repair my-feature --check`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWorktreeRepair,
+}
+
+func init() {
+	rootCmd.AddCommand(worktreeCmd)
+	worktreeCmd.AddCommand(worktreeRepairCmd)
+	worktreeRepairCmd.Flags().BoolVar(&worktreeRepairCheck, "check", false, "only report problems, don't fix them")
+	worktreeRepairCmd.Flags().BoolVar(&worktreeRepairForce, "force", false, "remove lock files regardless of age")
+}
+
+func runWorktreeRepair(cmd *cobra.Command, args []string) error {
+	queue := plan.NewQueue("plans")
+	p, err := queue.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	g := git.NewGit(".")
+	if _, err := g.RepoRoot(); err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	manager, err := worktree.NewManager(g, filepath.Join(filepath.Dir(GetConfigPath()), "worktrees"))
+	if err != nil {
+		return fmt.Errorf("creating worktree manager: %w", err)
+	}
+
+	var issues []worktree.Issue
+	if worktreeRepairCheck {
+		issues, err = manager.Check(p)
+	} else {
+		lockAge := worktree.StaleLockAge
+		if worktreeRepairForce {
+			lockAge = 0
+		}
+		issues, err = manager.Repair(p, lockAge, true)
+	}
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(issues) == 0 {
+		fmt.Fprintf(out, "Worktree for %s is healthy\n", p.Name)
+		return nil
+	}
+	for _, issue := range issues {
+		mark := "✗"
+		if issue.Repaired {
+			mark = "✓"
+		}
+		fmt.Fprintf(out, "  %s %s\n", mark, issue)
+	}
+
+	if worktreeRepairCheck {
+		return fmt.Errorf("%d worktree problem(s) found; run without --check to repair", len(issues))
+	}
+	if unrepaired := worktree.Unrepaired(issues); len(unrepaired) > 0 {
+		return fmt.Errorf("%d worktree problem(s) need a manual fix", len(unrepaired))
+	}
+	fmt.Fprintf(out, "Repaired %d worktree problem(s)\n", len(issues))
+	return nil
+}
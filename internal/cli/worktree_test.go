@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+func TestRunWorktreeRepair(t *testing.T) {
+	defer setupAbandonTest(t)()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "--allow-empty", "-m", "Initial commit"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join("plans", "current", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+
+	var out bytes.Buffer
+	worktreeRepairCmd.SetOut(&out)
+	defer worktreeRepairCmd.SetOut(nil)
+
+	if err := runWorktreeRepair(worktreeRepairCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runWorktreeRepair() without a worktree error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Worktree for alpha is healthy") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	manager, err := worktree.NewManager(git.NewGit("."), filepath.Join(".ralph", "worktrees"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := plan.NewQueue("plans").Find("alpha")
+	wt, err := manager.Create(p)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	os.Remove(filepath.Join(wt.Path, ".git"))
+
+	worktreeRepairCheck = true
+	out.Reset()
+	if err := runWorktreeRepair(worktreeRepairCmd, []string{"alpha"}); err == nil {
+		t.Error("--check should fail when problems are found")
+	}
+	worktreeRepairCheck = false
+	if !strings.Contains(out.String(), "✗ broken_link") || git.IsLinkedWorktree(wt.Path) {
+		t.Errorf("--check output = %q, want an unrepaired broken link", out.String())
+	}
+
+	out.Reset()
+	if err := runWorktreeRepair(worktreeRepairCmd, []string{"alpha"}); err != nil {
+		t.Fatalf("runWorktreeRepair() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "✓ broken_link") || !strings.Contains(out.String(), "Repaired 1 worktree problem(s)") {
+		t.Errorf("output = %q", out.String())
+	}
+	if !git.IsLinkedWorktree(wt.Path) {
+		t.Error("worktree should be linked after repair")
+	}
+}
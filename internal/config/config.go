@@ -3,9 +3,16 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/i18n"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,19 +24,168 @@ type Config struct {
 	Slack      SlackConfig      `yaml:"slack"`
 	Worktree   WorktreeConfig   `yaml:"worktree"`
 	Completion CompletionConfig `yaml:"completion"`
+	Stages     []StageConfig    `yaml:"stages"`
+	Hooks      HooksConfig      `yaml:"hooks"`
+	Redact     RedactConfig     `yaml:"redact"`
+	Worker     WorkerConfig     `yaml:"worker"`
+	Runner     RunnerConfig     `yaml:"runner"`
+	Serve      ServeConfig      `yaml:"serve"`
+	Jira       JiraConfig       `yaml:"jira"`
+	Linear     LinearConfig     `yaml:"linear"`
+	GitHub     GitHubConfig     `yaml:"github"`
+	Blockers   BlockersConfig   `yaml:"blockers"`
+	Ask        AskConfig        `yaml:"ask"`
+	TDD        TDDConfig        `yaml:"tdd"`
+	Coverage   CoverageConfig   `yaml:"coverage"`
+	Bench      BenchConfig      `yaml:"bench"`
+	Flaky      FlakyConfig      `yaml:"flaky"`
+	Audit      AuditConfig      `yaml:"audit"`
+	Lessons    LessonsConfig    `yaml:"lessons"`
+	Knowledge  KnowledgeConfig  `yaml:"knowledge"`
+	State      StateConfig      `yaml:"state"`
+	GC         GCConfig         `yaml:"gc"`
+	Feedback   FeedbackConfig   `yaml:"feedback"`
 }
 
 // ProjectConfig contains project identification settings.
 type ProjectConfig struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
+
+	// Locale is the language of Slack notifications and CLI output, e.g.
+	// "de" (empty = the environment's locale; RALPH_LOCALE overrides it).
+	Locale string `yaml:"locale"`
 }
 
 // GitConfig contains git-related settings.
 type GitConfig struct {
 	BaseBranch string `yaml:"base_branch"`
+
+	// BranchTemplate names plan branches, as a Go template over
+	// git.BranchNameData, e.g. "ralph/{{.Date}}/{{.Name}}" (empty =
+	// "feat/{{.Name}}").
+	BranchTemplate string `yaml:"branch_template"`
+
+	// DeleteBranchOnMerge deletes a plan's branch on origin, and prunes the
+	// local refs, once it's merged: right after a merge-mode merge, or when
+	// the worker sees a pr-mode pull request merged.
+	DeleteBranchOnMerge bool `yaml:"delete_branch_on_merge"`
+
+	// MaxDiffFiles caps the files one iteration may change before ralph
+	// stops committing automatically (0 = no limit).
+	MaxDiffFiles int `yaml:"max_diff_files"`
+
+	// MaxDiffLines caps the lines (added + deleted) one iteration may change
+	// before ralph stops committing automatically (0 = no limit).
+	MaxDiffLines int `yaml:"max_diff_lines"`
+
+	// DiffLimitAction is what happens when an iteration exceeds a diff limit
+	// (see DiffLimit* constants; empty = split).
+	DiffLimitAction string `yaml:"diff_limit_action"`
+
+	// MaxFileSize is the largest file the loop commits, e.g. "5MB"
+	// ("0" = no limit). Larger files are unstaged and gitignored.
+	MaxFileSize string `yaml:"max_file_size"`
+
+	// DenyPatterns are files the loop never commits: a glob matched against
+	// the file name ("*.zip"), a path glob ("assets/*.psd"), or a directory ("dist/").
+	DenyPatterns []string `yaml:"deny_patterns"`
+
+	// RecentCommits is how many recent commits on the base branch and the
+	// plan branch are summarized in each iteration's prompt (0 = none).
+	RecentCommits int `yaml:"recent_commits"`
+
+	// StateBranch mirrors the plans directory to this branch (e.g.
+	// "ralph-state") whenever the worker activates, syncs progress for, or
+	// finishes a plan, so the queue can be followed on the git host (empty =
+	// off). The branch is committed to directly and never checked out.
+	StateBranch string `yaml:"state_branch"`
+
+	// StatePush pushes the state branch to origin after each mirror commit.
+	StatePush bool `yaml:"state_push"`
+
+	// Provider is the git host origin is on, for links to its commits,
+	// branches, and pull requests: "github", "gitlab", or "bitbucket"
+	// (empty = detect from the host name, falling back to GitHub). Set it
+	// for a self-hosted GitLab or Bitbucket whose name doesn't say so.
+	Provider string `yaml:"provider"`
+
+	// PR configures the pull requests opened in pr completion mode.
+	PR PRConfig `yaml:"pr"`
 }
 
+// PRConfig contains the settings of the pull requests ralph opens. Plans can
+// override them with **Reviewers:**, **Assignees:**, **PR Labels:**, and
+// **Draft:** header lines.
+type PRConfig struct {
+	// Reviewers are GitHub logins or org/team slugs asked to review.
+	Reviewers []string `yaml:"reviewers"`
+
+	// Assignees are GitHub logins the pull request is assigned to ("@me"
+	// for the gh user).
+	Assignees []string `yaml:"assignees"`
+
+	// Labels are GitHub labels added to the pull request; they must exist
+	// in the repository.
+	Labels []string `yaml:"labels"`
+
+	// Draft opens pull requests as drafts.
+	Draft bool `yaml:"draft"`
+
+	// CodeOwners also asks the CODEOWNERS owners of the files the plan
+	// changed to review.
+	CodeOwners bool `yaml:"codeowners"`
+
+	// IgnoreTemplate uses ralph's standard pull request body even if the
+	// repository has a pull request template.
+	IgnoreTemplate bool `yaml:"ignore_template"`
+}
+
+// MaxFileSizeBytes returns git.max_file_size in bytes (0 = no limit).
+// Invalid sizes are rejected by Validate and treated as no limit here.
+func (g GitConfig) MaxFileSizeBytes() int64 {
+	n, _ := ParseSize(g.MaxFileSize)
+	return n
+}
+
+// sizeRegex matches a size like "5MB", "512 KB", or "1048576".
+var sizeRegex = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d+)?)\s*(b|kb|mb|gb)?\s*$`)
+
+// ParseSize parses a size like "5MB" or "512KB" into bytes, with KB, MB,
+// and GB as powers of 1024. A bare number is bytes; "" and "0" are 0.
+func ParseSize(s string) (int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	m := sizeRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500KB or 5MB)", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	switch strings.ToLower(m[2]) {
+	case "kb":
+		n *= 1 << 10
+	case "mb":
+		n *= 1 << 20
+	case "gb":
+		n *= 1 << 30
+	}
+	return int64(n), nil
+}
+
+// Diff limit actions for git.diff_limit_action.
+const (
+	// DiffLimitSplit leaves the changes uncommitted and asks the agent to
+	// commit them in smaller chunks.
+	DiffLimitSplit = "split"
+
+	// DiffLimitBlock leaves the changes uncommitted and raises a blocker.
+	DiffLimitBlock = "block"
+)
+
 // CommandsConfig contains project command configurations.
 type CommandsConfig struct {
 	Test  string `yaml:"test"`
@@ -41,6 +197,7 @@ type CommandsConfig struct {
 // SlackConfig contains Slack notification settings.
 type SlackConfig struct {
 	WebhookURL      string `yaml:"webhook_url"`
+	WebhookSecret   string `yaml:"webhook_secret"`
 	Channel         string `yaml:"channel"`
 	BotToken        string `yaml:"bot_token"`
 	AppToken        string `yaml:"app_token"`
@@ -50,20 +207,659 @@ type SlackConfig struct {
 	NotifyIteration bool   `yaml:"notify_iteration"`
 	NotifyError     bool   `yaml:"notify_error"`
 	NotifyBlocker   bool   `yaml:"notify_blocker"`
+
+	// ThreadRetentionDays prunes tracked threads for plans completed longer ago.
+	ThreadRetentionDays int `yaml:"thread_retention_days"`
+
+	// MaxThreads caps the number of entries in slack_threads.json.
+	MaxThreads int `yaml:"max_threads"`
+
+	// Digest replaces per-event messages with a periodic summary ("hourly" or "daily").
+	Digest string `yaml:"digest"`
+
+	// Roles maps Slack user IDs to roles (viewer, operator, admin) that
+	// authorize bot commands, buttons, plan requests, and thread replies.
+	// Empty lets everyone in the channel do everything.
+	Roles map[string]string `yaml:"roles"`
+
+	// DefaultRole is the role of users not in Roles. Empty gives them no access.
+	DefaultRole string `yaml:"default_role"`
+
+	// RateLimit caps notifications so a burst of failures doesn't flood the channel.
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// PlainText sends notifications as plain text instead of Block Kit, for
+	// clients and screen readers that render blocks poorly.
+	PlainText bool `yaml:"plain_text"`
+
+	// DiffPreview posts each iteration's changes in the plan's thread.
+	DiffPreview DiffPreviewConfig `yaml:"diff_preview"`
+}
+
+// DiffPreviewConfig posts each iteration's committed changes as a reply in
+// the plan's Slack thread (bot only), so reviewers can follow along.
+type DiffPreviewConfig struct {
+	Mode     string `yaml:"mode"`      // "diff" (truncated unified diff), "link" (push and link the commit), or "" (off)
+	MaxLines int    `yaml:"max_lines"` // diff lines shown before truncating (default 40, 0 = no limit)
+	MaxFiles int    `yaml:"max_files"` // iterations touching more files get the file summary only (default 20, 0 = no limit)
 }
 
+// Diff preview modes for slack.diff_preview.mode.
+const (
+	// DiffPreviewDiff posts the iteration's unified diff, truncated.
+	DiffPreviewDiff = "diff"
+
+	// DiffPreviewLink pushes the plan branch and posts a link to the
+	// iteration's commit on the remote.
+	DiffPreviewLink = "link"
+)
+
+// RateLimitConfig limits Slack notifications per time window. Notifications
+// over a limit, and repeats of one already sent in the window, are held back
+// and sent as one summary per window.
+type RateLimitConfig struct {
+	Window  string `yaml:"window"`   // e.g. "10m" (empty = no rate limits)
+	PerPlan int    `yaml:"per_plan"` // notifications per plan per window (default 5, 0 = unlimited)
+	Global  int    `yaml:"global"`   // notifications across all plans per window (default 10, 0 = unlimited)
+}
+
+// Slack roles, from least to most privileged.
+const (
+	RoleViewer   = "viewer"   // status and the Home tab
+	RoleOperator = "operator" // queue plans, pause/resume/skip, thread feedback
+	RoleAdmin    = "admin"    // approve and reject plans
+)
+
 // WorktreeConfig contains worktree initialization settings.
 type WorktreeConfig struct {
-	CopyEnvFiles string `yaml:"copy_env_files"`
-	InitCommands string `yaml:"init_commands"`
+	CopyEnvFiles  string `yaml:"copy_env_files"`
+	InitCommands  string `yaml:"init_commands"`
+	CompleteHooks string `yaml:"complete_hooks"` // commands run in the worktree after completion, before cleanup
+	Submodules    bool   `yaml:"submodules"`     // run `git submodule update --init --recursive` in new worktrees
+	LFS           bool   `yaml:"lfs"`            // run `git lfs pull` in new worktrees
+
+	// Preset is a built-in setup for a language ecosystem (go, node, python,
+	// rust) that installs dependencies and points tools at shared caches.
+	// Empty detects it from go.mod, package.json, pyproject.toml, ...
+	Preset string `yaml:"preset"`
+
+	// InterruptedOps is what the worker does with a merge, rebase, or
+	// cherry-pick left half-done in a plan's worktree (see InterruptedOps*
+	// constants). Defaults to "abort".
+	InterruptedOps string `yaml:"interrupted_ops"`
+
+	// ScratchDir holds each plan's scratch directory for build artifacts
+	// and temp files, exported to claude as RALPH_SCRATCH and removed when
+	// the plan completes or is abandoned. Relative paths are relative to the
+	// repository root; empty uses a directory under the system temp
+	// directory, outside the repository.
+	ScratchDir string `yaml:"scratch_dir"`
 }
 
+// Interrupted operation policies.
+const (
+	// InterruptedOpsAbort aborts the operation and carries on.
+	InterruptedOpsAbort = "abort"
+
+	// InterruptedOpsBlock leaves the operation in place and raises a
+	// blocker for a human to finish or abort it.
+	InterruptedOpsBlock = "block"
+)
+
+// Worktree presets.
+const (
+	PresetGo     = "go"
+	PresetNode   = "node"
+	PresetPython = "python"
+	PresetRust   = "rust"
+)
+
 // CompletionConfig contains plan completion settings.
 type CompletionConfig struct {
 	Mode              string `yaml:"mode"`               // "pr" or "merge"
 	VerificationModel string `yaml:"verification_model"` // model for plan verification (default: claude-3-5-haiku-latest)
+
+	// SecondVerifier, if set, checks every completion claim as well; both
+	// verifiers must agree the plan is complete.
+	SecondVerifier VerifierConfig `yaml:"second_verifier"`
+
+	// Gates runs commands.test and commands.lint in the worktree when a plan
+	// completes and reports the results in the completion notification.
+	Gates bool `yaml:"gates"`
+
+	// RequireApproval waits for a human to approve the plan (Slack buttons or
+	// `ralph approve`) before opening the pull request or merging.
+	RequireApproval bool `yaml:"require_approval"`
+
+	// ApprovalTimeout is how long to wait for approval (e.g. "24h") before the
+	// plan is moved to failed/.
+	ApprovalTimeout string `yaml:"approval_timeout"`
+
+	// AutoMerge enables GitHub auto-merge on the pull request once it's
+	// opened, with this strategy (see AutoMerge* constants; empty = off).
+	AutoMerge string `yaml:"auto_merge"`
+}
+
+// Auto-merge strategies for completion.auto_merge.
+const (
+	// AutoMergeSquash squashes the pull request into one commit.
+	AutoMergeSquash = "squash"
+
+	// AutoMergeRebase rebases the pull request's commits onto the base branch.
+	AutoMergeRebase = "rebase"
+
+	// AutoMergeMerge merges the pull request with a merge commit.
+	AutoMergeMerge = "merge"
+
+	// AutoMergeQueue adds the pull request to the base branch's merge queue,
+	// which decides how it's merged.
+	AutoMergeQueue = "queue"
+)
+
+// AutoMergeStrategies are the valid values of completion.auto_merge.
+var AutoMergeStrategies = []string{AutoMergeSquash, AutoMergeRebase, AutoMergeMerge, AutoMergeQueue}
+
+// VerifierConfig is a verification model and, optionally, the claude
+// executable it runs with, e.g. a separate install or wrapper script.
+type VerifierConfig struct {
+	// Model is the verification model (empty = the default verification model).
+	Model string `yaml:"model"`
+
+	// ClaudePath is the claude executable (empty = runner.claude_path).
+	ClaudePath string `yaml:"claude_path"`
+}
+
+// Enabled returns true if a verifier is configured.
+func (v VerifierConfig) Enabled() bool {
+	return v.Model != "" || v.ClaudePath != ""
+}
+
+// Stage completion criteria.
+const (
+	// StageCompletionMarker ends a stage when the agent outputs the completion marker.
+	StageCompletionMarker = "marker"
+
+	// StageCompletionTasks ends a stage when every task in the plan is checked off.
+	StageCompletionTasks = "tasks"
+
+	// StageCompletionVerify ends a stage when the completion marker passes verification.
+	StageCompletionVerify = "verify"
+)
+
+// StageCompletions are the valid values of stages[].completion.
+var StageCompletions = []string{StageCompletionMarker, StageCompletionTasks, StageCompletionVerify}
+
+// StageConfig is one stage of the per-plan pipeline, e.g. plan → implement → test → review.
+// With no stages configured, a plan runs as a single stage using prompt.md.
+type StageConfig struct {
+	// Name identifies the stage in logs, notifications, and the execution context.
+	Name string `yaml:"name"`
+
+	// Prompt is the stage's prompt template (default: prompt.md).
+	// Relative paths are looked up in the prompts directory, then .ralph/.
+	Prompt string `yaml:"prompt"`
+
+	// Goal tells the agent what the stage should achieve.
+	Goal string `yaml:"goal"`
+
+	// Completion is "marker", "tasks", or "verify".
+	// Default: "verify" for the last stage, "marker" for the others.
+	Completion string `yaml:"completion"`
+
+	// MaxIterations fails the plan if the stage isn't done within this many
+	// iterations (0 = limited only by the plan's max iterations).
+	MaxIterations int `yaml:"max_iterations"`
+
+	// Model is the model for the stage's iterations, e.g. "haiku" for a review
+	// stage. Overrides runner.model and the plan's **Model:** header.
+	Model string `yaml:"model"`
 }
 
+// CompletionFor returns the stage's completion criterion, applying the default
+// for its position. last reports whether it is the final stage.
+func (s StageConfig) CompletionFor(last bool) string {
+	if s.Completion != "" {
+		return s.Completion
+	}
+	if last {
+		return StageCompletionVerify
+	}
+	return StageCompletionMarker
+}
+
+// HooksConfig contains global lifecycle hooks.
+// Each entry is either a shell command or an http(s) URL that receives a JSON payload.
+type HooksConfig struct {
+	OnPlanComplete []string `yaml:"on_plan_complete"`
+	OnPlanError    []string `yaml:"on_plan_error"`
+
+	// PreIteration is a shell command run in the worktree before each prompt.
+	PreIteration string `yaml:"pre_iteration"`
+
+	// CapturePreIteration includes the pre_iteration output in the prompt.
+	CapturePreIteration bool `yaml:"capture_pre_iteration"`
+}
+
+// RedactConfig contains secret masking settings.
+// Slack credentials and values from worktree.copy_env_files are always masked.
+type RedactConfig struct {
+	// Patterns are extra regular expressions whose matches are masked.
+	Patterns []string `yaml:"patterns"`
+}
+
+// WorkerConfig contains queue worker settings.
+type WorkerConfig struct {
+	// PlanRetries is how many times a plan that fails for a transient reason
+	// (rate limits, network errors, timeouts) is requeued before it moves to failed/.
+	PlanRetries int `yaml:"plan_retries"`
+
+	// RetryBackoff is the delay before the first retry (e.g. "5m"); it doubles
+	// with each further retry.
+	RetryBackoff string `yaml:"retry_backoff"`
+
+	// MaxPlanDuration is how long a plan may run in total (e.g. "6h")
+	// before it is stopped and moved to failed/; empty means no limit. A
+	// plan's **Max-Duration:** header overrides it.
+	MaxPlanDuration string `yaml:"max_plan_duration"`
+
+	// AvoidOverlap holds back a pending plan whose likely paths overlap an
+	// unmerged plan branch until that branch is merged, to avoid conflicts.
+	AvoidOverlap bool `yaml:"avoid_overlap"`
+
+	// Include limits the worker to plans whose names match one of these
+	// globs (e.g. "infra-*"); empty means every plan.
+	Include []string `yaml:"include"`
+
+	// Exclude skips plans whose names match one of these globs, even if
+	// they match Include.
+	Exclude []string `yaml:"exclude"`
+
+	// MainRepoCheck is what the worker does at startup when the main
+	// worktree is on a branch other than git.base_branch, behind its
+	// upstream, or has uncommitted changes under plans/ (see MainRepoCheck*
+	// constants; empty = warn). `ralph worker --force` skips a failure.
+	MainRepoCheck string `yaml:"main_repo_check"`
+}
+
+// Main worktree check modes for worker.main_repo_check.
+const (
+	// MainRepoCheckOff skips the check.
+	MainRepoCheckOff = "off"
+
+	// MainRepoCheckWarn logs problems and starts anyway.
+	MainRepoCheckWarn = "warn"
+
+	// MainRepoCheckFail refuses to start unless --force is given.
+	MainRepoCheckFail = "fail"
+)
+
+// RunnerConfig contains claude CLI settings.
+type RunnerConfig struct {
+	// ClaudePath is the claude executable (default: "claude" on PATH).
+	ClaudePath string `yaml:"claude_path"`
+
+	// MinClaudeVersion is the oldest supported claude CLI version, inclusive.
+	MinClaudeVersion string `yaml:"min_claude_version"`
+
+	// MaxClaudeVersion pins the claude CLI below this version, exclusive (empty = no limit).
+	MaxClaudeVersion string `yaml:"max_claude_version"`
+
+	// Model is the model for plan iterations, e.g. "sonnet" (empty = claude's default).
+	// A plan's **Model:** header and a stage's model override it.
+	Model string `yaml:"model"`
+
+	// SkipPreflight disables the claude CLI checks run before the first plan starts.
+	SkipPreflight bool `yaml:"skip_preflight"`
+
+	// AllowedTools are the tools claude may use without asking, e.g. "Edit", "Bash(go test:*)".
+	AllowedTools []string `yaml:"allowed_tools"`
+
+	// DisallowedTools are tools claude may never use, e.g. "WebFetch", "Bash(curl:*)".
+	DisallowedTools []string `yaml:"disallowed_tools"`
+
+	// PermissionMode is claude's --permission-mode: default, acceptEdits, plan,
+	// or bypassPermissions (empty = claude's default).
+	PermissionMode string `yaml:"permission_mode"`
+
+	// MCPServers are MCP servers made available to claude in every worktree, keyed by name.
+	MCPServers map[string]MCPServer `yaml:"mcp_servers"`
+
+	// ResourceAlerts flags iterations whose claude process tree used too
+	// much CPU, memory, or subprocesses, e.g. runaway test processes.
+	ResourceAlerts ResourceAlertConfig `yaml:"resource_alerts"`
+
+	// OrphanProcesses is what happens to processes claude leaves running
+	// when it exits or is stopped, such as dev servers and watchers (see
+	// OrphanProcesses* constants).
+	OrphanProcesses string `yaml:"orphan_processes"`
+}
+
+// runner.orphan_processes policies.
+const (
+	// OrphanProcessesKill kills leftover processes before the next iteration.
+	OrphanProcessesKill = "kill"
+
+	// OrphanProcessesReport logs leftover processes and leaves them running.
+	OrphanProcessesReport = "report"
+)
+
+// ResourceAlertConfig sets the per-iteration resource usage that gets an
+// iteration flagged in the log and notifications. Empty or 0 = no limit.
+type ResourceAlertConfig struct {
+	CPUTime      string `yaml:"cpu_time"`     // CPU time of the process tree, e.g. "2h"
+	Memory       string `yaml:"memory"`       // peak resident memory, e.g. "8GB" (default "8GB")
+	Subprocesses int    `yaml:"subprocesses"` // subprocesses running at once (default 100)
+}
+
+// MCPServer configures one MCP server. Local servers set Command; remote
+// servers set URL. Field names match claude's .mcp.json format.
+type MCPServer struct {
+	// Type is "stdio" (default for Command), "sse", or "http" (default for URL).
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// Command starts a local server, e.g. "npx".
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// Args are the command's arguments.
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Env is extra environment for the command.
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// URL is a remote server's endpoint.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// Headers are sent with requests to a remote server.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// ServeConfig contains settings for the `ralph serve` HTTP listener.
+type ServeConfig struct {
+	// Addr is the host:port to listen on (default: "127.0.0.1:8484").
+	Addr string `yaml:"addr"`
+
+	// IngestToken is the bearer token POST /plans requires. The
+	// RALPH_INGEST_TOKEN environment variable takes precedence.
+	IngestToken string `yaml:"ingest_token"`
+}
+
+// JiraConfig contains Jira settings for `ralph import-jira` and for syncing
+// the status of a plan's **Jira:** issue. Sync is on when URL is set.
+type JiraConfig struct {
+	// URL is the Jira site, e.g. "https://acme.atlassian.net".
+	URL string `yaml:"url"`
+
+	// Email is the account for Jira Cloud basic auth. Leave it empty to send
+	// APIToken as a bearer token (Jira Server and Data Center).
+	Email string `yaml:"email"`
+
+	// APIToken authenticates API requests. The JIRA_API_TOKEN environment
+	// variable takes precedence.
+	APIToken string `yaml:"api_token"`
+
+	// InProgress is the status an issue moves to when its plan is activated.
+	InProgress string `yaml:"in_progress"`
+
+	// InReview is the status an issue moves to when its pull request is opened.
+	InReview string `yaml:"in_review"`
+
+	// Done is the status an issue moves to when its plan is merged.
+	Done string `yaml:"done"`
+}
+
+// LinearConfig contains Linear settings for `ralph import-linear`, the
+// `ralph serve --linear` webhook, and for syncing the status of a plan's
+// **Linear:** issue. Sync is on when an API key is set.
+type LinearConfig struct {
+	// APIKey authenticates API requests. The LINEAR_API_KEY environment
+	// variable takes precedence.
+	APIKey string `yaml:"api_key"`
+
+	// APIURL is the GraphQL endpoint (default: "https://api.linear.app/graphql").
+	APIURL string `yaml:"api_url"`
+
+	// WebhookSecret verifies webhook signatures. The LINEAR_WEBHOOK_SECRET
+	// environment variable takes precedence.
+	WebhookSecret string `yaml:"webhook_secret"`
+
+	// TriggerLabel is the label that makes the webhook queue an issue as a
+	// plan (default: "ralph").
+	TriggerLabel string `yaml:"trigger_label"`
+
+	// InProgress is the workflow state an issue moves to when its plan is activated.
+	InProgress string `yaml:"in_progress"`
+
+	// InReview is the workflow state an issue moves to when its pull request is opened.
+	InReview string `yaml:"in_review"`
+
+	// Done is the workflow state an issue moves to when its plan is merged.
+	Done string `yaml:"done"`
+}
+
+// GitHubConfig contains GitHub Issues settings for `ralph import-github` and
+// for syncing the status of a plan's **GitHub:** issue through the gh CLI.
+type GitHubConfig struct {
+	// Issues turns on GitHub Issues sync and import.
+	Issues bool `yaml:"issues"`
+
+	// Repo is the OWNER/NAME repository of the issues (default: the
+	// repository gh infers from the working directory).
+	Repo string `yaml:"repo"`
+
+	// InProgress is the label added to an issue when its plan is activated
+	// (empty = no label).
+	InProgress string `yaml:"in_progress"`
+
+	// InReview is the label swapped in when the plan's pull request is
+	// opened (empty = no label). Issues are closed when their plan is merged.
+	InReview string `yaml:"in_review"`
+}
+
+// BlockersConfig is the escalation policy for blockers the agent keeps
+// reporting. A blocker is unresolved while consecutive iterations report it.
+type BlockersConfig struct {
+	// EscalateAfter re-notifies, mentioning Mention, and pages once a blocker
+	// has been unresolved this long, e.g. "2h" (empty = never).
+	EscalateAfter string `yaml:"escalate_after"`
+
+	// Mention is who the escalation notifies: "@here", "@channel", or a
+	// Slack user or group ID like "U0123ABCD".
+	Mention string `yaml:"mention"`
+
+	// MaxWait moves the plan to failed/ once a blocker has been unresolved
+	// this long, e.g. "24h" (empty = never).
+	MaxWait string `yaml:"max_wait"`
+
+	// Page sends an alert to an on-call service on escalation.
+	Page PageConfig `yaml:"page"`
+}
+
+// AskConfig configures questions the agent asks with <ask>. Questions are
+// posted in the plan's Slack thread and the reply is added to the next
+// prompt.
+type AskConfig struct {
+	// Timeout is how long the loop waits for an answer before the next
+	// iteration, e.g. "30m" ("0" = don't wait; a later answer arrives as
+	// regular feedback).
+	Timeout string `yaml:"timeout"`
+}
+
+// TDDConfig configures test-first plans (**Mode:** tdd).
+type TDDConfig struct {
+	// TestPatterns are the files test-phase iterations may change, matched
+	// like git.deny_patterns: a file name glob ("*_test.go"), a path glob
+	// ("e2e/*.ts"), or a directory ("tests/").
+	TestPatterns []string `yaml:"test_patterns"`
+}
+
+// Coverage report formats.
+const (
+	CoverageGo    = "go"
+	CoverageLCOV  = "lcov"
+	CoverageRegex = "regex"
+)
+
+// CoverageConfig tracks test coverage across a plan's iterations.
+type CoverageConfig struct {
+	// Command measures coverage, e.g. "go test -coverprofile=coverage.out ./..."
+	// (empty = no coverage tracking). It runs before the first iteration and
+	// after every iteration.
+	Command string `yaml:"command"`
+
+	// Format is how the total is read: "go" (a coverprofile), "lcov", or
+	// "regex" (the command output; see CoverageGo).
+	Format string `yaml:"format"`
+
+	// Report is the coverprofile or lcov file the command writes, relative
+	// to the worktree. Required for the go and lcov formats.
+	Report string `yaml:"report"`
+
+	// Pattern matches the percentage in the command output for the regex
+	// format; its first group is the number and the last match wins.
+	Pattern string `yaml:"pattern"`
+
+	// MaxDrop is how many percentage points coverage may fall below the base
+	// branch before the coverage gate fails (0 = any drop).
+	MaxDrop float64 `yaml:"max_drop"`
+
+	// Gate fails the plan when the coverage gate fails, instead of only
+	// reporting it in the completion notification.
+	Gate bool `yaml:"gate"`
+}
+
+// Benchmark regression actions.
+const (
+	BenchFeedback = "feedback"
+	BenchBlock    = "block"
+)
+
+// BenchConfig compares benchmarks between the base branch and a plan's branch.
+type BenchConfig struct {
+	// Command runs the benchmarks and prints Go benchmark lines, e.g.
+	// "go test -run=^$ -bench=. -count=5 ./..." (empty = no benchmark gate).
+	// It runs before the first iteration and when the plan claims completion.
+	Command string `yaml:"command"`
+
+	// Threshold is how many percent worse a benchmark may get before it
+	// counts as a regression.
+	Threshold float64 `yaml:"threshold"`
+
+	// Action is what happens on a regression: "feedback" reports it and
+	// lets the plan complete, "block" rejects the completion claim until the
+	// regressions are fixed (see BenchFeedback).
+	Action string `yaml:"action"`
+}
+
+// FlakyConfig re-runs a failing test gate to tell flaky tests from real failures.
+type FlakyConfig struct {
+	// Retries is how many times a failing commands.test is re-run (0 = never).
+	// Tests that fail in some runs but not others are reported as flaky and
+	// don't fail the gate.
+	Retries int `yaml:"retries"`
+
+	// Rerun re-runs only the failing tests: {{TESTS}} is replaced with their
+	// names joined by "|", e.g. "go test -run '^({{TESTS}})$' ./..."
+	// (empty = re-run commands.test).
+	Rerun string `yaml:"rerun"`
+}
+
+// AuditConfig controls the tamper-evident audit log.
+type AuditConfig struct {
+	// Enabled records every state-changing operation (queue moves, commits,
+	// pull requests, the config in effect) in .ralph/audit.jsonl, each entry
+	// chained to the previous one by its hash.
+	Enabled bool `yaml:"enabled"`
+}
+
+// LessonsConfig controls the retrospective run when a plan finishes.
+type LessonsConfig struct {
+	// Enabled classifies each completed, failed, or abandoned plan's outcome
+	// and asks a fast model for lessons worth remembering, appended to
+	// .ralph/lessons.md.
+	Enabled bool `yaml:"enabled"`
+
+	// Model runs the retrospective (empty = completion.verification_model).
+	Model string `yaml:"model"`
+
+	// Prompt includes .ralph/lessons.md in every iteration's prompt.
+	Prompt bool `yaml:"prompt"`
+}
+
+// KnowledgeConfig controls the cross-plan knowledge base.
+type KnowledgeConfig struct {
+	// Enabled collects finished plans' progress gotchas (and lessons, with
+	// lessons.enabled) in .ralph/knowledge.md, keyed by the paths they were
+	// found in, and adds the entries relevant to a plan to its prompt.
+	Enabled bool `yaml:"enabled"`
+
+	// MaxEntries caps the entries in a prompt (0 = no cap).
+	MaxEntries int `yaml:"max_entries"`
+
+	// MaxTokens caps the estimated tokens of the entries in a prompt
+	// (0 = no cap).
+	MaxTokens int `yaml:"max_tokens"`
+}
+
+// State backends.
+const (
+	StateFiles  = "files"
+	StateSQLite = "sqlite"
+)
+
+// StateConfig selects where queue state is read from for status and reports.
+type StateConfig struct {
+	// Backend is "files" (scan the plans directories and .ralph/events.jsonl)
+	// or "sqlite" (also mirror plans and events in .ralph/state.db, for
+	// repositories with thousands of finished plans). The files stay the
+	// source of truth either way; the database is rebuilt from them as
+	// needed.
+	Backend string `yaml:"backend"`
+}
+
+// GCConfig is the garbage collection policy for .ralph runtime artifacts.
+type GCConfig struct {
+	// Auto runs garbage collection when the worker starts.
+	Auto bool `yaml:"auto"`
+
+	// RetentionDays is how long after a plan finished its logs, execution
+	// context, and Slack thread entries are kept.
+	RetentionDays int `yaml:"retention_days"`
+}
+
+// FeedbackConfig configures how feedback entries are attributed.
+type FeedbackConfig struct {
+	// Authors maps feedback authors to the display names recorded with
+	// their entries: Slack user IDs, overriding the Slack profile name, and
+	// names given to ralph feedback --as, e.g. {alice: "Alice Smith"}.
+	Authors map[string]string `yaml:"authors"`
+}
+
+// Page providers.
+const (
+	PagePagerDuty = "pagerduty"
+	PageOpsgenie  = "opsgenie"
+	PageWebhook   = "webhook"
+)
+
+// PageConfig is the on-call service that blocker escalations page.
+type PageConfig struct {
+	// Provider is "pagerduty", "opsgenie", or "webhook" (empty = no paging).
+	Provider string `yaml:"provider"`
+
+	// URL overrides the provider's API endpoint; required for webhook.
+	URL string `yaml:"url"`
+
+	// Key is the PagerDuty routing key or Opsgenie API key
+	// (RALPH_PAGE_KEY overrides).
+	Key string `yaml:"key"`
+}
+
+// PermissionModes are the valid values of runner.permission_mode.
+var PermissionModes = []string{"default", "acceptEdits", "plan", "bypassPermissions"}
+
+// versionRegex matches a dotted version number like "1.0" or "1.0.17".
+var versionRegex = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
 // Load reads and parses a YAML config file.
 // Returns an error if the file cannot be read or parsed.
 // For missing files, use LoadWithDefaults instead.
@@ -125,6 +921,178 @@ func (c *Config) Validate() error {
 	if c.Completion.Mode != "" && c.Completion.Mode != "pr" && c.Completion.Mode != "merge" {
 		return fmt.Errorf("completion.mode must be 'pr' or 'merge', got '%s'", c.Completion.Mode)
 	}
+	if c.Completion.ApprovalTimeout != "" {
+		if d, err := time.ParseDuration(c.Completion.ApprovalTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("completion.approval_timeout must be a positive duration like '24h', got '%s'", c.Completion.ApprovalTimeout)
+		}
+	}
+	if c.Completion.AutoMerge != "" {
+		valid := false
+		for _, strategy := range AutoMergeStrategies {
+			if c.Completion.AutoMerge == strategy {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("completion.auto_merge must be one of %s, got '%s'", strings.Join(AutoMergeStrategies, ", "), c.Completion.AutoMerge)
+		}
+	}
+
+	// Validate diff limits
+	if c.Git.MaxDiffFiles < 0 {
+		return fmt.Errorf("git.max_diff_files must be >= 0, got %d", c.Git.MaxDiffFiles)
+	}
+	if c.Git.MaxDiffLines < 0 {
+		return fmt.Errorf("git.max_diff_lines must be >= 0, got %d", c.Git.MaxDiffLines)
+	}
+	if a := c.Git.DiffLimitAction; a != "" && a != DiffLimitSplit && a != DiffLimitBlock {
+		return fmt.Errorf("git.diff_limit_action must be '%s' or '%s', got '%s'", DiffLimitSplit, DiffLimitBlock, a)
+	}
+	switch c.Git.Provider {
+	case "", git.ProviderGitHub, git.ProviderGitLab, git.ProviderBitbucket:
+	default:
+		return fmt.Errorf("git.provider must be 'github', 'gitlab', or 'bitbucket', got '%s'", c.Git.Provider)
+	}
+
+	if c.Git.BranchTemplate != "" {
+		sample := git.BranchNameData{Name: "example-plan", Date: "2024-01-30", Jira: "PROJ-1", Linear: "ENG-1", GitHub: "1"}
+		if _, err := git.RenderBranchName(c.Git.BranchTemplate, sample); err != nil {
+			return fmt.Errorf("git.branch_template: %w", err)
+		}
+	}
+
+	if c.Git.RecentCommits < 0 {
+		return fmt.Errorf("git.recent_commits must be >= 0, got %d", c.Git.RecentCommits)
+	}
+
+	if b := c.Git.StateBranch; b != "" {
+		if err := git.CheckBranchName(b); err != nil {
+			return fmt.Errorf("git.state_branch: %w", err)
+		}
+		if b == c.Git.BaseBranch {
+			return fmt.Errorf("git.state_branch must not be the base branch, got '%s'", b)
+		}
+	}
+
+	// Validate large-file protection
+	if _, err := ParseSize(c.Git.MaxFileSize); err != nil {
+		return fmt.Errorf("git.max_file_size: %w", err)
+	}
+	for _, p := range c.Git.DenyPatterns {
+		if _, err := path.Match(strings.TrimSuffix(p, "/"), ""); err != nil || strings.TrimSuffix(p, "/") == "" {
+			return fmt.Errorf("git.deny_patterns: invalid pattern '%s'", p)
+		}
+	}
+
+	// Validate test-first test patterns
+	for _, p := range c.TDD.TestPatterns {
+		if _, err := path.Match(strings.TrimSuffix(p, "/"), ""); err != nil || strings.TrimSuffix(p, "/") == "" {
+			return fmt.Errorf("tdd.test_patterns: invalid pattern '%s'", p)
+		}
+	}
+
+	// Validate coverage tracking
+	switch c.Coverage.Format {
+	case "", CoverageRegex:
+		if c.Coverage.Pattern != "" {
+			re, err := regexp.Compile(c.Coverage.Pattern)
+			if err != nil || re.NumSubexp() < 1 {
+				return fmt.Errorf("coverage.pattern must be a regex with a group for the percentage, got '%s'", c.Coverage.Pattern)
+			}
+		}
+	case CoverageGo, CoverageLCOV:
+		if c.Coverage.Report == "" {
+			return fmt.Errorf("coverage.report is required for the %s format", c.Coverage.Format)
+		}
+	default:
+		return fmt.Errorf("coverage.format must be go, lcov, or regex, got '%s'", c.Coverage.Format)
+	}
+	if c.Coverage.MaxDrop < 0 {
+		return fmt.Errorf("coverage.max_drop must not be negative, got %g", c.Coverage.MaxDrop)
+	}
+
+	// Validate benchmark gate
+	if c.Bench.Threshold < 0 {
+		return fmt.Errorf("bench.threshold must not be negative, got %g", c.Bench.Threshold)
+	}
+	if c.Bench.Action != "" && c.Bench.Action != BenchFeedback && c.Bench.Action != BenchBlock {
+		return fmt.Errorf("bench.action must be 'feedback' or 'block', got '%s'", c.Bench.Action)
+	}
+
+	// Validate knowledge base limits
+	if c.Knowledge.MaxEntries < 0 {
+		return fmt.Errorf("knowledge.max_entries must not be negative, got %d", c.Knowledge.MaxEntries)
+	}
+	if c.Knowledge.MaxTokens < 0 {
+		return fmt.Errorf("knowledge.max_tokens must not be negative, got %d", c.Knowledge.MaxTokens)
+	}
+
+	// Validate state backend
+	if b := c.State.Backend; b != "" && b != StateFiles && b != StateSQLite {
+		return fmt.Errorf("state.backend must be '%s' or '%s', got '%s'", StateFiles, StateSQLite, b)
+	}
+
+	// Validate garbage collection policy
+	if c.GC.RetentionDays < 0 {
+		return fmt.Errorf("gc.retention_days must not be negative, got %d", c.GC.RetentionDays)
+	}
+
+	// Validate locale
+	if l := c.Project.Locale; l != "" && !i18n.Supported(l) {
+		return fmt.Errorf("project.locale must be one of %s, got '%s'", strings.Join(i18n.Locales(), ", "), l)
+	}
+
+	// Validate feedback authors
+	for author, name := range c.Feedback.Authors {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("feedback.authors.%s must not be empty", author)
+		}
+	}
+
+	// Validate flaky test retries
+	if c.Flaky.Retries < 0 {
+		return fmt.Errorf("flaky.retries must not be negative, got %d", c.Flaky.Retries)
+	}
+
+	// Validate worktree preset
+	switch c.Worktree.Preset {
+	case "", PresetGo, PresetNode, PresetPython, PresetRust:
+	default:
+		return fmt.Errorf("worktree.preset must be 'go', 'node', 'python' or 'rust', got '%s'", c.Worktree.Preset)
+	}
+	switch c.Worktree.InterruptedOps {
+	case "", InterruptedOpsAbort, InterruptedOpsBlock:
+	default:
+		return fmt.Errorf("worktree.interrupted_ops must be 'abort' or 'block', got '%s'", c.Worktree.InterruptedOps)
+	}
+
+	// Validate stages
+	stageNames := make(map[string]bool)
+	for i, stage := range c.Stages {
+		if stage.Name == "" {
+			return fmt.Errorf("stages[%d].name is required", i)
+		}
+		if stageNames[stage.Name] {
+			return fmt.Errorf("stages: duplicate stage name '%s'", stage.Name)
+		}
+		stageNames[stage.Name] = true
+		if stage.Completion != "" {
+			valid := false
+			for _, completion := range StageCompletions {
+				if stage.Completion == completion {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf("stages.%s.completion must be one of %s, got '%s'", stage.Name, strings.Join(StageCompletions, ", "), stage.Completion)
+			}
+		}
+		if stage.MaxIterations < 0 {
+			return fmt.Errorf("stages.%s.max_iterations must be >= 0, got %d", stage.Name, stage.MaxIterations)
+		}
+	}
 
 	// Validate Slack webhook URL format
 	if c.Slack.WebhookURL != "" {
@@ -147,9 +1115,200 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate Slack digest mode
+	if c.Slack.Digest != "" && c.Slack.Digest != "hourly" && c.Slack.Digest != "daily" {
+		return fmt.Errorf("slack.digest must be 'hourly' or 'daily', got '%s'", c.Slack.Digest)
+	}
+
+	// Validate Slack roles
+	for user, role := range c.Slack.Roles {
+		if !isSlackRole(role) {
+			return fmt.Errorf("slack.roles.%s must be 'viewer', 'operator', or 'admin', got '%s'", user, role)
+		}
+	}
+	if c.Slack.DefaultRole != "" && !isSlackRole(c.Slack.DefaultRole) {
+		return fmt.Errorf("slack.default_role must be 'viewer', 'operator', or 'admin', got '%s'", c.Slack.DefaultRole)
+	}
+
+	// Validate Slack rate limits
+	if c.Slack.RateLimit.Window != "" {
+		if d, err := time.ParseDuration(c.Slack.RateLimit.Window); err != nil || d <= 0 {
+			return fmt.Errorf("slack.rate_limit.window must be a positive duration like '10m', got '%s'", c.Slack.RateLimit.Window)
+		}
+	}
+	if m := c.Slack.DiffPreview.Mode; m != "" && m != DiffPreviewDiff && m != DiffPreviewLink {
+		return fmt.Errorf("slack.diff_preview.mode must be '%s' or '%s', got '%s'", DiffPreviewDiff, DiffPreviewLink, m)
+	}
+	if c.Slack.DiffPreview.MaxLines < 0 {
+		return fmt.Errorf("slack.diff_preview.max_lines must be >= 0, got %d", c.Slack.DiffPreview.MaxLines)
+	}
+	if c.Slack.DiffPreview.MaxFiles < 0 {
+		return fmt.Errorf("slack.diff_preview.max_files must be >= 0, got %d", c.Slack.DiffPreview.MaxFiles)
+	}
+	if c.Slack.RateLimit.PerPlan < 0 {
+		return fmt.Errorf("slack.rate_limit.per_plan must be >= 0, got %d", c.Slack.RateLimit.PerPlan)
+	}
+	if c.Slack.RateLimit.Global < 0 {
+		return fmt.Errorf("slack.rate_limit.global must be >= 0, got %d", c.Slack.RateLimit.Global)
+	}
+
+	// Validate redact patterns
+	for _, p := range c.Redact.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("redact.patterns: invalid pattern '%s': %v", p, err)
+		}
+	}
+
+	// Validate claude version range
+	for _, v := range []struct{ key, value string }{
+		{"runner.min_claude_version", c.Runner.MinClaudeVersion},
+		{"runner.max_claude_version", c.Runner.MaxClaudeVersion},
+	} {
+		if v.value != "" && !versionRegex.MatchString(v.value) {
+			return fmt.Errorf("%s must be a version like '1.0.0', got '%s'", v.key, v.value)
+		}
+	}
+
+	// Validate permission mode
+	if c.Runner.PermissionMode != "" {
+		valid := false
+		for _, mode := range PermissionModes {
+			if c.Runner.PermissionMode == mode {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("runner.permission_mode must be one of %s, got '%s'", strings.Join(PermissionModes, ", "), c.Runner.PermissionMode)
+		}
+	}
+
+	// Validate resource alerts
+	if a := c.Runner.ResourceAlerts.CPUTime; a != "" {
+		if d, err := time.ParseDuration(a); err != nil || d <= 0 {
+			return fmt.Errorf("runner.resource_alerts.cpu_time must be a positive duration (e.g. \"2h\"), got '%s'", a)
+		}
+	}
+	if _, err := ParseSize(c.Runner.ResourceAlerts.Memory); err != nil {
+		return fmt.Errorf("runner.resource_alerts.memory: %w", err)
+	}
+	if c.Runner.ResourceAlerts.Subprocesses < 0 {
+		return fmt.Errorf("runner.resource_alerts.subprocesses must be >= 0, got %d", c.Runner.ResourceAlerts.Subprocesses)
+	}
+
+	switch c.Runner.OrphanProcesses {
+	case "", OrphanProcessesKill, OrphanProcessesReport:
+	default:
+		return fmt.Errorf("runner.orphan_processes must be 'kill' or 'report', got '%s'", c.Runner.OrphanProcesses)
+	}
+
+	// Validate MCP servers
+	for name, server := range c.Runner.MCPServers {
+		if (server.Command == "") == (server.URL == "") {
+			return fmt.Errorf("runner.mcp_servers.%s: set exactly one of command or url", name)
+		}
+		switch server.Type {
+		case "", "stdio", "sse", "http":
+		default:
+			return fmt.Errorf("runner.mcp_servers.%s.type must be stdio, sse, or http, got '%s'", name, server.Type)
+		}
+	}
+
+	// Validate worker retry policy
+	if c.Worker.PlanRetries < 0 {
+		return fmt.Errorf("worker.plan_retries must not be negative, got %d", c.Worker.PlanRetries)
+	}
+	if c.Worker.RetryBackoff != "" {
+		if d, err := time.ParseDuration(c.Worker.RetryBackoff); err != nil || d <= 0 {
+			return fmt.Errorf("worker.retry_backoff must be a positive duration like '5m', got '%s'", c.Worker.RetryBackoff)
+		}
+	}
+	if c.Worker.MaxPlanDuration != "" {
+		if d, err := time.ParseDuration(c.Worker.MaxPlanDuration); err != nil || d <= 0 {
+			return fmt.Errorf("worker.max_plan_duration must be a positive duration like '6h', got '%s'", c.Worker.MaxPlanDuration)
+		}
+	}
+
+	// Validate serve listen address
+	if c.Serve.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Serve.Addr); err != nil {
+			return fmt.Errorf("serve.addr must be host:port like '127.0.0.1:8484', got '%s'", c.Serve.Addr)
+		}
+	}
+
+	// Validate Jira site URL
+	if c.Jira.URL != "" && !strings.HasPrefix(c.Jira.URL, "https://") && !strings.HasPrefix(c.Jira.URL, "http://") {
+		return fmt.Errorf("jira.url must be an http(s) URL, got '%s'", c.Jira.URL)
+	}
+
+	// Validate Linear API endpoint
+	if c.Linear.APIURL != "" && !strings.HasPrefix(c.Linear.APIURL, "https://") && !strings.HasPrefix(c.Linear.APIURL, "http://") {
+		return fmt.Errorf("linear.api_url must be an http(s) URL, got '%s'", c.Linear.APIURL)
+	}
+
+	// Validate GitHub issues repository
+	if c.GitHub.Repo != "" && strings.Count(c.GitHub.Repo, "/") != 1 {
+		return fmt.Errorf("github.repo must be OWNER/NAME, got '%s'", c.GitHub.Repo)
+	}
+
+	// Validate blocker escalation policy
+	for _, field := range []struct{ name, value string }{
+		{"escalate_after", c.Blockers.EscalateAfter},
+		{"max_wait", c.Blockers.MaxWait},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(field.value); err != nil || d <= 0 {
+			return fmt.Errorf("blockers.%s must be a positive duration like '2h', got '%s'", field.name, field.value)
+		}
+	}
+
+	switch c.Blockers.Page.Provider {
+	case "", PagePagerDuty, PageOpsgenie:
+	case PageWebhook:
+		if c.Blockers.Page.URL == "" {
+			return fmt.Errorf("blockers.page.url is required for the webhook provider")
+		}
+	default:
+		return fmt.Errorf("blockers.page.provider must be pagerduty, opsgenie, or webhook, got '%s'", c.Blockers.Page.Provider)
+	}
+	if c.Blockers.Page.URL != "" && !strings.HasPrefix(c.Blockers.Page.URL, "https://") && !strings.HasPrefix(c.Blockers.Page.URL, "http://") {
+		return fmt.Errorf("blockers.page.url must be an http(s) URL, got '%s'", c.Blockers.Page.URL)
+	}
+
+	// Validate ask timeout
+	if c.Ask.Timeout != "" {
+		if d, err := time.ParseDuration(c.Ask.Timeout); err != nil || d < 0 {
+			return fmt.Errorf("ask.timeout must be a duration like '30m' or '0', got '%s'", c.Ask.Timeout)
+		}
+	}
+
+	// Validate worker plan filters
+	for _, p := range c.Worker.Include {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return fmt.Errorf("worker.include: invalid pattern '%s'", p)
+		}
+	}
+	for _, p := range c.Worker.Exclude {
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return fmt.Errorf("worker.exclude: invalid pattern '%s'", p)
+		}
+	}
+	switch c.Worker.MainRepoCheck {
+	case "", MainRepoCheckOff, MainRepoCheckWarn, MainRepoCheckFail:
+	default:
+		return fmt.Errorf("worker.main_repo_check must be 'off', 'warn', or 'fail', got '%s'", c.Worker.MainRepoCheck)
+	}
+
 	return nil
 }
 
+// isSlackRole reports whether role is one of the Slack roles.
+func isSlackRole(role string) bool {
+	return role == RoleViewer || role == RoleOperator || role == RoleAdmin
+}
+
 // mergeConfig merges values from src into dst.
 // Only non-zero values from src overwrite dst.
 func mergeConfig(dst, src *Config) {
@@ -160,11 +1319,55 @@ func mergeConfig(dst, src *Config) {
 	if src.Project.Description != "" {
 		dst.Project.Description = src.Project.Description
 	}
+	if src.Project.Locale != "" {
+		dst.Project.Locale = src.Project.Locale
+	}
 
 	// Git
 	if src.Git.BaseBranch != "" {
 		dst.Git.BaseBranch = src.Git.BaseBranch
 	}
+	if src.Git.BranchTemplate != "" {
+		dst.Git.BranchTemplate = src.Git.BranchTemplate
+	}
+	dst.Git.DeleteBranchOnMerge = src.Git.DeleteBranchOnMerge
+	if src.Git.MaxDiffFiles != 0 {
+		dst.Git.MaxDiffFiles = src.Git.MaxDiffFiles
+	}
+	if src.Git.MaxDiffLines != 0 {
+		dst.Git.MaxDiffLines = src.Git.MaxDiffLines
+	}
+	if src.Git.DiffLimitAction != "" {
+		dst.Git.DiffLimitAction = src.Git.DiffLimitAction
+	}
+	if src.Git.MaxFileSize != "" {
+		dst.Git.MaxFileSize = src.Git.MaxFileSize
+	}
+	if len(src.Git.DenyPatterns) > 0 {
+		dst.Git.DenyPatterns = src.Git.DenyPatterns
+	}
+	if src.Git.RecentCommits != 0 {
+		dst.Git.RecentCommits = src.Git.RecentCommits
+	}
+	if src.Git.StateBranch != "" {
+		dst.Git.StateBranch = src.Git.StateBranch
+	}
+	dst.Git.StatePush = src.Git.StatePush
+	if src.Git.Provider != "" {
+		dst.Git.Provider = src.Git.Provider
+	}
+	if len(src.Git.PR.Reviewers) > 0 {
+		dst.Git.PR.Reviewers = src.Git.PR.Reviewers
+	}
+	if len(src.Git.PR.Assignees) > 0 {
+		dst.Git.PR.Assignees = src.Git.PR.Assignees
+	}
+	if len(src.Git.PR.Labels) > 0 {
+		dst.Git.PR.Labels = src.Git.PR.Labels
+	}
+	dst.Git.PR.Draft = src.Git.PR.Draft
+	dst.Git.PR.CodeOwners = src.Git.PR.CodeOwners
+	dst.Git.PR.IgnoreTemplate = src.Git.PR.IgnoreTemplate
 
 	// Commands
 	if src.Commands.Test != "" {
@@ -184,6 +1387,9 @@ func mergeConfig(dst, src *Config) {
 	if src.Slack.WebhookURL != "" {
 		dst.Slack.WebhookURL = src.Slack.WebhookURL
 	}
+	if src.Slack.WebhookSecret != "" {
+		dst.Slack.WebhookSecret = src.Slack.WebhookSecret
+	}
 	if src.Slack.Channel != "" {
 		dst.Slack.Channel = src.Slack.Channel
 	}
@@ -207,6 +1413,40 @@ func mergeConfig(dst, src *Config) {
 	dst.Slack.NotifyIteration = src.Slack.NotifyIteration
 	dst.Slack.NotifyError = src.Slack.NotifyError || dst.Slack.NotifyError
 	dst.Slack.NotifyBlocker = src.Slack.NotifyBlocker || dst.Slack.NotifyBlocker
+	dst.Slack.PlainText = src.Slack.PlainText
+	if src.Slack.ThreadRetentionDays > 0 {
+		dst.Slack.ThreadRetentionDays = src.Slack.ThreadRetentionDays
+	}
+	if src.Slack.MaxThreads > 0 {
+		dst.Slack.MaxThreads = src.Slack.MaxThreads
+	}
+	if src.Slack.Digest != "" {
+		dst.Slack.Digest = src.Slack.Digest
+	}
+	if len(src.Slack.Roles) > 0 {
+		dst.Slack.Roles = src.Slack.Roles
+	}
+	if src.Slack.DefaultRole != "" {
+		dst.Slack.DefaultRole = src.Slack.DefaultRole
+	}
+	if src.Slack.DiffPreview.Mode != "" {
+		dst.Slack.DiffPreview.Mode = src.Slack.DiffPreview.Mode
+	}
+	if src.Slack.DiffPreview.MaxLines > 0 {
+		dst.Slack.DiffPreview.MaxLines = src.Slack.DiffPreview.MaxLines
+	}
+	if src.Slack.DiffPreview.MaxFiles > 0 {
+		dst.Slack.DiffPreview.MaxFiles = src.Slack.DiffPreview.MaxFiles
+	}
+	if src.Slack.RateLimit.Window != "" {
+		dst.Slack.RateLimit.Window = src.Slack.RateLimit.Window
+	}
+	if src.Slack.RateLimit.PerPlan > 0 {
+		dst.Slack.RateLimit.PerPlan = src.Slack.RateLimit.PerPlan
+	}
+	if src.Slack.RateLimit.Global > 0 {
+		dst.Slack.RateLimit.Global = src.Slack.RateLimit.Global
+	}
 
 	// Worktree
 	if src.Worktree.CopyEnvFiles != "" {
@@ -215,6 +1455,20 @@ func mergeConfig(dst, src *Config) {
 	if src.Worktree.InitCommands != "" {
 		dst.Worktree.InitCommands = src.Worktree.InitCommands
 	}
+	if src.Worktree.CompleteHooks != "" {
+		dst.Worktree.CompleteHooks = src.Worktree.CompleteHooks
+	}
+	dst.Worktree.Submodules = src.Worktree.Submodules
+	dst.Worktree.LFS = src.Worktree.LFS
+	if src.Worktree.Preset != "" {
+		dst.Worktree.Preset = src.Worktree.Preset
+	}
+	if src.Worktree.InterruptedOps != "" {
+		dst.Worktree.InterruptedOps = src.Worktree.InterruptedOps
+	}
+	if src.Worktree.ScratchDir != "" {
+		dst.Worktree.ScratchDir = src.Worktree.ScratchDir
+	}
 
 	// Completion
 	if src.Completion.Mode != "" {
@@ -223,4 +1477,265 @@ func mergeConfig(dst, src *Config) {
 	if src.Completion.VerificationModel != "" {
 		dst.Completion.VerificationModel = src.Completion.VerificationModel
 	}
+	if src.Completion.SecondVerifier.Model != "" {
+		dst.Completion.SecondVerifier.Model = src.Completion.SecondVerifier.Model
+	}
+	if src.Completion.SecondVerifier.ClaudePath != "" {
+		dst.Completion.SecondVerifier.ClaudePath = src.Completion.SecondVerifier.ClaudePath
+	}
+	dst.Completion.Gates = src.Completion.Gates
+	dst.Completion.RequireApproval = src.Completion.RequireApproval
+	if src.Completion.ApprovalTimeout != "" {
+		dst.Completion.ApprovalTimeout = src.Completion.ApprovalTimeout
+	}
+	if src.Completion.AutoMerge != "" {
+		dst.Completion.AutoMerge = src.Completion.AutoMerge
+	}
+
+	// Stages
+	if len(src.Stages) > 0 {
+		dst.Stages = src.Stages
+	}
+
+	// Hooks
+	if len(src.Hooks.OnPlanComplete) > 0 {
+		dst.Hooks.OnPlanComplete = src.Hooks.OnPlanComplete
+	}
+	if len(src.Hooks.OnPlanError) > 0 {
+		dst.Hooks.OnPlanError = src.Hooks.OnPlanError
+	}
+	if src.Hooks.PreIteration != "" {
+		dst.Hooks.PreIteration = src.Hooks.PreIteration
+	}
+	dst.Hooks.CapturePreIteration = src.Hooks.CapturePreIteration
+
+	// Redact
+	if len(src.Redact.Patterns) > 0 {
+		dst.Redact.Patterns = src.Redact.Patterns
+	}
+
+	// Runner
+	if src.Runner.ClaudePath != "" {
+		dst.Runner.ClaudePath = src.Runner.ClaudePath
+	}
+	if src.Runner.MinClaudeVersion != "" {
+		dst.Runner.MinClaudeVersion = src.Runner.MinClaudeVersion
+	}
+	if src.Runner.MaxClaudeVersion != "" {
+		dst.Runner.MaxClaudeVersion = src.Runner.MaxClaudeVersion
+	}
+	if src.Runner.Model != "" {
+		dst.Runner.Model = src.Runner.Model
+	}
+	dst.Runner.SkipPreflight = src.Runner.SkipPreflight
+	if len(src.Runner.AllowedTools) > 0 {
+		dst.Runner.AllowedTools = src.Runner.AllowedTools
+	}
+	if len(src.Runner.DisallowedTools) > 0 {
+		dst.Runner.DisallowedTools = src.Runner.DisallowedTools
+	}
+	if src.Runner.PermissionMode != "" {
+		dst.Runner.PermissionMode = src.Runner.PermissionMode
+	}
+	if len(src.Runner.MCPServers) > 0 {
+		dst.Runner.MCPServers = src.Runner.MCPServers
+	}
+	if src.Runner.ResourceAlerts.CPUTime != "" {
+		dst.Runner.ResourceAlerts.CPUTime = src.Runner.ResourceAlerts.CPUTime
+	}
+	if src.Runner.ResourceAlerts.Memory != "" {
+		dst.Runner.ResourceAlerts.Memory = src.Runner.ResourceAlerts.Memory
+	}
+	if src.Runner.ResourceAlerts.Subprocesses > 0 {
+		dst.Runner.ResourceAlerts.Subprocesses = src.Runner.ResourceAlerts.Subprocesses
+	}
+	if src.Runner.OrphanProcesses != "" {
+		dst.Runner.OrphanProcesses = src.Runner.OrphanProcesses
+	}
+
+	// Worker
+	if src.Worker.PlanRetries != 0 {
+		dst.Worker.PlanRetries = src.Worker.PlanRetries
+	}
+	if src.Worker.RetryBackoff != "" {
+		dst.Worker.RetryBackoff = src.Worker.RetryBackoff
+	}
+	if src.Worker.MaxPlanDuration != "" {
+		dst.Worker.MaxPlanDuration = src.Worker.MaxPlanDuration
+	}
+	dst.Worker.AvoidOverlap = src.Worker.AvoidOverlap
+	if len(src.Worker.Include) > 0 {
+		dst.Worker.Include = src.Worker.Include
+	}
+	if len(src.Worker.Exclude) > 0 {
+		dst.Worker.Exclude = src.Worker.Exclude
+	}
+	if src.Worker.MainRepoCheck != "" {
+		dst.Worker.MainRepoCheck = src.Worker.MainRepoCheck
+	}
+
+	// Serve
+	if src.Serve.Addr != "" {
+		dst.Serve.Addr = src.Serve.Addr
+	}
+	if src.Serve.IngestToken != "" {
+		dst.Serve.IngestToken = src.Serve.IngestToken
+	}
+
+	// Jira
+	if src.Jira.URL != "" {
+		dst.Jira.URL = src.Jira.URL
+	}
+	if src.Jira.Email != "" {
+		dst.Jira.Email = src.Jira.Email
+	}
+	if src.Jira.APIToken != "" {
+		dst.Jira.APIToken = src.Jira.APIToken
+	}
+	if src.Jira.InProgress != "" {
+		dst.Jira.InProgress = src.Jira.InProgress
+	}
+	if src.Jira.InReview != "" {
+		dst.Jira.InReview = src.Jira.InReview
+	}
+	if src.Jira.Done != "" {
+		dst.Jira.Done = src.Jira.Done
+	}
+
+	// Linear
+	if src.Linear.APIKey != "" {
+		dst.Linear.APIKey = src.Linear.APIKey
+	}
+	if src.Linear.APIURL != "" {
+		dst.Linear.APIURL = src.Linear.APIURL
+	}
+	if src.Linear.WebhookSecret != "" {
+		dst.Linear.WebhookSecret = src.Linear.WebhookSecret
+	}
+	if src.Linear.TriggerLabel != "" {
+		dst.Linear.TriggerLabel = src.Linear.TriggerLabel
+	}
+	if src.Linear.InProgress != "" {
+		dst.Linear.InProgress = src.Linear.InProgress
+	}
+	if src.Linear.InReview != "" {
+		dst.Linear.InReview = src.Linear.InReview
+	}
+	if src.Linear.Done != "" {
+		dst.Linear.Done = src.Linear.Done
+	}
+
+	// GitHub
+	dst.GitHub.Issues = src.GitHub.Issues
+	if src.GitHub.Repo != "" {
+		dst.GitHub.Repo = src.GitHub.Repo
+	}
+	if src.GitHub.InProgress != "" {
+		dst.GitHub.InProgress = src.GitHub.InProgress
+	}
+	if src.GitHub.InReview != "" {
+		dst.GitHub.InReview = src.GitHub.InReview
+	}
+
+	// Blockers
+	if src.Blockers.EscalateAfter != "" {
+		dst.Blockers.EscalateAfter = src.Blockers.EscalateAfter
+	}
+	if src.Blockers.Mention != "" {
+		dst.Blockers.Mention = src.Blockers.Mention
+	}
+	if src.Blockers.MaxWait != "" {
+		dst.Blockers.MaxWait = src.Blockers.MaxWait
+	}
+	if src.Blockers.Page.Provider != "" {
+		dst.Blockers.Page.Provider = src.Blockers.Page.Provider
+	}
+	if src.Blockers.Page.URL != "" {
+		dst.Blockers.Page.URL = src.Blockers.Page.URL
+	}
+	if src.Blockers.Page.Key != "" {
+		dst.Blockers.Page.Key = src.Blockers.Page.Key
+	}
+
+	// Ask
+	if src.Ask.Timeout != "" {
+		dst.Ask.Timeout = src.Ask.Timeout
+	}
+
+	// TDD
+	if len(src.TDD.TestPatterns) > 0 {
+		dst.TDD.TestPatterns = src.TDD.TestPatterns
+	}
+
+	// Coverage
+	if src.Coverage.Command != "" {
+		dst.Coverage.Command = src.Coverage.Command
+	}
+	if src.Coverage.Format != "" {
+		dst.Coverage.Format = src.Coverage.Format
+	}
+	if src.Coverage.Report != "" {
+		dst.Coverage.Report = src.Coverage.Report
+	}
+	if src.Coverage.Pattern != "" {
+		dst.Coverage.Pattern = src.Coverage.Pattern
+	}
+	if src.Coverage.MaxDrop != 0 {
+		dst.Coverage.MaxDrop = src.Coverage.MaxDrop
+	}
+	dst.Coverage.Gate = src.Coverage.Gate
+
+	// Flaky
+	if src.Flaky.Retries != 0 {
+		dst.Flaky.Retries = src.Flaky.Retries
+	}
+	if src.Flaky.Rerun != "" {
+		dst.Flaky.Rerun = src.Flaky.Rerun
+	}
+
+	// Bench
+	if src.Bench.Command != "" {
+		dst.Bench.Command = src.Bench.Command
+	}
+	if src.Bench.Threshold != 0 {
+		dst.Bench.Threshold = src.Bench.Threshold
+	}
+	if src.Bench.Action != "" {
+		dst.Bench.Action = src.Bench.Action
+	}
+
+	// Audit
+	dst.Audit.Enabled = src.Audit.Enabled
+
+	// Lessons
+	dst.Lessons.Enabled = src.Lessons.Enabled
+	if src.Lessons.Model != "" {
+		dst.Lessons.Model = src.Lessons.Model
+	}
+	dst.Lessons.Prompt = src.Lessons.Prompt
+
+	// Knowledge
+	dst.Knowledge.Enabled = src.Knowledge.Enabled
+	if src.Knowledge.MaxEntries != 0 {
+		dst.Knowledge.MaxEntries = src.Knowledge.MaxEntries
+	}
+	if src.Knowledge.MaxTokens != 0 {
+		dst.Knowledge.MaxTokens = src.Knowledge.MaxTokens
+	}
+
+	// State
+	if src.State.Backend != "" {
+		dst.State.Backend = src.State.Backend
+	}
+
+	// GC
+	dst.GC.Auto = src.GC.Auto
+	if src.GC.RetentionDays != 0 {
+		dst.GC.RetentionDays = src.GC.RetentionDays
+	}
+
+	// Feedback
+	if len(src.Feedback.Authors) > 0 {
+		dst.Feedback.Authors = src.Feedback.Authors
+	}
 }
@@ -306,10 +306,13 @@ slack:
   notify_iteration: true
   notify_error: false
   notify_blocker: false
+  plain_text: true
 
 worktree:
   copy_env_files: ".env, .env.local, .env.test"
   init_commands: "npm ci && npm run setup"
+  submodules: true
+  lfs: true
 
 completion:
   mode: "merge"
@@ -375,13 +378,861 @@ completion:
 	if cfg.Slack.NotifyBlocker {
 		t.Errorf("Slack.NotifyBlocker should be false")
 	}
+	if !cfg.Slack.PlainText {
+		t.Errorf("Slack.PlainText should be true")
+	}
 	if cfg.Worktree.CopyEnvFiles != ".env, .env.local, .env.test" {
 		t.Errorf("Worktree.CopyEnvFiles mismatch")
 	}
 	if cfg.Worktree.InitCommands != "npm ci && npm run setup" {
 		t.Errorf("Worktree.InitCommands mismatch")
 	}
+	if !cfg.Worktree.Submodules || !cfg.Worktree.LFS {
+		t.Errorf("Worktree.Submodules and Worktree.LFS should be true")
+	}
 	if cfg.Completion.Mode != "merge" {
 		t.Errorf("Completion.Mode mismatch")
 	}
 }
+
+func TestLoadWithDefaults_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	content := `
+worktree:
+  complete_hooks: "make clean"
+hooks:
+  on_plan_complete:
+    - "./scripts/deploy-preview.sh"
+    - "https://example.com/ralph"
+  on_plan_error:
+    - "./scripts/page.sh"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if cfg.Worktree.CompleteHooks != "make clean" {
+		t.Errorf("Worktree.CompleteHooks = %q, want %q", cfg.Worktree.CompleteHooks, "make clean")
+	}
+	if len(cfg.Hooks.OnPlanComplete) != 2 || cfg.Hooks.OnPlanComplete[1] != "https://example.com/ralph" {
+		t.Errorf("Hooks.OnPlanComplete = %v", cfg.Hooks.OnPlanComplete)
+	}
+	if len(cfg.Hooks.OnPlanError) != 1 || cfg.Hooks.OnPlanError[0] != "./scripts/page.sh" {
+		t.Errorf("Hooks.OnPlanError = %v", cfg.Hooks.OnPlanError)
+	}
+}
+
+func TestLoadWithDefaults_ThreadPruning(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if err := os.WriteFile(path, []byte("slack:\n  thread_retention_days: 7\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if cfg.Slack.ThreadRetentionDays != 7 {
+		t.Errorf("Slack.ThreadRetentionDays = %d, want 7", cfg.Slack.ThreadRetentionDays)
+	}
+	if cfg.Slack.MaxThreads != 500 {
+		t.Errorf("Slack.MaxThreads = %d, want default 500", cfg.Slack.MaxThreads)
+	}
+}
+
+func TestLoadWithDefaults_Digest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if err := os.WriteFile(path, []byte("slack:\n  digest: hourly\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if cfg.Slack.Digest != "hourly" {
+		t.Errorf("Slack.Digest = %q, want %q", cfg.Slack.Digest, "hourly")
+	}
+}
+
+func TestValidate_Digest(t *testing.T) {
+	for _, mode := range []string{"", "hourly", "daily"} {
+		cfg := Defaults()
+		cfg.Slack.Digest = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with digest %q error = %v", mode, err)
+		}
+	}
+
+	cfg := Defaults()
+	cfg.Slack.Digest = "weekly"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown digest mode")
+	}
+}
+
+func TestLoadWithDefaults_SlackRoles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	content := "slack:\n  roles:\n    U0123ABCD: admin\n    U0456EFGH: operator\n  default_role: viewer\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+	if cfg.Slack.Roles["U0123ABCD"] != RoleAdmin || cfg.Slack.Roles["U0456EFGH"] != RoleOperator || cfg.Slack.DefaultRole != RoleViewer {
+		t.Errorf("Slack roles = %v, default %q", cfg.Slack.Roles, cfg.Slack.DefaultRole)
+	}
+}
+
+func TestValidate_SlackRoles(t *testing.T) {
+	cfg := Defaults()
+	cfg.Slack.Roles = map[string]string{"U1": "superuser"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown role")
+	}
+
+	cfg = Defaults()
+	cfg.Slack.DefaultRole = "guest"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject unknown default_role")
+	}
+}
+
+func TestValidate_SlackRateLimit(t *testing.T) {
+	cfg := Defaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() with default rate limits error = %v", err)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Slack.RateLimit.Window = "soon" },
+		func(c *Config) { c.Slack.RateLimit.Window = "-1m" },
+		func(c *Config) { c.Slack.RateLimit.PerPlan = -1 },
+		func(c *Config) { c.Slack.RateLimit.Global = -1 },
+	} {
+		cfg := Defaults()
+		mutate(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject rate limit %+v", cfg.Slack.RateLimit)
+		}
+	}
+}
+
+func TestValidate_SlackDiffPreview(t *testing.T) {
+	for _, mode := range []string{"", DiffPreviewDiff, DiffPreviewLink} {
+		cfg := Defaults()
+		cfg.Slack.DiffPreview.Mode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with diff preview mode %q error = %v", mode, err)
+		}
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Slack.DiffPreview.Mode = "patch" },
+		func(c *Config) { c.Slack.DiffPreview.MaxLines = -1 },
+		func(c *Config) { c.Slack.DiffPreview.MaxFiles = -1 },
+	} {
+		cfg := Defaults()
+		mutate(cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject diff preview %+v", cfg.Slack.DiffPreview)
+		}
+	}
+}
+
+func TestLoadWithDefaults_RedactPatterns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if err := os.WriteFile(path, []byte("redact:\n  patterns:\n    - 'AKIA[0-9A-Z]{16}'\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if len(cfg.Redact.Patterns) != 1 || cfg.Redact.Patterns[0] != "AKIA[0-9A-Z]{16}" {
+		t.Errorf("Redact.Patterns = %v", cfg.Redact.Patterns)
+	}
+}
+
+func TestValidate_RedactPatterns(t *testing.T) {
+	cfg := Defaults()
+	cfg.Redact.Patterns = []string{"token-[a-z]+"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Redact.Patterns = []string{"token-[a-z"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an invalid redact pattern")
+	}
+}
+
+func TestLoadWithDefaults_WorkerRetries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if err := os.WriteFile(path, []byte("worker:\n  plan_retries: 3\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if cfg.Worker.PlanRetries != 3 {
+		t.Errorf("Worker.PlanRetries = %d, want 3", cfg.Worker.PlanRetries)
+	}
+	if cfg.Worker.RetryBackoff != "5m" {
+		t.Errorf("Worker.RetryBackoff = %q, want default %q", cfg.Worker.RetryBackoff, "5m")
+	}
+}
+
+func TestValidate_WorkerRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries int
+		backoff string
+		wantErr bool
+	}{
+		{"defaults", 0, "5m", false},
+		{"custom", 2, "30s", false},
+		{"negative retries", -1, "5m", true},
+		{"bad backoff", 2, "soon", true},
+		{"zero backoff", 2, "0s", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Worker.PlanRetries = tt.retries
+			cfg.Worker.RetryBackoff = tt.backoff
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifierConfig_Enabled(t *testing.T) {
+	if (VerifierConfig{}).Enabled() {
+		t.Error("empty verifier should be disabled")
+	}
+	if !(VerifierConfig{Model: "opus"}).Enabled() || !(VerifierConfig{ClaudePath: "/opt/claude"}).Enabled() {
+		t.Error("a model or executable should enable the verifier")
+	}
+}
+
+func TestValidate_ApprovalTimeout(t *testing.T) {
+	tests := []struct {
+		timeout string
+		wantErr bool
+	}{
+		{"", false},
+		{"24h", false},
+		{"tomorrow", true},
+		{"-1h", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timeout, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Completion.ApprovalTimeout = tt.timeout
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_AutoMerge(t *testing.T) {
+	tests := []struct {
+		strategy string
+		wantErr  bool
+	}{
+		{"", false},
+		{"squash", false},
+		{"rebase", false},
+		{"merge", false},
+		{"queue", false},
+		{"fast-forward", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Completion.AutoMerge = tt.strategy
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Blockers(t *testing.T) {
+	tests := []struct {
+		name     string
+		blockers BlockersConfig
+		wantErr  bool
+	}{
+		{"unset", BlockersConfig{}, false},
+		{"full policy", BlockersConfig{EscalateAfter: "2h", MaxWait: "24h", Page: PageConfig{Provider: PagePagerDuty, Key: "k"}}, false},
+		{"webhook", BlockersConfig{Page: PageConfig{Provider: PageWebhook, URL: "https://alerts.example.com"}}, false},
+		{"bad escalate_after", BlockersConfig{EscalateAfter: "later"}, true},
+		{"zero max_wait", BlockersConfig{MaxWait: "0s"}, true},
+		{"unknown provider", BlockersConfig{Page: PageConfig{Provider: "pager"}}, true},
+		{"webhook without url", BlockersConfig{Page: PageConfig{Provider: PageWebhook}}, true},
+		{"bad url", BlockersConfig{Page: PageConfig{Provider: PageOpsgenie, URL: "api.opsgenie.com"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Blockers = tt.blockers
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_AskTimeout(t *testing.T) {
+	for timeout, wantErr := range map[string]bool{"": false, "30m": false, "0": false, "soon": true, "-1m": true} {
+		cfg := Defaults()
+		cfg.Ask.Timeout = timeout
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate() with ask.timeout %q error = %v, wantErr %v", timeout, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_MaxPlanDuration(t *testing.T) {
+	for limit, wantErr := range map[string]bool{"": false, "6h": false, "90m": false, "0": true, "-1h": true, "long": true} {
+		cfg := Defaults()
+		cfg.Worker.MaxPlanDuration = limit
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate() with worker.max_plan_duration %q error = %v, wantErr %v", limit, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_ResourceAlerts(t *testing.T) {
+	tests := map[string]struct {
+		alerts  ResourceAlertConfig
+		wantErr bool
+	}{
+		"defaults":           {Defaults().Runner.ResourceAlerts, false},
+		"off":                {ResourceAlertConfig{}, false},
+		"all set":            {ResourceAlertConfig{CPUTime: "2h", Memory: "512MB", Subprocesses: 50}, false},
+		"bad cpu time":       {ResourceAlertConfig{CPUTime: "lots"}, true},
+		"zero cpu time":      {ResourceAlertConfig{CPUTime: "0s"}, true},
+		"bad memory":         {ResourceAlertConfig{Memory: "8 gigs"}, true},
+		"negative processes": {ResourceAlertConfig{Subprocesses: -1}, true},
+	}
+	for name, tt := range tests {
+		cfg := Defaults()
+		cfg.Runner.ResourceAlerts = tt.alerts
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_RunnerOrphanProcesses(t *testing.T) {
+	for policy, wantErr := range map[string]bool{"": false, "kill": false, "report": false, "ignore": true} {
+		cfg := Defaults()
+		cfg.Runner.OrphanProcesses = policy
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate(orphan_processes=%q) error = %v, wantErr %v", policy, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_WorkerFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		wantErr bool
+	}{
+		{"unset", nil, nil, false},
+		{"globs", []string{"infra-*"}, []string{"infra-legacy-*", "docs-?"}, false},
+		{"bad include", []string{"[infra"}, nil, true},
+		{"empty exclude", nil, []string{""}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Worker.Include = tt.include
+			cfg.Worker.Exclude = tt.exclude
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_WorkerMainRepoCheck(t *testing.T) {
+	for mode, wantErr := range map[string]bool{
+		"":     false,
+		"off":  false,
+		"warn": false,
+		"fail": false,
+		"deny": true,
+	} {
+		cfg := Defaults()
+		cfg.Worker.MainRepoCheck = mode
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate(main_repo_check=%q) error = %v, wantErr %v", mode, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_WorktreeInterruptedOps(t *testing.T) {
+	for policy, wantErr := range map[string]bool{"": false, "abort": false, "block": false, "reset": true} {
+		cfg := Defaults()
+		cfg.Worktree.InterruptedOps = policy
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate(interrupted_ops=%q) error = %v, wantErr %v", policy, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_ServeAddr(t *testing.T) {
+	for addr, wantErr := range map[string]bool{
+		"":               false,
+		"127.0.0.1:8484": false,
+		":9000":          false,
+		"localhost":      true,
+	} {
+		cfg := Defaults()
+		cfg.Serve.Addr = addr
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate() with serve.addr %q error = %v, wantErr %v", addr, err, wantErr)
+		}
+	}
+}
+
+func TestLoadWithDefaults_Runner(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	content := "runner:\n  claude_path: /opt/claude/bin/claude\n  max_claude_version: \"2.0\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+
+	if cfg.Runner.ClaudePath != "/opt/claude/bin/claude" || cfg.Runner.MaxClaudeVersion != "2.0" {
+		t.Errorf("Runner = %+v", cfg.Runner)
+	}
+	if cfg.Runner.MinClaudeVersion != "1.0.0" {
+		t.Errorf("Runner.MinClaudeVersion = %q, want default %q", cfg.Runner.MinClaudeVersion, "1.0.0")
+	}
+}
+
+func TestValidate_RunnerVersions(t *testing.T) {
+	cfg := Defaults()
+	cfg.Runner.MaxClaudeVersion = "2.1.0"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.Runner.MinClaudeVersion = "latest"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a non-numeric version")
+	}
+}
+
+func TestValidate_MCPServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  MCPServer
+		wantErr bool
+	}{
+		{"command", MCPServer{Command: "npx", Args: []string{"-y", "server"}}, false},
+		{"url", MCPServer{Type: "http", URL: "http://localhost:3000/mcp"}, false},
+		{"sse", MCPServer{Type: "sse", URL: "http://localhost:3000/sse"}, false},
+		{"neither", MCPServer{Type: "stdio"}, true},
+		{"both", MCPServer{Command: "npx", URL: "http://localhost"}, true},
+		{"unknown type", MCPServer{Type: "websocket", URL: "ws://localhost"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Runner.MCPServers = map[string]MCPServer{"test": tt.server}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Stages(t *testing.T) {
+	tests := []struct {
+		name    string
+		stages  []StageConfig
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"pipeline", []StageConfig{{Name: "plan", Completion: "marker"}, {Name: "implement", Completion: "tasks"}, {Name: "review", MaxIterations: 2}}, false},
+		{"missing name", []StageConfig{{Goal: "Plan"}}, true},
+		{"duplicate name", []StageConfig{{Name: "test"}, {Name: "test"}}, true},
+		{"unknown completion", []StageConfig{{Name: "plan", Completion: "done"}}, true},
+		{"negative max", []StageConfig{{Name: "plan", MaxIterations: -1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Stages = tt.stages
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_GitProvider(t *testing.T) {
+	for provider, wantErr := range map[string]bool{"": false, "github": false, "gitlab": false, "bitbucket": false, "gitea": true} {
+		cfg := Defaults()
+		cfg.Git.Provider = provider
+		if err := cfg.Validate(); (err != nil) != wantErr {
+			t.Errorf("Validate(git.provider=%q) error = %v, wantErr %v", provider, err, wantErr)
+		}
+	}
+}
+
+func TestValidate_DiffLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		git     GitConfig
+		wantErr bool
+	}{
+		{"unset", GitConfig{}, false},
+		{"split", GitConfig{MaxDiffFiles: 40, MaxDiffLines: 1500, DiffLimitAction: DiffLimitSplit}, false},
+		{"block", GitConfig{MaxDiffLines: 1500, DiffLimitAction: DiffLimitBlock}, false},
+		{"negative files", GitConfig{MaxDiffFiles: -1}, true},
+		{"negative lines", GitConfig{MaxDiffLines: -1}, true},
+		{"negative recent commits", GitConfig{RecentCommits: -1}, true},
+		{"unknown action", GitConfig{MaxDiffLines: 100, DiffLimitAction: "revert"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Git = tt.git
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_BranchTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{"unset", "", false},
+		{"date", "ralph/{{.Date}}/{{.Name}}", false},
+		{"ticket", "{{.Jira}}-{{.Name}}", false},
+		{"bad syntax", "ralph/{{.Name", true},
+		{"unknown field", "ralph/{{.Owner}}", true},
+		{"invalid ref", "ralph:{{.Name}}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Git.BranchTemplate = tt.template
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_StateBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr bool
+	}{
+		{"unset", "", false},
+		{"valid", "ralph-state", false},
+		{"nested", "ralph/state", false},
+		{"invalid ref", "ralph state", true},
+		{"base branch", "main", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Git.StateBranch = tt.branch
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{"1024", 1024, false},
+		{"500KB", 500 << 10, false},
+		{"5MB", 5 << 20, false},
+		{"1.5 mb", 3 << 19, false},
+		{"2GB", 2 << 30, false},
+		{"5 megabytes", 0, true},
+		{"-1MB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_FileProtection(t *testing.T) {
+	tests := []struct {
+		name    string
+		git     GitConfig
+		wantErr bool
+	}{
+		{"defaults", Defaults().Git, false},
+		{"patterns", GitConfig{MaxFileSize: "0", DenyPatterns: []string{"*.zip", "assets/*.psd", "dist/"}}, false},
+		{"bad size", GitConfig{MaxFileSize: "huge"}, true},
+		{"bad pattern", GitConfig{DenyPatterns: []string{"[abc"}}, true},
+		{"empty pattern", GitConfig{DenyPatterns: []string{"/"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Git = tt.git
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_TestPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{"defaults", Defaults().TDD.TestPatterns, false},
+		{"none", nil, false},
+		{"bad pattern", []string{"[abc"}, true},
+		{"empty pattern", []string{"/"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.TDD.TestPatterns = tt.patterns
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Coverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		coverage CoverageConfig
+		wantErr  bool
+	}{
+		{"defaults", Defaults().Coverage, false},
+		{"go", CoverageConfig{Format: CoverageGo, Report: "coverage.out"}, false},
+		{"lcov without report", CoverageConfig{Format: CoverageLCOV}, true},
+		{"unknown format", CoverageConfig{Format: "cobertura"}, true},
+		{"pattern without group", CoverageConfig{Pattern: `[0-9]+%`}, true},
+		{"bad pattern", CoverageConfig{Pattern: `([0-9]+`}, true},
+		{"negative max drop", CoverageConfig{MaxDrop: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Coverage = tt.coverage
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_Bench(t *testing.T) {
+	tests := []struct {
+		name    string
+		bench   BenchConfig
+		wantErr bool
+	}{
+		{"defaults", Defaults().Bench, false},
+		{"block", BenchConfig{Command: "make bench", Threshold: 5, Action: BenchBlock}, false},
+		{"negative threshold", BenchConfig{Threshold: -1}, true},
+		{"unknown action", BenchConfig{Action: "page"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.Bench = tt.bench
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_FlakyRetries(t *testing.T) {
+	cfg := Defaults()
+	cfg.Flaky.Retries = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject negative flaky.retries")
+	}
+}
+
+func TestValidate_KnowledgeLimits(t *testing.T) {
+	cfg := Defaults()
+	cfg.Knowledge.MaxEntries = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject negative knowledge.max_entries")
+	}
+
+	cfg = Defaults()
+	cfg.Knowledge.MaxTokens = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject negative knowledge.max_tokens")
+	}
+}
+
+func TestValidate_StateBackend(t *testing.T) {
+	for _, backend := range []string{"", StateFiles, StateSQLite} {
+		cfg := Defaults()
+		cfg.State.Backend = backend
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with state.backend %q error = %v", backend, err)
+		}
+	}
+
+	cfg := Defaults()
+	cfg.State.Backend = "postgres"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown state.backend")
+	}
+}
+
+func TestValidate_GCRetention(t *testing.T) {
+	cfg := Defaults()
+	cfg.GC.RetentionDays = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject negative gc.retention_days")
+	}
+}
+
+func TestValidate_Locale(t *testing.T) {
+	for _, locale := range []string{"", "en", "de", "de_DE.UTF-8"} {
+		cfg := Defaults()
+		cfg.Project.Locale = locale
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with locale %q error = %v", locale, err)
+		}
+	}
+	cfg := Defaults()
+	cfg.Project.Locale = "xx"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unshipped project.locale")
+	}
+}
+
+func TestValidate_FeedbackAuthors(t *testing.T) {
+	cfg := Defaults()
+	cfg.Feedback.Authors = map[string]string{"alice": "Alice Smith"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	cfg.Feedback.Authors["bob"] = " "
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an empty feedback.authors name")
+	}
+}
+
+func TestValidate_WorktreePreset(t *testing.T) {
+	for _, preset := range []string{"", PresetGo, PresetNode, PresetPython, PresetRust} {
+		cfg := Defaults()
+		cfg.Worktree.Preset = preset
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with preset %q error = %v", preset, err)
+		}
+	}
+	cfg := Defaults()
+	cfg.Worktree.Preset = "java"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown worktree.preset")
+	}
+}
+
+func TestStageConfig_CompletionFor(t *testing.T) {
+	if got := (StageConfig{}).CompletionFor(false); got != StageCompletionMarker {
+		t.Errorf("CompletionFor(false) = %q, want marker", got)
+	}
+	if got := (StageConfig{}).CompletionFor(true); got != StageCompletionVerify {
+		t.Errorf("CompletionFor(true) = %q, want verify", got)
+	}
+	if got := (StageConfig{Completion: StageCompletionTasks}).CompletionFor(true); got != StageCompletionTasks {
+		t.Errorf("CompletionFor() with explicit completion = %q", got)
+	}
+}
+
+func TestValidate_PermissionMode(t *testing.T) {
+	for _, mode := range append([]string{""}, PermissionModes...) {
+		cfg := Defaults()
+		cfg.Runner.PermissionMode = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with permission mode %q error = %v", mode, err)
+		}
+	}
+
+	cfg := Defaults()
+	cfg.Runner.PermissionMode = "yolo"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown permission mode")
+	}
+}
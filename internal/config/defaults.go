@@ -8,7 +8,8 @@ func Defaults() *Config {
 			Description: "",
 		},
 		Git: GitConfig{
-			BaseBranch: "main",
+			BaseBranch:  "main",
+			MaxFileSize: "5MB",
 		},
 		Commands: CommandsConfig{
 			Test:  "",
@@ -17,24 +18,92 @@ func Defaults() *Config {
 			Dev:   "",
 		},
 		Slack: SlackConfig{
-			WebhookURL:      "",
-			Channel:         "",
-			BotToken:        "",
-			AppToken:        "",
-			GlobalBot:       false,
-			NotifyStart:     true,
-			NotifyComplete:  true,
-			NotifyIteration: false,
-			NotifyError:     true,
-			NotifyBlocker:   true,
+			WebhookURL:          "",
+			Channel:             "",
+			BotToken:            "",
+			AppToken:            "",
+			GlobalBot:           false,
+			NotifyStart:         true,
+			NotifyComplete:      true,
+			NotifyIteration:     false,
+			NotifyError:         true,
+			NotifyBlocker:       true,
+			ThreadRetentionDays: 30,
+			MaxThreads:          500,
+			RateLimit: RateLimitConfig{
+				Window:  "",
+				PerPlan: 5,
+				Global:  10,
+			},
+			DiffPreview: DiffPreviewConfig{
+				MaxLines: 40,
+				MaxFiles: 20,
+			},
 		},
 		Worktree: WorktreeConfig{
-			CopyEnvFiles: ".env",
-			InitCommands: "",
+			CopyEnvFiles:   ".env",
+			InitCommands:   "",
+			InterruptedOps: InterruptedOpsAbort,
 		},
 		Completion: CompletionConfig{
 			Mode:              "pr",
 			VerificationModel: "claude-3-5-haiku-latest",
+			ApprovalTimeout:   "24h",
+		},
+		Runner: RunnerConfig{
+			MinClaudeVersion: "1.0.0",
+			ResourceAlerts: ResourceAlertConfig{
+				Memory:       "8GB",
+				Subprocesses: 100,
+			},
+			OrphanProcesses: OrphanProcessesKill,
+		},
+		Worker: WorkerConfig{
+			PlanRetries:   0,
+			RetryBackoff:  "5m",
+			MainRepoCheck: MainRepoCheckWarn,
+		},
+		Serve: ServeConfig{
+			Addr: "127.0.0.1:8484",
+		},
+		Jira: JiraConfig{
+			InProgress: "In Progress",
+			InReview:   "In Review",
+			Done:       "Done",
+		},
+		Linear: LinearConfig{
+			APIURL:       "https://api.linear.app/graphql",
+			TriggerLabel: "ralph",
+			InProgress:   "In Progress",
+			InReview:     "In Review",
+			Done:         "Done",
+		},
+		Coverage: CoverageConfig{
+			Format:  "regex",
+			Pattern: `([0-9]+(?:\.[0-9]+)?)%`,
+		},
+		Flaky: FlakyConfig{
+			Retries: 2,
+		},
+		Knowledge: KnowledgeConfig{
+			MaxEntries: 8,
+			MaxTokens:  800,
+		},
+		State: StateConfig{
+			Backend: StateFiles,
+		},
+		GC: GCConfig{
+			RetentionDays: 30,
+		},
+		Bench: BenchConfig{
+			Threshold: 10,
+			Action:    BenchFeedback,
+		},
+		Ask: AskConfig{
+			Timeout: "30m",
+		},
+		TDD: TDDConfig{
+			TestPatterns: []string{"*_test.go", "test_*.py", "*_test.py", "*.test.*", "*.spec.*", "test/", "tests/", "__tests__/", "spec/", "testdata/"},
 		},
 	}
 }
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalCommented renders cfg as a config.yaml with a comment explaining
+// each setting, as written by `ralph init`. Loading the result yields cfg.
+func MarshalCommented(cfg *Config) []byte {
+	var sb strings.Builder
+	w := func(format string, args ...interface{}) {
+		sb.WriteString(fmt.Sprintf(format, args...))
+	}
+
+	w("# Ralph configuration\n")
+	w("# Settings left empty fall back to built-in defaults.\n\n")
+
+	w("project:\n")
+	w("  name: %s  # Project name used in prompts\n", yamlString(cfg.Project.Name))
+	w("  description: %s  # Short description used in prompts\n", yamlString(cfg.Project.Description))
+	w("  locale: %s  # Language of Slack notifications and CLI output: en or de (empty = from the environment)\n\n", yamlString(cfg.Project.Locale))
+
+	w("git:\n")
+	w("  base_branch: %s  # Branch feature branches are created from and merged into\n", yamlString(cfg.Git.BaseBranch))
+	w("  branch_template: %s  # Plan branch names, e.g. \"ralph/{{.Date}}/{{.Name}}\" or \"{{.Jira}}/{{.Name}}\" (empty = feat/<plan>)\n", yamlString(cfg.Git.BranchTemplate))
+	w("  delete_branch_on_merge: %t  # Delete the plan branch on origin and prune local refs once it's merged\n", cfg.Git.DeleteBranchOnMerge)
+	w("  max_diff_files: %d  # Don't auto-commit an iteration that changes more files than this (0 = no limit)\n", cfg.Git.MaxDiffFiles)
+	w("  max_diff_lines: %d  # Don't auto-commit an iteration that changes more lines than this (0 = no limit)\n", cfg.Git.MaxDiffLines)
+	w("  diff_limit_action: %s  # \"split\" asks the agent to commit in smaller chunks, \"block\" raises a blocker\n", yamlString(cfg.Git.DiffLimitAction))
+	w("  max_file_size: %s  # Never commit files larger than this, e.g. \"5MB\" (\"0\" = no limit)\n", yamlString(cfg.Git.MaxFileSize))
+	w("  deny_patterns: %s  # Files never committed, e.g. [\"*.zip\", \"dist/\"]\n", yamlList(cfg.Git.DenyPatterns))
+	w("  recent_commits: %d  # Summarize this many recent base and plan branch commits in the prompt (0 = off)\n", cfg.Git.RecentCommits)
+	w("  state_branch: %s  # Mirror plans/ to this branch for team visibility, e.g. \"ralph-state\" (empty = off)\n", yamlString(cfg.Git.StateBranch))
+	w("  state_push: %t  # Push the state branch to origin after each update\n", cfg.Git.StatePush)
+	w("  provider: %s  # github, gitlab, or bitbucket, for links to origin (empty = detect from the host)\n", yamlString(cfg.Git.Provider))
+	w("  pr:  # Pull requests opened in pr mode (plans override with **Reviewers:**, **Assignees:**, **PR Labels:**, **Draft:**)\n")
+	w("    reviewers: %s  # GitHub logins or org/team slugs\n", yamlList(cfg.Git.PR.Reviewers))
+	w("    assignees: %s  # GitHub logins (\"@me\" = the gh user)\n", yamlList(cfg.Git.PR.Assignees))
+	w("    labels: %s  # Labels must exist in the repository\n", yamlList(cfg.Git.PR.Labels))
+	w("    draft: %t  # Open pull requests as drafts\n", cfg.Git.PR.Draft)
+	w("    codeowners: %t  # Also request review from the CODEOWNERS of the changed files\n", cfg.Git.PR.CodeOwners)
+	w("    ignore_template: %t  # Don't fill in the repository's pull request template\n\n", cfg.Git.PR.IgnoreTemplate)
+
+	w("# Commands the agent runs to verify its work\n")
+	w("commands:\n")
+	w("  test: %s\n", yamlString(cfg.Commands.Test))
+	w("  lint: %s\n", yamlString(cfg.Commands.Lint))
+	w("  build: %s\n", yamlString(cfg.Commands.Build))
+	w("  dev: %s\n\n", yamlString(cfg.Commands.Dev))
+
+	w("completion:\n")
+	w("  mode: %s  # \"pr\" to open a pull request, \"merge\" to merge into base_branch\n", yamlString(cfg.Completion.Mode))
+	w("  verification_model: %s  # Model that verifies a plan is really complete\n", yamlString(cfg.Completion.VerificationModel))
+	w("  second_verifier:  # A second, independent verification; both must agree (empty = off)\n")
+	w("    model: %s\n", yamlString(cfg.Completion.SecondVerifier.Model))
+	w("    claude_path: %s  # Executable for the second verifier (empty = runner.claude_path)\n", yamlString(cfg.Completion.SecondVerifier.ClaudePath))
+	w("  gates: %t  # Run commands.test and commands.lint on completion and report the results\n", cfg.Completion.Gates)
+	w("  require_approval: %t  # Wait for ralph approve (or the Slack button) before the PR/merge\n", cfg.Completion.RequireApproval)
+	w("  approval_timeout: %s  # Move the plan to failed/ if nobody approves within this long\n", yamlString(cfg.Completion.ApprovalTimeout))
+	w("  auto_merge: %s  # Enable GitHub auto-merge on the PR: squash, rebase, merge, or queue (merge queue); empty = off\n\n", yamlString(cfg.Completion.AutoMerge))
+
+	w("# Pipeline each plan runs through, e.g. plan -> implement -> test -> review (empty = one stage)\n")
+	if len(cfg.Stages) == 0 {
+		w("stages: []\n\n")
+	} else {
+		w("stages:\n")
+		for _, stage := range cfg.Stages {
+			w("  - name: %s\n", yamlString(stage.Name))
+			w("    prompt: %s  # Prompt template (empty = prompt.md)\n", yamlString(stage.Prompt))
+			w("    goal: %s  # What the stage should achieve, shown to the agent\n", yamlString(stage.Goal))
+			w("    completion: %s  # marker, tasks, or verify (empty = verify for the last stage, marker otherwise)\n", yamlString(stage.Completion))
+			w("    max_iterations: %d  # Fail if the stage isn't done in this many iterations (0 = no limit)\n", stage.MaxIterations)
+			w("    model: %s  # Model for this stage, e.g. \"haiku\" (empty = plan or runner.model)\n", yamlString(stage.Model))
+		}
+		w("\n")
+	}
+
+	w("worktree:\n")
+	w("  copy_env_files: %s  # Comma-separated env files copied into each worktree\n", yamlString(cfg.Worktree.CopyEnvFiles))
+	w("  init_commands: %s  # Custom worktree setup (skips dependency auto-detection if set)\n", yamlString(cfg.Worktree.InitCommands))
+	w("  complete_hooks: %s  # Commands run in the worktree after completion, before cleanup\n", yamlString(cfg.Worktree.CompleteHooks))
+	w("  submodules: %t  # Initialize submodules (recursively) in new worktrees\n", cfg.Worktree.Submodules)
+	w("  lfs: %t  # Fetch git-lfs objects in new worktrees\n", cfg.Worktree.LFS)
+	w("  preset: %s  # go, node, python or rust setup (empty = detect from go.mod, package.json, ...)\n", yamlString(cfg.Worktree.Preset))
+	w("  interrupted_ops: %s  # Half-done merge or rebase in a worktree: \"abort\" it, or \"block\" for a human\n", yamlString(cfg.Worktree.InterruptedOps))
+	w("  scratch_dir: %s  # Per-plan scratch dirs ($RALPH_SCRATCH) for build artifacts and temp files (empty = system temp dir)\n\n", yamlString(cfg.Worktree.ScratchDir))
+
+	w("hooks:\n")
+	w("  on_plan_complete: %s  # Commands or http(s) URLs run after a plan completes\n", yamlList(cfg.Hooks.OnPlanComplete))
+	w("  on_plan_error: %s  # Commands or http(s) URLs run when a plan fails\n", yamlList(cfg.Hooks.OnPlanError))
+	w("  pre_iteration: %s  # Command run in the worktree before each prompt\n", yamlString(cfg.Hooks.PreIteration))
+	w("  capture_pre_iteration: %t  # Include pre_iteration output in the prompt\n\n", cfg.Hooks.CapturePreIteration)
+
+	w("runner:\n")
+	w("  claude_path: %s  # claude executable (empty = claude on PATH)\n", yamlString(cfg.Runner.ClaudePath))
+	w("  min_claude_version: %s  # Oldest supported claude CLI version\n", yamlString(cfg.Runner.MinClaudeVersion))
+	w("  max_claude_version: %s  # Pin claude below this version (empty = no limit)\n", yamlString(cfg.Runner.MaxClaudeVersion))
+	w("  model: %s  # Model for plan iterations, e.g. \"sonnet\" (empty = claude's default)\n", yamlString(cfg.Runner.Model))
+	w("  skip_preflight: %t  # Skip the claude install/version/auth check at startup\n", cfg.Runner.SkipPreflight)
+	w("  allowed_tools: %s  # Tools claude may use without asking, e.g. [\"Edit\", \"Bash(go test:*)\"]\n", yamlList(cfg.Runner.AllowedTools))
+	w("  disallowed_tools: %s  # Tools claude may never use, e.g. [\"WebFetch\", \"Bash(curl:*)\"]\n", yamlList(cfg.Runner.DisallowedTools))
+	w("  permission_mode: %s  # default, acceptEdits, plan, or bypassPermissions (empty = claude's default)\n", yamlString(cfg.Runner.PermissionMode))
+	w("  mcp_servers: %s  # MCP servers for every worktree, e.g. {db: {command: npx, args: [...]}}\n", yamlMap(cfg.Runner.MCPServers))
+	w("  resource_alerts:  # Flag iterations using more than these (empty or 0 = no limit)\n")
+	w("    cpu_time: %s  # CPU time of claude and its subprocesses, e.g. \"2h\"\n", yamlString(cfg.Runner.ResourceAlerts.CPUTime))
+	w("    memory: %s  # Peak memory of the process tree, e.g. \"8GB\"\n", yamlString(cfg.Runner.ResourceAlerts.Memory))
+	w("    subprocesses: %d  # Subprocesses running at once\n", cfg.Runner.ResourceAlerts.Subprocesses)
+	w("  orphan_processes: %s  # Processes claude leaves running: \"kill\" them, or \"report\" only\n\n", yamlString(cfg.Runner.OrphanProcesses))
+
+	w("worker:\n")
+	w("  plan_retries: %d  # Requeue plans that fail transiently (rate limits, network) this many times\n", cfg.Worker.PlanRetries)
+	w("  retry_backoff: %s  # Delay before the first retry; doubles with each retry\n", yamlString(cfg.Worker.RetryBackoff))
+	w("  max_plan_duration: %s  # Stop plans running longer than this, e.g. \"6h\" (empty = no limit)\n", yamlString(cfg.Worker.MaxPlanDuration))
+	w("  avoid_overlap: %t  # Hold back plans whose likely paths overlap an unmerged plan branch\n", cfg.Worker.AvoidOverlap)
+	w("  include: %s  # Only process plans whose names match these globs, e.g. [\"infra-*\"]\n", yamlList(cfg.Worker.Include))
+	w("  exclude: %s  # Never process plans whose names match these globs\n", yamlList(cfg.Worker.Exclude))
+	w("  main_repo_check: %s  # Main worktree on the wrong branch, behind, or dirty under plans/: \"off\", \"warn\", or \"fail\"\n\n", yamlString(cfg.Worker.MainRepoCheck))
+
+	w("redact:\n")
+	w("  patterns: %s  # Extra regexes masked in logs, transcripts, and Slack messages\n\n", yamlList(cfg.Redact.Patterns))
+
+	w("serve:\n")
+	w("  addr: %s  # Address `ralph serve` listens on\n", yamlString(cfg.Serve.Addr))
+	w("  ingest_token: %s  # Bearer token for POST /plans (RALPH_INGEST_TOKEN overrides)\n\n", yamlString(cfg.Serve.IngestToken))
+
+	w("jira:\n")
+	w("  url: %s  # Jira site, e.g. \"https://acme.atlassian.net\" (empty = no Jira sync)\n", yamlString(cfg.Jira.URL))
+	w("  email: %s  # Account for Jira Cloud (empty = send api_token as a bearer token)\n", yamlString(cfg.Jira.Email))
+	w("  api_token: %s  # API token (JIRA_API_TOKEN overrides)\n", yamlString(cfg.Jira.APIToken))
+	w("  in_progress: %s  # Status when the plan starts\n", yamlString(cfg.Jira.InProgress))
+	w("  in_review: %s  # Status when the pull request is opened\n", yamlString(cfg.Jira.InReview))
+	w("  done: %s  # Status when the plan is merged\n\n", yamlString(cfg.Jira.Done))
+
+	w("linear:\n")
+	w("  api_key: %s  # API key (LINEAR_API_KEY overrides; empty = no Linear sync)\n", yamlString(cfg.Linear.APIKey))
+	w("  api_url: %s  # GraphQL endpoint\n", yamlString(cfg.Linear.APIURL))
+	w("  webhook_secret: %s  # Signing secret for ralph serve --linear (LINEAR_WEBHOOK_SECRET overrides)\n", yamlString(cfg.Linear.WebhookSecret))
+	w("  trigger_label: %s  # Issues given this label are queued as plans by the webhook\n", yamlString(cfg.Linear.TriggerLabel))
+	w("  in_progress: %s  # Workflow state when the plan starts\n", yamlString(cfg.Linear.InProgress))
+	w("  in_review: %s  # Workflow state when the pull request is opened\n", yamlString(cfg.Linear.InReview))
+	w("  done: %s  # Workflow state when the plan is merged\n\n", yamlString(cfg.Linear.Done))
+
+	w("github:\n")
+	w("  issues: %t  # Sync plans' **GitHub:** issues through gh\n", cfg.GitHub.Issues)
+	w("  repo: %s  # OWNER/NAME of the issues (empty = the current repository)\n", yamlString(cfg.GitHub.Repo))
+	w("  in_progress: %s  # Label added when the plan starts (empty = none)\n", yamlString(cfg.GitHub.InProgress))
+	w("  in_review: %s  # Label swapped in when the pull request is opened; issues close on merge\n\n", yamlString(cfg.GitHub.InReview))
+
+	w("blockers:\n")
+	w("  escalate_after: %s  # Re-notify and page when a blocker stays unresolved this long (empty = never)\n", yamlString(cfg.Blockers.EscalateAfter))
+	w("  mention: %s  # Who the escalation mentions: @here, @channel, or a Slack user/group ID\n", yamlString(cfg.Blockers.Mention))
+	w("  max_wait: %s  # Move the plan to failed/ when a blocker stays unresolved this long (empty = never)\n", yamlString(cfg.Blockers.MaxWait))
+	w("  page:\n")
+	w("    provider: %s  # pagerduty, opsgenie, or webhook (empty = no paging)\n", yamlString(cfg.Blockers.Page.Provider))
+	w("    url: %s  # Endpoint override; required for webhook\n", yamlString(cfg.Blockers.Page.URL))
+	w("    key: %s  # PagerDuty routing key or Opsgenie API key (RALPH_PAGE_KEY overrides)\n\n", yamlString(cfg.Blockers.Page.Key))
+
+	w("ask:\n")
+	w("  timeout: %s  # How long the loop waits for an answer to an <ask> question (\"0\" = don't wait)\n\n", yamlString(cfg.Ask.Timeout))
+
+	w("tdd:\n")
+	w("  test_patterns: %s  # Files the test phase of **Mode:** tdd plans may change\n\n", yamlList(cfg.TDD.TestPatterns))
+
+	w("coverage:\n")
+	w("  command: %s  # Measures coverage before the first and after every iteration (empty = off)\n", yamlString(cfg.Coverage.Command))
+	w("  format: %s  # go (coverprofile), lcov, or regex (the command output)\n", yamlString(cfg.Coverage.Format))
+	w("  report: %s  # Coverprofile or lcov file the command writes, for go and lcov\n", yamlString(cfg.Coverage.Report))
+	w("  pattern: %s  # Regex whose first group is the percentage; the last match wins\n", yamlString(cfg.Coverage.Pattern))
+	w("  max_drop: %g  # Percentage points coverage may fall below the base branch\n", cfg.Coverage.MaxDrop)
+	w("  gate: %t  # Fail the plan when coverage drops more than max_drop\n\n", cfg.Coverage.Gate)
+
+	w("flaky:\n")
+	w("  retries: %d  # Re-run a failing commands.test this often; inconsistent tests are flaky (0 = never)\n", cfg.Flaky.Retries)
+	w("  rerun: %s  # Command re-running only the failing tests, with {{TESTS}} as \"TestA|TestB\" (empty = commands.test)\n\n", yamlString(cfg.Flaky.Rerun))
+
+	w("bench:\n")
+	w("  command: %s  # Benchmarks compared between the base branch and the plan branch (empty = off)\n", yamlString(cfg.Bench.Command))
+	w("  threshold: %g  # Percent a benchmark may get worse before it's a regression\n", cfg.Bench.Threshold)
+	w("  action: %s  # feedback (report regressions) or block (reject completion until fixed)\n\n", yamlString(cfg.Bench.Action))
+
+	w("audit:\n")
+	w("  enabled: %t  # Hash-chained log of queue moves, commits, PRs, and config in .ralph/audit.jsonl\n\n", cfg.Audit.Enabled)
+
+	w("lessons:\n")
+	w("  enabled: %t  # Classify each finished plan's outcome and append lessons to .ralph/lessons.md\n", cfg.Lessons.Enabled)
+	w("  model: %s  # Model for the retrospective (empty = completion.verification_model)\n", yamlString(cfg.Lessons.Model))
+	w("  prompt: %t  # Include .ralph/lessons.md in every iteration's prompt\n\n", cfg.Lessons.Prompt)
+
+	w("knowledge:\n")
+	w("  enabled: %t  # Collect progress gotchas in .ralph/knowledge.md by path and add the relevant ones to prompts\n", cfg.Knowledge.Enabled)
+	w("  max_entries: %d  # Entries per prompt, best matches first (0 = no cap)\n", cfg.Knowledge.MaxEntries)
+	w("  max_tokens: %d  # Estimated token budget for the entries in a prompt (0 = no cap)\n\n", cfg.Knowledge.MaxTokens)
+
+	w("state:\n")
+	w("  backend: %s  # files, or sqlite to mirror the queue and events in .ralph/state.db\n\n", yamlString(cfg.State.Backend))
+
+	w("gc:\n")
+	w("  auto: %t  # Remove finished plans' logs, context files, and Slack thread entries when the worker starts\n", cfg.GC.Auto)
+	w("  retention_days: %d  # Days after a plan finished before its runtime artifacts are removed\n\n", cfg.GC.RetentionDays)
+
+	w("feedback:\n")
+	w("  authors: %s  # Display names for feedback authors: Slack user IDs or ralph feedback --as names, e.g. {alice: \"Alice Smith\"}\n\n", yamlMap(cfg.Feedback.Authors))
+
+	w("slack:\n")
+	w("  webhook_url: %s  # Incoming webhook for simple notifications\n", yamlString(cfg.Slack.WebhookURL))
+	w("  webhook_secret: %s  # Signs webhook payloads (X-Ralph-Signature-256 header)\n", yamlString(cfg.Slack.WebhookSecret))
+	w("  bot_token: %s  # xoxb-... for threaded notifications (optional)\n", yamlString(cfg.Slack.BotToken))
+	w("  app_token: %s  # xapp-... for replies and /ralph commands via Socket Mode (optional)\n", yamlString(cfg.Slack.AppToken))
+	w("  channel: %s  # Channel ID, required for bot features\n", yamlString(cfg.Slack.Channel))
+	w("  global_bot: %t  # Use the shared bot from ~/.ralph/slack.env\n", cfg.Slack.GlobalBot)
+	w("  notify_start: %t\n", cfg.Slack.NotifyStart)
+	w("  notify_complete: %t\n", cfg.Slack.NotifyComplete)
+	w("  notify_error: %t\n", cfg.Slack.NotifyError)
+	w("  notify_blocker: %t\n", cfg.Slack.NotifyBlocker)
+	w("  notify_iteration: %t\n", cfg.Slack.NotifyIteration)
+	w("  thread_retention_days: %d  # Prune threads of plans completed longer ago\n", cfg.Slack.ThreadRetentionDays)
+	w("  max_threads: %d  # Cap on tracked Slack threads\n", cfg.Slack.MaxThreads)
+	w("  digest: %s  # \"hourly\" or \"daily\" to send one summary per period\n", yamlString(cfg.Slack.Digest))
+	w("  plain_text: %t  # Send plain text messages instead of Block Kit\n", cfg.Slack.PlainText)
+	w("  roles: %s  # Slack user ID to viewer, operator, or admin, e.g. {U0123ABCD: admin} (empty = everyone may do everything)\n", yamlMap(cfg.Slack.Roles))
+	w("  default_role: %s  # Role of users not in roles (empty = no access)\n", yamlString(cfg.Slack.DefaultRole))
+	w("  rate_limit:\n")
+	w("    window: %s  # e.g. \"10m\"; notifications over the limits are summarized once per window (empty = no limits)\n", yamlString(cfg.Slack.RateLimit.Window))
+	w("    per_plan: %d  # Notifications per plan per window\n", cfg.Slack.RateLimit.PerPlan)
+	w("    global: %d  # Notifications across plans per window\n", cfg.Slack.RateLimit.Global)
+	w("  diff_preview:\n")
+	w("    mode: %s  # \"diff\" or \"link\" to post each iteration's changes in the plan's thread (empty = off)\n", yamlString(cfg.Slack.DiffPreview.Mode))
+	w("    max_lines: %d  # Diff lines shown before truncating\n", cfg.Slack.DiffPreview.MaxLines)
+	w("    max_files: %d  # Iterations touching more files get the file summary only\n", cfg.Slack.DiffPreview.MaxFiles)
+
+	return []byte(sb.String())
+}
+
+// yamlString quotes s as a YAML scalar. JSON strings are valid YAML.
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// yamlMap renders m as a YAML flow mapping. JSON objects are valid YAML.
+func yamlMap[V any](m map[string]V) string {
+	if len(m) == 0 {
+		return "{}"
+	}
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
+// yamlList renders items as a YAML flow sequence.
+func yamlList(items []string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(items)
+	return string(data)
+}
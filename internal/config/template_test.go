@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMarshalCommented_RoundTrip(t *testing.T) {
+	cfg := Defaults()
+	cfg.Project.Name = `my "quoted" project`
+	cfg.Project.Locale = "de"
+	cfg.Commands.Test = "go test ./... # all"
+	cfg.Completion.Mode = "merge"
+	cfg.Completion.Gates = true
+	cfg.Completion.SecondVerifier = VerifierConfig{Model: "opus", ClaudePath: "/opt/claude/bin/claude"}
+	cfg.Completion.RequireApproval = true
+	cfg.Completion.ApprovalTimeout = "2h"
+	cfg.Completion.AutoMerge = "squash"
+	cfg.Git.MaxDiffFiles = 40
+	cfg.Git.MaxDiffLines = 1500
+	cfg.Git.DiffLimitAction = DiffLimitBlock
+	cfg.Git.MaxFileSize = "10MB"
+	cfg.Git.DenyPatterns = []string{"*.zip", "dist/"}
+	cfg.Git.RecentCommits = 5
+	cfg.Git.StateBranch = "ralph-state"
+	cfg.Git.StatePush = true
+	cfg.Git.Provider = "gitlab"
+	cfg.Git.BranchTemplate = "ralph/{{.Date}}/{{.Name}}"
+	cfg.Git.DeleteBranchOnMerge = true
+	cfg.Git.PR = PRConfig{Reviewers: []string{"alice", "org/team"}, Assignees: []string{"@me"}, Labels: []string{"ralph"}, Draft: true, CodeOwners: true, IgnoreTemplate: true}
+	cfg.Hooks.OnPlanComplete = []string{"./deploy.sh", "https://ci.example.com/hook"}
+	cfg.Hooks.OnPlanError = []string{"./page.sh"}
+	cfg.Hooks.CapturePreIteration = true
+	cfg.Worktree.Submodules = true
+	cfg.Worktree.LFS = true
+	cfg.Worktree.Preset = PresetNode
+	cfg.Worktree.InterruptedOps = InterruptedOpsBlock
+	cfg.Worktree.ScratchDir = "../scratch"
+	cfg.Worker.MaxPlanDuration = "6h"
+	cfg.Worker.AvoidOverlap = true
+	cfg.Worker.Include = []string{"infra-*"}
+	cfg.Worker.Exclude = []string{"infra-legacy-*"}
+	cfg.Worker.MainRepoCheck = MainRepoCheckFail
+	cfg.Redact.Patterns = []string{`AKIA[0-9A-Z]{16}`}
+	cfg.Slack.Digest = "daily"
+	cfg.Slack.WebhookSecret = "s3cret"
+	cfg.Slack.Roles = map[string]string{"U0123ABCD": RoleAdmin}
+	cfg.Slack.DefaultRole = RoleViewer
+	cfg.Slack.PlainText = true
+	cfg.Slack.DiffPreview = DiffPreviewConfig{Mode: DiffPreviewLink, MaxLines: 10, MaxFiles: 5}
+	cfg.Slack.RateLimit = RateLimitConfig{Window: "5m", PerPlan: 2, Global: 4}
+	cfg.Serve.Addr = ":9000"
+	cfg.Serve.IngestToken = "s3cret"
+	cfg.Jira.URL = "https://acme.atlassian.net"
+	cfg.Jira.Email = "bot@acme.com"
+	cfg.Jira.APIToken = "jira-token"
+	cfg.Jira.InReview = "Code Review"
+	cfg.Linear.APIKey = "lin_api_key"
+	cfg.Linear.WebhookSecret = "lin_wh_secret"
+	cfg.Linear.TriggerLabel = "agent"
+	cfg.Linear.Done = "Shipped"
+	cfg.GitHub.Issues = true
+	cfg.GitHub.Repo = "acme/app"
+	cfg.GitHub.InProgress = "ralph: working"
+	cfg.GitHub.InReview = "ralph: review"
+	cfg.Blockers = BlockersConfig{
+		EscalateAfter: "2h",
+		Mention:       "@here",
+		MaxWait:       "24h",
+		Page:          PageConfig{Provider: PageWebhook, URL: "https://alerts.example.com/hook", Key: "k"},
+	}
+	cfg.Ask.Timeout = "1h"
+	cfg.TDD.TestPatterns = []string{"*_test.go", "e2e/"}
+	cfg.Flaky = FlakyConfig{Retries: 3, Rerun: "go test -run '^({{TESTS}})$' ./..."}
+	cfg.Audit.Enabled = true
+	cfg.Lessons = LessonsConfig{Enabled: true, Model: "haiku", Prompt: true}
+	cfg.Knowledge = KnowledgeConfig{Enabled: true, MaxEntries: 5, MaxTokens: 400}
+	cfg.State.Backend = StateSQLite
+	cfg.GC = GCConfig{Auto: true, RetentionDays: 7}
+	cfg.Feedback.Authors = map[string]string{"alice": "Alice Smith", "U0123ABCD": "Bob"}
+	cfg.Bench = BenchConfig{Command: "go test -run=^$ -bench=. ./...", Threshold: 5, Action: BenchBlock}
+	cfg.Coverage = CoverageConfig{Command: "go test -coverprofile=c.out ./...", Format: CoverageGo, Report: "c.out", Pattern: `total: ([0-9.]+)%`, MaxDrop: 0.5, Gate: true}
+	cfg.Runner.AllowedTools = []string{"Edit", "Bash(go test:*)"}
+	cfg.Runner.DisallowedTools = []string{"WebFetch"}
+	cfg.Runner.PermissionMode = "acceptEdits"
+	cfg.Runner.Model = "sonnet"
+	cfg.Runner.ResourceAlerts = ResourceAlertConfig{CPUTime: "2h", Memory: "4GB", Subprocesses: 50}
+	cfg.Runner.OrphanProcesses = OrphanProcessesReport
+	cfg.Stages = []StageConfig{
+		{Name: "plan", Goal: "Expand the task list", MaxIterations: 3, Model: "haiku"},
+		{Name: "implement", Prompt: "prompt.md", Completion: StageCompletionVerify},
+	}
+	cfg.Runner.MCPServers = map[string]MCPServer{
+		"db":  {Command: "npx", Args: []string{"-y", "server-postgres"}, Env: map[string]string{"PGUSER": "dev"}},
+		"web": {Type: "http", URL: "http://localhost:3000/mcp"},
+	}
+
+	data := MarshalCommented(cfg)
+
+	var got Config
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(&got, cfg) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, *cfg)
+	}
+}
+
+func TestMarshalCommented_Defaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := MarshalCommented(Defaults())
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadWithDefaults(path)
+	if err != nil {
+		t.Fatalf("LoadWithDefaults() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Defaults()) {
+		t.Errorf("defaults mismatch:\ngot  %+v\nwant %+v", *cfg, *Defaults())
+	}
+
+	if !strings.Contains(string(data), "# \"pr\" to open a pull request") {
+		t.Errorf("expected explanatory comments:\n%s", data)
+	}
+}
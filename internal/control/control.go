@@ -0,0 +1,416 @@
+// Package control provides the file-based worker control plane.
+// External actors (Slack commands, CLI) write requests to .ralph/control.json;
+// the worker and iteration loop read it between plans and iterations.
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ControlFileName is the name of the control file in the .ralph directory.
+const ControlFileName = "control.json"
+
+// LockFileName is the name of the lock file next to the control file, held
+// while the control file is updated.
+const LockFileName = ControlFileName + ".lock"
+
+// State is the persisted control state.
+type State struct {
+	// Paused stops the worker from starting new iterations or plans.
+	Paused bool `json:"paused"`
+
+	// PausedBy records who paused the worker.
+	PausedBy string `json:"paused_by,omitempty"`
+
+	// PausedAt is when the worker was paused.
+	PausedAt time.Time `json:"paused_at,omitempty"`
+
+	// Skipped lists plan names the worker should pass over.
+	Skipped []string `json:"skipped,omitempty"`
+
+	// Abandoned holds pending abandon requests keyed by plan name.
+	Abandoned map[string]*AbandonRequest `json:"abandoned,omitempty"`
+
+	// Retries tracks automatic retries of plans that failed transiently, keyed by plan name.
+	Retries map[string]*RetryState `json:"retries,omitempty"`
+
+	// Approvals holds merge/PR approval requests keyed by plan name.
+	Approvals map[string]*Approval `json:"approvals,omitempty"`
+}
+
+// Approval decisions.
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// Approval is a request for a human to approve a completed plan before its
+// pull request is opened or its branch is merged.
+type Approval struct {
+	// Decision is pending, approved, or rejected.
+	Decision string `json:"decision"`
+
+	// By records who approved or rejected the plan.
+	By string `json:"by,omitempty"`
+
+	// Reason is why the plan was rejected.
+	Reason string `json:"reason,omitempty"`
+
+	// RequestedAt is when approval was requested.
+	RequestedAt time.Time `json:"requested_at"`
+
+	// DecidedAt is when the plan was approved or rejected.
+	DecidedAt time.Time `json:"decided_at,omitempty"`
+}
+
+// RetryState records automatic retries of a plan that failed transiently.
+type RetryState struct {
+	// Attempts is the number of retries scheduled so far.
+	Attempts int `json:"attempts"`
+
+	// NotBefore is when the plan may run again.
+	NotBefore time.Time `json:"not_before"`
+
+	// LastError is the failure that triggered the latest retry.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// AbandonRequest asks the worker to stop a plan and move it to abandoned/.
+type AbandonRequest struct {
+	// Reason is why the plan is being abandoned.
+	Reason string `json:"reason"`
+
+	// By records who requested the abandon.
+	By string `json:"by,omitempty"`
+
+	// DeleteBranch also deletes the plan's feature branch.
+	DeleteBranch bool `json:"delete_branch,omitempty"`
+
+	// RequestedAt is when the abandon was requested.
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// Store reads and writes the control file.
+// It is safe for concurrent use within a process; across processes the
+// file is replaced atomically so readers never see partial writes.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// Path returns the control file path for the given .ralph directory.
+func Path(configDir string) string {
+	return filepath.Join(configDir, ControlFileName)
+}
+
+// NewStore creates a Store for the control file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the current control state.
+// Returns an empty State if the file doesn't exist.
+func (s *Store) Load() (*State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// IsPaused returns true if the worker is paused.
+// Read errors are treated as not paused.
+func (s *Store) IsPaused() bool {
+	state, err := s.Load()
+	if err != nil {
+		return false
+	}
+	return state.Paused
+}
+
+// IsSkipped returns true if the plan is marked to be skipped.
+// Read errors are treated as not skipped.
+func (s *Store) IsSkipped(planName string) bool {
+	state, err := s.Load()
+	if err != nil {
+		return false
+	}
+	for _, name := range state.Skipped {
+		if name == planName {
+			return true
+		}
+	}
+	return false
+}
+
+// Pause marks the worker as paused.
+func (s *Store) Pause(by string) error {
+	return s.update(func(state *State) {
+		if !state.Paused {
+			state.PausedAt = time.Now()
+		}
+		state.Paused = true
+		state.PausedBy = by
+	})
+}
+
+// Resume clears the paused state.
+func (s *Store) Resume() error {
+	return s.update(func(state *State) {
+		state.Paused = false
+		state.PausedBy = ""
+		state.PausedAt = time.Time{}
+	})
+}
+
+// Skip marks a plan to be skipped.
+func (s *Store) Skip(planName string) error {
+	return s.update(func(state *State) {
+		for _, name := range state.Skipped {
+			if name == planName {
+				return
+			}
+		}
+		state.Skipped = append(state.Skipped, planName)
+		sort.Strings(state.Skipped)
+	})
+}
+
+// Unskip removes a plan from the skip list.
+func (s *Store) Unskip(planName string) error {
+	return s.update(func(state *State) {
+		var kept []string
+		for _, name := range state.Skipped {
+			if name != planName {
+				kept = append(kept, name)
+			}
+		}
+		state.Skipped = kept
+	})
+}
+
+// RequestAbandon records a request to abandon a plan.
+func (s *Store) RequestAbandon(planName string, req AbandonRequest) error {
+	if req.RequestedAt.IsZero() {
+		req.RequestedAt = time.Now()
+	}
+	return s.update(func(state *State) {
+		if state.Abandoned == nil {
+			state.Abandoned = make(map[string]*AbandonRequest)
+		}
+		state.Abandoned[planName] = &req
+	})
+}
+
+// AbandonRequest returns the pending abandon request for a plan, or nil.
+// Read errors are treated as no request.
+func (s *Store) AbandonRequest(planName string) *AbandonRequest {
+	state, err := s.Load()
+	if err != nil {
+		return nil
+	}
+	return state.Abandoned[planName]
+}
+
+// ClearAbandon removes a plan's abandon request.
+func (s *Store) ClearAbandon(planName string) error {
+	return s.update(func(state *State) {
+		delete(state.Abandoned, planName)
+	})
+}
+
+// RecordRetry schedules another retry of a plan no earlier than notBefore
+// and returns the updated retry state.
+func (s *Store) RecordRetry(planName, lastError string, notBefore time.Time) (*RetryState, error) {
+	var retry RetryState
+	err := s.update(func(state *State) {
+		if state.Retries == nil {
+			state.Retries = make(map[string]*RetryState)
+		}
+		r := state.Retries[planName]
+		if r == nil {
+			r = &RetryState{}
+			state.Retries[planName] = r
+		}
+		r.Attempts++
+		r.NotBefore = notBefore
+		r.LastError = lastError
+		retry = *r
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &retry, nil
+}
+
+// Retry returns the retry state for a plan, or nil if it has none.
+// Read errors are treated as no retries.
+func (s *Store) Retry(planName string) *RetryState {
+	state, err := s.Load()
+	if err != nil {
+		return nil
+	}
+	return state.Retries[planName]
+}
+
+// ClearRetry removes a plan's retry state.
+func (s *Store) ClearRetry(planName string) error {
+	return s.update(func(state *State) {
+		delete(state.Retries, planName)
+	})
+}
+
+// RequestApproval records a pending approval request for a plan, replacing
+// any earlier decision.
+func (s *Store) RequestApproval(planName string) error {
+	return s.update(func(state *State) {
+		if state.Approvals == nil {
+			state.Approvals = make(map[string]*Approval)
+		}
+		state.Approvals[planName] = &Approval{Decision: ApprovalPending, RequestedAt: time.Now()}
+	})
+}
+
+// Decide approves or rejects a plan's pending approval request.
+// Returns an error if the plan has no pending request.
+func (s *Store) Decide(planName string, approved bool, by, reason string) error {
+	var err error
+	updateErr := s.update(func(state *State) {
+		a := state.Approvals[planName]
+		if a == nil || a.Decision != ApprovalPending {
+			err = fmt.Errorf("plan %s is not awaiting approval", planName)
+			return
+		}
+		a.Decision = ApprovalRejected
+		if approved {
+			a.Decision = ApprovalApproved
+		}
+		a.By = by
+		a.Reason = reason
+		a.DecidedAt = time.Now()
+	})
+	if updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// Approval returns a plan's approval request, or nil if it has none.
+// Read errors are treated as no request.
+func (s *Store) Approval(planName string) *Approval {
+	state, err := s.Load()
+	if err != nil {
+		return nil
+	}
+	return state.Approvals[planName]
+}
+
+// ClearApproval removes a plan's approval request.
+func (s *Store) ClearApproval(planName string) error {
+	return s.update(func(state *State) {
+		delete(state.Approvals, planName)
+	})
+}
+
+// update applies fn to the current state and saves it. The lock file is
+// held throughout, so updates from the CLI, the Slack bot, and the worker,
+// which run in separate processes, don't overwrite each other.
+func (s *Store) update(fn func(state *State)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	fn(state)
+	return s.save(state)
+}
+
+// lock takes the exclusive lock on the lock file next to the control file,
+// waiting for other processes, and returns the function releasing it.
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, fmt.Errorf("creating control directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening control lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking control file: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// load reads the control file (caller holds the lock).
+func (s *Store) load() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("reading control file: %w", err)
+	}
+
+	var state State
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("parsing control file: %w", err)
+		}
+	}
+	return &state, nil
+}
+
+// save writes the control file atomically (caller holds the lock).
+func (s *Store) save(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling control state: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating control directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(s.path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp control file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing temp control file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming control file: %w", err)
+	}
+
+	return nil
+}
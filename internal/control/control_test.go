@@ -0,0 +1,229 @@
+package control
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "control.json"))
+
+	state, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if state.Paused || len(state.Skipped) != 0 {
+		t.Errorf("expected empty state, got %+v", state)
+	}
+}
+
+func TestStore_PauseResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ralph", "control.json")
+	s := NewStore(path)
+
+	if err := s.Pause("alice"); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if !s.IsPaused() {
+		t.Error("expected paused after Pause()")
+	}
+
+	// State should persist across stores
+	state, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Paused || state.PausedBy != "alice" || state.PausedAt.IsZero() {
+		t.Errorf("persisted state = %+v", state)
+	}
+
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if s.IsPaused() {
+		t.Error("expected not paused after Resume()")
+	}
+}
+
+func TestStore_SkipUnskip(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "control.json"))
+
+	s.Skip("beta")
+	s.Skip("alpha")
+	s.Skip("beta") // duplicate ignored
+
+	state, _ := s.Load()
+	if len(state.Skipped) != 2 || state.Skipped[0] != "alpha" || state.Skipped[1] != "beta" {
+		t.Errorf("Skipped = %v, want [alpha beta]", state.Skipped)
+	}
+	if !s.IsSkipped("alpha") {
+		t.Error("expected alpha to be skipped")
+	}
+
+	if err := s.Unskip("alpha"); err != nil {
+		t.Fatalf("Unskip() error = %v", err)
+	}
+	if s.IsSkipped("alpha") {
+		t.Error("expected alpha to no longer be skipped")
+	}
+	if !s.IsSkipped("beta") {
+		t.Error("expected beta to remain skipped")
+	}
+}
+
+func TestStore_AbandonRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.json")
+	s := NewStore(path)
+
+	if s.AbandonRequest("alpha") != nil {
+		t.Error("expected no abandon request initially")
+	}
+
+	if err := s.RequestAbandon("alpha", AbandonRequest{Reason: "superseded", By: "alice", DeleteBranch: true}); err != nil {
+		t.Fatalf("RequestAbandon() error = %v", err)
+	}
+
+	// Request should persist across stores
+	req := NewStore(path).AbandonRequest("alpha")
+	if req == nil || req.Reason != "superseded" || req.By != "alice" || !req.DeleteBranch || req.RequestedAt.IsZero() {
+		t.Errorf("AbandonRequest() = %+v", req)
+	}
+	if s.AbandonRequest("beta") != nil {
+		t.Error("expected no abandon request for beta")
+	}
+
+	if err := s.ClearAbandon("alpha"); err != nil {
+		t.Fatalf("ClearAbandon() error = %v", err)
+	}
+	if s.AbandonRequest("alpha") != nil {
+		t.Error("expected abandon request to be cleared")
+	}
+}
+
+func TestStore_Retries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.json")
+	s := NewStore(path)
+
+	if s.Retry("alpha") != nil {
+		t.Error("expected no retry state initially")
+	}
+
+	notBefore := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+	r, err := s.RecordRetry("alpha", "rate limit", notBefore)
+	if err != nil {
+		t.Fatalf("RecordRetry() error = %v", err)
+	}
+	if r.Attempts != 1 || !r.NotBefore.Equal(notBefore) || r.LastError != "rate limit" {
+		t.Errorf("RecordRetry() = %+v", r)
+	}
+
+	r, _ = s.RecordRetry("alpha", "connection reset", notBefore.Add(time.Minute))
+	if r.Attempts != 2 || r.LastError != "connection reset" {
+		t.Errorf("second RecordRetry() = %+v", r)
+	}
+
+	// Retry state persists across stores
+	if got := NewStore(path).Retry("alpha"); got == nil || got.Attempts != 2 {
+		t.Errorf("Retry() = %+v", got)
+	}
+
+	if err := s.ClearRetry("alpha"); err != nil {
+		t.Fatalf("ClearRetry() error = %v", err)
+	}
+	if s.Retry("alpha") != nil {
+		t.Error("expected retry state to be cleared")
+	}
+}
+
+func TestStore_Approvals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.json")
+	s := NewStore(path)
+
+	if err := s.Decide("alpha", true, "jane", ""); err == nil {
+		t.Error("Decide() without a pending request should fail")
+	}
+
+	if err := s.RequestApproval("alpha"); err != nil {
+		t.Fatalf("RequestApproval() error = %v", err)
+	}
+	if a := NewStore(path).Approval("alpha"); a == nil || a.Decision != ApprovalPending || a.RequestedAt.IsZero() {
+		t.Errorf("Approval() = %+v, want pending", a)
+	}
+
+	if err := s.Decide("alpha", false, "jane", "needs a migration"); err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+	if a := s.Approval("alpha"); a.Decision != ApprovalRejected || a.By != "jane" || a.Reason != "needs a migration" || a.DecidedAt.IsZero() {
+		t.Errorf("Approval() = %+v, want rejected by jane", a)
+	}
+	if err := s.Decide("alpha", true, "bob", ""); err == nil {
+		t.Error("Decide() on a decided request should fail")
+	}
+
+	// A new request replaces the earlier decision
+	s.RequestApproval("alpha")
+	if err := s.Decide("alpha", true, "bob", ""); err != nil || s.Approval("alpha").Decision != ApprovalApproved {
+		t.Errorf("Decide() error = %v, approval = %+v", err, s.Approval("alpha"))
+	}
+
+	if err := s.ClearApproval("alpha"); err != nil {
+		t.Fatalf("ClearApproval() error = %v", err)
+	}
+	if s.Approval("alpha") != nil {
+		t.Error("expected approval to be cleared")
+	}
+}
+
+func TestStore_ConcurrentStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.json")
+
+	// Separate stores stand in for the CLI and worker processes
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := NewStore(path).Skip(fmt.Sprintf("plan-%02d", i)); err != nil {
+				t.Errorf("Skip() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	state, err := NewStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(state.Skipped) != n {
+		t.Errorf("expected %d skipped plans, got %d: %v", n, len(state.Skipped), state.Skipped)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".control.json-*.tmp")); len(tmps) != 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+}
+
+func TestStore_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.json")
+	os.WriteFile(path, []byte("not json"), 0644)
+
+	s := NewStore(path)
+	if _, err := s.Load(); err == nil {
+		t.Error("expected error for invalid control file")
+	}
+
+	// Read helpers degrade gracefully
+	if s.IsPaused() || s.IsSkipped("x") {
+		t.Error("invalid control file should read as not paused/not skipped")
+	}
+}
+
+func TestPath(t *testing.T) {
+	if got := Path("/repo/.ralph"); got != filepath.Join("/repo/.ralph", "control.json") {
+		t.Errorf("Path() = %q", got)
+	}
+}
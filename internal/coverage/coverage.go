@@ -0,0 +1,168 @@
+// Package coverage runs a project's coverage command and reads the total
+// coverage from a Go coverprofile, an lcov report, or the command output.
+package coverage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/gate"
+)
+
+// DefaultPattern matches a percentage like "81.2%" in the command output.
+const DefaultPattern = `([0-9]+(?:\.[0-9]+)?)%`
+
+// ErrNoCoverage is returned when a report or the output has no coverage data.
+var ErrNoCoverage = errors.New("no coverage data")
+
+// Measure runs cfg.Command in dir and returns the total coverage in percent.
+// A report the command creates is removed afterwards so it isn't committed.
+func Measure(ctx context.Context, dir string, cfg config.CoverageConfig) (float64, error) {
+	var report string
+	created := false
+	if cfg.Report != "" && cfg.Format != config.CoverageRegex && cfg.Format != "" {
+		report = filepath.Join(dir, filepath.FromSlash(cfg.Report))
+		if _, err := os.Stat(report); os.IsNotExist(err) {
+			created = true
+		}
+	}
+
+	result := gate.Run(ctx, dir, gate.Coverage, cfg.Command)
+	if created {
+		defer os.Remove(report)
+	}
+	if !result.Passed {
+		return 0, fmt.Errorf("%s failed: %s", cfg.Command, lastLine(result.Output))
+	}
+
+	switch cfg.Format {
+	case config.CoverageGo, config.CoverageLCOV:
+		data, err := os.ReadFile(report)
+		if err != nil {
+			return 0, fmt.Errorf("reading coverage report: %w", err)
+		}
+		if cfg.Format == config.CoverageGo {
+			return ParseGoProfile(string(data))
+		}
+		return ParseLCOV(string(data))
+	default:
+		pattern := cfg.Pattern
+		if pattern == "" {
+			pattern = DefaultPattern
+		}
+		return ParseOutput(result.Output, pattern)
+	}
+}
+
+// ParseGoProfile returns the statement coverage of a Go coverprofile
+// ("go test -coverprofile"). A block listed more than once, as with
+// -coverpkg, counts as covered if any run covered it.
+func ParseGoProfile(data string) (float64, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// Format: file.go:12.34,15.2 3 1 (position, statements, count)
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("invalid coverprofile line: %q", line)
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			return 0, fmt.Errorf("invalid coverprofile line: %q", line)
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+
+	var total, covered int
+	for _, b := range blocks {
+		total += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	if total == 0 {
+		return 0, ErrNoCoverage
+	}
+	return 100 * float64(covered) / float64(total), nil
+}
+
+// ParseLCOV returns the line coverage of an lcov report, from the LF (lines
+// found) and LH (lines hit) records of each file.
+func ParseLCOV(data string) (float64, error) {
+	var found, hit int
+	for _, line := range strings.Split(data, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || (key != "LF" && key != "LH") {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lcov line: %q", line)
+		}
+		if key == "LF" {
+			found += n
+		} else {
+			hit += n
+		}
+	}
+	if found == 0 {
+		return 0, ErrNoCoverage
+	}
+	return 100 * float64(hit) / float64(found), nil
+}
+
+// ParseOutput returns the percentage captured by the first group of the
+// pattern's last match in output.
+func ParseOutput(output, pattern string) (float64, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coverage pattern: %w", err)
+	}
+	matches := re.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 || len(matches[len(matches)-1]) < 2 {
+		return 0, ErrNoCoverage
+	}
+	percent, err := strconv.ParseFloat(matches[len(matches)-1][1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid coverage %q: %w", matches[len(matches)-1][1], err)
+	}
+	return percent, nil
+}
+
+// Check compares coverage with the base branch: the gate fails if coverage
+// dropped more than maxDrop percentage points.
+func Check(command string, base, latest, maxDrop float64) gate.Result {
+	drop := base - latest
+	return gate.Result{
+		Name:    gate.Coverage,
+		Command: command,
+		Passed:  drop <= maxDrop+1e-9,
+		Output:  fmt.Sprintf("coverage %.1f%% (base %.1f%%, %+.1f points)", latest, base, latest-base),
+	}
+}
+
+// lastLine returns the last non-empty line of s.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,136 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+const goProfile = `mode: set
+github.com/acme/app/calc.go:3.20,5.2 2 1
+github.com/acme/app/calc.go:7.20,9.2 2 0
+github.com/acme/app/calc.go:11.20,13.2 4 0
+github.com/acme/app/calc.go:11.20,13.2 4 1
+`
+
+const lcovReport = `TN:
+SF:src/calc.js
+DA:1,1
+LF:10
+LH:7
+end_of_record
+SF:src/util.js
+LF:10
+LH:1
+end_of_record
+`
+
+func TestParseGoProfile(t *testing.T) {
+	got, err := ParseGoProfile(goProfile)
+	if err != nil {
+		t.Fatalf("ParseGoProfile() error = %v", err)
+	}
+	// 6 of 8 statements: the repeated block was covered by one of its runs
+	if got != 75 {
+		t.Errorf("ParseGoProfile() = %v, want 75", got)
+	}
+
+	if _, err := ParseGoProfile("mode: set\n"); !errors.Is(err, ErrNoCoverage) {
+		t.Errorf("empty profile error = %v, want ErrNoCoverage", err)
+	}
+	if _, err := ParseGoProfile("mode: set\ncalc.go:1.1,2.2 x 1\n"); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
+
+func TestParseLCOV(t *testing.T) {
+	got, err := ParseLCOV(lcovReport)
+	if err != nil {
+		t.Fatalf("ParseLCOV() error = %v", err)
+	}
+	if got != 40 {
+		t.Errorf("ParseLCOV() = %v, want 40", got)
+	}
+	if _, err := ParseLCOV("TN:\n"); !errors.Is(err, ErrNoCoverage) {
+		t.Errorf("empty report error = %v, want ErrNoCoverage", err)
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		pattern string
+		want    float64
+		wantErr bool
+	}{
+		{"last match wins", "ok  pkg/a  coverage: 50.0% of statements\nok  pkg/b  coverage: 81.5% of statements", DefaultPattern, 81.5, false},
+		{"custom pattern", "total:\t(statements)\t64.2%\nother 10%", `total:\s+\(statements\)\s+([0-9.]+)%`, 64.2, false},
+		{"integer", "Lines: 90%", DefaultPattern, 90, false},
+		{"no match", "no tests ran", DefaultPattern, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOutput(tt.output, tt.pattern)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseOutput() = %v, %v; want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := Measure(context.Background(), dir, config.CoverageConfig{Command: "echo 'coverage: 72.5% of statements'"})
+	if err != nil || got != 72.5 {
+		t.Errorf("Measure() regex = %v, %v; want 72.5", got, err)
+	}
+
+	cfg := config.CoverageConfig{
+		Command: "printf 'mode: set\\ncalc.go:1.1,2.2 1 1\\ncalc.go:3.1,4.2 3 0\\n' > cover.out",
+		Format:  config.CoverageGo,
+		Report:  "cover.out",
+	}
+	got, err = Measure(context.Background(), dir, cfg)
+	if err != nil || got != 25 {
+		t.Errorf("Measure() go = %v, %v; want 25", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cover.out")); !os.IsNotExist(err) {
+		t.Errorf("the report the command created should be removed: %v", err)
+	}
+
+	// A report that was already there is left alone
+	os.WriteFile(filepath.Join(dir, "lcov.info"), []byte(lcovReport), 0644)
+	got, err = Measure(context.Background(), dir, config.CoverageConfig{Command: "true", Format: config.CoverageLCOV, Report: "lcov.info"})
+	if err != nil || got != 40 {
+		t.Errorf("Measure() lcov = %v, %v; want 40", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "lcov.info")); err != nil {
+		t.Errorf("existing report removed: %v", err)
+	}
+
+	if _, err := Measure(context.Background(), dir, config.CoverageConfig{Command: "echo boom; exit 1"}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Measure() failing command error = %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if r := Check("make cover", 80, 79.5, 1); !r.Passed || r.Output != "coverage 79.5% (base 80.0%, -0.5 points)" {
+		t.Errorf("Check() within max drop = %+v", r)
+	}
+	if r := Check("make cover", 80, 78, 1); r.Passed {
+		t.Errorf("Check() beyond max drop = %+v, want failed", r)
+	}
+	if r := Check("make cover", 80, 80, 0); !r.Passed || !strings.Contains(r.Output, "+0.0 points") {
+		t.Errorf("Check() unchanged = %+v", r)
+	}
+	if r := Check("make cover", 70.1, 70, 0.1); !r.Passed {
+		t.Errorf("Check() at exactly max drop = %+v, want passed", r)
+	}
+}
@@ -0,0 +1,288 @@
+// Package deps finds outdated dependencies (Go modules, npm packages) and
+// groups them into update tasks for a maintenance plan.
+package deps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// Ecosystems.
+const (
+	Go  = "go"
+	NPM = "npm"
+)
+
+// Update kinds, from the version component that changed.
+const (
+	Major = "major"
+	Minor = "minor"
+	Patch = "patch"
+)
+
+// Update is an outdated dependency.
+type Update struct {
+	// Ecosystem is Go or NPM.
+	Ecosystem string
+
+	// Name is the module path or package name.
+	Name string
+
+	// Current and Latest are the used and newest versions.
+	Current, Latest string
+
+	// Kind is Major, Minor, or Patch.
+	Kind string
+
+	// Changelog links to the dependency's releases.
+	Changelog string
+}
+
+// String formats the update, e.g. "github.com/spf13/cobra v1.7.0 → v1.8.0".
+func (u Update) String() string {
+	return fmt.Sprintf("%s %s → %s", u.Name, u.Current, u.Latest)
+}
+
+// Find returns the outdated dependencies of the Go module and npm package
+// in dir, whichever exist. An ecosystem whose tool fails is reported in the
+// error, after the updates found for the others.
+func Find(ctx context.Context, dir string) ([]Update, error) {
+	var updates []Update
+	var errs []error
+	if exists(filepath.Join(dir, "go.mod")) {
+		u, err := GoOutdated(ctx, dir)
+		updates = append(updates, u...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if exists(filepath.Join(dir, "package.json")) {
+		u, err := NPMOutdated(ctx, dir)
+		updates = append(updates, u...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return updates, errors.Join(errs...)
+}
+
+// GoOutdated runs `go list -u -m -json all` in dir and returns the direct
+// dependencies with a newer version.
+func GoOutdated(ctx context.Context, dir string) ([]Update, error) {
+	out, err := output(ctx, dir, "go", "list", "-u", "-m", "-json", "all")
+	if err != nil {
+		return nil, err
+	}
+	return ParseGoList(out)
+}
+
+// ParseGoList parses the JSON stream of `go list -u -m -json`, skipping the
+// main module, indirect dependencies, and modules without an update.
+func ParseGoList(data []byte) ([]Update, error) {
+	var updates []Update
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var m struct {
+			Path     string
+			Version  string
+			Main     bool
+			Indirect bool
+			Update   *struct{ Version string }
+		}
+		if err := dec.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing go list output: %w", err)
+		}
+		if m.Main || m.Indirect || m.Update == nil {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: Go,
+			Name:      m.Path,
+			Current:   m.Version,
+			Latest:    m.Update.Version,
+			Kind:      kind(m.Version, m.Update.Version),
+			Changelog: goChangelog(m.Path),
+		})
+	}
+	return updates, nil
+}
+
+// NPMOutdated runs `npm outdated --json` in dir and returns the outdated packages.
+func NPMOutdated(ctx context.Context, dir string) ([]Update, error) {
+	// npm outdated exits 1 when something is outdated
+	out, err := output(ctx, dir, "npm", "outdated", "--json")
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && len(bytes.TrimSpace(out)) > 0) {
+		return nil, err
+	}
+	return ParseNPMOutdated(out)
+}
+
+// ParseNPMOutdated parses the output of `npm outdated --json`, sorted by name.
+func ParseNPMOutdated(data []byte) ([]Update, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var packages map[string]struct {
+		Current string `json:"current"`
+		Latest  string `json:"latest"`
+	}
+	if err := json.Unmarshal(data, &packages); err != nil {
+		return nil, fmt.Errorf("parsing npm outdated output: %w", err)
+	}
+
+	var updates []Update
+	for name, p := range packages {
+		// Packages that aren't installed have no current version
+		if p.Current == "" || p.Current == p.Latest {
+			continue
+		}
+		updates = append(updates, Update{
+			Ecosystem: NPM,
+			Name:      name,
+			Current:   p.Current,
+			Latest:    p.Latest,
+			Kind:      kind(p.Current, p.Latest),
+			Changelog: "https://www.npmjs.com/package/" + name + "?activeTab=versions",
+		})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].Name < updates[j].Name })
+	return updates, nil
+}
+
+// Group is the updates handled by one task.
+type Group struct {
+	// Title is the task title.
+	Title string
+
+	// Updates are the dependencies it updates.
+	Updates []Update
+}
+
+// GroupUpdates groups updates into tasks: each major update gets its own
+// task, since it may need code changes; the minor and patch updates of an
+// ecosystem share one.
+func GroupUpdates(updates []Update) []Group {
+	var majors, groups []Group
+	minors := make(map[string]*Group)
+	var ecosystems []string
+	for _, u := range updates {
+		if u.Kind == Major {
+			majors = append(majors, Group{
+				Title:   fmt.Sprintf("Update %s to %s (major)", u.Name, u.Latest),
+				Updates: []Update{u},
+			})
+			continue
+		}
+		g, ok := minors[u.Ecosystem]
+		if !ok {
+			g = &Group{}
+			minors[u.Ecosystem] = g
+			ecosystems = append(ecosystems, u.Ecosystem)
+		}
+		g.Updates = append(g.Updates, u)
+	}
+	for _, eco := range ecosystems {
+		g := minors[eco]
+		g.Title = fmt.Sprintf("Update %s minor and patch dependencies (%d)", ecosystemName(eco), len(g.Updates))
+		groups = append(groups, *g)
+	}
+	return append(groups, majors...)
+}
+
+// PlanOptions returns the options of a pending plan updating the groups:
+// one task per group, and the updates with their changelog links in the
+// Context.
+func PlanOptions(groups []Group) plan.ScaffoldOptions {
+	var b strings.Builder
+	b.WriteString("Update outdated dependencies. Read each changelog for breaking changes, update the code that uses them, and keep the tests passing. Skip an update that can't be made safely and record why in Discovered.\n")
+	tasks := make([]string, len(groups))
+	for i, g := range groups {
+		tasks[i] = g.Title
+		fmt.Fprintf(&b, "\n**T%d: %s**\n", i+1, g.Title)
+		for _, u := range g.Updates {
+			fmt.Fprintf(&b, "- `%s` %s → %s ([changelog](%s))\n", u.Name, u.Current, u.Latest, u.Changelog)
+		}
+	}
+	return plan.ScaffoldOptions{
+		Title:  "Update dependencies",
+		Body:   b.String(),
+		Source: "ralph deps-plan",
+		Tasks:  tasks,
+		Labels: []string{"dependencies"},
+	}
+}
+
+// kind returns which version component differs between current and latest.
+func kind(current, latest string) string {
+	cur, next := versionParts(current), versionParts(latest)
+	switch {
+	case cur[0] != next[0]:
+		return Major
+	case cur[1] != next[1]:
+		return Minor
+	default:
+		return Patch
+	}
+}
+
+// versionParts returns the major and minor numbers of a version like
+// "v1.2.3" or "1.2.3-beta", ignoring what can't be parsed.
+func versionParts(version string) [2]int {
+	var parts [2]int
+	fields := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	for i := 0; i < len(fields) && i < 2; i++ {
+		parts[i], _ = strconv.Atoi(fields[i])
+	}
+	return parts
+}
+
+// goChangelog links to a Go module's releases: GitHub's for modules hosted
+// there, pkg.go.dev's version list otherwise.
+func goChangelog(path string) string {
+	if parts := strings.Split(path, "/"); len(parts) >= 3 && parts[0] == "github.com" {
+		return "https://github.com/" + parts[1] + "/" + parts[2] + "/releases"
+	}
+	return "https://pkg.go.dev/" + path + "?tab=versions"
+}
+
+// ecosystemName returns the display name of an ecosystem.
+func ecosystemName(ecosystem string) string {
+	if ecosystem == Go {
+		return "Go"
+	}
+	return ecosystem
+}
+
+// output runs name with args in dir and returns its stdout.
+func output(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
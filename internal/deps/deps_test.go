@@ -0,0 +1,124 @@
+package deps
+
+import (
+	"strings"
+	"testing"
+)
+
+const goListOutput = `{
+	"Path": "github.com/acme/app",
+	"Main": true
+}
+{
+	"Path": "github.com/spf13/cobra",
+	"Version": "v1.7.0",
+	"Update": {"Path": "github.com/spf13/cobra", "Version": "v1.8.1"}
+}
+{
+	"Path": "golang.org/x/text",
+	"Version": "v0.14.0",
+	"Indirect": true,
+	"Update": {"Path": "golang.org/x/text", "Version": "v0.16.0"}
+}
+{
+	"Path": "gopkg.in/yaml.v3",
+	"Version": "v3.0.1"
+}
+{
+	"Path": "example.com/lib",
+	"Version": "v1.2.3",
+	"Update": {"Path": "example.com/lib", "Version": "v1.2.4"}
+}
+`
+
+func TestParseGoList(t *testing.T) {
+	updates, err := ParseGoList([]byte(goListOutput))
+	if err != nil {
+		t.Fatalf("ParseGoList() error = %v", err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("ParseGoList() = %+v, want the 2 direct updates", updates)
+	}
+	if u := updates[0]; u.Name != "github.com/spf13/cobra" || u.Latest != "v1.8.1" || u.Kind != Minor || u.Changelog != "https://github.com/spf13/cobra/releases" {
+		t.Errorf("updates[0] = %+v", u)
+	}
+	if u := updates[1]; u.Kind != Patch || u.Changelog != "https://pkg.go.dev/example.com/lib?tab=versions" {
+		t.Errorf("updates[1] = %+v", u)
+	}
+
+	if _, err := ParseGoList([]byte("{not json")); err == nil {
+		t.Error("ParseGoList() should fail on invalid output")
+	}
+}
+
+func TestParseNPMOutdated(t *testing.T) {
+	updates, err := ParseNPMOutdated([]byte(`{
+  "react": {"current": "17.0.2", "wanted": "17.0.2", "latest": "18.3.1"},
+  "lodash": {"current": "4.17.20", "wanted": "4.17.21", "latest": "4.17.21"},
+  "missing": {"wanted": "1.0.0", "latest": "1.0.0"}
+}`))
+	if err != nil {
+		t.Fatalf("ParseNPMOutdated() error = %v", err)
+	}
+	if len(updates) != 2 || updates[0].Name != "lodash" || updates[0].Kind != Patch || updates[1].Name != "react" || updates[1].Kind != Major {
+		t.Fatalf("ParseNPMOutdated() = %+v", updates)
+	}
+	if updates[1].Changelog != "https://www.npmjs.com/package/react?activeTab=versions" {
+		t.Errorf("Changelog = %q", updates[1].Changelog)
+	}
+
+	if updates, err := ParseNPMOutdated(nil); err != nil || updates != nil {
+		t.Errorf("ParseNPMOutdated(empty) = %v, %v", updates, err)
+	}
+}
+
+func TestGroupUpdates(t *testing.T) {
+	groups := GroupUpdates([]Update{
+		{Ecosystem: Go, Name: "github.com/a/b", Current: "v1.0.0", Latest: "v1.1.0", Kind: Minor},
+		{Ecosystem: NPM, Name: "react", Current: "17.0.2", Latest: "18.3.1", Kind: Major},
+		{Ecosystem: Go, Name: "github.com/c/d", Current: "v0.1.0", Latest: "v0.1.1", Kind: Patch},
+		{Ecosystem: NPM, Name: "lodash", Current: "4.17.20", Latest: "4.17.21", Kind: Patch},
+	})
+	var titles []string
+	for _, g := range groups {
+		titles = append(titles, g.Title)
+	}
+	want := []string{
+		"Update Go minor and patch dependencies (2)",
+		"Update npm minor and patch dependencies (1)",
+		"Update react to 18.3.1 (major)",
+	}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Errorf("GroupUpdates() titles = %q, want %q", titles, want)
+	}
+}
+
+func TestPlanOptions(t *testing.T) {
+	opts := PlanOptions(GroupUpdates([]Update{
+		{Ecosystem: NPM, Name: "react", Current: "17.0.2", Latest: "18.3.1", Kind: Major, Changelog: "https://www.npmjs.com/package/react?activeTab=versions"},
+	}))
+	if len(opts.Tasks) != 1 || opts.Tasks[0] != "Update react to 18.3.1 (major)" {
+		t.Errorf("Tasks = %v", opts.Tasks)
+	}
+	if !strings.Contains(opts.Body, "- `react` 17.0.2 → 18.3.1 ([changelog](https://www.npmjs.com/package/react?activeTab=versions))") {
+		t.Errorf("Body = %q", opts.Body)
+	}
+	if len(opts.Labels) != 1 || opts.Labels[0] != "dependencies" {
+		t.Errorf("Labels = %v", opts.Labels)
+	}
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct{ current, latest, want string }{
+		{"v1.2.3", "v2.0.0", Major},
+		{"v1.2.3", "v1.3.0", Minor},
+		{"v1.2.3", "v1.2.4", Patch},
+		{"1.0.0-beta.1", "1.0.0", Patch},
+		{"v0.0.0-20230101000000-abcdef", "v0.1.0", Minor},
+	}
+	for _, tt := range tests {
+		if got := kind(tt.current, tt.latest); got != tt.want {
+			t.Errorf("kind(%q, %q) = %q, want %q", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
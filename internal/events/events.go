@@ -0,0 +1,381 @@
+// Package events provides the append-only worker events log.
+// Events are stored as JSON lines in .ralph/events.jsonl and are used for
+// digest notifications and reporting.
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EventsFileName is the name of the events log in the .ralph directory.
+const EventsFileName = "events.jsonl"
+
+// Event types.
+const (
+	// TypePlanStarted is recorded when the worker starts processing a plan.
+	TypePlanStarted = "plan_started"
+
+	// TypeIteration is recorded after each iteration.
+	TypeIteration = "iteration"
+
+	// TypePlanCompleted is recorded when a plan is verified complete.
+	TypePlanCompleted = "plan_completed"
+
+	// TypePlanError is recorded when a plan fails.
+	TypePlanError = "plan_error"
+
+	// TypeBlocker is recorded when the agent reports a blocker.
+	TypeBlocker = "blocker"
+
+	// TypeVerificationFailed is recorded when a completion claim fails verification.
+	TypeVerificationFailed = "verification_failed"
+
+	// TypeStageChanged is recorded when a plan finishes a stage and moves to the next.
+	TypeStageChanged = "stage_changed"
+
+	// TypePlanFailed is recorded when a plan is moved to failed/.
+	TypePlanFailed = "plan_failed"
+
+	// TypePlanRetry is recorded when a transiently failed plan is requeued for an automatic retry.
+	TypePlanRetry = "plan_retry"
+
+	// TypePlanAbandoned is recorded when a plan is abandoned.
+	TypePlanAbandoned = "plan_abandoned"
+
+	// TypePlanReset is recorded when a plan's execution state is reset.
+	TypePlanReset = "plan_reset"
+
+	// TypePlanSnoozed is recorded when a pending plan is snoozed; Message is the wake time.
+	TypePlanSnoozed = "plan_snoozed"
+
+	// TypePlanWoken is recorded when the worker wakes a snoozed plan.
+	TypePlanWoken = "plan_woken"
+
+	// TypeDigestSent is recorded when a digest notification is sent.
+	TypeDigestSent = "digest_sent"
+
+	// TypeFilesRejected is recorded when denied or oversized files are held back from a commit.
+	TypeFilesRejected = "files_rejected"
+
+	// TypeWorktreeRepaired is recorded when a broken worktree is repaired.
+	TypeWorktreeRepaired = "worktree_repaired"
+
+	// TypePlanQueued is recorded when a plan is submitted to the ingest endpoint.
+	TypePlanQueued = "plan_queued"
+
+	// TypeBlockerEscalated is recorded when a blocker stays unresolved for blockers.escalate_after.
+	TypeBlockerEscalated = "blocker_escalated"
+
+	// TypeVerification is recorded with both verdicts of a two-person verification.
+	TypeVerification = "verification"
+
+	// TypeVerificationDisagreement is recorded when the two verifiers reach different verdicts.
+	TypeVerificationDisagreement = "verification_disagreement"
+
+	// TypeApprovalRequested is recorded when a completed plan waits for approval.
+	TypeApprovalRequested = "approval_requested"
+
+	// TypePlanApproved is recorded when a completed plan is approved; Message is who approved it.
+	TypePlanApproved = "plan_approved"
+
+	// TypeCoverage is recorded with each coverage measurement; iteration 0 is the base branch.
+	TypeCoverage = "coverage"
+
+	// TypeBenchRegression is recorded when benchmarks regress beyond bench.threshold; Message lists them.
+	TypeBenchRegression = "bench_regression"
+
+	// TypePlanUpdated is recorded when plan edits in the main worktree are synced into a running plan's worktree; Message lists the changed sections.
+	TypePlanUpdated = "plan_updated"
+
+	// TypePlanOutcome is recorded by the retrospective when a plan finishes (lessons.enabled); Message lists the lessons recorded.
+	TypePlanOutcome = "plan_outcome"
+
+	// TypeFeedback is recorded when a human adds feedback to a plan; Author is who wrote it and Message its source.
+	TypeFeedback = "feedback"
+
+	// TypeQuestion is recorded when the agent asks a human a question with <ask>; Message is the question.
+	TypeQuestion = "question"
+
+	// TypeOrphanProcesses is recorded when the agent leaves processes running after it exits; Message lists them.
+	TypeOrphanProcesses = "orphan_processes"
+)
+
+// Event is a single entry in the events log.
+type Event struct {
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+
+	// Type is the event type (see Type* constants).
+	Type string `json:"type"`
+
+	// Plan is the plan name, if the event is about a plan.
+	Plan string `json:"plan,omitempty"`
+
+	// Iteration is the iteration number for iteration events.
+	Iteration int `json:"iteration,omitempty"`
+
+	// MaxIterations is the iteration limit for iteration events.
+	MaxIterations int `json:"max_iterations,omitempty"`
+
+	// Duration is the iteration wall time for iteration events.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// TasksDone and TasksTotal are the plan's task counts when the event was recorded.
+	TasksDone  int `json:"tasks_done,omitempty"`
+	TasksTotal int `json:"tasks_total,omitempty"`
+
+	// InputTokens and OutputTokens are the token counts for iteration events.
+	InputTokens  int `json:"input_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+
+	// Stage is the stage a plan moved to, for stage change events.
+	Stage string `json:"stage,omitempty"`
+
+	// Tools counts the agent's tool calls for iteration events.
+	Tools *ToolStats `json:"tools,omitempty"`
+
+	// Resources is the agent's CPU, memory, and subprocess usage for
+	// iteration events.
+	Resources *ResourceUsage `json:"resources,omitempty"`
+
+	// PRURL is the pull request URL for completion events.
+	PRURL string `json:"pr_url,omitempty"`
+
+	// Labels are the plan's labels, recorded on plan start events.
+	Labels []string `json:"labels,omitempty"`
+
+	// Coverage is the test coverage in percent for coverage events.
+	Coverage *float64 `json:"coverage,omitempty"`
+
+	// Outcome is the classified outcome for plan outcome events (clean,
+	// needed-human-help, failed-verification, abandoned).
+	Outcome string `json:"outcome,omitempty"`
+
+	// Author is who wrote the feedback, for feedback events.
+	Author string `json:"author,omitempty"`
+
+	// Message carries the error text, blocker description, or other detail.
+	Message string `json:"message,omitempty"`
+}
+
+// Log appends and reads events from a JSON lines file.
+// It is safe for concurrent use within a process.
+type Log struct {
+	path string
+	mu   sync.Mutex
+
+	// Mirror is called with each event after it's appended (optional), e.g.
+	// to keep the state database in sync. It must not call back into the log.
+	Mirror func(Event)
+}
+
+// Path returns the events log path for the given .ralph directory.
+func Path(configDir string) string {
+	return filepath.Join(configDir, EventsFileName)
+}
+
+// NewLog creates a Log for the file at path.
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append writes an event to the log. Time defaults to now.
+func (l *Log) Append(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("creating events directory: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+	if l.Mirror != nil {
+		l.Mirror(e)
+	}
+	return nil
+}
+
+// Since returns events with Time after since, oldest first.
+// A zero since returns all events. Malformed lines are skipped.
+// Returns an empty slice if the log doesn't exist.
+func (l *Log) Since(since time.Time) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	var result []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if since.IsZero() || e.Time.After(since) {
+			result = append(result, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("reading events log: %w", err)
+	}
+
+	return result, nil
+}
+
+// ReadFrom returns the events written after byte offset in the log, oldest
+// first, and the offset to read from next. A line still being written is
+// left for the next call. If the log is shorter than offset (it was
+// truncated or replaced), it is read from the start. Malformed lines are
+// skipped; a log that doesn't exist has no events.
+func (l *Log) ReadFrom(offset int64) ([]Event, int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, offset, fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, fmt.Errorf("reading events log: %w", err)
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, fmt.Errorf("reading events log: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, fmt.Errorf("reading events log: %w", err)
+	}
+
+	var result []Event
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		var e Event
+		if err := json.Unmarshal(data[:end], &e); err == nil {
+			result = append(result, e)
+		}
+		data = data[end+1:]
+		offset += int64(end + 1)
+	}
+	return result, offset, nil
+}
+
+// Last returns the most recent event of the given type, or nil if none.
+func (l *Log) Last(eventType string) (*Event, error) {
+	var last *Event
+	err := l.Reverse(func(e Event) bool {
+		if e.Type == eventType {
+			last = &e
+			return false
+		}
+		return true
+	})
+	return last, err
+}
+
+// reverseChunkSize is how much of the log Reverse reads at a time.
+const reverseChunkSize = 64 * 1024
+
+// Reverse calls fn with each event, newest first, until fn returns false.
+// The log is read backwards from its end, so stopping early leaves older
+// events unread. Malformed lines are skipped; a log that doesn't exist has
+// no events.
+func (l *Log) Reverse(fn func(Event) bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("opening events log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("reading events log: %w", err)
+	}
+
+	// each visits a line, reporting whether to go on
+	each := func(line []byte) bool {
+		var e Event
+		if len(line) == 0 || json.Unmarshal(line, &e) != nil {
+			return true
+		}
+		return fn(e)
+	}
+
+	// partial is the start of a line whose beginning is in an earlier chunk
+	var partial []byte
+	for pos := info.Size(); pos > 0; {
+		n := int64(reverseChunkSize)
+		if pos < n {
+			n = pos
+		}
+		pos -= n
+		chunk := make([]byte, n, n+int64(len(partial)))
+		if _, err := f.ReadAt(chunk, pos); err != nil {
+			return fmt.Errorf("reading events log: %w", err)
+		}
+		data := append(chunk, partial...)
+		for {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if !each(data[i+1:]) {
+				return nil
+			}
+			data = data[:i]
+		}
+		partial = data
+	}
+	each(partial)
+	return nil
+}
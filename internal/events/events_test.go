@@ -0,0 +1,196 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ralph", EventsFileName)
+	l := NewLog(path)
+
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	l.Append(Event{Time: base, Type: TypePlanStarted, Plan: "alpha"})
+	l.Append(Event{Time: base.Add(time.Minute), Type: TypeIteration, Plan: "alpha", Iteration: 1, MaxIterations: 30, Duration: 90 * time.Second})
+	l.Append(Event{Time: base.Add(2 * time.Minute), Type: TypePlanCompleted, Plan: "alpha", PRURL: "https://github.com/o/r/pull/1"})
+
+	all, err := l.Since(time.Time{})
+	if err != nil {
+		t.Fatalf("Since() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("len(all) = %d, want 3", len(all))
+	}
+	if all[1].Duration != 90*time.Second || all[1].Iteration != 1 {
+		t.Errorf("iteration event round-trip = %+v", all[1])
+	}
+
+	recent, err := l.Since(base.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Type != TypePlanCompleted {
+		t.Errorf("Since() should exclude events at or before the cutoff, got %+v", recent)
+	}
+}
+
+func TestLog_AppendDefaultsTime(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), EventsFileName))
+
+	before := time.Now()
+	l.Append(Event{Type: TypePlanError, Plan: "beta", Message: "boom"})
+
+	all, _ := l.Since(time.Time{})
+	if len(all) != 1 || all[0].Time.Before(before) {
+		t.Errorf("expected event time to default to now, got %+v", all)
+	}
+}
+
+func TestLog_Mirror(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), EventsFileName))
+	var mirrored []Event
+	l.Mirror = func(e Event) { mirrored = append(mirrored, e) }
+
+	l.Append(Event{Type: TypeIteration, Plan: "alpha", Iteration: 1})
+	if len(mirrored) != 1 || mirrored[0].Plan != "alpha" || mirrored[0].Time.IsZero() {
+		t.Errorf("mirrored = %+v, want the appended event with its time", mirrored)
+	}
+}
+
+func TestLog_SinceMissingFile(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), EventsFileName))
+
+	all, err := l.Since(time.Time{})
+	if err != nil || len(all) != 0 {
+		t.Errorf("Since() = %v, %v; want empty, nil", all, err)
+	}
+}
+
+func TestLog_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EventsFileName)
+	os.WriteFile(path, []byte("not json\n{\"type\":\"iteration\",\"plan\":\"alpha\",\"time\":\"2024-01-30T12:00:00Z\"}\n"), 0644)
+
+	all, err := NewLog(path).Since(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Plan != "alpha" {
+		t.Errorf("expected one valid event, got %+v", all)
+	}
+}
+
+func TestLog_ReadFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EventsFileName)
+	l := NewLog(path)
+
+	got, offset, err := l.ReadFrom(0)
+	if err != nil || len(got) != 0 || offset != 0 {
+		t.Fatalf("ReadFrom() on a missing log = %v, %d, %v", got, offset, err)
+	}
+
+	l.Append(Event{Type: TypePlanStarted, Plan: "alpha"})
+	l.Append(Event{Type: TypeIteration, Plan: "alpha", Iteration: 1})
+	got, offset, err = l.ReadFrom(0)
+	if err != nil || len(got) != 2 || got[1].Iteration != 1 {
+		t.Fatalf("ReadFrom(0) = %+v, %v", got, err)
+	}
+
+	// A partial line is left for the next read
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"type":"plan_completed",`)
+	f.Close()
+	got, next, err := l.ReadFrom(offset)
+	if err != nil || len(got) != 0 || next != offset {
+		t.Errorf("ReadFrom() with a partial line = %+v, %d, %v; want none at %d", got, next, err, offset)
+	}
+	f, _ = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`"plan":"alpha"}` + "\n")
+	f.Close()
+	got, _, _ = l.ReadFrom(offset)
+	if len(got) != 1 || got[0].Type != TypePlanCompleted {
+		t.Errorf("ReadFrom() after the line was finished = %+v", got)
+	}
+
+	// A replaced log is read from the start
+	os.WriteFile(path, []byte(`{"type":"plan_error"}`+"\n"), 0644)
+	got, _, _ = l.ReadFrom(offset)
+	if len(got) != 1 || got[0].Type != TypePlanError {
+		t.Errorf("ReadFrom() after truncation = %+v", got)
+	}
+}
+
+func TestLog_Last(t *testing.T) {
+	l := NewLog(filepath.Join(t.TempDir(), EventsFileName))
+
+	if e, err := l.Last(TypeDigestSent); err != nil || e != nil {
+		t.Errorf("Last() on empty log = %v, %v", e, err)
+	}
+
+	base := time.Now()
+	l.Append(Event{Time: base, Type: TypeDigestSent})
+	l.Append(Event{Time: base.Add(time.Hour), Type: TypeDigestSent})
+	l.Append(Event{Time: base.Add(2 * time.Hour), Type: TypeIteration})
+
+	e, err := l.Last(TypeDigestSent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e == nil || !e.Time.Equal(base.Add(time.Hour)) {
+		t.Errorf("Last() = %+v, want the second digest", e)
+	}
+}
+
+func TestLog_Reverse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EventsFileName)
+	l := NewLog(path)
+
+	// Enough events to span several chunks, and a malformed line
+	const n = 2000
+	for i := 1; i <= n; i++ {
+		l.Append(Event{Type: TypeIteration, Iteration: i, Message: strings.Repeat("x", 40)})
+	}
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("not json\n")
+	f.Close()
+
+	var seen []int
+	if err := l.Reverse(func(e Event) bool {
+		seen = append(seen, e.Iteration)
+		return true
+	}); err != nil {
+		t.Fatalf("Reverse() error = %v", err)
+	}
+	if len(seen) != n || seen[0] != n || seen[n-1] != 1 {
+		t.Fatalf("Reverse() saw %d events, first %v; want %d newest first", len(seen), seen[:1], n)
+	}
+	for i := 1; i < n; i++ {
+		if seen[i] != seen[i-1]-1 {
+			t.Fatalf("Reverse() out of order at %d: %d after %d", i, seen[i], seen[i-1])
+		}
+	}
+
+	seen = nil
+	l.Reverse(func(e Event) bool {
+		seen = append(seen, e.Iteration)
+		return len(seen) < 3
+	})
+	if len(seen) != 3 || seen[2] != n-2 {
+		t.Errorf("Reverse() stopping early saw %v", seen)
+	}
+
+	if err := NewLog(filepath.Join(t.TempDir(), "missing.jsonl")).Reverse(func(Event) bool { return true }); err != nil {
+		t.Errorf("Reverse() on a missing log error = %v", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	if got := Path("/repo/.ralph"); got != filepath.Join("/repo/.ralph", EventsFileName) {
+		t.Errorf("Path() = %q", got)
+	}
+}
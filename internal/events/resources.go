@@ -0,0 +1,25 @@
+package events
+
+import "time"
+
+// ResourceUsage is what the agent's process tree used during an iteration.
+type ResourceUsage struct {
+	// CPUTime is the user and system CPU time of the agent and the
+	// subprocesses it waited for.
+	CPUTime time.Duration `json:"cpu_time,omitempty"`
+
+	// PeakMemory is the most resident memory in bytes: of the whole process
+	// tree where it can be sampled, of its largest process otherwise.
+	PeakMemory int64 `json:"peak_memory,omitempty"`
+
+	// Subprocesses is the number of distinct subprocesses seen while the
+	// agent ran, and PeakSubprocesses the most running at once (both 0
+	// where the process tree can't be sampled).
+	Subprocesses     int `json:"subprocesses,omitempty"`
+	PeakSubprocesses int `json:"peak_subprocesses,omitempty"`
+}
+
+// Empty reports whether no usage was recorded.
+func (u *ResourceUsage) Empty() bool {
+	return u == nil || (u.CPUTime == 0 && u.PeakMemory == 0 && u.Subprocesses == 0)
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToolStats counts the tool calls the agent made during an iteration.
+type ToolStats struct {
+	// Calls is the number of calls per tool name, e.g. {"Edit": 12, "Bash": 4}.
+	Calls map[string]int `json:"calls,omitempty"`
+
+	// Edits is the number of file edits (Edit, MultiEdit, Write, NotebookEdit).
+	Edits int `json:"edits,omitempty"`
+
+	// Files are the distinct files edited, in the order first edited.
+	Files []string `json:"files,omitempty"`
+
+	// Commands is the number of shell commands run.
+	Commands int `json:"commands,omitempty"`
+
+	// TestRuns is the number of shell commands that ran tests.
+	TestRuns int `json:"test_runs,omitempty"`
+
+	// WebFetches is the number of web fetches and searches.
+	WebFetches int `json:"web_fetches,omitempty"`
+}
+
+// Empty reports whether no tool calls were recorded.
+func (s *ToolStats) Empty() bool {
+	return s == nil || len(s.Calls) == 0
+}
+
+// Total returns the total number of tool calls.
+func (s *ToolStats) Total() int {
+	if s == nil {
+		return 0
+	}
+	total := 0
+	for _, n := range s.Calls {
+		total += n
+	}
+	return total
+}
+
+// Summary describes the stats in a short phrase, e.g.
+// "12 edits across 5 files, 3 test runs, 4 other commands, 1 web fetch".
+// Returns "" if no tool calls were recorded.
+func (s *ToolStats) Summary() string {
+	if s.Empty() {
+		return ""
+	}
+
+	var parts []string
+	if s.Edits > 0 {
+		parts = append(parts, fmt.Sprintf("%s across %s", plural(s.Edits, "edit"), plural(len(s.Files), "file")))
+	}
+	if s.TestRuns > 0 {
+		parts = append(parts, plural(s.TestRuns, "test run"))
+	}
+	if other := s.Commands - s.TestRuns; other > 0 {
+		noun := "command"
+		if s.TestRuns > 0 {
+			noun = "other command"
+		}
+		parts = append(parts, plural(other, noun))
+	}
+	if s.WebFetches > 0 {
+		parts = append(parts, plural(s.WebFetches, "web fetch"))
+	}
+	if len(parts) == 0 {
+		return plural(s.Total(), "tool call")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural formats n with noun, adding "s" (or "es") when n != 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	if strings.HasSuffix(noun, "ch") {
+		return fmt.Sprintf("%d %ses", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
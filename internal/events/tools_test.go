@@ -0,0 +1,69 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToolStats_Summary(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats *ToolStats
+		want  string
+	}{
+		{"nil", nil, ""},
+		{"empty", &ToolStats{}, ""},
+		{
+			"edits and tests",
+			&ToolStats{Calls: map[string]int{"Edit": 12, "Bash": 3}, Edits: 12, Files: []string{"a", "b", "c", "d", "e"}, Commands: 3, TestRuns: 3},
+			"12 edits across 5 files, 3 test runs",
+		},
+		{
+			"mixed",
+			&ToolStats{Calls: map[string]int{"Write": 1, "Bash": 5, "WebFetch": 1}, Edits: 1, Files: []string{"a"}, Commands: 5, TestRuns: 1, WebFetches: 1},
+			"1 edit across 1 file, 1 test run, 4 other commands, 1 web fetch",
+		},
+		{
+			"commands only",
+			&ToolStats{Calls: map[string]int{"Bash": 2, "WebSearch": 2}, Commands: 2, WebFetches: 2},
+			"2 commands, 2 web fetches",
+		},
+		{
+			"reads only",
+			&ToolStats{Calls: map[string]int{"Read": 4, "Grep": 2}},
+			"6 tool calls",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvent_ToolsRoundTrip(t *testing.T) {
+	ev := Event{Type: TypeIteration, Plan: "alpha", Tools: &ToolStats{Calls: map[string]int{"Edit": 2}, Edits: 2, Files: []string{"main.go"}}}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Event
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got.Tools, ev.Tools) {
+		t.Errorf("Tools = %+v, want %+v", got.Tools, ev.Tools)
+	}
+
+	data, _ = json.Marshal(Event{Type: TypeIteration})
+	var raw map[string]interface{}
+	json.Unmarshal(data, &raw)
+	if _, ok := raw["tools"]; ok {
+		t.Errorf("tools should be omitted when nil: %s", data)
+	}
+}
@@ -0,0 +1,118 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// TestsPlaceholder is replaced with the failing test names in flaky.rerun.
+const TestsPlaceholder = "{{TESTS}}"
+
+// unnamedFailure stands for a failing run whose failed tests couldn't be
+// identified, e.g. a build error.
+const unnamedFailure = "(unidentified failure)"
+
+// goFailRegex matches a failed Go test: "--- FAIL: TestName (0.01s)".
+var goFailRegex = regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`)
+
+// pytestFailRegex matches a failed pytest test: "FAILED tests/test_x.py::test_y".
+var pytestFailRegex = regexp.MustCompile(`(?m)^FAILED (\S+)`)
+
+// FailedTests returns the failed tests named in Go test or pytest output,
+// in order and without duplicates. Go subtests are reported as their
+// top-level test, which is what "go test -run" re-runs.
+func FailedTests(output string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, m := range goFailRegex.FindAllStringSubmatch(output, -1) {
+		name, _, _ := strings.Cut(m[1], "/")
+		add(name)
+	}
+	for _, m := range pytestFailRegex.FindAllStringSubmatch(output, -1) {
+		add(m[1])
+	}
+	return names
+}
+
+// RunTests runs the test gate. When it fails, the tests are re-run up to
+// flaky.Retries times: tests that fail in some runs and pass in others are
+// flaky and listed in Result.Flaky, and the gate passes unless a test
+// failed in every run.
+func RunTests(ctx context.Context, dir, command string, flaky config.FlakyConfig) Result {
+	start := time.Now()
+	output, err := Output(ctx, dir, command)
+	result := Result{Name: Test, Command: command, Passed: err == nil, Output: tail(output)}
+	if result.Passed || flaky.Retries <= 0 {
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	failures := make(map[string]int)
+	first := FailedTests(output)
+	countFailures(failures, first)
+
+	runs := 1
+	for i := 0; i < flaky.Retries && ctx.Err() == nil; i++ {
+		rerun := command
+		if flaky.Rerun != "" && len(first) > 0 {
+			rerun = strings.ReplaceAll(flaky.Rerun, TestsPlaceholder, strings.Join(first, "|"))
+		}
+		out, err := Output(ctx, dir, rerun)
+		runs++
+		if err != nil {
+			countFailures(failures, FailedTests(out))
+		}
+	}
+
+	var flakyTests, failing []string
+	for name, n := range failures {
+		if n < runs {
+			flakyTests = append(flakyTests, fmt.Sprintf("%s (failed %d of %d runs)", name, n, runs))
+		} else {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(flakyTests)
+	result.Flaky = flakyTests
+	result.Passed = len(failing) == 0
+	result.Duration = time.Since(start)
+	return result
+}
+
+// countFailures counts one failure for each of names, or an unidentified
+// failure if the failing run named no tests.
+func countFailures(failures map[string]int, names []string) {
+	if len(names) == 0 {
+		names = []string{unnamedFailure}
+	}
+	for _, name := range names {
+		failures[name]++
+	}
+}
+
+// FlakyReport formats the flaky tests of a result for the feedback file.
+// Returns "" if there are none.
+func FlakyReport(r Result) string {
+	if len(r.Flaky) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("**Flaky tests (quarantined):**\n")
+	for _, name := range r.Flaky {
+		fmt.Fprintf(&b, "- %s\n", name)
+	}
+	b.WriteString("These tests fail intermittently with the same code, so their failures aren't caused by your changes. Don't chase them as part of this plan.")
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package gate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestFailedTests(t *testing.T) {
+	output := `=== RUN   TestParse
+--- FAIL: TestParse (0.00s)
+    --- FAIL: TestParse/empty (0.00s)
+--- FAIL: TestLoad (0.01s)
+FAIL
+FAILED tests/test_api.py::test_create - AssertionError
+`
+	got := FailedTests(output)
+	want := []string{"TestParse", "TestLoad", "tests/test_api.py::test_create"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("FailedTests() = %v, want %v", got, want)
+	}
+}
+
+func TestRunTests_Flaky(t *testing.T) {
+	dir := t.TempDir()
+	// TestFlaky fails on the first run only; TestBroken fails every run
+	script := `n=$(cat runs 2>/dev/null || echo 0); n=$((n+1)); echo $n > runs
+if [ $n -eq 1 ]; then echo '--- FAIL: TestFlaky (0.00s)'; fi
+echo '--- FAIL: TestBroken (0.00s)'; exit 1`
+
+	r := RunTests(context.Background(), dir, script, config.FlakyConfig{Retries: 2})
+	if r.Passed {
+		t.Errorf("RunTests() passed with a consistently failing test: %+v", r)
+	}
+	if len(r.Flaky) != 1 || r.Flaky[0] != "TestFlaky (failed 1 of 3 runs)" {
+		t.Errorf("Flaky = %v, want TestFlaky", r.Flaky)
+	}
+
+	// A gate whose only failures are flaky passes
+	dir = t.TempDir()
+	script = `n=$(cat runs 2>/dev/null || echo 0); n=$((n+1)); echo $n > runs
+if [ $n -eq 1 ]; then echo '--- FAIL: TestFlaky (0.00s)'; exit 1; fi`
+	r = RunTests(context.Background(), dir, script, config.FlakyConfig{Retries: 2})
+	if !r.Passed || len(r.Flaky) != 1 {
+		t.Errorf("RunTests() = %+v, want passed with TestFlaky reported", r)
+	}
+	if report := FlakyReport(r); !strings.Contains(report, "- TestFlaky (failed 1 of 3 runs)") {
+		t.Errorf("FlakyReport() = %q", report)
+	}
+}
+
+func TestRunTests_Rerun(t *testing.T) {
+	dir := t.TempDir()
+	r := RunTests(context.Background(), dir, "echo '--- FAIL: TestA (0.00s)'; echo '--- FAIL: TestB (0.00s)'; exit 1",
+		config.FlakyConfig{Retries: 1, Rerun: "echo '{{TESTS}}' > rerun.txt"})
+	if !r.Passed || len(r.Flaky) != 2 {
+		t.Errorf("RunTests() = %+v, want both tests flaky after the re-run passed", r)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "rerun.txt")); strings.TrimSpace(string(data)) != "TestA|TestB" {
+		t.Errorf("re-run got %q, want the failing tests", data)
+	}
+
+	// Without retries, a failure is final
+	if r := RunTests(context.Background(), dir, "exit 1", config.FlakyConfig{}); r.Passed || r.Flaky != nil {
+		t.Errorf("RunTests() without retries = %+v", r)
+	}
+	// A failure no test is named for fails every run
+	if r := RunTests(context.Background(), dir, "echo 'build failed'; exit 2", config.FlakyConfig{Retries: 1}); r.Passed {
+		t.Errorf("RunTests() with an unidentified failure = %+v, want failed", r)
+	}
+}
@@ -0,0 +1,126 @@
+// Package gate runs a project's quality gates, the configured test and lint
+// commands, and reports whether they pass.
+package gate
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// Gate names.
+const (
+	Test     = "test"
+	Lint     = "lint"
+	Coverage = "coverage"
+	Bench    = "bench"
+)
+
+// DefaultTimeout bounds how long a single gate may run.
+const DefaultTimeout = 10 * time.Minute
+
+// MaxOutput is how much of a gate's output is kept, from the end.
+const MaxOutput = 4 * 1024
+
+// Result is the outcome of running one gate.
+type Result struct {
+	// Name is the gate name (see Test and Lint).
+	Name string `json:"name"`
+
+	// Command is the shell command that was run.
+	Command string `json:"command"`
+
+	// Passed is true if the command exited successfully.
+	Passed bool `json:"passed"`
+
+	// Output is the tail of the combined stdout/stderr, up to MaxOutput bytes.
+	Output string `json:"output,omitempty"`
+
+	// Flaky lists the tests that failed inconsistently when a failing test
+	// gate was re-run (see RunTests).
+	Flaky []string `json:"flaky,omitempty"`
+
+	// Duration is how long the command ran.
+	Duration time.Duration `json:"duration"`
+}
+
+// Run runs a gate's command in dir with DefaultTimeout. A command that
+// can't start, fails, or times out doesn't pass.
+func Run(ctx context.Context, dir, name, command string) Result {
+	start := time.Now()
+	output, err := Output(ctx, dir, command)
+	return Result{
+		Name:     name,
+		Command:  command,
+		Passed:   err == nil,
+		Output:   tail(output),
+		Duration: time.Since(start),
+	}
+}
+
+// Output runs command in dir with DefaultTimeout and returns its full
+// combined stdout/stderr. The error is non-nil if the command can't start,
+// fails, or times out.
+func Output(ctx context.Context, dir, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(output) + "\ntimed out after " + DefaultTimeout.String(), ctx.Err()
+	}
+	return string(output), err
+}
+
+// RunAll runs the test and then the lint gate in dir, skipping gates whose
+// command isn't configured. A failing test gate is re-run per flaky (see
+// RunTests).
+func RunAll(ctx context.Context, dir string, commands config.CommandsConfig, flaky config.FlakyConfig) []Result {
+	var results []Result
+	for _, g := range []struct{ name, command string }{
+		{Test, commands.Test},
+		{Lint, commands.Lint},
+	} {
+		if strings.TrimSpace(g.command) == "" {
+			continue
+		}
+		if g.name == Test {
+			results = append(results, RunTests(ctx, dir, g.command, flaky))
+			continue
+		}
+		results = append(results, Run(ctx, dir, g.name, g.command))
+	}
+	return results
+}
+
+// Passed reports whether every result passed.
+func Passed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// tail returns the last MaxOutput bytes of s.
+func tail(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= MaxOutput {
+		return s
+	}
+	return "..." + s[len(s)-MaxOutput:]
+}
@@ -0,0 +1,59 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	pass := Run(context.Background(), dir, Test, "echo ok")
+	if !pass.Passed || pass.Output != "ok" || pass.Name != Test || pass.Command != "echo ok" {
+		t.Errorf("Run(echo ok) = %+v", pass)
+	}
+
+	fail := Run(context.Background(), dir, Lint, "echo broken; exit 1")
+	if fail.Passed || fail.Output != "broken" {
+		t.Errorf("Run(exit 1) = %+v", fail)
+	}
+}
+
+func TestRun_TruncatesOutput(t *testing.T) {
+	r := Run(context.Background(), t.TempDir(), Test, "yes x | head -c 10000; echo; echo last")
+	if len(r.Output) > MaxOutput+3 || !strings.HasSuffix(r.Output, "last") || !strings.HasPrefix(r.Output, "...") {
+		t.Errorf("Output is %d bytes, ending %q", len(r.Output), r.Output[len(r.Output)-10:])
+	}
+}
+
+func TestOutput(t *testing.T) {
+	output, err := Output(context.Background(), t.TempDir(), "yes x | head -c 10000")
+	if err != nil || len(output) != 10000 {
+		t.Errorf("Output() = %d bytes, %v; want all 10000", len(output), err)
+	}
+	if _, err := Output(context.Background(), t.TempDir(), "exit 3"); err == nil {
+		t.Error("Output() should fail when the command fails")
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	results := RunAll(context.Background(), t.TempDir(), config.CommandsConfig{Test: "true", Lint: "false"}, config.FlakyConfig{})
+	if len(results) != 2 || results[0].Name != Test || !results[0].Passed || results[1].Name != Lint || results[1].Passed {
+		t.Errorf("RunAll() = %+v", results)
+	}
+	if Passed(results) {
+		t.Error("Passed() should be false when a gate fails")
+	}
+
+	// Unconfigured gates are skipped
+	results = RunAll(context.Background(), t.TempDir(), config.CommandsConfig{Lint: "true"}, config.FlakyConfig{})
+	if len(results) != 1 || results[0].Name != Lint || !Passed(results) {
+		t.Errorf("RunAll() with only lint = %+v", results)
+	}
+	if got := RunAll(context.Background(), t.TempDir(), config.CommandsConfig{}, config.FlakyConfig{}); len(got) != 0 {
+		t.Errorf("RunAll() with no commands = %+v", got)
+	}
+}
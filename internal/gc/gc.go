@@ -0,0 +1,272 @@
+// Package gc removes the runtime artifacts of long-finished plans from the
+// .ralph directory: per-plan logs, execution context and checkpoints left
+// in imports and orphaned worktrees, scratch directories, and Slack thread
+// tracker entries.
+//
+// What a plan leaves behind as a record is kept: the plan file and its
+// progress, feedback, summary, and changes files in the plans directories,
+// and the events, audit, lessons, and knowledge files in .ralph.
+package gc
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// DefaultRetentionDays is how long a finished plan's artifacts are kept.
+const DefaultRetentionDays = 30
+
+// finishedDirs are the queue directories of finished plans.
+var finishedDirs = []string{"complete", "abandoned", "failed"}
+
+// Artifact kinds.
+const (
+	KindLog        = "log"
+	KindContext    = "context"
+	KindCheckpoint = "checkpoint"
+	KindThread     = "thread"
+	KindScratch    = "scratch"
+)
+
+// Options configures a collection.
+type Options struct {
+	// ConfigDir is the .ralph directory.
+	ConfigDir string
+
+	// PlansDir is the plans directory with the queue subdirectories.
+	PlansDir string
+
+	// WorktreesDir is the worktrees directory (optional). The execution
+	// context and checkpoint in a finished plan's worktree are removed if
+	// it's still there; the worktree itself is left to ralph cleanup.
+	WorktreesDir string
+
+	// ScratchDir holds the plans' scratch directories (optional; see
+	// worktree.ScratchRoot). Completed and abandoned plans' are removed
+	// right away, so this mostly catches failed plans'.
+	ScratchDir string
+
+	// Threads is the Slack thread tracker (optional).
+	Threads *notify.ThreadTracker
+
+	// RetentionDays is how long after a plan finished its artifacts are
+	// kept (0 = DefaultRetentionDays).
+	RetentionDays int
+
+	// DryRun reports what would be removed without removing anything.
+	DryRun bool
+
+	// Now is the current time (zero = time.Now()).
+	Now time.Time
+}
+
+// Artifact is a runtime artifact of a finished plan.
+type Artifact struct {
+	// Plan is the plan the artifact belongs to.
+	Plan string
+
+	// Kind is the kind of artifact (see Kind* constants).
+	Kind string
+
+	// Path is the file or directory removed.
+	Path string
+
+	// Size is the bytes the artifact took up on disk.
+	Size int64
+}
+
+// Result reports what a collection removed, or would remove in a dry run.
+type Result struct {
+	// Plans is the number of finished plans past the retention period.
+	Plans int
+
+	// Artifacts are the artifacts removed.
+	Artifacts []Artifact
+
+	// Reclaimed is the total size of the artifacts removed.
+	Reclaimed int64
+}
+
+// Collect removes the runtime artifacts of plans that finished (completed,
+// failed, or were abandoned) more than the retention period ago. A plan
+// counts as finished when its plan, progress, or summary file was last
+// written. Plans with the same name in pending/ or current/ are left alone.
+// An artifact that can't be removed is logged and skipped.
+func Collect(opts Options) (*Result, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	days := opts.RetentionDays
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+	cutoff := now.Add(-time.Duration(days) * 24 * time.Hour)
+
+	expired, err := expiredPlans(opts.PlansDir, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Plans: len(expired)}
+	for _, name := range expired {
+		for _, a := range artifacts(opts, name) {
+			if !opts.DryRun {
+				if err := os.RemoveAll(a.Path); err != nil {
+					log.Warn("Failed to remove %s: %v", a.Path, err)
+					continue
+				}
+			}
+			result.Artifacts = append(result.Artifacts, a)
+			result.Reclaimed += a.Size
+		}
+
+		if opts.Threads == nil {
+			continue
+		}
+		n := threadEntries(opts.Threads, name)
+		if n > 0 && !opts.DryRun {
+			if _, err := opts.Threads.DeletePlan(name); err != nil {
+				log.Warn("Failed to remove thread tracker entries for %s: %v", name, err)
+				continue
+			}
+		}
+		for i := 0; i < n; i++ {
+			result.Artifacts = append(result.Artifacts, Artifact{Plan: name, Kind: KindThread, Path: notify.ThreadTrackerPath(opts.ConfigDir)})
+		}
+	}
+	return result, nil
+}
+
+// expiredPlans returns the names of finished plans last written before
+// cutoff, sorted, leaving out names still pending or current.
+func expiredPlans(plansDir string, cutoff time.Time) ([]string, error) {
+	active := make(map[string]bool)
+	for _, dir := range []string{"pending", "current"} {
+		names, err := planFiles(filepath.Join(plansDir, dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			active[name] = true
+		}
+	}
+
+	var expired []string
+	for _, dir := range finishedDirs {
+		dir = filepath.Join(plansDir, dir)
+		names, err := planFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if active[name] {
+				continue
+			}
+			if finishedAt(dir, name).Before(cutoff) {
+				expired = append(expired, name)
+			}
+		}
+	}
+	sort.Strings(expired)
+	return expired, nil
+}
+
+// planFiles returns the names of the plans in a queue directory, without
+// parsing them. A missing directory has no plans.
+func planFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing %s: %w", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && plan.IsPlanFile(entry.Name()) {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+		}
+	}
+	return names, nil
+}
+
+// finishedAt returns when a finished plan's files were last written.
+func finishedAt(dir, name string) time.Time {
+	var latest time.Time
+	for _, suffix := range []string{".md", ".progress.md", ".summary.md"} {
+		if info, err := os.Stat(filepath.Join(dir, name+suffix)); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// artifacts returns the files a finished plan left behind in .ralph.
+func artifacts(opts Options, name string) []Artifact {
+	candidates := []Artifact{
+		{Plan: name, Kind: KindLog, Path: log.PlanLogPath(opts.ConfigDir, name)},
+		{Plan: name, Kind: KindContext, Path: filepath.Join(opts.ConfigDir, archive.ImportsDir, name)},
+	}
+	if opts.WorktreesDir != "" {
+		worktree := filepath.Join(opts.WorktreesDir, plan.Slug(name))
+		candidates = append(candidates,
+			Artifact{Plan: name, Kind: KindContext, Path: runner.ContextPath(worktree)},
+			Artifact{Plan: name, Kind: KindCheckpoint, Path: runner.CheckpointPath(worktree)},
+		)
+	}
+	if opts.ScratchDir != "" {
+		candidates = append(candidates, Artifact{Plan: name, Kind: KindScratch, Path: filepath.Join(opts.ScratchDir, worktree.ScratchName(name))})
+	}
+
+	var found []Artifact
+	for _, a := range candidates {
+		size, err := diskSize(a.Path)
+		if err != nil {
+			continue
+		}
+		a.Size = size
+		found = append(found, a)
+	}
+	return found
+}
+
+// threadEntries counts a plan's entries in the thread tracker.
+func threadEntries(threads *notify.ThreadTracker, name string) int {
+	n := 0
+	for _, info := range threads.List() {
+		if info.PlanName == name {
+			n++
+		}
+	}
+	return n
+}
+
+// diskSize returns the size of a file, or of the files under a directory.
+func diskSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,160 @@
+package gc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/archive"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// writeFile writes content to path, creating its directory, and sets its
+// modification time.
+func writeFile(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".ralph")
+	plansDir := filepath.Join(dir, "plans")
+	worktreesDir := filepath.Join(configDir, "worktrees")
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-40 * 24 * time.Hour)
+
+	// An old completed plan with a log, imported context, a leftover
+	// worktree context, and a Slack thread
+	writeFile(t, filepath.Join(plansDir, "complete", "old-plan.md"), "# Plan\n", old)
+	writeFile(t, filepath.Join(plansDir, "complete", "old-plan.summary.md"), "# Summary\n", old)
+	writeFile(t, log.PlanLogPath(configDir, "old-plan"), "0123456789", old)
+	writeFile(t, filepath.Join(configDir, archive.ImportsDir, "old-plan", runner.ContextFilename), "{}", old)
+	writeFile(t, runner.ContextPath(filepath.Join(worktreesDir, "old-plan")), "{}", old)
+	threads, _ := notify.NewThreadTracker(notify.ThreadTrackerPath(configDir))
+	threads.Set("old-plan", &notify.ThreadInfo{ThreadTS: "1"})
+	threads.Set("recent-plan", &notify.ThreadInfo{ThreadTS: "2"})
+
+	// A plan whose progress was written recently, a recent failed plan, and
+	// an old abandoned plan that was requeued under the same name
+	writeFile(t, filepath.Join(plansDir, "complete", "recent-plan.md"), "# Plan\n", old)
+	writeFile(t, filepath.Join(plansDir, "complete", "recent-plan.progress.md"), "# Progress\n", now.Add(-time.Hour))
+	writeFile(t, log.PlanLogPath(configDir, "recent-plan"), "log", old)
+	writeFile(t, filepath.Join(plansDir, "failed", "broken.md"), "# Plan\n", now.Add(-24*time.Hour))
+	writeFile(t, log.PlanLogPath(configDir, "broken"), "log", old)
+	writeFile(t, filepath.Join(plansDir, "abandoned", "again.md"), "# Plan\n", old)
+	writeFile(t, filepath.Join(plansDir, "pending", "again.md"), "# Plan\n", now)
+	writeFile(t, log.PlanLogPath(configDir, "again"), "log", old)
+
+	opts := Options{ConfigDir: configDir, PlansDir: plansDir, WorktreesDir: worktreesDir, Threads: threads, Now: now, DryRun: true}
+	result, err := Collect(opts)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if result.Plans != 1 || len(result.Artifacts) != 4 || result.Reclaimed != 14 {
+		t.Errorf("dry run = %d plans, %+v, %d bytes; want 1 plan, 4 artifacts, 14 bytes", result.Plans, result.Artifacts, result.Reclaimed)
+	}
+	if !exists(log.PlanLogPath(configDir, "old-plan")) || threads.Get("old-plan") == nil {
+		t.Fatal("a dry run should not remove anything")
+	}
+
+	opts.DryRun = false
+	if _, err := Collect(opts); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	for _, path := range []string{
+		log.PlanLogPath(configDir, "old-plan"),
+		filepath.Join(configDir, archive.ImportsDir, "old-plan"),
+		runner.ContextPath(filepath.Join(worktreesDir, "old-plan")),
+	} {
+		if exists(path) {
+			t.Errorf("%s should be removed", path)
+		}
+	}
+	if threads.Get("old-plan") != nil {
+		t.Error("old-plan's thread entry should be removed")
+	}
+
+	// The plan's record and the other plans' artifacts are kept
+	for _, path := range []string{
+		filepath.Join(plansDir, "complete", "old-plan.md"),
+		filepath.Join(plansDir, "complete", "old-plan.summary.md"),
+		log.PlanLogPath(configDir, "recent-plan"),
+		log.PlanLogPath(configDir, "broken"),
+		log.PlanLogPath(configDir, "again"),
+	} {
+		if !exists(path) {
+			t.Errorf("%s should be kept", path)
+		}
+	}
+	if threads.Get("recent-plan") == nil {
+		t.Error("recent-plan's thread entry should be kept")
+	}
+}
+
+func TestCollect_RetentionDays(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".ralph")
+	plansDir := filepath.Join(dir, "plans")
+	now := time.Now()
+	writeFile(t, filepath.Join(plansDir, "complete", "last-week.md"), "# Plan\n", now.Add(-8*24*time.Hour))
+	writeFile(t, log.PlanLogPath(configDir, "last-week"), "log", now)
+
+	result, err := Collect(Options{ConfigDir: configDir, PlansDir: plansDir})
+	if err != nil || result.Plans != 0 {
+		t.Fatalf("Collect() with the default retention = %+v, %v; want nothing collected", result, err)
+	}
+	result, err = Collect(Options{ConfigDir: configDir, PlansDir: plansDir, RetentionDays: 7})
+	if err != nil || result.Plans != 1 || len(result.Artifacts) != 1 {
+		t.Errorf("Collect(RetentionDays: 7) = %+v, %v; want the log collected", result, err)
+	}
+}
+
+func TestCollect_NoPlansDir(t *testing.T) {
+	result, err := Collect(Options{ConfigDir: t.TempDir(), PlansDir: filepath.Join(t.TempDir(), "missing")})
+	if err != nil || result.Plans != 0 || len(result.Artifacts) != 0 {
+		t.Errorf("Collect() = %+v, %v; want nothing", result, err)
+	}
+}
+
+func TestCollect_Scratch(t *testing.T) {
+	dir := t.TempDir()
+	plansDir := filepath.Join(dir, "plans")
+	scratchDir := filepath.Join(dir, "scratch")
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-40 * 24 * time.Hour)
+
+	// Old and recent failed plans that left their scratch directories
+	writeFile(t, filepath.Join(plansDir, "failed", "old-plan.md"), "# Plan\n", old)
+	writeFile(t, filepath.Join(scratchDir, worktree.ScratchName("old-plan"), "build", "app"), "0123456789", old)
+	writeFile(t, filepath.Join(plansDir, "failed", "recent-plan.md"), "# Plan\n", now)
+	writeFile(t, filepath.Join(scratchDir, worktree.ScratchName("recent-plan"), "app"), "app", now)
+
+	result, err := Collect(Options{ConfigDir: filepath.Join(dir, ".ralph"), PlansDir: plansDir, ScratchDir: scratchDir, Now: now})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].Kind != KindScratch || result.Reclaimed != 10 {
+		t.Errorf("Collect() = %+v, %d bytes; want old-plan's scratch directory, 10 bytes", result.Artifacts, result.Reclaimed)
+	}
+	if exists(filepath.Join(scratchDir, worktree.ScratchName("old-plan"))) || !exists(filepath.Join(scratchDir, worktree.ScratchName("recent-plan"))) {
+		t.Error("only old-plan's scratch directory should be removed")
+	}
+}
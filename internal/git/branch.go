@@ -0,0 +1,85 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// BranchNameData is what a git.branch_template is rendered with.
+type BranchNameData struct {
+	// Name is the plan name as used in file and branch names ("add-login").
+	Name string
+
+	// Date is the day the plan started, as YYYY-MM-DD.
+	Date string
+
+	// Jira, Linear, and GitHub are the plan's issue headers, if any
+	// ("PROJ-123", "ENG-42", "17").
+	Jira   string
+	Linear string
+	GitHub string
+}
+
+// RenderBranchName renders a branch name template such as
+// "ralph/{{.Date}}/{{.Name}}". Path segments left empty or dangling by unset
+// fields ("{{.Jira}}/{{.Name}}" for a plan without a Jira issue) are dropped,
+// and the result must be a valid branch name (see CheckBranchName).
+func RenderBranchName(tmpl string, data BranchNameData) (string, error) {
+	t, err := template.New("branch").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing branch template: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("rendering branch template: %w", err)
+	}
+
+	var parts []string
+	for _, part := range strings.Split(sb.String(), "/") {
+		if part = strings.Trim(strings.TrimSpace(part), "-_"); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	name := strings.Join(parts, "/")
+	if err := CheckBranchName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// CheckBranchName returns an error if name isn't a valid branch name under
+// git's ref name rules (git check-ref-format --branch).
+func CheckBranchName(name string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid branch name %q: %s", name, reason)
+	}
+
+	switch {
+	case name == "":
+		return invalid("empty")
+	case name == "@":
+		return invalid(`"@" is reserved`)
+	case strings.HasPrefix(name, "-"):
+		return invalid(`starts with "-"`)
+	case strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return invalid(`ends with "/" or "."`)
+	case strings.Contains(name, ".."):
+		return invalid(`contains ".."`)
+	case strings.Contains(name, "@{"):
+		return invalid(`contains "@{"`)
+	case strings.Contains(name, "//"):
+		return invalid("contains an empty path component")
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return invalid(fmt.Sprintf("contains %q", r))
+		}
+	}
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return invalid(`a path component starts with "." or ends with ".lock"`)
+		}
+	}
+	return nil
+}
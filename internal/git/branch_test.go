@@ -0,0 +1,46 @@
+package git
+
+import "testing"
+
+func TestRenderBranchName(t *testing.T) {
+	data := BranchNameData{Name: "add-login", Date: "2024-01-30", Jira: "PROJ-123"}
+
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{"feat/{{.Name}}", "feat/add-login", false},
+		{"ralph/{{.Date}}/{{.Name}}", "ralph/2024-01-30/add-login", false},
+		{"{{.Jira}}-{{.Name}}", "PROJ-123-add-login", false},
+		// Unset fields drop their path segment and separators
+		{"{{.Linear}}/{{.Name}}", "add-login", false},
+		{"ralph/{{.GitHub}}-{{.Name}}", "ralph/add-login", false},
+		{"{{.Name", "", true},
+		{"{{.Owner}}/{{.Name}}", "", true},
+		{"{{.Name}}.lock", "", true},
+		{"{{.Linear}}", "", true},
+	}
+	for _, tt := range tests {
+		got, err := RenderBranchName(tt.tmpl, data)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("RenderBranchName(%q) = %q, %v; want %q, wantErr %v", tt.tmpl, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckBranchName(t *testing.T) {
+	valid := []string{"feat/add-login", "ralph/2024-01-30/add-login", "PROJ-123", "a.b"}
+	for _, name := range valid {
+		if err := CheckBranchName(name); err != nil {
+			t.Errorf("CheckBranchName(%q) error = %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "@", "-x", "feat/", "feat.", "a..b", "a@{b", "a//b", "a b", "a~1", "a^", "a:b", "a?", "a*", "a[b", `a\b`, "a\tb", ".hidden", "feat/.x", "x.lock", "feat/x.lock/y"}
+	for _, name := range invalid {
+		if err := CheckBranchName(name); err == nil {
+			t.Errorf("CheckBranchName(%q) should fail", name)
+		}
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -41,8 +44,32 @@ type WorktreeInfo struct {
 	Branch string // Branch checked out in this worktree (empty for detached HEAD)
 	Commit string // Commit SHA checked out
 	Bare   bool   // True if this is the bare repository
+
+	// Prunable is true if git considers the entry stale, e.g. its directory
+	// or .git file is gone. PruneReason is git's explanation.
+	Prunable    bool
+	PruneReason string
+}
+
+// FileChange is a file changed between two commits.
+type FileChange struct {
+	Status  string // Change status: A (added), M (modified), D (deleted), R (renamed), C (copied), T (type changed)
+	Path    string // Path after the change
+	OldPath string // Path before a rename or copy (empty otherwise)
+	Added   int    // Lines added (0 for binary files)
+	Deleted int    // Lines deleted (0 for binary files)
 }
 
+// FileStat is the size of the uncommitted change to one file.
+type FileStat struct {
+	Path    string // File path relative to the repository root
+	Added   int    // Lines added (0 for binary files)
+	Deleted int    // Lines deleted (0 for binary files)
+}
+
+// emptyTree is the hash of git's empty tree, used to diff from before the first commit.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
 // Git defines the interface for git operations.
 type Git interface {
 	// Status returns the current status of the working tree.
@@ -67,6 +94,9 @@ type Git interface {
 	// CurrentBranch returns the name of the current branch.
 	CurrentBranch() (string, error)
 
+	// HeadCommit returns the SHA of the current HEAD commit.
+	HeadCommit() (string, error)
+
 	// CreateBranch creates a new branch at the current HEAD.
 	CreateBranch(name string) error
 
@@ -76,6 +106,9 @@ type Git interface {
 	// DeleteRemoteBranch deletes a remote branch.
 	DeleteRemoteBranch(remote, branch string) error
 
+	// PruneRemote removes remote-tracking refs for branches deleted on the remote.
+	PruneRemote(remote string) error
+
 	// BranchExists checks if a branch exists locally.
 	BranchExists(name string) (bool, error)
 
@@ -105,6 +138,66 @@ type Git interface {
 
 	// ListWorktrees returns information about all worktrees in the repository.
 	ListWorktrees() ([]WorktreeInfo, error)
+
+	// DiffFiles returns the files changed between two commits with their line
+	// counts, detecting renames. An empty from diffs against the empty tree.
+	DiffFiles(from, to string) ([]FileChange, error)
+
+	// Diff returns the unified diff between two commits, detecting renames.
+	// An empty from diffs against the empty tree.
+	Diff(from, to string) (string, error)
+
+	// RemoteURL returns the fetch URL of the named remote.
+	RemoteURL(remote string) (string, error)
+
+	// UncommittedStats returns per-file line counts of the changes since HEAD,
+	// including untracked files.
+	UncommittedStats() ([]FileStat, error)
+
+	// StagedChanges returns the files staged for the next commit.
+	StagedChanges() ([]FileChange, error)
+
+	// Unstage removes files from the index, keeping them in the working tree.
+	Unstage(files ...string) error
+
+	// Restore discards the changes to tracked files, resetting them in the
+	// index and working tree to HEAD.
+	Restore(files ...string) error
+
+	// PruneWorktrees removes stale worktree entries whose directories are gone.
+	PruneWorktrees() error
+
+	// RepairWorktree rewrites the links between the repository and the worktree at path.
+	RepairWorktree(path string) error
+
+	// IsAncestor reports whether commit ancestor is reachable from commit descendant.
+	IsAncestor(ancestor, descendant string) (bool, error)
+
+	// LFSTracked returns which of files are stored with git-lfs (filter=lfs).
+	LFSTracked(files ...string) (map[string]bool, error)
+
+	// Log returns `git log --stat` output for the last n first-parent commits
+	// in revRange (a branch, commit, or A..B range).
+	Log(revRange string, n int) (string, error)
+
+	// CommitsWithTrailers returns the SHAs of the commits in revRange whose
+	// messages have all of the given trailers (e.g. "Ralph-Plan": "my-plan"),
+	// newest first.
+	CommitsWithTrailers(revRange string, trailers map[string]string) ([]string, error)
+
+	// UpstreamDivergence returns the current branch's upstream (e.g.
+	// "origin/main") and how many commits HEAD is ahead of and behind it, as
+	// of the last fetch. Returns an empty upstream if none is configured.
+	UpstreamDivergence() (upstream string, ahead, behind int, err error)
+
+	// AbortOperation aborts an interrupted merge, rebase, am, cherry-pick,
+	// or revert (see InterruptedOperation).
+	AbortOperation(op string) error
+
+	// CommitSnapshot commits files (tree path → source file) as the whole
+	// tree of branch, without touching the working tree, index, or HEAD.
+	// Returns "" if the tree is unchanged.
+	CommitSnapshot(branch, message string, files map[string]string) (string, error)
 }
 
 // CLIGit implements Git interface using git CLI commands.
@@ -183,7 +276,9 @@ func (g *CLIGit) Status() (*Status, error) {
 	status.Branch = branch
 
 	// Get status in porcelain format (use runRaw to preserve leading spaces)
-	output, _, err := g.runRaw("status", "--porcelain")
+	// Changes inside a submodule's working tree can't be committed from
+	// here, so only count submodules whose checked-out commit changed
+	output, _, err := g.runRaw("status", "--porcelain", "--ignore-submodules=dirty")
 	if err != nil {
 		return nil, fmt.Errorf("getting status: %w", err)
 	}
@@ -301,6 +396,15 @@ func (g *CLIGit) CurrentBranch() (string, error) {
 	return branch, nil
 }
 
+// HeadCommit returns the SHA of the current HEAD commit.
+func (g *CLIGit) HeadCommit() (string, error) {
+	sha, stderr, err := g.run("rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("getting HEAD commit: %s: %w", stderr, err)
+	}
+	return sha, nil
+}
+
 // CreateBranch creates a new branch at the current HEAD.
 func (g *CLIGit) CreateBranch(name string) error {
 	_, stderr, err := g.run("branch", name)
@@ -338,6 +442,15 @@ func (g *CLIGit) DeleteRemoteBranch(remote, branch string) error {
 	return nil
 }
 
+// PruneRemote removes remote-tracking refs for branches deleted on the remote.
+func (g *CLIGit) PruneRemote(remote string) error {
+	_, stderr, err := g.run("remote", "prune", remote)
+	if err != nil {
+		return fmt.Errorf("git remote prune: %s: %w", stderr, err)
+	}
+	return nil
+}
+
 // BranchExists checks if a branch exists locally.
 func (g *CLIGit) BranchExists(name string) (bool, error) {
 	_, _, err := g.run("show-ref", "--verify", "--quiet", "refs/heads/"+name)
@@ -442,8 +555,9 @@ func (g *CLIGit) RemoveWorktree(path string) error {
 		if strings.Contains(stderr, "is not a working tree") || strings.Contains(stderr, "No such file or directory") {
 			return ErrWorktreeNotFound
 		}
-		// If there are untracked files or changes, try force
-		if strings.Contains(stderr, "contains modified or untracked files") {
+		// If there are untracked files or changes, or submodules (which git
+		// never removes without force), try force
+		if strings.Contains(stderr, "contains modified or untracked files") || strings.Contains(stderr, "containing submodules") {
 			_, stderr, err = g.run("worktree", "remove", "--force", path)
 			if err != nil {
 				return fmt.Errorf("git worktree remove --force: %s: %w", stderr, err)
@@ -492,6 +606,9 @@ func (g *CLIGit) ListWorktrees() ([]WorktreeInfo, error) {
 			current.Bare = true
 		} else if strings.HasPrefix(line, "detached") && current != nil {
 			// Detached HEAD - branch stays empty
+		} else if strings.HasPrefix(line, "prunable") && current != nil {
+			current.Prunable = true
+			current.PruneReason = strings.TrimSpace(strings.TrimPrefix(line, "prunable"))
 		}
 	}
 
@@ -502,3 +619,322 @@ func (g *CLIGit) ListWorktrees() ([]WorktreeInfo, error) {
 
 	return worktrees, nil
 }
+
+// PruneWorktrees removes stale worktree entries whose directories are gone.
+func (g *CLIGit) PruneWorktrees() error {
+	if _, stderr, err := g.run("worktree", "prune"); err != nil {
+		return fmt.Errorf("git worktree prune: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// RepairWorktree rewrites the links between the repository and the worktree
+// at path, e.g. a missing or broken .git file. git can fix a link yet still
+// exit non-zero, so the worktree is checked again afterwards.
+func (g *CLIGit) RepairWorktree(path string) error {
+	_, stderr, err := g.run("worktree", "repair", path)
+	if !IsLinkedWorktree(path) {
+		if err == nil {
+			err = errors.New("worktree is still not linked")
+		}
+		return fmt.Errorf("git worktree repair: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// IsLinkedWorktree reports whether path has a .git file pointing to an
+// existing worktree directory in the repository.
+func IsLinkedWorktree(path string) bool {
+	gitDir, err := WorktreeGitDir(path)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(gitDir)
+	return err == nil && info.IsDir()
+}
+
+// WorktreeGitDir returns the repository directory a linked worktree's .git
+// file points to, e.g. "/repo/.git/worktrees/my-feature".
+func WorktreeGitDir(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s/.git is not a worktree link", path)
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(path, gitDir)
+	}
+	return gitDir, nil
+}
+
+// IsAncestor reports whether commit ancestor is reachable from commit descendant.
+func (g *CLIGit) IsAncestor(ancestor, descendant string) (bool, error) {
+	_, stderr, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("git merge-base: %s: %w", stderr, err)
+	}
+	return true, nil
+}
+
+// LFSTracked returns which of files are stored with git-lfs, i.e. have the
+// filter=lfs attribute. Their content is committed as a small pointer file.
+func (g *CLIGit) LFSTracked(files ...string) (map[string]bool, error) {
+	tracked := make(map[string]bool)
+	if len(files) == 0 {
+		return tracked, nil
+	}
+
+	args := append([]string{"check-attr", "-z", "filter", "--"}, files...)
+	output, stderr, err := g.runRaw(args...)
+	if err != nil {
+		return nil, fmt.Errorf("git check-attr: %s: %w", strings.TrimSpace(stderr), err)
+	}
+
+	// Format with -z: PATH\0ATTRIBUTE\0VALUE\0
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			tracked[fields[i]] = true
+		}
+	}
+	return tracked, nil
+}
+
+// Log returns `git log --stat` output for the last n commits in revRange (a
+// branch, commit, or A..B range), each headed "<sha> <subject> (<author>,
+// <date>)". Only first-parent commits are listed, so a merged branch shows up
+// as its merge commit with the merge's combined stat. Returns "" if the range
+// has no commits.
+func (g *CLIGit) Log(revRange string, n int) (string, error) {
+	output, stderr, err := g.run("log", "-n", strconv.Itoa(n), "--first-parent", "--stat=100", "--stat-count=10",
+		"--format=%h %s (%an, %ar)", revRange, "--")
+	if err != nil {
+		return "", fmt.Errorf("git log %s: %s: %w", revRange, stderr, err)
+	}
+	return output, nil
+}
+
+// CommitsWithTrailers returns the SHAs of the commits in revRange that have
+// all of the given "Key: value" trailer lines, newest first.
+func (g *CLIGit) CommitsWithTrailers(revRange string, trailers map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := []string{"log", "--format=%H", "--extended-regexp", "--all-match"}
+	for _, key := range keys {
+		args = append(args, "--grep=^"+regexp.QuoteMeta(key+": "+trailers[key])+"$")
+	}
+	output, stderr, err := g.run(append(args, revRange, "--")...)
+	if err != nil {
+		return nil, fmt.Errorf("git log %s: %s: %w", revRange, stderr, err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// UpstreamDivergence returns the current branch's upstream and how many
+// commits HEAD is ahead of and behind it. Remote refs aren't fetched, so the
+// counts are as of the last fetch. Returns an empty upstream if the branch
+// has none (or HEAD is detached).
+func (g *CLIGit) UpstreamDivergence() (string, int, int, error) {
+	upstream, _, err := g.run("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil || upstream == "" {
+		return "", 0, 0, nil
+	}
+
+	output, stderr, err := g.run("rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+	if err != nil {
+		return upstream, 0, 0, fmt.Errorf("git rev-list: %s: %w", stderr, err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return upstream, 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return upstream, 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return upstream, 0, 0, fmt.Errorf("unexpected rev-list output: %q", output)
+	}
+	return upstream, ahead, behind, nil
+}
+
+// DiffFiles returns the files changed between two commits with their line
+// counts, detecting renames. An empty from diffs against the empty tree.
+func (g *CLIGit) DiffFiles(from, to string) ([]FileChange, error) {
+	if from == "" {
+		from = emptyTree
+	}
+	output, stderr, err := g.runRaw("diff", "--name-status", "-z", "-M", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("git diff %s %s: %s: %w", from, to, strings.TrimSpace(stderr), err)
+	}
+	changes := parseNameStatus(output)
+
+	output, stderr, err = g.runRaw("diff", "--numstat", "-z", "-M", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat %s %s: %s: %w", from, to, strings.TrimSpace(stderr), err)
+	}
+	stats := parseNumstat(output)
+	for i := range changes {
+		if stat, ok := stats[changes[i].Path]; ok {
+			changes[i].Added, changes[i].Deleted = stat.Added, stat.Deleted
+		}
+	}
+	return changes, nil
+}
+
+// Diff returns the unified diff between two commits, detecting renames.
+// An empty from diffs against the empty tree.
+func (g *CLIGit) Diff(from, to string) (string, error) {
+	if from == "" {
+		from = emptyTree
+	}
+	output, stderr, err := g.runRaw("diff", "--no-color", "--no-ext-diff", "-M", from, to)
+	if err != nil {
+		return "", fmt.Errorf("git diff %s %s: %s: %w", from, to, strings.TrimSpace(stderr), err)
+	}
+	return output, nil
+}
+
+// RemoteURL returns the fetch URL of the named remote.
+func (g *CLIGit) RemoteURL(remote string) (string, error) {
+	url, stderr, err := g.run("remote", "get-url", remote)
+	if err != nil {
+		return "", fmt.Errorf("getting URL of remote %s: %s: %w", remote, stderr, err)
+	}
+	return url, nil
+}
+
+// parseNumstat parses `git diff --numstat -z` output into line counts by path.
+func parseNumstat(output string) map[string]FileStat {
+	// Format with -z: ADDED\tDELETED\tPATH\0, or ADDED\tDELETED\t\0OLD\0NEW\0
+	// for renames and copies, with "-" counts for binary files
+	stats := make(map[string]FileStat)
+	fields := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2]}
+		if stat.Path == "" {
+			if i+2 >= len(fields) {
+				break
+			}
+			stat.Path = fields[i+2]
+			i += 2
+		}
+		stat.Added, _ = strconv.Atoi(parts[0])
+		stat.Deleted, _ = strconv.Atoi(parts[1])
+		stats[stat.Path] = stat
+	}
+	return stats
+}
+
+// parseNameStatus parses `git diff --name-status -z` output.
+func parseNameStatus(output string) []FileChange {
+	// Format with -z: STATUS\0PATH\0, or STATUS\0OLD\0NEW\0 for renames and copies
+	var changes []FileChange
+	fields := strings.Split(strings.TrimSuffix(output, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" || i+1 >= len(fields) {
+			continue
+		}
+		change := FileChange{Status: status[:1]}
+		if change.Status == "R" || change.Status == "C" {
+			if i+2 >= len(fields) {
+				break
+			}
+			change.OldPath, change.Path = fields[i+1], fields[i+2]
+			i += 2
+		} else {
+			change.Path = fields[i+1]
+			i++
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// StagedChanges returns the files staged for the next commit, without
+// rename detection (a staged rename is a deletion and an addition).
+func (g *CLIGit) StagedChanges() ([]FileChange, error) {
+	output, stderr, err := g.runRaw("diff", "--cached", "--name-status", "-z", "--no-renames")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --cached: %s: %w", strings.TrimSpace(stderr), err)
+	}
+	return parseNameStatus(output), nil
+}
+
+// Unstage removes files from the index, keeping them in the working tree.
+func (g *CLIGit) Unstage(files ...string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	args := append([]string{"reset", "-q", "--"}, files...)
+	_, stderr, err := g.run(args...)
+	if err != nil {
+		return fmt.Errorf("git reset: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// Restore discards the changes to tracked files, resetting them in the
+// index and working tree to HEAD.
+func (g *CLIGit) Restore(files ...string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	args := append([]string{"checkout", "-q", "HEAD", "--"}, files...)
+	_, stderr, err := g.run(args...)
+	if err != nil {
+		return fmt.Errorf("git checkout: %s: %w", stderr, err)
+	}
+	return nil
+}
+
+// UncommittedStats returns per-file line counts of the changes since HEAD,
+// including untracked files. Untracked files are marked intent-to-add so the
+// diff includes them; they are still staged normally by the next Add.
+func (g *CLIGit) UncommittedStats() ([]FileStat, error) {
+	if _, stderr, err := g.run("add", "-A", "--intent-to-add"); err != nil {
+		return nil, fmt.Errorf("git add --intent-to-add: %s: %w", stderr, err)
+	}
+	output, stderr, err := g.runRaw("diff", "HEAD", "--numstat", "--no-renames", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("git diff --numstat: %s: %w", strings.TrimSpace(stderr), err)
+	}
+
+	// Format with -z: ADDED\tDELETED\tPATH\0, with "-" counts for binary files
+	var stats []FileStat
+	for _, entry := range strings.Split(output, "\x00") {
+		parts := strings.SplitN(entry, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		stat := FileStat{Path: parts[2]}
+		stat.Added, _ = strconv.Atoi(parts[0])
+		stat.Deleted, _ = strconv.Atoi(parts[1])
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
@@ -1,9 +1,11 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -254,6 +256,29 @@ func TestCurrentBranch(t *testing.T) {
 	}
 }
 
+func TestHeadCommit(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	if _, err := g.HeadCommit(); err == nil {
+		t.Error("HeadCommit() should fail before the first commit")
+	}
+
+	createFile(t, repoDir, "README.md", "# Test\n")
+	if err := g.Commit("Initial commit", "README.md"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+
+	sha, err := g.HeadCommit()
+	if err != nil {
+		t.Fatalf("HeadCommit: %v", err)
+	}
+	if len(sha) != 40 {
+		t.Errorf("HeadCommit() = %q, want a full SHA", sha)
+	}
+}
+
 func TestCreateBranch(t *testing.T) {
 	repoDir, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -838,3 +863,520 @@ func TestWorktreeInfo(t *testing.T) {
 		t.Error("Bare should be false")
 	}
 }
+
+func TestDiffFiles(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "# Test\n")
+	createFile(t, repoDir, "old name.txt", "some content that is long enough to be detected as a rename\n")
+	createFile(t, repoDir, "doomed.txt", "bye\n")
+	if err := g.Commit("Initial commit", "README.md", "old name.txt", "doomed.txt"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+	first, _ := g.HeadCommit()
+
+	// From the empty tree, everything is added
+	changes, err := g.DiffFiles("", first)
+	if err != nil {
+		t.Fatalf("DiffFiles from empty tree: %v", err)
+	}
+	if len(changes) != 3 || changes[0].Status != "A" {
+		t.Errorf("DiffFiles(\"\", first) = %+v, want 3 added files", changes)
+	}
+
+	createFile(t, repoDir, "README.md", "# Test\n\nMore.\n")
+	createFile(t, repoDir, "src/new.go", "package src\n")
+	exec.Command("git", "-C", repoDir, "mv", "old name.txt", "new name.txt").Run()
+	exec.Command("git", "-C", repoDir, "rm", "-q", "doomed.txt").Run()
+	if err := g.Commit("Second commit", "README.md", "src/new.go"); err != nil {
+		t.Fatalf("second commit: %v", err)
+	}
+	second, _ := g.HeadCommit()
+
+	changes, err = g.DiffFiles(first, second)
+	if err != nil {
+		t.Fatalf("DiffFiles: %v", err)
+	}
+	want := []FileChange{
+		{Status: "M", Path: "README.md", Added: 2},
+		{Status: "D", Path: "doomed.txt", Deleted: 1},
+		{Status: "R", Path: "new name.txt", OldPath: "old name.txt"},
+		{Status: "A", Path: "src/new.go", Added: 1},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("DiffFiles() = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "# Test\n")
+	if err := g.Commit("Initial commit", "README.md"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+	first, _ := g.HeadCommit()
+
+	createFile(t, repoDir, "README.md", "# Test\n\nMore.\n")
+	if err := g.Commit("Second commit", "README.md"); err != nil {
+		t.Fatalf("second commit: %v", err)
+	}
+	second, _ := g.HeadCommit()
+
+	diff, err := g.Diff(first, second)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !strings.HasPrefix(diff, "diff --git a/README.md b/README.md\n") || !strings.Contains(diff, "\n+More.\n") {
+		t.Errorf("Diff() = %q, want the README change", diff)
+	}
+
+	if diff, err := g.Diff("", first); err != nil || !strings.Contains(diff, "new file mode") {
+		t.Errorf("Diff from the empty tree = %q, %v", diff, err)
+	}
+}
+
+func TestRemoteURL(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	if _, err := g.RemoteURL("origin"); err == nil {
+		t.Error("RemoteURL() without the remote succeeded")
+	}
+	exec.Command("git", "-C", repoDir, "remote", "add", "origin", "git@github.com:acme/app.git").Run()
+	if url, err := g.RemoteURL("origin"); err != nil || url != "git@github.com:acme/app.git" {
+		t.Errorf("RemoteURL() = %q, %v", url, err)
+	}
+}
+
+func TestUncommittedStats(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "one\ntwo\nthree\n")
+	if err := g.Commit("Initial commit", "README.md"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+
+	stats, err := g.UncommittedStats()
+	if err != nil || len(stats) != 0 {
+		t.Fatalf("UncommittedStats() on a clean tree = %+v, %v", stats, err)
+	}
+
+	createFile(t, repoDir, "README.md", "one\n2\nthree\nfour\n")
+	createFile(t, repoDir, "src/new.go", "package src\n\nfunc New() {}\n")
+	stats, err = g.UncommittedStats()
+	if err != nil {
+		t.Fatalf("UncommittedStats: %v", err)
+	}
+	want := []FileStat{
+		{Path: "README.md", Added: 2, Deleted: 1},
+		{Path: "src/new.go", Added: 3},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("UncommittedStats() = %+v, want %+v", stats, want)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stat %d = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+
+	// The untracked file is still committed by a normal commit
+	status, _ := g.Status()
+	if err := g.Commit("Second commit", append(status.Unstaged, status.Untracked...)...); err != nil {
+		t.Fatalf("commit after stats: %v", err)
+	}
+	if clean, _ := g.IsClean(); !clean {
+		t.Error("expected a clean tree after committing")
+	}
+}
+
+func TestStagedChanges_Unstage(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "hello\n")
+	createFile(t, repoDir, "old.txt", "old\n")
+	if err := g.Commit("Initial commit", "README.md", "old.txt"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+
+	createFile(t, repoDir, "README.md", "changed\n")
+	createFile(t, repoDir, "big.bin", "binary\n")
+	if err := os.Remove(filepath.Join(repoDir, "old.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add("README.md", "big.bin", "old.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	staged, err := g.StagedChanges()
+	if err != nil {
+		t.Fatalf("StagedChanges: %v", err)
+	}
+	want := []FileChange{{Status: "M", Path: "README.md"}, {Status: "A", Path: "big.bin"}, {Status: "D", Path: "old.txt"}}
+	if len(staged) != len(want) {
+		t.Fatalf("StagedChanges() = %+v, want %+v", staged, want)
+	}
+	for i := range want {
+		if staged[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, staged[i], want[i])
+		}
+	}
+
+	if err := g.Unstage("big.bin"); err != nil {
+		t.Fatalf("Unstage: %v", err)
+	}
+	staged, _ = g.StagedChanges()
+	for _, c := range staged {
+		if c.Path == "big.bin" {
+			t.Error("big.bin still staged after Unstage")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "big.bin")); err != nil {
+		t.Errorf("Unstage removed the file from the working tree: %v", err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "hello\n")
+	createFile(t, repoDir, "old.txt", "old\n")
+	if err := g.Commit("Initial commit", "README.md", "old.txt"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+
+	createFile(t, repoDir, "README.md", "changed\n")
+	if err := os.Remove(filepath.Join(repoDir, "old.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Add("README.md", "old.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if err := g.Restore("README.md", "old.txt"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repoDir, "README.md")); string(data) != "hello\n" {
+		t.Errorf("README.md = %q, want the committed content", data)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "old.txt")); err != nil {
+		t.Errorf("old.txt not restored: %v", err)
+	}
+	if clean, _ := g.IsClean(); !clean {
+		t.Error("expected a clean tree after Restore")
+	}
+}
+
+func TestPruneAndRepairWorktree(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	createFile(t, repoDir, "README.md", "# Test\n")
+	g := NewGit(repoDir)
+	if err := g.Commit("Initial commit", "README.md"); err != nil {
+		t.Fatalf("initial commit: %v", err)
+	}
+	wt := filepath.Join(repoDir, ".worktrees", "feature")
+	if err := g.CreateWorktree(wt, "feature"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	if !IsLinkedWorktree(wt) {
+		t.Fatal("new worktree should be linked")
+	}
+
+	// A missing .git file makes the entry prunable and is fixed by RepairWorktree
+	os.Remove(filepath.Join(wt, ".git"))
+	worktrees, _ := g.ListWorktrees()
+	if len(worktrees) != 2 || !worktrees[1].Prunable || worktrees[1].PruneReason == "" {
+		t.Fatalf("ListWorktrees() = %+v, want a prunable feature worktree", worktrees)
+	}
+	if err := g.RepairWorktree(wt); err != nil {
+		t.Fatalf("RepairWorktree: %v", err)
+	}
+	if !IsLinkedWorktree(wt) {
+		t.Error("worktree should be linked after repair")
+	}
+
+	// Once the directory is gone, PruneWorktrees drops the entry
+	os.RemoveAll(wt)
+	if err := g.PruneWorktrees(); err != nil {
+		t.Fatalf("PruneWorktrees: %v", err)
+	}
+	if worktrees, _ := g.ListWorktrees(); len(worktrees) != 1 {
+		t.Errorf("ListWorktrees() after prune = %+v, want only the main worktree", worktrees)
+	}
+	if err := g.RepairWorktree(wt); err == nil {
+		t.Error("RepairWorktree of a missing worktree should fail")
+	}
+}
+
+func TestIsAncestor(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "README.md", "one\n")
+	if err := g.Commit("First", "README.md"); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := g.HeadCommit()
+	createFile(t, repoDir, "README.md", "two\n")
+	if err := g.Commit("Second", "README.md"); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := g.HeadCommit()
+
+	if ok, err := g.IsAncestor(first, second); err != nil || !ok {
+		t.Errorf("IsAncestor(first, second) = %v, %v; want true", ok, err)
+	}
+	if ok, err := g.IsAncestor(second, first); err != nil || ok {
+		t.Errorf("IsAncestor(second, first) = %v, %v; want false", ok, err)
+	}
+	if _, err := g.IsAncestor("no-such-ref", second); err == nil {
+		t.Error("IsAncestor with an unknown ref should fail")
+	}
+}
+
+func TestLFSTracked(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	createFile(t, repoDir, ".gitattributes", "*.psd filter=lfs diff=lfs merge=lfs -text\n")
+	g := NewGit(repoDir)
+	tracked, err := g.LFSTracked("art/logo.psd", "main.go")
+	if err != nil {
+		t.Fatalf("LFSTracked: %v", err)
+	}
+	if !tracked["art/logo.psd"] || tracked["main.go"] || len(tracked) != 1 {
+		t.Errorf("LFSTracked() = %v, want only art/logo.psd", tracked)
+	}
+	if tracked, err := g.LFSTracked(); err != nil || len(tracked) != 0 {
+		t.Errorf("LFSTracked() with no files = %v, %v", tracked, err)
+	}
+}
+
+func TestSubmodules_StatusAndRemoveWorktree(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	libDir, cleanupLib := setupTestRepo(t)
+	defer cleanupLib()
+	createFile(t, libDir, "lib.go", "package lib\n")
+	if err := NewGit(libDir).Commit("lib", "lib.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	g := NewGit(repoDir)
+	cmd := exec.Command("git", "submodule", "add", "-q", libDir, "lib")
+	cmd.Dir = repoDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("submodule add: %v: %s", err, out)
+	}
+	if err := g.Commit("Add submodule"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Untracked files inside a submodule don't make the superproject dirty
+	createFile(t, repoDir, "lib/scratch.txt", "scratch\n")
+	if clean, err := g.IsClean(); err != nil || !clean {
+		t.Errorf("IsClean() with a dirty submodule = %v, %v; want true", clean, err)
+	}
+
+	// A worktree with an initialized submodule can still be removed
+	wt := filepath.Join(repoDir, ".worktrees", "feature")
+	if err := g.CreateWorktree(wt, "feature"); err != nil {
+		t.Fatalf("CreateWorktree: %v", err)
+	}
+	cmd = exec.Command("git", "submodule", "update", "--init")
+	cmd.Dir = wt
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("submodule update: %v: %s", err, out)
+	}
+	if err := g.RemoveWorktree(wt); err != nil {
+		t.Fatalf("RemoveWorktree with a submodule: %v", err)
+	}
+	if _, err := os.Stat(wt); !os.IsNotExist(err) {
+		t.Error("worktree directory should be removed")
+	}
+}
+
+func TestLog(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	for _, msg := range []string{"First", "Second", "Third"} {
+		createFile(t, repoDir, strings.ToLower(msg)+".txt", msg+"\n")
+		if err := g.Commit(msg, strings.ToLower(msg)+".txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := g.Log("main", 2)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if !strings.Contains(out, "Third (Test User,") || !strings.Contains(out, "second.txt | 1 +") {
+		t.Errorf("Log() should list commits with author and stat, got:\n%s", out)
+	}
+	if strings.Contains(out, "First") {
+		t.Errorf("Log(n=2) should stop after two commits, got:\n%s", out)
+	}
+
+	if out, err := g.Log("main..main", 2); err != nil || out != "" {
+		t.Errorf("Log(empty range) = %q, %v; want empty", out, err)
+	}
+	if _, err := g.Log("no-such-ref", 2); err == nil {
+		t.Error("Log with an unknown ref should fail")
+	}
+}
+
+func TestCommitsWithTrailers(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	messages := []string{
+		"ralph: iteration 1\n\nRalph-Plan: a.b\nRalph-Iteration: 1\n",
+		"ralph: iteration 12\n\nRalph-Plan: a.b\nRalph-Iteration: 12\n",
+		"ralph: iteration 1\n\nRalph-Plan: axb\nRalph-Iteration: 1\n",
+	}
+	var shas []string
+	for i, msg := range messages {
+		name := fmt.Sprintf("f%d.txt", i)
+		createFile(t, repoDir, name, msg)
+		if err := g.Commit(msg, name); err != nil {
+			t.Fatal(err)
+		}
+		sha, _ := g.HeadCommit()
+		shas = append(shas, sha)
+	}
+
+	// Values match whole trailer lines, literally
+	got, err := g.CommitsWithTrailers("HEAD", map[string]string{"Ralph-Plan": "a.b", "Ralph-Iteration": "1"})
+	if err != nil {
+		t.Fatalf("CommitsWithTrailers() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != shas[0] {
+		t.Errorf("CommitsWithTrailers() = %v, want [%s]", got, shas[0])
+	}
+
+	got, _ = g.CommitsWithTrailers("HEAD", map[string]string{"Ralph-Plan": "a.b"})
+	if len(got) != 2 || got[0] != shas[1] {
+		t.Errorf("CommitsWithTrailers(plan) = %v, want newest first", got)
+	}
+
+	if got, err := g.CommitsWithTrailers(shas[0]+"..HEAD", map[string]string{"Ralph-Iteration": "1"}); err != nil || len(got) != 1 || got[0] != shas[2] {
+		t.Errorf("CommitsWithTrailers(range) = %v, %v; want [%s]", got, err, shas[2])
+	}
+}
+
+func TestPruneRemote(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "a.txt", "a\n")
+	if err := g.Commit("First", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	remoteDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", "-b", "main", remoteDir},
+		{"-C", repoDir, "remote", "add", "origin", remoteDir},
+		{"-C", repoDir, "push", "origin", "main", "main:feat/done"},
+		{"-C", repoDir, "fetch", "origin"},
+		// Deleted on the remote by someone else, e.g. when its PR merged
+		{"-C", remoteDir, "branch", "-D", "feat/done"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	refExists := func() bool {
+		return exec.Command("git", "-C", repoDir, "show-ref", "--verify", "--quiet", "refs/remotes/origin/feat/done").Run() == nil
+	}
+	if !refExists() {
+		t.Fatal("origin/feat/done should exist before pruning")
+	}
+	if err := g.PruneRemote("origin"); err != nil {
+		t.Fatalf("PruneRemote() error = %v", err)
+	}
+	if refExists() {
+		t.Error("origin/feat/done should be pruned")
+	}
+}
+
+func TestUpstreamDivergence(t *testing.T) {
+	repoDir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	g := NewGit(repoDir)
+	createFile(t, repoDir, "a.txt", "a\n")
+	if err := g.Commit("First", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if upstream, _, _, err := g.UpstreamDivergence(); err != nil || upstream != "" {
+		t.Fatalf("UpstreamDivergence() without upstream = %q, %v; want empty", upstream, err)
+	}
+
+	remoteDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", "-b", "main", remoteDir},
+		{"-C", repoDir, "remote", "add", "origin", remoteDir},
+		{"-C", repoDir, "push", "-u", "origin", "main"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	// One commit ahead locally, then one behind after the remote moves on
+	createFile(t, repoDir, "b.txt", "b\n")
+	if err := g.Commit("Second", "b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"-C", repoDir, "push", "origin", "HEAD:main"},
+		{"-C", repoDir, "reset", "--hard", "HEAD~1"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	createFile(t, repoDir, "c.txt", "c\n")
+	if err := g.Commit("Third", "c.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream, ahead, behind, err := g.UpstreamDivergence()
+	if err != nil {
+		t.Fatalf("UpstreamDivergence() error = %v", err)
+	}
+	if upstream != "origin/main" || ahead != 1 || behind != 1 {
+		t.Errorf("UpstreamDivergence() = %q, %d ahead, %d behind; want origin/main, 1, 1", upstream, ahead, behind)
+	}
+}
@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Operations that git can leave half-done in a working tree, named after
+// the git command that continues or aborts them.
+const (
+	OpRebase     = "rebase"
+	OpAm         = "am"
+	OpMerge      = "merge"
+	OpCherryPick = "cherry-pick"
+	OpRevert     = "revert"
+)
+
+// InterruptedOperation returns the operation in progress in the git
+// directory gitDir (a merge waiting on conflicts, a stopped rebase, ...), or
+// "" if there is none. A rebase is reported before the cherry-pick it may be
+// running.
+func InterruptedOperation(gitDir string) string {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	switch {
+	case exists("rebase-merge"):
+		return OpRebase
+	case exists(filepath.Join("rebase-apply", "applying")):
+		return OpAm
+	case exists("rebase-apply"):
+		return OpRebase
+	case exists("MERGE_HEAD"):
+		return OpMerge
+	case exists("CHERRY_PICK_HEAD"):
+		return OpCherryPick
+	case exists("REVERT_HEAD"):
+		return OpRevert
+	}
+	return ""
+}
+
+// AbortOperation aborts an interrupted operation (see InterruptedOperation),
+// restoring the branch and working tree to where they were before it started.
+func (g *CLIGit) AbortOperation(op string) error {
+	switch op {
+	case OpRebase, OpAm, OpMerge, OpCherryPick, OpRevert:
+	default:
+		return fmt.Errorf("unknown git operation %q", op)
+	}
+	if _, stderr, err := g.run(op, "--abort"); err != nil {
+		return fmt.Errorf("git %s --abort: %s: %w", op, stderr, err)
+	}
+	return nil
+}
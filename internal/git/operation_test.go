@@ -0,0 +1,77 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterruptedOperation(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"MERGE_HEAD":                OpMerge,
+		"CHERRY_PICK_HEAD":          OpCherryPick,
+		"REVERT_HEAD":               OpRevert,
+		"rebase-merge/":             OpRebase,
+		"rebase-apply/":             OpRebase,
+		"rebase-apply/applying":     OpAm,
+		"rebase-merge/git-rebase-x": OpRebase,
+	}
+	for path, want := range tests {
+		gitDir := t.TempDir()
+		full := filepath.Join(gitDir, path)
+		if strings.HasSuffix(path, "/") {
+			os.MkdirAll(full, 0755)
+		} else if path != "" {
+			os.MkdirAll(filepath.Dir(full), 0755)
+			os.WriteFile(full, []byte("abc\n"), 0644)
+		}
+		if got := InterruptedOperation(gitDir); got != want {
+			t.Errorf("InterruptedOperation() with %q = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestAbortOperation_Merge(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+	g := NewGit(dir)
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("base\n")
+	g.Commit("base", "a.txt")
+	g.CreateBranch("other")
+	g.Checkout("other")
+	write("other\n")
+	g.Commit("other", "a.txt")
+	g.Checkout("main")
+	write("main\n")
+	g.Commit("main", "a.txt")
+
+	if err := g.Merge("other", true); err == nil {
+		t.Fatal("Merge() succeeded, want a conflict")
+	}
+	gitDir := filepath.Join(dir, ".git")
+	if op := InterruptedOperation(gitDir); op != OpMerge {
+		t.Fatalf("InterruptedOperation() = %q, want %q", op, OpMerge)
+	}
+
+	if err := g.AbortOperation(OpMerge); err != nil {
+		t.Fatalf("AbortOperation() error = %v", err)
+	}
+	if op := InterruptedOperation(gitDir); op != "" {
+		t.Errorf("InterruptedOperation() after abort = %q", op)
+	}
+	if clean, _ := g.IsClean(); !clean {
+		t.Error("working tree not clean after abort")
+	}
+	if err := g.AbortOperation("bisect"); err == nil {
+		t.Error("AbortOperation(bisect) succeeded, want an error")
+	}
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Git hosting providers, which lay out their web pages differently.
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+)
+
+// RemoteInfo is a remote repository on a git host, for linking to its web
+// pages.
+type RemoteInfo struct {
+	// Provider is the host's page layout (see Provider* constants).
+	Provider string
+
+	// Scheme is the web scheme, "https" unless the remote is plain http.
+	Scheme string
+
+	// Host is the web host, e.g. "github.com" or "git.example.com:8443".
+	Host string
+
+	// Path is the repository path, e.g. "acme/app" or "group/sub/app".
+	Path string
+}
+
+// ParseRemote parses a remote URL: https ("https://github.com/acme/app.git"),
+// ssh ("ssh://git@host:2222/acme/app"), or scp-like ("git@host:acme/app").
+// provider names the host's provider (see Provider* constants); empty
+// detects it from the host name, taking hosts it doesn't recognize, such as
+// a self-hosted GitHub Enterprise, for GitHub. Returns an error for remotes
+// without a web page, such as local paths.
+func ParseRemote(remote, provider string) (*RemoteInfo, error) {
+	trimmed := strings.TrimSuffix(strings.TrimSpace(remote), "/")
+	trimmed = strings.TrimSuffix(trimmed, ".git")
+
+	info := &RemoteInfo{Scheme: "https"}
+	var path string
+	if strings.Contains(trimmed, "://") {
+		u, err := url.Parse(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("parsing remote %q: %w", remote, err)
+		}
+		switch u.Scheme {
+		case "https", "http":
+			info.Scheme, info.Host = u.Scheme, u.Host
+		case "ssh", "git+ssh":
+			// The ssh port isn't the web port
+			info.Host = u.Hostname()
+		default:
+			return nil, fmt.Errorf("remote %q has no web page", remote)
+		}
+		path = u.Path
+	} else if at := strings.Index(trimmed, "@"); at >= 0 {
+		var ok bool
+		info.Host, path, ok = strings.Cut(trimmed[at+1:], ":")
+		if !ok {
+			return nil, fmt.Errorf("remote %q has no repository path", remote)
+		}
+	}
+
+	info.Path = strings.Trim(path, "/")
+	if info.Host == "" || info.Path == "" {
+		return nil, fmt.Errorf("remote %q has no web page", remote)
+	}
+
+	info.Provider = provider
+	if info.Provider == "" {
+		info.Provider = detectProvider(info.Host)
+	}
+	return info, nil
+}
+
+// LookupRemote returns the named remote of g (e.g. "origin"), parsed with
+// ParseRemote.
+func LookupRemote(g Git, name, provider string) (*RemoteInfo, error) {
+	remote, err := g.RemoteURL(name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRemote(remote, provider)
+}
+
+// detectProvider guesses a host's provider from its name.
+func detectProvider(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case strings.Contains(host, "bitbucket"):
+		return ProviderBitbucket
+	}
+	return ProviderGitHub
+}
+
+// URL returns the repository's web page.
+func (r *RemoteInfo) URL() string {
+	return r.Scheme + "://" + r.Host + "/" + r.Path
+}
+
+// CommitURL returns the web page of a commit.
+func (r *RemoteInfo) CommitURL(sha string) string {
+	switch r.Provider {
+	case ProviderGitLab:
+		return r.URL() + "/-/commit/" + sha
+	case ProviderBitbucket:
+		return r.URL() + "/commits/" + sha
+	}
+	return r.URL() + "/commit/" + sha
+}
+
+// BranchURL returns the web page of a branch's files.
+func (r *RemoteInfo) BranchURL(branch string) string {
+	switch r.Provider {
+	case ProviderGitLab:
+		return r.URL() + "/-/tree/" + branch
+	case ProviderBitbucket:
+		return r.URL() + "/src/" + branch
+	}
+	return r.URL() + "/tree/" + branch
+}
+
+// NewPullRequestURL returns the page that opens a pull request (a merge
+// request on GitLab) from head into base.
+func (r *RemoteInfo) NewPullRequestURL(base, head string) string {
+	switch r.Provider {
+	case ProviderGitLab:
+		q := url.Values{"merge_request[source_branch]": {head}, "merge_request[target_branch]": {base}}
+		return r.URL() + "/-/merge_requests/new?" + q.Encode()
+	case ProviderBitbucket:
+		q := url.Values{"source": {head}, "dest": {base}}
+		return r.URL() + "/pull-requests/new?" + q.Encode()
+	}
+	return r.URL() + "/compare/" + base + "..." + head + "?expand=1"
+}
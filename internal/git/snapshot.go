@@ -0,0 +1,119 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// CommitSnapshot commits the given files to branch without touching the
+// working tree, the index, or HEAD: the branch's tree becomes exactly files
+// (tree path → path of the file to read it from), with the branch's current
+// tip as the parent. The branch is created if it doesn't exist. Returns the
+// new commit's SHA, or "" if the tree is unchanged and nothing was committed.
+func (g *CLIGit) CommitSnapshot(branch, message string, files map[string]string) (string, error) {
+	if err := CheckBranchName(branch); err != nil {
+		return "", err
+	}
+	ref := "refs/heads/" + branch
+
+	index, err := os.CreateTemp("", "ralph-snapshot-index-*")
+	if err != nil {
+		return "", fmt.Errorf("creating snapshot index: %w", err)
+	}
+	index.Close()
+	// git refuses an empty file as an index; it creates its own
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if len(paths) > 0 {
+		var sources strings.Builder
+		for _, path := range paths {
+			sources.WriteString(files[path] + "\n")
+		}
+		output, stderr, err := g.runInput(nil, sources.String(), "hash-object", "-w", "--stdin-paths", "--no-filters")
+		if err != nil {
+			return "", fmt.Errorf("git hash-object: %s: %w", stderr, err)
+		}
+		hashes := strings.Fields(output)
+		if len(hashes) != len(paths) {
+			return "", fmt.Errorf("git hash-object: got %d hashes for %d files", len(hashes), len(paths))
+		}
+
+		var entries strings.Builder
+		for i, path := range paths {
+			mode := "100644"
+			if info, err := os.Stat(files[path]); err == nil && info.Mode()&0111 != 0 {
+				mode = "100755"
+			}
+			fmt.Fprintf(&entries, "%s %s\t%s\n", mode, hashes[i], path)
+		}
+		if _, stderr, err := g.runInput(env, entries.String(), "update-index", "--add", "--index-info"); err != nil {
+			return "", fmt.Errorf("git update-index: %s: %w", stderr, err)
+		}
+	} else if _, stderr, err := g.runWithEnv(env, "read-tree", "--empty"); err != nil {
+		return "", fmt.Errorf("git read-tree: %s: %w", stderr, err)
+	}
+
+	tree, stderr, err := g.runWithEnv(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("git write-tree: %s: %w", stderr, err)
+	}
+
+	parent, _, err := g.run("rev-parse", "-q", "--verify", ref+"^{commit}")
+	if err != nil {
+		parent = ""
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		if parentTree, _, err := g.run("rev-parse", parent+"^{tree}"); err == nil && parentTree == tree {
+			return "", nil
+		}
+		args = append(args, "-p", parent)
+	}
+	commit, stderr, err := g.run(args...)
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree: %s: %w", stderr, err)
+	}
+
+	// Passing the old tip makes the update fail if another writer moved the
+	// branch in the meantime, rather than dropping their commit
+	updateArgs := []string{"update-ref", "-m", "ralph: " + message, ref, commit}
+	if parent != "" {
+		updateArgs = append(updateArgs, parent)
+	} else {
+		updateArgs = append(updateArgs, "")
+	}
+	if _, stderr, err := g.run(updateArgs...); err != nil {
+		return "", fmt.Errorf("git update-ref %s: %s: %w", ref, stderr, err)
+	}
+	return commit, nil
+}
+
+// runInput executes a git command with input on stdin and optional extra
+// environment variables.
+func (g *CLIGit) runInput(env []string, input string, args ...string) (string, string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.workDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	return strings.TrimSpace(stdout.String()), strings.TrimSpace(stderr.String()), err
+}
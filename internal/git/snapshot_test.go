@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommitSnapshot(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# repo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewGit(dir)
+	if err := g.Commit("initial", "README.md"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	head, _ := g.HeadCommit()
+
+	src := t.TempDir()
+	plan := filepath.Join(src, "plan.md")
+	if err := os.WriteFile(plan, []byte("# Plan\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"current/plan.md": plan}
+
+	first, err := g.CommitSnapshot("ralph-state", "activate plan", files)
+	if err != nil {
+		t.Fatalf("CommitSnapshot() error = %v", err)
+	}
+	if first == "" {
+		t.Fatal("CommitSnapshot() = \"\", want a commit")
+	}
+	if got := gitOutput(t, dir, "show", "ralph-state:current/plan.md"); got != "# Plan" {
+		t.Errorf("ralph-state:current/plan.md = %q, want %q", got, "# Plan")
+	}
+
+	// The checkout is untouched
+	if branch, _ := g.CurrentBranch(); branch != "main" {
+		t.Errorf("CurrentBranch() = %q, want main", branch)
+	}
+	if after, _ := g.HeadCommit(); after != head {
+		t.Errorf("HEAD moved from %s to %s", head, after)
+	}
+	if clean, _ := g.IsClean(); !clean {
+		t.Error("working tree not clean after CommitSnapshot()")
+	}
+
+	// An unchanged tree commits nothing
+	again, err := g.CommitSnapshot("ralph-state", "again", files)
+	if err != nil || again != "" {
+		t.Errorf("CommitSnapshot() unchanged = %q, %v; want \"\", nil", again, err)
+	}
+
+	// A changed tree replaces the old one, with the previous snapshot as parent
+	files = map[string]string{"complete/plan.md": plan}
+	second, err := g.CommitSnapshot("ralph-state", "complete plan", files)
+	if err != nil || second == "" {
+		t.Fatalf("CommitSnapshot() = %q, %v", second, err)
+	}
+	if parent := gitOutput(t, dir, "rev-parse", "ralph-state^"); parent != first {
+		t.Errorf("ralph-state^ = %s, want %s", parent, first)
+	}
+	if tree := gitOutput(t, dir, "ls-tree", "-r", "--name-only", "ralph-state"); tree != "complete/plan.md" {
+		t.Errorf("ralph-state tree = %q, want complete/plan.md only", tree)
+	}
+	if msg := gitOutput(t, dir, "log", "-1", "--format=%s", "ralph-state"); msg != "complete plan" {
+		t.Errorf("ralph-state message = %q, want %q", msg, "complete plan")
+	}
+}
+
+func TestCommitSnapshot_InvalidBranch(t *testing.T) {
+	dir, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	if _, err := NewGit(dir).CommitSnapshot("bad..name", "msg", nil); err == nil {
+		t.Error("CommitSnapshot() with invalid branch: expected error")
+	}
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,179 @@
+// Package github syncs plans with GitHub Issues through the gh CLI, so it
+// uses the same authentication as pull request creation. It registers the
+// "github" tracker.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// ErrNotConfigured is returned when github.issues is off.
+var ErrNotConfigured = fmt.Errorf("github is %w (set github.issues: true)", tracker.ErrNotConfigured)
+
+func init() {
+	tracker.Register("github", func(cfg *config.Config) (tracker.Tracker, error) {
+		return NewTracker(cfg.GitHub)
+	})
+}
+
+// ghRun runs gh with args and returns its stdout.
+var ghRun = func(args ...string) ([]byte, error) {
+	cmd := exec.Command("gh", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gh %s: %s: %w", strings.Join(args[:min(len(args), 3)], " "), strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Tracker is the GitHub Issues tracker for plans with a **GitHub:** header.
+// Issues get the github.in_progress and github.in_review labels as the plan
+// advances and are closed when it is merged.
+type Tracker struct {
+	cfg config.GitHubConfig
+}
+
+// NewTracker creates a GitHub Issues tracker from cfg. Returns
+// ErrNotConfigured if github.issues is off.
+func NewTracker(cfg config.GitHubConfig) (*Tracker, error) {
+	if !cfg.Issues {
+		return nil, ErrNotConfigured
+	}
+	return &Tracker{cfg: cfg}, nil
+}
+
+// Name returns "github".
+func (t *Tracker) Name() string {
+	return "github"
+}
+
+// ParseKey accepts an issue number with or without "#".
+func (t *Tracker) ParseKey(s string) (string, error) {
+	key := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if n, err := strconv.Atoi(key); err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid GitHub issue number %q (expected e.g. 42 or #42)", s)
+	}
+	return key, nil
+}
+
+// IssueFor returns the plan's **GitHub:** issue number.
+func (t *Tracker) IssueFor(p *plan.Plan) string {
+	return p.GitHub
+}
+
+// FetchIssue fetches the issue with `gh issue view`.
+func (t *Tracker) FetchIssue(number string) (*tracker.Issue, error) {
+	out, err := t.gh("issue", "view", number, "--json", "number,title,body,labels,state,url")
+	if err != nil {
+		return nil, fmt.Errorf("fetching #%s: %w", number, err)
+	}
+	var resp struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		State  string `json:"state"`
+		URL    string `json:"url"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("parsing #%s: %w", number, err)
+	}
+	issue := &tracker.Issue{
+		Key:    strconv.Itoa(resp.Number),
+		Title:  resp.Title,
+		Body:   resp.Body,
+		Status: resp.State,
+		URL:    resp.URL,
+	}
+	for _, l := range resp.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue, nil
+}
+
+// PlanOptions builds a plan for the issue with a **GitHub:** header.
+func (t *Tracker) PlanOptions(issue *tracker.Issue) plan.ScaffoldOptions {
+	opts := tracker.ScaffoldOptions(issue, "GitHub issue")
+	opts.Title = fmt.Sprintf("#%s %s", issue.Key, issue.Title)
+	opts.Source = fmt.Sprintf("GitHub issue #%s (%s)", issue.Key, issue.URL)
+	opts.GitHub = issue.Key
+	return opts
+}
+
+// Comment posts text on the issue.
+func (t *Tracker) Comment(number, text string) error {
+	if _, err := t.gh("issue", "comment", number, "--body", text); err != nil {
+		return fmt.Errorf("commenting on #%s: %w", number, err)
+	}
+	return nil
+}
+
+// Transition labels the issue for stage: github.in_progress when the plan
+// starts, github.in_review in its place when the pull request opens, and
+// neither once it is closed on merge.
+func (t *Tracker) Transition(number, stage string) error {
+	var args []string
+	switch stage {
+	case tracker.StageInProgress:
+		args = labelArgs(t.cfg.InProgress, "")
+	case tracker.StageInReview:
+		args = labelArgs(t.cfg.InReview, t.cfg.InProgress)
+	case tracker.StageDone:
+		if remove := labelArgs("", t.cfg.InReview); remove != nil {
+			if _, err := t.gh(append([]string{"issue", "edit", number}, remove...)...); err != nil {
+				return fmt.Errorf("labeling #%s: %w", number, err)
+			}
+		}
+		if _, err := t.gh("issue", "close", number, "--reason", "completed"); err != nil {
+			return fmt.Errorf("closing #%s: %w", number, err)
+		}
+		return nil
+	}
+	if args == nil {
+		return nil
+	}
+	if _, err := t.gh(append([]string{"issue", "edit", number}, args...)...); err != nil {
+		return fmt.Errorf("labeling #%s: %w", number, err)
+	}
+	return nil
+}
+
+// LinkPR mentions the pull request on the issue, which GitHub shows as a
+// cross-reference on both.
+func (t *Tracker) LinkPR(number, prURL string) error {
+	return t.Comment(number, "Ralph opened a pull request: "+prURL)
+}
+
+// gh runs gh against github.repo, if set.
+func (t *Tracker) gh(args ...string) ([]byte, error) {
+	if t.cfg.Repo != "" {
+		args = append(args, "--repo", t.cfg.Repo)
+	}
+	return ghRun(args...)
+}
+
+// labelArgs returns `gh issue edit` arguments adding add and removing
+// remove, skipping empty labels, or nil if there is nothing to change.
+func labelArgs(add, remove string) []string {
+	var args []string
+	if add != "" {
+		args = append(args, "--add-label", add)
+	}
+	if remove != "" {
+		args = append(args, "--remove-label", remove)
+	}
+	return args
+}
@@ -0,0 +1,109 @@
+package github
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// stubGH replaces gh with a fake that answers `issue view` for issue 42 and
+// records every other command.
+func stubGH(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	old := ghRun
+	t.Cleanup(func() { ghRun = old })
+	ghRun = func(args ...string) ([]byte, error) {
+		if args[0] == "issue" && args[1] == "view" {
+			if args[2] != "42" {
+				return nil, errors.New("no issue")
+			}
+			return []byte(`{"number": 42, "title": "Fix login", "body": "Login fails on Safari.", "state": "OPEN", "url": "https://github.com/acme/app/issues/42", "labels": [{"name": "bug"}]}`), nil
+		}
+		calls = append(calls, strings.Join(args, " "))
+		return nil, nil
+	}
+	return &calls
+}
+
+func TestNewTracker(t *testing.T) {
+	if _, err := NewTracker(config.GitHubConfig{}); !errors.Is(err, tracker.ErrNotConfigured) {
+		t.Errorf("NewTracker() with issues off error = %v, want ErrNotConfigured", err)
+	}
+	cfg := config.Defaults()
+	cfg.GitHub.Issues = true
+	if tr, err := tracker.New("github", cfg); err != nil || tr.Name() != "github" {
+		t.Errorf("tracker.New(github) = %v, %v", tr, err)
+	}
+}
+
+func TestTracker_ParseKeyAndIssueFor(t *testing.T) {
+	tr := &Tracker{}
+	for in, want := range map[string]string{"42": "42", "#7": "7", " #3 ": "3"} {
+		if got, err := tr.ParseKey(in); err != nil || got != want {
+			t.Errorf("ParseKey(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "#", "abc", "0", "-1"} {
+		if _, err := tr.ParseKey(in); err == nil {
+			t.Errorf("ParseKey(%q) should fail", in)
+		}
+	}
+	if got := tr.IssueFor(&plan.Plan{GitHub: "42"}); got != "42" {
+		t.Errorf("IssueFor() = %q", got)
+	}
+}
+
+func TestTracker_FetchIssue(t *testing.T) {
+	stubGH(t)
+	tr := &Tracker{cfg: config.GitHubConfig{Issues: true}}
+
+	issue, err := tr.FetchIssue("42")
+	if err != nil {
+		t.Fatalf("FetchIssue() error = %v", err)
+	}
+	if issue.Key != "42" || issue.Title != "Fix login" || !issue.HasLabel("bug") {
+		t.Errorf("FetchIssue() = %+v", issue)
+	}
+	opts := tr.PlanOptions(issue)
+	if opts.Title != "#42 Fix login" || opts.GitHub != "42" || !strings.Contains(opts.Source, "issues/42") {
+		t.Errorf("PlanOptions() = %+v", opts)
+	}
+	if _, err := tr.FetchIssue("404"); err == nil {
+		t.Error("FetchIssue() of a missing issue should fail")
+	}
+}
+
+func TestTracker_TransitionAndLinkPR(t *testing.T) {
+	calls := stubGH(t)
+	tr := &Tracker{cfg: config.GitHubConfig{Issues: true, Repo: "acme/app", InProgress: "ralph: working", InReview: "ralph: review"}}
+
+	tr.Transition("42", tracker.StageInProgress)
+	tr.Transition("42", tracker.StageInReview)
+	tr.LinkPR("42", "https://github.com/acme/app/pull/7")
+	tr.Transition("42", tracker.StageDone)
+
+	want := []string{
+		"issue edit 42 --add-label ralph: working --repo acme/app",
+		"issue edit 42 --add-label ralph: review --remove-label ralph: working --repo acme/app",
+		"issue comment 42 --body Ralph opened a pull request: https://github.com/acme/app/pull/7 --repo acme/app",
+		"issue edit 42 --remove-label ralph: review --repo acme/app",
+		"issue close 42 --reason completed --repo acme/app",
+	}
+	if strings.Join(*calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("gh calls =\n%s\nwant\n%s", strings.Join(*calls, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Without labels only done does anything
+	*calls = nil
+	bare := &Tracker{cfg: config.GitHubConfig{Issues: true}}
+	bare.Transition("42", tracker.StageInProgress)
+	bare.Transition("42", tracker.StageDone)
+	if len(*calls) != 1 || (*calls)[0] != "issue close 42 --reason completed" {
+		t.Errorf("gh calls = %q, want only the close", *calls)
+	}
+}
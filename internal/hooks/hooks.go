@@ -0,0 +1,211 @@
+// Package hooks runs user-configured lifecycle hooks.
+// A hook is either a shell command or an http(s) URL. Commands receive the
+// event payload as JSON on stdin (and as RALPH_* environment variables);
+// URLs receive the payload as a JSON POST body.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// Event names sent in hook payloads.
+const (
+	EventPlanComplete = "plan_complete"
+	EventPlanError    = "plan_error"
+	EventPreIteration = "pre_iteration"
+)
+
+// DefaultTimeout bounds how long a single hook may run.
+const DefaultTimeout = 5 * time.Minute
+
+// Payload is the JSON document passed to every hook.
+type Payload struct {
+	// Event is the lifecycle event that triggered the hook (e.g., "plan_complete").
+	Event string `json:"event"`
+
+	// Plan is the plan name.
+	Plan string `json:"plan"`
+
+	// Branch is the plan's feature branch.
+	Branch string `json:"branch"`
+
+	// PRURL is the pull request URL, if one was created.
+	PRURL string `json:"pr_url,omitempty"`
+
+	// Result is the outcome: "complete" or "error".
+	Result string `json:"result"`
+
+	// Iterations is the number of iterations executed.
+	Iterations int `json:"iterations,omitempty"`
+
+	// Error is the error message for failed plans.
+	Error string `json:"error,omitempty"`
+
+	// Timestamp is when the event occurred.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Env returns the payload as RALPH_* environment variables.
+func (p Payload) Env() []string {
+	return []string{
+		"RALPH_EVENT=" + p.Event,
+		"RALPH_PLAN=" + p.Plan,
+		"RALPH_BRANCH=" + p.Branch,
+		"RALPH_PR_URL=" + p.PRURL,
+		"RALPH_RESULT=" + p.Result,
+		"RALPH_ITERATIONS=" + strconv.Itoa(p.Iterations),
+		"RALPH_ERROR=" + p.Error,
+	}
+}
+
+// Result contains the outcome of running a single hook.
+type Result struct {
+	// Hook is the command or URL that was run.
+	Hook string
+
+	// Output is the combined stdout/stderr (commands) or response status (URLs).
+	Output string
+
+	// Err is non-nil if the hook failed.
+	Err error
+}
+
+// Runner executes hooks.
+type Runner struct {
+	// WorkDir is the working directory for command hooks.
+	WorkDir string
+
+	// Env is additional environment for command hooks (e.g., MAIN_WORKTREE=...).
+	Env []string
+
+	// Timeout bounds each hook (default: DefaultTimeout).
+	Timeout time.Duration
+
+	// HTTPClient is used for URL hooks.
+	HTTPClient *http.Client
+}
+
+// NewRunner creates a hook Runner for the given working directory.
+func NewRunner(workDir string) *Runner {
+	return &Runner{
+		WorkDir: workDir,
+		Timeout: DefaultTimeout,
+		HTTPClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// IsURL returns true if the hook should be delivered over HTTP.
+func IsURL(hook string) bool {
+	return strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://")
+}
+
+// Run executes all hooks in order with the given payload.
+// Every hook is attempted; failures are logged and reported in the results.
+func (r *Runner) Run(ctx context.Context, hooks []string, payload Payload) []Result {
+	if payload.Timestamp.IsZero() {
+		payload.Timestamp = time.Now()
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return []Result{{Err: fmt.Errorf("marshaling hook payload: %w", err)}}
+	}
+
+	var results []Result
+	for _, hook := range hooks {
+		hook = strings.TrimSpace(hook)
+		if hook == "" {
+			continue
+		}
+
+		var res Result
+		if IsURL(hook) {
+			res = r.post(ctx, hook, body)
+		} else {
+			res = r.command(ctx, hook, body, payload.Env())
+		}
+
+		if res.Err != nil {
+			log.Warn("Hook %s failed: %v", payload.Event, res.Err)
+		} else {
+			log.Debug("Hook %s succeeded: %s", payload.Event, hook)
+		}
+		results = append(results, res)
+	}
+
+	return results
+}
+
+// command runs a shell command hook with the payload on stdin.
+func (r *Runner) command(ctx context.Context, hook string, body []byte, env []string) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout())
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", hook)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", hook)
+	}
+	cmd.Dir = r.WorkDir
+	cmd.Env = append(append(os.Environ(), r.Env...), env...)
+	cmd.Env = append(cmd.Env, "RALPH_PAYLOAD="+string(body))
+	cmd.Stdin = bytes.NewReader(body)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return Result{Hook: hook, Output: string(output), Err: fmt.Errorf("command failed: %w\nOutput:\n%s", err, output)}
+	}
+	return Result{Hook: hook, Output: string(output)}
+}
+
+// post delivers the payload to a URL hook.
+func (r *Runner) post(ctx context.Context, url string, body []byte) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{Hook: url, Err: fmt.Errorf("creating request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ralph-hooks")
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{Hook: url, Err: fmt.Errorf("sending request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Result{Hook: url, Output: resp.Status, Err: fmt.Errorf("unexpected status code: %d", resp.StatusCode)}
+	}
+	return Result{Hook: url, Output: resp.Status}
+}
+
+// timeout returns the configured per-hook timeout.
+func (r *Runner) timeout() time.Duration {
+	if r.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return r.Timeout
+}
@@ -0,0 +1,160 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestIsURL(t *testing.T) {
+	tests := []struct {
+		hook string
+		want bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://localhost:8080", true},
+		{"./deploy.sh", false},
+		{"curl https://example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsURL(tt.hook); got != tt.want {
+			t.Errorf("IsURL(%q) = %v, want %v", tt.hook, got, tt.want)
+		}
+	}
+}
+
+func TestRunner_Command(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell command test on Windows")
+	}
+
+	dir := t.TempDir()
+	r := NewRunner(dir)
+	r.Env = []string{"MAIN_WORKTREE=/main"}
+
+	results := r.Run(context.Background(), []string{
+		`echo "$RALPH_PLAN $RALPH_BRANCH $RALPH_PR_URL $MAIN_WORKTREE" > env.txt && cat > payload.json`,
+	}, Payload{
+		Event:  EventPlanComplete,
+		Plan:   "my-plan",
+		Branch: "feat/my-plan",
+		PRURL:  "https://github.com/o/r/pull/1",
+		Result: "complete",
+	})
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Err != nil {
+		t.Fatalf("hook failed: %v", results[0].Err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "my-plan feat/my-plan https://github.com/o/r/pull/1 /main"
+	if strings.TrimSpace(string(env)) != want {
+		t.Errorf("env = %q, want %q", strings.TrimSpace(string(env)), want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "payload.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin payload is not JSON: %v", err)
+	}
+	if got.Event != EventPlanComplete || got.Plan != "my-plan" {
+		t.Errorf("payload = %+v", got)
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("payload timestamp should be set")
+	}
+}
+
+func TestRunner_CommandFailureContinues(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell command test on Windows")
+	}
+
+	dir := t.TempDir()
+	r := NewRunner(dir)
+
+	results := r.Run(context.Background(), []string{"exit 3", "touch ran.txt"}, Payload{Event: EventPlanError})
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Err == nil {
+		t.Error("first hook should fail")
+	}
+	if results[1].Err != nil {
+		t.Errorf("second hook should succeed: %v", results[1].Err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran.txt")); err != nil {
+		t.Error("second hook should run after the first failed")
+	}
+}
+
+func TestRunner_URL(t *testing.T) {
+	var received Payload
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	r := NewRunner(t.TempDir())
+	results := r.Run(context.Background(), []string{server.URL}, Payload{
+		Event:      EventPlanError,
+		Plan:       "broken",
+		Result:     "error",
+		Error:      "max iterations reached",
+		Iterations: 30,
+	})
+
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	if received.Plan != "broken" || received.Error != "max iterations reached" || received.Iterations != 30 {
+		t.Errorf("received = %+v", received)
+	}
+}
+
+func TestRunner_URLErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	r := NewRunner(t.TempDir())
+	results := r.Run(context.Background(), []string{server.URL}, Payload{Event: EventPlanComplete})
+
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("expected error for 500 response, got %+v", results)
+	}
+}
+
+func TestRunner_SkipsBlankHooks(t *testing.T) {
+	r := NewRunner(t.TempDir())
+	results := r.Run(context.Background(), []string{"", "  "}, Payload{Event: EventPlanComplete})
+	if len(results) != 0 {
+		t.Errorf("got %d results, want 0", len(results))
+	}
+}
@@ -0,0 +1,71 @@
+package i18n
+
+// de is the German catalog.
+var de = map[string]string{
+	// Errors
+	"Error:": "Fehler:",
+
+	// ralph status
+	"No plans directory found. Run 'ralph init' to initialize.": "Kein plans-Verzeichnis gefunden. Zum Einrichten 'ralph init' ausführen.",
+	"Queue Status":                          "Warteschlange",
+	"Current:":                              "Aktuell:",
+	"%s (branch: %s)":                       "%s (Branch: %s)",
+	"(none)":                                "(keiner)",
+	"Pending:":                              "Ausstehend:",
+	"snoozed until %s":                      "zurückgestellt bis %s",
+	"%d plan(s)":                            "%d Plan/Pläne",
+	"Complete:":                             "Abgeschlossen:",
+	"Abandoned:":                            "Aufgegeben:",
+	"Failed:":                               "Fehlgeschlagen:",
+	"Worktrees":                             "Worktrees",
+	"(worktree status not yet implemented)": "(Worktree-Status noch nicht implementiert)",
+
+	// Notifications
+	":rocket: *Plan Started*\n`%s`":                        ":rocket: *Plan gestartet*\n`%s`",
+	"*Branch:*\n`%s`":                                      "*Branch:*\n`%s`",
+	"*Branch:*\n<%s|%s>":                                   "*Branch:*\n<%s|%s>",
+	":white_check_mark: *Plan Complete*\n`%s`":             ":white_check_mark: *Plan abgeschlossen*\n`%s`",
+	":warning: *Human Input Required*\n`%s`":               ":warning: *Eingabe erforderlich*\n`%s`",
+	"*Description:*\n%s":                                   "*Beschreibung:*\n%s",
+	"*Action Required:*\n%s":                               "*Erforderliche Aktion:*\n%s",
+	"*On Resume:*\n%s":                                     "*Beim Fortsetzen:*\n%s",
+	":x: *Plan Error*\n`%s`":                               ":x: *Fehler im Plan*\n`%s`",
+	"*Error:*\n```%s```":                                   "*Fehler:*\n```%s```",
+	":rotating_light: *Urgent Feedback Unprocessed*\n`%s`": ":rotating_light: *Dringendes Feedback unbearbeitet*\n`%s`",
+	":question: *Question from Ralph*\n`%s`":               ":question: *Frage von Ralph*\n`%s`",
+	"Answer with `ralph feedback %s \"...\"`.":             "Antworte mit `ralph feedback %s \"...\"`.",
+	"Reply in this thread to answer.":                      "Antworte in diesem Thread.",
+	"The plan waits up to %s for the answer.":              "Der Plan wartet bis zu %s auf die Antwort.",
+	":hourglass_flowing_sand: *Iteration %d/%d*\n`%s`":     ":hourglass_flowing_sand: *Iteration %d/%d*\n`%s`",
+	":arrow_right: *Stage Complete*\n`%s`: %s → %s":        ":arrow_right: *Phase abgeschlossen*\n`%s`: %s → %s",
+	"*Pull Request:*\n<%s|View PR>":                        "*Pull Request:*\n<%s|PR ansehen>",
+	"*Changes:*\n%s\n+%d −%d lines":                        "*Änderungen:*\n%s\n+%d −%d Zeilen",
+	"*Iterations:*\n":                                      "*Iterationen:*\n",
+	"*Duration:*\n":                                        "*Dauer:*\n",
+	"*Gates:*\n":                                           "*Prüfungen:*\n",
+	":rotating_light: *Blocker Unresolved for %s*\n`%s` is still waiting for human input":                    ":rotating_light: *Blocker seit %s ungelöst*\n`%s` wartet weiterhin auf eine Eingabe",
+	":raised_hand: *Approval Required*\n`%s` is complete and waiting for approval before the PR/merge step.": ":raised_hand: *Freigabe erforderlich*\n`%s` ist fertig und wartet vor dem PR-/Merge-Schritt auf Freigabe.",
+	"\nRun `ralph approve %s` or `ralph reject %s --reason ...` within %s.":                                  "\nBitte innerhalb von %[3]s `ralph approve %[1]s` oder `ralph reject %[2]s --reason ...` ausführen.",
+	"\nRun `ralph approve %s` or `ralph reject %s --reason ...`.":                                            "\nBitte `ralph approve %s` oder `ralph reject %s --reason ...` ausführen.",
+	":mag: *Iteration %d Changes* `%.8s`\n%d file(s), +%d −%d lines":                                         ":mag: *Änderungen aus Iteration %d* `%.8s`\n%d Datei(en), +%d −%d Zeilen",
+	"\n_… %d more line(s)_": "\n_… %d weitere Zeile(n)_",
+	"<%s|View commit>":      "<%s|Commit ansehen>",
+	":warning: *Iteration %d Used Too Many Resources*\n`%s`": ":warning: *Iteration %d hat zu viele Ressourcen verbraucht*\n`%s`",
+	"_Usage: %s_": "_Verbrauch: %s_",
+	"Approve":     "Freigeben",
+	"Reject":      "Ablehnen",
+	"passed":      "bestanden",
+	"failed":      "fehlgeschlagen",
+	"warning":     "Warnung",
+
+	// Digests and rate limits
+	":newspaper: *Ralph Digest* (%s – %s)":               ":newspaper: *Ralph-Zusammenfassung* (%s – %s)",
+	"\n\n*Started:*":                                     "\n\n*Gestartet:*",
+	"\n\n*Progressed:*":                                  "\n\n*Fortgeschritten:*",
+	"\n• `%s` – %d iteration(s), now at %d/%d":           "\n• `%s` – %d Iteration(en), jetzt bei %d/%d",
+	"\n\n*Completed:*":                                   "\n\n*Abgeschlossen:*",
+	" <%s|View PR>":                                      " <%s|PR ansehen>",
+	"\n\n*Blocked:*":                                     "\n\n*Blockiert:*",
+	"\n\n*Errors:*":                                      "\n\n*Fehler:*",
+	":mute: *Notifications rate limited* in the last %s": ":mute: *Benachrichtigungen gedrosselt* in den letzten %s",
+}
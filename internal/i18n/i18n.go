@@ -0,0 +1,128 @@
+// Package i18n translates the user-facing strings of Slack notifications and
+// key CLI output. Messages are looked up by their English text, so a string
+// without a translation, or any string in the default locale, is shown as
+// written. The locale is chosen once at startup (see Resolve and SetLocale).
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EnvVar selects the locale, taking precedence over project.locale.
+const EnvVar = "RALPH_LOCALE"
+
+// DefaultLocale is the locale messages are written in.
+const DefaultLocale = "en"
+
+// catalogs maps each shipped locale other than DefaultLocale to its
+// translations, keyed by the English message.
+var catalogs = map[string]map[string]string{
+	"de": de,
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLocale
+)
+
+// Locales returns the shipped locales, DefaultLocale first.
+func Locales() []string {
+	locales := []string{DefaultLocale}
+	var others []string
+	for locale := range catalogs {
+		others = append(others, locale)
+	}
+	sort.Strings(others)
+	return append(locales, others...)
+}
+
+// Supported reports whether locale (in any form Normalize accepts) is shipped.
+func Supported(locale string) bool {
+	locale = Normalize(locale)
+	if locale == DefaultLocale {
+		return true
+	}
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Normalize reduces a locale such as "de_DE.UTF-8" or "de-AT" to its
+// language ("de"). "C" and "POSIX" are DefaultLocale.
+func Normalize(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ToLower(locale)
+	if locale == "c" || locale == "posix" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// Resolve picks the locale to use: RALPH_LOCALE, then configured
+// (project.locale), then the first of LC_ALL, LC_MESSAGES, and LANG that is
+// shipped. Falls back to DefaultLocale.
+func Resolve(configured string) string {
+	if locale := os.Getenv(EnvVar); locale != "" && Supported(locale) {
+		return Normalize(locale)
+	}
+	if configured != "" && Supported(configured) {
+		return Normalize(configured)
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			if Supported(locale) {
+				return Normalize(locale)
+			}
+			// The first one set decides, as with the C library
+			break
+		}
+	}
+	return DefaultLocale
+}
+
+// SetLocale sets the locale messages are translated to. An unsupported
+// locale selects DefaultLocale.
+func SetLocale(locale string) {
+	locale = Normalize(locale)
+	if !Supported(locale) {
+		locale = DefaultLocale
+	}
+	mu.Lock()
+	current = locale
+	mu.Unlock()
+}
+
+// Locale returns the current locale.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T translates msg to the current locale, returning it unchanged if there
+// is no translation.
+func T(msg string) string {
+	mu.RLock()
+	catalog := catalogs[current]
+	mu.RUnlock()
+	if translated, ok := catalog[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf translates format to the current locale and formats it with args.
+// Translations keep the verbs of the English format, reordering them with
+// explicit argument indexes ("%[2]s") where the language needs to.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
@@ -0,0 +1,192 @@
+package i18n
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"":            "",
+		"de":          "de",
+		"de_DE.UTF-8": "de",
+		"de-AT":       "de",
+		"EN_us":       "en",
+		"C":           "en",
+		"POSIX":       "en",
+		"sr@latin":    "sr",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for _, locale := range []string{"en", "de", "de_CH.UTF-8", "C"} {
+		if !Supported(locale) {
+			t.Errorf("Supported(%q) = false, want true", locale)
+		}
+	}
+	if Supported("xx") {
+		t.Error(`Supported("xx") = true, want false`)
+	}
+	if locales := Locales(); len(locales) < 2 || locales[0] != DefaultLocale {
+		t.Errorf("Locales() = %v, want %s first and at least one more", locales, DefaultLocale)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		configured string
+		want       string
+	}{
+		{"default", nil, "", "en"},
+		{"configured", nil, "de", "de"},
+		{"env overrides config", map[string]string{EnvVar: "en"}, "de", "en"},
+		{"unsupported env ignored", map[string]string{EnvVar: "xx"}, "de", "de"},
+		{"LANG", map[string]string{"LANG": "de_DE.UTF-8"}, "", "de"},
+		{"LC_ALL before LANG", map[string]string{"LC_ALL": "C", "LANG": "de_DE.UTF-8"}, "", "en"},
+		{"unsupported LANG", map[string]string{"LANG": "fr_FR.UTF-8"}, "", "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{EnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(env, tt.env[env])
+			}
+			if got := Resolve(tt.configured); got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+
+	if got := T("Queue Status"); got != "Queue Status" {
+		t.Errorf("T() in en = %q, want the message unchanged", got)
+	}
+
+	SetLocale("de_DE.UTF-8")
+	if Locale() != "de" {
+		t.Fatalf("Locale() = %q, want de", Locale())
+	}
+	if got := T("Queue Status"); got != "Warteschlange" {
+		t.Errorf("T() in de = %q, want %q", got, "Warteschlange")
+	}
+	if got := Sprintf("%d plan(s)", 3); got != "3 Plan/Pläne" {
+		t.Errorf("Sprintf() in de = %q", got)
+	}
+	if got := T("not in the catalog"); got != "not in the catalog" {
+		t.Errorf("T() of an untranslated message = %q, want it unchanged", got)
+	}
+
+	SetLocale("xx")
+	if Locale() != DefaultLocale {
+		t.Errorf("SetLocale(unsupported) selected %q, want %s", Locale(), DefaultLocale)
+	}
+}
+
+// verbRegex matches a format verb with an optional argument index, flags,
+// width, and precision.
+var verbRegex = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// sampleArgs returns arguments for the verbs of an English format.
+func sampleArgs(format string) []any {
+	var args []any
+	for _, m := range verbRegex.FindAllStringSubmatch(format, -1) {
+		switch m[2] {
+		case "%":
+			continue
+		case "d":
+			args = append(args, len(args)+1)
+		default:
+			args = append(args, "arg"+strconv.Itoa(len(args)+1))
+		}
+	}
+	return args
+}
+
+func TestCatalogs_Verbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for msg, translated := range catalog {
+			args := sampleArgs(msg)
+			got := fmt.Sprintf(translated, args...)
+			if strings.Contains(got, "%!") {
+				t.Errorf("%s: %q formats badly: %q", locale, translated, got)
+			}
+			// Every argument must appear in the translation
+			for _, arg := range args {
+				if s, ok := arg.(string); ok && !strings.Contains(got, s) {
+					t.Errorf("%s: %q drops argument %s of %q", locale, translated, s, msg)
+				}
+			}
+		}
+	}
+}
+
+// TestCatalogs_Complete checks every literal message passed to T and
+// Sprintf in the ralph packages has a translation in every catalog.
+func TestCatalogs_Complete(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "*", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	messages := make(map[string]string)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("parsing %s: %v", file, err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "T" && sel.Sel.Name != "Sprintf") {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Fatalf("%s: %v", fset.Position(lit.Pos()), err)
+			}
+			messages[msg] = fset.Position(lit.Pos()).String()
+			return true
+		})
+	}
+	if len(messages) == 0 {
+		t.Fatal("found no i18n.T or i18n.Sprintf calls")
+	}
+
+	for locale, catalog := range catalogs {
+		for msg, pos := range messages {
+			if _, ok := catalog[msg]; !ok {
+				t.Errorf("%s: no translation for %q (%s)", locale, msg, pos)
+			}
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package ingest provides the HTTP endpoint `ralph serve --ingest` uses to
+// accept new plans from external systems such as forms, ticketing tools,
+// and chat ops.
+package ingest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// MaxRequestSize caps the body of a POST /plans request.
+const MaxRequestSize = 1 << 20
+
+// maxTitleLen caps a title derived from the first line of a request body.
+const maxTitleLen = 60
+
+// Request is a JSON plan submission. Either Markdown, a complete plan file,
+// or Title and Body, scaffolded into a plan like a Slack request, is required.
+type Request struct {
+	// Title is the plan title. Derived from the first line of Body, or the
+	// heading of Markdown, if empty.
+	Title string `json:"title"`
+
+	// Body is the request text placed in the plan's Context section.
+	Body string `json:"body"`
+
+	// Source records where the request came from, e.g. a ticket URL.
+	Source string `json:"source"`
+
+	// Tasks are optional initial task titles.
+	Tasks []string `json:"tasks"`
+
+	// Labels are optional plan labels.
+	Labels []string `json:"labels"`
+
+	// Markdown is a complete plan file, written as-is. Body, Tasks, and
+	// Labels are ignored if it is set.
+	Markdown string `json:"markdown"`
+}
+
+// Response is the JSON reply to a POST /plans request.
+type Response struct {
+	// Plan is the queued plan's name.
+	Plan string `json:"plan,omitempty"`
+
+	// Branch is the branch the plan will run on.
+	Branch string `json:"branch,omitempty"`
+
+	// Position is the plan's place in the pending queue, starting at 1.
+	Position int `json:"position,omitempty"`
+
+	// Error describes why the request was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler serves POST /plans, which queues a new pending plan with its
+// feedback and progress files, and GET /healthz. Requests to /plans must
+// carry the token as "Authorization: Bearer <token>".
+type Handler struct {
+	queue *plan.Queue
+	token string
+
+	// Events records a plan_queued event for each queued plan (optional).
+	Events *events.Log
+
+	// OnQueued is called after a plan is queued (optional).
+	OnQueued func(p *plan.Plan, source string)
+
+	// mu serializes plan creation so concurrent requests with the same
+	// title get distinct file names.
+	mu sync.Mutex
+}
+
+// NewHandler creates a Handler that queues plans in q. An empty token
+// rejects every request.
+func NewHandler(q *plan.Queue, token string) *Handler {
+	return &Handler{queue: q, token: token}
+}
+
+// ServeHTTP routes a request to the plans or health endpoint.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/plans":
+		h.servePlans(w, r)
+	case "/healthz":
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok\n")
+	default:
+		writeJSON(w, http.StatusNotFound, Response{Error: "not found"})
+	}
+}
+
+// servePlans handles POST /plans.
+func (h *Handler) servePlans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "use POST"})
+		return
+	}
+	if !h.authorized(r) {
+		log.Warn("Rejected plan submission from %s: bad or missing token", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ralph"`)
+		writeJSON(w, http.StatusUnauthorized, Response{Error: "unauthorized"})
+		return
+	}
+
+	req, err := decodeRequest(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			status = http.StatusRequestEntityTooLarge
+		case errors.Is(err, errUnsupportedType):
+			status = http.StatusUnsupportedMediaType
+		}
+		writeJSON(w, status, Response{Error: err.Error()})
+		return
+	}
+
+	p, err := h.queuePlan(req)
+	if err != nil {
+		if errors.Is(err, errInvalidRequest) {
+			writeJSON(w, http.StatusBadRequest, Response{Error: err.Error()})
+			return
+		}
+		log.Error("Failed to queue submitted plan: %v", err)
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "failed to queue plan"})
+		return
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "ingest endpoint"
+	}
+	log.Info("Queued plan %s from %s", p.Name, source)
+	if h.Events != nil {
+		if err := h.Events.Append(events.Event{Type: events.TypePlanQueued, Plan: p.Name, Labels: p.Labels, Message: source}); err != nil {
+			log.Debug("Failed to record %s event: %v", events.TypePlanQueued, err)
+		}
+	}
+	if h.OnQueued != nil {
+		h.OnQueued(p, source)
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Plan: p.Name, Branch: p.Branch, Position: h.position(p)})
+}
+
+// authorized reports whether r carries the bearer token.
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(auth[len(prefix):])), []byte(h.token)) == 1
+}
+
+var (
+	// errUnsupportedType is returned for a body that is neither JSON nor markdown.
+	errUnsupportedType = errors.New("unsupported content type: use application/json or text/markdown")
+
+	// errInvalidRequest is returned for a submission that can't become a plan.
+	errInvalidRequest = errors.New("invalid plan request")
+)
+
+// decodeRequest reads a JSON or markdown submission. A markdown body
+// (text/markdown or text/plain) is a complete plan if it has a top-level
+// heading, otherwise the request text; the title, source, and label query
+// parameters fill in the rest.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (*Request, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxRequestSize))
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, err = mime.ParseMediaType(ct); err != nil {
+			return nil, errUnsupportedType
+		}
+	}
+
+	switch mediaType {
+	case "application/json":
+		var req Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return &req, nil
+
+	case "text/markdown", "text/x-markdown", "text/plain":
+		query := r.URL.Query()
+		req := &Request{Title: query.Get("title"), Source: query.Get("source")}
+		for _, l := range query["label"] {
+			req.Labels = append(req.Labels, strings.Split(l, ",")...)
+		}
+		if plan.MarkdownTitle(string(body)) != "" {
+			req.Markdown = string(body)
+		} else {
+			req.Body = string(body)
+		}
+		return req, nil
+	}
+	return nil, errUnsupportedType
+}
+
+// queuePlan writes the submission to the pending queue with its feedback
+// and progress files.
+func (h *Handler) queuePlan(req *Request) (*plan.Plan, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var p *plan.Plan
+	var err error
+	if strings.TrimSpace(req.Markdown) != "" {
+		title := strings.TrimSpace(req.Title)
+		if title == "" {
+			title = plan.MarkdownTitle(req.Markdown)
+		}
+		if title == "" {
+			return nil, fmt.Errorf("%w: markdown plan needs a \"# Plan: <title>\" heading or a title", errInvalidRequest)
+		}
+		p, err = plan.Create(h.queue.PendingDir(), title, req.Markdown)
+	} else {
+		title := strings.TrimSpace(req.Title)
+		if title == "" {
+			title = titleFromText(req.Body)
+		}
+		if title == "" {
+			return nil, fmt.Errorf("%w: title or body is required", errInvalidRequest)
+		}
+		var labels []string
+		for _, l := range req.Labels {
+			if l = strings.TrimSpace(l); l != "" {
+				labels = append(labels, l)
+			}
+		}
+		p, err = plan.Scaffold(h.queue.PendingDir(), plan.ScaffoldOptions{
+			Title:  title,
+			Body:   req.Body,
+			Source: req.Source,
+			Tasks:  req.Tasks,
+			Labels: labels,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := plan.CreateFeedbackFile(p); err != nil {
+		return nil, fmt.Errorf("creating feedback file: %w", err)
+	}
+	if err := plan.CreateProgressFile(p); err != nil {
+		return nil, fmt.Errorf("creating progress file: %w", err)
+	}
+	return p, nil
+}
+
+// position returns p's place in the pending queue, or 0 if unknown.
+func (h *Handler) position(p *plan.Plan) int {
+	pending, err := h.queue.Pending()
+	if err != nil {
+		return 0
+	}
+	for i, pp := range pending {
+		if pp.Name == p.Name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// titleFromText derives a short plan title from the first line of text.
+func titleFromText(text string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if len(title) <= maxTitleLen {
+		return title
+	}
+
+	cut := title[:maxTitleLen]
+	if i := strings.LastIndex(cut, " "); i > maxTitleLen/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut)
+}
+
+// writeJSON writes resp with the given status code.
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// newTestHandler returns a handler with token "s3cret" over a queue in a temp dir.
+func newTestHandler(t *testing.T) (*Handler, *plan.Queue) {
+	t.Helper()
+	dir := t.TempDir()
+	q := plan.NewQueue(filepath.Join(dir, "plans"))
+	h := NewHandler(q, "s3cret")
+	h.Events = events.NewLog(filepath.Join(dir, "events.jsonl"))
+	return h, q
+}
+
+// post sends body to /plans with the given content type and token.
+func post(h http.Handler, target, contentType, token, body string) (*httptest.ResponseRecorder, Response) {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var resp Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec, resp
+}
+
+func TestHandler_JSON(t *testing.T) {
+	h, q := newTestHandler(t)
+	var queued string
+	h.OnQueued = func(p *plan.Plan, source string) { queued = p.Name + " " + source }
+
+	body := `{"title": "Add audit log", "body": "Record who changed what.", "source": "JIRA-12", "tasks": ["Design schema", "Write entries"], "labels": ["backend"]}`
+	rec, resp := post(h, "/plans", "application/json", "s3cret", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if resp.Plan != "add-audit-log" || resp.Branch != "feat/add-audit-log" || resp.Position != 1 {
+		t.Errorf("response = %+v", resp)
+	}
+	if queued != "add-audit-log JIRA-12" {
+		t.Errorf("OnQueued got %q", queued)
+	}
+
+	p, err := q.Find("add-audit-log")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	for _, want := range []string{"Record who changed what.", "_Source: JIRA-12_", "### T2: Write entries"} {
+		if !strings.Contains(p.Content, want) {
+			t.Errorf("plan missing %q:\n%s", want, p.Content)
+		}
+	}
+	if len(p.Labels) != 1 || p.Labels[0] != "backend" {
+		t.Errorf("Labels = %v, want [backend]", p.Labels)
+	}
+	for _, path := range []string{plan.FeedbackPath(p), plan.ProgressPath(p)} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("missing bundle file %s: %v", path, err)
+		}
+	}
+
+	evs, err := h.Events.Since(time.Time{})
+	if err != nil || len(evs) != 1 || evs[0].Type != events.TypePlanQueued || evs[0].Message != "JIRA-12" {
+		t.Errorf("events = %+v, %v; want one plan_queued from JIRA-12", evs, err)
+	}
+}
+
+func TestHandler_Markdown(t *testing.T) {
+	h, q := newTestHandler(t)
+
+	content := "# Plan: Rotate keys\n\n## Tasks\n- [ ] Rotate the signing key\n"
+	rec, resp := post(h, "/plans", "text/markdown; charset=utf-8", "s3cret", content)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	p, err := q.Find(resp.Plan)
+	if err != nil {
+		t.Fatalf("Find(%q) error = %v", resp.Plan, err)
+	}
+	if p.Content != content {
+		t.Errorf("Content = %q, want the submitted plan", p.Content)
+	}
+
+	// Markdown without a heading is the request text
+	rec, resp = post(h, "/plans?title=Fix+flaky+test&label=ci", "text/plain", "s3cret", "TestUpload fails one run in ten.")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	p, err = q.Find(resp.Plan)
+	if err != nil {
+		t.Fatalf("Find(%q) error = %v", resp.Plan, err)
+	}
+	if resp.Plan != "fix-flaky-test" || !strings.Contains(p.Content, "TestUpload fails one run in ten.") {
+		t.Errorf("plan %s content:\n%s", resp.Plan, p.Content)
+	}
+	if len(p.Labels) != 1 || p.Labels[0] != "ci" {
+		t.Errorf("Labels = %v, want [ci]", p.Labels)
+	}
+}
+
+func TestHandler_Rejects(t *testing.T) {
+	h, q := newTestHandler(t)
+
+	tests := []struct {
+		name        string
+		contentType string
+		token       string
+		body        string
+		want        int
+	}{
+		{"no token", "application/json", "", `{"title": "x"}`, http.StatusUnauthorized},
+		{"wrong token", "application/json", "guess", `{"title": "x"}`, http.StatusUnauthorized},
+		{"bad json", "application/json", "s3cret", `{"title":`, http.StatusBadRequest},
+		{"empty request", "application/json", "s3cret", `{}`, http.StatusBadRequest},
+		{"unsupported type", "image/png", "s3cret", "x", http.StatusUnsupportedMediaType},
+		{"too large", "text/plain", "s3cret", strings.Repeat("x", MaxRequestSize+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, resp := post(h, "/plans", tt.contentType, tt.token, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+			if resp.Error == "" {
+				t.Error("expected an error message")
+			}
+		})
+	}
+
+	pending, _ := q.Pending()
+	if len(pending) != 0 {
+		t.Errorf("rejected requests queued %d plans", len(pending))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/plans", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /plans status = %d, want 405", rec.Code)
+	}
+}
+
+func TestHandler_EmptyTokenRejectsAll(t *testing.T) {
+	q := plan.NewQueue(t.TempDir())
+	rec, _ := post(NewHandler(q, ""), "/plans", "application/json", "", `{"title": "x"}`)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestTitleFromText(t *testing.T) {
+	long := strings.Repeat("word ", 20)
+	if got := titleFromText(long); len(got) > maxTitleLen || strings.HasSuffix(got, " ") {
+		t.Errorf("titleFromText(long) = %q", got)
+	}
+	if got := titleFromText("\n  Fix login\nmore detail"); got != "Fix login" {
+		t.Errorf("titleFromText() = %q, want Fix login", got)
+	}
+}
@@ -239,20 +239,13 @@ func setupWorkspace(t *testing.T) string {
 	cmd.Run()
 
 	// Create ralph directory structure
-	dirs := []string{
-		".ralph",
-		".ralph/worktrees",
-		"plans/pending",
-		"plans/current",
-		"plans/complete",
-	}
-	for _, dir := range dirs {
-		if err := os.MkdirAll(filepath.Join(workspace, dir), 0755); err != nil {
-			t.Fatalf("Failed to create directory %s: %v", dir, err)
-		}
+	cmd = exec.Command(getRalphBinary(t), "init")
+	cmd.Dir = workspace
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("ralph init failed: %v\n%s", err, output)
 	}
 
-	// Create minimal config
+	// Override the generated config with test commands
 	configContent := `project:
   name: "Test Project"
   description: "Integration test workspace"
@@ -267,12 +260,6 @@ commands:
 		t.Fatalf("Failed to create config: %v", err)
 	}
 
-	// Create .gitignore for worktrees
-	gitignore := filepath.Join(workspace, ".ralph/worktrees/.gitignore")
-	if err := os.WriteFile(gitignore, []byte("*\n"), 0644); err != nil {
-		t.Fatalf("Failed to create .gitignore: %v", err)
-	}
-
 	// Commit the setup
 	cmd = exec.Command("git", "add", ".")
 	cmd.Dir = workspace
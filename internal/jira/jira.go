@@ -0,0 +1,314 @@
+// Package jira is a small Jira REST client for importing issues as plans
+// and keeping their status in sync as the worker processes them. It
+// registers the "jira" tracker.
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// TokenEnv overrides jira.api_token.
+const TokenEnv = "JIRA_API_TOKEN"
+
+// requestTimeout bounds each Jira API request.
+const requestTimeout = 30 * time.Second
+
+// keyRegex matches a Jira issue key such as "PROJ-123".
+var keyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-\d+$`)
+
+// ErrNotConfigured is returned when jira.url is not set.
+var ErrNotConfigured = fmt.Errorf("jira is %w (set jira.url)", tracker.ErrNotConfigured)
+
+func init() {
+	tracker.Register("jira", func(cfg *config.Config) (tracker.Tracker, error) {
+		return NewTracker(cfg.Jira)
+	})
+}
+
+// Issue is the part of a Jira issue ralph uses.
+type Issue struct {
+	// Key is the issue key, e.g. "PROJ-123".
+	Key string
+
+	// Summary is the issue title.
+	Summary string
+
+	// Description is the issue body in Jira wiki markup.
+	Description string
+
+	// Labels are the issue's labels.
+	Labels []string
+
+	// Status is the issue's current status name.
+	Status string
+
+	// URL is the issue's browse link.
+	URL string
+}
+
+// Client talks to the Jira REST API (v2, which takes and returns plain text
+// rather than Atlassian Document Format).
+type Client struct {
+	baseURL string
+	email   string
+	token   string
+	http    *http.Client
+}
+
+// New creates a client from cfg. The API token comes from $JIRA_API_TOKEN
+// or jira.api_token. With jira.email set, requests use basic auth (Jira
+// Cloud); without it the token is sent as a bearer token (a personal access
+// token on Jira Server and Data Center). Returns ErrNotConfigured if
+// jira.url is empty.
+func New(cfg config.JiraConfig) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, ErrNotConfigured
+	}
+	token := os.Getenv(TokenEnv)
+	if token == "" {
+		token = cfg.APIToken
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no Jira API token: set jira.api_token or $%s", TokenEnv)
+	}
+	return &Client{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		email:   cfg.Email,
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// ValidKey reports whether key looks like a Jira issue key.
+func ValidKey(key string) bool {
+	return keyRegex.MatchString(key)
+}
+
+// BrowseURL returns the web link to an issue.
+func (c *Client) BrowseURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
+
+// Issue fetches an issue by key.
+func (c *Client) Issue(key string) (*Issue, error) {
+	var resp struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string   `json:"summary"`
+			Description string   `json:"description"`
+			Labels      []string `json:"labels"`
+			Status      struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,description,labels,status"
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", key, err)
+	}
+	return &Issue{
+		Key:         resp.Key,
+		Summary:     resp.Fields.Summary,
+		Description: resp.Fields.Description,
+		Labels:      resp.Fields.Labels,
+		Status:      resp.Fields.Status.Name,
+		URL:         c.BrowseURL(resp.Key),
+	}, nil
+}
+
+// Transition moves an issue to the status named status, using the
+// transition with that name or the one leading to that status. Returns
+// tracker.ErrNoTransition if the issue's workflow has none.
+func (c *Client) Transition(key, status string) error {
+	var resp struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := c.do(http.MethodGet, path, nil, &resp); err != nil {
+		return fmt.Errorf("listing transitions for %s: %w", key, err)
+	}
+
+	for _, t := range resp.Transitions {
+		if strings.EqualFold(t.Name, status) || strings.EqualFold(t.To.Name, status) {
+			body := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			if err := c.do(http.MethodPost, path, body, nil); err != nil {
+				return fmt.Errorf("moving %s to %s: %w", key, status, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w to %q for %s", tracker.ErrNoTransition, status, key)
+}
+
+// Comment adds a comment to an issue.
+func (c *Client) Comment(key, text string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/comment"
+	if err := c.do(http.MethodPost, path, map[string]string{"body": text}, nil); err != nil {
+		return fmt.Errorf("commenting on %s: %w", key, err)
+	}
+	return nil
+}
+
+// RemoteLink adds a link to target, shown under the issue's links, with the
+// given title.
+func (c *Client) RemoteLink(key, target, title string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/remotelink"
+	body := map[string]interface{}{"object": map[string]string{"url": target, "title": title}}
+	if err := c.do(http.MethodPost, path, body, nil); err != nil {
+		return fmt.Errorf("linking %s: %w", key, err)
+	}
+	return nil
+}
+
+// do sends a request with a JSON body (if any) and decodes a JSON reply into out (if non-nil).
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Tracker adapts a Client to tracker.Tracker for plans with a **Jira:**
+// header, moving issues to the statuses in jira.in_progress, jira.in_review,
+// and jira.done.
+type Tracker struct {
+	client *Client
+	cfg    config.JiraConfig
+}
+
+// NewTracker creates a Jira tracker from cfg (see New).
+func NewTracker(cfg config.JiraConfig) (*Tracker, error) {
+	client, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracker{client: client, cfg: cfg}, nil
+}
+
+// Name returns "jira".
+func (t *Tracker) Name() string {
+	return "jira"
+}
+
+// ParseKey uppercases key and checks it looks like a Jira issue key.
+func (t *Tracker) ParseKey(s string) (string, error) {
+	key := strings.ToUpper(strings.TrimSpace(s))
+	if !ValidKey(key) {
+		return "", fmt.Errorf("invalid Jira issue key %q (expected e.g. PROJ-123)", s)
+	}
+	return key, nil
+}
+
+// IssueFor returns the plan's **Jira:** issue key.
+func (t *Tracker) IssueFor(p *plan.Plan) string {
+	return p.Jira
+}
+
+// FetchIssue fetches the issue.
+func (t *Tracker) FetchIssue(key string) (*tracker.Issue, error) {
+	issue, err := t.client.Issue(key)
+	if err != nil {
+		return nil, err
+	}
+	return &tracker.Issue{
+		Key:    issue.Key,
+		Title:  issue.Summary,
+		Body:   issue.Description,
+		Labels: issue.Labels,
+		Status: issue.Status,
+		URL:    issue.URL,
+	}, nil
+}
+
+// PlanOptions builds a plan for the issue with a **Jira:** header. The
+// branch name carries the key, so Jira's development panel links it.
+func (t *Tracker) PlanOptions(issue *tracker.Issue) plan.ScaffoldOptions {
+	opts := tracker.ScaffoldOptions(issue, "Jira")
+	opts.Jira = issue.Key
+	return opts
+}
+
+// Comment posts text on the issue.
+func (t *Tracker) Comment(key, text string) error {
+	return t.client.Comment(key, text)
+}
+
+// Transition moves the issue to the status configured for stage. A stage
+// without a status is a no-op.
+func (t *Tracker) Transition(key, stage string) error {
+	status := StatusFor(t.cfg, stage)
+	if status == "" {
+		return nil
+	}
+	return t.client.Transition(key, status)
+}
+
+// LinkPR adds the pull request as a remote link on the issue.
+func (t *Tracker) LinkPR(key, prURL string) error {
+	return t.client.RemoteLink(key, prURL, "Pull request "+prURL)
+}
+
+// StatusFor returns the configured Jira status for a stage.
+func StatusFor(cfg config.JiraConfig, stage string) string {
+	switch stage {
+	case tracker.StageInProgress:
+		return cfg.InProgress
+	case tracker.StageInReview:
+		return cfg.InReview
+	case tracker.StageDone:
+		return cfg.Done
+	}
+	return ""
+}
@@ -0,0 +1,210 @@
+package jira
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// fakeJira serves an issue PROJ-1 with the transitions "Start work" (to In
+// Progress) and "Done", recording each write request as "METHOD path body".
+type fakeJira struct {
+	mu       sync.Mutex
+	requests []string
+	auth     string
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/PROJ-1":
+		io.WriteString(w, `{"key": "PROJ-1", "fields": {"summary": "Fix login", "description": "Login fails on Safari.", "labels": ["web"], "status": {"name": "To Do"}}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/PROJ-1/transitions":
+		io.WriteString(w, `{"transitions": [{"id": "11", "name": "Start work", "to": {"name": "In Progress"}}, {"id": "31", "name": "Done", "to": {"name": "Done"}}]}`)
+	case r.Method == http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		f.requests = append(f.requests, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, `{"errorMessages": ["Issue does not exist"]}`, http.StatusNotFound)
+	}
+}
+
+func (f *fakeJira) writes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func newTestClient(t *testing.T, email string) (*Client, *fakeJira) {
+	t.Helper()
+	fake := &fakeJira{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	t.Setenv(TokenEnv, "")
+
+	client, err := New(config.JiraConfig{URL: server.URL + "/", Email: email, APIToken: "tok"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client, fake
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+	if _, err := New(config.JiraConfig{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("New() without url error = %v, want ErrNotConfigured", err)
+	}
+	if _, err := New(config.JiraConfig{URL: "https://acme.atlassian.net"}); err == nil {
+		t.Error("New() without token should fail")
+	}
+
+	t.Setenv(TokenEnv, "from-env")
+	client, err := New(config.JiraConfig{URL: "https://acme.atlassian.net", APIToken: "from-config"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.token != "from-env" {
+		t.Errorf("token = %q, want $%s to take precedence", client.token, TokenEnv)
+	}
+}
+
+func TestClient_Issue(t *testing.T) {
+	client, fake := newTestClient(t, "bot@acme.com")
+
+	issue, err := client.Issue("PROJ-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if issue.Summary != "Fix login" || issue.Description != "Login fails on Safari." || issue.Status != "To Do" {
+		t.Errorf("Issue() = %+v", issue)
+	}
+	if !strings.HasSuffix(issue.URL, "/browse/PROJ-1") {
+		t.Errorf("URL = %q", issue.URL)
+	}
+	if !strings.HasPrefix(fake.auth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth with an email", fake.auth)
+	}
+
+	if _, err := client.Issue("PROJ-404"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Issue() of a missing issue error = %v", err)
+	}
+}
+
+func TestClient_TransitionAndComment(t *testing.T) {
+	client, fake := newTestClient(t, "")
+
+	// Matches the target status as well as the transition name
+	if err := client.Transition("PROJ-1", "in progress"); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	if err := client.Transition("PROJ-1", "In Review"); !errors.Is(err, tracker.ErrNoTransition) {
+		t.Errorf("Transition() to a missing status error = %v, want ErrNoTransition", err)
+	}
+	if err := client.Comment("PROJ-1", "PR: https://github.com/o/r/pull/1"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if fake.auth != "Bearer tok" {
+		t.Errorf("Authorization = %q, want a bearer token without an email", fake.auth)
+	}
+
+	writes := fake.writes()
+	if len(writes) != 2 {
+		t.Fatalf("writes = %q, want a transition and a comment", writes)
+	}
+	var transition struct {
+		Transition struct{ ID string } `json:"transition"`
+	}
+	json.Unmarshal([]byte(strings.SplitN(writes[0], " ", 3)[2]), &transition)
+	if !strings.HasPrefix(writes[0], "POST /rest/api/2/issue/PROJ-1/transitions") || transition.Transition.ID != "11" {
+		t.Errorf("transition request = %q", writes[0])
+	}
+	if !strings.Contains(writes[1], "/comment") || !strings.Contains(writes[1], "pull/1") {
+		t.Errorf("comment request = %q", writes[1])
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{"PROJ-123": true, "AB2-1": true, "proj-1": false, "PROJ": false, "PROJ-": false, "1-2": false} {
+		if got := ValidKey(key); got != want {
+			t.Errorf("ValidKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	cfg := config.Defaults().Jira
+	for stage, want := range map[string]string{tracker.StageInProgress: "In Progress", tracker.StageInReview: "In Review", tracker.StageDone: "Done", "other": ""} {
+		if got := StatusFor(cfg, stage); got != want {
+			t.Errorf("StatusFor(%q) = %q, want %q", stage, got, want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	client, fake := newTestClient(t, "")
+	var tr tracker.Tracker = &Tracker{client: client, cfg: config.JiraConfig{InProgress: "In Progress"}}
+
+	if got := tr.IssueFor(&plan.Plan{Jira: "PROJ-1"}); got != "PROJ-1" {
+		t.Errorf("IssueFor() = %q, want PROJ-1", got)
+	}
+	if key, err := tr.ParseKey(" proj-1 "); err != nil || key != "PROJ-1" {
+		t.Errorf("ParseKey() = %q, %v, want PROJ-1", key, err)
+	}
+	if _, err := tr.ParseKey("not a key"); err == nil {
+		t.Error("ParseKey() should reject an invalid key")
+	}
+
+	issue, err := tr.FetchIssue("PROJ-1")
+	if err != nil {
+		t.Fatalf("FetchIssue() error = %v", err)
+	}
+	if issue.Title != "Fix login" || issue.Body != "Login fails on Safari." || !issue.HasLabel("WEB") {
+		t.Errorf("FetchIssue() = %+v", issue)
+	}
+	opts := tr.PlanOptions(issue)
+	if opts.Title != "PROJ-1 Fix login" || opts.Jira != "PROJ-1" || !strings.Contains(opts.Source, "browse/PROJ-1") {
+		t.Errorf("PlanOptions() = %+v", opts)
+	}
+
+	if err := tr.Transition("PROJ-1", tracker.StageInProgress); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	// No status configured for the stage
+	if err := tr.Transition("PROJ-1", tracker.StageDone); err != nil {
+		t.Fatalf("Transition() without a status error = %v", err)
+	}
+	if err := tr.LinkPR("PROJ-1", "https://github.com/o/r/pull/7"); err != nil {
+		t.Fatalf("LinkPR() error = %v", err)
+	}
+	writes := fake.writes()
+	if len(writes) != 2 || !strings.Contains(writes[1], "/remotelink") || !strings.Contains(writes[1], "pull/7") {
+		t.Errorf("writes = %q, want one transition and a remote link", writes)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	cfg := config.Defaults()
+	if _, err := tracker.New("jira", cfg); !errors.Is(err, tracker.ErrNotConfigured) {
+		t.Errorf("tracker.New(jira) without jira.url error = %v, want ErrNotConfigured", err)
+	}
+
+	t.Setenv(TokenEnv, "tok")
+	cfg.Jira.URL = "https://acme.atlassian.net"
+	tr, err := tracker.New("jira", cfg)
+	if err != nil || tr.Name() != "jira" {
+		t.Errorf("tracker.New(jira) = %v, %v", tr, err)
+	}
+}
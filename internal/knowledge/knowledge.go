@@ -0,0 +1,259 @@
+// Package knowledge provides the cross-plan knowledge base: gotchas from
+// plans' progress logs and retrospective lessons, kept in
+// .ralph/knowledge.md keyed by the paths and topics they apply to, so later
+// plans touching the same code get the relevant ones in their prompt.
+package knowledge
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// FileName is the name of the knowledge base in the .ralph directory.
+const FileName = "knowledge.md"
+
+// header starts a new knowledge base.
+const header = `# Knowledge
+
+Gotchas ralph collected from finished plans, keyed by the paths and topics they apply to. One entry per line: ` + "`- [keys] text`" + `. Edit or prune freely.
+`
+
+// entryRegex matches an entry line, e.g.
+// "- [internal/worker/**, testing] Reset cobra flags <!-- add-login 2024-01-30 seen=2 -->".
+var entryRegex = regexp.MustCompile(`^- (?:\[([^\]]*)\]\s*)?(.*?)\s*(?:<!--\s*(.*?)\s*-->)?\s*$`)
+
+// Entry is one piece of knowledge.
+type Entry struct {
+	// Keys are the path globs ("internal/worker/**", "go.mod") and topics
+	// ("testing") the entry applies to. An entry without keys applies
+	// everywhere.
+	Keys []string
+
+	// Text is the gotcha or lesson.
+	Text string
+
+	// Plan is the plan that last recorded the entry.
+	Plan string
+
+	// Date is when the entry was last recorded, as YYYY-MM-DD.
+	Date string
+
+	// Seen is how many times the entry was recorded.
+	Seen int
+}
+
+// Store is the knowledge base file.
+type Store struct {
+	path string
+
+	// Entries are the entries in file order.
+	Entries []Entry
+}
+
+// Path returns the knowledge base path for the given .ralph directory.
+func Path(configDir string) string {
+	return filepath.Join(configDir, FileName)
+}
+
+// Load reads the knowledge base at path. A missing file is an empty store.
+// Lines that aren't entries are dropped when the store is saved.
+func Load(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("reading knowledge base: %w", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "- ") {
+			continue
+		}
+		m := entryRegex.FindStringSubmatch(line)
+		if m == nil || m[2] == "" {
+			continue
+		}
+		e := Entry{Keys: splitKeys(m[1]), Text: m[2], Seen: 1}
+		parseMeta(&e, m[3])
+		s.Entries = append(s.Entries, e)
+	}
+	return s, nil
+}
+
+// Save writes the store back to its file.
+func (s *Store) Save() error {
+	var sb strings.Builder
+	sb.WriteString(header + "\n")
+	for _, e := range s.Entries {
+		sb.WriteString(e.line() + "\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating knowledge base directory: %w", err)
+	}
+	return plan.WriteFileAtomic(s.path, []byte(sb.String()), 0644)
+}
+
+// Add records an entry. An entry with the same text (ignoring case,
+// spacing, and a trailing period) is updated instead: its keys are merged,
+// Seen is incremented, and Plan and Date are replaced. Returns true if the
+// entry is new.
+func (s *Store) Add(e Entry) bool {
+	e.Text = strings.Join(strings.Fields(e.Text), " ")
+	if e.Text == "" {
+		return false
+	}
+	for i := range s.Entries {
+		existing := &s.Entries[i]
+		if normalize(existing.Text) != normalize(e.Text) {
+			continue
+		}
+		for _, key := range e.Keys {
+			existing.Keys = appendKey(existing.Keys, key)
+		}
+		existing.Seen++
+		existing.Plan, existing.Date = e.Plan, e.Date
+		return false
+	}
+	if e.Seen == 0 {
+		e.Seen = 1
+	}
+	var keys []string
+	for _, key := range e.Keys {
+		keys = appendKey(keys, key)
+	}
+	e.Keys = keys
+	s.Entries = append(s.Entries, e)
+	return true
+}
+
+// Harvest adds the gotchas of a plan's progress log to the store, each keyed
+// by the directories of the files its iteration changed (or, if it changed
+// none, the plan's **Scope:**) and the plan's labels as topics. Returns the
+// number of new entries.
+func (s *Store) Harvest(p *plan.Plan, progress *plan.Progress, now time.Time) int {
+	added := 0
+	for _, entry := range progress.Entries {
+		if len(entry.Gotchas) == 0 {
+			continue
+		}
+		keys := PathKeys(entry.Files)
+		if len(keys) == 0 {
+			keys = PathKeys(p.Scope)
+		}
+		keys = append(keys, p.Labels...)
+		for _, gotcha := range entry.Gotchas {
+			if s.Add(Entry{Keys: keys, Text: gotcha, Plan: p.Name, Date: now.Format("2006-01-02")}) {
+				added++
+			}
+		}
+	}
+	return added
+}
+
+// PathKeys turns file paths into path keys: "dir/**" for files in a
+// directory, the path itself for files at the repository root, and
+// "dir/**" for directories ("internal/worker/"). Duplicates are dropped.
+func PathKeys(paths []string) []string {
+	var keys []string
+	for _, p := range paths {
+		p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+		if p == "" {
+			continue
+		}
+		switch dir := path.Dir(p); {
+		case strings.HasSuffix(p, "/"):
+			keys = appendKey(keys, strings.TrimSuffix(p, "/")+"/**")
+		case dir == ".":
+			keys = appendKey(keys, p)
+		default:
+			keys = appendKey(keys, dir+"/**")
+		}
+	}
+	return keys
+}
+
+// line formats the entry as a knowledge base line.
+func (e Entry) line() string {
+	var sb strings.Builder
+	sb.WriteString("- ")
+	if len(e.Keys) > 0 {
+		sb.WriteString("[" + strings.Join(e.Keys, ", ") + "] ")
+	}
+	sb.WriteString(e.Text)
+
+	var meta []string
+	if e.Plan != "" {
+		meta = append(meta, e.Plan)
+	}
+	if e.Date != "" {
+		meta = append(meta, e.Date)
+	}
+	if e.Seen > 1 {
+		meta = append(meta, fmt.Sprintf("seen=%d", e.Seen))
+	}
+	if len(meta) > 0 {
+		sb.WriteString(" <!-- " + strings.Join(meta, " ") + " -->")
+	}
+	return sb.String()
+}
+
+// parseMeta reads an entry's trailing comment: the plan, the date, and
+// seen=N, in any order.
+func parseMeta(e *Entry, meta string) {
+	for _, field := range strings.Fields(meta) {
+		switch {
+		case strings.HasPrefix(field, "seen="):
+			if n, err := strconv.Atoi(strings.TrimPrefix(field, "seen=")); err == nil && n > 0 {
+				e.Seen = n
+			}
+		case isDate(field):
+			e.Date = field
+		default:
+			e.Plan = field
+		}
+	}
+}
+
+// isDate reports whether s is a YYYY-MM-DD date.
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// splitKeys splits a comma-separated key list.
+func splitKeys(list string) []string {
+	var keys []string
+	for _, key := range strings.Split(list, ",") {
+		keys = appendKey(keys, key)
+	}
+	return keys
+}
+
+// appendKey appends key to keys unless it's blank or already there.
+func appendKey(keys []string, key string) []string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return keys
+	}
+	for _, existing := range keys {
+		if strings.EqualFold(existing, key) {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
+
+// normalize is the form entry texts are compared in.
+func normalize(text string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.Join(strings.Fields(text), " ")), ".")
+}
@@ -0,0 +1,103 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	path := Path(filepath.Join(t.TempDir(), ".ralph"))
+	s, err := Load(path)
+	if err != nil || len(s.Entries) != 0 {
+		t.Fatalf("Load() of a missing file = %+v, %v", s, err)
+	}
+
+	s.Add(Entry{Keys: []string{"internal/worker/**", "testing"}, Text: "Reset cobra flags in each test", Plan: "add-login", Date: "2024-01-30"})
+	s.Add(Entry{Text: "The CI runner has no network"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "- [internal/worker/**, testing] Reset cobra flags in each test <!-- add-login 2024-01-30 -->\n") {
+		t.Errorf("file = %s", data)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Keys: []string{"internal/worker/**", "testing"}, Text: "Reset cobra flags in each test", Plan: "add-login", Date: "2024-01-30", Seen: 1},
+		{Text: "The CI runner has no network", Seen: 1},
+	}
+	if !reflect.DeepEqual(loaded.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", loaded.Entries, want)
+	}
+}
+
+func TestLoad_HandEdited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	os.WriteFile(path, []byte("# Knowledge\n\nSome notes.\n\n- [go.mod] Run go mod tidy after adding imports <!-- seen=3 deps 2024-02-01 -->\n- Plain entry\n-\n"), 0644)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{Keys: []string{"go.mod"}, Text: "Run go mod tidy after adding imports", Plan: "deps", Date: "2024-02-01", Seen: 3},
+		{Text: "Plain entry", Seen: 1},
+	}
+	if !reflect.DeepEqual(s.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", s.Entries, want)
+	}
+}
+
+func TestStore_Add_Merges(t *testing.T) {
+	s := &Store{}
+	if !s.Add(Entry{Keys: []string{"internal/worker/**"}, Text: "Reset cobra flags", Plan: "a", Date: "2024-01-01"}) {
+		t.Error("Add() of a new entry should return true")
+	}
+	if s.Add(Entry{Keys: []string{"cli/**", "internal/worker/**"}, Text: "reset  cobra flags.", Plan: "b", Date: "2024-02-01"}) {
+		t.Error("Add() of a known entry should return false")
+	}
+	want := Entry{Keys: []string{"internal/worker/**", "cli/**"}, Text: "Reset cobra flags", Plan: "b", Date: "2024-02-01", Seen: 2}
+	if len(s.Entries) != 1 || !reflect.DeepEqual(s.Entries[0], want) {
+		t.Errorf("Entries = %+v, want [%+v]", s.Entries, want)
+	}
+}
+
+func TestStore_Harvest(t *testing.T) {
+	p := &plan.Plan{Name: "add-login", Scope: []string{"internal/auth/"}, Labels: []string{"backend"}}
+	progress := &plan.Progress{Entries: []plan.ProgressEntry{
+		{Iteration: 1, Gotchas: []string{"Sessions need the DB container"}, Files: []string{"internal/session/store.go", "go.mod"}},
+		{Iteration: 2},
+		{Iteration: 3, Gotchas: []string{"Login tests share a fixture"}},
+	}}
+
+	s := &Store{}
+	if n := s.Harvest(p, progress, time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)); n != 2 {
+		t.Errorf("Harvest() = %d, want 2", n)
+	}
+	want := []Entry{
+		{Keys: []string{"internal/session/**", "go.mod", "backend"}, Text: "Sessions need the DB container", Plan: "add-login", Date: "2024-01-30", Seen: 1},
+		{Keys: []string{"internal/auth/**", "backend"}, Text: "Login tests share a fixture", Plan: "add-login", Date: "2024-01-30", Seen: 1},
+	}
+	if !reflect.DeepEqual(s.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", s.Entries, want)
+	}
+}
+
+func TestPathKeys(t *testing.T) {
+	got := PathKeys([]string{"internal/worker/worker.go", "internal/worker/gates.go", "./README.md", "cmd/", ""})
+	want := []string{"internal/worker/**", "README.md", "cmd/**"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PathKeys() = %q, want %q", got, want)
+	}
+}
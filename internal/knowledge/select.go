@@ -0,0 +1,153 @@
+package knowledge
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// charsPerToken estimates prompt tokens from characters.
+const charsPerToken = 4
+
+// Query is what entries are matched against for a plan.
+type Query struct {
+	// Paths are the files and directories the plan touches.
+	Paths []string
+
+	// Topics are the plan's labels and the words of its name.
+	Topics []string
+}
+
+// QueryFor builds the query for a plan: its **Scope:** and the files in its
+// changes ledger as paths, its labels and name words as topics.
+func QueryFor(p *plan.Plan) Query {
+	q := Query{Paths: append([]string(nil), p.Scope...)}
+	if changes, err := plan.LoadChanges(p); err == nil {
+		for _, f := range changes.Files {
+			q.Paths = append(q.Paths, f.Path)
+		}
+	}
+	q.Topics = append(q.Topics, p.Labels...)
+	q.Topics = append(q.Topics, strings.Split(plan.Slug(p.Name), "-")...)
+	return q
+}
+
+// Relevant returns up to k entries relevant to q, best first (k <= 0 = all).
+// Each key matching one of the paths scores 2 and each matching topic 1;
+// entries without keys apply everywhere and score 1, and entries that match
+// nothing are left out. Ties go to the entry seen more often, then the more
+// recent one.
+func (s *Store) Relevant(q Query, k int) []Entry {
+	type scored struct {
+		entry Entry
+		score int
+	}
+	var matches []scored
+	for _, e := range s.Entries {
+		score := 0
+		if len(e.Keys) == 0 {
+			score = 1
+		}
+		for _, key := range e.Keys {
+			if isPathKey(key) {
+				if matchesAnyPath(key, q.Paths) {
+					score += 2
+				}
+			} else if containsFold(q.Topics, key) {
+				score++
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{e, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.entry.Seen != b.entry.Seen {
+			return a.entry.Seen > b.entry.Seen
+		}
+		return a.entry.Date > b.entry.Date
+	})
+	if k > 0 && len(matches) > k {
+		matches = matches[:k]
+	}
+
+	entries := make([]Entry, len(matches))
+	for i, m := range matches {
+		entries[i] = m.entry
+	}
+	return entries
+}
+
+// Format renders entries as the prompt's knowledge section, leaving out the
+// entries past maxTokens (estimated at 4 characters per token; 0 = no
+// limit). Returns an empty string if no entries fit.
+func Format(entries []Entry, maxTokens int) string {
+	var lines []string
+	used := 0
+	for _, e := range entries {
+		line := "- " + e.Text
+		if len(e.Keys) > 0 {
+			line += " (" + strings.Join(e.Keys, ", ") + ")"
+		}
+		tokens := (len(line) + charsPerToken - 1) / charsPerToken
+		if maxTokens > 0 && used+tokens > maxTokens {
+			break
+		}
+		used += tokens
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Known Gotchas\n\n")
+	fmt.Fprintf(&sb, "Earlier plans in this repository ran into these where this plan is working (from .ralph/%s).\n\n", FileName)
+	sb.WriteString(strings.Join(lines, "\n"))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// isPathKey reports whether key is a path glob rather than a topic.
+func isPathKey(key string) bool {
+	return strings.ContainsAny(key, "/*.")
+}
+
+// matchesAnyPath reports whether the path key matches one of paths: a file
+// under a "dir/**" key or a directory containing it, or a file matching the
+// key as a glob.
+func matchesAnyPath(key string, paths []string) bool {
+	prefix := strings.TrimSuffix(strings.TrimSuffix(key, "**"), "/")
+	for _, p := range paths {
+		p = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(p), "./"), "/")
+		if p == "" {
+			continue
+		}
+		if p == prefix || strings.HasPrefix(p, prefix+"/") || strings.HasPrefix(prefix, p+"/") {
+			return true
+		}
+		if ok, _ := path.Match(key, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
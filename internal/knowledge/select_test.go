@@ -0,0 +1,85 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func texts(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Text)
+	}
+	return out
+}
+
+func TestStore_Relevant(t *testing.T) {
+	s := &Store{Entries: []Entry{
+		{Keys: []string{"internal/worker/**"}, Text: "worker", Seen: 1},
+		{Keys: []string{"internal/notify/**"}, Text: "notify", Seen: 5},
+		{Keys: []string{"testing"}, Text: "topic", Seen: 1},
+		{Text: "global", Seen: 1, Date: "2024-01-01"},
+		{Keys: []string{"internal/worker/**", "testing"}, Text: "worker testing", Seen: 1},
+		{Keys: []string{"*.md"}, Text: "markdown", Seen: 1, Date: "2024-02-01"},
+	}}
+	q := Query{Paths: []string{"internal/worker/worker.go", "README.md"}, Topics: []string{"Testing"}}
+
+	got := texts(s.Relevant(q, 0))
+	want := []string{"worker testing", "markdown", "worker", "global", "topic"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Relevant() = %q, want %q", got, want)
+	}
+	if got := texts(s.Relevant(q, 2)); !reflect.DeepEqual(got, want[:2]) {
+		t.Errorf("Relevant(k=2) = %q, want %q", got, want[:2])
+	}
+
+	// A scope directory matches keys below it
+	if got := texts(s.Relevant(Query{Paths: []string{"internal/"}}, 0)); !reflect.DeepEqual(got, []string{"notify", "worker", "worker testing", "global"}) {
+		t.Errorf("Relevant(internal/) = %q", got)
+	}
+}
+
+func TestQueryFor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Add Login.md")
+	os.WriteFile(path, []byte("# Plan\n**Scope:** internal/auth/\n**Labels:** backend\n"), 0644)
+	p, err := plan.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "internal/session/store.go", Change: plan.ChangeCreated}})
+
+	q := QueryFor(p)
+	if !reflect.DeepEqual(q.Paths, []string{"internal/auth/", "internal/session/store.go"}) {
+		t.Errorf("Paths = %q", q.Paths)
+	}
+	if !reflect.DeepEqual(q.Topics, []string{"backend", "add", "login"}) {
+		t.Errorf("Topics = %q", q.Topics)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format(nil, 100); got != "" {
+		t.Errorf("Format(nil) = %q, want empty", got)
+	}
+
+	entries := []Entry{
+		{Keys: []string{"internal/worker/**"}, Text: "Reset cobra flags in each test"},
+		{Text: strings.Repeat("x", 400)},
+		{Text: "Short"},
+	}
+	got := Format(entries, 30)
+	if !strings.Contains(got, "## Known Gotchas") || !strings.Contains(got, "- Reset cobra flags in each test (internal/worker/**)") {
+		t.Errorf("Format() = %q", got)
+	}
+	if strings.Contains(got, "xxxx") || strings.Contains(got, "Short") {
+		t.Errorf("Format() should stop at the first entry over the budget, got %q", got)
+	}
+	if got := Format(entries, 0); !strings.Contains(got, "- Short") {
+		t.Errorf("Format() without a budget should include every entry, got %q", got)
+	}
+}
@@ -0,0 +1,346 @@
+// Package linear is a small Linear GraphQL client for importing issues as
+// plans, queueing plans from labeled issues via webhook, and keeping issue
+// status in sync as the worker processes them. It registers the "linear"
+// tracker.
+package linear
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// APIKeyEnv overrides linear.api_key.
+const APIKeyEnv = "LINEAR_API_KEY"
+
+// DefaultAPIURL is Linear's GraphQL endpoint.
+const DefaultAPIURL = "https://api.linear.app/graphql"
+
+// requestTimeout bounds each Linear API request.
+const requestTimeout = 30 * time.Second
+
+// idRegex matches a Linear issue identifier such as "ENG-123".
+var idRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*-\d+$`)
+
+// ErrNotConfigured is returned when no Linear API key is set.
+var ErrNotConfigured = fmt.Errorf("linear is %w (set linear.api_key or $%s)", tracker.ErrNotConfigured, APIKeyEnv)
+
+func init() {
+	tracker.Register("linear", func(cfg *config.Config) (tracker.Tracker, error) {
+		return NewTracker(cfg.Linear)
+	})
+}
+
+// Issue is the part of a Linear issue ralph uses.
+type Issue struct {
+	// ID is Linear's internal issue ID.
+	ID string
+
+	// Identifier is the human-readable key, e.g. "ENG-123".
+	Identifier string
+
+	// Title is the issue title.
+	Title string
+
+	// Description is the issue body in markdown.
+	Description string
+
+	// Labels are the issue's label names.
+	Labels []string
+
+	// State is the issue's current workflow state name.
+	State string
+
+	// URL is the issue's web link.
+	URL string
+}
+
+// Client talks to the Linear GraphQL API.
+type Client struct {
+	apiURL string
+	apiKey string
+	http   *http.Client
+}
+
+// New creates a client from cfg. The API key comes from $LINEAR_API_KEY or
+// linear.api_key; returns ErrNotConfigured if neither is set.
+func New(cfg config.LinearConfig) (*Client, error) {
+	key := APIKey(cfg)
+	if key == "" {
+		return nil, ErrNotConfigured
+	}
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{apiURL: apiURL, apiKey: key, http: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// APIKey returns the Linear API key from $LINEAR_API_KEY or linear.api_key.
+func APIKey(cfg config.LinearConfig) string {
+	if key := os.Getenv(APIKeyEnv); key != "" {
+		return key
+	}
+	return cfg.APIKey
+}
+
+// ValidIdentifier reports whether id looks like a Linear issue identifier.
+func ValidIdentifier(id string) bool {
+	return idRegex.MatchString(id)
+}
+
+// issueFields are the issue fields requested by every issue query.
+const issueFields = `id identifier title description url state { name } labels { nodes { name } }`
+
+// issueNode is an issue as returned by the API.
+type issueNode struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	State       struct {
+		Name string `json:"name"`
+	} `json:"state"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Team struct {
+		States struct {
+			Nodes []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"nodes"`
+		} `json:"states"`
+	} `json:"team"`
+}
+
+// issue converts the node to an Issue.
+func (n *issueNode) issue() *Issue {
+	issue := &Issue{
+		ID:          n.ID,
+		Identifier:  n.Identifier,
+		Title:       n.Title,
+		Description: n.Description,
+		State:       n.State.Name,
+		URL:         n.URL,
+	}
+	for _, l := range n.Labels.Nodes {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue
+}
+
+// Issue fetches an issue by identifier ("ENG-123") or ID.
+func (c *Client) Issue(id string) (*Issue, error) {
+	var resp struct {
+		Issue *issueNode `json:"issue"`
+	}
+	query := `query($id: String!) { issue(id: $id) { ` + issueFields + ` } }`
+	if err := c.do(query, map[string]interface{}{"id": id}, &resp); err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", id, err)
+	}
+	if resp.Issue == nil {
+		return nil, fmt.Errorf("fetching %s: issue not found", id)
+	}
+	return resp.Issue.issue(), nil
+}
+
+// Transition moves an issue to the workflow state named state in its team.
+// Returns tracker.ErrNoTransition if the team has no such state.
+func (c *Client) Transition(id, state string) error {
+	var resp struct {
+		Issue *issueNode `json:"issue"`
+	}
+	query := `query($id: String!) { issue(id: $id) { id team { states { nodes { id name } } } } }`
+	if err := c.do(query, map[string]interface{}{"id": id}, &resp); err != nil {
+		return fmt.Errorf("listing states for %s: %w", id, err)
+	}
+	if resp.Issue == nil {
+		return fmt.Errorf("listing states for %s: issue not found", id)
+	}
+
+	for _, s := range resp.Issue.Team.States.Nodes {
+		if strings.EqualFold(s.Name, state) {
+			mutation := `mutation($id: String!, $stateId: String!) { issueUpdate(id: $id, input: {stateId: $stateId}) { success } }`
+			if err := c.do(mutation, map[string]interface{}{"id": resp.Issue.ID, "stateId": s.ID}, nil); err != nil {
+				return fmt.Errorf("moving %s to %s: %w", id, state, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w to %q for %s", tracker.ErrNoTransition, state, id)
+}
+
+// Comment adds a markdown comment to an issue.
+func (c *Client) Comment(id, body string) error {
+	mutation := `mutation($id: String!, $body: String!) { commentCreate(input: {issueId: $id, body: $body}) { success } }`
+	if err := c.do(mutation, map[string]interface{}{"id": id, "body": body}, nil); err != nil {
+		return fmt.Errorf("commenting on %s: %w", id, err)
+	}
+	return nil
+}
+
+// Attach attaches a link to target, with the given title, to an issue.
+func (c *Client) Attach(id, target, title string) error {
+	mutation := `mutation($id: String!, $url: String!, $title: String!) { attachmentCreate(input: {issueId: $id, url: $url, title: $title}) { success } }`
+	if err := c.do(mutation, map[string]interface{}{"id": id, "url": target, "title": title}, nil); err != nil {
+		return fmt.Errorf("attaching to %s: %w", id, err)
+	}
+	return nil
+}
+
+// do runs a GraphQL query and decodes its data into out (if non-nil).
+func (c *Client) do(query string, variables map[string]interface{}, out interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.apiURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("linear returned %s: %s", resp.Status, strings.TrimSpace(string(body[:min(len(body), 1024)])))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("parsing linear response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = e.Message
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// Tracker adapts a Client to tracker.Tracker for plans with a **Linear:**
+// header, moving issues to the states in linear.in_progress,
+// linear.in_review, and linear.done.
+type Tracker struct {
+	client *Client
+	cfg    config.LinearConfig
+}
+
+// NewTracker creates a Linear tracker from cfg (see New).
+func NewTracker(cfg config.LinearConfig) (*Tracker, error) {
+	client, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Tracker{client: client, cfg: cfg}, nil
+}
+
+// Name returns "linear".
+func (t *Tracker) Name() string {
+	return "linear"
+}
+
+// ParseKey uppercases id and checks it looks like a Linear identifier.
+func (t *Tracker) ParseKey(s string) (string, error) {
+	id := strings.ToUpper(strings.TrimSpace(s))
+	if !ValidIdentifier(id) {
+		return "", fmt.Errorf("invalid Linear issue ID %q (expected e.g. ENG-123)", s)
+	}
+	return id, nil
+}
+
+// IssueFor returns the plan's **Linear:** issue identifier.
+func (t *Tracker) IssueFor(p *plan.Plan) string {
+	return p.Linear
+}
+
+// FetchIssue fetches the issue, keyed by its identifier.
+func (t *Tracker) FetchIssue(id string) (*tracker.Issue, error) {
+	issue, err := t.client.Issue(id)
+	if err != nil {
+		return nil, err
+	}
+	return &tracker.Issue{
+		Key:    issue.Identifier,
+		Title:  issue.Title,
+		Body:   issue.Description,
+		Labels: issue.Labels,
+		Status: issue.State,
+		URL:    issue.URL,
+	}, nil
+}
+
+// PlanOptions builds a plan for the issue with a **Linear:** header. The
+// branch name carries the identifier, so Linear links it.
+func (t *Tracker) PlanOptions(issue *tracker.Issue) plan.ScaffoldOptions {
+	opts := tracker.ScaffoldOptions(issue, "Linear")
+	opts.Linear = issue.Key
+	return opts
+}
+
+// Comment posts text on the issue.
+func (t *Tracker) Comment(id, text string) error {
+	return t.client.Comment(id, text)
+}
+
+// Transition moves the issue to the state configured for stage. A stage
+// without a state is a no-op.
+func (t *Tracker) Transition(id, stage string) error {
+	state := StateFor(t.cfg, stage)
+	if state == "" {
+		return nil
+	}
+	return t.client.Transition(id, state)
+}
+
+// LinkPR attaches the pull request to the issue.
+func (t *Tracker) LinkPR(id, prURL string) error {
+	return t.client.Attach(id, prURL, "Pull request")
+}
+
+// StateFor returns the configured Linear workflow state for a stage.
+func StateFor(cfg config.LinearConfig, stage string) string {
+	switch stage {
+	case tracker.StageInProgress:
+		return cfg.InProgress
+	case tracker.StageInReview:
+		return cfg.InReview
+	case tracker.StageDone:
+		return cfg.Done
+	}
+	return ""
+}
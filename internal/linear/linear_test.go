@@ -0,0 +1,186 @@
+package linear
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// fakeLinear serves issue ENG-1 in a team with the states "Todo",
+// "In Progress", and "Done", recording each mutation's variables.
+type fakeLinear struct {
+	mu        sync.Mutex
+	mutations []string
+	auth      string
+}
+
+func (f *fakeLinear) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	switch {
+	case strings.HasPrefix(req.Query, "mutation"):
+		vars, _ := json.Marshal(req.Variables)
+		f.mutations = append(f.mutations, string(vars))
+		io.WriteString(w, `{"data": {"result": {"success": true}}}`)
+	case req.Variables["id"] != "ENG-1":
+		io.WriteString(w, `{"data": null, "errors": [{"message": "Entity not found: Issue"}]}`)
+	case strings.Contains(req.Query, "team"):
+		io.WriteString(w, `{"data": {"issue": {"id": "uuid-1", "team": {"states": {"nodes": [{"id": "s1", "name": "Todo"}, {"id": "s2", "name": "In Progress"}, {"id": "s3", "name": "Done"}]}}}}}`)
+	default:
+		io.WriteString(w, `{"data": {"issue": {"id": "uuid-1", "identifier": "ENG-1", "title": "Fix login", "description": "Login fails on Safari.", "url": "https://linear.app/acme/issue/ENG-1", "state": {"name": "Todo"}, "labels": {"nodes": [{"name": "ralph"}, {"name": "web"}]}}}}`)
+	}
+}
+
+func (f *fakeLinear) writes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.mutations...)
+}
+
+func newTestClient(t *testing.T) (*Client, *fakeLinear) {
+	t.Helper()
+	fake := &fakeLinear{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	t.Setenv(APIKeyEnv, "")
+
+	client, err := New(config.LinearConfig{APIKey: "lin_key", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return client, fake
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(APIKeyEnv, "")
+	if _, err := New(config.LinearConfig{}); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("New() without key error = %v, want ErrNotConfigured", err)
+	}
+
+	t.Setenv(APIKeyEnv, "from-env")
+	client, err := New(config.LinearConfig{APIKey: "from-config"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.apiKey != "from-env" {
+		t.Errorf("apiKey = %q, want $%s to take precedence", client.apiKey, APIKeyEnv)
+	}
+	if client.apiURL != DefaultAPIURL {
+		t.Errorf("apiURL = %q, want %q", client.apiURL, DefaultAPIURL)
+	}
+}
+
+func TestClient_Issue(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	issue, err := client.Issue("ENG-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if issue.Identifier != "ENG-1" || issue.Title != "Fix login" || issue.State != "Todo" || len(issue.Labels) != 2 {
+		t.Errorf("Issue() = %+v", issue)
+	}
+	if fake.auth != "lin_key" {
+		t.Errorf("Authorization = %q, want the bare API key", fake.auth)
+	}
+
+	if _, err := client.Issue("ENG-404"); err == nil || !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("Issue() for a missing issue error = %v, want the GraphQL error", err)
+	}
+}
+
+func TestClient_TransitionAndComment(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	if err := client.Transition("ENG-1", "in progress"); err != nil {
+		t.Fatalf("Transition() error = %v", err)
+	}
+	if err := client.Transition("ENG-1", "In Review"); !errors.Is(err, tracker.ErrNoTransition) {
+		t.Errorf("Transition() to a missing state error = %v, want ErrNoTransition", err)
+	}
+	if err := client.Comment("ENG-1", "PR opened"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+
+	got := fake.writes()
+	if len(got) != 2 || !strings.Contains(got[0], `"stateId":"s2"`) || !strings.Contains(got[1], `"body":"PR opened"`) {
+		t.Errorf("mutations = %q, want a state update to s2 and a comment", got)
+	}
+}
+
+func TestValidIdentifier(t *testing.T) {
+	for id, want := range map[string]bool{"ENG-1": true, "A1-42": true, "eng-1": false, "ENG": false, "ENG-": false} {
+		if got := ValidIdentifier(id); got != want {
+			t.Errorf("ValidIdentifier(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	fake := &fakeLinear{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	t.Setenv(APIKeyEnv, "")
+
+	cfg := config.Defaults().Linear
+	cfg.APIKey = "lin_key"
+	cfg.APIURL = server.URL
+	cfg.InReview = ""
+	tr, err := NewTracker(cfg)
+	if err != nil {
+		t.Fatalf("NewTracker() error = %v", err)
+	}
+
+	if tr.Name() != "linear" {
+		t.Errorf("Name() = %q", tr.Name())
+	}
+	if key := tr.IssueFor(&plan.Plan{Linear: "ENG-1", Jira: "PROJ-1"}); key != "ENG-1" {
+		t.Errorf("IssueFor() = %q, want ENG-1", key)
+	}
+	if key, err := tr.ParseKey("eng-1"); err != nil || key != "ENG-1" {
+		t.Errorf("ParseKey() = %q, %v, want ENG-1", key, err)
+	}
+
+	issue, err := tr.FetchIssue("ENG-1")
+	if err != nil {
+		t.Fatalf("FetchIssue() error = %v", err)
+	}
+	opts := tr.PlanOptions(issue)
+	if opts.Title != "ENG-1 Fix login" || opts.Linear != "ENG-1" || opts.Body != "Login fails on Safari." {
+		t.Errorf("PlanOptions() = %+v", opts)
+	}
+	if !strings.Contains(opts.Source, "https://linear.app/acme/issue/ENG-1") {
+		t.Errorf("Source = %q, want the issue URL", opts.Source)
+	}
+
+	if err := tr.Transition("ENG-1", tracker.StageInReview); err != nil {
+		t.Errorf("Transition() to a stage without a state error = %v, want no-op", err)
+	}
+	if err := tr.Transition("ENG-1", tracker.StageDone); err != nil {
+		t.Fatalf("Transition(done) error = %v", err)
+	}
+	if err := tr.LinkPR("ENG-1", "https://github.com/o/r/pull/7"); err != nil {
+		t.Fatalf("LinkPR() error = %v", err)
+	}
+	got := fake.writes()
+	if len(got) != 2 || !strings.Contains(got[0], `"stateId":"s3"`) || !strings.Contains(got[1], "pull/7") {
+		t.Errorf("mutations = %q, want a move to Done and a PR attachment", got)
+	}
+}
@@ -0,0 +1,139 @@
+package linear
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// WebhookSecretEnv overrides linear.webhook_secret.
+const WebhookSecretEnv = "LINEAR_WEBHOOK_SECRET"
+
+// MaxWebhookSize caps the body of a webhook delivery.
+const MaxWebhookSize = 1 << 20
+
+// maxWebhookAge is how far a delivery's webhookTimestamp may be from now
+// before it is rejected as a replay.
+const maxWebhookAge = time.Minute
+
+// WebhookSecret returns the webhook signing secret from
+// $LINEAR_WEBHOOK_SECRET or linear.webhook_secret.
+func WebhookSecret(cfg config.LinearConfig) string {
+	if secret := os.Getenv(WebhookSecretEnv); secret != "" {
+		return secret
+	}
+	return cfg.WebhookSecret
+}
+
+// webhookPayload is the part of a Linear webhook delivery ralph uses.
+type webhookPayload struct {
+	Action           string `json:"action"`
+	Type             string `json:"type"`
+	WebhookTimestamp int64  `json:"webhookTimestamp"`
+	Data             struct {
+		ID         string `json:"id"`
+		Identifier string `json:"identifier"`
+		Labels     []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"data"`
+}
+
+// WebhookHandler serves Linear webhook deliveries. When an issue is created
+// or updated with the trigger label it calls OnIssue with the issue's
+// identifier; OnIssue is expected to fetch the issue and queue it unless
+// it already has a plan. Deliveries must carry a valid Linear-Signature.
+type WebhookHandler struct {
+	secret string
+	label  string
+
+	// OnIssue is called with the identifier of each labeled issue.
+	OnIssue func(id string) error
+
+	// now returns the current time (for tests).
+	now func() time.Time
+}
+
+// NewWebhookHandler creates a handler verifying deliveries with secret and
+// reacting to issues labeled label. An empty secret rejects every delivery.
+func NewWebhookHandler(secret, label string, onIssue func(id string) error) *WebhookHandler {
+	return &WebhookHandler{secret: secret, label: label, OnIssue: onIssue, now: time.Now}
+}
+
+// ServeHTTP handles a webhook delivery.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxWebhookSize))
+	if err != nil {
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !h.verify(body, r.Header.Get("Linear-Signature")) {
+		log.Warn("Rejected Linear webhook from %s: bad or missing signature", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if age := h.now().Sub(time.UnixMilli(payload.WebhookTimestamp)); age > maxWebhookAge || age < -maxWebhookAge {
+		log.Warn("Rejected Linear webhook from %s: stale timestamp", r.RemoteAddr)
+		http.Error(w, "stale delivery", http.StatusUnauthorized)
+		return
+	}
+
+	if payload.Type == "Issue" && (payload.Action == "create" || payload.Action == "update") && h.labeled(&payload) {
+		id := payload.Data.Identifier
+		if id == "" {
+			id = payload.Data.ID
+		}
+		if h.OnIssue != nil {
+			if err := h.OnIssue(id); err != nil {
+				log.Error("Failed to queue Linear issue %s: %v", id, err)
+				http.Error(w, "failed to queue issue", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify reports whether signature is the hex HMAC-SHA256 of body.
+func (h *WebhookHandler) verify(body []byte, signature string) bool {
+	if h.secret == "" || signature == "" {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(h.secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// labeled reports whether the delivered issue has the trigger label.
+func (h *WebhookHandler) labeled(payload *webhookPayload) bool {
+	for _, l := range payload.Data.Labels {
+		if strings.EqualFold(l.Name, h.label) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package linear
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookHandler(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var queued []string
+	h := NewWebhookHandler("whsec", "ralph", func(id string) error {
+		queued = append(queued, id)
+		return nil
+	})
+	h.now = func() time.Time { return now }
+
+	payload := func(action, labels string, ts time.Time) string {
+		return fmt.Sprintf(`{"action": %q, "type": "Issue", "webhookTimestamp": %d, "data": {"id": "uuid-1", "identifier": "ENG-1", "labels": [%s]}}`,
+			action, ts.UnixMilli(), labels)
+	}
+	deliver := func(body, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/linear", strings.NewReader(body))
+		req.Header.Set("Linear-Signature", signature)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	labeled := payload("update", `{"name": "Ralph"}`, now)
+	if code := deliver(labeled, sign("whsec", labeled)); code != http.StatusOK {
+		t.Errorf("labeled issue status = %d, want 200", code)
+	}
+	unlabeled := payload("create", `{"name": "web"}`, now)
+	if code := deliver(unlabeled, sign("whsec", unlabeled)); code != http.StatusOK {
+		t.Errorf("unlabeled issue status = %d, want 200", code)
+	}
+	removed := payload("remove", `{"name": "ralph"}`, now)
+	deliver(removed, sign("whsec", removed))
+	if len(queued) != 1 || queued[0] != "ENG-1" {
+		t.Errorf("queued = %q, want only the labeled create/update", queued)
+	}
+
+	if code := deliver(labeled, sign("other", labeled)); code != http.StatusUnauthorized {
+		t.Errorf("bad signature status = %d, want 401", code)
+	}
+	if code := deliver(labeled, ""); code != http.StatusUnauthorized {
+		t.Errorf("missing signature status = %d, want 401", code)
+	}
+	stale := payload("update", `{"name": "ralph"}`, now.Add(-5*time.Minute))
+	if code := deliver(stale, sign("whsec", stale)); code != http.StatusUnauthorized {
+		t.Errorf("stale delivery status = %d, want 401", code)
+	}
+	if len(queued) != 1 {
+		t.Errorf("rejected deliveries should not queue, got %q", queued)
+	}
+
+	open := NewWebhookHandler("", "ralph", nil)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/linear", strings.NewReader(labeled))
+	req.Header.Set("Linear-Signature", sign("", labeled))
+	rec := httptest.NewRecorder()
+	open.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("handler without a secret status = %d, want 401", rec.Code)
+	}
+}
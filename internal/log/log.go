@@ -1,10 +1,14 @@
-// Package log provides structured logging with level filtering and color support.
+// Package log provides structured logging with level filtering, color support,
+// an optional JSON output format, and per-plan log files.
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -43,6 +47,52 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel parses a level name (debug, info, warn, error).
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "success":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("invalid log level %q (want debug, info, warn, or error)", s)
+	}
+}
+
+// Format is the output format of log lines.
+type Format int
+
+const (
+	// FormatText is "[15:04:05] [LEVEL] message", optionally colored.
+	FormatText Format = iota
+	// FormatJSON is one JSON object per line with time, level, msg, and plan fields.
+	FormatJSON
+)
+
+// ParseFormat parses a format name (text, json).
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("invalid log format %q (want text or json)", s)
+	}
+}
+
+// LogsDir is the directory within .ralph holding per-plan log files.
+const LogsDir = "logs"
+
+// PlanLogPath returns the per-plan log file path within the .ralph directory.
+func PlanLogPath(configDir, plan string) string {
+	return filepath.Join(configDir, LogsDir, plan+".log")
+}
+
 // Logger defines the interface for logging operations.
 type Logger interface {
 	// Debug logs a debug message.
@@ -62,6 +112,11 @@ type Logger interface {
 	SetOutput(w io.Writer)
 	// SetColorEnabled enables or disables color output.
 	SetColorEnabled(enabled bool)
+	// SetFormat sets the output format.
+	SetFormat(format Format)
+	// SetPlanSink tags messages with plan and also writes them to w.
+	// A nil w removes the sink.
+	SetPlanSink(plan string, w io.Writer)
 }
 
 // ConsoleLogger implements Logger with console output.
@@ -70,6 +125,19 @@ type ConsoleLogger struct {
 	level        Level
 	output       io.Writer
 	colorEnabled bool
+	format       Format
+
+	// plan and sink are the current plan's name and log file, if any.
+	plan string
+	sink io.Writer
+}
+
+// jsonEntry is a log line in FormatJSON.
+type jsonEntry struct {
+	Time  string `json:"time"`
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+	Plan  string `json:"plan,omitempty"`
 }
 
 // ANSI color codes
@@ -130,7 +198,28 @@ func (l *ConsoleLogger) SetColorEnabled(enabled bool) {
 	l.colorEnabled = enabled
 }
 
+// SetFormat sets the output format.
+func (l *ConsoleLogger) SetFormat(format Format) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+}
+
+// SetPlanSink tags messages with plan and also writes them to w.
+// The sink receives every level, including debug, so a plan's log file
+// can be used to diagnose it after the fact. A nil w removes the sink.
+func (l *ConsoleLogger) SetPlanSink(plan string, w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w == nil {
+		plan = ""
+	}
+	l.plan = plan
+	l.sink = w
+}
+
 // log writes a log message if the level is at or above the current threshold.
+// Messages are always written to the plan sink, if set.
 func (l *ConsoleLogger) log(level Level, format string, args ...interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -144,26 +233,41 @@ func (l *ConsoleLogger) log(level Level, format string, args ...interface{}) {
 	if minLevel == LevelSuccess {
 		minLevel = LevelInfo
 	}
-	if effectiveLevel < minLevel {
+	show := effectiveLevel >= minLevel
+	if !show && l.sink == nil {
 		return
 	}
 
-	timestamp := time.Now().Format("15:04:05")
-	message := fmt.Sprintf(format, args...)
+	now := time.Now()
+	message := Redact(fmt.Sprintf(format, args...))
+
+	if show {
+		fmt.Fprint(l.output, l.formatLine(now, level, message, l.colorEnabled))
+	}
+	if l.sink != nil {
+		fmt.Fprint(l.sink, l.formatLine(now, level, message, false))
+	}
+}
 
-	var output string
-	if l.colorEnabled {
-		color := levelColors[level]
-		if color != "" {
-			output = fmt.Sprintf("%s[%s] [%s] %s%s\n", color, timestamp, level.String(), message, colorReset)
-		} else {
-			output = fmt.Sprintf("[%s] [%s] %s\n", timestamp, level.String(), message)
+// formatLine renders a log line in the logger's format.
+func (l *ConsoleLogger) formatLine(now time.Time, level Level, message string, color bool) string {
+	if l.format == FormatJSON {
+		data, err := json.Marshal(jsonEntry{
+			Time:  now.Format(time.RFC3339Nano),
+			Level: strings.ToLower(level.String()),
+			Msg:   message,
+			Plan:  l.plan,
+		})
+		if err == nil {
+			return string(data) + "\n"
 		}
-	} else {
-		output = fmt.Sprintf("[%s] [%s] %s\n", timestamp, level.String(), message)
 	}
 
-	fmt.Fprint(l.output, output)
+	line := fmt.Sprintf("[%s] [%s] %s", now.Format("15:04:05"), level.String(), message)
+	if code := levelColors[level]; color && code != "" {
+		return code + line + colorReset + "\n"
+	}
+	return line + "\n"
 }
 
 // Debug logs a debug message.
@@ -204,6 +308,11 @@ func Default() Logger {
 	return defaultLogger
 }
 
+// SetPlanSink sets the default logger's plan sink.
+func SetPlanSink(plan string, w io.Writer) {
+	defaultLogger.SetPlanSink(plan, w)
+}
+
 // Package-level functions that use the default logger
 
 // Debug logs a debug message using the default logger.
@@ -2,6 +2,8 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -242,3 +244,91 @@ func TestDefault(t *testing.T) {
 		t.Error("Default() should not return nil")
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warning", LevelWarn, false},
+		{"error", LevelError, false},
+		{"loud", LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, err %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("json"); err != nil || f != FormatJSON {
+		t.Errorf("ParseFormat(json) = %v, %v", f, err)
+	}
+	if f, err := ParseFormat(""); err != nil || f != FormatText {
+		t.Errorf("ParseFormat(\"\") = %v, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestConsoleLogger_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewConsoleLogger()
+	logger.SetOutput(&buf)
+	logger.SetColorEnabled(true)
+	logger.SetFormat(FormatJSON)
+	logger.SetPlanSink("my-plan", &bytes.Buffer{})
+
+	logger.Warn("disk %s", "low")
+
+	var entry jsonEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output is not JSON: %q (%v)", buf.String(), err)
+	}
+	if entry.Level != "warn" || entry.Msg != "disk low" || entry.Plan != "my-plan" || entry.Time == "" {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestConsoleLogger_PlanSink(t *testing.T) {
+	var out, sink bytes.Buffer
+	logger := NewConsoleLogger()
+	logger.SetOutput(&out)
+	logger.SetColorEnabled(true)
+	logger.SetLevel(LevelWarn)
+	logger.SetPlanSink("my-plan", &sink)
+
+	logger.Debug("debug msg")
+	logger.Error("error msg")
+
+	// The sink gets every level, without color
+	if !strings.Contains(sink.String(), "[DEBUG] debug msg") || !strings.Contains(sink.String(), "[ERROR] error msg") {
+		t.Errorf("sink = %q", sink.String())
+	}
+	if strings.Contains(sink.String(), "\033[") {
+		t.Errorf("sink should not contain color codes: %q", sink.String())
+	}
+	if strings.Contains(out.String(), "debug msg") || !strings.Contains(out.String(), "error msg") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	// Removing the sink stops copying
+	logger.SetPlanSink("my-plan", nil)
+	logger.Error("after")
+	if strings.Contains(sink.String(), "after") {
+		t.Errorf("sink written after removal: %q", sink.String())
+	}
+}
+
+func TestPlanLogPath(t *testing.T) {
+	if got := PlanLogPath(".ralph", "my-plan"); got != filepath.Join(".ralph", "logs", "my-plan.log") {
+		t.Errorf("PlanLogPath() = %q", got)
+	}
+}
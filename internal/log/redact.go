@@ -0,0 +1,154 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces masked secrets in output.
+const Redacted = "[REDACTED]"
+
+// minSecretLen is the shortest literal secret that is masked. Shorter values
+// (ports, flags like "true") would mask ordinary text.
+const minSecretLen = 8
+
+// DefaultPatterns match well-known credential formats and are always masked.
+var DefaultPatterns = []string{
+	`xox[abposr]-[A-Za-z0-9-]{10,}`,                      // Slack bot/user tokens
+	`xapp-[A-Za-z0-9-]{10,}`,                             // Slack app-level tokens
+	`https://hooks\.slack\.com/services/[A-Za-z0-9/_-]+`, // Slack incoming webhooks
+	`gh[pousr]_[A-Za-z0-9]{20,}`,                         // GitHub tokens
+	`sk-ant-[A-Za-z0-9_-]{20,}`,                          // Anthropic API keys
+}
+
+// Redactor masks secrets in text before it reaches logs, transcripts,
+// or notifications. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	secrets  []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a Redactor that masks the literal secrets and matches of
+// patterns, in addition to DefaultPatterns. Secrets shorter than 8 characters
+// are ignored. Each secret is also masked in its JSON-escaped forms, so
+// redacting a marshaled payload catches values containing &, <, >, ", or \.
+// Returns an error if a pattern is not a valid regular expression.
+func NewRedactor(secrets []string, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	seen := make(map[string]bool)
+	for _, s := range secrets {
+		s = strings.TrimSpace(s)
+		if len(s) < minSecretLen {
+			continue
+		}
+		for _, form := range append([]string{s}, jsonEscaped(s)...) {
+			if !seen[form] {
+				seen[form] = true
+				r.secrets = append(r.secrets, form)
+			}
+		}
+	}
+	// Longest first, so a secret containing another is masked whole
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+
+	for _, p := range append(append([]string{}, DefaultPatterns...), patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// jsonEscaped returns s as it appears inside a JSON string, both with the
+// HTML escaping of json.Marshal (\u0026 for &) and without it.
+func jsonEscaped(s string) []string {
+	var forms []string
+	for _, escapeHTML := range []bool{true, false} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(escapeHTML)
+		if err := enc.Encode(s); err != nil {
+			continue
+		}
+		// Drop the quotes and the encoder's trailing newline
+		quoted := strings.TrimSuffix(buf.String(), "\n")
+		forms = append(forms, quoted[1:len(quoted)-1])
+	}
+	return forms
+}
+
+// Redact returns s with every secret and pattern match replaced by Redacted.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+// EnvFileValues returns the values assigned in a .env file, for masking.
+// Blank lines and comments are skipped; surrounding quotes are removed.
+func EnvFileValues(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		_, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return values, nil
+}
+
+var (
+	redactorMu      sync.RWMutex
+	defaultRedactor *Redactor
+)
+
+// SetRedactor sets the redactor applied to all log messages and to Redact.
+// A nil redactor disables redaction.
+func SetRedactor(r *Redactor) {
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	defaultRedactor = r
+}
+
+// Redact masks secrets in s using the redactor set with SetRedactor.
+func Redact(s string) string {
+	redactorMu.RLock()
+	defer redactorMu.RUnlock()
+	return defaultRedactor.Redact(s)
+}
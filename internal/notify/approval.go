@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+)
+
+// Action IDs of the approval request buttons. The button value is the plan name.
+const (
+	ApproveActionID = "ralph_approve"
+	RejectActionID  = "ralph_reject"
+)
+
+// approvalText formats an approval request headline and how to answer it
+// from the CLI.
+func approvalText(p *plan.Plan, timeout time.Duration) string {
+	text := i18n.Sprintf(":raised_hand: *Approval Required*\n`%s` is complete and waiting for approval before the PR/merge step.", p.Name)
+	if timeout > 0 {
+		return text + i18n.Sprintf("\nRun `ralph approve %s` or `ralph reject %s --reason ...` within %s.", p.Name, p.Name, timeout)
+	}
+	return text + i18n.Sprintf("\nRun `ralph approve %s` or `ralph reject %s --reason ...`.", p.Name, p.Name)
+}
+
+// handleApprovalAction records an Approve or Reject button press.
+// Presses are only accepted in the configured channel or the plan's thread
+// channel, and from admins.
+func (b *SocketModeBot) handleApprovalAction(callback slack.InteractionCallback, action *slack.BlockAction) {
+	channelID := callback.Channel.ID
+	threadTS := callback.Message.ThreadTimestamp
+	if threadTS == "" {
+		threadTS = callback.Message.Timestamp
+	}
+
+	allowed := channelID == b.channelID
+	if info := b.findThread(threadTS); info != nil && info.ChannelID == channelID {
+		allowed = true
+	}
+	if !allowed {
+		b.postEphemeral(channelID, callback.User.ID, fmt.Sprintf("Ralph plans can only be approved from <#%s>.", b.channelID))
+		log.Warn("Rejected approval from unauthorized channel %s (user %s)", channelID, callback.User.ID)
+		return
+	}
+	planName := action.Value
+	approved := action.ActionID == ApproveActionID
+	verb := "approve"
+	if !approved {
+		verb = "reject"
+	}
+	if resp, ok := b.authorize(callback.User.ID, fmt.Sprintf("%s `%s`", verb, planName), RoleAdmin); !ok {
+		b.replyInChannelThread(channelID, threadTS, resp)
+		return
+	}
+	if b.control == nil {
+		b.postEphemeral(channelID, callback.User.ID, "Worker control is not available.")
+		return
+	}
+
+	user := callback.User.Name
+	if user == "" {
+		user = b.displayName(callback.User.ID)
+	}
+
+	reason := ""
+	if !approved {
+		reason = fmt.Sprintf("rejected in Slack by %s", user)
+	}
+	if err := b.control.Decide(planName, approved, user, reason); err != nil {
+		b.postEphemeral(channelID, callback.User.ID, fmt.Sprintf(":x: %v", err))
+		return
+	}
+
+	log.Info("Plan %s %s in Slack by %s", planName, decisionVerb(approved), user)
+	text := fmt.Sprintf(":white_check_mark: `%s` approved by %s", planName, user)
+	if !approved {
+		text = fmt.Sprintf(":no_entry: `%s` rejected by %s", planName, user)
+	}
+	b.replyInThread(threadTS, commandResponse{Text: text, PlanName: planName})
+}
+
+// decisionVerb returns "approved" or "rejected".
+func decisionVerb(approved bool) string {
+	if approved {
+		return "approved"
+	}
+	return "rejected"
+}
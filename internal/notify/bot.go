@@ -9,6 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/slack-go/slack"
@@ -23,7 +26,10 @@ const BotConfigFilename = "slack.env"
 var GlobalBotPath = filepath.Join(os.Getenv("HOME"), ".ralph")
 
 // SocketModeBot listens for Slack thread replies and writes them to feedback files.
-// It uses Slack Socket Mode to receive real-time events.
+// It also handles /ralph slash commands that operate on the queue and worker
+// control plane, creates plans from app mentions and message shortcuts, and
+// publishes a live queue status view on the App Home tab. It uses Slack
+// Socket Mode to receive real-time events.
 type SocketModeBot struct {
 	// client is the Slack Socket Mode client.
 	client *socketmode.Client
@@ -39,8 +45,39 @@ type SocketModeBot struct {
 	planBasePath string
 
 	// channelID is the channel ID to listen for messages in.
+	// Slash commands are only accepted from this channel.
 	channelID string
 
+	// queue is the plan queue operated on by slash commands.
+	queue *plan.Queue
+
+	// control is the worker control plane (pause/skip) used by slash commands.
+	control *control.Store
+
+	// auth maps Slack users to roles; nil allows everyone everything.
+	auth *Authorizer
+
+	// authors maps Slack user IDs to display names, overriding their profiles.
+	authors map[string]string
+
+	// names caches display names looked up with the Slack API.
+	names map[string]string
+
+	// namesMu protects names.
+	namesMu sync.Mutex
+
+	// events records feedback in the events log (optional).
+	events *events.Log
+
+	// remote links plan branches on the Home tab (optional).
+	remote *git.RemoteInfo
+
+	// homeUsers records users who opened the Home tab, for refreshes.
+	homeUsers map[string]bool
+
+	// homeMu protects homeUsers.
+	homeMu sync.Mutex
+
 	// mu protects running state.
 	mu sync.Mutex
 
@@ -68,6 +105,30 @@ type BotConfig struct {
 	// ChannelID is the channel ID to listen for messages in.
 	ChannelID string
 
+	// Queue is the plan queue for slash commands (optional).
+	Queue *plan.Queue
+
+	// Control is the worker control plane for slash commands (optional).
+	Control *control.Store
+
+	// Roles maps Slack user IDs to roles (viewer, operator, admin). Empty
+	// allows everyone in the channel everything.
+	Roles map[string]string
+
+	// DefaultRole is the role of users not in Roles (empty = no access).
+	DefaultRole string
+
+	// Authors maps Slack user IDs to display names (feedback.authors),
+	// overriding the names from their Slack profiles.
+	Authors map[string]string
+
+	// Events records attributed feedback in the events log (optional).
+	Events *events.Log
+
+	// Remote is the origin remote, for linking plan branches on the Home
+	// tab (optional).
+	Remote *git.RemoteInfo
+
 	// Debug enables debug logging for the Slack client.
 	Debug bool
 }
@@ -101,6 +162,12 @@ func NewSocketModeBot(cfg BotConfig) *SocketModeBot {
 		threadTracker: cfg.ThreadTracker,
 		planBasePath:  cfg.PlanBasePath,
 		channelID:     cfg.ChannelID,
+		queue:         cfg.Queue,
+		control:       cfg.Control,
+		auth:          NewAuthorizer(cfg.Roles, cfg.DefaultRole),
+		authors:       cfg.Authors,
+		events:        cfg.Events,
+		remote:        cfg.Remote,
 		stopCh:        make(chan struct{}),
 	}
 }
@@ -198,6 +265,12 @@ func (b *SocketModeBot) processEvent(evt socketmode.Event) {
 	case socketmode.EventTypeEventsAPI:
 		b.handleEventsAPIEvent(evt)
 
+	case socketmode.EventTypeSlashCommand:
+		b.handleSlashCommand(evt)
+
+	case socketmode.EventTypeInteractive:
+		b.handleInteractive(evt)
+
 	default:
 		// Acknowledge unknown events
 		if evt.Request != nil {
@@ -235,6 +308,10 @@ func (b *SocketModeBot) handleCallbackEvent(evt slackevents.EventsAPIEvent) {
 	switch ev := innerEvent.Data.(type) {
 	case *slackevents.MessageEvent:
 		b.handleMessageEvent(ev)
+	case *slackevents.AppMentionEvent:
+		b.handleAppMention(ev)
+	case *slackevents.AppHomeOpenedEvent:
+		b.handleAppHomeOpened(ev)
 	}
 }
 
@@ -246,23 +323,29 @@ func (b *SocketModeBot) handleMessageEvent(ev *slackevents.MessageEvent) {
 		return
 	}
 
-	// Only process messages in the configured channel
-	if ev.Channel != b.channelID {
-		return
-	}
-
 	// Only process thread replies (messages with ThreadTimeStamp that differs from TimeStamp)
 	if ev.ThreadTimeStamp == "" || ev.ThreadTimeStamp == ev.TimeStamp {
 		return
 	}
 
 	// Look up the plan from the thread timestamp
-	planName := b.findPlanByThread(ev.ThreadTimeStamp)
-	if planName == "" {
+	info := b.findThread(ev.ThreadTimeStamp)
+	if info == nil {
 		log.Debug("No plan found for thread: %s", ev.ThreadTimeStamp)
 		return
 	}
 
+	// Only process messages in the configured channel or the plan's override channel
+	if ev.Channel != b.channelID && ev.Channel != info.ChannelID {
+		return
+	}
+	planName := info.PlanName
+
+	if resp, ok := b.authorize(ev.User, fmt.Sprintf("send feedback to `%s`", planName), RoleOperator); !ok {
+		b.replyInChannelThread(ev.Channel, ev.ThreadTimeStamp, resp)
+		return
+	}
+
 	// Write the message to the feedback file
 	if err := b.writeFeedback(planName, ev.User, ev.Text); err != nil {
 		log.Error("Failed to write feedback: %v", err)
@@ -274,33 +357,31 @@ func (b *SocketModeBot) handleMessageEvent(ev *slackevents.MessageEvent) {
 
 // findPlanByThread looks up the plan name from a thread timestamp.
 func (b *SocketModeBot) findPlanByThread(threadTS string) string {
+	if info := b.findThread(threadTS); info != nil {
+		return info.PlanName
+	}
+	return ""
+}
+
+// findThread looks up tracked thread info from a thread timestamp.
+func (b *SocketModeBot) findThread(threadTS string) *ThreadInfo {
 	if b.threadTracker == nil {
-		return ""
+		return nil
 	}
 
 	// Get all threads and find matching one
 	for _, info := range b.threadTracker.List() {
 		if info.ThreadTS == threadTS {
-			return info.PlanName
+			return info
 		}
 	}
 
-	return ""
+	return nil
 }
 
 // writeFeedback writes a thread reply to the plan's feedback file.
 func (b *SocketModeBot) writeFeedback(planName, userID, text string) error {
-	// Get user info for display name
-	userName := userID
-	if b.api != nil {
-		if user, err := b.api.GetUserInfo(userID); err == nil {
-			if user.RealName != "" {
-				userName = user.RealName
-			} else if user.Name != "" {
-				userName = user.Name
-			}
-		}
-	}
+	userName := b.displayName(userID)
 
 	// Create a minimal plan for feedback path calculation
 	p := &plan.Plan{
@@ -309,8 +390,51 @@ func (b *SocketModeBot) writeFeedback(planName, userID, text string) error {
 	}
 
 	// Append to feedback file
-	source := fmt.Sprintf("Slack reply from %s", userName)
-	return plan.AppendFeedback(p, source, text)
+	source := "Slack reply"
+	if err := plan.AppendFeedback(p, plan.FeedbackSource(source, userName), text); err != nil {
+		return err
+	}
+	if b.events != nil {
+		if err := b.events.Append(events.Event{Type: events.TypeFeedback, Plan: planName, Author: userName, Message: source}); err != nil {
+			log.Debug("Failed to record feedback event: %v", err)
+		}
+	}
+	return nil
+}
+
+// displayName returns the user's name from feedback.authors, or their real
+// name or handle from Slack, falling back to the ID. Names looked up in
+// Slack are cached for the life of the bot.
+func (b *SocketModeBot) displayName(userID string) string {
+	if name := b.authors[userID]; name != "" {
+		return name
+	}
+
+	b.namesMu.Lock()
+	defer b.namesMu.Unlock()
+	if name, ok := b.names[userID]; ok {
+		return name
+	}
+	if b.api == nil {
+		return userID
+	}
+	user, err := b.api.GetUserInfo(userID)
+	if err != nil {
+		// Not cached, so a transient API error doesn't stick
+		log.Debug("Failed to look up Slack user %s: %v", userID, err)
+		return userID
+	}
+	name := userID
+	if user.RealName != "" {
+		name = user.RealName
+	} else if user.Name != "" {
+		name = user.Name
+	}
+	if b.names == nil {
+		b.names = make(map[string]string)
+	}
+	b.names[userID] = name
+	return name
 }
 
 // LoadGlobalBotConfig loads bot configuration from the global location (~/.ralph/slack.env).
@@ -413,6 +537,17 @@ func parseEnvLine(line string) (key, value string) {
 // This is a convenience function for auto-starting the bot from worker.
 // Returns nil if bot couldn't be started (missing config), or the bot instance if started.
 func StartBotIfConfigured(ctx context.Context, threadTracker *ThreadTracker, planBasePath, channelID string) *SocketModeBot {
+	return StartBot(ctx, BotConfig{
+		ThreadTracker: threadTracker,
+		PlanBasePath:  planBasePath,
+		ChannelID:     channelID,
+	})
+}
+
+// StartBot starts the Socket Mode bot with tokens from the global bot config.
+// All fields other than the tokens are taken from opts.
+// Returns nil if bot couldn't be started (missing config), or the bot instance if started.
+func StartBot(ctx context.Context, opts BotConfig) *SocketModeBot {
 	cfg, err := LoadGlobalBotConfig()
 	if err != nil {
 		log.Debug("Failed to load bot config: %v", err)
@@ -424,9 +559,16 @@ func StartBotIfConfigured(ctx context.Context, threadTracker *ThreadTracker, pla
 		return nil
 	}
 
-	cfg.ThreadTracker = threadTracker
-	cfg.PlanBasePath = planBasePath
-	cfg.ChannelID = channelID
+	cfg.ThreadTracker = opts.ThreadTracker
+	cfg.PlanBasePath = opts.PlanBasePath
+	cfg.ChannelID = opts.ChannelID
+	cfg.Queue = opts.Queue
+	cfg.Control = opts.Control
+	cfg.Roles = opts.Roles
+	cfg.DefaultRole = opts.DefaultRole
+	cfg.Authors = opts.Authors
+	cfg.Events = opts.Events
+	cfg.Debug = opts.Debug
 
 	bot := NewSocketModeBot(*cfg)
 	if bot == nil {
@@ -4,10 +4,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack/slackevents"
 )
 
 func TestNewSocketModeBot_MissingBotToken(t *testing.T) {
@@ -206,11 +209,57 @@ func TestSocketModeBot_WriteFeedback(t *testing.T) {
 	if !contains(contentStr, "Test feedback message") {
 		t.Errorf("feedback file missing message, got: %s", contentStr)
 	}
-	if !contains(contentStr, "Slack reply from U123") {
+	if !contains(contentStr, "Slack reply (by U123)") {
 		t.Errorf("feedback file missing source, got: %s", contentStr)
 	}
 }
 
+func TestSocketModeBot_WriteFeedback_Attribution(t *testing.T) {
+	tmpDir := t.TempDir()
+	eventLog := events.NewLog(filepath.Join(tmpDir, "events.jsonl"))
+	bot := &SocketModeBot{
+		planBasePath: tmpDir,
+		authors:      map[string]string{"U123": "Alice Smith"},
+		events:       eventLog,
+	}
+
+	if err := bot.writeFeedback("test-plan", "U123", "Use OAuth"); err != nil {
+		t.Fatalf("writeFeedback failed: %v", err)
+	}
+
+	fb, err := plan.LoadFeedback(&plan.Plan{Name: "test-plan", Path: filepath.Join(tmpDir, "test-plan.md")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fb.Pending) != 1 || fb.Pending[0].Author != "Alice Smith" {
+		t.Fatalf("pending feedback = %+v, want one entry by Alice Smith", fb.Pending)
+	}
+
+	evs, err := eventLog.Since(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].Type != events.TypeFeedback || evs[0].Author != "Alice Smith" || evs[0].Plan != "test-plan" {
+		t.Errorf("events = %+v, want one feedback event by Alice Smith", evs)
+	}
+}
+
+func TestSocketModeBot_DisplayName(t *testing.T) {
+	bot := &SocketModeBot{
+		authors: map[string]string{"U1": "Configured"},
+		names:   map[string]string{"U1": "From Slack", "U2": "Cached"},
+	}
+	if got := bot.displayName("U1"); got != "Configured" {
+		t.Errorf("displayName(U1) = %q, want the feedback.authors name", got)
+	}
+	if got := bot.displayName("U2"); got != "Cached" {
+		t.Errorf("displayName(U2) = %q, want the cached name", got)
+	}
+	if got := bot.displayName("U3"); got != "U3" {
+		t.Errorf("displayName(U3) = %q, want the ID without a Slack client", got)
+	}
+}
+
 func TestLoadGlobalBotConfig_FromEnv(t *testing.T) {
 	// Save and restore env vars
 	oldBot := os.Getenv("SLACK_BOT_TOKEN")
@@ -603,3 +652,40 @@ func TestWriteFeedback_Integration(t *testing.T) {
 		t.Errorf("feedback should contain message, got: %s", feedbackContent)
 	}
 }
+
+func TestSocketModeBot_HandleMessageEvent_OverrideChannel(t *testing.T) {
+	tmpDir := t.TempDir()
+	tracker, err := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Set(ThreadKey("my-plan", "#payments-team"), &ThreadInfo{ThreadTS: "100.1", ChannelID: "CPAY"})
+
+	bot := &SocketModeBot{
+		threadTracker: tracker,
+		planBasePath:  tmpDir,
+		channelID:     "C123",
+	}
+
+	// Reply in the plan's override channel thread is written to feedback
+	bot.handleMessageEvent(&slackevents.MessageEvent{
+		User: "U1", Text: "Use the v2 API", Channel: "CPAY", TimeStamp: "100.2", ThreadTimeStamp: "100.1",
+	})
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "my-plan.feedback.md"))
+	if err != nil {
+		t.Fatalf("expected feedback file: %v", err)
+	}
+	if !strings.Contains(string(data), "Use the v2 API") {
+		t.Errorf("feedback missing reply:\n%s", data)
+	}
+
+	// Same thread timestamp in an unrelated channel is ignored
+	bot.handleMessageEvent(&slackevents.MessageEvent{
+		User: "U1", Text: "Unrelated", Channel: "COTHER", TimeStamp: "100.3", ThreadTimeStamp: "100.1",
+	})
+	data, _ = os.ReadFile(filepath.Join(tmpDir, "my-plan.feedback.md"))
+	if strings.Contains(string(data), "Unrelated") {
+		t.Error("reply from unrelated channel should be ignored")
+	}
+}
@@ -0,0 +1,301 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// SlashCommand is the slash command handled by the bot.
+const SlashCommand = "/ralph"
+
+// commandUsage is shown for "/ralph help" and unknown subcommands.
+const commandUsage = "*Ralph commands:*\n" +
+	"• `/ralph status` - show queue status\n" +
+	"• `/ralph queue add <url|text>` - add a new pending plan\n" +
+	"• `/ralph pause` - pause the worker after the current iteration\n" +
+	"• `/ralph resume` - resume a paused worker\n" +
+	"• `/ralph skip <plan>` - skip a plan (stops it if running)\n" +
+	"• `/ralph unskip <plan>` - stop skipping a plan"
+
+// maxPlanTitleLen bounds plan titles derived from free text.
+const maxPlanTitleLen = 60
+
+// commandResponse is the outcome of executing a slash command.
+type commandResponse struct {
+	// Text is the reply posted to Slack.
+	Text string
+
+	// PlanName is the plan the command targeted; the reply is threaded under
+	// that plan's notification thread when one exists.
+	PlanName string
+}
+
+// handleSlashCommand processes a slash command event.
+// Commands are only accepted from the configured channel.
+func (b *SocketModeBot) handleSlashCommand(evt socketmode.Event) {
+	cmd, ok := evt.Data.(slack.SlashCommand)
+	if !ok {
+		log.Debug("Failed to cast to SlashCommand")
+		if evt.Request != nil {
+			b.client.Ack(*evt.Request)
+		}
+		return
+	}
+
+	if cmd.ChannelID != b.channelID {
+		if evt.Request != nil {
+			b.client.Ack(*evt.Request, map[string]interface{}{
+				"response_type": "ephemeral",
+				"text":          fmt.Sprintf("Ralph commands are only accepted in <#%s>.", b.channelID),
+			})
+		}
+		log.Warn("Rejected %s from unauthorized channel %s (user %s)", cmd.Command, cmd.ChannelID, cmd.UserID)
+		return
+	}
+
+	// Acknowledge immediately; the reply is posted as a message
+	if evt.Request != nil {
+		b.client.Ack(*evt.Request)
+	}
+
+	action := fmt.Sprintf("run `%s %s`", cmd.Command, strings.Join(strings.Fields(cmd.Text), " "))
+	if resp, ok := b.authorize(cmd.UserID, action, commandRole(cmd.Text)); !ok {
+		b.reply(resp)
+		return
+	}
+
+	log.Info("Slack command from %s: %s %s", cmd.UserName, cmd.Command, cmd.Text)
+	resp := b.executeCommand(cmd.UserName, cmd.Text)
+	b.reply(resp)
+	b.RefreshHome()
+}
+
+// executeCommand runs a /ralph subcommand and returns the reply.
+func (b *SocketModeBot) executeCommand(user, text string) commandResponse {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return commandResponse{Text: commandUsage}
+	}
+
+	sub := strings.ToLower(fields[0])
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), fields[0]))
+
+	switch sub {
+	case "status":
+		return b.commandStatus()
+	case "queue":
+		if len(fields) < 3 || strings.ToLower(fields[1]) != "add" {
+			return commandResponse{Text: "Usage: `/ralph queue add <url|text>`"}
+		}
+		return b.commandQueueAdd(user, strings.TrimSpace(strings.TrimPrefix(arg, fields[1])))
+	case "pause":
+		return b.commandPause(user)
+	case "resume":
+		return b.commandResume()
+	case "skip":
+		return b.commandSkip(arg, true)
+	case "unskip":
+		return b.commandSkip(arg, false)
+	default:
+		return commandResponse{Text: commandUsage}
+	}
+}
+
+// commandStatus reports the queue and control state.
+func (b *SocketModeBot) commandStatus() commandResponse {
+	if b.queue == nil {
+		return commandResponse{Text: "Queue is not available."}
+	}
+
+	status, err := b.queue.Status()
+	if err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to read queue: %v", err)}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*Queue Status*\n")
+
+	if status.CurrentPlan != "" {
+		sb.WriteString(fmt.Sprintf("*Current:* `%s`", status.CurrentPlan))
+		if status.CurrentETA != nil {
+			sb.WriteString(fmt.Sprintf(" (%s)", status.CurrentETA))
+		} else if status.CurrentTotal > 0 {
+			sb.WriteString(fmt.Sprintf(" (%d/%d tasks)", status.CurrentDone, status.CurrentTotal))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("*Current:* (none)\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("*Pending:* %d", status.PendingCount))
+	for _, name := range status.PendingPlans {
+		sb.WriteString(fmt.Sprintf("\n  • `%s`", name))
+	}
+	sb.WriteString(fmt.Sprintf("\n*Complete:* %d", status.CompleteCount))
+	if status.FailedCount > 0 {
+		sb.WriteString(fmt.Sprintf("\n*Failed:* %d", status.FailedCount))
+		for _, name := range status.FailedPlans {
+			sb.WriteString(fmt.Sprintf("\n  • `%s`", name))
+		}
+	}
+
+	if b.control != nil {
+		if state, err := b.control.Load(); err == nil {
+			if state.Paused {
+				sb.WriteString(fmt.Sprintf("\n:double_vertical_bar: *Paused* by %s", state.PausedBy))
+			}
+			if len(state.Skipped) > 0 {
+				sb.WriteString(fmt.Sprintf("\n*Skipped:* `%s`", strings.Join(state.Skipped, "`, `")))
+			}
+		}
+	}
+
+	return commandResponse{Text: sb.String()}
+}
+
+// commandQueueAdd scaffolds a new pending plan from a URL or free text.
+func (b *SocketModeBot) commandQueueAdd(user, input string) commandResponse {
+	if b.queue == nil {
+		return commandResponse{Text: "Queue is not available."}
+	}
+
+	p, err := b.queuePlan(planRequestOptions(input, fmt.Sprintf("Slack command from %s", user)))
+	if err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to add plan: %v", err)}
+	}
+
+	return commandResponse{Text: b.queuedMessage(p)}
+}
+
+// commandPause pauses the worker.
+func (b *SocketModeBot) commandPause(user string) commandResponse {
+	if b.control == nil {
+		return commandResponse{Text: "Worker control is not available."}
+	}
+	if err := b.control.Pause(user); err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to pause: %v", err)}
+	}
+	return commandResponse{Text: ":double_vertical_bar: Worker paused. It will stop after the current iteration."}
+}
+
+// commandResume resumes a paused worker.
+func (b *SocketModeBot) commandResume() commandResponse {
+	if b.control == nil {
+		return commandResponse{Text: "Worker control is not available."}
+	}
+	if err := b.control.Resume(); err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to resume: %v", err)}
+	}
+	return commandResponse{Text: ":arrow_forward: Worker resumed."}
+}
+
+// commandSkip marks (or unmarks) a plan as skipped.
+func (b *SocketModeBot) commandSkip(name string, skip bool) commandResponse {
+	verb := "skip"
+	if !skip {
+		verb = "unskip"
+	}
+	if name == "" {
+		return commandResponse{Text: fmt.Sprintf("Usage: `/ralph %s <plan>`", verb)}
+	}
+	if b.control == nil || b.queue == nil {
+		return commandResponse{Text: "Worker control is not available."}
+	}
+
+	p, err := b.queue.Find(name)
+	if err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: %v", err)}
+	}
+
+	if skip {
+		err = b.control.Skip(p.Name)
+	} else {
+		err = b.control.Unskip(p.Name)
+	}
+	if err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to %s `%s`: %v", verb, p.Name, err), PlanName: p.Name}
+	}
+
+	if skip {
+		return commandResponse{Text: fmt.Sprintf(":fast_forward: `%s` will be skipped.", p.Name), PlanName: p.Name}
+	}
+	return commandResponse{Text: fmt.Sprintf(":arrow_forward: `%s` is no longer skipped.", p.Name), PlanName: p.Name}
+}
+
+// queuePlan scaffolds a plan into the pending queue.
+func (b *SocketModeBot) queuePlan(opts plan.ScaffoldOptions) (*plan.Plan, error) {
+	return plan.Scaffold(b.queue.PendingDir(), opts)
+}
+
+// queuedMessage describes a newly queued plan and its position.
+func (b *SocketModeBot) queuedMessage(p *plan.Plan) string {
+	pending, err := b.queue.Pending()
+	if err != nil {
+		return fmt.Sprintf(":inbox_tray: Queued `%s`", p.Name)
+	}
+
+	for i, pp := range pending {
+		if pp.Name == p.Name {
+			return fmt.Sprintf(":inbox_tray: Queued `%s` (position %d of %d)", p.Name, i+1, len(pending))
+		}
+	}
+	return fmt.Sprintf(":inbox_tray: Queued `%s`", p.Name)
+}
+
+// planRequestOptions builds scaffold options from a URL or free-text request.
+func planRequestOptions(input, source string) plan.ScaffoldOptions {
+	input = strings.TrimSpace(input)
+
+	if u, err := url.Parse(input); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && !strings.ContainsAny(input, " \n") {
+		title := strings.Trim(u.Host+u.Path, "/")
+		return plan.ScaffoldOptions{
+			Title:  title,
+			Body:   fmt.Sprintf("Implement the request described at %s", input),
+			Source: fmt.Sprintf("%s (%s)", source, input),
+		}
+	}
+
+	return plan.ScaffoldOptions{
+		Title:  planTitle(input),
+		Body:   input,
+		Source: source,
+	}
+}
+
+// planTitle derives a short plan title from the first line of text.
+func planTitle(text string) string {
+	title := strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+	if len(title) <= maxPlanTitleLen {
+		return title
+	}
+
+	cut := title[:maxPlanTitleLen]
+	if i := strings.LastIndex(cut, " "); i > maxPlanTitleLen/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut)
+}
+
+// reply posts a command response, threaded under the plan's thread if one exists.
+func (b *SocketModeBot) reply(resp commandResponse) {
+	if b.api == nil {
+		return
+	}
+
+	opts := []slack.MsgOption{slack.MsgOptionText(log.Redact(resp.Text), false)}
+	if resp.PlanName != "" && b.threadTracker != nil {
+		if info := b.threadTracker.Get(resp.PlanName); info != nil && info.ThreadTS != "" {
+			opts = append(opts, slack.MsgOptionTS(info.ThreadTS))
+		}
+	}
+
+	if _, _, err := b.api.PostMessage(b.channelID, opts...); err != nil {
+		log.Debug("Failed to post command reply: %v", err)
+	}
+}
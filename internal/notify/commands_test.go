@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// setupCommandBot creates a bot with a temp queue and control store.
+func setupCommandBot(t *testing.T) (*SocketModeBot, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	queueDir := filepath.Join(tmpDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+
+	bot := &SocketModeBot{
+		channelID: "C123",
+		queue:     plan.NewQueue(queueDir),
+		control:   control.NewStore(filepath.Join(tmpDir, ".ralph", "control.json")),
+	}
+	return bot, queueDir
+}
+
+func TestExecuteCommand_Help(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	for _, text := range []string{"", "help", "bogus"} {
+		resp := bot.executeCommand("alice", text)
+		if !strings.Contains(resp.Text, "/ralph status") {
+			t.Errorf("executeCommand(%q) should show usage, got %q", text, resp.Text)
+		}
+	}
+}
+
+func TestExecuteCommand_Status(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n\n- [x] one\n- [ ] two\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "pending", "beta.md"), []byte("# Plan: Beta\n"), 0644)
+	bot.control.Pause("alice")
+	bot.control.Skip("beta")
+
+	resp := bot.executeCommand("alice", "status")
+
+	for _, want := range []string{"`alpha` (1/2 tasks)", "*Pending:* 1", "`beta`", "*Paused* by alice", "*Skipped:* `beta`"} {
+		if !strings.Contains(resp.Text, want) {
+			t.Errorf("status missing %q:\n%s", want, resp.Text)
+		}
+	}
+}
+
+func TestExecuteCommand_StatusETA(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n\n- [x] one\n- [ ] two\n"), 0644)
+
+	bot.queue.Events = events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	bot.queue.Events.Append(events.Event{Type: events.TypePlanStarted, Plan: "alpha"})
+	bot.queue.Events.Append(events.Event{Type: events.TypeIteration, Plan: "alpha", Duration: 30 * time.Minute, TasksDone: 1, TasksTotal: 2})
+
+	resp := bot.executeCommand("alice", "status")
+	if !strings.Contains(resp.Text, "`alpha` (1/2 tasks, ~30m remaining at current pace)") {
+		t.Errorf("status should include ETA:\n%s", resp.Text)
+	}
+}
+
+func TestExecuteCommand_QueueAddText(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "aaa.md"), []byte("# Plan: AAA\n"), 0644)
+
+	resp := bot.executeCommand("alice", "queue add Fix the flaky login test\nIt fails on CI about 1 in 5 runs.")
+
+	if !strings.Contains(resp.Text, "`fix-the-flaky-login-test`") || !strings.Contains(resp.Text, "position 2 of 2") {
+		t.Errorf("unexpected reply: %q", resp.Text)
+	}
+
+	content, err := os.ReadFile(filepath.Join(queueDir, "pending", "fix-the-flaky-login-test.md"))
+	if err != nil {
+		t.Fatalf("plan not created: %v", err)
+	}
+	if !strings.Contains(string(content), "It fails on CI about 1 in 5 runs.") {
+		t.Errorf("plan should contain the full request text:\n%s", content)
+	}
+	if !strings.Contains(string(content), "_Source: Slack command from alice_") {
+		t.Errorf("plan should record its source:\n%s", content)
+	}
+}
+
+func TestExecuteCommand_QueueAddURL(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+
+	resp := bot.executeCommand("alice", "queue add https://github.com/o/r/issues/42")
+	if !strings.Contains(resp.Text, "Queued") {
+		t.Fatalf("unexpected reply: %q", resp.Text)
+	}
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending plan, got %d", len(pending))
+	}
+	content, _ := os.ReadFile(filepath.Join(queueDir, "pending", pending[0].Name+".md"))
+	if !strings.Contains(string(content), "https://github.com/o/r/issues/42") {
+		t.Errorf("plan should reference the URL:\n%s", content)
+	}
+}
+
+func TestExecuteCommand_QueueAddUsage(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	resp := bot.executeCommand("alice", "queue add")
+	if !strings.Contains(resp.Text, "Usage") {
+		t.Errorf("expected usage, got %q", resp.Text)
+	}
+}
+
+func TestExecuteCommand_PauseResume(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	bot.executeCommand("alice", "pause")
+	if !bot.control.IsPaused() {
+		t.Error("expected worker to be paused")
+	}
+
+	bot.executeCommand("alice", "resume")
+	if bot.control.IsPaused() {
+		t.Error("expected worker to be resumed")
+	}
+}
+
+func TestExecuteCommand_SkipUnskip(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "beta.md"), []byte("# Plan: Beta\n"), 0644)
+
+	resp := bot.executeCommand("alice", "skip beta")
+	if resp.PlanName != "beta" {
+		t.Errorf("PlanName = %q, want beta", resp.PlanName)
+	}
+	if !bot.control.IsSkipped("beta") {
+		t.Error("expected beta to be skipped")
+	}
+
+	bot.executeCommand("alice", "unskip beta")
+	if bot.control.IsSkipped("beta") {
+		t.Error("expected beta to no longer be skipped")
+	}
+}
+
+func TestExecuteCommand_SkipUnknownPlan(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	resp := bot.executeCommand("alice", "skip nope")
+	if !strings.Contains(resp.Text, "plan not found") {
+		t.Errorf("expected not found error, got %q", resp.Text)
+	}
+	if bot.control.IsSkipped("nope") {
+		t.Error("unknown plan should not be skipped")
+	}
+}
+
+func TestExecuteCommand_NoQueue(t *testing.T) {
+	bot := &SocketModeBot{channelID: "C123"}
+
+	for _, text := range []string{"status", "queue add thing", "pause", "resume", "skip x"} {
+		resp := bot.executeCommand("alice", text)
+		if !strings.Contains(resp.Text, "not available") {
+			t.Errorf("executeCommand(%q) = %q, want not available", text, resp.Text)
+		}
+	}
+}
+
+func TestPlanTitle(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Fix login", "Fix login"},
+		{"First line\nsecond line", "First line"},
+		{"This is a very long request title that goes on and on well past the limit", "This is a very long request title that goes on and on well"},
+	}
+
+	for _, tt := range tests {
+		if got := planTitle(tt.input); got != tt.want {
+			t.Errorf("planTitle(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRequestOptions(t *testing.T) {
+	opts := planRequestOptions("https://example.com/spec/", "Slack")
+	if opts.Title != "example.com/spec" {
+		t.Errorf("Title = %q", opts.Title)
+	}
+	if !strings.Contains(opts.Source, "https://example.com/spec/") {
+		t.Errorf("Source = %q", opts.Source)
+	}
+
+	opts = planRequestOptions("Add a dark mode", "Slack")
+	if opts.Title != "Add a dark mode" || opts.Body != "Add a dark mode" || opts.Source != "Slack" {
+		t.Errorf("text opts = %+v", opts)
+	}
+}
+
+func TestHandleSlashCommand_ChannelRestricted(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	bot.handleSlashCommand(socketmode.Event{
+		Type: socketmode.EventTypeSlashCommand,
+		Data: slack.SlashCommand{Command: SlashCommand, Text: "pause", ChannelID: "COTHER", UserName: "mallory"},
+	})
+	if bot.control.IsPaused() {
+		t.Error("command from another channel should be rejected")
+	}
+
+	bot.handleSlashCommand(socketmode.Event{
+		Type: socketmode.EventTypeSlashCommand,
+		Data: slack.SlashCommand{Command: SlashCommand, Text: "pause", ChannelID: "C123", UserName: "alice"},
+	})
+	if !bot.control.IsPaused() {
+		t.Error("command from the configured channel should be executed")
+	}
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+)
+
+// maxDiffPreviewBytes keeps a diff preview within Slack's 3000 character
+// limit on section text.
+const maxDiffPreviewBytes = 2800
+
+// DiffPreview is one iteration's committed changes (slack.diff_preview).
+type DiffPreview struct {
+	// Iteration is the iteration that made the commit.
+	Iteration int
+
+	// Commit is the iteration's commit SHA.
+	Commit string
+
+	// Files, Added, and Deleted count the files and lines changed.
+	Files   int
+	Added   int
+	Deleted int
+
+	// Diff is the unified diff, already cut to the configured number of
+	// lines (empty = file summary only). Omitted is the number of lines cut.
+	Diff    string
+	Omitted int
+
+	// URL links the commit on the remote (empty = unknown).
+	URL string
+}
+
+// DiffPreviewSender is implemented by notifiers that can post a diff preview
+// in a plan's thread.
+type DiffPreviewSender interface {
+	// DiffPreview posts an iteration's changes as a reply in the plan's thread.
+	DiffPreview(p *plan.Plan, d DiffPreview) error
+}
+
+// diffPreviewTexts formats a diff preview: the headline with the file and
+// line counts, the diff as a code block, and the commit link. Texts without
+// data are left out.
+func diffPreviewTexts(d DiffPreview) []string {
+	texts := []string{i18n.Sprintf(":mag: *Iteration %d Changes* `%.8s`\n%d file(s), +%d −%d lines", d.Iteration, d.Commit, d.Files, d.Added, d.Deleted)}
+
+	if diff := strings.TrimRight(d.Diff, "\n"); diff != "" {
+		omitted := d.Omitted
+		if len(diff) > maxDiffPreviewBytes {
+			cut := strings.LastIndex(diff[:maxDiffPreviewBytes], "\n")
+			if cut < 0 {
+				cut = maxDiffPreviewBytes
+			}
+			omitted += strings.Count(diff[cut:], "\n")
+			diff = diff[:cut]
+		}
+		// A fence inside the diff would end the code block early
+		text := "```" + strings.ReplaceAll(diff, "```", "`\u200b``") + "```"
+		if omitted > 0 {
+			text += i18n.Sprintf("\n_… %d more line(s)_", omitted)
+		}
+		texts = append(texts, text)
+	}
+
+	if d.URL != "" {
+		texts = append(texts, i18n.Sprintf("<%s|View commit>", d.URL))
+	}
+	return texts
+}
+
+// DiffPreview posts an iteration's changes in the plan's thread. Plans
+// without a thread get no preview, so previews never clutter the channel.
+func (s *SlackNotifier) DiffPreview(p *plan.Plan, d DiffPreview) error {
+	if s.threadTracker == nil {
+		return nil
+	}
+	if _, key := s.channelFor(p); s.threadTracker.Get(key) == nil {
+		return nil
+	}
+
+	var blocks []slack.Block
+	for _, text := range diffPreviewTexts(d) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	}
+	s.postMessageInThread(p, blocks)
+	return nil
+}
+
+// DiffPreview passes a diff preview through to the wrapped notifier, within
+// the limits.
+func (r *RateLimitNotifier) DiffPreview(p *plan.Plan, d DiffPreview) error {
+	sender, ok := r.inner.(DiffPreviewSender)
+	if !ok {
+		return nil
+	}
+	if !r.allow(kindDiff, p.Name, d.Commit, "") {
+		return nil
+	}
+	return sender.DiffPreview(p, d)
+}
+
+var _ DiffPreviewSender = (*SlackNotifier)(nil)
+var _ DiffPreviewSender = (*RateLimitNotifier)(nil)
@@ -0,0 +1,297 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// Digest modes for slack.digest.
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// DigestPeriod returns the interval for a digest mode.
+// Returns false if the mode is empty or unknown (per-event notifications).
+func DigestPeriod(mode string) (time.Duration, bool) {
+	switch mode {
+	case DigestHourly:
+		return time.Hour, true
+	case DigestDaily:
+		return 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+// DigestSender is implemented by notifiers that can deliver a digest summary.
+type DigestSender interface {
+	// Digest sends a single summary message for a digest period.
+	Digest(summary *DigestSummary) error
+}
+
+// DigestPlan summarizes one plan's activity during a digest period.
+type DigestPlan struct {
+	// Name is the plan name.
+	Name string
+
+	// Iterations is the number of iterations run during the period.
+	Iterations int
+
+	// LastIteration and MaxIterations describe the latest iteration seen.
+	LastIteration int
+	MaxIterations int
+
+	// PRURL is the pull request URL for completed plans.
+	PRURL string
+
+	// Message is the blocker description or error text.
+	Message string
+}
+
+// DigestSummary is the activity summary for one digest period.
+type DigestSummary struct {
+	Since time.Time
+	Until time.Time
+
+	// Started lists plans that started during the period.
+	Started []string
+
+	// Progressed lists plans that ran iterations during the period.
+	Progressed []DigestPlan
+
+	// Completed lists plans that completed during the period.
+	Completed []DigestPlan
+
+	// Blocked lists plans that reported a blocker during the period.
+	Blocked []DigestPlan
+
+	// Errored lists plans that failed during the period.
+	Errored []DigestPlan
+}
+
+// Empty returns true if nothing happened during the period.
+func (s *DigestSummary) Empty() bool {
+	return len(s.Started) == 0 && len(s.Progressed) == 0 && len(s.Completed) == 0 &&
+		len(s.Blocked) == 0 && len(s.Errored) == 0
+}
+
+// BuildDigest summarizes events for the period [since, until].
+// Plans appear in the order they were first seen.
+func BuildDigest(evs []events.Event, since, until time.Time) *DigestSummary {
+	summary := &DigestSummary{Since: since, Until: until}
+	progressed := make(map[string]int)
+
+	for _, e := range evs {
+		if e.Time.Before(since) || e.Time.After(until) {
+			continue
+		}
+
+		switch e.Type {
+		case events.TypePlanStarted:
+			summary.Started = append(summary.Started, e.Plan)
+		case events.TypeIteration:
+			i, ok := progressed[e.Plan]
+			if !ok {
+				summary.Progressed = append(summary.Progressed, DigestPlan{Name: e.Plan})
+				i = len(summary.Progressed) - 1
+				progressed[e.Plan] = i
+			}
+			summary.Progressed[i].Iterations++
+			summary.Progressed[i].LastIteration = e.Iteration
+			summary.Progressed[i].MaxIterations = e.MaxIterations
+		case events.TypePlanCompleted:
+			summary.Completed = append(summary.Completed, DigestPlan{Name: e.Plan, PRURL: e.PRURL})
+		case events.TypeBlocker:
+			summary.Blocked = append(summary.Blocked, DigestPlan{Name: e.Plan, Message: e.Message})
+		case events.TypePlanError:
+			summary.Errored = append(summary.Errored, DigestPlan{Name: e.Plan, Message: e.Message})
+		}
+	}
+
+	return summary
+}
+
+// formatDigest renders a digest summary as Slack mrkdwn.
+func formatDigest(s *DigestSummary) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf(":newspaper: *Ralph Digest* (%s – %s)",
+		s.Since.Format("Jan 2 15:04"), s.Until.Format("Jan 2 15:04")))
+
+	if len(s.Started) > 0 {
+		sb.WriteString(i18n.T("\n\n*Started:*"))
+		for _, name := range s.Started {
+			sb.WriteString(fmt.Sprintf("\n• `%s`", name))
+		}
+	}
+
+	if len(s.Progressed) > 0 {
+		sb.WriteString(i18n.T("\n\n*Progressed:*"))
+		for _, p := range s.Progressed {
+			sb.WriteString(i18n.Sprintf("\n• `%s` – %d iteration(s), now at %d/%d", p.Name, p.Iterations, p.LastIteration, p.MaxIterations))
+		}
+	}
+
+	if len(s.Completed) > 0 {
+		sb.WriteString(i18n.T("\n\n*Completed:*"))
+		for _, p := range s.Completed {
+			line := fmt.Sprintf("\n• `%s`", p.Name)
+			if p.PRURL != "" {
+				line += i18n.Sprintf(" <%s|View PR>", p.PRURL)
+			}
+			sb.WriteString(line)
+		}
+	}
+
+	if len(s.Blocked) > 0 {
+		sb.WriteString(i18n.T("\n\n*Blocked:*"))
+		for _, p := range s.Blocked {
+			sb.WriteString(fmt.Sprintf("\n• `%s` – %s", p.Name, truncate(p.Message, 200)))
+		}
+	}
+
+	if len(s.Errored) > 0 {
+		sb.WriteString(i18n.T("\n\n*Errors:*"))
+		for _, p := range s.Errored {
+			sb.WriteString(fmt.Sprintf("\n• `%s` – %s", p.Name, truncate(p.Message, 200)))
+		}
+	}
+
+	return sb.String()
+}
+
+// truncate shortens s to at most n bytes, adding an ellipsis.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// DigestNotifier buffers start, iteration, completion, and error notifications
+// and sends a single summary per period instead. The worker records those events
+// in the events log, so the digest is built from there. Blockers and urgent
+// feedback need a human and are still sent immediately.
+type DigestNotifier struct {
+	inner  Notifier
+	log    *events.Log
+	period time.Duration
+
+	// started is the digest cursor when no digest has been sent yet
+	started time.Time
+
+	// now returns the current time (for testing)
+	now func() time.Time
+}
+
+// NewDigestNotifier wraps inner so per-event messages are replaced by a digest
+// sent every period.
+func NewDigestNotifier(inner Notifier, eventLog *events.Log, period time.Duration) *DigestNotifier {
+	return &DigestNotifier{
+		inner:   inner,
+		log:     eventLog,
+		period:  period,
+		started: time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Start is buffered in the events log.
+func (d *DigestNotifier) Start(p *plan.Plan) error { return nil }
+
+// Complete is buffered in the events log.
+func (d *DigestNotifier) Complete(p *plan.Plan, c Completion) error { return nil }
+
+// Error is buffered in the events log.
+func (d *DigestNotifier) Error(p *plan.Plan, err error) error { return nil }
+
+// Iteration is buffered in the events log.
+func (d *DigestNotifier) Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error {
+	return nil
+}
+
+// StageChange is buffered in the events log.
+func (d *DigestNotifier) StageChange(p *plan.Plan, from, to string) error { return nil }
+
+// Blocker is sent immediately.
+func (d *DigestNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
+	return d.inner.Blocker(p, blocker)
+}
+
+// BlockerEscalation is sent immediately.
+func (d *DigestNotifier) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	return d.inner.BlockerEscalation(p, blocker, unresolved, mention)
+}
+
+// ApprovalRequest is sent immediately.
+func (d *DigestNotifier) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	return d.inner.ApprovalRequest(p, c, timeout)
+}
+
+// UrgentFeedback is sent immediately.
+func (d *DigestNotifier) UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error {
+	return d.inner.UrgentFeedback(p, entries)
+}
+
+// Run sends a digest every period until ctx is cancelled.
+func (d *DigestNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.SendDigest(); err != nil {
+				log.Debug("Failed to send digest: %v", err)
+			}
+		}
+	}
+}
+
+// SendDigest sends a summary of events since the last digest.
+// Nothing is sent if there was no activity. The send is recorded in the
+// events log so the next digest picks up where this one left off.
+func (d *DigestNotifier) SendDigest() error {
+	since := d.started
+	last, err := d.log.Last(events.TypeDigestSent)
+	if err != nil {
+		return fmt.Errorf("reading last digest: %w", err)
+	}
+	if last != nil {
+		since = last.Time
+	}
+
+	until := d.now()
+	evs, err := d.log.Since(since)
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+
+	summary := BuildDigest(evs, since, until)
+	if summary.Empty() {
+		return nil
+	}
+
+	sender, ok := d.inner.(DigestSender)
+	if !ok {
+		return nil
+	}
+	if err := sender.Digest(summary); err != nil {
+		return err
+	}
+
+	return d.log.Append(events.Event{Time: until, Type: events.TypeDigestSent})
+}
+
+// Ensure DigestNotifier implements Notifier.
+var _ Notifier = (*DigestNotifier)(nil)
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// digestRecorder is a Notifier and DigestSender that records calls.
+type digestRecorder struct {
+	NoopNotifier
+	digests   []*DigestSummary
+	blockers  int
+	approvals int
+	err       error
+}
+
+func (r *digestRecorder) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
+	r.blockers++
+	return nil
+}
+
+func (r *digestRecorder) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	r.blockers++
+	return nil
+}
+
+func (r *digestRecorder) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	r.approvals++
+	return nil
+}
+
+func (r *digestRecorder) Digest(summary *DigestSummary) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.digests = append(r.digests, summary)
+	return nil
+}
+
+func TestDigestPeriod(t *testing.T) {
+	tests := []struct {
+		mode string
+		want time.Duration
+		ok   bool
+	}{
+		{"hourly", time.Hour, true},
+		{"daily", 24 * time.Hour, true},
+		{"", 0, false},
+		{"weekly", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := DigestPeriod(tt.mode)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("DigestPeriod(%q) = %v, %v; want %v, %v", tt.mode, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	evs := []events.Event{
+		{Time: base.Add(-time.Minute), Type: events.TypeIteration, Plan: "old", Iteration: 1},
+		{Time: base.Add(time.Minute), Type: events.TypePlanStarted, Plan: "alpha"},
+		{Time: base.Add(2 * time.Minute), Type: events.TypeIteration, Plan: "alpha", Iteration: 1, MaxIterations: 30},
+		{Time: base.Add(3 * time.Minute), Type: events.TypeIteration, Plan: "alpha", Iteration: 2, MaxIterations: 30},
+		{Time: base.Add(4 * time.Minute), Type: events.TypeBlocker, Plan: "alpha", Message: "need API key"},
+		{Time: base.Add(5 * time.Minute), Type: events.TypePlanCompleted, Plan: "beta", PRURL: "https://github.com/o/r/pull/2"},
+		{Time: base.Add(6 * time.Minute), Type: events.TypePlanError, Plan: "gamma", Message: "boom"},
+		{Time: base.Add(2 * time.Hour), Type: events.TypeIteration, Plan: "late", Iteration: 1},
+	}
+
+	s := BuildDigest(evs, base, base.Add(time.Hour))
+
+	if len(s.Started) != 1 || s.Started[0] != "alpha" {
+		t.Errorf("Started = %v", s.Started)
+	}
+	if len(s.Progressed) != 1 {
+		t.Fatalf("Progressed = %+v, want only alpha", s.Progressed)
+	}
+	if p := s.Progressed[0]; p.Name != "alpha" || p.Iterations != 2 || p.LastIteration != 2 || p.MaxIterations != 30 {
+		t.Errorf("Progressed[0] = %+v", p)
+	}
+	if len(s.Completed) != 1 || s.Completed[0].PRURL != "https://github.com/o/r/pull/2" {
+		t.Errorf("Completed = %+v", s.Completed)
+	}
+	if len(s.Blocked) != 1 || s.Blocked[0].Message != "need API key" {
+		t.Errorf("Blocked = %+v", s.Blocked)
+	}
+	if len(s.Errored) != 1 || s.Errored[0].Name != "gamma" {
+		t.Errorf("Errored = %+v", s.Errored)
+	}
+
+	text := formatDigest(s)
+	for _, want := range []string{"*Ralph Digest*", "`alpha` – 2 iteration(s), now at 2/30", "<https://github.com/o/r/pull/2|View PR>", "need API key", "boom"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("digest text missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildDigest_Empty(t *testing.T) {
+	s := BuildDigest(nil, time.Now().Add(-time.Hour), time.Now())
+	if !s.Empty() {
+		t.Error("digest with no events should be empty")
+	}
+}
+
+func TestDigestNotifier_BuffersAndPassesThrough(t *testing.T) {
+	inner := &digestRecorder{}
+	d := NewDigestNotifier(inner, events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName)), time.Hour)
+	p := &plan.Plan{Name: "alpha"}
+
+	d.Start(p)
+	d.Iteration(p, 1, 30, nil)
+	d.Complete(p, Completion{})
+	d.Error(p, errors.New("boom"))
+	if len(inner.digests) != 0 {
+		t.Error("buffered events should not send anything")
+	}
+
+	d.Blocker(p, &runner.Blocker{Description: "help"})
+	d.BlockerEscalation(p, &runner.Blocker{Description: "help"}, time.Hour, "@here")
+	if inner.blockers != 2 {
+		t.Errorf("blockers = %d, want 2 (sent immediately)", inner.blockers)
+	}
+	d.ApprovalRequest(p, Completion{}, time.Hour)
+	if inner.approvals != 1 {
+		t.Errorf("approvals = %d, want 1 (sent immediately)", inner.approvals)
+	}
+}
+
+func TestDigestNotifier_SendDigest(t *testing.T) {
+	eventLog := events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	inner := &digestRecorder{}
+	d := NewDigestNotifier(inner, eventLog, time.Hour)
+
+	base := time.Now()
+	d.started = base.Add(-time.Hour)
+	d.now = func() time.Time { return base }
+
+	// No activity: nothing sent, nothing recorded
+	if err := d.SendDigest(); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.digests) != 0 {
+		t.Fatal("empty digest should not be sent")
+	}
+
+	eventLog.Append(events.Event{Time: base.Add(-30 * time.Minute), Type: events.TypePlanCompleted, Plan: "alpha"})
+	if err := d.SendDigest(); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.digests) != 1 || len(inner.digests[0].Completed) != 1 {
+		t.Fatalf("digests = %+v, want one with alpha completed", inner.digests)
+	}
+
+	last, _ := eventLog.Last(events.TypeDigestSent)
+	if last == nil || !last.Time.Equal(base) {
+		t.Fatalf("digest_sent event = %+v, want time %v", last, base)
+	}
+
+	// Next digest starts after the last one
+	d.now = func() time.Time { return base.Add(time.Hour) }
+	if err := d.SendDigest(); err != nil {
+		t.Fatal(err)
+	}
+	if len(inner.digests) != 1 {
+		t.Error("already-digested events should not be sent again")
+	}
+}
+
+func TestDigestNotifier_SendDigestError(t *testing.T) {
+	eventLog := events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	inner := &digestRecorder{err: errors.New("slack down")}
+	d := NewDigestNotifier(inner, eventLog, time.Hour)
+	d.started = time.Now().Add(-time.Hour)
+
+	eventLog.Append(events.Event{Type: events.TypePlanStarted, Plan: "alpha"})
+	if err := d.SendDigest(); err == nil {
+		t.Fatal("expected error from sender")
+	}
+
+	// Failed sends are not recorded, so the events are retried next period
+	if last, _ := eventLog.Last(events.TypeDigestSent); last != nil {
+		t.Error("failed digest should not be recorded")
+	}
+}
@@ -0,0 +1,184 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// maxRecentCompletions is the number of recent completions shown on the Home tab.
+const maxRecentCompletions = 5
+
+// progressBarWidth is the number of segments in the Home tab progress bar.
+const progressBarWidth = 20
+
+// handleAppHomeOpened publishes the Home tab for a user and remembers them
+// so the view can be refreshed when the queue changes.
+func (b *SocketModeBot) handleAppHomeOpened(ev *slackevents.AppHomeOpenedEvent) {
+	if ev.Tab != "" && ev.Tab != "home" {
+		return
+	}
+	if _, ok := b.authorize(ev.User, "view the Home tab", RoleViewer); !ok {
+		return
+	}
+
+	b.homeMu.Lock()
+	if b.homeUsers == nil {
+		b.homeUsers = make(map[string]bool)
+	}
+	b.homeUsers[ev.User] = true
+	b.homeMu.Unlock()
+
+	b.publishHome(ev.User)
+}
+
+// RefreshHome republishes the Home tab for every user who has opened it.
+// Call this when the queue or a plan's progress changes.
+func (b *SocketModeBot) RefreshHome() {
+	if b == nil {
+		return
+	}
+
+	b.homeMu.Lock()
+	users := make([]string, 0, len(b.homeUsers))
+	for user := range b.homeUsers {
+		users = append(users, user)
+	}
+	b.homeMu.Unlock()
+
+	for _, user := range users {
+		b.publishHome(user)
+	}
+}
+
+// publishHome publishes the Home tab view for a single user.
+func (b *SocketModeBot) publishHome(userID string) {
+	if b.api == nil {
+		return
+	}
+
+	view := slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: b.homeBlocks()},
+	}
+	if _, err := b.api.PublishView(userID, view, ""); err != nil {
+		log.Debug("Failed to publish Home tab for %s: %v", userID, err)
+	}
+}
+
+// homeBlocks builds the Home tab blocks from the queue and thread tracker.
+func (b *SocketModeBot) homeBlocks() []slack.Block {
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Ralph Queue", false, false)),
+	}
+
+	if b.queue == nil {
+		return append(blocks, markdownSection("Queue is not available."))
+	}
+
+	status, err := b.queue.Status()
+	if err != nil {
+		return append(blocks, markdownSection(fmt.Sprintf(":x: Failed to read queue: %v", err)))
+	}
+
+	counts := fmt.Sprintf("*Pending:* %d    *Current:* %d    *Complete:* %d",
+		status.PendingCount, status.CurrentCount, status.CompleteCount)
+	if status.FailedCount > 0 {
+		counts += fmt.Sprintf("    *Failed:* %d", status.FailedCount)
+	}
+	if b.control != nil && b.control.IsPaused() {
+		counts += "\n:double_vertical_bar: *Worker paused*"
+	}
+	blocks = append(blocks, markdownSection(counts), slack.NewDividerBlock())
+
+	// Current plan with progress bar
+	current, _ := b.queue.CurrentLive()
+	if current != nil {
+		text := fmt.Sprintf("*Current plan:* `%s`\n%s", current.Name, progressBar(status.CurrentDone, status.CurrentTotal))
+		if status.CurrentETA != nil {
+			text += "\n" + status.CurrentETA.String()
+		}
+		if current.Branch != "" && b.remote != nil {
+			text += fmt.Sprintf("\nBranch: <%s|%s>", b.remote.BranchURL(current.Branch), current.Branch)
+		} else if current.Branch != "" {
+			text += fmt.Sprintf("\nBranch: `%s`", current.Branch)
+		}
+		if progress, err := plan.LoadProgress(current); err == nil {
+			if next := progress.NextStep(); next != "" {
+				text += "\n*Next:* " + truncate(strings.ReplaceAll(next, "\n", " "), 200)
+			}
+		}
+		blocks = append(blocks, markdownSection(text))
+	} else {
+		blocks = append(blocks, markdownSection("*Current plan:* (none)"))
+	}
+
+	if len(status.PendingPlans) > 0 {
+		var sb strings.Builder
+		sb.WriteString("*Up next:*")
+		for i, name := range status.PendingPlans {
+			sb.WriteString(fmt.Sprintf("\n%d. `%s`", i+1, name))
+		}
+		blocks = append(blocks, markdownSection(sb.String()))
+	}
+
+	// Recent completions with PR links
+	if recent := b.recentCompletions(); len(recent) > 0 {
+		var sb strings.Builder
+		sb.WriteString("*Recently completed:*")
+		for _, info := range recent {
+			line := fmt.Sprintf("\n• `%s` (%s)", info.PlanName, info.CompletedAt.Format("Jan 2 15:04"))
+			if info.PRURL != "" {
+				line += fmt.Sprintf(" <%s|View PR>", info.PRURL)
+			}
+			sb.WriteString(line)
+		}
+		blocks = append(blocks, slack.NewDividerBlock(), markdownSection(sb.String()))
+	}
+
+	return blocks
+}
+
+// recentCompletions returns the most recently completed plans, newest first.
+func (b *SocketModeBot) recentCompletions() []*ThreadInfo {
+	if b.threadTracker == nil {
+		return nil
+	}
+
+	var completed []*ThreadInfo
+	for _, info := range b.threadTracker.List() {
+		if !info.CompletedAt.IsZero() {
+			completed = append(completed, info)
+		}
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].CompletedAt.After(completed[j].CompletedAt)
+	})
+
+	if len(completed) > maxRecentCompletions {
+		completed = completed[:maxRecentCompletions]
+	}
+	return completed
+}
+
+// progressBar renders a text progress bar such as "▓▓▓▓░░░░ 2/4 tasks (50%)".
+func progressBar(done, total int) string {
+	if total == 0 {
+		return "_No tasks yet_"
+	}
+
+	filled := done * progressBarWidth / total
+	bar := strings.Repeat("▓", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("`%s` %d/%d tasks (%d%%)", bar, done, total, done*100/total)
+}
+
+// markdownSection creates a section block with markdown text.
+func markdownSection(text string) slack.Block {
+	return slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil)
+}
@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// homeText renders the Home tab blocks as JSON for substring checks.
+func homeText(t *testing.T, bot *SocketModeBot) string {
+	t.Helper()
+	data, err := json.Marshal(bot.homeBlocks())
+	if err != nil {
+		t.Fatalf("marshaling blocks: %v", err)
+	}
+	return string(data)
+}
+
+func TestHomeBlocks(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n\n- [x] one\n- [ ] two\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.progress.md"), []byte("### Iteration 1: one\n**Next:** two\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "pending", "beta.md"), []byte("# Plan: Beta\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "complete", "gamma.md"), []byte("# Plan: Gamma\n"), 0644)
+
+	tracker, _ := NewThreadTracker(filepath.Join(t.TempDir(), "threads.json"))
+	tracker.MarkComplete("gamma", "https://github.com/o/r/pull/7")
+	bot.threadTracker = tracker
+
+	text := homeText(t, bot)
+
+	for _, want := range []string{
+		"*Pending:* 1",
+		"*Current:* 1",
+		"*Complete:* 1",
+		"`alpha`",
+		"1/2 tasks (50%)",
+		"*Next:* two",
+		"1. `beta`",
+		"`gamma`",
+		"https://github.com/o/r/pull/7|View PR",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("home view missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHomeBlocks_LivePlan(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n\n- [ ] one\n- [ ] two\n"), 0644)
+
+	// The worktree copy the agent is updating is ahead of plans/current
+	live := filepath.Join(t.TempDir(), "alpha.md")
+	os.WriteFile(live, []byte("# Plan: Alpha\n\n- [x] one\n- [ ] two\n"), 0644)
+	os.WriteFile(filepath.Join(filepath.Dir(live), "alpha.progress.md"), []byte("### Iteration 1: one\n**Next:** two\n"), 0644)
+	bot.queue.Live = func(p *plan.Plan) *plan.Plan {
+		if updated, err := plan.Load(live); err == nil {
+			return updated
+		}
+		return p
+	}
+
+	text := homeText(t, bot)
+	for _, want := range []string{"1/2 tasks (50%)", "*Next:* two"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("home view missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHomeBlocks_BarMatchesETA(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	// plans/current hasn't been synced from the worktree since the run started
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n\n- [ ] one\n- [ ] two\n- [ ] three\n- [ ] four\n"), 0644)
+
+	bot.queue.Events = events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	bot.queue.Events.Append(events.Event{Type: events.TypePlanStarted, Plan: "alpha"})
+	bot.queue.Events.Append(events.Event{Type: events.TypeIteration, Plan: "alpha", Duration: 30 * time.Minute, TasksDone: 2, TasksTotal: 4})
+
+	text := homeText(t, bot)
+	for _, want := range []string{"2/4 tasks (50%)", "2/4 tasks, ~30m remaining"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("home view missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestHomeBlocks_Paused(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+	bot.control.Pause("alice")
+
+	text := homeText(t, bot)
+	if !strings.Contains(text, "Worker paused") {
+		t.Errorf("home view should show paused state:\n%s", text)
+	}
+	if !strings.Contains(text, "(none)") {
+		t.Errorf("home view should show no current plan:\n%s", text)
+	}
+}
+
+func TestHomeBlocks_BranchLink(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n**Branch:** feat/alpha\n"), 0644)
+	bot.remote = &git.RemoteInfo{Provider: git.ProviderGitLab, Scheme: "https", Host: "gitlab.com", Path: "acme/app"}
+
+	if text := homeText(t, bot); !strings.Contains(text, "Branch: \\u003chttps://gitlab.com/acme/app/-/tree/feat/alpha|feat/alpha\\u003e") {
+		t.Errorf("home view should link the branch:\n%s", text)
+	}
+}
+
+func TestHomeBlocks_NoQueue(t *testing.T) {
+	bot := &SocketModeBot{}
+	if text := homeText(t, bot); !strings.Contains(text, "not available") {
+		t.Errorf("expected not available message:\n%s", text)
+	}
+}
+
+func TestRecentCompletions(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+	tracker, _ := NewThreadTracker(filepath.Join(t.TempDir(), "threads.json"))
+	bot.threadTracker = tracker
+
+	base := time.Now()
+	for i := 0; i < maxRecentCompletions+2; i++ {
+		name := string(rune('a' + i))
+		tracker.Set(name, &ThreadInfo{ThreadTS: name, CompletedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	tracker.Set("in-progress", &ThreadInfo{ThreadTS: "x"})
+
+	recent := bot.recentCompletions()
+	if len(recent) != maxRecentCompletions {
+		t.Fatalf("len(recent) = %d, want %d", len(recent), maxRecentCompletions)
+	}
+	if recent[0].PlanName != string(rune('a'+maxRecentCompletions+1)) {
+		t.Errorf("newest completion should be first, got %s", recent[0].PlanName)
+	}
+	for _, info := range recent {
+		if info.PlanName == "in-progress" {
+			t.Error("in-progress plans should not be listed")
+		}
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	if got := progressBar(0, 0); got != "_No tasks yet_" {
+		t.Errorf("progressBar(0, 0) = %q", got)
+	}
+
+	got := progressBar(1, 4)
+	if !strings.Contains(got, strings.Repeat("▓", 5)+strings.Repeat("░", 15)) || !strings.Contains(got, "1/4 tasks (25%)") {
+		t.Errorf("progressBar(1, 4) = %q", got)
+	}
+}
+
+func TestHandleAppHomeOpened_RemembersUser(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	bot.handleAppHomeOpened(&slackevents.AppHomeOpenedEvent{User: "U1", Tab: "home"})
+	bot.handleAppHomeOpened(&slackevents.AppHomeOpenedEvent{User: "U2", Tab: "messages"})
+
+	if !bot.homeUsers["U1"] {
+		t.Error("user who opened Home should be remembered")
+	}
+	if bot.homeUsers["U2"] {
+		t.Error("opening the messages tab should be ignored")
+	}
+
+	// Refresh without an API client is a no-op
+	bot.RefreshHome()
+	var nilBot *SocketModeBot
+	nilBot.RefreshHome()
+}
@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// CreatePlanCallbackID is the callback ID of the "Create Ralph plan" message shortcut.
+// Configure a message shortcut with this callback ID in the Slack app settings.
+const CreatePlanCallbackID = "ralph_create_plan"
+
+// mentionRegex matches user mentions such as <@U0123ABCD>.
+var mentionRegex = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// handleAppMention turns a message mentioning the bot into a pending plan.
+// Mentions inside tracked plan threads are left to the feedback handler.
+func (b *SocketModeBot) handleAppMention(ev *slackevents.AppMentionEvent) {
+	if ev.BotID != "" || ev.Channel != b.channelID {
+		return
+	}
+
+	if ev.ThreadTimeStamp != "" && b.findPlanByThread(ev.ThreadTimeStamp) != "" {
+		return
+	}
+
+	text := strings.TrimSpace(mentionRegex.ReplaceAllString(ev.Text, ""))
+
+	var thread []string
+	if ev.ThreadTimeStamp != "" {
+		thread = b.threadMessages(ev.Channel, ev.ThreadTimeStamp, ev.TimeStamp)
+	}
+
+	// Reply in the thread the mention belongs to
+	replyTS := ev.ThreadTimeStamp
+	if replyTS == "" {
+		replyTS = ev.TimeStamp
+	}
+	if resp, ok := b.authorize(ev.User, "create plans", RoleOperator); !ok {
+		b.replyInThread(replyTS, resp)
+		return
+	}
+
+	user := b.displayName(ev.User)
+	resp := b.planFromSlack(user, text, thread, b.permalink(ev.Channel, ev.TimeStamp))
+	b.replyInThread(replyTS, resp)
+	b.RefreshHome()
+}
+
+// handleInteractive processes interactive payloads such as message shortcuts
+// and approval buttons.
+func (b *SocketModeBot) handleInteractive(evt socketmode.Event) {
+	callback, ok := evt.Data.(slack.InteractionCallback)
+	if evt.Request != nil {
+		b.client.Ack(*evt.Request)
+	}
+	if !ok {
+		log.Debug("Failed to cast to InteractionCallback")
+		return
+	}
+
+	if callback.Type == slack.InteractionTypeBlockActions {
+		for _, action := range callback.ActionCallback.BlockActions {
+			if action.ActionID == ApproveActionID || action.ActionID == RejectActionID {
+				b.handleApprovalAction(callback, action)
+			}
+		}
+		return
+	}
+
+	if callback.Type != slack.InteractionTypeMessageAction || callback.CallbackID != CreatePlanCallbackID {
+		return
+	}
+
+	if callback.Channel.ID != b.channelID {
+		b.postEphemeral(callback.Channel.ID, callback.User.ID, fmt.Sprintf("Ralph plans can only be created from <#%s>.", b.channelID))
+		log.Warn("Rejected plan shortcut from unauthorized channel %s (user %s)", callback.Channel.ID, callback.User.ID)
+		return
+	}
+
+	msg := callback.Message
+	threadTS := msg.ThreadTimestamp
+	replyTS := threadTS
+	if replyTS == "" {
+		replyTS = msg.Timestamp
+	}
+	if resp, ok := b.authorize(callback.User.ID, "create plans", RoleOperator); !ok {
+		b.replyInThread(replyTS, resp)
+		return
+	}
+
+	text := msg.Text
+	var thread []string
+	if threadTS != "" {
+		thread = b.threadMessages(callback.Channel.ID, threadTS, msg.Timestamp)
+	}
+
+	user := callback.User.Name
+	if user == "" {
+		user = b.displayName(callback.User.ID)
+	}
+
+	resp := b.planFromSlack(user, text, thread, b.permalink(callback.Channel.ID, msg.Timestamp))
+	b.replyInThread(replyTS, resp)
+	b.RefreshHome()
+}
+
+// planFromSlack scaffolds a pending plan from a Slack message and optional thread.
+// text is the triggering message; thread holds the thread's messages in order.
+// The plan title comes from text if present, otherwise from the first thread message.
+func (b *SocketModeBot) planFromSlack(user, text string, thread []string, link string) commandResponse {
+	if b.queue == nil {
+		return commandResponse{Text: "Queue is not available."}
+	}
+
+	text = strings.TrimSpace(text)
+	body := strings.TrimSpace(strings.Join(thread, "\n\n"))
+
+	titleSource := text
+	if titleSource == "" {
+		titleSource = body
+	}
+	if titleSource == "" {
+		return commandResponse{Text: "Nothing to plan: the message is empty."}
+	}
+
+	opts := planRequestOptions(titleSource, fmt.Sprintf("Slack message from %s", user))
+	if body != "" {
+		if text != "" {
+			opts.Body = text + "\n\nThread:\n\n" + body
+		} else {
+			opts.Body = body
+		}
+	}
+	if link != "" {
+		opts.Source = fmt.Sprintf("%s (%s)", opts.Source, link)
+	}
+
+	p, err := b.queuePlan(opts)
+	if err != nil {
+		return commandResponse{Text: fmt.Sprintf(":x: Failed to create plan: %v", err)}
+	}
+
+	log.Info("Created plan %s from Slack message by %s", p.Name, user)
+	return commandResponse{Text: b.queuedMessage(p)}
+}
+
+// threadMessages returns the text of a thread's messages, oldest first.
+// The message with timestamp excludeTS (typically the bot mention) is omitted.
+func (b *SocketModeBot) threadMessages(channelID, threadTS, excludeTS string) []string {
+	if b.api == nil {
+		return nil
+	}
+
+	var texts []string
+	cursor := ""
+	for {
+		msgs, hasMore, next, err := b.api.GetConversationReplies(&slack.GetConversationRepliesParameters{
+			ChannelID: channelID,
+			Timestamp: threadTS,
+			Cursor:    cursor,
+		})
+		if err != nil {
+			log.Debug("Failed to fetch thread %s: %v", threadTS, err)
+			return texts
+		}
+
+		for _, m := range msgs {
+			if m.Timestamp == excludeTS || m.BotID != "" {
+				continue
+			}
+			if t := strings.TrimSpace(mentionRegex.ReplaceAllString(m.Text, "")); t != "" {
+				texts = append(texts, t)
+			}
+		}
+
+		if !hasMore || next == "" {
+			return texts
+		}
+		cursor = next
+	}
+}
+
+// permalink returns a link to the message, or "" if unavailable.
+func (b *SocketModeBot) permalink(channelID, ts string) string {
+	if b.api == nil || ts == "" {
+		return ""
+	}
+	link, err := b.api.GetPermalink(&slack.PermalinkParameters{Channel: channelID, Ts: ts})
+	if err != nil {
+		log.Debug("Failed to get permalink: %v", err)
+		return ""
+	}
+	return link
+}
+
+// replyInThread posts a response as a reply to the given message timestamp.
+func (b *SocketModeBot) replyInThread(threadTS string, resp commandResponse) {
+	b.replyInChannelThread(b.channelID, threadTS, resp)
+}
+
+// replyInChannelThread posts a response as a reply to the given message
+// timestamp in channelID.
+func (b *SocketModeBot) replyInChannelThread(channelID, threadTS string, resp commandResponse) {
+	if b.api == nil {
+		return
+	}
+
+	opts := []slack.MsgOption{slack.MsgOptionText(log.Redact(resp.Text), false)}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+
+	if _, _, err := b.api.PostMessage(channelID, opts...); err != nil {
+		log.Debug("Failed to post reply: %v", err)
+	}
+}
+
+// postEphemeral posts a message only visible to the given user.
+func (b *SocketModeBot) postEphemeral(channelID, userID, text string) {
+	if b.api == nil {
+		return
+	}
+	if _, err := b.api.PostEphemeral(channelID, userID, slack.MsgOptionText(log.Redact(text), false)); err != nil {
+		log.Debug("Failed to post ephemeral message: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+func TestPlanFromSlack_Message(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+
+	resp := bot.planFromSlack("Jane", "Add CSV export to the reports page", nil, "https://example.slack.com/archives/C123/p1")
+
+	if !strings.Contains(resp.Text, "`add-csv-export-to-the-reports-page`") || !strings.Contains(resp.Text, "position 1 of 1") {
+		t.Errorf("unexpected reply: %q", resp.Text)
+	}
+
+	content, err := os.ReadFile(filepath.Join(queueDir, "pending", "add-csv-export-to-the-reports-page.md"))
+	if err != nil {
+		t.Fatalf("plan not created: %v", err)
+	}
+	if !strings.Contains(string(content), "_Source: Slack message from Jane (https://example.slack.com/archives/C123/p1)_") {
+		t.Errorf("plan should record source and permalink:\n%s", content)
+	}
+}
+
+func TestPlanFromSlack_Thread(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+
+	thread := []string{"Reports are slow to load", "It takes 30s for the monthly view"}
+	bot.planFromSlack("Jane", "", thread, "")
+
+	content, err := os.ReadFile(filepath.Join(queueDir, "pending", "reports-are-slow-to-load.md"))
+	if err != nil {
+		t.Fatalf("plan should be titled from the first thread message: %v", err)
+	}
+	if !strings.Contains(string(content), "It takes 30s for the monthly view") {
+		t.Errorf("plan should include the whole thread:\n%s", content)
+	}
+}
+
+func TestPlanFromSlack_TextWithThread(t *testing.T) {
+	bot, queueDir := setupCommandBot(t)
+
+	bot.planFromSlack("Jane", "Speed up reports", []string{"Reports are slow to load"}, "")
+
+	content, err := os.ReadFile(filepath.Join(queueDir, "pending", "speed-up-reports.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Speed up reports\n\nThread:\n\nReports are slow to load") {
+		t.Errorf("plan should include message and thread:\n%s", content)
+	}
+}
+
+func TestPlanFromSlack_Empty(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	resp := bot.planFromSlack("Jane", "  ", nil, "")
+	if !strings.Contains(resp.Text, "empty") {
+		t.Errorf("expected empty message reply, got %q", resp.Text)
+	}
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 0 {
+		t.Errorf("no plan should be created, got %d", len(pending))
+	}
+}
+
+func TestHandleAppMention(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	bot.handleAppMention(&slackevents.AppMentionEvent{
+		User:      "U1",
+		Text:      "<@UBOT> Fix the broken signup link",
+		Channel:   "C123",
+		TimeStamp: "1700000000.000100",
+	})
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 1 || pending[0].Name != "fix-the-broken-signup-link" {
+		t.Errorf("expected plan from mention, got %v", pending)
+	}
+}
+
+func TestHandleAppMention_Ignored(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	tracker, err := NewThreadTracker(filepath.Join(t.TempDir(), "threads.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Set("existing-plan", &ThreadInfo{PlanName: "existing-plan", ThreadTS: "1700000000.000001"})
+	bot.threadTracker = tracker
+
+	events := []*slackevents.AppMentionEvent{
+		// Other channel
+		{User: "U1", Text: "<@UBOT> do a thing", Channel: "COTHER", TimeStamp: "1"},
+		// From a bot
+		{User: "U1", Text: "<@UBOT> do a thing", Channel: "C123", TimeStamp: "2", BotID: "B1"},
+		// Inside a tracked plan thread (handled as feedback)
+		{User: "U1", Text: "<@UBOT> do a thing", Channel: "C123", TimeStamp: "3", ThreadTimeStamp: "1700000000.000001"},
+	}
+	for _, ev := range events {
+		bot.handleAppMention(ev)
+	}
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 0 {
+		t.Errorf("expected no plans, got %d", len(pending))
+	}
+}
+
+func TestHandleInteractive_MessageShortcut(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	callback := slack.InteractionCallback{
+		Type:       slack.InteractionTypeMessageAction,
+		CallbackID: CreatePlanCallbackID,
+		User:       slack.User{ID: "U1", Name: "jane"},
+	}
+	callback.Channel.ID = "C123"
+	callback.Message.Text = "Support dark mode in the dashboard"
+	callback.Message.Timestamp = "1700000000.000200"
+
+	bot.handleInteractive(socketmode.Event{Type: socketmode.EventTypeInteractive, Data: callback})
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 1 || pending[0].Name != "support-dark-mode-in-the-dashboard" {
+		t.Errorf("expected plan from shortcut, got %v", pending)
+	}
+}
+
+func TestHandleInteractive_Rejected(t *testing.T) {
+	bot, _ := setupCommandBot(t)
+
+	// Wrong channel
+	callback := slack.InteractionCallback{
+		Type:       slack.InteractionTypeMessageAction,
+		CallbackID: CreatePlanCallbackID,
+	}
+	callback.Channel.ID = "COTHER"
+	callback.Message.Text = "Do something"
+	bot.handleInteractive(socketmode.Event{Data: callback})
+
+	// Other shortcut
+	callback.Channel.ID = "C123"
+	callback.CallbackID = "something_else"
+	bot.handleInteractive(socketmode.Event{Data: callback})
+
+	pending, _ := bot.queue.Pending()
+	if len(pending) != 0 {
+		t.Errorf("expected no plans, got %d", len(pending))
+	}
+}
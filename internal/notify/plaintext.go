@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/i18n"
+)
+
+// Plain text mode (slack.plain_text) sends every notification as simple text
+// instead of Block Kit, for clients and screen readers that render blocks
+// poorly. Messages are still built as blocks and flattened on the way out, so
+// templates and new messages get the same treatment.
+
+var (
+	// emojiRegex matches a Slack emoji shortcode such as ":white_check_mark:".
+	emojiRegex = regexp.MustCompile(`:[a-z][a-z0-9_+-]*:`)
+
+	// statusEmojiRegex matches a status icon in front of the word it
+	// describes, as in gate results (":x: lint").
+	statusEmojiRegex = regexp.MustCompile(`:(white_check_mark|x|warning): (\S+)`)
+
+	// labeledLinkRegex and linkRegex match mrkdwn links, <url|label> and <url>.
+	labeledLinkRegex = regexp.MustCompile(`<(https?://[^|>]+)\|([^>]+)>`)
+	linkRegex        = regexp.MustCompile(`<(https?://[^|>]+)>`)
+
+	boldRegex = regexp.MustCompile(`\*([^*\n]+)\*`)
+	codeRegex = regexp.MustCompile("`([^`\n]*)`")
+)
+
+// plainBlock is the part of a Block Kit block plain text mode reads.
+// Elements are decoded only for context blocks, whose elements are text.
+type plainBlock struct {
+	Type     string          `json:"type"`
+	Text     *slackText      `json:"text,omitempty"`
+	Fields   []slackText     `json:"fields,omitempty"`
+	Elements json.RawMessage `json:"elements,omitempty"`
+}
+
+// plainTextMessage flattens a message's Block Kit blocks (JSON) to plain
+// text, one section or field per line. The headline comes first and parts
+// with links come last, so screen readers announce the status before URLs.
+// Emoji icons and mrkdwn formatting are removed; mentions are kept so they
+// still notify. Buttons are left out: messages with buttons also say how to
+// answer from the CLI. fallback is used when there are no blocks.
+func plainTextMessage(blocks []byte, fallback string) string {
+	var parsed []plainBlock
+	if len(blocks) > 0 {
+		if err := json.Unmarshal(blocks, &parsed); err != nil {
+			parsed = nil
+		}
+	}
+
+	var texts []*slackText
+	for _, b := range parsed {
+		switch b.Type {
+		case "section", "header":
+			if b.Text != nil {
+				texts = append(texts, b.Text)
+			}
+			for i := range b.Fields {
+				texts = append(texts, &b.Fields[i])
+			}
+		case "context":
+			var elements []slackText
+			if err := json.Unmarshal(b.Elements, &elements); err == nil {
+				for i := range elements {
+					if elements[i].Text != "" {
+						texts = append(texts, &elements[i])
+					}
+				}
+			}
+		}
+	}
+	if len(texts) == 0 && fallback != "" {
+		texts = append(texts, &slackText{Type: "mrkdwn", Text: fallback})
+	}
+
+	var parts, links []string
+	for _, t := range texts {
+		part := plainTextPart(t)
+		switch {
+		case part == "":
+		case linkRegex.MatchString(t.Text) || labeledLinkRegex.MatchString(t.Text):
+			links = append(links, part)
+		default:
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(append(parts, links...), "\n")
+}
+
+// plainTextPart flattens one text object. A bold first line followed by a
+// single line of value ("*Branch:*\n`feat/x`") becomes "Branch: feat/x".
+func plainTextPart(t *slackText) string {
+	if t.Type == "plain_text" {
+		return strings.TrimSpace(t.Text)
+	}
+
+	lines := strings.Split(strings.TrimSpace(t.Text), "\n")
+	// Icons in the headline only decorate it
+	lines[0] = strings.Join(strings.Fields(emojiRegex.ReplaceAllString(lines[0], "")), " ")
+	label := ""
+	if first := lines[0]; len(first) > 2 && boldRegex.FindString(first) == first {
+		label = strings.TrimSuffix(first[1:len(first)-1], ":")
+	}
+
+	inCode := false
+	for i, line := range lines {
+		// Code blocks, such as error output and diffs, are kept as written
+		if fences := strings.Count(line, "```"); fences > 0 || inCode {
+			lines[i] = strings.ReplaceAll(line, "```", "")
+			if fences%2 == 1 {
+				inCode = !inCode
+			}
+			continue
+		}
+		if i > 0 {
+			line = statusEmojiRegex.ReplaceAllStringFunc(line, func(m string) string {
+				sub := statusEmojiRegex.FindStringSubmatch(m)
+				return sub[2] + " (" + statusWord(sub[1]) + ")"
+			})
+			line = strings.TrimSpace(emojiRegex.ReplaceAllString(line, ""))
+		}
+		line = labeledLinkRegex.ReplaceAllString(line, "$2 ($1)")
+		line = linkRegex.ReplaceAllString(line, "$1")
+		line = codeRegex.ReplaceAllString(line, "$1")
+		lines[i] = boldRegex.ReplaceAllString(line, "$1")
+	}
+
+	if label != "" && len(lines) == 2 && lines[1] != "" {
+		return label + ": " + lines[1]
+	}
+
+	// Collapse runs of blank lines, such as the gaps between digest sections
+	var out []string
+	for _, line := range lines {
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// statusWord names what a status icon in a message body means.
+func statusWord(emoji string) string {
+	switch emoji {
+	case "white_check_mark":
+		return i18n.T("passed")
+	case "x":
+		return i18n.T("failed")
+	default:
+		return i18n.T("warning")
+	}
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// blocksJSON marshals webhook blocks the way they are sent.
+func blocksJSON(t *testing.T, blocks []slackBlock) []byte {
+	t.Helper()
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPlainTextMessage_Complete(t *testing.T) {
+	p := &plan.Plan{Name: "my-plan", Branch: "feat/my-plan"}
+	c := Completion{
+		PRURL:         "https://github.com/o/r/pull/7",
+		Iterations:    3,
+		MaxIterations: 10,
+		Gates:         []gate.Result{{Name: "test", Passed: true}, {Name: "lint"}},
+	}
+	blocks := []slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: ":white_check_mark: *Plan Complete*\n`my-plan`"}}}
+	var fields []slackText
+	for _, field := range completionFields(p, c) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: field})
+	}
+	blocks = append(blocks, slackBlock{Type: "section", Fields: fields})
+
+	got := plainTextMessage(blocksJSON(t, blocks), "")
+	want := strings.Join([]string{
+		"Plan Complete: my-plan",
+		"Branch: feat/my-plan",
+		"Iterations: 3/10",
+		"Gates: test (passed)   lint (failed)",
+		"Pull Request: View PR (https://github.com/o/r/pull/7)",
+	}, "\n")
+	if got != want {
+		t.Errorf("plainTextMessage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlainTextMessage_Escalation(t *testing.T) {
+	var blocks []slackBlock
+	blocker := &runner.Blocker{Description: "Need an API key", Action: "Add it to .env"}
+	for _, text := range escalationText(&plan.Plan{Name: "my-plan"}, blocker, 2*time.Hour, "@here") {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+
+	got := plainTextMessage(blocksJSON(t, blocks), "")
+	want := strings.Join([]string{
+		"<!here> Blocker Unresolved for 2h0m0s",
+		"my-plan is still waiting for human input",
+		"Description: Need an API key",
+		"Action Required: Add it to .env",
+	}, "\n")
+	if got != want {
+		t.Errorf("plainTextMessage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlainTextMessage_Digest(t *testing.T) {
+	summary := &DigestSummary{
+		Since:     time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		Until:     time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		Started:   []string{"a"},
+		Completed: []DigestPlan{{Name: "b", PRURL: "https://github.com/o/r/pull/1"}},
+	}
+	blocks := []slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: formatDigest(summary)}}}
+
+	got := plainTextMessage(blocksJSON(t, blocks), "")
+	want := strings.Join([]string{
+		"Ralph Digest (Jan 2 09:00 – Jan 2 10:00)",
+		"",
+		"Started:",
+		"• a",
+		"",
+		"Completed:",
+		"• b View PR (https://github.com/o/r/pull/1)",
+	}, "\n")
+	if got != want {
+		t.Errorf("plainTextMessage() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlainTextMessage_Other(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   string
+		fallback string
+		want     string
+	}{
+		{
+			name:   "error code block",
+			blocks: `[{"type":"section","text":{"type":"mrkdwn","text":"*Error:*\n` + "```exit status 1```" + `"}}]`,
+			want:   "Error: exit status 1",
+		},
+		{
+			name:   "header, context, and buttons",
+			blocks: `[{"type":"header","text":{"type":"plain_text","text":"Deploy *now*"}},{"type":"context","elements":[{"type":"mrkdwn","text":"by <@U123>"}]},{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"Approve"}}]}]`,
+			want:   "Deploy *now*\nby <@U123>",
+		},
+		{
+			name:     "text only",
+			fallback: ":tada: *Shipped* `my-plan`",
+			want:     "Shipped my-plan",
+		},
+		{
+			name:   "bare link",
+			blocks: `[{"type":"section","text":{"type":"mrkdwn","text":"Logs: <https://ci.example.com/1>"}},{"type":"section","text":{"type":"mrkdwn","text":"Done"}}]`,
+			want:   "Done\nLogs: https://ci.example.com/1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plainTextMessage([]byte(tt.blocks), tt.fallback); got != tt.want {
+				t.Errorf("plainTextMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/slack-go/slack"
+)
+
+// QuestionSender is implemented by notifiers that can ask a human the
+// agent's question (<ask>).
+type QuestionSender interface {
+	// Question asks the agent's question; the loop waits up to timeout for
+	// the answer (0 = it doesn't wait).
+	Question(p *plan.Plan, question string, timeout time.Duration) error
+}
+
+// questionHint tells the human how to answer: in the thread, where the bot
+// writes replies to the feedback file, or with ralph feedback.
+func questionHint(p *plan.Plan, inThread bool, timeout time.Duration) string {
+	hint := i18n.Sprintf("Answer with `ralph feedback %s \"...\"`.", p.Name)
+	if inThread {
+		hint = i18n.T("Reply in this thread to answer.")
+	}
+	if timeout > 0 {
+		hint += " " + i18n.Sprintf("The plan waits up to %s for the answer.", timeout)
+	}
+	return hint
+}
+
+// Question posts the agent's question in the plan's thread, broadcast to the
+// channel so it gets seen.
+func (s *SlackNotifier) Question(p *plan.Plan, question string, timeout time.Duration) error {
+	if question == "" {
+		return nil
+	}
+
+	inThread := false
+	if s.threadTracker != nil {
+		_, key := s.channelFor(p)
+		inThread = s.threadTracker.Get(key) != nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf(":question: *Question from Ralph*\n`%s`", p.Name), false, false),
+			nil, nil,
+		),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, question, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, questionHint(p, inThread, timeout), false, false), nil, nil),
+	}
+
+	s.postMessageInThread(p, blocks, slack.MsgOptionBroadcast())
+	return nil
+}
+
+// Question is always sent; a held-back question would stall the plan.
+func (r *RateLimitNotifier) Question(p *plan.Plan, question string, timeout time.Duration) error {
+	if sender, ok := r.inner.(QuestionSender); ok {
+		return sender.Question(p, question, timeout)
+	}
+	return nil
+}
+
+// Question is sent immediately.
+func (d *DigestNotifier) Question(p *plan.Plan, question string, timeout time.Duration) error {
+	if sender, ok := d.inner.(QuestionSender); ok {
+		return sender.Question(p, question, timeout)
+	}
+	return nil
+}
+
+var _ QuestionSender = (*SlackNotifier)(nil)
+var _ QuestionSender = (*RateLimitNotifier)(nil)
+var _ QuestionSender = (*DigestNotifier)(nil)
@@ -0,0 +1,381 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// maxCoalescedPlans bounds the plan names listed per line of a coalesced summary.
+const maxCoalescedPlans = 5
+
+// Notification kinds counted by the rate limiter, with their summary labels.
+var (
+	kindStart      = notificationKind{"plan start", "plan starts"}
+	kindIteration  = notificationKind{"iteration update", "iteration updates"}
+	kindComplete   = notificationKind{"completion", "completions"}
+	kindError      = notificationKind{"error", "errors"}
+	kindBlocker    = notificationKind{"blocker", "blockers"}
+	kindEscalation = notificationKind{"blocker escalation", "blocker escalations"}
+	kindUrgent     = notificationKind{"urgent feedback alert", "urgent feedback alerts"}
+	kindStage      = notificationKind{"stage change", "stage changes"}
+	kindDiff       = notificationKind{"diff preview", "diff previews"}
+	kindResources  = notificationKind{"resource alert", "resource alerts"}
+)
+
+// notificationKind names a kind of notification in coalesced summaries.
+type notificationKind struct {
+	one, many string
+}
+
+// label returns the kind's name for n notifications.
+func (k notificationKind) label(n int) string {
+	if n == 1 {
+		return k.one
+	}
+	return k.many
+}
+
+// CoalescedSender is implemented by notifiers that can deliver a summary of
+// notifications held back by a rate limit.
+type CoalescedSender interface {
+	// Coalesced sends a single message summarizing held-back notifications.
+	Coalesced(summary *CoalescedSummary) error
+}
+
+// CoalescedGroup summarizes held-back notifications of one kind.
+type CoalescedGroup struct {
+	// Kind names the notification kind for Count, e.g. "errors".
+	Kind string
+
+	// Count is the number of notifications held back.
+	Count int
+
+	// Plans are the plans they were about, in the order first seen.
+	Plans []string
+
+	// Message is the most common error or blocker text, if any.
+	Message string
+
+	// MessageCount is how many of the notifications had Message.
+	MessageCount int
+}
+
+// CoalescedSummary summarizes the notifications held back during a window.
+type CoalescedSummary struct {
+	// Window is the rate limit window.
+	Window time.Duration
+
+	// Groups are the held-back notifications by kind, in the order first seen.
+	Groups []CoalescedGroup
+}
+
+// Empty returns true if no notifications were held back.
+func (s *CoalescedSummary) Empty() bool {
+	return len(s.Groups) == 0
+}
+
+// formatCoalesced renders a coalesced summary as Slack mrkdwn.
+func formatCoalesced(s *CoalescedSummary) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.Sprintf(":mute: *Notifications rate limited* in the last %s", formatWindow(s.Window)))
+	for _, g := range s.Groups {
+		plans := g.Plans
+		more := ""
+		if len(plans) > maxCoalescedPlans {
+			more = fmt.Sprintf(" and %d more", len(plans)-maxCoalescedPlans)
+			plans = plans[:maxCoalescedPlans]
+		}
+		noun := "plans"
+		if len(g.Plans) == 1 {
+			noun = "plan"
+		}
+		sb.WriteString(fmt.Sprintf("\n• %d %s from %d %s: `%s`%s", g.Count, g.Kind, len(g.Plans), noun, strings.Join(plans, "`, `"), more))
+		if g.Message != "" {
+			sb.WriteString(fmt.Sprintf("\n   Most common (%d): %s", g.MessageCount, truncate(g.Message, 200)))
+		}
+	}
+	return sb.String()
+}
+
+// formatWindow formats a window without trailing zero units, e.g. "10m" or "1h".
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// RateLimitNotifier limits how many notifications are sent per window, per
+// plan and across all plans, and drops repeats of a notification already
+// sent in the window. Notifications over a limit are held back and sent as
+// one summary per window, so a burst of failures (e.g. an API outage erroring
+// every plan) becomes a single message. Approval requests are always sent.
+type RateLimitNotifier struct {
+	inner   Notifier
+	window  time.Duration
+	perPlan int
+	global  int
+
+	// mu protects sent and held.
+	mu sync.Mutex
+
+	// sent are the notifications sent during the last window, oldest first.
+	sent []sentNotification
+
+	// held are the notifications held back since the last summary.
+	held []heldNotification
+
+	// now returns the current time (for testing).
+	now func() time.Time
+}
+
+// sentNotification is a notification counted against the limits.
+type sentNotification struct {
+	at   time.Time
+	plan string
+	key  string
+}
+
+// heldNotification is a notification held back for the next summary.
+type heldNotification struct {
+	kind    notificationKind
+	plan    string
+	message string
+}
+
+// NewRateLimitNotifier wraps inner so at most perPlan notifications per plan
+// and global notifications overall are sent per window (0 = unlimited).
+func NewRateLimitNotifier(inner Notifier, window time.Duration, perPlan, global int) *RateLimitNotifier {
+	return &RateLimitNotifier{
+		inner:   inner,
+		window:  window,
+		perPlan: perPlan,
+		global:  global,
+		now:     time.Now,
+	}
+}
+
+// allow reports whether a notification may be sent, counting it if so and
+// holding it back otherwise. detail tells repeats apart (e.g. the error text);
+// message is shown in the summary.
+func (r *RateLimitNotifier) allow(kind notificationKind, planName, detail, message string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	cutoff := now.Add(-r.window)
+	i := 0
+	for i < len(r.sent) && !r.sent[i].at.After(cutoff) {
+		i++
+	}
+	r.sent = r.sent[i:]
+
+	key := kind.one + "\x00" + detail
+	perPlan, repeat := 0, false
+	for _, s := range r.sent {
+		if s.plan == planName {
+			perPlan++
+			repeat = repeat || s.key == key
+		}
+	}
+
+	if repeat || (r.perPlan > 0 && perPlan >= r.perPlan) || (r.global > 0 && len(r.sent) >= r.global) {
+		r.held = append(r.held, heldNotification{kind: kind, plan: planName, message: message})
+		log.Debug("Rate limited %s notification for %s", kind.one, planName)
+		return false
+	}
+	r.sent = append(r.sent, sentNotification{at: now, plan: planName, key: key})
+	return true
+}
+
+// Start sends a notification when a plan starts, within the limits.
+func (r *RateLimitNotifier) Start(p *plan.Plan) error {
+	if !r.allow(kindStart, p.Name, "", "") {
+		return nil
+	}
+	return r.inner.Start(p)
+}
+
+// Complete sends a notification when a plan completes, within the limits.
+func (r *RateLimitNotifier) Complete(p *plan.Plan, c Completion) error {
+	if !r.allow(kindComplete, p.Name, "", "") {
+		return nil
+	}
+	return r.inner.Complete(p, c)
+}
+
+// Blocker sends a notification when a blocker is encountered, within the limits.
+func (r *RateLimitNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
+	description := ""
+	if blocker != nil {
+		description = blocker.Description
+	}
+	if !r.allow(kindBlocker, p.Name, description, description) {
+		return nil
+	}
+	return r.inner.Blocker(p, blocker)
+}
+
+// BlockerEscalation re-notifies about an unresolved blocker, within the limits.
+func (r *RateLimitNotifier) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	if !r.allow(kindEscalation, p.Name, unresolved.String(), "") {
+		return nil
+	}
+	return r.inner.BlockerEscalation(p, blocker, unresolved, mention)
+}
+
+// Error sends a notification when an error occurs, within the limits.
+// Repeats of the same error for a plan are held back.
+func (r *RateLimitNotifier) Error(p *plan.Plan, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !r.allow(kindError, p.Name, err.Error(), err.Error()) {
+		return nil
+	}
+	return r.inner.Error(p, err)
+}
+
+// Iteration sends a notification for each iteration, within the limits.
+func (r *RateLimitNotifier) Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error {
+	if !r.allow(kindIteration, p.Name, strconv.Itoa(iteration), "") {
+		return nil
+	}
+	return r.inner.Iteration(p, iteration, maxIterations, eta)
+}
+
+// UrgentFeedback sends a notification about unprocessed urgent feedback,
+// within the limits.
+func (r *RateLimitNotifier) UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	if !r.allow(kindUrgent, p.Name, strings.Join(ids, ","), "") {
+		return nil
+	}
+	return r.inner.UrgentFeedback(p, entries)
+}
+
+// StageChange sends a notification when a plan changes stage, within the limits.
+func (r *RateLimitNotifier) StageChange(p *plan.Plan, from, to string) error {
+	if !r.allow(kindStage, p.Name, from+"\x00"+to, "") {
+		return nil
+	}
+	return r.inner.StageChange(p, from, to)
+}
+
+// ApprovalRequest is always sent; a held-back request would stall the plan.
+func (r *RateLimitNotifier) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	return r.inner.ApprovalRequest(p, c, timeout)
+}
+
+// Digest passes a digest summary through to the wrapped notifier.
+func (r *RateLimitNotifier) Digest(summary *DigestSummary) error {
+	if sender, ok := r.inner.(DigestSender); ok {
+		return sender.Digest(summary)
+	}
+	return nil
+}
+
+// Run sends a summary of held-back notifications every window until ctx is
+// cancelled, and once more on the way out.
+func (r *RateLimitNotifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := r.Flush(); err != nil {
+				log.Debug("Failed to send rate limit summary: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				log.Debug("Failed to send rate limit summary: %v", err)
+			}
+		}
+	}
+}
+
+// Flush sends one summary of the notifications held back since the last
+// summary. Nothing is sent if none were held back.
+func (r *RateLimitNotifier) Flush() error {
+	r.mu.Lock()
+	held := r.held
+	r.held = nil
+	r.mu.Unlock()
+
+	summary := buildCoalesced(held, r.window)
+	if summary.Empty() {
+		return nil
+	}
+	sender, ok := r.inner.(CoalescedSender)
+	if !ok {
+		return nil
+	}
+	return sender.Coalesced(summary)
+}
+
+// buildCoalesced groups held-back notifications by kind.
+func buildCoalesced(held []heldNotification, window time.Duration) *CoalescedSummary {
+	summary := &CoalescedSummary{Window: window}
+	var kinds []notificationKind
+	groups := make(map[notificationKind]int)
+	messages := make(map[notificationKind]map[string]int)
+
+	for _, h := range held {
+		i, ok := groups[h.kind]
+		if !ok {
+			summary.Groups = append(summary.Groups, CoalescedGroup{})
+			kinds = append(kinds, h.kind)
+			i = len(summary.Groups) - 1
+			groups[h.kind] = i
+			messages[h.kind] = make(map[string]int)
+		}
+		g := &summary.Groups[i]
+		g.Count++
+		if !containsString(g.Plans, h.plan) {
+			g.Plans = append(g.Plans, h.plan)
+		}
+		if h.message != "" {
+			messages[h.kind][h.message]++
+			if n := messages[h.kind][h.message]; n > g.MessageCount {
+				g.Message, g.MessageCount = h.message, n
+			}
+		}
+	}
+
+	for i, kind := range kinds {
+		summary.Groups[i].Kind = kind.label(summary.Groups[i].Count)
+	}
+	return summary
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure RateLimitNotifier implements Notifier and passes digests through.
+var _ Notifier = (*RateLimitNotifier)(nil)
+var _ DigestSender = (*RateLimitNotifier)(nil)
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// rateRecorder is a Notifier and CoalescedSender that records calls.
+type rateRecorder struct {
+	digestRecorder
+	errors    []string
+	starts    int
+	coalesced []*CoalescedSummary
+}
+
+func (r *rateRecorder) Start(p *plan.Plan) error {
+	r.starts++
+	return nil
+}
+
+func (r *rateRecorder) Error(p *plan.Plan, err error) error {
+	r.errors = append(r.errors, p.Name+": "+err.Error())
+	return nil
+}
+
+func (r *rateRecorder) Coalesced(summary *CoalescedSummary) error {
+	r.coalesced = append(r.coalesced, summary)
+	return nil
+}
+
+// newTestRateLimiter returns a rate limiter over a recorder with a settable clock.
+func newTestRateLimiter(perPlan, global int) (*RateLimitNotifier, *rateRecorder, *time.Time) {
+	rec := &rateRecorder{}
+	r := NewRateLimitNotifier(rec, 10*time.Minute, perPlan, global)
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	return r, rec, &now
+}
+
+func TestRateLimitNotifier_Global(t *testing.T) {
+	r, rec, now := newTestRateLimiter(0, 3)
+
+	outage := errors.New("API rate limit exceeded")
+	for i := 1; i <= 5; i++ {
+		r.Error(&plan.Plan{Name: fmt.Sprintf("plan-%d", i)}, outage)
+	}
+	if len(rec.errors) != 3 {
+		t.Fatalf("sent %d errors, want 3: %v", len(rec.errors), rec.errors)
+	}
+
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(rec.coalesced) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(rec.coalesced))
+	}
+	g := rec.coalesced[0].Groups[0]
+	if g.Kind != "errors" || g.Count != 2 || strings.Join(g.Plans, ",") != "plan-4,plan-5" || g.Message != outage.Error() || g.MessageCount != 2 {
+		t.Errorf("group = %+v", g)
+	}
+
+	// Nothing is held back, so nothing more is sent
+	r.Flush()
+	if len(rec.coalesced) != 1 {
+		t.Errorf("empty flush sent a summary")
+	}
+
+	// The window slides
+	*now = now.Add(11 * time.Minute)
+	r.Error(&plan.Plan{Name: "plan-6"}, outage)
+	if len(rec.errors) != 4 {
+		t.Errorf("error after the window should be sent, got %v", rec.errors)
+	}
+}
+
+func TestRateLimitNotifier_PerPlan(t *testing.T) {
+	r, rec, _ := newTestRateLimiter(2, 0)
+
+	p := &plan.Plan{Name: "noisy"}
+	for i := 1; i <= 4; i++ {
+		r.Error(p, fmt.Errorf("attempt %d failed", i))
+	}
+	r.Error(&plan.Plan{Name: "quiet"}, errors.New("failed"))
+
+	if len(rec.errors) != 3 || rec.errors[2] != "quiet: failed" {
+		t.Errorf("errors = %v, want 2 for noisy and 1 for quiet", rec.errors)
+	}
+}
+
+func TestRateLimitNotifier_Dedupe(t *testing.T) {
+	r, rec, _ := newTestRateLimiter(0, 0)
+
+	p := &plan.Plan{Name: "alpha"}
+	r.Error(p, errors.New("connection refused"))
+	r.Error(p, errors.New("connection refused"))
+	r.Error(p, errors.New("disk full"))
+	r.Error(&plan.Plan{Name: "beta"}, errors.New("connection refused"))
+
+	if len(rec.errors) != 3 {
+		t.Errorf("errors = %v, want the repeat held back", rec.errors)
+	}
+
+	r.Flush()
+	if len(rec.coalesced) != 1 || rec.coalesced[0].Groups[0].Kind != "error" {
+		t.Errorf("summary = %+v", rec.coalesced)
+	}
+}
+
+func TestRateLimitNotifier_ApprovalAlwaysSent(t *testing.T) {
+	r, rec, _ := newTestRateLimiter(1, 1)
+
+	p := &plan.Plan{Name: "alpha"}
+	r.Start(p)
+	r.Blocker(p, &runner.Blocker{Description: "Need credentials"})
+	r.ApprovalRequest(p, Completion{}, 0)
+	r.ApprovalRequest(p, Completion{}, 0)
+
+	if rec.starts != 1 || rec.blockers != 0 || rec.approvals != 2 {
+		t.Errorf("starts = %d, blockers = %d, approvals = %d", rec.starts, rec.blockers, rec.approvals)
+	}
+}
+
+func TestRateLimitNotifier_DigestPassthrough(t *testing.T) {
+	r, rec, _ := newTestRateLimiter(1, 1)
+
+	if err := r.Digest(&DigestSummary{Started: []string{"alpha"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.digests) != 1 {
+		t.Errorf("digest not passed through")
+	}
+}
+
+func TestFormatCoalesced(t *testing.T) {
+	summary := buildCoalesced([]heldNotification{
+		{kind: kindError, plan: "a", message: "rate limit exceeded"},
+		{kind: kindError, plan: "b", message: "rate limit exceeded"},
+		{kind: kindError, plan: "c", message: "timeout"},
+		{kind: kindError, plan: "d", message: "rate limit exceeded"},
+		{kind: kindError, plan: "e", message: "rate limit exceeded"},
+		{kind: kindError, plan: "f", message: "rate limit exceeded"},
+		{kind: kindIteration, plan: "a"},
+	}, 10*time.Minute)
+
+	text := formatCoalesced(summary)
+	for _, want := range []string{
+		"*Notifications rate limited* in the last 10m",
+		"• 6 errors from 6 plans: `a`, `b`, `c`, `d`, `e` and 1 more",
+		"Most common (5): rate limit exceeded",
+		"• 1 iteration update from 1 plan: `a`",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatWindow(t *testing.T) {
+	tests := map[time.Duration]string{
+		10 * time.Minute:        "10m",
+		time.Hour:               "1h",
+		90 * time.Minute:        "1h30m",
+		30 * time.Second:        "30s",
+		time.Hour + time.Second: "1h0m1s",
+	}
+	for d, want := range tests {
+		if got := formatWindow(d); got != want {
+			t.Errorf("formatWindow(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/i18n"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/slack-go/slack"
+)
+
+// ResourceAlertSender is implemented by notifiers that can flag an
+// iteration whose process tree exceeded runner.resource_alerts.
+type ResourceAlertSender interface {
+	// ResourceAlert flags an iteration's resource usage; alerts say which
+	// limits it exceeded.
+	ResourceAlert(p *plan.Plan, iteration int, usage events.ResourceUsage, alerts []string) error
+}
+
+// resourceAlertText formats a resource alert: the headline, the exceeded
+// limits, and the full usage.
+func resourceAlertText(p *plan.Plan, iteration int, usage events.ResourceUsage, alerts []string) string {
+	text := i18n.Sprintf(":warning: *Iteration %d Used Too Many Resources*\n`%s`", iteration, p.Name)
+	for _, alert := range alerts {
+		text += "\n• " + alert
+	}
+	if summary := runner.ResourceSummary(&usage); summary != "" {
+		text += "\n" + i18n.Sprintf("_Usage: %s_", summary)
+	}
+	return text
+}
+
+// ResourceAlert posts the alert in the plan's thread, broadcast to the
+// channel: a runaway iteration may need someone to step in.
+func (s *SlackNotifier) ResourceAlert(p *plan.Plan, iteration int, usage events.ResourceUsage, alerts []string) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, resourceAlertText(p, iteration, usage, alerts), false, false), nil, nil),
+	}
+	s.postMessageInThread(p, blocks, slack.MsgOptionBroadcast())
+	return nil
+}
+
+// ResourceAlert passes a resource alert through to the wrapped notifier,
+// within the limits. Further alerts for the plan in the window are held back.
+func (r *RateLimitNotifier) ResourceAlert(p *plan.Plan, iteration int, usage events.ResourceUsage, alerts []string) error {
+	sender, ok := r.inner.(ResourceAlertSender)
+	if !ok {
+		return nil
+	}
+	if !r.allow(kindResources, p.Name, "", strings.Join(alerts, ", ")) {
+		return nil
+	}
+	return sender.ResourceAlert(p, iteration, usage, alerts)
+}
+
+// ResourceAlert is sent immediately.
+func (d *DigestNotifier) ResourceAlert(p *plan.Plan, iteration int, usage events.ResourceUsage, alerts []string) error {
+	if sender, ok := d.inner.(ResourceAlertSender); ok {
+		return sender.ResourceAlert(p, iteration, usage, alerts)
+	}
+	return nil
+}
+
+var _ ResourceAlertSender = (*SlackNotifier)(nil)
+var _ ResourceAlertSender = (*RateLimitNotifier)(nil)
+var _ ResourceAlertSender = (*DigestNotifier)(nil)
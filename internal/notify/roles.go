@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// Role is a Slack user's permission level, from least to most privileged.
+type Role int
+
+const (
+	// RoleNone allows nothing.
+	RoleNone Role = iota
+
+	// RoleViewer allows reading queue status.
+	RoleViewer
+
+	// RoleOperator also allows queueing plans, controlling the worker, and
+	// replying with feedback in plan threads.
+	RoleOperator
+
+	// RoleAdmin also allows approving and rejecting plans.
+	RoleAdmin
+)
+
+// String returns the role's config name.
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return config.RoleViewer
+	case RoleOperator:
+		return config.RoleOperator
+	case RoleAdmin:
+		return config.RoleAdmin
+	default:
+		return "none"
+	}
+}
+
+// parseRole converts a config role name to a Role. Unknown names are RoleNone.
+func parseRole(name string) Role {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case config.RoleViewer:
+		return RoleViewer
+	case config.RoleOperator:
+		return RoleOperator
+	case config.RoleAdmin:
+		return RoleAdmin
+	default:
+		return RoleNone
+	}
+}
+
+// Authorizer maps Slack user IDs to roles. A nil Authorizer allows everything.
+type Authorizer struct {
+	roles       map[string]Role
+	defaultRole Role
+}
+
+// NewAuthorizer creates an Authorizer from slack.roles and slack.default_role.
+// Returns nil (everyone allowed) if no roles are configured.
+func NewAuthorizer(roles map[string]string, defaultRole string) *Authorizer {
+	if len(roles) == 0 {
+		return nil
+	}
+	a := &Authorizer{roles: make(map[string]Role, len(roles)), defaultRole: parseRole(defaultRole)}
+	for user, role := range roles {
+		a.roles[user] = parseRole(role)
+	}
+	return a
+}
+
+// Role returns the role of a Slack user ID.
+func (a *Authorizer) Role(userID string) Role {
+	if a == nil {
+		return RoleAdmin
+	}
+	if role, ok := a.roles[userID]; ok {
+		return role
+	}
+	return a.defaultRole
+}
+
+// Allows reports whether a Slack user ID has at least the required role.
+func (a *Authorizer) Allows(userID string, required Role) bool {
+	return a.Role(userID) >= required
+}
+
+// commandRole returns the role required to run a /ralph subcommand.
+func commandRole(text string) Role {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return RoleViewer
+	}
+	switch strings.ToLower(fields[0]) {
+	case "queue", "pause", "resume", "skip", "unskip":
+		return RoleOperator
+	default:
+		return RoleViewer
+	}
+}
+
+// authorize checks that a Slack user may perform action, which needs the
+// required role. Denials are logged and described in the returned reply.
+func (b *SocketModeBot) authorize(userID, action string, required Role) (commandResponse, bool) {
+	role := b.auth.Role(userID)
+	if role >= required {
+		return commandResponse{}, true
+	}
+	log.Warn("Denied %s to Slack user %s (role %s, needs %s)", action, userID, role, required)
+	return commandResponse{
+		Text: fmt.Sprintf(":no_entry_sign: <@%s> is not allowed to %s (needs the %s role, has %s).", userID, action, required, role),
+	}, false
+}
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+func TestNewAuthorizer(t *testing.T) {
+	if a := NewAuthorizer(nil, "viewer"); a != nil {
+		t.Errorf("NewAuthorizer() without roles = %+v, want nil", a)
+	}
+
+	var open *Authorizer
+	if !open.Allows("U9", RoleAdmin) {
+		t.Error("nil Authorizer should allow everything")
+	}
+
+	a := NewAuthorizer(map[string]string{"UADMIN": "admin", "UOPS": "operator", "UVIEW": "viewer"}, "")
+	tests := []struct {
+		user string
+		want Role
+	}{
+		{"UADMIN", RoleAdmin},
+		{"UOPS", RoleOperator},
+		{"UVIEW", RoleViewer},
+		{"USTRANGER", RoleNone},
+	}
+	for _, tt := range tests {
+		if got := a.Role(tt.user); got != tt.want {
+			t.Errorf("Role(%s) = %s, want %s", tt.user, got, tt.want)
+		}
+	}
+	if a.Allows("UOPS", RoleAdmin) || !a.Allows("UADMIN", RoleOperator) {
+		t.Error("roles should be ordered viewer < operator < admin")
+	}
+
+	a = NewAuthorizer(map[string]string{"UADMIN": "admin"}, "viewer")
+	if got := a.Role("USTRANGER"); got != RoleViewer {
+		t.Errorf("Role() of unlisted user = %s, want the default viewer", got)
+	}
+}
+
+func TestCommandRole(t *testing.T) {
+	tests := map[string]Role{
+		"":                  RoleViewer,
+		"status":            RoleViewer,
+		"help":              RoleViewer,
+		"queue add Fix it":  RoleOperator,
+		"PAUSE":             RoleOperator,
+		"resume":            RoleOperator,
+		"skip alpha":        RoleOperator,
+		"unskip alpha":      RoleOperator,
+		"something unknown": RoleViewer,
+	}
+	for text, want := range tests {
+		if got := commandRole(text); got != want {
+			t.Errorf("commandRole(%q) = %s, want %s", text, got, want)
+		}
+	}
+}
+
+// setupRoleBot creates a command bot with an admin, an operator, and a viewer.
+func setupRoleBot(t *testing.T) *SocketModeBot {
+	t.Helper()
+	bot, _ := setupCommandBot(t)
+	bot.auth = NewAuthorizer(map[string]string{"UADMIN": "admin", "UOPS": "operator", "UVIEW": "viewer"}, "")
+	return bot
+}
+
+func TestHandleSlashCommand_Roles(t *testing.T) {
+	bot := setupRoleBot(t)
+	pause := func(userID string) {
+		bot.handleSlashCommand(socketmode.Event{
+			Type: socketmode.EventTypeSlashCommand,
+			Data: slack.SlashCommand{Command: SlashCommand, Text: "pause", ChannelID: "C123", UserID: userID, UserName: userID},
+		})
+	}
+
+	for _, user := range []string{"UVIEW", "USTRANGER"} {
+		pause(user)
+		if bot.control.IsPaused() {
+			t.Fatalf("%s should not be allowed to pause", user)
+		}
+	}
+
+	pause("UOPS")
+	if !bot.control.IsPaused() {
+		t.Error("operator should be allowed to pause")
+	}
+}
+
+func TestHandleInteractive_ApprovalRoles(t *testing.T) {
+	bot := setupRoleBot(t)
+	bot.control.RequestApproval("alpha")
+
+	press := func(userID string) {
+		callback := approvalCallback("C123", ApproveActionID)
+		callback.User.ID = userID
+		bot.handleInteractive(socketmode.Event{Data: callback})
+	}
+
+	press("UOPS")
+	if a := bot.control.Approval("alpha"); a.Decision != control.ApprovalPending {
+		t.Fatalf("Approval() after operator press = %+v, want pending", a)
+	}
+
+	press("UADMIN")
+	if a := bot.control.Approval("alpha"); a.Decision != control.ApprovalApproved {
+		t.Errorf("Approval() after admin press = %+v, want approved", a)
+	}
+}
+
+func TestHandleAppMention_Roles(t *testing.T) {
+	bot := setupRoleBot(t)
+	mention := func(userID string) {
+		bot.handleAppMention(&slackevents.AppMentionEvent{
+			User: userID, Text: "<@UBOT> Fix the broken signup link", Channel: "C123", TimeStamp: "1700000000.000100",
+		})
+	}
+
+	mention("UVIEW")
+	if pending, _ := bot.queue.Pending(); len(pending) != 0 {
+		t.Fatalf("viewer should not be able to create plans, got %d", len(pending))
+	}
+
+	mention("UOPS")
+	if pending, _ := bot.queue.Pending(); len(pending) != 1 {
+		t.Errorf("operator should be able to create plans, got %d", len(pending))
+	}
+}
+
+func TestHandleMessageEvent_Roles(t *testing.T) {
+	tmpDir := t.TempDir()
+	tracker, err := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.Set("my-plan", &ThreadInfo{ThreadTS: "100.1", ChannelID: "C123"})
+
+	bot := &SocketModeBot{
+		threadTracker: tracker,
+		planBasePath:  tmpDir,
+		channelID:     "C123",
+		auth:          NewAuthorizer(map[string]string{"UOPS": "operator"}, "viewer"),
+	}
+
+	bot.handleMessageEvent(&slackevents.MessageEvent{
+		User: "UVIEW", Text: "Drop the tests", Channel: "C123", TimeStamp: "100.2", ThreadTimeStamp: "100.1",
+	})
+	if _, err := os.Stat(filepath.Join(tmpDir, "my-plan.feedback.md")); !os.IsNotExist(err) {
+		t.Fatal("feedback from a viewer should not be written")
+	}
+
+	bot.handleMessageEvent(&slackevents.MessageEvent{
+		User: "UOPS", Text: "Use the v2 API", Channel: "C123", TimeStamp: "100.3", ThreadTimeStamp: "100.1",
+	})
+	if _, err := os.Stat(filepath.Join(tmpDir, "my-plan.feedback.md")); err != nil {
+		t.Errorf("feedback from an operator should be written: %v", err)
+	}
+}
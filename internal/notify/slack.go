@@ -1,8 +1,11 @@
 package notify
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/arvesolland/ralph/internal/i18n"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/runner"
@@ -11,10 +14,13 @@ import (
 
 // SlackNotifier sends notifications via the Slack Bot API with thread tracking.
 // If bot_token is not configured, it falls back to WebhookNotifier.
+// Plans with a **Notify:** header are routed to that channel instead of the default.
 type SlackNotifier struct {
 	client        *slack.Client
 	channel       string
 	threadTracker *ThreadTracker
+	templates     *Templates
+	plainText     bool
 
 	// fallback is used when bot_token is not configured
 	fallback *WebhookNotifier
@@ -26,6 +32,18 @@ type SlackNotifierConfig struct {
 	Channel       string
 	WebhookURL    string
 	ThreadTracker *ThreadTracker
+
+	// WebhookSecret signs webhook payloads (see WebhookNotifierConfig).
+	WebhookSecret string
+
+	// WebhookSpoolPath keeps undelivered webhook payloads (empty = dropped).
+	WebhookSpoolPath string
+
+	// Templates overrides the default messages (nil = defaults).
+	Templates *Templates
+
+	// PlainText sends messages as plain text instead of blocks.
+	PlainText bool
 }
 
 // NewSlackNotifier creates a new SlackNotifier.
@@ -38,12 +56,20 @@ func NewSlackNotifier(cfg SlackNotifierConfig) Notifier {
 			client:        slack.New(cfg.BotToken),
 			channel:       cfg.Channel,
 			threadTracker: cfg.ThreadTracker,
+			templates:     cfg.Templates,
+			plainText:     cfg.PlainText,
 		}
 	}
 
 	// Fall back to webhook
 	if cfg.WebhookURL != "" {
-		return NewWebhookNotifier(cfg.WebhookURL)
+		return NewWebhookNotifierWithConfig(WebhookNotifierConfig{
+			URL:       cfg.WebhookURL,
+			Secret:    cfg.WebhookSecret,
+			SpoolPath: cfg.WebhookSpoolPath,
+			Templates: cfg.Templates,
+			PlainText: cfg.PlainText,
+		})
 	}
 
 	// No configuration, return noop
@@ -54,32 +80,37 @@ func NewSlackNotifier(cfg SlackNotifierConfig) Notifier {
 func (s *SlackNotifier) Start(p *plan.Plan) error {
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(":rocket: *Plan Started*\n`%s`", p.Name), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf(":rocket: *Plan Started*\n`%s`", p.Name), false, false),
 			nil, nil,
 		),
 		slack.NewSectionBlock(nil,
 			[]*slack.TextBlockObject{
-				slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Branch:*\n`%s`", p.Branch), false, false),
+				slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf("*Branch:*\n`%s`", p.Branch), false, false),
 			},
 			nil,
 		),
 	}
+	blocks = s.applyTemplate(blocks, newTemplateContext(TemplateStart, p))
 
 	// Post message to channel (this creates the thread)
-	_, ts, err := s.postMessage(blocks)
+	channel, key := s.channelFor(p)
+	channelID, ts, err := s.postMessage(channel, blocks)
 	if err != nil {
 		log.Debug("Failed to send Slack start notification: %v", err)
 		return nil // Don't fail plan execution for notification errors
 	}
+	if channelID == "" {
+		channelID = channel
+	}
 
 	// Save thread info for future messages
 	if s.threadTracker != nil && ts != "" {
 		info := &ThreadInfo{
 			PlanName:  p.Name,
 			ThreadTS:  ts,
-			ChannelID: s.channel,
+			ChannelID: channelID,
 		}
-		if err := s.threadTracker.Set(p.Name, info); err != nil {
+		if err := s.threadTracker.Set(key, info); err != nil {
 			log.Debug("Failed to save thread info: %v", err)
 		}
 	}
@@ -88,19 +119,12 @@ func (s *SlackNotifier) Start(p *plan.Plan) error {
 }
 
 // Complete sends a notification when a plan completes.
-func (s *SlackNotifier) Complete(p *plan.Plan, prURL string) error {
-	text := fmt.Sprintf(":white_check_mark: *Plan Complete*\n`%s`", p.Name)
+func (s *SlackNotifier) Complete(p *plan.Plan, c Completion) error {
+	text := i18n.Sprintf(":white_check_mark: *Plan Complete*\n`%s`", p.Name)
 
-	fields := []*slack.TextBlockObject{
-		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Branch:*\n`%s`", p.Branch), false, false),
-	}
-
-	if prURL != "" {
-		fields = append(fields, slack.NewTextBlockObject(
-			slack.MarkdownType,
-			fmt.Sprintf("*Pull Request:*\n<%s|View PR>", prURL),
-			false, false,
-		))
+	var fields []*slack.TextBlockObject
+	for _, field := range completionFields(p, c) {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, field, false, false))
 	}
 
 	blocks := []slack.Block{
@@ -110,8 +134,9 @@ func (s *SlackNotifier) Complete(p *plan.Plan, prURL string) error {
 		),
 		slack.NewSectionBlock(nil, fields, nil),
 	}
+	blocks = s.applyTemplate(blocks, completeContext(p, c))
 
-	s.postMessageInThread(p.Name, blocks)
+	s.postMessageInThread(p, blocks)
 	return nil
 }
 
@@ -122,9 +147,11 @@ func (s *SlackNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 		return nil
 	}
 
+	_, key := s.channelFor(p)
+
 	// Check if this blocker has already been notified
 	if s.threadTracker != nil {
-		if s.threadTracker.HasNotifiedBlocker(p.Name, blocker.Hash) {
+		if s.threadTracker.HasNotifiedBlocker(key, blocker.Hash) {
 			log.Debug("Blocker already notified (hash: %s), skipping", blocker.Hash)
 			return nil
 		}
@@ -137,34 +164,35 @@ func (s *SlackNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(":warning: *Human Input Required*\n`%s`", p.Name), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf(":warning: *Human Input Required*\n`%s`", p.Name), false, false),
 			nil, nil,
 		),
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Description:*\n%s", blockerText), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf("*Description:*\n%s", blockerText), false, false),
 			nil, nil,
 		),
 	}
 
 	if blocker.Action != "" {
 		blocks = append(blocks, slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Action Required:*\n%s", blocker.Action), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf("*Action Required:*\n%s", blocker.Action), false, false),
 			nil, nil,
 		))
 	}
 
 	if blocker.Resume != "" {
 		blocks = append(blocks, slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*On Resume:*\n%s", blocker.Resume), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf("*On Resume:*\n%s", blocker.Resume), false, false),
 			nil, nil,
 		))
 	}
+	blocks = s.applyTemplate(blocks, blockerContext(p, blocker))
 
-	s.postMessageInThread(p.Name, blocks)
+	s.postMessageInThread(p, blocks)
 
 	// Mark blocker as notified
 	if s.threadTracker != nil {
-		if _, err := s.threadTracker.AddNotifiedBlocker(p.Name, blocker.Hash); err != nil {
+		if _, err := s.threadTracker.AddNotifiedBlocker(key, blocker.Hash); err != nil {
 			log.Debug("Failed to mark blocker as notified: %v", err)
 		}
 	}
@@ -185,63 +213,235 @@ func (s *SlackNotifier) Error(p *plan.Plan, err error) error {
 
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(":x: *Plan Error*\n`%s`", p.Name), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf(":x: *Plan Error*\n`%s`", p.Name), false, false),
 			nil, nil,
 		),
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*Error:*\n```%s```", errMsg), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf("*Error:*\n```%s```", errMsg), false, false),
 			nil, nil,
 		),
 	}
+	blocks = s.applyTemplate(blocks, errorContext(p, errMsg))
 
-	s.postMessageInThread(p.Name, blocks)
+	s.postMessageInThread(p, blocks)
 	return nil
 }
 
 // Iteration sends a notification for each iteration (if enabled).
-func (s *SlackNotifier) Iteration(p *plan.Plan, iteration, maxIterations int) error {
+func (s *SlackNotifier) Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, iterationText(p, iteration, maxIterations, eta), false, false),
+			nil, nil,
+		),
+	}
+	blocks = s.applyTemplate(blocks, iterationContext(p, iteration, maxIterations, eta))
+
+	s.postMessageInThread(p, blocks)
+	return nil
+}
+
+// StageChange sends a notification when a plan moves from one stage to the next.
+func (s *SlackNotifier) StageChange(p *plan.Plan, from, to string) error {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, stageChangeText(p, from, to), false, false),
+			nil, nil,
+		),
+	}
+
+	s.postMessageInThread(p, blocks)
+	return nil
+}
+
+// BlockerEscalation re-notifies about a blocker that is still unresolved, in
+// the plan's thread and broadcast to the channel so the mention is seen.
+func (s *SlackNotifier) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	if blocker == nil {
+		return nil
+	}
+
+	var blocks []slack.Block
+	for _, text := range escalationText(p, blocker, unresolved, mention) {
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil))
+	}
+
+	s.postMessageInThread(p, blocks, slack.MsgOptionBroadcast())
+	return nil
+}
+
+// ApprovalRequest posts an approval request with Approve and Reject buttons
+// in the plan's thread, broadcast to the channel. The buttons are handled by
+// the Socket Mode bot.
+func (s *SlackNotifier) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	var fields []*slack.TextBlockObject
+	for _, field := range completionFields(p, c) {
+		fields = append(fields, slack.NewTextBlockObject(slack.MarkdownType, field, false, false))
+	}
+
+	approve := slack.NewButtonBlockElement(ApproveActionID, p.Name, slack.NewTextBlockObject(slack.PlainTextType, i18n.T("Approve"), false, false))
+	approve.Style = slack.StylePrimary
+	reject := slack.NewButtonBlockElement(RejectActionID, p.Name, slack.NewTextBlockObject(slack.PlainTextType, i18n.T("Reject"), false, false))
+	reject.Style = slack.StyleDanger
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, approvalText(p, timeout), false, false), nil, nil),
+		slack.NewSectionBlock(nil, fields, nil),
+		slack.NewActionBlock("ralph_approval", approve, reject),
+	}
+
+	s.postMessageInThread(p, blocks, slack.MsgOptionBroadcast())
+	return nil
+}
+
+// UrgentFeedback sends a notification when urgent feedback stays unprocessed.
+func (s *SlackNotifier) UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf(":hourglass_flowing_sand: *Iteration %d/%d*\n`%s`", iteration, maxIterations, p.Name), false, false),
+			slack.NewTextBlockObject(slack.MarkdownType, i18n.Sprintf(":rotating_light: *Urgent Feedback Unprocessed*\n`%s`", p.Name), false, false),
+			nil, nil,
+		),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, formatFeedbackEntries(entries), false, false),
 			nil, nil,
 		),
 	}
 
-	s.postMessageInThread(p.Name, blocks)
+	s.postMessageInThread(p, blocks)
 	return nil
 }
 
+// Digest posts a single summary message to the default channel.
+func (s *SlackNotifier) Digest(summary *DigestSummary) error {
+	if summary == nil || summary.Empty() {
+		return nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, formatDigest(summary), false, false),
+			nil, nil,
+		),
+	}
+
+	if _, _, err := s.postMessage(s.channel, blocks); err != nil {
+		return fmt.Errorf("posting digest: %w", err)
+	}
+	return nil
+}
+
+// Coalesced posts a summary of rate limited notifications to the default channel.
+func (s *SlackNotifier) Coalesced(summary *CoalescedSummary) error {
+	if summary == nil || summary.Empty() {
+		return nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, formatCoalesced(summary), false, false),
+			nil, nil,
+		),
+	}
+
+	if _, _, err := s.postMessage(s.channel, blocks); err != nil {
+		return fmt.Errorf("posting rate limit summary: %w", err)
+	}
+	return nil
+}
+
+// applyTemplate returns the user's template for the event rendered as
+// blocks, or the default blocks if there is none.
+func (s *SlackNotifier) applyTemplate(blocks []slack.Block, ctx TemplateContext) []slack.Block {
+	if rendered, ok := s.templates.render(ctx); ok {
+		return rendered.slackBlocks()
+	}
+	return blocks
+}
+
+// channelFor returns the channel for a plan's notifications and its thread tracker key.
+func (s *SlackNotifier) channelFor(p *plan.Plan) (string, string) {
+	key := PlanThreadKey(p, s.channel)
+	if key == p.Name {
+		return s.channel, key
+	}
+	return p.Notify, key
+}
+
 // postMessage posts a message to the channel and returns the channel ID and timestamp.
-func (s *SlackNotifier) postMessage(blocks []slack.Block) (string, string, error) {
-	channel, ts, err := s.client.PostMessage(
-		s.channel,
-		slack.MsgOptionBlocks(blocks...),
-	)
-	return channel, ts, err
+func (s *SlackNotifier) postMessage(channel string, blocks []slack.Block) (string, string, error) {
+	channelID, ts, err := s.client.PostMessage(channel, s.messageOptions(blocks)...)
+	return channelID, ts, err
 }
 
-// postMessageInThread posts a message as a reply to the plan's thread.
-// If no thread exists for the plan, posts to the channel directly.
-func (s *SlackNotifier) postMessageInThread(planName string, blocks []slack.Block) {
+// postMessageInThread posts a message as a reply to the plan's thread, with
+// threadOpts. If no thread exists for the plan, posts to the channel directly.
+func (s *SlackNotifier) postMessageInThread(p *plan.Plan, blocks []slack.Block, threadOpts ...slack.MsgOption) {
+	channel, key := s.channelFor(p)
+
 	go func() {
 		var threadTS string
 		if s.threadTracker != nil {
-			if info := s.threadTracker.Get(planName); info != nil {
+			if info := s.threadTracker.Get(key); info != nil {
 				threadTS = info.ThreadTS
+				if info.ChannelID != "" {
+					channel = info.ChannelID
+				}
 			}
 		}
 
-		opts := []slack.MsgOption{slack.MsgOptionBlocks(blocks...)}
+		opts := s.messageOptions(blocks)
 		if threadTS != "" {
 			opts = append(opts, slack.MsgOptionTS(threadTS))
+			opts = append(opts, threadOpts...)
 		}
 
-		_, _, err := s.client.PostMessage(s.channel, opts...)
+		_, _, err := s.client.PostMessage(channel, opts...)
 		if err != nil {
 			log.Debug("Failed to send Slack notification: %v", err)
 		}
 	}()
 }
 
-// Ensure SlackNotifier implements Notifier.
+// messageOptions returns the options that send blocks as the message, or
+// their plain text version with slack.plain_text. Secrets are masked.
+func (s *SlackNotifier) messageOptions(blocks []slack.Block) []slack.MsgOption {
+	if !s.plainText {
+		return []slack.MsgOption{slack.MsgOptionBlocks(redactBlocks(blocks)...)}
+	}
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		log.Debug("Failed to flatten Slack blocks to plain text: %v", err)
+	}
+	return []slack.MsgOption{
+		slack.MsgOptionText(log.Redact(plainTextMessage(data, "")), false),
+		slack.MsgOptionDisableMarkdown(),
+	}
+}
+
+// redactBlocks masks secrets in the text of message blocks.
+func redactBlocks(blocks []slack.Block) []slack.Block {
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		return blocks
+	}
+	redacted := log.Redact(string(data))
+	if redacted == string(data) {
+		return blocks
+	}
+
+	var out slack.Blocks
+	if err := json.Unmarshal([]byte(redacted), &out); err != nil {
+		log.Debug("Failed to rebuild redacted Slack blocks: %v", err)
+		return blocks
+	}
+	return out.BlockSet
+}
+
+// Ensure SlackNotifier implements Notifier, DigestSender, and CoalescedSender.
 var _ Notifier = (*SlackNotifier)(nil)
+var _ DigestSender = (*SlackNotifier)(nil)
+var _ CoalescedSender = (*SlackNotifier)(nil)
@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// SpoolFilename is the name of the file undelivered webhook payloads are kept in.
+const SpoolFilename = "webhook_spool.jsonl"
+
+// SpoolLockFileName is the name of the lock file next to the spool, held
+// while the spool is updated.
+const SpoolLockFileName = SpoolFilename + ".lock"
+
+// maxSpooledPayloads caps the spool; the oldest payloads are dropped first.
+const maxSpooledPayloads = 1000
+
+// SpoolPath returns the webhook spool path in a config directory (e.g. ".ralph").
+func SpoolPath(configDir string) string {
+	return filepath.Join(configDir, SpoolFilename)
+}
+
+// webhookSpool keeps undelivered webhook payloads, one JSON body per line.
+type webhookSpool struct {
+	path string
+
+	// mu serializes reads and writes of the spool file within the process;
+	// lock serializes them across processes.
+	mu sync.Mutex
+}
+
+// lock takes the cross-process lock on the spool, so two workers on the
+// same repository don't lose each other's payloads. The returned func
+// releases it.
+func (s *webhookSpool) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(filepath.Dir(s.path), SpoolLockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock spool: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// add appends a payload body to the spool.
+func (s *webhookSpool) add(body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	bodies, err := s.read()
+	if err != nil {
+		return err
+	}
+	bodies = append(bodies, body)
+	if len(bodies) > maxSpooledPayloads {
+		log.Warn("Webhook spool is full, dropping %d oldest payload(s)", len(bodies)-maxSpooledPayloads)
+		bodies = bodies[len(bodies)-maxSpooledPayloads:]
+	}
+	return s.write(bodies)
+}
+
+// replay attempts delivery of each spooled payload once with post, in order,
+// keeping the ones that fail.
+func (s *webhookSpool) replay(post func(body []byte) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		log.Warn("Failed to lock webhook spool: %v", err)
+		return
+	}
+	defer unlock()
+
+	bodies, err := s.read()
+	if err != nil {
+		log.Warn("Failed to read webhook spool: %v", err)
+		return
+	}
+	if len(bodies) == 0 {
+		return
+	}
+
+	var failed [][]byte
+	for _, body := range bodies {
+		if err := post(body); err != nil {
+			log.Debug("Failed to replay spooled webhook payload: %v", err)
+			failed = append(failed, body)
+		}
+	}
+	if delivered := len(bodies) - len(failed); delivered > 0 {
+		log.Info("Delivered %d spooled webhook payload(s)", delivered)
+	}
+	if err := s.write(failed); err != nil {
+		log.Warn("Failed to update webhook spool: %v", err)
+	}
+}
+
+// read returns the spooled payload bodies. A missing spool is empty.
+func (s *webhookSpool) read() ([][]byte, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open spool: %w", err)
+	}
+	defer f.Close()
+
+	var bodies [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			bodies = append(bodies, append([]byte(nil), line...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+	return bodies, nil
+}
+
+// write replaces the spool with bodies. An empty spool is removed.
+func (s *webhookSpool) write(bodies [][]byte) error {
+	if len(bodies) == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove spool: %w", err)
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, body := range bodies {
+		buf.Write(body)
+		buf.WriteByte('\n')
+	}
+
+	return plan.WriteFileAtomic(s.path, buf.Bytes(), 0600)
+}
@@ -0,0 +1,272 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/slack-go/slack"
+)
+
+// TemplatesDir is the directory in the config directory (e.g. ".ralph") that
+// holds notification template overrides.
+const TemplatesDir = "notify-templates"
+
+// Events with customizable templates. A template file is named after its
+// event: "<event>.tmpl" renders Slack mrkdwn text for a single section block,
+// and "<event>.json.tmpl" renders a JSON array of Block Kit blocks. If both
+// exist, the Block Kit template is used.
+const (
+	TemplateStart     = "start"
+	TemplateComplete  = "complete"
+	TemplateBlocker   = "blocker"
+	TemplateError     = "error"
+	TemplateIteration = "iteration"
+)
+
+// templateEvents lists the events with customizable templates.
+var templateEvents = []string{TemplateStart, TemplateComplete, TemplateBlocker, TemplateError, TemplateIteration}
+
+// TemplateContext is the data notification templates are executed with.
+// Fields that don't apply to an event are zero.
+type TemplateContext struct {
+	// Event is the event being notified, e.g. "complete".
+	Event string
+
+	// Plan is the plan's name, branch, and status.
+	Plan PlanContext
+
+	// PRURL is the pull request URL (complete).
+	PRURL string
+
+	// BranchURL is the plan branch's web page on origin, if it was pushed
+	// (complete).
+	BranchURL string
+
+	// Iteration and MaxIterations are the current and maximum iteration
+	// (iteration), or the iterations used (complete).
+	Iteration     int
+	MaxIterations int
+
+	// ETA is the estimated time remaining, e.g. "ETA: ~12m" (iteration).
+	ETA string
+
+	// Duration is the plan's total iteration time, e.g. "21m0s" (complete).
+	Duration string
+
+	// Changes summarizes the files changed, e.g. "3 files, +120 -8" (complete).
+	Changes string
+
+	// Gates summarizes the completion gates (complete).
+	Gates string
+
+	// Blocker is the blocker's description, action, and resume note (blocker).
+	Blocker BlockerContext
+
+	// Error is the error message, truncated to 500 characters (error).
+	Error string
+}
+
+// PlanContext describes the plan in a TemplateContext.
+type PlanContext struct {
+	Name   string
+	Branch string
+	Status string
+}
+
+// BlockerContext describes a blocker in a TemplateContext.
+type BlockerContext struct {
+	Description string
+	Action      string
+	Resume      string
+}
+
+// newTemplateContext returns the context for an event about p.
+func newTemplateContext(event string, p *plan.Plan) TemplateContext {
+	return TemplateContext{
+		Event: event,
+		Plan:  PlanContext{Name: p.Name, Branch: p.Branch, Status: p.Status},
+	}
+}
+
+// completeContext returns the template context for a completion.
+func completeContext(p *plan.Plan, c Completion) TemplateContext {
+	ctx := newTemplateContext(TemplateComplete, p)
+	ctx.PRURL = c.PRURL
+	ctx.BranchURL = c.BranchURL
+	ctx.Iteration = c.Iterations
+	ctx.MaxIterations = c.MaxIterations
+	if c.Duration > 0 {
+		ctx.Duration = c.Duration.Round(time.Second).String()
+	}
+	ctx.Changes = changesText(p)
+	ctx.Gates = gatesText(c.Gates)
+	return ctx
+}
+
+// blockerContext returns the template context for a blocker.
+func blockerContext(p *plan.Plan, blocker *runner.Blocker) TemplateContext {
+	ctx := newTemplateContext(TemplateBlocker, p)
+	description := blocker.Description
+	if description == "" {
+		description = blocker.Content
+	}
+	ctx.Blocker = BlockerContext{Description: description, Action: blocker.Action, Resume: blocker.Resume}
+	return ctx
+}
+
+// errorContext returns the template context for an error message.
+func errorContext(p *plan.Plan, errMsg string) TemplateContext {
+	ctx := newTemplateContext(TemplateError, p)
+	ctx.Error = errMsg
+	return ctx
+}
+
+// iterationContext returns the template context for an iteration update.
+func iterationContext(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) TemplateContext {
+	ctx := newTemplateContext(TemplateIteration, p)
+	ctx.Iteration = iteration
+	ctx.MaxIterations = maxIterations
+	if eta != nil {
+		ctx.ETA = eta.String()
+	}
+	return ctx
+}
+
+// templateFuncs are the functions available to templates. json quotes a
+// value for use in Block Kit templates, e.g. {"type": "plain_text", "text": {{json .Error}}}.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Templates holds the user's notification template overrides.
+// A nil Templates has no overrides.
+type Templates struct {
+	text   map[string]*template.Template
+	blocks map[string]*template.Template
+}
+
+// LoadTemplates parses the template overrides in dir. Returns nil if dir
+// doesn't exist or has no templates. A template that fails to parse is an
+// error, so mistakes show up at startup instead of as missing messages.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{
+		text:   make(map[string]*template.Template),
+		blocks: make(map[string]*template.Template),
+	}
+	for _, event := range templateEvents {
+		for _, kind := range []struct {
+			suffix string
+			into   map[string]*template.Template
+		}{
+			{".tmpl", t.text},
+			{".json.tmpl", t.blocks},
+		} {
+			path := filepath.Join(dir, event+kind.suffix)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to read template: %w", err)
+			}
+			tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+			}
+			kind.into[event] = tmpl
+		}
+	}
+	if len(t.text) == 0 && len(t.blocks) == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// renderedTemplate is a rendered notification: mrkdwn text, or Block Kit
+// blocks as a JSON array.
+type renderedTemplate struct {
+	text   string
+	blocks json.RawMessage
+}
+
+// render executes the override for ctx.Event. Returns false if there is no
+// override or it fails, in which case the baked-in message is used.
+func (t *Templates) render(ctx TemplateContext) (renderedTemplate, bool) {
+	if t == nil {
+		return renderedTemplate{}, false
+	}
+
+	if tmpl, ok := t.blocks[ctx.Event]; ok {
+		out, err := execute(tmpl, ctx)
+		if err == nil {
+			var blocks slack.Blocks
+			if err = json.Unmarshal([]byte(out), &blocks); err == nil && len(blocks.BlockSet) == 0 {
+				err = fmt.Errorf("no blocks")
+			}
+		}
+		if err == nil {
+			return renderedTemplate{blocks: json.RawMessage(out)}, true
+		}
+		log.Warn("Notification template %s is not a Block Kit array, using the default: %v", tmpl.Name(), err)
+		return renderedTemplate{}, false
+	}
+
+	if tmpl, ok := t.text[ctx.Event]; ok {
+		out, err := execute(tmpl, ctx)
+		if err == nil && out != "" {
+			return renderedTemplate{text: out}, true
+		}
+		if err == nil {
+			err = fmt.Errorf("empty output")
+		}
+		log.Warn("Notification template %s failed, using the default: %v", tmpl.Name(), err)
+	}
+	return renderedTemplate{}, false
+}
+
+// execute runs tmpl with ctx and returns the trimmed output.
+func execute(tmpl *template.Template, ctx TemplateContext) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// slackBlocks returns the rendered notification as Bot API blocks.
+func (r renderedTemplate) slackBlocks() []slack.Block {
+	if r.blocks != nil {
+		var blocks slack.Blocks
+		if err := json.Unmarshal(r.blocks, &blocks); err == nil {
+			return blocks.BlockSet
+		}
+	}
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, r.text, false, false), nil, nil),
+	}
+}
+
+// apply replaces a webhook payload's Slack message with the rendered one.
+func (r renderedTemplate) apply(payload *webhookPayload) {
+	payload.Attachments = nil
+	if r.blocks != nil {
+		payload.Text = ""
+		payload.Blocks = r.blocks
+		return
+	}
+	payload.Text = r.text
+	blocks, _ := json.Marshal([]slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: r.text}}})
+	payload.Blocks = blocks
+}
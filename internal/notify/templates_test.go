@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/slack-go/slack"
+)
+
+// writeTemplates writes template files to a temp dir and loads them.
+func writeTemplates(t *testing.T, files map[string]string) *Templates {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	templates, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+	return templates
+}
+
+func TestLoadTemplates_None(t *testing.T) {
+	templates, err := LoadTemplates(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || templates != nil {
+		t.Errorf("LoadTemplates() = %v, %v, want nil, nil", templates, err)
+	}
+
+	// A nil Templates has no overrides
+	if _, ok := templates.render(newTemplateContext(TemplateStart, &plan.Plan{Name: "alpha"})); ok {
+		t.Error("nil Templates should not render")
+	}
+}
+
+func TestLoadTemplates_ParseError(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "start.tmpl"), []byte("{{.Plan.Name"), 0644)
+
+	if _, err := LoadTemplates(dir); err == nil || !strings.Contains(err.Error(), "start.tmpl") {
+		t.Errorf("LoadTemplates() error = %v, want a parse error naming the file", err)
+	}
+}
+
+func TestTemplates_RenderText(t *testing.T) {
+	templates := writeTemplates(t, map[string]string{
+		"complete.tmpl": ":tada: *{{.Plan.Name}}* shipped in {{.Iteration}}/{{.MaxIterations}} iterations{{if .PRURL}} — <{{.PRURL}}|PR>{{end}}\n",
+	})
+
+	p := &plan.Plan{Name: "alpha", Branch: "feat/alpha"}
+	rendered, ok := templates.render(completeContext(p, Completion{PRURL: "https://github.com/o/r/pull/1", Iterations: 3, MaxIterations: 30}))
+	if !ok {
+		t.Fatal("render() found no template")
+	}
+	if want := ":tada: *alpha* shipped in 3/30 iterations — <https://github.com/o/r/pull/1|PR>"; rendered.text != want {
+		t.Errorf("text = %q, want %q", rendered.text, want)
+	}
+
+	// Events without a template use the defaults
+	if _, ok := templates.render(newTemplateContext(TemplateStart, p)); ok {
+		t.Error("start has no template")
+	}
+}
+
+func TestTemplates_RenderBlocks(t *testing.T) {
+	templates := writeTemplates(t, map[string]string{
+		"error.tmpl":      "unused: {{.Error}}",
+		"error.json.tmpl": `[{"type": "header", "text": {"type": "plain_text", "text": {{json .Plan.Name}}}}, {"type": "section", "text": {"type": "mrkdwn", "text": {{json .Error}}}}]`,
+	})
+
+	rendered, ok := templates.render(errorContext(&plan.Plan{Name: "alpha"}, `exit status 1: "quoted"`))
+	if !ok || rendered.blocks == nil {
+		t.Fatalf("render() = %+v, %t, want blocks", rendered, ok)
+	}
+	blocks := rendered.slackBlocks()
+	if len(blocks) != 2 || blocks[0].BlockType() != slack.MBTHeader {
+		t.Fatalf("blocks = %+v", blocks)
+	}
+	if section := blocks[1].(*slack.SectionBlock); section.Text.Text != `exit status 1: "quoted"` {
+		t.Errorf("section text = %q", section.Text.Text)
+	}
+}
+
+func TestTemplates_InvalidBlocksFallBack(t *testing.T) {
+	templates := writeTemplates(t, map[string]string{
+		"start.json.tmpl": `{"type": "section"}`,
+		"error.tmpl":      "{{.Nope}}",
+	})
+
+	p := &plan.Plan{Name: "alpha"}
+	if _, ok := templates.render(newTemplateContext(TemplateStart, p)); ok {
+		t.Error("a template that isn't a block array should fall back to the default")
+	}
+	if _, ok := templates.render(errorContext(p, "boom")); ok {
+		t.Error("a template that fails to execute should fall back to the default")
+	}
+}
+
+func TestBlockerContext(t *testing.T) {
+	ctx := blockerContext(&plan.Plan{Name: "alpha"}, &runner.Blocker{Content: "Need credentials", Action: "Add the key"})
+	if ctx.Event != TemplateBlocker || ctx.Blocker.Description != "Need credentials" || ctx.Blocker.Action != "Add the key" {
+		t.Errorf("context = %+v", ctx)
+	}
+}
+
+func TestSlackNotifier_ApplyTemplate(t *testing.T) {
+	s := &SlackNotifier{templates: writeTemplates(t, map[string]string{
+		"iteration.tmpl": "{{.Plan.Name}} {{.Iteration}}/{{.MaxIterations}}",
+	})}
+	defaults := []slack.Block{slack.NewDividerBlock()}
+
+	p := &plan.Plan{Name: "alpha"}
+	blocks := s.applyTemplate(defaults, iterationContext(p, 2, 10, nil))
+	section, ok := blocks[0].(*slack.SectionBlock)
+	if len(blocks) != 1 || !ok || section.Text.Text != "alpha 2/10" {
+		t.Errorf("blocks = %+v", blocks)
+	}
+
+	if blocks := s.applyTemplate(defaults, errorContext(p, "boom")); len(blocks) != 1 || blocks[0] != defaults[0] {
+		t.Errorf("blocks without a template = %+v, want the defaults", blocks)
+	}
+}
+
+func TestWebhookNotifier_Template(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifierWithConfig(WebhookNotifierConfig{
+		URL:       server.URL,
+		Templates: writeTemplates(t, map[string]string{"error.tmpl": ":boom: {{.Plan.Name}}: {{.Error}}"}),
+	})
+	if err := n.Error(&plan.Plan{Name: "alpha"}, errors.New("disk full")); err != nil {
+		t.Fatal(err)
+	}
+
+	var body []byte
+	select {
+	case body = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+
+	var got struct {
+		Event  string         `json:"event"`
+		Text   string         `json:"text"`
+		Blocks []slackBlock   `json:"blocks"`
+		Data   map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "plan_error" || got.Text != ":boom: alpha: disk full" || got.Data["error"] != "disk full" {
+		t.Errorf("payload = %+v", got)
+	}
+	if len(got.Blocks) != 1 || got.Blocks[0].Text.Text != got.Text {
+		t.Errorf("blocks = %+v, want one section with the template text", got.Blocks)
+	}
+}
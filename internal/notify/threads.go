@@ -6,13 +6,82 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
 )
 
 // ThreadsFilename is the name of the file that stores thread information.
 const ThreadsFilename = "slack_threads.json"
 
+// threadKeySep separates the plan name from the channel in tracker keys.
+const threadKeySep = "|"
+
+// ThreadKey returns the tracker key for a plan's thread in a channel.
+// Plans notified in the default channel use the plain plan name, so existing
+// entries keep working; channel overrides are keyed by plan+channel.
+func ThreadKey(planName, channel string) string {
+	if channel == "" {
+		return planName
+	}
+	return planName + threadKeySep + channel
+}
+
+// PlanThreadKey returns the tracker key for a plan given the default channel.
+// A **Notify:** override equal to the default channel is treated as no override.
+func PlanThreadKey(p *plan.Plan, defaultChannel string) string {
+	if p.Notify == "" || p.Notify == defaultChannel {
+		return p.Name
+	}
+	return ThreadKey(p.Name, p.Notify)
+}
+
+// planNameFromKey returns the plan name part of a tracker key.
+func planNameFromKey(key string) string {
+	if i := strings.Index(key, threadKeySep); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// Defaults for thread tracker pruning.
+const (
+	// DefaultThreadRetentionDays is how long entries for completed plans are kept.
+	DefaultThreadRetentionDays = 30
+
+	// DefaultMaxThreads caps the number of tracked entries.
+	DefaultMaxThreads = 500
+
+	// DefaultMaxNotifiedBlockers caps the blocker hashes kept per entry.
+	DefaultMaxNotifiedBlockers = 50
+)
+
+// PruneOptions controls ThreadTracker.Prune.
+// Zero values disable the corresponding limit.
+type PruneOptions struct {
+	// MaxAge removes entries for plans completed longer ago than this.
+	MaxAge time.Duration
+
+	// MaxEntries caps the number of entries; the oldest are removed first,
+	// completed plans before in-progress ones.
+	MaxEntries int
+
+	// MaxNotifiedBlockers caps the blocker hashes kept per entry (most recent kept).
+	MaxNotifiedBlockers int
+}
+
+// PruneResult reports what Prune changed.
+type PruneResult struct {
+	// Removed lists the plan names whose entries were removed.
+	Removed []string
+
+	// Compacted is the number of entries whose NotifiedBlockers were compacted.
+	Compacted int
+}
+
 // ThreadInfo contains Slack thread information for a plan.
 type ThreadInfo struct {
 	// PlanName is the name of the plan this thread is associated with.
@@ -28,6 +97,12 @@ type ThreadInfo struct {
 	// Used to prevent duplicate notifications for the same blocker.
 	NotifiedBlockers []string `json:"notified_blockers,omitempty"`
 
+	// PRURL is the pull request URL recorded when the plan completed.
+	PRURL string `json:"pr_url,omitempty"`
+
+	// CompletedAt is when the plan completed (zero while still in progress).
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+
 	// CreatedAt is when this thread was first created.
 	CreatedAt time.Time `json:"created_at"`
 
@@ -90,6 +165,7 @@ func (t *ThreadTracker) Get(planName string) *ThreadInfo {
 }
 
 // Set saves thread info for a plan and persists to file.
+// planName is the tracker key (see ThreadKey); info.PlanName is set from it.
 func (t *ThreadTracker) Set(planName string, info *ThreadInfo) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -100,7 +176,7 @@ func (t *ThreadTracker) Set(planName string, info *ThreadInfo) error {
 		info.CreatedAt = now
 	}
 	info.UpdatedAt = now
-	info.PlanName = planName
+	info.PlanName = planNameFromKey(planName)
 
 	// Make a copy to store
 	copy := *info
@@ -122,6 +198,25 @@ func (t *ThreadTracker) Delete(planName string) error {
 	return t.saveUnlocked()
 }
 
+// DeletePlan removes the thread info for a plan in every channel and
+// persists to file. Returns the number of entries removed.
+func (t *ThreadTracker) DeletePlan(planName string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	removed := 0
+	for key := range t.threads {
+		if planNameFromKey(key) == planName {
+			delete(t.threads, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, t.saveUnlocked()
+}
+
 // AddNotifiedBlocker adds a blocker hash to the list of notified blockers for a plan.
 // Returns true if the hash was added (wasn't already present).
 func (t *ThreadTracker) AddNotifiedBlocker(planName, blockerHash string) (bool, error) {
@@ -147,6 +242,26 @@ func (t *ThreadTracker) AddNotifiedBlocker(planName, blockerHash string) (bool,
 	return true, t.saveUnlocked()
 }
 
+// MarkComplete records that a plan completed, with its PR URL if any.
+// Creates an entry without a thread if the plan has none (e.g., webhook-only setups).
+func (t *ThreadTracker) MarkComplete(planName, prURL string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	info, ok := t.threads[planName]
+	if !ok {
+		info = &ThreadInfo{PlanName: planNameFromKey(planName), CreatedAt: now}
+		t.threads[planName] = info
+	}
+
+	info.PRURL = prURL
+	info.CompletedAt = now
+	info.UpdatedAt = now
+
+	return t.saveUnlocked()
+}
+
 // HasNotifiedBlocker checks if a blocker has already been notified for a plan.
 func (t *ThreadTracker) HasNotifiedBlocker(planName, blockerHash string) bool {
 	t.mu.RLock()
@@ -165,6 +280,86 @@ func (t *ThreadTracker) HasNotifiedBlocker(planName, blockerHash string) bool {
 	return false
 }
 
+// Prune removes stale entries and compacts blocker lists, then persists the result.
+// The file is only rewritten if something changed.
+func (t *ThreadTracker) Prune(opts PruneOptions) (*PruneResult, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := &PruneResult{}
+	now := time.Now()
+
+	// Remove plans completed longer ago than MaxAge
+	if opts.MaxAge > 0 {
+		cutoff := now.Add(-opts.MaxAge)
+		for name, info := range t.threads {
+			if !info.CompletedAt.IsZero() && info.CompletedAt.Before(cutoff) {
+				delete(t.threads, name)
+				result.Removed = append(result.Removed, name)
+			}
+		}
+	}
+
+	// Enforce the entry cap, oldest completed plans first
+	if opts.MaxEntries > 0 && len(t.threads) > opts.MaxEntries {
+		keys := make([]string, 0, len(t.threads))
+		for key := range t.threads {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			a, b := t.threads[keys[i]], t.threads[keys[j]]
+			ca, cb := !a.CompletedAt.IsZero(), !b.CompletedAt.IsZero()
+			if ca != cb {
+				return ca
+			}
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		})
+		for _, key := range keys[:len(keys)-opts.MaxEntries] {
+			delete(t.threads, key)
+			result.Removed = append(result.Removed, key)
+		}
+	}
+
+	// Compact blocker hash lists: drop duplicates and keep the most recent
+	for _, info := range t.threads {
+		compacted := compactBlockers(info.NotifiedBlockers, opts.MaxNotifiedBlockers)
+		if len(compacted) != len(info.NotifiedBlockers) {
+			info.NotifiedBlockers = compacted
+			result.Compacted++
+		}
+	}
+
+	sort.Strings(result.Removed)
+
+	if len(result.Removed) == 0 && result.Compacted == 0 {
+		return result, nil
+	}
+	return result, t.saveUnlocked()
+}
+
+// compactBlockers removes duplicate hashes (keeping the latest occurrence)
+// and keeps at most max entries, dropping the oldest. max <= 0 means no cap.
+func compactBlockers(hashes []string, max int) []string {
+	seen := make(map[string]bool, len(hashes))
+	var reversed []string
+	for i := len(hashes) - 1; i >= 0; i-- {
+		if seen[hashes[i]] {
+			continue
+		}
+		seen[hashes[i]] = true
+		reversed = append(reversed, hashes[i])
+		if max > 0 && len(reversed) == max {
+			break
+		}
+	}
+
+	compacted := make([]string, len(reversed))
+	for i, h := range reversed {
+		compacted[len(reversed)-1-i] = h
+	}
+	return compacted
+}
+
 // List returns all tracked thread infos.
 func (t *ThreadTracker) List() []*ThreadInfo {
 	t.mu.RLock()
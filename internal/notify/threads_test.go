@@ -318,6 +318,27 @@ func TestThreadTracker_Delete(t *testing.T) {
 	})
 }
 
+func TestThreadTracker_DeletePlan(t *testing.T) {
+	tracker, _ := NewThreadTracker(filepath.Join(t.TempDir(), "threads.json"))
+	tracker.Set("test-plan", &ThreadInfo{ThreadTS: "1.1", ChannelID: "C1"})
+	tracker.Set(ThreadKey("test-plan", "C2"), &ThreadInfo{ThreadTS: "2.2", ChannelID: "C2"})
+	tracker.Set("other-plan", &ThreadInfo{ThreadTS: "3.3", ChannelID: "C1"})
+
+	removed, err := tracker.DeletePlan("test-plan")
+	if err != nil {
+		t.Fatalf("DeletePlan() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("DeletePlan() = %d, want 2", removed)
+	}
+	if got := tracker.List(); len(got) != 1 || got[0].PlanName != "other-plan" {
+		t.Errorf("List() after DeletePlan = %+v, want only other-plan", got)
+	}
+	if removed, _ := tracker.DeletePlan("missing"); removed != 0 {
+		t.Errorf("DeletePlan(missing) = %d, want 0", removed)
+	}
+}
+
 func TestThreadTracker_AddNotifiedBlocker(t *testing.T) {
 	t.Run("adds new blocker hash", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -377,6 +398,43 @@ func TestThreadTracker_AddNotifiedBlocker(t *testing.T) {
 	})
 }
 
+func TestThreadTracker_MarkComplete(t *testing.T) {
+	t.Run("updates existing thread", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		tracker, _ := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
+
+		tracker.Set("test-plan", &ThreadInfo{ThreadTS: "1234567890.123456", ChannelID: "C123456"})
+
+		if err := tracker.MarkComplete("test-plan", "https://github.com/o/r/pull/1"); err != nil {
+			t.Fatalf("MarkComplete() error = %v", err)
+		}
+
+		info := tracker.Get("test-plan")
+		if info.PRURL != "https://github.com/o/r/pull/1" || info.CompletedAt.IsZero() {
+			t.Errorf("completion not recorded: %+v", info)
+		}
+		if info.ThreadTS != "1234567890.123456" {
+			t.Error("thread info should be preserved")
+		}
+	})
+
+	t.Run("creates entry for plan without thread", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "threads.json")
+		tracker, _ := NewThreadTracker(path)
+
+		if err := tracker.MarkComplete("no-thread", ""); err != nil {
+			t.Fatalf("MarkComplete() error = %v", err)
+		}
+
+		reloaded, _ := NewThreadTracker(path)
+		info := reloaded.Get("no-thread")
+		if info == nil || info.CompletedAt.IsZero() || info.ThreadTS != "" {
+			t.Errorf("expected persisted completion entry, got %+v", info)
+		}
+	})
+}
+
 func TestThreadTracker_HasNotifiedBlocker(t *testing.T) {
 	tmpDir := t.TempDir()
 	tracker, _ := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
@@ -547,3 +605,100 @@ func TestThreadTracker_AtomicWrite(t *testing.T) {
 		t.Error("data file should exist after write")
 	}
 }
+
+func TestThreadTracker_Prune(t *testing.T) {
+	t.Run("removes plans completed before max age", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "threads.json")
+		tracker, _ := NewThreadTracker(path)
+
+		tracker.Set("old", &ThreadInfo{ThreadTS: "1", CompletedAt: time.Now().Add(-40 * 24 * time.Hour)})
+		tracker.Set("recent", &ThreadInfo{ThreadTS: "2", CompletedAt: time.Now().Add(-1 * time.Hour)})
+		tracker.Set("active", &ThreadInfo{ThreadTS: "3"})
+
+		result, err := tracker.Prune(PruneOptions{MaxAge: 30 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Removed) != 1 || result.Removed[0] != "old" {
+			t.Errorf("Removed = %v, want [old]", result.Removed)
+		}
+
+		reloaded, _ := NewThreadTracker(path)
+		if reloaded.Get("old") != nil {
+			t.Error("old entry should be removed from file")
+		}
+		if reloaded.Get("recent") == nil || reloaded.Get("active") == nil {
+			t.Error("recent and active entries should be kept")
+		}
+	})
+
+	t.Run("enforces max entries, completed first", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		tracker, _ := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
+
+		tracker.Set("active-old", &ThreadInfo{ThreadTS: "1"})
+		time.Sleep(time.Millisecond)
+		tracker.Set("done-a", &ThreadInfo{ThreadTS: "2", CompletedAt: time.Now()})
+		time.Sleep(time.Millisecond)
+		tracker.Set("done-b", &ThreadInfo{ThreadTS: "3", CompletedAt: time.Now()})
+
+		result, err := tracker.Prune(PruneOptions{MaxEntries: 2})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Removed) != 1 || result.Removed[0] != "done-a" {
+			t.Errorf("Removed = %v, want [done-a]", result.Removed)
+		}
+		if tracker.Get("active-old") == nil {
+			t.Error("in-progress entry should be kept over completed ones")
+		}
+	})
+
+	t.Run("compacts notified blockers", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		tracker, _ := NewThreadTracker(filepath.Join(tmpDir, "threads.json"))
+
+		tracker.Set("test-plan", &ThreadInfo{
+			ThreadTS:         "1",
+			NotifiedBlockers: []string{"a", "b", "a", "c", "d"},
+		})
+
+		result, err := tracker.Prune(PruneOptions{MaxNotifiedBlockers: 2})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if result.Compacted != 1 {
+			t.Errorf("Compacted = %d, want 1", result.Compacted)
+		}
+
+		info := tracker.Get("test-plan")
+		if len(info.NotifiedBlockers) != 2 || info.NotifiedBlockers[0] != "c" || info.NotifiedBlockers[1] != "d" {
+			t.Errorf("NotifiedBlockers = %v, want [c d]", info.NotifiedBlockers)
+		}
+	})
+
+	t.Run("no changes does not write", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, "threads.json")
+		tracker, _ := NewThreadTracker(path)
+
+		result, err := tracker.Prune(PruneOptions{MaxAge: time.Hour, MaxEntries: 10})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Removed) != 0 || result.Compacted != 0 {
+			t.Errorf("expected no changes, got %+v", result)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("file should not be created when nothing changed")
+		}
+	})
+}
+
+func TestCompactBlockers(t *testing.T) {
+	got := compactBlockers([]string{"a", "b", "a"}, 0)
+	if len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Errorf("compactBlockers() = %v, want [b a]", got)
+	}
+}
@@ -4,11 +4,19 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/i18n"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/runner"
@@ -20,36 +28,206 @@ type Notifier interface {
 	Start(p *plan.Plan) error
 
 	// Complete sends a notification when a plan completes.
-	Complete(p *plan.Plan, prURL string) error
+	Complete(p *plan.Plan, c Completion) error
 
 	// Blocker sends a notification when a blocker is encountered.
 	Blocker(p *plan.Plan, blocker *runner.Blocker) error
 
+	// BlockerEscalation re-notifies about a blocker that has been unresolved
+	// for the given duration, mentioning mention (see mentionText).
+	BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error
+
 	// Error sends a notification when an error occurs.
 	Error(p *plan.Plan, err error) error
 
 	// Iteration sends a notification for each iteration (if enabled).
-	Iteration(p *plan.Plan, iteration, maxIterations int) error
+	// eta is the estimated time remaining, or nil if unknown.
+	Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error
+
+	// UrgentFeedback sends a notification when urgent feedback stays unprocessed.
+	UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error
+
+	// StageChange sends a notification when a plan moves from one stage to the next.
+	StageChange(p *plan.Plan, from, to string) error
+
+	// ApprovalRequest asks for approval of a completed plan before its PR or
+	// merge, within timeout (0 = no deadline).
+	ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error
+}
+
+// Completion summarizes a completed plan for the completion notification.
+// File and line counts come from the plan's changes ledger.
+type Completion struct {
+	// PRURL is the pull request URL, if one was created.
+	PRURL string
+
+	// BranchURL is the web page of the plan branch on origin, if it was
+	// pushed there.
+	BranchURL string
+
+	// Iterations is the number of iterations used, out of MaxIterations.
+	Iterations    int
+	MaxIterations int
+
+	// Duration is the total iteration wall time of the plan's latest run.
+	Duration time.Duration
+
+	// Gates are the results of the final test and lint gates, if they ran.
+	Gates []gate.Result
 }
 
 // WebhookNotifier sends notifications via Slack incoming webhooks.
+// Payloads are versioned JSON (see webhookPayload) that Slack renders from
+// the text and blocks fields, optionally signed with an HMAC secret.
+// Deliveries are retried on 5xx and network errors; payloads that still fail
+// are spooled and replayed after the next successful delivery.
 type WebhookNotifier struct {
 	webhookURL string
+	secret     string
+	templates  *Templates
+	spool      *webhookSpool
+	retrier    *runner.Retrier
 	httpClient *http.Client
+	plainText  bool
+}
+
+// WebhookNotifierConfig contains configuration for creating a WebhookNotifier.
+type WebhookNotifierConfig struct {
+	// URL is the incoming webhook URL.
+	URL string
+
+	// Secret signs payloads with HMAC-SHA256 (empty = unsigned).
+	Secret string
+
+	// SpoolPath is the file undelivered payloads are kept in (empty = dropped).
+	SpoolPath string
+
+	// Templates overrides the default messages (nil = defaults).
+	Templates *Templates
+
+	// PlainText sends the message as plain text without blocks (see
+	// plainTextMessage).
+	PlainText bool
 }
 
-// NewWebhookNotifier creates a new WebhookNotifier.
-// Returns nil if webhookURL is empty (notifications disabled).
+// NewWebhookNotifier creates a new WebhookNotifier with unsigned payloads
+// and no spool. Returns nil if webhookURL is empty (notifications disabled).
 func NewWebhookNotifier(webhookURL string) *WebhookNotifier {
-	if webhookURL == "" {
+	return NewWebhookNotifierWithConfig(WebhookNotifierConfig{URL: webhookURL})
+}
+
+// NewWebhookNotifierWithConfig creates a new WebhookNotifier.
+// Returns nil if cfg.URL is empty (notifications disabled).
+func NewWebhookNotifierWithConfig(cfg WebhookNotifierConfig) *WebhookNotifier {
+	if cfg.URL == "" {
 		return nil
 	}
-	return &WebhookNotifier{
-		webhookURL: webhookURL,
+	w := &WebhookNotifier{
+		webhookURL: cfg.URL,
+		secret:     cfg.Secret,
+		templates:  cfg.Templates,
+		plainText:  cfg.PlainText,
+		retrier:    runner.NewRetrier(runner.DefaultRetryConfig()),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
+	if cfg.SpoolPath != "" {
+		w.spool = &webhookSpool{path: cfg.SpoolPath}
+	}
+	return w
+}
+
+// WebhookSchemaVersion is the version of the webhook payload schema. It is
+// bumped when fields are removed or change meaning; new fields may be added
+// without a bump.
+const WebhookSchemaVersion = 1
+
+// SignatureHeader carries the payload's HMAC-SHA256 signature as
+// "sha256=<hex>" when slack.webhook_secret is set.
+const SignatureHeader = "X-Ralph-Signature-256"
+
+// Webhook event names not shared with the event log.
+const (
+	webhookEventUrgentFeedback = "urgent_feedback"
+	webhookEventDigest         = "digest"
+	webhookEventRateLimited    = "rate_limited"
+)
+
+// webhookPayload is the versioned JSON body sent to the webhook. The Slack
+// message fields (text, blocks, attachments) keep the payload displayable by
+// Slack incoming webhooks; other receivers can use the structured fields.
+// Blocks are raw JSON so user templates can use any Block Kit block.
+type webhookPayload struct {
+	Version     int               `json:"version"`
+	ID          string            `json:"id"`
+	Event       string            `json:"event"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Plan        *webhookPlan      `json:"plan,omitempty"`
+	Links       map[string]string `json:"links,omitempty"`
+	Data        map[string]any    `json:"data,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Blocks      json.RawMessage   `json:"blocks,omitempty"`
+	Attachments []attachment      `json:"attachments,omitempty"`
+
+	// Mrkdwn is false for plain text messages, so Slack shows them as written.
+	Mrkdwn *bool `json:"mrkdwn,omitempty"`
+}
+
+// webhookPlan identifies the plan a webhook payload is about.
+type webhookPlan struct {
+	Name   string `json:"name"`
+	Branch string `json:"branch,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// newWebhookPayload builds the payload for event about p (nil for
+// summaries of several plans), displayed in Slack as msg.
+func newWebhookPayload(event string, p *plan.Plan, msg slackMessage) *webhookPayload {
+	payload := &webhookPayload{
+		Version:     WebhookSchemaVersion,
+		ID:          newPayloadID(),
+		Event:       event,
+		Timestamp:   time.Now().UTC(),
+		Text:        msg.Text,
+		Attachments: msg.Attachments,
+	}
+	if len(msg.Blocks) > 0 {
+		if blocks, err := json.Marshal(msg.Blocks); err == nil {
+			payload.Blocks = blocks
+		}
+	}
+	if p != nil {
+		payload.Plan = &webhookPlan{Name: p.Name, Branch: p.Branch, Status: p.Status}
+	}
+	return payload
+}
+
+// toPlainText replaces the payload's Slack message with its plain text
+// version.
+func (p *webhookPayload) toPlainText() {
+	p.Text = plainTextMessage(p.Blocks, p.Text)
+	p.Blocks = nil
+	p.Attachments = nil
+	mrkdwn := false
+	p.Mrkdwn = &mrkdwn
+}
+
+// newPayloadID returns a random ID receivers can use to drop duplicate
+// deliveries of a payload.
+func newPayloadID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// signPayload returns the SignatureHeader value for body.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
 }
 
 // slackMessage represents a Slack webhook message payload.
@@ -86,35 +264,31 @@ func (w *WebhookNotifier) Start(p *plan.Plan) error {
 				Type: "section",
 				Text: &slackText{
 					Type: "mrkdwn",
-					Text: fmt.Sprintf(":rocket: *Plan Started*\n`%s`", p.Name),
+					Text: i18n.Sprintf(":rocket: *Plan Started*\n`%s`", p.Name),
 				},
 			},
 			{
 				Type: "section",
 				Fields: []slackText{
-					{Type: "mrkdwn", Text: fmt.Sprintf("*Branch:*\n`%s`", p.Branch)},
+					{Type: "mrkdwn", Text: i18n.Sprintf("*Branch:*\n`%s`", p.Branch)},
 				},
 			},
 		},
 	}
 
-	w.sendAsync(msg)
+	payload := newWebhookPayload(events.TypePlanStarted, p, msg)
+	w.applyTemplate(payload, newTemplateContext(TemplateStart, p))
+	w.sendAsync(payload)
 	return nil
 }
 
 // Complete sends a notification when a plan completes.
-func (w *WebhookNotifier) Complete(p *plan.Plan, prURL string) error {
-	text := fmt.Sprintf(":white_check_mark: *Plan Complete*\n`%s`", p.Name)
-
-	fields := []slackText{
-		{Type: "mrkdwn", Text: fmt.Sprintf("*Branch:*\n`%s`", p.Branch)},
-	}
+func (w *WebhookNotifier) Complete(p *plan.Plan, c Completion) error {
+	text := i18n.Sprintf(":white_check_mark: *Plan Complete*\n`%s`", p.Name)
 
-	if prURL != "" {
-		fields = append(fields, slackText{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Pull Request:*\n<%s|View PR>", prURL),
-		})
+	var fields []slackText
+	for _, field := range completionFields(p, c) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: field})
 	}
 
 	msg := slackMessage{
@@ -130,7 +304,15 @@ func (w *WebhookNotifier) Complete(p *plan.Plan, prURL string) error {
 		},
 	}
 
-	w.sendAsync(msg)
+	payload := newWebhookPayload(events.TypePlanCompleted, p, msg)
+	payload.Links = completionLinks(c)
+	payload.Data = map[string]any{
+		"iterations":       c.Iterations,
+		"max_iterations":   c.MaxIterations,
+		"duration_seconds": int(c.Duration.Seconds()),
+	}
+	w.applyTemplate(payload, completeContext(p, c))
+	w.sendAsync(payload)
 	return nil
 }
 
@@ -150,14 +332,14 @@ func (w *WebhookNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 			Type: "section",
 			Text: &slackText{
 				Type: "mrkdwn",
-				Text: fmt.Sprintf(":warning: *Human Input Required*\n`%s`", p.Name),
+				Text: i18n.Sprintf(":warning: *Human Input Required*\n`%s`", p.Name),
 			},
 		},
 		{
 			Type: "section",
 			Text: &slackText{
 				Type: "mrkdwn",
-				Text: fmt.Sprintf("*Description:*\n%s", blockerText),
+				Text: i18n.Sprintf("*Description:*\n%s", blockerText),
 			},
 		},
 	}
@@ -167,7 +349,7 @@ func (w *WebhookNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 			Type: "section",
 			Text: &slackText{
 				Type: "mrkdwn",
-				Text: fmt.Sprintf("*Action Required:*\n%s", blocker.Action),
+				Text: i18n.Sprintf("*Action Required:*\n%s", blocker.Action),
 			},
 		})
 	}
@@ -177,7 +359,7 @@ func (w *WebhookNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 			Type: "section",
 			Text: &slackText{
 				Type: "mrkdwn",
-				Text: fmt.Sprintf("*On Resume:*\n%s", blocker.Resume),
+				Text: i18n.Sprintf("*On Resume:*\n%s", blocker.Resume),
 			},
 		})
 	}
@@ -186,7 +368,10 @@ func (w *WebhookNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error {
 		Blocks: blocks,
 	}
 
-	w.sendAsync(msg)
+	payload := newWebhookPayload(events.TypeBlocker, p, msg)
+	payload.Data = map[string]any{"description": blockerText, "action": blocker.Action}
+	w.applyTemplate(payload, blockerContext(p, blocker))
+	w.sendAsync(payload)
 	return nil
 }
 
@@ -207,79 +392,409 @@ func (w *WebhookNotifier) Error(p *plan.Plan, err error) error {
 				Type: "section",
 				Text: &slackText{
 					Type: "mrkdwn",
-					Text: fmt.Sprintf(":x: *Plan Error*\n`%s`", p.Name),
+					Text: i18n.Sprintf(":x: *Plan Error*\n`%s`", p.Name),
 				},
 			},
 			{
 				Type: "section",
 				Text: &slackText{
 					Type: "mrkdwn",
-					Text: fmt.Sprintf("*Error:*\n```%s```", errMsg),
+					Text: i18n.Sprintf("*Error:*\n```%s```", errMsg),
 				},
 			},
 		},
 	}
 
-	w.sendAsync(msg)
+	payload := newWebhookPayload(events.TypePlanError, p, msg)
+	payload.Data = map[string]any{"error": errMsg}
+	w.applyTemplate(payload, errorContext(p, errMsg))
+	w.sendAsync(payload)
 	return nil
 }
 
 // Iteration sends a notification for each iteration (if enabled).
-func (w *WebhookNotifier) Iteration(p *plan.Plan, iteration, maxIterations int) error {
+func (w *WebhookNotifier) Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error {
+	msg := slackMessage{
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{
+					Type: "mrkdwn",
+					Text: iterationText(p, iteration, maxIterations, eta),
+				},
+			},
+		},
+	}
+
+	payload := newWebhookPayload(events.TypeIteration, p, msg)
+	payload.Data = map[string]any{"iteration": iteration, "max_iterations": maxIterations}
+	w.applyTemplate(payload, iterationContext(p, iteration, maxIterations, eta))
+	w.sendAsync(payload)
+	return nil
+}
+
+// StageChange sends a notification when a plan moves from one stage to the next.
+func (w *WebhookNotifier) StageChange(p *plan.Plan, from, to string) error {
 	msg := slackMessage{
 		Blocks: []slackBlock{
 			{
 				Type: "section",
 				Text: &slackText{
 					Type: "mrkdwn",
-					Text: fmt.Sprintf(":hourglass_flowing_sand: *Iteration %d/%d*\n`%s`", iteration, maxIterations, p.Name),
+					Text: stageChangeText(p, from, to),
 				},
 			},
 		},
 	}
 
-	w.sendAsync(msg)
+	payload := newWebhookPayload(events.TypeStageChanged, p, msg)
+	payload.Data = map[string]any{"from": from, "to": to}
+	w.sendAsync(payload)
+	return nil
+}
+
+// BlockerEscalation re-notifies about a blocker that is still unresolved.
+func (w *WebhookNotifier) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	if blocker == nil {
+		return nil
+	}
+
+	blocks := []slackBlock{}
+	for _, text := range escalationText(p, blocker, unresolved, mention) {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+
+	payload := newWebhookPayload(events.TypeBlockerEscalated, p, slackMessage{Blocks: blocks})
+	payload.Data = map[string]any{"description": blocker.Description, "unresolved_seconds": int(unresolved.Seconds())}
+	w.sendAsync(payload)
 	return nil
 }
 
-// sendAsync sends the message asynchronously.
+// ApprovalRequest sends an approval request with the CLI commands to
+// approve or reject the plan.
+func (w *WebhookNotifier) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	var fields []slackText
+	for _, field := range completionFields(p, c) {
+		fields = append(fields, slackText{Type: "mrkdwn", Text: field})
+	}
+
+	msg := slackMessage{
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: approvalText(p, timeout)},
+			},
+			{
+				Type:   "section",
+				Fields: fields,
+			},
+		},
+	}
+
+	payload := newWebhookPayload(events.TypeApprovalRequested, p, msg)
+	payload.Links = completionLinks(c)
+	if timeout > 0 {
+		payload.Data = map[string]any{"timeout_seconds": int(timeout.Seconds())}
+	}
+	w.sendAsync(payload)
+	return nil
+}
+
+// UrgentFeedback sends a notification when urgent feedback stays unprocessed.
+func (w *WebhookNotifier) UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	msg := slackMessage{
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{
+					Type: "mrkdwn",
+					Text: i18n.Sprintf(":rotating_light: *Urgent Feedback Unprocessed*\n`%s`", p.Name),
+				},
+			},
+			{
+				Type: "section",
+				Text: &slackText{
+					Type: "mrkdwn",
+					Text: formatFeedbackEntries(entries),
+				},
+			},
+		},
+	}
+
+	payload := newWebhookPayload(webhookEventUrgentFeedback, p, msg)
+	payload.Data = map[string]any{"entries": len(entries)}
+	w.sendAsync(payload)
+	return nil
+}
+
+// Digest sends a single summary message for a digest period.
+func (w *WebhookNotifier) Digest(summary *DigestSummary) error {
+	if summary == nil || summary.Empty() {
+		return nil
+	}
+
+	msg := slackMessage{
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: formatDigest(summary)},
+			},
+		},
+	}
+
+	w.sendAsync(newWebhookPayload(webhookEventDigest, nil, msg))
+	return nil
+}
+
+// Coalesced sends a summary of rate limited notifications.
+func (w *WebhookNotifier) Coalesced(summary *CoalescedSummary) error {
+	if summary == nil || summary.Empty() {
+		return nil
+	}
+
+	msg := slackMessage{
+		Blocks: []slackBlock{
+			{
+				Type: "section",
+				Text: &slackText{Type: "mrkdwn", Text: formatCoalesced(summary)},
+			},
+		},
+	}
+
+	w.sendAsync(newWebhookPayload(webhookEventRateLimited, nil, msg))
+	return nil
+}
+
+// iterationText formats the iteration notification text, with the ETA if known.
+func iterationText(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) string {
+	text := i18n.Sprintf(":hourglass_flowing_sand: *Iteration %d/%d*\n`%s`", iteration, maxIterations, p.Name)
+	if eta != nil {
+		text += "\n" + eta.String()
+	}
+	return text
+}
+
+// stageChangeText formats a stage change notification.
+func stageChangeText(p *plan.Plan, from, to string) string {
+	return i18n.Sprintf(":arrow_right: *Stage Complete*\n`%s`: %s → %s", p.Name, from, to)
+}
+
+// completionLinks returns the pull request and branch links of a completion
+// for the webhook payload, or nil if it has none.
+func completionLinks(c Completion) map[string]string {
+	var links map[string]string
+	for name, url := range map[string]string{"pr": c.PRURL, "branch": c.BranchURL} {
+		if url == "" {
+			continue
+		}
+		if links == nil {
+			links = make(map[string]string)
+		}
+		links[name] = url
+	}
+	return links
+}
+
+// completionFields formats the completion notification's message fields:
+// branch, pull request, changes, iterations, duration, and gates. Fields
+// without data are left out.
+func completionFields(p *plan.Plan, c Completion) []string {
+	fields := []string{i18n.Sprintf("*Branch:*\n`%s`", p.Branch)}
+	if c.BranchURL != "" {
+		fields[0] = i18n.Sprintf("*Branch:*\n<%s|%s>", c.BranchURL, p.Branch)
+	}
+	if c.PRURL != "" {
+		fields = append(fields, i18n.Sprintf("*Pull Request:*\n<%s|View PR>", c.PRURL))
+	}
+	if changes := changesText(p); changes != "" {
+		fields = append(fields, changes)
+	}
+	if c.Iterations > 0 {
+		iterations := fmt.Sprintf("%d", c.Iterations)
+		if c.MaxIterations > 0 {
+			iterations += fmt.Sprintf("/%d", c.MaxIterations)
+		}
+		fields = append(fields, i18n.T("*Iterations:*\n")+iterations)
+	}
+	if c.Duration > 0 {
+		fields = append(fields, i18n.T("*Duration:*\n")+c.Duration.Round(time.Second).String())
+	}
+	if len(c.Gates) > 0 {
+		fields = append(fields, gatesText(c.Gates))
+	}
+	return fields
+}
+
+// changesText formats the plan's changes ledger summary and line counts as
+// a message field. Returns "" if no changes were recorded.
+func changesText(p *plan.Plan) string {
+	changes, err := plan.LoadChanges(p)
+	if err != nil || len(changes.Files) == 0 {
+		return ""
+	}
+	added, deleted := changes.Lines()
+	return i18n.Sprintf("*Changes:*\n%s\n+%d −%d lines", changes.Summary(), added, deleted)
+}
+
+// gatesText formats gate results as a message field, e.g.
+// "*Gates:*\n:white_check_mark: test   :x: lint". A gate that passed with
+// flaky tests gets a warning.
+func gatesText(results []gate.Result) string {
+	var parts []string
+	for _, r := range results {
+		icon := ":white_check_mark:"
+		switch {
+		case !r.Passed:
+			icon = ":x:"
+		case len(r.Flaky) > 0:
+			icon = ":warning:"
+		}
+		parts = append(parts, icon+" "+r.Name)
+	}
+	return i18n.T("*Gates:*\n") + strings.Join(parts, "   ")
+}
+
+// escalationText formats a blocker escalation: the headline with the
+// mention, the description, and the action if there is one.
+func escalationText(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) []string {
+	headline := i18n.Sprintf(":rotating_light: *Blocker Unresolved for %s*\n`%s` is still waiting for human input", unresolved.Round(time.Minute), p.Name)
+	if m := mentionText(mention); m != "" {
+		headline = m + " " + headline
+	}
+
+	description := blocker.Description
+	if description == "" {
+		description = blocker.Content
+	}
+	texts := []string{headline, i18n.Sprintf("*Description:*\n%s", description)}
+	if blocker.Action != "" {
+		texts = append(texts, i18n.Sprintf("*Action Required:*\n%s", blocker.Action))
+	}
+	return texts
+}
+
+// mentionText converts blockers.mention to Slack mention syntax: "@here",
+// "@channel", and "@everyone" become special mentions, user IDs (U..., W...)
+// user mentions, and group IDs (S...) group mentions. Anything else, such as
+// text already in <...> syntax, is used as is.
+func mentionText(mention string) string {
+	mention = strings.TrimSpace(mention)
+	switch {
+	case mention == "":
+		return ""
+	case mention == "@here" || mention == "@channel" || mention == "@everyone":
+		return "<!" + mention[1:] + ">"
+	case strings.HasPrefix(mention, "<"):
+		return mention
+	}
+	id := strings.TrimPrefix(mention, "@")
+	if id != strings.ToUpper(id) || strings.ContainsAny(id, " \t") {
+		return mention
+	}
+	switch id[0] {
+	case 'U', 'W':
+		return "<@" + id + ">"
+	case 'S':
+		return "<!subteam^" + id + ">"
+	}
+	return mention
+}
+
+// formatFeedbackEntries formats feedback entries as a Slack mrkdwn list.
+func formatFeedbackEntries(entries []plan.FeedbackEntry) string {
+	var sb strings.Builder
+	for _, e := range entries {
+		text := e.Text
+		if len(text) > 300 {
+			text = text[:300] + "..."
+		}
+		sb.WriteString(fmt.Sprintf("• [%s] %s\n", e.ID, text))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// applyTemplate replaces the payload's message with the user's template for
+// the event, if there is one.
+func (w *WebhookNotifier) applyTemplate(payload *webhookPayload, ctx TemplateContext) {
+	if rendered, ok := w.templates.render(ctx); ok {
+		rendered.apply(payload)
+	}
+}
+
+// sendAsync sends the payload asynchronously.
 // Errors are logged but not returned.
-func (w *WebhookNotifier) sendAsync(msg slackMessage) {
+func (w *WebhookNotifier) sendAsync(payload *webhookPayload) {
 	go func() {
-		if err := w.send(msg); err != nil {
+		if err := w.send(payload); err != nil {
 			log.Debug("Failed to send Slack notification: %v", err)
 		}
 	}()
 }
 
-// send sends the message synchronously.
-func (w *WebhookNotifier) send(msg slackMessage) error {
-	body, err := json.Marshal(msg)
+// send sends the payload synchronously, retrying on 5xx and network errors.
+// A payload that still can't be delivered is spooled; after a successful
+// delivery, spooled payloads are replayed.
+func (w *WebhookNotifier) send(payload *webhookPayload) error {
+	if w.plainText {
+		payload.toPlainText()
+	}
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
+	body = []byte(log.Redact(string(body)))
+
+	if err := w.retrier.Do(func() error { return w.post(body) }); err != nil {
+		if runner.IsRetryable(err) && w.spool != nil {
+			if spoolErr := w.spool.add(body); spoolErr != nil {
+				log.Warn("Failed to spool webhook payload: %v", spoolErr)
+			}
+		}
+		return err
+	}
 
+	if w.spool != nil {
+		w.spool.replay(w.post)
+	}
+	return nil
+}
+
+// post makes a single delivery attempt. Server errors and network failures
+// are returned as retryable errors; other rejections are not retryable.
+func (w *WebhookNotifier) post(body []byte) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.webhookURL, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return runner.WrapNonRetryable(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, signPayload(w.secret, body))
+	}
 
 	resp, err := w.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return fmt.Errorf("failed to send request: %w: %v", runner.ErrConnectionFailed, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("unexpected status code: %d: %w", resp.StatusCode, runner.ErrRateLimit)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status code: %d: %w", resp.StatusCode, runner.ErrConnectionFailed)
+	default:
+		return runner.WrapNonRetryable(fmt.Errorf("unexpected status code: %d", resp.StatusCode))
 	}
-
-	return nil
 }
 
 // NoopNotifier is a Notifier that does nothing.
@@ -290,7 +805,12 @@ type NoopNotifier struct{}
 func (n *NoopNotifier) Start(p *plan.Plan) error { return nil }
 
 // Complete does nothing.
-func (n *NoopNotifier) Complete(p *plan.Plan, prURL string) error { return nil }
+func (n *NoopNotifier) Complete(p *plan.Plan, c Completion) error { return nil }
+
+// BlockerEscalation does nothing.
+func (n *NoopNotifier) BlockerEscalation(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration, mention string) error {
+	return nil
+}
 
 // Blocker does nothing.
 func (n *NoopNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error { return nil }
@@ -299,10 +819,25 @@ func (n *NoopNotifier) Blocker(p *plan.Plan, blocker *runner.Blocker) error { re
 func (n *NoopNotifier) Error(p *plan.Plan, err error) error { return nil }
 
 // Iteration does nothing.
-func (n *NoopNotifier) Iteration(p *plan.Plan, iteration, maxIterations int) error { return nil }
+func (n *NoopNotifier) Iteration(p *plan.Plan, iteration, maxIterations int, eta *plan.ETA) error {
+	return nil
+}
+
+// UrgentFeedback does nothing.
+func (n *NoopNotifier) UrgentFeedback(p *plan.Plan, entries []plan.FeedbackEntry) error { return nil }
+
+// StageChange does nothing.
+func (n *NoopNotifier) StageChange(p *plan.Plan, from, to string) error { return nil }
+
+// ApprovalRequest does nothing.
+func (n *NoopNotifier) ApprovalRequest(p *plan.Plan, c Completion, timeout time.Duration) error {
+	return nil
+}
 
 // Ensure NoopNotifier implements Notifier.
 var _ Notifier = (*NoopNotifier)(nil)
 
-// Ensure WebhookNotifier implements Notifier.
+// Ensure WebhookNotifier implements Notifier, DigestSender, and CoalescedSender.
 var _ Notifier = (*WebhookNotifier)(nil)
+var _ DigestSender = (*WebhookNotifier)(nil)
+var _ CoalescedSender = (*WebhookNotifier)(nil)
@@ -3,12 +3,20 @@ package notify
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/runner"
 )
@@ -97,7 +105,7 @@ func TestWebhookNotifier_Complete_WithPR(t *testing.T) {
 	n := NewWebhookNotifier(server.URL)
 	p := &plan.Plan{Name: "test-plan", Branch: "feat/test-plan"}
 
-	err := n.Complete(p, "https://github.com/owner/repo/pull/123")
+	err := n.Complete(p, Completion{PRURL: "https://github.com/owner/repo/pull/123"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -146,7 +154,7 @@ func TestWebhookNotifier_Complete_NoPR(t *testing.T) {
 	n := NewWebhookNotifier(server.URL)
 	p := &plan.Plan{Name: "test-plan", Branch: "feat/test-plan"}
 
-	err := n.Complete(p, "")
+	err := n.Complete(p, Completion{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -350,7 +358,7 @@ func TestWebhookNotifier_Iteration(t *testing.T) {
 	n := NewWebhookNotifier(server.URL)
 	p := &plan.Plan{Name: "test-plan"}
 
-	err := n.Iteration(p, 5, 30)
+	err := n.Iteration(p, 5, 30, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -369,6 +377,208 @@ func TestWebhookNotifier_Iteration(t *testing.T) {
 	}
 }
 
+func TestIterationText_ETA(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan"}
+
+	if got := iterationText(p, 2, 30, nil); strings.Contains(got, "remaining") {
+		t.Errorf("iteration text without ETA = %q", got)
+	}
+
+	eta := &plan.ETA{Done: 4, Total: 9, Remaining: 2 * time.Hour, Known: true}
+	got := iterationText(p, 2, 30, eta)
+	if !strings.Contains(got, "*Iteration 2/30*") || !strings.Contains(got, "4/9 tasks, ~2h remaining at current pace") {
+		t.Errorf("iteration text with ETA = %q", got)
+	}
+}
+
+func TestChangesText(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan", Path: filepath.Join(t.TempDir(), "test-plan.md")}
+	if got := changesText(p); got != "" {
+		t.Errorf("changes text without ledger = %q", got)
+	}
+
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "main.go", Change: plan.ChangeModified, Added: 12, Deleted: 3}})
+	if got := changesText(p); got != "*Changes:*\n1 file changed: 1 modified\n+12 −3 lines" {
+		t.Errorf("changes text = %q", got)
+	}
+}
+
+func TestMentionText(t *testing.T) {
+	tests := map[string]string{
+		"":              "",
+		"@here":         "<!here>",
+		"@channel":      "<!channel>",
+		"U0123ABCD":     "<@U0123ABCD>",
+		"@W0123ABCD":    "<@W0123ABCD>",
+		"S0123ABCD":     "<!subteam^S0123ABCD>",
+		"<@U0123ABCD>":  "<@U0123ABCD>",
+		"@oncall-team":  "@oncall-team",
+		"Platform team": "Platform team",
+	}
+	for in, want := range tests {
+		if got := mentionText(in); got != want {
+			t.Errorf("mentionText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEscalationText(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan"}
+	blocker := &runner.Blocker{Description: "Need API key", Action: "Add STRIPE_KEY to .env"}
+
+	got := escalationText(p, blocker, 2*time.Hour+20*time.Second, "@here")
+	want := []string{
+		"<!here> :rotating_light: *Blocker Unresolved for 2h0m0s*\n`test-plan` is still waiting for human input",
+		"*Description:*\nNeed API key",
+		"*Action Required:*\nAdd STRIPE_KEY to .env",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("escalationText() =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := escalationText(p, &runner.Blocker{Content: "raw"}, time.Hour, ""); len(got) != 2 || strings.HasPrefix(got[0], "<") || got[1] != "*Description:*\nraw" {
+		t.Errorf("escalationText() without mention or action = %q", got)
+	}
+}
+
+func TestWebhookNotifier_BlockerEscalation(t *testing.T) {
+	var received slackMessage
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		close(done)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	if err := n.BlockerEscalation(&plan.Plan{Name: "test-plan"}, &runner.Blocker{Description: "Need API key"}, time.Hour, "@channel"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+	if len(received.Blocks) != 2 || !strings.HasPrefix(received.Blocks[0].Text.Text, "<!channel>") {
+		t.Errorf("blocks = %+v", received.Blocks)
+	}
+}
+
+func TestCompletionFields(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan", Branch: "feat/test-plan", Path: filepath.Join(t.TempDir(), "test-plan.md")}
+	if got := completionFields(p, Completion{}); len(got) != 1 || got[0] != "*Branch:*\n`feat/test-plan`" {
+		t.Errorf("fields without data = %q", got)
+	}
+
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "main.go", Change: plan.ChangeCreated, Added: 40}})
+	got := completionFields(p, Completion{
+		PRURL:         "https://github.com/owner/repo/pull/1",
+		Iterations:    7,
+		MaxIterations: 30,
+		Duration:      42*time.Minute + 10*time.Second + 300*time.Millisecond,
+		Gates: []gate.Result{
+			{Name: gate.Test, Passed: true},
+			{Name: gate.Lint, Passed: false},
+			{Name: gate.Test, Passed: true, Flaky: []string{"TestRace (failed 1 of 3 runs)"}},
+		},
+	})
+	want := []string{
+		"*Branch:*\n`feat/test-plan`",
+		"*Pull Request:*\n<https://github.com/owner/repo/pull/1|View PR>",
+		"*Changes:*\n1 file changed: 1 created\n+40 −0 lines",
+		"*Iterations:*\n7/30",
+		"*Duration:*\n42m10s",
+		"*Gates:*\n:white_check_mark: test   :x: lint   :warning: test",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("fields =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWebhookNotifier_StageChange(t *testing.T) {
+	var received slackMessage
+	var mu sync.Mutex
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&received)
+		close(done)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	p := &plan.Plan{Name: "test-plan"}
+
+	if err := n.StageChange(p, "implement", "review"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received.Blocks) != 1 || !strings.Contains(received.Blocks[0].Text.Text, "implement → review") {
+		t.Errorf("unexpected stage change message: %+v", received.Blocks)
+	}
+}
+
+func TestWebhookNotifier_UrgentFeedback(t *testing.T) {
+	var received slackMessage
+	var mu sync.Mutex
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&received)
+		close(done)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	p := &plan.Plan{Name: "test-plan"}
+
+	err := n.UrgentFeedback(p, []plan.FeedbackEntry{
+		{ID: "2024-01-30 14:32", Text: "cli: !urgent Production login is broken", Urgent: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received.Blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(received.Blocks))
+	}
+	if !strings.Contains(received.Blocks[1].Text.Text, "Production login is broken") {
+		t.Errorf("expected entry text in notification, got %q", received.Blocks[1].Text.Text)
+	}
+}
+
+func TestWebhookNotifier_UrgentFeedback_Empty(t *testing.T) {
+	n := NewWebhookNotifier("http://localhost:1")
+	if err := n.UrgentFeedback(&plan.Plan{Name: "test"}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestWebhookNotifier_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -422,7 +632,7 @@ func TestNoopNotifier(t *testing.T) {
 	if err := n.Start(p); err != nil {
 		t.Errorf("Start: unexpected error: %v", err)
 	}
-	if err := n.Complete(p, ""); err != nil {
+	if err := n.Complete(p, Completion{}); err != nil {
 		t.Errorf("Complete: unexpected error: %v", err)
 	}
 	if err := n.Blocker(p, &runner.Blocker{}); err != nil {
@@ -431,9 +641,18 @@ func TestNoopNotifier(t *testing.T) {
 	if err := n.Error(p, errors.New("test")); err != nil {
 		t.Errorf("Error: unexpected error: %v", err)
 	}
-	if err := n.Iteration(p, 1, 10); err != nil {
+	if err := n.Iteration(p, 1, 10, nil); err != nil {
 		t.Errorf("Iteration: unexpected error: %v", err)
 	}
+	if err := n.UrgentFeedback(p, []plan.FeedbackEntry{{ID: "2024-01-30 14:32"}}); err != nil {
+		t.Errorf("UrgentFeedback: unexpected error: %v", err)
+	}
+	if err := n.BlockerEscalation(p, &runner.Blocker{}, time.Hour, "@here"); err != nil {
+		t.Errorf("BlockerEscalation: unexpected error: %v", err)
+	}
+	if err := n.ApprovalRequest(p, Completion{}, time.Hour); err != nil {
+		t.Errorf("ApprovalRequest: unexpected error: %v", err)
+	}
 }
 
 func TestNotifierInterface(t *testing.T) {
@@ -441,3 +660,265 @@ func TestNotifierInterface(t *testing.T) {
 	var _ Notifier = (*WebhookNotifier)(nil)
 	var _ Notifier = (*NoopNotifier)(nil)
 }
+
+func TestWebhookNotifier_RedactsSecrets(t *testing.T) {
+	r, _ := log.NewRedactor([]string{"supersecretvalue", "p@ss&word<123>"}, nil)
+	log.SetRedactor(r)
+	defer log.SetRedactor(nil)
+
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	if err := n.send(newWebhookPayload(events.TypePlanError, nil, slackMessage{Text: "failed with supersecretvalue and p@ss&word<123>"})); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	if strings.Contains(body, "supersecretvalue") || strings.Contains(body, `p@ss\u0026word`) || strings.Count(body, log.Redacted) < 2 {
+		t.Errorf("body not redacted: %s", body)
+	}
+}
+
+// fastRetrier retries quickly so tests don't wait out the default backoff.
+func fastRetrier(retries int) *runner.Retrier {
+	return runner.NewRetrier(runner.RetryConfig{MaxRetries: retries, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+}
+
+func TestWebhookNotifier_PayloadSchema(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	p := &plan.Plan{Name: "alpha", Branch: "feat/alpha", Status: "complete"}
+	payload := newWebhookPayload(events.TypePlanCompleted, p, slackMessage{Text: "done"})
+	payload.Links = map[string]string{"pr": "https://github.com/o/r/pull/1"}
+	if err := n.send(payload); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	var got struct {
+		Version   int               `json:"version"`
+		ID        string            `json:"id"`
+		Event     string            `json:"event"`
+		Timestamp time.Time         `json:"timestamp"`
+		Plan      webhookPlan       `json:"plan"`
+		Links     map[string]string `json:"links"`
+		Text      string            `json:"text"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, body)
+	}
+	if got.Version != WebhookSchemaVersion || got.ID == "" || got.Event != "plan_completed" || got.Timestamp.IsZero() {
+		t.Errorf("envelope = %+v", got)
+	}
+	if got.Plan != (webhookPlan{Name: "alpha", Branch: "feat/alpha", Status: "complete"}) || got.Links["pr"] == "" {
+		t.Errorf("plan = %+v, links = %v", got.Plan, got.Links)
+	}
+	if got.Text != "done" {
+		t.Errorf("text = %q, want the Slack message inline", got.Text)
+	}
+}
+
+func TestWebhookNotifier_PlainText(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifierWithConfig(WebhookNotifierConfig{URL: server.URL, PlainText: true})
+	p := &plan.Plan{Name: "alpha", Branch: "feat/alpha"}
+	msg := slackMessage{Blocks: []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: ":rocket: *Plan Started*\n`alpha`"}},
+		{Type: "section", Fields: []slackText{{Type: "mrkdwn", Text: "*Branch:*\n`feat/alpha`"}}},
+	}}
+	if err := n.send(newWebhookPayload(events.TypePlanStarted, p, msg)); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, body)
+	}
+	if got["text"] != "Plan Started: alpha\nBranch: feat/alpha" {
+		t.Errorf("text = %q", got["text"])
+	}
+	if _, ok := got["blocks"]; ok {
+		t.Error("plain text payload has blocks")
+	}
+	if got["mrkdwn"] != false {
+		t.Errorf("mrkdwn = %v, want false", got["mrkdwn"])
+	}
+}
+
+func TestWebhookNotifier_Signature(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifierWithConfig(WebhookNotifierConfig{URL: server.URL, Secret: "s3cret"})
+	if err := n.send(newWebhookPayload(events.TypePlanStarted, &plan.Plan{Name: "alpha"}, slackMessage{})); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if signature == "" || signature != signPayload("s3cret", body) {
+		t.Errorf("signature = %q, want %q", signature, signPayload("s3cret", body))
+	}
+
+	n = NewWebhookNotifier(server.URL)
+	if err := n.send(newWebhookPayload(events.TypePlanStarted, &plan.Plan{Name: "alpha"}, slackMessage{})); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if signature != "" {
+		t.Errorf("unsigned payload has signature %q", signature)
+	}
+}
+
+func TestWebhookNotifier_Retries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL)
+	n.retrier = fastRetrier(5)
+	if err := n.send(newWebhookPayload(events.TypePlanStarted, nil, slackMessage{})); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestWebhookNotifier_NoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	spoolPath := filepath.Join(t.TempDir(), SpoolFilename)
+	n := NewWebhookNotifierWithConfig(WebhookNotifierConfig{URL: server.URL, SpoolPath: spoolPath})
+	n.retrier = fastRetrier(5)
+	if err := n.send(newWebhookPayload(events.TypePlanStarted, nil, slackMessage{})); err == nil {
+		t.Fatal("send() should fail on 400")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+	if _, err := os.Stat(spoolPath); !os.IsNotExist(err) {
+		t.Error("rejected payload should not be spooled")
+	}
+}
+
+func TestWebhookNotifier_SpoolAndReplay(t *testing.T) {
+	var mu sync.Mutex
+	down := true
+	var delivered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var payload webhookPayload
+		json.NewDecoder(r.Body).Decode(&payload)
+		delivered = append(delivered, payload.Event)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	spoolPath := filepath.Join(t.TempDir(), SpoolFilename)
+	n := NewWebhookNotifierWithConfig(WebhookNotifierConfig{URL: server.URL, SpoolPath: spoolPath})
+	n.retrier = fastRetrier(1)
+
+	p := &plan.Plan{Name: "alpha"}
+	for _, event := range []string{events.TypePlanStarted, events.TypePlanError} {
+		if err := n.send(newWebhookPayload(event, p, slackMessage{})); err == nil {
+			t.Fatalf("send(%s) should fail while the server is down", event)
+		}
+	}
+	bodies, err := n.spool.read()
+	if err != nil || len(bodies) != 2 {
+		t.Fatalf("spool = %d payloads (err %v), want 2", len(bodies), err)
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	if err := n.send(newWebhookPayload(events.TypePlanCompleted, p, slackMessage{})); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	if got := strings.Join(delivered, ","); got != "plan_completed,plan_started,plan_error" {
+		t.Errorf("delivered = %s", got)
+	}
+	if _, err := os.Stat(spoolPath); !os.IsNotExist(err) {
+		t.Error("spool should be removed once replayed")
+	}
+}
+
+func TestWebhookSpool_ConcurrentSpools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SpoolFilename)
+
+	// Separate spools stand in for two worker processes
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := &webhookSpool{path: path}
+			if err := s.add([]byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+				t.Errorf("add() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	bodies, err := (&webhookSpool{path: path}).read()
+	if err != nil || len(bodies) != n {
+		t.Errorf("spool = %d payloads (err %v), want %d", len(bodies), err, n)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "."+SpoolFilename+"-*.tmp")); len(tmps) != 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+}
+
+func TestWebhookSpool_Cap(t *testing.T) {
+	s := &webhookSpool{path: filepath.Join(t.TempDir(), SpoolFilename)}
+	for i := 0; i < maxSpooledPayloads+2; i++ {
+		if err := s.add([]byte(fmt.Sprintf(`{"n":%d}`, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bodies, _ := s.read()
+	if len(bodies) != maxSpooledPayloads || string(bodies[0]) != `{"n":2}` {
+		t.Errorf("spool has %d payloads starting %s, want the oldest dropped", len(bodies), bodies[0])
+	}
+}
@@ -0,0 +1,149 @@
+// Package page sends on-call alerts through PagerDuty, Opsgenie, or a
+// generic webhook when a blocker is escalated.
+package page
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// KeyEnv overrides blockers.page.key.
+const KeyEnv = "RALPH_PAGE_KEY"
+
+// Default provider endpoints.
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// requestTimeout bounds each alert request.
+const requestTimeout = 15 * time.Second
+
+// maxOpsgenieMessage is the longest message Opsgenie accepts.
+const maxOpsgenieMessage = 130
+
+// Alert is an on-call alert about a plan.
+type Alert struct {
+	// Key identifies the alert so repeated triggers for the same blocker
+	// are deduplicated (PagerDuty dedup_key, Opsgenie alias).
+	Key string `json:"key"`
+
+	// Summary is the one-line alert title.
+	Summary string `json:"summary"`
+
+	// Plan is the plan name.
+	Plan string `json:"plan"`
+
+	// Details is the longer description, e.g. the blocker's action.
+	Details string `json:"details,omitempty"`
+}
+
+// Pager sends alerts to the configured provider.
+type Pager struct {
+	provider string
+	url      string
+	key      string
+	http     *http.Client
+}
+
+// New creates a pager from cfg. Returns nil if no provider is configured.
+func New(cfg config.PageConfig) *Pager {
+	if cfg.Provider == "" {
+		return nil
+	}
+	url := cfg.URL
+	if url == "" {
+		switch cfg.Provider {
+		case config.PagePagerDuty:
+			url = DefaultPagerDutyURL
+		case config.PageOpsgenie:
+			url = DefaultOpsgenieURL
+		}
+	}
+	return &Pager{provider: cfg.Provider, url: url, key: Key(cfg), http: &http.Client{Timeout: requestTimeout}}
+}
+
+// Key returns the provider key from $RALPH_PAGE_KEY or blockers.page.key.
+func Key(cfg config.PageConfig) string {
+	if key := os.Getenv(KeyEnv); key != "" {
+		return key
+	}
+	return cfg.Key
+}
+
+// Trigger sends the alert.
+func (p *Pager) Trigger(a Alert) error {
+	var body interface{}
+	header := http.Header{}
+	switch p.provider {
+	case config.PagePagerDuty:
+		body = map[string]interface{}{
+			"routing_key":  p.key,
+			"event_action": "trigger",
+			"dedup_key":    a.Key,
+			"payload": map[string]interface{}{
+				"summary":        a.Summary,
+				"source":         "ralph",
+				"severity":       "warning",
+				"custom_details": map[string]string{"plan": a.Plan, "details": a.Details},
+			},
+		}
+	case config.PageOpsgenie:
+		message := a.Summary
+		if len(message) > maxOpsgenieMessage {
+			message = message[:maxOpsgenieMessage-3] + "..."
+		}
+		body = map[string]interface{}{
+			"message":     message,
+			"alias":       a.Key,
+			"description": a.Details,
+			"source":      "ralph",
+			"tags":        []string{"ralph"},
+			"details":     map[string]string{"plan": a.Plan},
+		}
+		header.Set("Authorization", "GenieKey "+p.key)
+	default:
+		body = struct {
+			Event string `json:"event"`
+			Alert
+		}{"blocker_escalated", a}
+		if p.key != "" {
+			header.Set("Authorization", "Bearer "+p.key)
+		}
+	}
+	return p.post(body, header)
+}
+
+// post sends body as JSON, failing on a non-2xx response.
+func (p *Pager) post(body interface{}, header http.Header) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding alert: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ralph")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending %s alert: %w", p.provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sending %s alert: %s: %s", p.provider, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package page
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// alertServer records the last request's Authorization header and JSON body.
+func alertServer(t *testing.T, status int) (*httptest.Server, *string, *map[string]interface{}) {
+	t.Helper()
+	var auth string
+	body := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &auth, &body
+}
+
+var testAlert = Alert{Key: "ralph-billing-abc123", Summary: "billing blocked for 2h: need Stripe keys", Plan: "billing", Details: "Add STRIPE_KEY to .env"}
+
+func TestNew(t *testing.T) {
+	if New(config.PageConfig{}) != nil {
+		t.Error("New() without a provider should be nil")
+	}
+	if p := New(config.PageConfig{Provider: config.PagePagerDuty}); p.url != DefaultPagerDutyURL {
+		t.Errorf("pagerduty url = %q", p.url)
+	}
+	if p := New(config.PageConfig{Provider: config.PageOpsgenie}); p.url != DefaultOpsgenieURL {
+		t.Errorf("opsgenie url = %q", p.url)
+	}
+
+	t.Setenv(KeyEnv, "from-env")
+	if got := Key(config.PageConfig{Key: "from-config"}); got != "from-env" {
+		t.Errorf("Key() = %q, want $%s", got, KeyEnv)
+	}
+}
+
+func TestTrigger_PagerDuty(t *testing.T) {
+	server, _, body := alertServer(t, http.StatusAccepted)
+	p := New(config.PageConfig{Provider: config.PagePagerDuty, URL: server.URL, Key: "routing"})
+
+	if err := p.Trigger(testAlert); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	payload, _ := (*body)["payload"].(map[string]interface{})
+	if (*body)["routing_key"] != "routing" || (*body)["event_action"] != "trigger" || (*body)["dedup_key"] != testAlert.Key || payload["summary"] != testAlert.Summary {
+		t.Errorf("body = %v", *body)
+	}
+}
+
+func TestTrigger_Opsgenie(t *testing.T) {
+	server, auth, body := alertServer(t, http.StatusAccepted)
+	p := New(config.PageConfig{Provider: config.PageOpsgenie, URL: server.URL, Key: "genie"})
+
+	long := testAlert
+	long.Summary = strings.Repeat("x", 200)
+	if err := p.Trigger(long); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if *auth != "GenieKey genie" {
+		t.Errorf("Authorization = %q", *auth)
+	}
+	if msg, _ := (*body)["message"].(string); len(msg) != maxOpsgenieMessage || (*body)["alias"] != testAlert.Key {
+		t.Errorf("body = %v", *body)
+	}
+}
+
+func TestTrigger_Webhook(t *testing.T) {
+	server, auth, body := alertServer(t, http.StatusOK)
+	p := New(config.PageConfig{Provider: config.PageWebhook, URL: server.URL})
+
+	if err := p.Trigger(testAlert); err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if *auth != "" || (*body)["event"] != "blocker_escalated" || (*body)["plan"] != "billing" || (*body)["details"] != testAlert.Details {
+		t.Errorf("auth = %q, body = %v", *auth, *body)
+	}
+}
+
+func TestTrigger_Error(t *testing.T) {
+	server, _, _ := alertServer(t, http.StatusBadRequest)
+	p := New(config.PageConfig{Provider: config.PageWebhook, URL: server.URL})
+
+	if err := p.Trigger(testAlert); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Trigger() error = %v, want the 400 status", err)
+	}
+}
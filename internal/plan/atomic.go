@@ -0,0 +1,78 @@
+// Package plan handles plan parsing and queue management.
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to path through a temp file in the same
+// directory and a rename, so readers and a crash mid-write see either the old
+// content or the new, never a truncated file. The temp file is synced before
+// the rename and the directory after it, so the new content survives a power
+// loss once WriteFileAtomic returns. An existing file's permissions are kept;
+// perm applies to a new file.
+//
+// Plan, progress, feedback, and context files are all written this way.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	// Create temp file in same directory for atomic rename
+	dir := filepath.Dir(path)
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	// Clean up temp file if something goes wrong
+	success := false
+	defer func() {
+		if !success {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	// Write content to temp file and flush it to disk
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+
+	// Close temp file before rename
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// Preserve original file permissions if file exists
+	mode := perm
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	// Atomic rename
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+	success = true
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes a directory entry change (e.g. a rename) to disk. Best
+// effort: some platforms and filesystems can't sync directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
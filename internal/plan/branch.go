@@ -0,0 +1,59 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/git"
+)
+
+// maxBranchSuffix bounds the -2, -3, ... suffixes AssignBranch tries.
+const maxBranchSuffix = 100
+
+// AssignBranch gives the plan its branch from the git.branch_template tmpl
+// and records it in a **Branch:** header, so it stays the same however long
+// the plan runs (tmpl may use {{.Date}}). A plan that already has a
+// **Branch:** header keeps it, once it's checked to be a valid branch name,
+// and an empty tmpl leaves the default feat/<name>.
+//
+// If exists reports the rendered branch is taken, -2, -3, ... is appended
+// until it isn't. p is reloaded.
+func AssignBranch(p *Plan, tmpl string, now time.Time, exists func(branch string) (bool, error)) error {
+	if extractBranch(p.Content) != "" {
+		return git.CheckBranchName(p.Branch)
+	}
+	if tmpl == "" {
+		return nil
+	}
+
+	base, err := git.RenderBranchName(tmpl, git.BranchNameData{
+		Name:   Slug(p.Name),
+		Date:   now.Format("2006-01-02"),
+		Jira:   p.Jira,
+		Linear: p.Linear,
+		GitHub: p.GitHub,
+	})
+	if err != nil {
+		return err
+	}
+
+	branch := base
+	for n := 2; ; n++ {
+		taken, err := exists(branch)
+		if err != nil {
+			return fmt.Errorf("checking branch %s: %w", branch, err)
+		}
+		if !taken {
+			break
+		}
+		if n > maxBranchSuffix {
+			return fmt.Errorf("branches %s through %s-%d already exist", base, base, maxBranchSuffix)
+		}
+		branch = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return Edit(p, func(d *Document) error {
+		d.SetField("Branch", branch)
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssignBranch(t *testing.T) {
+	now := time.Date(2024, 1, 30, 9, 0, 0, 0, time.UTC)
+	none := func(string) (bool, error) { return false, nil }
+
+	p := writeEditPlan(t, "# Plan: Test\n**Jira:** PROJ-9\n**Status:** open\n\n- [ ] Do it\n")
+	if err := AssignBranch(p, "ralph/{{.Date}}/{{.Jira}}-{{.Name}}", now, none); err != nil {
+		t.Fatalf("AssignBranch() error = %v", err)
+	}
+	if p.Branch != "ralph/2024-01-30/PROJ-9-test-plan" {
+		t.Errorf("Branch = %q", p.Branch)
+	}
+	if !strings.Contains(readPlanFile(t, p), "**Branch:** ralph/2024-01-30/PROJ-9-test-plan\n") {
+		t.Errorf("plan file missing the **Branch:** header:\n%s", readPlanFile(t, p))
+	}
+
+	// Once recorded, the branch doesn't change with the date
+	if err := AssignBranch(p, "ralph/{{.Date}}/{{.Name}}", now.AddDate(0, 0, 1), none); err != nil {
+		t.Fatal(err)
+	}
+	if p.Branch != "ralph/2024-01-30/PROJ-9-test-plan" {
+		t.Errorf("Branch after a second AssignBranch = %q", p.Branch)
+	}
+}
+
+func TestAssignBranch_Default(t *testing.T) {
+	content := "# Plan: Test\n**Status:** open\n"
+	p := writeEditPlan(t, content)
+	if err := AssignBranch(p, "", time.Now(), nil); err != nil {
+		t.Fatalf("AssignBranch() error = %v", err)
+	}
+	if p.Branch != "feat/test-plan" || readPlanFile(t, p) != content {
+		t.Errorf("Branch = %q, plan = %q; want feat/test-plan and no edit", p.Branch, readPlanFile(t, p))
+	}
+}
+
+func TestAssignBranch_Collision(t *testing.T) {
+	taken := map[string]bool{"ralph/test-plan": true, "ralph/test-plan-2": true}
+	exists := func(b string) (bool, error) { return taken[b], nil }
+
+	p := writeEditPlan(t, "# Plan: Test\n")
+	if err := AssignBranch(p, "ralph/{{.Name}}", time.Now(), exists); err != nil {
+		t.Fatalf("AssignBranch() error = %v", err)
+	}
+	if p.Branch != "ralph/test-plan-3" {
+		t.Errorf("Branch = %q, want ralph/test-plan-3", p.Branch)
+	}
+
+	p = writeEditPlan(t, "# Plan: Test\n")
+	failing := func(string) (bool, error) { return false, errors.New("boom") }
+	if err := AssignBranch(p, "ralph/{{.Name}}", time.Now(), failing); err == nil {
+		t.Error("AssignBranch() should fail when the branch can't be checked")
+	}
+}
+
+func TestAssignBranch_InvalidHeader(t *testing.T) {
+	p := writeEditPlan(t, "# Plan: Test\n**Branch:** feat/bad..name\n")
+	if err := AssignBranch(p, "", time.Now(), nil); err == nil {
+		t.Error("AssignBranch() should reject an invalid **Branch:** header")
+	}
+}
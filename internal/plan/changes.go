@@ -0,0 +1,188 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Change kinds recorded in the changes ledger.
+const (
+	ChangeCreated  = "created"
+	ChangeModified = "modified"
+	ChangeDeleted  = "deleted"
+)
+
+// FileChange is one file in the changes ledger with its net change so far.
+type FileChange struct {
+	// Path is the file path relative to the repository root.
+	Path string `json:"path"`
+
+	// Change is the net change across iterations (see Change* constants).
+	Change string `json:"change"`
+
+	// Iterations are the iterations that touched the file, in order.
+	Iterations []int `json:"iterations"`
+
+	// Added and Deleted are the lines added and deleted across iterations.
+	Added   int `json:"added,omitempty"`
+	Deleted int `json:"deleted,omitempty"`
+}
+
+// Changes is the cumulative ledger of files a plan has created, modified,
+// or deleted, derived from each iteration's git diff.
+type Changes struct {
+	// Files are the changed files, sorted by path.
+	Files []FileChange `json:"files"`
+
+	// Recorded are the iterations whose diffs have been merged into the ledger.
+	Recorded []int `json:"recorded,omitempty"`
+}
+
+// ChangesPath returns the path to the changes ledger for a plan.
+// The ledger is named "<plan-name>.changes.json" in the same directory as the plan.
+// Example: "plans/current/go-rewrite.md" → "plans/current/go-rewrite.changes.json"
+func ChangesPath(plan *Plan) string {
+	ext := filepath.Ext(plan.Path)
+	return strings.TrimSuffix(plan.Path, ext) + ".changes.json"
+}
+
+// LoadChanges reads the plan's changes ledger.
+// Returns an empty ledger if the file doesn't exist.
+func LoadChanges(plan *Plan) (*Changes, error) {
+	data, err := os.ReadFile(ChangesPath(plan))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Changes{}, nil
+		}
+		return nil, fmt.Errorf("reading changes ledger: %w", err)
+	}
+
+	var changes Changes
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("parsing changes ledger: %w", err)
+	}
+	return &changes, nil
+}
+
+// SaveChanges writes the plan's changes ledger atomically.
+func SaveChanges(plan *Plan, changes *Changes) error {
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling changes ledger: %w", err)
+	}
+
+	if err := WriteFileAtomic(ChangesPath(plan), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing changes ledger: %w", err)
+	}
+	return nil
+}
+
+// RecordChanges merges one iteration's file changes into the plan's ledger.
+// Recording the same iteration twice (e.g. after resuming from a checkpoint)
+// has no effect.
+func RecordChanges(plan *Plan, iteration int, files []FileChange) error {
+	changes, err := LoadChanges(plan)
+	if err != nil {
+		return err
+	}
+	if !changes.Record(iteration, files) {
+		return nil
+	}
+	return SaveChanges(plan, changes)
+}
+
+// Record merges an iteration's file changes, combining them with earlier
+// changes to the same file and summing their line counts: a file created and
+// later modified stays created, a file created and later deleted is dropped,
+// and a file deleted and then recreated counts as modified. Returns false if
+// the iteration was already recorded.
+func (c *Changes) Record(iteration int, files []FileChange) bool {
+	for _, n := range c.Recorded {
+		if n == iteration {
+			return false
+		}
+	}
+	c.Recorded = append(c.Recorded, iteration)
+
+	for _, f := range files {
+		i := c.index(f.Path)
+		if i < 0 {
+			c.Files = append(c.Files, FileChange{Path: f.Path, Change: f.Change, Iterations: []int{iteration}, Added: f.Added, Deleted: f.Deleted})
+			continue
+		}
+
+		existing := &c.Files[i]
+		switch {
+		case existing.Change == ChangeCreated && f.Change == ChangeDeleted:
+			c.Files = append(c.Files[:i], c.Files[i+1:]...)
+			continue
+		case existing.Change == ChangeCreated:
+			// Still a new file
+		case f.Change == ChangeDeleted:
+			existing.Change = ChangeDeleted
+		default:
+			existing.Change = ChangeModified
+		}
+		existing.Iterations = append(existing.Iterations, iteration)
+		existing.Added += f.Added
+		existing.Deleted += f.Deleted
+	}
+
+	sort.Slice(c.Files, func(i, j int) bool { return c.Files[i].Path < c.Files[j].Path })
+	return true
+}
+
+// index returns the position of path in the ledger, or -1.
+func (c *Changes) index(path string) int {
+	for i, f := range c.Files {
+		if f.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Count returns the number of files with the given change kind.
+func (c *Changes) Count(change string) int {
+	n := 0
+	for _, f := range c.Files {
+		if f.Change == change {
+			n++
+		}
+	}
+	return n
+}
+
+// Lines returns the lines added and deleted across all files in the ledger.
+func (c *Changes) Lines() (added, deleted int) {
+	for _, f := range c.Files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+	return added, deleted
+}
+
+// Summary describes the ledger in a short phrase, e.g.
+// "7 files changed: 2 created, 4 modified, 1 deleted".
+// Returns "" if no files have changed.
+func (c *Changes) Summary() string {
+	if len(c.Files) == 0 {
+		return ""
+	}
+
+	noun := "files"
+	if len(c.Files) == 1 {
+		noun = "file"
+	}
+	var parts []string
+	for _, change := range []string{ChangeCreated, ChangeModified, ChangeDeleted} {
+		if n := c.Count(change); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, change))
+		}
+	}
+	return fmt.Sprintf("%d %s changed: %s", len(c.Files), noun, strings.Join(parts, ", "))
+}
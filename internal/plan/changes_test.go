@@ -0,0 +1,114 @@
+package plan
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangesPath(t *testing.T) {
+	p := &Plan{Path: filepath.Join("plans", "current", "go-rewrite.md")}
+	want := filepath.Join("plans", "current", "go-rewrite.changes.json")
+	if got := ChangesPath(p); got != want {
+		t.Errorf("ChangesPath() = %q, want %q", got, want)
+	}
+}
+
+func TestChanges_Record(t *testing.T) {
+	var c Changes
+	c.Record(1, []FileChange{
+		{Path: "main.go", Change: ChangeModified},
+		{Path: "new.go", Change: ChangeCreated},
+		{Path: "scratch.go", Change: ChangeCreated},
+		{Path: "old.go", Change: ChangeDeleted},
+	})
+	c.Record(2, []FileChange{
+		{Path: "new.go", Change: ChangeModified},
+		{Path: "scratch.go", Change: ChangeDeleted},
+		{Path: "old.go", Change: ChangeCreated},
+		{Path: "main.go", Change: ChangeDeleted},
+	})
+
+	want := []FileChange{
+		{Path: "main.go", Change: ChangeDeleted, Iterations: []int{1, 2}},
+		{Path: "new.go", Change: ChangeCreated, Iterations: []int{1, 2}},
+		{Path: "old.go", Change: ChangeModified, Iterations: []int{1, 2}},
+	}
+	if !reflect.DeepEqual(c.Files, want) {
+		t.Errorf("Files = %+v\nwant %+v", c.Files, want)
+	}
+
+	if c.Record(2, []FileChange{{Path: "again.go", Change: ChangeCreated}}) {
+		t.Error("Record() should ignore an iteration that was already recorded")
+	}
+	if len(c.Files) != 3 {
+		t.Errorf("duplicate iteration changed the ledger: %+v", c.Files)
+	}
+}
+
+func TestChanges_Lines(t *testing.T) {
+	var c Changes
+	c.Record(1, []FileChange{
+		{Path: "main.go", Change: ChangeModified, Added: 10, Deleted: 2},
+		{Path: "scratch.go", Change: ChangeCreated, Added: 5},
+	})
+	c.Record(2, []FileChange{
+		{Path: "main.go", Change: ChangeModified, Added: 1, Deleted: 3},
+		{Path: "scratch.go", Change: ChangeDeleted, Deleted: 5},
+	})
+
+	if c.Files[0].Added != 11 || c.Files[0].Deleted != 5 {
+		t.Errorf("main.go = %+v, want 11 added, 5 deleted", c.Files[0])
+	}
+	if added, deleted := c.Lines(); added != 11 || deleted != 5 {
+		t.Errorf("Lines() = %d, %d, want 11, 5", added, deleted)
+	}
+}
+
+func TestChanges_Summary(t *testing.T) {
+	c := &Changes{}
+	if got := c.Summary(); got != "" {
+		t.Errorf("empty Summary() = %q", got)
+	}
+
+	c.Record(1, []FileChange{{Path: "a.go", Change: ChangeCreated}})
+	if got := c.Summary(); got != "1 file changed: 1 created" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	c.Record(2, []FileChange{
+		{Path: "b.go", Change: ChangeModified},
+		{Path: "c.go", Change: ChangeModified},
+		{Path: "d.go", Change: ChangeDeleted},
+	})
+	if got := c.Summary(); got != "4 files changed: 1 created, 2 modified, 1 deleted" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestRecordChanges(t *testing.T) {
+	p := &Plan{Path: filepath.Join(t.TempDir(), "feature.md")}
+
+	c, err := LoadChanges(p)
+	if err != nil || len(c.Files) != 0 {
+		t.Fatalf("LoadChanges() without file = %+v, %v", c, err)
+	}
+
+	if err := RecordChanges(p, 1, []FileChange{{Path: "a.go", Change: ChangeCreated}}); err != nil {
+		t.Fatalf("RecordChanges: %v", err)
+	}
+	if err := RecordChanges(p, 2, []FileChange{{Path: "a.go", Change: ChangeModified}, {Path: "b.go", Change: ChangeModified}}); err != nil {
+		t.Fatalf("RecordChanges: %v", err)
+	}
+
+	c, err = LoadChanges(p)
+	if err != nil {
+		t.Fatalf("LoadChanges: %v", err)
+	}
+	if got := c.Summary(); got != "2 files changed: 1 created, 1 modified" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if !reflect.DeepEqual(c.Recorded, []int{1, 2}) {
+		t.Errorf("Recorded = %v", c.Recorded)
+	}
+}
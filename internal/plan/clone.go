@@ -0,0 +1,93 @@
+// Package plan handles plan parsing and queue management.
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// checkedBoxRegex matches a checked checkbox in a list item ("- [x]", "1. [X]").
+var checkedBoxRegex = regexp.MustCompile(`(?m)^(\s*(?:[-*+]|\d+\.)\s*\[)[xX](\])`)
+
+// statusLineRegex matches a **Status:** line, capturing the prefix and value.
+var statusLineRegex = regexp.MustCompile(`^(\*\*Status:\*\*\s*)(\S+)`)
+
+// branchLineRegex matches a **Branch:** line and its newline.
+var branchLineRegex = regexp.MustCompile(`(?m)^\*\*Branch:\*\*.*(\n|$)`)
+
+// createdLineRegex matches a **Created:** date line.
+var createdLineRegex = regexp.MustCompile(`(?m)^(\*\*Created:\*\*\s*)\S.*$`)
+
+// Clone copies src into dir as a fresh plan named name, returning the new plan.
+// Checkboxes are unchecked, the plan status is reset to pending, task statuses
+// other than blocked are reset to open, and the Created date is set to today.
+// The new plan gets empty progress and feedback files; a **Branch:** header
+// is dropped so its branch is assigned afresh. Fails if a plan with that name
+// already exists in dir.
+func Clone(src *Plan, dir, name string) (*Plan, error) {
+	base := CloneName(name)
+	if base == "" {
+		return nil, fmt.Errorf("invalid plan name: %q", name)
+	}
+
+	path := filepath.Join(dir, base+".md")
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("plan %s already exists at %s", base, path)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating plan directory: %w", err)
+	}
+
+	content := resetContent(src.Content, time.Now())
+	if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("writing plan file: %w", err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := CreateProgressFile(p); err != nil {
+		return nil, err
+	}
+	if err := CreateFeedbackFile(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CloneName returns the plan name Clone uses for name: lowercase, with
+// spaces replaced by hyphens and other special characters removed.
+func CloneName(name string) string {
+	return sanitizeBranchName(strings.TrimSuffix(name, ".md"))
+}
+
+// resetContent strips execution state from plan content so it can run again.
+func resetContent(content string, now time.Time) string {
+	content = checkedBoxRegex.ReplaceAllString(content, "${1} ${2}")
+	content = createdLineRegex.ReplaceAllString(content, "${1}"+now.Format("2006-01-02"))
+	content = branchLineRegex.ReplaceAllString(content, "")
+
+	// The first **Status:** line is the plan's; later ones belong to tasks
+	lines := strings.Split(content, "\n")
+	planStatus := true
+	for i, line := range lines {
+		m := statusLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		status := "open"
+		if planStatus {
+			status = "pending"
+			planStatus = false
+		} else if strings.EqualFold(m[2], "blocked") {
+			continue
+		}
+		lines[i] = m[1] + status + line[len(m[0]):]
+	}
+	return strings.Join(lines, "\n")
+}
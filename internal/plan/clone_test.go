@@ -0,0 +1,107 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const completedPlan = `# Plan: Weekly Report
+
+**Created:** 2026-01-31
+**Branch:** ralph/2026-01-31/weekly-report
+**Status:** complete
+
+## Tasks
+
+### T1: Collect data
+
+**Requires:** —
+**Status:** complete
+
+**Done when:**
+- [x] Data collected
+- [X] Data validated
+
+**Subtasks:**
+1. [x] Query the API
+
+### T2: Publish
+
+**Requires:** T1
+**Status:** blocked
+
+- [ ] Published
+`
+
+func TestClone(t *testing.T) {
+	tmp := t.TempDir()
+	srcDir := filepath.Join(tmp, "complete")
+	os.MkdirAll(srcDir, 0755)
+	srcPath := filepath.Join(srcDir, "weekly-report.md")
+	os.WriteFile(srcPath, []byte(completedPlan), 0644)
+	os.WriteFile(filepath.Join(srcDir, "weekly-report.progress.md"), []byte("## Iteration 1\ndid things\n"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "weekly-report.feedback.md"), []byte("## Pending\n- [2026-01-31 10:00] fix it\n"), 0644)
+	src, _ := Load(srcPath)
+
+	dest := filepath.Join(tmp, "pending")
+	p, err := Clone(src, dest, "Weekly Report Feb")
+	if err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	if p.Name != "weekly-report-feb" || p.Branch != "feat/weekly-report-feb" {
+		t.Errorf("Name = %q, Branch = %q", p.Name, p.Branch)
+	}
+	if p.Status != "pending" {
+		t.Errorf("Status = %q, want pending", p.Status)
+	}
+	if CountComplete(p.Tasks) != 0 || CountTotal(p.Tasks) != 3 {
+		t.Errorf("tasks complete = %d of %d, want 0 of 3", CountComplete(p.Tasks), CountTotal(p.Tasks))
+	}
+	for _, want := range []string{
+		"**Created:** " + time.Now().Format("2006-01-02"),
+		"1. [ ] Query the API",
+		"**Requires:** —\n**Status:** open",
+		"**Requires:** T1\n**Status:** blocked",
+	} {
+		if !strings.Contains(p.Content, want) {
+			t.Errorf("content missing %q:\n%s", want, p.Content)
+		}
+	}
+
+	progress, _ := ReadProgress(p)
+	if strings.Contains(progress, "did things") || !strings.Contains(progress, "# Progress: weekly-report-feb") {
+		t.Errorf("progress = %q, want a fresh file", progress)
+	}
+	fb, _ := LoadFeedback(p)
+	if len(fb.Pending) != 0 {
+		t.Errorf("feedback pending = %v, want none", fb.Pending)
+	}
+
+	// The source is untouched
+	if data, _ := os.ReadFile(srcPath); string(data) != completedPlan {
+		t.Error("source plan was modified")
+	}
+}
+
+func TestClone_Conflicts(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "taken.md"), []byte("# Plan: Taken\n"), 0644)
+	src := &Plan{Content: "# Plan: Source\n"}
+
+	if _, err := Clone(src, dir, "taken"); err == nil {
+		t.Error("expected error cloning onto an existing plan")
+	}
+	if _, err := Clone(src, dir, "!!!"); err == nil {
+		t.Error("expected error for a name with no usable characters")
+	}
+}
+
+func TestCloneName(t *testing.T) {
+	if got := CloneName("Weekly Report (Feb).md"); got != "weekly-report-feb" {
+		t.Errorf("CloneName() = %q, want weekly-report-feb", got)
+	}
+}
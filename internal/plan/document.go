@@ -0,0 +1,134 @@
+// Package plan handles plan parsing and queue management.
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Document is a plan file parsed for editing. It keeps every line as
+// written, including sections and comments ralph doesn't know about, so an
+// unchanged document serializes to the same bytes and an edit changes only
+// the lines it touches.
+type Document struct {
+	lines []string
+}
+
+// ParseDocument parses plan content for editing.
+func ParseDocument(content string) *Document {
+	return &Document{lines: strings.Split(content, "\n")}
+}
+
+// String serializes the document back to markdown.
+func (d *Document) String() string {
+	return strings.Join(d.lines, "\n")
+}
+
+// Tasks returns the document's checkbox tasks (see ExtractTasks).
+func (d *Document) Tasks() []Task {
+	return ExtractTasks(d.String())
+}
+
+// fieldRegex returns the regex for a **name:** header line, capturing the
+// prefix, the value, and a trailing HTML comment.
+func fieldRegex(name string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\*\*` + regexp.QuoteMeta(name) + `:\*\*[ \t]*)(.*?)([ \t]*<!--.*-->)?[ \t]*$`)
+}
+
+// Field returns the value of the first **name:** line (e.g. "Status"),
+// without a trailing HTML comment. Later lines with the same name belong to
+// tasks, as for Load.
+func (d *Document) Field(name string) (string, bool) {
+	re := fieldRegex(name)
+	for _, line := range d.lines {
+		if m := re.FindStringSubmatch(line); m != nil {
+			return m[2], true
+		}
+	}
+	return "", false
+}
+
+// SetField sets the value of the first **name:** line, keeping a trailing
+// HTML comment. A missing field is added after the plan's other header
+// fields, or after the title if it has none.
+func (d *Document) SetField(name, value string) {
+	re := fieldRegex(name)
+	for i, line := range d.lines {
+		if m := re.FindStringSubmatch(line); m != nil {
+			d.lines[i] = m[1] + value + m[3]
+			return
+		}
+	}
+	d.insert(d.headerEnd(), fmt.Sprintf("**%s:** %s", name, value))
+}
+
+// RemoveField removes the first **name:** line. Returns false if the
+// document has none.
+func (d *Document) RemoveField(name string) bool {
+	re := fieldRegex(name)
+	for i, line := range d.lines {
+		if re.MatchString(line) {
+			d.lines = append(d.lines[:i], d.lines[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// headerEnd returns the index after the title and the **Field:** lines that
+// directly follow it.
+func (d *Document) headerEnd() int {
+	at := 0
+	if len(d.lines) > 0 && strings.HasPrefix(d.lines[0], "# ") {
+		at = 1
+	}
+	for at < len(d.lines) && strings.HasPrefix(d.lines[at], "**") && strings.Contains(d.lines[at], ":**") {
+		at++
+	}
+	return at
+}
+
+// SetTaskComplete checks or unchecks the task on a 1-indexed line,
+// preserving the line's formatting (see UpdateCheckbox).
+func (d *Document) SetTaskComplete(lineNum int, complete bool) error {
+	content, err := UpdateCheckbox(d.String(), lineNum, complete)
+	if err != nil {
+		return err
+	}
+	d.lines = strings.Split(content, "\n")
+	return nil
+}
+
+// AddTask appends an unchecked task after the document's last task, with the
+// indentation of the last top-level task, or at the end of the document if
+// it has none.
+func (d *Document) AddTask(text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.Contains(text, "\n") {
+		return fmt.Errorf("task text must be a single non-empty line")
+	}
+
+	tasks := d.Tasks()
+	if len(tasks) == 0 {
+		// Keep a blank line between the existing text and the task
+		for len(d.lines) > 0 && d.lines[len(d.lines)-1] == "" {
+			d.lines = d.lines[:len(d.lines)-1]
+		}
+		if len(d.lines) > 0 {
+			d.lines = append(d.lines, "")
+		}
+		d.lines = append(d.lines, "- [ ] "+text, "")
+		return nil
+	}
+
+	top := tasks[len(tasks)-1]
+	all := flattenTasks(tasks)
+	d.insert(all[len(all)-1].Line, d.lines[top.Line-1][:top.Indent]+"- [ ] "+text)
+	return nil
+}
+
+// insert inserts a line before index at.
+func (d *Document) insert(at int, line string) {
+	d.lines = append(d.lines[:at], append([]string{line}, d.lines[at:]...)...)
+}
@@ -0,0 +1,130 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const documentContent = `# Plan: Test
+**Status:** open <!-- keep -->
+**Labels:** backend, urgent
+
+<!-- planning notes, not for the agent -->
+
+## Tasks
+- [ ] One
+  - [x] One.a
+
+## Custom Section
+Anything | goes
+`
+
+func TestDocument_RoundTrip(t *testing.T) {
+	for _, content := range []string{documentContent, "", "no trailing newline", "\r\nwindows\r\n"} {
+		if got := ParseDocument(content).String(); got != content {
+			t.Errorf("round trip = %q, want %q", got, content)
+		}
+	}
+}
+
+func TestDocument_Field(t *testing.T) {
+	d := ParseDocument(documentContent)
+
+	if v, ok := d.Field("Status"); !ok || v != "open" {
+		t.Errorf("Field(Status) = %q, %t", v, ok)
+	}
+	if v, ok := d.Field("Labels"); !ok || v != "backend, urgent" {
+		t.Errorf("Field(Labels) = %q, %t", v, ok)
+	}
+	if _, ok := d.Field("Model"); ok {
+		t.Error("Field(Model) found a missing field")
+	}
+
+	d.SetField("Status", "complete")
+	d.SetField("Model", "opus")
+	want := strings.Replace(documentContent, "**Status:** open <!-- keep -->\n**Labels:** backend, urgent\n",
+		"**Status:** complete <!-- keep -->\n**Labels:** backend, urgent\n**Model:** opus\n", 1)
+	if got := d.String(); got != want {
+		t.Errorf("after SetField = %q, want %q", got, want)
+	}
+	if p := parsePlanContent(t, d.String()); p.Status != "complete" || p.Model != "opus" {
+		t.Errorf("parsed status = %q, model = %q", p.Status, p.Model)
+	}
+
+	if !d.RemoveField("Model") || d.String() != strings.Replace(want, "**Model:** opus\n", "", 1) {
+		t.Errorf("after RemoveField = %q", d.String())
+	}
+	if d.RemoveField("Model") {
+		t.Error("RemoveField(Model) removed a missing field")
+	}
+}
+
+func TestDocument_Tasks(t *testing.T) {
+	d := ParseDocument(documentContent)
+
+	if err := d.SetTaskComplete(8, true); err != nil {
+		t.Fatalf("SetTaskComplete() error = %v", err)
+	}
+	if err := d.SetTaskComplete(9, false); err != nil {
+		t.Fatalf("SetTaskComplete() error = %v", err)
+	}
+	if err := d.SetTaskComplete(2, true); err == nil {
+		t.Error("SetTaskComplete() on a line without a checkbox should fail")
+	}
+	if err := d.AddTask("Two"); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+
+	want := strings.Replace(documentContent, "- [ ] One\n  - [x] One.a\n", "- [x] One\n  - [ ] One.a\n- [ ] Two\n", 1)
+	if got := d.String(); got != want {
+		t.Errorf("after edits = %q, want %q", got, want)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.md")
+	os.WriteFile(path, []byte(documentContent), 0600)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Edits see the file as it is now, not as p was loaded
+	os.WriteFile(path, []byte(documentContent+"- [ ] Late\n"), 0600)
+	err = Edit(p, func(d *Document) error {
+		d.SetField("Status", "blocked")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if p.Status != "blocked" || !strings.Contains(p.Content, "- [ ] Late") {
+		t.Errorf("reloaded plan = %+v", p)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the original 0600", info.Mode().Perm())
+	}
+
+	// A failed edit writes nothing
+	before := p.Content
+	if err := Edit(p, func(d *Document) error { return d.AddTask("") }); err == nil {
+		t.Error("Edit() should return fn's error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != before {
+		t.Error("failed edit changed the file")
+	}
+}
+
+// parsePlanContent writes content to a plan file and loads it.
+func parsePlanContent(t *testing.T, content string) *Plan {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.md")
+	os.WriteFile(path, []byte(content), 0644)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
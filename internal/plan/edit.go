@@ -0,0 +1,107 @@
+// Package plan handles plan parsing and queue management.
+package plan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Statuses are the plan and task statuses ralph understands.
+var Statuses = []string{"pending", "open", "in_progress", "blocked", "complete"}
+
+// ValidStatus reports whether status is one of Statuses.
+func ValidStatus(status string) bool {
+	for _, s := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTask appends an unchecked task to the plan file after its last task,
+// with the indentation of the last top-level task, or at the end of the plan
+// if it has none. The rest of the file is left as it is, and p is reloaded.
+func AddTask(p *Plan, text string) error {
+	return Edit(p, func(d *Document) error {
+		return d.AddTask(text)
+	})
+}
+
+// CheckTask checks off the task selected by sel, which is either its
+// 1-based position among all the plan's tasks (subtasks included, in file
+// order) or text that appears in exactly one task, case-insensitively.
+// Checking a task that is already complete is not an error. Returns the
+// selected task; p is reloaded.
+func CheckTask(p *Plan, sel string) (Task, error) {
+	var task Task
+	err := Edit(p, func(d *Document) error {
+		var err error
+		if task, err = selectTask(p.Name, d.Tasks(), sel); err != nil {
+			return err
+		}
+		if task.Complete {
+			return nil
+		}
+		task.Complete = true
+		return d.SetTaskComplete(task.Line, true)
+	})
+	return task, err
+}
+
+// selectTask returns the task among tasks selected by sel (see CheckTask).
+func selectTask(planName string, tasks []Task, sel string) (Task, error) {
+	all := flattenTasks(tasks)
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 1 || n > len(all) {
+			return Task{}, fmt.Errorf("plan %s has %d tasks, no task %d", planName, len(all), n)
+		}
+		return all[n-1], nil
+	}
+
+	pattern := strings.ToLower(strings.TrimSpace(sel))
+	if pattern == "" {
+		return Task{}, fmt.Errorf("no task given")
+	}
+	var matches []Task
+	for _, task := range all {
+		if strings.Contains(strings.ToLower(task.Text), pattern) {
+			matches = append(matches, task)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Task{}, fmt.Errorf("no task in plan %s matches %q", planName, sel)
+	case 1:
+		return matches[0], nil
+	}
+	texts := make([]string, len(matches))
+	for i, task := range matches {
+		texts[i] = fmt.Sprintf("line %d: %s", task.Line, task.Text)
+	}
+	return Task{}, fmt.Errorf("%d tasks in plan %s match %q, use a longer pattern or the task number: %s", len(matches), planName, sel, strings.Join(texts, "; "))
+}
+
+// SetStatus sets the plan's **Status:** line to status, adding one after the
+// header if the plan has none. Later **Status:** lines belong to tasks and
+// are left alone, as is the rest of the file. p is reloaded.
+func SetStatus(p *Plan, status string) error {
+	if !ValidStatus(status) {
+		return fmt.Errorf("invalid status %q (want one of %s)", status, strings.Join(Statuses, ", "))
+	}
+	return Edit(p, func(d *Document) error {
+		d.SetField("Status", status)
+		return nil
+	})
+}
+
+// flattenTasks returns tasks and their subtasks in file order.
+func flattenTasks(tasks []Task) []Task {
+	var all []Task
+	for _, task := range tasks {
+		all = append(all, task)
+		all = append(all, flattenTasks(task.Subtasks)...)
+	}
+	return all
+}
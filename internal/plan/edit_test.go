@@ -0,0 +1,141 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEditPlan writes content to a plan file and loads it.
+func writeEditPlan(t *testing.T, content string) *Plan {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test-plan.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// readPlanFile returns the plan file's content.
+func readPlanFile(t *testing.T, p *Plan) string {
+	t.Helper()
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestAddTask(t *testing.T) {
+	p := writeEditPlan(t, "# Plan: Test\n\n## Tasks\n  - [x] One\n    - [ ] One.a\n  - [ ] Two\n\n## Notes\n*keep me*\n")
+
+	if err := AddTask(p, "Three"); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	want := "# Plan: Test\n\n## Tasks\n  - [x] One\n    - [ ] One.a\n  - [ ] Two\n  - [ ] Three\n\n## Notes\n*keep me*\n"
+	if got := readPlanFile(t, p); got != want {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+	if CountTotal(p.Tasks) != 4 {
+		t.Errorf("reloaded plan has %d tasks, want 4", CountTotal(p.Tasks))
+	}
+
+	if err := AddTask(p, "two\nlines"); err == nil {
+		t.Error("AddTask() should reject multi-line text")
+	}
+}
+
+func TestAddTask_NoTasks(t *testing.T) {
+	p := writeEditPlan(t, "# Plan: Test\n\nJust notes.\n")
+
+	if err := AddTask(p, "First"); err != nil {
+		t.Fatalf("AddTask() error = %v", err)
+	}
+	if got, want := readPlanFile(t, p), "# Plan: Test\n\nJust notes.\n\n- [ ] First\n"; got != want {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+}
+
+func TestCheckTask(t *testing.T) {
+	content := "# Plan: Test\n- [ ] Set up CI\n  - [ ] Add lint step\n- [ ] Write docs\n- [X] Ship it\n"
+
+	tests := []struct {
+		name    string
+		sel     string
+		line    int
+		wantErr string
+	}{
+		{name: "index", sel: "2", line: 3},
+		{name: "pattern", sel: "DOCS", line: 4},
+		{name: "already complete", sel: "ship", line: 5},
+		{name: "out of range", sel: "5", wantErr: "has 4 tasks"},
+		{name: "no match", sel: "deploy", wantErr: "no task"},
+		{name: "ambiguous", sel: "s", wantErr: "match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := writeEditPlan(t, content)
+			task, err := CheckTask(p, tt.sel)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("CheckTask() error = %v, want %q", err, tt.wantErr)
+				}
+				if readPlanFile(t, p) != content {
+					t.Error("plan changed on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckTask() error = %v", err)
+			}
+			if task.Line != tt.line || !task.Complete {
+				t.Errorf("task = %+v, want line %d checked", task, tt.line)
+			}
+
+			lines := strings.Split(readPlanFile(t, p), "\n")
+			for i, line := range strings.Split(content, "\n") {
+				want := line
+				if i == tt.line-1 && strings.Contains(line, "[ ]") {
+					want = strings.Replace(line, "[ ]", "[x]", 1)
+				}
+				if lines[i] != want {
+					t.Errorf("line %d = %q, want %q", i+1, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	p := writeEditPlan(t, "# Plan: Test\n**Status:** pending  <!-- set by ralph -->\n\n### T1\n**Status:** open\n")
+
+	if err := SetStatus(p, "blocked"); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if got, want := readPlanFile(t, p), "# Plan: Test\n**Status:** blocked  <!-- set by ralph -->\n\n### T1\n**Status:** open\n"; got != want {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+	if p.Status != "blocked" {
+		t.Errorf("reloaded status = %q, want blocked", p.Status)
+	}
+
+	if err := SetStatus(p, "done"); err == nil || !strings.Contains(err.Error(), "in_progress") {
+		t.Errorf("SetStatus() error = %v, want the valid statuses", err)
+	}
+}
+
+func TestSetStatus_Missing(t *testing.T) {
+	p := writeEditPlan(t, "# Plan: Test\n\n- [ ] One\n")
+
+	if err := SetStatus(p, "open"); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if got, want := readPlanFile(t, p), "# Plan: Test\n**Status:** open\n\n- [ ] One\n"; got != want {
+		t.Errorf("plan = %q, want %q", got, want)
+	}
+}
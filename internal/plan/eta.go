@@ -0,0 +1,154 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+// ETA is an estimate of the time remaining for a plan, based on the
+// average iteration duration and the number of tasks completed per iteration.
+type ETA struct {
+	// Done and Total are the plan's current task counts.
+	Done  int
+	Total int
+
+	// Iterations is the number of iterations the estimate is based on.
+	Iterations int
+
+	// AvgIteration is the average iteration wall time.
+	AvgIteration time.Duration
+
+	// Remaining is the estimated time until all tasks are complete.
+	// Only meaningful when Known is true.
+	Remaining time.Duration
+
+	// Known is false when no tasks have been completed yet, so there is
+	// no velocity to extrapolate from.
+	Known bool
+}
+
+// String formats the estimate, e.g. "4/9 tasks, ~2h remaining at current pace".
+func (e *ETA) String() string {
+	if e == nil {
+		return ""
+	}
+
+	tasks := fmt.Sprintf("%d/%d tasks", e.Done, e.Total)
+	switch {
+	case e.Total > 0 && e.Done >= e.Total:
+		return tasks + ", finishing up"
+	case !e.Known:
+		return tasks + ", ETA unknown"
+	default:
+		return fmt.Sprintf("%s, %s remaining at current pace", tasks, formatRemaining(e.Remaining))
+	}
+}
+
+// EstimateETA computes an ETA for a plan from its iteration events.
+// Velocity is the number of tasks completed since the plan first started
+// divided by the number of iterations run. Task counts come from the latest
+// iteration event (the worktree copy) and fall back to the plan itself.
+// Returns nil if the plan has no tasks or no iterations have been recorded.
+func EstimateETA(p *Plan, evs []events.Event) *ETA {
+	if p == nil {
+		return nil
+	}
+
+	done := CountComplete(p.Tasks)
+	total := CountTotal(p.Tasks)
+
+	// baseline is the number of tasks already done when the plan first started
+	baseline := -1
+	var iterations int
+	var elapsed time.Duration
+	for _, e := range evs {
+		if e.Plan != p.Name {
+			continue
+		}
+		switch e.Type {
+		case events.TypePlanStarted:
+			if baseline < 0 {
+				baseline = e.TasksDone
+			}
+		case events.TypeIteration:
+			if e.Duration > 0 {
+				iterations++
+				elapsed += e.Duration
+			}
+			if e.TasksTotal > 0 {
+				done, total = e.TasksDone, e.TasksTotal
+			}
+		case events.TypePlanCompleted, events.TypePlanReset:
+			// A plan with the same name ran before; only the latest run counts
+			baseline, iterations, elapsed = -1, 0, 0
+			done, total = CountComplete(p.Tasks), CountTotal(p.Tasks)
+		}
+	}
+	if baseline < 0 {
+		baseline = 0
+	}
+
+	if total == 0 || iterations == 0 {
+		return nil
+	}
+
+	eta := &ETA{
+		Done:         done,
+		Total:        total,
+		Iterations:   iterations,
+		AvgIteration: elapsed / time.Duration(iterations),
+	}
+
+	completed := done - baseline
+	if completed <= 0 {
+		return eta
+	}
+
+	// remaining iterations = remaining tasks / (completed tasks per iteration)
+	remainingTasks := total - done
+	eta.Remaining = time.Duration(int64(eta.AvgIteration) * int64(remainingTasks) * int64(iterations) / int64(completed))
+	eta.Known = true
+	return eta
+}
+
+// RunEvents returns the events of the named plan's latest run, oldest first:
+// those after its last plan_completed or plan_reset event. The events log is
+// read backwards, so earlier runs and other plans' history aren't parsed.
+func RunEvents(log *events.Log, name string) ([]events.Event, error) {
+	var run []events.Event
+	err := log.Reverse(func(e events.Event) bool {
+		if e.Plan != name {
+			return true
+		}
+		if e.Type == events.TypePlanCompleted || e.Type == events.TypePlanReset {
+			return false
+		}
+		run = append(run, e)
+		return true
+	})
+	for i, j := 0, len(run)-1; i < j; i, j = i+1, j-1 {
+		run[i], run[j] = run[j], run[i]
+	}
+	return run, err
+}
+
+// formatRemaining formats a duration as a rough estimate such as "~2h", "~1h30m", or "~15m".
+func formatRemaining(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("~%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("~%dh", hours)
+	default:
+		return fmt.Sprintf("~%dh%dm", hours, minutes)
+	}
+}
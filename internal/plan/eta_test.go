@@ -0,0 +1,142 @@
+package plan
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func etaPlan(done, total int) *Plan {
+	p := &Plan{Name: "alpha"}
+	for i := 0; i < total; i++ {
+		p.Tasks = append(p.Tasks, Task{Text: "task", Complete: i < done})
+	}
+	return p
+}
+
+func TestEstimateETA(t *testing.T) {
+	evs := []events.Event{
+		{Type: events.TypePlanStarted, Plan: "alpha", TasksDone: 1, TasksTotal: 9},
+		{Type: events.TypeIteration, Plan: "alpha", Iteration: 1, Duration: 20 * time.Minute, TasksDone: 2, TasksTotal: 9},
+		{Type: events.TypeIteration, Plan: "other", Iteration: 1, Duration: 5 * time.Hour},
+		{Type: events.TypeIteration, Plan: "alpha", Iteration: 2, Duration: 40 * time.Minute, TasksDone: 4, TasksTotal: 9},
+	}
+
+	// Plan file is stale (main copy); event counts take precedence
+	eta := EstimateETA(etaPlan(1, 9), evs)
+	if eta == nil {
+		t.Fatal("EstimateETA() = nil")
+	}
+	if eta.Done != 4 || eta.Total != 9 || eta.Iterations != 2 {
+		t.Errorf("eta = %+v", eta)
+	}
+	if eta.AvgIteration != 30*time.Minute {
+		t.Errorf("AvgIteration = %v, want 30m", eta.AvgIteration)
+	}
+
+	// 3 tasks in 2 iterations; 5 remaining → 10/3 iterations × 30m = 100m
+	if !eta.Known || eta.Remaining != 100*time.Minute {
+		t.Errorf("Remaining = %v (known %v), want 1h40m", eta.Remaining, eta.Known)
+	}
+	if got := eta.String(); got != "4/9 tasks, ~1h40m remaining at current pace" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestEstimateETA_NoVelocity(t *testing.T) {
+	evs := []events.Event{
+		{Type: events.TypePlanStarted, Plan: "alpha", TasksDone: 2},
+		{Type: events.TypeIteration, Plan: "alpha", Duration: time.Minute, TasksDone: 2, TasksTotal: 5},
+	}
+
+	eta := EstimateETA(etaPlan(2, 5), evs)
+	if eta == nil || eta.Known {
+		t.Fatalf("eta = %+v, want unknown", eta)
+	}
+	if got := eta.String(); got != "2/5 tasks, ETA unknown" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestEstimateETA_NotEnoughData(t *testing.T) {
+	if eta := EstimateETA(etaPlan(0, 3), nil); eta != nil {
+		t.Errorf("no iterations: eta = %+v, want nil", eta)
+	}
+	if eta := EstimateETA(etaPlan(0, 0), []events.Event{{Type: events.TypeIteration, Plan: "alpha", Duration: time.Minute}}); eta != nil {
+		t.Errorf("no tasks: eta = %+v, want nil", eta)
+	}
+	if eta := EstimateETA(nil, nil); eta != nil {
+		t.Errorf("nil plan: eta = %+v, want nil", eta)
+	}
+}
+
+func TestEstimateETA_IgnoresPreviousRun(t *testing.T) {
+	evs := []events.Event{
+		{Type: events.TypeIteration, Plan: "alpha", Duration: 10 * time.Hour, TasksDone: 3, TasksTotal: 3},
+		{Type: events.TypePlanCompleted, Plan: "alpha"},
+		{Type: events.TypePlanStarted, Plan: "alpha", TasksDone: 0},
+		{Type: events.TypeIteration, Plan: "alpha", Duration: 10 * time.Minute, TasksDone: 1, TasksTotal: 4},
+	}
+
+	eta := EstimateETA(etaPlan(0, 4), evs)
+	if eta == nil || eta.Iterations != 1 || eta.Remaining != 30*time.Minute {
+		t.Errorf("eta = %+v, want 1 iteration and 30m remaining", eta)
+	}
+
+	// A reset also starts the estimate over
+	evs[1].Type = events.TypePlanReset
+	eta = EstimateETA(etaPlan(0, 4), evs)
+	if eta == nil || eta.Iterations != 1 {
+		t.Errorf("after reset: eta = %+v, want 1 iteration", eta)
+	}
+}
+
+func TestRunEvents(t *testing.T) {
+	l := events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	l.Append(events.Event{Type: events.TypeIteration, Plan: "alpha", Iteration: 1})
+	l.Append(events.Event{Type: events.TypePlanCompleted, Plan: "alpha"})
+	l.Append(events.Event{Type: events.TypePlanStarted, Plan: "alpha"})
+	l.Append(events.Event{Type: events.TypePlanCompleted, Plan: "beta"})
+	l.Append(events.Event{Type: events.TypeIteration, Plan: "alpha", Iteration: 1})
+	l.Append(events.Event{Type: events.TypeIteration, Plan: "alpha", Iteration: 2})
+
+	run, err := RunEvents(l, "alpha")
+	if err != nil {
+		t.Fatalf("RunEvents() error = %v", err)
+	}
+	if len(run) != 3 || run[0].Type != events.TypePlanStarted || run[2].Iteration != 2 {
+		t.Errorf("RunEvents() = %+v, want the latest run's 3 events, oldest first", run)
+	}
+}
+
+func TestETA_String(t *testing.T) {
+	var nilETA *ETA
+	if nilETA.String() != "" {
+		t.Error("nil ETA should format as empty string")
+	}
+
+	done := &ETA{Done: 3, Total: 3, Known: true}
+	if got := done.String(); got != "3/3 tasks, finishing up" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestFormatRemaining(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{20 * time.Second, "<1m"},
+		{15 * time.Minute, "~15m"},
+		{2 * time.Hour, "~2h"},
+		{90 * time.Minute, "~1h30m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRemaining(tt.d); got != tt.want {
+			t.Errorf("formatRemaining(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
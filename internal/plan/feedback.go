@@ -18,8 +18,187 @@ func FeedbackPath(plan *Plan) string {
 	return strings.TrimSuffix(plan.Path, ext) + ".feedback.md"
 }
 
-// feedbackEntryRegex matches a feedback entry line like "- [2024-01-30 14:32] content"
-var feedbackEntryRegex = regexp.MustCompile(`^- \[\d{4}-\d{2}-\d{2} \d{2}:\d{2}\] .+`)
+// feedbackEntryRegex matches a feedback entry line like
+// "- [2024-01-30 14:32:05] content". The ID in brackets may carry a "#2"
+// suffix, and entries written before IDs had seconds have none.
+var feedbackEntryRegex = regexp.MustCompile(`^- \[(\d{4}-\d{2}-\d{2} \d{2}:\d{2}(?::\d{2})?(?:#\d+)?)\] (.+)`)
+
+// feedbackAuthorRegex matches the author in an entry's source, as written by
+// FeedbackSource: "Slack reply (by Alice Smith): ..."
+var feedbackAuthorRegex = regexp.MustCompile(`^[^:\n]*\(by ([^)\n]+)\): `)
+
+// feedbackTimeFormat is the timestamp format used in feedback entry IDs.
+const feedbackTimeFormat = "2006-01-02 15:04:05"
+
+// legacyFeedbackTimeFormat is the minute-resolution format of entries
+// written by earlier versions.
+const legacyFeedbackTimeFormat = "2006-01-02 15:04"
+
+// UrgentTag marks a feedback entry as urgent. Urgent entries are handled first
+// and trigger a notification if they stay pending.
+const UrgentTag = "!urgent"
+
+// outcomePrefix marks the acknowledgment note beneath a processed entry.
+const outcomePrefix = "Outcome:"
+
+// FeedbackEntry is a single parsed entry from a feedback file.
+type FeedbackEntry struct {
+	// ID is the entry timestamp as written (e.g., "2024-01-30 14:32:05", or
+	// "2024-01-30 14:32:05#2" for a second entry in the same second).
+	// Used to acknowledge the entry.
+	ID string
+
+	// Timestamp is when the entry was added.
+	Timestamp time.Time
+
+	// Text is the entry text after the timestamp, including any source prefix
+	// and continuation lines.
+	Text string
+
+	// Author is who wrote the entry, if it was attributed (see FeedbackSource).
+	Author string
+
+	// Urgent is true if the entry is tagged with UrgentTag.
+	Urgent bool
+
+	// Outcome is the acknowledgment note for processed entries.
+	Outcome string
+}
+
+// Age returns how long the entry has existed as of now.
+func (e FeedbackEntry) Age(now time.Time) time.Duration {
+	if e.Timestamp.IsZero() {
+		return 0
+	}
+	return now.Sub(e.Timestamp)
+}
+
+// Feedback holds the parsed entries of a feedback file.
+type Feedback struct {
+	Pending   []FeedbackEntry
+	Processed []FeedbackEntry
+}
+
+// UrgentPending returns the pending entries tagged urgent.
+func (f *Feedback) UrgentPending() []FeedbackEntry {
+	var urgent []FeedbackEntry
+	for _, e := range f.Pending {
+		if e.Urgent {
+			urgent = append(urgent, e)
+		}
+	}
+	return urgent
+}
+
+// LoadFeedback parses a plan's feedback file into pending and processed entries.
+// Returns empty Feedback if the file doesn't exist.
+func LoadFeedback(plan *Plan) (*Feedback, error) {
+	content, err := os.ReadFile(FeedbackPath(plan))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Feedback{}, nil
+		}
+		return nil, fmt.Errorf("reading feedback file: %w", err)
+	}
+
+	return parseFeedback(string(content)), nil
+}
+
+// parseFeedback parses feedback file content.
+// Indented lines following an entry are continuation lines of that entry.
+func parseFeedback(content string) *Feedback {
+	fb := &Feedback{}
+	var section *[]FeedbackEntry
+	var current *FeedbackEntry
+
+	flush := func() {
+		if current != nil && section != nil {
+			*section = append(*section, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "## ") {
+			flush()
+			switch {
+			case strings.EqualFold(trimmed, "## Pending"):
+				section = &fb.Pending
+			case strings.EqualFold(trimmed, "## Processed"):
+				section = &fb.Processed
+			default:
+				section = nil
+			}
+			continue
+		}
+
+		if section == nil {
+			continue
+		}
+
+		if m := feedbackEntryRegex.FindStringSubmatch(line); m != nil {
+			flush()
+			current = &FeedbackEntry{ID: m[1], Timestamp: feedbackTime(m[1]), Text: m[2]}
+			if a := feedbackAuthorRegex.FindStringSubmatch(m[2]); a != nil {
+				current.Author = a[1]
+			}
+			current.Urgent = strings.Contains(strings.ToLower(m[2]), UrgentTag)
+			continue
+		}
+
+		// Continuation line of the current entry
+		if current != nil && trimmed != "" && line != trimmed {
+			if strings.HasPrefix(trimmed, outcomePrefix) {
+				current.Outcome = strings.TrimSpace(strings.TrimPrefix(trimmed, outcomePrefix))
+			} else {
+				current.Text += "\n" + trimmed
+				if strings.Contains(strings.ToLower(trimmed), UrgentTag) {
+					current.Urgent = true
+				}
+			}
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+		}
+	}
+	flush()
+
+	return fb
+}
+
+// feedbackTime returns the time in an entry ID, or the zero time if it
+// can't be parsed.
+func feedbackTime(id string) time.Time {
+	id, _, _ = strings.Cut(id, "#")
+	for _, layout := range []string{feedbackTimeFormat, legacyFeedbackTimeFormat} {
+		if ts, err := time.ParseInLocation(layout, id, time.Local); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
+// feedbackID returns a new entry ID for timestamp that no entry in content
+// has: the timestamp to the second, with a "#2", "#3", ... suffix if
+// entries were already added in that second.
+func feedbackID(content string, timestamp time.Time) string {
+	taken := make(map[string]bool)
+	for _, line := range strings.Split(content, "\n") {
+		if m := feedbackEntryRegex.FindStringSubmatch(line); m != nil {
+			taken[m[1]] = true
+		}
+	}
+
+	id := timestamp.Format(feedbackTimeFormat)
+	for n := 2; taken[id]; n++ {
+		id = fmt.Sprintf("%s#%d", timestamp.Format(feedbackTimeFormat), n)
+	}
+	return id
+}
 
 // ReadFeedback reads the pending feedback entries from a plan's feedback file.
 // Returns an empty string if the file doesn't exist or has no pending entries.
@@ -75,9 +254,23 @@ func extractPendingSection(content string) string {
 	return strings.Join(pendingLines, "\n")
 }
 
+// FeedbackSource returns the source label for an entry by author, which
+// LoadFeedback parses back into FeedbackEntry.Author: "cli (by Alice Smith)".
+// An empty author returns source unchanged.
+func FeedbackSource(source, author string) string {
+	author = strings.NewReplacer("(", "", ")", "", ":", "", "\n", " ").Replace(strings.TrimSpace(author))
+	if author == "" {
+		return source
+	}
+	if source == "" {
+		return "(by " + author + ")"
+	}
+	return source + " (by " + author + ")"
+}
+
 // AppendFeedback appends a new timestamped entry to the Pending section of the feedback file.
 // Creates the file with proper structure if it doesn't exist.
-// Entry format: - [YYYY-MM-DD HH:MM] source: content
+// Entry format: - [YYYY-MM-DD HH:MM:SS] source: content
 func AppendFeedback(plan *Plan, source string, content string) error {
 	return AppendFeedbackWithTime(plan, source, content, time.Now())
 }
@@ -97,8 +290,8 @@ func AppendFeedbackWithTime(plan *Plan, source string, content string, timestamp
 		existing = []byte("")
 	}
 
-	// Format timestamp
-	ts := timestamp.Format("2006-01-02 15:04")
+	// The entry's ID, unique within the file
+	ts := feedbackID(string(existing), timestamp)
 
 	// Build entry line
 	var entry string
@@ -118,7 +311,7 @@ func AppendFeedbackWithTime(plan *Plan, source string, content string, timestamp
 	}
 
 	// Write file
-	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("writing feedback file: %w", err)
 	}
 
@@ -195,7 +388,7 @@ func MarkProcessed(plan *Plan, entry string) error {
 		return fmt.Errorf("entry not found in Pending section")
 	}
 
-	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("writing feedback file: %w", err)
 	}
 
@@ -280,6 +473,91 @@ func moveEntryToProcessed(content string, entry string) (string, bool) {
 	return strings.Join(finalResult, "\n"), true
 }
 
+// AcknowledgeFeedback moves the first pending entry with the given ID to the
+// Processed section, recording the outcome note beneath it.
+// Returns an error if no pending entry has the ID.
+func AcknowledgeFeedback(plan *Plan, id string, outcome string) error {
+	path := FeedbackPath(plan)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("feedback file does not exist")
+		}
+		return fmt.Errorf("reading feedback file: %w", err)
+	}
+
+	newContent, found := acknowledgeEntry(string(content), strings.TrimSpace(id), outcome)
+	if !found {
+		return fmt.Errorf("entry %q not found in Pending section", id)
+	}
+
+	if err := WriteFileAtomic(path, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("writing feedback file: %w", err)
+	}
+
+	return nil
+}
+
+// acknowledgeEntry removes the entry with the given ID (and its continuation
+// lines) from Pending and inserts it at the top of Processed with an outcome line.
+// Returns the new content and whether the entry was found.
+func acknowledgeEntry(content string, id string, outcome string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var result []string
+	var entryLines []string
+	inPending := false
+	inEntry := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "## ") {
+			inPending = strings.EqualFold(trimmed, "## Pending")
+			inEntry = false
+		} else if inPending && entryLines == nil {
+			if m := feedbackEntryRegex.FindStringSubmatch(line); m != nil && m[1] == id {
+				entryLines = append(entryLines, line)
+				inEntry = true
+				continue
+			}
+		} else if inEntry {
+			if trimmed != "" && line != trimmed && !feedbackEntryRegex.MatchString(line) {
+				entryLines = append(entryLines, line)
+				continue
+			}
+			inEntry = false
+		}
+
+		result = append(result, line)
+	}
+
+	if entryLines == nil {
+		return content, false
+	}
+
+	outcome = strings.Join(strings.Fields(outcome), " ")
+	if outcome == "" {
+		outcome = "processed"
+	}
+	entryLines = append(entryLines, "  "+outcomePrefix+" "+outcome)
+
+	// Insert at the top of Processed (create the section if missing)
+	for i, line := range result {
+		if strings.EqualFold(strings.TrimSpace(line), "## Processed") {
+			final := make([]string, 0, len(result)+len(entryLines))
+			final = append(final, result[:i+1]...)
+			final = append(final, entryLines...)
+			final = append(final, result[i+1:]...)
+			return strings.Join(final, "\n"), true
+		}
+	}
+
+	result = append(result, "", "## Processed")
+	result = append(result, entryLines...)
+	return strings.Join(result, "\n") + "\n", true
+}
+
 // CreateFeedbackFile creates a new feedback file with proper structure if it doesn't exist.
 // If the file already exists, does nothing.
 func CreateFeedbackFile(plan *Plan) error {
@@ -302,7 +580,7 @@ func CreateFeedbackFile(plan *Plan) error {
 		return fmt.Errorf("creating feedback directory: %w", err)
 	}
 
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("creating feedback file: %w", err)
 	}
 
@@ -148,7 +148,7 @@ func TestAppendFeedback_NewFile(t *testing.T) {
 	if !strings.Contains(contentStr, "## Pending") {
 		t.Errorf("File should have Pending section, got %q", contentStr)
 	}
-	if !strings.Contains(contentStr, "[2024-01-30 14:32] slack: Task completed successfully") {
+	if !strings.Contains(contentStr, "[2024-01-30 14:32:00] slack: Task completed successfully") {
 		t.Errorf("File should have entry, got %q", contentStr)
 	}
 	if !strings.Contains(contentStr, "## Processed") {
@@ -191,7 +191,7 @@ func TestAppendFeedback_ExistingFile(t *testing.T) {
 	if !strings.Contains(contentStr, "First entry") {
 		t.Errorf("Should preserve existing entries, got %q", contentStr)
 	}
-	if !strings.Contains(contentStr, "[2024-01-30 14:32] New feedback item") {
+	if !strings.Contains(contentStr, "[2024-01-30 14:32:00] New feedback item") {
 		t.Errorf("Should add new entry, got %q", contentStr)
 	}
 	if !strings.Contains(contentStr, "Old processed entry") {
@@ -222,7 +222,7 @@ func TestAppendFeedback_NoSource(t *testing.T) {
 	if strings.Contains(contentStr, "14:32] :") {
 		t.Errorf("Should not have colon when no source, got %q", contentStr)
 	}
-	if !strings.Contains(contentStr, "[2024-01-30 14:32] Feedback without source") {
+	if !strings.Contains(contentStr, "[2024-01-30 14:32:00] Feedback without source") {
 		t.Errorf("Should have entry without source prefix, got %q", contentStr)
 	}
 }
@@ -460,3 +460,237 @@ func TestFeedbackPath_PreservesDirectory(t *testing.T) {
 		t.Errorf("FeedbackPath() directory = %q, want %q", filepath.Dir(got), wantDir)
 	}
 }
+
+func TestLoadFeedback(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my-plan.md")
+	feedbackPath := filepath.Join(dir, "my-plan.feedback.md")
+
+	content := `# Feedback: my-plan
+
+## Pending
+- [2024-01-30 14:32] cli: !urgent Production login is broken
+- [2024-01-30 15:00] ci: FAIL: TestLogin
+  expected 200, got 500
+
+## Processed
+- [2024-01-29 09:00] Use OAuth instead of API keys
+  Outcome: Switched auth to OAuth
+`
+	if err := os.WriteFile(feedbackPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fb, err := LoadFeedback(&Plan{Path: planPath, Name: "my-plan"})
+	if err != nil {
+		t.Fatalf("LoadFeedback() error = %v", err)
+	}
+
+	if len(fb.Pending) != 2 {
+		t.Fatalf("len(Pending) = %d, want 2", len(fb.Pending))
+	}
+	if !fb.Pending[0].Urgent || fb.Pending[1].Urgent {
+		t.Errorf("only the first pending entry should be urgent: %+v", fb.Pending)
+	}
+	if fb.Pending[0].ID != "2024-01-30 14:32" {
+		t.Errorf("Pending[0].ID = %q, want %q", fb.Pending[0].ID, "2024-01-30 14:32")
+	}
+	if fb.Pending[1].Text != "ci: FAIL: TestLogin\nexpected 200, got 500" {
+		t.Errorf("Pending[1].Text = %q, want continuation line included", fb.Pending[1].Text)
+	}
+	if len(fb.UrgentPending()) != 1 {
+		t.Errorf("UrgentPending() = %d entries, want 1", len(fb.UrgentPending()))
+	}
+
+	if len(fb.Processed) != 1 {
+		t.Fatalf("len(Processed) = %d, want 1", len(fb.Processed))
+	}
+	if fb.Processed[0].Outcome != "Switched auth to OAuth" {
+		t.Errorf("Processed[0].Outcome = %q, want %q", fb.Processed[0].Outcome, "Switched auth to OAuth")
+	}
+
+	want := time.Date(2024, 1, 29, 9, 0, 0, 0, time.Local)
+	if !fb.Processed[0].Timestamp.Equal(want) {
+		t.Errorf("Processed[0].Timestamp = %v, want %v", fb.Processed[0].Timestamp, want)
+	}
+	if age := fb.Processed[0].Age(want.Add(2 * time.Hour)); age != 2*time.Hour {
+		t.Errorf("Age() = %v, want 2h", age)
+	}
+}
+
+func TestFeedbackSource(t *testing.T) {
+	tests := []struct {
+		source, author, want string
+	}{
+		{"cli", "", "cli"},
+		{"cli", "Alice Smith", "cli (by Alice Smith)"},
+		{"", "alice", "(by alice)"},
+		{"Slack reply", "Bob (ops): lead", "Slack reply (by Bob ops lead)"},
+	}
+	for _, tt := range tests {
+		if got := FeedbackSource(tt.source, tt.author); got != tt.want {
+			t.Errorf("FeedbackSource(%q, %q) = %q, want %q", tt.source, tt.author, got, tt.want)
+		}
+	}
+}
+
+func TestLoadFeedback_Author(t *testing.T) {
+	dir := t.TempDir()
+	p := &Plan{Path: filepath.Join(dir, "my-plan.md"), Name: "my-plan"}
+	ts := time.Date(2024, 1, 30, 14, 32, 0, 0, time.Local)
+	if err := AppendFeedbackWithTime(p, FeedbackSource("cli", "Alice Smith"), "Use OAuth (not keys): please", ts); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendFeedbackWithTime(p, "ci", "FAIL (by design?): TestLogin", ts.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	fb, err := LoadFeedback(p)
+	if err != nil {
+		t.Fatalf("LoadFeedback() error = %v", err)
+	}
+	if len(fb.Pending) != 2 {
+		t.Fatalf("len(Pending) = %d, want 2", len(fb.Pending))
+	}
+	if fb.Pending[0].Author != "Alice Smith" {
+		t.Errorf("Pending[0].Author = %q, want %q", fb.Pending[0].Author, "Alice Smith")
+	}
+	if fb.Pending[1].Author != "" {
+		t.Errorf("Pending[1].Author = %q, want none for an unattributed entry", fb.Pending[1].Author)
+	}
+}
+
+func TestLoadFeedback_NonExistent(t *testing.T) {
+	fb, err := LoadFeedback(&Plan{Path: filepath.Join(t.TempDir(), "missing.md")})
+	if err != nil {
+		t.Fatalf("LoadFeedback() error = %v", err)
+	}
+	if len(fb.Pending) != 0 || len(fb.Processed) != 0 {
+		t.Errorf("expected empty feedback, got %+v", fb)
+	}
+}
+
+func TestAcknowledgeFeedback(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my-plan.md")
+	feedbackPath := filepath.Join(dir, "my-plan.feedback.md")
+
+	content := `# Feedback: my-plan
+
+## Pending
+- [2024-01-30 14:32] ci: FAIL: TestLogin
+  expected 200, got 500
+- [2024-01-30 15:00] Another pending entry
+
+## Processed
+- [2024-01-29 09:00] Previously processed
+`
+	if err := os.WriteFile(feedbackPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plan{Path: planPath, Name: "my-plan"}
+	if err := AcknowledgeFeedback(p, "2024-01-30 14:32", "Fixed status code in login handler"); err != nil {
+		t.Fatalf("AcknowledgeFeedback() error = %v", err)
+	}
+
+	fb, err := LoadFeedback(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fb.Pending) != 1 || fb.Pending[0].ID != "2024-01-30 15:00" {
+		t.Errorf("Pending = %+v, want only the 15:00 entry", fb.Pending)
+	}
+	if len(fb.Processed) != 2 {
+		t.Fatalf("len(Processed) = %d, want 2", len(fb.Processed))
+	}
+	got := fb.Processed[0]
+	if got.ID != "2024-01-30 14:32" || got.Outcome != "Fixed status code in login handler" {
+		t.Errorf("Processed[0] = %+v", got)
+	}
+	if !strings.Contains(got.Text, "expected 200, got 500") {
+		t.Errorf("continuation lines should move with the entry, got %q", got.Text)
+	}
+}
+
+func TestAcknowledgeFeedback_CreatesProcessedSection(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my-plan.md")
+	feedbackPath := filepath.Join(dir, "my-plan.feedback.md")
+
+	if err := os.WriteFile(feedbackPath, []byte("## Pending\n- [2024-01-30 14:32] Only entry\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plan{Path: planPath, Name: "my-plan"}
+	if err := AcknowledgeFeedback(p, "2024-01-30 14:32", ""); err != nil {
+		t.Fatalf("AcknowledgeFeedback() error = %v", err)
+	}
+
+	fb, _ := LoadFeedback(p)
+	if len(fb.Pending) != 0 || len(fb.Processed) != 1 {
+		t.Fatalf("expected entry moved to Processed, got %+v", fb)
+	}
+	if fb.Processed[0].Outcome != "processed" {
+		t.Errorf("Outcome = %q, want default %q", fb.Processed[0].Outcome, "processed")
+	}
+}
+
+func TestAcknowledgeFeedback_SameMinute(t *testing.T) {
+	p := &Plan{Path: filepath.Join(t.TempDir(), "my-plan.md"), Name: "my-plan"}
+	at := time.Date(2024, 1, 30, 14, 32, 5, 0, time.Local)
+	AppendFeedbackWithTime(p, "slack", "First reply", at)
+	AppendFeedbackWithTime(p, "slack", "Second reply", at)
+	AppendFeedbackWithTime(p, "slack", "Third reply", at.Add(20*time.Second))
+
+	fb, _ := LoadFeedback(p)
+	if len(fb.Pending) != 3 {
+		t.Fatalf("expected 3 pending entries, got %+v", fb.Pending)
+	}
+	ids := map[string]bool{}
+	for _, e := range fb.Pending {
+		ids[e.ID] = true
+		if e.Timestamp.Truncate(time.Minute) != at.Truncate(time.Minute) {
+			t.Errorf("entry %s Timestamp = %v", e.ID, e.Timestamp)
+		}
+	}
+	if len(ids) != 3 {
+		t.Fatalf("entries in the same minute share IDs: %v", ids)
+	}
+
+	var second FeedbackEntry
+	for _, e := range fb.Pending {
+		if strings.Contains(e.Text, "Second reply") {
+			second = e
+		}
+	}
+	if err := AcknowledgeFeedback(p, second.ID, "done"); err != nil {
+		t.Fatalf("AcknowledgeFeedback(%q) error = %v", second.ID, err)
+	}
+	fb, _ = LoadFeedback(p)
+	if len(fb.Processed) != 1 || !strings.Contains(fb.Processed[0].Text, "Second reply") {
+		t.Errorf("expected only the second reply acknowledged, got %+v", fb.Processed)
+	}
+}
+
+func TestAcknowledgeFeedback_NotFound(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "my-plan.md")
+	feedbackPath := filepath.Join(dir, "my-plan.feedback.md")
+
+	content := "## Pending\n- [2024-01-30 14:32] Entry\n\n## Processed\n- [2024-01-29 09:00] Old\n"
+	if err := os.WriteFile(feedbackPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plan{Path: planPath, Name: "my-plan"}
+
+	// Processed entries cannot be acknowledged again
+	if err := AcknowledgeFeedback(p, "2024-01-29 09:00", "again"); err == nil {
+		t.Error("expected error for entry not in Pending")
+	}
+	if err := AcknowledgeFeedback(&Plan{Path: filepath.Join(dir, "missing.md")}, "2024-01-30 14:32", ""); err == nil {
+		t.Error("expected error for missing feedback file")
+	}
+}
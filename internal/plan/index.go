@@ -0,0 +1,46 @@
+package plan
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Index mirrors the queue in a state database (state.backend: sqlite, see
+// internal/state), so status queries on a queue with thousands of finished
+// plans don't parse every plan file.
+type Index interface {
+	// PlanMoved records that a plan is now in the named queue directory
+	// ("pending", "current", "complete", "abandoned", or "failed").
+	PlanMoved(p *Plan, dir string) error
+
+	// Plans returns the plans in the named queue directory, sorted by name,
+	// with Name, Path, Branch, and Labels filled in.
+	Plans(dir string) ([]*Plan, error)
+}
+
+// QueueDirs are the names of the queue directories, in lifecycle order.
+var QueueDirs = []string{"pending", "current", "complete", "abandoned", "failed"}
+
+// IsPlanFile reports whether a file name in a queue directory is a plan
+// rather than one of its sidecar files (progress, feedback, instructions,
+// summary).
+func IsPlanFile(name string) bool {
+	if filepath.Ext(name) != ".md" {
+		return false
+	}
+	for _, suffix := range []string{".progress.md", ".feedback.md", ".instructions.md", ".summary.md"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// finished lists the plans in a finished-plans directory, from the index if
+// the queue has one.
+func (q *Queue) finished(dir string) ([]*Plan, error) {
+	if q.Index != nil {
+		return q.Index.Plans(filepath.Base(dir))
+	}
+	return q.listPlans(dir)
+}
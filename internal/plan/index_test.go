@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeIndex is an in-memory Index.
+type fakeIndex struct {
+	moves []string
+	plans map[string][]*Plan
+}
+
+func (f *fakeIndex) PlanMoved(p *Plan, dir string) error {
+	f.moves = append(f.moves, p.Name+"->"+dir)
+	return nil
+}
+
+func (f *fakeIndex) Plans(dir string) ([]*Plan, error) {
+	return f.plans[dir], nil
+}
+
+func TestIsPlanFile(t *testing.T) {
+	tests := map[string]bool{
+		"my-feature.md":              true,
+		"my-feature.progress.md":     false,
+		"my-feature.feedback.md":     false,
+		"my-feature.instructions.md": false,
+		"my-feature.summary.md":      false,
+		"my-feature.changes.json":    false,
+		".lock":                      false,
+	}
+	for name, want := range tests {
+		if got := IsPlanFile(name); got != want {
+			t.Errorf("IsPlanFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestQueue_Index(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	index := &fakeIndex{plans: map[string][]*Plan{
+		"complete": {{Name: "done-1", Labels: []string{"backend"}}, {Name: "done-2"}},
+		"failed":   {{Name: "broken"}},
+	}}
+	q := NewQueue(tmpDir)
+	q.Index = index
+
+	// Finished plans come from the index, not the directories
+	createTestPlanFile(t, q.completeDir(), "on-disk-only")
+	status, err := q.Status()
+	if err != nil {
+		t.Fatalf("getting status: %v", err)
+	}
+	if status.CompleteCount != 2 || status.FailedCount != 1 || !reflect.DeepEqual(status.FailedPlans, []string{"broken"}) {
+		t.Errorf("status = %+v, want 2 complete and 1 failed from the index", status)
+	}
+	if status, _ := q.StatusFor([]string{"backend"}); status.CompleteCount != 1 {
+		t.Errorf("StatusFor(backend).CompleteCount = %d, want 1", status.CompleteCount)
+	}
+
+	// Moves are recorded in the index
+	p, err := Load(createTestPlanFile(t, q.pendingDir(), "feature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Activate(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Complete(p); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"feature->current", "feature->complete"}; !reflect.DeepEqual(index.moves, want) {
+		t.Errorf("moves = %q, want %q", index.moves, want)
+	}
+}
@@ -0,0 +1,29 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InstructionsPath returns the path to the plan-specific instructions file.
+// The instructions file is named "<plan-name>.instructions.md" in the same directory as the plan.
+// Example: "plans/current/go-rewrite.md" → "plans/current/go-rewrite.instructions.md"
+func InstructionsPath(plan *Plan) string {
+	ext := filepath.Ext(plan.Path)
+	return strings.TrimSuffix(plan.Path, ext) + ".instructions.md"
+}
+
+// ReadInstructions reads the plan-specific instructions, trimmed of
+// surrounding whitespace. Returns an empty string if the file doesn't exist.
+func ReadInstructions(plan *Plan) (string, error) {
+	content, err := os.ReadFile(InstructionsPath(plan))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading instructions file: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,36 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstructionsPath(t *testing.T) {
+	p := &Plan{Path: filepath.Join("plans", "current", "go-rewrite.md")}
+	want := filepath.Join("plans", "current", "go-rewrite.instructions.md")
+	if got := InstructionsPath(p); got != want {
+		t.Errorf("InstructionsPath() = %q, want %q", got, want)
+	}
+}
+
+func TestReadInstructions(t *testing.T) {
+	dir := t.TempDir()
+	p := &Plan{Path: filepath.Join(dir, "feature.md")}
+
+	got, err := ReadInstructions(p)
+	if err != nil || got != "" {
+		t.Errorf("ReadInstructions() without file = %q, %v", got, err)
+	}
+
+	if err := os.WriteFile(InstructionsPath(p), []byte("\n- Keep handlers thin\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadInstructions(p)
+	if err != nil {
+		t.Fatalf("ReadInstructions() error = %v", err)
+	}
+	if got != "- Keep handlers thin" {
+		t.Errorf("ReadInstructions() = %q", got)
+	}
+}
@@ -0,0 +1,70 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+// HasLabels reports whether the plan has all of the given labels, compared
+// case-insensitively. Any plan matches an empty list.
+func (p *Plan) HasLabels(labels []string) bool {
+	return MatchLabels(p.Labels, labels)
+}
+
+// MatchLabels reports whether have contains all of want, compared
+// case-insensitively. An empty want matches anything.
+func MatchLabels(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if strings.EqualFold(strings.TrimSpace(w), h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterByLabels returns the plans that have all of the given labels.
+func FilterByLabels(plans []*Plan, labels []string) []*Plan {
+	if len(labels) == 0 {
+		return plans
+	}
+	var filtered []*Plan
+	for _, p := range plans {
+		if p.HasLabels(labels) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// EventsWithLabels returns the events of plans that have all of the given
+// labels. A plan's labels are taken from the latest of its events that
+// records them (plan start events do), so plans that haven't started since
+// they were labeled are left out. An empty labels list returns evs unchanged.
+func EventsWithLabels(evs []events.Event, labels []string) []events.Event {
+	if len(labels) == 0 {
+		return evs
+	}
+
+	planLabels := make(map[string][]string)
+	for _, e := range evs {
+		if e.Plan != "" && e.Labels != nil {
+			planLabels[e.Plan] = e.Labels
+		}
+	}
+
+	var filtered []events.Event
+	for _, e := range evs {
+		if have, ok := planLabels[e.Plan]; ok && MatchLabels(have, labels) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
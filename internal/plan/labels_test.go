@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func TestMatchLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		have []string
+		want []string
+		ok   bool
+	}{
+		{"no filter", nil, nil, true},
+		{"match", []string{"backend", "urgent"}, []string{"urgent"}, true},
+		{"all required", []string{"backend"}, []string{"backend", "urgent"}, false},
+		{"case-insensitive", []string{"docs"}, []string{"Docs"}, true},
+		{"unlabeled", nil, []string{"docs"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLabels(tt.have, tt.want); got != tt.ok {
+				t.Errorf("MatchLabels(%q, %q) = %v, want %v", tt.have, tt.want, got, tt.ok)
+			}
+		})
+	}
+}
+
+func TestFilterByLabels(t *testing.T) {
+	plans := []*Plan{
+		{Name: "api", Labels: []string{"backend"}},
+		{Name: "guide", Labels: []string{"docs"}},
+		{Name: "misc"},
+	}
+
+	if got := FilterByLabels(plans, nil); len(got) != 3 {
+		t.Errorf("FilterByLabels(nil) returned %d plans, want 3", len(got))
+	}
+	got := FilterByLabels(plans, []string{"docs"})
+	if len(got) != 1 || got[0].Name != "guide" {
+		t.Errorf("FilterByLabels(docs) = %v, want [guide]", got)
+	}
+}
+
+func TestEventsWithLabels(t *testing.T) {
+	evs := []events.Event{
+		{Type: events.TypePlanStarted, Plan: "api", Labels: []string{"backend"}},
+		{Type: events.TypePlanStarted, Plan: "guide", Labels: []string{"docs"}},
+		{Type: events.TypeIteration, Plan: "api", Iteration: 1},
+		{Type: events.TypeIteration, Plan: "guide", Iteration: 1},
+		{Type: events.TypeIteration, Plan: "old", Iteration: 1},
+		{Type: events.TypeDigestSent},
+	}
+
+	if got := EventsWithLabels(evs, nil); len(got) != len(evs) {
+		t.Errorf("EventsWithLabels(nil) returned %d events, want %d", len(got), len(evs))
+	}
+	got := EventsWithLabels(evs, []string{"backend"})
+	if len(got) != 2 {
+		t.Fatalf("EventsWithLabels(backend) returned %d events, want 2: %+v", len(got), got)
+	}
+	for _, e := range got {
+		if e.Plan != "api" {
+			t.Errorf("unexpected event for plan %q", e.Plan)
+		}
+	}
+}
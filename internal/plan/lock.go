@@ -0,0 +1,103 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// LockFileName is the advisory lock file created in each queue directory.
+const LockFileName = ".lock"
+
+// DefaultLockTimeout is how long queue operations wait for a lock held by
+// another process before giving up.
+const DefaultLockTimeout = 10 * time.Second
+
+// lockRetryInterval is the delay between attempts to take a held lock.
+const lockRetryInterval = 50 * time.Millisecond
+
+// ErrQueueLocked is returned when a queue directory stays locked by another
+// process (a worker, CLI command, or Slack bot) for longer than the lock timeout.
+var ErrQueueLocked = errors.New("queue is locked by another ralph process")
+
+// errLockHeld is returned by tryLockFile when another process holds the lock.
+var errLockHeld = errors.New("lock held")
+
+// queueLock holds advisory locks on one or more queue directories.
+type queueLock struct {
+	files []*os.File
+}
+
+// lock takes the advisory locks of the given queue directories, creating
+// them if needed. Directories are locked in sorted order so two operations
+// moving plans in opposite directions can't deadlock.
+// Returns ErrQueueLocked if a lock can't be taken within the queue's lock timeout.
+func (q *Queue) lock(dirs ...string) (*queueLock, error) {
+	timeout := q.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+
+	paths := make([]string, 0, len(dirs))
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		path := filepath.Join(resolvePath(dir), LockFileName)
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	l := &queueLock{}
+	for _, path := range paths {
+		f, err := acquireLock(path, timeout)
+		if err != nil {
+			l.unlock()
+			return nil, err
+		}
+		l.files = append(l.files, f)
+	}
+	return l, nil
+}
+
+// acquireLock opens the lock file at path and takes an exclusive lock on it,
+// retrying until timeout.
+func acquireLock(path string, timeout time.Duration) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating queue directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, errLockHeld) {
+			f.Close()
+			return nil, fmt.Errorf("locking %s: %w", path, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s still held after %s", ErrQueueLocked, path, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// unlock releases all held locks. Safe to call on a partially acquired lock.
+func (l *queueLock) unlock() {
+	for i := len(l.files) - 1; i >= 0; i-- {
+		unlockFile(l.files[i])
+		l.files[i].Close()
+	}
+	l.files = nil
+}
@@ -0,0 +1,125 @@
+package plan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue_Lock_Timeout(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	holder := NewQueue(tmpDir)
+	l, err := holder.lock(holder.pendingDir())
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+
+	q := NewQueue(tmpDir)
+	q.LockTimeout = 100 * time.Millisecond
+	createTestPlanFile(t, filepath.Join(tmpDir, "pending"), "alpha")
+	p, err := Load(filepath.Join(tmpDir, "pending", "alpha.md"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := q.Activate(p); !errors.Is(err, ErrQueueLocked) {
+		t.Fatalf("Activate() while locked error = %v, want ErrQueueLocked", err)
+	}
+
+	l.unlock()
+	if err := q.Activate(p); err != nil {
+		t.Errorf("Activate() after unlock error = %v", err)
+	}
+}
+
+func TestQueue_Lock_WaitsForRelease(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	l, err := q.lock(q.currentDir())
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l.unlock()
+	}()
+
+	start := time.Now()
+	l2, err := q.lock(q.currentDir(), q.pendingDir())
+	if err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+	l2.unlock()
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("lock() should wait until the held lock is released")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "current", LockFileName)); err != nil {
+		t.Errorf("lock file not created: %v", err)
+	}
+}
+
+func TestQueue_Activate_Concurrent(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	names := []string{"alpha", "beta", "gamma", "delta"}
+	for _, name := range names {
+		createTestPlanFile(t, filepath.Join(tmpDir, "pending"), name)
+	}
+
+	// Separate queues, as separate processes would have
+	var wg sync.WaitGroup
+	errs := make([]error, len(names))
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			p, err := Load(filepath.Join(tmpDir, "pending", name+".md"))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = NewQueue(tmpDir).Activate(p)
+		}(i, name)
+	}
+	wg.Wait()
+
+	activated := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			activated++
+		case !errors.Is(err, ErrQueueFull):
+			t.Errorf("Activate(%s) error = %v, want nil or ErrQueueFull", names[i], err)
+		}
+	}
+	if activated != 1 {
+		t.Errorf("%d plans activated, want exactly 1", activated)
+	}
+	if current, err := NewQueue(tmpDir).Current(); err != nil || current == nil {
+		t.Errorf("Current() = %v, %v; want one current plan", current, err)
+	}
+}
+
+func TestQueue_Activate_PlanMovedByOtherProcess(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	path := createTestPlanFile(t, filepath.Join(tmpDir, "pending"), "alpha")
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	os.Remove(path)
+
+	if err := NewQueue(tmpDir).Activate(p); !errors.Is(err, ErrPlanNotInPending) {
+		t.Errorf("Activate() error = %v, want ErrPlanNotInPending", err)
+	}
+}
@@ -0,0 +1,90 @@
+package plan
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// codeSpanRegex matches a backtick-quoted token without spaces.
+var codeSpanRegex = regexp.MustCompile("`([^`\\s]+)`")
+
+// fileExtRegex matches a lowercase file extension such as ".go" or ".yaml".
+var fileExtRegex = regexp.MustCompile(`^\.[a-z][a-z0-9]{0,5}$`)
+
+// EstimatePaths returns the repository paths a plan is likely to touch: its
+// **Scope:** header, the files earlier iterations changed (from the changes
+// ledger), and file paths mentioned in backticks in the plan. Directories
+// end in "/". Sorted, without duplicates.
+func EstimatePaths(p *Plan) []string {
+	seen := make(map[string]bool)
+	add := func(s string) {
+		s = strings.TrimPrefix(s, "./")
+		if s != "" && s != "/" {
+			seen[s] = true
+		}
+	}
+
+	for _, s := range p.Scope {
+		add(s)
+	}
+	if changes, err := LoadChanges(p); err == nil {
+		for _, f := range changes.Files {
+			add(f.Path)
+		}
+	}
+	for _, m := range codeSpanRegex.FindAllStringSubmatch(p.Content, -1) {
+		if looksLikePath(m[1]) {
+			add(m[1])
+		}
+	}
+
+	paths := make([]string, 0, len(seen))
+	for s := range seen {
+		paths = append(paths, s)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// looksLikePath reports whether a code span from a plan names a file or
+// directory rather than a command, identifier, or URL: it must contain a
+// slash or end in a lowercase file extension (so `fmt.Println` and
+// `./...` are left out).
+func looksLikePath(s string) bool {
+	if strings.Contains(s, "://") || strings.Contains(s, "...") || strings.ContainsAny(s, "*?[]{}()<>=$:;,'\"|\\") || strings.HasPrefix(s, "-") {
+		return false
+	}
+	if strings.Contains(strings.Trim(s, "/"), "/") || strings.HasSuffix(s, "/") {
+		return true
+	}
+	return fileExtRegex.MatchString(path.Ext(s))
+}
+
+// OverlappingPaths returns the paths of a that overlap a path of b: the same
+// file, or a file or directory inside a directory ("dir/") of the other. For
+// each overlapping pair the more specific path is returned. Sorted, without
+// duplicates.
+func OverlappingPaths(a, b []string) []string {
+	seen := make(map[string]bool)
+	for _, x := range a {
+		for _, y := range b {
+			switch {
+			case x == y:
+				seen[x] = true
+			case strings.HasSuffix(x, "/") && strings.HasPrefix(y, x):
+				seen[y] = true
+			case strings.HasSuffix(y, "/") && strings.HasPrefix(x, y):
+				seen[x] = true
+			}
+		}
+	}
+
+	overlap := make([]string, 0, len(seen))
+	for s := range seen {
+		overlap = append(overlap, s)
+	}
+	sort.Strings(overlap)
+	return overlap
+}
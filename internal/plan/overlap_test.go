@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEstimatePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "overlap.md")
+	content := "# Plan: Overlap\n**Status:** open\n**Scope:** internal/worker/\n\n" +
+		"- [ ] Add a `Completed` method to `internal/plan/queue.go`\n" +
+		"- [ ] Document it in `README.md`, call `fmt.Println` and run `go test ./...`\n" +
+		"- [ ] See `https://example.com/docs` and `*.go`\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordChanges(p, 1, []FileChange{{Path: "internal/worker/worker.go", Change: ChangeModified}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"README.md", "internal/plan/queue.go", "internal/worker/", "internal/worker/worker.go"}
+	if got := EstimatePaths(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("EstimatePaths() = %q, want %q", got, want)
+	}
+}
+
+func TestLooksLikePath(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"README.md", true},
+		{"internal/plan", true},
+		{"docs/", true},
+		{"config.yaml", true},
+		{"fmt.Println", false},
+		{"v1.2.3", false},
+		{"Completed", false},
+		{"--dry-run", false},
+		{"https://example.com/a", false},
+		{"*.go", false},
+		{"./...", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikePath(tt.s); got != tt.want {
+			t.Errorf("looksLikePath(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestOverlappingPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []string
+	}{
+		{"same file", []string{"README.md", "main.go"}, []string{"main.go"}, []string{"main.go"}},
+		{"file in directory", []string{"internal/worker/"}, []string{"internal/worker/worker.go", "cmd/main.go"}, []string{"internal/worker/worker.go"}},
+		{"nested directories", []string{"internal/"}, []string{"internal/plan/"}, []string{"internal/plan/"}},
+		{"sibling files", []string{"internal/plan/queue.go"}, []string{"internal/plan/plan.go"}, []string{}},
+		{"prefix is not a directory", []string{"internal/plan"}, []string{"internal/planner.go"}, []string{}},
+		{"empty", nil, []string{"main.go"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverlappingPaths(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OverlappingPaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
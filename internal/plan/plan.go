@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Plan represents a parsed plan file.
@@ -26,13 +27,89 @@ type Plan struct {
 	// Defaults to "pending" if not found.
 	Status string
 
-	// Branch is the git branch name for this plan (e.g., "feat/go-rewrite").
+	// Branch is the git branch name for this plan: the **Branch:** header
+	// (set by the worker from git.branch_template when the plan starts, or
+	// by hand), or "feat/<name>" (e.g., "feat/go-rewrite").
 	Branch string
+
+	// Notify is an optional Slack channel override from the **Notify:** header
+	// (e.g., "#payments-team" or a channel ID). Empty means the global channel.
+	Notify string
+
+	// Model is an optional model override from the **Model:** header
+	// (e.g., "opus"). Empty means runner.model.
+	Model string
+
+	// MaxDuration is how long the plan may run, from the **Max-Duration:**
+	// header (e.g., "2h"). Zero means worker.max_plan_duration.
+	MaxDuration time.Duration
+
+	// Mode is the iteration mode from the **Mode:** header, lowercased
+	// (e.g., "tdd", see ModeTDD). Empty means the normal loop.
+	Mode string
+
+	// Scope lists the paths the plan is expected to touch, from the
+	// **Scope:** header (e.g., "internal/worker/, cmd/ralph/main.go").
+	Scope []string
+
+	// Labels are the plan's labels from the **Labels:** header (e.g.,
+	// "backend, urgent"), lowercased. Commands can filter plans by label.
+	Labels []string
+
+	// Jira is the Jira issue key from the **Jira:** header (e.g.,
+	// "PROJ-123"). The worker keeps the issue's status in sync.
+	Jira string
+
+	// Linear is the Linear issue identifier from the **Linear:** header
+	// (e.g., "ENG-42"). The worker keeps the issue's status in sync.
+	Linear string
+
+	// GitHub is the GitHub issue number from the **GitHub:** header (e.g.,
+	// "#42" is "42"). The worker keeps the issue's status in sync.
+	GitHub string
+
+	// SnoozedUntil is when a snoozed plan wakes, from the **Snoozed-Until:**
+	// header (see Snooze). Zero means the plan isn't snoozed.
+	SnoozedUntil time.Time
 }
 
 // statusRegex matches **Status:** value patterns in markdown.
 var statusRegex = regexp.MustCompile(`(?m)^\*\*Status:\*\*\s*(\S+)`)
 
+// notifyRegex matches the **Notify:** channel override in markdown.
+var notifyRegex = regexp.MustCompile(`(?m)^\*\*Notify:\*\*[ \t]*(\S+)`)
+
+// branchRegex matches the **Branch:** name in markdown.
+var branchRegex = regexp.MustCompile(`(?m)^\*\*Branch:\*\*[ \t]*(\S+)`)
+
+// modelRegex matches the **Model:** override in markdown.
+var modelRegex = regexp.MustCompile(`(?m)^\*\*Model:\*\*[ \t]*(\S+)`)
+
+// maxDurationRegex matches the **Max-Duration:** limit in markdown.
+var maxDurationRegex = regexp.MustCompile(`(?m)^\*\*Max-Duration:\*\*[ \t]*(\S+)`)
+
+// modeRegex matches the **Mode:** iteration mode in markdown.
+var modeRegex = regexp.MustCompile(`(?m)^\*\*Mode:\*\*[ \t]*(\S+)`)
+
+// ModeTDD is the test-first iteration mode: iterations alternate between
+// writing tests and implementing until they pass.
+const ModeTDD = "tdd"
+
+// labelsRegex matches the **Labels:** list in markdown.
+var labelsRegex = regexp.MustCompile(`(?m)^\*\*Labels:\*\*[ \t]*(.+)$`)
+
+// jiraRegex matches the **Jira:** issue key in markdown.
+var jiraRegex = regexp.MustCompile(`(?m)^\*\*Jira:\*\*[ \t]*(\S+)`)
+
+// linearRegex matches the **Linear:** issue identifier in markdown.
+var linearRegex = regexp.MustCompile(`(?m)^\*\*Linear:\*\*[ \t]*(\S+)`)
+
+// githubRegex matches the **GitHub:** issue number in markdown.
+var githubRegex = regexp.MustCompile(`(?m)^\*\*GitHub:\*\*[ \t]*#?(\d+)\b`)
+
+// scopeRegex matches the **Scope:** path list in markdown.
+var scopeRegex = regexp.MustCompile(`(?m)^\*\*Scope:\*\*[ \t]*(.+)$`)
+
 // Load reads and parses a plan file from the given path.
 // It extracts the name, status, and branch from the content.
 // Returns an error if the file cannot be read.
@@ -49,16 +126,30 @@ func Load(path string) (*Plan, error) {
 
 	name := deriveName(absPath)
 	status := extractStatus(string(content))
-	branch := deriveBranch(name)
+	branch := extractBranch(string(content))
+	if branch == "" {
+		branch = deriveBranch(name)
+	}
 	tasks := ExtractTasks(string(content))
 
 	return &Plan{
-		Path:    absPath,
-		Name:    name,
-		Content: string(content),
-		Tasks:   tasks,
-		Status:  status,
-		Branch:  branch,
+		Path:        absPath,
+		Name:        name,
+		Content:     string(content),
+		Tasks:       tasks,
+		Status:      status,
+		Branch:      branch,
+		Notify:      extractNotify(string(content)),
+		Model:       extractModel(string(content)),
+		MaxDuration: extractMaxDuration(string(content)),
+		Mode:        extractMode(string(content)),
+		Scope:       extractScope(string(content)),
+		Labels:      extractLabels(string(content)),
+		Jira:        extractJira(string(content)),
+		Linear:      extractLinear(string(content)),
+		GitHub:      extractGitHub(string(content)),
+
+		SnoozedUntil: extractSnoozedUntil(string(content)),
 	}, nil
 }
 
@@ -81,6 +172,122 @@ func extractStatus(content string) string {
 	return "pending"
 }
 
+// extractNotify finds the **Notify:** channel override in the plan content.
+// Returns "" if not found.
+func extractNotify(content string) string {
+	matches := notifyRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// extractBranch finds the **Branch:** name in the plan content.
+// Returns "" if not found.
+func extractBranch(content string) string {
+	matches := branchRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// extractModel finds the **Model:** override in the plan content.
+// Returns "" if not found.
+func extractModel(content string) string {
+	matches := modelRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// extractMaxDuration finds the **Max-Duration:** limit in the plan content.
+// Returns 0 if not found or not a positive duration.
+func extractMaxDuration(content string) time.Duration {
+	matches := maxDurationRegex.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return 0
+	}
+	d, err := time.ParseDuration(matches[1])
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// extractMode finds the **Mode:** iteration mode in the plan content,
+// lowercased. Returns "" if not found.
+func extractMode(content string) string {
+	matches := modeRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return strings.ToLower(matches[1])
+	}
+	return ""
+}
+
+// extractJira finds the **Jira:** issue key in the plan content.
+// Returns "" if not found.
+func extractJira(content string) string {
+	matches := jiraRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return strings.ToUpper(matches[1])
+	}
+	return ""
+}
+
+// extractGitHub finds the **GitHub:** issue number in the plan content,
+// without the "#". Returns "" if not found.
+func extractGitHub(content string) string {
+	matches := githubRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+// extractLinear finds the **Linear:** issue identifier in the plan content.
+// Returns "" if not found.
+func extractLinear(content string) string {
+	matches := linearRegex.FindStringSubmatch(content)
+	if len(matches) >= 2 {
+		return strings.ToUpper(matches[1])
+	}
+	return ""
+}
+
+// extractScope finds the **Scope:** paths in the plan content, separated by
+// commas or spaces and optionally in backticks. Returns nil if not found.
+func extractScope(content string) []string {
+	matches := scopeRegex.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return nil
+	}
+	var scope []string
+	for _, field := range strings.FieldsFunc(matches[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if path := strings.TrimPrefix(strings.Trim(field, "`"), "./"); path != "" {
+			scope = append(scope, path)
+		}
+	}
+	return scope
+}
+
+// extractLabels finds the **Labels:** in the plan content, separated by
+// commas and lowercased. Returns nil if not found.
+func extractLabels(content string) []string {
+	matches := labelsRegex.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return nil
+	}
+	var labels []string
+	for _, field := range strings.Split(matches[1], ",") {
+		if label := strings.ToLower(strings.TrimSpace(field)); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
 // deriveBranch creates a git branch name from the plan name.
 // "go-rewrite" → "feat/go-rewrite"
 // "my plan (v2)" → "feat/my-plan-v2"
@@ -89,6 +296,12 @@ func deriveBranch(name string) string {
 	return "feat/" + sanitized
 }
 
+// Slug returns name as used in plan file and branch names, e.g. "my plan
+// (v2)" → "my-plan-v2" (see sanitizeBranchName).
+func Slug(name string) string {
+	return sanitizeBranchName(name)
+}
+
 // sanitizeBranchName converts a plan name to a valid git branch name.
 // - Converts to lowercase
 // - Replaces spaces with hyphens
@@ -3,7 +3,9 @@ package plan
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoad_ValidPlan(t *testing.T) {
@@ -159,6 +161,176 @@ func TestExtractStatus(t *testing.T) {
 	}
 }
 
+func TestExtractNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"channel name", "# Plan\n**Status:** open\n**Notify:** #payments-team\n", "#payments-team"},
+		{"channel ID", "**Notify:** C0123456789", "C0123456789"},
+		{"missing", "# Plan\n**Status:** open\n", ""},
+		{"empty value", "**Notify:**\n\nText", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractNotify(tt.content); got != tt.want {
+				t.Errorf("extractNotify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"alias", "# Plan\n**Status:** open\n**Model:** opus\n", "opus"},
+		{"full name", "**Model:** claude-sonnet-4-20250514", "claude-sonnet-4-20250514"},
+		{"missing", "# Plan\n**Status:** open\n", ""},
+		{"empty value", "**Model:**\n\nText", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractModel(tt.content); got != tt.want {
+				t.Errorf("extractModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractMaxDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"# Plan\n**Status:** open\n**Max-Duration:** 2h\n": 2 * time.Hour,
+		"**Max-Duration:** 90m":                            90 * time.Minute,
+		"**Max-Duration:** soon":                           0,
+		"**Max-Duration:** -1h":                            0,
+		"# Plan\n**Status:** open\n":                       0,
+	}
+	for content, want := range tests {
+		if got := extractMaxDuration(content); got != want {
+			t.Errorf("extractMaxDuration(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestExtractMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"tdd", "# Plan\n**Status:** open\n**Mode:** tdd\n", ModeTDD},
+		{"uppercase", "**Mode:** TDD", ModeTDD},
+		{"missing", "# Plan\n**Status:** open\n", ""},
+		{"empty value", "**Mode:**\n\nText", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractMode(tt.content); got != tt.want {
+				t.Errorf("extractMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"commas", "# Plan\n**Status:** open\n**Scope:** internal/worker/, cmd/ralph/main.go\n", []string{"internal/worker/", "cmd/ralph/main.go"}},
+		{"backticks", "**Scope:** `./docs/` `README.md`", []string{"docs/", "README.md"}},
+		{"missing", "# Plan\n**Status:** open\n", nil},
+		{"empty value", "**Scope:**\n\nText", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractScope(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractScope() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"list", "# Plan\n**Status:** open\n**Labels:** backend, Urgent\n", []string{"backend", "urgent"}},
+		{"single", "**Labels:** docs", []string{"docs"}},
+		{"blank entries", "**Labels:** docs, , api ,", []string{"docs", "api"}},
+		{"missing", "# Plan\n**Status:** open\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractLabels(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractLabels() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJira(t *testing.T) {
+	for content, want := range map[string]string{
+		"# Plan\n**Status:** open\n**Jira:** PROJ-123\n": "PROJ-123",
+		"**Jira:** proj-7":           "PROJ-7",
+		"# Plan\n**Status:** open\n": "",
+	} {
+		if got := extractJira(content); got != want {
+			t.Errorf("extractJira(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestExtractLinear(t *testing.T) {
+	for content, want := range map[string]string{
+		"# Plan\n**Status:** open\n**Linear:** ENG-42\n": "ENG-42",
+		"**Linear:** eng-7":          "ENG-7",
+		"# Plan\n**Jira:** PROJ-1\n": "",
+	} {
+		if got := extractLinear(content); got != want {
+			t.Errorf("extractLinear(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestExtractGitHub(t *testing.T) {
+	for content, want := range map[string]string{
+		"# Plan\n**Status:** open\n**GitHub:** #42\n": "42",
+		"**GitHub:** 7":               "7",
+		"**GitHub:** #x":              "",
+		"# Plan\n**Linear:** ENG-1\n": "",
+	} {
+		if got := extractGitHub(content); got != want {
+			t.Errorf("extractGitHub(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestLoad_BranchHeader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "add-login.md")
+	os.WriteFile(path, []byte("# Plan: Add login\n**Branch:** ralph/2024-01-30/add-login\n**Status:** open\n"), 0644)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Branch != "ralph/2024-01-30/add-login" {
+		t.Errorf("Branch = %q, want the **Branch:** header", p.Branch)
+	}
+}
+
 func TestSanitizeBranchName(t *testing.T) {
 	tests := []struct {
 		name string
@@ -65,7 +65,7 @@ func AppendProgress(plan *Plan, iteration int, content string) error {
 	}
 
 	// Write file
-	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("writing progress file: %w", err)
 	}
 
@@ -99,14 +99,140 @@ func AppendProgressWithTime(plan *Plan, iteration int, content string, timestamp
 	}
 
 	// Write file
-	if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("writing progress file: %w", err)
 	}
 
 	return nil
 }
 
-// CreateProgressFile creates a new progress file with a header if it doesn't exist.
+// AppendAbandoned appends an abandoned section recording why the plan was
+// given up on. Creates the file if it doesn't exist.
+// Entry format:
+//
+//	## Abandoned (YYYY-MM-DD HH:MM)
+//	Reason: {reason}
+//	By: {by}
+func AppendAbandoned(plan *Plan, reason, by string, timestamp time.Time) error {
+	path := ProgressPath(plan)
+
+	existing, err := ReadProgress(plan)
+	if err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("\n## Abandoned (%s)\nReason: %s\n", timestamp.Format("2006-01-02 15:04"), reason)
+	if by != "" {
+		entry += fmt.Sprintf("By: %s\n", by)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating progress directory: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(existing+entry), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+
+	return nil
+}
+
+// AppendFailed appends a failed section recording why the plan did not
+// complete. Creates the file if it doesn't exist.
+// Entry format:
+//
+//	## Failed (YYYY-MM-DD HH:MM)
+//	Reason: {reason}
+func AppendFailed(plan *Plan, reason string, timestamp time.Time) error {
+	path := ProgressPath(plan)
+
+	existing, err := ReadProgress(plan)
+	if err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("\n## Failed (%s)\nReason: %s\n", timestamp.Format("2006-01-02 15:04"), reason)
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating progress directory: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(existing+entry), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+
+	return nil
+}
+
+// AppendVerification appends a verification section recording each
+// verifier's verdict on a completion claim. Creates the file if it doesn't exist.
+// Entry format:
+//
+//	## Verification (iteration N, YYYY-MM-DD HH:MM)
+//	- {verdict}
+func AppendVerification(plan *Plan, iteration int, verdicts []string, timestamp time.Time) error {
+	path := ProgressPath(plan)
+
+	existing, err := ReadProgress(plan)
+	if err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("\n## Verification (iteration %d, %s)\n", iteration, timestamp.Format("2006-01-02 15:04"))
+	for _, v := range verdicts {
+		entry += fmt.Sprintf("- %s\n", v)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating progress directory: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(existing+entry), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+
+	return nil
+}
+
+// AppendPlanUpdated appends a section noting that the plan file was edited
+// outside the run before an iteration, listing the changed sections if known.
+// Creates the file if it doesn't exist.
+// Entry format:
+//
+//	## Plan Updated Externally (iteration N, YYYY-MM-DD HH:MM)
+//	Changed sections: {section}, {section}
+func AppendPlanUpdated(plan *Plan, iteration int, sections []string, timestamp time.Time) error {
+	path := ProgressPath(plan)
+
+	existing, err := ReadProgress(plan)
+	if err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("\n## Plan Updated Externally (iteration %d, %s)\n", iteration, timestamp.Format("2006-01-02 15:04"))
+	if len(sections) > 0 {
+		entry += fmt.Sprintf("Changed sections: %s\n", strings.Join(sections, ", "))
+	} else {
+		entry += "The plan file was edited in the main worktree.\n"
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating progress directory: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(existing+entry), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+
+	return nil
+}
+
+// CreateProgressFile creates a new progress file with a header, marked as
+// ProgressVersion, if it doesn't exist.
 // If the file already exists, does nothing.
 func CreateProgressFile(plan *Plan) error {
 	path := ProgressPath(plan)
@@ -120,7 +246,7 @@ func CreateProgressFile(plan *Plan) error {
 	}
 
 	// Create with header
-	header := fmt.Sprintf("# Progress: %s\n\nIteration log - what was done, gotchas, and next steps.\n", plan.Name)
+	header := fmt.Sprintf("# Progress: %s\n%s\n\nIteration log - what was done, gotchas, and next steps.\n", plan.Name, progressMarker)
 
 	// Ensure parent directory exists
 	dir := filepath.Dir(path)
@@ -128,7 +254,7 @@ func CreateProgressFile(plan *Plan) error {
 		return fmt.Errorf("creating progress directory: %w", err)
 	}
 
-	if err := os.WriteFile(path, []byte(header), 0644); err != nil {
+	if err := WriteFileAtomic(path, []byte(header), 0644); err != nil {
 		return fmt.Errorf("creating progress file: %w", err)
 	}
 
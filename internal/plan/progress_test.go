@@ -292,3 +292,88 @@ func TestProgressPath_PreservesDirectory(t *testing.T) {
 		t.Errorf("Progress dir %q != plan dir %q", progressDir, planDir)
 	}
 }
+
+func TestAppendAbandoned(t *testing.T) {
+	tmpDir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(tmpDir, "test.md"), Name: "test"}
+	timestamp := time.Date(2026, 1, 31, 14, 30, 0, 0, time.UTC)
+
+	if err := AppendProgressWithTime(plan, 1, "Did the thing.\n", timestamp); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendAbandoned(plan, "superseded", "alice", timestamp.Add(time.Hour)); err != nil {
+		t.Fatalf("AppendAbandoned() error: %v", err)
+	}
+
+	content, err := ReadProgress(plan)
+	if err != nil {
+		t.Fatalf("ReadProgress() error: %v", err)
+	}
+
+	expected := "\n## Iteration 1 (2026-01-31 14:30)\nDid the thing.\n\n" +
+		"\n## Abandoned (2026-01-31 15:30)\nReason: superseded\nBy: alice\n"
+	if content != expected {
+		t.Errorf("ReadProgress() = %q, want %q", content, expected)
+	}
+}
+
+func TestAppendVerification(t *testing.T) {
+	tmpDir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(tmpDir, "test.md"), Name: "test"}
+	timestamp := time.Date(2026, 1, 31, 14, 30, 0, 0, time.UTC)
+
+	if err := AppendVerification(plan, 4, []string{"haiku: YES", "opus: NO (Task 2 is unchecked)"}, timestamp); err != nil {
+		t.Fatalf("AppendVerification() error: %v", err)
+	}
+
+	content, err := ReadProgress(plan)
+	if err != nil {
+		t.Fatalf("ReadProgress() error: %v", err)
+	}
+	expected := "\n## Verification (iteration 4, 2026-01-31 14:30)\n- haiku: YES\n- opus: NO (Task 2 is unchecked)\n"
+	if content != expected {
+		t.Errorf("ReadProgress() = %q, want %q", content, expected)
+	}
+}
+
+func TestAppendPlanUpdated(t *testing.T) {
+	tmpDir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(tmpDir, "test.md"), Name: "test"}
+	timestamp := time.Date(2026, 1, 31, 14, 30, 0, 0, time.UTC)
+
+	if err := AppendPlanUpdated(plan, 3, []string{"Tasks", "Acceptance Criteria"}, timestamp); err != nil {
+		t.Fatalf("AppendPlanUpdated() error: %v", err)
+	}
+	if err := AppendPlanUpdated(plan, 5, nil, timestamp); err != nil {
+		t.Fatalf("AppendPlanUpdated() error: %v", err)
+	}
+
+	content, err := ReadProgress(plan)
+	if err != nil {
+		t.Fatalf("ReadProgress() error: %v", err)
+	}
+	expected := "\n## Plan Updated Externally (iteration 3, 2026-01-31 14:30)\nChanged sections: Tasks, Acceptance Criteria\n" +
+		"\n## Plan Updated Externally (iteration 5, 2026-01-31 14:30)\nThe plan file was edited in the main worktree.\n"
+	if content != expected {
+		t.Errorf("ReadProgress() = %q, want %q", content, expected)
+	}
+}
+
+func TestAppendFailed(t *testing.T) {
+	tmpDir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(tmpDir, "test.md"), Name: "test"}
+	timestamp := time.Date(2026, 1, 31, 14, 30, 0, 0, time.UTC)
+
+	if err := AppendFailed(plan, "max iterations (30) reached without completion", timestamp); err != nil {
+		t.Fatalf("AppendFailed() error: %v", err)
+	}
+
+	content, err := ReadProgress(plan)
+	if err != nil {
+		t.Fatalf("ReadProgress() error: %v", err)
+	}
+	expected := "\n## Failed (2026-01-31 14:30)\nReason: max iterations (30) reached without completion\n"
+	if content != expected {
+		t.Errorf("ReadProgress() = %q, want %q", content, expected)
+	}
+}
@@ -0,0 +1,633 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProgressVersion is the current progress file format: each iteration entry
+// carries a fenced YAML block with its structured fields, followed by
+// free-form markdown notes.
+const ProgressVersion = 2
+
+// progressMarker marks a progress file as ProgressVersion. It is an HTML
+// comment so it doesn't show in rendered markdown.
+const progressMarker = "<!-- ralph-progress: v2 -->"
+
+// progressFence opens an entry's structured block.
+const progressFence = "```yaml progress"
+
+var (
+	// iterationHeadingRegex matches the heading of ralph's own entries,
+	// e.g. "## Iteration 3 (2026-01-31 14:30)".
+	iterationHeadingRegex = regexp.MustCompile(`^## Iteration (\d+) \((\d{4}-\d{2}-\d{2} \d{2}:\d{2})\)\s*$`)
+
+	// agentHeadingRegex matches the heading of the agent's entries,
+	// e.g. "### Iteration 3: T2 - Add the parser".
+	agentHeadingRegex = regexp.MustCompile(`^###\s+Iteration\s+(\d+)\b[\s:–-]*(.*)$`)
+
+	// durationLineRegex matches the duration line of legacy ralph entries.
+	durationLineRegex = regexp.MustCompile(`^Claude execution completed in (\S+?)\.?$`)
+
+	// labelRegex matches the bold labels of legacy agent entries, e.g.
+	// "**Gotcha:** ...".
+	labelRegex = regexp.MustCompile(`^\*\*(Completed|Gotchas?|Next):\*\*\s*(.*)$`)
+
+	// commitLineRegex matches the commit line of ralph's entries, e.g.
+	// "Commit: `abc1234`" or "Commit: [`abc1234`](https://...)".
+	commitLineRegex = regexp.MustCompile("^Commit: (?:`([0-9a-f]+)`|\\[`([0-9a-f]+)`\\]\\((\\S+)\\))$")
+)
+
+// ProgressEntry is one iteration of a progress file. Ralph and the agent
+// each write an entry per iteration; ParseProgress merges them.
+type ProgressEntry struct {
+	// Iteration is the iteration number.
+	Iteration int
+
+	// Title is the task the agent worked on, from its entry heading.
+	Title string
+
+	// Time is when ralph recorded the iteration (zero if only the agent did).
+	Time time.Time
+
+	// Completed is what the iteration did.
+	Completed string
+
+	// Gotchas are surprises and pitfalls worth remembering.
+	Gotchas []string
+
+	// Next is what the following iteration should tackle.
+	Next string
+
+	// Files are the files the iteration changed.
+	Files []string
+
+	// Duration is how long the agent ran.
+	Duration time.Duration
+
+	// Commit is the SHA of the iteration's commit, abbreviated in the file.
+	Commit string
+
+	// CommitURL is the commit's web page, once the branch was pushed.
+	CommitURL string
+
+	// Notes is the entry's free-form markdown.
+	Notes string
+}
+
+// Progress is a parsed progress file.
+type Progress struct {
+	// Version is ProgressVersion for files with the v2 marker, 1 otherwise.
+	Version int
+
+	// Entries are the iterations, by iteration number.
+	Entries []ProgressEntry
+}
+
+// Latest returns the last iteration entry, or nil if there is none.
+func (p *Progress) Latest() *ProgressEntry {
+	if len(p.Entries) == 0 {
+		return nil
+	}
+	return &p.Entries[len(p.Entries)-1]
+}
+
+// NextStep returns the most recent Next of any entry, or "".
+func (p *Progress) NextStep() string {
+	for i := len(p.Entries) - 1; i >= 0; i-- {
+		if p.Entries[i].Next != "" {
+			return p.Entries[i].Next
+		}
+	}
+	return ""
+}
+
+// Gotchas returns the gotchas of all entries, oldest first.
+func (p *Progress) Gotchas() []string {
+	var gotchas []string
+	for _, e := range p.Entries {
+		gotchas = append(gotchas, e.Gotchas...)
+	}
+	return gotchas
+}
+
+// LoadProgress reads and parses the progress file of a plan. A missing file
+// parses as no entries.
+func LoadProgress(plan *Plan) (*Progress, error) {
+	content, err := ReadProgress(plan)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProgress(content), nil
+}
+
+// ParseProgress parses progress file content in the current or the legacy
+// format. Entries for the same iteration (ralph's and the agent's) are
+// merged. Other sections, such as Abandoned or Verification, are skipped.
+func ParseProgress(content string) *Progress {
+	p := &Progress{Version: 1}
+	if strings.Contains(content, progressMarker) {
+		p.Version = ProgressVersion
+	}
+
+	_, sections := splitProgress(content)
+	index := make(map[int]int)
+	for _, s := range sections {
+		if s.iteration == 0 {
+			continue
+		}
+		e := s.entry()
+		if i, ok := index[e.Iteration]; ok {
+			p.Entries[i].merge(e)
+			continue
+		}
+		index[e.Iteration] = len(p.Entries)
+		p.Entries = append(p.Entries, e)
+	}
+	sort.SliceStable(p.Entries, func(i, j int) bool { return p.Entries[i].Iteration < p.Entries[j].Iteration })
+	return p
+}
+
+// MigrateProgress converts legacy progress file content to the current
+// format: each iteration entry without a structured block gets one built
+// from its labels and duration line, and the header gets the v2 marker.
+// Other sections and the entries' remaining text are kept as they are.
+// Content that is empty or already migrated is returned unchanged.
+func MigrateProgress(content string) string {
+	if content == "" || strings.Contains(content, progressMarker) {
+		return content
+	}
+
+	preamble, sections := splitProgress(content)
+	lines := markPreamble(preamble)
+	for _, s := range sections {
+		lines = append(lines, s.heading)
+		if s.iteration == 0 || s.hasBlock() {
+			lines = append(lines, s.lines...)
+			continue
+		}
+
+		e := s.entry()
+		if block := e.block(); block != "" {
+			lines = append(lines, strings.Split(strings.TrimSuffix(block, "\n"), "\n")...)
+		}
+		if e.Notes != "" {
+			lines = append(lines, strings.Split(e.Notes, "\n")...)
+		}
+		lines = append(lines, trailingSeparators(s.lines)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AppendIteration appends ralph's entry for an iteration in the current
+// format, migrating a legacy file first. A zero Time means now. Creates the
+// file if it doesn't exist.
+// Entry format:
+//
+//	## Iteration N (YYYY-MM-DD HH:MM)
+//	```yaml progress
+//	duration: 1m30s
+//	files:
+//	  - main.go
+//	```
+//	{notes}
+func AppendIteration(plan *Plan, entry ProgressEntry) error {
+	path := ProgressPath(plan)
+
+	existing, err := ReadProgress(plan)
+	if err != nil {
+		return err
+	}
+	if existing == "" {
+		existing = progressMarker + "\n"
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating progress directory: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte(MigrateProgress(existing)+entry.String()), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+
+	return nil
+}
+
+// SetIterationCommit records the commit of an iteration on ralph's entry
+// for it, linked to url if it isn't empty, replacing any commit recorded
+// before. Does nothing if the file has no entry for the iteration.
+func SetIterationCommit(plan *Plan, iteration int, commit, url string) error {
+	existing, err := ReadProgress(plan)
+	if err != nil || !strings.Contains(existing, progressMarker) {
+		return err
+	}
+
+	preamble, sections := splitProgress(existing)
+	lines := preamble
+	found := false
+	for _, s := range sections {
+		lines = append(lines, s.heading)
+		if s.iteration != iteration || !iterationHeadingRegex.MatchString(s.heading) {
+			lines = append(lines, s.lines...)
+			continue
+		}
+
+		found = true
+		e := s.entry()
+		e.Commit, e.CommitURL = commit, url
+		body := strings.TrimPrefix(e.String(), "\n"+s.heading+"\n")
+		lines = append(lines, strings.Split(strings.TrimSuffix(body, "\n"), "\n")...)
+		lines = append(lines, trailingSeparators(s.lines)...)
+	}
+	if !found {
+		return nil
+	}
+
+	if err := WriteFileAtomic(ProgressPath(plan), []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("writing progress file: %w", err)
+	}
+	return nil
+}
+
+// String renders the entry as ralph writes it, starting with a blank line.
+func (e ProgressEntry) String() string {
+	s := fmt.Sprintf("\n## Iteration %d (%s)\n", e.Iteration, e.Time.Format("2006-01-02 15:04"))
+	s += e.block()
+	if line := e.commitLine(); line != "" {
+		s += line + "\n"
+	}
+	if notes := strings.TrimSpace(e.Notes); notes != "" {
+		s += notes + "\n"
+	}
+	return s
+}
+
+// commitLine renders the entry's commit line, or "" if it has no commit.
+func (e ProgressEntry) commitLine() string {
+	sha := e.Commit
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	switch {
+	case sha == "":
+		return ""
+	case e.CommitURL != "":
+		return fmt.Sprintf("Commit: [`%s`](%s)", sha, e.CommitURL)
+	default:
+		return fmt.Sprintf("Commit: `%s`", sha)
+	}
+}
+
+// progressFields is the YAML of an entry's structured block.
+type progressFields struct {
+	Completed text       `yaml:"completed,omitempty"`
+	Gotchas   stringList `yaml:"gotchas,omitempty"`
+	Next      text       `yaml:"next,omitempty"`
+	Files     stringList `yaml:"files,omitempty"`
+	Duration  string     `yaml:"duration,omitempty"`
+}
+
+// block renders the entry's structured fields as a fenced YAML block, or ""
+// if there are none.
+func (e ProgressEntry) block() string {
+	fields := progressFields{
+		Completed: text(e.Completed),
+		Gotchas:   e.Gotchas,
+		Next:      text(e.Next),
+		Files:     e.Files,
+	}
+	if e.Duration > 0 {
+		fields.Duration = e.Duration.Round(time.Second).String()
+	}
+	if isEmptyFields(fields) {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(fields); err != nil {
+		return ""
+	}
+	return progressFence + "\n" + buf.String() + "```\n"
+}
+
+// isEmptyFields reports whether fields has nothing to render.
+func isEmptyFields(f progressFields) bool {
+	return f.Completed == "" && len(f.Gotchas) == 0 && f.Next == "" && len(f.Files) == 0 && f.Duration == ""
+}
+
+// merge adds another entry for the same iteration. Text is joined, lists
+// are combined, and o's Next and Duration win when set.
+func (e *ProgressEntry) merge(o ProgressEntry) {
+	if e.Title == "" {
+		e.Title = o.Title
+	}
+	if e.Time.IsZero() {
+		e.Time = o.Time
+	}
+	e.Completed = joinText(e.Completed, o.Completed)
+	e.Gotchas = append(e.Gotchas, o.Gotchas...)
+	if o.Next != "" {
+		e.Next = o.Next
+	}
+	for _, f := range o.Files {
+		if !containsString(e.Files, f) {
+			e.Files = append(e.Files, f)
+		}
+	}
+	if o.Duration > 0 {
+		e.Duration = o.Duration
+	}
+	if o.Commit != "" {
+		e.Commit, e.CommitURL = o.Commit, o.CommitURL
+	}
+	e.Notes = joinText(e.Notes, o.Notes)
+}
+
+// progressSection is a heading and the lines up to the next one.
+type progressSection struct {
+	heading string
+	lines   []string
+
+	// iteration is the entry's iteration number, or 0 for other sections.
+	iteration int
+	title     string
+	time      time.Time
+}
+
+// splitProgress splits progress content into the lines before the first
+// section heading and the sections. Headings inside code blocks don't count.
+func splitProgress(content string) ([]string, []progressSection) {
+	var preamble []string
+	var sections []progressSection
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if s, ok := parseHeading(line); ok && !inCode {
+			sections = append(sections, s)
+			continue
+		}
+		if len(sections) == 0 {
+			preamble = append(preamble, line)
+		} else {
+			last := &sections[len(sections)-1]
+			last.lines = append(last.lines, line)
+		}
+	}
+	return preamble, sections
+}
+
+// parseHeading returns the section a line starts, if it is a section heading.
+func parseHeading(line string) (progressSection, bool) {
+	if m := iterationHeadingRegex.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		t, _ := time.ParseInLocation("2006-01-02 15:04", m[2], time.Local)
+		return progressSection{heading: line, iteration: n, time: t}, true
+	}
+	if m := agentHeadingRegex.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return progressSection{heading: line, iteration: n, title: strings.TrimSpace(m[2])}, true
+	}
+	if strings.HasPrefix(line, "## ") {
+		return progressSection{heading: line}, true
+	}
+	return progressSection{}, false
+}
+
+// hasBlock reports whether the section has a structured block.
+func (s progressSection) hasBlock() bool {
+	for _, line := range s.lines {
+		if strings.TrimSpace(line) == progressFence {
+			return true
+		}
+	}
+	return false
+}
+
+// entry parses an iteration section: the structured block if there is one,
+// the legacy labels and duration line otherwise. Everything else is notes.
+func (s progressSection) entry() ProgressEntry {
+	e := ProgressEntry{Iteration: s.iteration, Title: s.title, Time: s.time}
+	structured := s.hasBlock()
+
+	var notes, block []string
+	label := ""
+	inBlock, inCode := false, false
+	for _, line := range s.lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			if trimmed == "```" {
+				inBlock = false
+				if !e.applyBlock(strings.Join(block, "\n")) {
+					notes = append(notes, progressFence)
+					notes = append(notes, block...)
+					notes = append(notes, "```")
+				}
+			} else {
+				block = append(block, line)
+			}
+			continue
+		case trimmed == progressFence:
+			inBlock = true
+			continue
+		case strings.HasPrefix(trimmed, "```"):
+			inCode = !inCode
+		case inCode:
+		case trimmed == "---":
+			label = ""
+			continue
+		case commitLineRegex.MatchString(trimmed) && e.Commit == "":
+			m := commitLineRegex.FindStringSubmatch(trimmed)
+			e.Commit, e.CommitURL = m[1]+m[2], m[3]
+			continue
+		case !structured:
+			if m := labelRegex.FindStringSubmatch(trimmed); m != nil {
+				label = m[1]
+				e.addLabeled(label, m[2])
+				continue
+			}
+			if label != "" {
+				if trimmed == "" {
+					label = ""
+				} else {
+					e.addLabeled(label, line)
+				}
+				continue
+			}
+			if m := durationLineRegex.FindStringSubmatch(trimmed); m != nil && e.Duration == 0 {
+				e.Duration, _ = time.ParseDuration(m[1])
+			}
+		}
+		notes = append(notes, line)
+	}
+	e.Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+	return e
+}
+
+// applyBlock sets the entry's fields from a structured block. Returns false
+// if the block isn't valid YAML.
+func (e *ProgressEntry) applyBlock(data string) bool {
+	var fields progressFields
+	if err := yaml.Unmarshal([]byte(data), &fields); err != nil {
+		return false
+	}
+	e.Completed = string(fields.Completed)
+	e.Next = string(fields.Next)
+	for _, g := range fields.Gotchas {
+		if !isNone(g) {
+			e.Gotchas = append(e.Gotchas, g)
+		}
+	}
+	e.Files = fields.Files
+	if d, err := time.ParseDuration(fields.Duration); err == nil {
+		e.Duration = d
+	}
+	return true
+}
+
+// addLabeled adds a line of a legacy labeled field. Each gotcha line (or
+// list item) is a separate gotcha; "None" gotchas are dropped.
+func (e *ProgressEntry) addLabeled(label, line string) {
+	line = strings.TrimRight(line, " ")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	switch label {
+	case "Completed":
+		e.Completed = joinLines(e.Completed, line)
+	case "Next":
+		e.Next = joinLines(e.Next, line)
+	default:
+		g := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		if !isNone(g) {
+			e.Gotchas = append(e.Gotchas, g)
+		}
+	}
+}
+
+// markPreamble adds the v2 marker after the preamble's title, or at the top
+// if there is none.
+func markPreamble(preamble []string) []string {
+	if len(preamble) > 0 && strings.HasPrefix(preamble[0], "# ") {
+		return append([]string{preamble[0], progressMarker}, preamble[1:]...)
+	}
+	return append([]string{progressMarker}, preamble...)
+}
+
+// trailingSeparators returns the blank and "---" lines at the end of lines.
+func trailingSeparators(lines []string) []string {
+	i := len(lines)
+	for i > 0 {
+		if t := strings.TrimSpace(lines[i-1]); t != "" && t != "---" {
+			break
+		}
+		i--
+	}
+	return lines[i:]
+}
+
+// isNone reports whether a gotcha says there was none, e.g. "None - all
+// straightforward".
+func isNone(s string) bool {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if !strings.HasPrefix(lower, "none") {
+		return false
+	}
+	rest := lower[len("none"):]
+	return rest == "" || !(rest[0] >= 'a' && rest[0] <= 'z')
+}
+
+// joinText joins two blocks of text with a blank line.
+func joinText(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n\n" + b
+	}
+}
+
+// joinLines joins two lines of text.
+func joinLines(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// text is a YAML string that may also be written as a list, which is joined
+// into markdown list items.
+type text string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *text) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		for i, item := range items {
+			items[i] = "- " + item
+		}
+		*t = text(strings.Join(items, "\n"))
+		return nil
+	}
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*t = text(strings.TrimSpace(s))
+	return nil
+}
+
+// stringList is a YAML list that may also be written as a single string.
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var s string
+		if err := node.Decode(&s); err != nil {
+			return err
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			*l = stringList{s}
+		}
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = items
+	return nil
+}
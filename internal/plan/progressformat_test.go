@@ -0,0 +1,286 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// legacyProgress is a progress file written before the v2 format.
+const legacyProgress = `# Progress: Feature
+
+Iteration log - what was done, gotchas, and next steps.
+
+---
+### Iteration 1: T1 - Add the parser
+**Completed:**
+- Created parser.go
+- Added tests
+
+**Gotcha:** None - straightforward.
+
+**Next:** T2 - Wire up the CLI
+
+## Iteration 1 (2026-01-31 10:00)
+Claude execution completed in 1m30.5s.
+Tools: 2 edits across 1 file.
+
+---
+### Iteration 2: T2 - Wire up the CLI
+**Completed:** Added the command
+**Gotcha:** Cobra caches flags between tests
+
+**Next:** Plan complete
+
+## Iteration 2 (2026-01-31 11:00)
+Claude execution completed in 45s.
+Completion marker detected.
+
+## Verification (iteration 2, 2026-01-31 11:01)
+- approved
+`
+
+func TestParseProgress_Legacy(t *testing.T) {
+	p := ParseProgress(legacyProgress)
+	if p.Version != 1 {
+		t.Errorf("Version = %d, want 1", p.Version)
+	}
+	if len(p.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(p.Entries), p.Entries)
+	}
+
+	e := p.Entries[0]
+	if e.Iteration != 1 || e.Title != "T1 - Add the parser" {
+		t.Errorf("entry 1 = %d %q", e.Iteration, e.Title)
+	}
+	if e.Completed != "- Created parser.go\n- Added tests" {
+		t.Errorf("Completed = %q", e.Completed)
+	}
+	if len(e.Gotchas) != 0 {
+		t.Errorf("Gotchas = %v, want none", e.Gotchas)
+	}
+	if e.Next != "T2 - Wire up the CLI" {
+		t.Errorf("Next = %q", e.Next)
+	}
+	if e.Duration != 90500*time.Millisecond {
+		t.Errorf("Duration = %v", e.Duration)
+	}
+	if e.Time.Format("2006-01-02 15:04") != "2026-01-31 10:00" {
+		t.Errorf("Time = %v", e.Time)
+	}
+	if !strings.Contains(e.Notes, "Tools: 2 edits across 1 file.") || strings.Contains(e.Notes, "---") {
+		t.Errorf("Notes = %q", e.Notes)
+	}
+
+	if got := p.Gotchas(); !reflect.DeepEqual(got, []string{"Cobra caches flags between tests"}) {
+		t.Errorf("Gotchas() = %v", got)
+	}
+	if got := p.NextStep(); got != "Plan complete" {
+		t.Errorf("NextStep() = %q", got)
+	}
+	if got := p.Latest(); got == nil || got.Iteration != 2 || got.Duration != 45*time.Second {
+		t.Errorf("Latest() = %+v", got)
+	}
+}
+
+func TestParseProgress_V2(t *testing.T) {
+	content := progressMarker + "\n" + "---\n### Iteration 3: T3 - Docs\n" +
+		"```yaml progress\ncompleted: Wrote the README section\ngotchas: Links must be relative\nnext:\n  - Review\n  - Release\n```\nSee the PR for screenshots.\n" +
+		"\n## Iteration 3 (2026-01-31 12:00)\n```yaml progress\nduration: 2m0s\nfiles:\n  - README.md\n```\nClaude execution completed in 2m0s.\n"
+
+	p := ParseProgress(content)
+	if p.Version != ProgressVersion {
+		t.Errorf("Version = %d, want %d", p.Version, ProgressVersion)
+	}
+	if len(p.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(p.Entries))
+	}
+	e := p.Entries[0]
+	if e.Title != "T3 - Docs" || e.Completed != "Wrote the README section" {
+		t.Errorf("entry = %+v", e)
+	}
+	if !reflect.DeepEqual(e.Gotchas, []string{"Links must be relative"}) {
+		t.Errorf("Gotchas = %v", e.Gotchas)
+	}
+	if e.Next != "- Review\n- Release" {
+		t.Errorf("Next = %q", e.Next)
+	}
+	if !reflect.DeepEqual(e.Files, []string{"README.md"}) || e.Duration != 2*time.Minute {
+		t.Errorf("Files = %v, Duration = %v", e.Files, e.Duration)
+	}
+	if !strings.Contains(e.Notes, "See the PR for screenshots.") {
+		t.Errorf("Notes = %q", e.Notes)
+	}
+}
+
+func TestParseProgress_InvalidBlock(t *testing.T) {
+	content := "## Iteration 1 (2026-01-31 12:00)\n```yaml progress\nfiles: [unclosed\n```\n"
+	p := ParseProgress(content)
+	if len(p.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(p.Entries))
+	}
+	if !strings.Contains(p.Entries[0].Notes, "files: [unclosed") {
+		t.Errorf("invalid block should be kept as notes, got %q", p.Entries[0].Notes)
+	}
+}
+
+func TestParseProgress_Empty(t *testing.T) {
+	p := ParseProgress("")
+	if len(p.Entries) != 0 || p.Latest() != nil || p.NextStep() != "" {
+		t.Errorf("ParseProgress(\"\") = %+v", p)
+	}
+}
+
+func TestMigrateProgress(t *testing.T) {
+	migrated := MigrateProgress(legacyProgress)
+
+	if !strings.HasPrefix(migrated, "# Progress: Feature\n"+progressMarker+"\n") {
+		t.Errorf("marker not after the title:\n%s", migrated)
+	}
+	for _, want := range []string{
+		"### Iteration 1: T1 - Add the parser\n```yaml progress\ncompleted: |-\n  - Created parser.go\n  - Added tests\nnext: T2 - Wire up the CLI\n```\n",
+		"## Iteration 1 (2026-01-31 10:00)\n```yaml progress\nduration: 1m31s\n```\nClaude execution completed in 1m30.5s.\n",
+		"gotchas:\n  - Cobra caches flags between tests\n",
+		"\n---\n### Iteration 2",
+		"## Verification (iteration 2, 2026-01-31 11:01)\n- approved\n",
+	} {
+		if !strings.Contains(migrated, want) {
+			t.Errorf("migrated file missing %q:\n%s", want, migrated)
+		}
+	}
+	if strings.Contains(migrated, "**Completed:**") {
+		t.Errorf("labels should move into the blocks:\n%s", migrated)
+	}
+
+	// The migrated file parses to the same entries
+	before, after := ParseProgress(legacyProgress), ParseProgress(migrated)
+	if after.Version != ProgressVersion {
+		t.Errorf("migrated Version = %d", after.Version)
+	}
+	for i := range before.Entries {
+		b, a := before.Entries[i], after.Entries[i]
+		if a.Completed != b.Completed || a.Next != b.Next || !reflect.DeepEqual(a.Gotchas, b.Gotchas) || a.Duration.Round(time.Second) != b.Duration.Round(time.Second) {
+			t.Errorf("entry %d changed by migration:\nbefore %+v\nafter  %+v", i, b, a)
+		}
+	}
+
+	if again := MigrateProgress(migrated); again != migrated {
+		t.Errorf("MigrateProgress should be idempotent")
+	}
+	if got := MigrateProgress(""); got != "" {
+		t.Errorf("MigrateProgress(\"\") = %q", got)
+	}
+}
+
+func TestAppendIteration(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "feature.md")
+	os.WriteFile(planPath, []byte("# Plan"), 0644)
+	plan := &Plan{Path: planPath, Name: "feature"}
+
+	// A legacy file is migrated before appending
+	os.WriteFile(ProgressPath(plan), []byte(legacyProgress), 0644)
+	entry := ProgressEntry{
+		Iteration: 3,
+		Time:      time.Date(2026, 1, 31, 12, 0, 0, 0, time.Local),
+		Files:     []string{"main.go"},
+		Duration:  95 * time.Second,
+		Notes:     "Claude execution completed in 1m35s.\n",
+	}
+	if err := AppendIteration(plan, entry); err != nil {
+		t.Fatalf("AppendIteration() error = %v", err)
+	}
+
+	content, _ := ReadProgress(plan)
+	if !strings.Contains(content, progressMarker) {
+		t.Error("file was not migrated")
+	}
+	want := "\n## Iteration 3 (2026-01-31 12:00)\n```yaml progress\nfiles:\n  - main.go\nduration: 1m35s\n```\nClaude execution completed in 1m35s.\n"
+	if !strings.HasSuffix(content, want) {
+		t.Errorf("appended entry = %q, want suffix %q", content, want)
+	}
+
+	p := ParseProgress(content)
+	if latest := p.Latest(); latest == nil || latest.Iteration != 3 || latest.Duration != 95*time.Second {
+		t.Errorf("Latest() = %+v", latest)
+	}
+
+	// A new file starts with the marker
+	fresh := &Plan{Path: filepath.Join(dir, "fresh.md"), Name: "fresh"}
+	if err := AppendIteration(fresh, ProgressEntry{Iteration: 1, Notes: "First."}); err != nil {
+		t.Fatalf("AppendIteration() error = %v", err)
+	}
+	if content, _ := ReadProgress(fresh); !strings.HasPrefix(content, progressMarker+"\n\n## Iteration 1 (") {
+		t.Errorf("new file = %q", content)
+	}
+}
+
+func TestSetIterationCommit(t *testing.T) {
+	dir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(dir, "feature.md"), Name: "feature"}
+	at := time.Date(2026, 1, 31, 12, 0, 0, 0, time.Local)
+	AppendIteration(plan, ProgressEntry{Iteration: 1, Time: at, Duration: time.Minute, Notes: "First."})
+	agent := "\n### Iteration 2: T2 - Parser\n**Completed:** Parser\n"
+	os.WriteFile(ProgressPath(plan), []byte(mustReadProgress(t, plan)+agent), 0644)
+	AppendIteration(plan, ProgressEntry{Iteration: 2, Time: at, Notes: "Second."})
+
+	if err := SetIterationCommit(plan, 2, "0123456789abcdef", ""); err != nil {
+		t.Fatalf("SetIterationCommit() error = %v", err)
+	}
+	content := mustReadProgress(t, plan)
+	if !strings.HasSuffix(content, "## Iteration 2 (2026-01-31 12:00)\nCommit: `0123456`\nSecond.\n") {
+		t.Errorf("entry with commit = %q", content)
+	}
+
+	// Pushing links the commit, replacing the plain SHA
+	url := "https://github.com/acme/app/commit/0123456789abcdef"
+	if err := SetIterationCommit(plan, 2, "0123456789abcdef", url); err != nil {
+		t.Fatalf("SetIterationCommit() error = %v", err)
+	}
+	content = mustReadProgress(t, plan)
+	if !strings.HasSuffix(content, "Commit: [`0123456`]("+url+")\nSecond.\n") || strings.Count(content, "Commit:") != 1 {
+		t.Errorf("entry with commit link = %q", content)
+	}
+	if !strings.Contains(content, agent) || !strings.Contains(content, "duration: 1m0s\n```\nFirst.\n") {
+		t.Errorf("other entries changed: %q", content)
+	}
+
+	p := ParseProgress(content)
+	if e := p.Latest(); e.Commit != "0123456" || e.CommitURL != url || e.Notes != "Second." || e.Completed != "Parser" {
+		t.Errorf("Latest() = %+v", e)
+	}
+
+	// Iterations without an entry are left alone
+	if err := SetIterationCommit(plan, 5, "abc", ""); err != nil || mustReadProgress(t, plan) != content {
+		t.Errorf("SetIterationCommit(missing) = %v", err)
+	}
+}
+
+// mustReadProgress reads the plan's progress file.
+func mustReadProgress(t *testing.T, plan *Plan) string {
+	t.Helper()
+	content, err := ReadProgress(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestLoadProgress(t *testing.T) {
+	dir := t.TempDir()
+	plan := &Plan{Path: filepath.Join(dir, "feature.md"), Name: "feature"}
+
+	p, err := LoadProgress(plan)
+	if err != nil || len(p.Entries) != 0 {
+		t.Fatalf("LoadProgress() without a file = %+v, %v", p, err)
+	}
+
+	os.WriteFile(ProgressPath(plan), []byte(legacyProgress), 0644)
+	p, err = LoadProgress(plan)
+	if err != nil || len(p.Entries) != 2 {
+		t.Errorf("LoadProgress() = %+v, %v", p, err)
+	}
+}
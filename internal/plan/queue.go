@@ -8,13 +8,43 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
 )
 
 // Queue manages the plan queue lifecycle: pending → current → complete.
+// Plans that are given up on are moved to abandoned/ instead of complete/,
+// and plans that run out of iterations are moved to failed/.
+//
+// Plan moves take an advisory lock (a .lock file) on the source and
+// destination directories, so a worker, CLI commands, and the Slack bot
+// can operate on the same queue without racing.
 type Queue struct {
 	// BaseDir is the base directory containing the queue subdirectories.
 	// Typically "plans/" containing pending/, current/, complete/ subdirectories.
 	BaseDir string
+
+	// Events is the worker events log used to estimate the current plan's ETA (optional).
+	Events *events.Log
+
+	// Audit records every plan move (optional).
+	Audit *audit.Log
+
+	// Index mirrors the queue in a state database (optional): plan moves
+	// are recorded there, and StatusFor lists finished plans from it.
+	Index Index
+
+	// LockTimeout is how long plan moves wait for a queue directory locked by
+	// another process (default: DefaultLockTimeout).
+	LockTimeout time.Duration
+
+	// Live returns the copy of the current plan the agent is updating, such
+	// as the one in its execution worktree (optional). plans/current is only
+	// synced when a run ends, so CurrentLive reads task state from Live.
+	Live func(p *Plan) *Plan
 }
 
 // QueueStatus contains counts for each queue state.
@@ -28,11 +58,35 @@ type QueueStatus struct {
 	// CompleteCount is the number of plans that have been completed.
 	CompleteCount int
 
+	// AbandonedCount is the number of plans that have been abandoned.
+	AbandonedCount int
+
+	// FailedCount is the number of plans that failed to complete.
+	FailedCount int
+
+	// FailedPlans contains the names of failed plans.
+	FailedPlans []string
+
 	// PendingPlans contains the names of pending plans.
 	PendingPlans []string
 
+	// SnoozedUntil maps snoozed pending plans to their wake times.
+	SnoozedUntil map[string]time.Time
+
 	// CurrentPlan is the name of the current plan, if any.
 	CurrentPlan string
+
+	// CurrentBranch is the current plan's branch, if any.
+	CurrentBranch string
+
+	// CurrentDone and CurrentTotal are the current plan's task counts, the
+	// same as CurrentETA's when there is one, so the two never disagree.
+	CurrentDone  int
+	CurrentTotal int
+
+	// CurrentETA is the estimated time remaining for the current plan.
+	// Nil if there is no current plan, no events log, or not enough history.
+	CurrentETA *ETA
 }
 
 var (
@@ -47,6 +101,12 @@ var (
 
 	// ErrPlanNotInCurrent is returned when trying to complete a plan that's not in current/.
 	ErrPlanNotInCurrent = errors.New("plan is not in current directory")
+
+	// ErrPlanNotFound is returned when a plan cannot be found in any queue directory.
+	ErrPlanNotFound = errors.New("plan not found")
+
+	// ErrPlanNotRetryable is returned when retrying a plan that is not in failed/ or abandoned/.
+	ErrPlanNotRetryable = errors.New("plan is not in failed or abandoned directory")
 )
 
 // NewQueue creates a new Queue with the given base directory.
@@ -59,6 +119,11 @@ func (q *Queue) pendingDir() string {
 	return filepath.Join(q.BaseDir, "pending")
 }
 
+// PendingDir returns the path to the pending/ directory where new plans are added.
+func (q *Queue) PendingDir() string {
+	return q.pendingDir()
+}
+
 // currentDir returns the path to the current/ directory.
 func (q *Queue) currentDir() string {
 	return filepath.Join(q.BaseDir, "current")
@@ -69,6 +134,16 @@ func (q *Queue) completeDir() string {
 	return filepath.Join(q.BaseDir, "complete")
 }
 
+// abandonedDir returns the path to the abandoned/ directory.
+func (q *Queue) abandonedDir() string {
+	return filepath.Join(q.BaseDir, "abandoned")
+}
+
+// failedDir returns the path to the failed/ directory.
+func (q *Queue) failedDir() string {
+	return filepath.Join(q.BaseDir, "failed")
+}
+
 // resolvePath resolves a path to its absolute form with symlinks evaluated.
 // Returns the original path on error for graceful degradation.
 func resolvePath(path string) string {
@@ -89,6 +164,11 @@ func (q *Queue) Pending() ([]*Plan, error) {
 	return q.listPlans(q.pendingDir())
 }
 
+// Completed returns all plans in the complete/ directory, sorted by name.
+func (q *Queue) Completed() ([]*Plan, error) {
+	return q.listPlans(q.completeDir())
+}
+
 // Current returns the plan in current/, or nil if empty.
 // Returns an error if there are multiple plans in current/ (shouldn't happen).
 func (q *Queue) Current() (*Plan, error) {
@@ -108,10 +188,27 @@ func (q *Queue) Current() (*Plan, error) {
 	return plans[0], nil
 }
 
+// CurrentLive returns the current plan as the agent is updating it: Live's
+// copy if Live is set, otherwise the plan in current/. Returns nil, nil if
+// there is no current plan.
+func (q *Queue) CurrentLive() (*Plan, error) {
+	current, err := q.Current()
+	if err != nil || current == nil || q.Live == nil {
+		return current, err
+	}
+	return q.Live(current), nil
+}
+
 // Activate moves a plan from pending/ to current/.
 // Returns ErrQueueFull if current/ already has a plan.
 // Returns ErrPlanNotInPending if the plan is not in pending/.
 func (q *Queue) Activate(plan *Plan) error {
+	l, err := q.lock(q.pendingDir(), q.currentDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
 	// Check if current/ is empty
 	current, err := q.Current()
 	if err != nil {
@@ -127,8 +224,17 @@ func (q *Queue) Activate(plan *Plan) error {
 	if planDir != pendingDir {
 		return ErrPlanNotInPending
 	}
+	// Another process may have moved it since it was listed
+	if _, err := os.Stat(plan.Path); os.IsNotExist(err) {
+		return ErrPlanNotInPending
+	}
 
-	// Move to current/
+	// Move to current/, taking the plan's instructions and changes ledger along
+	for _, path := range []string{InstructionsPath(plan), ChangesPath(plan)} {
+		if err := moveSidecar(path, q.currentDir(), "current"); err != nil {
+			return err
+		}
+	}
 	newPath := filepath.Join(q.currentDir(), filepath.Base(plan.Path))
 	if err := os.Rename(plan.Path, newPath); err != nil {
 		return fmt.Errorf("moving plan to current: %w", err)
@@ -136,6 +242,7 @@ func (q *Queue) Activate(plan *Plan) error {
 
 	// Update plan's path
 	plan.Path = newPath
+	q.recordMove(plan, "pending", "current")
 
 	return nil
 }
@@ -143,14 +250,28 @@ func (q *Queue) Activate(plan *Plan) error {
 // Complete moves a plan from current/ to complete/.
 // Returns ErrPlanNotInCurrent if the plan is not in current/.
 func (q *Queue) Complete(plan *Plan) error {
+	l, err := q.lock(q.currentDir(), q.completeDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
 	// Verify plan is in current/
 	planDir := resolvePath(filepath.Dir(plan.Path))
 	currentDir := resolvePath(q.currentDir())
 	if planDir != currentDir {
 		return ErrPlanNotInCurrent
 	}
+	if err := checkNotMoved(plan, ErrPlanNotInCurrent); err != nil {
+		return err
+	}
 
-	// Move to complete/
+	// Move to complete/, taking the plan's instructions and changes ledger along
+	for _, path := range []string{InstructionsPath(plan), ChangesPath(plan)} {
+		if err := moveSidecar(path, q.completeDir(), "complete"); err != nil {
+			return err
+		}
+	}
 	newPath := filepath.Join(q.completeDir(), filepath.Base(plan.Path))
 	if err := os.Rename(plan.Path, newPath); err != nil {
 		return fmt.Errorf("moving plan to complete: %w", err)
@@ -158,6 +279,7 @@ func (q *Queue) Complete(plan *Plan) error {
 
 	// Update plan's path
 	plan.Path = newPath
+	q.recordMove(plan, "current", "complete")
 
 	return nil
 }
@@ -165,14 +287,28 @@ func (q *Queue) Complete(plan *Plan) error {
 // Reset moves a plan from current/ back to pending/.
 // Returns ErrPlanNotInCurrent if the plan is not in current/.
 func (q *Queue) Reset(plan *Plan) error {
+	l, err := q.lock(q.currentDir(), q.pendingDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
 	// Verify plan is in current/
 	planDir := resolvePath(filepath.Dir(plan.Path))
 	currentDir := resolvePath(q.currentDir())
 	if planDir != currentDir {
 		return ErrPlanNotInCurrent
 	}
+	if err := checkNotMoved(plan, ErrPlanNotInCurrent); err != nil {
+		return err
+	}
 
-	// Move to pending/
+	// Move to pending/, taking the plan's instructions and changes ledger along
+	for _, path := range []string{InstructionsPath(plan), ChangesPath(plan)} {
+		if err := moveSidecar(path, q.pendingDir(), "pending"); err != nil {
+			return err
+		}
+	}
 	newPath := filepath.Join(q.pendingDir(), filepath.Base(plan.Path))
 	if err := os.Rename(plan.Path, newPath); err != nil {
 		return fmt.Errorf("moving plan to pending: %w", err)
@@ -180,12 +316,162 @@ func (q *Queue) Reset(plan *Plan) error {
 
 	// Update plan's path
 	plan.Path = newPath
+	q.recordMove(plan, "current", "pending")
 
 	return nil
 }
 
+// Abandon moves a plan from pending/ or current/ to abandoned/, together with
+// its progress and feedback files. Creates abandoned/ if needed.
+// Returns ErrPlanNotFound if the plan is in neither directory.
+func (q *Queue) Abandon(plan *Plan) error {
+	l, err := q.lock(filepath.Dir(plan.Path), q.abandonedDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
+	planDir := resolvePath(filepath.Dir(plan.Path))
+	if planDir != resolvePath(q.pendingDir()) && planDir != resolvePath(q.currentDir()) {
+		return fmt.Errorf("%w in pending or current: %s", ErrPlanNotFound, plan.Name)
+	}
+	return q.moveWithSidecars(plan, q.abandonedDir(), "abandoned")
+}
+
+// Fail moves a plan from current/ to failed/, together with its progress and
+// feedback files, after appending reason to the progress file.
+// Creates failed/ if needed. Returns ErrPlanNotInCurrent if the plan is not in current/.
+func (q *Queue) Fail(plan *Plan, reason string) error {
+	l, err := q.lock(q.currentDir(), q.failedDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
+	if resolvePath(filepath.Dir(plan.Path)) != resolvePath(q.currentDir()) {
+		return ErrPlanNotInCurrent
+	}
+	if err := checkNotMoved(plan, ErrPlanNotInCurrent); err != nil {
+		return err
+	}
+	if err := AppendFailed(plan, reason, time.Now()); err != nil {
+		return fmt.Errorf("recording failure reason: %w", err)
+	}
+	return q.moveWithSidecars(plan, q.failedDir(), "failed")
+}
+
+// Retry moves a plan from failed/ or abandoned/ back to pending/, together
+// with its progress and feedback files.
+// Returns ErrPlanNotRetryable if the plan is in neither directory.
+func (q *Queue) Retry(plan *Plan) error {
+	l, err := q.lock(filepath.Dir(plan.Path), q.pendingDir())
+	if err != nil {
+		return err
+	}
+	defer l.unlock()
+
+	planDir := resolvePath(filepath.Dir(plan.Path))
+	if planDir != resolvePath(q.failedDir()) && planDir != resolvePath(q.abandonedDir()) {
+		return fmt.Errorf("%w: %s", ErrPlanNotRetryable, plan.Name)
+	}
+	return q.moveWithSidecars(plan, q.pendingDir(), "pending")
+}
+
+// moveWithSidecars moves a plan and its progress, feedback, instructions, changes, and summary files into dir,
+// creating it if needed, and updates the plan's path.
+// The caller must hold the locks of the plan's directory and dir.
+func (q *Queue) moveWithSidecars(plan *Plan, dir, label string) error {
+	if err := checkNotMoved(plan, ErrPlanNotFound); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating %s directory: %w", label, err)
+	}
+
+	// Move sidecar files first so they follow the plan's new path
+	for _, path := range []string{ProgressPath(plan), FeedbackPath(plan), InstructionsPath(plan), ChangesPath(plan), SummaryPath(plan)} {
+		if err := moveSidecar(path, dir, label); err != nil {
+			return err
+		}
+	}
+
+	from := filepath.Base(filepath.Dir(plan.Path))
+	newPath := filepath.Join(dir, filepath.Base(plan.Path))
+	if err := os.Rename(plan.Path, newPath); err != nil {
+		return fmt.Errorf("moving plan to %s: %w", label, err)
+	}
+
+	// Update plan's path
+	plan.Path = newPath
+	q.recordMove(plan, from, label)
+
+	return nil
+}
+
+// checkNotMoved returns notThere, naming the plan, if its file is gone:
+// another process moved it after it was loaded and before the caller took
+// the queue locks. Checked before anything moves, so no sidecars are left
+// behind.
+func checkNotMoved(plan *Plan, notThere error) error {
+	if _, err := os.Stat(plan.Path); os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s was moved by another process", notThere, plan.Name)
+	}
+	return nil
+}
+
+// recordMove records a plan move in the audit log and the index, if
+// configured. Failures are logged; the move has already happened.
+func (q *Queue) recordMove(plan *Plan, from, to string) {
+	if err := q.Audit.QueueMove(plan.Name, from, to); err != nil {
+		log.Warn("Failed to record audit entry: %v", err)
+	}
+	if q.Index != nil {
+		if err := q.Index.PlanMoved(plan, to); err != nil {
+			log.Warn("Failed to record plan move in the state database: %v", err)
+		}
+	}
+}
+
+// moveSidecar moves an optional file next to a plan into dir.
+func moveSidecar(path, dir, label string) error {
+	dest := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("moving %s to %s: %w", filepath.Base(path), label, err)
+	}
+	return nil
+}
+
+// Find looks up a plan in current/, pending/, complete/, then failed/.
+// name may be a plan name ("my-feature"), a file name ("my-feature.md"),
+// or a path to an existing plan file.
+// Returns ErrPlanNotFound if no plan matches.
+func (q *Queue) Find(name string) (*Plan, error) {
+	// Explicit path to a plan file
+	if filepath.Ext(name) == ".md" {
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			return Load(name)
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(name), ".md")
+	for _, dir := range []string{q.currentDir(), q.pendingDir(), q.completeDir(), q.failedDir()} {
+		path := filepath.Join(dir, base+".md")
+		if _, err := os.Stat(path); err == nil {
+			return Load(path)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrPlanNotFound, name)
+}
+
 // Status returns the current queue status with counts and plan names.
 func (q *Queue) Status() (*QueueStatus, error) {
+	return q.StatusFor(nil)
+}
+
+// StatusFor returns the queue status counting only plans that have all of
+// the given labels. A current plan without them is left out.
+func (q *Queue) StatusFor(labels []string) (*QueueStatus, error) {
 	pending, err := q.Pending()
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("listing pending: %w", err)
@@ -196,30 +482,82 @@ func (q *Queue) Status() (*QueueStatus, error) {
 		return nil, fmt.Errorf("getting current: %w", err)
 	}
 
-	complete, err := q.listPlans(q.completeDir())
+	complete, err := q.finished(q.completeDir())
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("listing complete: %w", err)
 	}
 
+	abandoned, err := q.finished(q.abandonedDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("listing abandoned: %w", err)
+	}
+
+	failed, err := q.finished(q.failedDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("listing failed: %w", err)
+	}
+
+	pending = FilterByLabels(pending, labels)
+	complete = FilterByLabels(complete, labels)
+	abandoned = FilterByLabels(abandoned, labels)
+	failed = FilterByLabels(failed, labels)
+	if current != nil && !current.HasLabels(labels) {
+		current = nil
+	}
+
 	status := &QueueStatus{
-		PendingCount:  len(pending),
-		CurrentCount:  0,
-		CompleteCount: len(complete),
-		PendingPlans:  make([]string, len(pending)),
+		PendingCount:   len(pending),
+		CurrentCount:   0,
+		CompleteCount:  len(complete),
+		AbandonedCount: len(abandoned),
+		FailedCount:    len(failed),
+		PendingPlans:   make([]string, len(pending)),
 	}
 
+	now := time.Now()
 	for i, p := range pending {
 		status.PendingPlans[i] = p.Name
+		if p.Snoozed(now) {
+			if status.SnoozedUntil == nil {
+				status.SnoozedUntil = make(map[string]time.Time)
+			}
+			status.SnoozedUntil[p.Name] = p.SnoozedUntil
+		}
+	}
+	for _, p := range failed {
+		status.FailedPlans = append(status.FailedPlans, p.Name)
 	}
 
 	if current != nil {
 		status.CurrentCount = 1
 		status.CurrentPlan = current.Name
+		status.CurrentBranch = current.Branch
+		if q.Live != nil {
+			current = q.Live(current)
+		}
+		status.CurrentDone, status.CurrentTotal = CountComplete(current.Tasks), CountTotal(current.Tasks)
+		if status.CurrentETA = q.ETA(current); status.CurrentETA != nil {
+			status.CurrentDone, status.CurrentTotal = status.CurrentETA.Done, status.CurrentETA.Total
+		}
 	}
 
 	return status, nil
 }
 
+// ETA estimates the time remaining for a plan from the events log.
+// Returns nil if no events log is configured or there is not enough history.
+func (q *Queue) ETA(p *Plan) *ETA {
+	if q.Events == nil {
+		return nil
+	}
+
+	evs, err := RunEvents(q.Events, p.Name)
+	if err != nil {
+		return nil
+	}
+	return EstimateETA(p, evs)
+}
+
 // listPlans lists all .md files in the given directory as plans.
 // Returns an empty slice if the directory doesn't exist.
 func (q *Queue) listPlans(dir string) ([]*Plan, error) {
@@ -242,12 +580,8 @@ func (q *Queue) listPlans(dir string) ([]*Plan, error) {
 			continue
 		}
 
-		// Skip progress and feedback files
-		name := entry.Name()
-		if strings.HasSuffix(name, ".progress.md") {
-			continue
-		}
-		if strings.HasSuffix(name, ".feedback.md") {
+		// Skip progress, feedback, and other sidecar files
+		if !IsPlanFile(entry.Name()) {
 			continue
 		}
 
@@ -1,9 +1,15 @@
 package plan
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/events"
 )
 
 // createTestQueue sets up a temporary queue directory structure.
@@ -172,6 +178,25 @@ func TestQueue_Current_WithPlan(t *testing.T) {
 	}
 }
 
+func TestQueue_CurrentLive(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	createTestPlanFile(t, q.currentDir(), "active-plan")
+
+	current, _ := q.CurrentLive()
+	if current == nil || current.Name != "active-plan" {
+		t.Fatalf("CurrentLive() without Live = %+v, want active-plan", current)
+	}
+
+	live := &Plan{Name: "active-plan", Path: "worktree/active-plan.md"}
+	q.Live = func(p *Plan) *Plan { return live }
+	if current, _ := q.CurrentLive(); current != live {
+		t.Errorf("CurrentLive() = %+v, want Live's copy", current)
+	}
+}
+
 func TestQueue_Activate(t *testing.T) {
 	tmpDir, cleanup := createTestQueue(t)
 	defer cleanup()
@@ -206,6 +231,75 @@ func TestQueue_Activate(t *testing.T) {
 	}
 }
 
+func TestQueue_InstructionsFollowPlan(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	planPath := createTestPlanFile(t, q.pendingDir(), "with-instructions")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+	if err := os.WriteFile(InstructionsPath(plan), []byte("No new dependencies."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The instructions file is not listed as a plan
+	pending, err := q.Pending()
+	if err != nil {
+		t.Fatalf("listing pending: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("expected 1 pending plan, got %d", len(pending))
+	}
+
+	if err := q.Activate(plan); err != nil {
+		t.Fatalf("activating plan: %v", err)
+	}
+	if got, _ := ReadInstructions(plan); got != "No new dependencies." {
+		t.Errorf("instructions after activate = %q", got)
+	}
+
+	if err := q.Complete(plan); err != nil {
+		t.Fatalf("completing plan: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(q.completeDir(), "with-instructions.instructions.md")); err != nil {
+		t.Errorf("instructions not moved to complete: %v", err)
+	}
+}
+
+func TestQueue_ChangesFollowPlan(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	planPath := createTestPlanFile(t, q.pendingDir(), "with-changes")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+	if err := q.Activate(plan); err != nil {
+		t.Fatalf("activating plan: %v", err)
+	}
+	if err := RecordChanges(plan, 1, []FileChange{{Path: "main.go", Change: ChangeModified}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Complete(plan); err != nil {
+		t.Fatalf("completing plan: %v", err)
+	}
+	changes, err := LoadChanges(plan)
+	if err != nil || len(changes.Files) != 1 {
+		t.Errorf("ledger after complete = %+v, %v", changes, err)
+	}
+	if _, err := os.Stat(filepath.Join(q.currentDir(), "with-changes.changes.json")); !os.IsNotExist(err) {
+		t.Errorf("ledger left behind in current/: %v", err)
+	}
+}
+
 func TestQueue_Activate_QueueFull(t *testing.T) {
 	tmpDir, cleanup := createTestQueue(t)
 	defer cleanup()
@@ -303,6 +397,33 @@ func TestQueue_Complete_NotInCurrent(t *testing.T) {
 	}
 }
 
+func TestQueue_Complete_MovedByAnotherProcess(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	planPath := createTestPlanFile(t, q.currentDir(), "racing")
+
+	// Two processes loaded the plan; the first abandons it
+	winner, _ := Load(planPath)
+	loser, _ := Load(planPath)
+	if err := q.Abandon(winner); err != nil {
+		t.Fatalf("Abandon() error = %v", err)
+	}
+
+	// A sidecar written after the abandon must stay where it is
+	os.WriteFile(ChangesPath(loser), []byte("late"), 0644)
+	for name, move := range map[string]func(*Plan) error{"Complete": q.Complete, "Reset": q.Reset} {
+		err := move(loser)
+		if !errors.Is(err, ErrPlanNotInCurrent) || !strings.Contains(err.Error(), "moved by another process") {
+			t.Errorf("%s() error = %v, want ErrPlanNotInCurrent naming the other process", name, err)
+		}
+	}
+	if _, err := os.Stat(ChangesPath(loser)); err != nil {
+		t.Errorf("sidecar moved by the failed move: %v", err)
+	}
+}
+
 func TestQueue_Reset(t *testing.T) {
 	tmpDir, cleanup := createTestQueue(t)
 	defer cleanup()
@@ -357,6 +478,212 @@ func TestQueue_Reset_NotInCurrent(t *testing.T) {
 	}
 }
 
+func TestQueue_Abandon(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	// Create a current plan with progress and feedback files
+	planPath := createTestPlanFile(t, q.currentDir(), "giving-up")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+	os.WriteFile(ProgressPath(plan), []byte("progress"), 0644)
+	os.WriteFile(FeedbackPath(plan), []byte("feedback"), 0644)
+
+	if err := q.Abandon(plan); err != nil {
+		t.Fatalf("abandoning plan: %v", err)
+	}
+
+	expectedNewPath := filepath.Join(q.abandonedDir(), "giving-up.md")
+	if plan.Path != expectedNewPath {
+		t.Errorf("plan path not updated: expected %s, got %s", expectedNewPath, plan.Path)
+	}
+	for _, path := range []string{plan.Path, ProgressPath(plan), FeedbackPath(plan)} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s not in abandoned: %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(planPath); !os.IsNotExist(err) {
+		t.Error("plan file still exists in current")
+	}
+
+	status, err := q.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.AbandonedCount != 1 || status.CurrentCount != 0 {
+		t.Errorf("status = %+v, want 1 abandoned and no current", status)
+	}
+}
+
+func TestQueue_AuditMoves(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	auditPath := filepath.Join(tmpDir, audit.FileName)
+	q.Audit = audit.NewLog(auditPath, "worker")
+
+	plan, err := Load(createTestPlanFile(t, q.pendingDir(), "audited"))
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+	if err := q.Activate(plan); err != nil {
+		t.Fatalf("activating plan: %v", err)
+	}
+	if err := q.Reset(plan); err != nil {
+		t.Fatalf("resetting plan: %v", err)
+	}
+	q.Activate(plan)
+	if err := q.Abandon(plan); err != nil {
+		t.Fatalf("abandoning plan: %v", err)
+	}
+
+	entries, err := audit.ReadAll(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]string{{"pending", "current"}, {"current", "pending"}, {"pending", "current"}, {"current", "abandoned"}}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, e := range entries {
+		if e.Action != audit.ActionQueueMove || e.Plan != "audited" || e.Details["from"] != want[i][0] || e.Details["to"] != want[i][1] {
+			t.Errorf("entry %d = %+v, want %s -> %s", i, e, want[i][0], want[i][1])
+		}
+	}
+	if _, err := audit.Verify(entries); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestQueue_Abandon_Pending(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	// Plans without sidecar files can be abandoned from pending/
+	planPath := createTestPlanFile(t, q.pendingDir(), "not-needed")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+
+	if err := q.Abandon(plan); err != nil {
+		t.Fatalf("abandoning plan: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(q.abandonedDir(), "not-needed.md")); err != nil {
+		t.Errorf("plan file not in abandoned: %v", err)
+	}
+}
+
+func TestQueue_Abandon_Complete(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	planPath := createTestPlanFile(t, q.completeDir(), "already-done")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+
+	if err := q.Abandon(plan); !errors.Is(err, ErrPlanNotFound) {
+		t.Errorf("expected ErrPlanNotFound, got %v", err)
+	}
+}
+
+func TestQueue_FailAndRetry(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+
+	planPath := createTestPlanFile(t, q.currentDir(), "ran-out")
+	plan, err := Load(planPath)
+	if err != nil {
+		t.Fatalf("loading plan: %v", err)
+	}
+	os.WriteFile(FeedbackPath(plan), []byte("feedback"), 0644)
+
+	if err := q.Fail(plan, "max iterations reached"); err != nil {
+		t.Fatalf("failing plan: %v", err)
+	}
+	if plan.Path != filepath.Join(q.failedDir(), "ran-out.md") {
+		t.Errorf("plan path not updated: got %s", plan.Path)
+	}
+	for _, path := range []string{plan.Path, ProgressPath(plan), FeedbackPath(plan)} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s not in failed: %v", filepath.Base(path), err)
+		}
+	}
+	progress, _ := ReadProgress(plan)
+	if !strings.Contains(progress, "Reason: max iterations reached") {
+		t.Errorf("progress = %q, want failure reason", progress)
+	}
+
+	status, err := q.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.FailedCount != 1 || len(status.FailedPlans) != 1 || status.FailedPlans[0] != "ran-out" || status.CurrentCount != 0 {
+		t.Errorf("status = %+v, want 1 failed and no current", status)
+	}
+
+	// Failed plans can still be found by name
+	if found, err := q.Find("ran-out"); err != nil || found.Path != plan.Path {
+		t.Errorf("Find() = %v, %v", found, err)
+	}
+
+	if err := q.Retry(plan); err != nil {
+		t.Fatalf("retrying plan: %v", err)
+	}
+	if plan.Path != filepath.Join(q.pendingDir(), "ran-out.md") {
+		t.Errorf("plan path not updated: got %s", plan.Path)
+	}
+	if _, err := os.Stat(FeedbackPath(plan)); err != nil {
+		t.Errorf("feedback not moved to pending: %v", err)
+	}
+}
+
+func TestQueue_Fail_NotCurrent(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	plan, _ := Load(createTestPlanFile(t, q.pendingDir(), "waiting"))
+
+	if err := q.Fail(plan, "nope"); !errors.Is(err, ErrPlanNotInCurrent) {
+		t.Errorf("expected ErrPlanNotInCurrent, got %v", err)
+	}
+	if err := q.Retry(plan); !errors.Is(err, ErrPlanNotRetryable) {
+		t.Errorf("expected ErrPlanNotRetryable, got %v", err)
+	}
+}
+
+func TestQueue_Retry_Abandoned(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	plan, _ := Load(createTestPlanFile(t, q.pendingDir(), "second-thoughts"))
+	if err := q.Abandon(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.Retry(plan); err != nil {
+		t.Fatalf("retrying abandoned plan: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(q.pendingDir(), "second-thoughts.md")); err != nil {
+		t.Errorf("plan not back in pending: %v", err)
+	}
+}
+
 func TestQueue_Status(t *testing.T) {
 	tmpDir, cleanup := createTestQueue(t)
 	defer cleanup()
@@ -411,6 +738,68 @@ func TestQueue_Status(t *testing.T) {
 	}
 }
 
+func TestQueue_StatusFor_Labels(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	os.WriteFile(filepath.Join(q.pendingDir(), "api.md"), []byte("# Plan: API\n**Labels:** backend, urgent\n"), 0644)
+	os.WriteFile(filepath.Join(q.pendingDir(), "guide.md"), []byte("# Plan: Guide\n**Labels:** docs\n"), 0644)
+	os.WriteFile(filepath.Join(q.currentDir(), "db.md"), []byte("# Plan: DB\n**Labels:** Backend\n"), 0644)
+	createTestPlanFile(t, q.completeDir(), "unlabeled")
+
+	status, err := q.StatusFor([]string{"backend"})
+	if err != nil {
+		t.Fatalf("getting status: %v", err)
+	}
+	if status.PendingCount != 1 || status.PendingPlans[0] != "api" {
+		t.Errorf("expected only api pending, got %v", status.PendingPlans)
+	}
+	if status.CurrentPlan != "db" {
+		t.Errorf("expected current plan db, got %q", status.CurrentPlan)
+	}
+	if status.CompleteCount != 0 {
+		t.Errorf("expected unlabeled complete plan to be filtered out, got %d", status.CompleteCount)
+	}
+
+	status, err = q.StatusFor([]string{"docs"})
+	if err != nil {
+		t.Fatalf("getting status: %v", err)
+	}
+	if status.CurrentPlan != "" || status.PendingCount != 1 {
+		t.Errorf("expected only guide for docs, got current %q, pending %v", status.CurrentPlan, status.PendingPlans)
+	}
+}
+
+func TestQueue_Status_ETA(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	createTestPlanFile(t, q.currentDir(), "current-1")
+
+	// Without an events log there is no ETA
+	status, err := q.Status()
+	if err != nil {
+		t.Fatalf("getting status: %v", err)
+	}
+	if status.CurrentETA != nil {
+		t.Errorf("expected no ETA without events log, got %+v", status.CurrentETA)
+	}
+
+	q.Events = events.NewLog(filepath.Join(tmpDir, events.EventsFileName))
+	q.Events.Append(events.Event{Type: events.TypePlanStarted, Plan: "current-1"})
+	q.Events.Append(events.Event{Type: events.TypeIteration, Plan: "current-1", Duration: time.Hour, TasksDone: 1, TasksTotal: 2})
+
+	status, err = q.Status()
+	if err != nil {
+		t.Fatalf("getting status: %v", err)
+	}
+	if status.CurrentETA == nil || status.CurrentETA.Remaining != time.Hour {
+		t.Errorf("CurrentETA = %+v, want 1h remaining", status.CurrentETA)
+	}
+}
+
 func TestQueue_FullLifecycle(t *testing.T) {
 	tmpDir, cleanup := createTestQueue(t)
 	defer cleanup()
@@ -479,3 +868,46 @@ func TestQueue_NonExistentDirectory(t *testing.T) {
 		t.Errorf("expected 0 plans, got %d", len(plans))
 	}
 }
+
+func TestQueue_Find(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	createTestPlanFile(t, filepath.Join(tmpDir, "current"), "active")
+	createTestPlanFile(t, filepath.Join(tmpDir, "pending"), "queued")
+	donePath := createTestPlanFile(t, filepath.Join(tmpDir, "complete"), "done")
+
+	q := NewQueue(tmpDir)
+
+	tests := []struct {
+		name    string
+		wantDir string
+	}{
+		{"active", "current"},
+		{"queued.md", "pending"},
+		{"done", "complete"},
+		{donePath, "complete"},
+	}
+
+	for _, tt := range tests {
+		p, err := q.Find(tt.name)
+		if err != nil {
+			t.Errorf("Find(%q) error = %v", tt.name, err)
+			continue
+		}
+		if filepath.Base(filepath.Dir(p.Path)) != tt.wantDir {
+			t.Errorf("Find(%q) path = %s, want in %s/", tt.name, p.Path, tt.wantDir)
+		}
+	}
+}
+
+func TestQueue_Find_NotFound(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	_, err := q.Find("missing")
+	if !errors.Is(err, ErrPlanNotFound) {
+		t.Errorf("expected ErrPlanNotFound, got %v", err)
+	}
+}
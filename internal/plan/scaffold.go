@@ -0,0 +1,153 @@
+// Package plan handles plan parsing and queue management.
+package plan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ScaffoldOptions describes a new plan created by Scaffold.
+type ScaffoldOptions struct {
+	// Title is the plan title (required). The file name is derived from it.
+	Title string
+
+	// Body is the request text placed in the Context section.
+	Body string
+
+	// Source records where the request came from (e.g., a URL or "Slack message from Jane").
+	Source string
+
+	// Tasks are optional initial task titles. If empty, a single task asks the
+	// agent to break the request down and implement it.
+	Tasks []string
+
+	// Labels are optional plan labels, written to the **Labels:** header.
+	Labels []string
+
+	// Jira is an optional Jira issue key, written to the **Jira:** header.
+	Jira string
+
+	// Linear is an optional Linear issue identifier, written to the
+	// **Linear:** header.
+	Linear string
+
+	// GitHub is an optional GitHub issue number, written to the
+	// **GitHub:** header.
+	GitHub string
+}
+
+// Scaffold creates a new plan file in dir from opts and returns the loaded plan.
+// The file name is derived from the title; a numeric suffix is appended if a
+// plan with that name already exists in dir.
+func Scaffold(dir string, opts ScaffoldOptions) (*Plan, error) {
+	title := strings.TrimSpace(opts.Title)
+	if title == "" {
+		return nil, fmt.Errorf("plan title is required")
+	}
+	return Create(dir, title, renderScaffold(title, opts))
+}
+
+// Create writes content as a new plan file in dir and returns the loaded
+// plan. The file name is derived from title as in Scaffold.
+func Create(dir, title, content string) (*Plan, error) {
+	base := sanitizeBranchName(strings.TrimSpace(title))
+	if base == "" {
+		base = "plan"
+	}
+	if len(base) > 50 {
+		base = strings.Trim(base[:50], "-")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating plan directory: %w", err)
+	}
+
+	// Find an unused file name
+	path := filepath.Join(dir, base+".md")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.md", base, i))
+	}
+
+	// The worker watches pending/, so it must never see a partial plan
+	if err := WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("writing plan file: %w", err)
+	}
+
+	return Load(path)
+}
+
+// MarkdownTitle returns the title from a plan's first top-level heading
+// ("# Plan: Title" or "# Title"), or "" if it has none.
+func MarkdownTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+		title := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		return strings.TrimSpace(strings.TrimPrefix(title, "Plan:"))
+	}
+	return ""
+}
+
+// renderScaffold renders the plan markdown following the plan spec layout.
+func renderScaffold(title string, opts ScaffoldOptions) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# Plan: %s\n\n", title))
+	sb.WriteString("**Status:** pending\n")
+	if len(opts.Labels) > 0 {
+		sb.WriteString(fmt.Sprintf("**Labels:** %s\n", strings.Join(opts.Labels, ", ")))
+	}
+	if opts.Jira != "" {
+		sb.WriteString(fmt.Sprintf("**Jira:** %s\n", opts.Jira))
+	}
+	if opts.Linear != "" {
+		sb.WriteString(fmt.Sprintf("**Linear:** %s\n", opts.Linear))
+	}
+	if opts.GitHub != "" {
+		sb.WriteString(fmt.Sprintf("**GitHub:** #%s\n", opts.GitHub))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Context\n")
+	if body := strings.TrimSpace(opts.Body); body != "" {
+		sb.WriteString(body + "\n")
+	} else {
+		sb.WriteString(title + "\n")
+	}
+	if opts.Source != "" {
+		sb.WriteString(fmt.Sprintf("\n_Source: %s_\n", opts.Source))
+	}
+	sb.WriteString("\n---\n\n")
+
+	sb.WriteString("## Tasks\n\n")
+	tasks := opts.Tasks
+	if len(tasks) == 0 {
+		tasks = []string{"Break down and implement the request"}
+	}
+	for i, task := range tasks {
+		sb.WriteString(fmt.Sprintf("### T%d: %s\n\n", i+1, task))
+		if i == 0 {
+			sb.WriteString("**Requires:** —\n")
+			sb.WriteString("**Status:** open\n\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("**Requires:** T%d\n", i))
+			sb.WriteString("**Status:** blocked\n\n")
+		}
+		sb.WriteString("**Done when:**\n")
+		sb.WriteString("- [ ] Request in Context is fully addressed\n")
+		sb.WriteString("- [ ] Tests and lint pass\n\n")
+		sb.WriteString("---\n\n")
+	}
+
+	sb.WriteString("## Discovered\n")
+	sb.WriteString("<!-- Add with D1, D2, etc. Include \"Found in: T1\" -->\n")
+
+	return sb.String()
+}
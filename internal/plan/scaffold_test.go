@@ -0,0 +1,138 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffold(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pending")
+
+	p, err := Scaffold(dir, ScaffoldOptions{
+		Title:  "Add dark mode toggle",
+		Body:   "Users want a dark mode toggle in settings.",
+		Source: "https://github.com/o/r/issues/12",
+	})
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+
+	if p.Name != "add-dark-mode-toggle" {
+		t.Errorf("Name = %q, want %q", p.Name, "add-dark-mode-toggle")
+	}
+	if p.Branch != "feat/add-dark-mode-toggle" {
+		t.Errorf("Branch = %q", p.Branch)
+	}
+	if p.Status != "pending" {
+		t.Errorf("Status = %q, want pending", p.Status)
+	}
+	for _, want := range []string{
+		"# Plan: Add dark mode toggle",
+		"Users want a dark mode toggle in settings.",
+		"_Source: https://github.com/o/r/issues/12_",
+		"### T1: Break down and implement the request",
+	} {
+		if !strings.Contains(p.Content, want) {
+			t.Errorf("plan content missing %q:\n%s", want, p.Content)
+		}
+	}
+	if len(p.Tasks) == 0 {
+		t.Error("scaffolded plan should contain checkbox tasks")
+	}
+}
+
+func TestScaffold_UniqueName(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := Scaffold(dir, ScaffoldOptions{Title: "Fix login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Scaffold(dir, ScaffoldOptions{Title: "Fix login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Name != "fix-login" || second.Name != "fix-login-2" {
+		t.Errorf("names = %q, %q; want fix-login, fix-login-2", first.Name, second.Name)
+	}
+	if _, err := os.Stat(first.Path); err != nil {
+		t.Error("first plan should still exist")
+	}
+}
+
+func TestScaffold_Tasks(t *testing.T) {
+	p, err := Scaffold(t.TempDir(), ScaffoldOptions{
+		Title: "Two steps",
+		Tasks: []string{"First step", "Second step"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(p.Content, "### T2: Second step\n\n**Requires:** T1\n**Status:** blocked") {
+		t.Errorf("second task should depend on the first:\n%s", p.Content)
+	}
+}
+
+func TestScaffold_RequiresTitle(t *testing.T) {
+	if _, err := Scaffold(t.TempDir(), ScaffoldOptions{Title: "  "}); err == nil {
+		t.Error("expected error for empty title")
+	}
+}
+
+func TestScaffold_Labels(t *testing.T) {
+	p, err := Scaffold(t.TempDir(), ScaffoldOptions{Title: "Rotate keys", Labels: []string{"infra", "Urgent"}})
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+	if got := strings.Join(p.Labels, ","); got != "infra,urgent" {
+		t.Errorf("Labels = %v, want [infra urgent]", p.Labels)
+	}
+	if p.Status != "pending" {
+		t.Errorf("Status = %q, want pending", p.Status)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Plan: Add metrics\n\n## Tasks\n- [ ] Export counters\n"
+
+	p, err := Create(dir, MarkdownTitle(content), content)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if p.Name != "add-metrics" {
+		t.Errorf("Name = %q, want add-metrics", p.Name)
+	}
+	if p.Content != content {
+		t.Errorf("Content = %q, want it unchanged", p.Content)
+	}
+
+	second, err := Create(dir, "Add metrics", content)
+	if err != nil {
+		t.Fatalf("second Create() error = %v", err)
+	}
+	if second.Name != "add-metrics-2" {
+		t.Errorf("second Name = %q, want add-metrics-2", second.Name)
+	}
+}
+
+func TestMarkdownTitle(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"# Plan: Add metrics\n", "Add metrics"},
+		{"<!-- note -->\n# Fix login\n## Tasks\n", "Fix login"},
+		{"## Tasks only\n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MarkdownTitle(tt.content); got != tt.want {
+			t.Errorf("MarkdownTitle(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnoozeField is the header that parks a pending plan until a wake time.
+const SnoozeField = "Snoozed-Until"
+
+// snoozeRegex matches the **Snoozed-Until:** wake time in markdown.
+var snoozeRegex = regexp.MustCompile(`(?m)^\*\*Snoozed-Until:\*\*[ \t]*(\S+)`)
+
+// defaultWakeHour is the hour a day given without a time wakes at
+// ("mon" is Monday 09:00).
+const defaultWakeHour = 9
+
+// clockRegex matches a time of day: "9am", "9:30pm", or "14:00".
+var clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+// weekdays maps day names and their abbreviations to weekdays.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// extractSnoozedUntil parses the **Snoozed-Until:** header (RFC 3339).
+// Returns the zero time if there is none or it doesn't parse.
+func extractSnoozedUntil(content string) time.Time {
+	matches := snoozeRegex.FindStringSubmatch(content)
+	if len(matches) < 2 {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, matches[1])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Snoozed reports whether the plan is snoozed at now. Queues skip snoozed
+// plans until they wake.
+func (p *Plan) Snoozed(now time.Time) bool {
+	return now.Before(p.SnoozedUntil)
+}
+
+// Snooze parks the plan until the given time by setting its
+// **Snoozed-Until:** header.
+func Snooze(p *Plan, until time.Time) error {
+	return Edit(p, func(d *Document) error {
+		d.SetField(SnoozeField, until.Format(time.RFC3339))
+		return nil
+	})
+}
+
+// Wake removes the plan's **Snoozed-Until:** header, if it has one.
+func Wake(p *Plan) error {
+	return Edit(p, func(d *Document) error {
+		d.RemoveField(SnoozeField)
+		return nil
+	})
+}
+
+// ParseWakeTime parses when a snoozed plan should wake, relative to now:
+//   - a day and time: "mon 9am", "friday 14:30", "tomorrow 8am" (a day
+//     alone wakes at 09:00; a weekday is its next occurrence after now)
+//   - a time of day: "3pm", the next time the clock shows it
+//   - a period: "2h", "3d", "1w"
+//   - a date: "2026-01-05", "2026-01-05 14:00", or RFC 3339
+//
+// The wake time must be in the future.
+func ParseWakeTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	t, ok := parseWakeExact(s, now)
+	if !ok {
+		var err error
+		if t, err = parseWakeDay(strings.ToLower(s), now); err != nil {
+			return time.Time{}, fmt.Errorf("invalid wake time %q (use e.g. \"mon 9am\", \"tomorrow\", \"3d\", or \"2026-01-05 14:00\")", s)
+		}
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("wake time %s is in the past", t.Format("2006-01-02 15:04"))
+	}
+	return t, nil
+}
+
+// parseWakeExact parses a date or a period. ok is false if s is neither.
+func parseWakeExact(s string, now time.Time) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, true
+		}
+	}
+
+	s = strings.ToLower(s)
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if len(s) > 1 {
+		if mult, ok := unit[s[len(s)-1]]; ok {
+			if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n > 0 {
+				return now.Add(time.Duration(n) * mult), true
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), true
+	}
+	return time.Time{}, false
+}
+
+// parseWakeDay parses a day ("today", "tomorrow", or a weekday) and a time
+// of day, either of which may be left out.
+func parseWakeDay(s string, now time.Time) (time.Time, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, fmt.Errorf("want a day and/or a time")
+	}
+
+	hour, minute := defaultWakeHour, 0
+	days, weekday := -1, time.Weekday(-1)
+	hasDay, hasClock := false, false
+	for _, f := range fields {
+		if wd, ok := weekdays[f]; ok && !hasDay {
+			weekday, hasDay = wd, true
+		} else if (f == "today" || f == "tomorrow") && !hasDay {
+			days, hasDay = 0, true
+			if f == "tomorrow" {
+				days = 1
+			}
+		} else if h, m, ok := parseClock(f); ok && !hasClock {
+			hour, minute, hasClock = h, m, true
+		} else {
+			return time.Time{}, fmt.Errorf("unexpected %q", f)
+		}
+	}
+
+	t := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	switch {
+	case weekday >= 0:
+		t = t.AddDate(0, 0, (int(weekday)-int(now.Weekday())+7)%7)
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 7)
+		}
+	case days >= 0:
+		t = t.AddDate(0, 0, days)
+	case !t.After(now):
+		// A time of day alone is its next occurrence
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// parseClock parses a time of day such as "9am", "12:30pm", or "14:00". A
+// bare number ("9") is rejected as ambiguous.
+func parseClock(s string) (hour, minute int, ok bool) {
+	m := clockRegex.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
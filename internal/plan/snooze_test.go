@@ -0,0 +1,97 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseWakeTime(t *testing.T) {
+	// Wednesday 10:30
+	now := time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := map[string]time.Time{
+		"mon 9am":              at(19, 9, 0),
+		"Monday":               at(19, 9, 0),
+		"wed 11am":             at(14, 11, 0),
+		"wed 9am":              at(21, 9, 0),
+		"9:15pm fri":           at(16, 21, 15),
+		"tomorrow":             at(15, 9, 0),
+		"tomorrow 12am":        at(15, 0, 0),
+		"today 14:00":          at(14, 14, 0),
+		"3pm":                  at(14, 15, 0),
+		"8am":                  at(15, 8, 0),
+		"12pm":                 at(14, 12, 0),
+		"2h":                   now.Add(2 * time.Hour),
+		"3d":                   now.AddDate(0, 0, 3),
+		"1W":                   now.AddDate(0, 0, 7),
+		"2026-10-20":           at(20, 0, 0),
+		"2026-10-20 14:00":     at(20, 14, 0),
+		"2026-10-20T08:00:00Z": at(20, 8, 0),
+	}
+	for input, want := range tests {
+		got, err := ParseWakeTime(input, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseWakeTime(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "soon", "9", "13pm", "mon tue", "9am 10am", "mon 9am extra", "today 8am", "2026-01-01", "-2h"} {
+		if got, err := ParseWakeTime(input, now); err == nil {
+			t.Errorf("ParseWakeTime(%q) = %v, want an error", input, got)
+		}
+	}
+}
+
+func TestSnoozeAndWake(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "my-plan.md")
+	os.WriteFile(path, []byte("# Plan: Mine\n**Status:** pending\n\n## Tasks\n- [ ] One\n"), 0644)
+	p, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Snoozed(time.Now()) {
+		t.Fatal("new plan is snoozed")
+	}
+
+	until := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	if err := Snooze(p, until); err != nil {
+		t.Fatalf("Snooze() error = %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "**Status:** pending\n**Snoozed-Until:** 2030-01-07T09:00:00Z\n") {
+		t.Errorf("plan after Snooze() =\n%s", content)
+	}
+	if !p.SnoozedUntil.Equal(until) || !p.Snoozed(time.Now()) || p.Snoozed(until) {
+		t.Errorf("SnoozedUntil = %v, want %v", p.SnoozedUntil, until)
+	}
+
+	if err := Wake(p); err != nil {
+		t.Fatalf("Wake() error = %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	if strings.Contains(string(content), "Snoozed-Until") || !p.SnoozedUntil.IsZero() {
+		t.Errorf("plan after Wake() =\n%s", content)
+	}
+}
+
+func TestQueueStatus_Snoozed(t *testing.T) {
+	q := NewQueue(t.TempDir())
+	os.MkdirAll(q.PendingDir(), 0755)
+	os.WriteFile(filepath.Join(q.PendingDir(), "later.md"), []byte("# Plan: Later\n**Snoozed-Until:** 2099-01-05T09:00:00Z\n"), 0644)
+	os.WriteFile(filepath.Join(q.PendingDir(), "woken.md"), []byte("# Plan: Woken\n**Snoozed-Until:** 2000-01-05T09:00:00Z\n"), 0644)
+
+	status, err := q.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.SnoozedUntil) != 1 || status.SnoozedUntil["later"].Year() != 2099 {
+		t.Errorf("SnoozedUntil = %v, want only later", status.SnoozedUntil)
+	}
+}
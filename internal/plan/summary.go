@@ -0,0 +1,19 @@
+package plan
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SummaryPath returns the path to the plan's completion summary.
+// The summary is named "<plan-name>.summary.md" in the same directory as the plan.
+// Example: "plans/complete/go-rewrite.md" → "plans/complete/go-rewrite.summary.md"
+func SummaryPath(plan *Plan) string {
+	ext := filepath.Ext(plan.Path)
+	return strings.TrimSuffix(plan.Path, ext) + ".summary.md"
+}
+
+// WriteSummary writes the plan's completion summary.
+func WriteSummary(plan *Plan, content string) error {
+	return WriteFileAtomic(SummaryPath(plan), []byte(content), 0644)
+}
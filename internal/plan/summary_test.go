@@ -0,0 +1,40 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSummaryPath(t *testing.T) {
+	p := &Plan{Path: filepath.Join("plans", "complete", "go-rewrite.md")}
+	want := filepath.Join("plans", "complete", "go-rewrite.summary.md")
+	if got := SummaryPath(p); got != want {
+		t.Errorf("SummaryPath() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteSummary_NotListedAsPlan(t *testing.T) {
+	tmpDir, cleanup := createTestQueue(t)
+	defer cleanup()
+
+	q := NewQueue(tmpDir)
+	p, err := Load(createTestPlanFile(t, q.completeDir(), "done"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSummary(p, "# Summary: done\n"); err != nil {
+		t.Fatalf("WriteSummary() error = %v", err)
+	}
+	if data, err := os.ReadFile(SummaryPath(p)); err != nil || string(data) != "# Summary: done\n" {
+		t.Errorf("summary = %q, %v", data, err)
+	}
+
+	completed, err := q.Completed()
+	if err != nil {
+		t.Fatalf("Completed() error = %v", err)
+	}
+	if len(completed) != 1 {
+		t.Errorf("expected 1 completed plan, got %d", len(completed))
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -79,49 +78,33 @@ func Save(plan *Plan) error {
 	if plan.Path == "" {
 		return errors.New("plan path is empty")
 	}
+	return WriteFileAtomic(plan.Path, []byte(plan.Content), 0644)
+}
 
-	// Create temp file in same directory for atomic rename
-	dir := filepath.Dir(plan.Path)
-	tmpFile, err := os.CreateTemp(dir, ".plan-*.tmp")
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-
-	// Clean up temp file if something goes wrong
-	success := false
-	defer func() {
-		if !success {
-			os.Remove(tmpPath)
-		}
-	}()
-
-	// Write content to temp file
-	_, err = tmpFile.WriteString(plan.Content)
+// Edit applies fn to the plan file as a Document and, if fn changed it,
+// writes it back atomically. The file is read afresh, so edits made since p
+// was loaded aren't lost; p is reloaded afterwards. Nothing is written if fn
+// returns an error.
+func Edit(p *Plan, fn func(d *Document) error) error {
+	content, err := os.ReadFile(p.Path)
 	if err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("failed to write temp file: %w", err)
+		return fmt.Errorf("reading plan file: %w", err)
 	}
 
-	// Close temp file before rename
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("failed to close temp file: %w", err)
+	doc := ParseDocument(string(content))
+	if err := fn(doc); err != nil {
+		return err
 	}
-
-	// Preserve original file permissions if file exists
-	info, err := os.Stat(plan.Path)
-	if err == nil {
-		// File exists, use its permissions
-		if err := os.Chmod(tmpPath, info.Mode()); err != nil {
-			return fmt.Errorf("failed to set file permissions: %w", err)
+	if updated := doc.String(); updated != string(content) {
+		if err := WriteFileAtomic(p.Path, []byte(updated), 0644); err != nil {
+			return err
 		}
 	}
 
-	// Atomic rename
-	if err := os.Rename(tmpPath, plan.Path); err != nil {
-		return fmt.Errorf("failed to rename temp file: %w", err)
+	reloaded, err := Load(p.Path)
+	if err != nil {
+		return err
 	}
-
-	success = true
+	*p = *reloaded
 	return nil
 }
@@ -315,7 +315,7 @@ func TestSave_AtomicWrite(t *testing.T) {
 		t.Fatalf("failed to read dir: %v", err)
 	}
 	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), ".plan-") && strings.HasSuffix(entry.Name(), ".tmp") {
+		if strings.HasPrefix(entry.Name(), ".") && strings.HasSuffix(entry.Name(), ".tmp") {
 			t.Errorf("temp file left behind: %s", entry.Name())
 		}
 	}
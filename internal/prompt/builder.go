@@ -36,7 +36,7 @@ var placeholderRegex = regexp.MustCompile(`\{\{([A-Z_]+)\}\}`)
 // Build loads a template and substitutes all placeholders.
 // templatePath can be:
 //   - An absolute path to a template file
-//   - A relative path from the prompts directory
+//   - A relative path from the prompts directory or the .ralph directory
 //   - A template name (e.g., "prompt.md")
 //
 // overrides allows providing additional placeholder values that take precedence over config.
@@ -87,11 +87,21 @@ func (b *Builder) loadTemplate(templatePath string) (string, error) {
 		}
 	}
 
+	// Try from the .ralph directory (custom stage templates)
+	if b.configDir != "" && !filepath.IsAbs(templatePath) {
+		content, err := os.ReadFile(filepath.Join(b.configDir, templatePath))
+		if err == nil {
+			return string(content), nil
+		}
+	}
+
 	// Fall back to embedded prompts
 	return loadEmbeddedPrompt(templatePath)
 }
 
 // buildSubstitutions creates a map of all placeholder substitutions.
+// INSTRUCTIONS combines .ralph/instructions.md with the PLAN_INSTRUCTIONS override,
+// and LESSONS is .ralph/lessons.md if lessons.prompt is set.
 func (b *Builder) buildSubstitutions(overrides map[string]string) map[string]string {
 	subs := make(map[string]string)
 
@@ -113,6 +123,17 @@ func (b *Builder) buildSubstitutions(overrides map[string]string) map[string]str
 		subs["TECH_STACK"] = b.loadOverrideFile("tech-stack.md")
 	}
 
+	var projectInstructions string
+	if b.configDir != "" {
+		projectInstructions = b.loadOverrideFile(InstructionsFile)
+	}
+	subs["INSTRUCTIONS"] = FormatInstructions(projectInstructions, overrides["PLAN_INSTRUCTIONS"])
+
+	subs["LESSONS"] = ""
+	if b.config != nil && b.config.Lessons.Prompt && b.configDir != "" {
+		subs["LESSONS"] = FormatLessons(ReadLessons(b.configDir))
+	}
+
 	// Apply explicit overrides (highest precedence)
 	for k, v := range overrides {
 		subs[k] = v
@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// MaxRecentCommitsSize caps each branch's log in the recent commits section,
+// so a run of large commits can't crowd out the plan and task context.
+const MaxRecentCommitsSize = 8 * 1024
+
+// RecentCommits renders the last n commits on the base branch and on the
+// plan branch as the prompt's recent history section, from `git log --stat`
+// in the worktree. The base branch shows what humans and other plans have
+// landed; the plan branch shows what earlier iterations committed (commits
+// not yet on the base branch). Returns an empty string if n is 0 or neither
+// branch has commits to show.
+func RecentCommits(g git.Git, baseBranch, planBranch string, n int) string {
+	if g == nil || n <= 0 {
+		return ""
+	}
+
+	var base, branch string
+	if baseBranch != "" {
+		base = recentLog(g, baseBranch, n)
+	}
+	if planBranch != "" && planBranch != baseBranch {
+		revRange := "HEAD"
+		if baseBranch != "" {
+			revRange = baseBranch + "..HEAD"
+		}
+		branch = recentLog(g, revRange, n)
+	}
+	if base == "" && branch == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Recent Commits\n\n")
+	sb.WriteString("Recent history for context. Check the base branch for changes that affect your work, and don't redo what earlier iterations already committed.\n")
+	if base != "" {
+		fmt.Fprintf(&sb, "\n### %s\n\n```\n%s\n```\n", baseBranch, base)
+	}
+	if branch != "" && baseBranch != "" {
+		fmt.Fprintf(&sb, "\n### %s (not yet on %s)\n\n```\n%s\n```\n", planBranch, baseBranch, branch)
+	} else if branch != "" {
+		fmt.Fprintf(&sb, "\n### %s\n\n```\n%s\n```\n", planBranch, branch)
+	}
+	return sb.String()
+}
+
+// recentLog returns the log for revRange truncated to MaxRecentCommitsSize,
+// or "" if it can't be read.
+func recentLog(g git.Git, revRange string, n int) string {
+	output, err := g.Log(revRange, n)
+	if err != nil {
+		log.Debug("Skipping recent commits for %s: %v", revRange, err)
+		return ""
+	}
+	if len(output) <= MaxRecentCommitsSize {
+		return output
+	}
+
+	// Cut at a line break so the last stat line isn't half a path
+	cut := strings.LastIndex(output[:MaxRecentCommitsSize], "\n")
+	if cut <= 0 {
+		cut = MaxRecentCommitsSize
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+	}
+	return output[:cut] + fmt.Sprintf("\n[Truncated: log exceeds %d bytes]", MaxRecentCommitsSize)
+}
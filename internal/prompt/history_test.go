@@ -0,0 +1,93 @@
+package prompt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/git"
+)
+
+// setupHistoryRepo creates a repository with two commits on main and one on
+// feat/history, which is checked out.
+func setupHistoryRepo(t *testing.T) git.Git {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(file, message string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(message+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", file)
+		run("commit", "-q", "-m", message)
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test User")
+	commit("README.md", "Add readme")
+	commit("api.go", "Rename the API client")
+	run("checkout", "-q", "-b", "feat/history")
+	commit("feature.go", "Add the feature")
+	return git.NewGit(dir)
+}
+
+func TestRecentCommits(t *testing.T) {
+	g := setupHistoryRepo(t)
+
+	got := RecentCommits(g, "main", "feat/history", 5)
+	if !strings.Contains(got, "## Recent Commits") {
+		t.Fatalf("expected recent commits section, got:\n%s", got)
+	}
+
+	base := strings.Index(got, "### main")
+	branch := strings.Index(got, "### feat/history (not yet on main)")
+	if base < 0 || branch < base {
+		t.Fatalf("expected base then plan branch history, got:\n%s", got)
+	}
+	if !strings.Contains(got[base:branch], "Rename the API client") || !strings.Contains(got[base:branch], "api.go") {
+		t.Errorf("base branch history should list its commits with their stat, got:\n%s", got[base:branch])
+	}
+	if strings.Contains(got[branch:], "Rename the API client") || !strings.Contains(got[branch:], "Add the feature") {
+		t.Errorf("plan branch history should only list commits not on main, got:\n%s", got[branch:])
+	}
+}
+
+func TestRecentCommits_Limit(t *testing.T) {
+	g := setupHistoryRepo(t)
+
+	got := RecentCommits(g, "main", "feat/history", 1)
+	if !strings.Contains(got, "Rename the API client") || strings.Contains(got, "Add readme") {
+		t.Errorf("expected only the last commit on main, got:\n%s", got)
+	}
+}
+
+func TestRecentCommits_Empty(t *testing.T) {
+	g := setupHistoryRepo(t)
+
+	if got := RecentCommits(g, "main", "feat/history", 0); got != "" {
+		t.Errorf("RecentCommits(n=0) = %q, want empty", got)
+	}
+	if got := RecentCommits(nil, "main", "feat/history", 5); got != "" {
+		t.Errorf("RecentCommits(nil git) = %q, want empty", got)
+	}
+	if got := RecentCommits(g, "no-such-branch", "", 5); got != "" {
+		t.Errorf("RecentCommits(unknown branch) = %q, want empty", got)
+	}
+
+	// Running on the base branch itself shows it once
+	got := RecentCommits(g, "feat/history", "feat/history", 5)
+	if strings.Count(got, "###") != 1 {
+		t.Errorf("expected a single history section, got:\n%s", got)
+	}
+}
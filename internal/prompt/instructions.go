@@ -0,0 +1,62 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// InstructionsFile is the project's standing instructions file in .ralph/.
+const InstructionsFile = "instructions.md"
+
+// MaxInstructionsSize caps each instructions file injected into the prompt,
+// so an oversized file can't crowd out the plan and task context.
+const MaxInstructionsSize = 16 * 1024
+
+// FormatInstructions renders the project and plan instructions as the
+// prompt's standing instructions section. Plan instructions come last and
+// win where the two conflict. Each is truncated to MaxInstructionsSize.
+// Returns an empty string if both are empty.
+func FormatInstructions(project, plan string) string {
+	project = truncateInstructions(".ralph/"+InstructionsFile, strings.TrimSpace(project))
+	plan = truncateInstructions("plan instructions", strings.TrimSpace(plan))
+	if project == "" && plan == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Standing Instructions\n\n")
+	sb.WriteString("Follow these instructions in every iteration.")
+	if project != "" && plan != "" {
+		sb.WriteString(" Where the plan instructions conflict with the project instructions, the plan instructions win.")
+	}
+	sb.WriteString("\n")
+	if project != "" {
+		sb.WriteString("\n### Project Instructions\n\n")
+		sb.WriteString(project)
+		sb.WriteString("\n")
+	}
+	if plan != "" {
+		sb.WriteString("\n### Plan Instructions\n\n")
+		sb.WriteString(plan)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// truncateInstructions cuts content to MaxInstructionsSize on a UTF-8
+// boundary and notes the truncation for both the user and the agent.
+func truncateInstructions(source, content string) string {
+	if len(content) <= MaxInstructionsSize {
+		return content
+	}
+	log.Warn("%s is %d bytes; only the first %d are included in the prompt", source, len(content), MaxInstructionsSize)
+
+	cut := MaxInstructionsSize
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut] + fmt.Sprintf("\n\n[Truncated: %s exceeds %d bytes]", source, MaxInstructionsSize)
+}
@@ -0,0 +1,89 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatInstructions_Empty(t *testing.T) {
+	if got := FormatInstructions("", "  \n"); got != "" {
+		t.Errorf("FormatInstructions() = %q, want empty", got)
+	}
+}
+
+func TestFormatInstructions_Precedence(t *testing.T) {
+	got := FormatInstructions("Use tabs.", "Use spaces.")
+
+	project := strings.Index(got, "### Project Instructions")
+	plan := strings.Index(got, "### Plan Instructions")
+	if project < 0 || plan < 0 || plan < project {
+		t.Fatalf("expected project then plan instructions, got:\n%s", got)
+	}
+	if !strings.Contains(got, "the plan instructions win") {
+		t.Errorf("expected precedence rule, got:\n%s", got)
+	}
+
+	// The precedence rule only matters when both are present
+	got = FormatInstructions("Use tabs.", "")
+	if strings.Contains(got, "Plan Instructions") || strings.Contains(got, "win") {
+		t.Errorf("unexpected plan section, got:\n%s", got)
+	}
+}
+
+func TestFormatInstructions_Truncates(t *testing.T) {
+	// Multi-byte runes straddle the limit
+	long := strings.Repeat("é", MaxInstructionsSize)
+	got := FormatInstructions(long, "")
+
+	if !strings.Contains(got, "[Truncated: .ralph/instructions.md exceeds") {
+		t.Errorf("expected truncation note")
+	}
+	if len(got) > MaxInstructionsSize+200 {
+		t.Errorf("formatted instructions are %d bytes", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Error("truncation split a UTF-8 sequence")
+	}
+}
+
+func TestBuilder_Build_Instructions(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".ralph")
+	promptsDir := filepath.Join(tempDir, "prompts")
+	os.MkdirAll(configDir, 0755)
+	os.MkdirAll(promptsDir, 0755)
+
+	os.WriteFile(filepath.Join(configDir, InstructionsFile), []byte("Never use panic.\n"), 0644)
+	os.WriteFile(filepath.Join(promptsDir, "test.md"), []byte("{{INSTRUCTIONS}}"), 0644)
+
+	builder := NewBuilder(nil, configDir, promptsDir)
+	result, err := builder.Build("test.md", map[string]string{"PLAN_INSTRUCTIONS": "Panics are fine in main."})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for _, want := range []string{"## Standing Instructions", "Never use panic.", "Panics are fine in main."} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in prompt, got:\n%s", want, result)
+		}
+	}
+
+	// Without instructions the placeholder disappears
+	os.Remove(filepath.Join(configDir, InstructionsFile))
+	result, err = builder.Build("test.md", nil)
+	if err != nil || result != "" {
+		t.Errorf("Build() without instructions = %q, %v", result, err)
+	}
+}
+
+func TestBuilder_Build_EmbeddedPromptHasInstructions(t *testing.T) {
+	content, err := loadEmbeddedPrompt("prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "{{INSTRUCTIONS}}") {
+		t.Error("prompt.md should include the {{INSTRUCTIONS}} placeholder")
+	}
+}
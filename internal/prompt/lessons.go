@@ -0,0 +1,90 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LessonsFile is the project's lessons file in .ralph/, appended to by the
+// retrospective run when a plan finishes (lessons.enabled).
+const LessonsFile = "lessons.md"
+
+// lessonsHeader starts a new lessons file.
+const lessonsHeader = `# Lessons
+
+Recurring gotchas ralph noted when plans in this repository finished. Edit or prune freely.
+`
+
+// LessonsPath returns the lessons file path for the given .ralph directory.
+func LessonsPath(configDir string) string {
+	return filepath.Join(configDir, LessonsFile)
+}
+
+// ReadLessons returns the lessons file's content, or "" if there is none.
+func ReadLessons(configDir string) string {
+	content, err := os.ReadFile(LessonsPath(configDir))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// AppendLessons appends a plan's lessons to the lessons file under a heading
+// naming the plan and its outcome, creating the file if needed. Does nothing
+// if lessons is empty.
+func AppendLessons(configDir, planName, outcome string, lessons []string, now time.Time) error {
+	if len(lessons) == 0 {
+		return nil
+	}
+
+	path := LessonsPath(configDir)
+	var sb strings.Builder
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		sb.WriteString(lessonsHeader)
+	}
+	sb.WriteString(fmt.Sprintf("\n## %s %s (%s)\n\n", now.Format("2006-01-02"), planName, outcome))
+	for _, lesson := range lessons {
+		sb.WriteString("- " + lesson + "\n")
+	}
+
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", configDir, err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening lessons file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return fmt.Errorf("writing lessons file: %w", err)
+	}
+	return nil
+}
+
+// FormatLessons renders the lessons file as the prompt's lessons section.
+// A file over MaxInstructionsSize keeps its most recent lessons. Returns an
+// empty string if lessons is empty.
+func FormatLessons(lessons string) string {
+	lessons = strings.TrimSpace(lessons)
+	if lessons == "" {
+		return ""
+	}
+	lessons = strings.TrimSpace(strings.TrimPrefix(lessons, "# Lessons"))
+	if len(lessons) > MaxInstructionsSize {
+		cut := len(lessons) - MaxInstructionsSize
+		if i := strings.Index(lessons[cut:], "\n## "); i >= 0 {
+			cut += i + 1
+		}
+		lessons = lessons[cut:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Lessons From Earlier Plans\n\n")
+	sb.WriteString("Earlier plans in this repository ran into these. Keep them in mind.\n\n")
+	sb.WriteString(lessons)
+	sb.WriteString("\n")
+	return sb.String()
+}
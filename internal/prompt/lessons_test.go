@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestAppendLessons(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".ralph")
+	day := time.Date(2024, 1, 30, 14, 0, 0, 0, time.UTC)
+
+	if err := AppendLessons(configDir, "nothing", "clean", nil, day); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(LessonsPath(configDir)); !os.IsNotExist(err) {
+		t.Error("AppendLessons() without lessons should not create the file")
+	}
+
+	if err := AppendLessons(configDir, "add-login", "needed-human-help", []string{"Run make generate first"}, day); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendLessons(configDir, "add-logout", "clean", []string{"Sessions live in internal/session"}, day); err != nil {
+		t.Fatal(err)
+	}
+
+	got := ReadLessons(configDir)
+	if strings.Count(got, "# Lessons\n") != 1 {
+		t.Errorf("expected one file header, got:\n%s", got)
+	}
+	for _, want := range []string{
+		"## 2024-01-30 add-login (needed-human-help)\n\n- Run make generate first\n",
+		"## 2024-01-30 add-logout (clean)\n\n- Sessions live in internal/session",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("lessons missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatLessons(t *testing.T) {
+	if got := FormatLessons("  \n"); got != "" {
+		t.Errorf("FormatLessons(blank) = %q, want empty", got)
+	}
+
+	got := FormatLessons(lessonsHeader + "\n## 2024-01-30 add-login (clean)\n\n- Run make generate first\n")
+	if !strings.HasPrefix(got, "## Lessons From Earlier Plans\n") || !strings.Contains(got, "- Run make generate first") {
+		t.Errorf("FormatLessons() = %q", got)
+	}
+
+	// An oversized file keeps its most recent lessons, cut at a heading
+	old := "\n## 2024-01-01 old (clean)\n\n- " + strings.Repeat("x", MaxInstructionsSize) + "\n"
+	got = FormatLessons(lessonsHeader + old + "\n## 2024-02-01 new (clean)\n\n- Recent\n")
+	if strings.Contains(got, "old (clean)") || !strings.Contains(got, "## 2024-02-01 new (clean)\n\n- Recent") {
+		t.Errorf("FormatLessons() should keep only the recent lessons, got %d bytes", len(got))
+	}
+}
+
+func TestBuilder_Build_Lessons(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, ".ralph")
+	promptsDir := filepath.Join(tempDir, "prompts")
+	os.MkdirAll(promptsDir, 0755)
+	os.WriteFile(filepath.Join(promptsDir, "test.md"), []byte("{{LESSONS}}"), 0644)
+	AppendLessons(configDir, "add-login", "clean", []string{"Run make generate first"}, time.Now())
+
+	cfg := &config.Config{}
+	builder := NewBuilder(cfg, configDir, promptsDir)
+	if result, err := builder.Build("test.md", nil); err != nil || result != "" {
+		t.Errorf("Build() without lessons.prompt = %q, %v", result, err)
+	}
+
+	cfg.Lessons.Prompt = true
+	result, err := builder.Build("test.md", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "- Run make generate first") {
+		t.Errorf("Build() with lessons.prompt = %q", result)
+	}
+}
+
+func TestBuilder_Build_EmbeddedPromptHasLessons(t *testing.T) {
+	content, err := loadEmbeddedPrompt("prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(content, "{{LESSONS}}") {
+		t.Error("prompt.md should include the {{LESSONS}} placeholder")
+	}
+}
@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// MaxProgressGotchas caps the gotchas in the progress summary section; the
+// most recent are kept.
+const MaxProgressGotchas = 10
+
+// ProgressSummary renders the prompt's summary of the progress file: the
+// latest next step and the gotchas recorded so far, so they aren't missed
+// in a long file. Returns an empty string if p has neither.
+func ProgressSummary(p *plan.Progress) string {
+	if p == nil {
+		return ""
+	}
+	next := p.NextStep()
+	gotchas := p.Gotchas()
+	if next == "" && len(gotchas) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Progress Summary\n\n")
+	fmt.Fprintf(&sb, "From the progress file (%d iterations logged). Read the file for details.\n", len(p.Entries))
+	if next != "" {
+		fmt.Fprintf(&sb, "\n**Next:** %s\n", next)
+	}
+	if len(gotchas) > MaxProgressGotchas {
+		gotchas = gotchas[len(gotchas)-MaxProgressGotchas:]
+	}
+	if len(gotchas) > 0 {
+		sb.WriteString("\n**Gotchas so far:**\n")
+		for _, g := range gotchas {
+			fmt.Fprintf(&sb, "- %s\n", g)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestProgressSummary(t *testing.T) {
+	p := &plan.Progress{Entries: []plan.ProgressEntry{
+		{Iteration: 1, Gotchas: []string{"Cobra caches flags"}, Next: "T2"},
+		{Iteration: 2, Next: "T3 - Docs"},
+		{Iteration: 3},
+	}}
+
+	got := ProgressSummary(p)
+	for _, want := range []string{"## Progress Summary", "3 iterations logged", "**Next:** T3 - Docs", "- Cobra caches flags"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestProgressSummary_Empty(t *testing.T) {
+	if got := ProgressSummary(nil); got != "" {
+		t.Errorf("ProgressSummary(nil) = %q", got)
+	}
+	if got := ProgressSummary(&plan.Progress{Entries: []plan.ProgressEntry{{Iteration: 1}}}); got != "" {
+		t.Errorf("ProgressSummary() without next or gotchas = %q", got)
+	}
+}
+
+func TestProgressSummary_CapsGotchas(t *testing.T) {
+	var gotchas []string
+	for i := 1; i <= MaxProgressGotchas+2; i++ {
+		gotchas = append(gotchas, fmt.Sprintf("gotcha %d", i))
+	}
+	got := ProgressSummary(&plan.Progress{Entries: []plan.ProgressEntry{{Iteration: 1, Gotchas: gotchas}}})
+	if strings.Contains(got, "- gotcha 2\n") || !strings.Contains(got, "- gotcha 3\n") || !strings.Contains(got, fmt.Sprintf("- gotcha %d\n", MaxProgressGotchas+2)) {
+		t.Errorf("summary should keep the last %d gotchas:\n%s", MaxProgressGotchas, got)
+	}
+}
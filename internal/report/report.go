@@ -0,0 +1,329 @@
+// Package report aggregates per-plan statistics from the events log into
+// markdown or HTML reports, to help tune max_iterations and prompt templates.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+// Plan outcomes.
+const (
+	OutcomeCompleted  = "completed"
+	OutcomeFailed     = "failed"
+	OutcomeInProgress = "in progress"
+	OutcomeAbandoned  = "abandoned"
+)
+
+// PlanStats holds aggregated statistics for one plan.
+type PlanStats struct {
+	// Name is the plan name.
+	Name string
+
+	// Outcome is the plan's latest outcome (see Outcome* constants).
+	Outcome string
+
+	// Iterations is the number of iterations run.
+	Iterations int
+
+	// MaxIterations is the iteration limit the plan ran with.
+	MaxIterations int
+
+	// InputTokens and OutputTokens are the total token counts.
+	InputTokens  int
+	OutputTokens int
+
+	// WallTime is the total iteration wall time.
+	WallTime time.Duration
+
+	// VerificationFailures is the number of completion claims that failed verification.
+	VerificationFailures int
+
+	// Blockers is the number of blockers reported.
+	Blockers int
+
+	// Errors is the number of plan errors.
+	Errors int
+
+	// FirstSeen and LastSeen bound the plan's activity.
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// Gotchas are the gotchas from the plan's progress file, if it was
+	// found (see AddGotchas).
+	Gotchas []string
+}
+
+// Report is the aggregated report over a time window.
+type Report struct {
+	// Since is the start of the window (zero means all history).
+	Since time.Time
+
+	// Generated is when the report was built.
+	Generated time.Time
+
+	// Plans holds per-plan statistics, most recently active first.
+	Plans []*PlanStats
+}
+
+// Build aggregates events after since into a report.
+func Build(evs []events.Event, since, now time.Time) *Report {
+	byName := make(map[string]*PlanStats)
+
+	for _, e := range evs {
+		if e.Plan == "" || (!since.IsZero() && !e.Time.After(since)) {
+			continue
+		}
+
+		s, ok := byName[e.Plan]
+		if !ok {
+			s = &PlanStats{Name: e.Plan, Outcome: OutcomeInProgress, FirstSeen: e.Time}
+			byName[e.Plan] = s
+		}
+		if e.Time.After(s.LastSeen) {
+			s.LastSeen = e.Time
+		}
+
+		switch e.Type {
+		case events.TypeIteration:
+			s.Iterations++
+			s.WallTime += e.Duration
+			s.InputTokens += e.InputTokens
+			s.OutputTokens += e.OutputTokens
+			if e.MaxIterations > 0 {
+				s.MaxIterations = e.MaxIterations
+			}
+		case events.TypeVerificationFailed:
+			s.VerificationFailures++
+		case events.TypeBlocker:
+			s.Blockers++
+		case events.TypePlanError:
+			s.Errors++
+			s.Outcome = OutcomeFailed
+		case events.TypePlanFailed:
+			s.Outcome = OutcomeFailed
+		case events.TypePlanRetry:
+			s.Errors++
+		case events.TypePlanCompleted:
+			s.Outcome = OutcomeCompleted
+		case events.TypePlanAbandoned:
+			s.Outcome = OutcomeAbandoned
+		case events.TypePlanStarted:
+			if s.Outcome != OutcomeCompleted {
+				s.Outcome = OutcomeInProgress
+			}
+		}
+	}
+
+	r := &Report{Since: since, Generated: now}
+	for _, s := range byName {
+		r.Plans = append(r.Plans, s)
+	}
+	sort.Slice(r.Plans, func(i, j int) bool {
+		if !r.Plans[i].LastSeen.Equal(r.Plans[j].LastSeen) {
+			return r.Plans[i].LastSeen.After(r.Plans[j].LastSeen)
+		}
+		return r.Plans[i].Name < r.Plans[j].Name
+	})
+	return r
+}
+
+// Summary holds totals across all plans in a report.
+type Summary struct {
+	Plans                int
+	Completed            int
+	Failed               int
+	Iterations           int
+	InputTokens          int
+	OutputTokens         int
+	WallTime             time.Duration
+	VerificationFailures int
+	Blockers             int
+
+	// AvgIterationsToComplete is the mean iteration count of completed plans.
+	AvgIterationsToComplete float64
+
+	// MaxIterationsToComplete is the highest iteration count of a completed plan.
+	MaxIterationsToComplete int
+}
+
+// Summary computes totals across all plans.
+func (r *Report) Summary() Summary {
+	var sum Summary
+	var completedIterations int
+
+	for _, s := range r.Plans {
+		sum.Plans++
+		sum.Iterations += s.Iterations
+		sum.InputTokens += s.InputTokens
+		sum.OutputTokens += s.OutputTokens
+		sum.WallTime += s.WallTime
+		sum.VerificationFailures += s.VerificationFailures
+		sum.Blockers += s.Blockers
+
+		switch s.Outcome {
+		case OutcomeCompleted:
+			sum.Completed++
+			completedIterations += s.Iterations
+			if s.Iterations > sum.MaxIterationsToComplete {
+				sum.MaxIterationsToComplete = s.Iterations
+			}
+		case OutcomeFailed:
+			sum.Failed++
+		}
+	}
+
+	if sum.Completed > 0 {
+		sum.AvgIterationsToComplete = float64(completedIterations) / float64(sum.Completed)
+	}
+	return sum
+}
+
+// AddGotchas sets the gotchas of the named plan. Plans not in the report
+// are ignored.
+func (r *Report) AddGotchas(name string, gotchas []string) {
+	for _, s := range r.Plans {
+		if s.Name == name {
+			s.Gotchas = gotchas
+		}
+	}
+}
+
+// hasGotchas reports whether any plan has gotchas.
+func (r *Report) hasGotchas() bool {
+	for _, s := range r.Plans {
+		if len(s.Gotchas) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteMarkdown writes the report as a markdown document.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	sum := r.Summary()
+
+	var sb strings.Builder
+	sb.WriteString("# Ralph Report\n\n")
+	sb.WriteString(fmt.Sprintf("%s, generated %s\n\n", r.window(), r.Generated.Format("2006-01-02 15:04")))
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(fmt.Sprintf("- Plans: %d (%d completed, %d failed)\n", sum.Plans, sum.Completed, sum.Failed))
+	sb.WriteString(fmt.Sprintf("- Iterations: %d\n", sum.Iterations))
+	if sum.Completed > 0 {
+		sb.WriteString(fmt.Sprintf("- Iterations to complete: avg %.1f, max %d\n", sum.AvgIterationsToComplete, sum.MaxIterationsToComplete))
+	}
+	sb.WriteString(fmt.Sprintf("- Tokens: %d in / %d out\n", sum.InputTokens, sum.OutputTokens))
+	sb.WriteString(fmt.Sprintf("- Wall time: %s\n", formatDuration(sum.WallTime)))
+	sb.WriteString(fmt.Sprintf("- Verification failures: %d\n", sum.VerificationFailures))
+	sb.WriteString(fmt.Sprintf("- Blockers: %d\n", sum.Blockers))
+
+	sb.WriteString("\n## Plans\n\n")
+	if len(r.Plans) == 0 {
+		sb.WriteString("No plan activity recorded.\n")
+	} else {
+		sb.WriteString("| Plan | Outcome | Iterations | Tokens (in/out) | Wall time | Verification failures | Blockers |\n")
+		sb.WriteString("|------|---------|------------|-----------------|-----------|-----------------------|----------|\n")
+		for _, s := range r.Plans {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d/%d | %s | %d | %d |\n",
+				s.Name, s.Outcome, s.IterationsUsed(), s.InputTokens, s.OutputTokens,
+				formatDuration(s.WallTime), s.VerificationFailures, s.Blockers))
+		}
+	}
+
+	if r.hasGotchas() {
+		sb.WriteString("\n## Gotchas\n")
+		for _, s := range r.Plans {
+			if len(s.Gotchas) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", s.Name))
+			for _, g := range s.Gotchas {
+				sb.WriteString(fmt.Sprintf("- %s\n", g))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// htmlTemplate renders the report as a standalone HTML page.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Ralph Report</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f4f4f4; }
+</style>
+</head>
+<body>
+<h1>Ralph Report</h1>
+<p>{{.Window}}, generated {{.Report.Generated.Format "2006-01-02 15:04"}}</p>
+<h2>Summary</h2>
+<ul>
+<li>Plans: {{.Summary.Plans}} ({{.Summary.Completed}} completed, {{.Summary.Failed}} failed)</li>
+<li>Iterations: {{.Summary.Iterations}}</li>
+{{if .Summary.Completed}}<li>Iterations to complete: avg {{printf "%.1f" .Summary.AvgIterationsToComplete}}, max {{.Summary.MaxIterationsToComplete}}</li>
+{{end}}<li>Tokens: {{.Summary.InputTokens}} in / {{.Summary.OutputTokens}} out</li>
+<li>Wall time: {{duration .Summary.WallTime}}</li>
+<li>Verification failures: {{.Summary.VerificationFailures}}</li>
+<li>Blockers: {{.Summary.Blockers}}</li>
+</ul>
+<h2>Plans</h2>
+{{if .Report.Plans}}<table>
+<tr><th>Plan</th><th>Outcome</th><th>Iterations</th><th>Tokens (in/out)</th><th>Wall time</th><th>Verification failures</th><th>Blockers</th></tr>
+{{range .Report.Plans}}<tr><td>{{.Name}}</td><td>{{.Outcome}}</td><td>{{.IterationsUsed}}</td><td>{{.InputTokens}}/{{.OutputTokens}}</td><td>{{duration .WallTime}}</td><td>{{.VerificationFailures}}</td><td>{{.Blockers}}</td></tr>
+{{end}}</table>
+{{else}}<p>No plan activity recorded.</p>
+{{end}}{{if .Gotchas}}<h2>Gotchas</h2>
+{{range .Report.Plans}}{{if .Gotchas}}<h3>{{.Name}}</h3>
+<ul>
+{{range .Gotchas}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML page.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, struct {
+		Report  *Report
+		Summary Summary
+		Window  string
+		Gotchas bool
+	}{r, r.Summary(), r.window(), r.hasGotchas()})
+}
+
+// IterationsUsed formats iterations used against the limit, e.g. "12/30".
+func (s *PlanStats) IterationsUsed() string {
+	if s.MaxIterations == 0 {
+		return fmt.Sprintf("%d", s.Iterations)
+	}
+	return fmt.Sprintf("%d/%d", s.Iterations, s.MaxIterations)
+}
+
+// window describes the report's time window.
+func (r *Report) window() string {
+	if r.Since.IsZero() {
+		return "All history"
+	}
+	return fmt.Sprintf("Since %s", r.Since.Format("2006-01-02 15:04"))
+}
+
+// formatDuration formats a duration rounded to the second.
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
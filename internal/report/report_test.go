@@ -0,0 +1,184 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func sampleEvents(base time.Time) []events.Event {
+	return []events.Event{
+		{Time: base.Add(-48 * time.Hour), Type: events.TypeIteration, Plan: "ancient", Duration: time.Hour},
+		{Time: base, Type: events.TypePlanStarted, Plan: "alpha"},
+		{Time: base.Add(time.Minute), Type: events.TypeIteration, Plan: "alpha", MaxIterations: 30, Duration: 10 * time.Minute, InputTokens: 1000, OutputTokens: 200},
+		{Time: base.Add(2 * time.Minute), Type: events.TypeVerificationFailed, Plan: "alpha", Message: "Task 2 unchecked"},
+		{Time: base.Add(3 * time.Minute), Type: events.TypeIteration, Plan: "alpha", MaxIterations: 30, Duration: 20 * time.Minute, InputTokens: 500, OutputTokens: 100},
+		{Time: base.Add(4 * time.Minute), Type: events.TypePlanCompleted, Plan: "alpha", PRURL: "https://github.com/o/r/pull/1"},
+		{Time: base.Add(5 * time.Minute), Type: events.TypePlanStarted, Plan: "beta"},
+		{Time: base.Add(6 * time.Minute), Type: events.TypeBlocker, Plan: "beta", Message: "need creds"},
+		{Time: base.Add(7 * time.Minute), Type: events.TypePlanError, Plan: "beta", Message: "max iterations"},
+		{Time: base.Add(8 * time.Minute), Type: events.TypeDigestSent},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build(sampleEvents(base), base.Add(-time.Hour), base.Add(time.Hour))
+
+	if len(r.Plans) != 2 {
+		t.Fatalf("len(Plans) = %d, want 2 (old and unnamed events excluded)", len(r.Plans))
+	}
+
+	// Most recently active first
+	beta, alpha := r.Plans[0], r.Plans[1]
+	if beta.Name != "beta" || alpha.Name != "alpha" {
+		t.Fatalf("order = %s, %s", r.Plans[0].Name, r.Plans[1].Name)
+	}
+
+	if alpha.Outcome != OutcomeCompleted || alpha.Iterations != 2 || alpha.MaxIterations != 30 {
+		t.Errorf("alpha = %+v", alpha)
+	}
+	if alpha.InputTokens != 1500 || alpha.OutputTokens != 300 || alpha.WallTime != 30*time.Minute {
+		t.Errorf("alpha totals = %+v", alpha)
+	}
+	if alpha.VerificationFailures != 1 {
+		t.Errorf("alpha.VerificationFailures = %d, want 1", alpha.VerificationFailures)
+	}
+	if beta.Outcome != OutcomeFailed || beta.Blockers != 1 || beta.Errors != 1 {
+		t.Errorf("beta = %+v", beta)
+	}
+}
+
+func TestBuild_Abandoned(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build([]events.Event{
+		{Time: base, Type: events.TypeIteration, Plan: "gamma"},
+		{Time: base.Add(time.Minute), Type: events.TypePlanAbandoned, Plan: "gamma", Message: "superseded"},
+	}, time.Time{}, base)
+
+	if len(r.Plans) != 1 || r.Plans[0].Outcome != OutcomeAbandoned {
+		t.Errorf("plans = %+v, want gamma abandoned", r.Plans)
+	}
+}
+
+func TestBuild_Failed(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build([]events.Event{
+		{Time: base, Type: events.TypeIteration, Plan: "delta"},
+		{Time: base.Add(time.Minute), Type: events.TypePlanFailed, Plan: "delta", Message: "max iterations"},
+	}, time.Time{}, base)
+
+	if len(r.Plans) != 1 || r.Plans[0].Outcome != OutcomeFailed || r.Plans[0].Errors != 0 {
+		t.Errorf("plans = %+v, want delta failed without an error count", r.Plans)
+	}
+}
+
+func TestBuild_PlanRetry(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build([]events.Event{
+		{Time: base, Type: events.TypePlanStarted, Plan: "echo"},
+		{Time: base.Add(time.Minute), Type: events.TypePlanRetry, Plan: "echo", Message: "retry 1/3 in 5m0s: rate limit"},
+	}, time.Time{}, base)
+
+	if len(r.Plans) != 1 || r.Plans[0].Outcome != OutcomeInProgress || r.Plans[0].Errors != 1 {
+		t.Errorf("plans = %+v, want echo in progress with one error", r.Plans)
+	}
+}
+
+func TestReport_Summary(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	sum := Build(sampleEvents(base), time.Time{}, base).Summary()
+
+	if sum.Plans != 3 || sum.Completed != 1 || sum.Failed != 1 {
+		t.Errorf("summary counts = %+v", sum)
+	}
+	if sum.AvgIterationsToComplete != 2 || sum.MaxIterationsToComplete != 2 {
+		t.Errorf("iterations to complete = %v/%d", sum.AvgIterationsToComplete, sum.MaxIterationsToComplete)
+	}
+	if sum.Iterations != 3 || sum.Blockers != 1 || sum.VerificationFailures != 1 {
+		t.Errorf("summary totals = %+v", sum)
+	}
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build(sampleEvents(base), base.Add(-time.Hour), base.Add(time.Hour))
+
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# Ralph Report",
+		"Since 2024-01-30 11:00",
+		"- Plans: 2 (1 completed, 1 failed)",
+		"- Iterations to complete: avg 2.0, max 2",
+		"| alpha | completed | 2/30 | 1500/300 | 30m0s | 1 | 0 |",
+		"| beta | failed | 0 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReport_WriteHTML(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	evs := append(sampleEvents(base), events.Event{Time: base.Add(time.Minute), Type: events.TypePlanStarted, Plan: "<script>"})
+	r := Build(evs, time.Time{}, base)
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"<h1>Ralph Report</h1>", "All history", "<td>alpha</td><td>completed</td><td>2/30</td>", "&lt;script&gt;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReport_Gotchas(t *testing.T) {
+	base := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	r := Build(sampleEvents(base), base.Add(-time.Hour), base.Add(time.Hour))
+
+	var buf bytes.Buffer
+	r.WriteMarkdown(&buf)
+	if strings.Contains(buf.String(), "## Gotchas") {
+		t.Error("markdown should have no Gotchas section without gotchas")
+	}
+
+	r.AddGotchas("alpha", []string{"Cobra caches flags"})
+	r.AddGotchas("missing", []string{"ignored"})
+
+	buf.Reset()
+	r.WriteMarkdown(&buf)
+	if !strings.Contains(buf.String(), "## Gotchas\n\n### alpha\n\n- Cobra caches flags\n") || strings.Contains(buf.String(), "ignored") {
+		t.Errorf("markdown gotchas:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	r.WriteHTML(&buf)
+	if !strings.Contains(buf.String(), "<h3>alpha</h3>\n<ul>\n<li>Cobra caches flags</li>") {
+		t.Errorf("html gotchas:\n%s", buf.String())
+	}
+}
+
+func TestReport_Empty(t *testing.T) {
+	r := Build(nil, time.Time{}, time.Now())
+
+	var md, html bytes.Buffer
+	r.WriteMarkdown(&md)
+	r.WriteHTML(&html)
+
+	if !strings.Contains(md.String(), "No plan activity recorded.") || !strings.Contains(html.String(), "No plan activity recorded.") {
+		t.Error("empty report should say there is no activity")
+	}
+}
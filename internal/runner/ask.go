@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// askTagRegex matches <ask>...</ask> content.
+var askTagRegex = regexp.MustCompile(`(?s)<ask>(.*?)</ask>`)
+
+// DefaultAskTimeout is how long the loop waits for an answer to an <ask>
+// question when ask.timeout is unset.
+const DefaultAskTimeout = 30 * time.Minute
+
+// askPollInterval is how often a loop waiting for an answer re-reads the
+// feedback file.
+var askPollInterval = 5 * time.Second
+
+// ExtractQuestion returns the question in an <ask> marker in Claude output.
+// Returns "" if no ask tag is found.
+func ExtractQuestion(output string) string {
+	matches := askTagRegex.FindStringSubmatch(output)
+	if matches == nil {
+		return ""
+	}
+	return strings.TrimSpace(matches[1])
+}
+
+// Answer is the human's reply to a question the agent asked with <ask>.
+type Answer struct {
+	// Question is the question as the agent asked it.
+	Question string
+
+	// Entry is the feedback entry that answered it; nil if none arrived
+	// within ask.timeout.
+	Entry *plan.FeedbackEntry
+
+	// Waited is how long the loop waited for the answer.
+	Waited time.Duration
+}
+
+// askHuman sends the agent's question and waits up to ask.timeout for a new
+// feedback entry, such as a reply in the plan's Slack thread or
+// `ralph feedback`. Returns nil if the loop shouldn't wait (timeout "0") or
+// the wait was cut short by cancellation or a stop request.
+func (l *IterationLoop) askHuman(ctx context.Context, question string) *Answer {
+	timeout := l.askTimeout()
+
+	// Entries already pending aren't answers to this question
+	seen := make(map[string]bool)
+	if fb, err := plan.LoadFeedback(l.plan); err == nil {
+		for _, e := range fb.Pending {
+			seen[e.ID+e.Text] = true
+		}
+	}
+
+	log.Info("Agent asked: %s", question)
+	if l.onAsk != nil {
+		l.onAsk(question, timeout)
+	}
+	if timeout <= 0 {
+		return nil
+	}
+
+	log.Info("Waiting up to %v for an answer...", timeout)
+	start := time.Now()
+	deadline := time.After(timeout)
+	for {
+		if fb, err := plan.LoadFeedback(l.plan); err == nil {
+			for _, e := range fb.Pending {
+				if !seen[e.ID+e.Text] {
+					entry := e
+					log.Info("Question answered after %v", time.Since(start).Round(time.Second))
+					return &Answer{Question: question, Entry: &entry, Waited: time.Since(start)}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-l.stop:
+			return nil
+		case <-deadline:
+			log.Warn("No answer within %v, continuing without one", timeout)
+			return &Answer{Question: question, Waited: timeout}
+		case <-time.After(askPollInterval):
+		}
+	}
+}
+
+// askTimeout returns ask.timeout, defaulting to DefaultAskTimeout when it's
+// unset or invalid.
+func (l *IterationLoop) askTimeout() time.Duration {
+	if l.config == nil || l.config.Ask.Timeout == "" {
+		return DefaultAskTimeout
+	}
+	d, err := time.ParseDuration(l.config.Ask.Timeout)
+	if err != nil || d < 0 {
+		return DefaultAskTimeout
+	}
+	return d
+}
+
+// answerSection returns the prompt section that hands the answer to the
+// agent's last question to the next iteration, or "" if there is none.
+func (l *IterationLoop) answerSection() string {
+	a := l.answer
+	if a == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Answer to Your Question\n\n")
+	sb.WriteString(fmt.Sprintf("Last iteration you asked:\n> %s\n\n", strings.ReplaceAll(a.Question, "\n", "\n> ")))
+	if a.Entry == nil {
+		sb.WriteString(fmt.Sprintf("No answer arrived within %v. Continue with your best judgment and note the assumption you made in the progress file.\n", a.Waited.Round(time.Second)))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("The answer (feedback entry [%s]):\n> %s\n\n", a.Entry.ID, strings.ReplaceAll(a.Entry.Text, "\n", "\n> ")))
+	sb.WriteString("Act on it, then acknowledge the entry with a `<feedback-ack>` marker as usual.\n")
+	return sb.String()
+}
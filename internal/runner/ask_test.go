@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestExtractQuestion(t *testing.T) {
+	tests := map[string]string{
+		"no marker":                             "",
+		"<ask></ask>":                           "",
+		"Done.\n<ask>\nREST or gRPC?\n</ask>\n": "REST or gRPC?",
+		"<ask>First?</ask> <ask>Second?</ask>":  "First?",
+	}
+	for output, want := range tests {
+		if got := ExtractQuestion(output); got != want {
+			t.Errorf("ExtractQuestion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+// askLoop returns a loop for a plan with a feedback file and an entry
+// already pending, recording the questions it asks.
+func askLoop(t *testing.T, timeout string) (*IterationLoop, *[]string) {
+	t.Helper()
+	oldInterval := askPollInterval
+	askPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { askPollInterval = oldInterval })
+
+	p := &plan.Plan{Name: "my-plan", Path: filepath.Join(t.TempDir(), "my-plan.md")}
+	if err := plan.AppendFeedback(p, "Earlier", "Old news"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Ask.Timeout = timeout
+	var asked []string
+	l := &IterationLoop{plan: p, config: cfg, onAsk: func(question string, timeout time.Duration) {
+		asked = append(asked, question)
+	}}
+	return l, &asked
+}
+
+func TestIterationLoop_AskHuman(t *testing.T) {
+	l, asked := askLoop(t, "5s")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		plan.AppendFeedback(l.plan, plan.FeedbackSource("Slack reply", "Alice"), "Return 404")
+	}()
+
+	answer := l.askHuman(context.Background(), "404 or empty list?")
+	if len(*asked) != 1 || (*asked)[0] != "404 or empty list?" {
+		t.Errorf("asked %q, want the question once", *asked)
+	}
+	if answer == nil || answer.Entry == nil {
+		t.Fatalf("askHuman() = %+v, want the new feedback entry", answer)
+	}
+	if answer.Entry.Author != "Alice" || !strings.Contains(answer.Entry.Text, "Return 404") {
+		t.Errorf("answer entry = %+v", answer.Entry)
+	}
+
+	l.answer = answer
+	section := l.answerSection()
+	for _, want := range []string{"## Answer to Your Question", "> 404 or empty list?", "Return 404", "[" + answer.Entry.ID + "]", "<feedback-ack>"} {
+		if !strings.Contains(section, want) {
+			t.Errorf("answerSection() missing %q:\n%s", want, section)
+		}
+	}
+}
+
+func TestIterationLoop_AskHuman_Timeout(t *testing.T) {
+	l, _ := askLoop(t, "50ms")
+
+	answer := l.askHuman(context.Background(), "Which color?")
+	if answer == nil || answer.Entry != nil {
+		t.Fatalf("askHuman() = %+v, want an unanswered question", answer)
+	}
+
+	l.answer = answer
+	if section := l.answerSection(); !strings.Contains(section, "No answer arrived within") {
+		t.Errorf("answerSection() = %q, want the missing answer noted", section)
+	}
+}
+
+func TestIterationLoop_AskHuman_NoWait(t *testing.T) {
+	l, asked := askLoop(t, "0")
+
+	if answer := l.askHuman(context.Background(), "Which color?"); answer != nil || len(*asked) != 1 {
+		t.Errorf("askHuman() = %+v after asking %d time(s), want the question sent without waiting", answer, len(*asked))
+	}
+
+	// Cancellation cuts the wait short
+	l.config.Ask.Timeout = "1h"
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if answer := l.askHuman(ctx, "Which color?"); answer != nil {
+		t.Errorf("askHuman() = %+v after cancellation, want nil", answer)
+	}
+
+	if section := (&IterationLoop{}).answerSection(); section != "" {
+		t.Errorf("answerSection() without an answer = %q", section)
+	}
+}
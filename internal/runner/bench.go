@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/bench"
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// benchCommand returns bench.command, or "" if it isn't configured.
+func (l *IterationLoop) benchCommand() string {
+	if l.config == nil {
+		return ""
+	}
+	return strings.TrimSpace(l.config.Bench.Command)
+}
+
+// measureBenchBase runs the benchmarks before the first iteration, on the
+// base branch, for comparison when the plan claims completion.
+func (l *IterationLoop) measureBenchBase(ctx context.Context) {
+	command := l.benchCommand()
+	if command == "" {
+		return
+	}
+	results, err := bench.Run(ctx, l.worktreePath, command)
+	if err != nil {
+		log.Warn("Base benchmarks not measured: %v", err)
+		return
+	}
+	l.ctx.BenchBase = results
+	log.Info("Measured %d base benchmarks", len(results))
+}
+
+// benchAllowsCompletion runs the benchmarks on the plan's branch and
+// compares them with the base branch. Regressions beyond bench.threshold
+// are written to the feedback file and reported; with bench.action "block"
+// the completion claim is rejected.
+func (l *IterationLoop) benchAllowsCompletion(ctx context.Context) bool {
+	command := l.benchCommand()
+	if command == "" {
+		return true
+	}
+	if l.ctx.BenchBase == nil {
+		log.Warn("Skipping benchmark comparison: the base branch wasn't measured")
+		return true
+	}
+
+	results, err := bench.Run(ctx, l.worktreePath, command)
+	if err != nil {
+		log.Warn("Benchmarks not measured: %v", err)
+		return true
+	}
+	deltas := bench.Compare(l.ctx.BenchBase, results)
+	result := bench.Gate(command, deltas, l.config.Bench.Threshold)
+	l.benchGate = &result
+	if result.Passed {
+		log.Success("Gate bench passed: %s", result.Output)
+		return true
+	}
+
+	regressions := bench.Regressions(deltas, l.config.Bench.Threshold)
+	log.Warn("Benchmark regressions beyond %g%%:\n%s", l.config.Bench.Threshold, result.Output)
+	if l.onBenchRegression != nil {
+		l.onBenchRegression(regressions)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Benchmark regressions (more than %g%% worse than the base branch):**\n", l.config.Bench.Threshold)
+	for _, d := range regressions {
+		fmt.Fprintf(&b, "- %s\n", d)
+	}
+	block := l.config.Bench.Action == config.BenchBlock
+	if block {
+		b.WriteString("The plan can't complete until these regressions are fixed.")
+	} else {
+		b.WriteString("Investigate these regressions before the pull request is reviewed.")
+	}
+	if err := plan.AppendFeedback(l.plan, "bench", b.String()); err != nil {
+		log.Error("Failed to write benchmark feedback: %v", err)
+	}
+	return !block
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/bench"
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// slowerBenchCommand prints a benchmark that takes 100 ns/op on the base
+// branch and 200 ns/op once an iteration ran.
+const slowerBenchCommand = "if test -f plans/current/test-plan.progress.md; then echo 'BenchmarkX-8 10 200 ns/op'; else echo 'BenchmarkX-8 10 100 ns/op'; fi"
+
+func TestIterationLoop_Run_BenchFeedback(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Done <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "YES"},
+		},
+	}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+	loop.config.Bench.Command = slowerBenchCommand
+
+	var regressions []bench.Delta
+	loop.onBenchRegression = func(r []bench.Delta) { regressions = r }
+
+	result := loop.Run(context.Background())
+
+	if !result.Completed {
+		t.Fatalf("Run() error = %v, want completed despite the regression", result.Error)
+	}
+	if result.Bench == nil || result.Bench.Passed || !strings.Contains(result.Bench.Output, "BenchmarkX ns/op: 100 → 200 (+100.0%)") {
+		t.Errorf("result.Bench = %+v", result.Bench)
+	}
+	if len(regressions) != 1 {
+		t.Errorf("regressions = %v, want BenchmarkX", regressions)
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "Benchmark regressions") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestIterationLoop_Run_BenchBlock(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Done <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "YES"},
+		},
+	}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+	loop.ctx.MaxIterations = 1
+	loop.config.Bench = config.BenchConfig{Command: slowerBenchCommand, Threshold: 50, Action: config.BenchBlock}
+
+	result := loop.Run(context.Background())
+
+	if result.Completed || !errors.Is(result.Error, ErrMaxIterations) {
+		t.Fatalf("Run() = %+v, want the completion claim rejected", result)
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "can't complete until these regressions are fixed") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestIterationLoop_BenchAllowsCompletion_NoBase(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	if !loop.benchAllowsCompletion(context.Background()) {
+		t.Error("benchAllowsCompletion() without bench.command should be true")
+	}
+
+	loop.config.Bench = config.BenchConfig{Command: "echo 'BenchmarkX 1 5 ns/op'", Action: config.BenchBlock}
+	if !loop.benchAllowsCompletion(context.Background()) || loop.benchGate != nil {
+		t.Error("benchAllowsCompletion() without a base measurement should skip the comparison")
+	}
+}
@@ -41,6 +41,18 @@ func ExtractBlocker(output string) *Blocker {
 	return blocker
 }
 
+// NewBlocker returns a blocker raised by ralph itself rather than the agent,
+// e.g. for a worktree that needs a human.
+func NewBlocker(description, action string) *Blocker {
+	content := "Description: " + description + "\nAction: " + action
+	return &Blocker{
+		Content:     content,
+		Description: description,
+		Action:      action,
+		Hash:        computeBlockerHash(content),
+	}
+}
+
 // parseBlockerFields extracts Description, Action, and Resume fields from content.
 // If the content doesn't have explicit fields, the entire content is used as Description.
 func parseBlockerFields(content string) (description, action, resume string) {
@@ -209,6 +209,14 @@ RESUME: After it's done
 	}
 }
 
+func TestNewBlocker(t *testing.T) {
+	b := NewBlocker("rebase in progress", "Run `git rebase --abort`")
+	parsed := ExtractBlocker("<blocker>" + b.Content + "</blocker>")
+	if parsed == nil || *parsed != *b {
+		t.Errorf("NewBlocker() = %+v, want it to parse back as %+v", b, parsed)
+	}
+}
+
 func TestExtractBlocker_Hash(t *testing.T) {
 	output1 := "<blocker>Content A</blocker>"
 	output2 := "<blocker>Content B</blocker>"
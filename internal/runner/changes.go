@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// recordChanges adds the files changed between the checkpoint's HeadBefore
+// and Commit to the plan's changes ledger. Failures are logged.
+func (l *IterationLoop) recordChanges(cp *Checkpoint) {
+	if l.git == nil || cp.Commit == "" || cp.Commit == cp.HeadBefore {
+		return
+	}
+
+	diff, err := l.git.DiffFiles(cp.HeadBefore, cp.Commit)
+	if err != nil {
+		log.Warn("Failed to diff iteration %d for the changes ledger: %v", cp.Iteration, err)
+		return
+	}
+	if err := plan.RecordChanges(l.plan, cp.Iteration, l.ledgerChanges(diff)); err != nil {
+		log.Warn("Failed to record changes: %v", err)
+	}
+}
+
+// auditCommits records the commits of the checkpoint's iteration, between
+// HeadBefore and Commit, in the audit log. Failures are logged.
+func (l *IterationLoop) auditCommits(cp *Checkpoint) {
+	if l.audit == nil || cp.Commit == "" || cp.Commit == cp.HeadBefore {
+		return
+	}
+	err := l.audit.Record(audit.Entry{
+		Action: audit.ActionCommit,
+		Plan:   l.plan.Name,
+		Details: map[string]string{
+			"branch":    l.ctx.FeatureBranch,
+			"from":      cp.HeadBefore,
+			"to":        cp.Commit,
+			"iteration": strconv.Itoa(cp.Iteration),
+		},
+	})
+	if err != nil {
+		log.Warn("Failed to record audit entry: %v", err)
+	}
+}
+
+// ledgerChanges converts a git diff into ledger entries. A rename counts as
+// deleting the old path and creating the new one, which carries the rename's
+// line counts. Ralph's own files are
+// left out.
+func (l *IterationLoop) ledgerChanges(diff []git.FileChange) []plan.FileChange {
+	var changes []plan.FileChange
+	add := func(p, change string, added, deleted int) {
+		if !l.isRalphFile(p) {
+			changes = append(changes, plan.FileChange{Path: p, Change: change, Added: added, Deleted: deleted})
+		}
+	}
+
+	for _, f := range diff {
+		switch f.Status {
+		case "A", "C":
+			add(f.Path, plan.ChangeCreated, f.Added, f.Deleted)
+		case "D":
+			add(f.Path, plan.ChangeDeleted, f.Added, f.Deleted)
+		case "R":
+			add(f.OldPath, plan.ChangeDeleted, 0, 0)
+			add(f.Path, plan.ChangeCreated, f.Added, f.Deleted)
+		default:
+			add(f.Path, plan.ChangeModified, f.Added, f.Deleted)
+		}
+	}
+	return changes
+}
+
+// isRalphFile reports whether a repository path is ralph's own bookkeeping:
+// the worktree's context and checkpoint, or the plan file and its sidecar
+// files (progress, feedback, instructions, changes).
+func (l *IterationLoop) isRalphFile(p string) bool {
+	if p == ".ralph/"+ContextFilename || p == ".ralph/"+CheckpointFilename {
+		return true
+	}
+
+	planFile := l.ctx.PlanFile
+	if filepath.IsAbs(planFile) && l.worktreePath != "" {
+		if rel, err := filepath.Rel(l.worktreePath, planFile); err == nil {
+			planFile = rel
+		}
+	}
+	planFile = filepath.ToSlash(planFile)
+	if planFile == "" || path.Dir(p) != path.Dir(planFile) {
+		return false
+	}
+
+	base := path.Base(planFile)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	return path.Base(p) == base || strings.HasPrefix(path.Base(p), stem+".")
+}
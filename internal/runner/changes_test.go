@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestIterationLoop_FinishIteration_RecordsChanges(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	os.MkdirAll(filepath.Join(tempDir, "src"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "src", "app.go"), []byte("package src\n"), 0644)
+	if err := runShellCommand(tempDir, "git add -A && git commit -m base"); err != nil {
+		t.Fatalf("committing base: %v", err)
+	}
+
+	loop.ctx.Iteration = 1
+	os.WriteFile(filepath.Join(tempDir, "src", "app.go"), []byte("package src\n\nfunc App() {}\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "src", "util.go"), []byte("package src\n"), 0644)
+
+	cp := &Checkpoint{Iteration: 1, HeadBefore: loop.headCommit()}
+	result := &Result{}
+	loop.finishIteration(result, cp)
+	if result.HeadBefore != cp.HeadBefore || result.Commit == "" || result.Commit == result.HeadBefore {
+		t.Errorf("result commits = %q..%q, want the iteration's commit", result.HeadBefore, result.Commit)
+	}
+
+	changes, err := plan.LoadChanges(loop.plan)
+	if err != nil {
+		t.Fatalf("LoadChanges: %v", err)
+	}
+	// The plan, its progress file, and the checkpoint are committed too but aren't part of the ledger
+	want := []plan.FileChange{
+		{Path: "src/app.go", Change: plan.ChangeModified, Iterations: []int{1}, Added: 2},
+		{Path: "src/util.go", Change: plan.ChangeCreated, Iterations: []int{1}, Added: 1},
+	}
+	if !reflect.DeepEqual(changes.Files, want) {
+		t.Errorf("ledger = %+v, want %+v", changes.Files, want)
+	}
+
+	// A second iteration that deletes the new file
+	loop.ctx.Iteration = 2
+	os.Remove(filepath.Join(tempDir, "src", "util.go"))
+
+	cp = &Checkpoint{Iteration: 2, HeadBefore: loop.headCommit()}
+	loop.finishIteration(&Result{}, cp)
+
+	changes, _ = plan.LoadChanges(loop.plan)
+	if got := changes.Summary(); got != "1 file changed: 1 modified" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestIterationLoop_FinishIteration_AuditsCommit(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	auditPath := filepath.Join(t.TempDir(), audit.FileName)
+	loop.audit = audit.NewLog(auditPath, "run")
+	os.WriteFile(filepath.Join(tempDir, "app.go"), []byte("package app\n"), 0644)
+	if err := runShellCommand(tempDir, "git add -A && git commit -m base"); err != nil {
+		t.Fatalf("committing base: %v", err)
+	}
+
+	loop.ctx.Iteration = 1
+	os.WriteFile(filepath.Join(tempDir, "app.go"), []byte("package app\n\nfunc App() {}\n"), 0644)
+	cp := &Checkpoint{Iteration: 1, HeadBefore: loop.headCommit()}
+	loop.finishIteration(&Result{}, cp)
+
+	entries, err := audit.ReadAll(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Action != audit.ActionCommit || e.Actor != "run" || e.Details["to"] != cp.Commit || e.Details["from"] != cp.HeadBefore || e.Details["iteration"] != "1" {
+		t.Errorf("entry = %+v, checkpoint = %+v", e, cp)
+	}
+}
+
+func TestIterationLoop_LedgerChanges(t *testing.T) {
+	loop := &IterationLoop{ctx: &Context{PlanFile: "plans/current/feature.md"}}
+
+	got := loop.ledgerChanges([]git.FileChange{
+		{Status: "M", Path: "main.go", Added: 3, Deleted: 1},
+		{Status: "A", Path: "new.go"},
+		{Status: "D", Path: "gone.go"},
+		{Status: "R", Path: "after.go", OldPath: "before.go", Added: 2},
+		{Status: "M", Path: "plans/current/feature.md"},
+		{Status: "A", Path: "plans/current/feature.progress.md"},
+		{Status: "A", Path: "plans/current/other.md"},
+		{Status: "M", Path: ".ralph/context.json"},
+	})
+	want := []plan.FileChange{
+		{Path: "main.go", Change: plan.ChangeModified, Added: 3, Deleted: 1},
+		{Path: "new.go", Change: plan.ChangeCreated},
+		{Path: "gone.go", Change: plan.ChangeDeleted},
+		{Path: "before.go", Change: plan.ChangeDeleted},
+		{Path: "after.go", Change: plan.ChangeCreated, Added: 2},
+		{Path: "plans/current/other.md", Change: plan.ChangeCreated},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ledgerChanges() = %+v\nwant %+v", got, want)
+	}
+}
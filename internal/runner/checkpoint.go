@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// CheckpointFilename is the filename for loop checkpoints in worktrees.
+const CheckpointFilename = "checkpoint.json"
+
+// Checkpoint phases, in the order an iteration passes through them.
+const (
+	// PhaseStarted means Claude execution began but did not finish.
+	PhaseStarted = "started"
+
+	// PhaseExecuted means Claude finished; progress and commit may be pending.
+	PhaseExecuted = "executed"
+
+	// PhaseCommitted means the iteration's changes were committed.
+	PhaseCommitted = "committed"
+)
+
+// Checkpoint is the persisted state of the iteration in flight.
+// It is saved atomically at each phase so a worker restarted after a crash
+// or reboot resumes the iteration where it left off instead of re-running it.
+type Checkpoint struct {
+	// Iteration is the iteration number this checkpoint describes.
+	Iteration int `json:"iteration"`
+
+	// Phase is how far the iteration got (see Phase* constants).
+	Phase string `json:"phase"`
+
+	// PromptHash identifies the prompt sent to Claude.
+	PromptHash string `json:"promptHash,omitempty"`
+
+	// HeadBefore is the HEAD commit when the iteration started.
+	HeadBefore string `json:"headBefore,omitempty"`
+
+	// Commit is the HEAD commit after the iteration's changes were committed.
+	Commit string `json:"commit,omitempty"`
+
+	// Duration is how long Claude execution took.
+	Duration time.Duration `json:"duration,omitempty"`
+
+	// PendingVerification is true if Claude claimed completion and the claim
+	// has not been verified yet.
+	PendingVerification bool `json:"pendingVerification,omitempty"`
+
+	// Blocker is the blocker reported by this iteration, if any.
+	Blocker *Blocker `json:"blocker,omitempty"`
+
+	// FeedbackAcks are the feedback acknowledgments from this iteration.
+	FeedbackAcks []FeedbackAck `json:"feedbackAcks,omitempty"`
+
+	// Question is the question this iteration asked with <ask>, if any.
+	Question string `json:"question,omitempty"`
+
+	// Tools counts the tool calls made during this iteration.
+	Tools *events.ToolStats `json:"tools,omitempty"`
+
+	// Resources is the resource usage of this iteration's claude process tree.
+	Resources *events.ResourceUsage `json:"resources,omitempty"`
+
+	// UpdatedAt is when the checkpoint was last saved.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CheckpointPath returns the path to the checkpoint file within a worktree.
+// The checkpoint is stored next to context.json in the worktree's .ralph directory.
+func CheckpointPath(worktreePath string) string {
+	return filepath.Join(worktreePath, ".ralph", CheckpointFilename)
+}
+
+// LoadCheckpoint reads a checkpoint from a JSON file.
+// Returns an error if the file doesn't exist or is invalid JSON.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file: %w", err)
+	}
+
+	return &cp, nil
+}
+
+// SaveCheckpoint writes the checkpoint to a JSON file atomically.
+func SaveCheckpoint(cp *Checkpoint, path string) error {
+	cp.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := plan.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	return nil
+}
+
+// Result reconstructs the iteration result recorded in the checkpoint.
+func (cp *Checkpoint) Result() *Result {
+	result := &Result{
+		Duration:     cp.Duration,
+		IsComplete:   cp.PendingVerification,
+		Blocker:      cp.Blocker,
+		FeedbackAcks: cp.FeedbackAcks,
+		Question:     cp.Question,
+		HeadBefore:   cp.HeadBefore,
+		Commit:       cp.Commit,
+	}
+	if cp.Tools != nil {
+		result.Tools = *cp.Tools
+	}
+	if cp.Resources != nil {
+		result.Resources = *cp.Resources
+	}
+	return result
+}
+
+// hashPrompt returns a short hash identifying a prompt.
+func hashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+)
+
+func TestSaveLoadCheckpoint(t *testing.T) {
+	path := CheckpointPath(t.TempDir())
+
+	cp := &Checkpoint{
+		Iteration:           3,
+		Phase:               PhaseExecuted,
+		PromptHash:          hashPrompt("do the thing"),
+		HeadBefore:          "abc123",
+		Commit:              "def456",
+		Duration:            2 * time.Minute,
+		PendingVerification: true,
+		Blocker:             &Blocker{Description: "need creds"},
+		FeedbackAcks:        []FeedbackAck{{ID: "2024-01-30 14:32", Outcome: "fixed"}},
+		Question:            "REST or gRPC?",
+	}
+	if err := SaveCheckpoint(cp, path); err != nil {
+		t.Fatalf("SaveCheckpoint() error = %v", err)
+	}
+
+	loaded, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if loaded.Iteration != 3 || loaded.Phase != PhaseExecuted || loaded.PromptHash != cp.PromptHash || loaded.UpdatedAt.IsZero() {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	result := loaded.Result()
+	if !result.IsComplete || result.Duration != 2*time.Minute || result.Blocker.Description != "need creds" || len(result.FeedbackAcks) != 1 ||
+		result.HeadBefore != "abc123" || result.Commit != "def456" || result.Question != "REST or gRPC?" {
+		t.Errorf("Result() = %+v", result)
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temp file should not remain after save")
+	}
+}
+
+func TestLoadCheckpoint_Missing(t *testing.T) {
+	if _, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing checkpoint")
+	}
+}
+
+func TestHashPrompt(t *testing.T) {
+	if hashPrompt("a") == hashPrompt("b") {
+		t.Error("different prompts should hash differently")
+	}
+	if len(hashPrompt("a")) != 16 {
+		t.Errorf("hashPrompt() length = %d, want 16", len(hashPrompt("a")))
+	}
+}
+
+// setupCheckpointLoop creates a loop on a one-task plan in a fresh git repo.
+func setupCheckpointLoop(t *testing.T, mockRunner *MockRunner) (*IterationLoop, string) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n## Tasks\n- [ ] Task 1\n"), 0644)
+
+	// Track the progress file so each iteration's progress entry is committed
+	os.WriteFile(filepath.Join(planDir, "test-plan.progress.md"), []byte("# Progress\n"), 0644)
+
+	gitRepo := setupTestGitRepo(t, tempDir)
+	if err := runShellCommand(tempDir, "git add -A && git commit -m 'add plan'"); err != nil {
+		t.Fatalf("committing plan: %v", err)
+	}
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+
+	loop := NewIterationLoop(LoopConfig{
+		Plan:             p,
+		Context:          NewContext(p, "main", 1),
+		Config:           config.Defaults(),
+		Runner:           mockRunner,
+		Git:              gitRepo,
+		PromptBuilder:    prompt.NewBuilder(config.Defaults(), "", ""),
+		WorktreePath:     tempDir,
+		IterationTimeout: time.Second,
+	})
+	return loop, tempDir
+}
+
+func TestIterationLoop_RunIteration_Checkpoints(t *testing.T) {
+	loop, dir := setupCheckpointLoop(t, &MockRunner{Responses: []MockResponse{{IsComplete: true}}})
+
+	if _, err := loop.runIteration(context.Background()); err != nil {
+		t.Fatalf("runIteration() error = %v", err)
+	}
+
+	cp, err := LoadCheckpoint(CheckpointPath(dir))
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if cp.Iteration != 1 || cp.Phase != PhaseCommitted || !cp.PendingVerification {
+		t.Errorf("checkpoint = %+v", cp)
+	}
+	if cp.PromptHash == "" || cp.HeadBefore == "" || cp.Commit == "" || cp.Commit == cp.HeadBefore {
+		t.Errorf("checkpoint should record prompt hash and commits, got %+v", cp)
+	}
+}
+
+func TestIterationLoop_Run_ResumesCommittedIteration(t *testing.T) {
+	mockRunner := &MockRunner{}
+	loop, dir := setupCheckpointLoop(t, mockRunner)
+	SaveCheckpoint(&Checkpoint{Iteration: 1, Phase: PhaseCommitted, Blocker: &Blocker{Description: "need creds"}}, CheckpointPath(dir))
+
+	var iterations []int
+	loop.onIteration = func(iteration int, result *Result) { iterations = append(iterations, iteration) }
+
+	result := loop.Run(context.Background())
+
+	// The committed iteration is not executed again
+	if len(mockRunner.RecordedOpts) != 0 {
+		t.Errorf("runner called %d times, want 0", len(mockRunner.RecordedOpts))
+	}
+	if len(iterations) != 1 || iterations[0] != 1 || result.Iterations != 1 {
+		t.Errorf("iterations = %v, result = %+v", iterations, result)
+	}
+	if result.FinalBlocker == nil || result.FinalBlocker.Description != "need creds" {
+		t.Errorf("blocker should be restored from checkpoint, got %+v", result.FinalBlocker)
+	}
+}
+
+func TestIterationLoop_ResumeCheckpoint_Executed(t *testing.T) {
+	loop, dir := setupCheckpointLoop(t, &MockRunner{})
+	SaveCheckpoint(&Checkpoint{Iteration: 1, Phase: PhaseExecuted, Duration: time.Minute}, CheckpointPath(dir))
+	headBefore, _ := loop.git.HeadCommit()
+
+	result := loop.resumeCheckpoint()
+	if result == nil || result.Duration != time.Minute {
+		t.Fatalf("resumeCheckpoint() = %+v", result)
+	}
+
+	// The interrupted commit and progress entry are completed
+	progress, _ := plan.ReadProgress(loop.plan)
+	if !strings.Contains(progress, "## Iteration 1") {
+		t.Errorf("progress = %q", progress)
+	}
+	cp, _ := LoadCheckpoint(CheckpointPath(dir))
+	if cp.Phase != PhaseCommitted || cp.Commit == "" || cp.Commit == headBefore {
+		t.Errorf("checkpoint = %+v, want a new commit after %s", cp, headBefore)
+	}
+}
+
+func TestIterationLoop_ResumeCheckpoint_RerunsInterrupted(t *testing.T) {
+	loop, dir := setupCheckpointLoop(t, &MockRunner{})
+
+	// Interrupted mid-execution: run it again
+	SaveCheckpoint(&Checkpoint{Iteration: 1, Phase: PhaseStarted}, CheckpointPath(dir))
+	if result := loop.resumeCheckpoint(); result != nil {
+		t.Errorf("started checkpoint: resumeCheckpoint() = %+v, want nil", result)
+	}
+
+	// A checkpoint from an earlier iteration is stale
+	loop.ctx = loop.ctx.Increment()
+	SaveCheckpoint(&Checkpoint{Iteration: 1, Phase: PhaseCommitted}, CheckpointPath(dir))
+	if result := loop.resumeCheckpoint(); result != nil {
+		t.Errorf("stale checkpoint: resumeCheckpoint() = %+v, want nil", result)
+	}
+}
@@ -2,12 +2,16 @@
 package runner
 
 import (
+	"os"
 	"os/exec"
 	"strings"
 )
 
 // Options configures Claude CLI execution.
 type Options struct {
+	// Binary is the claude executable name or path (default: "claude" on PATH)
+	Binary string
+
 	// Model specifies the model to use (e.g., "claude-sonnet-4-20250514", "claude-3-5-haiku-20241022")
 	Model string
 
@@ -17,9 +21,21 @@ type Options struct {
 	// AllowedTools is a list of tools the agent can use
 	AllowedTools []string
 
+	// DisallowedTools is a list of tools the agent may never use
+	DisallowedTools []string
+
+	// PermissionMode is passed as --permission-mode (e.g., "acceptEdits", "plan")
+	PermissionMode string
+
+	// MCPConfig is an MCP server config file passed as --mcp-config
+	MCPConfig string
+
 	// WorkDir is the working directory for command execution
 	WorkDir string
 
+	// Env is added to the environment of the claude process, as "KEY=value"
+	Env []string
+
 	// Print outputs the prompt that would be sent (dry-run mode)
 	Print bool
 
@@ -50,12 +66,19 @@ func DefaultOptions() Options {
 func BuildCommand(prompt string, opts Options) *exec.Cmd {
 	args := buildArgs(opts)
 
-	cmd := exec.Command("claude", args...)
+	binary := opts.Binary
+	if binary == "" {
+		binary = DefaultClaudeBinary
+	}
+	cmd := exec.Command(binary, args...)
 
 	// Set working directory if specified
 	if opts.WorkDir != "" {
 		cmd.Dir = opts.WorkDir
 	}
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 
 	// Note: Prompt is passed via stdin by the caller
 	// This avoids shell escaping issues with complex prompts
@@ -98,6 +121,20 @@ func buildArgs(opts Options) []string {
 		args = append(args, "--allowedTools", strings.Join(opts.AllowedTools, ","))
 	}
 
+	// Disallowed tools (comma-separated)
+	if len(opts.DisallowedTools) > 0 {
+		args = append(args, "--disallowedTools", strings.Join(opts.DisallowedTools, ","))
+	}
+
+	// Permission mode
+	if opts.PermissionMode != "" {
+		args = append(args, "--permission-mode", opts.PermissionMode)
+	}
+
+	if opts.MCPConfig != "" {
+		args = append(args, "--mcp-config", opts.MCPConfig)
+	}
+
 	// System prompt
 	if opts.SystemPrompt != "" {
 		args = append(args, "--system-prompt", opts.SystemPrompt)
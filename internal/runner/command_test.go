@@ -83,6 +83,54 @@ func TestBuildCommand_WithAllowedTools(t *testing.T) {
 	}
 }
 
+func TestBuildCommand_WithDisallowedToolsAndPermissionMode(t *testing.T) {
+	cmd := BuildCommand("test", Options{
+		DisallowedTools: []string{"WebFetch", "Bash(curl:*)"},
+		PermissionMode:  "acceptEdits",
+	})
+
+	args := strings.Join(cmd.Args, " ")
+	for _, expected := range []string{"--disallowedTools WebFetch,Bash(curl:*)", "--permission-mode acceptEdits"} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("expected %q in args, got: %s", expected, args)
+		}
+	}
+}
+
+func TestBuildCommand_WithMCPConfig(t *testing.T) {
+	cmd := BuildCommand("test", Options{MCPConfig: "/tmp/ralph/.mcp.json"})
+
+	args := strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--mcp-config /tmp/ralph/.mcp.json") {
+		t.Errorf("expected --mcp-config in args, got: %s", args)
+	}
+
+	cmd = BuildCommand("test", Options{})
+	if strings.Contains(strings.Join(cmd.Args, " "), "--mcp-config") {
+		t.Errorf("did not expect --mcp-config without a config file")
+	}
+}
+
+func TestCLIRunner_ApplyPermissions(t *testing.T) {
+	r := NewCLIRunner()
+	r.SetPermissions(Permissions{
+		AllowedTools:    []string{"Edit"},
+		DisallowedTools: []string{"WebFetch"},
+		PermissionMode:  "plan",
+	})
+
+	opts := r.applyPermissions(DefaultOptions())
+	if len(opts.AllowedTools) != 1 || len(opts.DisallowedTools) != 1 || opts.PermissionMode != "plan" {
+		t.Errorf("applyPermissions() = %+v", opts)
+	}
+
+	// Per-call options win
+	opts = r.applyPermissions(Options{AllowedTools: []string{"Read"}, PermissionMode: "default"})
+	if opts.AllowedTools[0] != "Read" || opts.PermissionMode != "default" || opts.DisallowedTools[0] != "WebFetch" {
+		t.Errorf("applyPermissions() with explicit options = %+v", opts)
+	}
+}
+
 func TestBuildCommand_WithWorkDir(t *testing.T) {
 	opts := Options{
 		WorkDir: "/tmp/test-workspace",
@@ -94,6 +142,18 @@ func TestBuildCommand_WithWorkDir(t *testing.T) {
 	}
 }
 
+func TestBuildCommand_WithEnv(t *testing.T) {
+	cmd := BuildCommand("test", Options{Env: []string{"CARGO_TARGET_DIR=/repo/target"}})
+	if len(cmd.Env) == 0 || cmd.Env[len(cmd.Env)-1] != "CARGO_TARGET_DIR=/repo/target" {
+		t.Errorf("expected Env to be appended to the environment, got: %v", cmd.Env)
+	}
+
+	// Without Env the process inherits the environment
+	if cmd := BuildCommand("test", Options{}); cmd.Env != nil {
+		t.Errorf("expected inherited environment, got: %v", cmd.Env)
+	}
+}
+
 func TestBuildCommand_WithPrint(t *testing.T) {
 	opts := Options{
 		Print: true,
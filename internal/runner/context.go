@@ -2,17 +2,27 @@
 package runner
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/arvesolland/ralph/internal/bench"
+	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 )
 
 // Context represents the execution state for a plan iteration.
 // It is persisted as context.json in the worktree to maintain state between iterations.
 type Context struct {
+	// SchemaVersion is the layout of the file (see ContextSchemaVersion).
+	// Files written before versioning have none and are treated as version 0.
+	SchemaVersion int `json:"schemaVersion"`
+
 	// PlanFile is the path to the plan file being executed
 	PlanFile string `json:"planFile"`
 
@@ -22,11 +32,59 @@ type Context struct {
 	// BaseBranch is the base branch to merge into (e.g., "main")
 	BaseBranch string `json:"baseBranch"`
 
+	// RunID identifies this execution of the plan; iteration commits carry
+	// it in their Ralph-Run-ID trailer
+	RunID string `json:"runId,omitempty"`
+
+	// RunTime is how long the plan's iterations have run in total, for
+	// worker.max_plan_duration. Time spent waiting (retry backoff, pending,
+	// paused) doesn't count.
+	RunTime time.Duration `json:"runTime,omitempty"`
+
 	// Iteration is the current iteration number (1-indexed)
 	Iteration int `json:"iteration"`
 
 	// MaxIterations is the maximum allowed iterations before failure
 	MaxIterations int `json:"maxIterations"`
+
+	// Stage is the current pipeline stage (empty when no stages are configured)
+	Stage string `json:"stage,omitempty"`
+
+	// StageIteration is the iteration number within the current stage (1-indexed)
+	StageIteration int `json:"stageIteration,omitempty"`
+
+	// Blocker is the blocker the latest iterations kept reporting, if any
+	Blocker *BlockerState `json:"blocker,omitempty"`
+
+	// TDD is the test-first state of a **Mode:** tdd plan
+	TDD *TDDState `json:"tdd,omitempty"`
+
+	// Coverage is the test coverage measured with coverage.command
+	Coverage *CoverageState `json:"coverage,omitempty"`
+
+	// BenchBase is the base branch's bench.command results, measured before the first iteration
+	BenchBase bench.Results `json:"benchBase,omitempty"`
+}
+
+// BlockerState tracks how long a blocker has gone unresolved, for escalation.
+type BlockerState struct {
+	// Hash identifies the blocker (see Blocker.Hash)
+	Hash string `json:"hash"`
+
+	// Since is when an iteration first reported the blocker
+	Since time.Time `json:"since"`
+
+	// Escalated is true once the blocker has been escalated
+	Escalated bool `json:"escalated,omitempty"`
+}
+
+// TDDState tracks the phase of a test-first plan.
+type TDDState struct {
+	// Phase is the phase of the current iteration (see TDDPhaseTest)
+	Phase string `json:"phase"`
+
+	// TestsPassed is true if the test gate passed after the latest iteration
+	TestsPassed bool `json:"testsPassed,omitempty"`
 }
 
 // DefaultMaxIterations is the default maximum number of iterations
@@ -35,6 +93,39 @@ const DefaultMaxIterations = 30
 // ContextFilename is the filename for context files in worktrees
 const ContextFilename = "context.json"
 
+// ContextSchemaVersion is the context.json layout this binary writes.
+// Bump it when a change needs existing files converted, and append the
+// conversion to contextMigrations.
+const ContextSchemaVersion = 2
+
+// ErrContextTooNew is returned by LoadContext for a context.json written by
+// a newer ralph, which this binary can't safely resume from.
+var ErrContextTooNew = errors.New("context was written by a newer ralph")
+
+// contextMigrations converts a context file, decoded as generic JSON, from
+// schema version i to i+1.
+var contextMigrations = []func(fields map[string]any) error{
+	// 0 → 1: the schemaVersion field was added; nothing else changed
+	func(fields map[string]any) error { return nil },
+
+	// 1 → 2: runId was added; give the resumed run one
+	func(fields map[string]any) error {
+		if _, ok := fields["runId"]; !ok {
+			fields["runId"] = newRunID()
+		}
+		return nil
+	},
+}
+
+// newRunID returns a random ID for a plan run.
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
 // NewContext creates a new Context from a plan.
 // The context is initialized for the first iteration with the specified base branch and max iterations.
 func NewContext(p *plan.Plan, baseBranch string, maxIterations int) *Context {
@@ -42,22 +133,42 @@ func NewContext(p *plan.Plan, baseBranch string, maxIterations int) *Context {
 		maxIterations = DefaultMaxIterations
 	}
 	return &Context{
+		SchemaVersion: ContextSchemaVersion,
 		PlanFile:      p.Path,
 		FeatureBranch: p.Branch,
 		BaseBranch:    baseBranch,
+		RunID:         newRunID(),
 		Iteration:     1,
 		MaxIterations: maxIterations,
 	}
 }
 
-// LoadContext reads a context from a JSON file.
-// Returns an error if the file doesn't exist or is invalid JSON.
+// LoadContext reads a context from a JSON file, migrating a file written
+// with an older schema to ContextSchemaVersion.
+// Returns an error if the file doesn't exist or is invalid JSON, and
+// ErrContextTooNew if it has a newer schema than this binary supports.
 func LoadContext(path string) (*Context, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read context file: %w", err)
 	}
 
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse context file: %w", err)
+	}
+	switch {
+	case header.SchemaVersion > ContextSchemaVersion:
+		return nil, fmt.Errorf("%w: %s has schema version %d, this ralph supports up to %d; upgrade ralph, or run `ralph reset` to start the plan over",
+			ErrContextTooNew, path, header.SchemaVersion, ContextSchemaVersion)
+	case header.SchemaVersion < ContextSchemaVersion:
+		if data, err = migrateContext(data, header.SchemaVersion); err != nil {
+			return nil, fmt.Errorf("failed to migrate context file %s: %w", path, err)
+		}
+	}
+
 	var ctx Context
 	if err := json.Unmarshal(data, &ctx); err != nil {
 		return nil, fmt.Errorf("failed to parse context file: %w", err)
@@ -66,9 +177,27 @@ func LoadContext(path string) (*Context, error) {
 	return &ctx, nil
 }
 
+// migrateContext runs the migrations from version to ContextSchemaVersion
+// on context file data.
+func migrateContext(data []byte, version int) ([]byte, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for v := version; v < ContextSchemaVersion; v++ {
+		if err := contextMigrations[v](fields); err != nil {
+			return nil, fmt.Errorf("schema version %d to %d: %w", v, v+1, err)
+		}
+		log.Debug("Migrated context from schema version %d to %d", v, v+1)
+	}
+	fields["schemaVersion"] = ContextSchemaVersion
+	return json.Marshal(fields)
+}
+
 // SaveContext writes the context to a JSON file.
 // The file is written atomically (write to temp, then rename).
 func SaveContext(ctx *Context, path string) error {
+	ctx.SchemaVersion = ContextSchemaVersion
 	data, err := json.MarshalIndent(ctx, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
@@ -80,16 +209,9 @@ func SaveContext(ctx *Context, path string) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Write to temp file first for atomic save
-	tempPath := path + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp context file: %w", err)
-	}
-
-	// Rename temp file to target path (atomic on POSIX)
-	if err := os.Rename(tempPath, path); err != nil {
-		os.Remove(tempPath) // Clean up temp file on failure
-		return fmt.Errorf("failed to rename context file: %w", err)
+	// Atomic and synced, so a crash never leaves a truncated context
+	if err := plan.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write context file: %w", err)
 	}
 
 	return nil
@@ -102,14 +224,36 @@ func ContextPath(worktreePath string) string {
 }
 
 // Increment increments the iteration count and returns a copy of the context.
+// The stage iteration is incremented too when a stage is set.
 func (c *Context) Increment() *Context {
-	return &Context{
+	next := &Context{
+		SchemaVersion: c.SchemaVersion,
 		PlanFile:      c.PlanFile,
 		FeatureBranch: c.FeatureBranch,
 		BaseBranch:    c.BaseBranch,
+		RunID:         c.RunID,
+		RunTime:       c.RunTime,
 		Iteration:     c.Iteration + 1,
 		MaxIterations: c.MaxIterations,
+		Stage:         c.Stage,
+		BenchBase:     c.BenchBase,
+	}
+	if c.Blocker != nil {
+		blocker := *c.Blocker
+		next.Blocker = &blocker
+	}
+	if c.TDD != nil {
+		tdd := *c.TDD
+		next.TDD = &tdd
+	}
+	if c.Coverage != nil {
+		coverage := *c.Coverage
+		next.Coverage = &coverage
+	}
+	if c.Stage != "" {
+		next.StageIteration = c.StageIteration + 1
 	}
+	return next
 }
 
 // IsMaxReached returns true if the current iteration exceeds the maximum allowed.
@@ -1,8 +1,10 @@
 package runner
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/arvesolland/ralph/internal/plan"
@@ -18,6 +20,9 @@ func TestNewContext(t *testing.T) {
 	t.Run("with default max iterations", func(t *testing.T) {
 		ctx := NewContext(p, "main", 0)
 
+		if ctx.RunID == "" || ctx.RunID == NewContext(p, "main", 0).RunID {
+			t.Errorf("RunID = %q, want a unique ID per run", ctx.RunID)
+		}
 		if ctx.PlanFile != p.Path {
 			t.Errorf("PlanFile = %q, want %q", ctx.PlanFile, p.Path)
 		}
@@ -60,8 +65,12 @@ func TestContext_Increment(t *testing.T) {
 		PlanFile:      "/plans/current/test.md",
 		FeatureBranch: "feat/test",
 		BaseBranch:    "main",
+		RunID:         "run1",
 		Iteration:     5,
 		MaxIterations: 30,
+		Blocker:       &BlockerState{Hash: "abc12345"},
+		TDD:           &TDDState{Phase: TDDPhaseImplement},
+		Coverage:      &CoverageState{Base: ptrFloat(80)},
 	}
 
 	next := ctx.Increment()
@@ -83,6 +92,23 @@ func TestContext_Increment(t *testing.T) {
 	if next.MaxIterations != ctx.MaxIterations {
 		t.Errorf("next MaxIterations = %d, want %d", next.MaxIterations, ctx.MaxIterations)
 	}
+	if next.RunID != ctx.RunID {
+		t.Errorf("next RunID = %q, want %q", next.RunID, ctx.RunID)
+	}
+
+	// The blocker state is copied, not shared
+	next.Blocker.Escalated = true
+	if next.Blocker.Hash != "abc12345" || ctx.Blocker.Escalated {
+		t.Errorf("next Blocker = %+v, original %+v", next.Blocker, ctx.Blocker)
+	}
+	next.TDD.TestsPassed = true
+	if next.TDD.Phase != TDDPhaseImplement || ctx.TDD.TestsPassed {
+		t.Errorf("next TDD = %+v, original %+v", next.TDD, ctx.TDD)
+	}
+	next.Coverage.Latest = ptrFloat(75)
+	if *next.Coverage.Base != 80 || ctx.Coverage.Latest != nil {
+		t.Errorf("next Coverage = %+v, original %+v", next.Coverage, ctx.Coverage)
+	}
 }
 
 func TestContext_IsMaxReached(t *testing.T) {
@@ -250,6 +276,47 @@ func TestSaveContext_AtomicWrite(t *testing.T) {
 	}
 }
 
+func TestLoadContext_MigratesUnversioned(t *testing.T) {
+	ctxPath := filepath.Join(t.TempDir(), "context.json")
+	legacy := `{"planFile": "plans/current/test.md", "featureBranch": "feat/test", "baseBranch": "main", "iteration": 4, "maxIterations": 30, "stage": "implement"}`
+	if err := os.WriteFile(ctxPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := LoadContext(ctxPath)
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if ctx.SchemaVersion != ContextSchemaVersion || ctx.Iteration != 4 || ctx.Stage != "implement" || ctx.PlanFile != "plans/current/test.md" {
+		t.Errorf("migrated context = %+v", ctx)
+	}
+	if ctx.RunID == "" {
+		t.Error("migrated context should get a run ID")
+	}
+}
+
+func TestLoadContext_TooNew(t *testing.T) {
+	ctxPath := filepath.Join(t.TempDir(), "context.json")
+	if err := os.WriteFile(ctxPath, []byte(`{"schemaVersion": 99, "iteration": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadContext(ctxPath)
+	if !errors.Is(err, ErrContextTooNew) {
+		t.Fatalf("LoadContext() error = %v, want ErrContextTooNew", err)
+	}
+	if !strings.Contains(err.Error(), "ralph reset") {
+		t.Errorf("error %q should suggest ralph reset", err)
+	}
+}
+
+func TestContextMigrations(t *testing.T) {
+	// Every version below the current one needs a migration
+	if len(contextMigrations) != ContextSchemaVersion {
+		t.Errorf("%d context migrations for schema version %d", len(contextMigrations), ContextSchemaVersion)
+	}
+}
+
 func TestContextPath(t *testing.T) {
 	tests := []struct {
 		worktreePath string
@@ -293,6 +360,7 @@ func TestJSONSerialization(t *testing.T) {
 
 	content := string(data)
 	expectedFields := []string{
+		`"schemaVersion": 2`,
 		`"planFile"`,
 		`"featureBranch"`,
 		`"baseBranch"`,
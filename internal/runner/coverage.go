@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"context"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/coverage"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// CoverageState is the test coverage of a plan's worktree, in percent.
+type CoverageState struct {
+	// Base is the coverage before the first iteration, on the base branch
+	Base *float64 `json:"base,omitempty"`
+
+	// Latest is the coverage after the latest iteration
+	Latest *float64 `json:"latest,omitempty"`
+}
+
+// measureCoverage runs coverage.command in the worktree and records the
+// result: as the base coverage for iteration 0, as the latest coverage
+// otherwise. A failed measurement is logged and skipped.
+func (l *IterationLoop) measureCoverage(ctx context.Context, iteration int) {
+	if l.config == nil || strings.TrimSpace(l.config.Coverage.Command) == "" {
+		return
+	}
+
+	percent, err := coverage.Measure(ctx, l.worktreePath, l.config.Coverage)
+	if err != nil {
+		log.Warn("Coverage not measured: %v", err)
+		return
+	}
+
+	if l.ctx.Coverage == nil {
+		l.ctx.Coverage = &CoverageState{}
+	}
+	if iteration == 0 {
+		l.ctx.Coverage.Base = &percent
+		log.Info("Base coverage: %.1f%%", percent)
+	} else {
+		l.ctx.Coverage.Latest = &percent
+		log.Info("Coverage after iteration %d: %.1f%%", iteration, percent)
+	}
+	if l.onCoverage != nil {
+		l.onCoverage(iteration, percent)
+	}
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"context"
+	"testing"
+)
+
+// ptrFloat returns a pointer to f.
+func ptrFloat(f float64) *float64 {
+	return &f
+}
+
+func TestIterationLoop_Run_Coverage(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Done <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "YES"},
+		},
+	}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+	// Coverage is 80% on the base branch and 75% once the iteration ran
+	loop.config.Coverage.Command = "if test -f plans/current/test-plan.progress.md; then echo 'coverage: 75.0%'; else echo 'coverage: 80.0%'; fi"
+
+	var measured []float64
+	var iterations []int
+	loop.onCoverage = func(iteration int, percent float64) {
+		iterations = append(iterations, iteration)
+		measured = append(measured, percent)
+	}
+
+	result := loop.Run(context.Background())
+
+	if !result.Completed {
+		t.Fatalf("Run() error = %v, want completed", result.Error)
+	}
+	if len(measured) != 2 || iterations[0] != 0 || measured[0] != 80 || iterations[1] != 1 || measured[1] != 75 {
+		t.Errorf("measurements = %v at iterations %v, want 80 at 0 and 75 at 1", measured, iterations)
+	}
+	if c := result.Coverage; c == nil || *c.Base != 80 || *c.Latest != 75 {
+		t.Errorf("result.Coverage = %+v", result.Coverage)
+	}
+}
+
+func TestIterationLoop_MeasureCoverage(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+
+	// Without coverage.command nothing is measured
+	loop.measureCoverage(context.Background(), 0)
+	if loop.ctx.Coverage != nil {
+		t.Errorf("Coverage = %+v, want nil without a command", loop.ctx.Coverage)
+	}
+
+	// A failed measurement is skipped
+	loop.config.Coverage.Command = "exit 1"
+	loop.measureCoverage(context.Background(), 1)
+	if loop.ctx.Coverage != nil {
+		t.Errorf("Coverage = %+v after a failed measurement", loop.ctx.Coverage)
+	}
+}
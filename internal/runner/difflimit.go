@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// checkDiffLimit compares the uncommitted changes against git.max_diff_files
+// and git.max_diff_lines. Returns a description of the overrun, e.g.
+// "62 files changed (limit 40)", or "" if the changes are within the limits
+// or no limits are set. Ralph's own files don't count.
+func (l *IterationLoop) checkDiffLimit() string {
+	if l.git == nil || l.config == nil {
+		return ""
+	}
+	maxFiles, maxLines := l.config.Git.MaxDiffFiles, l.config.Git.MaxDiffLines
+	if maxFiles == 0 && maxLines == 0 {
+		return ""
+	}
+
+	stats, err := l.git.UncommittedStats()
+	if err != nil {
+		log.Warn("Failed to measure iteration diff, skipping diff limits: %v", err)
+		return ""
+	}
+	files, lines := 0, 0
+	for _, s := range stats {
+		if l.isRalphFile(s.Path) {
+			continue
+		}
+		files++
+		lines += s.Added + s.Deleted
+	}
+
+	var overruns []string
+	if maxFiles > 0 && files > maxFiles {
+		overruns = append(overruns, fmt.Sprintf("%d files changed (limit %d)", files, maxFiles))
+	}
+	if maxLines > 0 && lines > maxLines {
+		overruns = append(overruns, fmt.Sprintf("%d lines changed (limit %d)", lines, maxLines))
+	}
+	return strings.Join(overruns, ", ")
+}
+
+// holdDiff handles an iteration whose diff exceeded the limits. Its changes
+// stay uncommitted in the worktree; depending on git.diff_limit_action the
+// agent is asked to commit them in smaller chunks, or a blocker is raised.
+func (l *IterationLoop) holdDiff(result *Result, overrun string) {
+	log.Warn("Iteration %d diff too large, not committing: %s", l.ctx.Iteration, overrun)
+	result.DiffLimit = overrun
+
+	if l.config.Git.DiffLimitAction == config.DiffLimitBlock {
+		if result.Blocker == nil {
+			description := fmt.Sprintf("Iteration %d diff exceeds the configured limit: %s", l.ctx.Iteration, overrun)
+			action := "Review the uncommitted changes in the worktree and commit, split, or discard them, or raise git.max_diff_files / git.max_diff_lines."
+			content := description + "\nAction: " + action
+			result.Blocker = &Blocker{Content: content, Description: description, Action: action, Hash: computeBlockerHash(content)}
+		}
+		return
+	}
+
+	content := fmt.Sprintf("**Diff too large to commit:** %s.\nThe changes were left uncommitted. Before continuing, commit them yourself in smaller, self-contained chunks (`git add <files> && git commit -m \"...\"`), each within %s.", overrun, l.diffLimits())
+	if err := plan.AppendFeedback(l.plan, "diff limit", content); err != nil {
+		log.Error("Failed to write diff limit feedback: %v", err)
+	}
+}
+
+// diffLimits describes the configured diff limits, e.g. "40 files and 1500 lines".
+func (l *IterationLoop) diffLimits() string {
+	var limits []string
+	if n := l.config.Git.MaxDiffFiles; n > 0 {
+		limits = append(limits, fmt.Sprintf("%d files", n))
+	}
+	if n := l.config.Git.MaxDiffLines; n > 0 {
+		limits = append(limits, fmt.Sprintf("%d lines", n))
+	}
+	return strings.Join(limits, " and ")
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// writeLines writes a file with n lines into dir.
+func writeLines(t *testing.T, dir, name string, n int) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(strings.Repeat("line\n", n)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIterationLoop_CheckDiffLimit(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	writeLines(t, tempDir, "a.go", 30)
+	writeLines(t, tempDir, "b.go", 30)
+
+	if got := loop.checkDiffLimit(); got != "" {
+		t.Errorf("checkDiffLimit() without limits = %q", got)
+	}
+
+	loop.config.Git.MaxDiffFiles = 5
+	loop.config.Git.MaxDiffLines = 100
+	if got := loop.checkDiffLimit(); got != "" {
+		t.Errorf("checkDiffLimit() within limits = %q", got)
+	}
+
+	loop.config.Git.MaxDiffFiles = 1
+	loop.config.Git.MaxDiffLines = 50
+	if got, want := loop.checkDiffLimit(), "2 files changed (limit 1), 60 lines changed (limit 50)"; got != want {
+		t.Errorf("checkDiffLimit() = %q, want %q", got, want)
+	}
+}
+
+func TestIterationLoop_FinishIteration_DiffLimitSplit(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.config.Git.MaxDiffLines = 100
+	loop.ctx.Iteration = 1
+	writeLines(t, tempDir, "big.go", 500)
+
+	head := loop.headCommit()
+	result := &Result{}
+	loop.finishIteration(result, &Checkpoint{Iteration: 1, HeadBefore: head})
+
+	if loop.headCommit() != head {
+		t.Error("an oversized iteration should not be committed")
+	}
+	if result.DiffLimit != "500 lines changed (limit 100)" || result.Blocker != nil {
+		t.Errorf("result = %+v, want a diff limit and no blocker", result)
+	}
+
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "Diff too large to commit") || !strings.Contains(feedback, "within 100 lines") {
+		t.Errorf("feedback = %q, want a request to commit in smaller chunks", feedback)
+	}
+	progress, _ := plan.ReadProgress(loop.plan)
+	if !strings.Contains(progress, "Not committed, diff too large: 500 lines changed (limit 100).") {
+		t.Errorf("progress = %q", progress)
+	}
+
+	// Once the agent has committed most of it, the rest is committed normally
+	if err := runShellCommand(tempDir, "git add big.go && git commit -q -m chunk"); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, tempDir, "small.go", 10)
+	loop.ctx.Iteration = 2
+	head = loop.headCommit()
+	result = &Result{}
+	loop.finishIteration(result, &Checkpoint{Iteration: 2, HeadBefore: head})
+	if result.DiffLimit != "" || loop.headCommit() == head {
+		t.Errorf("iteration within limits should be committed, result = %+v", result)
+	}
+}
+
+func TestIterationLoop_FinishIteration_DiffLimitBlock(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.config.Git.MaxDiffFiles = 2
+	loop.config.Git.DiffLimitAction = config.DiffLimitBlock
+	loop.ctx.Iteration = 1
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		writeLines(t, tempDir, name, 1)
+	}
+
+	head := loop.headCommit()
+	result := &Result{}
+	loop.finishIteration(result, &Checkpoint{Iteration: 1, HeadBefore: head})
+
+	if loop.headCommit() != head {
+		t.Error("an oversized iteration should not be committed")
+	}
+	if result.Blocker == nil || !strings.Contains(result.Blocker.Description, "3 files changed (limit 2)") {
+		t.Fatalf("blocker = %+v, want a diff limit blocker", result.Blocker)
+	}
+	if result.Blocker.Hash == "" || result.Blocker.Action == "" {
+		t.Errorf("blocker = %+v, want hash and action", result.Blocker)
+	}
+}
@@ -0,0 +1,37 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPlanTimeout is returned when a plan has run longer than its
+// **Max-Duration:** header or worker.max_plan_duration.
+var ErrPlanTimeout = errors.New("plan exceeded its max duration")
+
+// maxPlanDuration returns how long the plan may run: its **Max-Duration:**
+// header, else worker.max_plan_duration. Zero means no limit.
+func (l *IterationLoop) maxPlanDuration() time.Duration {
+	if l.plan != nil && l.plan.MaxDuration > 0 {
+		return l.plan.MaxDuration
+	}
+	if l.config == nil {
+		return 0
+	}
+	return policyDuration(l.config.Worker.MaxPlanDuration)
+}
+
+// checkPlanDuration returns ErrPlanTimeout once the plan's iterations have
+// run for its max duration in total (Context.RunTime). It is checked between
+// iterations, so a running iteration always finishes.
+func (l *IterationLoop) checkPlanDuration() error {
+	limit := l.maxPlanDuration()
+	if limit <= 0 {
+		return nil
+	}
+	if running := l.ctx.RunTime; running >= limit {
+		return fmt.Errorf("%w: running for %s, limit %s", ErrPlanTimeout, running.Round(time.Minute), limit)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+)
+
+func TestIterationLoop_CheckPlanDuration(t *testing.T) {
+	cfg := config.Defaults()
+	l := &IterationLoop{
+		ctx:    &Context{RunTime: 3 * time.Hour},
+		plan:   &plan.Plan{Name: "my-plan"},
+		config: cfg,
+	}
+
+	// No limit by default
+	if err := l.checkPlanDuration(); err != nil {
+		t.Errorf("checkPlanDuration() without a limit = %v", err)
+	}
+
+	cfg.Worker.MaxPlanDuration = "6h"
+	if err := l.checkPlanDuration(); err != nil {
+		t.Errorf("checkPlanDuration() within the limit = %v", err)
+	}
+	cfg.Worker.MaxPlanDuration = "2h"
+	if err := l.checkPlanDuration(); !errors.Is(err, ErrPlanTimeout) {
+		t.Errorf("checkPlanDuration() past the limit = %v, want ErrPlanTimeout", err)
+	}
+
+	// The plan's header overrides the config
+	l.plan.MaxDuration = 4 * time.Hour
+	if err := l.checkPlanDuration(); err != nil {
+		t.Errorf("checkPlanDuration() within the header's limit = %v", err)
+	}
+	l.plan.MaxDuration = time.Hour
+	cfg.Worker.MaxPlanDuration = ""
+	if err := l.checkPlanDuration(); !errors.Is(err, ErrPlanTimeout) {
+		t.Errorf("checkPlanDuration() past the header's limit = %v, want ErrPlanTimeout", err)
+	}
+}
+
+func TestIterationLoop_Run_RetriedPlanKeepsRunTime(t *testing.T) {
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n**Status:** open\n## Tasks\n- [ ] Task 1\n"), 0644)
+
+	gitRepo := setupTestGitRepo(t, tempDir)
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Worker.MaxPlanDuration = "1h"
+	newLoop := func(execCtx *Context, mock *MockRunner) *IterationLoop {
+		return NewIterationLoop(LoopConfig{
+			Plan:             p,
+			Context:          execCtx,
+			Config:           cfg,
+			Runner:           mock,
+			Git:              gitRepo,
+			PromptBuilder:    prompt.NewBuilder(cfg, "", ""),
+			WorktreePath:     tempDir,
+			IterationTimeout: time.Second,
+		})
+	}
+
+	// Ten minutes of earlier iterations, then a transient failure
+	execCtx := NewContext(p, "main", 10)
+	execCtx.RunTime = 10 * time.Minute
+	result := newLoop(execCtx, &MockRunner{Responses: []MockResponse{{Error: errors.New("rate limit exceeded")}}}).Run(context.Background())
+	if result.Error == nil {
+		t.Fatal("Run() should return the transient error")
+	}
+
+	// The retry resumes the saved context however long the backoff was:
+	// only the time iterations ran counts toward the limit
+	saved, err := LoadContext(ContextPath(tempDir))
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if saved.RunTime < 10*time.Minute || saved.RunTime > 11*time.Minute {
+		t.Errorf("saved RunTime = %v, want just over 10m", saved.RunTime)
+	}
+	result = newLoop(saved, &MockRunner{Responses: []MockResponse{
+		{TextContent: "Done! <promise>COMPLETE</promise>", IsComplete: true},
+		{TextContent: "YES"},
+	}}).Run(context.Background())
+	if !result.Completed {
+		t.Errorf("retried plan should complete within its limit, error: %v", result.Error)
+	}
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// ErrBlockerUnresolved is returned when a blocker stays unresolved longer
+// than blockers.max_wait.
+var ErrBlockerUnresolved = errors.New("blocker unresolved")
+
+// trackBlocker updates how long the iteration's blocker has gone unresolved
+// and applies the blockers.* escalation policy. A blocker is unresolved while
+// consecutive iterations report it; an iteration without it, or with a
+// different one, starts over. Returns ErrBlockerUnresolved once it has been
+// unresolved for blockers.max_wait.
+func (l *IterationLoop) trackBlocker(blocker *Blocker) error {
+	if blocker == nil {
+		l.ctx.Blocker = nil
+		return nil
+	}
+	if l.ctx.Blocker == nil || l.ctx.Blocker.Hash != blocker.Hash {
+		l.ctx.Blocker = &BlockerState{Hash: blocker.Hash, Since: time.Now()}
+		return nil
+	}
+	if l.config == nil {
+		return nil
+	}
+
+	unresolved := time.Since(l.ctx.Blocker.Since)
+	if maxWait := policyDuration(l.config.Blockers.MaxWait); maxWait > 0 && unresolved >= maxWait {
+		return fmt.Errorf("%w for %s: %s", ErrBlockerUnresolved, unresolved.Round(time.Minute), blocker.Description)
+	}
+	if after := policyDuration(l.config.Blockers.EscalateAfter); after > 0 && unresolved >= after && !l.ctx.Blocker.Escalated {
+		l.ctx.Blocker.Escalated = true
+		log.Warn("Blocker unresolved for %s, escalating: %s", unresolved.Round(time.Minute), blocker.Description)
+		if l.onBlockerEscalation != nil {
+			l.onBlockerEscalation(blocker, unresolved)
+		}
+	}
+	return nil
+}
+
+// policyDuration parses a blockers.* duration; empty or invalid means unset.
+func policyDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestIterationLoop_TrackBlocker(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Blockers.EscalateAfter = "1h"
+	cfg.Blockers.MaxWait = "4h"
+
+	var escalations []time.Duration
+	l := &IterationLoop{
+		ctx:    &Context{},
+		config: cfg,
+		onBlockerEscalation: func(b *Blocker, unresolved time.Duration) {
+			escalations = append(escalations, unresolved)
+		},
+	}
+	blocker := &Blocker{Description: "Need API key", Hash: "abc12345"}
+
+	// First report starts the clock
+	if err := l.trackBlocker(blocker); err != nil || l.ctx.Blocker == nil || l.ctx.Blocker.Hash != "abc12345" {
+		t.Fatalf("trackBlocker() = %v, state %+v", err, l.ctx.Blocker)
+	}
+	if err := l.trackBlocker(blocker); err != nil || len(escalations) != 0 {
+		t.Fatalf("a fresh blocker shouldn't escalate: %v, %v", err, escalations)
+	}
+
+	// Past escalate_after it escalates once
+	l.ctx.Blocker.Since = time.Now().Add(-90 * time.Minute)
+	l.trackBlocker(blocker)
+	l.trackBlocker(blocker)
+	if len(escalations) != 1 || escalations[0] < 90*time.Minute || !l.ctx.Blocker.Escalated {
+		t.Errorf("escalations = %v, state %+v, want one", escalations, l.ctx.Blocker)
+	}
+
+	// Past max_wait the loop gives up
+	l.ctx.Blocker.Since = time.Now().Add(-5 * time.Hour)
+	if err := l.trackBlocker(blocker); !errors.Is(err, ErrBlockerUnresolved) {
+		t.Errorf("trackBlocker() after max_wait = %v, want ErrBlockerUnresolved", err)
+	}
+
+	// A different blocker, or none, starts over
+	l.trackBlocker(&Blocker{Description: "Need DNS", Hash: "def67890"})
+	if l.ctx.Blocker.Hash != "def67890" || l.ctx.Blocker.Escalated || time.Since(l.ctx.Blocker.Since) > time.Minute {
+		t.Errorf("state after a new blocker = %+v", l.ctx.Blocker)
+	}
+	l.trackBlocker(nil)
+	if l.ctx.Blocker != nil {
+		t.Errorf("state after an iteration without a blocker = %+v, want nil", l.ctx.Blocker)
+	}
+}
+
+func TestIterationLoop_TrackBlocker_NoPolicy(t *testing.T) {
+	l := &IterationLoop{ctx: &Context{}, config: config.Defaults()}
+	blocker := &Blocker{Hash: "abc12345"}
+
+	l.trackBlocker(blocker)
+	l.ctx.Blocker.Since = time.Now().Add(-30 * 24 * time.Hour)
+	if err := l.trackBlocker(blocker); err != nil || l.ctx.Blocker.Escalated {
+		t.Errorf("trackBlocker() without a policy = %v, state %+v", err, l.ctx.Blocker)
+	}
+}
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// feedbackAckRegex matches <feedback-ack id="...">outcome</feedback-ack> tags.
+var feedbackAckRegex = regexp.MustCompile(`(?s)<feedback-ack\s+id="([^"]+)"\s*>(.*?)</feedback-ack>`)
+
+// FeedbackAck is the agent's acknowledgment of a processed feedback entry.
+type FeedbackAck struct {
+	// ID is the feedback entry timestamp (e.g., "2024-01-30 14:32").
+	ID string
+
+	// Outcome describes what the agent did with the feedback.
+	Outcome string
+}
+
+// ExtractFeedbackAcks extracts all feedback acknowledgments from Claude output.
+// Returns nil if no acknowledgment tags are found.
+func ExtractFeedbackAcks(output string) []FeedbackAck {
+	matches := feedbackAckRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	acks := make([]FeedbackAck, 0, len(matches))
+	for _, m := range matches {
+		id := strings.TrimSpace(m[1])
+		if id == "" {
+			continue
+		}
+		acks = append(acks, FeedbackAck{
+			ID:      id,
+			Outcome: strings.TrimSpace(m[2]),
+		})
+	}
+
+	return acks
+}
@@ -0,0 +1,32 @@
+package runner
+
+import "testing"
+
+func TestExtractFeedbackAcks(t *testing.T) {
+	output := `Handled feedback.
+<feedback-ack id="2024-01-30 14:32">Switched auth to OAuth</feedback-ack>
+Some more text.
+<feedback-ack id="2024-01-30 15:00">
+Fixed the failing login test
+</feedback-ack>`
+
+	acks := ExtractFeedbackAcks(output)
+	if len(acks) != 2 {
+		t.Fatalf("got %d acks, want 2", len(acks))
+	}
+	if acks[0].ID != "2024-01-30 14:32" || acks[0].Outcome != "Switched auth to OAuth" {
+		t.Errorf("acks[0] = %+v", acks[0])
+	}
+	if acks[1].ID != "2024-01-30 15:00" || acks[1].Outcome != "Fixed the failing login test" {
+		t.Errorf("acks[1] = %+v", acks[1])
+	}
+}
+
+func TestExtractFeedbackAcks_None(t *testing.T) {
+	if acks := ExtractFeedbackAcks("no acknowledgments here"); acks != nil {
+		t.Errorf("expected nil, got %+v", acks)
+	}
+	if acks := ExtractFeedbackAcks(`<feedback-ack id=" ">empty id</feedback-ack>`); len(acks) != 0 {
+		t.Errorf("expected no acks for empty id, got %+v", acks)
+	}
+}
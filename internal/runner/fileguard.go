@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// RejectedFile is a staged file the loop refused to commit.
+type RejectedFile struct {
+	// Path is the file path relative to the repository root.
+	Path string
+
+	// Reason says why the file was rejected, e.g. "matches deny pattern *.zip".
+	Reason string
+
+	// Ignored is true if the file was added to .gitignore.
+	Ignored bool
+}
+
+// String formats the file as "path (reason)".
+func (f RejectedFile) String() string {
+	return fmt.Sprintf("%s (%s)", f.Path, f.Reason)
+}
+
+// guardStagedFiles unstages files that match git.deny_patterns or exceed
+// git.max_file_size. New files are also added to the worktree's .gitignore
+// so they aren't picked up again; tracked files can't be ignored and are
+// left for the agent to fix. Returns the rejected files.
+func (l *IterationLoop) guardStagedFiles() ([]RejectedFile, error) {
+	if l.git == nil || l.config == nil {
+		return nil, nil
+	}
+	maxSize := l.config.Git.MaxFileSizeBytes()
+	patterns := l.config.Git.DenyPatterns
+	if maxSize == 0 && len(patterns) == 0 {
+		return nil, nil
+	}
+
+	staged, err := l.git.StagedChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	oversized := l.oversizedFiles(staged, maxSize)
+
+	var rejected []RejectedFile
+	var unstage, ignore []string
+	for _, change := range staged {
+		if change.Status == "D" || l.isRalphFile(change.Path) {
+			continue
+		}
+
+		file := RejectedFile{Path: change.Path}
+		var ignoreEntry string
+		if pattern := matchDenyPatterns(patterns, change.Path); pattern != "" {
+			file.Reason = "matches deny pattern " + pattern
+			ignoreEntry = pattern
+		} else if size, ok := oversized[change.Path]; ok {
+			file.Reason = fmt.Sprintf("%s exceeds git.max_file_size %s", FormatSize(size), l.config.Git.MaxFileSize)
+			ignoreEntry = "/" + change.Path
+		} else {
+			continue
+		}
+
+		if change.Status == "A" {
+			file.Ignored = true
+			ignore = append(ignore, ignoreEntry)
+		}
+		unstage = append(unstage, change.Path)
+		rejected = append(rejected, file)
+	}
+	if len(rejected) == 0 {
+		return nil, nil
+	}
+
+	if err := l.git.Unstage(unstage...); err != nil {
+		return nil, err
+	}
+	if len(ignore) > 0 {
+		if err := appendGitignore(l.git.WorkDir(), ignore); err != nil {
+			log.Warn("Failed to update .gitignore: %v", err)
+		} else if err := l.git.Add(".gitignore"); err != nil {
+			log.Warn("Failed to stage .gitignore: %v", err)
+		}
+	}
+	return rejected, nil
+}
+
+// oversizedFiles returns the sizes of staged files larger than maxSize.
+// Files stored with git-lfs are left out: only a small pointer is committed.
+func (l *IterationLoop) oversizedFiles(staged []git.FileChange, maxSize int64) map[string]int64 {
+	oversized := make(map[string]int64)
+	if maxSize == 0 {
+		return oversized
+	}
+
+	var paths []string
+	for _, change := range staged {
+		if change.Status == "D" {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(l.git.WorkDir(), filepath.FromSlash(change.Path)))
+		if err == nil && info.Size() > maxSize {
+			oversized[change.Path] = info.Size()
+			paths = append(paths, change.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return oversized
+	}
+
+	lfs, err := l.git.LFSTracked(paths...)
+	if err != nil {
+		log.Debug("Failed to check git-lfs attributes: %v", err)
+	}
+	for p := range lfs {
+		delete(oversized, p)
+	}
+	return oversized
+}
+
+// reportRejectedFiles asks the agent, through feedback, to deal with the
+// files the loop refused to commit, and calls onFilesRejected.
+func (l *IterationLoop) reportRejectedFiles(files []RejectedFile) {
+	var b strings.Builder
+	b.WriteString("**Files not committed:**\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`: %s", f.Path, f.Reason)
+		if f.Ignored {
+			b.WriteString(" (added to .gitignore)")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Don't commit build artifacts, archives, or large binaries. Remove these files or generate them at build time; a tracked file must be brought back within the limit or deleted with `git rm --cached`.")
+
+	for _, f := range files {
+		log.Warn("Not committing %s", f)
+	}
+	if err := plan.AppendFeedback(l.plan, "file guard", b.String()); err != nil {
+		log.Error("Failed to write file guard feedback: %v", err)
+	}
+	if l.onFilesRejected != nil {
+		l.onFilesRejected(files)
+	}
+}
+
+// matchDenyPatterns returns the first pattern that matches p, or "".
+func matchDenyPatterns(patterns []string, p string) string {
+	for _, pattern := range patterns {
+		if matchDenyPattern(pattern, p) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// matchDenyPattern reports whether the slash-separated path p matches a
+// deny pattern. A pattern ending in "/" matches everything under a
+// directory of that name; a pattern containing "/" is matched against the
+// whole path from the repository root; any other pattern is matched
+// against the file name.
+func matchDenyPattern(pattern, p string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		dir = strings.TrimPrefix(dir, "/")
+		segments := strings.Split(path.Dir(p), "/")
+		if strings.Contains(dir, "/") {
+			depth := strings.Count(dir, "/") + 1
+			if depth > len(segments) {
+				return false
+			}
+			matched, _ := path.Match(dir, strings.Join(segments[:depth], "/"))
+			return matched
+		}
+		for _, segment := range segments {
+			if matched, _ := path.Match(dir, segment); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	if strings.Contains(pattern, "/") {
+		matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), p)
+		return matched
+	}
+	matched, _ := path.Match(pattern, path.Base(p))
+	return matched
+}
+
+// appendGitignore adds entries to the .gitignore at the root of dir,
+// skipping entries it already contains.
+func appendGitignore(dir string, entries []string) error {
+	gitignore := filepath.Join(dir, ".gitignore")
+	data, err := os.ReadFile(gitignore)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var b strings.Builder
+	for _, entry := range entries {
+		if existing[entry] {
+			continue
+		}
+		existing[entry] = true
+		b.WriteString(entry + "\n")
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return os.WriteFile(gitignore, []byte(content+b.String()), 0644)
+}
+
+// FormatSize formats a byte count with a binary unit, e.g. "12.3 MB".
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
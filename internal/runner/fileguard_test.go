@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// gitOutput runs a git command in dir and returns its output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return string(out)
+}
+
+func TestMatchDenyPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.zip", "release.zip", true},
+		{"*.zip", "build/out/release.zip", true},
+		{"*.zip", "zip.go", false},
+		{"assets/*.psd", "assets/logo.psd", true},
+		{"assets/*.psd", "web/assets/logo.psd", false},
+		{"/assets/*.psd", "assets/logo.psd", true},
+		{"dist/", "dist/app.js", true},
+		{"dist/", "web/dist/chunks/app.js", true},
+		{"dist/", "dist", false},
+		{"dist/", "distribution/app.js", false},
+		{"web/dist/", "web/dist/app.js", true},
+		{"web/dist/", "dist/app.js", false},
+	}
+	for _, tt := range tests {
+		if got := matchDenyPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchDenyPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAppendGitignore(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("node_modules/\n*.zip"), 0644)
+
+	if err := appendGitignore(dir, []string{"*.zip", "/data.bin", "/data.bin"}); err != nil {
+		t.Fatalf("appendGitignore: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if got, want := string(data), "node_modules/\n*.zip\n/data.bin\n"; got != want {
+		t.Errorf(".gitignore = %q, want %q", got, want)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for n, want := range map[int64]string{512: "512 B", 2048: "2.0 KB", 12 << 20: "12.0 MB", 3 << 30: "3.0 GB"} {
+		if got := FormatSize(n); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestIterationLoop_CommitChanges_FileGuard(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.config.Git.MaxFileSize = "1KB"
+	loop.config.Git.DenyPatterns = []string{"*.zip"}
+	loop.ctx.Iteration = 1
+
+	var reported []RejectedFile
+	loop.onFilesRejected = func(files []RejectedFile) { reported = files }
+
+	writeLines(t, tempDir, "tracked.txt", 1)
+	if err := runShellCommand(tempDir, "git add tracked.txt && git commit -q -m base"); err != nil {
+		t.Fatal(err)
+	}
+
+	writeLines(t, tempDir, "main.go", 5)
+	writeLines(t, tempDir, "release.zip", 1)
+	writeLines(t, tempDir, "data.bin", 1000)
+	writeLines(t, tempDir, "tracked.txt", 2)
+
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+
+	committed := gitOutput(t, tempDir, "show", "--name-only", "--format=", "HEAD")
+	for _, want := range []string{"main.go", "tracked.txt", ".gitignore"} {
+		if !strings.Contains(committed, want) {
+			t.Errorf("commit is missing %s:\n%s", want, committed)
+		}
+	}
+	for _, unwanted := range []string{"release.zip", "data.bin"} {
+		if strings.Contains(committed, unwanted) {
+			t.Errorf("commit should not contain %s:\n%s", unwanted, committed)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, unwanted)); err != nil {
+			t.Errorf("%s should stay in the worktree: %v", unwanted, err)
+		}
+	}
+
+	gitignore, _ := os.ReadFile(filepath.Join(tempDir, ".gitignore"))
+	if got, want := string(gitignore), "/data.bin\n*.zip\n"; got != want {
+		t.Errorf(".gitignore = %q, want %q", got, want)
+	}
+
+	if len(reported) != 2 || reported[0].Path != "data.bin" || reported[1].Path != "release.zip" {
+		t.Fatalf("reported = %+v, want data.bin and release.zip", reported)
+	}
+	if got, want := reported[0].String(), "data.bin (4.9 KB exceeds git.max_file_size 1KB)"; got != want {
+		t.Errorf("reported[0] = %q, want %q", got, want)
+	}
+
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "Files not committed") || !strings.Contains(feedback, "`release.zip`: matches deny pattern *.zip (added to .gitignore)") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestIterationLoop_CommitChanges_FileGuardTracked(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.config.Git.MaxFileSize = "1KB"
+	loop.ctx.Iteration = 1
+
+	writeLines(t, tempDir, "data.csv", 10)
+	if err := runShellCommand(tempDir, "git add -A && git commit -q -m base"); err != nil {
+		t.Fatal(err)
+	}
+	head := loop.headCommit()
+
+	// A tracked file growing past the limit is unstaged but can't be ignored
+	writeLines(t, tempDir, "data.csv", 1000)
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+	if loop.headCommit() != head {
+		t.Error("nothing should be committed when every change is rejected")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".gitignore")); !os.IsNotExist(err) {
+		t.Error("a tracked file should not be added to .gitignore")
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "`data.csv`: 4.9 KB exceeds git.max_file_size 1KB\n") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestIterationLoop_CommitChanges_FileGuardLFS(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.config.Git.MaxFileSize = "1KB"
+	loop.ctx.Iteration = 1
+
+	os.WriteFile(filepath.Join(tempDir, ".gitattributes"), []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), 0644)
+	if err := runShellCommand(tempDir, "git add -A && git commit -q -m base"); err != nil {
+		t.Fatal(err)
+	}
+
+	// git-lfs files are committed as small pointers, so their size doesn't count
+	writeLines(t, tempDir, "art/logo.psd", 1000)
+	writeLines(t, tempDir, "data.bin", 1000)
+	os.WriteFile(filepath.Join(tempDir, ".gitattributes"), []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n*.png binary\n"), 0644)
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".gitignore")); err == nil {
+		data, _ := os.ReadFile(filepath.Join(tempDir, ".gitignore"))
+		if strings.Contains(string(data), "logo.psd") {
+			t.Errorf(".gitignore = %q, git-lfs files should not be rejected", data)
+		}
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if strings.Contains(feedback, "logo.psd") || !strings.Contains(feedback, "`data.bin`") {
+		t.Errorf("feedback = %q, want only data.bin rejected", feedback)
+	}
+}
@@ -0,0 +1,24 @@
+package runner
+
+import (
+	"github.com/arvesolland/ralph/internal/knowledge"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// knowledgeSection returns the knowledge base entries relevant to the plan's
+// scope, labels, and changes so far, within knowledge.max_entries and
+// knowledge.max_tokens. The knowledge base is re-read every iteration so
+// edits take effect right away. Returns "" without knowledge.enabled or
+// matching entries.
+func (l *IterationLoop) knowledgeSection() string {
+	if l.knowledgePath == "" || l.config == nil || !l.config.Knowledge.Enabled {
+		return ""
+	}
+	store, err := knowledge.Load(l.knowledgePath)
+	if err != nil {
+		log.Warn("Skipping knowledge base: %v", err)
+		return ""
+	}
+	entries := store.Relevant(knowledge.QueryFor(l.plan), l.config.Knowledge.MaxEntries)
+	return knowledge.Format(entries, l.config.Knowledge.MaxTokens)
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/knowledge"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestIterationLoop_KnowledgeSection(t *testing.T) {
+	dir := t.TempDir()
+	path := knowledge.Path(dir)
+	store, _ := knowledge.Load(path)
+	store.Add(knowledge.Entry{Keys: []string{"internal/worker/**"}, Text: "Reset cobra flags in each test"})
+	store.Add(knowledge.Entry{Keys: []string{"internal/notify/**"}, Text: "Slack blocks cap at 50"})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Defaults()
+	loop := &IterationLoop{
+		plan:          &plan.Plan{Name: "fix-worker", Path: filepath.Join(dir, "fix-worker.md"), Scope: []string{"internal/worker/"}},
+		config:        cfg,
+		knowledgePath: path,
+	}
+	if got := loop.knowledgeSection(); got != "" {
+		t.Errorf("knowledgeSection() without knowledge.enabled = %q", got)
+	}
+
+	cfg.Knowledge.Enabled = true
+	got := loop.knowledgeSection()
+	if !strings.Contains(got, "Reset cobra flags in each test") || strings.Contains(got, "Slack blocks") {
+		t.Errorf("knowledgeSection() = %q, want only the worker entry", got)
+	}
+}
@@ -3,11 +3,18 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/bench"
 	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/gate"
 	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/hooks"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/prompt"
@@ -19,6 +26,26 @@ const IterationCooldown = 3 * time.Second
 // IterationTimeout is the default timeout for a single iteration.
 const IterationTimeout = 30 * time.Minute
 
+// ErrPlanSkipped is returned when the plan is marked skipped via the control plane.
+var ErrPlanSkipped = errors.New("plan skipped via control plane")
+
+// ErrPlanAbandoned is returned when the plan is abandoned via the control plane.
+var ErrPlanAbandoned = errors.New("plan abandoned via control plane")
+
+// ErrStopRequested is returned when the loop stops between iterations because
+// a graceful shutdown was requested.
+var ErrStopRequested = errors.New("stop requested")
+
+// ErrMaxIterations is returned when the loop runs out of iterations without
+// the plan being verified complete.
+var ErrMaxIterations = errors.New("max iterations reached without completion")
+
+// controlPollInterval is how often a paused loop re-checks the control plane.
+var controlPollInterval = 5 * time.Second
+
+// maxHookOutputLen bounds pre_iteration output included in prompts and feedback.
+const maxHookOutputLen = 4000
+
 // LoopResult represents the outcome of the iteration loop.
 type LoopResult struct {
 	// Completed is true if the plan was verified complete.
@@ -32,6 +59,12 @@ type LoopResult struct {
 
 	// Error is the error that caused termination, if any.
 	Error error
+
+	// Coverage is the test coverage measured with coverage.command, if any.
+	Coverage *CoverageState
+
+	// Bench is the benchmark comparison with the base branch, if bench.command ran.
+	Bench *gate.Result
 }
 
 // IterationLoop manages the main execution loop for plan completion.
@@ -58,6 +91,9 @@ type IterationLoop struct {
 	// worktreePath is the path to the execution worktree
 	worktreePath string
 
+	// env is added to the environment of every agent run
+	env []string
+
 	// iterationTimeout is the timeout for each iteration
 	iterationTimeout time.Duration
 
@@ -66,6 +102,66 @@ type IterationLoop struct {
 
 	// onBlocker is called when a blocker is detected
 	onBlocker func(blocker *Blocker)
+
+	// onAsk is called with a question the agent asked and how long the loop waits for the answer
+	onAsk func(question string, timeout time.Duration)
+
+	// onBlockerEscalation is called once when a blocker has been unresolved for blockers.escalate_after
+	onBlockerEscalation func(blocker *Blocker, unresolved time.Duration)
+
+	// onUrgentFeedback is called when urgent feedback stays pending across iterations
+	onUrgentFeedback func(entries []plan.FeedbackEntry)
+
+	// onVerificationFailed is called when the agent claims completion but verification disagrees
+	onVerificationFailed func(reason string)
+
+	// onReview is called with both verdicts when a second verifier is configured
+	onReview func(review *Review)
+
+	// onStageChange is called when the plan moves from one stage to the next
+	onStageChange func(from, to string)
+
+	// onFilesRejected is called when staged files are held back from a commit
+	onFilesRejected func(files []RejectedFile)
+
+	// onCoverage is called with each coverage measurement (iteration 0 is the base branch)
+	onCoverage func(iteration int, percent float64)
+
+	// onBenchRegression is called when benchmarks regress beyond bench.threshold
+	onBenchRegression func(regressions []bench.Delta)
+
+	// checkWorktree detects and repairs worktree problems before each iteration
+	checkWorktree func() error
+
+	// beforeIteration is called before each iteration's prompt is built
+	beforeIteration func(iteration int)
+
+	// urgentSeen records the iteration each urgent feedback entry was first seen pending
+	urgentSeen map[string]int
+
+	// urgentNotified records urgent feedback entries already notified
+	urgentNotified map[string]bool
+
+	// control is the worker control plane (pause/skip), checked between iterations
+	control *control.Store
+
+	// stop is closed to request that the loop stop after the in-flight iteration
+	stop <-chan struct{}
+
+	// audit records each iteration's commits (nil = off)
+	audit *audit.Log
+
+	// knowledgePath is the knowledge base relevant entries are taken from
+	knowledgePath string
+
+	// tddGate is the test gate run after the latest iteration of a test-first plan
+	tddGate *gate.Result
+
+	// benchGate is the latest benchmark comparison with the base branch
+	benchGate *gate.Result
+
+	// answer is the reply to the agent's question, added to the next prompt
+	answer *Answer
 }
 
 // LoopConfig holds configuration for creating an IterationLoop.
@@ -80,6 +176,59 @@ type LoopConfig struct {
 	IterationTimeout time.Duration
 	OnIteration      func(iteration int, result *Result)
 	OnBlocker        func(blocker *Blocker)
+	OnUrgentFeedback func(entries []plan.FeedbackEntry)
+	Control          *control.Store
+
+	// Env is added to the environment of every agent run, e.g. the plan's
+	// RALPH_SCRATCH
+	Env []string
+
+	// OnVerificationFailed is called when a completion claim fails verification
+	OnVerificationFailed func(reason string)
+
+	// OnReview is called with both verdicts of a two-person verification
+	// (completion.second_verifier)
+	OnReview func(review *Review)
+
+	// OnStageChange is called when the plan finishes a stage and moves to the next
+	OnStageChange func(from, to string)
+
+	// OnFilesRejected is called when denied or oversized files are held back from a commit
+	OnFilesRejected func(files []RejectedFile)
+
+	// OnCoverage is called with the coverage measured before the first
+	// iteration (iteration 0, the base branch) and after each iteration
+	OnCoverage func(iteration int, percent float64)
+
+	// OnBenchRegression is called when a completion claim's benchmarks are
+	// more than bench.threshold worse than the base branch
+	OnBenchRegression func(regressions []bench.Delta)
+
+	// OnBlockerEscalation is called when a blocker has gone unresolved for
+	// blockers.escalate_after
+	OnBlockerEscalation func(blocker *Blocker, unresolved time.Duration)
+
+	// OnAsk is called when the agent asks a question with <ask>, before the
+	// loop waits up to timeout for the answer
+	OnAsk func(question string, timeout time.Duration)
+
+	// CheckWorktree is called before each iteration to detect and repair a
+	// broken worktree; an error fails the iteration
+	CheckWorktree func() error
+
+	// BeforeIteration is called with the iteration about to run, after the
+	// worktree check and before the prompt is built, e.g. to pull in plan edits
+	BeforeIteration func(iteration int)
+
+	// Stop, when closed, stops the loop after the in-flight iteration finishes
+	Stop <-chan struct{}
+
+	// Audit records each iteration's commits (optional)
+	Audit *audit.Log
+
+	// KnowledgePath is the knowledge base whose entries relevant to the plan
+	// are added to each prompt with knowledge.enabled (optional)
+	KnowledgePath string
 }
 
 // NewIterationLoop creates a new iteration loop with the given configuration.
@@ -90,16 +239,34 @@ func NewIterationLoop(cfg LoopConfig) *IterationLoop {
 	}
 
 	return &IterationLoop{
-		plan:             cfg.Plan,
-		ctx:              cfg.Context,
-		config:           cfg.Config,
-		runner:           cfg.Runner,
-		git:              cfg.Git,
-		promptBuilder:    cfg.PromptBuilder,
-		worktreePath:     cfg.WorktreePath,
-		iterationTimeout: timeout,
-		onIteration:      cfg.OnIteration,
-		onBlocker:        cfg.OnBlocker,
+		plan:                 cfg.Plan,
+		ctx:                  cfg.Context,
+		config:               cfg.Config,
+		runner:               cfg.Runner,
+		git:                  cfg.Git,
+		promptBuilder:        cfg.PromptBuilder,
+		worktreePath:         cfg.WorktreePath,
+		env:                  cfg.Env,
+		iterationTimeout:     timeout,
+		onIteration:          cfg.OnIteration,
+		onBlocker:            cfg.OnBlocker,
+		onUrgentFeedback:     cfg.OnUrgentFeedback,
+		urgentSeen:           make(map[string]int),
+		urgentNotified:       make(map[string]bool),
+		control:              cfg.Control,
+		onVerificationFailed: cfg.OnVerificationFailed,
+		onReview:             cfg.OnReview,
+		onCoverage:           cfg.OnCoverage,
+		onBenchRegression:    cfg.OnBenchRegression,
+		onStageChange:        cfg.OnStageChange,
+		onFilesRejected:      cfg.OnFilesRejected,
+		onBlockerEscalation:  cfg.OnBlockerEscalation,
+		onAsk:                cfg.OnAsk,
+		checkWorktree:        cfg.CheckWorktree,
+		beforeIteration:      cfg.BeforeIteration,
+		stop:                 cfg.Stop,
+		audit:                cfg.Audit,
+		knowledgePath:        cfg.KnowledgePath,
 	}
 }
 
@@ -108,27 +275,66 @@ func NewIterationLoop(cfg LoopConfig) *IterationLoop {
 func (l *IterationLoop) Run(ctx context.Context) *LoopResult {
 	result := &LoopResult{}
 
+	// Start or resume the configured stage pipeline
+	l.initStage()
+	l.initTDD()
+
+	// Pick up an iteration that already ran before a crash or restart
+	resumed := l.resumeCheckpoint()
+
+	// Measure the base branch's coverage and benchmarks before the first iteration changes it
+	if l.ctx.Iteration == 1 && resumed == nil {
+		l.measureCoverage(ctx, 0)
+		l.measureBenchBase(ctx)
+	}
+
 	for !l.ctx.IsMaxReached() {
-		// Check for context cancellation
+		// Check for cancellation or a graceful stop request
 		select {
 		case <-ctx.Done():
 			result.Error = ctx.Err()
 			return result
+		case <-l.stop:
+			log.Info("Stopping before iteration %d as requested", l.ctx.Iteration)
+			result.Error = ErrStopRequested
+			return result
 		default:
 		}
 
-		log.Info("Starting iteration %d/%d", l.ctx.Iteration, l.ctx.MaxIterations)
-
-		// Run single iteration
-		iterResult, err := l.runIteration(ctx)
-		result.Iterations = l.ctx.Iteration
-
-		if err != nil {
-			log.Error("Iteration %d failed: %v", l.ctx.Iteration, err)
+		// Honor pause/skip requests before starting the next iteration
+		if err := l.waitForControl(ctx); err != nil {
 			result.Error = err
 			return result
 		}
 
+		iterResult := resumed
+		resumed = nil
+		if iterResult == nil {
+			if err := l.checkPlanDuration(); err != nil {
+				log.Error("%v", err)
+				result.Error = err
+				return result
+			}
+			log.Info("Starting iteration %d/%d", l.ctx.Iteration, l.ctx.MaxIterations)
+
+			// Run single iteration, counting its time against the max duration
+			var err error
+			started := time.Now()
+			iterResult, err = l.runIteration(ctx)
+			l.ctx.RunTime += time.Since(started)
+			if err != nil {
+				// Keep the time spent for the retry
+				if err := SaveContext(l.ctx, ContextPath(l.worktreePath)); err != nil {
+					log.Error("Failed to save context: %v", err)
+				}
+				result.Iterations = l.ctx.Iteration
+				log.Error("Iteration %d failed: %v", l.ctx.Iteration, err)
+				result.Error = err
+				return result
+			}
+		}
+		result.Iterations = l.ctx.Iteration
+
 		// Call iteration hook if set
 		if l.onIteration != nil {
 			l.onIteration(l.ctx.Iteration, iterResult)
@@ -143,30 +349,47 @@ func (l *IterationLoop) Run(ctx context.Context) *LoopResult {
 			}
 			// Continue - agent may have worked on other tasks
 		}
+		if err := l.trackBlocker(iterResult.Blocker); err != nil {
+			log.Error("%v", err)
+			result.Error = err
+			return result
+		}
 
-		// Check for completion
-		if iterResult.IsComplete {
-			log.Info("Completion marker detected, verifying...")
-
-			// Verify completion with configured model
-			verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
-			verifyResult, verifyErr := Verify(verifyCtx, l.plan, l.runner, l.config.Completion.VerificationModel)
-			cancel()
-
-			if verifyErr != nil {
-				log.Warn("Verification failed: %v", verifyErr)
-				// Continue anyway - let next iteration try again
-			} else if verifyResult.Verified {
-				log.Success("Plan verified complete!")
-				result.Completed = true
-				return result
-			} else {
-				log.Warn("Verification failed: %s", verifyResult.Reason)
-				// Write feedback for next iteration
-				if err := l.writeFeedback(verifyResult.Reason); err != nil {
-					log.Error("Failed to write verification feedback: %v", err)
+		// Wait for the human to answer the agent's question
+		if iterResult.Question != "" {
+			l.answer = l.askHuman(ctx, iterResult.Question)
+		}
+
+		// Escalate urgent feedback the agent hasn't processed
+		l.checkUrgentFeedback()
+
+		// Run the tests between test-first phases
+		l.advanceTDD(ctx)
+
+		// Track coverage after each iteration
+		l.measureCoverage(ctx, l.ctx.Iteration)
+
+		// Check for completion of the current stage, or the plan
+		if stage, last := l.currentStage(); stage != nil {
+			if l.stageDone(ctx, iterResult, stage, last) && (!last || l.benchAllowsCompletion(ctx)) {
+				if !l.advanceStage() {
+					log.Success("Final stage %s complete, plan done!", stage.Name)
+					result.Completed = true
+					result.Coverage = l.ctx.Coverage
+					result.Bench = l.benchGate
+					return result
 				}
+			} else if stage.MaxIterations > 0 && l.ctx.StageIteration >= stage.MaxIterations {
+				log.Error("Stage %s used all %d of its iterations without completing", stage.Name, stage.MaxIterations)
+				result.Error = fmt.Errorf("%w: stage %s (%d)", ErrMaxIterations, stage.Name, stage.MaxIterations)
+				return result
 			}
+		} else if iterResult.IsComplete && l.tddAllowsCompletion() && l.verifyCompletion(ctx) && l.benchAllowsCompletion(ctx) {
+			log.Success("Plan verified complete!")
+			result.Completed = true
+			result.Coverage = l.ctx.Coverage
+			result.Bench = l.benchGate
+			return result
 		}
 
 		// Increment iteration for next round
@@ -185,27 +408,171 @@ func (l *IterationLoop) Run(ctx context.Context) *LoopResult {
 		case <-ctx.Done():
 			result.Error = ctx.Err()
 			return result
+		case <-l.stop:
 		case <-time.After(IterationCooldown):
 		}
 	}
 
 	// Max iterations reached
 	log.Error("Max iterations (%d) reached without completion", l.ctx.MaxIterations)
-	result.Error = fmt.Errorf("max iterations (%d) reached without completion", l.ctx.MaxIterations)
+	result.Error = fmt.Errorf("%w (%d)", ErrMaxIterations, l.ctx.MaxIterations)
 	return result
 }
 
+// verifyCompletion checks a completion claim with the verification model.
+// A failed check is reported and written to the feedback file for the next
+// iteration; an error running the check just lets the next iteration try again.
+func (l *IterationLoop) verifyCompletion(ctx context.Context) bool {
+	log.Info("Completion marker detected, verifying...")
+
+	verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+	verifyResult, verifyErr := Verify(verifyCtx, l.plan, l.runner, l.config.Completion.VerificationModel)
+	cancel()
+
+	if verifyErr != nil {
+		log.Warn("Verification failed: %v", verifyErr)
+		return false
+	}
+
+	reason := verifyResult.Reason
+	if second := l.config.Completion.SecondVerifier; second.Enabled() {
+		review, err := l.review(ctx, verifyResult, Verifier{Model: second.Model, Binary: second.ClaudePath})
+		if err != nil {
+			log.Warn("Second verification failed: %v", err)
+			return false
+		}
+		if review.Verified() {
+			return true
+		}
+		reason = review.Reason()
+	} else if verifyResult.Verified {
+		return true
+	}
+
+	log.Warn("Verification failed: %s", reason)
+	if l.onVerificationFailed != nil {
+		l.onVerificationFailed(reason)
+	}
+	// Write feedback for next iteration
+	if err := l.writeFeedback(reason); err != nil {
+		log.Error("Failed to write verification feedback: %v", err)
+	}
+	return false
+}
+
+// review runs the second verifier on a completion claim and records both
+// verdicts in the progress file, so the check isn't graded by one model alone.
+func (l *IterationLoop) review(ctx context.Context, primary *VerificationResult, second Verifier) (*Review, error) {
+	verifyCtx, cancel := context.WithTimeout(ctx, VerificationTimeout)
+	secondResult, err := VerifyWith(verifyCtx, l.plan, l.runner, second)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	review := &Review{Verdicts: []Verdict{
+		{Verifier: Verifier{Model: l.config.Completion.VerificationModel}, Verified: primary.Verified, Reason: primary.Reason},
+		{Verifier: second, Verified: secondResult.Verified, Reason: secondResult.Reason},
+	}}
+
+	verdicts := make([]string, len(review.Verdicts))
+	for i, v := range review.Verdicts {
+		verdicts[i] = v.String()
+	}
+	if review.Disagreement() {
+		log.Warn("Verifiers disagree: %s", review)
+		verdicts = append(verdicts, "Verifiers disagree; the plan is not complete until both say YES")
+	}
+	if err := plan.AppendVerification(l.plan, l.ctx.Iteration, verdicts, time.Now()); err != nil {
+		log.Warn("Failed to record verification in progress file: %v", err)
+	}
+
+	if l.onReview != nil {
+		l.onReview(review)
+	}
+	return review, nil
+}
+
+// waitForControl blocks while the worker is paused and returns ErrPlanAbandoned
+// or ErrPlanSkipped if the plan has been marked abandoned or skipped, or
+// ErrStopRequested if a stop is requested while paused.
+// Returns nil if no control store is set.
+func (l *IterationLoop) waitForControl(ctx context.Context) error {
+	if l.control == nil {
+		return nil
+	}
+
+	logged := false
+	for {
+		if l.control.AbandonRequest(l.plan.Name) != nil {
+			log.Warn("Plan %s abandoned via control plane", l.plan.Name)
+			return ErrPlanAbandoned
+		}
+		if l.control.IsSkipped(l.plan.Name) {
+			log.Warn("Plan %s skipped via control plane", l.plan.Name)
+			return ErrPlanSkipped
+		}
+		if !l.control.IsPaused() {
+			if logged {
+				log.Info("Worker resumed")
+			}
+			return nil
+		}
+
+		if !logged {
+			log.Info("Worker paused, waiting for resume...")
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-l.stop:
+			log.Info("Stopping while paused as requested")
+			return ErrStopRequested
+		case <-time.After(controlPollInterval):
+		}
+	}
+}
+
 // runIteration executes a single iteration of the loop.
 func (l *IterationLoop) runIteration(ctx context.Context) (*Result, error) {
+	// Make sure git works in the worktree before spending an iteration on it
+	if l.checkWorktree != nil {
+		if err := l.checkWorktree(); err != nil {
+			return nil, err
+		}
+	}
+	if l.beforeIteration != nil {
+		l.beforeIteration(l.ctx.Iteration)
+	}
+
+	// Refresh the environment before prompting
+	hookOutput := l.runPreIterationHook(ctx)
+
 	// Build the prompt
-	prompt, err := l.buildPrompt()
+	prompt, err := l.buildPrompt(hookOutput)
 	if err != nil {
 		return nil, fmt.Errorf("building prompt: %w", err)
 	}
 
+	// Checkpoint before executing so a restart knows this iteration was in flight
+	cp := &Checkpoint{
+		Iteration:  l.ctx.Iteration,
+		Phase:      PhaseStarted,
+		PromptHash: hashPrompt(prompt),
+		HeadBefore: l.headCommit(),
+	}
+	l.saveCheckpoint(cp)
+
 	// Set up options for Claude
 	opts := DefaultOptions()
 	opts.WorkDir = l.worktreePath
+	opts.Env = l.env
+	opts.Model = l.model()
+	if opts.Model != "" {
+		log.Debug("Using model %s", opts.Model)
+	}
 
 	// Create timeout context for this iteration
 	iterCtx, cancel := context.WithTimeout(ctx, l.iterationTimeout)
@@ -217,6 +584,41 @@ func (l *IterationLoop) runIteration(ctx context.Context) (*Result, error) {
 		return result, fmt.Errorf("claude execution: %w", err)
 	}
 
+	cp.Phase = PhaseExecuted
+	cp.Duration = result.Duration
+	cp.PendingVerification = result.IsComplete
+	cp.Blocker = result.Blocker
+	cp.FeedbackAcks = result.FeedbackAcks
+	cp.Question = result.Question
+	if !result.Tools.Empty() {
+		cp.Tools = &result.Tools
+	}
+	if !result.Resources.Empty() {
+		cp.Resources = &result.Resources
+	}
+	l.saveCheckpoint(cp)
+	l.checkResources(result)
+
+	l.finishIteration(result, cp)
+	return result, nil
+}
+
+// checkResources flags the iteration if its process tree used more than
+// runner.resource_alerts allows, e.g. because of runaway test processes.
+func (l *IterationLoop) checkResources(result *Result) {
+	if l.config == nil {
+		return
+	}
+	result.ResourceAlerts = ResourceAlerts(result.Resources, l.config.Runner.ResourceAlerts)
+	if len(result.ResourceAlerts) > 0 {
+		log.Warn("Iteration %d used too many resources: %s", l.ctx.Iteration, strings.Join(result.ResourceAlerts, ", "))
+	}
+}
+
+// finishIteration records an executed iteration: reloads the plan, acknowledges
+// feedback, appends progress, and commits unless the diff exceeds the
+// configured limits, then records the commit's changes and checkpoints it.
+func (l *IterationLoop) finishIteration(result *Result, cp *Checkpoint) {
 	// Reload the plan to get updated content
 	updatedPlan, err := plan.Load(l.plan.Path)
 	if err != nil {
@@ -226,6 +628,15 @@ func (l *IterationLoop) runIteration(ctx context.Context) (*Result, error) {
 		l.plan = updatedPlan
 	}
 
+	// Move acknowledged feedback to Processed
+	l.acknowledgeFeedback(result)
+
+	// Hold back a runaway iteration instead of committing it
+	overrun := l.checkDiffLimit()
+	if overrun != "" {
+		l.holdDiff(result, overrun)
+	}
+
 	// Append to progress file
 	if err := l.appendProgress(result); err != nil {
 		log.Error("Failed to append progress: %v", err)
@@ -233,48 +644,239 @@ func (l *IterationLoop) runIteration(ctx context.Context) (*Result, error) {
 	}
 
 	// Commit changes
-	if err := l.commitChanges(); err != nil {
-		log.Error("Failed to commit changes: %v", err)
-		// Non-fatal, continue
+	if overrun == "" {
+		if err := l.commitChanges(); err != nil {
+			log.Error("Failed to commit changes: %v", err)
+			// Non-fatal, continue
+		}
 	}
 
-	return result, nil
+	cp.Phase = PhaseCommitted
+	cp.Commit = l.headCommit()
+	result.HeadBefore, result.Commit = cp.HeadBefore, cp.Commit
+	l.recordCommit(cp)
+	l.recordChanges(cp)
+	l.auditCommits(cp)
+	l.saveCheckpoint(cp)
+}
+
+// resumeCheckpoint returns the result of the current iteration if its
+// checkpoint shows Claude already ran, finishing any steps that were
+// interrupted before the commit. Returns nil if the iteration needs to run.
+func (l *IterationLoop) resumeCheckpoint() *Result {
+	if l.worktreePath == "" {
+		return nil
+	}
+
+	cp, err := LoadCheckpoint(CheckpointPath(l.worktreePath))
+	if err != nil || cp.Iteration != l.ctx.Iteration {
+		return nil
+	}
+
+	switch cp.Phase {
+	case PhaseExecuted:
+		result := cp.Result()
+		// A crash between the commit and the checkpoint leaves the commit in place
+		if sha := l.landedCommit(cp); sha != "" {
+			log.Info("Resuming iteration %d from checkpoint: already committed in %s, recording results", cp.Iteration, sha)
+			if updatedPlan, err := plan.Load(l.plan.Path); err == nil {
+				l.plan = updatedPlan
+			}
+			cp.Phase = PhaseCommitted
+			cp.Commit = l.headCommit()
+			result.Commit = cp.Commit
+			l.recordCommit(cp)
+			l.recordChanges(cp)
+			l.auditCommits(cp)
+			l.saveCheckpoint(cp)
+			return result
+		}
+		log.Info("Resuming iteration %d from checkpoint: execution finished, recording results", cp.Iteration)
+		l.finishIteration(result, cp)
+		return result
+	case PhaseCommitted:
+		log.Info("Resuming iteration %d from checkpoint: already committed, skipping execution", cp.Iteration)
+		if updatedPlan, err := plan.Load(l.plan.Path); err == nil {
+			l.plan = updatedPlan
+		}
+		return cp.Result()
+	default:
+		log.Info("Iteration %d was interrupted during execution, running it again", cp.Iteration)
+		return nil
+	}
+}
+
+// saveCheckpoint saves the checkpoint to the worktree. Failures are logged.
+func (l *IterationLoop) saveCheckpoint(cp *Checkpoint) {
+	if l.worktreePath == "" {
+		return
+	}
+	if err := SaveCheckpoint(cp, CheckpointPath(l.worktreePath)); err != nil {
+		log.Error("Failed to save checkpoint: %v", err)
+	}
+}
+
+// headCommit returns the worktree's HEAD commit, or "" if unavailable.
+func (l *IterationLoop) headCommit() string {
+	if l.git == nil {
+		return ""
+	}
+	sha, err := l.git.HeadCommit()
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// runPreIterationHook runs hooks.pre_iteration in the worktree.
+// Returns the hook output when capture_pre_iteration is enabled.
+// Failures are written to the feedback file so the agent sees them this iteration.
+func (l *IterationLoop) runPreIterationHook(ctx context.Context) string {
+	if l.config == nil || strings.TrimSpace(l.config.Hooks.PreIteration) == "" {
+		return ""
+	}
+
+	log.Info("Running pre_iteration hook...")
+	r := hooks.NewRunner(l.worktreePath)
+	results := r.Run(ctx, []string{l.config.Hooks.PreIteration}, hooks.Payload{
+		Event:      hooks.EventPreIteration,
+		Plan:       l.plan.Name,
+		Branch:     l.plan.Branch,
+		Iterations: l.ctx.Iteration,
+	})
+	if len(results) == 0 {
+		return ""
+	}
+
+	res := results[0]
+	output := truncate(strings.TrimSpace(res.Output), maxHookOutputLen)
+	if res.Err != nil {
+		content := fmt.Sprintf("**Pre-iteration hook failed:** `%s`\n```\n%s\n```", res.Hook, output)
+		if err := plan.AppendFeedback(l.plan, "pre_iteration", content); err != nil {
+			log.Error("Failed to write pre_iteration feedback: %v", err)
+		}
+	}
+
+	if !l.config.Hooks.CapturePreIteration {
+		return ""
+	}
+	return output
 }
 
 // buildPrompt builds the prompt for Claude using the template builder.
-func (l *IterationLoop) buildPrompt() (string, error) {
+// hookOutput, if non-empty, is appended as a pre-iteration hook section.
+func (l *IterationLoop) buildPrompt(hookOutput string) (string, error) {
+	// Re-read every iteration so edits take effect without a restart
+	planInstructions, err := plan.ReadInstructions(l.plan)
+	if err != nil {
+		log.Warn("Ignoring plan instructions: %v", err)
+	}
+
 	// Build context overrides for placeholders
 	overrides := map[string]string{
-		"ITERATION":      fmt.Sprintf("%d", l.ctx.Iteration),
-		"MAX_ITERATIONS": fmt.Sprintf("%d", l.ctx.MaxIterations),
-		"FEATURE_BRANCH": l.ctx.FeatureBranch,
-		"BASE_BRANCH":    l.ctx.BaseBranch,
-		"PLAN_FILE":      l.ctx.PlanFile,
+		"ITERATION":         fmt.Sprintf("%d", l.ctx.Iteration),
+		"MAX_ITERATIONS":    fmt.Sprintf("%d", l.ctx.MaxIterations),
+		"FEATURE_BRANCH":    l.ctx.FeatureBranch,
+		"BASE_BRANCH":       l.ctx.BaseBranch,
+		"PLAN_FILE":         l.ctx.PlanFile,
+		"PLAN_INSTRUCTIONS": planInstructions,
+		"STAGE":             l.ctx.Stage,
 	}
 
 	// Build the main prompt
-	content, err := l.promptBuilder.Build("prompt.md", overrides)
+	content, err := l.promptBuilder.Build(l.promptTemplate(), overrides)
 	if err != nil {
 		return "", fmt.Errorf("building prompt: %w", err)
 	}
+	content += l.stageSection()
+	content += l.tddSection()
+	content += l.answerSection()
+	l.answer = nil
+	if progress, err := plan.LoadProgress(l.plan); err != nil {
+		log.Warn("Skipping progress summary: %v", err)
+	} else {
+		content += prompt.ProgressSummary(progress)
+	}
+	content += l.knowledgeSection()
+	content += prompt.RecentCommits(l.git, l.ctx.BaseBranch, l.ctx.FeatureBranch, l.config.Git.RecentCommits)
+
+	if hookOutput != "" {
+		content += fmt.Sprintf("\n\n## Pre-iteration Hook Output\n\nOutput of `%s`:\n```\n%s\n```\n", l.config.Hooks.PreIteration, hookOutput)
+	}
 
 	return content, nil
 }
 
-// appendProgress appends iteration results to the progress file.
+// appendProgress appends iteration results to the progress file: the
+// duration and changed files as structured fields, the rest as notes.
 func (l *IterationLoop) appendProgress(result *Result) error {
 	// Build progress entry
 	content := fmt.Sprintf("Claude execution completed in %v.\n", result.Duration)
 
+	if summary := result.Tools.Summary(); summary != "" {
+		content += fmt.Sprintf("Tools: %s.\n", summary)
+	}
+
+	if summary := ResourceSummary(&result.Resources); summary != "" {
+		content += fmt.Sprintf("Resources: %s.\n", summary)
+	}
+
+	if len(result.ResourceAlerts) > 0 {
+		content += fmt.Sprintf("Resource alert: %s.\n", strings.Join(result.ResourceAlerts, ", "))
+	}
+
+	if len(result.Orphans) > 0 {
+		if l.config != nil && l.config.Runner.OrphanProcesses == config.OrphanProcessesReport {
+			content += fmt.Sprintf("Left running: %s.\n", strings.Join(result.Orphans, ", "))
+		} else {
+			content += fmt.Sprintf("Killed leftover processes: %s.\n", strings.Join(result.Orphans, ", "))
+		}
+	}
+
+	if phase := l.tddPhase(); phase != "" {
+		content += fmt.Sprintf("Test-first phase: %s.\n", phase)
+	}
+
 	if result.IsComplete {
 		content += "Completion marker detected.\n"
 	}
 
+	if result.DiffLimit != "" {
+		content += fmt.Sprintf("Not committed, diff too large: %s.\n", result.DiffLimit)
+	}
+
 	if result.Blocker != nil {
 		content += fmt.Sprintf("Blocker: %s\n", result.Blocker.Description)
 	}
 
-	return plan.AppendProgress(l.plan, l.ctx.Iteration, content)
+	if result.Question != "" {
+		content += fmt.Sprintf("Question: %s\n", result.Question)
+	}
+
+	var files []string
+	for _, f := range result.Tools.Files {
+		if !l.isRalphFile(f) {
+			files = append(files, f)
+		}
+	}
+
+	return plan.AppendIteration(l.plan, plan.ProgressEntry{
+		Iteration: l.ctx.Iteration,
+		Files:     files,
+		Duration:  result.Duration,
+		Notes:     content,
+	})
+}
+
+// recordCommit adds the iteration's commit to its progress entry, if it
+// committed anything. The entry is committed with the next iteration.
+func (l *IterationLoop) recordCommit(cp *Checkpoint) {
+	if cp.Commit == "" || cp.Commit == cp.HeadBefore {
+		return
+	}
+	if err := plan.SetIterationCommit(l.plan, cp.Iteration, cp.Commit, ""); err != nil {
+		log.Debug("Failed to record commit in progress: %v", err)
+	}
 }
 
 // commitChanges commits all changes after an iteration.
@@ -296,11 +898,32 @@ func (l *IterationLoop) commitChanges() error {
 		return fmt.Errorf("staging changes: %w", err)
 	}
 
-	// Build commit message
-	message := fmt.Sprintf("ralph: iteration %d", l.ctx.Iteration)
+	// Revert changes outside the tests in a test-first test iteration
+	reverted, err := l.guardTestPhase()
+	if err != nil {
+		log.Warn("Failed to check test iteration changes: %v", err)
+	}
+	if len(reverted) > 0 {
+		l.reportTestPhaseFiles(reverted)
+	}
+
+	// Hold back denied and oversized files
+	rejected, err := l.guardStagedFiles()
+	if err != nil {
+		log.Warn("Failed to check staged files: %v", err)
+	}
+	if len(rejected) > 0 {
+		l.reportRejectedFiles(rejected)
+	}
+	if len(rejected) > 0 || len(reverted) > 0 {
+		if staged, err := l.git.StagedChanges(); err == nil && len(staged) == 0 {
+			log.Debug("No changes left to commit")
+			return nil
+		}
+	}
 
-	// Commit
-	if err := l.git.Commit(message); err != nil {
+	// Commit, with trailers identifying the plan, iteration, and run
+	if err := l.git.Commit(l.commitMessage()); err != nil {
 		return fmt.Errorf("committing: %w", err)
 	}
 
@@ -308,6 +931,50 @@ func (l *IterationLoop) commitChanges() error {
 	return nil
 }
 
+// acknowledgeFeedback moves each feedback entry the agent acknowledged to Processed.
+func (l *IterationLoop) acknowledgeFeedback(result *Result) {
+	for _, ack := range result.FeedbackAcks {
+		if err := plan.AcknowledgeFeedback(l.plan, ack.ID, ack.Outcome); err != nil {
+			log.Debug("Failed to acknowledge feedback %s: %v", ack.ID, err)
+			continue
+		}
+		log.Info("Feedback acknowledged: [%s] %s", ack.ID, ack.Outcome)
+	}
+}
+
+// checkUrgentFeedback calls onUrgentFeedback for urgent entries that have
+// stayed pending for more than one iteration. Each entry is reported once.
+func (l *IterationLoop) checkUrgentFeedback() {
+	if l.onUrgentFeedback == nil {
+		return
+	}
+
+	fb, err := plan.LoadFeedback(l.plan)
+	if err != nil {
+		log.Debug("Failed to load feedback: %v", err)
+		return
+	}
+
+	var stale []plan.FeedbackEntry
+	for _, entry := range fb.UrgentPending() {
+		key := entry.ID + " " + entry.Text
+		first, seen := l.urgentSeen[key]
+		if !seen {
+			l.urgentSeen[key] = l.ctx.Iteration
+			continue
+		}
+		if l.ctx.Iteration-first >= 1 && !l.urgentNotified[key] {
+			l.urgentNotified[key] = true
+			stale = append(stale, entry)
+		}
+	}
+
+	if len(stale) > 0 {
+		log.Warn("Urgent feedback still pending: %d entries", len(stale))
+		l.onUrgentFeedback(stale)
+	}
+}
+
 // writeFeedback writes verification failure reason to the feedback file.
 func (l *IterationLoop) writeFeedback(reason string) error {
 	content := fmt.Sprintf("**Verification failed:**\n%s", reason)
@@ -2,14 +2,19 @@ package runner
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/prompt"
@@ -112,7 +117,7 @@ func TestIterationLoop_Run_MaxIterations(t *testing.T) {
 	if result.Error == nil {
 		t.Error("Expected max iterations error")
 	}
-	if !strings.Contains(result.Error.Error(), "max iterations") {
+	if !errors.Is(result.Error, ErrMaxIterations) {
 		t.Errorf("Expected max iterations error, got: %v", result.Error)
 	}
 	if result.Iterations != 2 {
@@ -386,6 +391,7 @@ func TestIterationLoop_Run_VerificationFails(t *testing.T) {
 		},
 	}
 
+	var verifyFailures []string
 	loop := NewIterationLoop(LoopConfig{
 		Plan:             p,
 		Context:          ctx,
@@ -395,6 +401,9 @@ func TestIterationLoop_Run_VerificationFails(t *testing.T) {
 		PromptBuilder:    prompt.NewBuilder(config.Defaults(), "", ""),
 		WorktreePath:     tempDir,
 		IterationTimeout: 1 * time.Second,
+		OnVerificationFailed: func(reason string) {
+			verifyFailures = append(verifyFailures, reason)
+		},
 	})
 
 	result := loop.Run(context.Background())
@@ -403,6 +412,9 @@ func TestIterationLoop_Run_VerificationFails(t *testing.T) {
 	if result.Completed {
 		t.Error("Expected loop to not complete after verification failure")
 	}
+	if len(verifyFailures) != 1 {
+		t.Errorf("Expected 1 OnVerificationFailed call, got %d", len(verifyFailures))
+	}
 	// Should hit max iterations
 	if result.Error == nil || !strings.Contains(result.Error.Error(), "max iterations") {
 		t.Errorf("Expected max iterations error, got: %v", result.Error)
@@ -416,6 +428,119 @@ func TestIterationLoop_Run_VerificationFails(t *testing.T) {
 	}
 }
 
+func TestIterationLoop_Run_SecondVerifierDisagrees(t *testing.T) {
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n**Status:** open\n## Tasks\n- [x] Task 1\n"), 0644)
+
+	gitRepo := setupTestGitRepo(t, tempDir)
+
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+
+	// The primary verifier says YES, the second says NO
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Done! <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "YES"},
+			{TextContent: "NO: Task 1 has no tests"},
+		},
+	}
+
+	cfg := config.Defaults()
+	cfg.Completion.SecondVerifier = config.VerifierConfig{Model: "opus", ClaudePath: "/opt/claude"}
+
+	var reviews []*Review
+	var verifyFailures []string
+	loop := NewIterationLoop(LoopConfig{
+		Plan:             p,
+		Context:          NewContext(p, "main", 1),
+		Config:           cfg,
+		Runner:           mockRunner,
+		Git:              gitRepo,
+		PromptBuilder:    prompt.NewBuilder(cfg, "", ""),
+		WorktreePath:     tempDir,
+		IterationTimeout: 1 * time.Second,
+		OnVerificationFailed: func(reason string) {
+			verifyFailures = append(verifyFailures, reason)
+		},
+		OnReview: func(review *Review) {
+			reviews = append(reviews, review)
+		},
+	})
+
+	if result := loop.Run(context.Background()); result.Completed {
+		t.Error("Expected loop to not complete when the second verifier says NO")
+	}
+	if len(reviews) != 1 || !reviews[0].Disagreement() {
+		t.Fatalf("expected one disagreeing review, got %+v", reviews)
+	}
+	if len(verifyFailures) != 1 || verifyFailures[0] != "opus (/opt/claude): Task 1 has no tests" {
+		t.Errorf("verification failures = %q", verifyFailures)
+	}
+	if opts := mockRunner.RecordedOpts[2]; opts.Model != "opus" || opts.Binary != "/opt/claude" {
+		t.Errorf("second verification ran %s via %s", opts.Model, opts.Binary)
+	}
+
+	progress, _ := plan.ReadProgress(p)
+	if !strings.Contains(progress, "## Verification (iteration 1") || !strings.Contains(progress, "Verifiers disagree") {
+		t.Errorf("progress should record both verdicts:\n%s", progress)
+	}
+}
+
+func TestIterationLoop_Run_StopRequested(t *testing.T) {
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n## Tasks\n- [ ] Task 1\n"), 0644)
+
+	gitRepo := setupTestGitRepo(t, tempDir)
+	p, _ := plan.Load(planPath)
+
+	mockRunner := &MockRunner{}
+	stop := make(chan struct{})
+
+	loop := NewIterationLoop(LoopConfig{
+		Plan:             p,
+		Context:          NewContext(p, "main", 5),
+		Config:           config.Defaults(),
+		Runner:           mockRunner,
+		Git:              gitRepo,
+		PromptBuilder:    prompt.NewBuilder(config.Defaults(), "", ""),
+		WorktreePath:     tempDir,
+		IterationTimeout: time.Second,
+		// Request a stop while the first iteration is in flight
+		OnIteration: func(iteration int, result *Result) { close(stop) },
+		Stop:        stop,
+	})
+
+	start := time.Now()
+	result := loop.Run(context.Background())
+
+	if !errors.Is(result.Error, ErrStopRequested) {
+		t.Errorf("Expected ErrStopRequested, got: %v", result.Error)
+	}
+	if result.Iterations != 1 || len(mockRunner.RecordedOpts) != 1 {
+		t.Errorf("Expected the in-flight iteration to finish and no more, got %d iterations, %d runs", result.Iterations, len(mockRunner.RecordedOpts))
+	}
+	if time.Since(start) >= IterationCooldown {
+		t.Error("Stop should cut the cooldown short")
+	}
+
+	// The finished iteration's state is saved for the next run
+	saved, err := LoadContext(ContextPath(tempDir))
+	if err != nil || saved.Iteration != 2 {
+		t.Errorf("saved context = %+v, %v; want iteration 2", saved, err)
+	}
+}
+
 func TestNewIterationLoop_DefaultTimeout(t *testing.T) {
 	loop := NewIterationLoop(LoopConfig{})
 
@@ -435,6 +560,365 @@ func TestNewIterationLoop_CustomTimeout(t *testing.T) {
 	}
 }
 
+func TestIterationLoop_PreIterationHook_Capture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell hook test on Windows")
+	}
+
+	tempDir := t.TempDir()
+	planPath := filepath.Join(tempDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n- [ ] Task 1\n"), 0644)
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Hooks.PreIteration = "echo schema refreshed"
+	cfg.Hooks.CapturePreIteration = true
+
+	loop := NewIterationLoop(LoopConfig{
+		Plan:          p,
+		Context:       NewContext(p, "main", 5),
+		Config:        cfg,
+		PromptBuilder: prompt.NewBuilder(cfg, "", ""),
+		WorktreePath:  tempDir,
+	})
+
+	output := loop.runPreIterationHook(context.Background())
+	if output != "schema refreshed" {
+		t.Errorf("hook output = %q, want %q", output, "schema refreshed")
+	}
+
+	content, err := loop.buildPrompt(output)
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if !strings.Contains(content, "## Pre-iteration Hook Output") || !strings.Contains(content, "schema refreshed") {
+		t.Error("prompt should include captured pre_iteration output")
+	}
+
+	// Successful hooks should not write feedback
+	if _, err := os.Stat(plan.FeedbackPath(p)); !os.IsNotExist(err) {
+		t.Error("feedback file should not exist after a successful hook")
+	}
+}
+
+func TestIterationLoop_BuildPrompt_PlanInstructions(t *testing.T) {
+	tempDir := t.TempDir()
+	planPath := filepath.Join(tempDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n- [ ] Task 1\n"), 0644)
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+	os.WriteFile(plan.InstructionsPath(p), []byte("Keep the public API unchanged.\n"), 0644)
+
+	cfg := config.Defaults()
+	loop := NewIterationLoop(LoopConfig{
+		Plan:          p,
+		Context:       NewContext(p, "main", 5),
+		Config:        cfg,
+		PromptBuilder: prompt.NewBuilder(cfg, "", ""),
+		WorktreePath:  tempDir,
+	})
+
+	content, err := loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if !strings.Contains(content, "### Plan Instructions") || !strings.Contains(content, "Keep the public API unchanged.") {
+		t.Error("prompt should include the plan's instructions")
+	}
+}
+
+func TestIterationLoop_BuildPrompt_RecentCommits(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	cmd := "git branch -M main && git checkout -q -b " + loop.ctx.FeatureBranch + " && git commit -q --allow-empty -m 'Earlier iteration'"
+	if err := runShellCommand(tempDir, cmd); err != nil {
+		t.Fatalf("Failed to create plan branch: %v", err)
+	}
+
+	content, err := loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if strings.Contains(content, "## Recent Commits") {
+		t.Error("recent commits should be off by default")
+	}
+
+	loop.config.Git.RecentCommits = 3
+	content, err = loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if !strings.Contains(content, "## Recent Commits") || !strings.Contains(content, "Earlier iteration") {
+		t.Errorf("prompt should include recent commits, got:\n%s", content)
+	}
+}
+
+func TestIterationLoop_BuildPrompt_ProgressSummary(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	progress := "---\n### Iteration 1: T1\n**Completed:** Parser\n**Gotcha:** The lexer is not reentrant\n\n**Next:** Wire up the CLI\n"
+	os.WriteFile(plan.ProgressPath(loop.plan), []byte(progress), 0644)
+
+	content, err := loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	for _, want := range []string{"## Progress Summary", "**Next:** Wire up the CLI", "- The lexer is not reentrant"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestIterationLoop_PreIterationHook_NoCapture(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell hook test on Windows")
+	}
+
+	tempDir := t.TempDir()
+	p := &plan.Plan{Path: filepath.Join(tempDir, "test-plan.md"), Name: "test-plan"}
+
+	cfg := config.Defaults()
+	cfg.Hooks.PreIteration = "touch ran.txt && echo hidden"
+
+	loop := NewIterationLoop(LoopConfig{
+		Plan:         p,
+		Context:      NewContext(p, "main", 5),
+		Config:       cfg,
+		WorktreePath: tempDir,
+	})
+
+	if output := loop.runPreIterationHook(context.Background()); output != "" {
+		t.Errorf("hook output = %q, want empty without capture", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "ran.txt")); err != nil {
+		t.Error("pre_iteration hook should run in the worktree")
+	}
+}
+
+func TestIterationLoop_PreIterationHook_FailureWritesFeedback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell hook test on Windows")
+	}
+
+	tempDir := t.TempDir()
+	p := &plan.Plan{Path: filepath.Join(tempDir, "test-plan.md"), Name: "test-plan"}
+
+	cfg := config.Defaults()
+	cfg.Hooks.PreIteration = "echo codegen exploded && exit 1"
+
+	loop := NewIterationLoop(LoopConfig{
+		Plan:         p,
+		Context:      NewContext(p, "main", 5),
+		Config:       cfg,
+		WorktreePath: tempDir,
+	})
+
+	loop.runPreIterationHook(context.Background())
+
+	data, err := os.ReadFile(plan.FeedbackPath(p))
+	if err != nil {
+		t.Fatalf("feedback file should be written on hook failure: %v", err)
+	}
+	if !strings.Contains(string(data), "Pre-iteration hook failed") || !strings.Contains(string(data), "codegen exploded") {
+		t.Errorf("feedback = %q, want hook failure with output", string(data))
+	}
+}
+
+func TestIterationLoop_PreIterationHook_NotConfigured(t *testing.T) {
+	loop := NewIterationLoop(LoopConfig{Config: config.Defaults()})
+	if output := loop.runPreIterationHook(context.Background()); output != "" {
+		t.Errorf("hook output = %q, want empty", output)
+	}
+}
+
+func TestIterationLoop_AcknowledgeFeedback(t *testing.T) {
+	tempDir := t.TempDir()
+	p := &plan.Plan{Path: filepath.Join(tempDir, "test-plan.md"), Name: "test-plan"}
+
+	feedback := "# Feedback: test-plan\n\n## Pending\n- [2024-01-30 14:32] Use OAuth\n- [2024-01-30 15:00] Keep me\n\n## Processed\n"
+	os.WriteFile(plan.FeedbackPath(p), []byte(feedback), 0644)
+
+	loop := NewIterationLoop(LoopConfig{Plan: p, Context: NewContext(p, "main", 5)})
+	loop.acknowledgeFeedback(&Result{FeedbackAcks: []FeedbackAck{
+		{ID: "2024-01-30 14:32", Outcome: "Switched to OAuth"},
+		{ID: "2024-01-01 00:00", Outcome: "Unknown entry is ignored"},
+	}})
+
+	fb, err := plan.LoadFeedback(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fb.Pending) != 1 || fb.Pending[0].ID != "2024-01-30 15:00" {
+		t.Errorf("Pending = %+v, want only the 15:00 entry", fb.Pending)
+	}
+	if len(fb.Processed) != 1 || fb.Processed[0].Outcome != "Switched to OAuth" {
+		t.Errorf("Processed = %+v, want acknowledged entry with outcome", fb.Processed)
+	}
+}
+
+func TestIterationLoop_CheckUrgentFeedback(t *testing.T) {
+	tempDir := t.TempDir()
+	p := &plan.Plan{Path: filepath.Join(tempDir, "test-plan.md"), Name: "test-plan"}
+
+	feedback := "## Pending\n- [2024-01-30 14:32] cli: !urgent Production is down\n- [2024-01-30 15:00] Not urgent\n"
+	os.WriteFile(plan.FeedbackPath(p), []byte(feedback), 0644)
+
+	var notified [][]plan.FeedbackEntry
+	loop := NewIterationLoop(LoopConfig{
+		Plan:    p,
+		Context: NewContext(p, "main", 5),
+		OnUrgentFeedback: func(entries []plan.FeedbackEntry) {
+			notified = append(notified, entries)
+		},
+	})
+
+	// First sighting: no notification yet
+	loop.checkUrgentFeedback()
+	if len(notified) != 0 {
+		t.Fatalf("expected no notification on first sighting, got %d", len(notified))
+	}
+
+	// Still pending after another iteration: notify once
+	loop.ctx = loop.ctx.Increment()
+	loop.checkUrgentFeedback()
+	if len(notified) != 1 || len(notified[0]) != 1 || !notified[0][0].Urgent {
+		t.Fatalf("expected one notification with the urgent entry, got %+v", notified)
+	}
+
+	// Already notified: no repeat
+	loop.ctx = loop.ctx.Increment()
+	loop.checkUrgentFeedback()
+	if len(notified) != 1 {
+		t.Errorf("expected no repeat notification, got %d", len(notified))
+	}
+}
+
+func TestIterationLoop_WaitForControl_Skipped(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan"}
+	store := control.NewStore(filepath.Join(t.TempDir(), "control.json"))
+	store.Skip("test-plan")
+
+	loop := NewIterationLoop(LoopConfig{Plan: p, Control: store})
+	if err := loop.waitForControl(context.Background()); !errors.Is(err, ErrPlanSkipped) {
+		t.Errorf("waitForControl() error = %v, want ErrPlanSkipped", err)
+	}
+}
+
+func TestIterationLoop_WaitForControl_Abandoned(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan"}
+	store := control.NewStore(filepath.Join(t.TempDir(), "control.json"))
+	store.Pause("alice")
+	store.RequestAbandon("test-plan", control.AbandonRequest{Reason: "superseded"})
+
+	// Abandon takes effect even while paused
+	loop := NewIterationLoop(LoopConfig{Plan: p, Control: store})
+	if err := loop.waitForControl(context.Background()); !errors.Is(err, ErrPlanAbandoned) {
+		t.Errorf("waitForControl() error = %v, want ErrPlanAbandoned", err)
+	}
+}
+
+func TestIterationLoop_WaitForControl_Paused(t *testing.T) {
+	orig := controlPollInterval
+	controlPollInterval = 10 * time.Millisecond
+	defer func() { controlPollInterval = orig }()
+
+	p := &plan.Plan{Name: "test-plan"}
+	store := control.NewStore(filepath.Join(t.TempDir(), "control.json"))
+	store.Pause("alice")
+
+	loop := NewIterationLoop(LoopConfig{Plan: p, Control: store})
+
+	done := make(chan error, 1)
+	go func() { done <- loop.waitForControl(context.Background()) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("waitForControl() returned %v while paused", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	store.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("waitForControl() error = %v after resume", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForControl() did not return after resume")
+	}
+}
+
+func TestIterationLoop_Run_StopWhilePaused(t *testing.T) {
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n## Tasks\n- [ ] Task 1\n"), 0644)
+	p, _ := plan.Load(planPath)
+
+	store := control.NewStore(filepath.Join(tempDir, "control.json"))
+	store.Pause("alice")
+
+	mockRunner := &MockRunner{}
+	stop := make(chan struct{})
+	loop := NewIterationLoop(LoopConfig{
+		Plan:             p,
+		Context:          NewContext(p, "main", 5),
+		Config:           config.Defaults(),
+		Runner:           mockRunner,
+		Git:              setupTestGitRepo(t, tempDir),
+		PromptBuilder:    prompt.NewBuilder(config.Defaults(), "", ""),
+		WorktreePath:     tempDir,
+		IterationTimeout: time.Second,
+		Control:          store,
+		Stop:             stop,
+	})
+
+	done := make(chan *LoopResult, 1)
+	go func() { done <- loop.Run(context.Background()) }()
+
+	// Drain the paused worker: the stop must not wait for a resume
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	select {
+	case result := <-done:
+		if !errors.Is(result.Error, ErrStopRequested) {
+			t.Errorf("Run() error = %v, want ErrStopRequested", result.Error)
+		}
+		if len(mockRunner.RecordedOpts) != 0 {
+			t.Errorf("expected no iterations while paused, got %d", len(mockRunner.RecordedOpts))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not stop while paused")
+	}
+}
+
+func TestIterationLoop_WaitForControl_PausedCancelled(t *testing.T) {
+	p := &plan.Plan{Name: "test-plan"}
+	store := control.NewStore(filepath.Join(t.TempDir(), "control.json"))
+	store.Pause("alice")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	loop := NewIterationLoop(LoopConfig{Plan: p, Control: store})
+	if err := loop.waitForControl(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitForControl() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestIterationLoop_WaitForControl_NoStore(t *testing.T) {
+	loop := NewIterationLoop(LoopConfig{Plan: &plan.Plan{Name: "test-plan"}})
+	if err := loop.waitForControl(context.Background()); err != nil {
+		t.Errorf("waitForControl() error = %v, want nil", err)
+	}
+}
+
 // setupTestGitRepo creates a git repo for testing.
 func setupTestGitRepo(t *testing.T, dir string) git.Git {
 	t.Helper()
@@ -456,3 +940,126 @@ func runShellCommand(dir, cmd string) error {
 	c.Dir = dir
 	return c.Run()
 }
+
+func TestIterationLoop_CheckWorktree(t *testing.T) {
+	mockRunner := &MockRunner{Responses: []MockResponse{{TextContent: "Working"}}}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+	loop.checkWorktree = func() error { return errors.New("worktree needs a manual fix") }
+
+	result := loop.Run(context.Background())
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "worktree needs a manual fix") {
+		t.Errorf("Run() error = %v, want the worktree check error", result.Error)
+	}
+	if len(mockRunner.RecordedOpts) != 0 {
+		t.Errorf("claude ran %d times on a broken worktree, want 0", len(mockRunner.RecordedOpts))
+	}
+}
+
+func TestIterationLoop_BeforeIteration(t *testing.T) {
+	mockRunner := &MockRunner{Responses: []MockResponse{
+		{TextContent: "Working"},
+		{TextContent: "Done <promise>COMPLETE</promise>", IsComplete: true},
+		{TextContent: "YES"}, // Verification
+	}}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+
+	var calls []string
+	loop.beforeIteration = func(iteration int) {
+		calls = append(calls, fmt.Sprintf("%d after %d runs", iteration, len(mockRunner.RecordedOpts)))
+	}
+
+	if result := loop.Run(context.Background()); !result.Completed {
+		t.Fatalf("Run() = %+v, want completed", result)
+	}
+	if want := []string{"1 after 0 runs", "2 after 1 runs"}; strings.Join(calls, "; ") != strings.Join(want, "; ") {
+		t.Errorf("beforeIteration calls = %v, want %v", calls, want)
+	}
+}
+
+func TestIterationLoop_FinishIteration_RecordsCommit(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	os.WriteFile(filepath.Join(tempDir, "app.go"), []byte("package app\n"), 0644)
+	if err := runShellCommand(tempDir, "git add -A && git commit -m base"); err != nil {
+		t.Fatalf("committing base: %v", err)
+	}
+	loop.ctx.Iteration = 1
+	os.WriteFile(filepath.Join(tempDir, "app.go"), []byte("package app\n\nfunc App() {}\n"), 0644)
+
+	cp := &Checkpoint{Iteration: 1, HeadBefore: loop.headCommit()}
+	result := &Result{Duration: time.Second}
+	loop.finishIteration(result, cp)
+
+	progress, err := plan.LoadProgress(loop.plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := progress.Latest(); e == nil || e.Commit != result.Commit[:7] {
+		t.Errorf("Latest() = %+v, want commit %s", e, result.Commit)
+	}
+
+	// The next iteration commits the recorded SHA and records its own
+	loop.ctx.Iteration = 2
+	second := &Result{}
+	loop.finishIteration(second, &Checkpoint{Iteration: 2, HeadBefore: loop.headCommit()})
+	progress, _ = plan.LoadProgress(loop.plan)
+	if len(progress.Entries) != 2 || progress.Entries[0].Commit != result.Commit[:7] || progress.Entries[1].Commit != second.Commit[:7] {
+		t.Errorf("Entries = %+v", progress.Entries)
+	}
+}
+
+func TestIterationLoop_CheckResources(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	loop.ctx.Iteration = 1
+
+	result := &Result{Resources: events.ResourceUsage{PeakMemory: 12 << 30, Subprocesses: 3, PeakSubprocesses: 2}}
+	loop.checkResources(result)
+	if len(result.ResourceAlerts) != 1 || result.ResourceAlerts[0] != "peak memory 12.0 GB over 8GB" {
+		t.Fatalf("ResourceAlerts = %q, want the default memory limit exceeded", result.ResourceAlerts)
+	}
+
+	if err := loop.appendProgress(result); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := plan.ReadProgress(loop.plan)
+	for _, want := range []string{"Resources: 12.0 GB peak memory, 3 subprocesses (2 at once).", "Resource alert: peak memory 12.0 GB over 8GB."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("progress missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestIterationLoop_AppendProgress_Orphans(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	loop.ctx.Iteration = 1
+	result := &Result{Orphans: []string{"4242 (node)", "4250 (esbuild)"}}
+
+	if err := loop.appendProgress(result); err != nil {
+		t.Fatal(err)
+	}
+	loop.config.Runner.OrphanProcesses = config.OrphanProcessesReport
+	loop.ctx.Iteration = 2
+	if err := loop.appendProgress(result); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := plan.ReadProgress(loop.plan)
+	for _, want := range []string{"Killed leftover processes: 4242 (node), 4250 (esbuild).", "Left running: 4242 (node), 4250 (esbuild)."} {
+		if !strings.Contains(content, want) {
+			t.Errorf("progress missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestIterationLoop_RunIteration_Env(t *testing.T) {
+	mockRunner := &MockRunner{Responses: []MockResponse{{TextContent: "Working"}}}
+	loop, _ := newStageTestLoop(t, nil, mockRunner)
+	loop.env = []string{"RALPH_SCRATCH=/tmp/scratch"}
+	loop.ctx.Iteration = 1
+
+	if _, err := loop.runIteration(context.Background()); err != nil {
+		t.Fatalf("runIteration() error = %v", err)
+	}
+	if env := mockRunner.RecordedOpts[0].Env; len(env) != 1 || env[0] != "RALPH_SCRATCH=/tmp/scratch" {
+		t.Errorf("agent run Env = %q, want the loop's env", env)
+	}
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// MCPConfigFile is the file name ralph writes the configured MCP servers to.
+const MCPConfigFile = ".mcp.json"
+
+// mcpConfig is claude's --mcp-config file format.
+type mcpConfig struct {
+	MCPServers map[string]config.MCPServer `json:"mcpServers"`
+}
+
+// WriteMCPConfig writes servers to path in claude's --mcp-config format.
+// The file is only readable by the owner since server env and headers
+// often hold credentials.
+func WriteMCPConfig(path string, servers map[string]config.MCPServer) error {
+	data, err := json.MarshalIndent(mcpConfig{MCPServers: servers}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding MCP config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating MCP config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing MCP config: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0600)
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestWriteMCPConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "worktrees", MCPConfigFile)
+	servers := map[string]config.MCPServer{
+		"db":  {Command: "npx", Args: []string{"-y", "server-postgres"}, Env: map[string]string{"PGPASSWORD": "secret"}},
+		"web": {Type: "http", URL: "http://localhost:3000/mcp"},
+	}
+
+	if err := WriteMCPConfig(path, servers); err != nil {
+		t.Fatalf("WriteMCPConfig() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading MCP config: %v", err)
+	}
+	var got struct {
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("MCP config is not valid JSON: %v\n%s", err, data)
+	}
+	if got.MCPServers["db"]["command"] != "npx" {
+		t.Errorf("db server = %v", got.MCPServers["db"])
+	}
+	if got.MCPServers["web"]["url"] != "http://localhost:3000/mcp" || got.MCPServers["web"]["type"] != "http" {
+		t.Errorf("web server = %v", got.MCPServers["web"])
+	}
+	if _, ok := got.MCPServers["web"]["command"]; ok {
+		t.Errorf("empty fields should be omitted, got %v", got.MCPServers["web"])
+	}
+
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(path)
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("MCP config mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+}
+
+func TestWriteMCPConfig_TightensExistingFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	path := filepath.Join(t.TempDir(), MCPConfigFile)
+	os.WriteFile(path, []byte("{}"), 0644)
+
+	if err := WriteMCPConfig(path, map[string]config.MCPServer{"db": {Command: "db-mcp"}}); err != nil {
+		t.Fatalf("WriteMCPConfig() error = %v", err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("MCP config mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestCLIRunner_SetEnv(t *testing.T) {
+	r := NewCLIRunner()
+	r.SetEnv([]string{"npm_config_prefer_offline=true"})
+	if len(r.env) != 1 || r.env[0] != "npm_config_prefer_offline=true" {
+		t.Errorf("env = %v", r.env)
+	}
+}
+
+func TestCLIRunner_SetMCPConfig(t *testing.T) {
+	r := NewCLIRunner()
+	r.SetMCPConfig("/tmp/.mcp.json")
+	if r.mcpConfig != "/tmp/.mcp.json" {
+		t.Errorf("mcpConfig = %q", r.mcpConfig)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// orphanPollInterval is how often killed leftover processes are checked for
+// having exited.
+const orphanPollInterval = 100 * time.Millisecond
+
+// SetOrphanProcesses sets what happens to processes the agent leaves running
+// when it exits (see config.OrphanProcesses*).
+func (r *CLIRunner) SetOrphanProcesses(policy string) {
+	r.orphanProcesses = policy
+}
+
+// sweepOrphans finds the processes the agent left running after it exited,
+// the agent having led process group pgid (see leftoverProcesses), and
+// unless runner.orphan_processes is "report" kills them: SIGTERM, then
+// SIGKILL for any still running after the grace period. Returns a
+// description of each, e.g. "4242 (node)", or nil if none were left.
+func (r *CLIRunner) sweepOrphans(pgid int, seen map[int]process) []string {
+	leftover := leftoverProcesses(pgid, seen)
+	if len(leftover) == 0 {
+		return nil
+	}
+	sort.Slice(leftover, func(i, j int) bool { return leftover[i].pid < leftover[j].pid })
+	orphans := make([]string, len(leftover))
+	for i, p := range leftover {
+		orphans[i] = p.String()
+	}
+
+	if r.orphanProcesses == config.OrphanProcessesReport {
+		log.Warn("Claude left %d processes running: %s", len(orphans), strings.Join(orphans, ", "))
+		return orphans
+	}
+
+	log.Warn("Killing %d processes Claude left running: %s", len(orphans), strings.Join(orphans, ", "))
+	signalProcesses(leftover, syscall.SIGTERM)
+	deadline := time.Now().Add(r.terminationGracePeriod)
+	for time.Now().Before(deadline) {
+		time.Sleep(orphanPollInterval)
+		if leftover = leftoverProcesses(pgid, seen); len(leftover) == 0 {
+			return orphans
+		}
+	}
+	log.Warn("%d leftover processes did not terminate within %v, sending SIGKILL", len(leftover), r.terminationGracePeriod)
+	signalProcesses(leftover, syscall.SIGKILL)
+	return orphans
+}
+
+// signalProcesses signals each process, ignoring those that already exited.
+func signalProcesses(procs []process, sig syscall.Signal) {
+	for _, p := range procs {
+		if err := signalProcess(p.pid, sig); err != nil {
+			log.Debug("Failed to signal process %s: %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package runner
+
+// leftoverProcesses returns the processes still in the process group pgid
+// and those in seen that are still running, e.g. a dev server that left the
+// group with setsid.
+func leftoverProcesses(pgid int, seen map[int]process) []process {
+	var leftover []process
+	for pid, st := range readProcs() {
+		if s, ok := seen[pid]; st.pgrp == pgid || (ok && s.start == st.start) {
+			leftover = append(leftover, st.process)
+		}
+	}
+	return leftover
+}
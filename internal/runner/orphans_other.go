@@ -0,0 +1,32 @@
+//go:build !linux && !windows
+
+package runner
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// leftoverProcesses returns the processes still in the process group pgid,
+// listed by ps. seen is unused: the tree isn't sampled here.
+func leftoverProcesses(pgid int, seen map[int]process) []process {
+	out, err := exec.Command("ps", "-A", "-o", "pid=,pgid=,stat=,comm=").Output()
+	if err != nil {
+		return nil
+	}
+	var leftover []process
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || strings.HasPrefix(fields[2], "Z") {
+			continue
+		}
+		pid, _ := strconv.Atoi(fields[0])
+		group, _ := strconv.Atoi(fields[1])
+		if pid > 0 && group == pgid {
+			leftover = append(leftover, process{pid: pid, name: filepath.Base(strings.Join(fields[3:], " "))})
+		}
+	}
+	return leftover
+}
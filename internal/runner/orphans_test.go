@@ -0,0 +1,129 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// orphanScript is a fake claude that starts a long sleep (via start, e.g.
+// "setsid"), records its pid, and exits after waiting for wait seconds.
+func orphanScript(t *testing.T, start, wait string) (binary, pidFile string) {
+	t.Helper()
+	pidFile = filepath.Join(t.TempDir(), "orphan.pid")
+	binary = fakeClaude(t, "1.0.17", "cat > /dev/null\n"+
+		start+" sleep 60 > /dev/null 2>&1 &\n"+
+		"echo $! > "+pidFile+"\n"+
+		"sleep "+wait+"\n"+
+		`echo '{"type":"result","result":"done"}'`)
+	return binary, pidFile
+}
+
+// orphanPID reads the pid recorded by orphanScript.
+func orphanPID(t *testing.T, pidFile string) int {
+	t.Helper()
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pid
+}
+
+// running reports whether the process with the given pid is running, not
+// just awaiting its parent.
+func running(pid int) bool {
+	out, err := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	return err == nil && !strings.HasPrefix(strings.TrimSpace(string(out)), "Z")
+}
+
+func TestCLIRunner_OrphansKilled(t *testing.T) {
+	binary, pidFile := orphanScript(t, "", "0")
+	r := NewCLIRunner()
+
+	result, err := r.runOnce(context.Background(), "prompt", Options{Binary: binary})
+	if err != nil {
+		t.Fatalf("runOnce() error = %v", err)
+	}
+	pid := orphanPID(t, pidFile)
+	if len(result.Orphans) != 1 || !strings.HasPrefix(result.Orphans[0], strconv.Itoa(pid)+" ") {
+		t.Errorf("Orphans = %v, want the sleep (%d)", result.Orphans, pid)
+	}
+	if running(pid) {
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Errorf("leftover sleep %d still running", pid)
+	}
+}
+
+func TestCLIRunner_OrphansReported(t *testing.T) {
+	binary, pidFile := orphanScript(t, "", "0")
+	r := NewCLIRunner()
+	r.SetOrphanProcesses(config.OrphanProcessesReport)
+
+	result, err := r.runOnce(context.Background(), "prompt", Options{Binary: binary})
+	if err != nil {
+		t.Fatalf("runOnce() error = %v", err)
+	}
+	pid := orphanPID(t, pidFile)
+	defer syscall.Kill(pid, syscall.SIGKILL)
+	if len(result.Orphans) != 1 {
+		t.Errorf("Orphans = %v, want the sleep (%d)", result.Orphans, pid)
+	}
+	if !running(pid) {
+		t.Errorf("reported sleep %d was killed", pid)
+	}
+}
+
+func TestCLIRunner_OrphansLeftGroup(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only sampled process trees track processes that leave the group")
+	}
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not installed")
+	}
+	// Long enough for a sample to see the sleep
+	binary, pidFile := orphanScript(t, "setsid", "1.5")
+	r := NewCLIRunner()
+
+	result, err := r.runOnce(context.Background(), "prompt", Options{Binary: binary})
+	if err != nil {
+		t.Fatalf("runOnce() error = %v", err)
+	}
+	pid := orphanPID(t, pidFile)
+	if len(result.Orphans) != 1 || running(pid) {
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Errorf("Orphans = %v, want the killed sleep (%d)", result.Orphans, pid)
+	}
+}
+
+func TestCLIRunner_TerminateProcessGroup(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 60 & wait")
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	r := &CLIRunner{terminationGracePeriod: time.Second}
+	if err := r.terminateProcess(cmd); err != nil {
+		t.Fatalf("terminateProcess() error = %v", err)
+	}
+	if leftover := leftoverProcesses(cmd.Process.Pid, nil); len(leftover) != 0 {
+		signalProcesses(leftover, syscall.SIGKILL)
+		t.Errorf("processes left in the group: %v", leftover)
+	}
+}
@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultClaudeBinary is the claude CLI looked up on PATH when no path is configured.
+const DefaultClaudeBinary = "claude"
+
+// DefaultMinClaudeVersion is the oldest claude CLI with the stream-json
+// output and flags ralph relies on.
+const DefaultMinClaudeVersion = "1.0.0"
+
+// PreflightTimeout bounds the whole preflight, including the auth check.
+const PreflightTimeout = 90 * time.Second
+
+// claudeInstallHint tells the user how to install or upgrade the claude CLI.
+const claudeInstallHint = "npm install -g @anthropic-ai/claude-code"
+
+var (
+	// ErrClaudeNotFound is returned when the claude binary can't be located.
+	ErrClaudeNotFound = errors.New("claude CLI not found")
+
+	// ErrClaudeVersion is returned when the claude CLI is outside the supported version range.
+	ErrClaudeVersion = errors.New("unsupported claude CLI version")
+
+	// ErrClaudeAuth is returned when the claude CLI can't make an authenticated request.
+	ErrClaudeAuth = errors.New("claude CLI is not authenticated")
+)
+
+// PreflightOptions configures the claude CLI preflight check.
+type PreflightOptions struct {
+	// Binary is the claude executable name or path (default: DefaultClaudeBinary).
+	Binary string
+
+	// MinVersion is the oldest supported version, inclusive (empty = no minimum).
+	MinVersion string
+
+	// MaxVersion is the first unsupported version, exclusive (empty = no maximum).
+	MaxVersion string
+
+	// CheckAuth sends one cheap --print request to verify credentials.
+	CheckAuth bool
+
+	// Model is the model used for the auth check (empty = claude's default).
+	Model string
+}
+
+// PreflightResult describes the claude CLI that passed the preflight.
+type PreflightResult struct {
+	// Path is the resolved path of the claude binary.
+	Path string
+
+	// Version is the reported version, e.g. "1.0.17".
+	Version string
+}
+
+// Preflight locates the claude CLI, checks its version against the supported
+// range, and optionally verifies authentication, so problems surface before a
+// plan is activated instead of on its first iteration. Errors wrap
+// ErrClaudeNotFound, ErrClaudeVersion, or ErrClaudeAuth and say how to fix them.
+func Preflight(ctx context.Context, opts PreflightOptions) (*PreflightResult, error) {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, PreflightTimeout)
+		defer cancel()
+	}
+
+	binary := opts.Binary
+	if binary == "" {
+		binary = DefaultClaudeBinary
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s (install with: %s, or set runner.claude_path)", ErrClaudeNotFound, binary, claudeInstallHint)
+	}
+
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("running %s --version: %v: %s (reinstall with: %s)", path, err, strings.TrimSpace(string(out)), claudeInstallHint)
+	}
+	version, err := ParseClaudeVersion(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckClaudeVersion(version, opts.MinVersion, opts.MaxVersion); err != nil {
+		return nil, err
+	}
+
+	if opts.CheckAuth {
+		if err := checkClaudeAuth(ctx, path, opts.Model); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PreflightResult{Path: path, Version: version}, nil
+}
+
+// claudeVersionRegex matches a version number like "1.0.17" or "2.1".
+var claudeVersionRegex = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?`)
+
+// ParseClaudeVersion extracts the version from `claude --version` output,
+// e.g. "1.0.17 (Claude Code)".
+func ParseClaudeVersion(output string) (string, error) {
+	version := claudeVersionRegex.FindString(output)
+	if version == "" {
+		return "", fmt.Errorf("%w: could not parse version from %q", ErrClaudeVersion, strings.TrimSpace(output))
+	}
+	return version, nil
+}
+
+// CheckClaudeVersion returns an ErrClaudeVersion error if version is older
+// than min or not older than max. Empty bounds are not checked.
+func CheckClaudeVersion(version, min, max string) error {
+	if min != "" && compareVersions(version, min) < 0 {
+		return fmt.Errorf("%w: %s is older than the minimum %s (upgrade with: %s)", ErrClaudeVersion, version, min, claudeInstallHint)
+	}
+	if max != "" && compareVersions(version, max) >= 0 {
+		return fmt.Errorf("%w: %s is not below the maximum %s (install a supported version with: %s@<version>, or raise runner.max_claude_version)", ErrClaudeVersion, version, max, claudeInstallHint)
+	}
+	return nil
+}
+
+// compareVersions compares dotted version numbers, treating missing parts as 0.
+// Returns -1, 0, or 1.
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkClaudeAuth sends a one-word prompt in --print mode. A failure that
+// looks transient (rate limit, network) is reported as such rather than as
+// an auth problem.
+func checkClaudeAuth(ctx context.Context, path, model string) error {
+	args := []string{"--print", "--output-format", "text"}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = strings.NewReader("Reply with the single word OK.")
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+
+	detail := strings.TrimSpace(string(out))
+	if detail == "" {
+		detail = err.Error()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || IsRetryable(errors.New(detail)) {
+		return fmt.Errorf("claude auth check failed (transient, try again): %s", detail)
+	}
+	return fmt.Errorf("%w: %s (run `claude` once and log in, or set ANTHROPIC_API_KEY)", ErrClaudeAuth, detail)
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeClaude writes an executable script standing in for the claude CLI.
+// It prints version for --version and runs printScript for --print calls.
+func fakeClaude(t *testing.T, version, printScript string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script fixtures need a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = \"--version\" ]; then echo '" + version + " (Claude Code)'; exit 0; fi\n" +
+		printScript + "\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatalf("writing fake claude: %v", err)
+	}
+	return path
+}
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		print    string
+		opts     PreflightOptions
+		wantErr  error
+		wantPass bool
+	}{
+		{"ok", "1.0.17", "echo OK", PreflightOptions{MinVersion: "1.0.0", CheckAuth: true}, nil, true},
+		{"too old", "0.2.9", "echo OK", PreflightOptions{MinVersion: "1.0.0"}, ErrClaudeVersion, false},
+		{"pinned", "2.0.1", "echo OK", PreflightOptions{MinVersion: "1.0.0", MaxVersion: "2.0"}, ErrClaudeVersion, false},
+		{"not logged in", "1.0.17", "echo 'Invalid API key · Please run /login' >&2; exit 1", PreflightOptions{CheckAuth: true}, ErrClaudeAuth, false},
+		{"auth skipped", "1.0.17", "exit 1", PreflightOptions{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.Binary = fakeClaude(t, tt.version, tt.print)
+			result, err := Preflight(context.Background(), opts)
+			if tt.wantPass {
+				if err != nil {
+					t.Fatalf("Preflight() error = %v", err)
+				}
+				if result.Version != tt.version || result.Path != opts.Binary {
+					t.Errorf("result = %+v", result)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Preflight() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPreflight_TransientAuthFailure(t *testing.T) {
+	binary := fakeClaude(t, "1.0.17", "echo 'API Error: 429 rate limit exceeded' >&2; exit 1")
+	_, err := Preflight(context.Background(), PreflightOptions{Binary: binary, CheckAuth: true})
+	if err == nil || errors.Is(err, ErrClaudeAuth) {
+		t.Errorf("Preflight() error = %v, want a transient (non-auth) error", err)
+	}
+}
+
+func TestPreflight_NotFound(t *testing.T) {
+	_, err := Preflight(context.Background(), PreflightOptions{Binary: filepath.Join(t.TempDir(), "missing-claude")})
+	if !errors.Is(err, ErrClaudeNotFound) {
+		t.Errorf("Preflight() error = %v, want ErrClaudeNotFound", err)
+	}
+}
+
+func TestParseClaudeVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"1.0.17 (Claude Code)", "1.0.17", false},
+		{"claude 2.1\n", "2.1", false},
+		{"unknown", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseClaudeVersion(tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClaudeVersion(%q) = %q, %v; want %q", tt.output, got, err, tt.want)
+		}
+	}
+}
+
+func TestCheckClaudeVersion(t *testing.T) {
+	tests := []struct {
+		version, min, max string
+		ok                bool
+	}{
+		{"1.0.0", "1.0.0", "", true},
+		{"1.0.17", "1.0.3", "2.0.0", true},
+		{"1.10.0", "1.9.0", "", true},
+		{"0.9.9", "1.0.0", "", false},
+		{"2.0.0", "", "2.0.0", false},
+		{"2.0", "", "2.0.0", false},
+		{"5.0.0", "", "", true},
+	}
+	for _, tt := range tests {
+		err := CheckClaudeVersion(tt.version, tt.min, tt.max)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckClaudeVersion(%q, %q, %q) = %v, want ok=%v", tt.version, tt.min, tt.max, err, tt.ok)
+		}
+	}
+}
+
+func TestBuildCommand_Binary(t *testing.T) {
+	cmd := BuildCommand("prompt", Options{Binary: "/opt/claude"})
+	if cmd.Path != "/opt/claude" {
+		t.Errorf("cmd.Path = %q, want /opt/claude", cmd.Path)
+	}
+}
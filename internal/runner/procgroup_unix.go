@@ -0,0 +1,32 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own, so the agent's
+// subprocesses (dev servers, watchers, test runners) are signalled with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalTree signals the process group p leads, or just p if it doesn't
+// lead one.
+func signalTree(p *os.Process, sig syscall.Signal) error {
+	if syscall.Kill(-p.Pid, sig) == nil {
+		return nil
+	}
+	return p.Signal(sig)
+}
+
+// signalProcess signals the process with the given pid.
+func signalProcess(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in a process group of its own, so console
+// interrupts meant for ralph don't reach the agent.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// signalTree ends p and its descendants with taskkill /T. Windows has no
+// signals: SIGKILL forces them to end, anything else asks them to close.
+func signalTree(p *os.Process, sig syscall.Signal) error {
+	args := []string{"/T", "/PID", strconv.Itoa(p.Pid)}
+	if sig == syscall.SIGKILL {
+		args = append([]string{"/F"}, args...)
+	}
+	if err := exec.Command("taskkill", args...).Run(); err != nil {
+		if sig == syscall.SIGKILL {
+			return p.Kill()
+		}
+		return err
+	}
+	return nil
+}
+
+// signalProcess ends the process with the given pid; any signal kills it.
+func signalProcess(pid int, sig syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+// leftoverProcesses can't find the processes the agent left running here:
+// once it exits, they no longer belong to its tree.
+func leftoverProcesses(pgid int, seen map[int]process) []process {
+	return nil
+}
@@ -0,0 +1,166 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+// resourceSampleInterval is how often the agent's process tree is sampled.
+const resourceSampleInterval = time.Second
+
+// process is a process the agent started. start is its start time in
+// clock ticks since boot, which tells a reused pid apart (0 where unknown).
+type process struct {
+	pid   int
+	start uint64
+	name  string
+}
+
+// String describes the process, e.g. "4242 (node)".
+func (p process) String() string {
+	if p.name == "" {
+		return fmt.Sprint(p.pid)
+	}
+	return fmt.Sprintf("%d (%s)", p.pid, p.name)
+}
+
+// resourceSampler samples the agent's process tree while it runs, counting
+// the subprocesses and tracking the tree's peak memory. Where the
+// tree can't be sampled (see processTree), only the process state's figures
+// are recorded when the agent exits.
+type resourceSampler struct {
+	pid      int
+	interval time.Duration
+
+	mu        sync.Mutex
+	seen      map[int]process
+	peakProcs int
+	peak      int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startResourceSampler starts sampling the process tree of pid every
+// interval.
+func startResourceSampler(pid int, interval time.Duration) *resourceSampler {
+	s := &resourceSampler{
+		pid:      pid,
+		interval: interval,
+		seen:     make(map[int]process),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// run samples the tree until stopped.
+func (s *resourceSampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.sample()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample records the tree's current subprocesses and memory.
+func (s *resourceSampler) sample() {
+	procs, rss := processTree(s.pid)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range procs {
+		if p.pid != s.pid {
+			s.seen[p.pid] = p
+		}
+	}
+	if n := len(procs) - 1; n > s.peakProcs {
+		s.peakProcs = n
+	}
+	if rss > s.peak {
+		s.peak = rss
+	}
+}
+
+// finish stops sampling and returns the usage, adding the CPU time and peak
+// memory from the exited agent's process state (nil if it didn't exit).
+func (s *resourceSampler) finish(state *os.ProcessState) events.ResourceUsage {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := events.ResourceUsage{PeakMemory: s.peak, Subprocesses: len(s.seen), PeakSubprocesses: s.peakProcs}
+	if state != nil {
+		usage.CPUTime = state.UserTime() + state.SystemTime()
+		if rss := maxRSS(state); rss > usage.PeakMemory {
+			usage.PeakMemory = rss
+		}
+	}
+	return usage
+}
+
+// subprocesses returns the subprocesses seen while sampling, by pid.
+func (s *resourceSampler) subprocesses() map[int]process {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[int]process, len(s.seen))
+	for pid, p := range s.seen {
+		seen[pid] = p
+	}
+	return seen
+}
+
+// ResourceSummary describes resource usage in a short phrase, e.g.
+// "4m12s CPU, 1.2 GB peak memory, 37 subprocesses (12 at once)". Returns ""
+// if no usage was recorded.
+func ResourceSummary(u *events.ResourceUsage) string {
+	if u.Empty() {
+		return ""
+	}
+
+	var parts []string
+	if u.CPUTime > 0 {
+		parts = append(parts, fmt.Sprintf("%s CPU", u.CPUTime.Round(time.Second)))
+	}
+	if u.PeakMemory > 0 {
+		parts = append(parts, fmt.Sprintf("%s peak memory", FormatSize(u.PeakMemory)))
+	}
+	switch {
+	case u.Subprocesses == 1:
+		parts = append(parts, "1 subprocess")
+	case u.Subprocesses > 1:
+		parts = append(parts, fmt.Sprintf("%d subprocesses (%d at once)", u.Subprocesses, u.PeakSubprocesses))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ResourceAlerts returns why an iteration's usage is pathological under
+// runner.resource_alerts, e.g. "peak memory 9.1 GB over 8GB", or nil if it
+// stayed within every limit. The subprocess limit applies to the most
+// running at once, which a build spawning many short-lived ones stays under.
+func ResourceAlerts(u events.ResourceUsage, cfg config.ResourceAlertConfig) []string {
+	var alerts []string
+	if limit, err := time.ParseDuration(cfg.CPUTime); err == nil && limit > 0 && u.CPUTime > limit {
+		alerts = append(alerts, fmt.Sprintf("CPU time %s over %s", u.CPUTime.Round(time.Second), cfg.CPUTime))
+	}
+	if limit, _ := config.ParseSize(cfg.Memory); limit > 0 && u.PeakMemory > limit {
+		alerts = append(alerts, fmt.Sprintf("peak memory %s over %s", FormatSize(u.PeakMemory), cfg.Memory))
+	}
+	if cfg.Subprocesses > 0 && u.PeakSubprocesses > cfg.Subprocesses {
+		alerts = append(alerts, fmt.Sprintf("%d subprocesses at once over %d", u.PeakSubprocesses, cfg.Subprocesses))
+	}
+	return alerts
+}
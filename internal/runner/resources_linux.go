@@ -0,0 +1,89 @@
+//go:build linux
+
+package runner
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// procStat is a process's entry in /proc/<pid>/stat.
+type procStat struct {
+	process
+	ppid     int
+	pgrp     int
+	rssPages int64
+}
+
+// processTree returns pid and its descendants, read from /proc, and their
+// total resident memory in bytes.
+func processTree(pid int) ([]process, int64) {
+	procs := readProcs()
+	if _, ok := procs[pid]; !ok {
+		return nil, 0
+	}
+
+	children := make(map[int][]int)
+	for p, st := range procs {
+		children[st.ppid] = append(children[st.ppid], p)
+	}
+
+	pids := []int{pid}
+	tree := make([]process, 0, 1)
+	var total int64
+	for i := 0; i < len(pids); i++ {
+		st := procs[pids[i]]
+		tree = append(tree, st.process)
+		total += st.rssPages * int64(os.Getpagesize())
+		pids = append(pids, children[pids[i]]...)
+	}
+	return tree, total
+}
+
+// readProcs reads the stat of every process in /proc, by pid.
+func readProcs() map[int]procStat {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	procs := make(map[int]procStat, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		if st, ok := readStat(pid); ok {
+			procs[pid] = st
+		}
+	}
+	return procs
+}
+
+// readStat reads a process's /proc/<pid>/stat. ok is false if the process
+// is gone.
+func readStat(pid int) (procStat, bool) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, false
+	}
+	// The command name is in parentheses and may contain spaces
+	start, end := strings.IndexByte(string(data), '('), strings.LastIndexByte(string(data), ')')
+	if start < 0 || end < start {
+		return procStat{}, false
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return procStat{}, false
+	}
+	// A zombie has exited and only awaits its parent
+	if fields[0] == "Z" {
+		return procStat{}, false
+	}
+	st := procStat{process: process{pid: pid, name: string(data[start+1 : end])}}
+	st.ppid, _ = strconv.Atoi(fields[1])
+	st.pgrp, _ = strconv.Atoi(fields[2])
+	st.start, _ = strconv.ParseUint(fields[19], 10, 64)
+	st.rssPages, _ = strconv.ParseInt(fields[21], 10, 64)
+	return st, true
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package runner
+
+// processTree can't sample the process tree here: it returns no processes,
+// leaving only the figures from the exited process state.
+func processTree(pid int) ([]process, int64) {
+	return nil, 0
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func TestResourceSampler(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "sleep 0.5 & sleep 0.5 & wait")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	s := startResourceSampler(cmd.Process.Pid, 20*time.Millisecond)
+	if err := cmd.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	usage := s.finish(cmd.ProcessState)
+
+	if usage.PeakMemory <= 0 {
+		t.Errorf("PeakMemory = %d, want > 0", usage.PeakMemory)
+	}
+	if runtime.GOOS == "linux" && (usage.Subprocesses != 2 || usage.PeakSubprocesses != 2) {
+		t.Errorf("subprocesses = %d (%d at once), want 2 (2 at once)", usage.Subprocesses, usage.PeakSubprocesses)
+	}
+}
+
+func TestResourceSummary(t *testing.T) {
+	tests := []struct {
+		usage *events.ResourceUsage
+		want  string
+	}{
+		{nil, ""},
+		{&events.ResourceUsage{}, ""},
+		{&events.ResourceUsage{CPUTime: 252400 * time.Millisecond, PeakMemory: 3 << 29, Subprocesses: 37, PeakSubprocesses: 12}, "4m12s CPU, 1.5 GB peak memory, 37 subprocesses (12 at once)"},
+		{&events.ResourceUsage{PeakMemory: 40 << 20, Subprocesses: 1, PeakSubprocesses: 1}, "40.0 MB peak memory, 1 subprocess"},
+	}
+	for _, tt := range tests {
+		if got := ResourceSummary(tt.usage); got != tt.want {
+			t.Errorf("ResourceSummary(%+v) = %q, want %q", tt.usage, got, tt.want)
+		}
+	}
+}
+
+func TestResourceAlerts(t *testing.T) {
+	cfg := config.ResourceAlertConfig{CPUTime: "1h", Memory: "8GB", Subprocesses: 100}
+
+	if got := ResourceAlerts(events.ResourceUsage{CPUTime: time.Hour, PeakMemory: 8 << 30, PeakSubprocesses: 100}, cfg); got != nil {
+		t.Errorf("ResourceAlerts(at the limits) = %v, want none", got)
+	}
+
+	usage := events.ResourceUsage{CPUTime: 90 * time.Minute, PeakMemory: 9 << 30, Subprocesses: 900, PeakSubprocesses: 240}
+	want := []string{"CPU time 1h30m0s over 1h", "peak memory 9.0 GB over 8GB", "240 subprocesses at once over 100"}
+	if got := ResourceAlerts(usage, cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("ResourceAlerts() = %q, want %q", got, want)
+	}
+
+	if got := ResourceAlerts(usage, config.ResourceAlertConfig{}); got != nil {
+		t.Errorf("ResourceAlerts(no limits) = %v, want none", got)
+	}
+}
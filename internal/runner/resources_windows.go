@@ -0,0 +1,10 @@
+//go:build windows
+
+package runner
+
+import "os"
+
+// maxRSS returns 0: peak memory isn't reported on Windows.
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Plan outcomes, as classified when a plan finishes.
+const (
+	// OutcomeClean is a completed plan that needed no human input.
+	OutcomeClean = "clean"
+
+	// OutcomeNeededHumanHelp is a completed plan that raised a blocker or
+	// got feedback along the way.
+	OutcomeNeededHumanHelp = "needed-human-help"
+
+	// OutcomeFailedVerification is a plan moved to failed/: it ran out of
+	// iterations, failed a gate, or wasn't approved.
+	OutcomeFailedVerification = "failed-verification"
+
+	// OutcomeAbandoned is a plan a human gave up on.
+	OutcomeAbandoned = "abandoned"
+)
+
+// MaxLessons caps the lessons one retrospective records.
+const MaxLessons = 3
+
+// maxRetrospectiveInput caps each file quoted in the retrospective prompt;
+// the end of a progress log says the most about how a plan finished.
+const maxRetrospectiveInput = 16 * 1024
+
+// Retrospective is what a retrospective looks back on.
+type Retrospective struct {
+	// Plan is the plan name and Content its final content.
+	Plan    string
+	Content string
+
+	// Outcome is the classified outcome (see Outcome* constants) and Reason
+	// why a plan failed or was abandoned.
+	Outcome string
+	Reason  string
+
+	// Progress and Feedback are the plan's progress and feedback files.
+	Progress string
+	Feedback string
+
+	// Lessons are the lessons already recorded, so they aren't repeated.
+	Lessons string
+}
+
+// retrospectivePromptTemplate asks for lessons from a finished plan.
+const retrospectivePromptTemplate = `You are reviewing how an autonomous coding agent did on a plan in this repository, to help it do better on future plans in the same repository.
+
+The plan finished with outcome: %s%s
+
+PLAN:
+%s
+
+PROGRESS LOG:
+%s
+
+HUMAN FEEDBACK:
+%s
+
+LESSONS ALREADY RECORDED:
+%s
+
+List at most %d lessons a future plan in this repository should know: recurring gotchas, commands or conventions that tripped the agent up, things humans had to point out. Each lesson must be general enough to apply to other plans, not a summary of this one, and must not repeat a lesson already recorded.
+
+Answer with one lesson per line, each starting with "- ". If there is nothing worth recording, answer with exactly "NONE".`
+
+// Retrospect asks a fast model for the lessons of a finished plan. The model
+// parameter specifies which model to use; if empty, uses
+// DefaultVerificationModel. Returns nil if the model found none.
+func Retrospect(ctx context.Context, r Retrospective, runner Runner, model string) ([]string, error) {
+	if model == "" {
+		model = DefaultVerificationModel
+	}
+
+	opts := DefaultOptions()
+	opts.Model = model
+	opts.Print = true
+	opts.OutputFormat = "text"
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, VerificationTimeout)
+		defer cancel()
+	}
+
+	result, err := runner.Run(ctx, buildRetrospectivePrompt(r), opts)
+	if err != nil {
+		return nil, fmt.Errorf("retrospective failed: %w", err)
+	}
+	return parseLessons(result.TextContent), nil
+}
+
+// buildRetrospectivePrompt creates the prompt for a retrospective.
+func buildRetrospectivePrompt(r Retrospective) string {
+	reason := ""
+	if r.Reason != "" {
+		reason = " (" + r.Reason + ")"
+	}
+	return fmt.Sprintf(retrospectivePromptTemplate,
+		r.Outcome, reason,
+		orNone(r.Content),
+		orNone(tail(r.Progress, maxRetrospectiveInput)),
+		orNone(tail(r.Feedback, maxRetrospectiveInput)),
+		orNone(tail(r.Lessons, maxRetrospectiveInput)),
+		MaxLessons)
+}
+
+// parseLessons extracts the "- " lines of a retrospective response, up to
+// MaxLessons. Returns nil for "NONE" or a response without any.
+func parseLessons(response string) []string {
+	var lessons []string
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "- ") && !strings.HasPrefix(line, "* ") {
+			continue
+		}
+		lesson := strings.TrimSpace(line[2:])
+		if lesson == "" || strings.EqualFold(lesson, "none") {
+			continue
+		}
+		lessons = append(lessons, lesson)
+		if len(lessons) == MaxLessons {
+			break
+		}
+	}
+	return lessons
+}
+
+// orNone returns s, or "(none)" if it's blank.
+func orNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "(none)"
+	}
+	return strings.TrimSpace(s)
+}
+
+// tail returns the last maxLen bytes of s, marking the cut.
+func tail(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return "[...earlier content omitted]\n" + s[len(s)-maxLen:]
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRetrospect(t *testing.T) {
+	mock := &mockRunner{response: "Here is what I found:\n- Run `make generate` before `go test`\n- The API client is mocked in testutil/\n"}
+	lessons, err := Retrospect(context.Background(), Retrospective{Plan: "add-login", Outcome: OutcomeNeededHumanHelp}, mock, "")
+	if err != nil {
+		t.Fatalf("Retrospect() error = %v", err)
+	}
+	want := []string{"Run `make generate` before `go test`", "The API client is mocked in testutil/"}
+	if !reflect.DeepEqual(lessons, want) {
+		t.Errorf("lessons = %q, want %q", lessons, want)
+	}
+	if mock.lastOpts.Model != DefaultVerificationModel || !mock.lastOpts.Print {
+		t.Errorf("opts = %+v, want --print with the default verification model", mock.lastOpts)
+	}
+}
+
+func TestRetrospect_Error(t *testing.T) {
+	mock := &mockRunner{err: errors.New("connection failed")}
+	if _, err := Retrospect(context.Background(), Retrospective{}, mock, "haiku"); err == nil {
+		t.Error("Retrospect() should return the runner's error")
+	}
+}
+
+func TestParseLessons(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     []string
+	}{
+		{"none", "NONE", nil},
+		{"bullet none", "- None", nil},
+		{"stars", "* One\n* Two", []string{"One", "Two"}},
+		{"capped", "- 1\n- 2\n- 3\n- 4", []string{"1", "2", "3"}},
+		{"prose only", "Nothing stood out.", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLessons(tt.response); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLessons() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRetrospectivePrompt(t *testing.T) {
+	prompt := buildRetrospectivePrompt(Retrospective{
+		Plan:     "add-login",
+		Content:  "# Plan: Add login",
+		Outcome:  OutcomeFailedVerification,
+		Reason:   "max iterations reached",
+		Progress: strings.Repeat("x", maxRetrospectiveInput) + "the end",
+		Lessons:  "- Use bcrypt",
+	})
+	for _, want := range []string{
+		"outcome: failed-verification (max iterations reached)",
+		"# Plan: Add login",
+		"[...earlier content omitted]",
+		"the end",
+		"HUMAN FEEDBACK:\n(none)",
+		"- Use bcrypt",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verifier identifies a verification model and the claude executable it
+// runs with (empty = the runner's default).
+type Verifier struct {
+	Model  string
+	Binary string
+}
+
+// String returns the verifier's model, with the executable if set.
+func (v Verifier) String() string {
+	model := v.Model
+	if model == "" {
+		model = DefaultVerificationModel
+	}
+	if v.Binary != "" {
+		return fmt.Sprintf("%s (%s)", model, v.Binary)
+	}
+	return model
+}
+
+// Verdict is one verifier's answer to a completion claim.
+type Verdict struct {
+	// Verifier is who gave the verdict.
+	Verifier Verifier
+
+	// Verified is true if the verifier found the plan complete.
+	Verified bool
+
+	// Reason explains a NO verdict.
+	Reason string
+}
+
+// String formats the verdict, e.g. "opus: NO (Task 3 is unchecked)".
+func (v Verdict) String() string {
+	if v.Verified {
+		return v.Verifier.String() + ": YES"
+	}
+	return fmt.Sprintf("%s: NO (%s)", v.Verifier, v.Reason)
+}
+
+// Review holds the verdicts of a two-person verification: the primary
+// verifier and the second verifier.
+type Review struct {
+	Verdicts []Verdict
+}
+
+// Verified returns true if every verifier found the plan complete.
+func (r *Review) Verified() bool {
+	for _, v := range r.Verdicts {
+		if !v.Verified {
+			return false
+		}
+	}
+	return len(r.Verdicts) > 0
+}
+
+// Disagreement returns true if the verifiers reached different verdicts.
+func (r *Review) Disagreement() bool {
+	for _, v := range r.Verdicts {
+		if v.Verified != r.Verdicts[0].Verified {
+			return true
+		}
+	}
+	return false
+}
+
+// Reason returns the reasons of the NO verdicts, attributed to their verifiers.
+func (r *Review) Reason() string {
+	var reasons []string
+	for _, v := range r.Verdicts {
+		if !v.Verified {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", v.Verifier, v.Reason))
+		}
+	}
+	return strings.Join(reasons, "\n")
+}
+
+// String lists the verdicts, e.g. "haiku: YES; opus: NO (Task 3 is unchecked)".
+func (r *Review) String() string {
+	parts := make([]string, len(r.Verdicts))
+	for i, v := range r.Verdicts {
+		parts[i] = v.String()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,31 @@
+package runner
+
+import "testing"
+
+func TestReview(t *testing.T) {
+	haiku := Verifier{Model: "haiku"}
+	opus := Verifier{Model: "opus", Binary: "/opt/claude"}
+
+	agree := &Review{Verdicts: []Verdict{{Verifier: haiku, Verified: true}, {Verifier: opus, Verified: true}}}
+	if !agree.Verified() || agree.Disagreement() {
+		t.Errorf("agreeing YES review: Verified() = %v, Disagreement() = %v", agree.Verified(), agree.Disagreement())
+	}
+
+	split := &Review{Verdicts: []Verdict{{Verifier: haiku, Verified: true}, {Verifier: opus, Reason: "Task 2 is unchecked"}}}
+	if split.Verified() || !split.Disagreement() {
+		t.Errorf("split review: Verified() = %v, Disagreement() = %v", split.Verified(), split.Disagreement())
+	}
+	if got := split.String(); got != "haiku: YES; opus (/opt/claude): NO (Task 2 is unchecked)" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := split.Reason(); got != "opus (/opt/claude): Task 2 is unchecked" {
+		t.Errorf("Reason() = %q", got)
+	}
+
+	if (&Review{}).Verified() {
+		t.Error("a review without verdicts should not verify")
+	}
+	if got := (Verifier{}).String(); got != DefaultVerificationModel {
+		t.Errorf("Verifier{}.String() = %q, want the default model", got)
+	}
+}
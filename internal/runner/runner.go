@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/log"
 )
 
@@ -41,6 +42,38 @@ type Result struct {
 
 	// Blocker holds extracted blocker information if present
 	Blocker *Blocker
+
+	// FeedbackAcks holds acknowledgments of processed feedback entries
+	FeedbackAcks []FeedbackAck
+
+	// Question is the question asked with <ask>, if present
+	Question string
+
+	// Usage holds the token counts reported by Claude CLI
+	Usage Usage
+
+	// Tools counts the tool calls the agent made
+	Tools events.ToolStats
+
+	// Resources is the CPU, memory, and subprocesses the agent's process
+	// tree used
+	Resources events.ResourceUsage
+
+	// ResourceAlerts say how Resources exceeded runner.resource_alerts
+	ResourceAlerts []string
+
+	// Orphans are the processes the agent left running when it exited, e.g.
+	// "4242 (node)"; killed unless runner.orphan_processes is "report"
+	Orphans []string
+
+	// DiffLimit describes how the iteration's diff exceeded git.max_diff_files
+	// or git.max_diff_lines; its changes were left uncommitted
+	DiffLimit string
+
+	// HeadBefore and Commit are the worktree's HEAD before the iteration and
+	// after its changes were committed. They are equal if nothing was committed.
+	HeadBefore string
+	Commit     string
 }
 
 // Blocker represents extracted blocker information from Claude output.
@@ -62,6 +95,21 @@ type Blocker struct {
 type CLIRunner struct {
 	retrier *Retrier
 
+	// binary is the claude executable used when Options.Binary is unset
+	binary string
+
+	// permissions are the tool permissions applied to every run
+	permissions Permissions
+
+	// mcpConfig is the MCP server config file used when Options.MCPConfig is unset
+	mcpConfig string
+
+	// env is added to the environment of every run, before Options.Env
+	env []string
+
+	// orphanProcesses is runner.orphan_processes (empty = kill)
+	orphanProcesses string
+
 	// terminationGracePeriod is how long to wait after SIGTERM before SIGKILL
 	terminationGracePeriod time.Duration
 
@@ -86,6 +134,53 @@ func NewCLIRunnerWithRetrier(retrier *Retrier) *CLIRunner {
 	}
 }
 
+// SetBinary sets the claude executable (name or path) used for every run.
+func (r *CLIRunner) SetBinary(path string) {
+	r.binary = path
+}
+
+// SetMCPConfig sets the MCP server config file passed to every run.
+func (r *CLIRunner) SetMCPConfig(path string) {
+	r.mcpConfig = path
+}
+
+// SetEnv sets environment variables ("KEY=value") added to every run.
+func (r *CLIRunner) SetEnv(env []string) {
+	r.env = env
+}
+
+// Permissions restricts which tools claude may use.
+type Permissions struct {
+	// AllowedTools are tools claude may use without asking.
+	AllowedTools []string
+
+	// DisallowedTools are tools claude may never use.
+	DisallowedTools []string
+
+	// PermissionMode is claude's --permission-mode (empty = claude's default).
+	PermissionMode string
+}
+
+// SetPermissions sets the tool permissions applied to every run.
+// Options that set their own permissions take precedence.
+func (r *CLIRunner) SetPermissions(p Permissions) {
+	r.permissions = p
+}
+
+// applyPermissions fills unset permission options from the runner's permissions.
+func (r *CLIRunner) applyPermissions(opts Options) Options {
+	if len(opts.AllowedTools) == 0 {
+		opts.AllowedTools = r.permissions.AllowedTools
+	}
+	if len(opts.DisallowedTools) == 0 {
+		opts.DisallowedTools = r.permissions.DisallowedTools
+	}
+	if opts.PermissionMode == "" {
+		opts.PermissionMode = r.permissions.PermissionMode
+	}
+	return opts
+}
+
 // Run executes Claude with the given prompt and options.
 // It handles timeout via context, streams output in real-time,
 // and retries on transient failures.
@@ -115,8 +210,19 @@ func (r *CLIRunner) Run(ctx context.Context, prompt string, opts Options) (*Resu
 // runOnce executes a single Claude CLI invocation.
 func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*Result, error) {
 	// Build the command
+	if opts.Binary == "" {
+		opts.Binary = r.binary
+	}
+	if opts.MCPConfig == "" {
+		opts.MCPConfig = r.mcpConfig
+	}
+	if len(r.env) > 0 {
+		opts.Env = append(append([]string(nil), r.env...), opts.Env...)
+	}
+	opts = r.applyPermissions(opts)
 	cmd := BuildCommand(prompt, opts)
 	cmd.Stdin = strings.NewReader(prompt)
+	setProcessGroup(cmd)
 
 	// Set up pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -145,12 +251,15 @@ func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*
 	if err := cmd.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start claude: %w", err)
 	}
+	sampler := startResourceSampler(cmd.Process.Pid, resourceSampleInterval)
 
 	// Set up streaming parser
 	parser := NewStreamParser()
+	output := &redactingWriter{w: os.Stdout}
+	defer output.Flush()
 	parser.OnText = func(text string) {
 		// Real-time output to user
-		fmt.Print(text)
+		output.Write(text)
 	}
 
 	// Collect stderr in background
@@ -185,6 +294,8 @@ func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*
 		// Wait for the process to actually exit
 		waitErr = <-waitDone
 		log.Debug("Process exited after termination with: %v", waitErr)
+		sampler.finish(cmd.ProcessState)
+		r.sweepOrphans(cmd.Process.Pid, sampler.subprocesses())
 
 		// Wait for stream goroutines with timeout to prevent leaks
 		streamCleanupTimeout := 5 * time.Second
@@ -212,11 +323,16 @@ func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*
 	<-streamDone
 	<-stderrDone
 
-	// Build result
+	// Build result, masking secrets so they don't reach transcripts or notifications
 	result := &Result{
-		Output:      parser.FullOutput(),
-		TextContent: parser.TextContent(),
+		Output:      log.Redact(parser.FullOutput()),
+		TextContent: log.Redact(parser.TextContent()),
+		Usage:       parser.Usage(),
+		Tools:       parser.ToolStats(),
+		Resources:   sampler.finish(cmd.ProcessState),
 	}
+	relativeToolFiles(&result.Tools, opts.WorkDir)
+	result.Orphans = r.sweepOrphans(cmd.Process.Pid, sampler.subprocesses())
 
 	// Check for completion marker
 	result.IsComplete = containsCompletionMarker(result.TextContent)
@@ -224,12 +340,18 @@ func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*
 	// Extract blocker if present
 	result.Blocker = ExtractBlocker(result.TextContent)
 
+	// Extract feedback acknowledgments
+	result.FeedbackAcks = ExtractFeedbackAcks(result.TextContent)
+
+	// Extract a question for the human if present
+	result.Question = ExtractQuestion(result.TextContent)
+
 	// Check exit status
 	if waitErr != nil {
 		// Check if it's just a non-zero exit (Claude CLI returns non-zero on some errors)
 		var exitErr *exec.ExitError
 		if errors.As(waitErr, &exitErr) {
-			stderrStr := stderrBuf.String()
+			stderrStr := log.Redact(stderrBuf.String())
 			log.Debug("Claude exited with code %d, stderr: %s", exitErr.ExitCode(), stderrStr)
 
 			// Determine if this is a retryable error
@@ -246,7 +368,8 @@ func (r *CLIRunner) runOnce(ctx context.Context, prompt string, opts Options) (*
 	return result, nil
 }
 
-// terminateProcess sends SIGTERM, waits for grace period, then SIGKILL if needed.
+// terminateProcess sends SIGTERM to the process and its process group,
+// waits for grace period, then SIGKILL if needed.
 func (r *CLIRunner) terminateProcess(cmd *exec.Cmd) error {
 	if cmd == nil || cmd.Process == nil {
 		return nil
@@ -255,13 +378,14 @@ func (r *CLIRunner) terminateProcess(cmd *exec.Cmd) error {
 	pid := cmd.Process.Pid
 	log.Debug("Sending SIGTERM to process %d", pid)
 
-	// Send SIGTERM first
-	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+	// Send SIGTERM first; if it can't be sent, go straight to SIGKILL
+	if err := signalTree(cmd.Process, syscall.SIGTERM); err != nil {
 		// Process may have already exited
 		if errors.Is(err, os.ErrProcessDone) {
 			return nil
 		}
-		return fmt.Errorf("failed to send SIGTERM: %w", err)
+		log.Debug("Failed to send SIGTERM to process %d: %v", pid, err)
+		return r.killProcess(cmd)
 	}
 
 	// Wait for process to exit or grace period to elapse
@@ -278,9 +402,12 @@ func (r *CLIRunner) terminateProcess(cmd *exec.Cmd) error {
 	case <-time.After(r.terminationGracePeriod):
 		log.Warn("Process %d did not terminate within %v, sending SIGKILL", pid, r.terminationGracePeriod)
 	}
+	return r.killProcess(cmd)
+}
 
-	// Send SIGKILL
-	if err := cmd.Process.Signal(syscall.SIGKILL); err != nil {
+// killProcess sends SIGKILL to the process and its process group.
+func (r *CLIRunner) killProcess(cmd *exec.Cmd) error {
+	if err := signalTree(cmd.Process, syscall.SIGKILL); err != nil {
 		if errors.Is(err, os.ErrProcessDone) {
 			return nil
 		}
@@ -327,3 +454,33 @@ func isRetryableExitError(code int, stderr string) bool {
 
 	return false
 }
+
+// redactingWriter prints streamed text a line at a time, so a secret split
+// across two chunks is still whole when it is masked. Safe for concurrent use.
+type redactingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	pending string
+}
+
+// Write prints the complete lines of text, holding back a trailing partial
+// line until the next Write or Flush.
+func (r *redactingWriter) Write(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending += text
+	if i := strings.LastIndexByte(r.pending, '\n'); i >= 0 {
+		fmt.Fprint(r.w, log.Redact(r.pending[:i+1]))
+		r.pending = r.pending[i+1:]
+	}
+}
+
+// Flush prints any held-back partial line.
+func (r *redactingWriter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending != "" {
+		fmt.Fprint(r.w, log.Redact(r.pending))
+		r.pending = ""
+	}
+}
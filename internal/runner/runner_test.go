@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
 )
 
 func TestNewCLIRunner(t *testing.T) {
@@ -201,6 +203,25 @@ func TestIsRetryableExitError(t *testing.T) {
 	}
 }
 
+func TestRedactingWriter_SplitSecret(t *testing.T) {
+	r, _ := log.NewRedactor([]string{"supersecretvalue"}, nil)
+	log.SetRedactor(r)
+	defer log.SetRedactor(nil)
+
+	var out strings.Builder
+	w := &redactingWriter{w: &out}
+	w.Write("using super")
+	w.Write("secretvalue now\nand super")
+	if got := out.String(); got != "using "+log.Redacted+" now\n" {
+		t.Errorf("after Write, output = %q", got)
+	}
+	w.Write("secretvalue")
+	w.Flush()
+	if got := out.String(); got != "using "+log.Redacted+" now\nand "+log.Redacted {
+		t.Errorf("after Flush, output = %q", got)
+	}
+}
+
 func TestResult_Fields(t *testing.T) {
 	result := &Result{
 		Output:      "raw output",
@@ -0,0 +1,171 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// DefaultPromptTemplate is the prompt template for plans without stages and
+// for stages that don't set their own.
+const DefaultPromptTemplate = "prompt.md"
+
+// stages returns the configured pipeline, or nil for a single-stage plan.
+func (l *IterationLoop) stages() []config.StageConfig {
+	if l.config == nil {
+		return nil
+	}
+	return l.config.Stages
+}
+
+// stageIndex returns the position of the context's stage in the pipeline,
+// or -1 if it isn't part of it.
+func (l *IterationLoop) stageIndex() int {
+	for i, stage := range l.stages() {
+		if stage.Name == l.ctx.Stage {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentStage returns the current stage and whether it is the last one.
+// Returns nil if no stages are configured.
+func (l *IterationLoop) currentStage() (*config.StageConfig, bool) {
+	stages := l.stages()
+	i := l.stageIndex()
+	if i < 0 {
+		return nil, false
+	}
+	return &stages[i], i == len(stages)-1
+}
+
+// initStage puts the context on a configured stage: the first one for a new
+// plan, or when the stage it was on is no longer configured.
+func (l *IterationLoop) initStage() {
+	stages := l.stages()
+	if len(stages) == 0 {
+		l.ctx.Stage = ""
+		l.ctx.StageIteration = 0
+		return
+	}
+
+	if l.stageIndex() >= 0 {
+		if l.ctx.StageIteration < 1 {
+			l.ctx.StageIteration = 1
+		}
+		log.Info("Resuming stage %s (%d/%d), stage iteration %d", l.ctx.Stage, l.stageIndex()+1, len(stages), l.ctx.StageIteration)
+		return
+	}
+
+	if l.ctx.Stage != "" {
+		log.Warn("Stage %s is no longer configured, restarting at stage %s", l.ctx.Stage, stages[0].Name)
+	}
+	l.ctx.Stage = stages[0].Name
+	l.ctx.StageIteration = 1
+	log.Info("Starting stage %s (1/%d)", stages[0].Name, len(stages))
+}
+
+// stageDone reports whether the iteration finished stage by its completion criterion.
+func (l *IterationLoop) stageDone(ctx context.Context, iterResult *Result, stage *config.StageConfig, last bool) bool {
+	switch stage.CompletionFor(last) {
+	case config.StageCompletionTasks:
+		total := plan.CountTotal(l.plan.Tasks)
+		return total > 0 && plan.CountComplete(l.plan.Tasks) == total
+	case config.StageCompletionVerify:
+		return iterResult.IsComplete && l.verifyCompletion(ctx)
+	default:
+		return iterResult.IsComplete
+	}
+}
+
+// advanceStage moves the context to the next stage and reports the
+// transition. Returns false if the current stage is the last one.
+func (l *IterationLoop) advanceStage() bool {
+	stages := l.stages()
+	i := l.stageIndex()
+	if i < 0 || i+1 >= len(stages) {
+		return false
+	}
+
+	from, to := stages[i].Name, stages[i+1].Name
+	log.Success("Stage %s complete after %d iterations, starting stage %s (%d/%d)", from, l.ctx.StageIteration, to, i+2, len(stages))
+
+	// Increment moves the stage iteration to 1
+	l.ctx.Stage = to
+	l.ctx.StageIteration = 0
+
+	if l.onStageChange != nil {
+		l.onStageChange(from, to)
+	}
+	return true
+}
+
+// model returns the model for the current iteration. Overrides cascade
+// runner.model → the plan's **Model:** header → the stage's model, with the
+// most specific winning. Empty means claude's default.
+func (l *IterationLoop) model() string {
+	if stage, _ := l.currentStage(); stage != nil && stage.Model != "" {
+		return stage.Model
+	}
+	if l.plan != nil && l.plan.Model != "" {
+		return l.plan.Model
+	}
+	if l.config != nil {
+		return l.config.Runner.Model
+	}
+	return ""
+}
+
+// promptTemplate returns the prompt template for the current stage.
+func (l *IterationLoop) promptTemplate() string {
+	if stage, _ := l.currentStage(); stage != nil && stage.Prompt != "" {
+		return stage.Prompt
+	}
+	return DefaultPromptTemplate
+}
+
+// stageSection describes the current stage to the agent: where it is in the
+// pipeline, its goal, and what ends it. Returns "" if no stages are configured.
+func (l *IterationLoop) stageSection() string {
+	stage, last := l.currentStage()
+	if stage == nil {
+		return ""
+	}
+	stages := l.stages()
+	names := make([]string, len(stages))
+	for i, s := range stages {
+		names[i] = s.Name
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Current Stage\n\n")
+	sb.WriteString(fmt.Sprintf("This plan runs in stages: %s. You are in stage **%s** (%d of %d), iteration %d of this stage",
+		strings.Join(names, " → "), stage.Name, l.stageIndex()+1, len(stages), l.ctx.StageIteration))
+	if stage.MaxIterations > 0 {
+		sb.WriteString(fmt.Sprintf(" (at most %d)", stage.MaxIterations))
+	}
+	sb.WriteString(".\n")
+	if stage.Goal != "" {
+		sb.WriteString(fmt.Sprintf("\n**Stage goal:** %s\n", stage.Goal))
+	}
+
+	sb.WriteString("\n")
+	switch stage.CompletionFor(last) {
+	case config.StageCompletionTasks:
+		sb.WriteString("This stage ends when every task in the plan is checked off.")
+	case config.StageCompletionVerify:
+		sb.WriteString("This stage ends when you output `<promise>COMPLETE</promise>` and an independent check confirms the plan is complete.")
+	default:
+		sb.WriteString("Output `<promise>COMPLETE</promise>` as soon as this stage's goal is met, even if plan tasks remain for later stages.")
+	}
+	if !last {
+		sb.WriteString(" Work only toward this stage's goal; later stages handle the rest.")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+)
+
+// newStageTestLoop creates a loop over a one-task plan with the given stages.
+func newStageTestLoop(t *testing.T, stages []config.StageConfig, mockRunner *MockRunner) (*IterationLoop, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	planDir := filepath.Join(tempDir, "plans", "current")
+	os.MkdirAll(planDir, 0755)
+
+	planPath := filepath.Join(planDir, "test-plan.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n**Status:** open\n## Tasks\n- [ ] Task 1\n"), 0644)
+
+	gitRepo := setupTestGitRepo(t, tempDir)
+	p, err := plan.Load(planPath)
+	if err != nil {
+		t.Fatalf("Failed to load plan: %v", err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Stages = stages
+	loop := NewIterationLoop(LoopConfig{
+		Plan:             p,
+		Context:          NewContext(p, "main", 10),
+		Config:           cfg,
+		Runner:           mockRunner,
+		Git:              gitRepo,
+		PromptBuilder:    prompt.NewBuilder(cfg, "", ""),
+		WorktreePath:     tempDir,
+		IterationTimeout: 1 * time.Second,
+	})
+	return loop, tempDir
+}
+
+func TestIterationLoop_Run_Stages(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Task list expanded <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "Implemented <promise>COMPLETE</promise>", IsComplete: true},
+			{TextContent: "YES"}, // Verification of the last stage
+		},
+	}
+	loop, tempDir := newStageTestLoop(t, []config.StageConfig{
+		{Name: "plan", Goal: "Expand the task list"},
+		{Name: "implement"},
+	}, mockRunner)
+
+	var changes []string
+	loop.onStageChange = func(from, to string) {
+		changes = append(changes, from+"->"+to)
+	}
+
+	result := loop.Run(context.Background())
+
+	if !result.Completed {
+		t.Fatalf("Expected loop to complete, error: %v", result.Error)
+	}
+	if result.Iterations != 2 {
+		t.Errorf("Expected 2 iterations, got %d", result.Iterations)
+	}
+	if len(changes) != 1 || changes[0] != "plan->implement" {
+		t.Errorf("stage changes = %v", changes)
+	}
+
+	ctx, err := LoadContext(ContextPath(tempDir))
+	if err != nil {
+		t.Fatalf("LoadContext() error = %v", err)
+	}
+	if ctx.Stage != "implement" || ctx.StageIteration != 1 {
+		t.Errorf("saved context stage = %q iteration %d, want implement 1", ctx.Stage, ctx.StageIteration)
+	}
+}
+
+func TestIterationLoop_Run_StageMaxIterations(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Still planning..."},
+		},
+	}
+	loop, _ := newStageTestLoop(t, []config.StageConfig{
+		{Name: "plan", MaxIterations: 1},
+		{Name: "implement"},
+	}, mockRunner)
+
+	result := loop.Run(context.Background())
+
+	if result.Completed {
+		t.Error("Expected loop to not complete")
+	}
+	if !errors.Is(result.Error, ErrMaxIterations) || !strings.Contains(result.Error.Error(), "stage plan") {
+		t.Errorf("Expected stage max iterations error, got: %v", result.Error)
+	}
+	if result.Iterations != 1 {
+		t.Errorf("Expected 1 iteration, got %d", result.Iterations)
+	}
+}
+
+func TestIterationLoop_InitStage(t *testing.T) {
+	loop, _ := newStageTestLoop(t, []config.StageConfig{{Name: "plan"}, {Name: "review"}}, &MockRunner{})
+
+	loop.initStage()
+	if loop.ctx.Stage != "plan" || loop.ctx.StageIteration != 1 {
+		t.Errorf("new plan stage = %q iteration %d, want plan 1", loop.ctx.Stage, loop.ctx.StageIteration)
+	}
+
+	// A resumed context keeps its stage
+	loop.ctx.Stage, loop.ctx.StageIteration = "review", 3
+	loop.initStage()
+	if loop.ctx.Stage != "review" || loop.ctx.StageIteration != 3 {
+		t.Errorf("resumed stage = %q iteration %d, want review 3", loop.ctx.Stage, loop.ctx.StageIteration)
+	}
+
+	// A stage that is no longer configured restarts the pipeline
+	loop.ctx.Stage = "deploy"
+	loop.initStage()
+	if loop.ctx.Stage != "plan" || loop.ctx.StageIteration != 1 {
+		t.Errorf("unknown stage restarted at %q iteration %d, want plan 1", loop.ctx.Stage, loop.ctx.StageIteration)
+	}
+
+	// Without stages the context has none
+	loop.config.Stages = nil
+	loop.initStage()
+	if loop.ctx.Stage != "" || loop.ctx.StageIteration != 0 {
+		t.Errorf("stage without pipeline = %q iteration %d", loop.ctx.Stage, loop.ctx.StageIteration)
+	}
+}
+
+func TestIterationLoop_StageDone_Tasks(t *testing.T) {
+	loop, _ := newStageTestLoop(t, []config.StageConfig{{Name: "implement", Completion: config.StageCompletionTasks}}, &MockRunner{})
+	loop.initStage()
+	stage, last := loop.currentStage()
+
+	// The completion marker alone doesn't finish a tasks stage
+	if loop.stageDone(context.Background(), &Result{IsComplete: true}, stage, last) {
+		t.Error("stage should not be done with unchecked tasks")
+	}
+
+	os.WriteFile(loop.plan.Path, []byte("# Plan: Test\n## Tasks\n- [x] Task 1\n"), 0644)
+	updated, _ := plan.Load(loop.plan.Path)
+	loop.plan = updated
+	if !loop.stageDone(context.Background(), &Result{}, stage, last) {
+		t.Error("stage should be done once every task is checked off")
+	}
+}
+
+func TestIterationLoop_BuildPrompt_Stage(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, []config.StageConfig{
+		{Name: "implement"},
+		{Name: "review", Prompt: "review.md", Goal: "Review the diff", MaxIterations: 2},
+	}, &MockRunner{})
+
+	// Stage templates can live in .ralph/
+	configDir := filepath.Join(tempDir, ".ralph")
+	os.MkdirAll(configDir, 0755)
+	os.WriteFile(filepath.Join(configDir, "review.md"), []byte("Review stage: {{STAGE}}"), 0644)
+	loop.promptBuilder = prompt.NewBuilder(loop.config, configDir, "")
+
+	loop.initStage()
+	content, err := loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if !strings.Contains(content, "stage **implement** (1 of 2)") || !strings.Contains(content, "even if plan tasks remain") {
+		t.Errorf("implement stage prompt missing stage section:\n%s", content[len(content)-600:])
+	}
+
+	loop.advanceStage()
+	loop.ctx = loop.ctx.Increment()
+	content, err = loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	for _, want := range []string{"Review stage: review", "implement → review", "(at most 2)", "**Stage goal:** Review the diff", "independent check"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in review prompt, got:\n%s", want, content)
+		}
+	}
+}
+
+func TestIterationLoop_BuildPrompt_NoStages(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	loop.initStage()
+
+	content, err := loop.buildPrompt("")
+	if err != nil {
+		t.Fatalf("buildPrompt() error = %v", err)
+	}
+	if strings.Contains(content, "## Current Stage") {
+		t.Error("prompt without stages should not have a stage section")
+	}
+}
+
+func TestContext_Increment_Stage(t *testing.T) {
+	ctx := &Context{Iteration: 4, MaxIterations: 10, Stage: "test", StageIteration: 2}
+	next := ctx.Increment()
+	if next.Iteration != 5 || next.Stage != "test" || next.StageIteration != 3 {
+		t.Errorf("Increment() = %+v", next)
+	}
+
+	next = (&Context{Iteration: 1, MaxIterations: 10}).Increment()
+	if next.Stage != "" || next.StageIteration != 0 {
+		t.Errorf("Increment() without stage = %+v", next)
+	}
+}
+
+func TestIterationLoop_Model(t *testing.T) {
+	loop, _ := newStageTestLoop(t, []config.StageConfig{
+		{Name: "implement"},
+		{Name: "review", Model: "haiku"},
+	}, &MockRunner{})
+	loop.initStage()
+
+	if got := loop.model(); got != "" {
+		t.Errorf("model() with no overrides = %q, want claude's default", got)
+	}
+
+	loop.config.Runner.Model = "sonnet"
+	if got := loop.model(); got != "sonnet" {
+		t.Errorf("model() = %q, want runner.model", got)
+	}
+
+	loop.plan.Model = "opus"
+	if got := loop.model(); got != "opus" {
+		t.Errorf("model() = %q, want the plan's model", got)
+	}
+
+	loop.advanceStage()
+	if got := loop.model(); got != "haiku" {
+		t.Errorf("model() in review stage = %q, want the stage's model", got)
+	}
+}
+
+func TestIterationLoop_Run_StageModel(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Done <promise>COMPLETE</promise>", IsComplete: true},
+		},
+	}
+	loop, _ := newStageTestLoop(t, []config.StageConfig{
+		{Name: "review", Model: "haiku", Completion: config.StageCompletionMarker},
+	}, mockRunner)
+
+	if result := loop.Run(context.Background()); !result.Completed {
+		t.Fatalf("Expected loop to complete, error: %v", result.Error)
+	}
+	if len(mockRunner.RecordedOpts) != 1 || mockRunner.RecordedOpts[0].Model != "haiku" {
+		t.Errorf("recorded options = %+v, want model haiku", mockRunner.RecordedOpts)
+	}
+}
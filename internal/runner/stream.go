@@ -7,6 +7,8 @@ import (
 	"io"
 	"strings"
 	"sync"
+
+	"github.com/arvesolland/ralph/internal/events"
 )
 
 // StreamEvent represents a parsed event from Claude CLI stream-json output.
@@ -16,12 +18,23 @@ type StreamEvent struct {
 		Content []ContentBlock `json:"content"`
 	} `json:"message"`
 	Result string `json:"result"`
+	Usage  Usage  `json:"usage"`
+}
+
+// Usage holds token counts reported in the result event.
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 // ContentBlock represents a content block within a message.
 type ContentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
+
+	// Name and Input are set for tool_use blocks.
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
 }
 
 // StreamParser parses Claude CLI streaming JSON output line-by-line.
@@ -43,6 +56,12 @@ type StreamParser struct {
 	// resultContent holds the final result
 	resultContent string
 
+	// usage holds the token counts from the result event
+	usage Usage
+
+	// tools counts the tool_use blocks in assistant messages
+	tools events.ToolStats
+
 	// OnText is called for each text chunk extracted from the stream
 	OnText func(text string)
 
@@ -139,6 +158,9 @@ func (p *StreamParser) parseLine(line string) {
 	case "assistant":
 		// Extract text content from assistant messages
 		for _, block := range event.Message.Content {
+			if block.Type == "tool_use" {
+				recordToolUse(&p.tools, block)
+			}
 			if block.Type == "text" && block.Text != "" {
 				p.textContent.WriteString(block.Text)
 				if p.OnText != nil {
@@ -150,6 +172,7 @@ func (p *StreamParser) parseLine(line string) {
 	case "result":
 		p.hasResult = true
 		p.resultContent = event.Result
+		p.usage = event.Usage
 		if p.OnResult != nil {
 			p.OnResult(event.Result)
 		}
@@ -184,6 +207,20 @@ func (p *StreamParser) ResultContent() string {
 	return p.resultContent
 }
 
+// Usage returns the token counts from the result event, if any.
+func (p *StreamParser) Usage() Usage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usage
+}
+
+// ToolStats returns the tool calls seen in assistant messages so far.
+func (p *StreamParser) ToolStats() events.ToolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tools
+}
+
 // Reset clears the parser state.
 func (p *StreamParser) Reset() {
 	p.mu.Lock()
@@ -194,4 +231,6 @@ func (p *StreamParser) Reset() {
 	p.textContent.Reset()
 	p.hasResult = false
 	p.resultContent = ""
+	p.usage = Usage{}
+	p.tools = events.ToolStats{}
 }
@@ -171,6 +171,36 @@ func TestStreamParser_OnResultCallback(t *testing.T) {
 	}
 }
 
+func TestStreamParser_Usage(t *testing.T) {
+	p := NewStreamParser()
+	p.Parse([]byte(`{"type":"result","result":"done","usage":{"input_tokens":1200,"output_tokens":340}}` + "\n"))
+
+	usage := p.Usage()
+	if usage.InputTokens != 1200 || usage.OutputTokens != 340 {
+		t.Errorf("Usage() = %+v, want 1200/340", usage)
+	}
+
+	p.Reset()
+	if p.Usage() != (Usage{}) {
+		t.Error("Reset() should clear usage")
+	}
+}
+
+func TestStreamParser_ToolStats(t *testing.T) {
+	p := NewStreamParser()
+	p.Parse([]byte(`{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit","input":{"file_path":"main.go"}},{"type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}]}}` + "\n"))
+
+	stats := p.ToolStats()
+	if stats.Edits != 1 || stats.TestRuns != 1 || stats.Commands != 1 {
+		t.Errorf("ToolStats() = %+v, want 1 edit, 1 command, 1 test run", stats)
+	}
+
+	p.Reset()
+	if stats := p.ToolStats(); !stats.Empty() {
+		t.Error("Reset() should clear tool stats")
+	}
+}
+
 func TestStreamParser_SkipsToolUseContent(t *testing.T) {
 	p := NewStreamParser()
 
@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// Test-first phases of a **Mode:** tdd plan. Test iterations may only
+// change files matching tdd.test_patterns; implement iterations repeat
+// until commands.test passes.
+const (
+	TDDPhaseTest      = "test"
+	TDDPhaseImplement = "implement"
+)
+
+// tddEnabled returns true if the plan runs in test-first mode.
+func (l *IterationLoop) tddEnabled() bool {
+	return l.plan != nil && l.plan.Mode == plan.ModeTDD
+}
+
+// initTDD starts a test-first plan with a test iteration, keeping the phase
+// of a resumed context.
+func (l *IterationLoop) initTDD() {
+	if l.tddEnabled() && l.ctx.TDD == nil {
+		l.ctx.TDD = &TDDState{Phase: TDDPhaseTest}
+	}
+}
+
+// tddPhase returns the phase of the current iteration, or "" if the plan
+// isn't test-first.
+func (l *IterationLoop) tddPhase() string {
+	if !l.tddEnabled() || l.ctx.TDD == nil {
+		return ""
+	}
+	return l.ctx.TDD.Phase
+}
+
+// testCommand returns commands.test, or "" if it isn't configured.
+func (l *IterationLoop) testCommand() string {
+	if l.config == nil {
+		return ""
+	}
+	return strings.TrimSpace(l.config.Commands.Test)
+}
+
+// advanceTDD runs the test gate after an iteration of a test-first plan and
+// picks the next phase: a test iteration is followed by an implement
+// iteration, and implement iterations repeat until the tests pass. Without
+// commands.test the phases simply alternate.
+func (l *IterationLoop) advanceTDD(ctx context.Context) {
+	if l.tddPhase() == "" {
+		return
+	}
+	state := l.ctx.TDD
+
+	l.tddGate = nil
+	passed := true
+	if command := l.testCommand(); command != "" {
+		result := gate.RunTests(ctx, l.worktreePath, command, l.config.Flaky)
+		l.tddGate = &result
+		passed = result.Passed
+		l.reportFlakyTests(result)
+		log.Info("Test gate %s after %s iteration %d", passStatus(passed), state.Phase, l.ctx.Iteration)
+	}
+	state.TestsPassed = passed
+
+	switch {
+	case state.Phase == TDDPhaseTest:
+		if passed && l.tddGate != nil {
+			log.Warn("Tests already pass after test iteration %d; new tests should fail until implemented", l.ctx.Iteration)
+		}
+		state.Phase = TDDPhaseImplement
+	case passed:
+		state.Phase = TDDPhaseTest
+	}
+}
+
+// tddAllowsCompletion returns false, writing feedback, if a test-first plan
+// claims completion while the tests fail.
+func (l *IterationLoop) tddAllowsCompletion() bool {
+	if l.tddPhase() == "" || l.ctx.TDD.TestsPassed {
+		return true
+	}
+	reason := fmt.Sprintf("This plan is test-first and `%s` fails. The plan is only complete once the tests pass.", l.testCommand())
+	log.Warn("Ignoring completion claim: tests fail")
+	if err := l.writeFeedback(reason); err != nil {
+		log.Error("Failed to write verification feedback: %v", err)
+	}
+	return false
+}
+
+// tddSection returns the prompt section describing the current test-first
+// phase, with the output of a failed test gate.
+func (l *IterationLoop) tddSection() string {
+	phase := l.tddPhase()
+	if phase == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n## Test-First Mode\n\n")
+	sb.WriteString("This plan is built test-first: iterations alternate between writing tests and implementing until they pass.\n\n")
+	if phase == TDDPhaseTest {
+		sb.WriteString("This is a **test** iteration. Write or extend the tests for the next task only - don't change the implementation. ")
+		sb.WriteString(fmt.Sprintf("Changes to files that don't match the test patterns (%s) are reverted before the commit. ", strings.Join(l.config.TDD.TestPatterns, ", ")))
+		sb.WriteString("The new tests are expected to fail until the next iteration implements them.")
+	} else {
+		sb.WriteString("This is an **implement** iteration. Make the failing tests pass without weakening or deleting them.")
+		if command := l.testCommand(); command != "" {
+			sb.WriteString(fmt.Sprintf(" The next test iteration starts once `%s` passes.", command))
+		}
+	}
+	sb.WriteString("\n")
+
+	if l.tddGate != nil && !l.tddGate.Passed && l.tddGate.Output != "" {
+		sb.WriteString(fmt.Sprintf("\nLatest `%s` output:\n```\n%s\n```\n", l.tddGate.Command, l.tddGate.Output))
+	}
+	return sb.String()
+}
+
+// guardTestPhase reverts the staged changes to non-test files in a test
+// iteration: new files are deleted and tracked files are restored to HEAD.
+// Returns the reverted files.
+func (l *IterationLoop) guardTestPhase() ([]RejectedFile, error) {
+	if l.git == nil || l.config == nil || l.tddPhase() != TDDPhaseTest {
+		return nil, nil
+	}
+
+	staged, err := l.git.StagedChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected []RejectedFile
+	var added, tracked []string
+	for _, change := range staged {
+		if l.isRalphFile(change.Path) || matchDenyPatterns(l.config.TDD.TestPatterns, change.Path) != "" {
+			continue
+		}
+		if change.Status == "A" {
+			added = append(added, change.Path)
+		} else {
+			tracked = append(tracked, change.Path)
+		}
+		rejected = append(rejected, RejectedFile{Path: change.Path, Reason: "not a test file (test iteration)"})
+	}
+	if len(rejected) == 0 {
+		return nil, nil
+	}
+
+	if err := l.git.Unstage(added...); err != nil {
+		return nil, err
+	}
+	for _, p := range added {
+		if err := os.Remove(filepath.Join(l.git.WorkDir(), filepath.FromSlash(p))); err != nil && !os.IsNotExist(err) {
+			log.Warn("Failed to remove %s: %v", p, err)
+		}
+	}
+	if err := l.git.Restore(tracked...); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+// reportTestPhaseFiles tells the agent, through feedback, which changes a
+// test iteration reverted, and calls onFilesRejected.
+func (l *IterationLoop) reportTestPhaseFiles(files []RejectedFile) {
+	var b strings.Builder
+	b.WriteString("**Changes reverted (test iteration):**\n")
+	for _, f := range files {
+		fmt.Fprintf(&b, "- `%s`\n", f.Path)
+		log.Warn("Reverting %s", f)
+	}
+	b.WriteString("Test iterations may only change tests. Make these changes in the implement iteration, once the tests describe them.")
+
+	if err := plan.AppendFeedback(l.plan, "test-first", b.String()); err != nil {
+		log.Error("Failed to write test-first feedback: %v", err)
+	}
+	if l.onFilesRejected != nil {
+		l.onFilesRejected(files)
+	}
+}
+
+// reportFlakyTests writes the flaky tests of a test gate run to the
+// feedback file, so the agent doesn't chase failures its changes didn't cause.
+func (l *IterationLoop) reportFlakyTests(result gate.Result) {
+	report := gate.FlakyReport(result)
+	if report == "" {
+		return
+	}
+	log.Warn("Flaky tests: %s", strings.Join(result.Flaky, ", "))
+	if err := plan.AppendFeedback(l.plan, "flaky tests", report); err != nil {
+		log.Error("Failed to write flaky test feedback: %v", err)
+	}
+}
+
+// passStatus returns "passed" or "failed".
+func passStatus(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
@@ -0,0 +1,207 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// newTDDTestLoop creates a test-first loop whose test command passes once
+// done.txt exists in the worktree.
+func newTDDTestLoop(t *testing.T, mockRunner *MockRunner) (*IterationLoop, string) {
+	t.Helper()
+	loop, tempDir := newStageTestLoop(t, nil, mockRunner)
+	content := strings.Replace(loop.plan.Content, "**Status:** open\n", "**Status:** open\n**Mode:** tdd\n", 1)
+	os.WriteFile(loop.plan.Path, []byte(content), 0644)
+	loop.plan.Content, loop.plan.Mode = content, plan.ModeTDD
+	loop.config.Commands.Test = "test -f done.txt"
+	return loop, tempDir
+}
+
+func TestIterationLoop_AdvanceTDD(t *testing.T) {
+	loop, tempDir := newTDDTestLoop(t, &MockRunner{})
+	loop.initTDD()
+	if got := loop.tddPhase(); got != TDDPhaseTest {
+		t.Fatalf("first phase = %q, want test", got)
+	}
+
+	// The new tests fail, so the next iteration implements them
+	loop.advanceTDD(context.Background())
+	if loop.ctx.TDD.Phase != TDDPhaseImplement || loop.ctx.TDD.TestsPassed {
+		t.Fatalf("after test iteration: %+v, want implement with failing tests", loop.ctx.TDD)
+	}
+
+	// Implementing continues until the tests pass
+	loop.advanceTDD(context.Background())
+	if loop.ctx.TDD.Phase != TDDPhaseImplement {
+		t.Errorf("after failing implement iteration: phase = %q, want implement", loop.ctx.TDD.Phase)
+	}
+	if loop.tddGate == nil || loop.tddGate.Passed {
+		t.Errorf("tddGate = %+v, want the failed test run", loop.tddGate)
+	}
+
+	os.WriteFile(filepath.Join(tempDir, "done.txt"), []byte("ok"), 0644)
+	loop.advanceTDD(context.Background())
+	if loop.ctx.TDD.Phase != TDDPhaseTest || !loop.ctx.TDD.TestsPassed {
+		t.Errorf("after passing implement iteration: %+v, want test with passing tests", loop.ctx.TDD)
+	}
+}
+
+func TestIterationLoop_AdvanceTDD_FlakyTests(t *testing.T) {
+	loop, _ := newTDDTestLoop(t, &MockRunner{})
+	// TestFlaky fails on the first run only
+	loop.config.Commands.Test = "if test -f ran; then exit 0; fi; touch ran; echo '--- FAIL: TestFlaky (0.00s)'; exit 1"
+	loop.config.Flaky.Retries = 1
+	loop.initTDD()
+	loop.ctx.TDD.Phase = TDDPhaseImplement
+
+	loop.advanceTDD(context.Background())
+	if !loop.ctx.TDD.TestsPassed || len(loop.tddGate.Flaky) != 1 {
+		t.Fatalf("tddGate = %+v, want passed with TestFlaky flaky", loop.tddGate)
+	}
+	feedback, err := plan.ReadFeedback(loop.plan)
+	if err != nil || !strings.Contains(feedback, "flaky tests") || !strings.Contains(feedback, "TestFlaky (failed 1 of 2 runs)") {
+		t.Errorf("feedback = %q, %v, want the flaky test report", feedback, err)
+	}
+}
+
+func TestIterationLoop_AdvanceTDD_NoTestCommand(t *testing.T) {
+	loop, _ := newTDDTestLoop(t, &MockRunner{})
+	loop.config.Commands.Test = ""
+	loop.initTDD()
+
+	var phases []string
+	for i := 0; i < 3; i++ {
+		loop.advanceTDD(context.Background())
+		phases = append(phases, loop.ctx.TDD.Phase)
+	}
+	if got := strings.Join(phases, ","); got != "implement,test,implement" {
+		t.Errorf("phases = %s, want strict alternation", got)
+	}
+}
+
+func TestIterationLoop_AdvanceTDD_Disabled(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	loop.initTDD()
+	loop.advanceTDD(context.Background())
+	if loop.ctx.TDD != nil || loop.tddSection() != "" || !loop.tddAllowsCompletion() {
+		t.Errorf("plan without **Mode:** tdd has TDD state %+v", loop.ctx.TDD)
+	}
+}
+
+func TestIterationLoop_TDDSection(t *testing.T) {
+	loop, _ := newTDDTestLoop(t, &MockRunner{})
+	loop.initTDD()
+
+	section := loop.tddSection()
+	if !strings.Contains(section, "## Test-First Mode") || !strings.Contains(section, "**test** iteration") || !strings.Contains(section, "*_test.go") {
+		t.Errorf("test section = %q", section)
+	}
+
+	loop.advanceTDD(context.Background())
+	section = loop.tddSection()
+	if !strings.Contains(section, "**implement** iteration") || !strings.Contains(section, "`test -f done.txt` passes") {
+		t.Errorf("implement section = %q", section)
+	}
+}
+
+func TestIterationLoop_CommitChanges_TestPhase(t *testing.T) {
+	loop, tempDir := newTDDTestLoop(t, &MockRunner{})
+	loop.initTDD()
+	loop.ctx.Iteration = 1
+
+	var reported []RejectedFile
+	loop.onFilesRejected = func(files []RejectedFile) { reported = files }
+
+	writeLines(t, tempDir, "calc.go", 1)
+	if err := runShellCommand(tempDir, "git add calc.go && git commit -q -m base"); err != nil {
+		t.Fatal(err)
+	}
+
+	writeLines(t, tempDir, "calc_test.go", 3)
+	writeLines(t, tempDir, "testdata/input.txt", 1)
+	writeLines(t, tempDir, "calc.go", 4)
+	writeLines(t, tempDir, "helper.go", 2)
+
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+
+	committed := gitOutput(t, tempDir, "show", "--name-only", "--format=", "HEAD")
+	for _, want := range []string{"calc_test.go", "testdata/input.txt", "test-plan.md"} {
+		if !strings.Contains(committed, want) {
+			t.Errorf("commit is missing %s:\n%s", want, committed)
+		}
+	}
+	if strings.Contains(committed, "calc.go\n") || strings.Contains(committed, "helper.go") {
+		t.Errorf("commit should only contain tests:\n%s", committed)
+	}
+
+	// Implementation changes are reverted, not left for the next iteration
+	if _, err := os.Stat(filepath.Join(tempDir, "helper.go")); !os.IsNotExist(err) {
+		t.Errorf("helper.go should be removed: %v", err)
+	}
+	if status := gitOutput(t, tempDir, "status", "--porcelain", "--", "calc.go", "helper.go"); status != "" {
+		t.Errorf("worktree not clean after commit:\n%s", status)
+	}
+
+	if len(reported) != 2 || reported[0].Path != "calc.go" || reported[1].Path != "helper.go" {
+		t.Fatalf("reported = %+v, want calc.go and helper.go", reported)
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "Changes reverted (test iteration)") || !strings.Contains(feedback, "`helper.go`") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestIterationLoop_CommitChanges_ImplementPhase(t *testing.T) {
+	loop, tempDir := newTDDTestLoop(t, &MockRunner{})
+	loop.ctx.TDD = &TDDState{Phase: TDDPhaseImplement}
+	loop.ctx.Iteration = 2
+
+	writeLines(t, tempDir, "calc.go", 1)
+	if err := runShellCommand(tempDir, "git add calc.go && git commit -q -m base"); err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, tempDir, "calc.go", 2)
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges: %v", err)
+	}
+	if committed := gitOutput(t, tempDir, "show", "--name-only", "--format=", "HEAD"); !strings.Contains(committed, "calc.go") {
+		t.Errorf("implement iteration should commit calc.go:\n%s", committed)
+	}
+}
+
+func TestIterationLoop_Run_TDDCompletionNeedsPassingTests(t *testing.T) {
+	mockRunner := &MockRunner{
+		Responses: []MockResponse{
+			{TextContent: "Wrote failing tests"},
+			{TextContent: "All done <promise>COMPLETE</promise>", IsComplete: true},
+		},
+	}
+	loop, _ := newTDDTestLoop(t, mockRunner)
+	loop.ctx.MaxIterations = 2
+
+	result := loop.Run(context.Background())
+
+	if result.Completed || !errors.Is(result.Error, ErrMaxIterations) {
+		t.Fatalf("Run() = %+v, want max iterations while tests fail", result)
+	}
+	if len(mockRunner.RecordedOpts) != 2 {
+		t.Errorf("runner called %d times, want 2 iterations and no verification", len(mockRunner.RecordedOpts))
+	}
+
+	progress, _ := plan.ReadProgress(loop.plan)
+	if !strings.Contains(progress, "Test-first phase: test") || !strings.Contains(progress, "Test-first phase: implement") {
+		t.Errorf("progress = %q", progress)
+	}
+	feedback, _ := plan.ReadFeedback(loop.plan)
+	if !strings.Contains(feedback, "only complete once the tests pass") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
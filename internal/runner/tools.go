@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+// editTools are the tools that change files, with the input field naming the file.
+var editTools = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// webTools are the tools that reach the network.
+var webTools = map[string]bool{
+	"WebFetch":  true,
+	"WebSearch": true,
+}
+
+// testCommandRegex matches shell commands that run a project's tests.
+var testCommandRegex = regexp.MustCompile(`\b(go test|(npm|yarn|pnpm|bun)( run)? test|npx (jest|vitest)|pytest|jest|vitest|cargo (test|nextest)|mix test|rspec|rails test|phpunit|dotnet test|(gradle|gradlew|mvn) test|make (test|check)|tox)\b`)
+
+// isTestCommand reports whether a shell command runs tests.
+func isTestCommand(command string) bool {
+	return testCommandRegex.MatchString(command)
+}
+
+// recordToolUse adds a tool_use content block to stats.
+func recordToolUse(stats *events.ToolStats, block ContentBlock) {
+	if block.Name == "" {
+		return
+	}
+	if stats.Calls == nil {
+		stats.Calls = make(map[string]int)
+	}
+	stats.Calls[block.Name]++
+
+	var input map[string]interface{}
+	if len(block.Input) > 0 {
+		json.Unmarshal(block.Input, &input)
+	}
+	inputString := func(key string) string {
+		s, _ := input[key].(string)
+		return s
+	}
+
+	switch {
+	case editTools[block.Name] != "":
+		stats.Edits++
+		if path := inputString(editTools[block.Name]); path != "" && !containsString(stats.Files, path) {
+			stats.Files = append(stats.Files, path)
+		}
+	case block.Name == "Bash":
+		stats.Commands++
+		if isTestCommand(inputString("command")) {
+			stats.TestRuns++
+		}
+	case webTools[block.Name]:
+		stats.WebFetches++
+	}
+}
+
+// relativeToolFiles rewrites edited file paths inside dir relative to it.
+func relativeToolFiles(stats *events.ToolStats, dir string) {
+	if dir == "" {
+		return
+	}
+	for i, path := range stats.Files {
+		if !filepath.IsAbs(path) {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			stats.Files[i] = rel
+		}
+	}
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
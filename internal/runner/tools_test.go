@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestIsTestCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"go test ./...", true},
+		{"cd internal && go test -run TestFoo ./runner", true},
+		{"npm test", true},
+		{"npm run test -- --watch=false", true},
+		{"pytest -x tests/", true},
+		{"cargo test", true},
+		{"make test", true},
+		{"go build ./...", false},
+		{"ls -la", false},
+		{"git commit -m 'add test'", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTestCommand(tt.command); got != tt.want {
+			t.Errorf("isTestCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestRecordToolUse(t *testing.T) {
+	var stats events.ToolStats
+	blocks := []ContentBlock{
+		{Type: "tool_use", Name: "Edit", Input: []byte(`{"file_path":"/work/main.go"}`)},
+		{Type: "tool_use", Name: "Edit", Input: []byte(`{"file_path":"/work/main.go"}`)},
+		{Type: "tool_use", Name: "Write", Input: []byte(`{"file_path":"/work/docs/README.md"}`)},
+		{Type: "tool_use", Name: "NotebookEdit", Input: []byte(`{"notebook_path":"/work/nb.ipynb"}`)},
+		{Type: "tool_use", Name: "Bash", Input: []byte(`{"command":"go test ./..."}`)},
+		{Type: "tool_use", Name: "Bash", Input: []byte(`{"command":"git status"}`)},
+		{Type: "tool_use", Name: "WebFetch", Input: []byte(`{"url":"https://example.com"}`)},
+		{Type: "tool_use", Name: "Read", Input: []byte(`{"file_path":"/work/go.mod"}`)},
+		{Type: "tool_use"},
+	}
+	for _, b := range blocks {
+		recordToolUse(&stats, b)
+	}
+
+	want := events.ToolStats{
+		Calls:      map[string]int{"Edit": 2, "Write": 1, "NotebookEdit": 1, "Bash": 2, "WebFetch": 1, "Read": 1},
+		Edits:      4,
+		Files:      []string{"/work/main.go", "/work/docs/README.md", "/work/nb.ipynb"},
+		Commands:   2,
+		TestRuns:   1,
+		WebFetches: 1,
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats = %+v\nwant %+v", stats, want)
+	}
+}
+
+func TestRelativeToolFiles(t *testing.T) {
+	stats := events.ToolStats{Files: []string{"/work/main.go", "/work/docs/README.md", "/elsewhere/x.go", "rel.go"}}
+	relativeToolFiles(&stats, "/work")
+
+	want := []string{"main.go", "docs/README.md", "/elsewhere/x.go", "rel.go"}
+	if !reflect.DeepEqual(stats.Files, want) {
+		t.Errorf("Files = %v, want %v", stats.Files, want)
+	}
+}
+
+func TestIterationLoop_AppendProgress_Tools(t *testing.T) {
+	loop, _ := newStageTestLoop(t, nil, &MockRunner{})
+	loop.ctx.Iteration = 1
+
+	result := &Result{Tools: events.ToolStats{Calls: map[string]int{"Edit": 2, "Bash": 1}, Edits: 2, Files: []string{"a.go"}, Commands: 1, TestRuns: 1}}
+	if err := loop.appendProgress(result); err != nil {
+		t.Fatalf("appendProgress: %v", err)
+	}
+
+	progress, err := plan.ReadProgress(loop.plan)
+	if err != nil {
+		t.Fatalf("ReadProgress: %v", err)
+	}
+	if !strings.Contains(progress, "Tools: 2 edits across 1 file, 1 test run.") {
+		t.Errorf("progress missing tool summary:\n%s", progress)
+	}
+}
+
+func TestIterationLoop_AppendProgress_Structured(t *testing.T) {
+	loop, tempDir := newStageTestLoop(t, nil, &MockRunner{})
+	loop.ctx.Iteration = 2
+	rel, _ := filepath.Rel(tempDir, loop.plan.Path)
+
+	result := &Result{Duration: 90 * time.Second, Tools: events.ToolStats{Files: []string{"a.go", rel, ".ralph/context.json"}}}
+	if err := loop.appendProgress(result); err != nil {
+		t.Fatalf("appendProgress: %v", err)
+	}
+
+	progress, err := plan.LoadProgress(loop.plan)
+	if err != nil {
+		t.Fatalf("LoadProgress: %v", err)
+	}
+	entry := progress.Latest()
+	if entry == nil || entry.Iteration != 2 || entry.Duration != 90*time.Second {
+		t.Fatalf("Latest() = %+v", entry)
+	}
+	if !reflect.DeepEqual(entry.Files, []string{"a.go"}) {
+		t.Errorf("Files = %v, want ralph's own files left out", entry.Files)
+	}
+}
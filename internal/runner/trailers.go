@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// Trailers added to every iteration commit, so a plan's history can be
+// queried with git (e.g. git log --grep "^Ralph-Plan: my-plan$") and a
+// resumed iteration can tell whether its commit already landed.
+const (
+	TrailerPlan      = "Ralph-Plan"
+	TrailerIteration = "Ralph-Iteration"
+	TrailerRunID     = "Ralph-Run-ID"
+)
+
+// iterationTrailers returns the trailers for the current iteration's commit.
+func (l *IterationLoop) iterationTrailers() map[string]string {
+	trailers := map[string]string{
+		TrailerPlan:      l.plan.Name,
+		TrailerIteration: strconv.Itoa(l.ctx.Iteration),
+	}
+	if l.ctx.RunID != "" {
+		trailers[TrailerRunID] = l.ctx.RunID
+	}
+	return trailers
+}
+
+// commitMessage returns the current iteration's commit message: a subject
+// line and the iteration trailers.
+func (l *IterationLoop) commitMessage() string {
+	trailers := l.iterationTrailers()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "ralph: iteration %d\n\n", l.ctx.Iteration)
+	for _, key := range []string{TrailerPlan, TrailerIteration, TrailerRunID} {
+		if value, ok := trailers[key]; ok {
+			fmt.Fprintf(&sb, "%s: %s\n", key, value)
+		}
+	}
+	return sb.String()
+}
+
+// landedCommit returns the commit made for the current iteration since the
+// checkpoint's HeadBefore, found by its trailers, or "" if there is none.
+// Without a run ID a commit can't be told apart from an earlier run's, so
+// none is reported.
+func (l *IterationLoop) landedCommit(cp *Checkpoint) string {
+	if l.git == nil || l.ctx.RunID == "" {
+		return ""
+	}
+	revRange := "HEAD"
+	if cp.HeadBefore != "" {
+		revRange = cp.HeadBefore + "..HEAD"
+	}
+	shas, err := l.git.CommitsWithTrailers(revRange, l.iterationTrailers())
+	if err != nil {
+		log.Debug("Failed to look up iteration %d's commit: %v", l.ctx.Iteration, err)
+		return ""
+	}
+	if len(shas) == 0 {
+		return ""
+	}
+	return shas[0]
+}
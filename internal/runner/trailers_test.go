@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestIterationLoop_CommitMessage(t *testing.T) {
+	loop := NewIterationLoop(LoopConfig{
+		Plan:    &plan.Plan{Name: "my-plan"},
+		Context: &Context{Iteration: 3, RunID: "abc123"},
+	})
+
+	want := "ralph: iteration 3\n\nRalph-Plan: my-plan\nRalph-Iteration: 3\nRalph-Run-ID: abc123\n"
+	if got := loop.commitMessage(); got != want {
+		t.Errorf("commitMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestIterationLoop_CommitChanges_Trailers(t *testing.T) {
+	loop, _ := setupCheckpointLoop(t, &MockRunner{})
+	loop.appendProgress(&Result{Duration: time.Minute})
+
+	if err := loop.commitChanges(); err != nil {
+		t.Fatalf("commitChanges() error = %v", err)
+	}
+	shas, err := loop.git.CommitsWithTrailers("HEAD", loop.iterationTrailers())
+	if err != nil || len(shas) != 1 {
+		t.Errorf("CommitsWithTrailers() = %v, %v; want the iteration commit", shas, err)
+	}
+}
+
+func TestIterationLoop_ResumeCheckpoint_AlreadyCommitted(t *testing.T) {
+	loop, dir := setupCheckpointLoop(t, &MockRunner{})
+	headBefore, _ := loop.git.HeadCommit()
+
+	// The iteration committed, then crashed before checkpointing it
+	SaveCheckpoint(&Checkpoint{Iteration: 1, Phase: PhaseExecuted, Duration: time.Minute, HeadBefore: headBefore}, CheckpointPath(dir))
+	loop.appendProgress(&Result{Duration: time.Minute})
+	if err := loop.commitChanges(); err != nil {
+		t.Fatal(err)
+	}
+	landed, _ := loop.git.HeadCommit()
+
+	result := loop.resumeCheckpoint()
+	if result == nil || result.Duration != time.Minute {
+		t.Fatalf("resumeCheckpoint() = %+v", result)
+	}
+
+	if head, _ := loop.git.HeadCommit(); head != landed {
+		t.Errorf("HEAD = %s, want %s with no second commit", head, landed)
+	}
+	progress, _ := plan.ReadProgress(loop.plan)
+	if n := strings.Count(progress, "## Iteration 1"); n != 1 {
+		t.Errorf("progress has %d entries for iteration 1, want 1:\n%s", n, progress)
+	}
+	cp, _ := LoadCheckpoint(CheckpointPath(dir))
+	if cp.Phase != PhaseCommitted || cp.Commit != landed {
+		t.Errorf("checkpoint = %+v, want committed at %s", cp, landed)
+	}
+}
+
+func TestIterationLoop_LandedCommit_OtherRun(t *testing.T) {
+	loop, _ := setupCheckpointLoop(t, &MockRunner{})
+	headBefore, _ := loop.git.HeadCommit()
+	loop.appendProgress(&Result{Duration: time.Minute})
+	if err := loop.commitChanges(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same iteration of a later run of the plan hasn't committed yet
+	loop.ctx.RunID = "later-run"
+	if sha := loop.landedCommit(&Checkpoint{Iteration: 1, HeadBefore: headBefore}); sha != "" {
+		t.Errorf("landedCommit() = %s, want none for another run", sha)
+	}
+
+	loop.ctx.RunID = ""
+	if sha := loop.landedCommit(&Checkpoint{Iteration: 1, HeadBefore: headBefore}); sha != "" {
+		t.Errorf("landedCommit() without a run ID = %s, want none", sha)
+	}
+}
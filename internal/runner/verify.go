@@ -59,10 +59,17 @@ var yesNoRegex = regexp.MustCompile(`(?im)^(YES|NO)\s*:?\s*(.*)`)
 // Returns (false, reason, nil) if not complete, with an explanation.
 // Returns (false, "", err) on execution errors.
 func Verify(ctx context.Context, p *plan.Plan, runner Runner, model string) (*VerificationResult, error) {
+	return VerifyWith(ctx, p, runner, Verifier{Model: model})
+}
+
+// VerifyWith is like Verify but runs the verifier's model with its claude
+// executable, if set.
+func VerifyWith(ctx context.Context, p *plan.Plan, runner Runner, v Verifier) (*VerificationResult, error) {
 	// Build the verification prompt with plan content
 	prompt := buildVerificationPrompt(p)
 
 	// Use default model if not specified
+	model := v.Model
 	if model == "" {
 		model = DefaultVerificationModel
 	}
@@ -70,6 +77,7 @@ func Verify(ctx context.Context, p *plan.Plan, runner Runner, model string) (*Ve
 	// Set up options for verification model
 	opts := DefaultOptions()
 	opts.Model = model
+	opts.Binary = v.Binary
 	opts.Print = true          // Use --print mode for simple prompt/response
 	opts.OutputFormat = "text" // Use text format for verification (stream-json requires --verbose with --print)
 
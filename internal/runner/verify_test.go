@@ -100,6 +100,18 @@ func TestVerify_UsesCustomModel(t *testing.T) {
 	}
 }
 
+func TestVerifyWith_UsesBinary(t *testing.T) {
+	mock := &mockRunner{response: "YES"}
+	p := &plan.Plan{Name: "test", Content: "content"}
+
+	if _, err := VerifyWith(context.Background(), p, mock, Verifier{Model: "opus", Binary: "/opt/claude"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.lastOpts.Model != "opus" || mock.lastOpts.Binary != "/opt/claude" {
+		t.Errorf("expected opus via /opt/claude, got %s via %s", mock.lastOpts.Model, mock.lastOpts.Binary)
+	}
+}
+
 func TestVerify_UsesPrintMode(t *testing.T) {
 	mock := &mockRunner{response: "YES"}
 	p := &plan.Plan{Name: "test", Content: "content"}
@@ -0,0 +1,4 @@
+package state
+
+// The pure-Go SQLite driver, registered as "sqlite".
+import _ "modernc.org/sqlite"
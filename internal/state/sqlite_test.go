@@ -0,0 +1,166 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(Path(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func writePlan(t *testing.T, dir, name, labels string) string {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	path := filepath.Join(dir, name+".md")
+	content := "# Plan: " + name + "\n"
+	if labels != "" {
+		content += "**Labels:** " + labels + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content+"\n- [ ] Task\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func names(plans []*plan.Plan) []string {
+	var out []string
+	for _, p := range plans {
+		out = append(out, p.Name)
+	}
+	return out
+}
+
+func TestDB_Sync_Plans(t *testing.T) {
+	db := openTestDB(t)
+	base := t.TempDir()
+	q := plan.NewQueue(base)
+	writePlan(t, filepath.Join(base, "pending"), "next", "")
+	writePlan(t, filepath.Join(base, "complete"), "b-done", "backend, api")
+	writePlan(t, filepath.Join(base, "complete"), "a-done", "")
+	os.WriteFile(filepath.Join(base, "complete", "a-done.progress.md"), []byte("# Progress\n"), 0644)
+
+	result, err := db.Sync(q, nil)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.Plans != 3 {
+		t.Errorf("Sync().Plans = %d, want 3", result.Plans)
+	}
+
+	complete, err := db.Plans("complete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names(complete), []string{"a-done", "b-done"}) {
+		t.Errorf("Plans(complete) = %q", names(complete))
+	}
+	if !reflect.DeepEqual(complete[1].Labels, []string{"backend", "api"}) {
+		t.Errorf("Labels = %q", complete[1].Labels)
+	}
+
+	// Nothing changed: nothing is parsed again
+	if result, _ := db.Sync(q, nil); result.Plans != 0 || result.Removed != 0 {
+		t.Errorf("second Sync() = %+v, want no changes", result)
+	}
+
+	// A plan moved and a plan deleted by hand
+	os.MkdirAll(filepath.Join(base, "failed"), 0755)
+	os.Rename(filepath.Join(base, "pending", "next.md"), filepath.Join(base, "failed", "next.md"))
+	os.Remove(filepath.Join(base, "complete", "b-done.md"))
+	result, err = db.Sync(q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Plans != 1 || result.Removed != 1 {
+		t.Errorf("Sync() after changes = %+v, want 1 updated and 1 removed", result)
+	}
+	if failed, _ := db.Plans("failed"); !reflect.DeepEqual(names(failed), []string{"next"}) {
+		t.Errorf("Plans(failed) = %q", names(failed))
+	}
+}
+
+func TestDB_QueueIndex(t *testing.T) {
+	db := openTestDB(t)
+	base := t.TempDir()
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(base, dir), 0755)
+	}
+	q := plan.NewQueue(base)
+	q.Index = db
+
+	p, err := plan.Load(writePlan(t, filepath.Join(base, "pending"), "feature", "backend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Activate(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Complete(p); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := q.StatusFor([]string{"backend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.CompleteCount != 1 {
+		t.Errorf("CompleteCount = %d, want 1", status.CompleteCount)
+	}
+
+	// The move kept the file's mtime, so a sync has nothing to do
+	if result, _ := db.Sync(q, nil); result.Plans != 0 {
+		t.Errorf("Sync() after a recorded move = %+v, want no changes", result)
+	}
+}
+
+func TestDB_Events(t *testing.T) {
+	db := openTestDB(t)
+	log := events.NewLog(filepath.Join(t.TempDir(), events.EventsFileName))
+	start := time.Date(2024, 1, 30, 10, 0, 0, 0, time.UTC)
+	log.Append(events.Event{Time: start, Type: events.TypePlanStarted, Plan: "alpha"})
+	log.Append(events.Event{Time: start.Add(time.Minute), Type: events.TypeIteration, Plan: "alpha", Iteration: 1, Duration: time.Minute, InputTokens: 100, OutputTokens: 10})
+
+	if result, err := db.Sync(nil, log); err != nil || result.Events != 2 {
+		t.Fatalf("Sync() = %+v, %v, want 2 events", result, err)
+	}
+
+	// Mirrored events are recorded once, even when a later sync reads them too
+	log.Mirror = func(e events.Event) { db.RecordEvent(e) }
+	log.Append(events.Event{Time: start.Add(2 * time.Minute), Type: events.TypeIteration, Plan: "alpha", Iteration: 2, Duration: 2 * time.Minute, InputTokens: 200, OutputTokens: 20})
+	if result, _ := db.Sync(nil, log); result.Events != 1 {
+		t.Errorf("Sync() = %+v, want the 1 new event read", result)
+	}
+
+	all, err := db.Events(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[2].Iteration != 2 || !all[0].Time.Equal(start) {
+		t.Errorf("Events() = %+v", all)
+	}
+	if since, _ := db.Events(start); len(since) != 2 {
+		t.Errorf("Events(since) = %d events, want 2", len(since))
+	}
+
+	usage, err := db.Usage("alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{Iterations: 2, Duration: 3 * time.Minute, InputTokens: 300, OutputTokens: 30}
+	if usage != want {
+		t.Errorf("Usage() = %+v, want %+v", usage, want)
+	}
+}
@@ -0,0 +1,254 @@
+// Package state provides the optional SQLite state database
+// (state.backend: sqlite): a mirror of the plan queue and the events log in
+// .ralph/state.db, so status and report queries stay fast on repositories
+// with thousands of finished plans. The plans directories and
+// .ralph/events.jsonl remain the source of truth; Sync brings the database
+// up to date with them.
+//
+// The package uses database/sql with the driver registered as "sqlite"
+// (modernc.org/sqlite, pure Go, so ralph still builds without cgo).
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// FileName is the name of the state database in the .ralph directory.
+const FileName = "state.db"
+
+// DriverName is the database/sql driver the database is opened with.
+const DriverName = "sqlite"
+
+// ErrNoDriver is returned by open when the database/sql driver isn't
+// registered.
+var ErrNoDriver = errors.New("state database driver not registered")
+
+// schema creates the database tables. Plans are keyed by file name, events
+// by time, type, plan, and iteration, so recording one twice is a no-op.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS plans (
+		file TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		dir TEXT NOT NULL,
+		path TEXT NOT NULL,
+		branch TEXT NOT NULL DEFAULT '',
+		labels TEXT NOT NULL DEFAULT '',
+		mtime INTEGER NOT NULL DEFAULT 0,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS plans_dir ON plans (dir, name)`,
+	`CREATE TABLE IF NOT EXISTS events (
+		time INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		plan TEXT NOT NULL DEFAULT '',
+		iteration INTEGER NOT NULL DEFAULT 0,
+		duration INTEGER NOT NULL DEFAULT 0,
+		input_tokens INTEGER NOT NULL DEFAULT 0,
+		output_tokens INTEGER NOT NULL DEFAULT 0,
+		data TEXT NOT NULL,
+		UNIQUE (time, type, plan, iteration)
+	)`,
+	`CREATE INDEX IF NOT EXISTS events_plan ON events (plan, time)`,
+	`CREATE TABLE IF NOT EXISTS meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`,
+}
+
+// eventsOffsetKey is the meta key holding how far into the events log the
+// database has read.
+const eventsOffsetKey = "events_offset"
+
+// DB is the state database. It is safe for concurrent use.
+type DB struct {
+	db *sql.DB
+}
+
+// Path returns the state database path for the given .ralph directory.
+func Path(configDir string) string {
+	return filepath.Join(configDir, FileName)
+}
+
+// Open opens (creating if needed) the state database at path.
+func Open(path string) (*DB, error) {
+	return open(DriverName, path)
+}
+
+// open opens the database with the given driver and creates its tables.
+func open(driver, dsn string) (*DB, error) {
+	registered := false
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			registered = true
+		}
+	}
+	if !registered {
+		return nil, ErrNoDriver
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dsn), 0755); err != nil {
+		return nil, fmt.Errorf("creating state database directory: %w", err)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("opening state database: %w", err)
+	}
+	// One connection keeps the pragmas in effect and serializes writers
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range append([]string{"PRAGMA busy_timeout = 5000", "PRAGMA journal_mode = WAL"}, schema...) {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("initializing state database: %w", err)
+		}
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// PlanMoved records that a plan is now in the named queue directory.
+// It implements plan.Index.
+func (d *DB) PlanMoved(p *plan.Plan, dir string) error {
+	return upsertPlan(d.db, p, dir, fileModTime(p.Path))
+}
+
+// Plans returns the plans in the named queue directory, sorted by name.
+// It implements plan.Index.
+func (d *DB) Plans(dir string) ([]*plan.Plan, error) {
+	rows, err := d.db.Query(`SELECT name, path, branch, labels FROM plans WHERE dir = ? ORDER BY name`, dir)
+	if err != nil {
+		return nil, fmt.Errorf("querying plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []*plan.Plan{}
+	for rows.Next() {
+		var p plan.Plan
+		var labels string
+		if err := rows.Scan(&p.Name, &p.Path, &p.Branch, &labels); err != nil {
+			return nil, fmt.Errorf("reading plans: %w", err)
+		}
+		if labels != "" {
+			p.Labels = strings.Split(labels, ",")
+		}
+		plans = append(plans, &p)
+	}
+	return plans, rows.Err()
+}
+
+// RecordEvent records an event. An event already recorded is ignored.
+func (d *DB) RecordEvent(e events.Event) error {
+	return insertEvent(d.db, e)
+}
+
+// Events returns the events with Time after since, oldest first.
+// A zero since returns all events.
+func (d *DB) Events(since time.Time) ([]events.Event, error) {
+	var from int64
+	if !since.IsZero() {
+		from = since.UnixNano()
+	}
+	rows, err := d.db.Query(`SELECT data FROM events WHERE time > ? ORDER BY time, rowid`, from)
+	if err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []events.Event
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("reading events: %w", err)
+		}
+		var e events.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// Usage is what a plan's iterations cost.
+type Usage struct {
+	// Iterations is the number of iterations run.
+	Iterations int
+
+	// Duration is the iterations' total wall time.
+	Duration time.Duration
+
+	// InputTokens and OutputTokens are the iterations' total token counts.
+	InputTokens  int
+	OutputTokens int
+}
+
+// Usage returns the total cost of a plan's iterations.
+func (d *DB) Usage(planName string) (Usage, error) {
+	var u Usage
+	var duration int64
+	err := d.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(duration), 0), COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0)
+		FROM events WHERE plan = ? AND type = ?`, planName, events.TypeIteration).
+		Scan(&u.Iterations, &duration, &u.InputTokens, &u.OutputTokens)
+	if err != nil {
+		return Usage{}, fmt.Errorf("querying usage: %w", err)
+	}
+	u.Duration = time.Duration(duration)
+	return u, nil
+}
+
+// execer is what the write helpers need from a *sql.DB or *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// upsertPlan records a plan's directory and metadata.
+func upsertPlan(x execer, p *plan.Plan, dir string, mtime int64) error {
+	_, err := x.Exec(`INSERT INTO plans (file, name, dir, path, branch, labels, mtime, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (file) DO UPDATE SET name = excluded.name, dir = excluded.dir, path = excluded.path,
+			branch = excluded.branch, labels = excluded.labels, mtime = excluded.mtime, updated_at = excluded.updated_at`,
+		filepath.Base(p.Path), p.Name, dir, p.Path, p.Branch, strings.Join(p.Labels, ","), mtime, time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("recording plan %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// insertEvent records an event unless it's already there.
+func insertEvent(x execer, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+	_, err = x.Exec(`INSERT OR IGNORE INTO events (time, type, plan, iteration, duration, input_tokens, output_tokens, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Time.UnixNano(), e.Type, e.Plan, e.Iteration, int64(e.Duration), e.InputTokens, e.OutputTokens, string(data))
+	if err != nil {
+		return fmt.Errorf("recording %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// fileModTime returns a file's modification time in Unix nanoseconds, or 0
+// if it can't be read.
+func fileModTime(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
@@ -0,0 +1,19 @@
+package state
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	if got := Path(".ralph"); got != filepath.Join(".ralph", "state.db") {
+		t.Errorf("Path() = %q", got)
+	}
+}
+
+func TestOpen_NoDriver(t *testing.T) {
+	if _, err := open("nosuchdriver", Path(t.TempDir())); !errors.Is(err, ErrNoDriver) {
+		t.Errorf("open() error = %v, want ErrNoDriver", err)
+	}
+}
@@ -0,0 +1,164 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// SyncResult counts what Sync changed.
+type SyncResult struct {
+	// Plans is the number of plans added or updated.
+	Plans int
+
+	// Removed is the number of plans no longer in the queue.
+	Removed int
+
+	// Events is the number of events read from the events log.
+	Events int
+}
+
+// Sync brings the database up to date with the queue and the events log
+// (either may be nil), catching up on changes made without it: plans added,
+// edited, moved or deleted by hand or by commands that don't use the
+// database. Only plans whose file is new, moved, or modified since it was
+// recorded are parsed, and only the events appended since the last Sync are
+// read.
+func (d *DB) Sync(q *plan.Queue, log *events.Log) (SyncResult, error) {
+	var result SyncResult
+	if q != nil {
+		if err := d.syncPlans(q, &result); err != nil {
+			return result, err
+		}
+	}
+	if log != nil {
+		if err := d.syncEvents(log, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// recorded is a plan file as the database knows it.
+type recorded struct {
+	dir   string
+	mtime int64
+}
+
+// syncPlans reconciles the plans table with the queue directories.
+func (d *DB) syncPlans(q *plan.Queue, result *SyncResult) error {
+	known := make(map[string]recorded)
+	rows, err := d.db.Query(`SELECT file, dir, mtime FROM plans`)
+	if err != nil {
+		return fmt.Errorf("querying plans: %w", err)
+	}
+	for rows.Next() {
+		var file string
+		var r recorded
+		if err := rows.Scan(&file, &r.dir, &r.mtime); err != nil {
+			rows.Close()
+			return fmt.Errorf("reading plans: %w", err)
+		}
+		known[file] = r
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("reading plans: %w", err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	seen := make(map[string]bool)
+	for _, dir := range plan.QueueDirs {
+		entries, err := os.ReadDir(filepath.Join(q.BaseDir, dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("listing %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !plan.IsPlanFile(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			seen[entry.Name()] = true
+			mtime := info.ModTime().UnixNano()
+			if r, ok := known[entry.Name()]; ok && r.dir == dir && r.mtime == mtime {
+				continue
+			}
+
+			p, err := plan.Load(filepath.Join(q.BaseDir, dir, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("loading plan %s: %w", entry.Name(), err)
+			}
+			if err := upsertPlan(tx, p, dir, mtime); err != nil {
+				return err
+			}
+			result.Plans++
+		}
+	}
+
+	for file := range known {
+		if seen[file] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM plans WHERE file = ?`, file); err != nil {
+			return fmt.Errorf("removing plan %s: %w", file, err)
+		}
+		result.Removed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing sync: %w", err)
+	}
+	return nil
+}
+
+// syncEvents records the events appended to the log since the last sync.
+// A log that was truncated or replaced is read again from the start; the
+// events already recorded are skipped.
+func (d *DB) syncEvents(log *events.Log, result *SyncResult) error {
+	var offset int64
+	var value string
+	if err := d.db.QueryRow(`SELECT value FROM meta WHERE key = ?`, eventsOffsetKey).Scan(&value); err == nil {
+		offset, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	evs, next, err := log.ReadFrom(offset)
+	if err != nil {
+		return fmt.Errorf("reading events log: %w", err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("starting sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, e := range evs {
+		if err := insertEvent(tx, e); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		eventsOffsetKey, strconv.FormatInt(next, 10)); err != nil {
+		return fmt.Errorf("recording events offset: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing sync: %w", err)
+	}
+	result.Events = len(evs)
+	return nil
+}
@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateFileName is the name of the tracker sync state file in the .ralph directory.
+const StateFileName = "trackers.json"
+
+// State records the last stage synced to each issue, so a resumed or
+// retried plan doesn't transition or comment twice, and the worker knows
+// which issues still wait for their pull request to merge.
+type State struct {
+	path string
+	mu   sync.Mutex
+
+	// Issues maps "<tracker>:<key>" to the issue's last synced stage.
+	Issues map[string]string `json:"issues"`
+}
+
+// StatePath returns the tracker sync state path for the given .ralph directory.
+func StatePath(configDir string) string {
+	return filepath.Join(configDir, StateFileName)
+}
+
+// LoadState reads the sync state at path. A missing file is an empty state.
+func LoadState(path string) (*State, error) {
+	s := &State{path: path, Issues: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading tracker state: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing tracker state: %w", err)
+	}
+	if s.Issues == nil {
+		s.Issues = make(map[string]string)
+	}
+	return s, nil
+}
+
+// Stage returns the last stage synced to the tracker's issue, or "".
+func (s *State) Stage(tracker, key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Issues[tracker+":"+key]
+}
+
+// Record saves stage as the tracker's issue's last synced stage.
+func (s *State) Record(tracker, key, stage string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Issues[tracker+":"+key] = stage
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("writing tracker state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,35 @@
+package tracker
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestState_RecordAndLoad(t *testing.T) {
+	path := StatePath(t.TempDir())
+
+	s, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() of a missing file error = %v", err)
+	}
+	if got := s.Stage("jira", "PROJ-1"); got != "" {
+		t.Errorf("Stage() = %q, want empty", got)
+	}
+	if err := s.Record("jira", "PROJ-1", StageInReview); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	reloaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if got := reloaded.Stage("jira", "PROJ-1"); got != StageInReview {
+		t.Errorf("Stage() after reload = %q, want %q", got, StageInReview)
+	}
+	if got := reloaded.Stage("linear", "PROJ-1"); got != "" {
+		t.Errorf("Stage() of the same key in another tracker = %q, want empty", got)
+	}
+	if filepath.Base(path) != StateFileName {
+		t.Errorf("StatePath() = %q", path)
+	}
+}
@@ -0,0 +1,180 @@
+// Package tracker defines the interface issue trackers (GitHub Issues,
+// Jira, Linear) implement, and a registry of them, so import commands and
+// the worker can work with a plan's linked issues without tracker-specific
+// code. Each tracker package registers itself from its init function.
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// Stages of a plan's issue, in the order the worker reaches them.
+const (
+	// StageInProgress is reached when the plan is activated.
+	StageInProgress = "in_progress"
+
+	// StageInReview is reached when the plan's pull request is opened.
+	StageInReview = "in_review"
+
+	// StageDone is reached when the plan's branch is merged.
+	StageDone = "done"
+)
+
+// ErrNoTransition is returned by Transition when the issue's workflow has
+// no way to the stage's status, e.g. because the issue is already there.
+var ErrNoTransition = errors.New("no matching transition")
+
+// ErrNotConfigured is wrapped by a Factory's error when the tracker's
+// config section doesn't enable it.
+var ErrNotConfigured = errors.New("not configured")
+
+// Issue is the part of an issue ralph uses, in any tracker.
+type Issue struct {
+	// Key identifies the issue in its tracker, e.g. "PROJ-123" or "42".
+	Key string
+
+	// Title is the issue title.
+	Title string
+
+	// Body is the issue description.
+	Body string
+
+	// Labels are the issue's labels.
+	Labels []string
+
+	// Status is the issue's current status or workflow state.
+	Status string
+
+	// URL is the issue's web link.
+	URL string
+}
+
+// HasLabel reports whether the issue has the label, ignoring case.
+func (i *Issue) HasLabel(label string) bool {
+	for _, l := range i.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tracker is an issue tracker plans can be linked to.
+type Tracker interface {
+	// Name identifies the tracker in logs, sync state, and the registry,
+	// e.g. "jira".
+	Name() string
+
+	// ParseKey normalizes an issue key given on the command line, or
+	// returns an error describing the expected form.
+	ParseKey(s string) (string, error)
+
+	// IssueFor returns the key of the plan's issue in this tracker, or ""
+	// if the plan isn't linked to one.
+	IssueFor(p *plan.Plan) string
+
+	// FetchIssue fetches an issue by key.
+	FetchIssue(key string) (*Issue, error)
+
+	// PlanOptions builds scaffold options for a plan implementing the
+	// issue, including the header that links the plan to it.
+	PlanOptions(issue *Issue) plan.ScaffoldOptions
+
+	// Comment posts text on the issue.
+	Comment(key, text string) error
+
+	// Transition moves the issue to the status configured for stage. A
+	// stage without a status is a no-op.
+	Transition(key, stage string) error
+
+	// LinkPR links the pull request at url to the issue.
+	LinkPR(key, url string) error
+}
+
+// Factory creates a tracker from the config. It returns an error wrapping
+// ErrNotConfigured if the config doesn't enable the tracker.
+type Factory func(cfg *config.Config) (Tracker, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a tracker available under name. It panics if name is
+// registered twice.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("tracker: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// Names returns the registered tracker names, sorted.
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the tracker registered under name from cfg.
+func New(name string, cfg *config.Config) (Tracker, error) {
+	registryMu.Lock()
+	factory, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown tracker %q (known: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Configured creates every registered tracker cfg enables, in name order.
+// Trackers that fail to start for any other reason than not being
+// configured are skipped and reported in the returned error.
+func Configured(cfg *config.Config) ([]Tracker, error) {
+	var trackers []Tracker
+	var errs []error
+	for _, name := range Names() {
+		t, err := New(name, cfg)
+		if errors.Is(err, ErrNotConfigured) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		trackers = append(trackers, t)
+	}
+	return trackers, errors.Join(errs...)
+}
+
+// ScaffoldOptions builds the tracker-independent part of a plan for the
+// issue: the title starts with the key, so the plan's branch name carries
+// it and the tracker can link the branch to the issue, the description
+// becomes the Context, and the issue's labels the plan's labels. source
+// names the tracker, e.g. "Jira".
+func ScaffoldOptions(issue *Issue, source string) plan.ScaffoldOptions {
+	body := strings.TrimSpace(issue.Body)
+	if body == "" {
+		body = issue.Title
+	}
+	return plan.ScaffoldOptions{
+		Title:  fmt.Sprintf("%s %s", issue.Key, issue.Title),
+		Body:   body,
+		Source: fmt.Sprintf("%s %s (%s)", source, issue.Key, issue.URL),
+		Labels: issue.Labels,
+	}
+}
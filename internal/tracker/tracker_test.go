@@ -0,0 +1,77 @@
+package tracker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// fakeTracker is a tracker that does nothing.
+type fakeTracker struct{ name string }
+
+func (f *fakeTracker) Name() string                              { return f.name }
+func (f *fakeTracker) ParseKey(s string) (string, error)         { return s, nil }
+func (f *fakeTracker) IssueFor(p *plan.Plan) string              { return "" }
+func (f *fakeTracker) FetchIssue(key string) (*Issue, error)     { return &Issue{Key: key}, nil }
+func (f *fakeTracker) PlanOptions(i *Issue) plan.ScaffoldOptions { return ScaffoldOptions(i, f.name) }
+func (f *fakeTracker) Comment(key, text string) error            { return nil }
+func (f *fakeTracker) Transition(key, stage string) error        { return nil }
+func (f *fakeTracker) LinkPR(key, url string) error              { return nil }
+
+func TestRegistry(t *testing.T) {
+	Register("test-on", func(cfg *config.Config) (Tracker, error) {
+		return &fakeTracker{name: "test-on"}, nil
+	})
+	Register("test-off", func(cfg *config.Config) (Tracker, error) {
+		return nil, ErrNotConfigured
+	})
+	Register("test-broken", func(cfg *config.Config) (Tracker, error) {
+		return nil, errors.New("bad credentials")
+	})
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "test-on")
+		delete(registry, "test-off")
+		delete(registry, "test-broken")
+		registryMu.Unlock()
+	}()
+
+	if _, err := New("test-missing", config.Defaults()); err == nil || !strings.Contains(err.Error(), "unknown tracker") {
+		t.Errorf("New() of an unknown tracker error = %v", err)
+	}
+
+	trackers, err := Configured(config.Defaults())
+	if len(trackers) != 1 || trackers[0].Name() != "test-on" {
+		t.Errorf("Configured() = %v, want only the enabled tracker", trackers)
+	}
+	if err == nil || !strings.Contains(err.Error(), "test-broken: bad credentials") {
+		t.Errorf("Configured() error = %v, want the broken tracker reported", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() twice should panic")
+		}
+	}()
+	Register("test-on", nil)
+}
+
+func TestScaffoldOptions(t *testing.T) {
+	issue := &Issue{Key: "PROJ-1", Title: "Fix login", Labels: []string{"web"}, URL: "https://acme.atlassian.net/browse/PROJ-1"}
+	opts := ScaffoldOptions(issue, "Jira")
+	if opts.Title != "PROJ-1 Fix login" || len(opts.Labels) != 1 {
+		t.Errorf("ScaffoldOptions() = %+v", opts)
+	}
+	if opts.Body != "Fix login" {
+		t.Errorf("Body = %q, want the title when there is no description", opts.Body)
+	}
+	if opts.Source != "Jira PROJ-1 (https://acme.atlassian.net/browse/PROJ-1)" {
+		t.Errorf("Source = %q", opts.Source)
+	}
+	if !issue.HasLabel("WEB") || issue.HasLabel("api") {
+		t.Error("HasLabel() should match labels case-insensitively")
+	}
+}
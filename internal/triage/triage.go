@@ -0,0 +1,164 @@
+// Package triage extracts the failing tests and error excerpts from a CI log
+// and turns them into a plan scoped to fixing the failure.
+package triage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// requestTimeout bounds fetching a CI log.
+const requestTimeout = 30 * time.Second
+
+// maxLogSize caps how much of a CI log is read.
+const maxLogSize = 20 << 20
+
+// Excerpt limits: lines kept before and after each failure line, and in total.
+const (
+	contextBefore   = 5
+	contextAfter    = 15
+	maxExcerptLines = 200
+)
+
+// maxTestTasks caps the per-test tasks of a plan; further tests are listed in
+// the Context only.
+const maxTestTasks = 10
+
+// ansiRegex matches terminal color codes.
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// timestampRegex matches the timestamp GitHub Actions prefixes to each line.
+var timestampRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z ?`)
+
+// failureRegex matches lines that report a failure: failed tests, panics,
+// tracebacks, compiler and runner errors.
+var failureRegex = regexp.MustCompile(`(?i)(^\s*--- FAIL|^FAIL\b|^FAILED |^panic:|^fatal error:|Traceback \(most recent call last\)|^##\[error\]|^\S+\.\w+:\d+:\d+: |\berror(\[E\d+\])?:|✕|● )`)
+
+// Failure is what a CI log says failed.
+type Failure struct {
+	// Tests are the failing tests, in order of appearance.
+	Tests []string
+
+	// Excerpt is the log lines around each failure, with "..." between
+	// non-adjacent parts.
+	Excerpt string
+}
+
+// Fetch downloads a CI log.
+func Fetch(url string) (string, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetching CI log: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching CI log: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogSize))
+	if err != nil {
+		return "", fmt.Errorf("reading CI log: %w", err)
+	}
+	return string(data), nil
+}
+
+// Extract finds the failing tests and the lines around each failure in log.
+func Extract(log string) Failure {
+	lines := strings.Split(clean(log), "\n")
+
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if !failureRegex.MatchString(line) {
+			continue
+		}
+		for j := max(0, i-contextBefore); j <= i+contextAfter && j < len(lines); j++ {
+			keep[j] = true
+		}
+	}
+
+	var excerpt []string
+	kept := 0
+	for i, line := range lines {
+		if !keep[i] {
+			continue
+		}
+		if kept == maxExcerptLines {
+			excerpt = append(excerpt, "... (excerpt truncated)")
+			break
+		}
+		if i > 0 && !keep[i-1] && len(excerpt) > 0 {
+			excerpt = append(excerpt, "...")
+		}
+		excerpt = append(excerpt, line)
+		kept++
+	}
+
+	return Failure{
+		Tests:   gate.FailedTests(strings.Join(lines, "\n")),
+		Excerpt: strings.TrimSpace(strings.Join(excerpt, "\n")),
+	}
+}
+
+// clean removes color codes, CI timestamps, and carriage returns from log.
+func clean(log string) string {
+	log = strings.ReplaceAll(log, "\r\n", "\n")
+	lines := strings.Split(log, "\n")
+	for i, line := range lines {
+		line = ansiRegex.ReplaceAllString(line, "")
+		lines[i] = timestampRegex.ReplaceAllString(line, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PlanOptions returns the options of a pending plan fixing f: a task to
+// reproduce the failure, then one per failing test (or one for the whole
+// failure if no test is named), with the tests and log excerpt in the Context.
+func PlanOptions(f Failure, source string) plan.ScaffoldOptions {
+	title := "Fix CI failure"
+	switch len(f.Tests) {
+	case 0:
+	case 1:
+		title = "Fix CI failure in " + f.Tests[0]
+	default:
+		title = fmt.Sprintf("Fix CI failure in %s and %d more", f.Tests[0], len(f.Tests)-1)
+	}
+
+	var body strings.Builder
+	body.WriteString("CI failed. Reproduce the failure locally, find the root cause, and fix it. Don't skip, disable, or loosen tests to make them pass.\n")
+	if len(f.Tests) > 0 {
+		body.WriteString("\n**Failing tests:**\n")
+		for _, test := range f.Tests {
+			fmt.Fprintf(&body, "- `%s`\n", test)
+		}
+	}
+	if f.Excerpt != "" {
+		fmt.Fprintf(&body, "\n**Log excerpt:**\n```text\n%s\n```\n", f.Excerpt)
+	}
+
+	tasks := []string{"Reproduce the CI failure locally"}
+	for i, test := range f.Tests {
+		if i == maxTestTasks {
+			tasks = append(tasks, fmt.Sprintf("Fix the remaining %d failing tests", len(f.Tests)-maxTestTasks))
+			break
+		}
+		tasks = append(tasks, "Fix "+test)
+	}
+	if len(f.Tests) == 0 {
+		tasks = append(tasks, "Fix the failure")
+	}
+
+	return plan.ScaffoldOptions{
+		Title:  title,
+		Body:   body.String(),
+		Source: source,
+		Tasks:  tasks,
+		Labels: []string{"ci"},
+	}
+}
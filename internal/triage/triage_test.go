@@ -0,0 +1,86 @@
+package triage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const ciLog = "2024-05-01T10:00:00.1234567Z ##[group]Run go test ./...\n" +
+	"2024-05-01T10:00:01.0000000Z ok  \tgithub.com/acme/app/config\t0.01s\n" +
+	"2024-05-01T10:00:02.0000000Z \x1b[31m--- FAIL: TestParse (0.00s)\x1b[0m\n" +
+	"2024-05-01T10:00:02.0000000Z     parse_test.go:12: got 1, want 2\n" +
+	"2024-05-01T10:00:02.0000000Z FAIL\n" +
+	"2024-05-01T10:00:02.0000000Z FAIL\tgithub.com/acme/app/parse\t0.02s\n" +
+	"2024-05-01T10:00:03.0000000Z ##[error]Process completed with exit code 1.\r\n"
+
+func TestExtract(t *testing.T) {
+	f := Extract(ciLog)
+	if len(f.Tests) != 1 || f.Tests[0] != "TestParse" {
+		t.Errorf("Tests = %v, want TestParse", f.Tests)
+	}
+	for _, want := range []string{"--- FAIL: TestParse (0.00s)", "parse_test.go:12: got 1, want 2", "##[error]Process completed with exit code 1."} {
+		if !strings.Contains(f.Excerpt, want) {
+			t.Errorf("Excerpt missing %q:\n%s", want, f.Excerpt)
+		}
+	}
+	if strings.Contains(f.Excerpt, "2024-05-01T") || strings.Contains(f.Excerpt, "\x1b[") || strings.Contains(f.Excerpt, "\r") {
+		t.Errorf("Excerpt not cleaned:\n%q", f.Excerpt)
+	}
+}
+
+func TestExtract_Excerpt(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "building...")
+	}
+	lines[50] = "panic: runtime error: index out of range"
+	f := Extract(strings.Join(lines, "\n"))
+
+	got := strings.Split(f.Excerpt, "\n")
+	if len(got) != contextBefore+1+contextAfter || got[contextBefore] != lines[50] {
+		t.Errorf("Excerpt = %d lines, want the panic with its context:\n%s", len(got), f.Excerpt)
+	}
+
+	if f := Extract("all good\nok\n"); f.Excerpt != "" || len(f.Tests) != 0 {
+		t.Errorf("Extract() of a passing log = %+v", f)
+	}
+}
+
+func TestPlanOptions(t *testing.T) {
+	opts := PlanOptions(Failure{Tests: []string{"TestA", "TestB"}, Excerpt: "--- FAIL: TestA"}, "https://ci.example.com/1")
+	if opts.Title != "Fix CI failure in TestA and 1 more" {
+		t.Errorf("Title = %q", opts.Title)
+	}
+	if strings.Join(opts.Tasks, "|") != "Reproduce the CI failure locally|Fix TestA|Fix TestB" {
+		t.Errorf("Tasks = %v", opts.Tasks)
+	}
+	if !strings.Contains(opts.Body, "```text\n--- FAIL: TestA\n```") || !strings.Contains(opts.Body, "- `TestB`") {
+		t.Errorf("Body = %q", opts.Body)
+	}
+
+	opts = PlanOptions(Failure{Excerpt: "error: linker failed"}, "")
+	if opts.Title != "Fix CI failure" || strings.Join(opts.Tasks, "|") != "Reproduce the CI failure locally|Fix the failure" {
+		t.Errorf("PlanOptions() without tests = %+v", opts)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(ciLog))
+	}))
+	defer server.Close()
+
+	log, err := Fetch(server.URL + "/log")
+	if err != nil || log != ciLog {
+		t.Errorf("Fetch() = %q, %v", log, err)
+	}
+	if _, err := Fetch(server.URL + "/missing"); err == nil {
+		t.Error("Fetch() should fail for a 404")
+	}
+}
@@ -0,0 +1,407 @@
+// Package update implements self-update from GitHub releases.
+// Release archives are verified against the release's checksums.txt and,
+// when the binary was built with a public key, an ed25519 signature of it.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Release asset names.
+const (
+	// ChecksumsAsset lists the sha256 of every archive (GoReleaser format).
+	ChecksumsAsset = "checksums.txt"
+
+	// SignatureAsset is the base64 ed25519 signature of ChecksumsAsset.
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// DefaultAPIURL is the GitHub API base URL.
+const DefaultAPIURL = "https://api.github.com"
+
+// PublicKey is the base64 ed25519 key release checksums are signed with.
+// It is set at build time using -ldflags; when empty, only checksums are verified.
+var PublicKey = ""
+
+// Common errors returned by update operations.
+var (
+	// ErrNoAsset is returned when a release has no archive for this platform.
+	ErrNoAsset = errors.New("no release asset for this platform")
+
+	// ErrChecksumMismatch is returned when a download doesn't match checksums.txt.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrBadSignature is returned when checksums.txt fails signature verification.
+	ErrBadSignature = errors.New("invalid checksums signature")
+)
+
+// Release is a GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the named asset, or nil.
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Client fetches and verifies releases of a GitHub repository.
+type Client struct {
+	// Owner and Repo identify the repository.
+	Owner string
+	Repo  string
+
+	// APIURL is the GitHub API base URL (DefaultAPIURL if empty).
+	APIURL string
+
+	// PublicKey overrides the package PublicKey (base64 ed25519).
+	PublicKey string
+
+	// HTTP is the client used for requests (a 60s-timeout client if nil).
+	HTTP *http.Client
+}
+
+// NewClient creates a Client for arvesolland/ralph releases.
+func NewClient() *Client {
+	return &Client{
+		Owner:     "arvesolland",
+		Repo:      "ralph",
+		APIURL:    DefaultAPIURL,
+		PublicKey: PublicKey,
+		HTTP:      &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// LatestRelease returns the latest published release.
+func (c *Client) LatestRelease(ctx context.Context) (*Release, error) {
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	data, err := c.get(ctx, fmt.Sprintf("%s/repos/%s/%s/releases/latest", strings.TrimSuffix(apiURL, "/"), c.Owner, c.Repo))
+	if err != nil {
+		return nil, fmt.Errorf("fetching latest release: %w", err)
+	}
+
+	var rel Release
+	if err := json.Unmarshal(data, &rel); err != nil {
+		return nil, fmt.Errorf("parsing release: %w", err)
+	}
+	return &rel, nil
+}
+
+// Download fetches this platform's archive from rel, verifies it, and
+// returns the extracted ralph binary.
+func (c *Client) Download(ctx context.Context, rel *Release) ([]byte, error) {
+	name := AssetName(rel.Version(), runtime.GOOS, runtime.GOARCH)
+	asset := rel.Asset(name)
+	if asset == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoAsset, name)
+	}
+
+	sums, err := c.checksums(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := c.get(ctx, asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", name, err)
+	}
+	if err := VerifyChecksum(archive, name, sums); err != nil {
+		return nil, err
+	}
+
+	return ExtractBinary(archive, name, BinaryName(runtime.GOOS))
+}
+
+// checksums downloads checksums.txt and verifies its signature when a
+// public key is configured.
+func (c *Client) checksums(ctx context.Context, rel *Release) ([]byte, error) {
+	asset := rel.Asset(ChecksumsAsset)
+	if asset == nil {
+		return nil, fmt.Errorf("release %s has no %s", rel.TagName, ChecksumsAsset)
+	}
+	sums, err := c.get(ctx, asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", ChecksumsAsset, err)
+	}
+
+	if c.PublicKey == "" {
+		return sums, nil
+	}
+
+	sigAsset := rel.Asset(SignatureAsset)
+	if sigAsset == nil {
+		return nil, fmt.Errorf("%w: release %s is not signed", ErrBadSignature, rel.TagName)
+	}
+	sig, err := c.get(ctx, sigAsset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", SignatureAsset, err)
+	}
+	if err := VerifySignature(sums, sig, c.PublicKey); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// get performs a GET request and returns the response body.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "ralph-self-update")
+
+	httpClient := c.HTTP
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// AssetName returns the GoReleaser archive name for a version and platform.
+func AssetName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("ralph_%s_%s_%s.%s", version, goos, goarch, ext)
+}
+
+// BinaryName returns the executable name inside the archive.
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "ralph.exe"
+	}
+	return "ralph"
+}
+
+// VerifyChecksum checks data against the sha256 listed for name in a
+// checksums.txt file ("<hex>  <name>" per line).
+func VerifyChecksum(data []byte, name string, checksums []byte) error {
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != strings.ToLower(fields[0]) {
+			return fmt.Errorf("%w for %s", ErrChecksumMismatch, name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s not listed in %s", name, ChecksumsAsset)
+}
+
+// VerifySignature checks a base64 ed25519 signature of data against a
+// base64 public key.
+func VerifySignature(data, sig []byte, publicKey string) error {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed public key", ErrBadSignature)
+	}
+	rawSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrBadSignature)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, rawSig) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ExtractBinary returns the named file from a .tar.gz or .zip archive.
+func ExtractBinary(archive []byte, archiveName, binary string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, fmt.Errorf("opening zip: %w", err)
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) != binary {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binary {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+}
+
+// ReplaceExecutable atomically replaces the file at path with data.
+// The new binary is written next to it and renamed into place, so a failed
+// update leaves the old binary intact.
+func ReplaceExecutable(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("checking executable: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".ralph-update-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file (is %s writable?): %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0111); err != nil {
+		return fmt.Errorf("setting permissions: %w", err)
+	}
+
+	// Windows can't replace a running executable, but it can rename it
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return fmt.Errorf("moving old binary aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing binary: %w", err)
+	}
+	return nil
+}
+
+// CompareVersions compares two semantic versions ("1.2.3", "v1.2.3-rc1").
+// Returns -1 if a < b, 0 if equal, 1 if a > b. A pre-release sorts before
+// its release. Returns an error if either version can't be parsed.
+func CompareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < 3; i++ {
+		if pa.nums[i] != pb.nums[i] {
+			if pa.nums[i] < pb.nums[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+
+	switch {
+	case pa.pre == pb.pre:
+		return 0, nil
+	case pa.pre == "":
+		return 1, nil
+	case pb.pre == "":
+		return -1, nil
+	case pa.pre < pb.pre:
+		return -1, nil
+	default:
+		return 1, nil
+	}
+}
+
+// version is a parsed semantic version.
+type version struct {
+	nums [3]int
+	pre  string
+}
+
+// parseVersion parses "v1.2.3", "1.2", or "1.2.3-rc1".
+func parseVersion(s string) (version, error) {
+	var v version
+	core, pre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(s), "v"), "-")
+	v.pre = pre
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v.nums[i] = n
+	}
+	return v, nil
+}
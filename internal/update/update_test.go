@@ -0,0 +1,223 @@
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0", "1.9.9", 1},
+		{"1.2.3-rc1", "1.2.3", -1},
+		{"1.2.3-rc2", "1.2.3-rc1", 1},
+	}
+
+	for _, tt := range tests {
+		got, err := CompareVersions(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	if _, err := CompareVersions("dev", "1.0.0"); err == nil {
+		t.Error("expected error for unparseable version")
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("1.2.3", "darwin", "arm64"); got != "ralph_1.2.3_darwin_arm64.tar.gz" {
+		t.Errorf("AssetName() = %q", got)
+	}
+	if got := AssetName("1.2.3", "windows", "amd64"); got != "ralph_1.2.3_windows_amd64.zip" {
+		t.Errorf("AssetName() = %q", got)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive")
+	sums := []byte(checksumLine(data, "ralph_1.0.0_linux_amd64.tar.gz") + "deadbeef  other.tar.gz\n")
+
+	if err := VerifyChecksum(data, "ralph_1.0.0_linux_amd64.tar.gz", sums); err != nil {
+		t.Errorf("VerifyChecksum() error = %v", err)
+	}
+	if err := VerifyChecksum([]byte("tampered"), "ralph_1.0.0_linux_amd64.tar.gz", sums); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("tampered archive: err = %v, want ErrChecksumMismatch", err)
+	}
+	if err := VerifyChecksum(data, "missing.tar.gz", sums); err == nil {
+		t.Error("expected error for an archive not listed")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	key := base64.StdEncoding.EncodeToString(pub)
+	data := []byte("checksums")
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data)) + "\n")
+
+	if err := VerifySignature(data, sig, key); err != nil {
+		t.Errorf("VerifySignature() error = %v", err)
+	}
+	if err := VerifySignature([]byte("tampered"), sig, key); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered data: err = %v, want ErrBadSignature", err)
+	}
+	if err := VerifySignature(data, sig, "not-a-key"); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("bad key: err = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestExtractBinary(t *testing.T) {
+	tgz := tarGz(t, map[string]string{"README.md": "readme", "ralph": "binary"})
+	if got, err := ExtractBinary(tgz, "x.tar.gz", "ralph"); err != nil || string(got) != "binary" {
+		t.Errorf("ExtractBinary(tar.gz) = %q, %v", got, err)
+	}
+	if _, err := ExtractBinary(tgz, "x.tar.gz", "missing"); err == nil {
+		t.Error("expected error for a missing binary")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, _ := zw.Create("ralph.exe")
+	f.Write([]byte("windows binary"))
+	zw.Close()
+	if got, err := ExtractBinary(buf.Bytes(), "x.zip", "ralph.exe"); err != nil || string(got) != "windows binary" {
+		t.Errorf("ExtractBinary(zip) = %q, %v", got, err)
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ralph")
+	os.WriteFile(path, []byte("old"), 0755)
+
+	if err := ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("ReplaceExecutable() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "new" {
+		t.Errorf("binary = %q, want new", data)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("binary not executable: %v", info.Mode())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
+
+func TestClient_LatestReleaseAndDownload(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	srv := newReleaseServer(t, "v1.5.0", []byte("new binary"), priv)
+	defer srv.Close()
+
+	c := &Client{Owner: "arvesolland", Repo: "ralph", APIURL: srv.URL, PublicKey: base64.StdEncoding.EncodeToString(pub)}
+
+	rel, err := c.LatestRelease(context.Background())
+	if err != nil {
+		t.Fatalf("LatestRelease() error = %v", err)
+	}
+	if rel.Version() != "1.5.0" {
+		t.Errorf("Version() = %q", rel.Version())
+	}
+
+	binary, err := c.Download(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if string(binary) != "new binary" {
+		t.Errorf("binary = %q", binary)
+	}
+
+	// A different key rejects the release
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	c.PublicKey = base64.StdEncoding.EncodeToString(otherPub)
+	if _, err := c.Download(context.Background(), rel); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("wrong key: err = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestClient_Download_NoAsset(t *testing.T) {
+	rel := &Release{TagName: "v1.0.0"}
+	if _, err := (&Client{}).Download(context.Background(), rel); !errors.Is(err, ErrNoAsset) {
+		t.Errorf("err = %v, want ErrNoAsset", err)
+	}
+}
+
+// newReleaseServer serves a GitHub "latest release" for this platform with
+// a signed checksums.txt.
+func newReleaseServer(t *testing.T, tag string, binary []byte, priv ed25519.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	name := AssetName(tag[1:], runtime.GOOS, runtime.GOARCH)
+	var archive []byte
+	if runtime.GOOS == "windows" {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create(BinaryName(runtime.GOOS))
+		f.Write(binary)
+		zw.Close()
+		archive = buf.Bytes()
+	} else {
+		archive = tarGz(t, map[string]string{BinaryName(runtime.GOOS): string(binary)})
+	}
+	sums := []byte(checksumLine(archive, name))
+	sig := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, sums)))
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	mux.HandleFunc("/repos/arvesolland/ralph/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Release{TagName: tag, Assets: []Asset{
+			{Name: name, URL: srv.URL + "/dl/archive"},
+			{Name: ChecksumsAsset, URL: srv.URL + "/dl/sums"},
+			{Name: SignatureAsset, URL: srv.URL + "/dl/sig"},
+		}})
+	})
+	mux.HandleFunc("/dl/archive", func(w http.ResponseWriter, r *http.Request) { w.Write(archive) })
+	mux.HandleFunc("/dl/sums", func(w http.ResponseWriter, r *http.Request) { w.Write(sums) })
+	mux.HandleFunc("/dl/sig", func(w http.ResponseWriter, r *http.Request) { w.Write(sig) })
+	return srv
+}
+
+// checksumLine returns a checksums.txt line for data.
+func checksumLine(data []byte, name string) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+}
+
+// tarGz builds a .tar.gz archive of files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
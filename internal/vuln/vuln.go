@@ -0,0 +1,438 @@
+// Package vuln parses vulnerability scanner reports (trivy, govulncheck) and
+// groups their findings into security fix plans.
+package vuln
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// Severities, most severe first.
+const (
+	Critical = "critical"
+	High     = "high"
+	Medium   = "medium"
+	Low      = "low"
+	Unknown  = "unknown"
+)
+
+// severityOrder ranks the severities; plans are created and titled in this order.
+var severityOrder = []string{Critical, High, Medium, Low, Unknown}
+
+// Grouping modes for plans.
+const (
+	ByPackage  = "package"
+	BySeverity = "severity"
+)
+
+// ErrUnknownFormat is returned for a report that is neither trivy nor
+// govulncheck JSON.
+var ErrUnknownFormat = errors.New("unrecognized scanner report (expected trivy or govulncheck JSON)")
+
+// Finding is one vulnerability in one package.
+type Finding struct {
+	// ID is the vulnerability ID, e.g. "CVE-2023-44487" or "GO-2023-2102".
+	ID string
+
+	// Package is the affected package or Go module.
+	Package string
+
+	// Installed is the version in use.
+	Installed string
+
+	// Fixed is the first version with the fix ("" if none is available).
+	Fixed string
+
+	// Severity is one of the severity constants.
+	Severity string
+
+	// Title summarizes the vulnerability.
+	Title string
+
+	// URL links to the advisory.
+	URL string
+
+	// Reproduction shows where the vulnerability is reached: the scanned
+	// target for trivy, the call stack for govulncheck.
+	Reproduction string
+}
+
+// Parse parses a trivy (`trivy ... --format json`) or govulncheck
+// (`govulncheck -json`) report.
+func Parse(data []byte) ([]Finding, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.Contains(trimmed, []byte(`"Results"`)) {
+		return ParseTrivy(trimmed)
+	}
+	if bytes.Contains(trimmed, []byte(`"osv"`)) || bytes.Contains(trimmed, []byte(`"finding"`)) {
+		return ParseGovulncheck(trimmed)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ParseTrivy parses a trivy JSON report.
+func ParseTrivy(data []byte) ([]Finding, error) {
+	var report struct {
+		Results []struct {
+			Target          string
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+				Title            string
+				PrimaryURL       string
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing trivy report: %w", err)
+	}
+
+	var findings []Finding
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			findings = append(findings, Finding{
+				ID:           v.VulnerabilityID,
+				Package:      v.PkgName,
+				Installed:    v.InstalledVersion,
+				Fixed:        v.FixedVersion,
+				Severity:     normalizeSeverity(v.Severity),
+				Title:        v.Title,
+				URL:          v.PrimaryURL,
+				Reproduction: "Found in " + r.Target,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// govulncheckFrame is a stack frame of a govulncheck finding's trace.
+type govulncheckFrame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+	Position *struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+	} `json:"position"`
+}
+
+// ParseGovulncheck parses the JSON message stream of `govulncheck -json`.
+// govulncheck reports no severity: findings whose vulnerable code is called
+// are high, those that are only imported or required are low. Each
+// vulnerability is reported once per module, with the first call stack.
+func ParseGovulncheck(data []byte) ([]Finding, error) {
+	osvs := make(map[string]struct {
+		Summary string
+		URL     string
+	})
+	var findings []Finding
+	index := make(map[string]int)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var msg struct {
+			OSV *struct {
+				ID               string `json:"id"`
+				Summary          string `json:"summary"`
+				DatabaseSpecific struct {
+					URL string `json:"url"`
+				} `json:"database_specific"`
+			} `json:"osv"`
+			Finding *struct {
+				OSV          string             `json:"osv"`
+				FixedVersion string             `json:"fixed_version"`
+				Trace        []govulncheckFrame `json:"trace"`
+			} `json:"finding"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing govulncheck report: %w", err)
+		}
+
+		if msg.OSV != nil {
+			osvs[msg.OSV.ID] = struct {
+				Summary string
+				URL     string
+			}{msg.OSV.Summary, msg.OSV.DatabaseSpecific.URL}
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+
+		f := msg.Finding
+		vulnerable := f.Trace[0]
+		severity := Low
+		if vulnerable.Function != "" {
+			severity = High
+		}
+		key := f.OSV + " " + vulnerable.Module
+		if i, ok := index[key]; ok {
+			// A called symbol outranks an earlier import-level finding
+			if severity == High && findings[i].Severity != High {
+				findings[i].Severity = High
+				findings[i].Reproduction = goTrace(f.Trace)
+			}
+			continue
+		}
+		index[key] = len(findings)
+		findings = append(findings, Finding{
+			ID:           f.OSV,
+			Package:      vulnerable.Module,
+			Installed:    vulnerable.Version,
+			Fixed:        f.FixedVersion,
+			Severity:     severity,
+			Reproduction: goTrace(f.Trace),
+		})
+	}
+
+	for i := range findings {
+		osv := osvs[findings[i].ID]
+		findings[i].Title = osv.Summary
+		findings[i].URL = osv.URL
+		if findings[i].URL == "" {
+			findings[i].URL = "https://pkg.go.dev/vuln/" + findings[i].ID
+		}
+	}
+	return findings, nil
+}
+
+// goTrace formats a govulncheck trace from the caller in this module down to
+// the vulnerable symbol.
+func goTrace(trace []govulncheckFrame) string {
+	if trace[0].Function == "" {
+		if trace[0].Package != "" {
+			return "Package " + trace[0].Package + " is imported"
+		}
+		return "Module " + trace[0].Module + " is required"
+	}
+
+	lines := make([]string, 0, len(trace))
+	for i := len(trace) - 1; i >= 0; i-- {
+		fr := trace[i]
+		name := fr.Function
+		if fr.Receiver != "" {
+			name = fr.Receiver + "." + name
+		}
+		line := fr.Package + "." + name
+		if fr.Position != nil && fr.Position.Filename != "" {
+			line += fmt.Sprintf(" (%s:%d)", fr.Position.Filename, fr.Position.Line)
+		}
+		lines = append(lines, line)
+	}
+	return "Called via " + strings.Join(lines, " → ")
+}
+
+// Bucket is the findings fixed by one plan.
+type Bucket struct {
+	// Name is the package, or the severity when grouping by severity.
+	Name string
+
+	// Severity is the most severe finding's severity.
+	Severity string
+
+	// Findings are the bucket's findings, most severe first.
+	Findings []Finding
+}
+
+// Group buckets findings by package or by severity (see ByPackage and
+// BySeverity), most severe bucket first. Duplicate findings, such as the
+// same CVE in two scanned targets, are merged.
+func Group(findings []Finding, by string) ([]Bucket, error) {
+	if by != ByPackage && by != BySeverity {
+		return nil, fmt.Errorf("unknown grouping %q (want %s or %s)", by, ByPackage, BySeverity)
+	}
+
+	var buckets []*Bucket
+	byName := make(map[string]*Bucket)
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		key := f.ID + " " + f.Package + " " + f.Installed
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		name := f.Package
+		if by == BySeverity {
+			name = f.Severity
+		}
+		b, ok := byName[name]
+		if !ok {
+			b = &Bucket{Name: name, Severity: f.Severity}
+			byName[name] = b
+			buckets = append(buckets, b)
+		}
+		if rank(f.Severity) < rank(b.Severity) {
+			b.Severity = f.Severity
+		}
+		b.Findings = append(b.Findings, f)
+	}
+
+	result := make([]Bucket, len(buckets))
+	for i, b := range buckets {
+		sort.SliceStable(b.Findings, func(i, j int) bool {
+			return rank(b.Findings[i].Severity) < rank(b.Findings[j].Severity)
+		})
+		result[i] = *b
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if rank(result[i].Severity) != rank(result[j].Severity) {
+			return rank(result[i].Severity) < rank(result[j].Severity)
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// PlanOptions returns the options of the pending plan fixing the bucket.
+// The title starts with a priority (P1 for critical ... P5 for unknown) so
+// that the queue, which runs plans by name, fixes the most severe first.
+// Each affected package gets a task; the Context lists every finding with
+// its advisory, reproduction, and remediation.
+func PlanOptions(b Bucket, source string) plan.ScaffoldOptions {
+	priority := rank(b.Severity) + 1
+	title := fmt.Sprintf("P%d: Fix %s vulnerabilities in %s", priority, b.Severity, b.Name)
+	if b.Name == b.Severity {
+		title = fmt.Sprintf("P%d: Fix %s vulnerabilities", priority, b.Severity)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "A vulnerability scan found %d %s in the dependencies below. Apply the remediation, confirm the vulnerable code is no longer reachable, and keep the tests passing.\n", len(b.Findings), plural(len(b.Findings), "vulnerability", "vulnerabilities"))
+
+	var tasks []string
+	var packages []string
+	byPackage := make(map[string][]Finding)
+	for _, f := range b.Findings {
+		if _, ok := byPackage[f.Package]; !ok {
+			packages = append(packages, f.Package)
+		}
+		byPackage[f.Package] = append(byPackage[f.Package], f)
+	}
+	for _, pkg := range packages {
+		pkgFindings := byPackage[pkg]
+		ids := make([]string, len(pkgFindings))
+		for i, f := range pkgFindings {
+			ids[i] = f.ID
+		}
+		if fixed := highestFix(pkgFindings); fixed != "" {
+			tasks = append(tasks, fmt.Sprintf("Upgrade %s to %s (%s)", pkg, fixed, strings.Join(ids, ", ")))
+		} else {
+			tasks = append(tasks, fmt.Sprintf("Mitigate %s (%s, no fix released)", pkg, strings.Join(ids, ", ")))
+		}
+
+		fmt.Fprintf(&body, "\n**%s %s**\n", pkg, pkgFindings[0].Installed)
+		for _, f := range pkgFindings {
+			fmt.Fprintf(&body, "- [%s](%s) (%s)", f.ID, f.URL, f.Severity)
+			if f.Title != "" {
+				fmt.Fprintf(&body, ": %s", f.Title)
+			}
+			body.WriteString("\n")
+			if f.Reproduction != "" {
+				fmt.Fprintf(&body, "  - Reproduction: %s\n", f.Reproduction)
+			}
+			if f.Fixed != "" {
+				fmt.Fprintf(&body, "  - Remediation: upgrade to %s\n", f.Fixed)
+			} else {
+				body.WriteString("  - Remediation: no fixed version yet; avoid the vulnerable code path or replace the dependency\n")
+			}
+		}
+	}
+
+	return plan.ScaffoldOptions{
+		Title:  title,
+		Body:   body.String(),
+		Source: source,
+		Tasks:  tasks,
+		Labels: []string{"security", b.Severity},
+	}
+}
+
+// highestFix returns the fixed version that fixes all findings, the
+// highest of theirs, or "" if one of them has no fix. Trivy may list several
+// fixed versions separated by commas; the last is used.
+func highestFix(findings []Finding) string {
+	var highest string
+	for _, f := range findings {
+		if f.Fixed == "" {
+			return ""
+		}
+		fixed := strings.TrimSpace(f.Fixed[strings.LastIndex(f.Fixed, ",")+1:])
+		if highest == "" || compareVersions(fixed, highest) > 0 {
+			highest = fixed
+		}
+	}
+	return highest
+}
+
+// compareVersions compares dotted versions numerically, ignoring a "v"
+// prefix; non-numeric parts compare as strings.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		var nx, ny int
+		_, errX := fmt.Sscanf(x, "%d", &nx)
+		_, errY := fmt.Sscanf(y, "%d", &ny)
+		switch {
+		case errX == nil && errY == nil && nx != ny:
+			if nx < ny {
+				return -1
+			}
+			return 1
+		case (errX != nil || errY != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// normalizeSeverity maps a scanner severity to a severity constant.
+func normalizeSeverity(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, known := range severityOrder {
+		if s == known {
+			return s
+		}
+	}
+	if s == "moderate" {
+		return Medium
+	}
+	return Unknown
+}
+
+// rank returns the position of severity in severityOrder.
+func rank(severity string) int {
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severityOrder) - 1
+}
+
+// plural returns singular for 1 and plural otherwise.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,145 @@
+package vuln
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse_Trivy(t *testing.T) {
+	findings, err := Parse(readTestdata(t, "trivy.json"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(findings) != 4 {
+		t.Fatalf("Parse() = %d findings, want 4", len(findings))
+	}
+	f := findings[1]
+	if f.ID != "CVE-2024-0002" || f.Package != "lodash" || f.Severity != Critical || f.Reproduction != "Found in package-lock.json" {
+		t.Errorf("findings[1] = %+v", f)
+	}
+}
+
+func TestParse_Govulncheck(t *testing.T) {
+	findings, err := Parse(readTestdata(t, "govulncheck.json"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Parse() = %+v, want one finding per vulnerability", findings)
+	}
+
+	// The called symbol replaces the import-level finding
+	f := findings[0]
+	if f.ID != "GO-2023-2102" || f.Package != "golang.org/x/net" || f.Installed != "v0.15.0" || f.Fixed != "v0.17.0" || f.Severity != High {
+		t.Errorf("findings[0] = %+v", f)
+	}
+	if f.Reproduction != "Called via github.com/acme/app/server.Run (server/server.go:42) → golang.org/x/net/http2.*Server.ServeConn" {
+		t.Errorf("Reproduction = %q", f.Reproduction)
+	}
+	if f.Title != "HTTP/2 rapid reset can cause excessive work in net/http" {
+		t.Errorf("Title = %q", f.Title)
+	}
+
+	f = findings[1]
+	if f.Severity != Low || f.Reproduction != "Package golang.org/x/net/http2 is imported" || f.URL != "https://pkg.go.dev/vuln/GO-2024-2687" {
+		t.Errorf("findings[1] = %+v", f)
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	if _, err := Parse([]byte(`{"issues": []}`)); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Parse() error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	findings, _ := ParseTrivy(readTestdata(t, "trivy.json"))
+
+	buckets, err := Group(findings, ByPackage)
+	if err != nil {
+		t.Fatalf("Group() error = %v", err)
+	}
+	if len(buckets) != 2 || buckets[0].Name != "lodash" || buckets[0].Severity != Critical || buckets[1].Name != "minimist" {
+		t.Fatalf("Group(package) = %+v", buckets)
+	}
+	// The duplicate CVE from the second target is merged; critical comes first
+	if got := buckets[0].Findings; len(got) != 2 || got[0].ID != "CVE-2024-0002" {
+		t.Errorf("lodash findings = %+v", got)
+	}
+
+	buckets, _ = Group(findings, BySeverity)
+	var names []string
+	for _, b := range buckets {
+		names = append(names, b.Name)
+	}
+	if strings.Join(names, ",") != "critical,high,medium" {
+		t.Errorf("Group(severity) = %v", names)
+	}
+
+	if _, err := Group(findings, "team"); err == nil {
+		t.Error("Group() should reject an unknown grouping")
+	}
+}
+
+func TestPlanOptions(t *testing.T) {
+	findings, _ := ParseTrivy(readTestdata(t, "trivy.json"))
+	buckets, _ := Group(findings, ByPackage)
+
+	opts := PlanOptions(buckets[0], "ralph security-plan --input trivy.json")
+	if opts.Title != "P1: Fix critical vulnerabilities in lodash" {
+		t.Errorf("Title = %q", opts.Title)
+	}
+	if len(opts.Tasks) != 1 || opts.Tasks[0] != "Upgrade lodash to 4.17.22 (CVE-2024-0002, CVE-2024-0001)" {
+		t.Errorf("Tasks = %v", opts.Tasks)
+	}
+	for _, want := range []string{
+		"- [CVE-2024-0002](https://avd.aquasec.com/nvd/cve-2024-0002) (critical): Command injection",
+		"  - Reproduction: Found in package-lock.json",
+		"  - Remediation: upgrade to 4.17.21",
+	} {
+		if !strings.Contains(opts.Body, want) {
+			t.Errorf("Body missing %q:\n%s", want, opts.Body)
+		}
+	}
+	if strings.Join(opts.Labels, ",") != "security,critical" {
+		t.Errorf("Labels = %v", opts.Labels)
+	}
+
+	opts = PlanOptions(buckets[1], "")
+	if len(opts.Tasks) != 1 || opts.Tasks[0] != "Mitigate minimist (CVE-2024-0003, no fix released)" {
+		t.Errorf("Tasks without a fix = %v", opts.Tasks)
+	}
+
+	severity, _ := Group(findings, BySeverity)
+	if got := PlanOptions(severity[2], "").Title; got != "P3: Fix medium vulnerabilities" {
+		t.Errorf("severity bucket Title = %q", got)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.17.0", "v0.9.0", 1},
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.1", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
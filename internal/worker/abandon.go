@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// AbandonOptions configures AbandonPlan.
+type AbandonOptions struct {
+	// Queue is the plan queue; the plan is moved to its abandoned/ directory.
+	Queue *plan.Queue
+
+	// WorktreeManager removes the plan's worktree (optional).
+	WorktreeManager *worktree.WorktreeManager
+
+	// Git is the main repository, used to delete the branch when there is
+	// no worktree (optional).
+	Git git.Git
+
+	// ScratchRoot holds the plan's scratch directory, which is removed
+	// (optional; see worktree.ScratchRoot).
+	ScratchRoot string
+
+	// Events records the plan_abandoned event (optional).
+	Events *events.Log
+
+	// Control clears any pending abandon request once done (optional).
+	Control *control.Store
+
+	// Reason is why the plan is being abandoned.
+	Reason string
+
+	// By records who abandoned the plan.
+	By string
+
+	// DeleteBranch also deletes the plan's feature branch.
+	DeleteBranch bool
+}
+
+// AbandonPlan gives up on a plan: removes its worktree and scratch directory
+// (and optionally its branch), records the reason in the progress file and
+// events log, and moves the plan to abandoned/. Worktree, scratch, and branch
+// failures are logged but don't stop the plan from being archived.
+func AbandonPlan(p *plan.Plan, opts AbandonOptions) error {
+	removed := false
+	if opts.WorktreeManager != nil {
+		err := opts.WorktreeManager.Remove(p, opts.DeleteBranch)
+		switch {
+		case err == nil:
+			removed = true
+		case errors.Is(err, worktree.ErrWorktreeNotFound):
+		default:
+			log.Warn("Failed to remove worktree: %v", err)
+		}
+	}
+
+	// Remove only deletes the branch alongside a worktree
+	if opts.DeleteBranch && !removed && opts.Git != nil && p.Branch != "" {
+		if err := opts.Git.DeleteBranch(p.Branch, true); err != nil && !errors.Is(err, git.ErrBranchNotFound) {
+			log.Warn("Failed to delete branch %s: %v", p.Branch, err)
+		}
+	}
+
+	if opts.ScratchRoot != "" {
+		if err := worktree.RemoveScratch(opts.ScratchRoot, p); err != nil {
+			log.Warn("%v", err)
+		}
+	}
+
+	if err := plan.AppendAbandoned(p, opts.Reason, opts.By, time.Now()); err != nil {
+		log.Warn("Failed to record abandon reason in progress file: %v", err)
+	}
+
+	if err := opts.Queue.Abandon(p); err != nil {
+		return fmt.Errorf("archiving abandoned plan: %w", err)
+	}
+
+	if opts.Events != nil {
+		if err := opts.Events.Append(events.Event{Type: events.TypePlanAbandoned, Plan: p.Name, Message: opts.Reason}); err != nil {
+			log.Debug("Failed to record %s event: %v", events.TypePlanAbandoned, err)
+		}
+	}
+
+	if opts.Control != nil {
+		if err := opts.Control.ClearAbandon(p.Name); err != nil {
+			log.Debug("Failed to clear abandon request: %v", err)
+		}
+	}
+
+	log.Success("Plan abandoned: %s", p.Name)
+	return nil
+}
+
+// abandonPlan abandons a plan using its pending control-plane request.
+func (w *Worker) abandonPlan(p *plan.Plan, req *control.AbandonRequest) error {
+	log.Warn("Abandoning plan %s: %s", p.Name, req.Reason)
+	w.planFinished(context.Background(), p, runner.OutcomeAbandoned, req.Reason)
+
+	err := AbandonPlan(p, AbandonOptions{
+		Queue:           w.queue,
+		WorktreeManager: w.worktreeManager,
+		Git:             w.git,
+		ScratchRoot:     w.scratchRoot(),
+		Events:          w.events,
+		Control:         w.control,
+		Reason:          req.Reason,
+		By:              req.By,
+		DeleteBranch:    req.DeleteBranch,
+	})
+	if err != nil {
+		return err
+	}
+	w.mirrorState(p, "", "abandoned")
+	w.refreshHome()
+	return nil
+}
+
+// abandonRequest returns the plan's pending abandon request, or nil.
+func (w *Worker) abandonRequest(p *plan.Plan) *control.AbandonRequest {
+	if w.control == nil {
+		return nil
+	}
+	return w.control.AbandonRequest(p.Name)
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// DefaultApprovalTimeout is how long to wait for approval when
+// completion.approval_timeout is not set.
+const DefaultApprovalTimeout = 24 * time.Hour
+
+// ErrApprovalRejected is returned when a human rejects a completed plan.
+var ErrApprovalRejected = errors.New("approval rejected")
+
+// ErrApprovalTimeout is returned when nobody approves a completed plan in time.
+var ErrApprovalTimeout = errors.New("approval timed out")
+
+// requireApproval returns true if completion.require_approval is set.
+func (w *Worker) requireApproval() bool {
+	return w.config != nil && w.config.Completion.RequireApproval && w.control != nil
+}
+
+// approvalTimeout returns the configured time to wait for approval.
+func (w *Worker) approvalTimeout() time.Duration {
+	if w.config != nil && w.config.Completion.ApprovalTimeout != "" {
+		if d, err := time.ParseDuration(w.config.Completion.ApprovalTimeout); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultApprovalTimeout
+}
+
+// awaitApproval asks for approval of a completed plan and waits for the
+// decision, checking the control file every poll interval. A request left
+// pending by an earlier run is resumed without notifying again. Returns nil
+// once approved (or if approval isn't required), ErrApprovalRejected or
+// ErrApprovalTimeout otherwise, or the context's error on shutdown.
+func (w *Worker) awaitApproval(ctx context.Context, p *plan.Plan, c notify.Completion) error {
+	if !w.requireApproval() {
+		return nil
+	}
+
+	timeout := w.approvalTimeout()
+	approval := w.control.Approval(p.Name)
+	if approval == nil || approval.Decision == control.ApprovalRejected {
+		if err := w.control.RequestApproval(p.Name); err != nil {
+			return fmt.Errorf("requesting approval: %w", err)
+		}
+		approval = w.control.Approval(p.Name)
+		w.recordEvent(events.Event{Type: events.TypeApprovalRequested, Plan: p.Name})
+		if err := w.notifier.ApprovalRequest(p, c, timeout); err != nil {
+			log.Debug("Failed to send approval request: %v", err)
+		}
+	}
+	log.Info("Waiting up to %s for approval of %s: ralph approve %s (or ralph reject %s)", timeout, p.Name, p.Name, p.Name)
+
+	var deadline time.Time
+	if approval != nil {
+		deadline = approval.RequestedAt.Add(timeout)
+	} else {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		if approval != nil {
+			switch approval.Decision {
+			case control.ApprovalApproved:
+				log.Success("Plan %s approved by %s", p.Name, approval.By)
+				w.recordEvent(events.Event{Type: events.TypePlanApproved, Plan: p.Name, Message: approval.By})
+				w.clearApproval(p)
+				return nil
+			case control.ApprovalRejected:
+				w.clearApproval(p)
+				if approval.Reason != "" {
+					return fmt.Errorf("%w by %s: %s", ErrApprovalRejected, approval.By, approval.Reason)
+				}
+				return fmt.Errorf("%w by %s", ErrApprovalRejected, approval.By)
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			w.clearApproval(p)
+			return fmt.Errorf("%w after %s", ErrApprovalTimeout, timeout)
+		}
+		wait := w.pollInterval
+		if wait > remaining {
+			wait = remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		approval = w.control.Approval(p.Name)
+	}
+}
+
+// clearApproval removes a plan's decided approval request.
+func (w *Worker) clearApproval(p *plan.Plan) {
+	if err := w.control.ClearApproval(p.Name); err != nil {
+		log.Debug("Failed to clear approval: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// setupApprovalWorker creates a worker that requires approval with a short poll interval.
+func setupApprovalWorker(t *testing.T, timeout string) (*Worker, *MockNotifier) {
+	t.Helper()
+
+	cfg := config.Defaults()
+	cfg.Completion.RequireApproval = true
+	cfg.Completion.ApprovalTimeout = timeout
+	notifier := &MockNotifier{}
+	dir := t.TempDir()
+	w := NewWorker(WorkerConfig{
+		Config:       cfg,
+		Notifier:     notifier,
+		Control:      control.NewStore(filepath.Join(dir, "control.json")),
+		Events:       events.NewLog(events.Path(dir)),
+		PollInterval: 10 * time.Millisecond,
+	})
+	return w, notifier
+}
+
+func TestWorker_AwaitApproval_NotRequired(t *testing.T) {
+	w := NewWorker(WorkerConfig{Config: config.Defaults(), Control: control.NewStore(filepath.Join(t.TempDir(), "control.json"))})
+	if err := w.awaitApproval(context.Background(), &plan.Plan{Name: "alpha"}, notify.Completion{}); err != nil {
+		t.Errorf("awaitApproval() error = %v, want nil", err)
+	}
+	if w.control.Approval("alpha") != nil {
+		t.Error("no approval should be requested")
+	}
+}
+
+func TestWorker_AwaitApproval_Approved(t *testing.T) {
+	w, notifier := setupApprovalWorker(t, "1m")
+	p := &plan.Plan{Name: "alpha"}
+
+	go func() {
+		for w.control.Decide("alpha", true, "jane", "") != nil {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	if err := w.awaitApproval(context.Background(), p, notify.Completion{}); err != nil {
+		t.Fatalf("awaitApproval() error = %v", err)
+	}
+	if notifier.ApprovalCalls != 1 {
+		t.Errorf("ApprovalCalls = %d, want 1", notifier.ApprovalCalls)
+	}
+	if w.control.Approval("alpha") != nil {
+		t.Error("approval should be cleared once decided")
+	}
+	if ev, _ := w.events.Last(events.TypePlanApproved); ev == nil || ev.Message != "jane" {
+		t.Errorf("plan_approved event = %+v", ev)
+	}
+}
+
+func TestWorker_AwaitApproval_Rejected(t *testing.T) {
+	w, _ := setupApprovalWorker(t, "1m")
+	go func() {
+		for w.control.Decide("alpha", false, "jane", "touches billing") != nil {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	err := w.awaitApproval(context.Background(), &plan.Plan{Name: "alpha"}, notify.Completion{})
+	if !errors.Is(err, ErrApprovalRejected) || err.Error() != "approval rejected by jane: touches billing" {
+		t.Errorf("awaitApproval() error = %v, want rejection", err)
+	}
+}
+
+func TestWorker_AwaitApproval_Timeout(t *testing.T) {
+	w, _ := setupApprovalWorker(t, "50ms")
+	if err := w.awaitApproval(context.Background(), &plan.Plan{Name: "alpha"}, notify.Completion{}); !errors.Is(err, ErrApprovalTimeout) {
+		t.Errorf("awaitApproval() error = %v, want %v", err, ErrApprovalTimeout)
+	}
+}
+
+func TestWorker_AwaitApproval_ResumesPendingRequest(t *testing.T) {
+	w, notifier := setupApprovalWorker(t, "1m")
+	w.control.RequestApproval("alpha")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.awaitApproval(ctx, &plan.Plan{Name: "alpha"}, notify.Completion{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("awaitApproval() error = %v, want the context error", err)
+	}
+	if notifier.ApprovalCalls != 0 {
+		t.Errorf("ApprovalCalls = %d, a pending request should not notify again", notifier.ApprovalCalls)
+	}
+	if a := w.control.Approval("alpha"); a == nil || a.Decision != control.ApprovalPending {
+		t.Errorf("Approval() = %+v, want still pending after shutdown", a)
+	}
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersPaths are where GitHub looks for a CODEOWNERS file, in order.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeOwnersRule is a CODEOWNERS line: a path pattern and its owners.
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// loadCodeOwners reads the repository's CODEOWNERS file. Returns nil if
+// there is none.
+func loadCodeOwners(repoDir string) []codeOwnersRule {
+	for _, path := range codeOwnersPaths {
+		data, err := os.ReadFile(filepath.Join(repoDir, path))
+		if err == nil {
+			return parseCodeOwners(string(data))
+		}
+	}
+	return nil
+}
+
+// parseCodeOwners parses CODEOWNERS content. Comments, blank lines, and
+// patterns that can't be parsed are skipped.
+func parseCodeOwners(content string) []codeOwnersRule {
+	var rules []codeOwnersRule
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeOwnersRule{pattern: re, owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeOwnersPattern compiles a CODEOWNERS path pattern, which follows
+// gitignore rules: a pattern with a leading or inner slash is relative to
+// the repository root, otherwise it matches at any depth; a trailing slash
+// (or a last segment without wildcards) also matches everything below; "*"
+// and "?" don't cross slashes, "**" does.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	prefix := "^(.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	suffix := "$"
+	last := pattern[strings.LastIndex(pattern, "/")+1:]
+	if dir {
+		suffix = "/.*$"
+	} else if !strings.ContainsAny(last, "*?") {
+		suffix = "(/.*)?$"
+	}
+	return regexp.Compile(prefix + sb.String() + suffix)
+}
+
+// ownersOf returns the owners of path: those of the last rule matching it.
+func ownersOf(rules []codeOwnersRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].pattern.MatchString(path) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnerReviewers returns the CODEOWNERS owners of files in repoDir as
+// reviewers for gh ("alice", "org/team"), in order of first appearance.
+// Owners given by email are left out, since gh can't request them.
+func codeOwnerReviewers(repoDir string, files []string) []string {
+	rules := loadCodeOwners(repoDir)
+	if len(rules) == 0 {
+		return nil
+	}
+	var reviewers []string
+	for _, file := range files {
+		for _, owner := range ownersOf(rules, file) {
+			if strings.HasPrefix(owner, "@") {
+				reviewers = appendUnique(reviewers, strings.TrimPrefix(owner, "@"))
+			}
+		}
+	}
+	return reviewers
+}
+
+// appendUnique appends the items of add to list that aren't in it yet,
+// compared case-insensitively as GitHub logins are.
+func appendUnique(list []string, add ...string) []string {
+	for _, item := range add {
+		found := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "any/file.go", true},
+		{"*.js", "web/src/app.js", true},
+		{"*.js", "web/src/app.ts", false},
+		{"/build/logs/", "build/logs/out.txt", true},
+		{"/build/logs/", "src/build/logs/out.txt", false},
+		{"docs/*", "docs/getting-started.md", true},
+		{"docs/*", "docs/build-app/troubleshooting.md", false},
+		{"apps/", "apps/web/main.go", true},
+		{"apps/", "src/apps/web/main.go", true},
+		{"apps/", "apps", false},
+		{"**/logs", "deep/down/logs/x.log", true},
+		{"/scripts", "scripts/deploy.sh", true},
+		{"internal/auth", "internal/auth/login.go", true},
+		{"internal/auth", "x/internal/auth/login.go", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file12.txt", false},
+	}
+	for _, tt := range tests {
+		re, err := codeOwnersPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("codeOwnersPattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	rules := parseCodeOwners(`# Default owners
+*       @org/core
+
+*.go    @gopher  # inline comment
+/docs/  @writer docs@example.com
+/docs/internal/
+`)
+	tests := map[string]string{
+		"README.md":          "@org/core",
+		"cmd/main.go":        "@gopher",
+		"docs/intro.md":      "@writer docs@example.com",
+		"docs/internal/x.md": "",
+	}
+	for path, want := range tests {
+		if got := strings.Join(ownersOf(rules, path), " "); got != want {
+			t.Errorf("ownersOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCodeOwnerReviewers_NoFile(t *testing.T) {
+	if got := codeOwnerReviewers(t.TempDir(), []string{"main.go"}); got != nil {
+		t.Errorf("codeOwnerReviewers() without CODEOWNERS = %v, want nil", got)
+	}
+}
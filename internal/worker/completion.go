@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/arvesolland/ralph/internal/config"
 	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
@@ -36,15 +37,113 @@ var (
 	ErrMergeFailed = errors.New("failed to merge branch")
 )
 
-// prURLRegex matches the PR URL from gh pr create output.
+// prURLRegex matches the PR URL from gh pr create output, on github.com or
+// a GitHub Enterprise host.
 // gh outputs: https://github.com/owner/repo/pull/123
-var prURLRegex = regexp.MustCompile(`https://github\.com/[^/]+/[^/]+/pull/\d+`)
+var prURLRegex = regexp.MustCompile(`https://[^/\s]+/[^/\s]+/[^/\s]+/pull/\d+`)
+
+// maxPRBodyChanges caps the files listed in the PR body's Changes section.
+const maxPRBodyChanges = 50
+
+// PROptions are the reviewers, assignees, and labels a pull request is
+// created with, whether it's a draft, and the repository's pull request
+// template its body fills in (empty = the standard body). Remote and Base,
+// if known, are origin and the branch the pull request targets, for the
+// link to open one by hand when gh can't.
+type PROptions struct {
+	Reviewers []string
+	Assignees []string
+	Labels    []string
+	Draft     bool
+	Template  string
+	Remote    *git.RemoteInfo
+	Base      string
+}
+
+// PROptionsFor returns the pull request options for p from cfg. The plan's
+// **Reviewers:**, **Assignees:**, **PR Labels:** (comma-separated), and
+// **Draft:** (yes or no) header lines replace the configured values. With
+// cfg.CodeOwners, the CODEOWNERS owners in repoDir of the files the plan
+// changed are added to the reviewers. The pull request template is read
+// from repoDir unless cfg.IgnoreTemplate is set.
+func PROptionsFor(p *plan.Plan, cfg config.PRConfig, repoDir string) PROptions {
+	opts := PROptions{
+		Reviewers: cfg.Reviewers,
+		Assignees: cfg.Assignees,
+		Labels:    cfg.Labels,
+		Draft:     cfg.Draft,
+	}
+	if !cfg.IgnoreTemplate {
+		opts.Template = findPRTemplate(repoDir)
+	}
+
+	doc := plan.ParseDocument(p.Content)
+	if v, ok := doc.Field("Reviewers"); ok {
+		opts.Reviewers = splitList(v)
+	}
+	if v, ok := doc.Field("Assignees"); ok {
+		opts.Assignees = splitList(v)
+	}
+	if v, ok := doc.Field("PR Labels"); ok {
+		opts.Labels = splitList(v)
+	}
+	if v, ok := doc.Field("Draft"); ok {
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "true":
+			opts.Draft = true
+		case "no", "false":
+			opts.Draft = false
+		}
+	}
+
+	if cfg.CodeOwners {
+		if changes, err := plan.LoadChanges(p); err == nil {
+			files := make([]string, len(changes.Files))
+			for i, f := range changes.Files {
+				files[i] = f.Path
+			}
+			opts.Reviewers = appendUnique(append([]string(nil), opts.Reviewers...), codeOwnerReviewers(repoDir, files)...)
+		}
+	}
+	return opts
+}
+
+// args returns the gh pr create flags for the options.
+func (o PROptions) args() []string {
+	var args []string
+	if len(o.Reviewers) > 0 {
+		args = append(args, "--reviewer", strings.Join(o.Reviewers, ","))
+	}
+	if len(o.Assignees) > 0 {
+		args = append(args, "--assignee", strings.Join(o.Assignees, ","))
+	}
+	if len(o.Labels) > 0 {
+		args = append(args, "--label", strings.Join(o.Labels, ","))
+	}
+	if o.Draft {
+		args = append(args, "--draft")
+	}
+	return args
+}
+
+// splitList splits a comma-separated header value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
 
 // CompletePR handles PR mode completion:
 // 1. Push branch to origin
-// 2. Create PR using gh CLI
-// Returns the PR URL on success.
-func CompletePR(p *plan.Plan, wt *worktree.Worktree, g git.Git) (string, error) {
+// 2. Create PR using gh CLI, with the reviewers, assignees, and labels in opts
+// Returns the PR URL on success. Without gh, or with origin on GitLab or
+// Bitbucket, the branch is pushed and a link to open the pull request is
+// logged instead.
+func CompletePR(p *plan.Plan, wt *worktree.Worktree, g git.Git, opts PROptions) (string, error) {
 	// Step 1: Push the branch to origin
 	log.Info("Pushing branch %s to origin...", p.Branch)
 	if err := pushBranch(g, p.Branch); err != nil {
@@ -52,13 +151,19 @@ func CompletePR(p *plan.Plan, wt *worktree.Worktree, g git.Git) (string, error)
 	}
 	log.Success("Branch pushed successfully")
 
-	// Step 2: Create PR using gh CLI
+	// Step 2: Create PR using gh CLI, which only knows GitHub
+	if opts.Remote != nil && opts.Remote.Provider != git.ProviderGitHub {
+		log.Warn("origin is on %s, which gh can't open pull requests on", opts.Remote.Host)
+		logManualPRInstructions(p, opts)
+		return "", nil
+	}
 	log.Info("Creating PR...")
-	prURL, err := createPR(p, g.WorkDir())
+	prURL, err := createPR(p, g.WorkDir(), opts)
 	if err != nil {
 		if errors.Is(err, ErrGHNotInstalled) {
 			// Log manual instructions instead of failing
-			logManualPRInstructions(p)
+			log.Warn("GitHub CLI (gh) not installed")
+			logManualPRInstructions(p, opts)
 			return "", nil
 		}
 		return "", fmt.Errorf("%w: %v", ErrPRCreateFailed, err)
@@ -68,6 +173,30 @@ func CompletePR(p *plan.Plan, wt *worktree.Worktree, g git.Git) (string, error)
 	return prURL, nil
 }
 
+// ghPRMerge runs `gh pr merge` with args in dir.
+var ghPRMerge = func(dir string, args ...string) error {
+	cmd := exec.Command("gh", append([]string{"pr", "merge"}, args...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gh pr merge: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// EnableAutoMerge turns on GitHub auto-merge for the pull request at prURL,
+// so it merges with strategy (see config.AutoMerge*) once its checks and
+// reviews pass. With config.AutoMergeQueue the pull request is added to the
+// base branch's merge queue instead, which requires one.
+func EnableAutoMerge(prURL, strategy, workDir string) error {
+	args := []string{prURL}
+	if strategy != config.AutoMergeQueue {
+		args = append(args, "--auto", "--"+strategy)
+	}
+	return ghPRMerge(workDir, args...)
+}
+
 // pushBranch pushes the branch to origin with upstream tracking.
 func pushBranch(g git.Git, branch string) error {
 	return g.PushWithUpstream("origin", branch)
@@ -75,7 +204,7 @@ func pushBranch(g git.Git, branch string) error {
 
 // createPR creates a PR using the gh CLI.
 // Returns the PR URL or an error.
-func createPR(p *plan.Plan, workDir string) (string, error) {
+func createPR(p *plan.Plan, workDir string, opts PROptions) (string, error) {
 	// Check if gh is installed
 	if !isGHInstalled() {
 		return "", ErrGHNotInstalled
@@ -84,12 +213,13 @@ func createPR(p *plan.Plan, workDir string) (string, error) {
 	// Build PR title and body
 	title := p.Name
 	body := buildPRBody(p)
+	if opts.Template != "" {
+		body = fillPRTemplate(opts.Template, p)
+	}
 
 	// Run gh pr create
-	cmd := exec.Command("gh", "pr", "create",
-		"--title", title,
-		"--body", body,
-	)
+	args := append([]string{"pr", "create", "--title", title, "--body", body}, opts.args()...)
+	cmd := exec.Command("gh", args...)
 	cmd.Dir = workDir
 
 	var stdout, stderr bytes.Buffer
@@ -124,23 +254,58 @@ func createPR(p *plan.Plan, workDir string) (string, error) {
 	return prURL, nil
 }
 
+// prFooter ends every PR body.
+const prFooter = "---\n\n🤖 Generated by [Ralph](https://github.com/arvesolland/ralph)\n"
+
 // buildPRBody creates the PR body with standard footer.
 func buildPRBody(p *plan.Plan) string {
 	var sb strings.Builder
 
 	sb.WriteString("## Summary\n\n")
+	sb.WriteString(prSummary(p))
+
+	// Add the changes ledger if one was recorded
+	if changes := prChangeList(p); changes != "" {
+		sb.WriteString("## Changes\n\n")
+		sb.WriteString(changes)
+	}
+
+	sb.WriteString(prFooter)
+
+	return sb.String()
+}
+
+// prSummary returns the plan name and, if it has tasks, the task counts.
+func prSummary(p *plan.Plan) string {
+	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Implements: %s\n\n", p.Name))
 
-	// Add task summary if available
 	totalTasks := plan.CountTotal(p.Tasks)
 	completedTasks := plan.CountComplete(p.Tasks)
 	if totalTasks > 0 {
 		sb.WriteString(fmt.Sprintf("Tasks completed: %d/%d\n\n", completedTasks, totalTasks))
 	}
+	return sb.String()
+}
 
-	sb.WriteString("---\n\n")
-	sb.WriteString("🤖 Generated by [Ralph](https://github.com/arvesolland/ralph)\n")
+// prChangeList returns the plan's changes ledger summary and files, or ""
+// if none was recorded.
+func prChangeList(p *plan.Plan) string {
+	changes, err := plan.LoadChanges(p)
+	if err != nil || len(changes.Files) == 0 {
+		return ""
+	}
 
+	var sb strings.Builder
+	sb.WriteString(changes.Summary() + "\n\n")
+	for i, f := range changes.Files {
+		if i == maxPRBodyChanges {
+			sb.WriteString(fmt.Sprintf("- ...and %d more\n", len(changes.Files)-maxPRBodyChanges))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` (%s)\n", f.Path, f.Change))
+	}
+	sb.WriteString("\n")
 	return sb.String()
 }
 
@@ -150,7 +315,7 @@ func isGHInstalled() bool {
 	return err == nil
 }
 
-// extractPRURL extracts a GitHub PR URL from text.
+// extractPRURL extracts a GitHub or GitHub Enterprise PR URL from text.
 func extractPRURL(text string) string {
 	match := prURLRegex.FindString(text)
 	return match
@@ -171,9 +336,15 @@ func getExistingPRURL(workDir string) (string, error) {
 	return strings.TrimSpace(stdout.String()), nil
 }
 
-// logManualPRInstructions logs instructions for creating PR manually.
-func logManualPRInstructions(p *plan.Plan) {
-	log.Warn("GitHub CLI (gh) not installed. Please create PR manually:")
+// logManualPRInstructions logs instructions for creating PR manually, with
+// a link that opens it if origin is known.
+func logManualPRInstructions(p *plan.Plan, opts PROptions) {
+	if opts.Remote != nil && opts.Base != "" {
+		log.Warn("Please create the pull request for branch %s manually:", p.Branch)
+		log.Info("  %s", opts.Remote.NewPullRequestURL(opts.Base, p.Branch))
+		return
+	}
+	log.Warn("Please create PR manually:")
 	log.Info("  1. Go to your repository on GitHub")
 	log.Info("  2. Create a new pull request for branch: %s", p.Branch)
 	log.Info("  3. Or install gh: https://cli.github.com/")
@@ -183,9 +354,9 @@ func logManualPRInstructions(p *plan.Plan) {
 // 1. Check out base branch in main worktree
 // 2. Merge feature branch with --no-ff
 // 3. Push base branch to origin
-// 4. Delete feature branch (local and remote)
+// 4. Delete feature branch (local, and on origin if deleteRemote is set)
 // The mainGit should be a Git instance for the main worktree (not the feature worktree).
-func CompleteMerge(p *plan.Plan, baseBranch string, mainGit git.Git) error {
+func CompleteMerge(p *plan.Plan, baseBranch string, mainGit git.Git, deleteRemote bool) error {
 	featureBranch := p.Branch
 
 	// Step 1: Checkout base branch in main worktree
@@ -221,14 +392,10 @@ func CompleteMerge(p *plan.Plan, baseBranch string, mainGit git.Git) error {
 		log.Debug("Deleted local branch %s", featureBranch)
 	}
 
-	// Step 5: Delete feature branch (remote)
-	log.Info("Deleting remote branch %s...", featureBranch)
-	if err := mainGit.DeleteRemoteBranch("origin", featureBranch); err != nil {
-		// Log warning but don't fail - the merge was successful
-		// Remote branch might not exist if we never pushed it
-		log.Warn("Failed to delete remote branch %s: %v", featureBranch, err)
-	} else {
-		log.Debug("Deleted remote branch %s", featureBranch)
+	// Step 5: Delete feature branch (remote) - failures are only logged,
+	// the merge was successful
+	if deleteRemote {
+		deleteRemoteBranch(mainGit, featureBranch)
 	}
 
 	log.Success("Merge complete: %s merged into %s", featureBranch, baseBranch)
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/arvesolland/ralph/internal/config"
 	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/worktree"
@@ -108,6 +109,20 @@ func TestBuildPRBody(t *testing.T) {
 		}
 	})
 
+	t.Run("plan with changes ledger", func(t *testing.T) {
+		p := &plan.Plan{Name: "with-changes", Path: filepath.Join(t.TempDir(), "with-changes.md")}
+		plan.RecordChanges(p, 1, []plan.FileChange{
+			{Path: "src/app.go", Change: plan.ChangeCreated},
+			{Path: "README.md", Change: plan.ChangeModified},
+		})
+
+		body := buildPRBody(p)
+
+		if !strings.Contains(body, "## Changes\n\n2 files changed: 1 created, 1 modified\n\n- `README.md` (modified)\n- `src/app.go` (created)\n") {
+			t.Errorf("body should list the changes ledger, got: %s", body)
+		}
+	})
+
 	t.Run("plan with nested tasks", func(t *testing.T) {
 		p := &plan.Plan{
 			Name: "nested-tasks",
@@ -137,6 +152,7 @@ func TestPRURLRegex(t *testing.T) {
 		"https://github.com/owner/repo/pull/1",
 		"https://github.com/a/b/pull/123",
 		"https://github.com/some-org/some-repo/pull/99999",
+		"https://github.example.com/acme/app/pull/7", // GitHub Enterprise
 	}
 
 	for _, url := range validURLs {
@@ -148,8 +164,8 @@ func TestPRURLRegex(t *testing.T) {
 	invalidURLs := []string{
 		"http://github.com/owner/repo/pull/1", // http not https
 		"https://github.com/owner/repo/issues/1",
-		"https://github.com/owner/repo/pull/",    // no number
-		"https://gitlab.com/owner/repo/pull/123", // not github
+		"https://github.com/owner/repo/pull/", // no number
+		"https://github.com/owner/pull/1",     // no repo
 	}
 
 	for _, url := range invalidURLs {
@@ -167,7 +183,9 @@ func TestLogManualPRInstructions(t *testing.T) {
 	}
 
 	// Capture that it runs without error
-	logManualPRInstructions(p)
+	logManualPRInstructions(p, PROptions{})
+	remote, _ := git.ParseRemote("git@gitlab.com:acme/app.git", "")
+	logManualPRInstructions(p, PROptions{Remote: remote, Base: "main"})
 }
 
 // TestCompletePR_Integration tests the full PR completion workflow
@@ -248,7 +266,7 @@ func TestCreatePR_GHNotInstalled(t *testing.T) {
 		Branch: "feat/test-plan",
 	}
 
-	_, err := createPR(p, "/tmp")
+	_, err := createPR(p, "/tmp", PROptions{})
 	if err != ErrGHNotInstalled {
 		t.Errorf("createPR() error = %v, want ErrGHNotInstalled", err)
 	}
@@ -336,7 +354,7 @@ echo "https://github.com/test/repo/pull/123"
 	}
 
 	// Run the PR completion (with our mock gh)
-	prURL, err := CompletePR(p, wt, mockGit)
+	prURL, err := CompletePR(p, wt, mockGit, PROptions{})
 	if err != nil {
 		t.Errorf("CompletePR() error = %v", err)
 	}
@@ -346,6 +364,126 @@ echo "https://github.com/test/repo/pull/123"
 	}
 }
 
+func TestCompletePR_NonGitHubRemote(t *testing.T) {
+	// A gh that would fail the test if it ran
+	binDir := t.TempDir()
+	os.WriteFile(filepath.Join(binDir, "gh"), []byte("#!/bin/sh\necho \"gh ran\" >&2\nexit 1\n"), 0755)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	remote, err := git.ParseRemote("git@gitlab.example.com:acme/app.git", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &plan.Plan{Name: "test-feature", Branch: "feat/test-feature"}
+	mockGit := &mockGitForCompletion{workDir: t.TempDir()}
+	prURL, err := CompletePR(p, &worktree.Worktree{Path: mockGit.workDir}, mockGit, PROptions{Remote: remote, Base: "main"})
+	if err != nil || prURL != "" {
+		t.Errorf("CompletePR() = %q, %v; want no PR and no error", prURL, err)
+	}
+	if mockGit.pushedBranch != "feat/test-feature" {
+		t.Errorf("pushed %q, want the plan branch", mockGit.pushedBranch)
+	}
+}
+
+func TestCreatePR_Options(t *testing.T) {
+	binDir := t.TempDir()
+	argsFile := filepath.Join(binDir, "args.txt")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\necho https://github.com/test/repo/pull/5\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	p := &plan.Plan{Name: "test-feature", Branch: "feat/test-feature"}
+	opts := PROptions{Reviewers: []string{"alice", "org/team"}, Assignees: []string{"@me"}, Labels: []string{"ralph"}, Draft: true}
+	if _, err := createPR(p, t.TempDir(), opts); err != nil {
+		t.Fatalf("createPR() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(argsFile)
+	args := "\n" + string(data)
+	for _, want := range []string{"\n--reviewer\nalice,org/team\n", "\n--assignee\n@me\n", "\n--label\nralph\n", "\n--draft\n"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("gh args missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestPROptionsFor(t *testing.T) {
+	cfg := config.PRConfig{Reviewers: []string{"alice"}, Labels: []string{"ralph"}, Draft: true}
+
+	p := &plan.Plan{Name: "test", Content: "# Plan: Test\n"}
+	opts := PROptionsFor(p, cfg, t.TempDir())
+	if strings.Join(opts.Reviewers, ",") != "alice" || strings.Join(opts.Labels, ",") != "ralph" || !opts.Draft || opts.Assignees != nil {
+		t.Errorf("PROptionsFor() = %+v, want the config", opts)
+	}
+
+	// Plan headers override the config
+	p.Content = "# Plan: Test\n**Reviewers:** bob, org/backend\n**Assignees:** @me\n**PR Labels:**\n**Draft:** no\n"
+	opts = PROptionsFor(p, cfg, t.TempDir())
+	if strings.Join(opts.Reviewers, ",") != "bob,org/backend" || strings.Join(opts.Assignees, ",") != "@me" || opts.Labels != nil || opts.Draft {
+		t.Errorf("PROptionsFor() with headers = %+v", opts)
+	}
+
+	// The repository's pull request template is used unless ignored
+	repoDir := t.TempDir()
+	os.WriteFile(filepath.Join(repoDir, "pull_request_template.md"), []byte("## Description\n"), 0644)
+	if opts := PROptionsFor(p, cfg, repoDir); opts.Template != "## Description\n" {
+		t.Errorf("Template = %q, want the repository's template", opts.Template)
+	}
+	cfg.IgnoreTemplate = true
+	if opts := PROptionsFor(p, cfg, repoDir); opts.Template != "" {
+		t.Errorf("Template with ignore_template = %q, want none", opts.Template)
+	}
+}
+
+func TestPROptionsFor_CodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	planPath := filepath.Join(dir, "test.md")
+	os.WriteFile(planPath, []byte("# Plan: Test\n"), 0644)
+	p, _ := plan.Load(planPath)
+	plan.SaveChanges(p, &plan.Changes{Files: []plan.FileChange{{Path: "internal/auth/login.go"}, {Path: "docs/auth.md"}}})
+
+	repoDir := t.TempDir()
+	os.MkdirAll(filepath.Join(repoDir, ".github"), 0755)
+	os.WriteFile(filepath.Join(repoDir, ".github", "CODEOWNERS"), []byte("* @org/core\n/internal/auth/ @carol @org/security\ndocs/ docs@example.com @Alice\n"), 0644)
+
+	opts := PROptionsFor(p, config.PRConfig{Reviewers: []string{"alice"}, CodeOwners: true}, repoDir)
+	if got := strings.Join(opts.Reviewers, ","); got != "alice,carol,org/security" {
+		t.Errorf("Reviewers = %s, want alice,carol,org/security", got)
+	}
+}
+
+func TestEnableAutoMerge(t *testing.T) {
+	oldMerge := ghPRMerge
+	defer func() { ghPRMerge = oldMerge }()
+	var got []string
+	ghPRMerge = func(dir string, args ...string) error {
+		got = args
+		return nil
+	}
+
+	url := "https://github.com/o/r/pull/7"
+	for strategy, want := range map[string]string{
+		"squash": url + " --auto --squash",
+		"rebase": url + " --auto --rebase",
+		"merge":  url + " --auto --merge",
+		"queue":  url,
+	} {
+		if err := EnableAutoMerge(url, strategy, t.TempDir()); err != nil {
+			t.Fatalf("EnableAutoMerge(%s) error = %v", strategy, err)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("EnableAutoMerge(%s) ran gh pr merge %v, want %s", strategy, got, want)
+		}
+	}
+
+	ghPRMerge = func(dir string, args ...string) error { return errors.New("auto-merge is not allowed") }
+	if err := EnableAutoMerge(url, "squash", t.TempDir()); err == nil {
+		t.Error("EnableAutoMerge() should return gh's error")
+	}
+}
+
 func TestCompletionErrors(t *testing.T) {
 	// Verify error variables are properly defined
 	if ErrGHNotInstalled.Error() != "gh CLI not installed" {
@@ -386,6 +524,7 @@ type mockGitForMerge struct {
 	mergedBranch        string
 	deletedBranch       string
 	deletedRemoteBranch string
+	prunedRemote        string
 }
 
 func (m *mockGitForMerge) Checkout(branch string) error {
@@ -413,6 +552,11 @@ func (m *mockGitForMerge) DeleteRemoteBranch(remote, branch string) error {
 	return m.deleteRemoteError
 }
 
+func (m *mockGitForMerge) PruneRemote(remote string) error {
+	m.prunedRemote = remote
+	return nil
+}
+
 func TestCompleteMerge_Success(t *testing.T) {
 	p := &plan.Plan{
 		Name:   "test-feature",
@@ -420,7 +564,7 @@ func TestCompleteMerge_Success(t *testing.T) {
 	}
 
 	mock := &mockGitForMerge{}
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err != nil {
 		t.Errorf("CompleteMerge() error = %v, want nil", err)
 	}
@@ -440,6 +584,30 @@ func TestCompleteMerge_Success(t *testing.T) {
 	if mock.deletedRemoteBranch != "feat/test-feature" {
 		t.Errorf("should delete remote feature branch, got %q", mock.deletedRemoteBranch)
 	}
+
+	if mock.prunedRemote != "origin" {
+		t.Errorf("should prune origin's deleted branches, got %q", mock.prunedRemote)
+	}
+}
+
+func TestCompleteMerge_KeepsRemoteBranch(t *testing.T) {
+	p := &plan.Plan{
+		Name:   "test-feature",
+		Branch: "feat/test-feature",
+	}
+
+	mock := &mockGitForMerge{}
+	if err := CompleteMerge(p, "main", mock, false); err != nil {
+		t.Errorf("CompleteMerge() error = %v, want nil", err)
+	}
+
+	if mock.deletedBranch != "feat/test-feature" {
+		t.Errorf("should delete local feature branch, got %q", mock.deletedBranch)
+	}
+
+	if mock.deletedRemoteBranch != "" || mock.prunedRemote != "" {
+		t.Errorf("should leave the remote alone without delete_branch_on_merge, deleted %q", mock.deletedRemoteBranch)
+	}
 }
 
 func TestCompleteMerge_CheckoutFails(t *testing.T) {
@@ -452,7 +620,7 @@ func TestCompleteMerge_CheckoutFails(t *testing.T) {
 		checkoutError: git.ErrBranchNotFound,
 	}
 
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err == nil {
 		t.Error("CompleteMerge() should return error when checkout fails")
 	}
@@ -472,7 +640,7 @@ func TestCompleteMerge_MergeConflict(t *testing.T) {
 		mergeError: git.ErrMergeConflict,
 	}
 
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err == nil {
 		t.Error("CompleteMerge() should return error on merge conflict")
 	}
@@ -492,7 +660,7 @@ func TestCompleteMerge_MergeFails(t *testing.T) {
 		mergeError: errors.New("some git error"),
 	}
 
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err == nil {
 		t.Error("CompleteMerge() should return error on merge failure")
 	}
@@ -512,7 +680,7 @@ func TestCompleteMerge_PushFails(t *testing.T) {
 		pushError: errors.New("push rejected"),
 	}
 
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err == nil {
 		t.Error("CompleteMerge() should return error on push failure")
 	}
@@ -533,7 +701,7 @@ func TestCompleteMerge_DeleteBranchFails(t *testing.T) {
 	}
 
 	// Should NOT fail - just log warning
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err != nil {
 		t.Errorf("CompleteMerge() should not fail when branch delete fails, got: %v", err)
 	}
@@ -550,7 +718,7 @@ func TestCompleteMerge_DeleteRemoteBranchFails(t *testing.T) {
 	}
 
 	// Should NOT fail - just log warning
-	err := CompleteMerge(p, "main", mock)
+	err := CompleteMerge(p, "main", mock, true)
 	if err != nil {
 		t.Errorf("CompleteMerge() should not fail when remote branch delete fails, got: %v", err)
 	}
@@ -0,0 +1,180 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// setupControlTest creates an empty queue and control store.
+func setupControlTest(t *testing.T) (*plan.Queue, *control.Store, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	queueDir := filepath.Join(tmpDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+
+	store := control.NewStore(filepath.Join(tmpDir, ".ralph", "control.json"))
+	return plan.NewQueue(queueDir), store, queueDir
+}
+
+func TestNewWorker_DefaultControl(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".ralph")
+	w := NewWorker(WorkerConfig{ConfigDir: configDir})
+
+	if w.control == nil {
+		t.Fatal("control store should default to the config directory")
+	}
+
+	// The default store reads the config directory's control file
+	control.NewStore(control.Path(configDir)).Pause("alice")
+	if !w.control.IsPaused() {
+		t.Error("worker control should see pause written to ConfigDir/control.json")
+	}
+}
+
+func TestWorker_RunOnce_Paused(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	store.Pause("alice")
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrPaused {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrPaused)
+	}
+
+	// Nothing should have been activated
+	if current, _ := queue.Current(); current != nil {
+		t.Errorf("expected no current plan while paused, got %s", current.Name)
+	}
+}
+
+func TestWorker_RunOnce_SkipsPending(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	store.Skip("alpha")
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+
+	pending, _ := queue.Pending()
+	if len(pending) != 1 || pending[0].Name != "alpha" {
+		t.Errorf("skipped plan should stay in pending, got %v", pending)
+	}
+}
+
+func TestWorker_RunOnce_Labels(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "alpha.md"), []byte("# Plan: Alpha\n**Labels:** backend\n"), 0644)
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+		Labels:  []string{"docs"},
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 1 {
+		t.Errorf("plan without the worker's labels should stay pending, got %v", pending)
+	}
+
+	// A current plan without the labels is left for another worker
+	os.Rename(filepath.Join(queueDir, "pending", "alpha.md"), filepath.Join(queueDir, "current", "alpha.md"))
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() with foreign current plan error = %v, want %v", err, ErrQueueEmpty)
+	}
+	if current, _ := queue.Current(); current == nil || current.Name != "alpha" {
+		t.Errorf("current plan should be left alone, got %v", current)
+	}
+}
+
+func TestWorker_RunOnce_SkippedCurrentReturnsToPending(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	store.Skip("alpha")
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+
+	if current, _ := queue.Current(); current != nil {
+		t.Errorf("skipped current plan should be moved out of current/, got %s", current.Name)
+	}
+	if _, err := os.Stat(filepath.Join(queueDir, "pending", "alpha.md")); err != nil {
+		t.Error("skipped current plan should be returned to pending/")
+	}
+}
+
+func TestWorker_RunOnce_AbandonsCurrent(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "current", "alpha.md"), []byte("# Plan: Alpha\n"), 0644)
+	store.RequestAbandon("alpha", control.AbandonRequest{Reason: "superseded", By: "alice"})
+	eventLog := events.NewLog(filepath.Join(t.TempDir(), "events.jsonl"))
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+		Events:  eventLog,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+
+	if current, _ := queue.Current(); current != nil {
+		t.Errorf("abandoned plan should be moved out of current/, got %s", current.Name)
+	}
+	if _, err := os.Stat(filepath.Join(queueDir, "abandoned", "alpha.md")); err != nil {
+		t.Error("abandoned plan should be moved to abandoned/")
+	}
+
+	progress, _ := os.ReadFile(filepath.Join(queueDir, "abandoned", "alpha.progress.md"))
+	if !strings.Contains(string(progress), "Reason: superseded") {
+		t.Errorf("progress file should record the reason, got %q", progress)
+	}
+
+	ev, _ := eventLog.Last(events.TypePlanAbandoned)
+	if ev == nil || ev.Plan != "alpha" || ev.Message != "superseded" {
+		t.Errorf("plan_abandoned event = %+v", ev)
+	}
+	if store.AbandonRequest("alpha") != nil {
+		t.Error("abandon request should be cleared")
+	}
+}
+
+func TestErrPaused(t *testing.T) {
+	if ErrPaused.Error() != "worker paused" {
+		t.Errorf("ErrPaused message unexpected: %q", ErrPaused.Error())
+	}
+}
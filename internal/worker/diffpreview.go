@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// sendDiffPreview posts the changes an iteration committed in the plan's
+// Slack thread (slack.diff_preview). Iterations that committed nothing, and
+// notifiers without threads, get no preview. Failures are logged.
+func (w *Worker) sendDiffPreview(p *plan.Plan, worktreePath string, iteration int, result *runner.Result) {
+	if w.config == nil || w.config.Slack.DiffPreview.Mode == "" || result == nil {
+		return
+	}
+	if result.Commit == "" || result.Commit == result.HeadBefore {
+		return
+	}
+	sender, ok := w.notifier.(notify.DiffPreviewSender)
+	if !ok {
+		return
+	}
+
+	preview, err := buildDiffPreview(git.NewGit(worktreePath), p.Branch, w.config.Slack.DiffPreview, w.config.Git.Provider, iteration, result.HeadBefore, result.Commit)
+	if err != nil {
+		log.Debug("Failed to build diff preview for %s: %v", p.Name, err)
+		return
+	}
+	// The commit is pushed now, so its progress entry can link it
+	if preview.URL != "" {
+		if err := plan.SetIterationCommit(p, iteration, result.Commit, preview.URL); err != nil {
+			log.Debug("Failed to link commit in progress: %v", err)
+		}
+	}
+	if err := sender.DiffPreview(p, *preview); err != nil {
+		log.Debug("Failed to send diff preview: %v", err)
+	}
+}
+
+// buildDiffPreview describes the commits from..to in the worktree of g. In
+// diff mode the diff is cut to cfg.MaxLines, and left out for iterations
+// touching more than cfg.MaxFiles files. In link mode branch is pushed to
+// origin first, so the commit link resolves; provider is git.provider.
+func buildDiffPreview(g git.Git, branch string, cfg config.DiffPreviewConfig, provider string, iteration int, from, to string) (*notify.DiffPreview, error) {
+	files, err := g.DiffFiles(from, to)
+	if err != nil {
+		return nil, err
+	}
+	d := &notify.DiffPreview{Iteration: iteration, Commit: to, Files: len(files)}
+	for _, f := range files {
+		d.Added += f.Added
+		d.Deleted += f.Deleted
+	}
+
+	switch cfg.Mode {
+	case config.DiffPreviewDiff:
+		if cfg.MaxFiles > 0 && len(files) > cfg.MaxFiles {
+			break
+		}
+		diff, err := g.Diff(from, to)
+		if err != nil {
+			return nil, err
+		}
+		d.Diff, d.Omitted = truncateLines(diff, cfg.MaxLines)
+	case config.DiffPreviewLink:
+		if err := g.PushWithUpstream("origin", branch); err != nil {
+			log.Warn("Failed to push %s for the diff preview, posting the summary only: %v", branch, err)
+			break
+		}
+		if remote, err := git.LookupRemote(g, "origin", provider); err == nil {
+			d.URL = remote.CommitURL(to)
+		}
+	}
+	return d, nil
+}
+
+// truncateLines returns the first n lines of s and the number of lines left
+// out (n = 0 keeps them all).
+func truncateLines(s string, n int) (string, int) {
+	lines := strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	if n <= 0 || len(lines) <= n {
+		return s, 0
+	}
+	return strings.Join(lines[:n], ""), len(lines) - n
+}
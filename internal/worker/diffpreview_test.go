@@ -0,0 +1,150 @@
+package worker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// diffPreviewRecorder is a notifier that records diff previews.
+type diffPreviewRecorder struct {
+	notify.NoopNotifier
+	previews []notify.DiffPreview
+}
+
+func (r *diffPreviewRecorder) DiffPreview(p *plan.Plan, d notify.DiffPreview) error {
+	r.previews = append(r.previews, d)
+	return nil
+}
+
+// commitFile writes and commits a file in dir, returning the new HEAD.
+func commitFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	g := git.NewGit(dir)
+	if err := g.Commit("update "+name, name); err != nil {
+		t.Fatalf("commit %s: %v", name, err)
+	}
+	sha, _ := g.HeadCommit()
+	return sha
+}
+
+func TestWorker_SendDiffPreview(t *testing.T) {
+	dir := stateRepo(t)
+	first := commitFile(t, dir, "app.go", "package app\n")
+	second := commitFile(t, dir, "app.go", "package app\n\nfunc One() {}\nfunc Two() {}\n")
+
+	cfg := config.Defaults()
+	recorder := &diffPreviewRecorder{}
+	w := &Worker{config: cfg, notifier: recorder}
+	p := &plan.Plan{Name: "my-plan", Branch: "main"}
+	result := &runner.Result{HeadBefore: first, Commit: second}
+
+	// Off by default
+	w.sendDiffPreview(p, dir, 2, result)
+	if len(recorder.previews) != 0 {
+		t.Fatal("sendDiffPreview() posted without slack.diff_preview")
+	}
+
+	cfg.Slack.DiffPreview.Mode = config.DiffPreviewDiff
+	cfg.Slack.DiffPreview.MaxLines = 6
+	w.sendDiffPreview(p, dir, 2, &runner.Result{HeadBefore: second, Commit: second})
+	if len(recorder.previews) != 0 {
+		t.Fatal("sendDiffPreview() posted for an iteration without a commit")
+	}
+
+	w.sendDiffPreview(p, dir, 2, result)
+	if len(recorder.previews) != 1 {
+		t.Fatalf("got %d previews, want 1", len(recorder.previews))
+	}
+	d := recorder.previews[0]
+	if d.Iteration != 2 || d.Commit != second || d.Files != 1 || d.Added != 3 || d.Deleted != 0 {
+		t.Errorf("preview = %+v", d)
+	}
+	if !strings.HasPrefix(d.Diff, "diff --git a/app.go b/app.go\n") || strings.Count(d.Diff, "\n") != 6 || d.Omitted == 0 {
+		t.Errorf("diff = %q, omitted %d; want 6 lines and the rest omitted", d.Diff, d.Omitted)
+	}
+
+	// Too many files: the summary only
+	cfg.Slack.DiffPreview.MaxFiles = 1
+	commitFile(t, dir, "b.go", "package app\n")
+	third := commitFile(t, dir, "c.go", "package app\n")
+	w.sendDiffPreview(p, dir, 3, &runner.Result{HeadBefore: first, Commit: third})
+	if d := recorder.previews[1]; d.Files != 3 || d.Diff != "" {
+		t.Errorf("preview over max_files = %+v, want the summary only", d)
+	}
+}
+
+func TestBuildDiffPreview_Link(t *testing.T) {
+	dir := stateRepo(t)
+	remote := t.TempDir()
+	for _, args := range [][]string{{"init", "--bare", remote}, {"-C", dir, "remote", "add", "origin", remote}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	first := commitFile(t, dir, "app.go", "package app\n")
+	second := commitFile(t, dir, "app.go", "package app\n\nfunc App() {}\n")
+
+	cfg := config.DiffPreviewConfig{Mode: config.DiffPreviewLink}
+	d, err := buildDiffPreview(git.NewGit(dir), "main", cfg, "", 1, first, second)
+	if err != nil {
+		t.Fatalf("buildDiffPreview() error = %v", err)
+	}
+	if d.Diff != "" || d.URL != "" {
+		t.Errorf("preview = %+v, want no diff and no link for a local remote", d)
+	}
+	out, err := exec.Command("git", "-C", remote, "rev-parse", "main").Output()
+	if err != nil || strings.TrimSpace(string(out)) != second {
+		t.Errorf("remote main = %q, %v; want the pushed commit %s", strings.TrimSpace(string(out)), err, second)
+	}
+}
+
+func TestTruncateLines(t *testing.T) {
+	if got, omitted := truncateLines("a\nb\nc\n", 2); got != "a\nb\n" || omitted != 1 {
+		t.Errorf("truncateLines(3 lines, 2) = %q, %d", got, omitted)
+	}
+	if got, omitted := truncateLines("a\nb\n", 2); got != "a\nb\n" || omitted != 0 {
+		t.Errorf("truncateLines(2 lines, 2) = %q, %d", got, omitted)
+	}
+	if got, omitted := truncateLines("a\nb\n", 0); got != "a\nb\n" || omitted != 0 {
+		t.Errorf("truncateLines(2 lines, 0) = %q, %d", got, omitted)
+	}
+}
+
+func TestWorker_SendDiffPreview_LinksProgress(t *testing.T) {
+	dir := stateRepo(t)
+	remote := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", remote},
+		{"-C", dir, "remote", "add", "origin", "https://github.com/acme/app.git"},
+		{"-C", dir, "config", "remote.origin.pushurl", remote},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	first := commitFile(t, dir, "app.go", "package app\n")
+	second := commitFile(t, dir, "app.go", "package app\n\nfunc App() {}\n")
+
+	p := &plan.Plan{Name: "my-plan", Branch: "main", Path: filepath.Join(t.TempDir(), "my-plan.md")}
+	plan.AppendIteration(p, plan.ProgressEntry{Iteration: 1, Notes: "Done."})
+
+	cfg := config.Defaults()
+	cfg.Slack.DiffPreview.Mode = config.DiffPreviewLink
+	w := &Worker{config: cfg, notifier: &diffPreviewRecorder{}}
+	w.sendDiffPreview(p, dir, 1, &runner.Result{HeadBefore: first, Commit: second})
+
+	progress, _ := plan.LoadProgress(p)
+	if e := progress.Latest(); e == nil || e.CommitURL != "https://github.com/acme/app/commit/"+second {
+		t.Errorf("Latest() = %+v, want the commit linked", e)
+	}
+}
@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/page"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// escalateBlocker re-notifies about a blocker that has been unresolved for
+// blockers.escalate_after, mentioning blockers.mention, and pages on-call if
+// blockers.page is configured. Failures are logged and never fail the plan.
+func (w *Worker) escalateBlocker(p *plan.Plan, blocker *runner.Blocker, unresolved time.Duration) {
+	unresolved = unresolved.Round(time.Minute)
+	w.recordEvent(events.Event{Type: events.TypeBlockerEscalated, Plan: p.Name, Message: fmt.Sprintf("unresolved for %s: %s", unresolved, blocker.Description)})
+
+	if w.config != nil && w.config.Slack.NotifyBlocker {
+		if err := w.notifier.BlockerEscalation(p, blocker, unresolved, w.config.Blockers.Mention); err != nil {
+			log.Debug("Failed to send blocker escalation: %v", err)
+		}
+	}
+
+	if w.pager != nil {
+		alert := page.Alert{
+			Key:     fmt.Sprintf("ralph-%s-%s", p.Name, blocker.Hash),
+			Summary: fmt.Sprintf("Ralph plan %s blocked for %s: %s", p.Name, unresolved, blocker.Description),
+			Plan:    p.Name,
+			Details: blocker.Action,
+		}
+		if err := w.pager.Trigger(alert); err != nil {
+			log.Warn("Failed to page for blocker: %v", err)
+		} else {
+			log.Info("Paged on-call for blocker in %s", p.Name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+func TestWorker_EscalateBlocker(t *testing.T) {
+	var alert map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&alert)
+	}))
+	defer server.Close()
+
+	cfg := config.Defaults()
+	cfg.Slack.NotifyBlocker = true
+	cfg.Blockers.Mention = "@here"
+	cfg.Blockers.Page = config.PageConfig{Provider: config.PageWebhook, URL: server.URL}
+
+	notifier := &MockNotifier{}
+	eventLog := events.NewLog(events.Path(t.TempDir()))
+	w := NewWorker(WorkerConfig{Config: cfg, Notifier: notifier, Events: eventLog})
+	p := &plan.Plan{Name: "billing"}
+
+	w.escalateBlocker(p, &runner.Blocker{Description: "Need Stripe keys", Action: "Add STRIPE_KEY", Hash: "abc12345"}, 2*time.Hour+10*time.Second)
+
+	if notifier.EscalationCalls != 1 || notifier.LastMention != "@here" {
+		t.Errorf("escalations = %d, mention = %q", notifier.EscalationCalls, notifier.LastMention)
+	}
+	if alert["key"] != "ralph-billing-abc12345" || !strings.Contains(alert["summary"].(string), "blocked for 2h0m0s: Need Stripe keys") {
+		t.Errorf("alert = %v", alert)
+	}
+	last, err := eventLog.Last(events.TypeBlockerEscalated)
+	if err != nil || last == nil || last.Plan != "billing" {
+		t.Errorf("escalation event = %+v, %v", last, err)
+	}
+
+	// Without paging or blocker notifications only the event is recorded
+	cfg.Slack.NotifyBlocker = false
+	quiet := NewWorker(WorkerConfig{Config: cfg, Notifier: notifier})
+	quiet.pager = nil
+	quiet.escalateBlocker(p, &runner.Blocker{Hash: "abc12345"}, time.Hour)
+	if notifier.EscalationCalls != 1 {
+		t.Errorf("escalation sent with notify_blocker off")
+	}
+}
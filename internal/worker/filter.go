@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"path"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// handles reports whether this worker processes the plan: it must have all
+// of the worker's labels, match worker.include if set, and not match
+// worker.exclude.
+func (w *Worker) handles(p *plan.Plan) bool {
+	if !p.HasLabels(w.labels) {
+		return false
+	}
+	if w.config == nil {
+		return true
+	}
+	return MatchPlanName(p.Name, w.config.Worker.Include, w.config.Worker.Exclude)
+}
+
+// MatchPlanName reports whether a plan name passes the include and exclude
+// globs: it must match an include pattern (any name does if there are none)
+// and no exclude pattern.
+func MatchPlanName(name string, include, exclude []string) bool {
+	if len(include) > 0 && !matchAny(include, name) {
+		return false
+	}
+	return !matchAny(exclude, name)
+}
+
+// matchAny reports whether name matches one of the glob patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestMatchPlanName(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"infra-dns", nil, nil, true},
+		{"infra-dns", []string{"infra-*"}, nil, true},
+		{"docs-api", []string{"infra-*"}, nil, false},
+		{"infra-dns", nil, []string{"infra-*"}, false},
+		{"docs-api", nil, []string{"infra-*"}, true},
+		{"infra-legacy-vpn", []string{"infra-*"}, []string{"infra-legacy-*"}, false},
+		{"docs-api", []string{"infra-*", "docs-*"}, nil, true},
+	}
+	for _, tt := range tests {
+		if got := MatchPlanName(tt.name, tt.include, tt.exclude); got != tt.want {
+			t.Errorf("MatchPlanName(%q, %q, %q) = %v, want %v", tt.name, tt.include, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestWorker_RunOnce_Exclude(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "infra-dns.md"), []byte("# Plan: DNS\n"), 0644)
+
+	cfg := config.Defaults()
+	cfg.Worker.Exclude = []string{"infra-*"}
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  cfg,
+		Control: store,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 1 {
+		t.Errorf("excluded plan should stay pending, got %v", pending)
+	}
+}
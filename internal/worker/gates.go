@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/coverage"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// runGates runs the final test and lint gates in the worktree if
+// completion.gates is set. Failures are reported, not enforced: the plan
+// still completes. Flaky tests are reported as a warning.
+func (w *Worker) runGates(ctx context.Context, dir string) []gate.Result {
+	if w.config == nil || !w.config.Completion.Gates {
+		return nil
+	}
+
+	results := gate.RunAll(ctx, dir, w.config.Commands, w.config.Flaky)
+	for _, r := range results {
+		if len(r.Flaky) > 0 {
+			log.Warn("Gate %s found flaky tests: %s", r.Name, strings.Join(r.Flaky, ", "))
+		}
+		if r.Passed {
+			log.Success("Gate %s passed (%s)", r.Name, r.Duration.Round(time.Second))
+		} else {
+			log.Warn("Gate %s failed: %s\n%s", r.Name, r.Command, r.Output)
+		}
+	}
+	return results
+}
+
+// coverageGate compares the plan's final coverage with the base branch's,
+// failing if it dropped more than coverage.max_drop. Returns nil if coverage
+// isn't tracked or wasn't measured.
+func (w *Worker) coverageGate(result *runner.LoopResult) *gate.Result {
+	if w.config == nil || w.config.Coverage.Command == "" || result == nil || result.Coverage == nil || result.Coverage.Latest == nil {
+		return nil
+	}
+
+	latest := *result.Coverage.Latest
+	if result.Coverage.Base == nil {
+		return &gate.Result{Name: gate.Coverage, Command: w.config.Coverage.Command, Passed: true, Output: fmt.Sprintf("coverage %.1f%% (no base measurement)", latest)}
+	}
+	r := coverage.Check(w.config.Coverage.Command, *result.Coverage.Base, latest, w.config.Coverage.MaxDrop)
+	if r.Passed {
+		log.Success("Gate coverage passed: %s", r.Output)
+	} else {
+		log.Warn("Gate coverage failed: %s dropped more than %g points", r.Output, w.config.Coverage.MaxDrop)
+	}
+	return &r
+}
+
+// completion summarizes a completed plan for the completion notification.
+func (w *Worker) completion(p *plan.Plan, result *runner.LoopResult, prURL string, gates []gate.Result) notify.Completion {
+	c := notify.Completion{
+		PRURL:         prURL,
+		MaxIterations: w.maxIterations,
+		Duration:      w.planDuration(p),
+		Gates:         gates,
+	}
+	if result != nil {
+		c.Iterations = result.Iterations
+	}
+	return c
+}
+
+// planDuration returns the total iteration wall time of the plan's latest
+// run from the events log, or 0 if it isn't known.
+func (w *Worker) planDuration(p *plan.Plan) time.Duration {
+	var elapsed time.Duration
+	for _, e := range w.latestRun(p) {
+		if e.Type == events.TypeIteration {
+			elapsed += e.Duration
+		}
+	}
+	return elapsed
+}
+
+// latestRun returns the events of the plan's latest run, or nil if the
+// events log isn't available.
+func (w *Worker) latestRun(p *plan.Plan) []events.Event {
+	if w.events == nil {
+		return nil
+	}
+
+	evs, err := w.events.Since(time.Time{})
+	if err != nil {
+		log.Debug("Failed to read events log: %v", err)
+		return nil
+	}
+	var run []events.Event
+	for _, e := range evs {
+		if e.Plan != p.Name {
+			continue
+		}
+		switch e.Type {
+		case events.TypePlanCompleted, events.TypePlanReset:
+			// A plan with the same name ran before; only the latest run counts
+			run = nil
+		default:
+			run = append(run, e)
+		}
+	}
+	return run
+}
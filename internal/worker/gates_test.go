@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+func TestWorker_RunGates(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.Commands = config.CommandsConfig{Test: "true", Lint: "exit 1"}
+	w := &Worker{config: cfg}
+
+	if got := w.runGates(context.Background(), t.TempDir()); got != nil {
+		t.Errorf("runGates() with completion.gates off = %+v, want nil", got)
+	}
+
+	cfg.Completion.Gates = true
+	got := w.runGates(context.Background(), t.TempDir())
+	if len(got) != 2 || !got[0].Passed || got[1].Passed || got[1].Name != gate.Lint {
+		t.Errorf("runGates() = %+v, want test passed and lint failed", got)
+	}
+}
+
+func TestWorker_CoverageGate(t *testing.T) {
+	cfg := config.Defaults()
+	w := &Worker{config: cfg}
+	base, dropped, unchanged := 80.0, 77.0, 80.0
+	result := &runner.LoopResult{Completed: true, Coverage: &runner.CoverageState{Base: &base, Latest: &dropped}}
+
+	if got := w.coverageGate(result); got != nil {
+		t.Errorf("coverageGate() without coverage.command = %+v, want nil", got)
+	}
+
+	cfg.Coverage.Command = "make cover"
+	cfg.Coverage.MaxDrop = 2
+	if got := w.coverageGate(result); got == nil || got.Passed || got.Name != gate.Coverage {
+		t.Errorf("coverageGate() after a 3 point drop = %+v, want failed", got)
+	}
+
+	result.Coverage.Latest = &unchanged
+	if got := w.coverageGate(result); got == nil || !got.Passed {
+		t.Errorf("coverageGate() unchanged = %+v, want passed", got)
+	}
+
+	result.Coverage.Base = nil
+	if got := w.coverageGate(result); got == nil || !got.Passed {
+		t.Errorf("coverageGate() without a base = %+v, want passed", got)
+	}
+	if got := w.coverageGate(&runner.LoopResult{}); got != nil {
+		t.Errorf("coverageGate() without measurements = %+v, want nil", got)
+	}
+}
+
+func TestWorker_Completion(t *testing.T) {
+	w := &Worker{events: events.NewLog(events.Path(t.TempDir())), maxIterations: 30}
+	p := &plan.Plan{Name: "test"}
+
+	// An earlier run of a plan with the same name doesn't count
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: "test", Duration: time.Hour})
+	w.recordEvent(events.Event{Type: events.TypePlanCompleted, Plan: "test"})
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: "test", Duration: 10 * time.Minute})
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: "other", Duration: time.Hour})
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: "test", Duration: 5 * time.Minute})
+
+	gates := []gate.Result{{Name: gate.Test, Passed: true}}
+	c := w.completion(p, &runner.LoopResult{Completed: true, Iterations: 2}, "https://github.com/o/r/pull/1", gates)
+	if c.PRURL != "https://github.com/o/r/pull/1" || c.Iterations != 2 || c.MaxIterations != 30 || c.Duration != 15*time.Minute || len(c.Gates) != 1 {
+		t.Errorf("completion() = %+v", c)
+	}
+
+	if c := (&Worker{}).completion(p, nil, "", nil); c.Iterations != 0 || c.Duration != 0 {
+		t.Errorf("completion() without result or events log = %+v", c)
+	}
+}
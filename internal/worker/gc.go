@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/gc"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// GCOptions returns the garbage collection options for the config
+// (gc.retention_days, worktree.scratch_dir) and .ralph directory. The caller
+// fills in the plans and worktrees directories and the Slack thread tracker.
+func GCOptions(cfg *config.Config, configDir string) gc.Options {
+	opts := gc.Options{ConfigDir: configDir, ScratchDir: worktree.ScratchRoot(cfg, filepath.Dir(configDir))}
+	if cfg != nil {
+		opts.RetentionDays = cfg.GC.RetentionDays
+	}
+	return opts
+}
+
+// collectGarbage removes the runtime artifacts of long-finished plans
+// (gc.auto). Failures are logged; they never stop the worker.
+func (w *Worker) collectGarbage() {
+	if w.config == nil || !w.config.GC.Auto || w.queue == nil {
+		return
+	}
+	opts := GCOptions(w.config, w.configDir)
+	opts.PlansDir = w.queue.BaseDir
+	opts.Threads = w.threadTracker
+	if w.worktreeManager != nil {
+		opts.WorktreesDir = w.worktreeManager.BaseDir()
+	}
+
+	result, err := gc.Collect(opts)
+	if err != nil {
+		log.Warn("Garbage collection failed: %v", err)
+		return
+	}
+	if len(result.Artifacts) > 0 {
+		log.Info("Garbage collected %d artifact(s) of %d finished plan(s), reclaimed %s",
+			len(result.Artifacts), result.Plans, runner.FormatSize(result.Reclaimed))
+	}
+}
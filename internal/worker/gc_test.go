@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestGCOptions(t *testing.T) {
+	if opts := GCOptions(nil, ".ralph"); opts.ConfigDir != ".ralph" || opts.RetentionDays != 0 {
+		t.Errorf("GCOptions(nil) = %+v", opts)
+	}
+	cfg := config.Defaults()
+	cfg.GC.RetentionDays = 7
+	if opts := GCOptions(cfg, ".ralph"); opts.RetentionDays != 7 {
+		t.Errorf("GCOptions().RetentionDays = %d, want 7", opts.RetentionDays)
+	}
+}
+
+func TestWorker_CollectGarbage(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".ralph")
+	plansDir := filepath.Join(dir, "plans")
+	os.MkdirAll(filepath.Join(plansDir, "complete"), 0755)
+	planPath := filepath.Join(plansDir, "complete", "old-plan.md")
+	os.WriteFile(planPath, []byte("# Plan\n"), 0644)
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	os.Chtimes(planPath, old, old)
+	logPath := log.PlanLogPath(configDir, "old-plan")
+	os.MkdirAll(filepath.Dir(logPath), 0755)
+	os.WriteFile(logPath, []byte("log"), 0644)
+
+	cfg := config.Defaults()
+	w := &Worker{config: cfg, configDir: configDir, queue: plan.NewQueue(plansDir)}
+
+	// Off by default
+	w.collectGarbage()
+	if _, err := os.Stat(logPath); err != nil {
+		t.Fatal("collectGarbage() removed a log without gc.auto")
+	}
+
+	cfg.GC.Auto = true
+	w.collectGarbage()
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("collectGarbage() should remove the log of a plan finished past gc.retention_days")
+	}
+	if _, err := os.Stat(planPath); err != nil {
+		t.Error("collectGarbage() should keep the plan file")
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/arvesolland/ralph/internal/hooks"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// runCompleteHooks runs the worktree complete hooks and the global
+// hooks.on_plan_complete hooks. Hook failures are logged but never fail completion.
+func (w *Worker) runCompleteHooks(ctx context.Context, p *plan.Plan, wt *worktree.Worktree, result *runner.LoopResult, prURL string) {
+	if w.config == nil {
+		return
+	}
+
+	payload := hooks.Payload{
+		Event:  hooks.EventPlanComplete,
+		Plan:   p.Name,
+		Branch: p.Branch,
+		PRURL:  prURL,
+		Result: "complete",
+	}
+	if result != nil {
+		payload.Iterations = result.Iterations
+	}
+
+	// Worktree-scoped hooks run inside the worktree before it and the
+	// scratch directory are removed, so they can keep build artifacts
+	if wt != nil {
+		env := append(payload.Env(), worktree.ScratchEnviron(worktree.ScratchPath(w.scratchRoot(), p))...)
+		hookResult, err := worktree.RunCompleteHooks(wt.Path, w.config, w.mainWorktreePath, env)
+		if err != nil {
+			log.Warn("Complete hooks failed: %v", err)
+		} else if hookResult != nil && hookResult.Method != "none" {
+			log.Debug("Complete hooks ran via method: %s", hookResult.Method)
+		}
+	}
+
+	if len(w.config.Hooks.OnPlanComplete) > 0 {
+		log.Info("Running on_plan_complete hooks...")
+		w.hookRunner().Run(ctx, w.config.Hooks.OnPlanComplete, payload)
+	}
+}
+
+// runErrorHooks runs the global hooks.on_plan_error hooks.
+func (w *Worker) runErrorHooks(p *plan.Plan, err error) {
+	if w.config == nil || len(w.config.Hooks.OnPlanError) == 0 {
+		return
+	}
+
+	payload := hooks.Payload{
+		Event:  hooks.EventPlanError,
+		Plan:   p.Name,
+		Branch: p.Branch,
+		Result: "error",
+	}
+	if err != nil {
+		payload.Error = err.Error()
+	}
+
+	log.Info("Running on_plan_error hooks...")
+	w.hookRunner().Run(context.Background(), w.config.Hooks.OnPlanError, payload)
+}
+
+// hookRunner creates a hook runner rooted at the main worktree.
+func (w *Worker) hookRunner() *hooks.Runner {
+	r := hooks.NewRunner(w.mainWorktreePath)
+	r.Env = []string{"MAIN_WORKTREE=" + w.mainWorktreePath}
+	return r
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+func TestWorker_RunCompleteHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell hook test on Windows")
+	}
+
+	mainDir := t.TempDir()
+	wtDir := t.TempDir()
+
+	cfg := config.Defaults()
+	cfg.Worktree.CompleteHooks = `echo "$RALPH_PLAN" > complete.txt`
+	cfg.Hooks.OnPlanComplete = []string{`echo "$RALPH_PR_URL $RALPH_ITERATIONS" > global.txt`}
+
+	w := &Worker{config: cfg, mainWorktreePath: mainDir}
+	p := &plan.Plan{Name: "my-plan", Branch: "feat/my-plan"}
+	wt := &worktree.Worktree{Path: wtDir, Branch: p.Branch}
+
+	w.runCompleteHooks(context.Background(), p, wt, &runner.LoopResult{Iterations: 4}, "https://github.com/o/r/pull/7")
+
+	data, err := os.ReadFile(filepath.Join(wtDir, "complete.txt"))
+	if err != nil {
+		t.Fatalf("worktree complete hook did not run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "my-plan" {
+		t.Errorf("complete.txt = %q, want %q", strings.TrimSpace(string(data)), "my-plan")
+	}
+
+	data, err = os.ReadFile(filepath.Join(mainDir, "global.txt"))
+	if err != nil {
+		t.Fatalf("on_plan_complete hook did not run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "https://github.com/o/r/pull/7 4" {
+		t.Errorf("global.txt = %q", strings.TrimSpace(string(data)))
+	}
+}
+
+func TestWorker_RunErrorHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell hook test on Windows")
+	}
+
+	mainDir := t.TempDir()
+
+	cfg := config.Defaults()
+	cfg.Hooks.OnPlanError = []string{`echo "$RALPH_RESULT: $RALPH_ERROR" > error.txt`}
+	cfg.Slack.NotifyError = false
+
+	w := &Worker{config: cfg, mainWorktreePath: mainDir}
+	w.notifyError(&plan.Plan{Name: "broken"}, errors.New("boom"))
+
+	data, err := os.ReadFile(filepath.Join(mainDir, "error.txt"))
+	if err != nil {
+		t.Fatalf("on_plan_error hook did not run: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "error: boom" {
+		t.Errorf("error.txt = %q, want %q", strings.TrimSpace(string(data)), "error: boom")
+	}
+}
+
+func TestWorker_RunHooks_NilConfig(t *testing.T) {
+	w := &Worker{}
+	p := &plan.Plan{Name: "test"}
+
+	// Should not panic with nil config
+	w.runCompleteHooks(context.Background(), p, nil, nil, "")
+	w.runErrorHooks(p, errors.New("boom"))
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/knowledge"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// planFinished looks back on a plan that completed, failed, or was
+// abandoned: the retrospective (lessons.enabled) and the knowledge base
+// (knowledge.enabled). Call it while the plan is still in current/.
+func (w *Worker) planFinished(ctx context.Context, p *plan.Plan, outcome, reason string) {
+	lessons := w.retrospect(ctx, p, outcome, reason)
+	w.updateKnowledge(p, lessons, time.Now())
+}
+
+// updateKnowledge adds the plan's progress gotchas and its lessons to the
+// knowledge base (knowledge.enabled). Lessons are keyed by the directories
+// of every file the plan changed and its labels. Failures are logged.
+func (w *Worker) updateKnowledge(p *plan.Plan, lessons []string, now time.Time) {
+	if w.config == nil || !w.config.Knowledge.Enabled {
+		return
+	}
+	store, err := knowledge.Load(knowledge.Path(w.configDir))
+	if err != nil {
+		log.Warn("Failed to load knowledge base: %v", err)
+		return
+	}
+
+	added := 0
+	if progress, err := plan.LoadProgress(p); err == nil {
+		added += store.Harvest(p, progress, now)
+	}
+	if len(lessons) > 0 {
+		var files []string
+		if changes, err := plan.LoadChanges(p); err == nil {
+			for _, f := range changes.Files {
+				files = append(files, f.Path)
+			}
+		}
+		keys := append(knowledge.PathKeys(files), p.Labels...)
+		for _, lesson := range lessons {
+			if store.Add(knowledge.Entry{Keys: keys, Text: lesson, Plan: p.Name, Date: now.Format("2006-01-02")}) {
+				added++
+			}
+		}
+	}
+
+	if len(store.Entries) == 0 {
+		return
+	}
+	if err := store.Save(); err != nil {
+		log.Warn("Failed to save knowledge base: %v", err)
+		return
+	}
+	log.Debug("Knowledge base: %d new entries from %s", added, p.Name)
+}
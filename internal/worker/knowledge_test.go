@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/knowledge"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestWorker_UpdateKnowledge(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".ralph")
+	path := filepath.Join(dir, "add-login.md")
+	os.WriteFile(path, []byte("# Plan: Add login\n**Labels:** backend\n\n- [x] Form\n"), 0644)
+	p, err := plan.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(plan.ProgressPath(p), []byte("### Iteration 1: Form\n```yaml progress\ncompleted: Added the form\ngotchas:\n  - go test needs the DB container\nfiles:\n  - internal/auth/form.go\n```\n"), 0644)
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "internal/auth/form.go", Change: plan.ChangeCreated}})
+
+	cfg := config.Defaults()
+	w := &Worker{config: cfg, configDir: configDir}
+	now := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+
+	// Disabled by default
+	w.updateKnowledge(p, []string{"Run make generate first"}, now)
+	if _, err := os.Stat(knowledge.Path(configDir)); !os.IsNotExist(err) {
+		t.Fatal("updateKnowledge() wrote the knowledge base without knowledge.enabled")
+	}
+
+	cfg.Knowledge.Enabled = true
+	w.updateKnowledge(p, []string{"Run make generate first"}, now)
+
+	store, err := knowledge.Load(knowledge.Path(configDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []knowledge.Entry{
+		{Keys: []string{"internal/auth/**", "backend"}, Text: "go test needs the DB container", Plan: "add-login", Date: "2024-01-30", Seen: 1},
+		{Keys: []string{"internal/auth/**", "backend"}, Text: "Run make generate first", Plan: "add-login", Date: "2024-01-30", Seen: 1},
+	}
+	if !reflect.DeepEqual(store.Entries, want) {
+		t.Errorf("Entries = %+v, want %+v", store.Entries, want)
+	}
+}
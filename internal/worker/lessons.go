@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// classifyCompletion classifies a completed plan's outcome: it needed human
+// help if it got feedback or raised a blocker in its latest run, and is clean
+// otherwise. Call it before the plan_completed event is recorded.
+func (w *Worker) classifyCompletion(p *plan.Plan) string {
+	if fb, err := plan.LoadFeedback(p); err == nil && len(fb.Pending)+len(fb.Processed) > 0 {
+		return runner.OutcomeNeededHumanHelp
+	}
+	for _, e := range w.latestRun(p) {
+		if e.Type == events.TypeBlocker {
+			return runner.OutcomeNeededHumanHelp
+		}
+	}
+	return runner.OutcomeClean
+}
+
+// retrospect looks back on a finished plan (lessons.enabled): it records the
+// plan's outcome in the events log and asks the lessons model what future
+// plans should know, appended to .ralph/lessons.md. reason is why a plan
+// failed or was abandoned. It reads the plan's progress and feedback files,
+// so call it before the plan leaves current/. Failures are logged and never
+// affect the plan. Returns the lessons recorded.
+func (w *Worker) retrospect(ctx context.Context, p *plan.Plan, outcome, reason string) []string {
+	if w.config == nil || !w.config.Lessons.Enabled {
+		return nil
+	}
+	log.Info("Plan outcome: %s", outcome)
+
+	var lessons []string
+	if w.runner != nil {
+		progress, _ := plan.ReadProgress(p)
+		feedback, _ := os.ReadFile(plan.FeedbackPath(p))
+		model := w.config.Lessons.Model
+		if model == "" {
+			model = w.config.Completion.VerificationModel
+		}
+
+		var err error
+		lessons, err = runner.Retrospect(ctx, runner.Retrospective{
+			Plan:     p.Name,
+			Content:  p.Content,
+			Outcome:  outcome,
+			Reason:   reason,
+			Progress: progress,
+			Feedback: string(feedback),
+			Lessons:  prompt.ReadLessons(w.configDir),
+		}, w.runner, model)
+		if err != nil {
+			log.Warn("Retrospective for %s failed: %v", p.Name, err)
+		} else if err := prompt.AppendLessons(w.configDir, p.Name, outcome, lessons, time.Now()); err != nil {
+			log.Warn("Failed to record lessons: %v", err)
+		} else if len(lessons) > 0 {
+			log.Info("Recorded %d lesson(s) in %s", len(lessons), prompt.LessonsPath(w.configDir))
+		}
+	}
+
+	w.recordEvent(events.Event{Type: events.TypePlanOutcome, Plan: p.Name, Outcome: outcome, Message: strings.Join(lessons, "; ")})
+	return lessons
+}
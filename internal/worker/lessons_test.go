@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+func TestWorker_ClassifyCompletion(t *testing.T) {
+	dir := t.TempDir()
+	w := &Worker{events: events.NewLog(events.Path(dir))}
+	p := &plan.Plan{Name: "add-login", Path: filepath.Join(dir, "add-login.md")}
+
+	// A blocker in an earlier run doesn't count
+	w.recordEvent(events.Event{Type: events.TypeBlocker, Plan: "add-login"})
+	w.recordEvent(events.Event{Type: events.TypePlanReset, Plan: "add-login"})
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: "add-login"})
+	if got := w.classifyCompletion(p); got != runner.OutcomeClean {
+		t.Errorf("classifyCompletion() = %q, want %q", got, runner.OutcomeClean)
+	}
+
+	w.recordEvent(events.Event{Type: events.TypeBlocker, Plan: "add-login"})
+	if got := w.classifyCompletion(p); got != runner.OutcomeNeededHumanHelp {
+		t.Errorf("classifyCompletion() after a blocker = %q, want %q", got, runner.OutcomeNeededHumanHelp)
+	}
+
+	// Feedback counts even without an events log
+	plan.AppendFeedback(p, "slack", "Use bcrypt")
+	if got := (&Worker{}).classifyCompletion(p); got != runner.OutcomeNeededHumanHelp {
+		t.Errorf("classifyCompletion() with feedback = %q, want %q", got, runner.OutcomeNeededHumanHelp)
+	}
+}
+
+func TestWorker_Retrospect(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, ".ralph")
+	path := filepath.Join(dir, "add-login.md")
+	os.WriteFile(path, []byte("# Plan: Add login\n\n- [x] Form\n"), 0644)
+	p, err := plan.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.AppendProgress(p, 1, "go test needs the DB container running")
+
+	var gotPrompt string
+	var gotModel string
+	mock := &MockRunner{RunFunc: func(ctx context.Context, text string, opts runner.Options) (*runner.Result, error) {
+		gotPrompt, gotModel = text, opts.Model
+		return &runner.Result{TextContent: "- Start the DB container before go test\n"}, nil
+	}}
+
+	cfg := config.Defaults()
+	w := &Worker{config: cfg, configDir: configDir, runner: mock, events: events.NewLog(events.Path(configDir))}
+
+	// Disabled by default
+	w.retrospect(context.Background(), p, runner.OutcomeClean, "")
+	if mock.calls != 0 {
+		t.Fatalf("retrospect() ran with lessons disabled")
+	}
+
+	cfg.Lessons = config.LessonsConfig{Enabled: true, Model: "haiku"}
+	w.retrospect(context.Background(), p, runner.OutcomeFailedVerification, "max iterations reached")
+
+	if gotModel != "haiku" {
+		t.Errorf("model = %q, want lessons.model", gotModel)
+	}
+	if !strings.Contains(gotPrompt, "DB container running") || !strings.Contains(gotPrompt, "failed-verification (max iterations reached)") {
+		t.Errorf("prompt lacks the progress log or outcome:\n%s", gotPrompt)
+	}
+	if lessons := prompt.ReadLessons(configDir); !strings.Contains(lessons, "add-login (failed-verification)\n\n- Start the DB container before go test") {
+		t.Errorf("lessons file = %q", lessons)
+	}
+
+	evs, _ := w.events.Since(time.Time{})
+	if len(evs) != 1 || evs[0].Type != events.TypePlanOutcome || evs[0].Outcome != runner.OutcomeFailedVerification {
+		t.Errorf("events = %+v, want one plan_outcome event", evs)
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/git"
+)
+
+// maxListedPaths bounds the dirty paths named in a main worktree problem.
+const maxListedPaths = 5
+
+// syncedPrefix is the part of the main worktree the worker writes to: plans
+// move between the queue folders, and plan and progress files are synced
+// back from the plan's worktree.
+const syncedPrefix = "plans/"
+
+// CheckMainWorktree returns the problems with running the worker against the
+// main worktree: it's on a branch other than baseBranch, behind its upstream
+// (as of the last fetch), or has uncommitted changes to tracked files under
+// plans/, which sync-back may overwrite. An empty baseBranch skips the branch
+// check. Returns no problems if everything looks safe.
+func CheckMainWorktree(g git.Git, baseBranch string) ([]string, error) {
+	status, err := g.Status()
+	if err != nil {
+		return nil, fmt.Errorf("checking main worktree status: %w", err)
+	}
+
+	var problems []string
+	if baseBranch != "" && status.Branch != baseBranch {
+		branch := status.Branch
+		if branch == "" || branch == "HEAD" {
+			branch = "a detached HEAD"
+		}
+		problems = append(problems, fmt.Sprintf("main worktree is on %s, expected %s (git.base_branch)", branch, baseBranch))
+	}
+
+	upstream, _, behind, err := g.UpstreamDivergence()
+	if err != nil {
+		return nil, fmt.Errorf("checking upstream: %w", err)
+	}
+	if behind > 0 {
+		problems = append(problems, fmt.Sprintf("main worktree is %d commit(s) behind %s; pull before starting", behind, upstream))
+	}
+
+	if dirty := dirtySyncedPaths(status); len(dirty) > 0 {
+		listed := dirty
+		more := ""
+		if len(listed) > maxListedPaths {
+			more = fmt.Sprintf(" and %d more", len(listed)-maxListedPaths)
+			listed = listed[:maxListedPaths]
+		}
+		problems = append(problems, fmt.Sprintf("main worktree has uncommitted changes under %s that the worker may overwrite: %s%s", syncedPrefix, strings.Join(listed, ", "), more))
+	}
+	return problems, nil
+}
+
+// dirtySyncedPaths returns the tracked files under plans/ with staged or
+// unstaged changes, sorted and without duplicates.
+func dirtySyncedPaths(status *git.Status) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, list := range [][]string{status.Staged, status.Unstaged} {
+		for _, path := range list {
+			if strings.HasPrefix(path, syncedPrefix) && !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/git"
+)
+
+// mockGitForMainRepo reports a fixed status and upstream divergence.
+type mockGitForMainRepo struct {
+	git.Git
+	status      *git.Status
+	upstream    string
+	behind      int
+	upstreamErr error
+}
+
+func (m *mockGitForMainRepo) Status() (*git.Status, error) {
+	return m.status, nil
+}
+
+func (m *mockGitForMainRepo) UpstreamDivergence() (string, int, int, error) {
+	return m.upstream, 0, m.behind, m.upstreamErr
+}
+
+func TestCheckMainWorktree(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   *mockGitForMainRepo
+		base   string
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "clean",
+			mock:   &mockGitForMainRepo{status: &git.Status{Branch: "main", Untracked: []string{"plans/pending/new.md"}}, upstream: "origin/main"},
+			base:   "main",
+			wantOK: true,
+		},
+		{
+			name: "wrong branch",
+			mock: &mockGitForMainRepo{status: &git.Status{Branch: "feat/wip"}},
+			base: "main",
+			want: []string{"main worktree is on feat/wip, expected main"},
+		},
+		{
+			name:   "any branch",
+			mock:   &mockGitForMainRepo{status: &git.Status{Branch: "feat/wip"}},
+			wantOK: true,
+		},
+		{
+			name: "behind",
+			mock: &mockGitForMainRepo{status: &git.Status{Branch: "main"}, upstream: "origin/main", behind: 3},
+			base: "main",
+			want: []string{"3 commit(s) behind origin/main"},
+		},
+		{
+			name: "dirty plans",
+			mock: &mockGitForMainRepo{status: &git.Status{
+				Branch:   "main",
+				Staged:   []string{"plans/current/alpha.md", "src/app.go"},
+				Unstaged: []string{"plans/current/alpha.progress.md", "plans/current/alpha.md"},
+			}},
+			base: "main",
+			want: []string{"under plans/ that the worker may overwrite: plans/current/alpha.md, plans/current/alpha.progress.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := CheckMainWorktree(tt.mock, tt.base)
+			if err != nil {
+				t.Fatalf("CheckMainWorktree() error = %v", err)
+			}
+			if tt.wantOK {
+				if len(problems) != 0 {
+					t.Errorf("problems = %v, want none", problems)
+				}
+				return
+			}
+			if len(problems) != len(tt.want) {
+				t.Fatalf("problems = %v, want %d", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i], want) {
+					t.Errorf("problem %q, want %q", problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMainWorktree_ManyDirty(t *testing.T) {
+	mock := &mockGitForMainRepo{status: &git.Status{Branch: "main", Unstaged: []string{
+		"plans/a.md", "plans/b.md", "plans/c.md", "plans/d.md", "plans/e.md", "plans/f.md", "plans/g.md",
+	}}}
+	problems, _ := CheckMainWorktree(mock, "main")
+	if len(problems) != 1 || !strings.HasSuffix(problems[0], "plans/e.md and 2 more") {
+		t.Errorf("problems = %v", problems)
+	}
+}
+
+func TestCheckMainWorktree_UpstreamError(t *testing.T) {
+	mock := &mockGitForMainRepo{status: &git.Status{Branch: "main"}, upstreamErr: errors.New("bad object")}
+	if _, err := CheckMainWorktree(mock, "main"); err == nil {
+		t.Error("CheckMainWorktree() should return the upstream error")
+	}
+}
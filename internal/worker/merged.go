@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// mergeCheckInterval is how often the worker asks GitHub whether the pull
+// requests of completed plans have merged.
+const mergeCheckInterval = 5 * time.Minute
+
+// ghPRState returns the state of the branch's pull request: OPEN, CLOSED, or MERGED.
+var ghPRState = func(dir, branch string) (string, error) {
+	cmd := exec.Command("gh", "pr", "view", branch, "--json", "state", "-q", ".state")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh pr view %s: %s: %w", branch, strings.TrimSpace(stderr.String()), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// syncMergedPlans handles completed plans whose pull requests have merged
+// since the last check: their issues move to done, and with
+// git.delete_branch_on_merge their branches are deleted. Runs at most every
+// mergeCheckInterval.
+func (w *Worker) syncMergedPlans() {
+	if time.Since(w.lastMergeCheck) < mergeCheckInterval {
+		return
+	}
+	deleteBranches := w.completionMode == "pr" && w.config.Git.DeleteBranchOnMerge && w.git != nil
+	if len(w.trackers) == 0 && !deleteBranches {
+		return
+	}
+	w.lastMergeCheck = time.Now()
+
+	// Merge state comes from gh, which only knows GitHub
+	if remote := w.originRemote(); remote != nil && remote.Provider != git.ProviderGitHub {
+		log.Debug("Skipping merge check: origin is on %s", remote.Provider)
+		return
+	}
+
+	complete, err := w.queue.Completed()
+	if err != nil {
+		log.Debug("Listing complete plans for merge check: %v", err)
+		return
+	}
+	for _, p := range complete {
+		syncIssue := w.awaitingMerge(p)
+		deleteBranch := deleteBranches && w.hasLocalBranch(p)
+		if !syncIssue && !deleteBranch {
+			continue
+		}
+		state, err := ghPRState(w.mainWorktreePath, p.Branch)
+		if err != nil {
+			log.Debug("Checking pull request of %s: %v", p.Name, err)
+			continue
+		}
+		if state != "MERGED" {
+			continue
+		}
+		if syncIssue {
+			w.syncIssue(p, tracker.StageDone, "", "")
+		}
+		if deleteBranch {
+			log.Info("Pull request of %s merged, deleting branch %s", p.Name, p.Branch)
+			deleteRemoteBranch(w.git, p.Branch)
+			if err := w.git.DeleteBranch(p.Branch, true); err != nil && !errors.Is(err, git.ErrBranchNotFound) {
+				log.Warn("Failed to delete local branch %s: %v", p.Branch, err)
+			}
+		}
+	}
+}
+
+// hasLocalBranch reports whether the plan's branch still exists in the main
+// repository. Deleting it is what marks a merged plan's branch cleaned up.
+func (w *Worker) hasLocalBranch(p *plan.Plan) bool {
+	exists, err := w.git.BranchExists(p.Branch)
+	return err == nil && exists
+}
+
+// deleteRemoteBranch deletes branch on origin and prunes the remote-tracking
+// refs of branches deleted there. Failures are logged, not returned: the
+// branch may never have been pushed, or GitHub may have deleted it already.
+func deleteRemoteBranch(g git.Git, branch string) {
+	log.Info("Deleting remote branch %s...", branch)
+	if err := g.DeleteRemoteBranch("origin", branch); err != nil {
+		log.Warn("Failed to delete remote branch %s: %v", branch, err)
+	} else {
+		log.Debug("Deleted remote branch %s", branch)
+	}
+	if err := g.PruneRemote("origin"); err != nil {
+		log.Debug("Failed to prune origin's deleted branches: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+)
+
+func TestWorker_SyncMergedPlans_DeletesBranches(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := runGitInit(repoDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	remoteDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", "-b", "main", remoteDir},
+		{"-C", repoDir, "remote", "add", "origin", remoteDir},
+		{"-C", repoDir, "branch", "feat/merged"},
+		{"-C", repoDir, "branch", "feat/open"},
+		{"-C", repoDir, "push", "origin", "main", "feat/merged", "feat/open"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	queue, _, queueDir := setupControlTest(t)
+	for _, name := range []string{"merged", "open"} {
+		os.WriteFile(filepath.Join(queueDir, "complete", name+".md"), []byte("# Plan\n- [x] Done\n"), 0644)
+	}
+
+	oldState := ghPRState
+	defer func() { ghPRState = oldState }()
+	var checked []string
+	ghPRState = func(dir, branch string) (string, error) {
+		checked = append(checked, branch)
+		if branch == "feat/merged" {
+			return "MERGED", nil
+		}
+		return "OPEN", nil
+	}
+
+	cfg := config.Defaults()
+	cfg.Git.DeleteBranchOnMerge = true
+	g := git.NewGit(repoDir)
+	w := NewWorker(WorkerConfig{Queue: queue, Config: cfg, Git: g, MainWorktreePath: repoDir, CompletionMode: "pr"})
+
+	w.syncMergedPlans()
+	if exists, _ := g.BranchExists("feat/merged"); exists {
+		t.Error("local branch of the merged plan should be deleted")
+	}
+	if exists, _ := g.BranchExists("feat/open"); !exists {
+		t.Error("local branch of the open plan should be kept")
+	}
+	remote := git.NewGit(remoteDir)
+	if exists, _ := remote.BranchExists("feat/merged"); exists {
+		t.Error("remote branch of the merged plan should be deleted")
+	}
+	if exists, _ := remote.BranchExists("feat/open"); !exists {
+		t.Error("remote branch of the open plan should be kept")
+	}
+
+	// Once its branch is gone, a merged plan isn't checked again
+	w.lastMergeCheck = w.lastMergeCheck.Add(-mergeCheckInterval)
+	checked = nil
+	w.syncMergedPlans()
+	if len(checked) != 1 || checked[0] != "feat/open" {
+		t.Errorf("checked = %v, want only feat/open", checked)
+	}
+}
+
+func TestWorker_SyncMergedPlans_Disabled(t *testing.T) {
+	queue, _, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "complete", "merged.md"), []byte("# Plan\n"), 0644)
+
+	oldState := ghPRState
+	defer func() { ghPRState = oldState }()
+	ghPRState = func(dir, branch string) (string, error) {
+		t.Errorf("ghPRState(%s) called without trackers or delete_branch_on_merge", branch)
+		return "MERGED", nil
+	}
+
+	w := NewWorker(WorkerConfig{Queue: queue, Config: config.Defaults(), Git: &mockGitForMerge{}, CompletionMode: "pr"})
+	w.syncMergedPlans()
+}
+
+func TestWorker_SyncMergedPlans_NonGitHubRemote(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := runGitInit(repoDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	for _, args := range [][]string{
+		{"-C", repoDir, "remote", "add", "origin", "git@gitlab.com:acme/app.git"},
+		{"-C", repoDir, "branch", "feat/merged"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	queue, _, queueDir := setupControlTest(t)
+	os.WriteFile(filepath.Join(queueDir, "complete", "merged.md"), []byte("# Plan\n"), 0644)
+
+	oldState := ghPRState
+	defer func() { ghPRState = oldState }()
+	ghPRState = func(dir, branch string) (string, error) {
+		t.Errorf("ghPRState(%s) called for a GitLab remote", branch)
+		return "MERGED", nil
+	}
+
+	cfg := config.Defaults()
+	cfg.Git.DeleteBranchOnMerge = true
+	w := NewWorker(WorkerConfig{Queue: queue, Config: cfg, Git: git.NewGit(repoDir), MainWorktreePath: repoDir, CompletionMode: "pr"})
+	w.syncMergedPlans()
+}
+
+func TestDeleteRemoteBranch(t *testing.T) {
+	mock := &mockGitForMerge{}
+	deleteRemoteBranch(mock, "feat/x")
+	if mock.deletedRemoteBranch != "feat/x" || mock.prunedRemote != "origin" {
+		t.Errorf("deleted %q, pruned %q; want feat/x on origin", mock.deletedRemoteBranch, mock.prunedRemote)
+	}
+
+	// The branch may already be gone
+	deleteRemoteBranch(&mockGitForMerge{deleteRemoteError: errors.New("remote ref does not exist")}, "feat/x")
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// Observer is notified as the worker processes plans, so programs embedding
+// ralph (custom orchestrators, tests) can follow along. Methods are called
+// synchronously from the worker's goroutine and should return quickly.
+// Embed NopObserver to implement only some of them.
+type Observer interface {
+	// PlanStarted is called when the worker picks up a plan.
+	PlanStarted(p *plan.Plan)
+
+	// IterationFinished is called after each iteration of the loop.
+	// result is nil if the iteration was resumed from a checkpoint without one.
+	IterationFinished(p *plan.Plan, iteration int, result *runner.Result)
+
+	// BlockerDetected is called when an iteration reports a blocker.
+	BlockerDetected(p *plan.Plan, blocker *runner.Blocker)
+
+	// PlanCompleted is called when a plan completes successfully.
+	PlanCompleted(p *plan.Plan, result *runner.LoopResult)
+
+	// PlanFailed is called when a plan fails.
+	PlanFailed(p *plan.Plan, err error)
+
+	// EventRecorded is called with every event the worker records (see the
+	// events.Type* constants), including stage changes, verification
+	// results, and worktree repairs.
+	EventRecorded(e events.Event)
+}
+
+// NopObserver implements Observer with methods that do nothing.
+type NopObserver struct{}
+
+func (NopObserver) PlanStarted(*plan.Plan)                            {}
+func (NopObserver) IterationFinished(*plan.Plan, int, *runner.Result) {}
+func (NopObserver) BlockerDetected(*plan.Plan, *runner.Blocker)       {}
+func (NopObserver) PlanCompleted(*plan.Plan, *runner.LoopResult)      {}
+func (NopObserver) PlanFailed(*plan.Plan, error)                      {}
+func (NopObserver) EventRecorded(events.Event)                        {}
+
+// Callbacks adapts functions to an Observer. Nil functions are skipped.
+type Callbacks struct {
+	OnPlanStart    func(p *plan.Plan)
+	OnIteration    func(p *plan.Plan, iteration int, result *runner.Result)
+	OnBlocker      func(p *plan.Plan, blocker *runner.Blocker)
+	OnPlanComplete func(p *plan.Plan, result *runner.LoopResult)
+	OnPlanError    func(p *plan.Plan, err error)
+	OnEvent        func(e events.Event)
+}
+
+func (c Callbacks) PlanStarted(p *plan.Plan) {
+	if c.OnPlanStart != nil {
+		c.OnPlanStart(p)
+	}
+}
+
+func (c Callbacks) IterationFinished(p *plan.Plan, iteration int, result *runner.Result) {
+	if c.OnIteration != nil {
+		c.OnIteration(p, iteration, result)
+	}
+}
+
+func (c Callbacks) BlockerDetected(p *plan.Plan, blocker *runner.Blocker) {
+	if c.OnBlocker != nil {
+		c.OnBlocker(p, blocker)
+	}
+}
+
+func (c Callbacks) PlanCompleted(p *plan.Plan, result *runner.LoopResult) {
+	if c.OnPlanComplete != nil {
+		c.OnPlanComplete(p, result)
+	}
+}
+
+func (c Callbacks) PlanFailed(p *plan.Plan, err error) {
+	if c.OnPlanError != nil {
+		c.OnPlanError(p, err)
+	}
+}
+
+func (c Callbacks) EventRecorded(e events.Event) {
+	if c.OnEvent != nil {
+		c.OnEvent(e)
+	}
+}
+
+// AddObserver registers o to be notified of the worker's activity. It can
+// be called while the worker runs; o sees events from then on.
+func (w *Worker) AddObserver(o Observer) {
+	w.observersMu.Lock()
+	defer w.observersMu.Unlock()
+	w.observers = append(w.observers, o)
+}
+
+// notify calls fn for each registered observer.
+func (w *Worker) notify(fn func(o Observer)) {
+	w.observersMu.Lock()
+	observers := append([]Observer(nil), w.observers...)
+	w.observersMu.Unlock()
+	for _, o := range observers {
+		fn(o)
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// recordingObserver records the names of the methods called on it.
+type recordingObserver struct {
+	NopObserver
+	calls []string
+}
+
+func (r *recordingObserver) PlanStarted(*plan.Plan) {
+	r.calls = append(r.calls, "PlanStarted")
+}
+
+func (r *recordingObserver) EventRecorded(e events.Event) {
+	r.calls = append(r.calls, "EventRecorded:"+e.Type)
+}
+
+func TestCallbacks(t *testing.T) {
+	var got []string
+	c := Callbacks{
+		OnPlanStart:    func(*plan.Plan) { got = append(got, "start") },
+		OnIteration:    func(_ *plan.Plan, i int, _ *runner.Result) { got = append(got, "iteration") },
+		OnBlocker:      func(*plan.Plan, *runner.Blocker) { got = append(got, "blocker") },
+		OnPlanComplete: func(*plan.Plan, *runner.LoopResult) { got = append(got, "complete") },
+		OnPlanError:    func(*plan.Plan, error) { got = append(got, "error") },
+		OnEvent:        func(events.Event) { got = append(got, "event") },
+	}
+
+	p := &plan.Plan{Name: "test"}
+	c.PlanStarted(p)
+	c.IterationFinished(p, 1, nil)
+	c.BlockerDetected(p, &runner.Blocker{})
+	c.PlanCompleted(p, &runner.LoopResult{})
+	c.PlanFailed(p, errors.New("boom"))
+	c.EventRecorded(events.Event{})
+
+	want := []string{"start", "iteration", "blocker", "complete", "error", "event"}
+	if len(got) != len(want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("calls[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	// Unset callbacks are skipped
+	var empty Callbacks
+	empty.PlanStarted(p)
+	empty.IterationFinished(p, 1, nil)
+	empty.BlockerDetected(p, nil)
+	empty.PlanCompleted(p, nil)
+	empty.PlanFailed(p, nil)
+	empty.EventRecorded(events.Event{})
+}
+
+func TestWorker_AddObserver(t *testing.T) {
+	first := &recordingObserver{}
+	w := NewWorker(WorkerConfig{
+		Queue:     plan.NewQueue(t.TempDir()),
+		Config:    config.Defaults(),
+		Observers: []Observer{first},
+	})
+	second := &recordingObserver{}
+	w.AddObserver(second)
+
+	p := &plan.Plan{Name: "test"}
+	w.notify(func(o Observer) { o.PlanStarted(p) })
+	w.recordEvent(events.Event{Type: events.TypeIteration, Plan: p.Name})
+
+	for name, o := range map[string]*recordingObserver{"first": first, "second": second} {
+		if len(o.calls) != 2 || o.calls[0] != "PlanStarted" || o.calls[1] != "EventRecorded:"+events.TypeIteration {
+			t.Errorf("%s observer calls = %v", name, o.calls)
+		}
+	}
+}
+
+func TestWorker_RecordEventSetsTime(t *testing.T) {
+	var got events.Event
+	w := NewWorker(WorkerConfig{
+		Queue:     plan.NewQueue(t.TempDir()),
+		Config:    config.Defaults(),
+		Observers: []Observer{Callbacks{OnEvent: func(e events.Event) { got = e }}},
+	})
+
+	w.recordEvent(events.Event{Type: events.TypePlanStarted})
+	if got.Time.IsZero() {
+		t.Error("EventRecorded got an event without a time")
+	}
+}
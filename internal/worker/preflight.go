@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// NewClaudeRunner creates the claude CLI runner from the runner config,
+// applying its tool permissions and MCP servers to every invocation. The MCP
+// servers are written to .ralph/worktrees/.mcp.json, outside every worktree,
+// so their credentials are never committed. Unless runner.skip_preflight is
+// set, it first checks that claude is installed, within the supported
+// version range, and authenticated (along with completion.second_verifier's
+// executable, if set), so a broken setup fails before any plan is activated.
+func NewClaudeRunner(ctx context.Context, cfg *config.Config, configDir string) (*runner.CLIRunner, error) {
+	claudeRunner := runner.NewCLIRunner()
+	claudeRunner.SetBinary(cfg.Runner.ClaudePath)
+	claudeRunner.SetPermissions(runner.Permissions{
+		AllowedTools:    cfg.Runner.AllowedTools,
+		DisallowedTools: cfg.Runner.DisallowedTools,
+		PermissionMode:  cfg.Runner.PermissionMode,
+	})
+	claudeRunner.SetOrphanProcesses(cfg.Runner.OrphanProcesses)
+	if len(cfg.Runner.MCPServers) > 0 {
+		mcpPath, err := filepath.Abs(filepath.Join(configDir, "worktrees", runner.MCPConfigFile))
+		if err != nil {
+			return nil, err
+		}
+		if err := runner.WriteMCPConfig(mcpPath, cfg.Runner.MCPServers); err != nil {
+			return nil, err
+		}
+		log.Debug("MCP servers written to %s", mcpPath)
+		claudeRunner.SetMCPConfig(mcpPath)
+	}
+
+	if cfg.Runner.SkipPreflight {
+		log.Debug("Skipping claude preflight (runner.skip_preflight)")
+		return claudeRunner, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	log.Info("Checking claude CLI...")
+	result, err := runner.Preflight(ctx, runner.PreflightOptions{
+		Binary:     cfg.Runner.ClaudePath,
+		MinVersion: cfg.Runner.MinClaudeVersion,
+		MaxVersion: cfg.Runner.MaxClaudeVersion,
+		CheckAuth:  true,
+		Model:      cfg.Completion.VerificationModel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info("claude %s (%s)", result.Version, result.Path)
+	claudeRunner.SetBinary(result.Path)
+
+	// A second verifier with its own executable is checked the same way
+	if second := cfg.Completion.SecondVerifier; second.ClaudePath != "" {
+		secondResult, err := runner.Preflight(ctx, runner.PreflightOptions{
+			Binary:     second.ClaudePath,
+			MinVersion: cfg.Runner.MinClaudeVersion,
+			MaxVersion: cfg.Runner.MaxClaudeVersion,
+			CheckAuth:  true,
+			Model:      second.Model,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("completion.second_verifier: %w", err)
+		}
+		log.Info("Second verifier: claude %s (%s)", secondResult.Version, secondResult.Path)
+	}
+	return claudeRunner, nil
+}
+
+// ApplyPresetEnv sets the environment of the repo's worktree preset (see
+// worktree.preset) for every claude run, so the agent's builds use the same
+// shared caches as the init hooks.
+func ApplyPresetEnv(claudeRunner *runner.CLIRunner, cfg *config.Config, mainWorktreePath string) {
+	preset := worktree.ResolvePreset(mainWorktreePath, cfg, mainWorktreePath)
+	if preset == nil || len(preset.Env) == 0 {
+		return
+	}
+	log.Debug("Using %s preset environment", preset.Name)
+	claudeRunner.SetEnv(preset.Environ(mainWorktreePath))
+}
+
+// EnforceMainRepoCheck runs the worker.main_repo_check preflight: problems
+// with the main worktree (see CheckMainWorktree) are logged, and with "fail"
+// stop the worker unless force is set.
+func EnforceMainRepoCheck(g git.Git, cfg *config.Config, force bool) error {
+	mode := cfg.Worker.MainRepoCheck
+	if mode == config.MainRepoCheckOff {
+		return nil
+	}
+
+	problems, err := CheckMainWorktree(g, cfg.Git.BaseBranch)
+	if err != nil {
+		log.Warn("Skipping main worktree check: %v", err)
+		return nil
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for _, problem := range problems {
+		log.Warn("%s", problem)
+	}
+	if mode != config.MainRepoCheckFail {
+		return nil
+	}
+	if force {
+		log.Warn("Starting anyway (--force)")
+		return nil
+	}
+	return fmt.Errorf("main worktree check failed (%d problem(s)); fix them or rerun with --force", len(problems))
+}
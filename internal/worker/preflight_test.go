@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+func TestNewClaudeRunner_WritesMCPConfig(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".ralph")
+
+	cfg := config.Defaults()
+	cfg.Runner.SkipPreflight = true
+	cfg.Runner.MCPServers = map[string]config.MCPServer{
+		"db": {Command: "npx", Args: []string{"-y", "server-postgres"}},
+	}
+
+	if _, err := NewClaudeRunner(nil, cfg, configDir); err != nil {
+		t.Fatalf("NewClaudeRunner() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "worktrees", runner.MCPConfigFile)); err != nil {
+		t.Errorf("expected MCP config in worktrees dir: %v", err)
+	}
+}
+
+func TestNewClaudeRunner_NoMCPServers(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), ".ralph")
+
+	cfg := config.Defaults()
+	cfg.Runner.SkipPreflight = true
+
+	if _, err := NewClaudeRunner(nil, cfg, configDir); err != nil {
+		t.Fatalf("NewClaudeRunner() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "worktrees", runner.MCPConfigFile)); !os.IsNotExist(err) {
+		t.Errorf("expected no MCP config without servers, stat error = %v", err)
+	}
+}
+
+func TestEnforceMainRepoCheck(t *testing.T) {
+	cfg := config.Defaults()
+	g := &mockGitForMainRepo{status: &git.Status{Branch: "feat/wip"}}
+
+	// warn (the default) never stops the worker
+	if err := EnforceMainRepoCheck(g, cfg, false); err != nil {
+		t.Errorf("EnforceMainRepoCheck(warn) error = %v", err)
+	}
+
+	cfg.Worker.MainRepoCheck = config.MainRepoCheckFail
+	if err := EnforceMainRepoCheck(g, cfg, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("EnforceMainRepoCheck(fail) error = %v, want a failure mentioning --force", err)
+	}
+	if err := EnforceMainRepoCheck(g, cfg, true); err != nil {
+		t.Errorf("EnforceMainRepoCheck(fail, --force) error = %v", err)
+	}
+
+	cfg.Git.BaseBranch = "feat/wip"
+	if err := EnforceMainRepoCheck(g, cfg, false); err != nil {
+		t.Errorf("EnforceMainRepoCheck() on the base branch error = %v", err)
+	}
+}
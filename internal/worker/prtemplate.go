@@ -0,0 +1,167 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// prTemplatePaths are where GitHub looks for a pull request template.
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+var (
+	// prHeadingRegex matches a markdown heading in a pull request template.
+	prHeadingRegex = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+
+	// prCheckboxRegex matches a checklist item.
+	prCheckboxRegex = regexp.MustCompile(`^\s*[-*] \[[ xX]\]`)
+
+	// htmlCommentRegex matches HTML comments, which templates use for guidance.
+	htmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Section kinds ralph fills in.
+const (
+	prSectionOther = iota
+	prSectionDescription
+	prSectionTesting
+)
+
+// findPRTemplate returns the repository's pull request template, or "" if it
+// has none.
+func findPRTemplate(repoDir string) string {
+	for _, path := range prTemplatePaths {
+		data, err := os.ReadFile(filepath.Join(repoDir, path))
+		if err == nil && strings.TrimSpace(string(data)) != "" {
+			log.Debug("Using pull request template %s", path)
+			return string(data)
+		}
+	}
+	return ""
+}
+
+// fillPRTemplate fills in a pull request template for the plan. Description
+// sections ("Description", "Summary", "What changed", ...) get the plan
+// summary and changes ledger, and testing sections ("Testing", "How was
+// this tested?", ...) what the progress log says about verification and
+// tests. Their guidance text is replaced. Checklists and other sections are
+// left for the reviewer as they are; if the template has no description
+// section, the summary goes first.
+func fillPRTemplate(tmpl string, p *plan.Plan) string {
+	lines := strings.Split(strings.TrimRight(tmpl, "\n"), "\n")
+
+	var out []string
+	var section []string
+	kind := prSectionOther
+	described := false
+	flush := func() {
+		switch {
+		case kind == prSectionDescription && !isChecklist(section):
+			out = append(out, "", strings.TrimRight(prDescription(p), "\n"), "")
+			described = true
+		case kind == prSectionTesting && !isChecklist(section):
+			out = append(out, "", strings.TrimRight(prTesting(p), "\n"), "")
+		default:
+			out = append(out, section...)
+		}
+		section = nil
+	}
+
+	inComment := false
+	for _, line := range lines {
+		// Headings inside guidance comments don't start sections
+		if m := prHeadingRegex.FindStringSubmatch(line); m != nil && !inComment {
+			flush()
+			out = append(out, line)
+			kind = prSectionKind(m[1])
+			continue
+		}
+		if strings.Contains(line, "<!--") {
+			inComment = true
+		}
+		if strings.Contains(line, "-->") {
+			inComment = false
+		}
+		section = append(section, line)
+	}
+	flush()
+
+	body := strings.Join(out, "\n")
+	if !described {
+		body = strings.TrimRight(prDescription(p), "\n") + "\n\n" + body
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + prFooter
+}
+
+// prSectionKind classifies a template section by its heading.
+func prSectionKind(heading string) int {
+	h := strings.ToLower(heading)
+	for _, word := range []string{"test", "verif", "qa", "how to review"} {
+		if strings.Contains(h, word) {
+			return prSectionTesting
+		}
+	}
+	for _, word := range []string{"descri", "summary", "what", "why", "overview", "change", "context", "motivation"} {
+		if strings.Contains(h, word) {
+			return prSectionDescription
+		}
+	}
+	return prSectionOther
+}
+
+// isChecklist reports whether a section's text is a checklist, which is
+// kept for the reviewer rather than filled in.
+func isChecklist(section []string) bool {
+	text := htmlCommentRegex.ReplaceAllString(strings.Join(section, "\n"), "")
+	for _, line := range strings.Split(text, "\n") {
+		if prCheckboxRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// prDescription is the body of a template's description section.
+func prDescription(p *plan.Plan) string {
+	return prSummary(p) + prChangeList(p)
+}
+
+// prTesting is the body of a template's testing section: how many
+// iterations it took, and the progress notes about tests.
+func prTesting(p *plan.Plan) string {
+	var sb strings.Builder
+	progress, err := plan.LoadProgress(p)
+	if err != nil || len(progress.Entries) == 0 {
+		sb.WriteString("Ralph verified the plan complete.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Ralph verified the plan complete after %d iterations.\n", len(progress.Entries)))
+	var notes []string
+	for _, e := range progress.Entries {
+		if e.Completed != "" && strings.Contains(strings.ToLower(e.Completed), "test") {
+			notes = append(notes, fmt.Sprintf("- Iteration %d: %s", e.Iteration, oneLine(e.Completed)))
+		}
+	}
+	if len(notes) > 0 {
+		sb.WriteString("\n" + strings.Join(notes, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// oneLine joins text's lines with spaces.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+const testPRTemplate = `<!-- Thanks for contributing! -->
+
+## Description
+
+<!-- What does this PR do and why? -->
+
+## How Has This Been Tested?
+
+Describe the tests you ran.
+
+## Checklist
+
+- [ ] I have updated the docs
+- [ ] I have added tests
+
+## Screenshots
+`
+
+// newTemplatePlan returns a plan with a task list, a changes ledger, and
+// two progress entries.
+func newTemplatePlan(t *testing.T) *plan.Plan {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "add-login.md")
+	os.WriteFile(path, []byte("# Plan: Add login\n\n- [x] Form\n- [x] Session\n"), 0644)
+	p, err := plan.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "login.go", Change: plan.ChangeCreated}})
+	plan.AppendIteration(p, plan.ProgressEntry{Iteration: 1, Completed: "Built the login form"})
+	plan.AppendIteration(p, plan.ProgressEntry{Iteration: 2, Completed: "Added tests for\nsession expiry"})
+	return p
+}
+
+func TestFillPRTemplate(t *testing.T) {
+	body := fillPRTemplate(testPRTemplate, newTemplatePlan(t))
+
+	for _, want := range []string{
+		"<!-- Thanks for contributing! -->\n\n## Description\n\nImplements: add-login\n\nTasks completed: 2/2\n\n1 file changed: 1 created\n\n- `login.go` (created)\n\n## How Has This Been Tested?",
+		"## How Has This Been Tested?\n\nRalph verified the plan complete after 2 iterations.\n\n- Iteration 2: Added tests for session expiry\n\n## Checklist",
+		"## Checklist\n\n- [ ] I have updated the docs\n- [ ] I have added tests\n\n## Screenshots",
+		"Generated by [Ralph]",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	for _, guidance := range []string{"What does this PR do", "Describe the tests you ran"} {
+		if strings.Contains(body, guidance) {
+			t.Errorf("body should replace the guidance %q:\n%s", guidance, body)
+		}
+	}
+}
+
+func TestFillPRTemplate_NoDescription(t *testing.T) {
+	body := fillPRTemplate("## Checklist\n\n- [ ] Reviewed\n", newTemplatePlan(t))
+	if !strings.HasPrefix(body, "Implements: add-login\n") || !strings.Contains(body, "## Checklist\n\n- [ ] Reviewed\n") {
+		t.Errorf("body = %q, want the summary before the template", body)
+	}
+}
+
+func TestPRSectionKind(t *testing.T) {
+	tests := map[string]int{
+		"Description":            prSectionDescription,
+		"What changed?":          prSectionDescription,
+		"Summary of changes":     prSectionDescription,
+		"Testing":                prSectionTesting,
+		"How was this verified?": prSectionTesting,
+		"Test plan":              prSectionTesting,
+		"Screenshots":            prSectionOther,
+		"Related issues":         prSectionOther,
+	}
+	for heading, want := range tests {
+		if got := prSectionKind(heading); got != want {
+			t.Errorf("prSectionKind(%q) = %d, want %d", heading, got, want)
+		}
+	}
+}
+
+func TestFindPRTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if got := findPRTemplate(dir); got != "" {
+		t.Errorf("findPRTemplate() without a template = %q", got)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "pull_request_template.md"), []byte("## Docs template\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".github"), 0755)
+	os.WriteFile(filepath.Join(dir, ".github", "PULL_REQUEST_TEMPLATE.md"), []byte("## GitHub template\n"), 0644)
+	if got := findPRTemplate(dir); got != "## GitHub template\n" {
+		t.Errorf("findPRTemplate() = %q, want the .github template first", got)
+	}
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// DefaultRetryBackoff is the delay before the first automatic plan retry
+// when worker.retry_backoff is unset or invalid.
+const DefaultRetryBackoff = 5 * time.Minute
+
+// maxRetryBackoff caps the delay between automatic plan retries.
+const maxRetryBackoff = 6 * time.Hour
+
+// isTransient reports whether a plan failure may succeed if the plan runs
+// again later: rate limits, network errors, and timeouts. Running out of
+// iterations or time, an unresolved blocker, and errors marked non-retryable
+// are not transient.
+func isTransient(err error) bool {
+	if errors.Is(err, runner.ErrMaxIterations) || errors.Is(err, runner.ErrBlockerUnresolved) || errors.Is(err, runner.ErrPlanTimeout) {
+		return false
+	}
+	return runner.IsRetryable(err)
+}
+
+// retryDelay returns the backoff before the given retry attempt (1-based):
+// the configured backoff, doubled for each earlier attempt.
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// planRetries returns the configured number of automatic retries per plan.
+func (w *Worker) planRetries() int {
+	if w.config == nil {
+		return 0
+	}
+	return w.config.Worker.PlanRetries
+}
+
+// retryBackoff returns the configured delay before the first automatic retry.
+func (w *Worker) retryBackoff() time.Duration {
+	if w.config != nil && w.config.Worker.RetryBackoff != "" {
+		if d, err := time.ParseDuration(w.config.Worker.RetryBackoff); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultRetryBackoff
+}
+
+// scheduleRetry returns a transiently failed plan to pending if it has
+// retries left, keeping its worktree so the next run resumes the work.
+// Returns false if the plan should fail instead.
+func (w *Worker) scheduleRetry(p *plan.Plan, cause error) (bool, error) {
+	if w.control == nil || !isTransient(cause) {
+		return false, nil
+	}
+	attempt := 1
+	if r := w.control.Retry(p.Name); r != nil {
+		attempt = r.Attempts + 1
+	}
+	if attempt > w.planRetries() {
+		return false, nil
+	}
+
+	delay := retryDelay(w.retryBackoff(), attempt)
+	if _, err := w.control.RecordRetry(p.Name, cause.Error(), time.Now().Add(delay)); err != nil {
+		return false, fmt.Errorf("recording retry: %w", err)
+	}
+	if err := w.queue.Reset(p); err != nil {
+		return false, fmt.Errorf("requeueing plan: %w", err)
+	}
+
+	message := fmt.Sprintf("retry %d/%d in %s: %v", attempt, w.planRetries(), delay, cause)
+	w.recordEvent(events.Event{Type: events.TypePlanRetry, Plan: p.Name, Message: message})
+	w.mirrorState(p, "", "requeued for retry")
+	log.Warn("Plan %s failed transiently, %s", p.Name, message)
+	w.refreshHome()
+	return true, nil
+}
+
+// retryPending reports whether a pending plan is waiting out its retry backoff.
+func (w *Worker) retryPending(p *plan.Plan) bool {
+	if w.control == nil {
+		return false
+	}
+	r := w.control.Retry(p.Name)
+	return r != nil && time.Now().Before(r.NotBefore)
+}
+
+// clearRetry drops a plan's retry state once it completes or fails for good.
+func (w *Worker) clearRetry(p *plan.Plan) {
+	if w.control == nil || w.control.Retry(p.Name) == nil {
+		return
+	}
+	if err := w.control.ClearRetry(p.Name); err != nil {
+		log.Debug("Failed to clear retry state: %v", err)
+	}
+}
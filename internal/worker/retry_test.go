@@ -0,0 +1,218 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit", errors.New("rate limit exceeded"), true},
+		{"timeout", runner.ErrTimeout, true},
+		{"max iterations", fmt.Errorf("%w (30)", runner.ErrMaxIterations), false},
+		{"unresolved blocker", fmt.Errorf("%w for 24h0m0s: rate limit on the vendor API", runner.ErrBlockerUnresolved), false},
+		{"plan timeout", fmt.Errorf("%w: running for 6h2m0s, limit 6h0m0s", runner.ErrPlanTimeout), false},
+		{"non-retryable", runner.WrapNonRetryable(errors.New("rate limit exceeded")), false},
+		{"other", errors.New("claude exited with code 1"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 5 * time.Minute},
+		{2, 10 * time.Minute},
+		{3, 20 * time.Minute},
+		{20, maxRetryBackoff},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(5*time.Minute, tt.attempt); got != tt.want {
+			t.Errorf("retryDelay(5m, %d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestWorker_RunOnce_RetriesTransientFailure(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	g := git.NewGit(tmpDir)
+	if err := runGitInit(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	queueDir := filepath.Join(tmpDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(queueDir, "pending", "flaky.md"), []byte("# Flaky\n\n## Tasks\n\n- [ ] Task 1\n"), 0644)
+	g.Add("plans/pending/flaky.md")
+	if err := g.Commit("Initial commit"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	manager, err := worktree.NewManager(g, filepath.Join(tmpDir, ".ralph", "worktrees"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	// The API is rate limited on every run
+	mockRunner := &MockRunner{
+		RunFunc: func(ctx context.Context, p string, opts runner.Options) (*runner.Result, error) {
+			return nil, errors.New("rate limit exceeded")
+		},
+	}
+
+	cfg := config.Defaults()
+	cfg.Git.BaseBranch = "main"
+	cfg.Worker.PlanRetries = 1
+	cfg.Worker.RetryBackoff = "1h"
+	queue := plan.NewQueue(queueDir)
+	store := control.NewStore(filepath.Join(tmpDir, ".ralph", "control.json"))
+	eventLog := events.NewLog(filepath.Join(tmpDir, ".ralph", "events.jsonl"))
+
+	w := NewWorker(WorkerConfig{
+		Queue:            queue,
+		Config:           cfg,
+		ConfigDir:        filepath.Join(tmpDir, ".ralph"),
+		WorktreeManager:  manager,
+		Git:              g,
+		MainWorktreePath: tmpDir,
+		Runner:           mockRunner,
+		PromptBuilder:    prompt.NewBuilder(cfg, tmpDir, ""),
+		Control:          store,
+		Events:           eventLog,
+		MaxIterations:    3,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// First failure: requeued with backoff
+	if err := w.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce() should return the transient error")
+	}
+	if _, err := os.Stat(filepath.Join(queueDir, "pending", "flaky.md")); err != nil {
+		t.Fatalf("plan should be back in pending/: %v", err)
+	}
+	retry := store.Retry("flaky")
+	if retry == nil || retry.Attempts != 1 || time.Until(retry.NotBefore) < 50*time.Minute {
+		t.Fatalf("retry state = %+v", retry)
+	}
+	if ev, _ := eventLog.Last(events.TypePlanRetry); ev == nil || ev.Plan != "flaky" {
+		t.Errorf("plan_retry event = %+v", ev)
+	}
+
+	// The plan sits out its backoff
+	if err := w.RunOnce(ctx); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatalf("RunOnce() during backoff error = %v, want ErrQueueEmpty", err)
+	}
+
+	// Backoff elapsed: the second failure exhausts the retries
+	if _, err := store.RecordRetry("flaky", "rate limit exceeded", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("RecordRetry() error = %v", err)
+	}
+	if err := w.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce() should return the transient error")
+	}
+	if _, err := os.Stat(filepath.Join(queueDir, "failed", "flaky.md")); err != nil {
+		t.Errorf("plan should be in failed/ once retries run out: %v", err)
+	}
+	if store.Retry("flaky") != nil {
+		t.Error("retry state should be cleared when the plan fails")
+	}
+}
+
+func TestWorker_RunOnce_NonTransientFailsImmediately(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	g := git.NewGit(tmpDir)
+	if err := runGitInit(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	queueDir := filepath.Join(tmpDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(queueDir, "pending", "broken.md"), []byte("# Broken\n\n## Tasks\n\n- [ ] Task 1\n"), 0644)
+	g.Add("plans/pending/broken.md")
+	if err := g.Commit("Initial commit"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	manager, err := worktree.NewManager(g, filepath.Join(tmpDir, ".ralph", "worktrees"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	mockRunner := &MockRunner{
+		RunFunc: func(ctx context.Context, p string, opts runner.Options) (*runner.Result, error) {
+			return nil, runner.WrapNonRetryable(errors.New("claude exited with code 1: invalid flag"))
+		},
+	}
+
+	cfg := config.Defaults()
+	cfg.Git.BaseBranch = "main"
+	cfg.Worker.PlanRetries = 3
+	queue := plan.NewQueue(queueDir)
+	store := control.NewStore(filepath.Join(tmpDir, ".ralph", "control.json"))
+
+	w := NewWorker(WorkerConfig{
+		Queue:            queue,
+		Config:           cfg,
+		ConfigDir:        filepath.Join(tmpDir, ".ralph"),
+		WorktreeManager:  manager,
+		Git:              g,
+		MainWorktreePath: tmpDir,
+		Runner:           mockRunner,
+		PromptBuilder:    prompt.NewBuilder(cfg, tmpDir, ""),
+		Control:          store,
+		MaxIterations:    3,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := w.RunOnce(ctx); err == nil {
+		t.Fatal("RunOnce() should return the error")
+	}
+	if _, err := os.Stat(filepath.Join(queueDir, "failed", "broken.md")); err != nil {
+		t.Errorf("non-transient failure should go straight to failed/: %v", err)
+	}
+	if store.Retry("broken") != nil {
+		t.Error("non-transient failure should not schedule a retry")
+	}
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// maxOverlapPaths is how many overlapping paths Overlap.String lists.
+const maxOverlapPaths = 3
+
+// Overlap is a pending plan held back because the paths it is likely to
+// touch overlap those of a plan whose branch isn't merged yet.
+type Overlap struct {
+	// Plan is the name of the held-back plan.
+	Plan string
+
+	// With is the name of the plan with the unmerged branch.
+	With string
+
+	// Branch is the unmerged branch.
+	Branch string
+
+	// Paths are the overlapping paths.
+	Paths []string
+}
+
+// String describes the overlap for display, e.g.
+// "waiting for feat/auth to merge (overlaps internal/auth/, README.md)".
+func (o Overlap) String() string {
+	paths := o.Paths
+	more := ""
+	if len(paths) > maxOverlapPaths {
+		more = fmt.Sprintf(" and %d more", len(paths)-maxOverlapPaths)
+		paths = paths[:maxOverlapPaths]
+	}
+	return fmt.Sprintf("waiting for %s to merge (overlaps %s%s)", o.Branch, strings.Join(paths, ", "), more)
+}
+
+// FindOverlaps returns the pending plans that should wait, keyed by plan
+// name. A plan's branch is unmerged if it exists and isn't contained in the
+// base branch or origin/<base>; plans in complete/ (open pull requests) and
+// pending plans that were started before can have one. A pending plan that
+// hasn't been started waits if its estimated paths (plan.EstimatePaths)
+// overlap those of a plan with an unmerged branch. Started plans never wait,
+// so two of them can't hold each other back.
+func FindOverlaps(q *plan.Queue, g git.Git, baseBranch string) (map[string]*Overlap, error) {
+	pending, err := q.Pending()
+	if err != nil {
+		return nil, fmt.Errorf("listing pending plans: %w", err)
+	}
+	complete, err := q.Completed()
+	if err != nil {
+		return nil, fmt.Errorf("listing complete plans: %w", err)
+	}
+
+	type inFlight struct {
+		plan  *plan.Plan
+		paths []string
+	}
+	var active []inFlight
+	started := make(map[string]bool)
+	for _, p := range append(pending, complete...) {
+		if !unmerged(g, p.Branch, baseBranch) {
+			continue
+		}
+		started[p.Name] = true
+		if paths := plan.EstimatePaths(p); len(paths) > 0 {
+			active = append(active, inFlight{plan: p, paths: paths})
+		}
+	}
+
+	overlaps := make(map[string]*Overlap)
+	for _, p := range pending {
+		if started[p.Name] || len(active) == 0 {
+			continue
+		}
+		paths := plan.EstimatePaths(p)
+		for _, a := range active {
+			if shared := plan.OverlappingPaths(paths, a.paths); len(shared) > 0 {
+				overlaps[p.Name] = &Overlap{Plan: p.Name, With: a.plan.Name, Branch: a.plan.Branch, Paths: shared}
+				break
+			}
+		}
+	}
+	return overlaps, nil
+}
+
+// unmerged reports whether branch exists and has commits that are on
+// neither the base branch nor origin/<base>. Returns false if that can't be
+// determined, so a missing base branch doesn't hold plans back.
+func unmerged(g git.Git, branch, baseBranch string) bool {
+	if exists, err := g.BranchExists(branch); err != nil || !exists {
+		return false
+	}
+	merged, err := g.IsAncestor(branch, baseBranch)
+	if err != nil {
+		log.Debug("Can't tell whether %s is merged into %s: %v", branch, baseBranch, err)
+		return false
+	}
+	if merged {
+		return false
+	}
+	if merged, err := g.IsAncestor(branch, "origin/"+baseBranch); err == nil && merged {
+		return false
+	}
+	return true
+}
+
+// overlaps returns the pending plans held back by worker.avoid_overlap, or
+// nil if it is off. Errors are logged and hold nothing back.
+func (w *Worker) overlaps() map[string]*Overlap {
+	if w.config == nil || !w.config.Worker.AvoidOverlap || w.git == nil {
+		return nil
+	}
+	overlaps, err := FindOverlaps(w.queue, w.git, w.config.Git.BaseBranch)
+	if err != nil {
+		log.Warn("Checking plan overlaps: %v", err)
+		return nil
+	}
+	return overlaps
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// setupOverlapTest creates a repository with a completed plan "auth" whose
+// branch feat/auth is unmerged and scoped to internal/auth/.
+func setupOverlapTest(t *testing.T) (*plan.Queue, git.Git, string) {
+	t.Helper()
+	repoDir := t.TempDir()
+	if err := runGitInit(repoDir); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	queueDir := filepath.Join(repoDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(queueDir, "complete", "auth.md"), []byte("# Plan: Auth\n**Scope:** internal/auth/\n"), 0644)
+
+	g := git.NewGit(repoDir)
+	if err := gitCommand(g.WorkDir(), "checkout", "-q", "-b", "feat/auth").Run(); err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(repoDir, "internal", "auth"), 0755)
+	os.WriteFile(filepath.Join(repoDir, "internal", "auth", "token.go"), []byte("package auth\n"), 0644)
+	if err := g.Commit("Add token", "internal/auth/token.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("main"); err != nil {
+		t.Fatal(err)
+	}
+	return plan.NewQueue(queueDir), g, queueDir
+}
+
+func TestFindOverlaps(t *testing.T) {
+	queue, g, queueDir := setupOverlapTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "login.md"), []byte("# Plan: Login\n- [ ] Use `internal/auth/token.go`\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "pending", "docs.md"), []byte("# Plan: Docs\n- [ ] Update `README.md`\n"), 0644)
+
+	overlaps, err := FindOverlaps(queue, g, "main")
+	if err != nil {
+		t.Fatalf("FindOverlaps() error = %v", err)
+	}
+	if len(overlaps) != 1 || overlaps["login"] == nil {
+		t.Fatalf("expected only login to be held back, got %v", overlaps)
+	}
+	o := overlaps["login"]
+	if o.With != "auth" || o.Branch != "feat/auth" || len(o.Paths) != 1 || o.Paths[0] != "internal/auth/token.go" {
+		t.Errorf("unexpected overlap %+v", o)
+	}
+	if got := o.String(); !strings.Contains(got, "waiting for feat/auth to merge") {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Once the branch is merged nothing waits
+	if err := g.Merge("feat/auth", false); err != nil {
+		t.Fatal(err)
+	}
+	overlaps, err = FindOverlaps(queue, g, "main")
+	if err != nil {
+		t.Fatalf("FindOverlaps() error = %v", err)
+	}
+	if len(overlaps) != 0 {
+		t.Errorf("expected no overlaps after merge, got %v", overlaps)
+	}
+}
+
+func TestFindOverlaps_StartedPlansDontWait(t *testing.T) {
+	queue, g, queueDir := setupOverlapTest(t)
+
+	// A pending plan with its own unmerged branch was started before
+	os.WriteFile(filepath.Join(queueDir, "pending", "session.md"), []byte("# Plan: Session\n**Scope:** internal/auth/\n"), 0644)
+	if err := gitCommand(g.WorkDir(), "checkout", "-q", "-b", "feat/session").Run(); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(g.WorkDir(), "session.go"), []byte("package main\n"), 0644)
+	if err := g.Commit("Add session", "session.go"); err != nil {
+		t.Fatal(err)
+	}
+	g.Checkout("main")
+
+	overlaps, err := FindOverlaps(queue, g, "main")
+	if err != nil {
+		t.Fatalf("FindOverlaps() error = %v", err)
+	}
+	if len(overlaps) != 0 {
+		t.Errorf("started plans should never wait, got %v", overlaps)
+	}
+}
+
+func TestOverlap_String(t *testing.T) {
+	o := Overlap{Branch: "feat/auth", Paths: []string{"a.go", "b.go", "c.go", "d.go", "e.go"}}
+	want := "waiting for feat/auth to merge (overlaps a.go, b.go, c.go and 2 more)"
+	if got := o.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestWorker_RunOnce_HoldsBackOverlap(t *testing.T) {
+	queue, g, queueDir := setupOverlapTest(t)
+	os.WriteFile(filepath.Join(queueDir, "pending", "login.md"), []byte("# Plan: Login\n**Scope:** internal/auth/login.go\n"), 0644)
+
+	cfg := config.Defaults()
+	cfg.Worker.AvoidOverlap = true
+	w := NewWorker(WorkerConfig{
+		Queue:  queue,
+		Config: cfg,
+		Git:    g,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+	if current, _ := queue.Current(); current != nil {
+		t.Errorf("overlapping plan should stay pending, got current %s", current.Name)
+	}
+}
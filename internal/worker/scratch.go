@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+// scratchRoot returns the directory holding the plans' scratch directories
+// (worktree.scratch_dir), for the repository .ralph is in.
+func (w *Worker) scratchRoot() string {
+	return worktree.ScratchRoot(w.config, filepath.Dir(w.configDir))
+}
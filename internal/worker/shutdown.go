@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// RecoveryFileName is the name of the recovery marker in the .ralph directory.
+const RecoveryFileName = "recovery.json"
+
+// RecoveryMarker records a plan that was interrupted by a hard stop, so the
+// next worker run knows the in-flight iteration did not finish cleanly.
+type RecoveryMarker struct {
+	// Plan is the name of the interrupted plan.
+	Plan string `json:"plan"`
+
+	// Iteration is the iteration that was in flight.
+	Iteration int `json:"iteration,omitempty"`
+
+	// Reason is why the worker stopped immediately.
+	Reason string `json:"reason"`
+
+	// StoppedAt is when the worker stopped.
+	StoppedAt time.Time `json:"stopped_at"`
+}
+
+// Drain requests a graceful shutdown: the in-flight iteration finishes and
+// its state is synced, then the worker stops. Safe to call more than once.
+func (w *Worker) Drain() {
+	w.drainOnce.Do(func() { close(w.drain) })
+}
+
+// Draining returns true once a graceful shutdown has been requested.
+func (w *Worker) Draining() bool {
+	select {
+	case <-w.drain:
+		return true
+	default:
+		return false
+	}
+}
+
+// HandleSignals installs two-stage shutdown on SIGINT/SIGTERM. The first
+// signal drains the worker; a second signal, or the drain timeout expiring,
+// calls cancel to stop immediately and a recovery marker is written for the
+// interrupted plan. Returns a function that stops signal handling.
+func (w *Worker) HandleSignals(cancel context.CancelFunc) func() {
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go w.handleShutdown(sigCh, done, cancel)
+
+	return func() {
+		signal.Stop(sigCh)
+		close(done)
+	}
+}
+
+// handleShutdown runs the two shutdown stages until done is closed.
+func (w *Worker) handleShutdown(sigCh <-chan os.Signal, done <-chan struct{}, cancel context.CancelFunc) {
+	select {
+	case sig := <-sigCh:
+		log.Warn("Received signal %v, finishing the current iteration (signal again to stop immediately)...", sig)
+		w.Drain()
+	case <-done:
+		return
+	}
+
+	var timeout <-chan time.Time
+	if w.drainTimeout > 0 {
+		timeout = time.After(w.drainTimeout)
+	}
+
+	select {
+	case sig := <-sigCh:
+		log.Warn("Received signal %v again, stopping immediately", sig)
+		w.hardStop("second signal", cancel)
+	case <-timeout:
+		log.Warn("Drain timeout (%v) reached, stopping immediately", w.drainTimeout)
+		w.hardStop("drain timeout", cancel)
+	case <-done:
+	}
+}
+
+// hardStop records why the worker is stopping immediately and cancels it.
+func (w *Worker) hardStop(reason string, cancel context.CancelFunc) {
+	w.shutdownMu.Lock()
+	w.hardStopReason = reason
+	w.shutdownMu.Unlock()
+	cancel()
+}
+
+// hardStopped returns the hard stop reason, or "" if there was none.
+func (w *Worker) hardStopped() string {
+	w.shutdownMu.Lock()
+	defer w.shutdownMu.Unlock()
+	return w.hardStopReason
+}
+
+// recoveryPath returns the recovery marker path, or "" without a config directory.
+func (w *Worker) recoveryPath() string {
+	if w.configDir == "" {
+		return ""
+	}
+	return filepath.Join(w.configDir, RecoveryFileName)
+}
+
+// writeRecoveryMarker records a plan interrupted by a hard stop.
+func (w *Worker) writeRecoveryMarker(p *plan.Plan, iteration int, reason string) {
+	path := w.recoveryPath()
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(RecoveryMarker{
+		Plan:      p.Name,
+		Iteration: iteration,
+		Reason:    reason,
+		StoppedAt: time.Now(),
+	}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		log.Warn("Failed to write recovery marker: %v", err)
+		return
+	}
+	log.Warn("Recovery marker written to %s", path)
+}
+
+// checkRecovery reports and clears a recovery marker left by a hard stop.
+func (w *Worker) checkRecovery() {
+	marker, err := LoadRecoveryMarker(w.configDir)
+	if err != nil {
+		log.Debug("Failed to read recovery marker: %v", err)
+		return
+	}
+	if marker == nil {
+		return
+	}
+
+	resume := "rerunning the interrupted iteration"
+	if w.hasCheckpoint(marker.Plan) {
+		resume = "resuming from its checkpoint"
+	}
+	log.Warn("Plan %s was stopped immediately during iteration %d (%s) at %s; %s",
+		marker.Plan, marker.Iteration, marker.Reason, marker.StoppedAt.Format("2006-01-02 15:04"), resume)
+	if err := os.Remove(w.recoveryPath()); err != nil {
+		log.Debug("Failed to remove recovery marker: %v", err)
+	}
+}
+
+// hasCheckpoint reports whether the named plan's worktree has a checkpoint
+// of an interrupted iteration to resume from.
+func (w *Worker) hasCheckpoint(name string) bool {
+	if w.worktreeManager == nil {
+		return false
+	}
+	_, err := os.Stat(runner.CheckpointPath(w.worktreeManager.Path(&plan.Plan{Name: name})))
+	return err == nil
+}
+
+// LoadRecoveryMarker reads the recovery marker in configDir.
+// Returns nil if there is none.
+func LoadRecoveryMarker(configDir string) (*RecoveryMarker, error) {
+	if configDir == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, RecoveryFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading recovery marker: %w", err)
+	}
+
+	var marker RecoveryMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("parsing recovery marker: %w", err)
+	}
+	return &marker, nil
+}
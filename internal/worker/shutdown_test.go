@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+func TestWorker_HandleShutdown_TwoStage(t *testing.T) {
+	w := NewWorker(WorkerConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		w.handleShutdown(sigCh, done, cancel)
+		close(finished)
+	}()
+
+	// First signal drains without cancelling
+	sigCh <- syscall.SIGINT
+	deadline := time.After(time.Second)
+	for !w.Draining() {
+		select {
+		case <-deadline:
+			t.Fatal("worker not draining after first signal")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if ctx.Err() != nil {
+		t.Fatal("first signal should not cancel the context")
+	}
+
+	// Second signal stops immediately
+	sigCh <- syscall.SIGINT
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("second signal should cancel the context")
+	}
+	<-finished
+	if got := w.hardStopped(); got != "second signal" {
+		t.Errorf("hardStopped() = %q, want %q", got, "second signal")
+	}
+}
+
+func TestWorker_HandleShutdown_DrainTimeout(t *testing.T) {
+	w := NewWorker(WorkerConfig{DrainTimeout: 20 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	sigCh <- syscall.SIGTERM
+	go w.handleShutdown(sigCh, make(chan struct{}), cancel)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("drain timeout should cancel the context")
+	}
+	if got := w.hardStopped(); got != "drain timeout" {
+		t.Errorf("hardStopped() = %q, want %q", got, "drain timeout")
+	}
+}
+
+func TestWorker_Run_StopsWhenDrained(t *testing.T) {
+	queueDir := filepath.Join(t.TempDir(), "plans")
+	os.MkdirAll(filepath.Join(queueDir, "pending"), 0755)
+	os.MkdirAll(filepath.Join(queueDir, "current"), 0755)
+
+	w := NewWorker(WorkerConfig{
+		Queue:        plan.NewQueue(queueDir),
+		Config:       config.Defaults(),
+		PollInterval: time.Hour,
+	})
+
+	// Drain while the worker is waiting for plans
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		w.Drain()
+		w.Drain() // idempotent
+	}()
+
+	if err := w.Run(context.Background()); err != ErrInterrupted {
+		t.Errorf("Run() error = %v, want %v", err, ErrInterrupted)
+	}
+}
+
+func TestWorker_RecoveryMarker(t *testing.T) {
+	configDir := t.TempDir()
+	w := NewWorker(WorkerConfig{ConfigDir: configDir})
+
+	if marker, err := LoadRecoveryMarker(configDir); marker != nil || err != nil {
+		t.Fatalf("LoadRecoveryMarker() = %+v, %v; want nil", marker, err)
+	}
+
+	w.writeRecoveryMarker(&plan.Plan{Name: "alpha"}, 4, "second signal")
+
+	marker, err := LoadRecoveryMarker(configDir)
+	if err != nil {
+		t.Fatalf("LoadRecoveryMarker() error = %v", err)
+	}
+	if marker.Plan != "alpha" || marker.Iteration != 4 || marker.Reason != "second signal" || marker.StoppedAt.IsZero() {
+		t.Errorf("marker = %+v", marker)
+	}
+
+	// The next run reports and clears the marker
+	w.checkRecovery()
+	if _, err := os.Stat(filepath.Join(configDir, RecoveryFileName)); !os.IsNotExist(err) {
+		t.Error("recovery marker should be removed once reported")
+	}
+}
+
+func TestWorker_HasCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := runGitInit(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	manager, err := worktree.NewManager(git.NewGit(tmpDir), filepath.Join(tmpDir, ".ralph", "worktrees"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	w := &Worker{worktreeManager: manager}
+
+	if w.hasCheckpoint("alpha") {
+		t.Error("hasCheckpoint() = true without a checkpoint")
+	}
+	path := runner.CheckpointPath(manager.Path(&plan.Plan{Name: "alpha"}))
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("{}"), 0644)
+	if !w.hasCheckpoint("alpha") {
+		t.Error("hasCheckpoint() = false with a checkpoint")
+	}
+	if (&Worker{}).hasCheckpoint("alpha") {
+		t.Error("hasCheckpoint() without a worktree manager should be false")
+	}
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// wakeSnoozed wakes the pending plans whose snooze has run out, removing
+// their **Snoozed-Until:** header so the queue shows them as ready again.
+// Failures are logged; an expired snooze doesn't hold a plan back anyway.
+func (w *Worker) wakeSnoozed(pending []*plan.Plan, now time.Time) {
+	for _, p := range pending {
+		if p.SnoozedUntil.IsZero() || p.Snoozed(now) {
+			continue
+		}
+		if err := plan.Wake(p); err != nil {
+			log.Warn("Failed to wake snoozed plan %s: %v", p.Name, err)
+			continue
+		}
+		log.Info("Plan %s woke from snooze", p.Name)
+		w.recordEvent(events.Event{Type: events.TypePlanWoken, Plan: p.Name})
+	}
+}
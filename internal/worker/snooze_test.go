@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestWorker_RunOnce_SkipsSnoozed(t *testing.T) {
+	queue, store, queueDir := setupControlTest(t)
+	wake := time.Now().Add(time.Hour).Format(time.RFC3339)
+	os.WriteFile(filepath.Join(queueDir, "pending", "alpha.md"), []byte("# Plan: Alpha\n**Snoozed-Until:** "+wake+"\n"), 0644)
+
+	w := NewWorker(WorkerConfig{
+		Queue:   queue,
+		Config:  config.Defaults(),
+		Control: store,
+	})
+
+	if err := w.RunOnce(context.Background()); err != ErrQueueEmpty {
+		t.Errorf("RunOnce() error = %v, want %v", err, ErrQueueEmpty)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 1 || pending[0].SnoozedUntil.IsZero() {
+		t.Errorf("snoozed plan should stay pending and snoozed, got %v", pending)
+	}
+}
+
+func TestWorker_WakeSnoozed(t *testing.T) {
+	queue, _, queueDir := setupControlTest(t)
+	now := time.Now()
+	os.WriteFile(filepath.Join(queueDir, "pending", "due.md"), []byte("# Plan: Due\n**Snoozed-Until:** "+now.Add(-time.Minute).Format(time.RFC3339)+"\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "pending", "later.md"), []byte("# Plan: Later\n**Snoozed-Until:** "+now.Add(time.Hour).Format(time.RFC3339)+"\n"), 0644)
+	os.WriteFile(filepath.Join(queueDir, "pending", "awake.md"), []byte("# Plan: Awake\n"), 0644)
+
+	eventLog := events.NewLog(filepath.Join(t.TempDir(), "events.jsonl"))
+	w := &Worker{events: eventLog}
+	pending, _ := queue.Pending()
+	w.wakeSnoozed(pending, now)
+
+	for _, name := range []string{"due", "later", "awake"} {
+		p, err := plan.Load(filepath.Join(queueDir, "pending", name+".md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snoozed := !p.SnoozedUntil.IsZero(); snoozed != (name == "later") {
+			t.Errorf("%s snoozed until %v after waking", name, p.SnoozedUntil)
+		}
+	}
+	evs, _ := eventLog.Since(time.Time{})
+	if len(evs) != 1 || evs[0].Type != events.TypePlanWoken || evs[0].Plan != "due" {
+		t.Errorf("events = %+v, want one plan_woken for due", evs)
+	}
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// mirrorState commits the plan queue to git.state_branch (and pushes it with
+// git.state_push), so teammates can follow the queue on the git host. While
+// a plan runs, its plan and progress files are taken from the worktree at
+// worktreePath (optional), which is ahead of the main copies until the loop
+// syncs back. Failures are logged; they never stop the worker.
+func (w *Worker) mirrorState(p *plan.Plan, worktreePath, message string) {
+	if w.config == nil || w.config.Git.StateBranch == "" || w.git == nil || w.queue == nil {
+		return
+	}
+	branch := w.config.Git.StateBranch
+
+	files, err := stateFiles(w.queue.BaseDir)
+	if err != nil {
+		log.Warn("Failed to mirror the queue to %s: %v", branch, err)
+		return
+	}
+	if p != nil && worktreePath != "" {
+		w.overlayWorktree(files, p, worktreePath)
+	}
+
+	if p != nil {
+		message = p.Name + ": " + message
+	}
+	sha, err := w.git.CommitSnapshot(branch, "ralph: "+message, files)
+	if err != nil {
+		log.Warn("Failed to mirror the queue to %s: %v", branch, err)
+		return
+	}
+	if sha == "" {
+		return
+	}
+	log.Debug("Mirrored the queue to %s at %.8s", branch, sha)
+
+	if w.config.Git.StatePush {
+		if err := w.git.PushWithUpstream("origin", branch); err != nil {
+			log.Warn("Failed to push %s: %v", branch, err)
+		}
+	}
+}
+
+// stateFiles returns the files in the queue directories under plansDir, by
+// their path relative to it ("current/my-plan.progress.md"). Lock files and
+// hidden files are left out.
+func stateFiles(plansDir string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, dir := range plan.QueueDirs {
+		root := filepath.Join(plansDir, dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			name := d.Name()
+			if strings.HasPrefix(name, ".") && path != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !d.Type().IsRegular() || strings.HasSuffix(name, ".lock") {
+				return nil
+			}
+			rel, err := filepath.Rel(plansDir, path)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(rel)] = path
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// overlayWorktree replaces a running plan's plan and progress files with
+// their worktree copies, found the way worktree.SyncFromWorktree finds them.
+func (w *Worker) overlayWorktree(files map[string]string, p *plan.Plan, worktreePath string) {
+	for _, path := range []string{p.Path, plan.ProgressPath(p)} {
+		tree, err := filepath.Rel(w.queue.BaseDir, path)
+		if err != nil || strings.HasPrefix(tree, "..") {
+			continue
+		}
+		rel, err := filepath.Rel(w.mainWorktreePath, path)
+		if err != nil {
+			rel = filepath.Join("plans", "current", filepath.Base(path))
+		}
+		src := filepath.Join(worktreePath, rel)
+		if _, err := os.Stat(src); err == nil {
+			files[filepath.ToSlash(tree)] = src
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// stateRepo creates a git repository with a plans directory and returns its path.
+func stateRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	for _, d := range plan.QueueDirs {
+		os.MkdirAll(filepath.Join(dir, "plans", d), 0755)
+	}
+	return dir
+}
+
+// stateTree returns the files on the state branch and the content of one.
+func stateTree(t *testing.T, dir, branch, file string) ([]string, string) {
+	t.Helper()
+	cmd := exec.Command("git", "ls-tree", "-r", "--name-only", branch)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git ls-tree %s: %v", branch, err)
+	}
+	cmd = exec.Command("git", "show", branch+":"+file)
+	cmd.Dir = dir
+	content, _ := cmd.Output()
+	return strings.Fields(string(out)), string(content)
+}
+
+func TestWorker_MirrorState(t *testing.T) {
+	dir := stateRepo(t)
+	plansDir := filepath.Join(dir, "plans")
+	planPath := filepath.Join(plansDir, "current", "my-plan.md")
+	os.WriteFile(planPath, []byte("# Plan\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, "current", "my-plan.progress.md"), []byte("main progress\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, "pending", "next.md"), []byte("# Next\n"), 0644)
+	os.WriteFile(filepath.Join(plansDir, "pending", "next.md.lock"), []byte("123"), 0644)
+
+	cfg := config.Defaults()
+	w := &Worker{config: cfg, queue: plan.NewQueue(plansDir), git: git.NewGit(dir), mainWorktreePath: dir}
+	p := &plan.Plan{Name: "my-plan", Path: planPath}
+
+	// Off by default
+	w.mirrorState(p, "", "activated")
+	if exists, _ := w.git.BranchExists("ralph-state"); exists {
+		t.Fatal("mirrorState() created a branch without git.state_branch")
+	}
+
+	cfg.Git.StateBranch = "ralph-state"
+	w.mirrorState(p, "", "activated")
+	files, _ := stateTree(t, dir, "ralph-state", "")
+	want := []string{"current/my-plan.md", "current/my-plan.progress.md", "pending/next.md"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("state branch files = %v, want %v", files, want)
+	}
+
+	// While running, the worktree copies are mirrored
+	wt := t.TempDir()
+	os.MkdirAll(filepath.Join(wt, "plans", "current"), 0755)
+	os.WriteFile(filepath.Join(wt, "plans", "current", "my-plan.progress.md"), []byte("worktree progress\n"), 0644)
+	w.mirrorState(p, wt, "iteration 1")
+	if _, content := stateTree(t, dir, "ralph-state", "current/my-plan.progress.md"); content != "worktree progress\n" {
+		t.Errorf("mirrored progress = %q, want the worktree copy", content)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%s", "ralph-state")
+	cmd.Dir = dir
+	if out, _ := cmd.Output(); strings.TrimSpace(string(out)) != "ralph: my-plan: iteration 1" {
+		t.Errorf("state commit message = %q", strings.TrimSpace(string(out)))
+	}
+
+	// The checkout stays on main
+	cmd = exec.Command("git", "symbolic-ref", "--short", "HEAD")
+	cmd.Dir = dir
+	if out, _ := cmd.Output(); strings.TrimSpace(string(out)) != "main" {
+		t.Errorf("HEAD = %q, want main", strings.TrimSpace(string(out)))
+	}
+}
+
+func TestWorker_MirrorState_Push(t *testing.T) {
+	dir := stateRepo(t)
+	remote := t.TempDir()
+	for _, args := range [][]string{{"init", "--bare", remote}, {"-C", dir, "remote", "add", "origin", remote}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "plans", "pending", "next.md"), []byte("# Next\n"), 0644)
+
+	cfg := config.Defaults()
+	cfg.Git.StateBranch = "ralph-state"
+	cfg.Git.StatePush = true
+	w := &Worker{config: cfg, queue: plan.NewQueue(filepath.Join(dir, "plans")), git: git.NewGit(dir), mainWorktreePath: dir}
+	w.mirrorState(nil, "", "queue updated")
+
+	if files, _ := stateTree(t, remote, "ralph-state", ""); len(files) != 1 || files[0] != "pending/next.md" {
+		t.Errorf("pushed state branch files = %v, want [pending/next.md]", files)
+	}
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// acceptanceHeadingRegex matches the heading of a plan's acceptance criteria
+// section ("## Acceptance Criteria", "### Acceptance").
+var acceptanceHeadingRegex = regexp.MustCompile(`(?i)^(#{1,6})\s+acceptance\b`)
+
+// completionSummary renders the <plan>.summary.md written next to a
+// completed plan: how it finished, what it took, what changed, how it did
+// against its acceptance criteria and gates, and the feedback it never got
+// to. outcome describes how the work landed ("pull request opened", "merged
+// into main"). It reads the plan's sidecars, so call it before the plan is
+// moved to complete/.
+func completionSummary(p *plan.Plan, outcome string, c notify.Completion, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Completion Summary: %s\n\n", p.Name))
+
+	status := "complete"
+	if outcome != "" {
+		status += ", " + outcome
+	}
+	sb.WriteString(fmt.Sprintf("**Status:** %s\n", status))
+	sb.WriteString(fmt.Sprintf("**Completed:** %s\n", now.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("**Branch:** `%s`\n", p.Branch))
+	if c.Iterations > 0 {
+		iterations := fmt.Sprintf("%d", c.Iterations)
+		if c.MaxIterations > 0 {
+			iterations += fmt.Sprintf("/%d", c.MaxIterations)
+		}
+		sb.WriteString(fmt.Sprintf("**Iterations:** %s\n", iterations))
+	}
+	if c.Duration > 0 {
+		sb.WriteString(fmt.Sprintf("**Duration:** %s\n", c.Duration.Round(time.Second)))
+	}
+	if c.PRURL != "" {
+		sb.WriteString(fmt.Sprintf("**Pull Request:** %s\n", c.PRURL))
+	}
+	if total := plan.CountTotal(p.Tasks); total > 0 {
+		sb.WriteString(fmt.Sprintf("**Tasks:** %d/%d complete\n", plan.CountComplete(p.Tasks), total))
+	}
+
+	sb.WriteString("\n## Changes\n\n")
+	if changes, err := plan.LoadChanges(p); err == nil && len(changes.Files) > 0 {
+		added, deleted := changes.Lines()
+		sb.WriteString(fmt.Sprintf("%s (+%d -%d lines)\n\n", changes.Summary(), added, deleted))
+		for _, f := range changes.Files {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s, +%d -%d)\n", f.Path, f.Change, f.Added, f.Deleted))
+		}
+	} else {
+		sb.WriteString("No changes recorded.\n")
+	}
+
+	sb.WriteString("\n## Acceptance\n\n")
+	criteria := acceptanceCriteria(p.Content)
+	if len(criteria) == 0 && len(c.Gates) == 0 {
+		sb.WriteString("No acceptance criteria or gates.\n")
+	}
+	for _, line := range criteria {
+		sb.WriteString(line + "\n")
+	}
+	if len(criteria) > 0 && len(c.Gates) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, r := range c.Gates {
+		result := "passed"
+		switch {
+		case !r.Passed:
+			result = "failed"
+		case len(r.Flaky) > 0:
+			result = fmt.Sprintf("passed (flaky: %s)", strings.Join(r.Flaky, ", "))
+		}
+		sb.WriteString(fmt.Sprintf("- Gate %s (`%s`): %s\n", r.Name, r.Command, result))
+		if !r.Passed && r.Output != "" {
+			sb.WriteString(fmt.Sprintf("  %s\n", oneLine(lastLine(r.Output))))
+		}
+	}
+
+	sb.WriteString("\n## Unresolved Feedback\n\n")
+	feedback, err := plan.LoadFeedback(p)
+	if err != nil || len(feedback.Pending) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		for _, e := range feedback.Pending {
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n", e.ID, oneLine(e.Text)))
+		}
+	}
+
+	return sb.String()
+}
+
+// acceptanceCriteria returns the checklist lines of the plan's acceptance
+// criteria section, or nil if it has none.
+func acceptanceCriteria(content string) []string {
+	var criteria []string
+	level := 0
+	for _, line := range strings.Split(content, "\n") {
+		if m := acceptanceHeadingRegex.FindStringSubmatch(line); m != nil {
+			level = len(m[1])
+			continue
+		}
+		if level == 0 {
+			continue
+		}
+		if prHeadingRegex.MatchString(line) && len(line)-len(strings.TrimLeft(line, "#")) <= level {
+			level = 0
+			continue
+		}
+		if prCheckboxRegex.MatchString(line) {
+			criteria = append(criteria, strings.TrimSpace(line))
+		}
+	}
+	return criteria
+}
+
+// lastLine returns the last non-blank line of text.
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return lines[i]
+		}
+	}
+	return ""
+}
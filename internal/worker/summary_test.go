@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/gate"
+	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestCompletionSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "add-login.md")
+	os.WriteFile(path, []byte("# Plan: Add login\n\n- [x] Form\n- [x] Session\n\n## Acceptance Criteria\n\n- [x] Users can log in\n- [ ] Sessions expire\n\n## Notes\n\n- [ ] Not a criterion\n"), 0644)
+	p, err := plan.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan.RecordChanges(p, 1, []plan.FileChange{{Path: "login.go", Change: plan.ChangeCreated, Added: 40}})
+	plan.AppendFeedbackWithTime(p, "slack", "Use bcrypt", time.Date(2024, 1, 30, 14, 32, 0, 0, time.Local))
+
+	c := notify.Completion{
+		PRURL:         "https://github.com/org/repo/pull/7",
+		Iterations:    3,
+		MaxIterations: 30,
+		Duration:      12*time.Minute + 3*time.Second,
+		Gates: []gate.Result{
+			{Name: gate.Test, Command: "go test ./...", Passed: true},
+			{Name: gate.Lint, Command: "golangci-lint run", Output: "lint output\nlogin.go:3: unused x\n"},
+		},
+	}
+	got := completionSummary(p, "pull request opened", c, time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		"# Completion Summary: add-login\n",
+		"**Status:** complete, pull request opened\n",
+		"**Completed:** 2024-01-31 09:00\n",
+		"**Iterations:** 3/30\n",
+		"**Duration:** 12m3s\n",
+		"**Pull Request:** https://github.com/org/repo/pull/7\n",
+		"**Tasks:** 3/5 complete\n",
+		"1 file changed: 1 created (+40 -0 lines)\n",
+		"- `login.go` (created, +40 -0)\n",
+		"- [x] Users can log in\n- [ ] Sessions expire\n",
+		"- Gate test (`go test ./...`): passed\n",
+		"- Gate lint (`golangci-lint run`): failed\n  login.go:3: unused x\n",
+		"- [2024-01-30 14:32:00] slack: Use bcrypt\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Not a criterion") {
+		t.Errorf("summary includes a checklist outside the acceptance criteria:\n%s", got)
+	}
+}
+
+func TestCompletionSummary_Empty(t *testing.T) {
+	p := &plan.Plan{Name: "tiny", Path: filepath.Join(t.TempDir(), "tiny.md"), Branch: "feat/tiny"}
+	got := completionSummary(p, "", notify.Completion{}, time.Now())
+
+	for _, want := range []string{
+		"**Status:** complete\n",
+		"No changes recorded.\n",
+		"No acceptance criteria or gates.\n",
+		"## Unresolved Feedback\n\nNone.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("summary missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "**Pull Request:**") || strings.Contains(got, "**Iterations:**") {
+		t.Errorf("summary includes unset fields:\n%s", got)
+	}
+}
+
+func TestAcceptanceCriteria(t *testing.T) {
+	content := "# Plan\n\n### Acceptance\n- [ ] One\n#### Detail\n- [x] Two\n### Tasks\n- [ ] Task\n"
+	want := []string{"- [ ] One", "- [x] Two"}
+	if got := acceptanceCriteria(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("acceptanceCriteria() = %q, want %q", got, want)
+	}
+	if got := acceptanceCriteria("# Plan\n\n- [ ] Task\n"); got != nil {
+		t.Errorf("acceptanceCriteria() without a section = %q, want nil", got)
+	}
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"errors"
+
+	"github.com/arvesolland/ralph/internal/log"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+
+	// Register the trackers
+	_ "github.com/arvesolland/ralph/internal/github"
+	_ "github.com/arvesolland/ralph/internal/jira"
+	_ "github.com/arvesolland/ralph/internal/linear"
+)
+
+// newTrackers returns the issue trackers and sync state for the worker:
+// cfg.Trackers if set, otherwise every tracker the config enables. Returns
+// nils if there are none.
+func newTrackers(cfg WorkerConfig) ([]tracker.Tracker, *tracker.State) {
+	trackers := cfg.Trackers
+	if trackers == nil && cfg.Config != nil {
+		var err error
+		if trackers, err = tracker.Configured(cfg.Config); err != nil {
+			log.Warn("Issue status sync disabled for some trackers: %v", err)
+		}
+	}
+	if len(trackers) == 0 || cfg.ConfigDir == "" {
+		return nil, nil
+	}
+	state, err := tracker.LoadState(tracker.StatePath(cfg.ConfigDir))
+	if err != nil {
+		log.Warn("Issue status sync disabled: %v", err)
+		return nil, nil
+	}
+	return trackers, state
+}
+
+// syncIssue moves the plan's linked issues to the status configured for
+// stage, links the pull request at prURL and posts comment (if any), unless
+// that stage was already synced. Tracker errors are logged and never fail
+// the plan.
+func (w *Worker) syncIssue(p *plan.Plan, stage, prURL, comment string) {
+	for _, t := range w.trackers {
+		key := t.IssueFor(p)
+		if key == "" || w.trackerState.Stage(t.Name(), key) == stage {
+			continue
+		}
+
+		err := t.Transition(key, stage)
+		if errors.Is(err, tracker.ErrNoTransition) {
+			log.Debug("Not moving %s: %v", key, err)
+		} else if err != nil {
+			log.Warn("Failed to update %s issue: %v", t.Name(), err)
+			continue
+		} else {
+			log.Info("Moved %s to %s", key, stage)
+		}
+		if prURL != "" {
+			if err := t.LinkPR(key, prURL); err != nil {
+				log.Warn("Failed to link pull request to %s issue: %v", t.Name(), err)
+			}
+		}
+		if comment != "" {
+			if err := t.Comment(key, comment); err != nil {
+				log.Warn("Failed to comment on %s issue: %v", t.Name(), err)
+			}
+		}
+		if err := w.trackerState.Record(t.Name(), key, stage); err != nil {
+			log.Debug("Failed to record %s sync state: %v", t.Name(), err)
+		}
+	}
+}
+
+// awaitingMerge reports whether any of the plan's issues was last synced
+// as in review.
+func (w *Worker) awaitingMerge(p *plan.Plan) bool {
+	for _, t := range w.trackers {
+		if key := t.IssueFor(p); key != "" && w.trackerState.Stage(t.Name(), key) == tracker.StageInReview {
+			return true
+		}
+	}
+	return false
+}
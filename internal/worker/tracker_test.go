@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/jira"
+	"github.com/arvesolland/ralph/internal/linear"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/tracker"
+)
+
+// newJiraTestWorker returns a worker syncing with a fake Jira whose issues
+// can move to "In Progress", "In Review", and "Done", and the list of
+// requests it received ("transition <id>", "link <body>", or "comment <body>").
+func newJiraTestWorker(t *testing.T) (*Worker, *plan.Queue, string, func() []string) {
+	t.Helper()
+	queue, store, queueDir := setupControlTest(t)
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/transitions"):
+			io.WriteString(w, `{"transitions": [{"id": "1", "name": "In Progress"}, {"id": "2", "name": "In Review"}, {"id": "3", "name": "Done"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/transitions"):
+			requests = append(requests, "transition "+strings.Trim(strings.SplitN(string(body), `"id":`, 2)[1], `"}`))
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/remotelink"):
+			requests = append(requests, "link "+string(body))
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasSuffix(r.URL.Path, "/comment"):
+			requests = append(requests, "comment "+string(body))
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.Defaults()
+	cfg.Jira.URL = server.URL
+	cfg.Jira.APIToken = "tok"
+	t.Setenv(jira.TokenEnv, "")
+	t.Setenv(linear.APIKeyEnv, "")
+
+	w := NewWorker(WorkerConfig{
+		Queue:     queue,
+		Config:    cfg,
+		ConfigDir: filepath.Join(filepath.Dir(queueDir), ".ralph"),
+		Control:   store,
+	})
+	if len(w.trackers) != 1 || w.trackers[0].Name() != "jira" {
+		t.Fatalf("trackers = %v, want Jira when jira.url is set", w.trackers)
+	}
+	return w, queue, queueDir, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestWorker_SyncIssue(t *testing.T) {
+	w, _, queueDir, requests := newJiraTestWorker(t)
+	path := filepath.Join(queueDir, "current", "proj-1-fix-login.md")
+	os.WriteFile(path, []byte("# Plan: PROJ-1 Fix login\n**Jira:** PROJ-1\n"), 0644)
+	p, _ := plan.Load(path)
+
+	w.syncIssue(p, tracker.StageInProgress, "", "")
+	w.syncIssue(p, tracker.StageInProgress, "", "") // resumed plan: already synced
+	w.syncIssue(p, tracker.StageInReview, "https://github.com/o/r/pull/7", "")
+
+	got := requests()
+	if len(got) != 3 || got[0] != "transition 1" || got[1] != "transition 2" || !strings.HasPrefix(got[2], "link ") || !strings.Contains(got[2], "pull/7") {
+		t.Errorf("requests = %q, want In Progress, In Review, and a PR link", got)
+	}
+	if stage := w.trackerState.Stage("jira", "PROJ-1"); stage != tracker.StageInReview {
+		t.Errorf("recorded stage = %q, want %q", stage, tracker.StageInReview)
+	}
+
+	// Plans without an issue are left alone
+	other := &plan.Plan{Name: "other"}
+	w.syncIssue(other, tracker.StageInProgress, "", "")
+	if len(requests()) != 3 {
+		t.Error("plan without **Jira:** should not call Jira")
+	}
+}
+
+func TestWorker_SyncMergedIssues(t *testing.T) {
+	w, _, queueDir, requests := newJiraTestWorker(t)
+	for name, key := range map[string]string{"merged": "PROJ-1", "open": "PROJ-2"} {
+		os.WriteFile(filepath.Join(queueDir, "complete", name+".md"), []byte("# Plan\n**Jira:** "+key+"\n"), 0644)
+		w.trackerState.Record("jira", key, tracker.StageInReview)
+	}
+
+	oldState := ghPRState
+	defer func() { ghPRState = oldState }()
+	ghPRState = func(dir, branch string) (string, error) {
+		if branch == "feat/merged" {
+			return "MERGED", nil
+		}
+		return "OPEN", nil
+	}
+
+	w.syncMergedPlans()
+	if got := requests(); len(got) != 1 || got[0] != "transition 3" {
+		t.Errorf("requests = %q, want the merged plan's issue moved to Done", got)
+	}
+	if stage := w.trackerState.Stage("jira", "PROJ-1"); stage != tracker.StageDone {
+		t.Errorf("PROJ-1 stage = %q, want done", stage)
+	}
+	if stage := w.trackerState.Stage("jira", "PROJ-2"); stage != tracker.StageInReview {
+		t.Errorf("PROJ-2 stage = %q, want it still in review", stage)
+	}
+
+	// Checks are throttled
+	w.trackerState.Record("jira", "PROJ-1", tracker.StageInReview)
+	w.syncMergedPlans()
+	if len(requests()) != 1 {
+		t.Error("second check within the interval should not query again")
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// watchSettle is how long the worker waits after a pending/ change before
+// checking the queue, so a plan that is still being written isn't read half-done.
+const watchSettle = 250 * time.Millisecond
+
+// dirWatcher reports changes to plan files in a directory.
+type dirWatcher interface {
+	// Events receives a value when a plan file is added or changed.
+	// Bursts of changes may be coalesced into one value.
+	Events() <-chan struct{}
+
+	// Close stops watching.
+	Close() error
+}
+
+// isPlanFileEvent reports whether a change to the named file should wake
+// the worker. Lock files, temp files, and non-markdown files are ignored.
+func isPlanFileEvent(name string) bool {
+	base := filepath.Base(name)
+	return !strings.HasPrefix(base, ".") && filepath.Ext(base) == ".md"
+}
+
+// signalChange sends a coalesced wake-up on ch without blocking.
+func signalChange(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// watchPending starts watching the queue's pending/ directory and returns a
+// channel that receives a value when a plan is dropped in. Returns a nil
+// channel (which never fires) if watching isn't possible; the worker then
+// relies on polling alone.
+func (w *Worker) watchPending() (<-chan struct{}, func()) {
+	if w.noWatch {
+		return nil, func() {}
+	}
+
+	dir := w.queue.PendingDir()
+	watcher, err := newDirWatcher(dir)
+	if err != nil {
+		log.Debug("Not watching %s, polling only: %v", dir, err)
+		return nil, func() {}
+	}
+	log.Debug("Watching %s for new plans", dir)
+	return watcher.Events(), func() { watcher.Close() }
+}
@@ -0,0 +1,72 @@
+//go:build linux
+
+package worker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyWatcher watches a directory with inotify.
+type inotifyWatcher struct {
+	file   *os.File
+	events chan struct{}
+}
+
+// newDirWatcher watches dir for plan files that are written or moved in.
+func newDirWatcher(dir string) (dirWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	// A non-blocking fd is registered with the runtime poller, so Close
+	// unblocks the pending Read
+	w := &inotifyWatcher{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan struct{}, 1),
+	}
+	go w.read()
+	return w, nil
+}
+
+// read forwards inotify events for plan files until the watcher is closed.
+func (w *inotifyWatcher) read() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if nameEnd > n {
+				break
+			}
+			name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+			if isPlanFileEvent(name) {
+				signalChange(w.events)
+			}
+			offset = nameEnd
+		}
+	}
+}
+
+// Events receives a value when a plan file is added or changed.
+func (w *inotifyWatcher) Events() <-chan struct{} {
+	return w.events
+}
+
+// Close stops watching.
+func (w *inotifyWatcher) Close() error {
+	return w.file.Close()
+}
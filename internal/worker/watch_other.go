@@ -0,0 +1,88 @@
+//go:build !linux
+
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// statWatchInterval is how often the stat watcher checks the directory.
+const statWatchInterval = time.Second
+
+// statWatcher watches a directory by comparing plan file modification
+// times every second, for platforms without inotify support here.
+type statWatcher struct {
+	dir       string
+	events    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newDirWatcher watches dir for plan files that are added or changed.
+func newDirWatcher(dir string) (dirWatcher, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	w := &statWatcher{
+		dir:    dir,
+		events: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// run checks the directory until the watcher is closed.
+func (w *statWatcher) run() {
+	ticker := time.NewTicker(statWatchInterval)
+	defer ticker.Stop()
+
+	last := w.snapshot()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		current := w.snapshot()
+		for name, modTime := range current {
+			if prev, ok := last[name]; !ok || !prev.Equal(modTime) {
+				signalChange(w.events)
+				break
+			}
+		}
+		last = current
+	}
+}
+
+// snapshot returns the modification times of the plan files in the directory.
+func (w *statWatcher) snapshot() map[string]time.Time {
+	files := make(map[string]time.Time)
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isPlanFileEvent(entry.Name()) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			files[filepath.Join(w.dir, entry.Name())] = info.ModTime()
+		}
+	}
+	return files
+}
+
+// Events receives a value when a plan file is added or changed.
+func (w *statWatcher) Events() <-chan struct{} {
+	return w.events
+}
+
+// Close stops watching.
+func (w *statWatcher) Close() error {
+	w.closeOnce.Do(func() { close(w.done) })
+	return nil
+}
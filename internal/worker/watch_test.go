@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/prompt"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+func TestIsPlanFileEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"feature.md", true},
+		{"feature.progress.md", true},
+		{".lock", false},
+		{".plan-123.tmp", false},
+		{"notes.txt", false},
+		{".hidden.md", false},
+	}
+	for _, tt := range tests {
+		if got := isPlanFileEvent(tt.name); got != tt.want {
+			t.Errorf("isPlanFileEvent(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDirWatcher_DetectsNewPlan(t *testing.T) {
+	dir := t.TempDir()
+	watcher, err := newDirWatcher(dir)
+	if err != nil {
+		t.Skipf("directory watching unavailable: %v", err)
+	}
+	defer watcher.Close()
+
+	// Lock files don't wake the worker
+	os.WriteFile(filepath.Join(dir, ".lock"), nil, 0644)
+	select {
+	case <-watcher.Events():
+		t.Fatal("lock file should not trigger an event")
+	case <-time.After(1500 * time.Millisecond):
+	}
+
+	os.WriteFile(filepath.Join(dir, "new-plan.md"), []byte("# Plan\n"), 0644)
+	select {
+	case <-watcher.Events():
+	case <-time.After(3 * time.Second):
+		t.Fatal("no event for a new plan file")
+	}
+}
+
+func TestWorker_Run_WakesOnNewPlan(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	g := git.NewGit(tmpDir)
+	if err := runGitInit(tmpDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	queueDir := filepath.Join(tmpDir, "plans")
+	for _, dir := range []string{"pending", "current", "complete"} {
+		os.MkdirAll(filepath.Join(queueDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Test\n"), 0644)
+	g.Add("README.md")
+	if err := g.Commit("Initial commit"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	manager, err := worktree.NewManager(g, filepath.Join(tmpDir, ".ralph", "worktrees"))
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	cfg := config.Defaults()
+	cfg.Git.BaseBranch = "main"
+
+	// A long poll interval, so only the watcher can pick up the plan in time
+	started := make(chan string, 1)
+	w := NewWorker(WorkerConfig{
+		Queue:            plan.NewQueue(queueDir),
+		Config:           cfg,
+		WorktreeManager:  manager,
+		Git:              g,
+		MainWorktreePath: tmpDir,
+		Runner:           &MockRunner{},
+		PromptBuilder:    prompt.NewBuilder(cfg, tmpDir, ""),
+		PollInterval:     time.Hour,
+		Observers: []Observer{Callbacks{OnPlanStart: func(p *plan.Plan) {
+			started <- p.Name
+		}}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	// Give Run time to find the queue empty and start waiting
+	time.Sleep(200 * time.Millisecond)
+	os.WriteFile(filepath.Join(queueDir, "pending", "dropped.md"), []byte("# Dropped\n\n- [ ] Task\n"), 0644)
+
+	select {
+	case name := <-started:
+		if name != "dropped" {
+			t.Errorf("started plan = %s, want dropped", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not pick up the new plan")
+	}
+	cancel()
+	<-done
+}
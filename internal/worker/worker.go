@@ -8,18 +8,25 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/bench"
 	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/control"
+	"github.com/arvesolland/ralph/internal/events"
 	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/knowledge"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/notify"
+	"github.com/arvesolland/ralph/internal/page"
 	"github.com/arvesolland/ralph/internal/plan"
 	"github.com/arvesolland/ralph/internal/prompt"
 	"github.com/arvesolland/ralph/internal/runner"
+	"github.com/arvesolland/ralph/internal/tracker"
 	"github.com/arvesolland/ralph/internal/worktree"
 )
 
@@ -30,6 +37,9 @@ var (
 
 	// ErrInterrupted is returned when the worker is interrupted by signal.
 	ErrInterrupted = errors.New("interrupted by signal")
+
+	// ErrPaused is returned when the worker is paused via the control plane.
+	ErrPaused = errors.New("worker paused")
 )
 
 // DefaultPollInterval is the default time to wait between queue checks when empty.
@@ -73,26 +83,53 @@ type Worker struct {
 	// bot is the Socket Mode bot for handling Slack replies
 	bot *notify.SocketModeBot
 
+	// control is the worker control plane (pause/skip)
+	control *control.Store
+
+	// events records plan activity in the events log
+	events *events.Log
+
 	// pollInterval is the time to wait between queue checks when empty
 	pollInterval time.Duration
 
+	// noWatch disables watching pending/ for new plans
+	noWatch bool
+
+	// labels restricts the worker to plans that have all of these labels
+	labels []string
+
+	// trackers sync the status of plans' linked issues (nil = off)
+	trackers       []tracker.Tracker
+	trackerState   *tracker.State
+	lastMergeCheck time.Time
+
+	// pager pages on-call when a blocker is escalated (nil = off)
+	pager *page.Pager
+
 	// maxIterations is the maximum iterations per plan
 	maxIterations int
 
 	// completionMode is "pr" or "merge"
 	completionMode string
 
-	// onPlanStart is called when a plan starts processing
-	onPlanStart func(p *plan.Plan)
+	// observers are notified of plan and loop activity (see AddObserver)
+	observersMu sync.Mutex
+	observers   []Observer
+
+	// drain is closed when a graceful shutdown is requested
+	drain     chan struct{}
+	drainOnce sync.Once
 
-	// onPlanComplete is called when a plan completes successfully
-	onPlanComplete func(p *plan.Plan, result *runner.LoopResult)
+	// drainTimeout bounds how long a graceful shutdown waits (0 = no limit)
+	drainTimeout time.Duration
 
-	// onPlanError is called when a plan fails
-	onPlanError func(p *plan.Plan, err error)
+	// hardStopReason is set when shutdown escalates to an immediate stop
+	shutdownMu     sync.Mutex
+	hardStopReason string
 
-	// onBlocker is called when a blocker is detected
-	onBlocker func(p *plan.Plan, blocker *runner.Blocker)
+	// parked holds plans blocked on an interrupted merge or rebase, by the
+	// operation their blocker named
+	parked map[string]string
 }
 
 // WorkerConfig holds configuration for creating a Worker.
@@ -124,20 +161,39 @@ type WorkerConfig struct {
 	// Notifier sends Slack notifications (optional, use NewNotifier to create)
 	Notifier notify.Notifier
 
+	// Control is the worker control plane (optional, defaults to ConfigDir/control.json)
+	Control *control.Store
+
+	// Events is the events log (optional, defaults to Queue.Events or ConfigDir/events.jsonl)
+	Events *events.Log
+
 	// PollInterval is the time to wait between queue checks when empty
 	PollInterval time.Duration
 
+	// NoWatch disables watching pending/ for new plans, leaving only polling
+	// (for network filesystems where change notifications are unreliable)
+	NoWatch bool
+
 	// MaxIterations is the maximum iterations per plan
 	MaxIterations int
 
 	// CompletionMode is "pr" or "merge"
 	CompletionMode string
 
-	// Callbacks
-	OnPlanStart    func(p *plan.Plan)
-	OnPlanComplete func(p *plan.Plan, result *runner.LoopResult)
-	OnPlanError    func(p *plan.Plan, err error)
-	OnBlocker      func(p *plan.Plan, blocker *runner.Blocker)
+	// DrainTimeout bounds how long a graceful shutdown waits for the in-flight
+	// iteration before stopping immediately (0 = no limit)
+	DrainTimeout time.Duration
+
+	// Labels restricts the worker to plans that have all of these labels
+	Labels []string
+
+	// Trackers sync plans' linked issues (optional, defaults to the
+	// trackers the config enables)
+	Trackers []tracker.Tracker
+
+	// Observers are notified of plan and loop activity (optional, more can
+	// be added with AddObserver; use Callbacks to observe with functions)
+	Observers []Observer
 }
 
 // NewWorker creates a new Worker with the given configuration.
@@ -163,6 +219,28 @@ func NewWorker(cfg WorkerConfig) *Worker {
 		notifier = &notify.NoopNotifier{}
 	}
 
+	// Use provided control store or the one in the config directory
+	controlStore := cfg.Control
+	if controlStore == nil && cfg.ConfigDir != "" {
+		controlStore = control.NewStore(control.Path(cfg.ConfigDir))
+	}
+
+	// Use provided events log, the queue's, or the one in the config directory
+	eventLog := cfg.Events
+	if eventLog == nil && cfg.Queue != nil {
+		eventLog = cfg.Queue.Events
+	}
+	if eventLog == nil && cfg.ConfigDir != "" {
+		eventLog = events.NewLog(events.Path(cfg.ConfigDir))
+	}
+
+	trackers, trackerState := newTrackers(cfg)
+
+	var pager *page.Pager
+	if cfg.Config != nil {
+		pager = page.New(cfg.Config.Blockers.Page)
+	}
+
 	return &Worker{
 		queue:            cfg.Queue,
 		config:           cfg.Config,
@@ -173,56 +251,81 @@ func NewWorker(cfg WorkerConfig) *Worker {
 		runner:           cfg.Runner,
 		promptBuilder:    cfg.PromptBuilder,
 		notifier:         notifier,
+		control:          controlStore,
+		events:           eventLog,
 		pollInterval:     pollInterval,
+		noWatch:          cfg.NoWatch,
+		labels:           cfg.Labels,
+		trackers:         trackers,
+		trackerState:     trackerState,
+		pager:            pager,
 		maxIterations:    maxIterations,
 		completionMode:   completionMode,
-		onPlanStart:      cfg.OnPlanStart,
-		onPlanComplete:   cfg.OnPlanComplete,
-		onPlanError:      cfg.OnPlanError,
-		onBlocker:        cfg.OnBlocker,
+		observers:        append([]Observer(nil), cfg.Observers...),
+		drain:            make(chan struct{}),
+		drainTimeout:     cfg.DrainTimeout,
 	}
 }
 
-// Run processes plans from the queue continuously until interrupted.
-// It polls for new plans when the queue is empty.
+// Run processes plans from the queue continuously until ctx is cancelled or
+// the worker is drained (see Drain and HandleSignals).
+// When the queue is empty it waits for a plan to be dropped into pending/,
+// falling back to polling every pollInterval.
 func (w *Worker) Run(ctx context.Context) error {
 	log.Info("Worker started, polling interval: %v", w.pollInterval)
+	w.collectGarbage()
 
-	// Set up interrupt handling
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		select {
-		case sig := <-sigCh:
-			log.Warn("Received signal %v, finishing current work...", sig)
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
+	wake, stopWatch := w.watchPending()
+	defer stopWatch()
 
 	for {
-		// Check for cancellation
+		// Check for cancellation or a graceful stop request
 		select {
 		case <-ctx.Done():
 			log.Info("Worker stopping due to context cancellation")
 			return ctx.Err()
+		case <-w.drain:
+			log.Info("Worker drained, stopping")
+			return ErrInterrupted
 		default:
 		}
 
+		// Handle completed plans whose pull requests merged
+		w.syncMergedPlans()
+
 		// Try to process a plan
 		err := w.RunOnce(ctx)
 		if err != nil {
+			if errors.Is(err, ErrPaused) {
+				log.Debug("Worker paused, waiting %v before next check", w.pollInterval)
+				select {
+				case <-ctx.Done():
+					log.Info("Worker stopping while paused")
+					return ctx.Err()
+				case <-w.drain:
+					return ErrInterrupted
+				case <-time.After(w.pollInterval):
+					continue
+				}
+			}
+
 			if errors.Is(err, ErrQueueEmpty) {
-				// No plans available, wait and poll again
-				log.Debug("Queue empty, waiting %v before next check", w.pollInterval)
+				// No plans available, wait for a new plan or the next poll
+				log.Debug("Queue empty, waiting up to %v before next check", w.pollInterval)
 				select {
 				case <-ctx.Done():
 					log.Info("Worker stopping while waiting")
 					return ctx.Err()
+				case <-w.drain:
+					return ErrInterrupted
+				case <-wake:
+					log.Debug("Plan change in pending/, checking queue")
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(watchSettle):
+					}
+					continue
 				case <-time.After(w.pollInterval):
 					continue
 				}
@@ -246,8 +349,17 @@ func (w *Worker) Run(ctx context.Context) error {
 }
 
 // RunOnce processes a single plan from the queue and returns.
-// Returns ErrQueueEmpty if no plans are pending.
+// Returns ErrQueueEmpty if no plans are pending, or ErrPaused if the worker
+// is paused via the control plane. Pending plans marked skipped, waiting out
+// an automatic retry backoff, held back by worker.avoid_overlap, or not
+// handled by this worker (labels, worker.include/exclude) are passed over.
 func (w *Worker) RunOnce(ctx context.Context) error {
+	w.checkRecovery()
+
+	if w.control != nil && w.control.IsPaused() {
+		return ErrPaused
+	}
+
 	// Check if there's already a current plan
 	currentPlan, err := w.queue.Current()
 	if err != nil {
@@ -257,6 +369,34 @@ func (w *Worker) RunOnce(ctx context.Context) error {
 	var p *plan.Plan
 
 	if currentPlan != nil {
+		if req := w.abandonRequest(currentPlan); req != nil {
+			if err := w.abandonPlan(currentPlan, req); err != nil {
+				return err
+			}
+			return w.RunOnce(ctx)
+		}
+
+		if w.isSkipped(currentPlan) {
+			// Return the skipped plan to pending, keeping its worktree
+			log.Info("Current plan %s is skipped, returning it to pending", currentPlan.Name)
+			if err := w.queue.Reset(currentPlan); err != nil {
+				return fmt.Errorf("resetting skipped plan: %w", err)
+			}
+			w.mirrorState(currentPlan, "", "skipped")
+			return w.RunOnce(ctx)
+		}
+
+		// Leave a plan this worker doesn't handle to the worker that does
+		if !w.handles(currentPlan) {
+			log.Debug("Current plan %s is for another worker, waiting", currentPlan.Name)
+			return ErrQueueEmpty
+		}
+
+		// Wait for a human to resolve the interrupted operation it's blocked on
+		if w.parkedOnInterruptedOp(currentPlan) {
+			return ErrQueueEmpty
+		}
+
 		// Resume the current plan
 		log.Info("Resuming current plan: %s", currentPlan.Name)
 		p = currentPlan
@@ -267,18 +407,45 @@ func (w *Worker) RunOnce(ctx context.Context) error {
 			return fmt.Errorf("listing pending plans: %w", err)
 		}
 
-		if len(pending) == 0 {
-			return ErrQueueEmpty
+		// Take the first pending plan that isn't skipped or snoozed
+		now := time.Now()
+		w.wakeSnoozed(pending, now)
+		overlaps := w.overlaps()
+		for _, candidate := range pending {
+			if !w.handles(candidate) {
+				log.Debug("Plan %s is for another worker", candidate.Name)
+				continue
+			}
+			if w.isSkipped(candidate) {
+				log.Debug("Skipping plan: %s", candidate.Name)
+				continue
+			}
+			if candidate.Snoozed(now) {
+				log.Debug("Plan %s is snoozed until %s", candidate.Name, candidate.SnoozedUntil.Format(time.RFC3339))
+				continue
+			}
+			if w.retryPending(candidate) {
+				log.Debug("Plan %s is waiting to retry", candidate.Name)
+				continue
+			}
+			if o := overlaps[candidate.Name]; o != nil {
+				log.Debug("Plan %s is %s", candidate.Name, o)
+				continue
+			}
+			p = candidate
+			break
 		}
 
-		// Take the first pending plan
-		p = pending[0]
+		if p == nil {
+			return ErrQueueEmpty
+		}
 
 		// Activate it (move to current/)
 		log.Info("Activating plan: %s", p.Name)
 		if err := w.queue.Activate(p); err != nil {
 			return fmt.Errorf("activating plan: %w", err)
 		}
+		w.mirrorState(p, "", "activated")
 	}
 
 	// Process the plan
@@ -288,13 +455,22 @@ func (w *Worker) RunOnce(ctx context.Context) error {
 // processPlan handles the full lifecycle of a single plan:
 // create worktree → sync files → run hooks → run loop → sync back → complete
 func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
+	defer w.openPlanLog(p)()
+
 	// Send start notification via Slack
+	w.recordEvent(events.Event{
+		Type:       events.TypePlanStarted,
+		Plan:       p.Name,
+		TasksDone:  plan.CountComplete(p.Tasks),
+		TasksTotal: plan.CountTotal(p.Tasks),
+		Labels:     p.Labels,
+	})
 	w.sendStartNotification(p)
+	w.syncIssue(p, tracker.StageInProgress, "", "")
+	w.refreshHome()
 
-	// Notify callback
-	if w.onPlanStart != nil {
-		w.onPlanStart(p)
-	}
+	// Notify observers
+	w.notify(func(o Observer) { o.PlanStarted(p) })
 
 	// Create or get existing worktree
 	wt, err := w.ensureWorktree(p)
@@ -313,13 +489,9 @@ func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
 	// We track this by checking if context.json exists
 	ctxPath := runner.ContextPath(wt.Path)
 	if _, err := os.Stat(ctxPath); os.IsNotExist(err) {
-		log.Info("Running worktree init hooks...")
-		hookResult, hookErr := worktree.RunInitHooks(wt.Path, w.config, w.mainWorktreePath)
-		if hookErr != nil {
-			log.Warn("Init hooks failed: %v", hookErr)
-			// Continue anyway - hooks are optional
-		} else if hookResult != nil {
-			log.Debug("Init hooks completed via method: %s", hookResult.Method)
+		if err := w.initWorktree(wt.Path); err != nil {
+			w.notifyError(p, err)
+			return err
 		}
 	}
 
@@ -333,6 +505,15 @@ func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
 		return fmt.Errorf("loading context: %w", err)
 	}
 
+	// Give the agent a scratch directory outside the repository
+	var env []string
+	if scratch, err := worktree.CreateScratch(w.scratchRoot(), p); err != nil {
+		log.Warn("No scratch directory for %s: %v", p.Name, err)
+	} else {
+		log.Debug("Scratch directory: %s", scratch)
+		env = worktree.ScratchEnviron(scratch)
+	}
+
 	// Create the iteration loop with notification callbacks
 	loop := runner.NewIterationLoop(runner.LoopConfig{
 		Plan:          p,
@@ -340,21 +521,110 @@ func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
 		Config:        w.config,
 		Runner:        w.runner,
 		Git:           wtGit,
+		Env:           env,
 		PromptBuilder: w.promptBuilder,
 		WorktreePath:  wt.Path,
+		Audit:         w.queue.Audit,
+		KnowledgePath: knowledge.Path(w.configDir),
 		OnIteration: func(iteration int, result *runner.Result) {
+			// Reload the worktree copy so task counts reflect this iteration
+			current := p
+			if updated, err := plan.Load(filepath.Join(wt.Path, execCtx.PlanFile)); err == nil {
+				current = updated
+			}
+
+			ev := events.Event{
+				Type:          events.TypeIteration,
+				Plan:          p.Name,
+				Iteration:     iteration,
+				MaxIterations: w.maxIterations,
+				TasksDone:     plan.CountComplete(current.Tasks),
+				TasksTotal:    plan.CountTotal(current.Tasks),
+			}
+			if result != nil {
+				ev.Duration = result.Duration
+				ev.InputTokens = result.Usage.InputTokens
+				ev.OutputTokens = result.Usage.OutputTokens
+				if !result.Tools.Empty() {
+					tools := result.Tools
+					ev.Tools = &tools
+				}
+				if !result.Resources.Empty() {
+					resources := result.Resources
+					ev.Resources = &resources
+				}
+			}
+			w.recordEvent(ev)
+			if result != nil && len(result.Orphans) > 0 {
+				w.recordEvent(events.Event{Type: events.TypeOrphanProcesses, Plan: p.Name, Iteration: iteration, Message: strings.Join(result.Orphans, ", ")})
+			}
+
+			w.notify(func(o Observer) { o.IterationFinished(current, iteration, result) })
+
 			// Send iteration notification if configured
-			w.sendIterationNotification(p, iteration, w.maxIterations)
+			w.sendIterationNotification(current, iteration, w.maxIterations)
+			w.sendDiffPreview(current, wt.Path, iteration, result)
+			w.sendResourceAlert(current, iteration, result)
+			w.refreshHome()
+			w.mirrorState(p, wt.Path, fmt.Sprintf("iteration %d", iteration))
 		},
 		OnBlocker: func(blocker *runner.Blocker) {
+			w.recordEvent(events.Event{Type: events.TypeBlocker, Plan: p.Name, Message: blocker.Description})
+
 			// Send blocker notification via Slack
 			w.sendBlockerNotification(p, blocker)
 
-			// Call user callback
-			if w.onBlocker != nil {
-				w.onBlocker(p, blocker)
+			// Notify observers
+			w.notify(func(o Observer) { o.BlockerDetected(p, blocker) })
+		},
+		OnBlockerEscalation: func(blocker *runner.Blocker, unresolved time.Duration) {
+			w.escalateBlocker(p, blocker, unresolved)
+		},
+		OnAsk: func(question string, timeout time.Duration) {
+			w.recordEvent(events.Event{Type: events.TypeQuestion, Plan: p.Name, Message: question})
+			w.sendQuestion(p, question, timeout)
+		},
+		OnUrgentFeedback: func(entries []plan.FeedbackEntry) {
+			w.sendUrgentFeedbackNotification(p, entries)
+		},
+		OnVerificationFailed: func(reason string) {
+			w.recordEvent(events.Event{Type: events.TypeVerificationFailed, Plan: p.Name, Message: reason})
+		},
+		OnReview: func(review *runner.Review) {
+			w.recordEvent(events.Event{Type: events.TypeVerification, Plan: p.Name, Message: review.String()})
+			if review.Disagreement() {
+				w.recordEvent(events.Event{Type: events.TypeVerificationDisagreement, Plan: p.Name, Message: review.String()})
+			}
+		},
+		OnStageChange: func(from, to string) {
+			w.recordEvent(events.Event{Type: events.TypeStageChanged, Plan: p.Name, Stage: to, Message: from + " → " + to})
+			w.sendStageChangeNotification(p, from, to)
+		},
+		OnFilesRejected: func(files []runner.RejectedFile) {
+			names := make([]string, len(files))
+			for i, f := range files {
+				names[i] = f.String()
+			}
+			w.recordEvent(events.Event{Type: events.TypeFilesRejected, Plan: p.Name, Message: strings.Join(names, ", ")})
+		},
+		OnCoverage: func(iteration int, percent float64) {
+			w.recordEvent(events.Event{Type: events.TypeCoverage, Plan: p.Name, Iteration: iteration, Coverage: &percent})
+		},
+		OnBenchRegression: func(regressions []bench.Delta) {
+			names := make([]string, len(regressions))
+			for i, d := range regressions {
+				names[i] = d.String()
 			}
+			w.recordEvent(events.Event{Type: events.TypeBenchRegression, Plan: p.Name, Message: strings.Join(names, "; ")})
+		},
+		CheckWorktree: func() error {
+			return w.repairWorktree(p)
 		},
+		BeforeIteration: func(iteration int) {
+			w.pullPlanEdits(p, wt.Path, iteration)
+		},
+		Control: w.control,
+		Stop:    w.drain,
 	})
 
 	// Run the iteration loop
@@ -365,17 +635,65 @@ func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
 	if syncErr := worktree.SyncFromWorktree(p, wt.Path, w.mainWorktreePath); syncErr != nil {
 		log.Error("Failed to sync from worktree: %v", syncErr)
 		// Continue to handle completion
+	} else {
+		w.mirrorState(p, "", "progress synced")
 	}
 
 	// Handle result
 	if result.Error != nil {
 		// Check if it's a cancellation
 		if errors.Is(result.Error, context.Canceled) {
+			if reason := w.hardStopped(); reason != "" {
+				w.writeRecoveryMarker(p, result.Iterations, reason)
+			}
 			log.Info("Plan processing interrupted")
 			return ErrInterrupted
 		}
 
+		// Drained: the in-flight iteration finished and its state is synced
+		if errors.Is(result.Error, runner.ErrStopRequested) {
+			log.Info("Plan %s stopped after iteration %d; it resumes on next start", p.Name, result.Iterations)
+			return ErrInterrupted
+		}
+
+		// Skipped plans go back to pending; the worktree is kept for later
+		if errors.Is(result.Error, runner.ErrPlanSkipped) {
+			log.Info("Plan %s skipped, returning it to pending", p.Name)
+			if err := w.queue.Reset(p); err != nil {
+				return fmt.Errorf("resetting skipped plan: %w", err)
+			}
+			w.mirrorState(p, "", "skipped")
+			return nil
+		}
+
+		// Abandoned plans are archived with their reason and the worktree removed
+		if errors.Is(result.Error, runner.ErrPlanAbandoned) {
+			if req := w.abandonRequest(p); req != nil {
+				return w.abandonPlan(p, req)
+			}
+			return nil
+		}
+
+		// Transient failures go back to pending with backoff while retries remain
+		retried, err := w.scheduleRetry(p, result.Error)
+		if err != nil {
+			return err
+		}
+		if retried {
+			return result.Error
+		}
+
 		w.notifyError(p, result.Error)
+
+		// Plans that run out of iterations or time, or wait too long on a blocker, move
+		// to failed/ instead of lingering in current/. With a retry policy, every other
+		// failure that wasn't retried fails too.
+		if errors.Is(result.Error, runner.ErrMaxIterations) || errors.Is(result.Error, runner.ErrBlockerUnresolved) ||
+			errors.Is(result.Error, runner.ErrPlanTimeout) || w.planRetries() > 0 {
+			if err := w.failPlan(p, result.Error.Error()); err != nil {
+				return err
+			}
+		}
 		return result.Error
 	}
 
@@ -390,15 +708,84 @@ func (w *Worker) processPlan(ctx context.Context, p *plan.Plan) error {
 	}
 
 	// Notify completion (even if not verified complete)
-	if w.onPlanComplete != nil {
-		w.onPlanComplete(p, result)
-	}
+	w.notify(func(o Observer) { o.PlanCompleted(p, result) })
 
 	return nil
 }
 
+// originRemote returns origin of the main repository, for links to its web
+// pages, or nil if it has none on a git host.
+func (w *Worker) originRemote() *git.RemoteInfo {
+	if w.git == nil || w.config == nil {
+		return nil
+	}
+	remote, err := git.LookupRemote(w.git, "origin", w.config.Git.Provider)
+	if err != nil {
+		log.Debug("No web links for origin: %v", err)
+		return nil
+	}
+	return remote
+}
+
+// refreshHome republishes the Slack App Home tab if the bot is running.
+func (w *Worker) refreshHome() {
+	if w.bot != nil {
+		w.bot.RefreshHome()
+	}
+}
+
+// recordEvent passes an event to the observers and appends it to the events
+// log, if one is configured. Failures are logged but don't affect plan
+// processing.
+func (w *Worker) recordEvent(e events.Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	w.notify(func(o Observer) { o.EventRecorded(e) })
+
+	if w.events == nil {
+		return
+	}
+	if err := w.events.Append(e); err != nil {
+		log.Debug("Failed to record %s event: %v", e.Type, err)
+	}
+}
+
+// recordAudit appends an entry to the queue's audit log, if one is configured.
+// Failures are logged.
+func (w *Worker) recordAudit(e audit.Entry) {
+	if err := w.queue.Audit.Record(e); err != nil {
+		log.Warn("Failed to record audit entry: %v", err)
+	}
+}
+
+// estimateETA estimates the time remaining for a plan from the events log.
+// Returns nil if there is no events log or not enough history.
+func (w *Worker) estimateETA(p *plan.Plan) *plan.ETA {
+	if w.events == nil {
+		return nil
+	}
+
+	evs, err := plan.RunEvents(w.events, p.Name)
+	if err != nil {
+		log.Debug("Failed to read events log: %v", err)
+		return nil
+	}
+	return plan.EstimateETA(p, evs)
+}
+
+// isSkipped returns true if the plan is marked skipped via the control plane.
+func (w *Worker) isSkipped(p *plan.Plan) bool {
+	return w.control != nil && w.control.IsSkipped(p.Name)
+}
+
 // ensureWorktree creates a worktree for the plan if it doesn't exist.
 func (w *Worker) ensureWorktree(p *plan.Plan) (*worktree.Worktree, error) {
+	// Fix a worktree left broken by a crash before reusing it
+	if err := w.repairWorktree(p); err != nil {
+		return nil, err
+	}
+
 	// Check if worktree already exists
 	existing, err := w.worktreeManager.Get(p)
 	if err != nil {
@@ -410,6 +797,12 @@ func (w *Worker) ensureWorktree(p *plan.Plan) (*worktree.Worktree, error) {
 		return existing, nil
 	}
 
+	// Name a new plan's branch from git.branch_template
+	branchExists := func(branch string) (bool, error) { return w.git.BranchExists(branch) }
+	if err := plan.AssignBranch(p, w.config.Git.BranchTemplate, time.Now(), branchExists); err != nil {
+		return nil, fmt.Errorf("assigning branch: %w", err)
+	}
+
 	// Create new worktree
 	log.Info("Creating worktree for branch: %s", p.Branch)
 	wt, err := w.worktreeManager.Create(p)
@@ -421,6 +814,121 @@ func (w *Worker) ensureWorktree(p *plan.Plan) (*worktree.Worktree, error) {
 	return wt, nil
 }
 
+// initWorktree prepares a newly created worktree: submodules and git-lfs
+// content first, then the init hooks. A failed checkout setup is an error,
+// since the code can't build without it; init hooks are optional.
+func (w *Worker) initWorktree(path string) error {
+	if err := worktree.SetupCheckout(path, w.config); err != nil {
+		return fmt.Errorf("setting up worktree checkout: %w", err)
+	}
+
+	log.Info("Running worktree init hooks...")
+	hookResult, hookErr := worktree.RunInitHooks(path, w.config, w.mainWorktreePath)
+	if hookErr != nil {
+		log.Warn("Init hooks failed: %v", hookErr)
+		// Continue anyway - hooks are optional
+	} else if hookResult != nil {
+		log.Debug("Init hooks completed via method: %s", hookResult.Method)
+	}
+	return nil
+}
+
+// pullPlanEdits syncs plan edits made in the main worktree during the run
+// into the plan's worktree before an iteration, and notes the update in the
+// progress file so the agent knows the plan changed under it.
+func (w *Worker) pullPlanEdits(p *plan.Plan, worktreePath string, iteration int) {
+	updated, sections, err := worktree.PullPlanEdits(p, worktreePath, w.mainWorktreePath)
+	if err != nil {
+		log.Warn("Failed to sync plan edits into the worktree: %v", err)
+	}
+	if !updated {
+		return
+	}
+
+	log.Info("Plan edited in the main worktree, synced into the worktree before iteration %d", iteration)
+	if err := plan.AppendPlanUpdated(p, iteration, sections, time.Now()); err != nil {
+		log.Warn("Failed to note plan update in progress file: %v", err)
+	}
+	w.recordEvent(events.Event{Type: events.TypePlanUpdated, Plan: p.Name, Iteration: iteration, Message: strings.Join(sections, ", ")})
+}
+
+// repairWorktree detects and repairs problems with the plan's worktree.
+// Lock files are removed regardless of age, since nothing else runs git in
+// the worktree between iterations. An interrupted merge or rebase is aborted
+// unless worktree.interrupted_ops is "block", in which case it raises a
+// blocker. Returns an error naming the problems that need a manual fix.
+func (w *Worker) repairWorktree(p *plan.Plan) error {
+	abortOps := w.config == nil || w.config.Worktree.InterruptedOps != config.InterruptedOpsBlock
+	issues, err := w.worktreeManager.Repair(p, 0, abortOps)
+	if err != nil {
+		return fmt.Errorf("checking worktree: %w", err)
+	}
+
+	var repaired []string
+	for _, issue := range issues {
+		if issue.Repaired {
+			log.Warn("Repaired worktree: %s", issue)
+			repaired = append(repaired, issue.Detail)
+		}
+		if issue.Recreated {
+			if err := w.initWorktree(w.worktreeManager.Path(p)); err != nil {
+				return err
+			}
+		}
+	}
+	if len(repaired) > 0 {
+		w.recordEvent(events.Event{Type: events.TypeWorktreeRepaired, Plan: p.Name, Message: strings.Join(repaired, "; ")})
+	}
+
+	if unrepaired := worktree.Unrepaired(issues); len(unrepaired) > 0 {
+		problems := make([]string, len(unrepaired))
+		for i, issue := range unrepaired {
+			problems[i] = issue.Detail
+			if issue.Kind == worktree.IssueInterruptedOp {
+				w.raiseInterruptedOp(p, issue)
+			}
+		}
+		return fmt.Errorf("worktree needs a manual fix: %s (see `ralph worktree repair %s`)", strings.Join(problems, "; "), p.Name)
+	}
+	return nil
+}
+
+// raiseInterruptedOp raises a blocker for a merge or rebase left half-done
+// in the plan's worktree, so a human finishes or aborts it before the next
+// iteration builds on it. The plan is parked until then, so the blocker is
+// raised once rather than on every pass of the worker loop.
+func (w *Worker) raiseInterruptedOp(p *plan.Plan, issue worktree.Issue) {
+	if w.parked == nil {
+		w.parked = make(map[string]string)
+	}
+	w.parked[p.Name] = issue.Operation
+
+	blocker := runner.NewBlocker(
+		fmt.Sprintf("A git %s was interrupted in the worktree %s", issue.Operation, issue.Path),
+		fmt.Sprintf("Finish the %s, or run `git %s --abort` in the worktree; the worker picks the plan up again once it's done", issue.Operation, issue.Operation),
+	)
+	log.Warn("Blocker raised: %s", blocker.Description)
+	w.recordEvent(events.Event{Type: events.TypeBlocker, Plan: p.Name, Message: blocker.Description})
+	w.sendBlockerNotification(p, blocker)
+	w.notify(func(o Observer) { o.BlockerDetected(p, blocker) })
+}
+
+// parkedOnInterruptedOp reports whether the plan is still waiting on the
+// interrupted merge or rebase its blocker asked a human to resolve.
+func (w *Worker) parkedOnInterruptedOp(p *plan.Plan) bool {
+	op, ok := w.parked[p.Name]
+	if !ok {
+		return false
+	}
+	gitDir, err := git.WorktreeGitDir(w.worktreeManager.Path(p))
+	if err == nil && git.InterruptedOperation(gitDir) == op {
+		log.Debug("Plan %s is waiting for the interrupted %s to be resolved", p.Name, op)
+		return true
+	}
+	delete(w.parked, p.Name)
+	return false
+}
+
 // loadOrCreateContext loads existing context or creates new one.
 func (w *Worker) loadOrCreateContext(p *plan.Plan, worktreePath string) (*runner.Context, error) {
 	ctxPath := runner.ContextPath(worktreePath)
@@ -466,22 +974,67 @@ func (w *Worker) loadOrCreateContext(p *plan.Plan, worktreePath string) (*runner
 func (w *Worker) completePlan(ctx context.Context, p *plan.Plan, wt *worktree.Worktree, result *runner.LoopResult) error {
 	log.Success("Plan completed: %s", p.Name)
 
+	// Run the final gates on the finished branch
+	gates := w.runGates(ctx, wt.Path)
+	if result != nil && result.Bench != nil {
+		gates = append(gates, *result.Bench)
+	}
+	if cov := w.coverageGate(result); cov != nil {
+		gates = append(gates, *cov)
+		if !cov.Passed && w.config.Coverage.Gate {
+			log.Warn("Plan %s failed the coverage gate: %s", p.Name, cov.Output)
+			return w.failPlan(p, fmt.Sprintf("coverage gate failed: %s", cov.Output))
+		}
+	}
+
+	// Wait for a human to approve the PR/merge if required
+	if err := w.awaitApproval(ctx, p, w.completion(p, result, "", gates)); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		log.Warn("Plan %s not approved: %v", p.Name, err)
+		return w.failPlan(p, err.Error())
+	}
+
 	// Set up git for the worktree
 	wtGit := git.NewGit(wt.Path)
 
 	// Handle completion based on mode
-	var prURL string
+	var prURL, branchURL, outcome string
 
 	switch w.completionMode {
 	case "pr":
+		opts := PROptionsFor(p, w.config.Git.PR, wt.Path)
+		opts.Remote, opts.Base = w.originRemote(), w.config.Git.BaseBranch
+		if opts.Base == "" {
+			opts.Base = "main"
+		}
 		var err error
-		prURL, err = CompletePR(p, wt, wtGit)
+		prURL, err = CompletePR(p, wt, wtGit, opts)
+		if opts.Remote != nil && !errors.Is(err, ErrPushFailed) {
+			branchURL = opts.Remote.BranchURL(p.Branch)
+		}
 		if err != nil {
 			// PR creation failure is logged but not fatal
 			// The plan is still complete, code is committed locally
 			log.Error("Failed to create PR: %v", err)
 			log.Warn("Plan completed but PR not created. Branch: %s", p.Branch)
 		}
+		outcome = "pull request not created"
+		if prURL != "" {
+			outcome = "pull request opened"
+			details := map[string]string{"branch": p.Branch, "url": prURL}
+			if strategy := w.config.Completion.AutoMerge; strategy != "" {
+				if err := EnableAutoMerge(prURL, strategy, wt.Path); err != nil {
+					log.Warn("Failed to enable auto-merge: %v", err)
+				} else {
+					log.Success("Auto-merge enabled (%s)", strategy)
+					details["auto_merge"] = strategy
+				}
+			}
+			w.recordAudit(audit.Entry{Action: audit.ActionPROpened, Plan: p.Name, Details: details})
+			w.syncIssue(p, tracker.StageInReview, prURL, "")
+		}
 	case "merge":
 		// Use CompleteMerge for merge mode
 		mainGit := git.NewGit(w.mainWorktreePath)
@@ -489,27 +1042,52 @@ func (w *Worker) completePlan(ctx context.Context, p *plan.Plan, wt *worktree.Wo
 		if baseBranch == "" {
 			baseBranch = "main"
 		}
-		if err := CompleteMerge(p, baseBranch, mainGit); err != nil {
+		if err := CompleteMerge(p, baseBranch, mainGit, w.config.Git.DeleteBranchOnMerge); err != nil {
 			log.Error("Failed to merge: %v", err)
 			log.Warn("Plan completed but merge failed. Branch: %s", p.Branch)
+			outcome = "merge failed"
+		} else {
+			outcome = "merged into " + baseBranch
+			w.recordAudit(audit.Entry{Action: audit.ActionMerged, Plan: p.Name, Details: map[string]string{"branch": p.Branch, "into": baseBranch}})
+			w.syncIssue(p, tracker.StageDone, "", fmt.Sprintf("Ralph merged %s into %s.", p.Branch, baseBranch))
 		}
 	default:
 		log.Debug("Unknown completion mode: %s, skipping", w.completionMode)
 	}
 
 	// Send completion notification via Slack
-	w.sendCompleteNotification(p, prURL)
+	completion := w.completion(p, result, prURL, gates)
+	completion.BranchURL = branchURL
+	classified := w.classifyCompletion(p)
+	w.recordEvent(events.Event{Type: events.TypePlanCompleted, Plan: p.Name, PRURL: prURL})
+	w.sendCompleteNotification(p, completion)
+
+	// Run post-completion hooks (before the worktree is removed)
+	w.runCompleteHooks(ctx, p, wt, result, prURL)
 
 	// Notify callback with PR URL if available
-	if w.onPlanComplete != nil {
-		w.onPlanComplete(p, result)
-	}
+	w.notify(func(o Observer) { o.PlanCompleted(p, result) })
 
-	// Archive the plan (move to complete/)
+	// Archive the plan (move to complete/) with its summary; both these
+	// read the sidecars left in current/
+	w.planFinished(ctx, p, classified, "")
+	summary := completionSummary(p, outcome, completion, time.Now())
 	if err := w.queue.Complete(p); err != nil {
 		log.Error("Failed to archive plan: %v", err)
 		// Continue with cleanup
+	} else if err := plan.WriteSummary(p, summary); err != nil {
+		log.Warn("Failed to write completion summary: %v", err)
+	}
+	w.clearRetry(p)
+	w.mirrorState(p, "", "completed")
+
+	// Record the completion for the Slack Home tab
+	if w.threadTracker != nil {
+		if err := w.threadTracker.MarkComplete(notify.PlanThreadKey(p, w.config.Slack.Channel), prURL); err != nil {
+			log.Debug("Failed to record completion: %v", err)
+		}
 	}
+	w.refreshHome()
 
 	// Clean up worktree
 	log.Info("Cleaning up worktree...")
@@ -518,6 +1096,9 @@ func (w *Worker) completePlan(ctx context.Context, p *plan.Plan, wt *worktree.Wo
 		log.Warn("Failed to remove worktree: %v", err)
 		// Non-fatal
 	}
+	if err := worktree.RemoveScratch(w.scratchRoot(), p); err != nil {
+		log.Warn("%v", err)
+	}
 
 	// Log PR URL at the end for visibility
 	if prURL != "" {
@@ -527,8 +1108,25 @@ func (w *Worker) completePlan(ctx context.Context, p *plan.Plan, wt *worktree.Wo
 	return nil
 }
 
+// failPlan moves a plan that can't continue to failed/. The worktree and
+// branch are kept so `ralph retry` can continue from the work done so far.
+func (w *Worker) failPlan(p *plan.Plan, reason string) error {
+	w.planFinished(context.Background(), p, runner.OutcomeFailedVerification, reason)
+	if err := w.queue.Fail(p, reason); err != nil {
+		return fmt.Errorf("moving plan to failed: %w", err)
+	}
+	w.clearRetry(p)
+	w.recordEvent(events.Event{Type: events.TypePlanFailed, Plan: p.Name, Message: reason})
+	w.mirrorState(p, "", "failed")
+	log.Warn("Plan %s moved to failed/; requeue it with: ralph retry %s", p.Name, p.Name)
+	w.refreshHome()
+	return nil
+}
+
 // notifyError sends error notification and calls the error callback if set.
 func (w *Worker) notifyError(p *plan.Plan, err error) {
+	w.recordEvent(events.Event{Type: events.TypePlanError, Plan: p.Name, Message: err.Error()})
+
 	// Send error notification via Slack
 	if w.config != nil && w.config.Slack.NotifyError {
 		if notifyErr := w.notifier.Error(p, err); notifyErr != nil {
@@ -536,10 +1134,11 @@ func (w *Worker) notifyError(p *plan.Plan, err error) {
 		}
 	}
 
+	// Run on_plan_error hooks
+	w.runErrorHooks(p, err)
+
 	// Call user callback
-	if w.onPlanError != nil {
-		w.onPlanError(p, err)
-	}
+	w.notify(func(o Observer) { o.PlanFailed(p, err) })
 }
 
 // sendStartNotification sends a start notification if configured.
@@ -552,14 +1151,26 @@ func (w *Worker) sendStartNotification(p *plan.Plan) {
 }
 
 // sendCompleteNotification sends a completion notification if configured.
-func (w *Worker) sendCompleteNotification(p *plan.Plan, prURL string) {
+func (w *Worker) sendCompleteNotification(p *plan.Plan, c notify.Completion) {
 	if w.config != nil && w.config.Slack.NotifyComplete {
-		if err := w.notifier.Complete(p, prURL); err != nil {
+		if err := w.notifier.Complete(p, c); err != nil {
 			log.Debug("Failed to send complete notification: %v", err)
 		}
 	}
 }
 
+// sendQuestion asks the agent's question through notifiers that can take
+// an answer back, such as the plan's Slack thread.
+func (w *Worker) sendQuestion(p *plan.Plan, question string, timeout time.Duration) {
+	sender, ok := w.notifier.(notify.QuestionSender)
+	if !ok {
+		return
+	}
+	if err := sender.Question(p, question, timeout); err != nil {
+		log.Debug("Failed to send question: %v", err)
+	}
+}
+
 // sendBlockerNotification sends a blocker notification if configured.
 func (w *Worker) sendBlockerNotification(p *plan.Plan, blocker *runner.Blocker) {
 	if w.config != nil && w.config.Slack.NotifyBlocker {
@@ -572,12 +1183,48 @@ func (w *Worker) sendBlockerNotification(p *plan.Plan, blocker *runner.Blocker)
 // sendIterationNotification sends an iteration notification if configured.
 func (w *Worker) sendIterationNotification(p *plan.Plan, iteration, maxIterations int) {
 	if w.config != nil && w.config.Slack.NotifyIteration {
-		if err := w.notifier.Iteration(p, iteration, maxIterations); err != nil {
+		if err := w.notifier.Iteration(p, iteration, maxIterations, w.estimateETA(p)); err != nil {
 			log.Debug("Failed to send iteration notification: %v", err)
 		}
 	}
 }
 
+// sendResourceAlert flags an iteration that exceeded runner.resource_alerts,
+// for notifiers that support it. It is a warning, so it follows the
+// notify_error setting.
+func (w *Worker) sendResourceAlert(p *plan.Plan, iteration int, result *runner.Result) {
+	if result == nil || len(result.ResourceAlerts) == 0 || w.config == nil || !w.config.Slack.NotifyError {
+		return
+	}
+	sender, ok := w.notifier.(notify.ResourceAlertSender)
+	if !ok {
+		return
+	}
+	if err := sender.ResourceAlert(p, iteration, result.Resources, result.ResourceAlerts); err != nil {
+		log.Debug("Failed to send resource alert: %v", err)
+	}
+}
+
+// sendStageChangeNotification sends a stage change notification if configured.
+// A new stage starting follows the notify_start setting.
+func (w *Worker) sendStageChangeNotification(p *plan.Plan, from, to string) {
+	if w.config != nil && w.config.Slack.NotifyStart {
+		if err := w.notifier.StageChange(p, from, to); err != nil {
+			log.Debug("Failed to send stage change notification: %v", err)
+		}
+	}
+}
+
+// sendUrgentFeedbackNotification sends an urgent feedback notification if configured.
+// Urgent feedback is human input, so it follows the notify_blocker setting.
+func (w *Worker) sendUrgentFeedbackNotification(p *plan.Plan, entries []plan.FeedbackEntry) {
+	if w.config != nil && w.config.Slack.NotifyBlocker {
+		if err := w.notifier.UrgentFeedback(p, entries); err != nil {
+			log.Debug("Failed to send urgent feedback notification: %v", err)
+		}
+	}
+}
+
 // SetupNotifications configures the notifier and optionally starts the Socket Mode bot.
 // This should be called before starting the worker.
 // Returns a cleanup function that should be called when the worker stops.
@@ -595,20 +1242,58 @@ func (w *Worker) SetupNotifications(ctx context.Context) func() {
 	}
 	w.threadTracker = tracker
 
+	// Prune stale thread entries so slack_threads.json doesn't grow forever
+	if tracker != nil {
+		if result, err := tracker.Prune(ThreadPruneOptions(w.config)); err != nil {
+			log.Warn("Failed to prune thread tracker: %v", err)
+		} else if len(result.Removed) > 0 {
+			log.Debug("Pruned %d stale Slack thread entries", len(result.Removed))
+		}
+	}
+
 	// Create notifier based on configuration
-	w.notifier = NewNotifier(w.config, tracker)
+	w.notifier = NewNotifier(w.config, tracker, w.configDir)
+
+	// Rate limit bursts, e.g. every plan erroring during an API outage
+	notifyCtx, stopNotifiers := context.WithCancel(ctx)
+	if window, err := time.ParseDuration(w.config.Slack.RateLimit.Window); err == nil && window > 0 {
+		limiter := notify.NewRateLimitNotifier(w.notifier, window, w.config.Slack.RateLimit.PerPlan, w.config.Slack.RateLimit.Global)
+		w.notifier = limiter
+		go limiter.Run(notifyCtx)
+		log.Info("Slack rate limits: %d per plan, %d overall per %s", w.config.Slack.RateLimit.PerPlan, w.config.Slack.RateLimit.Global, window)
+	}
+
+	// In digest mode, per-event messages are replaced by a periodic summary
+	if period, ok := notify.DigestPeriod(w.config.Slack.Digest); ok && w.events != nil {
+		digest := notify.NewDigestNotifier(w.notifier, w.events, period)
+		w.notifier = digest
+		go digest.Run(notifyCtx)
+		log.Info("Slack digest mode: %s", w.config.Slack.Digest)
+	}
 
 	// Auto-start Socket Mode bot if configured
 	if w.config.Slack.Channel != "" {
 		planBasePath := filepath.Join(w.mainWorktreePath, "plans", "current")
-		w.bot = notify.StartBotIfConfigured(ctx, tracker, planBasePath, w.config.Slack.Channel)
+		w.bot = notify.StartBot(ctx, notify.BotConfig{
+			ThreadTracker: tracker,
+			PlanBasePath:  planBasePath,
+			ChannelID:     w.config.Slack.Channel,
+			Queue:         w.queue,
+			Control:       w.control,
+			Roles:         w.config.Slack.Roles,
+			DefaultRole:   w.config.Slack.DefaultRole,
+			Authors:       w.config.Feedback.Authors,
+			Events:        w.events,
+			Remote:        w.originRemote(),
+		})
 		if w.bot != nil {
-			log.Info("Socket Mode bot started for Slack replies")
+			log.Info("Socket Mode bot started for Slack replies and commands")
 		}
 	}
 
 	// Return cleanup function
 	return func() {
+		stopNotifiers()
 		if w.bot != nil {
 			w.bot.Stop()
 			log.Debug("Socket Mode bot stopped")
@@ -616,14 +1301,47 @@ func (w *Worker) SetupNotifications(ctx context.Context) func() {
 	}
 }
 
-// NewNotifier creates a Notifier based on the configuration.
+// ThreadPruneOptions returns the thread tracker pruning limits from the configuration.
+func ThreadPruneOptions(cfg *config.Config) notify.PruneOptions {
+	opts := notify.PruneOptions{
+		MaxAge:              time.Duration(notify.DefaultThreadRetentionDays) * 24 * time.Hour,
+		MaxEntries:          notify.DefaultMaxThreads,
+		MaxNotifiedBlockers: notify.DefaultMaxNotifiedBlockers,
+	}
+	if cfg == nil {
+		return opts
+	}
+
+	if cfg.Slack.ThreadRetentionDays > 0 {
+		opts.MaxAge = time.Duration(cfg.Slack.ThreadRetentionDays) * 24 * time.Hour
+	}
+	if cfg.Slack.MaxThreads > 0 {
+		opts.MaxEntries = cfg.Slack.MaxThreads
+	}
+	return opts
+}
+
+// NewNotifier creates a Notifier based on the configuration. Message
+// templates are loaded from configDir's notify-templates/, and webhook
+// payloads that can't be delivered are spooled in configDir (empty = neither).
 // Returns a SlackNotifier if bot_token is configured, falls back to WebhookNotifier,
 // and returns NoopNotifier if neither is configured.
-func NewNotifier(cfg *config.Config, tracker *notify.ThreadTracker) notify.Notifier {
+func NewNotifier(cfg *config.Config, tracker *notify.ThreadTracker, configDir string) notify.Notifier {
 	if cfg == nil {
 		return &notify.NoopNotifier{}
 	}
 
+	var spoolPath string
+	var templates *notify.Templates
+	if configDir != "" {
+		spoolPath = notify.SpoolPath(configDir)
+		var err error
+		templates, err = notify.LoadTemplates(filepath.Join(configDir, notify.TemplatesDir))
+		if err != nil {
+			log.Warn("Failed to load notification templates, using the defaults: %v", err)
+		}
+	}
+
 	// Try Slack Bot API first
 	if cfg.Slack.BotToken != "" && cfg.Slack.Channel != "" {
 		return notify.NewSlackNotifier(notify.SlackNotifierConfig{
@@ -631,12 +1349,23 @@ func NewNotifier(cfg *config.Config, tracker *notify.ThreadTracker) notify.Notif
 			Channel:       cfg.Slack.Channel,
 			ThreadTracker: tracker,
 			WebhookURL:    cfg.Slack.WebhookURL, // Fallback
+
+			WebhookSecret:    cfg.Slack.WebhookSecret,
+			WebhookSpoolPath: spoolPath,
+			Templates:        templates,
+			PlainText:        cfg.Slack.PlainText,
 		})
 	}
 
 	// Fall back to webhook
 	if cfg.Slack.WebhookURL != "" {
-		notifier := notify.NewWebhookNotifier(cfg.Slack.WebhookURL)
+		notifier := notify.NewWebhookNotifierWithConfig(notify.WebhookNotifierConfig{
+			URL:       cfg.Slack.WebhookURL,
+			Secret:    cfg.Slack.WebhookSecret,
+			SpoolPath: spoolPath,
+			Templates: templates,
+			PlainText: cfg.Slack.PlainText,
+		})
 		if notifier != nil {
 			return notifier
 		}
@@ -645,3 +1374,29 @@ func NewNotifier(cfg *config.Config, tracker *notify.ThreadTracker) notify.Notif
 	// No Slack configured
 	return &notify.NoopNotifier{}
 }
+
+// openPlanLog starts copying log output to the plan's log file in .ralph/logs.
+// Returns a function that stops copying and closes the file.
+func (w *Worker) openPlanLog(p *plan.Plan) func() {
+	if w.configDir == "" {
+		return func() {}
+	}
+
+	path := log.PlanLogPath(w.configDir, p.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Warn("Failed to create log directory: %v", err)
+		return func() {}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Warn("Failed to open plan log: %v", err)
+		return func() {}
+	}
+
+	log.SetPlanSink(p.Name, f)
+	log.Debug("Logging plan %s to %s", p.Name, path)
+	return func() {
+		log.SetPlanSink("", nil)
+		f.Close()
+	}
+}
@@ -0,0 +1,144 @@
+package worktree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// ErrLFSNotInstalled is returned when worktree.lfs is set but git-lfs isn't installed.
+var ErrLFSNotInstalled = errors.New("git-lfs is not installed")
+
+// SetupCheckout completes the checkout of a new worktree before its init
+// hooks run: git worktree add checks out neither submodules nor git-lfs
+// content. Submodules are initialized if worktree.submodules is set, then
+// git-lfs objects are pulled (in submodules too) if worktree.lfs is set.
+func SetupCheckout(worktreePath string, cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Worktree.Submodules {
+		if err := InitSubmodules(worktreePath); err != nil {
+			return err
+		}
+	}
+	if cfg.Worktree.LFS {
+		if err := PullLFS(worktreePath, cfg.Worktree.Submodules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InitSubmodules runs `git submodule update --init --recursive` in the
+// worktree. If it fails, submodule URLs are synced from .gitmodules (they
+// may have changed upstream) and the update is retried once.
+func InitSubmodules(worktreePath string) error {
+	if _, err := os.Stat(filepath.Join(worktreePath, ".gitmodules")); os.IsNotExist(err) {
+		log.Debug("No .gitmodules in %s, skipping submodules", worktreePath)
+		return nil
+	}
+
+	log.Info("Initializing submodules...")
+	update := []string{"submodule", "update", "--init", "--recursive"}
+	err := runGitProgress(worktreePath, update...)
+	if err != nil {
+		log.Warn("Submodule update failed, syncing submodule URLs and retrying: %v", err)
+		if syncErr := runGitProgress(worktreePath, "submodule", "sync", "--recursive"); syncErr != nil {
+			return fmt.Errorf("initializing submodules: %w", err)
+		}
+		if err = runGitProgress(worktreePath, update...); err != nil {
+			return fmt.Errorf("initializing submodules: %w", err)
+		}
+	}
+	log.Success("Submodules initialized")
+	return nil
+}
+
+// PullLFS runs `git lfs pull` in the worktree, and in each submodule if
+// submodules is true. Returns ErrLFSNotInstalled if git-lfs is missing.
+func PullLFS(worktreePath string, submodules bool) error {
+	if err := exec.Command("git", "lfs", "version").Run(); err != nil {
+		return fmt.Errorf("%w (install it from https://git-lfs.com, or set worktree.lfs: false)", ErrLFSNotInstalled)
+	}
+
+	log.Info("Fetching git-lfs objects...")
+	if err := runGitProgress(worktreePath, "lfs", "pull"); err != nil {
+		return fmt.Errorf("git lfs pull: %w", err)
+	}
+	if submodules {
+		if _, err := os.Stat(filepath.Join(worktreePath, ".gitmodules")); err == nil {
+			if err := runGitProgress(worktreePath, "submodule", "foreach", "--recursive", "git lfs pull"); err != nil {
+				return fmt.Errorf("git lfs pull in submodules: %w", err)
+			}
+		}
+	}
+	log.Success("git-lfs objects fetched")
+	return nil
+}
+
+// runGitProgress runs a git command in dir, logging each line of its output
+// as it arrives so long clones and downloads show progress. On failure the
+// error includes the last line of output.
+func runGitProgress(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out := &progressWriter{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	err := cmd.Run()
+	out.flush()
+	if err != nil {
+		if out.last != "" {
+			return fmt.Errorf("%s: %w", out.last, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// progressWriter logs output line by line, treating carriage returns as
+// line breaks so in-place progress counters are logged as they update.
+type progressWriter struct {
+	buf  bytes.Buffer
+	last string
+}
+
+// Write logs each complete line in p.
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexAny(w.buf.Bytes(), "\r\n")
+		if i < 0 {
+			break
+		}
+		w.logLine(string(w.buf.Next(i + 1)))
+	}
+	return len(p), nil
+}
+
+// flush logs any output left without a line break.
+func (w *progressWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.logLine(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+// logLine logs one line of output, skipping blank lines.
+func (w *progressWriter) logLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	w.last = line
+	log.Info("  %s", line)
+}
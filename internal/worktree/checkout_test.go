@@ -0,0 +1,129 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+// allowFileSubmodules lets git clone submodules from local paths in tests.
+func allowFileSubmodules(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+}
+
+// fakeGitLFS puts a git-lfs script on PATH that answers `git lfs version`
+// and otherwise prints output and exits with code.
+func fakeGitLFS(t *testing.T, output string, code int) {
+	t.Helper()
+	bin := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\n[ \"$1\" = version ] && exit 0\nprintf '%s'\nexit %d\n", output, code)
+	if err := os.WriteFile(filepath.Join(bin, "git-lfs"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestSetupCheckout_Disabled(t *testing.T) {
+	if err := SetupCheckout(t.TempDir(), nil); err != nil {
+		t.Errorf("SetupCheckout(nil config) = %v", err)
+	}
+	if err := SetupCheckout(t.TempDir(), config.Defaults()); err != nil {
+		t.Errorf("SetupCheckout(defaults) = %v", err)
+	}
+}
+
+func TestInitSubmodules(t *testing.T) {
+	allowFileSubmodules(t)
+	tmpDir := t.TempDir()
+
+	// A library repo used as a submodule of the main repo
+	lib := filepath.Join(tmpDir, "lib")
+	os.MkdirAll(lib, 0755)
+	runTestGit(t, lib, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n"), 0644)
+	runTestGit(t, lib, "add", "lib.go")
+	runTestGit(t, lib, "commit", "-q", "-m", "lib")
+
+	repo := filepath.Join(tmpDir, "repo")
+	os.MkdirAll(repo, 0755)
+	runTestGit(t, repo, "init", "-q", "-b", "main")
+	runTestGit(t, repo, "submodule", "add", "-q", lib, "vendor/lib")
+	runTestGit(t, repo, "commit", "-q", "-m", "Add submodule")
+
+	wt := filepath.Join(tmpDir, "wt")
+	runTestGit(t, repo, "worktree", "add", "-q", "-b", "feat/sub", wt)
+	if _, err := os.Stat(filepath.Join(wt, "vendor", "lib", "lib.go")); err == nil {
+		t.Fatal("git worktree add should not check out submodules")
+	}
+
+	cfg := config.Defaults()
+	cfg.Worktree.Submodules = true
+	if err := SetupCheckout(wt, cfg); err != nil {
+		t.Fatalf("SetupCheckout() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "vendor", "lib", "lib.go")); err != nil {
+		t.Errorf("submodule not checked out: %v", err)
+	}
+
+	// Without .gitmodules there is nothing to do
+	if err := InitSubmodules(t.TempDir()); err != nil {
+		t.Errorf("InitSubmodules() without .gitmodules = %v", err)
+	}
+
+	// A submodule that can't be cloned fails with git's explanation
+	os.RemoveAll(lib)
+	os.RemoveAll(filepath.Join(repo, ".git", "modules"))
+	wt2 := filepath.Join(tmpDir, "wt2")
+	runTestGit(t, repo, "worktree", "add", "-q", "-b", "feat/sub2", wt2)
+	if err := InitSubmodules(wt2); err == nil || !strings.Contains(err.Error(), "initializing submodules") {
+		t.Errorf("InitSubmodules() with a missing submodule = %v, want an error", err)
+	}
+}
+
+func TestPullLFS(t *testing.T) {
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "-q", "-b", "main")
+
+	fakeGitLFS(t, "Downloading LFS objects: 100%% (2/2), 3 MB\\n", 0)
+	if err := PullLFS(dir, false); err != nil {
+		t.Errorf("PullLFS() error = %v", err)
+	}
+
+	fakeGitLFS(t, "batch request: missing protocol\\n", 2)
+	err := PullLFS(dir, false)
+	if err == nil || errors.Is(err, ErrLFSNotInstalled) || !strings.Contains(err.Error(), "batch request: missing protocol") {
+		t.Fatalf("PullLFS() with a failing git-lfs = %v", err)
+	}
+}
+
+func TestPullLFS_NotInstalled(t *testing.T) {
+	if exec.Command("git", "lfs", "version").Run() == nil {
+		t.Skip("git-lfs is installed")
+	}
+	cfg := config.Defaults()
+	cfg.Worktree.LFS = true
+	if err := SetupCheckout(t.TempDir(), cfg); !errors.Is(err, ErrLFSNotInstalled) {
+		t.Errorf("SetupCheckout() = %v, want ErrLFSNotInstalled", err)
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	w := &progressWriter{}
+	w.Write([]byte("Cloning into 'lib'...\nReceiving objects:  50%\rReceiving objects: 100"))
+	if w.last != "Receiving objects:  50%" {
+		t.Errorf("last = %q after a partial line", w.last)
+	}
+	w.Write([]byte("%, done.\n\n"))
+	w.flush()
+	if w.last != "Receiving objects: 100%, done." {
+		t.Errorf("last = %q, want the completed progress line", w.last)
+	}
+}
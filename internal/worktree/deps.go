@@ -92,7 +92,8 @@ func DetectAndInstall(worktreePath string) (*InstallResult, error) {
 }
 
 // runInstallCommand executes the install command for the given lockfile.
-func runInstallCommand(workDir string, lf Lockfile) (*InstallResult, error) {
+// env is appended to the environment.
+func runInstallCommand(workDir string, lf Lockfile, env ...string) (*InstallResult, error) {
 	// Check if command exists in PATH
 	cmdPath, err := exec.LookPath(lf.Command)
 	if err != nil {
@@ -104,6 +105,9 @@ func runInstallCommand(workDir string, lf Lockfile) (*InstallResult, error) {
 	// Build and execute the command
 	cmd := exec.Command(cmdPath, lf.Args...)
 	cmd.Dir = workDir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	// Capture combined output
 	output, err := cmd.CombinedOutput()
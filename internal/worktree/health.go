@@ -0,0 +1,294 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// Worktree problems found by Check.
+const (
+	// IssueBrokenLink is a worktree directory without a valid .git file.
+	IssueBrokenLink = "broken_link"
+
+	// IssueStaleEntry is a worktree git still lists whose directory or .git file is gone.
+	IssueStaleEntry = "stale_entry"
+
+	// IssueLockFile is a git lock file, usually left by a crashed git command.
+	IssueLockFile = "lock_file"
+
+	// IssueDetached is a worktree whose HEAD is detached from the plan branch.
+	IssueDetached = "detached"
+
+	// IssueInterruptedOp is a merge, rebase, am, cherry-pick, or revert left
+	// half-done, usually by a git command or agent that died mid-way.
+	IssueInterruptedOp = "interrupted_op"
+)
+
+// StaleLockAge is how old a lock file must be before `ralph worktree repair`
+// removes it, so a git command that is still running isn't disturbed.
+const StaleLockAge = time.Minute
+
+// Issue is a problem with a plan's worktree.
+type Issue struct {
+	// Kind is the kind of problem (see Issue* constants).
+	Kind string
+
+	// Path is the worktree or file the problem concerns.
+	Path string
+
+	// Detail describes the problem and, after Repair, what was done about it.
+	Detail string
+
+	// Repaired is true if Repair fixed the problem.
+	Repaired bool
+
+	// Recreated is true if Repair re-added the worktree from the plan
+	// branch, so its checkout setup and init hooks need to run again.
+	Recreated bool
+
+	// Operation is the interrupted git operation (e.g. "rebase") of an
+	// IssueInterruptedOp.
+	Operation string
+}
+
+// String formats the issue for display.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Kind, i.Detail)
+}
+
+// Unrepaired returns the issues Repair couldn't fix.
+func Unrepaired(issues []Issue) []Issue {
+	var unrepaired []Issue
+	for _, issue := range issues {
+		if !issue.Repaired {
+			unrepaired = append(unrepaired, issue)
+		}
+	}
+	return unrepaired
+}
+
+// Check looks for problems that make git fail in the plan's worktree: a
+// missing or broken .git file, stale worktree entries, lock files, a
+// detached HEAD, and an interrupted merge or rebase. A worktree that
+// doesn't exist yet has no problems of its own.
+func (m *WorktreeManager) Check(p *plan.Plan) ([]Issue, error) {
+	worktrees, err := m.git.ListWorktrees()
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	path := m.Path(p)
+	exists := m.Exists(p)
+	linked := exists && git.IsLinkedWorktree(path)
+
+	var issues []Issue
+	if exists && !linked {
+		issues = append(issues, Issue{Kind: IssueBrokenLink, Path: path, Detail: path + " has no valid .git file"})
+	}
+	for _, wt := range worktrees {
+		// A broken worktree of our own is covered by IssueBrokenLink
+		if !wt.Prunable || (exists && samePath(wt.Path, path)) {
+			continue
+		}
+		issues = append(issues, Issue{Kind: IssueStaleEntry, Path: wt.Path, Detail: fmt.Sprintf("stale entry for %s (%s)", wt.Path, wt.PruneReason)})
+	}
+	if !linked {
+		return issues, nil
+	}
+
+	gitDir, err := git.WorktreeGitDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, lock := range m.lockFiles(gitDir, p.Branch) {
+		info, err := os.Stat(lock)
+		if err != nil {
+			continue
+		}
+		age := time.Since(info.ModTime()).Round(time.Second)
+		issues = append(issues, Issue{Kind: IssueLockFile, Path: lock, Detail: fmt.Sprintf("lock file %s (%s old)", lock, age)})
+	}
+
+	// Checked after the lock files, which Repair removes before aborting.
+	// A rebase detaches HEAD until it's done, so an interrupted operation
+	// stands in for a detached HEAD.
+	op := git.InterruptedOperation(gitDir)
+	if op != "" {
+		issues = append(issues, Issue{Kind: IssueInterruptedOp, Path: path, Operation: op, Detail: fmt.Sprintf("%s in progress in %s", op, path)})
+	}
+
+	for _, wt := range worktrees {
+		if op == "" && samePath(wt.Path, path) && wt.Branch == "" && !wt.Bare {
+			issues = append(issues, Issue{Kind: IssueDetached, Path: path, Detail: fmt.Sprintf("HEAD detached at %s instead of %s", shortCommit(wt.Commit), p.Branch)})
+		}
+	}
+	return issues, nil
+}
+
+// Repair checks the plan's worktree and fixes what it can: a broken .git
+// file is relinked with git worktree repair, or if that fails the directory
+// is moved aside and the worktree re-added from the plan branch; stale
+// entries are pruned; lock files older than lockAge are removed (0 removes
+// all); an interrupted operation is aborted if abortOps is set; and a
+// detached HEAD is checked out onto the plan branch if that loses no
+// commits. Returns every issue found, marked Repaired if fixed.
+func (m *WorktreeManager) Repair(p *plan.Plan, lockAge time.Duration, abortOps bool) ([]Issue, error) {
+	issues, err := m.Check(p)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := false
+	for i := range issues {
+		issue := &issues[i]
+		switch issue.Kind {
+		case IssueBrokenLink:
+			issue.Detail, issue.Repaired, issue.Recreated = m.relink(p)
+			pruned = issue.Repaired
+
+		case IssueStaleEntry:
+			if !pruned {
+				if err := m.git.PruneWorktrees(); err != nil {
+					issue.Detail += "; " + err.Error()
+					continue
+				}
+				pruned = true
+			}
+			issue.Repaired = true
+			issue.Detail += "; pruned"
+
+		case IssueLockFile:
+			info, err := os.Stat(issue.Path)
+			if err != nil {
+				issue.Repaired = true
+				issue.Detail += "; already gone"
+				continue
+			}
+			if time.Since(info.ModTime()) < lockAge {
+				issue.Detail += "; left in place, a git command may still be running"
+				continue
+			}
+			if err := os.Remove(issue.Path); err != nil {
+				issue.Detail += "; " + err.Error()
+				continue
+			}
+			issue.Repaired = true
+			issue.Detail += "; removed"
+
+		case IssueInterruptedOp:
+			if !abortOps {
+				issue.Detail += fmt.Sprintf("; left in place, finish it or run `git %s --abort` there", issue.Operation)
+				continue
+			}
+			if err := git.NewGit(issue.Path).AbortOperation(issue.Operation); err != nil {
+				issue.Detail += "; " + err.Error()
+				continue
+			}
+			issue.Repaired = true
+			issue.Detail += "; aborted"
+
+		case IssueDetached:
+			issue.Detail, issue.Repaired = m.reattach(p, issue.Detail)
+		}
+	}
+	return issues, nil
+}
+
+// relink repairs a worktree directory whose .git file is missing or broken.
+// Returns what was done, whether the worktree is usable again, and whether
+// it was re-created.
+func (m *WorktreeManager) relink(p *plan.Plan) (string, bool, bool) {
+	path := m.Path(p)
+	if err := m.git.RepairWorktree(path); err == nil {
+		return path + " relinked with git worktree repair", true, false
+	}
+
+	// The worktree's entry in the repository is gone too: keep the old
+	// directory for any uncommitted work and re-add the worktree
+	backup := fmt.Sprintf("%s.broken-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, backup); err != nil {
+		return fmt.Sprintf("%s has no valid .git file and could not be moved aside: %v", path, err), false, false
+	}
+	if err := m.git.PruneWorktrees(); err != nil {
+		return fmt.Sprintf("%s moved to %s but pruning failed: %v", path, backup, err), false, false
+	}
+	if err := m.git.CreateWorktree(path, p.Branch); err != nil {
+		return fmt.Sprintf("%s moved to %s but re-adding the worktree failed: %v", path, backup, err), false, false
+	}
+
+	// Carry over ralph's execution state so the plan resumes where it was
+	for _, name := range []string{"context.json", "checkpoint.json"} {
+		src := filepath.Join(backup, ".ralph", name)
+		if _, err := os.Stat(src); err == nil {
+			os.MkdirAll(filepath.Join(path, ".ralph"), 0755)
+			copyFile(src, filepath.Join(path, ".ralph", name))
+		}
+	}
+	return fmt.Sprintf("%s re-created from %s; the old directory, with any uncommitted changes, is at %s", path, p.Branch, backup), true, true
+}
+
+// reattach checks out the plan branch in a worktree with a detached HEAD,
+// unless HEAD has commits the branch doesn't.
+func (m *WorktreeManager) reattach(p *plan.Plan, detail string) (string, bool) {
+	wtGit := git.NewGit(m.Path(p))
+	head, err := wtGit.HeadCommit()
+	if err != nil {
+		return detail + "; " + err.Error(), false
+	}
+	onBranch, err := wtGit.IsAncestor(head, p.Branch)
+	if err != nil {
+		return detail + "; " + err.Error(), false
+	}
+	if !onBranch {
+		return detail + "; HEAD has commits not on the branch, check it out by hand", false
+	}
+	if err := wtGit.Checkout(p.Branch); err != nil {
+		return detail + "; " + err.Error(), false
+	}
+	return detail + "; checked out " + p.Branch, true
+}
+
+// lockFiles returns the lock files that block git in a worktree: its index
+// and HEAD locks, and the lock on the plan branch in the shared repository.
+func (m *WorktreeManager) lockFiles(gitDir, branch string) []string {
+	commonDir := filepath.Join(m.repoRoot, ".git")
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return []string{
+		filepath.Join(gitDir, "index.lock"),
+		filepath.Join(gitDir, "HEAD.lock"),
+		filepath.Join(commonDir, "refs", "heads", filepath.FromSlash(branch)+".lock"),
+	}
+}
+
+// samePath reports whether two paths name the same directory, resolving
+// symlinks (macOS /tmp vs /private/var).
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return ra == rb
+}
+
+// shortCommit abbreviates a commit SHA for display.
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,242 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// runTestGit runs a git command in dir with a test identity.
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := execCommand("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setupHealthTest creates a repo with a worktree for a plan.
+func setupHealthTest(t *testing.T) (*WorktreeManager, *plan.Plan) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	runTestGit(t, tmpDir, "init", "-q", "-b", "main")
+	runTestGit(t, tmpDir, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	m, err := NewManager(git.NewGit(tmpDir), ".ralph/worktrees")
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	p := &plan.Plan{Name: "health", Branch: "feat/health"}
+	if _, err := m.Create(p); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	return m, p
+}
+
+// issueKinds returns the kinds of the issues.
+func issueKinds(issues []Issue) []string {
+	var kinds []string
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func TestManager_Check_Healthy(t *testing.T) {
+	m, p := setupHealthTest(t)
+	issues, err := m.Check(p)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Check() = %v, want no issues", issues)
+	}
+
+	// A worktree that doesn't exist yet is not a problem
+	issues, err = m.Check(&plan.Plan{Name: "other", Branch: "feat/other"})
+	if err != nil || len(issues) != 0 {
+		t.Errorf("Check() of a missing worktree = %v, %v", issues, err)
+	}
+}
+
+func TestManager_Repair_MissingGitFile(t *testing.T) {
+	m, p := setupHealthTest(t)
+	path := m.Path(p)
+	os.Remove(filepath.Join(path, ".git"))
+
+	issues, err := m.Repair(p, 0, true)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Kind != IssueBrokenLink || !issues[0].Repaired || issues[0].Recreated {
+		t.Fatalf("Repair() = %+v, want a repaired broken link", issues)
+	}
+	if branch, err := git.NewGit(path).CurrentBranch(); err != nil || branch != p.Branch {
+		t.Errorf("CurrentBranch() = %q, %v after repair", branch, err)
+	}
+}
+
+func TestManager_Repair_ReAddsPrunedWorktree(t *testing.T) {
+	m, p := setupHealthTest(t)
+	path := m.Path(p)
+	os.MkdirAll(filepath.Join(path, ".ralph"), 0755)
+	os.WriteFile(filepath.Join(path, ".ralph", "context.json"), []byte(`{"iteration": 4}`), 0644)
+	os.WriteFile(filepath.Join(path, "wip.txt"), []byte("uncommitted"), 0644)
+
+	// Break both sides of the link, as after a crash and a `git worktree prune`
+	os.Remove(filepath.Join(path, ".git"))
+	runTestGit(t, m.RepoRoot(), "worktree", "prune")
+
+	issues, err := m.Repair(p, 0, true)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(issues) != 1 || !issues[0].Repaired || !issues[0].Recreated || !strings.Contains(issues[0].Detail, "re-created from feat/health") {
+		t.Fatalf("Repair() = %+v, want a re-created worktree", issues)
+	}
+	if !git.IsLinkedWorktree(path) {
+		t.Error("worktree should be linked after repair")
+	}
+	if data, _ := os.ReadFile(filepath.Join(path, ".ralph", "context.json")); string(data) != `{"iteration": 4}` {
+		t.Errorf("context.json = %q, want it carried over", data)
+	}
+
+	backups, _ := filepath.Glob(path + ".broken-*")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want one", backups)
+	}
+	if _, err := os.Stat(filepath.Join(backups[0], "wip.txt")); err != nil {
+		t.Errorf("uncommitted work should be kept in the backup: %v", err)
+	}
+}
+
+func TestManager_Repair_StaleEntry(t *testing.T) {
+	m, p := setupHealthTest(t)
+	other := &plan.Plan{Name: "other", Branch: "feat/other"}
+	if _, err := m.Create(other); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	os.RemoveAll(m.Path(other))
+
+	issues, err := m.Check(p)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if got := issueKinds(issues); len(got) != 1 || got[0] != IssueStaleEntry {
+		t.Fatalf("Check() kinds = %v, want [%s]", got, IssueStaleEntry)
+	}
+
+	issues, _ = m.Repair(p, 0, true)
+	if len(Unrepaired(issues)) != 0 {
+		t.Errorf("Repair() left %v", Unrepaired(issues))
+	}
+
+	// The branch is free again, so the worktree can be re-created
+	if _, err := m.Create(other); err != nil {
+		t.Errorf("Create after prune failed: %v", err)
+	}
+}
+
+func TestManager_Repair_LockFiles(t *testing.T) {
+	m, p := setupHealthTest(t)
+	gitDir, err := git.WorktreeGitDir(m.Path(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := filepath.Join(gitDir, "index.lock")
+	os.WriteFile(lock, nil, 0644)
+
+	// A fresh lock may belong to a running git command
+	issues, _ := m.Repair(p, time.Hour, true)
+	if len(issues) != 1 || issues[0].Kind != IssueLockFile || issues[0].Repaired {
+		t.Fatalf("Repair(1h) = %+v, want an unrepaired lock file", issues)
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Fatal("a fresh lock file should be left in place")
+	}
+
+	issues, _ = m.Repair(p, 0, true)
+	if len(issues) != 1 || !issues[0].Repaired {
+		t.Fatalf("Repair(0) = %+v, want a removed lock file", issues)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Error("lock file should be removed")
+	}
+}
+
+func TestManager_Repair_Detached(t *testing.T) {
+	m, p := setupHealthTest(t)
+	path := m.Path(p)
+	runTestGit(t, path, "commit", "-q", "--allow-empty", "-m", "work")
+	runTestGit(t, path, "checkout", "-q", "--detach", "HEAD~1")
+
+	issues, _ := m.Repair(p, 0, true)
+	if len(issues) != 1 || issues[0].Kind != IssueDetached || !issues[0].Repaired {
+		t.Fatalf("Repair() = %+v, want a repaired detached HEAD", issues)
+	}
+	if branch, _ := git.NewGit(path).CurrentBranch(); branch != p.Branch {
+		t.Errorf("CurrentBranch() = %q, want %q", branch, p.Branch)
+	}
+
+	// Commits made on a detached HEAD would be lost by a checkout
+	runTestGit(t, path, "checkout", "-q", "--detach")
+	runTestGit(t, path, "commit", "-q", "--allow-empty", "-m", "detached work")
+	issues, _ = m.Repair(p, 0, true)
+	if len(issues) != 1 || issues[0].Repaired || !strings.Contains(issues[0].Detail, "commits not on the branch") {
+		t.Fatalf("Repair() = %+v, want an unrepaired detached HEAD", issues)
+	}
+}
+
+func TestManager_Repair_InterruptedRebase(t *testing.T) {
+	m, p := setupHealthTest(t)
+	path := m.Path(p)
+	os.WriteFile(filepath.Join(path, "a.txt"), []byte("plan\n"), 0644)
+	runTestGit(t, path, "add", "a.txt")
+	runTestGit(t, path, "commit", "-q", "-m", "plan work")
+	before := runTestGit(t, path, "rev-parse", "HEAD")
+	os.WriteFile(filepath.Join(m.repoRoot, "a.txt"), []byte("main\n"), 0644)
+	runTestGit(t, m.repoRoot, "add", "a.txt")
+	runTestGit(t, m.repoRoot, "commit", "-q", "-m", "main work")
+
+	// The rebase stops on the conflict, detaching HEAD
+	cmd := execCommand("git", "rebase", "main")
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com")
+	if err := cmd.Run(); err == nil {
+		t.Fatal("rebase succeeded, want a conflict")
+	}
+
+	// Without abortOps it's reported but left alone
+	issues, err := m.Repair(p, 0, false)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Kind != IssueInterruptedOp || issues[0].Operation != git.OpRebase || issues[0].Repaired {
+		t.Fatalf("Repair(abortOps=false) = %+v, want an unrepaired rebase", issues)
+	}
+	if !strings.Contains(issues[0].Detail, "git rebase --abort") {
+		t.Errorf("Detail = %q, want the abort command", issues[0].Detail)
+	}
+
+	issues, _ = m.Repair(p, 0, true)
+	if len(issues) != 1 || !issues[0].Repaired {
+		t.Fatalf("Repair() = %+v, want an aborted rebase", issues)
+	}
+	if head := runTestGit(t, path, "rev-parse", "HEAD"); head != before {
+		t.Errorf("HEAD = %s after abort, want %s", head, before)
+	}
+	if issues, _ := m.Check(p); len(issues) != 0 {
+		t.Errorf("Check() after repair = %v", issues)
+	}
+}
@@ -15,10 +15,13 @@ import (
 // hookFileName is the name of the custom worktree initialization hook.
 const hookFileName = "worktree-init"
 
+// completeHookFileName is the name of the custom worktree completion hook.
+const completeHookFileName = "worktree-complete"
+
 // HookResult contains the result of running init hooks.
 type HookResult struct {
 	// Method describes how initialization was performed.
-	// One of: "hook", "init_commands", "auto_detect", "none"
+	// One of: "hook", "init_commands", "preset", "auto_detect", "none"
 	Method string
 
 	// Command is the command that was run (if any).
@@ -33,7 +36,9 @@ type HookResult struct {
 //
 //  1. Custom hook: .ralph/hooks/worktree-init (if executable)
 //  2. Init commands: config.worktree.init_commands (if set)
-//  3. Auto-detection: DetectAndInstall (if no hook or init_commands)
+//  3. Preset: config.worktree.preset (if set)
+//  4. Auto-detection: the preset detected from go.mod, package.json,
+//     pyproject.toml, ... or else DetectAndInstall
 //
 // The mainWorktreePath is set as MAIN_WORKTREE environment variable for hooks.
 func RunInitHooks(worktreePath string, cfg *config.Config, mainWorktreePath string) (*HookResult, error) {
@@ -64,8 +69,22 @@ func RunInitHooks(worktreePath string, cfg *config.Config, mainWorktreePath stri
 	}
 	log.Debug("No init_commands configured")
 
-	// 3. Fall back to auto-detection
+	// 3. Check for a configured preset
+	if cfg != nil && cfg.Worktree.Preset != "" {
+		preset := GetPreset(cfg.Worktree.Preset)
+		if preset == nil {
+			return &HookResult{Method: "preset"}, fmt.Errorf("unknown worktree preset %q", cfg.Worktree.Preset)
+		}
+		log.Info("Running %s preset...", preset.Name)
+		return runPreset(preset, "preset", worktreePath, mainWorktreePath)
+	}
+
+	// 4. Fall back to auto-detection
 	log.Debug("Falling back to dependency auto-detection...")
+	if preset := DetectPreset(worktreePath); preset != nil {
+		log.Debug("Detected %s preset", preset.Name)
+		return runPreset(preset, "auto_detect", worktreePath, mainWorktreePath)
+	}
 	result, err := DetectAndInstall(worktreePath)
 	if err != nil {
 		return &HookResult{Method: "auto_detect", Command: result.Command, Output: result.Output}, err
@@ -79,6 +98,56 @@ func RunInitHooks(worktreePath string, cfg *config.Config, mainWorktreePath stri
 	return &HookResult{Method: "auto_detect", Command: result.Command, Output: result.Output}, nil
 }
 
+// runPreset runs the preset's install command, reporting it as method.
+func runPreset(preset *Preset, method, worktreePath, mainWorktreePath string) (*HookResult, error) {
+	result, err := preset.Install(worktreePath, mainWorktreePath)
+	if result == nil {
+		if err != nil {
+			return &HookResult{Method: method}, err
+		}
+		return &HookResult{Method: "none"}, nil
+	}
+	return &HookResult{Method: method, Command: result.Command, Output: result.Output}, err
+}
+
+// RunCompleteHooks runs completion hooks in a worktree after the plan completes,
+// before the worktree is removed. It mirrors RunInitHooks:
+//
+//  1. Custom hook: .ralph/hooks/worktree-complete (if executable)
+//  2. Complete hooks: config.worktree.complete_hooks (if set)
+//
+// There is no auto-detection fallback. env is passed to the hook in addition to
+// MAIN_WORKTREE (typically the RALPH_* variables from hooks.Payload.Env).
+func RunCompleteHooks(worktreePath string, cfg *config.Config, mainWorktreePath string, env []string) (*HookResult, error) {
+	log.Debug("Running worktree complete hooks for: %s", worktreePath)
+
+	// 1. Check for custom hook file
+	hookPath := filepath.Join(mainWorktreePath, ".ralph", "hooks", completeHookFileName)
+	if isExecutable(hookPath) {
+		log.Info("Running custom worktree-complete hook...")
+		output, err := runHook(hookPath, worktreePath, mainWorktreePath, env...)
+		if err != nil {
+			return &HookResult{Method: "hook", Command: hookPath, Output: output}, err
+		}
+		log.Success("Custom complete hook completed successfully")
+		return &HookResult{Method: "hook", Command: hookPath, Output: output}, nil
+	}
+	log.Debug("No executable complete hook found at: %s", hookPath)
+
+	// 2. Check for complete_hooks in config
+	if cfg != nil && cfg.Worktree.CompleteHooks != "" {
+		log.Info("Running complete hooks from config...")
+		output, err := runInitCommands(cfg.Worktree.CompleteHooks, worktreePath, mainWorktreePath, env...)
+		if err != nil {
+			return &HookResult{Method: "complete_hooks", Command: cfg.Worktree.CompleteHooks, Output: output}, err
+		}
+		log.Success("Complete hooks finished successfully")
+		return &HookResult{Method: "complete_hooks", Command: cfg.Worktree.CompleteHooks, Output: output}, nil
+	}
+
+	return &HookResult{Method: "none"}, nil
+}
+
 // isExecutable checks if a file exists and is executable.
 func isExecutable(path string) bool {
 	info, err := os.Stat(path)
@@ -97,7 +166,8 @@ func isExecutable(path string) bool {
 }
 
 // runHook executes the custom hook script with proper environment.
-func runHook(hookPath, worktreePath, mainWorktreePath string) (string, error) {
+// extraEnv is appended to the environment after MAIN_WORKTREE.
+func runHook(hookPath, worktreePath, mainWorktreePath string, extraEnv ...string) (string, error) {
 	log.Debug("Executing hook: %s", hookPath)
 	log.Debug("  Working directory: %s", worktreePath)
 	log.Debug("  MAIN_WORKTREE: %s", mainWorktreePath)
@@ -105,6 +175,7 @@ func runHook(hookPath, worktreePath, mainWorktreePath string) (string, error) {
 	cmd := exec.Command(hookPath)
 	cmd.Dir = worktreePath
 	cmd.Env = append(os.Environ(), "MAIN_WORKTREE="+mainWorktreePath)
+	cmd.Env = append(cmd.Env, extraEnv...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -115,7 +186,8 @@ func runHook(hookPath, worktreePath, mainWorktreePath string) (string, error) {
 }
 
 // runInitCommands executes the init_commands string in a shell.
-func runInitCommands(commands, worktreePath, mainWorktreePath string) (string, error) {
+// extraEnv is appended to the environment after MAIN_WORKTREE.
+func runInitCommands(commands, worktreePath, mainWorktreePath string, extraEnv ...string) (string, error) {
 	log.Debug("Executing init commands: %s", commands)
 	log.Debug("  Working directory: %s", worktreePath)
 	log.Debug("  MAIN_WORKTREE: %s", mainWorktreePath)
@@ -130,6 +202,7 @@ func runInitCommands(commands, worktreePath, mainWorktreePath string) (string, e
 
 	cmd.Dir = worktreePath
 	cmd.Env = append(os.Environ(), "MAIN_WORKTREE="+mainWorktreePath)
+	cmd.Env = append(cmd.Env, extraEnv...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
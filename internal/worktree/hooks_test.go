@@ -438,3 +438,86 @@ func TestRunInitHooks_NilConfig(t *testing.T) {
 		t.Errorf("Method = %q, want 'none'", result.Method)
 	}
 }
+
+func TestRunCompleteHooks_CustomHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	hooksDir := filepath.Join(mainDir, ".ralph", "hooks")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hookScript := `#!/bin/sh
+echo "complete $RALPH_PLAN"
+`
+	hookPath := filepath.Join(hooksDir, completeHookFileName)
+	if err := os.WriteFile(hookPath, []byte(hookScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RunCompleteHooks(worktreeDir, &config.Config{}, mainDir, []string{"RALPH_PLAN=my-plan"})
+	if err != nil {
+		t.Fatalf("RunCompleteHooks failed: %v", err)
+	}
+
+	if result.Method != "hook" {
+		t.Errorf("Method = %q, want 'hook'", result.Method)
+	}
+	if !strings.Contains(result.Output, "complete my-plan") {
+		t.Errorf("Output should contain plan env, got %q", result.Output)
+	}
+}
+
+func TestRunCompleteHooks_ConfigCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell command test on Windows")
+	}
+
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	cfg := &config.Config{
+		Worktree: config.WorktreeConfig{
+			CompleteHooks: "echo \"$RALPH_BRANCH\" > complete-marker.txt",
+		},
+	}
+
+	result, err := RunCompleteHooks(worktreeDir, cfg, mainDir, []string{"RALPH_BRANCH=feat/x"})
+	if err != nil {
+		t.Fatalf("RunCompleteHooks failed: %v", err)
+	}
+
+	if result.Method != "complete_hooks" {
+		t.Errorf("Method = %q, want 'complete_hooks'", result.Method)
+	}
+
+	data, err := os.ReadFile(filepath.Join(worktreeDir, "complete-marker.txt"))
+	if err != nil {
+		t.Fatalf("complete hooks did not create marker file: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "feat/x" {
+		t.Errorf("marker = %q, want %q", strings.TrimSpace(string(data)), "feat/x")
+	}
+}
+
+func TestRunCompleteHooks_NoMethod(t *testing.T) {
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	// No auto-detection for completion - nothing configured means nothing runs
+	if err := os.WriteFile(filepath.Join(worktreeDir, "go.sum"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RunCompleteHooks(worktreeDir, &config.Config{}, mainDir, nil)
+	if err != nil {
+		t.Fatalf("RunCompleteHooks failed: %v", err)
+	}
+	if result.Method != "none" {
+		t.Errorf("Method = %q, want 'none'", result.Method)
+	}
+}
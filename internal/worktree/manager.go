@@ -63,11 +63,26 @@ func NewManager(g git.Git, baseDir string) (*WorktreeManager, error) {
 }
 
 // Path returns the worktree path for a plan.
-// The path is: <baseDir>/<branch-name> (without feat/ prefix for cleaner directory names).
+// The path is: <baseDir>/<plan-slug>, named after the plan rather than its
+// branch so it doesn't change with git.branch_template (and matches the
+// branch name without the feat/ prefix by default).
 func (m *WorktreeManager) Path(p *plan.Plan) string {
-	// Use branch name without the feat/ prefix for shorter directory names
-	dirName := strings.TrimPrefix(p.Branch, "feat/")
-	return filepath.Join(m.baseDir, dirName)
+	return filepath.Join(m.baseDir, plan.Slug(p.Name))
+}
+
+// LivePlan returns the worktree's copy of a plan, which the agent updates
+// during a run, found the way SyncFromWorktree finds it. Returns p if the
+// plan has no worktree copy.
+func (m *WorktreeManager) LivePlan(p *plan.Plan) *plan.Plan {
+	rel, err := filepath.Rel(m.repoRoot, p.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Join("plans", "current", filepath.Base(p.Path))
+	}
+	live, err := plan.Load(filepath.Join(m.Path(p), rel))
+	if err != nil {
+		return p
+	}
+	return live
 }
 
 // Exists checks if a worktree exists for the given plan.
@@ -234,8 +249,7 @@ func (m *WorktreeManager) Cleanup(queue *plan.Queue) ([]CleanupResult, error) {
 	}
 	for _, p := range pending {
 		// Map plan name to directory name (matches Path() logic)
-		dirName := strings.TrimPrefix(p.Branch, "feat/")
-		activePlans[dirName] = true
+		activePlans[plan.Slug(p.Name)] = true
 	}
 
 	current, err := queue.Current()
@@ -243,8 +257,7 @@ func (m *WorktreeManager) Cleanup(queue *plan.Queue) ([]CleanupResult, error) {
 		return nil, fmt.Errorf("getting current plan: %w", err)
 	}
 	if current != nil {
-		dirName := strings.TrimPrefix(current.Branch, "feat/")
-		activePlans[dirName] = true
+		activePlans[plan.Slug(current.Name)] = true
 	}
 
 	// Check each directory in baseDir
@@ -40,6 +40,7 @@ func (m *mockGit) Push() error                                         { return
 func (m *mockGit) PushWithUpstream(remote, branch string) error        { return nil }
 func (m *mockGit) Pull() error                                         { return nil }
 func (m *mockGit) CurrentBranch() (string, error)                      { return "main", nil }
+func (m *mockGit) HeadCommit() (string, error)                         { return "", nil }
 func (m *mockGit) CreateBranch(name string) error                      { m.branches[name] = true; return nil }
 func (m *mockGit) DeleteBranch(name string, force bool) error          {
 	if m.deleteBranchErr != nil {
@@ -52,6 +53,7 @@ func (m *mockGit) DeleteBranch(name string, force bool) error          {
 	return nil
 }
 func (m *mockGit) DeleteRemoteBranch(remote, branch string) error      { return nil }
+func (m *mockGit) PruneRemote(remote string) error                     { return nil }
 func (m *mockGit) BranchExists(name string) (bool, error)              { return m.branches[name], nil }
 func (m *mockGit) Checkout(branch string) error                        { return nil }
 func (m *mockGit) Merge(branch string, noFastForward bool) error       { return nil }
@@ -90,6 +92,22 @@ func (m *mockGit) RemoveWorktree(path string) error {
 	return git.ErrWorktreeNotFound
 }
 
+func (m *mockGit) DiffFiles(from, to string) ([]git.FileChange, error) { return nil, nil }
+func (m *mockGit) UncommittedStats() ([]git.FileStat, error)         { return nil, nil }
+func (m *mockGit) StagedChanges() ([]git.FileChange, error)           { return nil, nil }
+func (m *mockGit) Unstage(files ...string) error                      { return nil }
+func (m *mockGit) Restore(files ...string) error                      { return nil }
+func (m *mockGit) PruneWorktrees() error                              { return nil }
+func (m *mockGit) RepairWorktree(path string) error                   { return nil }
+func (m *mockGit) IsAncestor(ancestor, descendant string) (bool, error) { return true, nil }
+func (m *mockGit) LFSTracked(files ...string) (map[string]bool, error)  { return nil, nil }
+func (m *mockGit) Log(revRange string, n int) (string, error)          { return "", nil }
+func (m *mockGit) CommitsWithTrailers(string, map[string]string) ([]string, error) { return nil, nil }
+func (m *mockGit) UpstreamDivergence() (string, int, int, error)      { return "", 0, 0, nil }
+func (m *mockGit) AbortOperation(string) error { return nil }
+func (m *mockGit) CommitSnapshot(string, string, map[string]string) (string, error) { return "", nil }
+func (m *mockGit) Diff(from, to string) (string, error)                 { return "", nil }
+func (m *mockGit) RemoteURL(remote string) (string, error)              { return "", nil }
 func (m *mockGit) ListWorktrees() ([]git.WorktreeInfo, error) {
 	return m.worktrees, nil
 }
@@ -154,6 +172,24 @@ func TestManager_Path(t *testing.T) {
 	}
 }
 
+func TestManager_LivePlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	m, _ := NewManager(newMockGit(tmpDir), ".ralph/worktrees")
+
+	p := &plan.Plan{Name: "my-plan", Path: filepath.Join(tmpDir, "plans", "current", "my-plan.md")}
+	if got := m.LivePlan(p); got != p {
+		t.Errorf("LivePlan() without a worktree copy = %+v, want the plan itself", got)
+	}
+
+	copyPath := filepath.Join(m.Path(p), "plans", "current", "my-plan.md")
+	os.MkdirAll(filepath.Dir(copyPath), 0755)
+	os.WriteFile(copyPath, []byte("# Plan: My Plan\n\n- [x] one\n- [ ] two\n"), 0644)
+	got := m.LivePlan(p)
+	if got.Path != copyPath || plan.CountComplete(got.Tasks) != 1 {
+		t.Errorf("LivePlan() = %+v, want the worktree copy", got)
+	}
+}
+
 func TestManager_Exists_NotExists(t *testing.T) {
 	tmpDir := t.TempDir()
 	g := newMockGit(tmpDir)
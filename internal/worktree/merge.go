@@ -0,0 +1,199 @@
+package worktree
+
+import (
+	"strings"
+)
+
+// section is a markdown section: a heading line and the lines up to the
+// next heading. The preamble before the first heading has an empty heading.
+type section struct {
+	heading string
+	text    string
+}
+
+// splitSections splits markdown into sections at heading lines, ignoring
+// "#" lines inside fenced code blocks. Returns false if two sections share a
+// heading, since they can't be matched up between versions.
+func splitSections(content string) ([]section, bool) {
+	var sections []section
+	seen := make(map[string]bool)
+	current := section{}
+	var text strings.Builder
+	inFence := false
+
+	flush := func() bool {
+		current.text = text.String()
+		text.Reset()
+		if current.heading == "" && current.text == "" {
+			return true
+		}
+		if seen[current.heading] {
+			return false
+		}
+		seen[current.heading] = true
+		sections = append(sections, current)
+		return true
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(line, "#") {
+			if !flush() {
+				return nil, false
+			}
+			current = section{heading: strings.TrimSpace(line)}
+		}
+		text.WriteString(line)
+	}
+	if !flush() {
+		return nil, false
+	}
+	return sections, true
+}
+
+// mergeSections merges the changes to a markdown file made in two copies
+// since base, section by section: a section changed in only one copy takes
+// that change, a section added in one copy is kept, and a section deleted in
+// one copy and unchanged in the other is dropped. Returns false if both
+// copies changed the same section differently, or a heading is repeated.
+// Sections are ordered as in theirs, with sections only in ours placed
+// after the section that precedes them there.
+func mergeSections(base, ours, theirs string) (string, bool) {
+	baseSections, ok1 := splitSections(base)
+	ourSections, ok2 := splitSections(ours)
+	theirSections, ok3 := splitSections(theirs)
+	if !ok1 || !ok2 || !ok3 {
+		return "", false
+	}
+
+	baseText := sectionMap(baseSections)
+	ourText := sectionMap(ourSections)
+	theirText := sectionMap(theirSections)
+
+	// resolve returns the merged text of a section and whether to keep it
+	resolve := func(heading string) (string, bool, bool) {
+		b, inBase := baseText[heading]
+		o, inOurs := ourText[heading]
+		t, inTheirs := theirText[heading]
+		switch {
+		case inOurs && inTheirs:
+			switch {
+			case o == t || o == b:
+				return t, true, true
+			case t == b:
+				return o, true, true
+			default:
+				return "", false, false
+			}
+		case inTheirs:
+			// Added by theirs, or deleted by ours
+			if !inBase {
+				return t, true, true
+			}
+			return "", false, t == b
+		case inOurs:
+			if !inBase {
+				return o, true, true
+			}
+			return "", false, o == b
+		}
+		return "", false, true
+	}
+
+	// Order: theirs, then sections only in ours after their predecessor
+	order := make([]string, 0, len(theirSections)+len(ourSections))
+	for _, s := range theirSections {
+		order = append(order, s.heading)
+	}
+	for i, s := range ourSections {
+		if _, ok := theirText[s.heading]; ok {
+			continue
+		}
+		at := 0
+		if i > 0 {
+			at = indexOf(order, ourSections[i-1].heading) + 1
+		}
+		order = append(order[:at], append([]string{s.heading}, order[at:]...)...)
+	}
+
+	var merged strings.Builder
+	for _, heading := range order {
+		text, keep, ok := resolve(heading)
+		if !ok {
+			return "", false
+		}
+		if keep {
+			merged.WriteString(ensureNewline(text))
+		}
+	}
+	return merged.String(), true
+}
+
+// sectionMap maps section headings to their text.
+func sectionMap(sections []section) map[string]string {
+	m := make(map[string]string, len(sections))
+	for _, s := range sections {
+		m[s.heading] = s.text
+	}
+	return m
+}
+
+// indexOf returns the index of s in list, or -1.
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// ensureNewline returns text ending in a newline, so a section that was last
+// in one copy doesn't run into the next heading when it isn't last anymore.
+func ensureNewline(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		return text + "\n"
+	}
+	return text
+}
+
+// changedSections returns the names of the sections added, changed, or
+// removed between two versions of a markdown file, without the leading "#"s.
+// Returns nil if either version can't be split into sections.
+func changedSections(before, after string) []string {
+	beforeSections, ok1 := splitSections(before)
+	afterSections, ok2 := splitSections(after)
+	if !ok1 || !ok2 {
+		return nil
+	}
+
+	beforeText := sectionMap(beforeSections)
+	afterText := sectionMap(afterSections)
+	var names []string
+	for _, s := range afterSections {
+		if text, ok := beforeText[s.heading]; !ok || text != s.text {
+			names = append(names, sectionName(s.heading))
+		}
+	}
+	for _, s := range beforeSections {
+		if _, ok := afterText[s.heading]; !ok {
+			names = append(names, sectionName(s.heading))
+		}
+	}
+	return names
+}
+
+// sectionName returns a heading without its "#"s, or "(preamble)" for the
+// text before the first heading.
+func sectionName(heading string) string {
+	if heading == "" {
+		return "(preamble)"
+	}
+	return strings.TrimSpace(strings.TrimLeft(heading, "#"))
+}
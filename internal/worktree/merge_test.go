@@ -0,0 +1,109 @@
+package worktree
+
+import "testing"
+
+func TestSplitSections(t *testing.T) {
+	content := "Preamble\n# Plan\nintro\n## Tasks\n- [ ] one\n```sh\n# not a heading\n```\n## Notes\n"
+	sections, ok := splitSections(content)
+	if !ok {
+		t.Fatal("splitSections() failed")
+	}
+	want := []section{
+		{heading: "", text: "Preamble\n"},
+		{heading: "# Plan", text: "# Plan\nintro\n"},
+		{heading: "## Tasks", text: "## Tasks\n- [ ] one\n```sh\n# not a heading\n```\n"},
+		{heading: "## Notes", text: "## Notes\n"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("sections = %+v", sections)
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("section %d = %+v, want %+v", i, sections[i], want[i])
+		}
+	}
+
+	if _, ok := splitSections("## Notes\na\n## Notes\nb\n"); ok {
+		t.Error("repeated headings should fail")
+	}
+}
+
+func TestMergeSections(t *testing.T) {
+	base := "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n"
+	tests := []struct {
+		name   string
+		ours   string
+		theirs string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "different sections",
+			ours:   "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nUse the v2 API\n",
+			theirs: "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+			want:   "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nUse the v2 API\n",
+			wantOK: true,
+		},
+		{
+			name:   "added sections",
+			ours:   "# Plan\n## Context\nsee #12\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n",
+			theirs: "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n## Discovered\n- [ ] three\n",
+			want:   "# Plan\n## Context\nsee #12\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n## Discovered\n- [ ] three\n",
+			wantOK: true,
+		},
+		{
+			name:   "deleted section",
+			ours:   "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n",
+			theirs: "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+			want:   "# Plan\n## Tasks\n- [x] one\n- [ ] two\n",
+			wantOK: true,
+		},
+		{
+			name:   "same change",
+			ours:   "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+			theirs: "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+			want:   "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+			wantOK: true,
+		},
+		{
+			name:   "same section",
+			ours:   "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n- [ ] extra\n## Notes\nnone\n",
+			theirs: "# Plan\n## Tasks\n- [x] one\n- [ ] two\n## Notes\nnone\n",
+		},
+		{
+			name:   "deleted and changed",
+			ours:   "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n",
+			theirs: "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\ncaveat\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeSections(base, tt.ours, tt.theirs)
+			if ok != tt.wantOK {
+				t.Fatalf("mergeSections() ok = %t, want %t (got %q)", ok, tt.wantOK, got)
+			}
+			if ok && got != tt.want {
+				t.Errorf("mergeSections() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedSections(t *testing.T) {
+	before := "# Plan\n## Tasks\n- [ ] one\n## Notes\nnone\n## Old\n"
+	after := "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n## Acceptance Criteria\n- works\n"
+	got := changedSections(before, after)
+	want := []string{"Tasks", "Acceptance Criteria", "Old"}
+	if len(got) != len(want) {
+		t.Fatalf("changedSections() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changedSections()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := changedSections("## A\n## A\n", after); got != nil {
+		t.Errorf("changedSections() with repeated headings = %v, want nil", got)
+	}
+}
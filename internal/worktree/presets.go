@@ -0,0 +1,142 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// Preset is a built-in worktree setup for a language ecosystem: how to
+// install its dependencies and which environment makes them use shared caches.
+type Preset struct {
+	// Name is the value of worktree.preset, e.g. "go".
+	Name string
+
+	// Markers are files whose presence selects the preset when none is
+	// configured, e.g. go.mod.
+	Markers []string
+
+	// Installs are the install commands; the first whose lockfile exists is
+	// run. An empty Name always matches.
+	Installs []Lockfile
+
+	// Env is set for the install command and every claude run, as
+	// "KEY=value". $MAIN_WORKTREE is expanded to the main worktree.
+	Env []string
+}
+
+// presets are the built-in presets, in detection order.
+var presets = []Preset{
+	{
+		Name:    config.PresetGo,
+		Markers: []string{"go.mod"},
+		Installs: []Lockfile{
+			{Name: "go.mod", Command: "go", Args: []string{"mod", "download"}, Description: "Go modules"},
+		},
+		// The module and build caches are per user, so worktrees already
+		// share them
+	},
+	{
+		Name:    config.PresetNode,
+		Markers: []string{"package.json"},
+		Installs: []Lockfile{
+			// pnpm hard-links packages from its global store
+			{Name: "pnpm-lock.yaml", Command: "pnpm", Args: []string{"install", "--frozen-lockfile", "--prefer-offline"}, Description: "pnpm"},
+			{Name: "bun.lockb", Command: "bun", Args: []string{"install", "--frozen-lockfile"}, Description: "Bun"},
+			{Name: "yarn.lock", Command: "yarn", Args: []string{"install", "--frozen-lockfile", "--prefer-offline"}, Description: "Yarn"},
+			{Name: "package-lock.json", Command: "npm", Args: []string{"ci", "--prefer-offline"}, Description: "npm"},
+			{Command: "npm", Args: []string{"install", "--prefer-offline"}, Description: "npm"},
+		},
+		Env: []string{"npm_config_prefer_offline=true", "npm_config_audit=false", "npm_config_fund=false"},
+	},
+	{
+		Name:    config.PresetPython,
+		Markers: []string{"pyproject.toml", "requirements.txt", "setup.py"},
+		Installs: []Lockfile{
+			{Name: "uv.lock", Command: "uv", Args: []string{"sync", "--frozen"}, Description: "uv"},
+			{Name: "poetry.lock", Command: "poetry", Args: []string{"install", "--no-interaction"}, Description: "Poetry"},
+			{Name: "requirements.txt", Command: "pip", Args: []string{"install", "-r", "requirements.txt"}, Description: "pip"},
+			{Name: "pyproject.toml", Command: "pip", Args: []string{"install", "-e", "."}, Description: "pip"},
+		},
+		// Each worktree gets its own virtualenv, linked from the shared uv cache
+		Env: []string{"POETRY_VIRTUALENVS_IN_PROJECT=true", "UV_LINK_MODE=hardlink", "PIP_DISABLE_PIP_VERSION_CHECK=1"},
+	},
+	{
+		Name:    config.PresetRust,
+		Markers: []string{"Cargo.toml"},
+		Installs: []Lockfile{
+			{Name: "Cargo.lock", Command: "cargo", Args: []string{"fetch", "--locked"}, Description: "Cargo"},
+			{Command: "cargo", Args: []string{"fetch"}, Description: "Cargo"},
+		},
+		// Share compiled dependencies with the main worktree instead of
+		// rebuilding them in every worktree
+		Env: []string{"CARGO_TARGET_DIR=$MAIN_WORKTREE/target"},
+	},
+}
+
+// GetPreset returns the built-in preset with the given name, or nil.
+func GetPreset(name string) *Preset {
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i]
+		}
+	}
+	return nil
+}
+
+// DetectPreset returns the first preset whose marker file exists in dir, or nil.
+func DetectPreset(dir string) *Preset {
+	for i := range presets {
+		for _, marker := range presets[i].Markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return &presets[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ResolvePreset returns the preset for dir: worktree.preset if configured,
+// otherwise the detected one. Returns nil if there is none, or if init
+// commands or a custom hook replace the presets.
+func ResolvePreset(dir string, cfg *config.Config, mainWorktreePath string) *Preset {
+	if HookExists(mainWorktreePath) || (cfg != nil && cfg.Worktree.InitCommands != "") {
+		return nil
+	}
+	if cfg != nil && cfg.Worktree.Preset != "" {
+		return GetPreset(cfg.Worktree.Preset)
+	}
+	return DetectPreset(dir)
+}
+
+// Environ returns the preset's environment with $MAIN_WORKTREE expanded.
+func (p *Preset) Environ(mainWorktreePath string) []string {
+	env := make([]string, len(p.Env))
+	for i, kv := range p.Env {
+		env[i] = os.Expand(kv, func(name string) string {
+			if name == "MAIN_WORKTREE" {
+				return mainWorktreePath
+			}
+			return "$" + name
+		})
+	}
+	return env
+}
+
+// Install runs the preset's install command in worktreePath. Returns nil if
+// none of its lockfiles exist.
+func (p *Preset) Install(worktreePath, mainWorktreePath string) (*InstallResult, error) {
+	for _, lf := range p.Installs {
+		if lf.Name != "" {
+			if _, err := os.Stat(filepath.Join(worktreePath, lf.Name)); err != nil {
+				continue
+			}
+		}
+		log.Debug("Using %s preset: %s", p.Name, lf.Description)
+		return runInstallCommand(worktreePath, lf, p.Environ(mainWorktreePath)...)
+	}
+	log.Debug("No %s lockfile found, skipping dependency installation", p.Name)
+	return nil, nil
+}
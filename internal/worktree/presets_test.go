@@ -0,0 +1,150 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+)
+
+func TestDetectPreset(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"go.mod", "go.sum"}, config.PresetGo},
+		{[]string{"package.json", "yarn.lock"}, config.PresetNode},
+		{[]string{"pyproject.toml"}, config.PresetPython},
+		{[]string{"requirements.txt"}, config.PresetPython},
+		{[]string{"Cargo.toml"}, config.PresetRust},
+		{[]string{"Gemfile.lock"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for _, f := range tt.files {
+			os.WriteFile(filepath.Join(dir, f), []byte(""), 0644)
+		}
+		got := ""
+		if p := DetectPreset(dir); p != nil {
+			got = p.Name
+		}
+		if got != tt.want {
+			t.Errorf("DetectPreset(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestGetPreset(t *testing.T) {
+	for _, name := range []string{config.PresetGo, config.PresetNode, config.PresetPython, config.PresetRust} {
+		if p := GetPreset(name); p == nil || p.Name != name || len(p.Installs) == 0 {
+			t.Errorf("GetPreset(%q) = %+v", name, p)
+		}
+	}
+	if p := GetPreset("java"); p != nil {
+		t.Errorf("GetPreset(java) = %+v, want nil", p)
+	}
+}
+
+func TestResolvePreset(t *testing.T) {
+	mainDir := t.TempDir()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte("{}"), 0644)
+
+	if p := ResolvePreset(dir, &config.Config{}, mainDir); p == nil || p.Name != config.PresetNode {
+		t.Errorf("ResolvePreset() = %+v, want the detected node preset", p)
+	}
+
+	cfg := &config.Config{Worktree: config.WorktreeConfig{Preset: config.PresetRust}}
+	if p := ResolvePreset(dir, cfg, mainDir); p == nil || p.Name != config.PresetRust {
+		t.Errorf("ResolvePreset() = %+v, want the configured rust preset", p)
+	}
+
+	// init_commands replace the presets
+	cfg = &config.Config{Worktree: config.WorktreeConfig{Preset: config.PresetRust, InitCommands: "make setup"}}
+	if p := ResolvePreset(dir, cfg, mainDir); p != nil {
+		t.Errorf("ResolvePreset() with init_commands = %+v, want nil", p)
+	}
+}
+
+func TestPreset_Environ(t *testing.T) {
+	env := GetPreset(config.PresetRust).Environ("/repo")
+	if len(env) != 1 || env[0] != "CARGO_TARGET_DIR=/repo/target" {
+		t.Errorf("Environ() = %v", env)
+	}
+}
+
+func TestPreset_Install(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping shell script test on Windows")
+	}
+
+	// A fake installer on PATH records its arguments and environment
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@ $TOOL_CACHE\" > installed.txt\n"
+	os.WriteFile(filepath.Join(binDir, "fake-pm"), []byte(script), 0755)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	preset := &Preset{
+		Name: "fake",
+		Installs: []Lockfile{
+			{Name: "fake.lock", Command: "fake-pm", Args: []string{"install", "--locked"}, Description: "fake"},
+			{Command: "fake-pm", Args: []string{"install"}, Description: "fake"},
+		},
+		Env: []string{"TOOL_CACHE=$MAIN_WORKTREE/.cache"},
+	}
+
+	dir := t.TempDir()
+	if _, err := preset.Install(dir, "/repo"); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "installed.txt")); strings.TrimSpace(string(data)) != "install /repo/.cache" {
+		t.Errorf("without a lockfile installed with %q, want the fallback", data)
+	}
+
+	os.WriteFile(filepath.Join(dir, "fake.lock"), []byte(""), 0644)
+	if _, err := preset.Install(dir, "/repo"); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "installed.txt")); strings.TrimSpace(string(data)) != "install --locked /repo/.cache" {
+		t.Errorf("with a lockfile installed with %q, want the locked install", data)
+	}
+
+	// Without a matching lockfile or fallback nothing runs
+	locked := &Preset{Name: "fake", Installs: preset.Installs[:1]}
+	if result, err := locked.Install(t.TempDir(), "/repo"); result != nil || err != nil {
+		t.Errorf("Install() = %+v, %v, want nothing to install", result, err)
+	}
+
+	// A missing installer is reported
+	missing := &Preset{Name: "fake", Installs: []Lockfile{{Command: "no-such-pm", Description: "missing"}}}
+	if _, err := missing.Install(t.TempDir(), "/repo"); !errors.Is(err, ErrCommandNotFound) {
+		t.Errorf("Install() error = %v, want ErrCommandNotFound", err)
+	}
+}
+
+func TestRunInitHooks_Preset(t *testing.T) {
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+
+	// An unknown preset is an error
+	cfg := &config.Config{Worktree: config.WorktreeConfig{Preset: "java"}}
+	if _, err := RunInitHooks(worktreeDir, cfg, mainDir); err == nil {
+		t.Error("RunInitHooks() should fail for an unknown preset")
+	}
+
+	// The configured preset runs even without its marker file; with no
+	// lockfile and no fallback install there's nothing to do
+	cfg = &config.Config{Worktree: config.WorktreeConfig{Preset: config.PresetGo}}
+	result, err := RunInitHooks(worktreeDir, cfg, mainDir)
+	if err != nil {
+		t.Fatalf("RunInitHooks() error = %v", err)
+	}
+	if result.Method != "none" {
+		t.Errorf("Method = %q, want 'none' without go.mod", result.Method)
+	}
+}
@@ -0,0 +1,81 @@
+package worktree
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+// ScratchEnv is the environment variable holding a plan's scratch directory.
+const ScratchEnv = "RALPH_SCRATCH"
+
+// ScratchRoot returns the directory holding the repository's per-plan
+// scratch directories: worktree.scratch_dir, relative to repoRoot, or if
+// unset a directory under the system temp directory named after the
+// repository, e.g. /tmp/ralph-scratch/app-1a2b3c4d.
+func ScratchRoot(cfg *config.Config, repoRoot string) string {
+	if abs, err := filepath.Abs(repoRoot); err == nil {
+		repoRoot = abs
+	}
+	if cfg != nil && cfg.Worktree.ScratchDir != "" {
+		if filepath.IsAbs(cfg.Worktree.ScratchDir) {
+			return cfg.Worktree.ScratchDir
+		}
+		return filepath.Join(repoRoot, cfg.Worktree.ScratchDir)
+	}
+	// The hash keeps repositories with the same name apart
+	sum := sha256.Sum256([]byte(repoRoot))
+	return filepath.Join(os.TempDir(), "ralph-scratch", filepath.Base(repoRoot)+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// ScratchName returns the name of a plan's scratch directory: the plan's slug
+// and a short hash of its name, so plans whose names differ only in
+// punctuation, or have no ASCII letters or digits at all, get
+// directories of their own, e.g. my-plan-1a2b3c4d.
+func ScratchName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:4])
+	if slug := plan.Slug(name); slug != "" {
+		return slug + "-" + hash
+	}
+	return hash
+}
+
+// ScratchPath returns a plan's scratch directory under root.
+func ScratchPath(root string, p *plan.Plan) string {
+	return filepath.Join(root, ScratchName(p.Name))
+}
+
+// CreateScratch creates a plan's scratch directory under root, readable only
+// by its owner, and returns its path.
+func CreateScratch(root string, p *plan.Plan) (string, error) {
+	dir := ScratchPath(root, p)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating scratch directory: %w", err)
+	}
+	return dir, nil
+}
+
+// ScratchEnviron returns the environment pointing a plan's runs at its
+// scratch directory: RALPH_SCRATCH, and TMPDIR so temp files land there too.
+func ScratchEnviron(dir string) []string {
+	return []string{ScratchEnv + "=" + dir, "TMPDIR=" + dir}
+}
+
+// RemoveScratch removes a plan's scratch directory under root, if any.
+// It refuses to remove root itself.
+func RemoveScratch(root string, p *plan.Plan) error {
+	dir := ScratchPath(root, p)
+	if filepath.Clean(dir) == filepath.Clean(root) {
+		return fmt.Errorf("refusing to remove scratch root %s", root)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing scratch directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package worktree
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/plan"
+)
+
+func TestScratchRoot(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "app")
+	cfg := config.Defaults()
+
+	root := ScratchRoot(cfg, repo)
+	if !strings.HasPrefix(root, filepath.Join(os.TempDir(), "ralph-scratch", "app-")) {
+		t.Errorf("ScratchRoot() = %q, want a directory under the temp dir named after the repo", root)
+	}
+	if other := ScratchRoot(cfg, filepath.Join(t.TempDir(), "app")); other == root {
+		t.Errorf("repositories with the same name share scratch root %q", root)
+	}
+
+	cfg.Worktree.ScratchDir = "../scratch"
+	if got, want := ScratchRoot(cfg, repo), filepath.Join(filepath.Dir(repo), "scratch"); got != want {
+		t.Errorf("ScratchRoot(relative) = %q, want %q", got, want)
+	}
+	cfg.Worktree.ScratchDir = filepath.Join(repo, "abs")
+	if got := ScratchRoot(cfg, repo); got != cfg.Worktree.ScratchDir {
+		t.Errorf("ScratchRoot(absolute) = %q, want %q", got, cfg.Worktree.ScratchDir)
+	}
+}
+
+func TestScratchName(t *testing.T) {
+	if got := ScratchName("My Plan"); !strings.HasPrefix(got, "my-plan-") {
+		t.Errorf("ScratchName(\"My Plan\") = %q, want the slug and a hash", got)
+	}
+	if got := ScratchName("修复"); got == "" || strings.ContainsAny(got, "/\\.") {
+		t.Errorf("ScratchName(\"修复\") = %q, want a non-empty directory name", got)
+	}
+	if ScratchName("fix: api") == ScratchName("fix api") {
+		t.Error("names differing only in punctuation share a scratch directory")
+	}
+}
+
+func TestRemoveScratch_NoSlug(t *testing.T) {
+	root := t.TempDir()
+	other := filepath.Join(root, "other-plan")
+	os.MkdirAll(other, 0755)
+
+	p := &plan.Plan{Name: "修复"}
+	if _, err := CreateScratch(root, p); err != nil {
+		t.Fatalf("CreateScratch() error = %v", err)
+	}
+	if err := RemoveScratch(root, p); err != nil {
+		t.Fatalf("RemoveScratch() error = %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("another plan's scratch directory was removed: %v", err)
+	}
+}
+
+func TestCreateAndRemoveScratch(t *testing.T) {
+	root := t.TempDir()
+	p := &plan.Plan{Name: "My Plan"}
+
+	dir, err := CreateScratch(root, p)
+	if err != nil {
+		t.Fatalf("CreateScratch() error = %v", err)
+	}
+	if dir != ScratchPath(root, p) {
+		t.Errorf("CreateScratch() = %q, want %q", dir, ScratchPath(root, p))
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("scratch directory not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		t.Errorf("scratch directory mode = %v, want 0700", info.Mode().Perm())
+	}
+	if env := ScratchEnviron(dir); len(env) != 2 || env[0] != "RALPH_SCRATCH="+dir || env[1] != "TMPDIR="+dir {
+		t.Errorf("ScratchEnviron() = %q", env)
+	}
+
+	os.WriteFile(filepath.Join(dir, "artifact"), []byte("x"), 0644)
+	if err := RemoveScratch(root, p); err != nil {
+		t.Fatalf("RemoveScratch() error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("scratch directory still exists: %v", err)
+	}
+	if err := RemoveScratch(root, p); err != nil {
+		t.Errorf("RemoveScratch() of a missing directory error = %v", err)
+	}
+}
@@ -9,10 +9,19 @@ import (
 	"strings"
 
 	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/git"
 	"github.com/arvesolland/ralph/internal/log"
 	"github.com/arvesolland/ralph/internal/plan"
 )
 
+// ConflictSuffix is appended to a plan or progress file's path for the
+// worktree's version when it conflicts with edits in the main worktree.
+const ConflictSuffix = ".conflict"
+
+// syncBaseDirName is the directory in the worktree's git dir holding the
+// plan and progress files as last synced, to detect edits on both sides.
+const syncBaseDirName = "ralph-sync"
+
 // SyncToWorktree copies plan, progress, and feedback files from the main worktree
 // to the execution worktree. Also copies .env files based on config.worktree.copy_env_files.
 //
@@ -77,9 +86,18 @@ func SyncToWorktree(p *plan.Plan, worktreePath string, cfg *config.Config, mainW
 		log.Debug("Copied feedback file: %s -> %s", feedbackPath, feedbackDstPath)
 	}
 
+	// Remember what was synced, to detect edits in the main worktree later
+	if baseDir := syncBaseDir(worktreePath); baseDir != "" {
+		for _, path := range []string{planPath, progressPath} {
+			if err := saveSyncBase(baseDir, path); err != nil {
+				log.Debug("Failed to record sync base for %s: %v", path, err)
+			}
+		}
+	}
+
 	// Copy .env files based on config
 	if cfg != nil && cfg.Worktree.CopyEnvFiles != "" {
-		envFiles := parseEnvFileList(cfg.Worktree.CopyEnvFiles)
+		envFiles := ParseEnvFileList(cfg.Worktree.CopyEnvFiles)
 		for _, envFile := range envFiles {
 			srcPath := filepath.Join(mainWorktreePath, envFile)
 			dstPath := filepath.Join(worktreePath, envFile)
@@ -100,6 +118,13 @@ func SyncToWorktree(p *plan.Plan, worktreePath string, cfg *config.Config, mainW
 // SyncFromWorktree copies plan and progress files from the execution worktree
 // back to the main worktree. This syncs changes made by the agent back to the queue.
 //
+// If a file also changed in the main worktree since SyncToWorktree (e.g. a
+// human edited the plan), the two versions are merged section by section
+// (see mergeSections) and the result is written to both worktrees. If they
+// can't be merged, the main worktree's file is kept, the worktree's version
+// is written next to it with ConflictSuffix, and a feedback entry asks the
+// agent to reconcile them.
+//
 // Missing source files are silently skipped (not an error).
 // Feedback file is NOT synced back (human input comes from main worktree).
 func SyncFromWorktree(p *plan.Plan, worktreePath string, mainWorktreePath string) error {
@@ -122,8 +147,10 @@ func SyncFromWorktree(p *plan.Plan, worktreePath string, mainWorktreePath string
 	}
 	progressSrcPath := filepath.Join(worktreePath, progressRelPath)
 
+	baseDir := syncBaseDir(worktreePath)
+
 	// Copy plan file back
-	if err := copyFile(planSrcPath, planPath); err != nil {
+	if err := syncBack(p, baseDir, planSrcPath, planPath); err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("copying plan file back: %w", err)
 		}
@@ -133,7 +160,7 @@ func SyncFromWorktree(p *plan.Plan, worktreePath string, mainWorktreePath string
 	}
 
 	// Copy progress file back
-	if err := copyFile(progressSrcPath, progressPath); err != nil {
+	if err := syncBack(p, baseDir, progressSrcPath, progressPath); err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("copying progress file back: %w", err)
 		}
@@ -145,6 +172,166 @@ func SyncFromWorktree(p *plan.Plan, worktreePath string, mainWorktreePath string
 	return nil
 }
 
+// PullPlanEdits copies edits made to the plan file in the main worktree since
+// it was last synced into the plan's worktree, so the agent doesn't work from
+// a stale copy. If the agent changed the worktree's copy too, the edits are
+// merged section by section; if they overlap, the worktree is left alone and
+// SyncFromWorktree reports the conflict when the run ends.
+//
+// Returns whether the worktree's copy was updated, and the names of the
+// sections that were edited in the main worktree. Does nothing without a
+// sync base (see SyncToWorktree).
+func PullPlanEdits(p *plan.Plan, worktreePath string, mainWorktreePath string) (bool, []string, error) {
+	baseDir := syncBaseDir(worktreePath)
+	base, ok := readSyncBase(baseDir, p.Path)
+	if !ok {
+		return false, nil, nil
+	}
+
+	edited, err := os.ReadFile(p.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil, nil
+		}
+		return false, nil, fmt.Errorf("reading plan file: %w", err)
+	}
+	if string(edited) == base {
+		return false, nil, nil
+	}
+
+	planRelPath, err := filepath.Rel(mainWorktreePath, p.Path)
+	if err != nil {
+		planRelPath = filepath.Join("plans", "current", filepath.Base(p.Path))
+	}
+	dstPath := filepath.Join(worktreePath, planRelPath)
+	current, err := os.ReadFile(dstPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("reading worktree plan file: %w", err)
+	}
+
+	updated := string(edited)
+	if string(current) != base && string(current) != updated {
+		merged, ok := mergeSections(base, string(current), updated)
+		if !ok {
+			log.Debug("Plan edits in the main worktree overlap the agent's, leaving them for sync-back")
+			return false, nil, nil
+		}
+		updated = merged
+	}
+
+	if err := plan.WriteFileAtomic(dstPath, []byte(updated), 0644); err != nil {
+		return false, nil, fmt.Errorf("writing worktree plan file: %w", err)
+	}
+	// The main worktree's copy is now the common ancestor: anything else in
+	// the worktree is the agent's and is synced back over it later
+	if err := writeSyncBase(baseDir, p.Path, edited); err != nil {
+		return true, nil, fmt.Errorf("recording sync base: %w", err)
+	}
+	return true, changedSections(base, string(edited)), nil
+}
+
+// syncBack copies src (in the plan's worktree) to dst (in the main
+// worktree), merging or reporting a conflict if dst changed since the base
+// recorded in baseDir. Without a recorded base dst is overwritten.
+// Returns os.ErrNotExist if src doesn't exist.
+func syncBack(p *plan.Plan, baseDir, src, dst string) error {
+	theirs, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	base, hasBase := readSyncBase(baseDir, dst)
+	ours, err := os.ReadFile(dst)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return err
+	case err != nil || !hasBase || string(ours) == base || string(ours) == string(theirs):
+		// Unchanged in the main worktree (or nothing to compare with)
+		if err := copyFile(src, dst); err != nil {
+			return err
+		}
+		return writeSyncBase(baseDir, dst, theirs)
+	case string(theirs) == base:
+		// Only the main worktree changed; keep it
+		log.Debug("%s changed only in the main worktree, keeping it", dst)
+		return nil
+	}
+
+	if merged, ok := mergeSections(base, string(ours), string(theirs)); ok {
+		log.Info("Merged edits to %s from the main worktree with the agent's changes", filepath.Base(dst))
+		for _, path := range []string{dst, src} {
+			if err := plan.WriteFileAtomic(path, []byte(merged), 0644); err != nil {
+				return fmt.Errorf("writing merged file: %w", err)
+			}
+		}
+		return writeSyncBase(baseDir, dst, []byte(merged))
+	}
+
+	conflictPath := dst + ConflictSuffix
+	if err := copyFile(src, conflictPath); err != nil {
+		return fmt.Errorf("writing conflict copy: %w", err)
+	}
+	log.Warn("%s changed in both the main worktree and the plan's worktree; kept the main worktree's version, the agent's is in %s", filepath.Base(dst), filepath.Base(conflictPath))
+	feedback := fmt.Sprintf("%s was edited in the main worktree while you worked, and the edits overlap yours. The edited version is now the plan's copy; your version was saved as %s. Reconcile the two, keeping the edits, and redo any of your changes that are missing.", filepath.Base(dst), filepath.Base(conflictPath))
+	if err := plan.AppendFeedback(p, "sync", feedback); err != nil {
+		log.Warn("Failed to add sync conflict feedback: %v", err)
+	}
+	return nil
+}
+
+// syncBaseDir returns where the sync base is stored for a worktree: inside
+// its git dir, so it's never committed. Returns "" if the worktree has no git
+// dir, in which case sync-back overwrites without conflict detection.
+func syncBaseDir(worktreePath string) string {
+	if gitDir, err := git.WorktreeGitDir(worktreePath); err == nil {
+		return filepath.Join(gitDir, syncBaseDirName)
+	}
+	if info, err := os.Stat(filepath.Join(worktreePath, ".git")); err == nil && info.IsDir() {
+		return filepath.Join(worktreePath, ".git", syncBaseDirName)
+	}
+	return ""
+}
+
+// saveSyncBase records path's current content as the sync base. A missing
+// file clears the base.
+func saveSyncBase(baseDir, path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		err = os.Remove(filepath.Join(baseDir, filepath.Base(path)))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return writeSyncBase(baseDir, path, data)
+}
+
+// writeSyncBase records data as the sync base for path.
+func writeSyncBase(baseDir, path string, data []byte) error {
+	if baseDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(baseDir, filepath.Base(path)), data, 0644)
+}
+
+// readSyncBase returns the sync base for path, if one was recorded.
+func readSyncBase(baseDir, path string) (string, bool) {
+	if baseDir == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir, filepath.Base(path)))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
 // copyFile copies a file from src to dst, preserving file permissions.
 // Creates destination directory if it doesn't exist.
 // Returns os.ErrNotExist if source file doesn't exist.
@@ -183,10 +370,10 @@ func copyFile(src, dst string) error {
 	return nil
 }
 
-// parseEnvFileList parses a comma-separated list of env file names.
+// ParseEnvFileList parses a comma-separated list of env file names.
 // Trims whitespace from each entry.
 // Example: ".env, .env.local" -> [".env", ".env.local"]
-func parseEnvFileList(list string) []string {
+func ParseEnvFileList(list string) []string {
 	if list == "" {
 		return nil
 	}
@@ -3,6 +3,7 @@ package worktree
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/arvesolland/ralph/internal/config"
@@ -358,7 +359,7 @@ func TestParseEnvFileList(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseEnvFileList(tt.input)
+			result := ParseEnvFileList(tt.input)
 
 			if len(result) != len(tt.expected) {
 				t.Errorf("Length mismatch: got %d, want %d", len(result), len(tt.expected))
@@ -410,3 +411,165 @@ func TestSyncToWorktree_PreservesPermissions(t *testing.T) {
 		t.Errorf("Permissions not preserved: src %v, dst %v", srcInfo.Mode(), dstInfo.Mode())
 	}
 }
+
+// setupSyncedPlan syncs a plan with the given content to a worktree with a
+// git dir, so the sync base is recorded, and returns the plan and the path
+// of its copy in the worktree.
+func setupSyncedPlan(t *testing.T, content string) (*plan.Plan, string, string, string) {
+	t.Helper()
+	mainDir := t.TempDir()
+	worktreeDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(worktreeDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plansDir := filepath.Join(mainDir, "plans", "current")
+	if err := os.MkdirAll(plansDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	planPath := filepath.Join(plansDir, "test-plan.md")
+	if err := os.WriteFile(planPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &plan.Plan{Path: planPath, Name: "test-plan"}
+	if err := SyncToWorktree(p, worktreeDir, &config.Config{}, mainDir); err != nil {
+		t.Fatalf("SyncToWorktree failed: %v", err)
+	}
+	return p, mainDir, worktreeDir, filepath.Join(worktreeDir, "plans", "current", "test-plan.md")
+}
+
+func TestSyncFromWorktree_MergesMainEdits(t *testing.T) {
+	p, mainDir, worktreeDir, wtPlanPath := setupSyncedPlan(t, "# Plan\n## Tasks\n- [ ] one\n## Notes\nnone\n")
+
+	// A human edits the notes while the agent checks off a task
+	os.WriteFile(p.Path, []byte("# Plan\n## Tasks\n- [ ] one\n## Notes\nUse the v2 API\n"), 0644)
+	os.WriteFile(wtPlanPath, []byte("# Plan\n## Tasks\n- [x] one\n## Notes\nnone\n"), 0644)
+
+	if err := SyncFromWorktree(p, worktreeDir, mainDir); err != nil {
+		t.Fatalf("SyncFromWorktree failed: %v", err)
+	}
+
+	want := "# Plan\n## Tasks\n- [x] one\n## Notes\nUse the v2 API\n"
+	for _, path := range []string{p.Path, wtPlanPath} {
+		if content, _ := os.ReadFile(path); string(content) != want {
+			t.Errorf("%s = %q, want %q", path, content, want)
+		}
+	}
+	if _, err := os.Stat(p.Path + ConflictSuffix); !os.IsNotExist(err) {
+		t.Error("clean merge should not write a conflict copy")
+	}
+
+	// The agent's next change applies on top of the merge
+	os.WriteFile(wtPlanPath, []byte("# Plan\n## Tasks\n- [x] one\n## Notes\nUse the v2 API\n## Done\n"), 0644)
+	if err := SyncFromWorktree(p, worktreeDir, mainDir); err != nil {
+		t.Fatalf("SyncFromWorktree failed: %v", err)
+	}
+	if content, _ := os.ReadFile(p.Path); string(content) != "# Plan\n## Tasks\n- [x] one\n## Notes\nUse the v2 API\n## Done\n" {
+		t.Errorf("plan after second sync = %q", content)
+	}
+}
+
+func TestSyncFromWorktree_Conflict(t *testing.T) {
+	p, mainDir, worktreeDir, wtPlanPath := setupSyncedPlan(t, "# Plan\n## Tasks\n- [ ] one\n")
+
+	humanContent := "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n"
+	agentContent := "# Plan\n## Tasks\n- [x] one\n"
+	os.WriteFile(p.Path, []byte(humanContent), 0644)
+	os.WriteFile(wtPlanPath, []byte(agentContent), 0644)
+
+	if err := SyncFromWorktree(p, worktreeDir, mainDir); err != nil {
+		t.Fatalf("SyncFromWorktree failed: %v", err)
+	}
+
+	if content, _ := os.ReadFile(p.Path); string(content) != humanContent {
+		t.Errorf("main plan = %q, want the human's edit kept", content)
+	}
+	if content, err := os.ReadFile(p.Path + ConflictSuffix); err != nil || string(content) != agentContent {
+		t.Errorf("conflict copy = %q, %v, want the agent's version", content, err)
+	}
+
+	feedback, err := plan.LoadFeedback(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback.Pending) != 1 || !strings.Contains(feedback.Pending[0].Text, "test-plan.md.conflict") {
+		t.Errorf("pending feedback = %+v, want a reconcile request", feedback.Pending)
+	}
+}
+
+func TestSyncFromWorktree_MainOnlyEdit(t *testing.T) {
+	p, mainDir, worktreeDir, _ := setupSyncedPlan(t, "# Plan\n## Tasks\n- [ ] one\n")
+
+	humanContent := "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n"
+	os.WriteFile(p.Path, []byte(humanContent), 0644)
+
+	if err := SyncFromWorktree(p, worktreeDir, mainDir); err != nil {
+		t.Fatalf("SyncFromWorktree failed: %v", err)
+	}
+	if content, _ := os.ReadFile(p.Path); string(content) != humanContent {
+		t.Errorf("main plan = %q, want the edit kept", content)
+	}
+}
+
+func TestPullPlanEdits(t *testing.T) {
+	p, mainDir, worktreeDir, wtPlanPath := setupSyncedPlan(t, "# Plan\n## Tasks\n- [ ] one\n## Notes\nnone\n")
+
+	// Nothing edited yet
+	if updated, _, err := PullPlanEdits(p, worktreeDir, mainDir); err != nil || updated {
+		t.Fatalf("PullPlanEdits() = %t, %v, want no update", updated, err)
+	}
+
+	// A human adds a task while the agent writes notes
+	os.WriteFile(p.Path, []byte("# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nnone\n"), 0644)
+	os.WriteFile(wtPlanPath, []byte("# Plan\n## Tasks\n- [ ] one\n## Notes\nfound the bug\n"), 0644)
+
+	updated, sections, err := PullPlanEdits(p, worktreeDir, mainDir)
+	if err != nil || !updated {
+		t.Fatalf("PullPlanEdits() = %t, %v, want an update", updated, err)
+	}
+	if len(sections) != 1 || sections[0] != "Tasks" {
+		t.Errorf("sections = %v, want [Tasks]", sections)
+	}
+	want := "# Plan\n## Tasks\n- [ ] one\n- [ ] two\n## Notes\nfound the bug\n"
+	if content, _ := os.ReadFile(wtPlanPath); string(content) != want {
+		t.Errorf("worktree plan = %q, want %q", content, want)
+	}
+
+	// Pulled once; the agent's version is synced back over the main worktree's
+	if updated, _, _ := PullPlanEdits(p, worktreeDir, mainDir); updated {
+		t.Error("PullPlanEdits() pulled the same edit twice")
+	}
+	if err := SyncFromWorktree(p, worktreeDir, mainDir); err != nil {
+		t.Fatalf("SyncFromWorktree failed: %v", err)
+	}
+	if content, _ := os.ReadFile(p.Path); string(content) != want {
+		t.Errorf("main plan after sync-back = %q, want %q", content, want)
+	}
+	if _, err := os.Stat(p.Path + ConflictSuffix); !os.IsNotExist(err) {
+		t.Error("sync-back after a pull should not conflict")
+	}
+}
+
+func TestPullPlanEdits_Overlap(t *testing.T) {
+	p, mainDir, worktreeDir, wtPlanPath := setupSyncedPlan(t, "# Plan\n## Tasks\n- [ ] one\n")
+
+	os.WriteFile(p.Path, []byte("# Plan\n## Tasks\n- [ ] one\n- [ ] two\n"), 0644)
+	agentContent := "# Plan\n## Tasks\n- [x] one\n"
+	os.WriteFile(wtPlanPath, []byte(agentContent), 0644)
+
+	if updated, _, err := PullPlanEdits(p, worktreeDir, mainDir); err != nil || updated {
+		t.Fatalf("PullPlanEdits() = %t, %v, want overlapping edits left alone", updated, err)
+	}
+	if content, _ := os.ReadFile(wtPlanPath); string(content) != agentContent {
+		t.Errorf("worktree plan = %q, want it unchanged", content)
+	}
+}
+
+func TestPullPlanEdits_NoBase(t *testing.T) {
+	mainDir := t.TempDir()
+	p := &plan.Plan{Path: filepath.Join(mainDir, "plans", "current", "test-plan.md"), Name: "test-plan"}
+	if updated, _, err := PullPlanEdits(p, t.TempDir(), mainDir); err != nil || updated {
+		t.Errorf("PullPlanEdits() = %t, %v, want nothing without a sync base", updated, err)
+	}
+}
@@ -0,0 +1,70 @@
+package ralph
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/log"
+)
+
+// Event is an entry in the worker events log, .ralph/events.jsonl.
+type Event = events.Event
+
+// Plan lifecycle event types. The log records other types too, such as
+// "verification_failed", as stable snake_case strings.
+const (
+	EventPlanStarted   = events.TypePlanStarted
+	EventIteration     = events.TypeIteration
+	EventBlocker       = events.TypeBlocker
+	EventStageChanged  = events.TypeStageChanged
+	EventPlanCompleted = events.TypePlanCompleted
+	EventPlanFailed    = events.TypePlanFailed
+	EventPlanError     = events.TypePlanError
+)
+
+// subscribePollInterval is how often Subscribe checks the events log.
+var subscribePollInterval = 500 * time.Millisecond
+
+// Subscribe returns a channel of the events recorded from now on by any
+// worker on this repository, in this process or another. The channel is
+// closed when ctx is canceled. Events are delivered in order; a subscriber
+// that stops reading holds up only its own channel.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	var offset int64
+	if info, err := os.Stat(events.Path(c.configDir)); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	ch := make(chan Event, 64)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(subscribePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			batch, next, err := c.events.ReadFrom(offset)
+			if err != nil {
+				log.Debug("Failed to read events log: %v", err)
+				continue
+			}
+			offset = next
+			for _, e := range batch {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,48 @@
+package ralph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/events"
+)
+
+func TestSubscribe(t *testing.T) {
+	defer func(d time.Duration) { subscribePollInterval = d }(subscribePollInterval)
+	subscribePollInterval = 10 * time.Millisecond
+
+	c := newTestClient(t)
+	log := events.NewLog(events.Path(c.configDir))
+	log.Append(Event{Type: EventPlanStarted, Plan: "old"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := c.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	log.Append(Event{Type: EventPlanStarted, Plan: "new"})
+	log.Append(Event{Type: EventIteration, Plan: "new", Iteration: 1})
+
+	for _, want := range []string{EventPlanStarted, EventIteration} {
+		select {
+		case e := <-ch:
+			if e.Type != want || e.Plan != "new" {
+				t.Errorf("event = %+v, want %s for new", e, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("unexpected event after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+}
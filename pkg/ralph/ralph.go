@@ -0,0 +1,229 @@
+// Package ralph is the public Go API for driving ralph from other programs:
+// creating and queueing plans, running the worker, and following its progress,
+// without shelling out to the ralph CLI. It works on the same plans/ queue and
+// .ralph/ state as the CLI, so the two can be mixed freely.
+package ralph
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/audit"
+	"github.com/arvesolland/ralph/internal/config"
+	"github.com/arvesolland/ralph/internal/events"
+	"github.com/arvesolland/ralph/internal/git"
+	"github.com/arvesolland/ralph/internal/plan"
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// Client drives the plan queue and worker of one repository.
+// It is safe for concurrent use.
+type Client struct {
+	root      string
+	configDir string
+	cfg       *config.Config
+	git       git.Git
+	queue     *plan.Queue
+	events    *events.Log
+
+	// runner replaces the claude CLI runner, for tests.
+	runner runner.Runner
+}
+
+// Open returns a Client for the git repository containing dir, loading its
+// .ralph/config.yaml (defaults apply if there is none).
+func Open(dir string) (*Client, error) {
+	g := git.NewGit(dir)
+	root, err := g.RepoRoot()
+	if err != nil {
+		return nil, fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	configDir := filepath.Join(root, ".ralph")
+	cfg, err := config.LoadWithDefaults(filepath.Join(configDir, "config.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	eventLog := events.NewLog(events.Path(configDir))
+	queue := plan.NewQueue(filepath.Join(root, "plans"))
+	queue.Events = eventLog
+	if cfg.Audit.Enabled {
+		queue.Audit = audit.NewLog(audit.Path(configDir), "api")
+	}
+
+	return &Client{
+		root:      root,
+		configDir: configDir,
+		cfg:       cfg,
+		git:       g,
+		queue:     queue,
+		events:    eventLog,
+	}, nil
+}
+
+// Root returns the repository root.
+func (c *Client) Root() string {
+	return c.root
+}
+
+// Plan describes a plan file in the queue.
+type Plan struct {
+	// Name is the plan name, the file name without ".md".
+	Name string
+
+	// Path is the absolute path to the plan file.
+	Path string
+
+	// Status is the plan's **Status:** header (e.g. "pending", "complete").
+	Status string
+
+	// Branch is the git branch the worker uses for the plan.
+	Branch string
+
+	// Labels are the plan's **Labels:** header values, lowercased.
+	Labels []string
+
+	// TasksDone and TasksTotal count the plan's checkbox tasks.
+	TasksDone  int
+	TasksTotal int
+}
+
+// newPlan converts a loaded plan.
+func newPlan(p *plan.Plan) *Plan {
+	return &Plan{
+		Name:       p.Name,
+		Path:       p.Path,
+		Status:     p.Status,
+		Branch:     p.Branch,
+		Labels:     p.Labels,
+		TasksDone:  plan.CountComplete(p.Tasks),
+		TasksTotal: plan.CountTotal(p.Tasks),
+	}
+}
+
+// PlanOptions describes a new plan created by CreatePlan.
+type PlanOptions struct {
+	// Title is the plan title. The plan name is derived from it. Required
+	// unless Content has a "# Plan: Title" heading.
+	Title string
+
+	// Content is the complete plan markdown. If set, it is used as is and
+	// the fields below are ignored.
+	Content string
+
+	// Body is the request text placed in the plan's Context section.
+	Body string
+
+	// Source records where the request came from (e.g. a URL).
+	Source string
+
+	// Tasks are the initial task titles. If empty, a single task asks the
+	// agent to break the request down and implement it.
+	Tasks []string
+
+	// Labels are written to the plan's **Labels:** header.
+	Labels []string
+}
+
+// CreatePlan writes a new plan to plans/pending/, where the worker picks it
+// up. A numeric suffix is added to the name if a plan with it exists.
+func (c *Client) CreatePlan(opts PlanOptions) (*Plan, error) {
+	pending := c.queue.PendingDir()
+	var p *plan.Plan
+	var err error
+	if opts.Content != "" {
+		title := opts.Title
+		if title == "" {
+			title = plan.MarkdownTitle(opts.Content)
+		}
+		if title == "" {
+			return nil, fmt.Errorf("plan title is required")
+		}
+		p, err = plan.Create(pending, title, opts.Content)
+	} else {
+		p, err = plan.Scaffold(pending, plan.ScaffoldOptions{
+			Title:  opts.Title,
+			Body:   opts.Body,
+			Source: opts.Source,
+			Tasks:  opts.Tasks,
+			Labels: opts.Labels,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newPlan(p), nil
+}
+
+// Enqueue copies the plan file at path (e.g. a draft outside plans/) into
+// plans/pending/ under the same name. It fails if a plan with that name is
+// already pending, current, complete, or failed.
+func (c *Client) Enqueue(path string) (*Plan, error) {
+	src, err := plan.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading plan: %w", err)
+	}
+	if existing, err := c.queue.Find(src.Name); err == nil {
+		return nil, fmt.Errorf("plan %s already exists at %s", src.Name, existing.Path)
+	}
+
+	pending := c.queue.PendingDir()
+	if err := os.MkdirAll(pending, 0755); err != nil {
+		return nil, fmt.Errorf("creating plan directory: %w", err)
+	}
+	dst := filepath.Join(pending, src.Name+".md")
+	if err := plan.WriteFileAtomic(dst, []byte(src.Content), 0644); err != nil {
+		return nil, fmt.Errorf("writing plan file: %w", err)
+	}
+	p, err := plan.Load(dst)
+	if err != nil {
+		return nil, err
+	}
+	return newPlan(p), nil
+}
+
+// Status is a snapshot of the plan queue.
+type Status struct {
+	// Pending lists the pending plans by name.
+	Pending []string
+
+	// Current is the plan being worked on, or "" if none.
+	Current string
+
+	// CurrentRemaining estimates the time left on the current plan from
+	// the events log. Zero if there's no current plan or no estimate yet.
+	CurrentRemaining time.Duration
+
+	// Failed lists the plans in plans/failed/.
+	Failed []string
+
+	// Complete and Abandoned count the finished plans.
+	Complete  int
+	Abandoned int
+}
+
+// QueryStatus returns the queue status. With labels, only plans that have
+// all of them are counted, as with `ralph status --label`.
+func (c *Client) QueryStatus(labels ...string) (*Status, error) {
+	s, err := c.queue.StatusFor(labels)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Pending:   s.PendingPlans,
+		Current:   s.CurrentPlan,
+		Failed:    s.FailedPlans,
+		Complete:  s.CompleteCount,
+		Abandoned: s.AbandonedCount,
+	}
+	if s.CurrentETA != nil && s.CurrentETA.Known {
+		status.CurrentRemaining = s.CurrentETA.Remaining
+	}
+	return status, nil
+}
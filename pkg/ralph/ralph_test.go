@@ -0,0 +1,121 @@
+package ralph
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestClient opens a client on a new git repository on branch main.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+		{"add", "README.md"},
+		{"commit", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return c
+}
+
+func TestOpen_NotARepo(t *testing.T) {
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Error("Open() outside a git repository should fail")
+	}
+}
+
+func TestCreatePlan(t *testing.T) {
+	c := newTestClient(t)
+
+	p, err := c.CreatePlan(PlanOptions{Title: "Add login", Body: "Users need to log in.", Tasks: []string{"Form", "Session"}, Labels: []string{"Backend"}})
+	if err != nil {
+		t.Fatalf("CreatePlan() error = %v", err)
+	}
+	if p.Name != "add-login" || p.TasksTotal == 0 || p.TasksDone != 0 {
+		t.Errorf("plan = %+v, want add-login with open tasks", p)
+	}
+	if len(p.Labels) != 1 || p.Labels[0] != "backend" {
+		t.Errorf("labels = %v, want [backend]", p.Labels)
+	}
+	if filepath.Dir(p.Path) != filepath.Join(c.Root(), "plans", "pending") {
+		t.Errorf("path = %s, want plans/pending", p.Path)
+	}
+
+	// Raw content takes its title from the heading
+	p, err = c.CreatePlan(PlanOptions{Content: "# Plan: Fix Bug\n\n- [x] Find it\n- [ ] Fix it\n"})
+	if err != nil {
+		t.Fatalf("CreatePlan(Content) error = %v", err)
+	}
+	if p.Name != "fix-bug" || p.TasksDone != 1 || p.TasksTotal != 2 {
+		t.Errorf("plan = %+v, want fix-bug with 1/2 tasks", p)
+	}
+
+	if _, err := c.CreatePlan(PlanOptions{Content: "no heading\n"}); err == nil {
+		t.Error("CreatePlan() without a title should fail")
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	c := newTestClient(t)
+
+	draft := filepath.Join(t.TempDir(), "my-feature.md")
+	if err := os.WriteFile(draft, []byte("# Plan: My Feature\n\n- [ ] Do it\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := c.Enqueue(draft)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if p.Path != filepath.Join(c.Root(), "plans", "pending", "my-feature.md") {
+		t.Errorf("path = %s", p.Path)
+	}
+	if _, err := os.Stat(draft); err != nil {
+		t.Errorf("draft should be left in place: %v", err)
+	}
+
+	if _, err := c.Enqueue(draft); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Enqueue() of a queued plan error = %v, want already exists", err)
+	}
+}
+
+func TestQueryStatus(t *testing.T) {
+	c := newTestClient(t)
+
+	c.CreatePlan(PlanOptions{Title: "One", Labels: []string{"docs"}})
+	c.CreatePlan(PlanOptions{Title: "Two"})
+
+	status, err := c.QueryStatus()
+	if err != nil {
+		t.Fatalf("QueryStatus() error = %v", err)
+	}
+	if len(status.Pending) != 2 || status.Pending[0] != "one" || status.Current != "" {
+		t.Errorf("status = %+v, want pending [one two]", status)
+	}
+
+	status, err = c.QueryStatus("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Pending) != 1 || status.Pending[0] != "one" {
+		t.Errorf("status with label = %+v, want pending [one]", status)
+	}
+}
@@ -0,0 +1,139 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/prompt"
+	"github.com/arvesolland/ralph/internal/worker"
+	"github.com/arvesolland/ralph/internal/worktree"
+)
+
+var (
+	// ErrQueueEmpty is returned by RunWorker with Once when no plan is pending.
+	ErrQueueEmpty = worker.ErrQueueEmpty
+
+	// ErrPaused is returned by RunWorker with Once while the worker is
+	// paused (/ralph pause in Slack).
+	ErrPaused = worker.ErrPaused
+)
+
+// WorkerOptions configures RunWorker. Zero values use the repository's
+// config and the CLI's defaults.
+type WorkerOptions struct {
+	// Once processes a single plan and returns, like `ralph worker --once`.
+	Once bool
+
+	// MaxIterations is the iteration limit per plan.
+	MaxIterations int
+
+	// CompletionMode is "pr" or "merge". Empty means completion.mode.
+	CompletionMode string
+
+	// PollInterval is how often an empty queue is checked, besides
+	// watching plans/pending/ for new plans.
+	PollInterval time.Duration
+
+	// Labels restricts the worker to plans that have all of them.
+	Labels []string
+
+	// Force starts the worker even if worker.main_repo_check is "fail"
+	// and the main worktree check finds problems.
+	Force bool
+
+	// Drain, when closed, lets the in-flight iteration finish and sync its
+	// state, then stops the worker. Canceling RunWorker's context instead
+	// stops it immediately, and the next run resumes from the checkpoint.
+	Drain <-chan struct{}
+
+	// OnEvent is called with every event the worker records, from the
+	// worker's goroutine. It should return quickly.
+	OnEvent func(Event)
+}
+
+// RunWorker processes plans from the queue until ctx is canceled, Drain is
+// closed, or, with Once, one plan has been processed. It checks the claude
+// CLI first and uses the repository's Slack settings, like `ralph worker`,
+// but installs no signal handlers. Returns nil when stopped by ctx or Drain.
+func (c *Client) RunWorker(ctx context.Context, opts WorkerOptions) error {
+	if err := worker.EnforceMainRepoCheck(c.git, c.cfg, opts.Force); err != nil {
+		return err
+	}
+
+	worktreesDir := filepath.Join(c.configDir, "worktrees")
+	for _, dir := range []string{c.queue.PendingDir(), filepath.Join(c.queue.BaseDir, "current"), filepath.Join(c.queue.BaseDir, "complete"), worktreesDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating %s: %w", dir, err)
+		}
+	}
+
+	wtManager, err := worktree.NewManager(c.git, worktreesDir)
+	if err != nil {
+		return fmt.Errorf("initializing worktree manager: %w", err)
+	}
+
+	claudeRunner := c.runner
+	if claudeRunner == nil {
+		cliRunner, err := worker.NewClaudeRunner(ctx, c.cfg, c.configDir)
+		if err != nil {
+			return err
+		}
+		worker.ApplyPresetEnv(cliRunner, c.cfg, c.root)
+		claudeRunner = cliRunner
+	}
+
+	completionMode := opts.CompletionMode
+	if completionMode == "" {
+		completionMode = c.cfg.Completion.Mode
+	}
+
+	var observers []worker.Observer
+	if opts.OnEvent != nil {
+		observers = append(observers, worker.Callbacks{OnEvent: opts.OnEvent})
+	}
+
+	w := worker.NewWorker(worker.WorkerConfig{
+		Queue:            c.queue,
+		Config:           c.cfg,
+		ConfigDir:        c.configDir,
+		WorktreeManager:  wtManager,
+		Git:              c.git,
+		MainWorktreePath: c.root,
+		Runner:           claudeRunner,
+		PromptBuilder:    prompt.NewBuilder(c.cfg, c.configDir, filepath.Join(c.configDir, "prompts")),
+		PollInterval:     opts.PollInterval,
+		MaxIterations:    opts.MaxIterations,
+		CompletionMode:   completionMode,
+		Labels:           opts.Labels,
+		Observers:        observers,
+	})
+
+	if opts.Drain != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-opts.Drain:
+				w.Drain()
+			case <-done:
+			}
+		}()
+	}
+
+	cleanup := w.SetupNotifications(ctx)
+	defer cleanup()
+
+	if opts.Once {
+		err = w.RunOnce(ctx)
+	} else {
+		err = w.Run(ctx)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, worker.ErrInterrupted) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arvesolland/ralph/internal/runner"
+)
+
+// completingRunner finishes every plan in one iteration and passes verification.
+type completingRunner struct{}
+
+func (completingRunner) Run(ctx context.Context, prompt string, opts runner.Options) (*runner.Result, error) {
+	if opts.Print {
+		return &runner.Result{Output: "YES", TextContent: "YES", Duration: time.Second, Attempts: 1}, nil
+	}
+	return &runner.Result{
+		Output:      "Done",
+		TextContent: "Done\n<promise>COMPLETE</promise>",
+		Duration:    time.Second,
+		Attempts:    1,
+		IsComplete:  true,
+	}, nil
+}
+
+func TestRunWorker_Once(t *testing.T) {
+	c := newTestClient(t)
+	c.runner = completingRunner{}
+	c.cfg.Git.BaseBranch = "main"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := c.RunWorker(ctx, WorkerOptions{Once: true}); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatalf("RunWorker() on an empty queue error = %v, want ErrQueueEmpty", err)
+	}
+
+	if _, err := c.CreatePlan(PlanOptions{Title: "Quick fix", Tasks: []string{"Fix"}}); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	err := c.RunWorker(ctx, WorkerOptions{
+		Once:           true,
+		CompletionMode: "merge",
+		OnEvent:        func(e Event) { types = append(types, e.Type) },
+	})
+	if err != nil {
+		t.Fatalf("RunWorker() error = %v", err)
+	}
+
+	status, err := c.QueryStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Pending) != 0 || status.Current != "" || status.Complete != 1 {
+		t.Errorf("status = %+v, want the plan complete", status)
+	}
+	if len(types) == 0 || types[0] != EventPlanStarted || types[len(types)-1] != EventPlanCompleted {
+		t.Errorf("events = %v, want plan_started ... plan_completed", types)
+	}
+}